@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	stdlog "log"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/proxy"
+	"github.com/mark3labs/mcphost/internal/reload"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate [-config <file>]",
+	Short: "Serve the tools of the configured MCP servers through one MCP server",
+	Long: `Connect as a client to the MCP servers of the mcpServers config mcphost chats
+with, ~/.mcp.json unless -config is given, and serve all of their tools through a
+single MCP server, over stdio, SSE or WebSocket. Each tool is named after its
+server, e.g. fetch.fetchURL or time.getCurrentTime, and its calls are routed to
+that server.
+
+Changes to the config file, or a SIGHUP, are applied without dropping the clients:
+servers added, changed or removed are started or stopped and clients are told the
+tools changed. Use mcphost proxy for bundled servers, SSE servers and renaming or
+hiding tools.
+
+Example:
+  mcphost aggregate
+  mcphost aggregate -config ~/Library/Application\ Support/Claude/claude_desktop_config.json
+  mcphost aggregate -transport=sse -listen :8080 -separator _`,
+	// aggregate parses its flags like the bundled servers do
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAggregate(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+}
+
+// loadAggregateConfig reads the servers of an mcpServers config file.
+func loadAggregateConfig(path, separator string) (*proxy.Config, error) {
+	servers, err := mcpconfig.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.AggregateConfig(servers, separator)
+}
+
+func runAggregate(cmd *cobra.Command, args []string) error {
+	fs := flag.NewFlagSet("aggregate", flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd.Println(cmd.Long)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "~/.mcp.json", "mcpServers config file declaring the servers")
+	separator := fs.String("separator", proxy.AggregateSeparator, "joins the server and tool names of the tools")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+	if err := config.Parse(fs, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	stdlog.SetPrefix("[AggregateServer] ")
+	stdlog.SetFlags(stdlog.Ldate | stdlog.Ltime)
+
+	config, err := loadAggregateConfig(*configPath, *separator)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), proxyConnectTimeout)
+	defer cancel()
+	p, err := proxy.Open(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := middlewareFlags.Apply(context.Background(), "aggregate", p.Server()); err != nil {
+		return err
+	}
+	defer middleware.Close(p.Server())
+
+	// Changes to the config start and stop servers while clients stay connected
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	watched, err := mcpconfig.ExpandHome(*configPath)
+	if err != nil {
+		return err
+	}
+	reload.Watch(watchCtx, []string{watched}, func() {
+		config, err := loadAggregateConfig(*configPath, *separator)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(watchCtx, proxyConnectTimeout)
+			err = p.Reload(ctx, config)
+			cancel()
+		}
+		if err != nil {
+			log.Error("Keeping the previous config", "error", err)
+			return
+		}
+		log.Info("Reloaded config", "tools", len(p.Tools()))
+	})
+
+	log.Info("Serving aggregated tools", "tools", len(p.Tools()), "transport", transportFlags.Transport)
+	return transport.Serve(p.Server(), transportFlags)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/bench"
+	"github.com/mark3labs/mcphost/internal/toolclient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchArgs        []string
+	benchJSON        string
+	benchConcurrency int
+	benchRequests    int
+	benchDuration    time.Duration
+	benchRate        float64
+	benchMaxErrors   float64
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench <server> <tool> [--arg key=value]... [-- server flags]",
+	Short: "Load test a tool of an MCP server",
+	Long: `Call a tool of an MCP server many times, concurrently, and report the latency
+percentiles, the throughput and the error rate of the calls, to check the
+capacity of a server before putting it in production. The server is a bundled
+server, a server of the mcpServers config or the URL of an SSE endpoint; the
+calls share one connection. Flags after -- are passed to the server.
+
+Calls returning a tool error count as tool errors, calls that fail, e.g. time
+out, as failures. With --max-error-rate, the exit status is 1 when the share
+of both is higher, for use in CI.
+
+Example:
+  mcphost bench time getCurrentTime --arg timezone=UTC -c 20 -n 5000
+  mcphost bench http://localhost:8080/sse fetchURL --arg url=http://localhost:9000 -c 50 -d 30s --rate 200`,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBench(cmd, args)
+	},
+}
+
+func init() {
+	flags := benchCmd.Flags()
+	flags.StringArrayVarP(&benchArgs, "arg", "a", nil, "tool argument as key=value, repeatable")
+	flags.StringVar(&benchJSON, "json", "", "tool arguments as a JSON object, extended by --arg")
+	flags.IntVarP(&benchConcurrency, "concurrency", "c", 10, "number of calls in flight at once")
+	flags.IntVarP(&benchRequests, "requests", "n", 1000, "number of calls to make, unless --duration is set")
+	flags.DurationVarP(&benchDuration, "duration", "d", 0, "keep calling for this long instead of making --requests calls")
+	flags.Float64Var(&benchRate, "rate", 0, "maximum calls started per second (0 for no limit)")
+	flags.Float64Var(&benchMaxErrors, "max-error-rate", 1, "fail when the share of failed calls and tool errors is higher, e.g. 0.01")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 && dash < 2 {
+		return errors.New("the server and tool go before --")
+	}
+	if benchConcurrency < 1 || benchRequests < 1 || benchDuration < 0 || benchRate < 0 {
+		return errors.New("--concurrency and --requests must be positive, --duration and --rate not negative")
+	}
+	connectCtx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+	client, err := connectClient(connectCtx, cmd, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(connectCtx, mcp.ListToolsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	tool, err := toolclient.FindTool(tools.Tools, args[1])
+	if err != nil {
+		return err
+	}
+	toolArgs, err := toolclient.Arguments(tool, benchJSON, benchArgs)
+	if err != nil {
+		return err
+	}
+
+	// Interrupting the run reports the calls made so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := bench.Run(ctx, bench.Options{
+		Concurrency: benchConcurrency,
+		Requests:    benchRequests,
+		Duration:    benchDuration,
+		Rate:        benchRate,
+		Timeout:     clientTimeout,
+	}, func(ctx context.Context) (bool, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = tool.Name
+		req.Params.Arguments = toolArgs
+		result, err := client.CallTool(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		return result.IsError, nil
+	})
+
+	if clientOutput == "json" {
+		err = writeJSON(cmd.OutOrStdout(), report)
+	} else {
+		err = report.Write(cmd.OutOrStdout())
+	}
+	if err != nil {
+		return err
+	}
+	if report.Calls == 0 {
+		return errors.New("no calls completed")
+	}
+	if report.ErrorRate > benchMaxErrors {
+		return fmt.Errorf("error rate %.2f%% is over the maximum of %.2f%%", 100*report.ErrorRate, 100*benchMaxErrors)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// chatProviders are the providers mcphost chat drives, with the model each uses
+// unless --model is given.
+var chatProviders = map[string]string{
+	"anthropic": "claude-3-5-sonnet-latest",
+	"openai":    "gpt-4o",
+}
+
+var chatProvider string
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat with a model using the tools of the configured MCP servers",
+	Long: `Start an interactive chat in the terminal with a model that calls the tools of
+the MCP servers of the mcpServers config, ~/.mcp.json unless --config is given.
+The tools of each server are offered to the model as <server>__<tool>; the calls
+it makes are run on their server and the results sent back until it answers.
+
+The model is chosen with --provider and --model, e.g. --provider anthropic
+--model claude-3-5-haiku-latest; --model also takes provider:model, as mcphost
+does. The API keys are read from --openai-api-key or OPENAI_API_KEY and from
+--anthropic-api-key or ANTHROPIC_API_KEY, and --openai-url points the OpenAI
+provider at another OpenAI compatible API.
+
+Example:
+  mcphost chat
+  mcphost chat --provider openai --model gpt-4o-mini
+  mcphost chat --provider anthropic --model claude-3-5-haiku-latest
+  mcphost chat --openai-url http://localhost:8000 --model llama-3.1-8b`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		model, err := chatModel(chatProvider, modelFlag, cmd.Flags().Changed("model"))
+		if err != nil {
+			return err
+		}
+		modelFlag = model
+		return runMCPHost()
+	},
+}
+
+func init() {
+	chatCmd.Flags().StringVar(&chatProvider, "provider", "openai", "provider of the model: "+strings.Join(chatProviderNames(), ", "))
+	rootCmd.AddCommand(chatCmd)
+}
+
+// chatProviderNames returns the names of the chat providers, sorted.
+func chatProviderNames() []string {
+	names := make([]string, 0, len(chatProviders))
+	for name := range chatProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// chatModel returns the provider:model string of the model to chat with: model if it
+// is prefixed with one of the chat providers, else model of provider, or its default
+// model unless modelSet.
+func chatModel(provider, model string, modelSet bool) (string, error) {
+	if modelSet {
+		if prefix, _, ok := strings.Cut(model, ":"); ok {
+			if _, ok := chatProviders[prefix]; ok {
+				return model, nil
+			}
+		}
+	}
+	defaultModel, ok := chatProviders[provider]
+	if !ok {
+		return "", fmt.Errorf("unsupported provider %q; use %s", provider, strings.Join(chatProviderNames(), " or "))
+	}
+	if !modelSet {
+		model = defaultModel
+	}
+	return provider + ":" + model, nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/toolclient"
+	"github.com/spf13/cobra"
+)
+
+var (
+	callArgs        []string
+	callJSON        string
+	clientHeaders   []string
+	clientTimeout   time.Duration
+	clientOutput    string
+	clientServerLog bool
+	toolsVerbose    bool
+)
+
+var callCmd = &cobra.Command{
+	Use:   "call <server> <tool> [--arg key=value]... [-- server flags]",
+	Short: "Call a tool of an MCP server and print the result",
+	Long: `Call a tool of an MCP server as an MCP client and print its result, for testing
+and scripting without an LLM. The server is a bundled server, a server of the
+mcpServers config or the URL of an SSE endpoint. Bundled servers run in process.
+Flags after -- are passed to the server.
+
+Argument values are converted to the types of the tool's input schema; values of
+array arguments can be given as a JSON array or by repeating the argument. The
+exit status is 1 when the tool returns an error.
+
+Example:
+  mcphost call time getCurrentTime --arg timezone=UTC
+  mcphost call fetch fetchURL --arg url=https://example.com -- -timeout 10
+  mcphost call http://localhost:8080/sse searchGoogle --json '{"query": "mcp"}'`,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCall(cmd, args)
+	},
+}
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools <server> [-- server flags]",
+	Short: "List the tools of an MCP server",
+	Long: `List the tools of an MCP server as an MCP client. The server is a bundled
+server, a server of the mcpServers config or the URL of an SSE endpoint. Flags
+after -- are passed to the server.
+
+Example:
+  mcphost tools fetch --verbose
+  mcphost tools sqlite -- --db-path /tmp/foo.db
+  mcphost tools http://localhost:8080/sse --output json`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTools(cmd, args)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{callCmd, toolsCmd, benchCmd} {
+		flags := c.Flags()
+		flags.StringArrayVarP(&clientHeaders, "header", "H", nil, "header sent to an SSE server, as \"Name: value\"")
+		flags.DurationVar(&clientTimeout, "timeout", time.Minute, "time to connect and get the result")
+		flags.StringVarP(&clientOutput, "output", "o", "text", "output format: text or json")
+		flags.BoolVar(&clientServerLog, "server-log", false, "show the log output of the server on stderr")
+		rootCmd.AddCommand(c)
+	}
+	callCmd.Flags().StringArrayVarP(&callArgs, "arg", "a", nil, "tool argument as key=value, repeatable")
+	callCmd.Flags().StringVar(&callJSON, "json", "", "tool arguments as a JSON object, extended by --arg")
+	toolsCmd.Flags().BoolVarP(&toolsVerbose, "verbose", "v", false, "show the arguments of the tools")
+}
+
+// connectClient connects to the server named by the first argument, passing the
+// arguments after -- to it.
+func connectClient(ctx context.Context, cmd *cobra.Command, args []string) (toolclient.Client, error) {
+	if clientOutput != "text" && clientOutput != "json" {
+		return nil, fmt.Errorf("invalid output format %q; use text or json", clientOutput)
+	}
+	var serverArgs []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		serverArgs = args[dash:]
+	}
+	headers := make(map[string]string)
+	for _, h := range clientHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", h)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	mcpServers, err := clientMCPServers()
+	if err != nil {
+		return nil, err
+	}
+	target := toolclient.Target{
+		Server:     args[0],
+		Args:       serverArgs,
+		Headers:    headers,
+		MCPServers: mcpServers,
+	}
+	if clientServerLog {
+		target.Stderr = cmd.ErrOrStderr()
+	} else {
+		// Bundled servers run in process and log through the standard logger
+		stdlog.SetOutput(io.Discard)
+	}
+	return toolclient.Connect(ctx, target)
+}
+
+// clientMCPServers returns the servers of the chat config, if there is one.
+func clientMCPServers() (map[string]mcpconfig.Server, error) {
+	if configFile != "" {
+		return mcpconfig.Load(configFile)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil
+	}
+	path := filepath.Join(homeDir, ".mcp.json")
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return mcpconfig.Load(path)
+}
+
+func runCall(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 && dash < 2 {
+		return errors.New("the server and tool go before --")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+	client, err := connectClient(ctx, cmd, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	tool, err := toolclient.FindTool(tools.Tools, args[1])
+	if err != nil {
+		return err
+	}
+	toolArgs, err := toolclient.Arguments(tool, callJSON, callArgs)
+	if err != nil {
+		return err
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool.Name
+	req.Params.Arguments = toolArgs
+	result, err := client.CallTool(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if result.IsError {
+		out = cmd.ErrOrStderr()
+	}
+	if clientOutput == "json" {
+		err = writeJSON(out, result)
+	} else {
+		err = toolclient.WriteResult(out, result)
+	}
+	if err != nil {
+		return err
+	}
+	if result.IsError {
+		return fmt.Errorf("tool %s returned an error", tool.Name)
+	}
+	return nil
+}
+
+func runTools(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash == 0 {
+		return errors.New("the server goes before --")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+	client, err := connectClient(ctx, cmd, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	if clientOutput == "json" {
+		return writeJSON(cmd.OutOrStdout(), tools.Tools)
+	}
+	return toolclient.WriteTools(cmd.OutOrStdout(), tools.Tools, toolsVerbose)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
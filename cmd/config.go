@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/configcheck"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configKind    string
+	configOffline bool
+	configTimeout time.Duration
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check the config files of mcphost serve and mcphost proxy",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a config file and check that its upstreams can be reached",
+	Long: `Validate the config file of mcphost serve, or of mcphost proxy with --kind proxy,
+without starting its servers. Errors are reported with their line in the file.
+
+Unless --offline is given, the upstreams of the enabled servers are then checked:
+the APIs the bundled servers call, e.g. the Google API for googlesearch, at their
+default URL or the one set by the args or the environment, the SSE endpoints of
+proxied servers and the commands of external servers. Any HTTP response counts;
+requests go through the HTTP proxy of the environment, if any. References to
+secrets in args and env, such as vault:secret/data/mcphost#key, are resolved.
+
+Example:
+  mcphost config validate mcphost.yaml
+  mcphost config validate --kind proxy --offline mcphost-proxy.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigValidate(cmd, args)
+	},
+}
+
+var configExplainCmd = &cobra.Command{
+	Use:   "explain [file]",
+	Short: "Show the effective configuration of a config file",
+	Long: `Show the configuration mcphost serve, or mcphost proxy with --kind proxy, would
+run with: the config file with its defaults filled in and its secrets masked, then
+the flags of each bundled server set by its args in the file, its env in the file
+or the MCPHOST_<SERVER>_<FLAG> environment variables, in that order of precedence.
+
+Example:
+  mcphost config explain mcphost.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfigFile(args)
+		if err != nil {
+			return err
+		}
+		return config.Explain(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{configValidateCmd, configExplainCmd} {
+		c.Flags().StringVar(&configKind, "kind", configcheck.Serve, "kind of config file: serve or proxy")
+		configCmd.AddCommand(c)
+	}
+	configValidateCmd.Flags().BoolVar(&configOffline, "offline", false, "do not check that the upstreams can be reached")
+	configValidateCmd.Flags().DurationVar(&configTimeout, "timeout", 10*time.Second, "time to wait for each upstream")
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadConfigFile loads the config file given in args, or the default one of its kind.
+func loadConfigFile(args []string) (*configcheck.Config, error) {
+	path := configcheck.DefaultPath(configKind)
+	if len(args) > 0 {
+		path = args[0]
+	}
+	return configcheck.Load(configKind, path)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	config, err := loadConfigFile(args)
+	if err != nil {
+		return err
+	}
+	total, enabled := config.Servers()
+	cmd.Printf("%s: valid %s config with %d servers, %d enabled\n", config.Path, config.Kind, total, enabled)
+	if configOffline {
+		return nil
+	}
+
+	results := config.Check(context.Background(), configTimeout)
+	if len(results) == 0 {
+		return nil
+	}
+	cmd.Println()
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	failed := 0
+	for _, r := range results {
+		status, detail := "ok", r.Detail
+		if r.Err != nil {
+			status, detail = "FAILED", r.Err.Error()
+			failed++
+		}
+		if r.Proxy != "" {
+			detail += " (via proxy " + r.Proxy + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, r.Server, r.Target, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
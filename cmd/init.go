@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mark3labs/mcphost/internal/secretref"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/wizard"
+	"github.com/spf13/cobra"
+)
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init [file]",
+	Short: "Create the config file of mcphost serve interactively",
+	Long: `Ask which bundled servers to enable and the credentials of those calling APIs,
+then write a config file for mcphost serve, mcphost.yaml by default, and print the
+configuration to add to Claude Desktop or another MCP client.
+
+API keys, tokens and passwords are not written in the config file: they are stored
+in the keychain of the OS, the macOS keychain or the Secret Service through
+secret-tool, or in files readable by you only under ~/.config/mcphost/secrets, and
+the config refers to them, e.g. keychain:mcphost/googlesearch-api-key.
+
+Example:
+  mcphost init
+  mcphost init ~/mcphost/mcphost.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit(cmd, args)
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite the config file if it exists")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	path := "mcphost.yaml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if _, err := os.Stat(path); err == nil && !initForce {
+		return fmt.Errorf("%s exists; pass --force to overwrite it", path)
+	}
+
+	var names []string
+	options := make([]huh.Option[string], 0, len(servers.All))
+	for _, s := range servers.All {
+		option := huh.NewOption(s.Name+" - "+s.Description, s.Name)
+		options = append(options, option.Selected(s.Name == "fetch" || s.Name == "time"))
+	}
+	err := huh.NewForm(huh.NewGroup(
+		huh.NewMultiSelect[string]().
+			Title("Servers to enable").
+			Description("space to select, / to filter, enter to confirm").
+			Options(options...).
+			Height(15).
+			Value(&names).
+			Validate(func(names []string) error {
+				if len(names) == 0 {
+					return errors.New("select at least one server")
+				}
+				return nil
+			}),
+	)).Run()
+	if err != nil {
+		return err
+	}
+
+	storage, err := askStorage(names)
+	if err != nil {
+		return err
+	}
+
+	enabled := make([]wizard.Server, 0, len(names))
+	for _, name := range names {
+		s, err := askCredentials(name)
+		if err != nil {
+			return err
+		}
+		for _, c := range wizard.Credentials[name] {
+			if !c.Secret || s.Values[c.Flag] == "" {
+				continue
+			}
+			ref := wizard.SecretRef(storage, name, c.Flag)
+			if err := secretref.Store(ref, s.Values[c.Flag]); err != nil {
+				return err
+			}
+			s.Values[c.Flag] = ref
+		}
+		enabled = append(enabled, s)
+	}
+
+	config, err := wizard.Config(enabled)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, config, 0o644); err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	client, err := wizard.ClientConfig(executable, enabled)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Wrote %s. Run the servers with:\n  mcphost serve -config %s\n", path, path)
+	fmt.Fprintf(out, "or in the background with:\n  mcphost service install %s\n\n", path)
+	fmt.Fprintln(out, "SSE clients connect to:")
+	for _, e := range wizard.Endpoints(enabled) {
+		fmt.Fprintf(out, "  %s: %s\n", e.Server, e.URL)
+	}
+	fmt.Fprintln(out, "\nClaude Desktop starts the servers itself; add them to claude_desktop_config.json,")
+	fmt.Fprintln(out, `in ~/Library/Application Support/Claude on macOS or %APPDATA%\Claude on Windows:`)
+	fmt.Fprintln(out, string(client))
+	return nil
+}
+
+// askStorage asks where to store the secrets of the servers called names, if they
+// take any.
+func askStorage(names []string) (string, error) {
+	secrets := false
+	for _, name := range names {
+		for _, c := range wizard.Credentials[name] {
+			secrets = secrets || c.Secret
+		}
+	}
+	if !secrets {
+		return "", nil
+	}
+	storage := wizard.Files
+	options := []huh.Option[string]{huh.NewOption("Files readable by you only, in ~/.config/mcphost/secrets", wizard.Files)}
+	if secretref.KeychainAvailable() {
+		storage = wizard.Keychain
+		options = append([]huh.Option[string]{huh.NewOption("The keychain of the system", wizard.Keychain)}, options...)
+	}
+	err := huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Where to store API keys and tokens").
+			Options(options...).
+			Value(&storage),
+	)).Run()
+	return storage, err
+}
+
+// askCredentials asks for the credentials of the server called name.
+func askCredentials(name string) (wizard.Server, error) {
+	s := wizard.Server{Name: name, Values: make(map[string]string)}
+	credentials := wizard.Credentials[name]
+	if len(credentials) == 0 {
+		return s, nil
+	}
+	values := make([]string, len(credentials))
+	fields := make([]huh.Field, len(credentials))
+	for i, c := range credentials {
+		values[i] = c.Default
+		title := c.Title
+		if !c.Required {
+			title += " (optional)"
+		}
+		required := c.Required
+		fields[i] = huh.NewInput().
+			Title(title).
+			Password(c.Secret).
+			Value(&values[i]).
+			Validate(func(value string) error {
+				if required && value == "" {
+					return errors.New("required")
+				}
+				return nil
+			})
+	}
+	if err := huh.NewForm(huh.NewGroup(fields...).Title(name)).Run(); err != nil {
+		return s, err
+	}
+	for i, c := range credentials {
+		s.Values[c.Flag] = values[i]
+	}
+	return s, nil
+}
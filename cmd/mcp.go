@@ -17,6 +17,8 @@ import (
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/secretref"
 	"github.com/mark3labs/mcphost/pkg/history"
 	"github.com/mark3labs/mcphost/pkg/llm"
 )
@@ -60,13 +62,7 @@ var (
 )
 
 type MCPConfig struct {
-	MCPServers map[string]ServerConfig `json:"mcpServers"`
-}
-
-type ServerConfig struct {
-	Command string            `json:"command"`
-	Args    []string          `json:"args"`
-	Env     map[string]string `json:"env,omitempty"`
+	MCPServers map[string]mcpconfig.Server `json:"mcpServers"`
 }
 
 func mcpToolsToAnthropicTools(
@@ -92,6 +88,77 @@ func mcpToolsToAnthropicTools(
 	return anthropicTools
 }
 
+// callMCPTool runs a tool call of the model on the server its name is namespaced with
+// and returns the tool_result block answering it, for every provider. Calls that fail
+// are answered with their error, as the model APIs refuse conversations with
+// unanswered tool calls.
+func callMCPTool(
+	mcpClients map[string]*mcpclient.StdioMCPClient,
+	toolCall llm.ToolCall,
+) history.ContentBlock {
+	errorResult := func(errMsg string) history.ContentBlock {
+		fmt.Printf("\n%s\n", errorStyle.Render(errMsg))
+		return history.ContentBlock{
+			Type:      "tool_result",
+			ToolUseID: toolCall.GetID(),
+			Text:      errMsg,
+			IsError:   true,
+			Content: []history.ContentBlock{{
+				Type: "text",
+				Text: errMsg,
+			}},
+		}
+	}
+
+	serverName, toolName, ok := strings.Cut(toolCall.GetName(), "__")
+	if !ok {
+		return errorResult(fmt.Sprintf("Error: Invalid tool name format: %s", toolCall.GetName()))
+	}
+	mcpClient, ok := mcpClients[serverName]
+	if !ok {
+		return errorResult(fmt.Sprintf("Error: Server not found: %s", serverName))
+	}
+
+	var toolResult *mcp.CallToolResult
+	var err error
+	action := func() {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = toolName
+		req.Params.Arguments = toolCall.GetArguments()
+		toolResult, err = mcpClient.CallTool(context.Background(), req)
+	}
+	_ = spinner.New().
+		Title(fmt.Sprintf("Running tool %s...", toolName)).
+		Action(action).
+		Run()
+	if err != nil {
+		return errorResult(fmt.Sprintf("Error calling tool %s: %v", toolName, err))
+	}
+	log.Debug("raw tool result content", "content", toolResult.Content)
+
+	// Extract text content
+	var resultText string
+	for _, item := range toolResult.Content {
+		if textContent, ok := item.(mcp.TextContent); ok {
+			resultText += fmt.Sprintf("%v ", textContent.Text)
+		}
+	}
+	resultBlock := history.ContentBlock{
+		Type:      "tool_result",
+		ToolUseID: toolCall.GetID(),
+		Text:      strings.TrimSpace(resultText),
+		Content:   toolResult.Content,
+		IsError:   toolResult.IsError,
+	}
+	if toolResult.Content == nil {
+		resultBlock.Content = []mcp.Content{}
+	}
+	log.Debug("created tool result block",
+		"block", resultBlock,
+		"tool_id", toolCall.GetID())
+	return resultBlock
+}
+
 func loadMCPConfig() (*MCPConfig, error) {
 	var configPath string
 	if configFile != "" {
@@ -108,7 +175,7 @@ func loadMCPConfig() (*MCPConfig, error) {
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Create default config
 		defaultConfig := MCPConfig{
-			MCPServers: make(map[string]ServerConfig),
+			MCPServers: make(map[string]mcpconfig.Server),
 		}
 
 		// Create the file with default config
@@ -125,22 +192,11 @@ func loadMCPConfig() (*MCPConfig, error) {
 		return &defaultConfig, nil
 	}
 
-	// Read existing config
-	configData, err := os.ReadFile(configPath)
+	servers, err := mcpconfig.Load(configPath)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"error reading config file %s: %w",
-			configPath,
-			err,
-		)
+		return nil, err
 	}
-
-	var config MCPConfig
-	if err := json.Unmarshal(configData, &config); err != nil {
-		return nil, fmt.Errorf("error parsing config file: %w", err)
-	}
-
-	return &config, nil
+	return &MCPConfig{MCPServers: servers}, nil
 }
 
 func createMCPClients(
@@ -149,9 +205,12 @@ func createMCPClients(
 	clients := make(map[string]*mcpclient.StdioMCPClient)
 
 	for name, server := range config.MCPServers {
-		var env []string
-		for k, v := range server.Env {
-			env = append(env, fmt.Sprintf("%s=%s", k, v))
+		env, err := secretref.Environ(server.Env)
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, fmt.Errorf("server %s: %w", name, err)
 		}
 		client, err := mcpclient.NewStdioMCPClient(
 			server.Command,
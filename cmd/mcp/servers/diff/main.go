@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mark3labs/mcphost/internal/servers/diff"
+)
+
+func main() {
+	if err := diff.Run(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+}
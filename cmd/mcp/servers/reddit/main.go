@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mark3labs/mcphost/internal/servers/reddit"
+)
+
+func main() {
+	if err := reddit.Run(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+}
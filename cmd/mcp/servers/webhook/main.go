@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mark3labs/mcphost/internal/servers/webhook"
+)
+
+func main() {
+	if err := webhook.Run(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	stdlog "log"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/proxy"
+	"github.com/mark3labs/mcphost/internal/reload"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+// proxyConnectTimeout bounds connecting to and initializing the downstream servers.
+const proxyConnectTimeout = 30 * time.Second
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy -config <file>",
+	Short: "Expose the tools of several MCP servers through one MCP server",
+	Long: `Connect to the MCP servers declared in a YAML or JSON config file and expose
+all of their tools through a single MCP server, served over stdio or SSE. Each tool
+is prefixed with the name of its server, e.g. fetch__fetchURL, unless the config
+renames or hides it.
+
+Bundled servers run inside the proxy; external servers are started as stdio
+commands or reached through their SSE endpoint. Changes to the config file, or a
+SIGHUP, are applied without dropping the clients: servers added, changed or
+removed are connected or disconnected and clients are told the tools changed.
+
+Example config:
+  servers:
+    fetch:
+      args: ["-timeout", "10"]
+    clock:
+      server: time
+    sqlite:
+      command: uvx
+      args: ["mcp-server-sqlite", "--db-path", "/tmp/foo.db"]
+    remote:
+      url: http://tools.internal:8080/sse
+
+Example:
+  mcphost proxy -config proxy.yaml
+  mcphost proxy -config proxy.yaml -transport=sse -listen :8080`,
+	// proxy parses its flags like the bundled servers do
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProxy(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+}
+
+func runProxy(cmd *cobra.Command, args []string) error {
+	fs := flag.NewFlagSet("proxy", flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd.Println(cmd.Long)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "mcphost-proxy.yaml", "config file declaring the downstream servers")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+	if err := config.Parse(fs, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// The proxied bundled servers log through the standard logger
+	stdlog.SetPrefix("[ProxyServer] ")
+	stdlog.SetFlags(stdlog.Ldate | stdlog.Ltime)
+
+	config, err := proxy.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), proxyConnectTimeout)
+	defer cancel()
+	p, err := proxy.Open(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := middlewareFlags.Apply(context.Background(), "proxy", p.Server()); err != nil {
+		return err
+	}
+	defer middleware.Close(p.Server())
+
+	// Changes to the config connect and disconnect servers while clients stay connected
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	watched := []string{*configPath}
+	if config.MCPServersFile != "" {
+		if file, err := mcpconfig.ExpandHome(config.MCPServersFile); err == nil {
+			watched = append(watched, file)
+		}
+	}
+	reload.Watch(watchCtx, watched, func() {
+		config, err := proxy.LoadConfig(*configPath)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(watchCtx, proxyConnectTimeout)
+			err = p.Reload(ctx, config)
+			cancel()
+		}
+		if err != nil {
+			log.Error("Keeping the previous config", "error", err)
+			return
+		}
+		log.Info("Reloaded config", "tools", len(p.Tools()))
+	})
+
+	log.Info("Serving proxied tools", "tools", len(p.Tools()), "transport", transportFlags.Transport)
+	return transport.Serve(p.Server(), transportFlags)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/repl"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl <server> [-- server flags]",
+	Short: "Explore the tools of an MCP server in an interactive shell",
+	Long: `Connect to an MCP server and call its tools from an interactive shell, with tab
+completion of tool and argument names, prompts for missing required arguments and
+pretty-printed results. The server is a bundled server, a server of the mcpServers
+config or the URL of an SSE endpoint. Flags after -- are passed to the server.
+
+Example:
+  mcphost repl fetch
+  mcphost repl http://localhost:8080/sse -H "Authorization: Bearer $KEY"`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runREPL(cmd, args)
+	},
+}
+
+func init() {
+	flags := replCmd.Flags()
+	flags.StringArrayVarP(&clientHeaders, "header", "H", nil, "header sent to an SSE server, as \"Name: value\"")
+	flags.DurationVar(&clientTimeout, "timeout", time.Minute, "time to connect and to get the result of each call")
+	flags.BoolVar(&clientServerLog, "server-log", false, "show the log output of the server on stderr")
+	rootCmd.AddCommand(replCmd)
+}
+
+func runREPL(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	connectCtx, cancel := context.WithTimeout(ctx, clientTimeout)
+	defer cancel()
+	client, err := connectClient(connectCtx, cmd, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	tools, err := client.ListTools(connectCtx, mcp.ListToolsRequest{})
+	if err != nil {
+		return err
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// Read commands from a pipe or file, without prompts
+		shell := repl.New(client, tools.Tools, &scannerReader{bufio.NewScanner(os.Stdin)}, cmd.OutOrStdout(), clientTimeout)
+		return shell.Run(ctx)
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		terminal.SetSize(width, height)
+	}
+	// Log output would garble the shell
+	if clientServerLog {
+		stdlog.SetOutput(redact.Writer(terminal))
+	}
+	shell := repl.New(client, tools.Tools, terminal, terminal, clientTimeout)
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return shell.Complete(line, pos)
+	}
+	return shell.Run(ctx)
+}
+
+// scannerReader reads the lines of a non-interactive input.
+type scannerReader struct {
+	*bufio.Scanner
+}
+
+func (r *scannerReader) ReadLine() (string, error) {
+	if !r.Scan() {
+		if err := r.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.Text(), nil
+}
+
+func (r *scannerReader) SetPrompt(string) {}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	stdlog "log"
 	"os"
 	"strings"
 	"time"
@@ -11,11 +12,15 @@ import (
 	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 
 	"github.com/charmbracelet/glamour"
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/buildinfo"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/redact"
 	"github.com/mark3labs/mcphost/pkg/history"
 	"github.com/mark3labs/mcphost/pkg/llm"
 	"github.com/mark3labs/mcphost/pkg/llm/anthropic"
@@ -54,15 +59,34 @@ Available models can be specified using the --model flag:
 - OpenAI: openai:gpt-4
 - Ollama models: ollama:modelname
 
+Every flag can also be set through an MCPHOST_* environment variable, e.g.
+MCPHOST_MODEL for --model or MCPHOST_OPENAI_API_KEY for --openai-api-key. The
+flags of the servers are read from MCPHOST_<SERVER>_<FLAG>, e.g.
+MCPHOST_FETCH_TIMEOUT.
+
 Example:
   mcphost -m ollama:qwen2.5:3b
   mcphost -m openai:gpt-4`,
+	Version: buildinfo.Get().Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.BindEnv(cmd.Flags()); err != nil {
+			return err
+		}
+		if checkUpdates && cmd != updateCmd {
+			go checkForUpdate()
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runMCPHost()
 	},
 }
 
 func Execute() {
+	// Secrets are masked in the log, which keeps the colors of the terminal
+	log.SetOutput(redact.Writer(os.Stderr))
+	log.SetColorProfile(lipgloss.NewRenderer(os.Stderr).ColorProfile())
+	stdlog.SetOutput(redact.Writer(os.Stderr))
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -254,9 +278,10 @@ func runPrompt(
 
 	for {
 		action := func() {
+			// The prompt is already the last of the messages
 			message, err = provider.CreateMessage(
 				context.Background(),
-				prompt,
+				"",
 				llmMessages,
 				tools,
 			)
@@ -340,91 +365,7 @@ func runPrompt(
 				"total_tokens", inputTokens+outputTokens)
 		}
 
-		parts := strings.Split(toolCall.GetName(), "__")
-		if len(parts) != 2 {
-			fmt.Printf(
-				"Error: Invalid tool name format: %s\n",
-				toolCall.GetName(),
-			)
-			continue
-		}
-
-		serverName, toolName := parts[0], parts[1]
-		mcpClient, ok := mcpClients[serverName]
-		if !ok {
-			fmt.Printf("Error: Server not found: %s\n", serverName)
-			continue
-		}
-
-		var toolArgs map[string]interface{}
-		if err := json.Unmarshal(input, &toolArgs); err != nil {
-			fmt.Printf("Error parsing tool arguments: %v\n", err)
-			continue
-		}
-
-		var toolResultPtr *mcp.CallToolResult
-		action := func() {
-			req := mcp.CallToolRequest{}
-			req.Params.Name = toolName
-			req.Params.Arguments = toolArgs
-			toolResultPtr, err = mcpClient.CallTool(
-				context.Background(),
-				req,
-			)
-		}
-		_ = spinner.New().
-			Title(fmt.Sprintf("Running tool %s...", toolName)).
-			Action(action).
-			Run()
-
-		if err != nil {
-			errMsg := fmt.Sprintf(
-				"Error calling tool %s: %v",
-				toolName,
-				err,
-			)
-			fmt.Printf("\n%s\n", errorStyle.Render(errMsg))
-
-			// Add error message as tool result
-			toolResults = append(toolResults, history.ContentBlock{
-				Type:      "tool_result",
-				ToolUseID: toolCall.GetID(),
-				Content: []history.ContentBlock{{
-					Type: "text",
-					Text: errMsg,
-				}},
-			})
-			continue
-		}
-
-		toolResult := *toolResultPtr
-
-		if toolResult.Content != nil {
-			log.Debug("raw tool result content", "content", toolResult.Content)
-
-			// Create the tool result block
-			resultBlock := history.ContentBlock{
-				Type:      "tool_result",
-				ToolUseID: toolCall.GetID(),
-				Content:   toolResult.Content,
-			}
-
-			// Extract text content
-			var resultText string
-			// Handle mcp.Content items
-			for _, item := range toolResult.Content {
-				if textContent, ok := item.(mcp.TextContent); ok {
-					resultText += fmt.Sprintf("%v ", textContent.Text)
-				}
-			}
-
-			resultBlock.Text = strings.TrimSpace(resultText)
-			log.Debug("created tool result block",
-				"block", resultBlock,
-				"tool_id", toolCall.GetID())
-
-			toolResults = append(toolResults, resultBlock)
-		}
+		toolResults = append(toolResults, callMCPTool(mcpClients, toolCall))
 	}
 
 	*messages = append(*messages, history.HistoryMessage{
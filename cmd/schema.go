@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/toolschema"
+	"github.com/spf13/cobra"
+)
+
+var schemaFormat string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [server]...",
+	Short: "Print the JSON Schemas of the tools of the servers",
+	Long: `Print the tools of the bundled and registered servers, or of the servers named,
+with their descriptions and the JSON Schemas of their arguments, including
+defaults, for generating typed clients and documentation. Servers are created
+with their default flags; servers that cannot be are reported with an error.
+
+With --format openapi, the tools are printed as an OpenAPI 3.1 document with
+an operation per tool, POST /<server>/<tool>, taking the tool's arguments.
+
+Example:
+  mcphost schema fetch time
+  mcphost schema --format openapi > tools.openapi.json`,
+	SilenceUsage: true,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, s := range servers.List() {
+			names = append(names, s.Name+"\t"+s.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if schemaFormat != "json" && schemaFormat != "openapi" {
+			return fmt.Errorf("invalid format %q: use json or openapi", schemaFormat)
+		}
+		list := servers.List()
+		if len(args) > 0 {
+			list = nil
+			for _, name := range args {
+				s, ok := servers.Lookup(name)
+				if !ok {
+					return fmt.Errorf("unknown server %q, see mcphost list", name)
+				}
+				list = append(list, s)
+			}
+		}
+
+		// Creating the servers logs through the standard logger
+		stdlog.SetOutput(io.Discard)
+		doc := toolschema.Export(list)
+		stdlog.SetOutput(redact.Writer(os.Stderr))
+
+		var out interface{} = doc
+		if schemaFormat == "openapi" {
+			out = toolschema.OpenAPI(doc)
+		}
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().StringVar(&schemaFormat, "format", "json", "output format: json or openapi")
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/compliance"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/spf13/cobra"
+)
+
+var selftestTimeout time.Duration
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest [server]... [-- server flags]",
+	Short: "Check that the servers follow the MCP protocol",
+	Long: `Drive the bundled and registered servers, or the servers named, through the
+MCP protocol as a client would, in process over the stdio transport: the
+initialize handshake, ping, an unknown method, tools/list, tool calls with
+valid and with missing arguments, cancellation, and resources and prompts
+when the server has them. Every response is checked against the protocol.
+
+Tool calls with valid arguments are limited to getServerInfo and to calls
+that need neither the network nor change anything. Servers are created with
+their default flags, or with the flags after -- when a single server is
+named; servers that cannot start with their default flags, e.g. for lack of
+a display, are skipped. The exit status is 1 when a check fails.
+
+Example:
+  mcphost selftest
+  mcphost selftest clipboard -- -allow-read`,
+	SilenceUsage: true,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, s := range servers.List() {
+			names = append(names, s.Name+"\t"+s.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runSelftest,
+}
+
+func init() {
+	selftestCmd.Flags().DurationVar(&selftestTimeout, "timeout", 10*time.Second, "time to wait for each response")
+	rootCmd.AddCommand(selftestCmd)
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	var serverArgs []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		args, serverArgs = args[:dash], args[dash:]
+		if len(args) != 1 {
+			return errors.New("server flags after -- need a single server")
+		}
+	}
+	list := servers.List()
+	if len(args) > 0 {
+		list = nil
+		for _, name := range args {
+			s, ok := servers.Lookup(name)
+			if !ok {
+				return fmt.Errorf("unknown server %q, see mcphost list", name)
+			}
+			list = append(list, s)
+		}
+	}
+
+	// The servers log through the standard logger
+	stdlog.SetOutput(io.Discard)
+	var results []compliance.Result
+	for _, s := range list {
+		results = append(results, compliance.CheckServer(context.Background(), s, serverArgs, selftestTimeout)...)
+	}
+	stdlog.SetOutput(redact.Writer(os.Stderr))
+
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	failed, skipped := 0, 0
+	for _, r := range results {
+		status, detail := "ok", r.Detail
+		switch {
+		case r.Err != nil:
+			status, detail = "FAILED", r.Err.Error()
+			failed++
+		case r.Skipped:
+			status = "skipped"
+			skipped++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, r.Server, r.Check, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	cmd.Printf("\n%d checks of %d servers: %d failed, %d skipped\n", len(results), len(list), failed, skipped)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
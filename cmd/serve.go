@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	stdlog "log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/configcheck"
+	"github.com/mark3labs/mcphost/internal/logfile"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/service"
+	"github.com/mark3labs/mcphost/internal/supervisor"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve -config <file>",
+	Short: "Run and supervise the MCP servers declared in a config file",
+	Long: `Run the MCP servers declared in a YAML or JSON config file, mcphost.yaml if it
+exists, else ~/.mcphost/config.yaml, restarting them with backoff when they crash.
+Bundled servers serve SSE, or WebSocket with transport: websocket, on the address set
+by their listen key, and any server runs in the working directory set by its dir key.
+Their status is logged and, when the config sets a status address, served as JSON
+over HTTP, or HTTPS with the TLS flags.
+
+Example config:
+  status: 127.0.0.1:8090
+  backoff:
+    initial: 1s
+    max: 30s
+  servers:
+    fetch:
+      listen: 127.0.0.1:8081
+      args: ["-timeout", "10"]
+    clock:
+      server: time
+      listen: 127.0.0.1:8082
+      transport: websocket
+    custom:
+      command: /usr/local/bin/my-server
+      dir: ~/servers/custom
+      env:
+        API_KEY: secret
+      restart: always
+
+Example:
+  mcphost serve -config mcphost.yaml`,
+	// serve parses its flags like the bundled servers do
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd.Println(cmd.Long)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "config file declaring the servers to run (default: mcphost.yaml, else ~/.mcphost/config.yaml)")
+	dir := fs.String("dir", "", "directory to run in, resolving relative paths in the config")
+	logFile := fs.String("log-file", "", "file to append the log and the output of the servers to, instead of stderr")
+	var logRotation logfile.Rotation
+	logRotation.Register(fs, "log", "log")
+	var tlsFlags transport.Flags
+	tlsFlags.RegisterTLS(fs)
+	if err := config.Parse(fs, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			return err
+		}
+	}
+	if *configPath == "" {
+		*configPath = configcheck.DefaultPath(configcheck.Serve)
+	}
+	// Secrets are also masked in the output of the servers
+	stderr := redact.Writer(os.Stderr)
+	if *logFile != "" {
+		f, err := logfile.Open(*logFile, logRotation)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		stderr = redact.Writer(f)
+		log.SetOutput(stderr)
+		stdlog.SetOutput(stderr)
+	}
+
+	config, err := supervisor.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := tlsFlags.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil && config.Status == "" {
+		return errors.New("the TLS flags secure the status endpoint; set status in the config")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	sup := supervisor.New(config, executable)
+	sup.Stderr = stderr
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if config.Status != "" {
+		ln, err := net.Listen("tcp", config.Status)
+		if err != nil {
+			return err
+		}
+		if tlsConfig != nil {
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+		statusServer := &http.Server{Handler: sup}
+		go func() {
+			if err := statusServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("Status endpoint failed", "error", err)
+			}
+		}()
+		defer statusServer.Close()
+		log.Info("Serving status", "address", ln.Addr(), "tls", tlsConfig != nil)
+	}
+
+	log.Info("Starting servers", "config", *configPath, "count", len(config.Servers))
+	// Installed as a Windows service, serve is stopped by the service manager
+	return service.Run(ctx, service.DefaultName, sup.Run)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/proxy"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <server> [flags]",
+	Short: "Run a bundled MCP server",
+	Long: `Run one of the MCP servers bundled with mcphost, serving it over stdio.
+Flags after the server name are passed to the server. Every server accepts
+-transport=sse -listen <addr> to serve remote clients over HTTP instead.
+
+Flags not given on the command line are read from MCPHOST_<SERVER>_<FLAG>
+environment variables, e.g. MCPHOST_FETCH_TIMEOUT=10.
+
+With --server, several servers are run in process and served as one, exposing
+the tools of all of them. The remaining flags are the transport and middleware
+flags; the servers take their own flags from the environment.
+
+Example:
+  mcphost run fetch -timeout 10
+  mcphost run time -transport=sse -listen :8080
+  mcphost run --server=fetch,time,googlesearch`,
+	// The server parses its own flags
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		var names []string
+		for _, s := range servers.List() {
+			names = append(names, s.Name+"\t"+s.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || isHelp(args[0]) {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+			cmd.Println("\nServers:")
+			return printTools(cmd.OutOrStdout(), servers.List())
+		}
+		if names, rest, ok := serverFlag(args); ok {
+			return runServers(cmd, names, rest)
+		}
+		s, ok := servers.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown server %q, see mcphost list", args[0])
+		}
+		for _, arg := range args[1:] {
+			if isHelp(arg) {
+				if err := s.Run(args[1:]); err != nil {
+					return err
+				}
+				fmt.Fprintln(os.Stderr, "\nTools:")
+				return printTools(os.Stderr, []servers.Server{s})
+			}
+		}
+		return s.Run(args[1:])
+	},
+}
+
+// serverFlag returns the servers selected by a leading --server flag and the
+// arguments after it.
+func serverFlag(args []string) ([]string, []string, bool) {
+	arg := strings.TrimPrefix(strings.TrimPrefix(args[0], "-"), "-")
+	switch {
+	case strings.HasPrefix(arg, "server="):
+		return strings.Split(strings.TrimPrefix(arg, "server="), ","), args[1:], true
+	case arg == "server" && len(args) > 1:
+		return strings.Split(args[1], ","), args[2:], true
+	}
+	return nil, nil, false
+}
+
+// runServers serves the bundled servers named as one server, through an in-process
+// proxy exposing their tools unprefixed.
+func runServers(cmd *cobra.Command, names []string, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd.Println(cmd.Long)
+		fs.PrintDefaults()
+	}
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+	if err := config.Parse(fs, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q; the servers take their flags from MCPHOST_<SERVER>_<FLAG> variables", fs.Arg(0))
+	}
+
+	// The bundled servers log through the standard logger
+	stdlog.SetPrefix("[Servers] ")
+	stdlog.SetFlags(stdlog.Ldate | stdlog.Ltime)
+
+	config, err := proxy.BundledConfig(names)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), proxyConnectTimeout)
+	defer cancel()
+	p, err := proxy.Open(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if err := middlewareFlags.Apply(context.Background(), "servers", p.Server()); err != nil {
+		return err
+	}
+	defer middleware.Close(p.Server())
+
+	log.Info("Serving servers", "servers", strings.Join(names, ","), "tools", len(p.Tools()), "transport", transportFlags.Transport)
+	return transport.Serve(p.Server(), transportFlags)
+}
+
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// printTools writes the tools of each server, as created with its default flags.
+func printTools(w io.Writer, list []servers.Server) error {
+	// Creating the servers logs through the standard logger
+	stdlog.SetOutput(io.Discard)
+	defer stdlog.SetOutput(redact.Writer(os.Stderr))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range list {
+		if len(list) > 1 {
+			fmt.Fprintf(tw, "%s\t%s\n", s.Name, s.Description)
+		}
+		tools, err := servers.Tools(s)
+		if err != nil {
+			fmt.Fprintf(tw, "  (tools unavailable: %v)\n", err)
+			continue
+		}
+		for _, tool := range tools {
+			fmt.Fprintf(tw, "  %s\t%s\n", tool.Name, firstLine(tool.Description))
+		}
+	}
+	return tw.Flush()
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the bundled and registered MCP servers",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		for _, s := range servers.List() {
+			fmt.Fprintf(w, "%s\t%s\n", s.Name, s.Description)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd, listCmd)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcphost/internal/configcheck"
+	"github.com/mark3labs/mcphost/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceName    string
+	serviceSystem  bool
+	serviceEnv     []string
+	serviceLogFile string
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run mcphost serve in the background as a system service",
+	Long: `Install mcphost serve as a systemd unit on Linux, a launchd agent on macOS or
+a Windows service, so that the servers of a config file keep running in the
+background, start at login, or at boot with --system, and restart when they fail.
+
+Example:
+  mcphost service install mcphost.yaml --env GOOGLE_API_KEY
+  mcphost service start
+  mcphost service stop
+  mcphost service uninstall`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [file]",
+	Short: "Install mcphost serve with a config file as a service",
+	Long: `Install mcphost serve with a config file, by default mcphost.yaml if it exists,
+else ~/.mcphost/config.yaml, as a service. It runs in the directory of the config file with the PATH of the current
+shell and the variables given with --env: NAME=value, or NAME to copy the value of
+the current environment, e.g. an API key. The service definition is readable by
+its owner only.
+
+The service is installed for the current user, started at login, unless --system
+installs it for all users, started at boot, which needs root. Windows services are
+always system ones and need an administrator.
+
+The log and the output of the servers go to the journal with systemd, and to
+~/Library/Logs/<name>.log with launchd or %ProgramData%\mcphost\<name>.log on
+Windows, unless --log-file sets another file.
+
+Example:
+  mcphost service install mcphost.yaml --env GOOGLE_API_KEY --env LOG_LEVEL=debug
+  sudo mcphost service install --system /etc/mcphost/mcphost.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := serviceConfig(args)
+		if err != nil {
+			return err
+		}
+		if err := service.Install(c); err != nil {
+			return err
+		}
+		cmd.Printf("Installed service %s, %s\nStart it with: mcphost service start%s\n", c.Name, service.Describe(c), serviceFlags())
+		return nil
+	},
+}
+
+var serviceStartCmd = &cobra.Command{
+	Use:          "start",
+	Short:        "Start the installed service",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return service.Start(service.Config{Name: serviceName, System: serviceSystem})
+	},
+}
+
+var serviceStopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "Stop the running service",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return service.Stop(service.Config{Name: serviceName, System: serviceSystem})
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:          "uninstall",
+	Short:        "Stop and remove the installed service",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := service.Uninstall(service.Config{Name: serviceName, System: serviceSystem}); err != nil {
+			return err
+		}
+		cmd.Printf("Removed service %s\n", serviceName)
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{serviceInstallCmd, serviceStartCmd, serviceStopCmd, serviceUninstallCmd} {
+		c.Flags().StringVar(&serviceName, "name", service.DefaultName, "name of the service")
+		c.Flags().BoolVar(&serviceSystem, "system", false, "service of the system, started at boot, rather than of the current user")
+		serviceCmd.AddCommand(c)
+	}
+	serviceInstallCmd.Flags().StringArrayVarP(&serviceEnv, "env", "e", nil, "environment variable of the service as NAME=value, or NAME to copy it, repeatable")
+	serviceInstallCmd.Flags().StringVar(&serviceLogFile, "log-file", "", "file to write the log to (default depends on the system)")
+	rootCmd.AddCommand(serviceCmd)
+}
+
+// serviceConfig returns the service running mcphost serve with the config file given
+// in args.
+func serviceConfig(args []string) (service.Config, error) {
+	configPath := configcheck.DefaultPath(configcheck.Serve)
+	if len(args) > 0 {
+		configPath = args[0]
+	}
+	configPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return service.Config{}, err
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return service.Config{}, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return service.Config{}, err
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return service.Config{}, err
+	}
+
+	env := map[string]string{"PATH": os.Getenv("PATH")}
+	for _, item := range serviceEnv {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			if value, ok = os.LookupEnv(name); !ok {
+				return service.Config{}, fmt.Errorf("environment variable %s is not set", name)
+			}
+		}
+		if name == "" {
+			return service.Config{}, fmt.Errorf("invalid environment variable %q: expected NAME=value or NAME", item)
+		}
+		env[name] = value
+	}
+
+	c := service.Config{
+		Name:        serviceName,
+		Description: "MCP servers of " + configPath,
+		Executable:  executable,
+		Args:        []string{"serve", "-config", configPath},
+		WorkingDir:  filepath.Dir(configPath),
+		Env:         env,
+		LogFile:     serviceLogFile,
+		System:      serviceSystem,
+	}
+	if c.LogFile == "" {
+		c.LogFile = service.DefaultLogFile(c)
+	} else if c.LogFile, err = filepath.Abs(c.LogFile); err != nil {
+		return service.Config{}, err
+	}
+	return c, nil
+}
+
+// serviceFlags returns the flags selecting the installed service in the other
+// service commands.
+func serviceFlags() string {
+	var flags string
+	if serviceName != service.DefaultName {
+		flags += " --name " + serviceName
+	}
+	if serviceSystem {
+		flags += " --system"
+	}
+	return flags
+}
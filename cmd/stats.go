@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/toolclient"
+	"github.com/mark3labs/mcphost/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats <url|file> [-- server flags]",
+	Short: "Show how often each tool of a server was called",
+	Long: `Show the calls, error rate and latency of each tool of a server started with
+-usage-stats, from its getUsageStats tool at the URL of its SSE endpoint, or from
+the file a server started with -usage-file saves them in.
+
+Example:
+  mcphost stats http://localhost:8081/sse
+  mcphost stats /var/lib/mcphost/fetch-usage.json --output json`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStats(cmd, args)
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringArrayVarP(&clientHeaders, "header", "H", nil, "header sent to an SSE server, as \"Name: value\"")
+	statsCmd.Flags().DurationVar(&clientTimeout, "timeout", time.Minute, "time to connect and get the statistics")
+	statsCmd.Flags().StringVarP(&clientOutput, "output", "o", "text", "output format: text or json")
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	report, err := readStats(cmd, args)
+	if err != nil {
+		return err
+	}
+	if clientOutput == "json" {
+		return writeJSON(cmd.OutOrStdout(), report)
+	}
+	return usage.WriteTable(cmd.OutOrStdout(), report)
+}
+
+// readStats reads the usage statistics of the server or file named by args.
+func readStats(cmd *cobra.Command, args []string) (usage.Report, error) {
+	if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+		return usage.ReadFile(args[0])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+	client, err := connectClient(ctx, cmd, args)
+	if err != nil {
+		return usage.Report{}, err
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return usage.Report{}, fmt.Errorf("failed to list tools: %w", err)
+	}
+	if _, err := toolclient.FindTool(tools.Tools, usage.ToolName); err != nil {
+		return usage.Report{}, fmt.Errorf("%s does not count its tool calls; start it with -usage-stats", args[0])
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Name = usage.ToolName
+	result, err := client.CallTool(ctx, req)
+	if err != nil {
+		return usage.Report{}, err
+	}
+	var report usage.Report
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok && !result.IsError {
+			return report, json.Unmarshal([]byte(text.Text), &report)
+		}
+	}
+	return report, errors.New("getUsageStats returned no statistics")
+}
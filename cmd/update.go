@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mark3labs/mcphost/internal/buildinfo"
+	"github.com/mark3labs/mcphost/internal/update"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateCheckOnly bool
+	updateForce     bool
+	checkUpdates    bool
+)
+
+// updateCheckTimeout bounds the lookup of the startup update check.
+const updateCheckTimeout = 5 * time.Second
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update mcphost to the latest release",
+	Long: `Download the latest release of mcphost from GitHub for this system, verify it
+against the SHA-256 sums published with it, and the signature of the sums in
+signed builds, then replace the running binary with it. Binaries installed with
+go install or a package manager are better updated the same way.
+
+With --check-updates, or MCPHOST_CHECK_UPDATES=true, every command checks for a
+new release once a day in the background and logs a notice when there is one.
+
+Example:
+  mcphost update --check
+  sudo mcphost update`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUpdate(cmd)
+	},
+}
+
+func init() {
+	updateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "only report whether a newer release exists")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "install the latest release even if it is not newer, e.g. over a development build")
+	rootCmd.PersistentFlags().BoolVar(&checkUpdates, "check-updates", false, "check for a new release once a day and log a notice")
+	rootCmd.AddCommand(updateCmd)
+}
+
+func runUpdate(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	client := update.NewClient()
+	current := buildinfo.Get().Version
+	release, err := client.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	newer := update.Newer(release.Version, current)
+	if updateCheckOnly {
+		if newer {
+			cmd.Printf("mcphost %s is available, this is %s: %s\n", release.Version, current, release.URL)
+		} else {
+			cmd.Printf("mcphost %s is the latest release, this is %s\n", release.Version, current)
+		}
+		return nil
+	}
+	if !newer && !updateForce {
+		if release.Version == current {
+			cmd.Printf("mcphost %s is up to date\n", current)
+			return nil
+		}
+		return fmt.Errorf("the latest release %s is not newer than %s; pass --force to install it anyway", release.Version, current)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return err
+	}
+	cmd.Printf("Downloading mcphost %s...\n", release.Version)
+	binary, err := client.Binary(ctx, release)
+	if err != nil {
+		return err
+	}
+	if err := update.Replace(executable, binary); err != nil {
+		return err
+	}
+	cmd.Printf("Updated %s from %s to %s\n", executable, current, release.Version)
+	return nil
+}
+
+// checkForUpdate logs a notice when a newer release exists, looking it up at most once
+// a day. Failures are logged at debug level only.
+func checkForUpdate() {
+	stamp, err := update.StampFile()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+	current := buildinfo.Get().Version
+	release, err := update.NewClient().Check(ctx, current, stamp)
+	switch {
+	case err != nil && !errors.Is(err, context.Canceled):
+		log.Debug("Update check failed", "error", err)
+	case release != nil:
+		log.Info("A new release of mcphost is available; run mcphost update", "version", release.Version, "current", current)
+	}
+}
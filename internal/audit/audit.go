@@ -0,0 +1,152 @@
+// Package audit records tool calls as JSON lines in a rotated file or in syslog.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/logfile"
+)
+
+// Outcomes of a tool call.
+const (
+	Success   = "success"   // the tool returned a result
+	ToolError = "toolError" // the tool returned a result flagged as an error
+	Failure   = "error"     // the call failed without a result
+)
+
+// Entry is the audit record of one tool call.
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Server     string                 `json:"server"`
+	Tool       string                 `json:"tool"`
+	Session    string                 `json:"session,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DurationMs float64                `json:"durationMs"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Options configure where audit entries go and how they are redacted.
+type Options struct {
+	// Destination is a file path, "syslog" for the local syslog daemon, or
+	// syslog://host:port or syslog+tcp://host:port for a remote one.
+	Destination string
+	// Rotation configures when a file is rotated and which rotated files are kept.
+	Rotation logfile.Rotation
+	// RedactKeys are argument names masked in addition to the built-in ones.
+	RedactKeys []string
+}
+
+// Logger writes audit entries.
+type Logger struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	redact *Redactor
+}
+
+// Open creates a Logger writing to the destination in opts.
+func Open(opts Options) (*Logger, error) {
+	dest := opts.Destination
+	if dest == "" {
+		return nil, errors.New("audit: no destination")
+	}
+	var w io.WriteCloser
+	if dest == "syslog" || strings.HasPrefix(dest, "syslog://") || strings.HasPrefix(dest, "syslog+tcp://") {
+		var err error
+		if w, err = dialSyslog(dest); err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+	} else {
+		var err error
+		if w, err = logfile.Open(dest, opts.Rotation); err != nil {
+			return nil, fmt.Errorf("audit: %w", err)
+		}
+	}
+	return NewLogger(w, NewRedactor(opts.RedactKeys...)), nil
+}
+
+// NewLogger creates a Logger writing one JSON line per entry to w, redacting the
+// arguments with r.
+func NewLogger(w io.WriteCloser, r *Redactor) *Logger {
+	return &Logger{w: w, redact: r}
+}
+
+// Log writes e, redacting its arguments.
+func (l *Logger) Log(e Entry) error {
+	e.Arguments = l.redact.Redact(e.Arguments)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(line)
+	return err
+}
+
+// Close closes the destination of l.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
+
+// Middleware returns tool middleware recording the calls to the tools of the server
+// called serverName. Failing to write an entry does not fail the call.
+func (l *Logger) Middleware(serverName string) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+
+			e := Entry{
+				Time:       start.UTC(),
+				Server:     serverName,
+				Tool:       req.Params.Name,
+				Arguments:  req.Params.Arguments,
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+				Outcome:    Success,
+			}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				e.Session = session.SessionID()
+			}
+			switch {
+			case err != nil:
+				e.Outcome = Failure
+				e.Error = err.Error()
+			case result != nil && result.IsError:
+				e.Outcome = ToolError
+				e.Error = resultText(result)
+			}
+			if logErr := l.Log(e); logErr != nil {
+				log.Printf("Warning: Failed to write audit entry for %s: %v", e.Tool, logErr)
+			}
+			return result, err
+		}
+	}
+}
+
+// resultText returns the text content of result, which for error results describes
+// the error.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return truncate(strings.Join(parts, "\n"))
+}
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/logfile"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (*nopCloser) Close() error { return nil }
+
+// Test that secret arguments are masked at any depth
+func TestRedact(t *testing.T) {
+	r := NewRedactor("chatId", " ")
+	redacted := r.Redact(map[string]interface{}{
+		"url":     "https://example.com",
+		"Api_Key": "k",
+		"headers": map[string]interface{}{"Authorization": "Bearer x", "Accept": "text/html"},
+		"items":   []interface{}{map[string]interface{}{"password": "p", "name": "n"}, 3.0},
+		"chatId":  "42",
+		"body":    strings.Repeat("é", maxValueLength),
+	})
+	assert.Equal(t, "https://example.com", redacted["url"])
+	assert.Equal(t, Redacted, redacted["Api_Key"])
+	assert.Equal(t, map[string]interface{}{"Authorization": Redacted, "Accept": "text/html"}, redacted["headers"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"password": Redacted, "name": "n"}, 3.0}, redacted["items"])
+	assert.Equal(t, Redacted, redacted["chatId"])
+	body := redacted["body"].(string)
+	assert.Len(t, body, maxValueLength+len("…"))
+	assert.True(t, strings.HasSuffix(body, "é…"))
+
+	assert.Nil(t, r.Redact(nil))
+}
+
+// Test the entries recorded by the middleware
+func TestMiddleware(t *testing.T) {
+	var out nopCloser
+	logger := NewLogger(&out, NewRedactor())
+	audited := logger.Middleware("fetch")
+
+	handlers := []func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		},
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("not found"), nil
+		},
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	for _, handler := range handlers {
+		_, _ = audited(handler)(context.Background(), mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": "https://example.com", "token": "t"}))
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	var entries []Entry
+	for _, line := range lines {
+		var e Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	assert.Equal(t, "fetch", entries[0].Server)
+	assert.Equal(t, "fetchURL", entries[0].Tool)
+	assert.Equal(t, map[string]interface{}{"url": "https://example.com", "token": Redacted}, entries[0].Arguments)
+	assert.Equal(t, Success, entries[0].Outcome)
+	assert.Empty(t, entries[0].Error)
+	assert.Equal(t, ToolError, entries[1].Outcome)
+	assert.Equal(t, "not found", entries[1].Error)
+	assert.Equal(t, Failure, entries[2].Outcome)
+	assert.Equal(t, "boom", entries[2].Error)
+}
+
+// Test that Open requires a destination and writes files
+func TestOpen(t *testing.T) {
+	_, err := Open(Options{})
+	assert.Error(t, err)
+
+	path := t.TempDir() + "/audit.jsonl"
+	logger, err := Open(Options{Destination: path, Rotation: logfile.Rotation{MaxSizeMB: 1}})
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(Entry{Server: "time", Tool: "getTime", Outcome: Success}))
+	require.NoError(t, logger.Close())
+}
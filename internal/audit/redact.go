@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcphost/internal/redact"
+)
+
+// Redacted replaces the values of secret arguments.
+const Redacted = redact.Mask
+
+// maxValueLength bounds the string values kept in an entry.
+const maxValueLength = 1024
+
+// Redactor masks the values of secret arguments.
+type Redactor struct {
+	keys []string
+}
+
+// NewRedactor creates a Redactor masking the arguments named like secrets, see
+// redact.SecretName, or whose name contains one of keys, ignoring case. The secrets
+// registered with package redact are masked in the other values.
+func NewRedactor(keys ...string) *Redactor {
+	r := &Redactor{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			r.keys = append(r.keys, strings.ToLower(key))
+		}
+	}
+	return r
+}
+
+// Redact returns a copy of args with secret values masked, including those in nested
+// objects, and long strings truncated.
+func (r *Redactor) Redact(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if r.secret(key) {
+			redacted[key] = Redacted
+			continue
+		}
+		redacted[key] = r.value(value)
+	}
+	return redacted
+}
+
+func (r *Redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.Redact(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = r.value(item)
+		}
+		return values
+	case string:
+		return truncate(redact.Secrets(v))
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) secret(key string) bool {
+	if redact.SecretName(key) {
+		return true
+	}
+	key = strings.ToLower(key)
+	for _, fragment := range r.keys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate shortens s to maxValueLength bytes, keeping it valid UTF-8.
+func truncate(s string) string {
+	if len(s) <= maxValueLength {
+		return s
+	}
+	cut := maxValueLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,26 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"io"
+	"log/syslog"
+	"net/url"
+	"strings"
+)
+
+// dialSyslog connects to the syslog daemon named by dest, "syslog" for the local one.
+func dialSyslog(dest string) (io.WriteCloser, error) {
+	network, addr := "", ""
+	if dest != "syslog" {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return nil, err
+		}
+		network, addr = "udp", u.Host
+		if strings.HasPrefix(dest, "syslog+tcp://") {
+			network = "tcp"
+		}
+	}
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_AUTH, "mcphost")
+}
@@ -0,0 +1,206 @@
+// Package bench runs tool call workloads against a server and reports their latency
+// percentiles, throughput and error rates.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Options are the shape of a workload.
+type Options struct {
+	// Concurrency is the number of calls in flight at once.
+	Concurrency int
+	// Requests is the number of calls to make, unless Duration is set.
+	Requests int
+	// Duration is how long to keep making calls.
+	Duration time.Duration
+	// Rate bounds the calls started per second, in all; 0 means no bound.
+	Rate float64
+	// Timeout bounds each call; 0 means no bound.
+	Timeout time.Duration
+}
+
+// Call makes one call. It returns whether the tool reported an error, or an error if
+// the call itself failed.
+type Call func(ctx context.Context) (toolError bool, err error)
+
+// Latency summarizes the durations of the calls.
+type Latency struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// Report is the outcome of a workload.
+type Report struct {
+	Calls      int           `json:"calls"`
+	Succeeded  int           `json:"succeeded"`
+	ToolErrors int           `json:"toolErrors"`
+	Failures   int           `json:"failures"`
+	Elapsed    time.Duration `json:"elapsed"`
+	// Throughput is the calls completed per second.
+	Throughput float64 `json:"throughput"`
+	// ErrorRate is the share of the calls that failed or returned a tool error.
+	ErrorRate float64 `json:"errorRate"`
+	Latency   Latency `json:"latency"`
+	// Errors counts the failures by message, so that a few causes show up once each.
+	Errors map[string]int `json:"errors,omitempty"`
+}
+
+// Run makes calls with opts until the requests are done, the duration is over or ctx
+// ends, and reports how they went.
+func Run(ctx context.Context, opts Options, call Call) Report {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+	var limiter *rate.Limiter
+	if opts.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.Rate), 1)
+	}
+
+	// Each call takes a ticket; without a duration there are as many as requests
+	tickets := make(chan struct{})
+	go func() {
+		defer close(tickets)
+		for i := 0; opts.Duration > 0 || i < opts.Requests; i++ {
+			if limiter != nil && limiter.Wait(ctx) != nil {
+				return
+			}
+			select {
+			case tickets <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		report    = Report{Errors: make(map[string]int)}
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tickets {
+				callCtx, cancel := ctx, context.CancelFunc(func() {})
+				if opts.Timeout > 0 {
+					callCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+				}
+				began := time.Now()
+				toolError, err := call(callCtx)
+				took := time.Since(began)
+				cancel()
+				// Calls cut short by the end of the run do not count
+				if err != nil && ctx.Err() != nil {
+					continue
+				}
+
+				mu.Lock()
+				report.Calls++
+				latencies = append(latencies, took)
+				switch {
+				case err != nil:
+					report.Failures++
+					report.Errors[err.Error()]++
+				case toolError:
+					report.ToolErrors++
+				default:
+					report.Succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(start)
+	if report.Elapsed > 0 {
+		report.Throughput = float64(report.Calls) / report.Elapsed.Seconds()
+	}
+	if report.Calls > 0 {
+		report.ErrorRate = float64(report.Failures+report.ToolErrors) / float64(report.Calls)
+	}
+	report.Latency = summarize(latencies)
+	return report
+}
+
+// summarize returns the statistics of durations.
+func summarize(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return Latency{
+		Min:  durations[0],
+		Mean: total / time.Duration(len(durations)),
+		P50:  percentile(durations, 50),
+		P90:  percentile(durations, 90),
+		P95:  percentile(durations, 95),
+		P99:  percentile(durations, 99),
+		Max:  durations[len(durations)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted durations, by the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// Write writes r as text.
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Calls:\t%d in %s\n", r.Calls, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Throughput:\t%.1f calls/s\n", r.Throughput)
+	fmt.Fprintf(tw, "Succeeded:\t%d\n", r.Succeeded)
+	fmt.Fprintf(tw, "Tool errors:\t%d\n", r.ToolErrors)
+	fmt.Fprintf(tw, "Failures:\t%d\n", r.Failures)
+	fmt.Fprintf(tw, "Error rate:\t%.2f%%\n", 100*r.ErrorRate)
+	fmt.Fprintln(tw, "Latency:")
+	for _, l := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"min", r.Latency.Min}, {"mean", r.Latency.Mean}, {"p50", r.Latency.P50}, {"p90", r.Latency.P90},
+		{"p95", r.Latency.P95}, {"p99", r.Latency.P99}, {"max", r.Latency.Max},
+	} {
+		fmt.Fprintf(tw, "  %s\t%s\n", l.name, l.d.Round(time.Microsecond))
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(tw, "Errors:")
+		messages := make([]string, 0, len(r.Errors))
+		for message := range r.Errors {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		for _, message := range messages {
+			fmt.Fprintf(tw, "  %d\t%s\n", r.Errors[message], message)
+		}
+	}
+	return tw.Flush()
+}
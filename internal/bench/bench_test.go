@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that a workload makes the calls asked for, concurrently, and counts outcomes
+func TestRun(t *testing.T) {
+	var calls, inFlight, maxInFlight atomic.Int32
+	report := Run(context.Background(), Options{Concurrency: 4, Requests: 40}, func(ctx context.Context) (bool, error) {
+		n := calls.Add(1)
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		switch {
+		case n%10 == 0:
+			return false, errors.New("connection reset")
+		case n%10 == 5:
+			return true, nil
+		}
+		return false, nil
+	})
+
+	assert.Equal(t, int32(40), calls.Load())
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(4))
+	assert.Equal(t, 40, report.Calls)
+	assert.Equal(t, 32, report.Succeeded)
+	assert.Equal(t, 4, report.ToolErrors)
+	assert.Equal(t, 4, report.Failures)
+	assert.Equal(t, map[string]int{"connection reset": 4}, report.Errors)
+	assert.InDelta(t, 0.2, report.ErrorRate, 1e-9)
+	assert.Greater(t, report.Throughput, 0.0)
+	assert.GreaterOrEqual(t, report.Latency.Min, time.Millisecond)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P99)
+
+	var out bytes.Buffer
+	assert.NoError(t, report.Write(&out))
+	assert.Contains(t, out.String(), "Error rate:   20.00%")
+	assert.Contains(t, out.String(), "4  connection reset")
+}
+
+// Test that a run with a duration stops in time and leaves out the calls it cut short
+func TestRunDuration(t *testing.T) {
+	report := Run(context.Background(), Options{Concurrency: 2, Duration: 50 * time.Millisecond, Rate: 100}, func(ctx context.Context) (bool, error) {
+		select {
+		case <-time.After(time.Millisecond):
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	})
+	assert.Less(t, report.Elapsed, time.Second)
+	assert.Equal(t, 0, report.Failures)
+	assert.InDelta(t, 5, report.Calls, 4, "The rate should bound the calls")
+}
+
+// Test nearest rank percentiles
+func TestSummarize(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	latency := summarize(durations)
+	assert.Equal(t, Latency{
+		Min:  time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P95:  95 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}, latency)
+	assert.Equal(t, Latency{}, summarize(nil))
+}
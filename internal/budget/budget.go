@@ -0,0 +1,64 @@
+// Package budget shares the time left to a tool call among the upstream requests it
+// makes. The deadline of a call, set by its client with timeoutMs in the _meta of the
+// request or by the -tool-timeout watchdog, is the deadline of its context; upstream
+// requests get the time left less a reserve, for the tool to report their failure,
+// or their own timeout if shorter, and are not started at all when too little is
+// left, so that a call with 10 seconds left does not start a 30 second request.
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+const (
+	// Reserve is kept from the time left to a call for reporting a failed request.
+	Reserve = 250 * time.Millisecond
+	// MinRequest is the least time left worth starting an upstream request with.
+	MinRequest = 500 * time.Millisecond
+)
+
+// Remaining returns the time left before the deadline of ctx, and false if ctx has
+// none.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// Timeout returns the timeout of an upstream request made with ctx: timeout, or the
+// time left to ctx less Reserve if shorter, 0 for no limit when timeout is 0 and ctx
+// has no deadline. It fails with a Timeout error when less than MinRequest is left.
+func Timeout(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+	remaining, ok := Remaining(ctx)
+	if !ok {
+		return timeout, nil
+	}
+	remaining -= Reserve
+	if remaining < MinRequest {
+		return 0, toolerr.Errorf(toolerr.Timeout, "only %s left of the time of the call, too little to start a request", max(remaining+Reserve, 0).Round(time.Millisecond))
+	}
+	if timeout <= 0 || remaining < timeout {
+		return remaining, nil
+	}
+	return timeout, nil
+}
+
+// WithTimeout returns ctx bounded by the timeout of Timeout, and the function
+// releasing it.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	timeout, err := Timeout(ctx, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
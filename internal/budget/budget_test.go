@@ -0,0 +1,53 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Test deriving the timeouts of upstream requests from the time left to a call
+func TestTimeout(t *testing.T) {
+	// Without a deadline, requests keep their own timeout
+	timeout, err := Timeout(context.Background(), 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+	_, ok := Remaining(context.Background())
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	remaining, ok := Remaining(ctx)
+	require.True(t, ok)
+	assert.InDelta(t, 10*time.Second, remaining, float64(time.Second))
+	timeout, err = Timeout(ctx, 30*time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 10*time.Second-Reserve, timeout, float64(time.Second), "A call with 10s left does not start a 30s request")
+	timeout, err = Timeout(ctx, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 10*time.Second-Reserve, timeout, float64(time.Second))
+	timeout, err = Timeout(ctx, 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, timeout)
+
+	bounded, release, err := WithTimeout(ctx, 2*time.Second)
+	require.NoError(t, err)
+	defer release()
+	deadline, _ := bounded.Deadline()
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), deadline, time.Second)
+
+	// Too little time left
+	short, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	_, err = Timeout(short, 30*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "left of the time of the call, too little to start a request")
+	assert.Equal(t, toolerr.Timeout, toolerr.Classify(err).Code)
+	_, _, err = WithTimeout(short, 0)
+	assert.Error(t, err)
+}
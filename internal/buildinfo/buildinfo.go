@@ -0,0 +1,82 @@
+// Package buildinfo describes the build of mcphost. Releases set Version, Commit and
+// Date with the linker, e.g.
+//
+//	go build -ldflags "-X github.com/mark3labs/mcphost/internal/buildinfo.Version=v1.2.3"
+//
+// and other builds fall back to the module version and VCS details Go records.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags -X at release time.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes a build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	// Dependencies are the versions of the modules built in, by module path.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// Get returns the information of the running build.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		// Installed with go install ...@version
+		info.Version = bi.Main.Version
+	}
+	var modified bool
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && Commit == "" && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+	if len(bi.Deps) > 0 {
+		info.Dependencies = make(map[string]string, len(bi.Deps))
+		for _, dep := range bi.Deps {
+			version := dep.Version
+			if r := dep.Replace; r != nil {
+				// Local replacements have no version
+				version = r.Version
+				if version == "" {
+					version = r.Path
+				}
+			}
+			info.Dependencies[dep.Path] = version
+		}
+	}
+	return info
+}
@@ -0,0 +1,126 @@
+// Package cache stores the results of tools and the responses of upstream APIs for a
+// time, in memory, in files on disk or in Redis, so that servers share one way of
+// caching and a cache can outlive a server or be shared by several instances.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key until they expire.
+type Cache interface {
+	// Get returns the value stored under key, if any and not expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Close() error
+}
+
+// defaultCapacity is the number of entries of a memory cache opened without one.
+const defaultCapacity = 1000
+
+// Open opens the cache described by spec:
+//
+//	memory[:entries]                    in memory, LRU, 1000 entries by default
+//	disk:/var/cache/mcphost             one file per entry in a directory
+//	redis://[user:password@]host:port[/db] or rediss:// for TLS
+func Open(spec string) (Cache, error) {
+	switch {
+	case spec == "memory":
+		return NewMemory(defaultCapacity), nil
+	case strings.HasPrefix(spec, "memory:"):
+		capacity, err := strconv.Atoi(strings.TrimPrefix(spec, "memory:"))
+		if err != nil || capacity < 1 {
+			return nil, fmt.Errorf("invalid cache %q: the number of entries must be a positive integer", spec)
+		}
+		return NewMemory(capacity), nil
+	case strings.HasPrefix(spec, "disk:"):
+		return NewDisk(strings.TrimPrefix(spec, "disk:"))
+	case strings.HasPrefix(spec, "redis://"), strings.HasPrefix(spec, "rediss://"):
+		return NewRedis(spec)
+	default:
+		return nil, fmt.Errorf("invalid cache %q: use memory[:entries], disk:<directory> or redis://host:port", spec)
+	}
+}
+
+// Memory is a size-bounded LRU cache with per-entry expiry. A Memory with no capacity
+// stores nothing.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemory returns a memory cache of capacity entries.
+func NewMemory(capacity int) *Memory {
+	return &Memory{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns a cached value if present and not expired.
+func (c *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if c.capacity <= 0 {
+		return nil, false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores a value, evicting the least recently used entry when full.
+func (c *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.capacity <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Close does nothing.
+func (c *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test the LRU memory cache
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory(2)
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	_, ok, _ := c.Get(ctx, "b")
+	assert.False(t, ok, "Least recently used entry should be evicted")
+	value, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	c.Set(ctx, "expired", []byte("1"), -time.Second)
+	_, ok, _ = c.Get(ctx, "expired")
+	assert.False(t, ok, "Expired entries should not be returned")
+
+	disabled := NewMemory(0)
+	disabled.Set(ctx, "a", []byte("1"), time.Minute)
+	_, ok, _ = disabled.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+// Test the disk cache, which outlives the process
+func TestDisk(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	c, err := NewDisk(dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "old", []byte("2"), -time.Second))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600))
+
+	reopened, err := NewDisk(dir)
+	require.NoError(t, err)
+	value, ok, err := reopened.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+	_, ok, err = reopened.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Opening the cache removed the expired entry, and only it
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	assert.NoError(t, err)
+}
+
+// fakeRedis serves GET, SET with PX, AUTH and SELECT, enough for the Redis cache.
+func fakeRedis(t *testing.T, password string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	data := make(map[string]string)
+	expires := make(map[string]time.Time)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				authed := password == ""
+				for {
+					reply, err := readReply(reader)
+					if err != nil {
+						return
+					}
+					var args []string
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, string(arg.([]byte)))
+					}
+					mu.Lock()
+					switch {
+					case args[0] == "AUTH":
+						authed = args[len(args)-1] == password
+						if authed {
+							conn.Write([]byte("+OK\r\n"))
+						} else {
+							conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+						}
+					case !authed:
+						conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+					case args[0] == "SELECT":
+						conn.Write([]byte("+OK\r\n"))
+					case args[0] == "SET":
+						ms, _ := strconv.Atoi(args[4])
+						data[args[1]] = args[2]
+						expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+						conn.Write([]byte("+OK\r\n"))
+					case args[0] == "GET":
+						value, ok := data[args[1]]
+						if !ok || time.Now().After(expires[args[1]]) {
+							conn.Write([]byte("$-1\r\n"))
+						} else {
+							conn.Write([]byte("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"))
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// Test the Redis cache
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	addr := fakeRedis(t, "hunter2")
+	c, err := Open("redis://:hunter2@" + addr + "/2")
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "a", []byte("line 1\r\nline 2"), time.Minute))
+	value, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "line 1\r\nline 2", string(value))
+	_, ok, err = c.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	wrong, err := Open("redis://:wrong@" + addr)
+	require.NoError(t, err)
+	_, _, err = wrong.Get(ctx, "a")
+	assert.ErrorContains(t, err, "WRONGPASS")
+}
+
+// Test the cache specs
+func TestOpen(t *testing.T) {
+	c, err := Open("memory")
+	require.NoError(t, err)
+	assert.Equal(t, defaultCapacity, c.(*Memory).capacity)
+	c, err = Open("memory:5")
+	require.NoError(t, err)
+	assert.Equal(t, 5, c.(*Memory).capacity)
+	c, err = Open("disk:" + t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, &Disk{}, c)
+	c, err = Open("redis://cache.internal")
+	require.NoError(t, err)
+	assert.Equal(t, "cache.internal:6379", c.(*Redis).addr)
+
+	for _, spec := range []string{"memory:0", "disk:", "floppy", "redis://host/db"} {
+		_, err := Open(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// Test caching the results of tools by their arguments
+func TestMiddleware(t *testing.T) {
+	c := NewMemory(10)
+	calls := 0
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if req.Params.Arguments["fail"] == true {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText("result " + strconv.Itoa(calls)), nil
+	}
+	policies := map[string]Policy{
+		"search": {TTL: time.Minute, Key: Args("query")},
+		"short":  {TTL: time.Minute},
+	}
+	policy := func(tool string) (Policy, bool) {
+		p, ok := policies[tool]
+		return p, ok
+	}
+	ttls, err := ParseTTLs("short=0")
+	require.NoError(t, err)
+	call := Middleware("test", c, policy, ttls)(handler)
+
+	ctx := context.Background()
+	result, err := call(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{"query": "mcp", "page": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, "result 1", mcptest.ResultText(result))
+	assert.Nil(t, result.Meta)
+
+	// Arguments not in the key do not matter
+	result, err = call(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{"query": "mcp", "page": 2}))
+	require.NoError(t, err)
+	assert.Equal(t, "result 1", mcptest.ResultText(result))
+	assert.Equal(t, true, result.Meta["cached"])
+
+	result, _ = call(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{"query": "go"}))
+	assert.Equal(t, "result 2", mcptest.ResultText(result))
+
+	// Errors are not cached, nor are tools without a policy or with a zero TTL
+	for i := 0; i < 2; i++ {
+		call(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{"query": "x", "fail": true}))
+		call(ctx, mcptest.NewCallToolRequest("other", nil))
+		call(ctx, mcptest.NewCallToolRequest("short", nil))
+	}
+	assert.Equal(t, 8, calls)
+
+	_, err = ParseTTLs("search=soon")
+	assert.ErrorContains(t, err, `invalid cache TTL "search=soon"`)
+	_, err = ParseTTLs("1h")
+	assert.Error(t, err)
+}
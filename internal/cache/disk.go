@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Disk is a cache keeping each entry in a file of a directory, named after the hash
+// of its key and holding its expiry time and value, so that it survives restarts
+// and can be shared by the servers of a machine. Expired entries are removed when
+// read and when the cache is opened.
+type Disk struct {
+	dir string
+}
+
+// NewDisk opens the disk cache in dir, creating the directory if needed.
+func NewDisk(dir string) (*Disk, error) {
+	if dir == "" {
+		return nil, errors.New("the disk cache needs a directory")
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error getting home directory: %w", err)
+		}
+		dir = filepath.Join(home, rest)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	d := &Disk{dir: dir}
+	d.prune()
+	return d, nil
+}
+
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns a cached value if present and not expired.
+func (d *Disk) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	path := d.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := decodeEntry(data)
+	if !ok {
+		os.Remove(path)
+		return nil, false, nil
+	}
+	return value, true, nil
+}
+
+// Set stores a value, replacing the file of the entry atomically.
+func (d *Disk) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	data := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Add(ttl).UnixNano()))
+	data = append(data, value...)
+
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Close does nothing.
+func (d *Disk) Close() error {
+	return nil
+}
+
+// prune removes the expired entries and the files left by interrupted writes.
+func (d *Disk) prune() {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(d.dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > time.Hour {
+				os.Remove(path)
+			}
+			continue
+		}
+		// Other files are left alone, in case the directory is not only the cache's
+		if isEntryName(entry.Name()) && expired(path) {
+			os.Remove(path)
+		}
+	}
+}
+
+// isEntryName reports whether name is the name of the file of an entry.
+func isEntryName(name string) bool {
+	_, err := hex.DecodeString(name)
+	return err == nil && len(name) == 2*sha256.Size
+}
+
+// expired reports whether the entry in the file at path expired, reading only its
+// expiry time.
+func expired(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return true
+	}
+	_, ok := decodeEntry(header)
+	return !ok
+}
+
+// decodeEntry returns the value of the file of an entry, unless it expired.
+func decodeEntry(data []byte) ([]byte, bool) {
+	if len(data) < 8 {
+		return nil, false
+	}
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data)))
+	if time.Now().After(expires) {
+		return nil, false
+	}
+	return data[8:], true
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AnyTool is the TTL key applying to the cacheable tools without a TTL of their own.
+const AnyTool = "*"
+
+// KeyFunc returns the cache key of a tool call, or false for a call not to cache, e.g.
+// a POST request.
+type KeyFunc func(req mcp.CallToolRequest) (string, bool)
+
+// Policy makes the results of a tool cacheable.
+type Policy struct {
+	// TTL is how long results are kept, unless the command line sets another.
+	TTL time.Duration
+	// Key identifies the calls with the same result. It defaults to Args().
+	Key KeyFunc
+}
+
+// Args returns a KeyFunc identifying calls by the values of the named arguments, or of
+// all arguments when none are named.
+func Args(names ...string) KeyFunc {
+	return func(req mcp.CallToolRequest) (string, bool) {
+		args := req.Params.Arguments
+		if len(names) > 0 {
+			args = make(map[string]interface{}, len(names))
+			for _, name := range names {
+				if value, ok := req.Params.Arguments[name]; ok {
+					args[name] = value
+				}
+			}
+		}
+		// Maps are encoded with sorted keys, so equal arguments give equal keys
+		data, err := json.Marshal(args)
+		if err != nil {
+			return "", false
+		}
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), true
+	}
+}
+
+// ParseTTLs parses comma separated cache lifetimes of the form tool=duration, where
+// tool is a tool name or * for the other cacheable tools, e.g. "searchGoogle=1h,*=5m".
+// A zero duration disables caching.
+func ParseTTLs(spec string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tool, value, ok := strings.Cut(item, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid cache TTL %q: expected tool=duration", item)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid cache TTL %q: expected a duration such as 10m", item)
+		}
+		ttls[tool] = ttl
+	}
+	return ttls, nil
+}
+
+// Middleware returns tool middleware answering the calls of cacheable tools from c,
+// and storing the successful results of the others in it. policy returns the policy
+// of a tool, or false for tools that are not cacheable, and ttls override the TTLs of
+// the policies. Keys are prefixed with the name of the server. Results from the cache
+// have cached set in their _meta. Failing to use the cache is logged, not returned.
+func Middleware(name string, c Cache, policy func(tool string) (Policy, bool), ttls map[string]time.Duration) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool := req.Params.Name
+			p, ok := policy(tool)
+			if !ok {
+				return next(ctx, req)
+			}
+			ttl := p.TTL
+			if override, ok := ttls[tool]; ok {
+				ttl = override
+			} else if override, ok := ttls[AnyTool]; ok {
+				ttl = override
+			}
+			keyFunc := p.Key
+			if keyFunc == nil {
+				keyFunc = Args()
+			}
+			key, ok := keyFunc(req)
+			if ttl <= 0 || !ok {
+				return next(ctx, req)
+			}
+			key = name + "/" + tool + "/" + key
+
+			if data, ok, err := c.Get(ctx, key); err != nil {
+				log.Printf("Warning: Failed to read the cache: %v", err)
+			} else if ok {
+				raw := json.RawMessage(data)
+				if result, err := mcp.ParseCallToolResult(&raw); err == nil {
+					if result.Meta == nil {
+						result.Meta = make(map[string]interface{})
+					}
+					result.Meta["cached"] = true
+					return result, nil
+				}
+			}
+
+			result, err := next(ctx, req)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			if data, err := json.Marshal(result); err != nil {
+				log.Printf("Warning: Failed to encode a result for the cache: %v", err)
+			} else if err := c.Set(ctx, key, data, ttl); err != nil {
+				log.Printf("Warning: Failed to write the cache: %v", err)
+			}
+			return result, nil
+		}
+	}
+}
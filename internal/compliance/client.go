@@ -0,0 +1,197 @@
+package compliance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// message is a JSON-RPC message of the server: a response, or a request or a
+// notification of its own.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// rpcError is a JSON-RPC error response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// conn is the client side of a server served with the stdio transport over pipes.
+type conn struct {
+	in     *io.PipeWriter
+	served chan error
+
+	mu        sync.Mutex
+	nextID    int64
+	pending   map[int64]chan message
+	cancelled map[int64]bool
+	// stray are the messages answering no request, e.g. responses to notifications.
+	stray []string
+}
+
+// dial serves s over pipes until the conn is closed.
+func dial(ctx context.Context, s *server.MCPServer) *conn {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &conn{
+		in:        clientOut,
+		served:    make(chan error, 1),
+		pending:   make(map[int64]chan message),
+		cancelled: make(map[int64]bool),
+	}
+	go func() {
+		err := transport.ListenStdio(ctx, s, serverIn, serverOut)
+		serverOut.Close()
+		c.served <- err
+	}()
+	go c.read(clientIn)
+	return c
+}
+
+// read dispatches the messages of the server until it stops writing.
+func (c *conn) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var m message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			c.addStray(fmt.Sprintf("invalid JSON: %s", scanner.Bytes()))
+			continue
+		}
+		if m.Method != "" {
+			// Notifications and requests of the server, e.g. log messages
+			continue
+		}
+		id, err := strconv.ParseInt(string(m.ID), 10, 64)
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		cancelled := c.cancelled[id]
+		c.mu.Unlock()
+		switch {
+		case err == nil && ok:
+			ch <- m
+		case err == nil && cancelled:
+			// Responses to cancelled requests may still arrive
+		default:
+			c.addStray(fmt.Sprintf("response to no request: %s", scanner.Bytes()))
+		}
+	}
+	// Drain whatever the server still writes, so that it never blocks
+	io.Copy(io.Discard, r)
+}
+
+func (c *conn) addStray(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stray = append(c.stray, s)
+}
+
+// takeStray returns the messages that answered no request since the last call.
+func (c *conn) takeStray() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stray := c.stray
+	c.stray = nil
+	return stray
+}
+
+// write sends a message to the server.
+func (c *conn) write(m map[string]interface{}) error {
+	m["jsonrpc"] = mcp.JSONRPC_VERSION
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = c.in.Write(append(data, '\n'))
+	return err
+}
+
+// start sends a request and returns its ID and the channel its response arrives on.
+func (c *conn) start(method string, params interface{}) (int64, <-chan message, error) {
+	ch := make(chan message, 1)
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+	request := map[string]interface{}{"id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	if err := c.write(request); err != nil {
+		return 0, nil, err
+	}
+	return id, ch, nil
+}
+
+// call sends a request and returns the result of its response. It returns error
+// responses as *rpcError.
+func (c *conn) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id, ch, err := c.start(method, params)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case m := <-ch:
+		if m.Error != nil {
+			return nil, m.Error
+		}
+		if m.Result == nil {
+			return nil, errors.New("response without a result or an error")
+		}
+		return m.Result, nil
+	case <-ctx.Done():
+		c.cancel(id, "timed out")
+		return nil, fmt.Errorf("%s: no response: %w", method, ctx.Err())
+	}
+}
+
+// cancel cancels the request id with notifications/cancelled.
+func (c *conn) cancel(id int64, reason string) error {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.cancelled[id] = true
+	c.mu.Unlock()
+	return c.notify("notifications/cancelled", map[string]interface{}{"requestId": id, "reason": reason})
+}
+
+// notify sends a notification.
+func (c *conn) notify(method string, params interface{}) error {
+	notification := map[string]interface{}{"method": method}
+	if params != nil {
+		notification["params"] = params
+	}
+	return c.write(notification)
+}
+
+// close closes the input of the server and waits for it to answer the running
+// requests and stop.
+func (c *conn) close(ctx context.Context) error {
+	c.in.Close()
+	select {
+	case err := <-c.served:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("server did not stop once its input was closed: %w", ctx.Err())
+	}
+}
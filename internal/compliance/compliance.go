@@ -0,0 +1,602 @@
+// Package compliance checks that MCP servers follow the protocol. It drives a server
+// through the stdio transport as clients do: the initialize handshake, ping,
+// tools/list, tool calls with valid and invalid arguments, cancellation, and
+// resources/list and prompts/list when the server has them, and checks that every
+// response is the one the specification asks for. It backs mcphost selftest and the
+// contract tests of the bundled servers.
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/servers"
+)
+
+// Checks, in the order they run.
+const (
+	CheckStart        = "start"
+	CheckInitialize   = "initialize"
+	CheckPing         = "ping"
+	CheckUnknown      = "unknown method"
+	CheckToolsList    = "tools/list"
+	CheckValidCalls   = "valid calls"
+	CheckInvalidCalls = "invalid calls"
+	CheckCancel       = "cancellation"
+	CheckResources    = "resources"
+	CheckPrompts      = "prompts"
+)
+
+// Call is a tool call.
+type Call struct {
+	Tool      string
+	Arguments map[string]interface{}
+}
+
+// ValidCalls are calls to the bundled servers that must succeed with their default
+// flags, by server. They neither need the network nor change anything, so that the
+// checks can run anywhere. Every server is also called with getServerInfo.
+var ValidCalls = map[string][]Call{
+	"crypto":      {{"hash", map[string]interface{}{"input": "abc"}}},
+	"dataformat":  {{"convert", map[string]interface{}{"input": `{"a": 1}`, "to": "yaml"}}},
+	"diff":        {{"diffText", map[string]interface{}{"original": "a\n", "modified": "b\n"}}},
+	"fakedata":    {{"generateLorem", map[string]interface{}{"seed": 1}}},
+	"identifiers": {{"generateIds", map[string]interface{}{"type": "uuid4"}}},
+	"markdown":    {{"renderMarkdown", map[string]interface{}{"markdown": "# Title"}}},
+	"qrcode":      {{"generateQRCode", map[string]interface{}{"text": "mcphost"}}},
+	"regex":       {{"testRegex", map[string]interface{}{"pattern": "a+", "text": "caaat"}}},
+	"secrets":     {{"generatePassword", map[string]interface{}{}}},
+	"time":        {{"getCurrentTime", map[string]interface{}{"timezone": "UTC"}}},
+}
+
+// serverInfoTool is the tool every bundled server has.
+const serverInfoTool = "getServerInfo"
+
+// Result is the outcome of a check of a server.
+type Result struct {
+	Server string
+	Check  string
+	// Detail describes what was checked, or why the check was skipped.
+	Detail  string
+	Skipped bool
+	Err     error
+}
+
+// CheckServer creates the server s with the flags args and checks it as Run does.
+// Servers that cannot start with their default flags, e.g. for lack of a display,
+// are skipped; with args, failing to start is an error.
+func CheckServer(ctx context.Context, s servers.Server, args []string, timeout time.Duration) []Result {
+	serverCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	mcpServer, _, err := s.New(serverCtx, args)
+	if err != nil {
+		if len(args) > 0 {
+			return []Result{{Server: s.Name, Check: CheckStart, Err: err}}
+		}
+		return []Result{{Server: s.Name, Check: CheckStart, Detail: err.Error(), Skipped: true}}
+	}
+	defer middleware.Close(mcpServer)
+	return Run(ctx, s.Name, mcpServer, ValidCalls[s.Name], timeout)
+}
+
+// Run runs the checks against s, the server called name, waiting at most timeout for
+// each response. calls are tool calls that must succeed, besides getServerInfo if s
+// has it.
+func Run(ctx context.Context, name string, s *server.MCPServer, calls []Call, timeout time.Duration) []Result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r := &runner{name: name, conn: dial(ctx, s), timeout: timeout}
+
+	var info mcp.InitializeResult
+	if !r.check(CheckInitialize, func() (string, error) { return r.initialize(ctx, &info) }) {
+		return r.results
+	}
+	r.check(CheckPing, func() (string, error) { return r.ping(ctx) })
+	r.check(CheckUnknown, func() (string, error) { return r.unknownMethod(ctx) })
+	var tools []tool
+	if r.check(CheckToolsList, func() (string, error) { return r.listTools(ctx, info, &tools) }) {
+		if hasTool(tools, serverInfoTool) {
+			calls = append([]Call{{Tool: serverInfoTool, Arguments: map[string]interface{}{}}}, calls...)
+		}
+		r.check(CheckValidCalls, func() (string, error) { return r.validCalls(ctx, calls) })
+		r.check(CheckInvalidCalls, func() (string, error) { return r.invalidCalls(ctx, tools) })
+		r.check(CheckCancel, func() (string, error) { return r.cancellation(ctx, calls) })
+	}
+	r.check(CheckResources, func() (string, error) {
+		if info.Capabilities.Resources == nil {
+			return "", skipped("the server has no resources")
+		}
+		return r.resources(ctx)
+	})
+	r.check(CheckPrompts, func() (string, error) {
+		if info.Capabilities.Prompts == nil {
+			return "", skipped("the server has no prompts")
+		}
+		return r.prompts(ctx)
+	})
+
+	closeCtx, cancelClose := context.WithTimeout(ctx, timeout)
+	defer cancelClose()
+	if err := r.conn.close(closeCtx); err != nil {
+		r.results = append(r.results, Result{Server: name, Check: "shutdown", Err: err})
+	}
+	return r.results
+}
+
+// skipped is returned by checks that do not apply to a server, with the reason.
+type skipped string
+
+func (s skipped) Error() string { return string(s) }
+
+// runner runs the checks of a server.
+type runner struct {
+	name    string
+	conn    *conn
+	timeout time.Duration
+	results []Result
+}
+
+// check runs the check called name and records its result, which it reports the
+// success of. Messages answering no request fail the check they came during.
+func (r *runner) check(name string, run func() (string, error)) bool {
+	detail, err := run()
+	if stray := r.conn.takeStray(); err == nil && len(stray) > 0 {
+		err = fmt.Errorf("unexpected message: %s", stray[0])
+	}
+	result := Result{Server: r.name, Check: name, Detail: detail, Err: err}
+	var skip skipped
+	if errors.As(err, &skip) {
+		result.Detail, result.Err, result.Skipped = string(skip), nil, true
+	}
+	r.results = append(r.results, result)
+	return result.Err == nil
+}
+
+// call sends a request, waiting at most the timeout for its response.
+func (r *runner) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.conn.call(ctx, method, params)
+}
+
+// callError sends a request that must fail and returns its error response.
+func (r *runner) callError(ctx context.Context, method string, params interface{}) (*rpcError, error) {
+	result, err := r.call(ctx, method, params)
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) {
+		return rpcErr, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s succeeded, expected an error response: %s", method, result)
+}
+
+func (r *runner) initialize(ctx context.Context, info *mcp.InitializeResult) (string, error) {
+	result, err := r.call(ctx, string(mcp.MethodInitialize), map[string]interface{}{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"clientInfo":      mcp.Implementation{Name: "mcphost-selftest", Version: "1.0.0"},
+		"capabilities":    mcp.ClientCapabilities{},
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(result, info); err != nil {
+		return "", fmt.Errorf("invalid result: %w", err)
+	}
+	switch {
+	case info.ProtocolVersion != mcp.LATEST_PROTOCOL_VERSION:
+		return "", fmt.Errorf("protocol version %q, requested %s", info.ProtocolVersion, mcp.LATEST_PROTOCOL_VERSION)
+	case info.ServerInfo.Name == "" || info.ServerInfo.Version == "":
+		return "", errors.New("serverInfo lacks a name or a version")
+	}
+	if err := r.conn.notify("notifications/initialized", nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("protocol %s, %s %s", info.ProtocolVersion, info.ServerInfo.Name, info.ServerInfo.Version), nil
+}
+
+func (r *runner) ping(ctx context.Context) (string, error) {
+	result, err := r.call(ctx, string(mcp.MethodPing), nil)
+	if err != nil {
+		return "", err
+	}
+	var empty map[string]interface{}
+	if err := json.Unmarshal(result, &empty); err != nil || empty == nil {
+		return "", fmt.Errorf("result is not an object: %s", result)
+	}
+	return "", nil
+}
+
+func (r *runner) unknownMethod(ctx context.Context) (string, error) {
+	rpcErr, err := r.callError(ctx, "selftest/unknown", nil)
+	if err != nil {
+		return "", err
+	}
+	if rpcErr.Code != mcp.METHOD_NOT_FOUND {
+		return "", fmt.Errorf("error code %d, expected %d (method not found)", rpcErr.Code, mcp.METHOD_NOT_FOUND)
+	}
+	return "", nil
+}
+
+// tool is a tool as listed by the server.
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// required returns the required parameters of t.
+func (t tool) required() []string {
+	var names []string
+	list, _ := t.InputSchema["required"].([]interface{})
+	for _, name := range list {
+		if s, ok := name.(string); ok {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+func hasTool(tools []tool, name string) bool {
+	for _, t := range tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validToolName matches the tool names clients and LLM APIs accept.
+var validToolName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+func (r *runner) listTools(ctx context.Context, info mcp.InitializeResult, tools *[]tool) (string, error) {
+	if info.Capabilities.Tools == nil {
+		return "", errors.New("the server does not declare the tools capability")
+	}
+	result, err := r.call(ctx, string(mcp.MethodToolsList), map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+	var list struct {
+		Tools []tool `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+		return "", fmt.Errorf("invalid result: %w", err)
+	}
+	if list.Tools == nil {
+		return "", errors.New("result lacks tools")
+	}
+	seen := make(map[string]bool)
+	for _, t := range list.Tools {
+		if !validToolName.MatchString(t.Name) {
+			return "", fmt.Errorf("invalid tool name %q", t.Name)
+		}
+		if seen[t.Name] {
+			return "", fmt.Errorf("tool %s is listed twice", t.Name)
+		}
+		seen[t.Name] = true
+		if strings.TrimSpace(t.Description) == "" {
+			return "", fmt.Errorf("tool %s has no description", t.Name)
+		}
+		if err := checkInputSchema(t); err != nil {
+			return "", fmt.Errorf("tool %s: %w", t.Name, err)
+		}
+	}
+	*tools = list.Tools
+	return fmt.Sprintf("%d tools", len(list.Tools)), nil
+}
+
+// checkInputSchema checks that the input schema of t is a JSON Schema of an object.
+func checkInputSchema(t tool) error {
+	if t.InputSchema["type"] != "object" {
+		return fmt.Errorf("input schema of type %v, expected object", t.InputSchema["type"])
+	}
+	properties := map[string]interface{}{}
+	if p, ok := t.InputSchema["properties"]; ok && p != nil {
+		if properties, ok = p.(map[string]interface{}); !ok {
+			return errors.New("properties of the input schema are not an object")
+		}
+	}
+	for name, property := range properties {
+		if _, ok := property.(map[string]interface{}); !ok {
+			return fmt.Errorf("parameter %s: schema is not an object", name)
+		}
+	}
+	if required, ok := t.InputSchema["required"]; ok && required != nil {
+		if _, ok := required.([]interface{}); !ok {
+			return errors.New("required parameters are not an array")
+		}
+	}
+	for _, name := range t.required() {
+		if _, ok := properties[name]; !ok {
+			return fmt.Errorf("required parameter %s is not declared", name)
+		}
+	}
+	return nil
+}
+
+// callTool calls a tool and checks that its result is a valid tool result.
+func (r *runner) callTool(ctx context.Context, name string, args interface{}) (*mcp.CallToolResult, error) {
+	raw, err := r.call(ctx, string(mcp.MethodToolsCall), map[string]interface{}{"name": name, "arguments": args})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkToolResult(raw); err != nil {
+		return nil, fmt.Errorf("invalid result of %s: %w", name, err)
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// checkToolResult checks the content of a tool result.
+func checkToolResult(raw json.RawMessage) error {
+	var result struct {
+		Content []map[string]interface{} `json:"content"`
+		IsError *bool                    `json:"isError"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return err
+	}
+	if result.Content == nil {
+		return errors.New("result lacks content")
+	}
+	for i, content := range result.Content {
+		if err := checkContent(content); err != nil {
+			return fmt.Errorf("content %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkContent checks a content item of a tool result or a prompt message.
+func checkContent(content map[string]interface{}) error {
+	str := func(m map[string]interface{}, key string) bool {
+		s, ok := m[key].(string)
+		return ok && s != ""
+	}
+	switch content["type"] {
+	case "text":
+		if _, ok := content["text"].(string); !ok {
+			return errors.New("text content lacks text")
+		}
+	case "image", "audio":
+		if !str(content, "data") || !str(content, "mimeType") {
+			return fmt.Errorf("%s content lacks data or a MIME type", content["type"])
+		}
+	case "resource":
+		resource, ok := content["resource"].(map[string]interface{})
+		if !ok || !str(resource, "uri") {
+			return errors.New("embedded resource lacks a URI")
+		}
+	default:
+		return fmt.Errorf("unknown content type %v", content["type"])
+	}
+	return nil
+}
+
+func (r *runner) validCalls(ctx context.Context, calls []Call) (string, error) {
+	if len(calls) == 0 {
+		return "", skipped("no call known to succeed offline")
+	}
+	var names []string
+	for _, c := range calls {
+		result, err := r.callTool(ctx, c.Tool, c.Arguments)
+		if err != nil {
+			return "", err
+		}
+		if result.IsError {
+			return "", fmt.Errorf("%s returned an error: %s", c.Tool, text(result))
+		}
+		names = append(names, c.Tool)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// text returns the texts of a tool result.
+func text(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if t, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, t.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// expectToolError calls a tool expecting an error response or an error result
+// explaining itself.
+func (r *runner) expectToolError(ctx context.Context, name string, args interface{}) error {
+	result, err := r.callTool(ctx, name, args)
+	var rpcErr *rpcError
+	switch {
+	case errors.As(err, &rpcErr):
+		return nil
+	case err != nil:
+		return err
+	case !result.IsError:
+		return fmt.Errorf("%s succeeded with invalid arguments: %.200s", name, text(result))
+	case strings.TrimSpace(text(result)) == "":
+		return fmt.Errorf("error result of %s has no message", name)
+	}
+	return nil
+}
+
+func (r *runner) invalidCalls(ctx context.Context, tools []tool) (string, error) {
+	if err := r.expectToolError(ctx, "selftestUnknownTool", map[string]interface{}{}); err != nil {
+		return "", fmt.Errorf("unknown tool: %w", err)
+	}
+	checked := 0
+	for _, t := range tools {
+		if len(t.required()) == 0 {
+			continue
+		}
+		if err := r.expectToolError(ctx, t.Name, map[string]interface{}{}); err != nil {
+			return "", fmt.Errorf("missing required parameters: %w", err)
+		}
+		checked++
+	}
+	if len(tools) > 0 {
+		// Arguments must be an object
+		if _, err := r.callError(ctx, string(mcp.MethodToolsCall), map[string]interface{}{"name": tools[0].Name, "arguments": "invalid"}); err != nil {
+			return "", fmt.Errorf("arguments that are not an object: %w", err)
+		}
+	}
+	return fmt.Sprintf("unknown tool, missing parameters of %d tools", checked), nil
+}
+
+func (r *runner) cancellation(ctx context.Context, calls []Call) (string, error) {
+	// Cancelling a request the server does not know of is ignored
+	if err := r.conn.cancel(1<<40, "selftest"); err != nil {
+		return "", err
+	}
+	detail := "unknown request"
+	if len(calls) > 0 {
+		id, _, err := r.conn.start(string(mcp.MethodToolsCall), map[string]interface{}{"name": calls[0].Tool, "arguments": calls[0].Arguments})
+		if err != nil {
+			return "", err
+		}
+		if err := r.conn.cancel(id, "selftest"); err != nil {
+			return "", err
+		}
+		detail += ", " + calls[0].Tool
+	}
+	// The server keeps answering
+	if _, err := r.ping(ctx); err != nil {
+		return "", fmt.Errorf("ping after cancelling: %w", err)
+	}
+	return detail, nil
+}
+
+func (r *runner) resources(ctx context.Context) (string, error) {
+	raw, err := r.call(ctx, string(mcp.MethodResourcesList), map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+	var list struct {
+		Resources []struct {
+			URI  string `json:"uri"`
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil || list.Resources == nil {
+		return "", fmt.Errorf("invalid resources/list result: %s", raw)
+	}
+	for _, resource := range list.Resources {
+		if resource.URI == "" || resource.Name == "" {
+			return "", fmt.Errorf("resource %q lacks a URI or a name", resource.URI)
+		}
+		if err := r.readResource(ctx, resource.URI); err != nil {
+			return "", err
+		}
+	}
+
+	raw, err = r.call(ctx, string(mcp.MethodResourcesTemplatesList), map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+	var templates struct {
+		ResourceTemplates []struct {
+			URITemplate string `json:"uriTemplate"`
+			Name        string `json:"name"`
+		} `json:"resourceTemplates"`
+	}
+	if err := json.Unmarshal(raw, &templates); err != nil || templates.ResourceTemplates == nil {
+		return "", fmt.Errorf("invalid resources/templates/list result: %s", raw)
+	}
+	for _, template := range templates.ResourceTemplates {
+		if template.URITemplate == "" || template.Name == "" {
+			return "", fmt.Errorf("resource template %q lacks a URI template or a name", template.URITemplate)
+		}
+	}
+
+	if _, err := r.callError(ctx, string(mcp.MethodResourcesRead), map[string]interface{}{"uri": "selftest://unknown"}); err != nil {
+		return "", fmt.Errorf("reading an unknown resource: %w", err)
+	}
+	return fmt.Sprintf("%d resources, %d templates", len(list.Resources), len(templates.ResourceTemplates)), nil
+}
+
+// readResource reads the resource at uri and checks its contents.
+func (r *runner) readResource(ctx context.Context, uri string) error {
+	raw, err := r.call(ctx, string(mcp.MethodResourcesRead), map[string]interface{}{"uri": uri})
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", uri, err)
+	}
+	var result struct {
+		Contents []map[string]interface{} `json:"contents"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || result.Contents == nil {
+		return fmt.Errorf("invalid contents of %s: %s", uri, raw)
+	}
+	for _, contents := range result.Contents {
+		_, hasText := contents["text"].(string)
+		_, hasBlob := contents["blob"].(string)
+		if uri, _ := contents["uri"].(string); uri == "" || (!hasText && !hasBlob) {
+			return fmt.Errorf("contents of %s lack a URI, or text or a blob", uri)
+		}
+	}
+	return nil
+}
+
+func (r *runner) prompts(ctx context.Context) (string, error) {
+	raw, err := r.call(ctx, string(mcp.MethodPromptsList), map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+	var list struct {
+		Prompts []struct {
+			Name      string `json:"name"`
+			Arguments []struct {
+				Name     string `json:"name"`
+				Required bool   `json:"required"`
+			} `json:"arguments"`
+		} `json:"prompts"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil || list.Prompts == nil {
+		return "", fmt.Errorf("invalid prompts/list result: %s", raw)
+	}
+	for _, prompt := range list.Prompts {
+		if prompt.Name == "" {
+			return "", errors.New("prompt lacks a name")
+		}
+		args := map[string]string{}
+		for _, arg := range prompt.Arguments {
+			if arg.Required {
+				args[arg.Name] = "selftest"
+			}
+		}
+		raw, err := r.call(ctx, string(mcp.MethodPromptsGet), map[string]interface{}{"name": prompt.Name, "arguments": args})
+		if err != nil {
+			return "", fmt.Errorf("prompt %s: %w", prompt.Name, err)
+		}
+		var result struct {
+			Messages []struct {
+				Role    string                 `json:"role"`
+				Content map[string]interface{} `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(raw, &result); err != nil || result.Messages == nil {
+			return "", fmt.Errorf("invalid result of prompt %s: %s", prompt.Name, raw)
+		}
+		for _, m := range result.Messages {
+			if m.Role != string(mcp.RoleUser) && m.Role != string(mcp.RoleAssistant) {
+				return "", fmt.Errorf("prompt %s: invalid role %q", prompt.Name, m.Role)
+			}
+			if err := checkContent(m.Content); err != nil {
+				return "", fmt.Errorf("prompt %s: %w", prompt.Name, err)
+			}
+		}
+	}
+	if _, err := r.callError(ctx, string(mcp.MethodPromptsGet), map[string]interface{}{"name": "selftest-unknown"}); err != nil {
+		return "", fmt.Errorf("getting an unknown prompt: %w", err)
+	}
+	return fmt.Sprintf("%d prompts", len(list.Prompts)), nil
+}
@@ -0,0 +1,102 @@
+package compliance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/servers"
+)
+
+// Test that the bundled servers follow the protocol
+func TestBundledServers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, s := range servers.All {
+		t.Run(s.Name, func(t *testing.T) {
+			t.Parallel()
+			results := CheckServer(context.Background(), s, nil, 10*time.Second)
+			require.NotEmpty(t, results)
+			for _, r := range results {
+				assert.NoError(t, r.Err, "%s: %s", r.Check, r.Detail)
+			}
+		})
+	}
+}
+
+// byCheck returns the results by check.
+func byCheck(results []Result) map[string]Result {
+	m := make(map[string]Result)
+	for _, r := range results {
+		m[r.Check] = r
+	}
+	return m
+}
+
+// Test that the checks catch servers breaking the protocol
+func TestRun(t *testing.T) {
+	s := server.NewMCPServer("broken", "1.0.0", server.WithResourceCapabilities(false, false), server.WithPromptCapabilities(false))
+	s.AddTool(mcp.NewTool("lenient", mcp.WithDescription("Accepts anything"), mcp.WithString("query", mcp.Required())),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		})
+	s.AddTool(mcp.NewTool("getServerInfo", mcp.WithDescription("Reports about the server")),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("not configured"), nil
+		})
+	s.AddResource(mcp.NewResource("test://empty", "empty"),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: "test://empty", Text: "contents"}}, nil
+		})
+	s.AddPrompt(mcp.NewPrompt("summarize", mcp.WithArgument("url", mcp.RequiredArgument())),
+		func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("Summarize", []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("Summarize "+req.Params.Arguments["url"])),
+			}), nil
+		})
+
+	results := byCheck(Run(context.Background(), "broken", s, nil, 5*time.Second))
+	for _, check := range []string{CheckInitialize, CheckPing, CheckUnknown, CheckToolsList, CheckCancel, CheckResources, CheckPrompts} {
+		assert.NoError(t, results[check].Err, check)
+		assert.False(t, results[check].Skipped, check)
+	}
+	assert.Equal(t, "protocol 2024-11-05, broken 1.0.0", results[CheckInitialize].Detail)
+	assert.Equal(t, "2 tools", results[CheckToolsList].Detail)
+	assert.Equal(t, "1 resources, 0 templates", results[CheckResources].Detail)
+	assert.EqualError(t, results[CheckValidCalls].Err, "getServerInfo returned an error: not configured")
+	assert.EqualError(t, results[CheckInvalidCalls].Err, "missing required parameters: lenient succeeded with invalid arguments: ok")
+	assert.Equal(t, "1 prompts", results[CheckPrompts].Detail)
+
+	// Invalid tool schemas
+	s = server.NewMCPServer("broken", "1.0.0")
+	tool := mcp.NewTool("search", mcp.WithDescription("Searches"))
+	tool.InputSchema.Required = []string{"query"}
+	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	results = byCheck(Run(context.Background(), "broken", s, nil, 5*time.Second))
+	assert.EqualError(t, results[CheckToolsList].Err, "tool search: required parameter query is not declared")
+	assert.NotContains(t, results, CheckValidCalls, "tool calls are not checked when the tools are invalid")
+	assert.True(t, results[CheckResources].Skipped)
+	assert.Equal(t, "the server has no prompts", results[CheckPrompts].Detail)
+
+	s = server.NewMCPServer("broken", "1.0.0")
+	s.AddTool(mcp.NewTool("search tool"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	results = byCheck(Run(context.Background(), "broken", s, nil, 5*time.Second))
+	assert.EqualError(t, results[CheckToolsList].Err, `invalid tool name "search tool"`)
+}
+
+// Test checking the content of tool results
+func TestCheckToolResult(t *testing.T) {
+	assert.NoError(t, checkToolResult([]byte(`{"content": [{"type": "text", "text": ""}, {"type": "image", "data": "AA==", "mimeType": "image/png"}]}`)))
+	assert.NoError(t, checkToolResult([]byte(`{"content": [{"type": "resource", "resource": {"uri": "a://b", "text": "c"}}], "isError": true}`)))
+	assert.EqualError(t, checkToolResult([]byte(`{"isError": true}`)), "result lacks content")
+	assert.EqualError(t, checkToolResult([]byte(`{"content": [{"type": "video"}]}`)), "content 0: unknown content type video")
+	assert.EqualError(t, checkToolResult([]byte(`{"content": [{"type": "image", "data": "AA=="}]}`)), "content 0: image content lacks data or a MIME type")
+}
@@ -0,0 +1,166 @@
+// Package concurrency limits how many tool calls a server runs at once, in all and per
+// tool, queueing the calls over the limits for a while before refusing them.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/i18n"
+)
+
+// AnyTool is the limit key applying to the tools without a limit of their own.
+const AnyTool = "*"
+
+// ParseLimits parses comma separated limits of the form tool=count, where tool is a
+// tool name or * for each other tool, e.g. "captureScreen=2,*=8".
+func ParseLimits(spec string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tool, countText, ok := strings.Cut(item, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid concurrency limit %q: expected tool=count", item)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countText))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid concurrency limit %q: count must be a positive integer", item)
+		}
+		if _, dup := limits[tool]; dup {
+			return nil, fmt.Errorf("duplicate concurrency limit for %s", tool)
+		}
+		limits[tool] = count
+	}
+	return limits, nil
+}
+
+// errBusy is returned by Acquire when no slot freed up in time.
+var errBusy = errors.New("busy")
+
+// Limiter bounds the concurrent calls of a server and of each of its tools.
+type Limiter struct {
+	all          chan struct{} // nil without a limit
+	limits       map[string]int
+	queueTimeout time.Duration
+
+	mu    sync.Mutex
+	tools map[string]chan struct{}
+}
+
+// New creates a Limiter running at most max calls at once, or any number if max is
+// 0, and at most limits[tool] calls of each tool, keyed by tool name or AnyTool. Calls
+// over the limits wait up to queueTimeout for a slot, and are refused at once if it
+// is 0.
+func New(max int, limits map[string]int, queueTimeout time.Duration) *Limiter {
+	l := &Limiter{limits: limits, queueTimeout: queueTimeout, tools: make(map[string]chan struct{})}
+	if max > 0 {
+		l.all = make(chan struct{}, max)
+	}
+	return l
+}
+
+// slots returns the semaphore of tool, or nil if it has no limit.
+func (l *Limiter) slots(tool string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slots, ok := l.tools[tool]; ok {
+		return slots
+	}
+	limit, ok := l.limits[tool]
+	if !ok {
+		limit = l.limits[AnyTool]
+	}
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+	l.tools[tool] = slots
+	return slots
+}
+
+// Acquire waits for a slot to call tool, first among the calls of the tool and then
+// among all calls, so that calls waiting for a busy tool do not hold a slot others
+// could use. It returns a function releasing the slots, or an error if they did not
+// free up within the queue timeout or ctx ended.
+func (l *Limiter) Acquire(ctx context.Context, tool string) (func(), error) {
+	// Without a queue timeout, calls get a free slot or none
+	ctx, cancel := context.WithTimeout(ctx, l.queueTimeout)
+	defer cancel()
+	toolSlots := l.slots(tool)
+	if err := acquire(ctx, toolSlots); err != nil {
+		return nil, err
+	}
+	if err := acquire(ctx, l.all); err != nil {
+		release(toolSlots)
+		return nil, err
+	}
+	return func() {
+		release(l.all)
+		release(toolSlots)
+	}, nil
+}
+
+// acquire takes a slot of a semaphore, waiting until ctx ends, but taking a free slot
+// even if it already has. A nil semaphore has slots for all.
+func acquire(ctx context.Context, slots chan struct{}) error {
+	if slots == nil {
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errBusy
+		}
+		return ctx.Err()
+	}
+}
+
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
+
+// Middleware returns tool middleware running the calls within the limits. Calls that
+// found no slot within the queue timeout get an error result, marked with busy in its
+// _meta so that clients can retry later.
+func (l *Limiter) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			done, err := l.Acquire(ctx, req.Params.Name)
+			if errors.Is(err, errBusy) {
+				lang := i18n.FromContext(ctx)
+				result := mcp.NewToolResultError(i18n.Sprintf(lang, "Too many concurrent tool calls, %s waited %s for a slot; retry later", req.Params.Name, l.queueTimeout))
+				if l.queueTimeout == 0 {
+					result = mcp.NewToolResultError(i18n.Sprintf(lang, "Too many concurrent tool calls, no slot free for %s; retry later", req.Params.Name))
+				}
+				result.Meta = map[string]interface{}{"busy": true}
+				return result, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			defer done()
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test limit parsing
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" captureScreen=2, *=8 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"captureScreen": 2, "*": 8}, limits)
+
+	for _, spec := range []string{"fetchURL", "=1", "fetchURL=0", "fetchURL=x", "a=1,a=2"} {
+		_, err := ParseLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// Test that calls wait for slots of their tool and of the server
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	l := New(2, map[string]int{"slow": 1}, 20*time.Millisecond)
+
+	release, err := l.Acquire(ctx, "slow")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "slow")
+	assert.ErrorIs(t, err, errBusy, "The tool allows one call at once")
+
+	other, err := l.Acquire(ctx, "fast")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "fast")
+	assert.ErrorIs(t, err, errBusy, "The server allows two calls at once")
+
+	// Queued calls get the slots freed while they wait
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		release()
+	}()
+	l.queueTimeout = time.Second
+	done, err := l.Acquire(ctx, "slow")
+	require.NoError(t, err)
+	done()
+	other()
+
+	// Ending the context of a queued call does not report it busy
+	release, err = l.Acquire(ctx, "slow")
+	require.NoError(t, err)
+	defer release()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = l.Acquire(canceled, "slow")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// Test that calls finding no slot get an error result
+func TestMiddleware(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-finish
+		return mcp.NewToolResultText("done"), nil
+	}
+	call := New(1, nil, 0).Middleware()(handler)
+
+	go call(context.Background(), mcptest.NewCallToolRequest("slow", nil))
+	<-started
+	result, err := call(context.Background(), mcptest.NewCallToolRequest("slow", nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Too many concurrent tool calls, no slot free for slow; retry later", mcptest.ResultText(result))
+	assert.Equal(t, true, result.Meta["busy"])
+	close(finish)
+}
@@ -0,0 +1,398 @@
+// Package config resolves the flags of mcphost and its servers from their sources.
+// A flag takes its value from the command line, else from its MCPHOST_* environment
+// variable or the other environment variables it is read from, else from the config
+// file, else keeps its default.
+//
+// The config file, ~/.config/mcphost/flags.yaml or the file named by
+// MCPHOST_CONFIG_FILE, is YAML or JSON with a section of flags per server or command:
+//
+//	fetch:
+//	  timeout: 10
+//	googlesearch:
+//	  api-key: keychain:mcphost/googlesearch-api-key
+//	  search-engine-id: 0123456789abcdef
+//
+// The all section sets the flags of every server and command defining them, unless
+// their own section does, e.g. the URL policy of the servers requesting URLs:
+//
+//	all:
+//	  url-deny-hosts: [169.254.0.0/16, metadata.google.internal]
+//
+// Values referring to secrets, e.g. env:GOOGLE_API_KEY or vault:path#field, are
+// replaced by the secrets wherever they come from, see package secretref. The secrets,
+// and the values of flags named like secrets, e.g. -api-key, are masked in the log,
+// see package redact.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/secretref"
+)
+
+// EnvPrefix prefixes the environment variables flags are read from.
+const EnvPrefix = "MCPHOST"
+
+// FileEnv names the config file, instead of the default one.
+const FileEnv = "MCPHOST_CONFIG_FILE"
+
+// SharedSection is the section of the config file with the flags of all servers and
+// commands.
+const SharedSection = "all"
+
+var envReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// EnvName returns the environment variable of a flag, e.g. MCPHOST_FETCH_TIMEOUT for
+// the timeout flag of the fetch server.
+func EnvName(names ...string) string {
+	return strings.ToUpper(envReplacer.Replace(strings.Join(append([]string{EnvPrefix}, names...), "_")))
+}
+
+// Source is where the value of a flag comes from.
+type Source int
+
+// Sources, by increasing precedence.
+const (
+	Default Source = iota
+	File
+	Environment
+	CommandLine
+)
+
+func (s Source) String() string {
+	switch s {
+	case File:
+		return "config file"
+	case Environment:
+		return "environment"
+	case CommandLine:
+		return "command line"
+	}
+	return "default"
+}
+
+// DefaultFile returns the config file read by default, ~/.config/mcphost/flags.yaml.
+func DefaultFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mcphost", "flags.yaml")
+}
+
+// FilePath returns the config file: that of MCPHOST_CONFIG_FILE, else the default
+// one.
+func FilePath() string {
+	if path := os.Getenv(FileEnv); path != "" {
+		return path
+	}
+	return DefaultFile()
+}
+
+// LoadFile reads the sections of the config file at path, keyed by server or
+// command, then by flag name. A missing file has no sections, unless it was named by
+// MCPHOST_CONFIG_FILE.
+func LoadFile(path string) (map[string]map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv(FileEnv) == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	sections := make(map[string]map[string]string, len(raw))
+	for name, flags := range raw {
+		section := make(map[string]string, len(flags))
+		for flagName, value := range flags {
+			s, err := fileValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config file %s: %s.%s: %w", path, name, flagName, err)
+			}
+			section[flagName] = s
+		}
+		sections[name] = section
+	}
+	return sections, nil
+}
+
+// fileValue returns the flag value of a value of the config file: scalars as they are
+// written and lists joined with commas, as comma separated flags take them.
+func fileValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("flags take a value or a list, not a mapping")
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Option adds a source to Load.
+type Option func(*options)
+
+type options struct {
+	env  map[string][]string
+	file *string
+}
+
+// WithEnv reads the flag called name from the environment variables names when its
+// MCPHOST_* variable is not set, e.g. the API_KEY variable for -api-key. Empty
+// variables are ignored.
+func WithEnv(name string, names ...string) Option {
+	return func(o *options) {
+		if o.env == nil {
+			o.env = make(map[string][]string)
+		}
+		o.env[name] = append(o.env[name], names...)
+	}
+}
+
+// WithFile reads the config file at path instead of that of FilePath, none if path
+// is empty.
+func WithFile(path string) Option {
+	return func(o *options) { o.file = &path }
+}
+
+// Config is the flags of a server or command, with where their values come from.
+type Config struct {
+	fs      *flag.FlagSet
+	sources map[string]Source
+}
+
+// Parse parses the flags of fs from args and fills in the others from their sources,
+// like Load.
+func Parse(fs *flag.FlagSet, args []string, opts ...Option) error {
+	_, err := Load(fs, args, opts...)
+	return err
+}
+
+// Load parses the flags of a server or command from args. Flags missing from args
+// are then set from their environment variable, named after the flag set and the
+// flag, or from the other variables given with WithEnv, else from the section of the
+// config file named after the flag set, or from its all section. Flags of the section
+// named after the flag set that fs does not define are errors.
+func Load(fs *flag.FlagSet, args []string, opts ...Option) (*Config, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	c := &Config{fs: fs, sources: make(map[string]Source)}
+	fs.Visit(func(f *flag.Flag) { c.sources[f.Name] = CommandLine })
+
+	path := FilePath()
+	if o.file != nil {
+		path = *o.file
+	}
+	sections, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	section := sections[fs.Name()]
+	for name := range section {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("config file %s: %s has no flag -%s", path, fs.Name(), name)
+		}
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := c.sources[f.Name]; ok || err != nil {
+			return
+		}
+		primary := EnvName(fs.Name(), f.Name)
+		for _, name := range append([]string{primary}, o.env[f.Name]...) {
+			// The other variables are ignored when empty, as the servers always did
+			if value, ok := os.LookupEnv(name); ok && (value != "" || name == primary) {
+				if setErr := fs.Set(f.Name, value); setErr != nil {
+					err = fmt.Errorf("invalid value %q for %s: %w", value, name, setErr)
+				}
+				c.sources[f.Name] = Environment
+				return
+			}
+		}
+		for _, name := range []string{fs.Name(), SharedSection} {
+			if value, ok := sections[name][f.Name]; ok {
+				if setErr := fs.Set(f.Name, value); setErr != nil {
+					err = fmt.Errorf("invalid value %q for %s.%s in %s: %w", value, name, f.Name, path, setErr)
+				}
+				c.sources[f.Name] = File
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if err == nil {
+			err = resolve(f.Name, f.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// resolve replaces the value of the flag called name by the secret it refers to, if
+// it is a reference, and registers secret values with package redact.
+func resolve(name string, value flag.Value) error {
+	if !secretref.IsRef(value.String()) {
+		if redact.SecretName(name) {
+			redact.AddSecret(value.String())
+		}
+		return nil
+	}
+	secret, err := secretref.Resolve(value.String())
+	if err != nil {
+		return fmt.Errorf("flag -%s: %w", name, err)
+	}
+	redact.AddSecret(secret)
+	if err := value.Set(secret); err != nil {
+		return fmt.Errorf("flag -%s: invalid secret: %w", name, err)
+	}
+	return nil
+}
+
+// Source returns where the value of the flag called name comes from.
+func (c *Config) Source(name string) Source {
+	return c.sources[name]
+}
+
+// Missing returns those of the flags called names that are empty, e.g. credentials
+// a server cannot work without.
+func (c *Config) Missing(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if c.String(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// lookup returns the flag called name. It panics if there is none, as asking for it
+// is a programming error.
+func (c *Config) lookup(name string) *flag.Flag {
+	f := c.fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("config: %s has no flag -%s", c.fs.Name(), name))
+	}
+	return f
+}
+
+// value returns the value of the flag called name.
+func (c *Config) value(name string) interface{} {
+	f := c.lookup(name)
+	if getter, ok := f.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return f.Value.String()
+}
+
+// String returns the value of the flag called name.
+func (c *Config) String(name string) string {
+	return c.lookup(name).Value.String()
+}
+
+// Int returns the value of the integer flag called name.
+func (c *Config) Int(name string) int {
+	n, _ := c.value(name).(int)
+	return n
+}
+
+// Int64 returns the value of the 64-bit integer flag called name.
+func (c *Config) Int64(name string) int64 {
+	n, _ := c.value(name).(int64)
+	return n
+}
+
+// Float64 returns the value of the floating point flag called name.
+func (c *Config) Float64(name string) float64 {
+	n, _ := c.value(name).(float64)
+	return n
+}
+
+// Bool returns the value of the boolean flag called name.
+func (c *Config) Bool(name string) bool {
+	b, _ := c.value(name).(bool)
+	return b
+}
+
+// Duration returns the value of the duration flag called name.
+func (c *Config) Duration(name string) time.Duration {
+	d, _ := c.value(name).(time.Duration)
+	return d
+}
+
+// BindEnv sets the flags of a cobra command missing from its command line from the
+// environment, with viper, e.g. --openai-api-key from MCPHOST_OPENAI_API_KEY, else
+// from the section of the config file named after the command, and resolves the
+// references to secrets among their values as Load does.
+func BindEnv(fs *pflag.FlagSet) error {
+	v := viper.New()
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(envReplacer)
+	v.AutomaticEnv()
+	path := FilePath()
+	sections, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	section := sections[fs.Name()]
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || err != nil {
+			return
+		}
+		if v.IsSet(f.Name) {
+			if setErr := fs.Set(f.Name, v.GetString(f.Name)); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %w", v.GetString(f.Name), EnvName(f.Name), setErr)
+			}
+			return
+		}
+		if value, ok := section[f.Name]; ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s.%s in %s: %w", value, fs.Name(), f.Name, path, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed && err == nil {
+			err = resolve(f.Name, f.Value)
+		}
+	})
+	return err
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/redact"
+)
+
+// Test environment variable names
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MCPHOST_FETCH_MAX_BODY_SIZE", EnvName("fetch", "max-body-size"))
+	assert.Equal(t, "MCPHOST_OPENAI_API_KEY", EnvName("openai-api-key"))
+}
+
+// Test that the command line wins over the environment and the environment over defaults
+func TestParse(t *testing.T) {
+	t.Setenv("MCPHOST_FETCH_TIMEOUT", "10")
+	t.Setenv("MCPHOST_FETCH_USER_AGENT", "from-env")
+	t.Setenv("MCPHOST_OTHER_VERBOSE", "true")
+
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	timeout := fs.Int("timeout", 30, "")
+	userAgent := fs.String("user-agent", "default", "")
+	verbose := fs.Bool("verbose", false, "")
+	require.NoError(t, Parse(fs, []string{"-user-agent", "from-flag", "arg"}))
+	assert.Equal(t, 10, *timeout)
+	assert.Equal(t, "from-flag", *userAgent)
+	assert.False(t, *verbose)
+	assert.Equal(t, []string{"arg"}, fs.Args())
+
+	t.Setenv("MCPHOST_FETCH_TIMEOUT", "soon")
+	fs = flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.Int("timeout", 30, "")
+	assert.ErrorContains(t, Parse(fs, nil), `invalid value "soon" for MCPHOST_FETCH_TIMEOUT`)
+}
+
+// writeFile writes a config file and makes it that of the test.
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv(FileEnv, path)
+	return path
+}
+
+// Test the precedence of the sources and the typed values of the flags
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+fetch:
+  timeout: 20
+  user-agent: from-file
+  retries: 3
+  hosts: [a.example, b.example]
+  delay: 2s
+  verbose: true
+googlesearch:
+  api-key: unknown
+`)
+	t.Setenv("MCPHOST_FETCH_USER_AGENT", "from-env")
+	t.Setenv("FETCH_RATIO", "0.5")
+	t.Setenv("FETCH_RETRIES", "")
+
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.Int("timeout", 30, "")
+	fs.String("user-agent", "default", "")
+	fs.Int64("retries", 1, "")
+	fs.String("hosts", "", "")
+	fs.Duration("delay", time.Second, "")
+	fs.Bool("verbose", false, "")
+	fs.Float64("ratio", 1, "")
+	fs.String("proxy", "", "")
+	c, err := Load(fs, []string{"-timeout", "10"}, WithEnv("ratio", "FETCH_RATIO"), WithEnv("retries", "FETCH_RETRIES"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, c.Int("timeout"))
+	assert.Equal(t, CommandLine, c.Source("timeout"))
+	assert.Equal(t, "from-env", c.String("user-agent"))
+	assert.Equal(t, Environment, c.Source("user-agent"))
+	assert.Equal(t, 0.5, c.Float64("ratio"))
+	assert.Equal(t, Environment, c.Source("ratio"))
+	assert.Equal(t, int64(3), c.Int64("retries"), "Empty variables given with WithEnv should be ignored")
+	assert.Equal(t, File, c.Source("retries"))
+	assert.Equal(t, "a.example,b.example", c.String("hosts"))
+	assert.Equal(t, 2*time.Second, c.Duration("delay"))
+	assert.True(t, c.Bool("verbose"))
+	assert.Equal(t, Default, c.Source("proxy"))
+	assert.Equal(t, "default", Default.String())
+	assert.Equal(t, []string{"proxy"}, c.Missing("proxy", "hosts"))
+	assert.Panics(t, func() { c.String("missing") })
+
+	fs = flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	fs.String("search-engine-id", "", "")
+	_, err = Load(fs, nil)
+	assert.EqualError(t, err, "config file "+path+": googlesearch has no flag -api-key")
+
+	// The all section sets the flags of every flag set defining them
+	writeFile(t, "all:\n  url-deny-hosts: [10.0.0.0/8, metadata.internal]\n  timeout: 5\n  unknown: 1\nfetch:\n  timeout: 20\n")
+	fs = flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.Int("timeout", 30, "")
+	fs.String("url-deny-hosts", "", "")
+	c, err = Load(fs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 20, c.Int("timeout"), "The section of the flag set comes first")
+	assert.Equal(t, "10.0.0.0/8,metadata.internal", c.String("url-deny-hosts"))
+	assert.Equal(t, File, c.Source("url-deny-hosts"))
+	fs = flag.NewFlagSet("crawler", flag.ContinueOnError)
+	fs.String("url-deny-hosts", "", "")
+	fs.Bool("url-allow-private", false, "")
+	c, err = Load(fs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8,metadata.internal", c.String("url-deny-hosts"))
+	writeFile(t, "all:\n  url-allow-private: maybe\n")
+	_, err = Load(fs, nil)
+	assert.ErrorContains(t, err, `invalid value "maybe" for all.url-allow-private in`)
+
+	writeFile(t, "fetch:\n  timeout: soon\n")
+	fs = flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.Int("timeout", 30, "")
+	_, err = Load(fs, nil)
+	assert.ErrorContains(t, err, `invalid value "soon" for fetch.timeout in`)
+
+	writeFile(t, "fetch:\n  timeout: {seconds: 1}\n")
+	_, err = Load(flag.NewFlagSet("fetch", flag.ContinueOnError), nil)
+	assert.ErrorContains(t, err, "fetch.timeout: flags take a value or a list, not a mapping")
+
+	t.Setenv(FileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = Load(flag.NewFlagSet("fetch", flag.ContinueOnError), nil)
+	assert.ErrorContains(t, err, "failed to read config file")
+	_, err = Load(flag.NewFlagSet("fetch", flag.ContinueOnError), nil, WithFile(""))
+	assert.NoError(t, err)
+}
+
+// Test that references to secrets are resolved, from the command line and the environment
+func TestParseSecrets(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "AIza-secret")
+	t.Setenv("MCPHOST_GOOGLESEARCH_SEARCH_ENGINE_ID", "env:GOOGLE_CX")
+	t.Setenv("GOOGLE_CX", "cx-secret")
+
+	fs := flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	apiKey := fs.String("api-key", "", "")
+	cx := fs.String("search-engine-id", "", "")
+	require.NoError(t, Parse(fs, []string{"-api-key", "env:GOOGLE_API_KEY"}))
+	assert.Equal(t, "AIza-secret", *apiKey)
+	assert.Equal(t, "cx-secret", *cx)
+	assert.Equal(t, "key=[REDACTED] cx=[REDACTED]", redact.Secrets("key=AIza-secret cx=cx-secret"), "Secrets should be masked in the log")
+
+	fs = flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	fs.String("api-key", "", "")
+	assert.ErrorContains(t, Parse(fs, []string{"-api-key", "env:MCPHOST_TEST_UNSET"}), "flag -api-key: failed to resolve env:MCPHOST_TEST_UNSET")
+
+	// Values of the config file and of the other variables are resolved too
+	writeFile(t, "googlesearch:\n  search-engine-id: env:GOOGLE_CX\n")
+	t.Setenv("MCPHOST_GOOGLESEARCH_SEARCH_ENGINE_ID", "")
+	os.Unsetenv("MCPHOST_GOOGLESEARCH_SEARCH_ENGINE_ID")
+	t.Setenv("API_KEY", "env:GOOGLE_API_KEY")
+	fs = flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	apiKey = fs.String("api-key", "", "")
+	cx = fs.String("search-engine-id", "", "")
+	require.NoError(t, Parse(fs, nil, WithEnv("api-key", "API_KEY")))
+	assert.Equal(t, "AIza-secret", *apiKey)
+	assert.Equal(t, "cx-secret", *cx)
+}
+
+// Test binding cobra flags to the environment
+func TestBindEnv(t *testing.T) {
+	t.Setenv("MCPHOST_MODEL", "openai:gpt-4")
+	t.Setenv("MCPHOST_MESSAGE_WINDOW", "20")
+	t.Setenv("MCPHOST_DEBUG", "true")
+
+	fs := pflag.NewFlagSet("mcphost", pflag.ContinueOnError)
+	model := fs.StringP("model", "m", "anthropic:claude-3-5-sonnet-latest", "")
+	window := fs.Int("message-window", 10, "")
+	debug := fs.Bool("debug", false, "")
+	require.NoError(t, fs.Parse([]string{"--message-window", "5"}))
+	require.NoError(t, BindEnv(fs))
+	assert.Equal(t, "openai:gpt-4", *model)
+	assert.Equal(t, 5, *window)
+	assert.True(t, *debug)
+
+	t.Setenv("MCPHOST_MESSAGE_WINDOW", "many")
+	fs = pflag.NewFlagSet("mcphost", pflag.ContinueOnError)
+	fs.Int("message-window", 10, "")
+	assert.ErrorContains(t, BindEnv(fs), "MCPHOST_MESSAGE_WINDOW")
+
+	t.Setenv("MCPHOST_MESSAGE_WINDOW", "10")
+	t.Setenv("OPENAI_KEY", "sk-secret")
+	fs = pflag.NewFlagSet("mcphost", pflag.ContinueOnError)
+	apiKey := fs.String("openai-api-key", "", "")
+	require.NoError(t, fs.Parse([]string{"--openai-api-key", "env:OPENAI_KEY"}))
+	require.NoError(t, BindEnv(fs))
+	assert.Equal(t, "sk-secret", *apiKey)
+
+	// The section of the command in the config file comes after the environment
+	writeFile(t, "mcphost:\n  model: ollama:qwen2.5\n  message-window: 30\n")
+	os.Unsetenv("MCPHOST_MODEL")
+	fs = pflag.NewFlagSet("mcphost", pflag.ContinueOnError)
+	model = fs.StringP("model", "m", "anthropic:claude-3-5-sonnet-latest", "")
+	window = fs.Int("message-window", 10, "")
+	require.NoError(t, BindEnv(fs))
+	assert.Equal(t, "ollama:qwen2.5", *model)
+	assert.Equal(t, 10, *window)
+}
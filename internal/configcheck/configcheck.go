@@ -0,0 +1,225 @@
+// Package configcheck checks the config files of mcphost serve and mcphost proxy
+// without starting their servers: it reports errors with their line in the file,
+// explains the effective configuration and checks that the upstreams of the servers
+// can be reached.
+package configcheck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/proxy"
+	"github.com/mark3labs/mcphost/internal/supervisor"
+)
+
+// Kinds of config files.
+const (
+	Serve = "serve"
+	Proxy = "proxy"
+)
+
+// DefaultPath returns the config file read by the command of kind when none is given:
+// for serve, mcphost.yaml in the current directory if it exists, else the config of
+// the user, ~/.mcphost/config.yaml.
+func DefaultPath(kind string) string {
+	if kind == Proxy {
+		return "mcphost-proxy.yaml"
+	}
+	if _, err := os.Stat("mcphost.yaml"); err == nil {
+		return "mcphost.yaml"
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "mcphost.yaml"
+	}
+	return filepath.Join(home, ".mcphost", "config.yaml")
+}
+
+// Config is a valid config file.
+type Config struct {
+	Kind string
+	Path string
+	// Value is the parsed config, with defaults filled in: a *supervisor.Config or a
+	// *proxy.Config.
+	Value interface{}
+
+	// root is the file as written.
+	root *yaml.Node
+	// servers are the servers of the config, by name.
+	servers []serverConfig
+}
+
+// serverConfig is a server of either kind of config.
+type serverConfig struct {
+	Name string
+	// Server is the bundled server, empty for external servers.
+	Server   string
+	Command  string
+	URL      string
+	Args     []string
+	Env      map[string]string
+	Disabled bool
+}
+
+// Load reads and validates the config file of kind at path.
+func Load(kind, path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return Parse(kind, path, data)
+}
+
+// Parse validates data, the config file of kind at path. Errors are given as
+// path:line: message when their place in the file is known.
+func Parse(kind, path string, data []byte) (*Config, error) {
+	c := &Config{Kind: kind, Path: path}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, locateYAML(path, err)
+	}
+	c.root = &root
+
+	switch kind {
+	case Serve:
+		cfg, err := supervisor.ParseConfig(data)
+		if err != nil {
+			return nil, c.locate(err)
+		}
+		c.Value = cfg
+		for name, sc := range cfg.Servers {
+			c.servers = append(c.servers, serverConfig{
+				Name: name, Server: sc.Server, Command: sc.Command,
+				Args: sc.Args, Env: sc.Env, Disabled: sc.Disabled,
+			})
+		}
+	case Proxy:
+		cfg, err := proxy.ParseConfig(data)
+		if err != nil {
+			return nil, c.locate(err)
+		}
+		c.Value = cfg
+		for name, bc := range cfg.Servers {
+			c.servers = append(c.servers, serverConfig{
+				Name: name, Server: bc.Server, Command: bc.Command, URL: bc.URL,
+				Args: bc.Args, Env: bc.Env, Disabled: bc.Disabled,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown config kind %q; use %s or %s", kind, Serve, Proxy)
+	}
+	sort.Slice(c.servers, func(i, j int) bool { return c.servers[i].Name < c.servers[j].Name })
+	return c, nil
+}
+
+// Servers returns the number of servers of the config, and of those enabled.
+func (c *Config) Servers() (total, enabled int) {
+	for _, s := range c.servers {
+		if !s.Disabled {
+			enabled++
+		}
+	}
+	return len(c.servers), enabled
+}
+
+// yamlLine matches the line yaml reports syntax and type errors at.
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// locateYAML prefixes the syntax errors of the file at path with its name and line.
+func locateYAML(path string, err error) error {
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		return fmt.Errorf("%s:%s: %s", path, m[1], m[2])
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// locations map validation errors to the keys they are about, as paths of keys in
+// the file. $1 is replaced by the first group of the pattern, and the first path
+// found in the file gives the line.
+var locations = []struct {
+	pattern *regexp.Regexp
+	paths   [][]string
+}{
+	{regexp.MustCompile(`^servers \S+ and (\S+) both listen`), [][]string{{"servers", "$1", "listen"}}},
+	{regexp.MustCompile(`^mcpServers: server ([^\s:]+)`), [][]string{{"mcpServers", "$1"}}},
+	{regexp.MustCompile(`^server ([^\s:]+)`), [][]string{{"servers", "$1"}, {"mcpServers", "$1"}}},
+	{regexp.MustCompile(`^prompt "?([^\s:"]+)`), [][]string{{"prompts", "$1"}}},
+	{regexp.MustCompile(`^backoff`), [][]string{{"backoff"}}},
+	{regexp.MustCompile(`conflicts policy`), [][]string{{"conflicts"}}},
+}
+
+// locate prefixes err, returned by the parser of the config, with the name of the
+// file and the line it is about, when known.
+func (c *Config) locate(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		// Unknown fields and values of the wrong type, one per line
+		lines := make([]string, len(typeErr.Errors))
+		for i, e := range typeErr.Errors {
+			lines[i] = locateYAML(c.Path, errors.New(e)).Error()
+		}
+		return errors.New(strings.Join(lines, "\n"))
+	}
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		return fmt.Errorf("%s:%s: %s", c.Path, m[1], m[2])
+	}
+	for _, l := range locations {
+		m := l.pattern.FindStringSubmatch(err.Error())
+		if m == nil {
+			continue
+		}
+		for _, path := range l.paths {
+			keys := make([]string, len(path))
+			for i, key := range path {
+				if key == "$1" {
+					key = m[1]
+				}
+				keys[i] = key
+			}
+			if key := lookup(c.root, keys...); key != nil {
+				return fmt.Errorf("%s:%d: %w", c.Path, key.Line, err)
+			}
+		}
+		break
+	}
+	return fmt.Errorf("%s: %w", c.Path, err)
+}
+
+// lookup returns the key node at the path of keys in the document root, or nil.
+func lookup(root *yaml.Node, keys ...string) *yaml.Node {
+	node := root
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	var key *yaml.Node
+	for _, name := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		key, node = mappingKey(node, name)
+		if key == nil {
+			return nil
+		}
+	}
+	return key
+}
+
+// mappingKey returns the key called name of mapping and its value, or nils.
+func mappingKey(mapping *yaml.Node, name string) (key, value *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,146 @@
+package configcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that errors are reported with their line in the file
+func TestParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		kind   string
+		config string
+		err    string
+	}{
+		"syntax": {Serve, "servers:\n  fetch: [\n", "mcphost.yaml:2: did not find expected node content"},
+		"unknown field": {Serve, "servers:\n  fetch:\n    listen: a\n    bogus: 1\n",
+			"mcphost.yaml:4: field bogus not found in type supervisor.ServerConfig"},
+		"unknown server": {Serve, "servers:\n  fetch:\n    listen: a\n  other:\n    server: nope\n    listen: b\n",
+			`mcphost.yaml:4: server other: unknown bundled server "nope"`},
+		"same address": {Serve, "servers:\n  a:\n    server: time\n    listen: x\n  b:\n    server: time\n    listen: x\n",
+			"mcphost.yaml:7: servers a and b both listen on x"},
+		"backoff": {Serve, "backoff:\n  initial: -1s\nservers:\n  time:\n    listen: x\n",
+			"mcphost.yaml:1: backoff must be positive with max at least initial"},
+		"conflicts": {Proxy, "conflicts: maybe\nservers:\n  time: {}\n",
+			`mcphost.yaml:1: invalid conflicts policy "maybe"; use priority or error`},
+		"prompt": {Proxy, "servers:\n  time: {}\nprompts:\n  greet:\n    description: x\n",
+			"mcphost.yaml:4: prompt greet: no template or messages"},
+		"no servers": {Serve, "status: :8090\n", "mcphost.yaml: no servers configured"},
+		"kind":       {"chat", "servers: {}\n", `unknown config kind "chat"; use serve or proxy`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tt.kind, "mcphost.yaml", []byte(tt.config))
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+// Test that serve falls back to the config of the user without mcphost.yaml
+func TestDefaultPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	assert.Equal(t, filepath.Join(home, ".mcphost", "config.yaml"), DefaultPath(Serve))
+	assert.Equal(t, "mcphost-proxy.yaml", DefaultPath(Proxy))
+}
+
+// Test the effective configuration, with defaults, merged servers and masked secrets
+func TestExplain(t *testing.T) {
+	t.Setenv("MCPHOST_FETCH_TIMEOUT", "5")
+	t.Setenv("MCPHOST_FETCH_USER_AGENT", "bot")
+	flagsFile := filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(flagsFile, []byte("fetch:\n  user-agent: file\n  max-body-size: 1024\n"), 0o600))
+	t.Setenv("MCPHOST_CONFIG_FILE", flagsFile)
+	config, err := Parse(Serve, "mcphost.yaml", []byte(`
+servers:
+  fetch:
+    listen: 127.0.0.1:8081
+    args: ["-timeout", "10", "-api-key=abc"]
+  custom:
+    command: /usr/local/bin/custom
+    env:
+      API_KEY: secret
+mcpServers:
+  sqlite:
+    command: uvx
+    args: [mcp-server-sqlite]
+`))
+	require.NoError(t, err)
+	total, enabled := config.Servers()
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 3, enabled)
+
+	var out strings.Builder
+	require.NoError(t, config.Explain(&out))
+	explained := out.String()
+	for _, want := range []string{
+		"backoff: # default\n  initial: 1s\n  max: 30s\n",
+		"    server: fetch # default\n",
+		"    args: [-timeout, \"10\", '-api-key=[REDACTED]']\n",
+		"      API_KEY: '[REDACTED]'\n",
+		"  sqlite: # from mcpServers\n    command: uvx\n    args: [mcp-server-sqlite]\n    restart: on-failure # default\n",
+		"  -timeout=10          args (overrides environment MCPHOST_FETCH_TIMEOUT)\n",
+		"  -api-key=[REDACTED]  args\n",
+		"  -user-agent=bot      environment MCPHOST_FETCH_USER_AGENT (overrides config file " + flagsFile + ")\n",
+		"  -max-body-size=1024  config file " + flagsFile + "\n",
+	} {
+		assert.Contains(t, explained, want)
+	}
+	assert.NotContains(t, explained, "abc")
+	assert.NotContains(t, explained, "API_KEY: secret")
+	assert.NotContains(t, explained, "mcpServers:")
+}
+
+// Test that the upstreams of the servers are checked
+func TestCheck(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no credentials", http.StatusUnauthorized)
+	}))
+	defer upstream.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	t.Setenv("MCPHOST_TELEGRAM_API_URL", closed.URL)
+	config, err := Parse(Proxy, "mcphost-proxy.yaml", []byte(`
+servers:
+  news:
+    server: hackernews
+    args: ["-api-url", "`+upstream.URL+`", "-algolia-url=`+upstream.URL+`"]
+  telegram:
+    args: ["-token", "env:MCPHOST_TEST_UNSET"]
+  remote:
+    url: `+upstream.URL+`/sse
+  missing:
+    command: mcphost-no-such-command
+  time:
+    disabled: true
+    command: mcphost-disabled
+`))
+	require.NoError(t, err)
+
+	results := config.Check(context.Background(), 5*time.Second)
+	require.Len(t, results, 6)
+	byTarget := make(map[string]Result)
+	for _, r := range results {
+		byTarget[r.Server+" "+r.Target] = r
+	}
+
+	r := byTarget["news "+upstream.URL]
+	assert.NoError(t, r.Err)
+	assert.Equal(t, "HTTP 401 Unauthorized", r.Detail)
+	assert.NoError(t, byTarget["remote "+upstream.URL+"/sse"].Err)
+	assert.Error(t, byTarget["telegram "+closed.URL].Err)
+	assert.Error(t, byTarget["missing mcphost-no-such-command"].Err)
+	assert.ErrorContains(t, byTarget["telegram -token env:MCPHOST_TEST_UNSET"].Err, "environment variable MCPHOST_TEST_UNSET is not set")
+}
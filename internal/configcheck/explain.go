@@ -0,0 +1,281 @@
+package configcheck
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/audit"
+	"github.com/mark3labs/mcphost/internal/config"
+)
+
+// Sources of the flags of bundled servers, by precedence.
+const (
+	sourceArgs        = "args"
+	sourceConfigEnv   = "env in the config"
+	sourceEnvironment = "environment"
+	sourceFlagsFile   = "config file"
+)
+
+// redactor masks the values of flags and environment variables whose names look
+// secret. key is masked too, for flags such as -tls-key.
+var redactor = audit.NewRedactor("key")
+
+// mask returns value, masked if name looks secret.
+func mask(name, value string) string {
+	return redactor.Redact(map[string]interface{}{name: value})[name].(string)
+}
+
+// setting is a flag of a bundled server set by the config or the environment.
+type setting struct {
+	Flag   string
+	Value  string
+	Source string
+	// Overrides lists where else the flag is set, with a lower precedence.
+	Overrides []string
+}
+
+// flag returns the value of a flag of the bundled server s set by the config or the
+// environment.
+func (s serverConfig) flag(name string) (string, bool) {
+	for _, st := range s.settings() {
+		if st.Flag == name {
+			return st.Value, true
+		}
+	}
+	return "", false
+}
+
+// settings returns the flags of the bundled server s set by its args, by its env in
+// the config, by the environment of mcphost or by the config file of the flags, as
+// config.Load reads them: args win over the environment of the server, the config
+// adds to the environment, and the environment wins over the file.
+func (s serverConfig) settings() []setting {
+	var settings []setting
+	index := make(map[string]int)
+	add := func(flag, value, source string) {
+		if i, ok := index[flag]; ok {
+			settings[i].Overrides = append(settings[i].Overrides, source)
+			return
+		}
+		index[flag] = len(settings)
+		settings = append(settings, setting{Flag: flag, Value: value, Source: source})
+	}
+
+	for _, arg := range parseArgs(s.Args) {
+		add(arg[0], arg[1], sourceArgs)
+	}
+	prefix := config.EnvName(s.Server) + "_"
+	fromEnv := func(env map[string]string, source string) {
+		names := make([]string, 0, len(env))
+		for name := range env {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			flag := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, prefix), "_", "-"))
+			add(flag, env[name], source+" "+name)
+		}
+	}
+	fromEnv(s.Env, sourceConfigEnv)
+	environ := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			environ[name] = value
+		}
+	}
+	fromEnv(environ, sourceEnvironment)
+	// An invalid file fails the server when it starts
+	path := config.FilePath()
+	if sections, err := config.LoadFile(path); err == nil {
+		section := sections[s.Server]
+		names := make([]string, 0, len(section))
+		for name := range section {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(name, section[name], sourceFlagsFile+" "+path)
+		}
+	}
+	return settings
+}
+
+// parseArgs returns the flags of args as pairs of name and value, the way the flag
+// package reads them: up to the first argument not a flag, with -name=value, -name
+// value or, for boolean flags, -name.
+func parseArgs(args []string) [][2]string {
+	var flags [][2]string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name, value, ok := strings.Cut(name, "="); ok {
+			flags = append(flags, [2]string{name, value})
+			continue
+		}
+		value := "true"
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			value = args[i+1]
+			i++
+		}
+		flags = append(flags, [2]string{name, value})
+	}
+	return flags
+}
+
+// Explain writes the effective configuration to w: the config with its defaults filled
+// in and its secrets masked, then the flags of the bundled servers set by the config
+// or the environment and where their values come from.
+func (c *Config) Explain(w io.Writer) error {
+	node, err := c.effective()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "# Effective configuration of %s for mcphost %s. Settings marked default are\n", c.Path, c.Kind)
+	fmt.Fprintf(w, "# not in the file; mcpServers are merged into servers and secrets are masked.\n")
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nFlags of the bundled servers, by precedence: args, then env in the config, then\n")
+	fmt.Fprintf(w, "the environment of mcphost. Other flags have their defaults, see mcphost run <server> -help.\n")
+	for _, s := range c.servers {
+		if s.Server == "" || s.Disabled {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%s)\n", s.Name, s.Server)
+		settings := s.settings()
+		if len(settings) == 0 {
+			fmt.Fprintf(w, "  all defaults\n")
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, st := range settings {
+			source := st.Source
+			if len(st.Overrides) > 0 {
+				source += " (overrides " + strings.Join(st.Overrides, ", ") + ")"
+			}
+			fmt.Fprintf(tw, "  -%s=%s\t%s\n", st.Flag, mask(st.Flag, st.Value), source)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// effective returns the config as a YAML node, with comments on the settings not in
+// the file and secrets masked.
+func (c *Config) effective() (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(c.Value); err != nil {
+		return nil, fmt.Errorf("failed to encode the config: %w", err)
+	}
+	var file *yaml.Node
+	if c.root != nil && len(c.root.Content) > 0 {
+		file = c.root.Content[0]
+	}
+	annotate(&node, file, nil)
+	if _, servers := mappingKey(&node, "servers"); servers != nil {
+		for i := 1; i < len(servers.Content); i += 2 {
+			maskServer(servers.Content[i])
+		}
+	}
+	return &node, nil
+}
+
+// annotate comments the settings of node, the mapping at path in the effective config,
+// missing from file, the same mapping in the file as written, and removes those that
+// are empty. Servers missing from the file come from mcpServers.
+func annotate(node, file *yaml.Node, path []string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if len(path) == 0 && (key.Value == "mcpServers" || key.Value == "mcpServersFile") {
+			continue
+		}
+		var fileValue *yaml.Node
+		if file != nil && file.Kind == yaml.MappingNode {
+			_, fileValue = mappingKey(file, key.Value)
+		}
+		switch {
+		case fileValue != nil:
+			annotate(value, fileValue, append(path, key.Value))
+		case empty(value):
+			continue
+		case len(path) == 1 && path[0] == "servers":
+			key.LineComment = "from mcpServers"
+			annotate(value, nil, []string{"mcpServers", key.Value})
+		case len(path) > 0 && path[0] == "mcpServers" && (key.Value == "command" || key.Value == "args" || key.Value == "env"):
+			// The settings of the mcpServers format
+		default:
+			key.LineComment = "default"
+		}
+		if key.Value == "args" && value.Kind == yaml.SequenceNode {
+			value.Style = yaml.FlowStyle
+		}
+		content = append(content, key, value)
+	}
+	node.Content = content
+}
+
+// empty reports whether node is an unset setting.
+func empty(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		return node.Tag == "!!null" || node.Value == "" || node.Value == "false" || node.Value == "0"
+	}
+	return false
+}
+
+// maskServer masks the secrets of a server of the effective config: the values of
+// secret flags in its args and of secret variables in its env, and the password of
+// its URL.
+func maskServer(server *yaml.Node) {
+	if _, args := mappingKey(server, "args"); args != nil {
+		for i := 0; i < len(args.Content); i++ {
+			arg := args.Content[i].Value
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			name := strings.TrimLeft(arg, "-")
+			if name, value, ok := strings.Cut(name, "="); ok {
+				args.Content[i].Value = arg[:len(arg)-len(value)] + mask(name, value)
+			} else if i+1 < len(args.Content) && !strings.HasPrefix(args.Content[i+1].Value, "-") {
+				args.Content[i+1].Value = mask(name, args.Content[i+1].Value)
+				i++
+			}
+		}
+	}
+	if _, env := mappingKey(server, "env"); env != nil {
+		for i := 0; i+1 < len(env.Content); i += 2 {
+			env.Content[i+1].Value = mask(env.Content[i].Value, env.Content[i+1].Value)
+		}
+	}
+	if _, u := mappingKey(server, "url"); u != nil {
+		if parsed, err := url.Parse(u.Value); err == nil && parsed.User != nil {
+			u.Value = parsed.Redacted()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package configcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/secretref"
+)
+
+// upstream is an API a bundled server calls: the flag setting its base URL, if any,
+// and the default URL.
+type upstream struct {
+	flag string
+	url  string
+}
+
+// upstreams are the APIs of the bundled servers, by server, with the defaults of
+// their flags.
+var upstreams = map[string][]upstream{
+	"discord":       {{"api-url", "https://discord.com/api/v10"}},
+	"geocoding":     {{"nominatim-url", "https://nominatim.openstreetmap.org"}},
+	"googlesearch":  {{"api-url", "https://www.googleapis.com/customsearch/v1"}},
+	"hackernews":    {{"api-url", "https://hacker-news.firebaseio.com/v0"}, {"algolia-url", "https://hn.algolia.com/api/v1"}},
+	"homeassistant": {{"url", "http://homeassistant.local:8123"}},
+	"papers":        {{"arxiv-url", "http://export.arxiv.org/api"}, {"s2-url", "https://api.semanticscholar.org/graph/v1"}},
+	"reddit":        {{"public-url", "https://www.reddit.com"}},
+	"spotify":       {{"api-url", "https://api.spotify.com/v1"}, {"accounts-url", "https://accounts.spotify.com"}},
+	"telegram":      {{"api-url", "https://api.telegram.org"}},
+}
+
+// Result is the outcome of checking an upstream.
+type Result struct {
+	// Server is the server of the config the upstream belongs to.
+	Server string
+	// Target is the URL or the command checked.
+	Target string
+	// Proxy is the HTTP proxy requests to Target go through, if any.
+	Proxy string
+	// Detail describes the response, e.g. HTTP 404 Not Found, or where the command is.
+	Detail string
+	Err    error
+
+	// secret is the reference to a secret Target names, to resolve.
+	secret string
+}
+
+// Check checks that the upstreams of the enabled servers can be reached, waiting at
+// most timeout for each: the APIs of the bundled servers, at their default URL or the
+// one set by the config or the environment, the SSE endpoints of proxied servers and
+// the commands of external servers. References to secrets in the args and env of the
+// servers are resolved too, see package secretref. Any HTTP response counts, since APIs refuse
+// requests without credentials; failing to connect, directly or through the HTTP
+// proxy of the environment, is an error.
+func (c *Config) Check(ctx context.Context, timeout time.Duration) []Result {
+	var results []Result
+	for _, s := range c.servers {
+		if s.Disabled {
+			continue
+		}
+		switch {
+		case s.URL != "":
+			results = append(results, Result{Server: s.Name, Target: s.URL})
+		case s.Command != "":
+			results = append(results, Result{Server: s.Name, Target: s.Command})
+		default:
+			for _, u := range upstreams[s.Server] {
+				target := u.url
+				if value, ok := s.flag(u.flag); ok && u.flag != "" {
+					target = value
+				}
+				results = append(results, Result{Server: s.Name, Target: target})
+			}
+		}
+		for _, arg := range parseArgs(s.Args) {
+			if secretref.IsRef(arg[1]) {
+				results = append(results, Result{Server: s.Name, Target: "-" + arg[0] + " " + arg[1], secret: arg[1]})
+			}
+		}
+		names := make([]string, 0, len(s.Env))
+		for name := range s.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := s.Env[name]; secretref.IsRef(value) {
+				results = append(results, Result{Server: s.Name, Target: name + "=" + value, secret: value})
+			}
+		}
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		// The first response tells the upstream is there
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *Result) {
+			defer wg.Done()
+			if r.secret != "" {
+				if _, err := secretref.Resolve(r.secret); err != nil {
+					r.Err = err
+				} else {
+					r.Detail = "resolved"
+				}
+			} else if isURL(r.Target) {
+				checkURL(ctx, client, r)
+			} else if path, err := exec.LookPath(r.Target); err != nil {
+				r.Err = err
+			} else {
+				r.Detail = path
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// isURL reports whether target is an HTTP URL rather than a command.
+func isURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// checkURL requests the URL of r with client and records the outcome in r.
+func checkURL(ctx context.Context, client *http.Client, r *Result) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.Target, nil)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r.Target = req.URL.Redacted()
+	if proxyURL, err := http.ProxyFromEnvironment(req); err == nil && proxyURL != nil {
+		r.Proxy = proxyURL.Redacted()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	// The body of an SSE endpoint never ends
+	resp.Body.Close()
+	r.Detail = fmt.Sprintf("HTTP %s", resp.Status)
+}
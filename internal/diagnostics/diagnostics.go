@@ -0,0 +1,221 @@
+// Package diagnostics helps to find out why a long-running server process grows or
+// stalls: it serves pprof profiles, expvar counters and goroutine and heap snapshots
+// on a separate debug address, and reports runtime statistics to clients through the
+// getRuntimeStats tool.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolName is the name of the runtime statistics tool.
+const ToolName = "getRuntimeStats"
+
+// shutdownTimeout bounds stopping the debug server.
+const shutdownTimeout = 5 * time.Second
+
+var (
+	started = time.Now()
+
+	// Tool calls and the errors among them by server/tool, published as expvar
+	// counters; servers running in one process share them.
+	toolCalls  = expvar.NewMap("toolCalls")
+	toolErrors = expvar.NewMap("toolErrors")
+)
+
+// Middleware returns tool middleware counting the calls to the tools of the server
+// called name, and the calls failing or returning an error result.
+func Middleware(name string) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			key := name + "/" + req.Params.Name
+			toolCalls.Add(key, 1)
+			result, err := next(ctx, req)
+			if err != nil || (result != nil && result.IsError) {
+				toolErrors.Add(key, 1)
+			}
+			return result, err
+		}
+	}
+}
+
+// Handler returns the handler of the debug endpoints: pprof under /debug/pprof/,
+// expvar counters, including memstats, at /debug/vars, and /debug/snapshot, which
+// writes a goroutine dump and a heap profile to dir on POST.
+func Handler(dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "POST to take a snapshot", http.StatusMethodNotAllowed)
+			return
+		}
+		snapshot, err := TakeSnapshot(dir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Wrote goroutine dump %s and heap profile %s", snapshot.Goroutines, snapshot.Heap)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snapshot)
+	})
+	return mux
+}
+
+// Snapshot are the files a snapshot was written to.
+type Snapshot struct {
+	Goroutines string `json:"goroutines"`
+	Heap       string `json:"heap"`
+}
+
+// TakeSnapshot writes the stacks of all goroutines, as text, and a heap profile, for
+// go tool pprof, to files named after the time in dir.
+func TakeSnapshot(dir string) (Snapshot, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	stamp := time.Now().UTC().Format("20060102T150405.000Z")
+	snapshot := Snapshot{
+		Goroutines: filepath.Join(dir, "goroutines-"+stamp+".txt"),
+		Heap:       filepath.Join(dir, "heap-"+stamp+".pprof"),
+	}
+	if err := writeProfile(snapshot.Goroutines, "goroutine", 2); err != nil {
+		return Snapshot{}, err
+	}
+	runtime.GC() // the heap profile shows the state of the last GC
+	if err := writeProfile(snapshot.Heap, "heap", 0); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func writeProfile(file, profile string, debug int) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	if err := rpprof.Lookup(profile).WriteTo(f, debug); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s profile: %w", profile, err)
+	}
+	return f.Close()
+}
+
+// Listen serves the debug endpoints on addr until the returned function is called. An
+// address that is not a loopback one is served with a warning, as the endpoints have
+// no access control.
+func Listen(addr, dir string) (func() error, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for debugging on %s: %w", addr, err)
+	}
+	if ip, ok := ln.Addr().(*net.TCPAddr); !ok || !ip.IP.IsLoopback() {
+		log.Printf("Warning: Debug endpoints on %s are reachable from other hosts and have no access control", ln.Addr())
+	}
+	srv := &http.Server{Handler: Handler(dir), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error: Debug server failed: %v", err)
+		}
+	}()
+	log.Printf("Serving pprof, expvar and snapshots on http://%s/debug/", ln.Addr())
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}, nil
+}
+
+// Stats are runtime statistics of the process.
+type Stats struct {
+	Uptime     string `json:"uptime"`
+	GoVersion  string `json:"goVersion"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	Goroutines int    `json:"goroutines"`
+	// Memory in bytes: allocated heap objects, heap spans in use, and all the memory
+	// obtained from the OS.
+	HeapAlloc   uint64 `json:"heapAllocBytes"`
+	HeapInuse   uint64 `json:"heapInuseBytes"`
+	HeapObjects uint64 `json:"heapObjects"`
+	Sys         uint64 `json:"sysBytes"`
+	NumGC       uint32 `json:"numGC"`
+	// LastGCPause is the duration of the last stop-the-world GC pause.
+	LastGCPause string `json:"lastGCPause"`
+	// ToolCalls and ToolErrors count the calls to the tools of the server, by tool.
+	ToolCalls  map[string]int64 `json:"toolCalls"`
+	ToolErrors map[string]int64 `json:"toolErrors,omitempty"`
+}
+
+// ReadStats returns the runtime statistics of the process and the tool counters of
+// the server called name.
+func ReadStats(name string) Stats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return Stats{
+		Uptime:      time.Since(started).Round(time.Second).String(),
+		GoVersion:   runtime.Version(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   m.HeapAlloc,
+		HeapInuse:   m.HeapInuse,
+		HeapObjects: m.HeapObjects,
+		Sys:         m.Sys,
+		NumGC:       m.NumGC,
+		LastGCPause: time.Duration(m.PauseNs[(m.NumGC+255)%256]).String(),
+		ToolCalls:   counters(toolCalls, name),
+		ToolErrors:  counters(toolErrors, name),
+	}
+}
+
+// counters returns the counters of m for the server called name, by tool.
+func counters(m *expvar.Map, name string) map[string]int64 {
+	counts := make(map[string]int64)
+	m.Do(func(kv expvar.KeyValue) {
+		if tool, ok := strings.CutPrefix(kv.Key, name+"/"); ok {
+			counts[tool] = kv.Value.(*expvar.Int).Value()
+		}
+	})
+	return counts
+}
+
+// Tool returns the definition of the getRuntimeStats tool.
+func Tool() mcp.Tool {
+	return mcp.NewTool(ToolName,
+		mcp.WithDescription("Reports runtime statistics of the server process: uptime, goroutines, heap and system memory, garbage collections and the calls to each tool, to diagnose memory growth and stalls"),
+	)
+}
+
+// ToolHandler returns the handler of the getRuntimeStats tool of the server called
+// name.
+func ToolHandler(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data, err := json.MarshalIndent(ReadStats(name), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode runtime statistics: %w", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
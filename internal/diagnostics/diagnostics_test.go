@@ -0,0 +1,80 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test that tool calls are counted and reported by getRuntimeStats
+func TestStats(t *testing.T) {
+	handler := Middleware("stats-test")(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch req.Params.Name {
+		case "fail":
+			return nil, errors.New("failed")
+		case "refuse":
+			return mcp.NewToolResultError("refused"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	for _, tool := range []string{"ok", "ok", "fail", "refuse"} {
+		handler(context.Background(), mcptest.NewCallToolRequest(tool, nil))
+	}
+
+	result, err := ToolHandler("stats-test")(context.Background(), mcptest.NewCallToolRequest(ToolName, nil))
+	require.NoError(t, err)
+	var stats Stats
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &stats))
+	assert.Equal(t, map[string]int64{"ok": 2, "fail": 1, "refuse": 1}, stats.ToolCalls)
+	assert.Equal(t, map[string]int64{"fail": 1, "refuse": 1}, stats.ToolErrors)
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAlloc)
+	assert.Empty(t, ReadStats("other").ToolCalls, "Counters are kept per server")
+}
+
+// Test the debug endpoints
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(Handler(dir))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/debug/vars")
+	require.NoError(t, err)
+	var vars map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&vars))
+	resp.Body.Close()
+	assert.Contains(t, vars, "toolCalls")
+	assert.Contains(t, vars, "memstats")
+
+	resp, err = http.Get(srv.URL + "/debug/pprof/goroutine?debug=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/debug/snapshot")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(srv.URL+"/debug/snapshot", "", nil)
+	require.NoError(t, err)
+	var snapshot Snapshot
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	resp.Body.Close()
+	goroutines, err := os.ReadFile(snapshot.Goroutines)
+	require.NoError(t, err)
+	assert.Contains(t, string(goroutines), "goroutine ")
+	info, err := os.Stat(snapshot.Heap)
+	require.NoError(t, err)
+	assert.Positive(t, info.Size())
+}
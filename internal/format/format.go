@@ -0,0 +1,138 @@
+// Package format renders the results of tools as text, Markdown or JSON, chosen by
+// the client with the format parameter of the tool, so that the same tool serves
+// people reading its output and programs parsing it.
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Format is a format of tool results.
+type Format string
+
+// Formats of tool results.
+const (
+	// Text is plain text for people, the default.
+	Text Format = "text"
+	// Markdown is Markdown, with tables for lists of records.
+	Markdown Format = "markdown"
+	// JSON is indented JSON for programs.
+	JSON Format = "json"
+)
+
+// Param returns the format parameter of a tool rendering its results in formats,
+// the first being the default.
+func Param(formats ...Format) mcp.ToolOption {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return mcp.WithString("format",
+		mcp.Description(fmt.Sprintf("Output format (default: %s)", formats[0])),
+		mcp.Enum(names...),
+	)
+}
+
+// Output is the result of a tool in each format.
+type Output struct {
+	// Data is rendered as JSON.
+	Data interface{}
+	// Text is the text rendering.
+	Text string
+	// Markdown is the Markdown rendering, Text if empty.
+	Markdown string
+}
+
+// Render returns o in format f, text if f is empty.
+func Render(f Format, o Output) (string, error) {
+	switch f {
+	case "", Text:
+		return o.Text, nil
+	case Markdown:
+		if o.Markdown == "" {
+			return o.Text, nil
+		}
+		return o.Markdown, nil
+	case JSON:
+		data, err := json.MarshalIndent(o.Data, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshaling result: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unsupported format: %s", f)
+}
+
+// Result returns the tool result of o in format f.
+func Result(f Format, o Output) (*mcp.CallToolResult, error) {
+	text, err := Render(f, o)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// Table is a list of records with the same columns.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Text renders t as aligned columns under a header line.
+func (t Table) Text() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.Columns, "\t"))
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Markdown renders t as a Markdown table. Cells are kept on one line and their pipes
+// escaped.
+func (t Table) Markdown() string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			cell = strings.Join(strings.Fields(cell), " ")
+			b.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(t.Columns)
+	separator := make([]string, len(t.Columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Records returns the rows of t as objects keyed by column, for JSON.
+func (t Table) Records() []map[string]string {
+	records := make([]map[string]string, len(t.Rows))
+	for i, row := range t.Rows {
+		records[i] = make(map[string]string, len(t.Columns))
+		for j, column := range t.Columns {
+			if j < len(row) {
+				records[i][column] = row[j]
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,68 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+var table = Table{
+	Columns: []string{"Name", "Note"},
+	Rows: [][]string{
+		{"alpha", "first | one"},
+		{"b", "two\nlines"},
+	},
+}
+
+// Test rendering tables as aligned text
+func TestTableText(t *testing.T) {
+	assert.Equal(t, "Name   Note\nalpha  first | one\nb      two lines", table.Text())
+}
+
+// Test rendering tables as Markdown, with pipes escaped
+func TestTableMarkdown(t *testing.T) {
+	assert.Equal(t, "| Name | Note |\n| --- | --- |\n| alpha | first \\| one |\n| b | two lines |", table.Markdown())
+}
+
+// Test rendering tables as records
+func TestTableRecords(t *testing.T) {
+	assert.Equal(t, []map[string]string{
+		{"Name": "alpha", "Note": "first | one"},
+		{"Name": "b", "Note": "two\nlines"},
+	}, table.Records())
+}
+
+// Test rendering outputs in each format
+func TestRender(t *testing.T) {
+	o := Output{Data: map[string]int{"count": 2}, Text: "2 items"}
+	for f, want := range map[Format]string{
+		"":       "2 items",
+		Text:     "2 items",
+		Markdown: "2 items",
+		JSON:     "{\n  \"count\": 2\n}",
+	} {
+		got, err := Render(f, o)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, f)
+	}
+
+	o.Markdown = "**2** items"
+	result, err := Result(Markdown, o)
+	require.NoError(t, err)
+	assert.Equal(t, "**2** items", mcptest.ResultText(result))
+
+	_, err = Render("xml", o)
+	assert.EqualError(t, err, "unsupported format: xml")
+}
+
+// Test that the format parameter lists the formats with the first as default
+func TestParam(t *testing.T) {
+	tool := mcp.NewTool("list", Param(Text, JSON))
+	property := tool.InputSchema.Properties["format"].(map[string]interface{})
+	assert.Equal(t, "Output format (default: text)", property["description"])
+	assert.Equal(t, []string{"text", "json"}, property["enum"])
+}
@@ -0,0 +1,170 @@
+// Package health serves the liveness and readiness endpoints probed by container
+// orchestrators, running the readiness checks the servers register.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Endpoint paths.
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Statuses of a report or check.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+const (
+	// checkTimeout bounds one run of a check.
+	checkTimeout = 5 * time.Second
+	// cacheTTL is how long a check result is reused, so frequent probes do not spend
+	// downstream API quota.
+	cacheTTL = 30 * time.Second
+)
+
+// Check reports whether something a server depends on, such as a downstream API, is
+// usable.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Report is the body of the readiness endpoint.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// OK reports whether all checks passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type registered struct {
+	name  string
+	check Check
+
+	mu     sync.Mutex
+	result CheckResult
+}
+
+var (
+	mu     sync.RWMutex
+	checks = make(map[*server.MCPServer][]*registered)
+)
+
+// Register adds a readiness check called name to s. The server is ready while all
+// its checks pass.
+func Register(s *server.MCPServer, name string, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[s] = append(checks[s], &registered{name: name, check: check})
+}
+
+// Ready runs the checks of s concurrently, reusing results younger than cacheTTL.
+func Ready(ctx context.Context, s *server.MCPServer) Report {
+	mu.RLock()
+	regs := checks[s]
+	mu.RUnlock()
+
+	report := Report{Status: StatusOK}
+	if len(regs) == 0 {
+		return report
+	}
+	report.Checks = make(map[string]CheckResult, len(regs))
+	results := make([]CheckResult, len(regs))
+	var wg sync.WaitGroup
+	for i, reg := range regs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = reg.run(ctx)
+		}()
+	}
+	wg.Wait()
+	for i, reg := range regs {
+		report.Checks[reg.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (r *registered) run(ctx context.Context) CheckResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.result.Time.IsZero() && time.Since(r.result.Time) < cacheTTL {
+		return r.result
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	r.result = CheckResult{Status: StatusOK, Time: time.Now()}
+	if err := r.check(ctx); err != nil {
+		r.result.Status, r.result.Error = StatusFail, err.Error()
+	}
+	return r.result
+}
+
+// Handler serves the liveness and readiness endpoints of s and passes other requests
+// on to next.
+func Handler(s *server.MCPServer, next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+		WriteReport(w, Report{Status: StatusOK})
+	})
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		WriteReport(w, Ready(r.Context(), s))
+	})
+	mux.Handle("/", next)
+	return mux
+}
+
+// WriteReport writes report as JSON, with status 503 if it failed.
+func WriteReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.OK() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Warning: Failed to write health report: %v", err)
+	}
+}
+
+// Reachable returns a check passing while url answers requests made with client
+// without a server error. Client errors such as 401 are left to checks knowing the
+// API.
+func Reachable(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+		}
+		return nil
+	}
+}
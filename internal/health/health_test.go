@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the endpoints and that check results are reused
+func TestHandler(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	calls := 0
+	Register(s, "config", func(ctx context.Context) error { return nil })
+	Register(s, "api", func(ctx context.Context) error {
+		calls++
+		return errors.New("unreachable")
+	})
+	handler := Handler(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	for range 2 {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", ReadinessPath, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["config"].Status)
+		assert.Equal(t, "unreachable", report.Checks["api"].Error)
+	}
+	assert.Equal(t, 1, calls)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/sse", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	// A server without checks is ready
+	assert.True(t, Ready(context.Background(), server.NewMCPServer("other", "1.0.0")).OK())
+}
+
+// Test the reachability check
+func TestReachable(t *testing.T) {
+	status := http.StatusUnauthorized
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer api.Close()
+
+	check := Reachable(api.Client(), api.URL)
+	assert.NoError(t, check(context.Background()))
+	status = http.StatusBadGateway
+	assert.ErrorContains(t, check(context.Background()), "502 Bad Gateway")
+	api.Close()
+	assert.Error(t, check(context.Background()))
+}
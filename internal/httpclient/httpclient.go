@@ -0,0 +1,249 @@
+// Package httpclient creates the HTTP clients the servers call upstream APIs with,
+// configured the same way: timeout, proxy, TLS, User-Agent, response size limit,
+// retries, the URLs allowed, the routes of the upstreams and a hook observing the
+// requests.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/budget"
+	"github.com/mark3labs/mcphost/internal/resilience"
+	"github.com/mark3labs/mcphost/internal/upstream"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// baseTransport is the transport of clients with their own proxy or TLS settings,
+// cloned from the default one before anything wraps it. Both check the addresses
+// they dial for clients with a Guard.
+var baseTransport = urlguard.Protect(http.DefaultTransport.(*http.Transport))
+
+// Options configure a client.
+type Options struct {
+	// Timeout bounds requests, reading the response included; 0 for no limit.
+	Timeout time.Duration
+	// UserAgent is sent with the requests that do not set one.
+	UserAgent string
+	// MaxBodySize cuts response bodies to this many bytes; 0 for no limit.
+	MaxBodySize int64
+	// Retries is how many times failed idempotent requests are retried, with
+	// exponential backoff, on network errors and 429 and 5xx responses.
+	Retries int
+
+	// Proxy is the proxy of all requests, instead of the one of HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY.
+	Proxy *url.URL
+	// TLS replaces the default TLS settings.
+	TLS *tls.Config
+
+	// Guard, if set, is the policy of the URLs requested, redirects included.
+	// Requests through a proxy have their URL checked, but not the addresses it
+	// dials.
+	Guard *urlguard.Policy
+	// Routes redirect the requests to upstream APIs, e.g. to a gateway, before
+	// Guard checks them.
+	Routes upstream.Routes
+	// Observe, if set, is called when each request completes.
+	Observe func(Request)
+}
+
+// Request describes a completed request for Options.Observe.
+type Request struct {
+	Method   string
+	Host     string
+	Status   int // 0 if the request failed
+	Err      error
+	Duration time.Duration
+}
+
+// New returns a client configured by o. Requests are bounded by the time left to the
+// tool call of their context, see package budget. Without Proxy and TLS, requests go through
+// http.DefaultTransport as it is when they are sent, so that tracing, -record,
+// -replay and the -retry, -circuit-breaker and -bulkhead settings of the server
+// apply to them; with them, the client has a transport of its own.
+func New(o Options) *http.Client {
+	t := &transport{options: o}
+	if o.Proxy != nil || o.TLS != nil {
+		t.own = baseTransport.Clone()
+		if o.Proxy != nil {
+			t.own.Proxy = http.ProxyURL(o.Proxy)
+		}
+		if o.TLS != nil {
+			t.own.TLSClientConfig = o.TLS
+		}
+	}
+	var rt http.RoundTripper = t
+	if o.Retries > 0 {
+		rt = resilience.New(resilience.Config{Retries: map[string]int{resilience.AnyUpstream: o.Retries}}, rt)
+	}
+	return &http.Client{Transport: rt, Timeout: o.Timeout}
+}
+
+// transport applies Options to requests.
+type transport struct {
+	options Options
+	// own is the transport of the proxy and TLS settings, if any
+	own *http.Transport
+}
+
+// base returns the transport sending the requests.
+func (t *transport) base() http.RoundTripper {
+	if t.own != nil {
+		return t.own
+	}
+	return http.DefaultTransport
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = t.options.Routes.Request(req)
+	// The deadline of the context is that of the tool call, unless it is the one
+	// http.Client sets for Options.Timeout
+	var cancel context.CancelFunc
+	if deadline, ok := req.Context().Deadline(); ok && (t.options.Timeout == 0 || deadline.Before(time.Now().Add(t.options.Timeout-budget.Reserve))) {
+		ctx, release, err := budget.WithTimeout(req.Context(), 0)
+		if err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		req, cancel = req.WithContext(ctx), release
+	}
+	if guard := t.options.Guard; guard != nil {
+		if err := guard.CheckURL(req.URL); err != nil {
+			closeBody(req)
+			if cancel != nil {
+				cancel()
+			}
+			return nil, err
+		}
+		if !t.proxied(req) {
+			req = req.WithContext(guard.WithDialCheck(req.Context(), req.URL))
+		}
+	}
+	if t.options.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.options.UserAgent)
+	}
+	start := time.Now()
+	resp, err := t.base().RoundTrip(req)
+	if t.options.Observe != nil {
+		r := Request{Method: req.Method, Host: req.URL.Host, Err: err, Duration: time.Since(start)}
+		if resp != nil {
+			r.Status = resp.StatusCode
+		}
+		t.options.Observe(r)
+	}
+	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
+		return nil, err
+	}
+	if t.options.MaxBodySize > 0 || cancel != nil {
+		var body io.Reader = resp.Body
+		if t.options.MaxBodySize > 0 {
+			body = io.LimitReader(resp.Body, t.options.MaxBodySize)
+		}
+		resp.Body = &limitedBody{Reader: body, body: resp.Body, cancel: cancel}
+	}
+	return resp, nil
+}
+
+// proxied reports whether req is sent through a proxy.
+func (t *transport) proxied(req *http.Request) bool {
+	proxy := http.ProxyFromEnvironment
+	if t.own != nil {
+		proxy = t.own.Proxy
+	}
+	if proxy == nil {
+		return false
+	}
+	u, err := proxy(req)
+	return err == nil && u != nil
+}
+
+// limitedBody is a response body cut to the maximum size, which releases the
+// context bounding its request, if any, once closed.
+type limitedBody struct {
+	io.Reader
+	body   io.Closer
+	cancel context.CancelFunc
+}
+
+func (b *limitedBody) Close() error {
+	if b.cancel != nil {
+		defer b.cancel()
+	}
+	return b.body.Close()
+}
+
+// closeBody closes the body of a request that is not sent, as RoundTrip must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// Flags are the flags of the proxy, TLS settings and routes of the upstream requests
+// of a server.
+type Flags struct {
+	Proxy    string
+	CAFile   string
+	Insecure bool
+	Routes   string
+}
+
+// Register defines the flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Proxy, "proxy", "", "Proxy URL of the requests to upstream servers (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY)")
+	fs.StringVar(&f.CAFile, "upstream-ca", "", "PEM file of CA certificates trusted for upstream servers, besides the system ones")
+	fs.BoolVar(&f.Insecure, "upstream-insecure", false, "Do not verify the TLS certificates of upstream servers (for testing only)")
+	fs.StringVar(&f.Routes, "upstream-url", "", "Comma separated from=to base URLs redirecting the requests to an upstream API to a compatible one, e.g. https://www.googleapis.com=https://gateway.internal/google")
+}
+
+// Apply sets the proxy, TLS settings and routes of o from the flags.
+func (f Flags) Apply(o *Options) error {
+	if f.Routes != "" {
+		routes, err := upstream.ParseRoutes(f.Routes)
+		if err != nil {
+			return err
+		}
+		o.Routes = routes
+	}
+	if f.Proxy != "" {
+		proxy, err := url.Parse(f.Proxy)
+		if err != nil || proxy.Host == "" {
+			return fmt.Errorf("invalid proxy %q: expected a URL such as http://proxy:3128", f.Proxy)
+		}
+		o.Proxy = proxy
+	}
+	if f.CAFile == "" && !f.Insecure {
+		return nil
+	}
+	config := &tls.Config{InsecureSkipVerify: f.Insecure}
+	if f.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return fmt.Errorf("error reading upstream CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return errors.New("no PEM certificates found in " + f.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	o.TLS = config
+	return nil
+}
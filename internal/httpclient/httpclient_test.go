@@ -0,0 +1,210 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/budget"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// Test that clients set the User-Agent, cut bodies, retry and observe requests
+func TestNew(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(r.Header.Get("User-Agent") + " 0123456789"))
+	}))
+	defer upstream.Close()
+
+	var observed []Request
+	client := New(Options{
+		Timeout:     5 * time.Second,
+		UserAgent:   "test/1.0",
+		MaxBodySize: 12,
+		Retries:     1,
+		Observe:     func(r Request) { observed = append(observed, r) },
+	})
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	get := func(path, userAgent string) string {
+		req, err := http.NewRequest(http.MethodGet, upstream.URL+path, nil)
+		require.NoError(t, err)
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	assert.Equal(t, "test/1.0 012", get("/", ""))
+	assert.Equal(t, "custom 01234", get("/", "custom"))
+
+	observed = nil
+	assert.Equal(t, "test/1.0 012", get("/flaky", ""))
+	require.Len(t, observed, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, observed[0].Status)
+	assert.Equal(t, http.StatusOK, observed[1].Status)
+	assert.Equal(t, http.MethodGet, observed[1].Method)
+	assert.Equal(t, upstream.Listener.Addr().String(), observed[1].Host)
+}
+
+// Test that requests are bounded by the time left to the tool call
+func TestBudget(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer upstream.Close()
+	client := New(Options{Timeout: 30 * time.Second})
+
+	get := func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// Too little time left
+	err := get(600 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too little to start a request")
+	assert.Equal(t, toolerr.Timeout, toolerr.Classify(err).Code)
+	assert.Zero(t, calls.Load(), "The request should not be sent")
+
+	// The request ends before the call, leaving time to report it
+	began := time.Now()
+	err = get(time.Second)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(began), time.Second-budget.Reserve/2)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// Test that clients with a Guard request only the URLs it allows, redirects included
+func TestGuard(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+	localhost := strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1)
+
+	client := New(Options{Guard: &urlguard.Policy{}})
+	for _, target := range []string{upstream.URL, localhost, "file:///etc/passwd"} {
+		_, err := client.Get(target)
+		require.Error(t, err, target)
+		assert.Equal(t, toolerr.PermissionDenied, toolerr.Classify(err).Code, target)
+	}
+	assert.Zero(t, calls.Load(), "Denied requests should not be sent")
+
+	client = New(Options{Guard: &urlguard.Policy{AllowHosts: []string{"127.0.0.1"}}})
+	resp, err := client.Get(upstream.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = client.Get(upstream.URL + "/redirect")
+	assert.ErrorContains(t, err, "host 169.254.169.254 is not allowed")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// Test the proxy and TLS flags
+func TestFlags(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	}))
+	defer upstream.Close()
+
+	// The certificate of the test server is not trusted by default
+	_, err := New(Options{}).Get(upstream.URL)
+	require.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := upstream.Certificate()
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+
+	var f Flags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Register(fs)
+	require.NoError(t, fs.Parse([]string{"-upstream-ca", caFile, "-proxy", "http://proxy.example.com:3128"}))
+	var o Options
+	require.NoError(t, f.Apply(&o))
+	assert.Equal(t, "proxy.example.com:3128", o.Proxy.Host)
+
+	// Without the proxy, the CA makes the server trusted
+	o.Proxy = nil
+	resp, err := New(o).Get(upstream.URL)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "secure", string(body))
+
+	_, err = cert.Verify(x509.VerifyOptions{Roots: o.TLS.RootCAs})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, Flags{Proxy: "proxy:3128"}.Apply(&Options{}), `invalid proxy "proxy:3128": expected a URL such as http://proxy:3128`)
+	assert.ErrorContains(t, Flags{CAFile: caFile + ".missing"}.Apply(&Options{}), "error reading upstream CA file")
+	require.NoError(t, os.WriteFile(caFile, []byte("not PEM"), 0o600))
+	assert.EqualError(t, Flags{CAFile: caFile}.Apply(&Options{}), "no PEM certificates found in "+caFile)
+
+	o = Options{}
+	require.NoError(t, Flags{Insecure: true}.Apply(&o))
+	assert.True(t, o.TLS.InsecureSkipVerify)
+}
+
+// Test redirecting the requests to an upstream API to a compatible one
+func TestRoutes(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer gateway.Close()
+
+	var o Options
+	require.NoError(t, Flags{Routes: "https://api.example.com/v1=" + gateway.URL + "/example"}.Apply(&o))
+	resp, err := New(o).Get("https://api.example.com/v1/items?id=7")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "/example/items?id=7", string(body))
+
+	// Guard checks the URL requests are redirected to
+	o.Guard = &urlguard.Policy{}
+	_, err = New(o).Get("https://api.example.com/v1/items")
+	assert.ErrorContains(t, err, "127.0.0.1 is a loopback address")
+
+	assert.ErrorContains(t, Flags{Routes: "https://api.example.com"}.Apply(&Options{}), "expected from=to")
+}
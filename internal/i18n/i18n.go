@@ -0,0 +1,124 @@
+// Package i18n translates the tool descriptions, status messages and errors of the
+// servers into Korean and Japanese, from message catalogs embedded in the binary.
+//
+// Messages are looked up by their English text, or their format for those built with
+// fmt, so that code keeps writing English and messages missing from a catalog stay in
+// English. The language of a server is set with -lang; clients may choose another one
+// for their session with the setLanguage tool.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Languages.
+const (
+	English  = "en"
+	Japanese = "ja"
+	Korean   = "ko"
+)
+
+// Languages are the supported languages.
+var Languages = []string{English, Japanese, Korean}
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// catalogs are the translations of English messages by language.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := catalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+	catalogs := make(map[string]map[string]string)
+	for _, entry := range entries {
+		data, err := catalogFiles.ReadFile(path.Join("catalogs", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+	return catalogs
+}
+
+// Parse returns the supported language of s, a language code such as ko, ko-KR or a
+// locale such as ko_KR.UTF-8.
+func Parse(s string) (string, error) {
+	code := strings.ToLower(s)
+	if i := strings.IndexAny(code, "-_."); i >= 0 {
+		code = code[:i]
+	}
+	for _, lang := range Languages {
+		if code == lang {
+			return lang, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported language %q: use %s", s, strings.Join(Languages, ", "))
+}
+
+// T returns the translation of msg into lang, or msg if it has none.
+func T(lang, msg string) string {
+	if translated, ok := catalogs[lang][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format into lang with a, as fmt.Sprintf does.
+func Sprintf(lang, format string, a ...interface{}) string {
+	return fmt.Sprintf(T(lang, format), a...)
+}
+
+type langKey struct{}
+
+// WithLanguage returns ctx carrying lang, the language of a tool call.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext returns the language of the tool call of ctx, English by default.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(langKey{}).(string); ok {
+		return lang
+	}
+	return English
+}
+
+// Tool returns tool with its description and those of its parameters translated into
+// lang. tool is left unchanged.
+func Tool(lang string, tool mcp.Tool) mcp.Tool {
+	if catalogs[lang] == nil {
+		return tool
+	}
+	tool.Description = T(lang, tool.Description)
+	if len(tool.InputSchema.Properties) == 0 {
+		return tool
+	}
+	properties := make(map[string]interface{}, len(tool.InputSchema.Properties))
+	for name, property := range tool.InputSchema.Properties {
+		if schema, ok := property.(map[string]interface{}); ok {
+			if description, ok := schema["description"].(string); ok {
+				schema = maps.Clone(schema)
+				schema["description"] = T(lang, description)
+			}
+			property = schema
+		}
+		properties[name] = property
+	}
+	tool.InputSchema.Properties = properties
+	return tool
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test parsing language codes and locales
+func TestParse(t *testing.T) {
+	for in, want := range map[string]string{"en": English, "ko": Korean, "ko-KR": Korean, "ja_JP.UTF-8": Japanese, "EN_us": English} {
+		lang, err := Parse(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, lang, in)
+	}
+	_, err := Parse("fr")
+	assert.EqualError(t, err, `unsupported language "fr": use en, ja, ko`)
+}
+
+// verbs matches the verbs of formats, with their argument index if any.
+var verbs = regexp.MustCompile(`%(?:\[\d+\])?[a-z]`)
+
+// Test that the catalogs translate into every language and keep the verbs of formats
+func TestCatalogs(t *testing.T) {
+	for _, lang := range Languages {
+		if lang == English {
+			continue
+		}
+		require.NotEmpty(t, catalogs[lang], lang)
+		for msg, translated := range catalogs[lang] {
+			assert.NotEmpty(t, translated, "%s: %s", lang, msg)
+			assert.Len(t, verbs.FindAllString(translated, -1), len(verbs.FindAllString(msg, -1)), "%s: %s", lang, msg)
+		}
+	}
+}
+
+// Test translating messages and formats
+func TestT(t *testing.T) {
+	assert.Equal(t, "Google 검색을 수행하고 결과를 반환합니다", T(Korean, "Performs a Google search and returns the results"))
+	assert.Equal(t, "Not in any catalog", T(Korean, "Not in any catalog"))
+	assert.Equal(t, "Performs a Google search and returns the results", T(English, "Performs a Google search and returns the results"))
+	assert.Equal(t, "search の呼び出し回数の上限を超えました。3 秒後に再試行してください",
+		Sprintf(Japanese, "Rate limit exceeded for %s, retry after %d seconds", "search", 3))
+	assert.Equal(t, "10바이트인 a.txt이(가) 최대 크기 4바이트를 초과합니다",
+		Sprintf(Korean, "%s of %d bytes exceeds the maximum size of %d bytes", "a.txt", 10, 4))
+	assert.Equal(t, English, FromContext(context.Background()))
+	assert.Equal(t, Korean, FromContext(WithLanguage(context.Background(), Korean)))
+}
+
+// Test translating the descriptions of a tool
+func TestTool(t *testing.T) {
+	tool := mcp.NewTool("searchGoogle",
+		mcp.WithDescription("Performs a Google search and returns the results"),
+		mcp.WithString("query", mcp.Description("The search query string")),
+		mcp.WithString("unknown", mcp.Description("Not in any catalog")),
+	)
+	translated := Tool(Japanese, tool)
+	assert.Equal(t, "Google 検索を行い、結果を返します", translated.Description)
+	assert.Equal(t, "検索クエリ", translated.InputSchema.Properties["query"].(map[string]interface{})["description"])
+	assert.Equal(t, "Not in any catalog", translated.InputSchema.Properties["unknown"].(map[string]interface{})["description"])
+
+	assert.Equal(t, "Performs a Google search and returns the results", tool.Description, "The tool should be left unchanged")
+	assert.Equal(t, "The search query string", tool.InputSchema.Properties["query"].(map[string]interface{})["description"])
+	assert.Equal(t, tool, Tool(English, tool))
+}
+
+// Test that sessions choose their language with setLanguage
+func TestSessions(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	languages := NewSessions(s, Korean)
+	middleware := languages.Middleware()
+	s.AddTool(SetLanguageTool(), middleware(languages.SetLanguageHandler()))
+	s.AddTool(mcp.NewTool("wait"), middleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args struct {
+			Cursor string `json:"cursor" param:"required"`
+		}
+		if err := params.Decode(req, &args); err != nil {
+			return nil, err
+		}
+		return nil, toolerr.Errorf(toolerr.NotFound, "the result of this cursor is no longer kept; results are kept for %s", time.Hour)
+	}))
+
+	c := mcptest.Connect(t, s)
+	assert.Contains(t, c.Error("wait", nil), "잘못된 매개변수: cursor은(는) 필수입니다")
+	assert.Contains(t, c.Error("wait", map[string]interface{}{"cursor": "x"}), "결과는 1h0m0s 동안 보관됩니다")
+
+	assert.Equal(t, "このセッションのメッセージは日本語で表示されます", c.Text(SetLanguageToolName, map[string]interface{}{"language": "ja"}))
+	assert.Contains(t, c.Error("wait", map[string]interface{}{"cursor": "x"}), "結果の保持期間は 1h0m0s です")
+	assert.Contains(t, c.Error(SetLanguageToolName, map[string]interface{}{"language": "fr"}), "language")
+
+	// Other sessions keep the language of the server
+	other := mcptest.Connect(t, s)
+	assert.Contains(t, other.Error("wait", map[string]interface{}{"cursor": "x"}), "결과는 1h0m0s 동안 보관됩니다")
+	assert.Equal(t, "Messages of this session are now in English", other.Text(SetLanguageToolName, map[string]interface{}{"language": "en"}))
+	assert.Contains(t, other.Error("wait", map[string]interface{}{"cursor": "x"}), "results are kept for 1h0m0s")
+}
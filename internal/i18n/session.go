@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/session"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// SetLanguageToolName is the name of the tool choosing the language of a session.
+const SetLanguageToolName = "setLanguage"
+
+// Sessions keep the language of the client sessions of a server: the language of the
+// server unless a session chose another one with setLanguage.
+type Sessions struct {
+	lang  string
+	store *session.Store
+}
+
+// sessionLanguage is the language of one session.
+type sessionLanguage struct {
+	mu   sync.Mutex
+	lang string
+}
+
+// NewSessions returns the languages of the sessions of s, lang by default.
+func NewSessions(s *server.MCPServer, lang string) *Sessions {
+	return &Sessions{lang: lang, store: session.Scoped(s)}
+}
+
+func (l *Sessions) language(ctx context.Context) *sessionLanguage {
+	return session.Value(ctx, l.store, "language", func() *sessionLanguage {
+		return &sessionLanguage{lang: l.lang}
+	})
+}
+
+// Language returns the language of the session of ctx.
+func (l *Sessions) Language(ctx context.Context) string {
+	sl := l.language(ctx)
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.lang
+}
+
+// Middleware returns tool middleware passing the language of the session to the tool
+// handlers and the middleware it wraps, see FromContext, and translating their
+// errors.
+func (l *Sessions) Middleware() func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			lang := l.Language(ctx)
+			result, err := next(WithLanguage(ctx, lang), req)
+			if err != nil {
+				return nil, translateError(lang, err)
+			}
+			return result, nil
+		}
+	}
+}
+
+// translateError returns err translated into lang, if it is a tool error or a missing
+// parameter.
+func translateError(lang string, err error) error {
+	if lang == English {
+		return err
+	}
+	if paramErr, ok := err.(*params.Error); ok && paramErr.Param != "" && paramErr.Message == "is required" {
+		return toolerr.New(toolerr.InvalidParams, Sprintf(lang, "invalid parameters: %s is required", paramErr.Param))
+	}
+	return toolerr.Translate(err, func(msg string) string { return T(lang, msg) })
+}
+
+// SetLanguageTool returns the definition of the setLanguage tool.
+func SetLanguageTool() mcp.Tool {
+	return mcp.NewTool(SetLanguageToolName,
+		mcp.WithDescription("Sets the language of the messages and errors of this server for the calling client session"),
+		mcp.WithString("language",
+			mcp.Description("Language: en (English), ja (Japanese) or ko (Korean)"),
+			mcp.Required(),
+			mcp.Enum(Languages...),
+		),
+	)
+}
+
+// SetLanguageHandler returns the handler of the setLanguage tool.
+func (l *Sessions) SetLanguageHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args struct {
+			Language string `json:"language" param:"required,enum=en|ja|ko"`
+		}
+		if err := params.Decode(req, &args); err != nil {
+			return nil, err
+		}
+		sl := l.language(ctx)
+		sl.mu.Lock()
+		sl.lang = args.Language
+		sl.mu.Unlock()
+		return mcp.NewToolResultText(T(args.Language, "Messages of this session are now in English")), nil
+	}
+}
@@ -0,0 +1,43 @@
+// Package identity carries the credentials a remote client presented with its request
+// to the tool handlers, e.g. for access control.
+package identity
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Credentials are what a client presented to identify itself.
+type Credentials struct {
+	// APIKey is the bearer token of the Authorization header or the X-API-Key header.
+	APIKey string
+	// CertificateCN is the common name of the verified TLS client certificate.
+	CertificateCN string
+	// RemoteAddr is the network address of the client, empty for local clients.
+	RemoteAddr string
+}
+
+type credentialsKey struct{}
+
+// FromRequest returns ctx carrying the credentials of r.
+func FromRequest(ctx context.Context, r *http.Request) context.Context {
+	var c Credentials
+	if auth := r.Header.Get("Authorization"); len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		c.APIKey = strings.TrimSpace(auth[len("Bearer "):])
+	} else {
+		c.APIKey = strings.TrimSpace(r.Header.Get("X-API-Key"))
+	}
+	c.RemoteAddr = r.RemoteAddr
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		c.CertificateCN = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	return context.WithValue(ctx, credentialsKey{}, c)
+}
+
+// FromContext returns the credentials carried by ctx, which are empty for local
+// clients such as those connected over stdio.
+func FromContext(ctx context.Context) Credentials {
+	c, _ := ctx.Value(credentialsKey{}).(Credentials)
+	return c
+}
@@ -0,0 +1,175 @@
+// Package inprocess connects clients to MCP servers running in the same process,
+// passing JSON-RPC messages to the server directly.
+package inprocess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+)
+
+// sessions numbers the sessions of in-process clients.
+var sessions atomic.Int64
+
+// session is the client session of a Client, through which the
+// server sends it notifications.
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *session) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *session) Initialized() bool {
+	return s.initialized.Load()
+}
+
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *session) SessionID() string {
+	return s.id
+}
+
+// Client talks to an MCPServer in the same process, passing JSON-RPC messages
+// to it directly.
+type Client struct {
+	server  *server.MCPServer
+	session *session
+	ctx     context.Context
+	cancel  context.CancelFunc
+	nextID  atomic.Int64
+
+	mu       sync.Mutex
+	handlers []func(notification mcp.JSONRPCNotification)
+}
+
+// NewClient connects to s as a client session and relays the notifications of the
+// server to the handlers until ctx is done. Close calls cancel, which should stop
+// the background work of the server.
+func NewClient(ctx context.Context, cancel context.CancelFunc, s *server.MCPServer) (*Client, error) {
+	c := &Client{
+		server: s,
+		session: &session{
+			id:            fmt.Sprintf("in-process-%d", sessions.Add(1)),
+			notifications: make(chan mcp.JSONRPCNotification, 100),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if err := s.RegisterSession(ctx, c.session); err != nil {
+		return nil, err
+	}
+	go c.relay()
+	return c, nil
+}
+
+// relay passes the notifications of the server on to the handlers.
+func (c *Client) relay() {
+	for {
+		select {
+		case notification := <-c.session.notifications:
+			c.mu.Lock()
+			handlers := c.handlers
+			c.mu.Unlock()
+			for _, handler := range handlers {
+				handler(notification)
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// send makes a request and returns the raw result.
+func (c *Client) send(ctx context.Context, method string, params interface{}) (*json.RawMessage, error) {
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      c.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(c.server.HandleMessage(c.server.WithContext(ctx, c.session), request))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s", response.Error.Message)
+	}
+	return &response.Result, nil
+}
+
+func (c *Client) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	response, err := c.send(ctx, "initialize", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.InitializeResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, nil
+}
+
+func (c *Client) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	response, err := c.send(ctx, "tools/list", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	var result mcp.ListToolsResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, nil
+}
+
+func (c *Client) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	response, err := c.send(ctx, "tools/call", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(response)
+}
+
+func (c *Client) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// Close ends the session and stops the server, releasing its tool middleware right
+// away, e.g. writing a cassette, rather than once its context is done.
+func (c *Client) Close() error {
+	c.server.UnregisterSession(c.session.id)
+	c.cancel()
+	middleware.Close(c.server)
+	return nil
+}
+
+// Done is closed once the client is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
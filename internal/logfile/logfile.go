@@ -0,0 +1,117 @@
+// Package logfile writes logs to files rotated by size and by time, keeping a bounded
+// number of old files, so that long-running servers need no external logrotate.
+package logfile
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Rotation configures when a log file is rotated and which rotated files are kept.
+type Rotation struct {
+	MaxSizeMB  int // size at which the file is rotated, 100 if 0
+	MaxBackups int // number of rotated files kept, 0 keeps all
+	MaxAgeDays int // age at which rotated files are removed, 0 keeps them
+	// Every rotates the file at each multiple of this interval since midnight UTC,
+	// e.g. daily with 24h, when it has been written to; 0 rotates it by size only.
+	Every    time.Duration
+	Compress bool // gzip rotated files
+}
+
+// Register defines the rotation flags of a log file on fs, named after prefix, such
+// as -audit-max-size for prefix audit, and described as what.
+func (r *Rotation) Register(fs *flag.FlagSet, prefix, what string) {
+	fs.IntVar(&r.MaxSizeMB, prefix+"-max-size", 100, fmt.Sprintf("Size in megabytes at which the %s file is rotated", what))
+	fs.IntVar(&r.MaxBackups, prefix+"-max-backups", 5, fmt.Sprintf("Number of rotated %s files to keep (0 keeps all)", what))
+	fs.IntVar(&r.MaxAgeDays, prefix+"-max-age", 0, fmt.Sprintf("Days after which rotated %s files are removed (0 keeps them)", what))
+	fs.DurationVar(&r.Every, prefix+"-rotate", 0, fmt.Sprintf("Also rotate the %s file at this interval, e.g. 24h for daily at midnight UTC (0 rotates by size only)", what))
+	fs.BoolVar(&r.Compress, prefix+"-compress", false, fmt.Sprintf("Compress rotated %s files with gzip", what))
+}
+
+// Validate checks that the limits of r are not negative.
+func (r Rotation) Validate() error {
+	if r.MaxSizeMB < 0 || r.MaxBackups < 0 || r.MaxAgeDays < 0 || r.Every < 0 {
+		return fmt.Errorf("invalid log rotation: sizes, counts, ages and intervals may not be negative")
+	}
+	return nil
+}
+
+// Writer appends to a log file, rotating it as configured. Rotated files are named
+// after the file and the time of their rotation, e.g. audit-2024-01-02T00-00-00.000.jsonl.
+type Writer struct {
+	mu    sync.Mutex
+	file  *lumberjack.Logger
+	every time.Duration
+	now   func() time.Time
+	// next is when the file is due to be rotated by time
+	next time.Time
+}
+
+// Open returns a Writer appending to path, which is created with its directory when
+// first written to.
+func Open(path string, r Rotation) (*Writer, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		file: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    r.MaxSizeMB,
+			MaxBackups: r.MaxBackups,
+			MaxAge:     r.MaxAgeDays,
+			Compress:   r.Compress,
+		},
+		every: r.Every,
+		now:   time.Now,
+	}
+	if w.every > 0 {
+		// A file left by a previous run is rotated if it was written before the current
+		// interval began
+		last := w.now()
+		if info, err := os.Stat(path); err == nil {
+			last = info.ModTime()
+		}
+		w.next = w.boundaryAfter(last)
+	}
+	return w, nil
+}
+
+// boundaryAfter returns the first multiple of the rotation interval after t.
+func (w *Writer) boundaryAfter(t time.Time) time.Time {
+	return t.UTC().Truncate(w.every).Add(w.every)
+}
+
+// Write appends p to the file, rotating it first when its interval has ended or when
+// p would make it larger than its maximum size.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.every > 0 {
+		if now := w.now(); !now.Before(w.next) {
+			if err := w.file.Rotate(); err != nil {
+				return 0, err
+			}
+			w.next = w.boundaryAfter(now)
+		}
+	}
+	return w.file.Write(p)
+}
+
+// Rotate closes the file, renames it after the current time and starts a new one.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Rotate()
+}
+
+// Close closes the file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
@@ -0,0 +1,95 @@
+package logfile
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// files returns the contents of the files in dir by name.
+func files(t *testing.T, dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		contents[e.Name()] = string(data)
+	}
+	return contents
+}
+
+// Test rotating by time, and rotating a file left by a previous run
+func TestRotateEvery(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "serve.log")
+	require.NoError(t, os.WriteFile(path, []byte("yesterday\n"), 0o600))
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, day.Add(-time.Hour), day.Add(-time.Hour)))
+
+	w, err := Open(path, Rotation{Every: 24 * time.Hour})
+	require.NoError(t, err)
+	defer w.Close()
+	now := day.Add(10 * time.Hour)
+	w.now = func() time.Time { return now }
+
+	_, err = w.Write([]byte("morning\n"))
+	require.NoError(t, err)
+	now = now.Add(10 * time.Hour)
+	_, err = w.Write([]byte("evening\n"))
+	require.NoError(t, err)
+	contents := files(t, dir)
+	assert.Len(t, contents, 2, "The file of the previous day was rotated once")
+	assert.Equal(t, "morning\nevening\n", contents["serve.log"])
+
+	now = now.Add(4 * time.Hour)
+	// Rotated files are named after the actual time, to the millisecond
+	time.Sleep(2 * time.Millisecond)
+	_, err = w.Write([]byte("next day\n"))
+	require.NoError(t, err)
+	contents = files(t, dir)
+	assert.Len(t, contents, 3)
+	assert.Equal(t, "next day\n", contents["serve.log"])
+	var rotated []string
+	for name, content := range contents {
+		if name != "serve.log" {
+			assert.True(t, strings.HasPrefix(name, "serve-") && strings.HasSuffix(name, ".log"), name)
+			rotated = append(rotated, content)
+		}
+	}
+	assert.ElementsMatch(t, []string{"yesterday\n", "morning\nevening\n"}, rotated)
+}
+
+// Test that rotating keeps the configured number of files
+func TestMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(filepath.Join(dir, "audit.jsonl"), Rotation{MaxBackups: 1})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("entry\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Rotate())
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, w.Close())
+	assert.Eventually(t, func() bool { return len(files(t, dir)) == 2 }, 5*time.Second, 10*time.Millisecond,
+		"Old files are removed in the background")
+}
+
+// Test the rotation flags
+func TestRegister(t *testing.T) {
+	var r Rotation
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	r.Register(fs, "audit", "audit log")
+	require.NoError(t, fs.Parse([]string{"-audit-max-age", "30", "-audit-rotate", "24h", "-audit-compress"}))
+	assert.Equal(t, Rotation{MaxSizeMB: 100, MaxBackups: 5, MaxAgeDays: 30, Every: 24 * time.Hour, Compress: true}, r)
+
+	_, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"), Rotation{Every: -time.Hour})
+	assert.Error(t, err)
+}
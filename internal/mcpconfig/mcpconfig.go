@@ -0,0 +1,78 @@
+// Package mcpconfig reads external MCP server declarations in the mcpServers format of
+// Claude Desktop, so that existing configurations can be reused as they are.
+package mcpconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Server is a stdio MCP server started with a command.
+type Server struct {
+	Command string            `json:"command" yaml:"command"`
+	Args    []string          `json:"args" yaml:"args"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env"`
+}
+
+// Load reads the mcpServers block of a Claude Desktop style JSON config file. Other
+// settings in the file are ignored. A leading ~ in path is the home directory.
+func Load(path string) (map[string]Server, error) {
+	path, err := ExpandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mcpServers file %s: %w", path, err)
+	}
+	var config struct {
+		MCPServers map[string]Server `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing mcpServers file %s: %w", path, err)
+	}
+	return config.MCPServers, nil
+}
+
+// ExpandHome replaces a leading ~ in path with the home directory.
+func ExpandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, rest), nil
+}
+
+// Merge combines servers declared inline in a config with those of an optional
+// mcpServers file, checking that every server has a command and no name is used twice.
+func Merge(inline map[string]Server, path string) (map[string]Server, error) {
+	merged := make(map[string]Server, len(inline))
+	for name, s := range inline {
+		merged[name] = s
+	}
+	if path != "" {
+		fromFile, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		for name, s := range fromFile {
+			if _, ok := merged[name]; ok {
+				return nil, fmt.Errorf("mcpServers: server %s is declared twice", name)
+			}
+			merged[name] = s
+		}
+	}
+	for name, s := range merged {
+		if s.Command == "" {
+			return nil, fmt.Errorf("mcpServers: server %s: command is required", name)
+		}
+	}
+	return merged, nil
+}
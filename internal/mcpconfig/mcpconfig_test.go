@@ -0,0 +1,67 @@
+package mcpconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// claudeConfig is a Claude Desktop config with settings besides mcpServers.
+const claudeConfig = `{
+  "globalShortcut": "Ctrl+Space",
+  "mcpServers": {
+    "sqlite": {
+      "command": "uvx",
+      "args": ["mcp-server-sqlite", "--db-path", "/tmp/foo.db"]
+    },
+    "github": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-github"],
+      "env": {"GITHUB_PERSONAL_ACCESS_TOKEN": "token"}
+    }
+  }
+}`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	servers, err := Load(writeConfig(t, claudeConfig))
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	assert.Equal(t, Server{Command: "uvx", Args: []string{"mcp-server-sqlite", "--db-path", "/tmp/foo.db"}}, servers["sqlite"])
+	assert.Equal(t, "token", servers["github"].Env["GITHUB_PERSONAL_ACCESS_TOKEN"])
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "error reading mcpServers file")
+
+	_, err = Load(writeConfig(t, "{"))
+	assert.ErrorContains(t, err, "error parsing mcpServers file")
+}
+
+func TestMerge(t *testing.T) {
+	path := writeConfig(t, claudeConfig)
+	inline := map[string]Server{"memory": {Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-memory"}}}
+
+	merged, err := Merge(inline, path)
+	require.NoError(t, err)
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "npx", merged["memory"].Command)
+
+	merged, err = Merge(nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, merged)
+
+	_, err = Merge(map[string]Server{"sqlite": {Command: "sqlite"}}, path)
+	assert.ErrorContains(t, err, "server sqlite is declared twice")
+
+	_, err = Merge(map[string]Server{"empty": {}}, "")
+	assert.ErrorContains(t, err, "server empty: command is required")
+}
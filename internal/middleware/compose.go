@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Stack composes mw into one middleware, the first one outermost, e.g. to install
+// the same layers on several tools.
+func Stack(mw ...Middleware) Middleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return Chain(next, mw...)
+	}
+}
+
+// ForTools returns middleware running the calls of the tools matching patterns,
+// path.Match patterns such as "capture*", through mw; other calls skip it.
+func ForTools(mw Middleware, patterns ...string) Middleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		wrapped := mw(next)
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, req.Params.Name); ok {
+					return wrapped(ctx, req)
+				}
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// Timeout returns middleware canceling the context of calls after d, for tools
+// running commands or requests that may hang. Unlike -tool-timeout, it relies on
+// the handler returning once its context is done. d of zero or less sets no timeout.
+func Timeout(d time.Duration) Middleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if d <= 0 {
+			return next
+		}
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, req)
+		}
+	}
+}
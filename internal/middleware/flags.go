@@ -0,0 +1,459 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/audit"
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/concurrency"
+	"github.com/mark3labs/mcphost/internal/diagnostics"
+	"github.com/mark3labs/mcphost/internal/i18n"
+	"github.com/mark3labs/mcphost/internal/logfile"
+	"github.com/mark3labs/mcphost/internal/policy"
+	"github.com/mark3labs/mcphost/internal/prompts"
+	"github.com/mark3labs/mcphost/internal/ratelimit"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/reload"
+	"github.com/mark3labs/mcphost/internal/resilience"
+	"github.com/mark3labs/mcphost/internal/serverinfo"
+	"github.com/mark3labs/mcphost/internal/session"
+	"github.com/mark3labs/mcphost/internal/sizelimit"
+	"github.com/mark3labs/mcphost/internal/tenant"
+	"github.com/mark3labs/mcphost/internal/testrecord"
+	"github.com/mark3labs/mcphost/internal/tracing"
+	"github.com/mark3labs/mcphost/internal/transform"
+	"github.com/mark3labs/mcphost/internal/usage"
+	"github.com/mark3labs/mcphost/internal/watchdog"
+)
+
+const (
+	// flushTimeout bounds flushing pending spans when a server closes.
+	flushTimeout = 5 * time.Second
+	// defaultMaxResultSize is the size tool results are truncated to by default.
+	defaultMaxResultSize = 1 << 20
+	// spilledResults is how many truncated results -spill-truncated keeps.
+	spilledResults = 20
+	// defaultToolTimeout is the longest a tool call runs by default.
+	defaultToolTimeout = "*=10m"
+)
+
+// Flags are the tool middleware flags shared by the servers.
+type Flags struct {
+	AuditLog      string
+	AuditRotation logfile.Rotation
+	AuditRedact   string
+
+	Redact string
+
+	Lang string
+
+	OTLPEndpoint string
+	OTLPInsecure bool
+
+	RateLimit string
+	Policy    string
+	Tenants   string
+
+	MaxArgsSize    int
+	MaxResultSize  int
+	SpillTruncated bool
+	Transforms     string
+
+	MaxConcurrent      int
+	MaxConcurrentTools string
+	QueueTimeout       time.Duration
+
+	ToolTimeout string
+
+	Retry          string
+	CircuitBreaker string
+	Bulkhead       string
+
+	Cache    string
+	CacheTTL string
+
+	Record string
+	Replay string
+
+	Prompts string
+
+	SessionIdleTimeout time.Duration
+
+	DebugListen string
+	DebugDir    string
+
+	UsageStats bool
+	UsageFile  string
+
+	// fs is the flag set of the server, reported by getServerInfo
+	fs *flag.FlagSet
+}
+
+// Register defines the middleware flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	f.fs = fs
+	fs.StringVar(&f.AuditLog, "audit-log", "", "Record tool calls as JSON lines in this file, or in syslog with syslog, syslog://host:port or syslog+tcp://host:port")
+	f.AuditRotation.Register(fs, "audit", "audit log")
+	fs.StringVar(&f.AuditRedact, "audit-redact", "", "Comma separated argument names to redact in the audit log, besides passwords, tokens and other secrets")
+	fs.StringVar(&f.Redact, "redact", "", "Regular expression of secrets to mask in the log and in tool results, besides the API keys, tokens and passwords given in flags, e.g. 'sk-[A-Za-z0-9]{20,}'")
+	fs.StringVar(&f.Lang, "lang", "", "Language of the tool descriptions and messages, en, ja or ko; clients may then choose another one for their session with setLanguage (default: en)")
+	fs.StringVar(&f.OTLPEndpoint, "otlp-endpoint", "", "OTLP/HTTP collector to export OpenTelemetry traces to, as host:port or URL (default: OTEL_EXPORTER_OTLP_ENDPOINT; no tracing if unset)")
+	fs.BoolVar(&f.OTLPInsecure, "otlp-insecure", false, "Export traces over plain HTTP instead of HTTPS")
+	fs.StringVar(&f.Policy, "policy", "", "YAML or JSON file of rules allowing or denying tool calls by tool, client and arguments")
+	fs.StringVar(&f.Tenants, "tenants", "", "YAML or JSON file of tenants, selected by the credentials of remote clients, with their own API keys, networks, enabled tools and rate limits")
+	fs.StringVar(&f.RateLimit, "rate-limit", "", "Comma separated limits of calls per tool and client session as tool=count/unit[:burst], unit s, m or h; * for other tools, e.g. searchGoogle=10/m,*=5/s")
+	fs.IntVar(&f.MaxArgsSize, "max-args-size", 0, "Maximum size in bytes of the JSON arguments of a tool call; larger calls are refused (0 for no limit)")
+	fs.IntVar(&f.MaxResultSize, "max-result-size", defaultMaxResultSize, "Size in bytes the text of tool results is truncated to (0 for no limit)")
+	fs.BoolVar(&f.SpillTruncated, "spill-truncated", false, "Keep the full text of the last truncated results as resources, linked from their truncation notice")
+	fs.StringVar(&f.Transforms, "transforms", "", "YAML or JSON file of transforms of tool results, such as truncating text, stripping JSON fields, rendering a template or redacting patterns")
+	fs.IntVar(&f.MaxConcurrent, "max-concurrent", 0, "Maximum number of tool calls run at once, by all clients; behind a proxy, of all its servers (0 for no limit)")
+	fs.StringVar(&f.MaxConcurrentTools, "max-concurrent-tools", "", "Comma separated maximum numbers of calls of a tool run at once as tool=count; * for each other tool, e.g. captureScreen=2,*=8")
+	fs.DurationVar(&f.QueueTimeout, "queue-timeout", 30*time.Second, "How long tool calls over -max-concurrent or -max-concurrent-tools wait for a slot before failing (0 fails them at once)")
+	fs.StringVar(&f.ToolTimeout, "tool-timeout", defaultToolTimeout, "Comma separated hard maximum execution times of tool calls as tool=duration, after which they are canceled; * for other tools, 0 for no limit, e.g. captureScreen=30s,*=2m")
+	fs.StringVar(&f.Retry, "retry", "", "Comma separated retries of failed idempotent requests to upstream APIs as host=count; * for other hosts, e.g. api.github.com=3,*=1")
+	fs.StringVar(&f.CircuitBreaker, "circuit-breaker", "", "Comma separated circuit breakers rejecting requests to an upstream host after consecutive failures as host=failures/cooldown; * for other hosts, e.g. *=5/30s")
+	fs.StringVar(&f.Bulkhead, "bulkhead", "", "Comma separated limits of concurrent requests to upstream hosts as host=count; * for other hosts, e.g. *=10")
+	fs.StringVar(&f.Cache, "cache", "", "Cache the results of cacheable tools in memory[:entries], disk:<directory> or redis://host:port[/db] (no caching if unset)")
+	fs.StringVar(&f.CacheTTL, "cache-ttl", "", "Comma separated cache lifetimes overriding those of the tools as tool=duration; * for the other cacheable tools, 0 to disable, e.g. searchGoogle=1h,*=5m")
+	fs.StringVar(&f.Prompts, "prompts", "", "YAML or JSON file of parameterized prompts to offer clients through prompts/list and prompts/get")
+	fs.DurationVar(&f.SessionIdleTimeout, "session-idle-timeout", session.DefaultIdleTimeout, "How long the state a server keeps per client session, such as cookies, is kept after the last call of the session")
+	fs.StringVar(&f.DebugListen, "debug-listen", "", "Serve pprof profiles, expvar counters and goroutine and heap snapshots on this address, e.g. localhost:6060, and add the getRuntimeStats tool (off if unset)")
+	fs.StringVar(&f.DebugDir, "debug-dir", filepath.Join(os.TempDir(), "mcphost-debug"), "Directory the snapshots taken with POST /debug/snapshot are written to")
+	fs.BoolVar(&f.UsageStats, "usage-stats", false, "Count the calls, errors and latency of each tool and add the getUsageStats tool")
+	fs.StringVar(&f.UsageFile, "usage-file", "", "Keep the usage statistics in this JSON file across restarts, saved every minute; implies -usage-stats")
+	fs.StringVar(&f.Record, "record", "", "Record the tool calls and the HTTP requests of the tools in this cassette file, written when the server stops")
+	fs.StringVar(&f.Replay, "replay", "", "Answer tool calls and HTTP requests from this cassette file, recorded with --record, without going to the network")
+}
+
+// Apply installs the middleware selected by f on the tools of s, the server called
+// name, and adds the getServerInfo tool. What it opens is released by Close, which
+// also runs when ctx is done.
+func (f Flags) Apply(ctx context.Context, name string, s *server.MCPServer) error {
+	if f.Record != "" && f.Replay != "" {
+		return errors.New("--record and --replay cannot be used together")
+	}
+	if f.Redact != "" {
+		if err := redact.AddPattern(f.Redact); err != nil {
+			return fmt.Errorf("-redact: %w", err)
+		}
+	}
+	redact.Install()
+	rules, err := ratelimit.ParseRules(f.RateLimit)
+	if err != nil {
+		return err
+	}
+	ttls, err := cache.ParseTTLs(f.CacheTTL)
+	if err != nil {
+		return err
+	}
+	limits, err := concurrency.ParseLimits(f.MaxConcurrentTools)
+	if err != nil {
+		return err
+	}
+	timeouts, err := watchdog.ParseLimits(f.ToolTimeout)
+	if err != nil {
+		return err
+	}
+	if f.MaxConcurrent < 0 || f.QueueTimeout < 0 || f.MaxArgsSize < 0 || f.MaxResultSize < 0 {
+		return errors.New("-max-concurrent, -queue-timeout, -max-args-size and -max-result-size cannot be negative")
+	}
+	if f.SessionIdleTimeout < 0 {
+		return errors.New("-session-idle-timeout cannot be negative")
+	}
+	var upstreams resilience.Config
+	if upstreams.Retries, err = resilience.ParseRetries(f.Retry); err != nil {
+		return err
+	}
+	if upstreams.Breakers, err = resilience.ParseBreakers(f.CircuitBreaker); err != nil {
+		return err
+	}
+	if upstreams.Bulkheads, err = resilience.ParseBulkheads(f.Bulkhead); err != nil {
+		return err
+	}
+	var library *prompts.Library
+	if f.Prompts != "" {
+		if library, err = prompts.Load(f.Prompts); err != nil {
+			return err
+		}
+	}
+	var cassette *testrecord.Cassette
+	if f.Replay != "" {
+		if cassette, err = testrecord.Load(f.Replay); err != nil {
+			return err
+		}
+	}
+	var tenants *tenant.Config
+	if f.Tenants != "" {
+		if tenants, err = tenant.Load(f.Tenants); err != nil {
+			return err
+		}
+	}
+	var transforms *transform.Config
+	if f.Transforms != "" {
+		if transforms, err = transform.Load(f.Transforms); err != nil {
+			return err
+		}
+	}
+	var toolPolicy *policy.Policy
+	if f.Policy != "" {
+		if toolPolicy, err = policy.Load(f.Policy); err != nil {
+			return err
+		}
+	}
+	lang := i18n.English
+	if f.Lang != "" {
+		if lang, err = i18n.Parse(f.Lang); err != nil {
+			return fmt.Errorf("-lang: %w", err)
+		}
+	}
+
+	var features []string
+	// Messages are translated outermost, after all the middleware ran
+	var languages *i18n.Sessions
+	if f.Lang != "" {
+		features = append(features, "i18n")
+		SetLanguage(s, lang)
+		languages = i18n.NewSessions(s, lang)
+		Use(s, languages.Middleware())
+	}
+	if tracing.Enabled(f.OTLPEndpoint) {
+		features = append(features, "tracing")
+		shutdown, err := tracing.Start(ctx, "mcphost-"+name, f.OTLPEndpoint, f.OTLPInsecure)
+		if err != nil {
+			Close(s)
+			return err
+		}
+		OnClose(s, func() error {
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+			return shutdown(flushCtx)
+		})
+		Use(s, tracing.Middleware(name))
+	}
+
+	if f.DebugListen != "" {
+		stop, err := diagnostics.Listen(f.DebugListen, f.DebugDir)
+		if err != nil {
+			Close(s)
+			return err
+		}
+		features = append(features, "diagnostics")
+		OnClose(s, stop)
+		Use(s, diagnostics.Middleware(name))
+		AddTool(s, diagnostics.Tool(), diagnostics.ToolHandler(name))
+	}
+
+	if f.UsageStats || f.UsageFile != "" {
+		tracker := usage.New(name)
+		if f.UsageFile != "" {
+			if tracker, err = usage.Open(name, f.UsageFile); err != nil {
+				Close(s)
+				return err
+			}
+		}
+		features = append(features, "usage")
+		OnClose(s, tracker.Close)
+		Use(s, tracker.Middleware())
+		AddTool(s, usage.Tool(), tracker.ToolHandler())
+	}
+
+	var guard *resilience.Transport
+	if !upstreams.Empty() {
+		features = append(features, "resilience")
+		restore := testrecord.Intercept(func(base http.RoundTripper) http.RoundTripper {
+			guard = resilience.New(upstreams, base)
+			return guard
+		})
+		OnClose(s, func() error {
+			restore()
+			return nil
+		})
+	}
+
+	if f.AuditLog != "" {
+		auditLogger, err := audit.Open(audit.Options{
+			Destination: f.AuditLog,
+			Rotation:    f.AuditRotation,
+			RedactKeys:  strings.Split(f.AuditRedact, ","),
+		})
+		if err != nil {
+			Close(s)
+			return err
+		}
+		features = append(features, "audit")
+		log.Printf("Recording tool calls in audit log %s", f.AuditLog)
+		OnClose(s, auditLogger.Close)
+		Use(s, auditLogger.Middleware(name))
+	}
+
+	// Tenants, policies and transforms are reloaded when their files change
+	var watched []string
+	var reloaders []func()
+	if tenants != nil {
+		features = append(features, "tenants")
+		log.Printf("Serving %d tenants from %s", len(tenants.Tenants), f.Tenants)
+		watched = append(watched, f.Tenants)
+		reloaders = append(reloaders, reloadable(s, f.Tenants, "tenants", tenants, tenant.Parse, (*tenant.Config).Middleware))
+	}
+	if toolPolicy != nil {
+		features = append(features, "policy")
+		log.Printf("Enforcing tool policy %s", f.Policy)
+		watched = append(watched, f.Policy)
+		reloaders = append(reloaders, reloadable(s, f.Policy, "policy", toolPolicy, policy.Parse, (*policy.Policy).Middleware))
+	}
+	if f.MaxArgsSize > 0 || f.MaxResultSize > 0 {
+		var spill *sizelimit.Spill
+		if f.SpillTruncated {
+			spill = sizelimit.NewSpill(s, name, spilledResults)
+		}
+		Use(s, sizelimit.Middleware(sizelimit.Limits{Args: f.MaxArgsSize, Result: f.MaxResultSize}, spill))
+	}
+	if transforms != nil {
+		// Inside the size limit, which applies to transformed results
+		features = append(features, "transforms")
+		log.Printf("Transforming tool results with %d transforms from %s", len(transforms.Transforms), f.Transforms)
+		watched = append(watched, f.Transforms)
+		reloaders = append(reloaders, reloadable(s, f.Transforms, "transforms", transforms, transform.Parse, (*transform.Config).Middleware))
+	}
+	if len(watched) > 0 {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		OnClose(s, func() error {
+			cancel()
+			return nil
+		})
+		reload.Watch(watchCtx, watched, func() {
+			for _, reload := range reloaders {
+				reload()
+			}
+		})
+	}
+	if len(rules) > 0 {
+		features = append(features, "rate-limit")
+		Use(s, ratelimit.New(rules).Middleware())
+	}
+	if f.Cache != "" {
+		c, err := cache.Open(f.Cache)
+		if err != nil {
+			Close(s)
+			return err
+		}
+		features = append(features, "cache")
+		where := f.Cache
+		if u, err := url.Parse(where); err == nil && u.User != nil {
+			where = u.Redacted()
+		}
+		log.Printf("Caching tool results in %s", where)
+		OnClose(s, c.Close)
+		Use(s, cache.Middleware(name, c, stateOf(s).cachePolicy, ttls))
+	}
+	// Calls answered from the cache do not wait for a slot
+	if f.MaxConcurrent > 0 || len(limits) > 0 {
+		features = append(features, "concurrency-limit")
+		Use(s, concurrency.New(f.MaxConcurrent, limits, f.QueueTimeout).Middleware())
+	}
+	// Hung calls are stopped where they run, after waiting for a slot
+	if len(timeouts) > 0 {
+		Use(s, watchdog.Middleware(timeouts))
+	}
+
+	if languages != nil {
+		AddTool(s, i18n.SetLanguageTool(), languages.SetLanguageHandler())
+	}
+
+	// Servers keeping state per client session let clients reset it
+	if sessions, ok := session.Lookup(s); ok {
+		features = append(features, "sessions")
+		if f.SessionIdleTimeout > 0 {
+			sessions.SetIdleTimeout(f.SessionIdleTimeout)
+		}
+		OnClose(s, sessions.Close)
+		AddTool(s, session.ResetTool(), sessions.ResetHandler())
+	}
+
+	if library != nil {
+		features = append(features, "prompts")
+		log.Printf("Offering %d prompts from %s", len(library.Prompts), f.Prompts)
+		library.Register(s)
+	}
+
+	// Recording and replay come last, so that they see the results of the tools
+	switch {
+	case f.Record != "":
+		features = append(features, "record")
+		log.Printf("Recording tool calls in cassette %s", f.Record)
+		recorder := testrecord.NewRecorder()
+		restore := testrecord.Intercept(recorder.Transport)
+		OnClose(s, func() error {
+			restore()
+			return recorder.Save(f.Record)
+		})
+		Use(s, recorder.Middleware())
+	case cassette != nil:
+		features = append(features, "replay")
+		log.Printf("Replaying tool calls from cassette %s", f.Replay)
+		player := testrecord.NewPlayer(cassette)
+		restore := testrecord.Intercept(func(http.RoundTripper) http.RoundTripper { return player.Transport() })
+		OnClose(s, func() error {
+			restore()
+			return nil
+		})
+		Use(s, player.Middleware())
+	}
+
+	info := serverinfo.New(name, f.fs, features)
+	if guard != nil {
+		info = info.WithUpstreams(guard.Stats)
+	}
+	AddTool(s, serverinfo.Tool(), serverinfo.Handler(info))
+
+	if ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			Close(s)
+		}()
+	}
+	return nil
+}
+
+// reloadable installs the middleware of config, parsed from file, on s. It returns a
+// function parsing file again and installing the middleware of the new config in its
+// place, unless the file is unchanged or invalid.
+func reloadable[T any](s *server.MCPServer, file, what string, config *T, parse func([]byte) (*T, error), middleware func(*T) func(server.ToolHandlerFunc) server.ToolHandlerFunc) func() {
+	var current atomic.Pointer[T]
+	current.Store(config)
+	Use(s, func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return middleware(current.Load())(next)(ctx, req)
+		}
+	})
+	last, _ := os.ReadFile(file)
+	return func() {
+		data, err := os.ReadFile(file)
+		if err != nil || bytes.Equal(data, last) {
+			return
+		}
+		config, err := parse(data)
+		if err != nil {
+			log.Printf("Warning: Keeping the previous %s, error parsing %s: %v", what, file, err)
+			return
+		}
+		last = data
+		current.Store(config)
+		log.Printf("Reloaded %s from %s", what, file)
+	}
+}
@@ -0,0 +1,261 @@
+// Package middleware layers behavior shared by the servers, such as audit logging,
+// over their tool handlers, and tracks the calls in flight so that servers can drain
+// them before shutting down.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/i18n"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Middleware wraps a tool handler, e.g. to observe or reject tool calls.
+type Middleware func(next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+// serverState is the middleware and the calls in flight of one server.
+type serverState struct {
+	mu       sync.Mutex
+	chain    []Middleware
+	closers  []func() error
+	draining bool
+	inFlight int
+	idle     chan struct{} // closed when the last call in flight ends while draining
+	// cacheable are the cache policies of the tools whose results may be cached
+	cacheable map[string]cache.Policy
+	// lang is the language of the tool descriptions, and tools the tools registered
+	// with AddTool, with their descriptions in English
+	lang  string
+	tools []server.ServerTool
+}
+
+var (
+	mu      sync.Mutex
+	servers = make(map[*server.MCPServer]*serverState)
+)
+
+func stateOf(s *server.MCPServer) *serverState {
+	mu.Lock()
+	defer mu.Unlock()
+	st, ok := servers[s]
+	if !ok {
+		st = &serverState{}
+		servers[s] = st
+	}
+	return st
+}
+
+// AddTool registers tool on s with a handler that runs through the middleware
+// installed on s with Use, including middleware installed later. Once s drains, new
+// calls are refused. Errors of the handler and the middleware reach clients as error
+// results, classified by toolerr. Secrets registered with package redact are masked
+// in the text of results, e.g. an API key in the URL of a failed request. Panics of
+// the handler and the middleware are recovered as Internal errors, see Recover. The
+// descriptions of the tool are translated into the language set with SetLanguage.
+//
+// mw wraps the handler of this tool only, inside the middleware of s, the first one
+// outermost, e.g. Timeout for a tool running commands.
+func AddTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc, mw ...Middleware) {
+	st := stateOf(s)
+	// The handler recovers inside the middleware, which so sees its panics as errors
+	handler = Chain(Recover()(handler), mw...)
+	recovered := Recover()
+	wrapped := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		chain, ok := st.begin()
+		if !ok {
+			return mcp.NewToolResultError(i18n.T(st.language(), "The server is shutting down, try again later")), nil
+		}
+		defer st.end()
+		result, err := recovered(Chain(handler, chain...))(ctx, req)
+		if err != nil {
+			result = toolerr.Result(err)
+		}
+		return redactResult(result), nil
+	}
+	st.mu.Lock()
+	st.tools = append(st.tools, server.ServerTool{Tool: tool, Handler: wrapped})
+	lang := st.lang
+	st.mu.Unlock()
+	s.AddTool(i18n.Tool(lang, tool), wrapped)
+}
+
+// SetLanguage translates the descriptions of the tools of s registered with AddTool,
+// and of those registered later, into lang.
+func SetLanguage(s *server.MCPServer, lang string) {
+	st := stateOf(s)
+	st.mu.Lock()
+	st.lang = lang
+	tools := make([]server.ServerTool, len(st.tools))
+	for i, t := range st.tools {
+		tools[i] = server.ServerTool{Tool: i18n.Tool(lang, t.Tool), Handler: t.Handler}
+	}
+	st.mu.Unlock()
+	s.AddTools(tools...)
+}
+
+func (st *serverState) language() string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.lang == "" {
+		return i18n.English
+	}
+	return st.lang
+}
+
+// redactResult returns result with the secrets in its text masked, copying it if
+// any are found, as cached results are shared.
+func redactResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	var redacted *mcp.CallToolResult
+	for i, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		masked := redact.Secrets(text.Text)
+		if masked == text.Text {
+			continue
+		}
+		if redacted == nil {
+			copied := *result
+			copied.Content = append([]mcp.Content(nil), result.Content...)
+			redacted = &copied
+		}
+		text.Text = masked
+		redacted.Content[i] = text
+	}
+	if redacted == nil {
+		return result
+	}
+	if e, ok := result.Meta["error"].(*toolerr.Error); ok {
+		copied := *e
+		copied.Message = redact.Secrets(e.Message)
+		redacted.Meta = maps.Clone(result.Meta)
+		redacted.Meta["error"] = &copied
+	}
+	return redacted
+}
+
+func (st *serverState) begin() ([]Middleware, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.draining {
+		return nil, false
+	}
+	st.inFlight++
+	return st.chain, true
+}
+
+func (st *serverState) end() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.inFlight--
+	if st.inFlight == 0 && st.idle != nil {
+		close(st.idle)
+		st.idle = nil
+	}
+}
+
+// Use installs mw on the tools of s registered with AddTool. Middleware runs in the
+// order it is installed, the first one outermost.
+func Use(s *server.MCPServer, mw ...Middleware) {
+	st := stateOf(s)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.chain = append(st.chain[:len(st.chain):len(st.chain)], mw...)
+}
+
+// Chain wraps handler in mw, the first middleware outermost.
+func Chain(handler server.ToolHandlerFunc, mw ...Middleware) server.ToolHandlerFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	return handler
+}
+
+// Drain makes s refuse new tool calls and waits for the calls in flight to end. It
+// fails if ctx is done first.
+func Drain(ctx context.Context, s *server.MCPServer) error {
+	st := stateOf(s)
+	st.mu.Lock()
+	st.draining = true
+	if st.inFlight == 0 {
+		st.mu.Unlock()
+		return nil
+	}
+	if st.idle == nil {
+		st.idle = make(chan struct{})
+	}
+	idle := st.idle
+	st.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return fmt.Errorf("%d tool calls still running", st.inFlight)
+	}
+}
+
+// Cacheable lets the results of tool on s be cached with p, once a cache is selected
+// with -cache.
+func Cacheable(s *server.MCPServer, tool string, p cache.Policy) {
+	st := stateOf(s)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.cacheable == nil {
+		st.cacheable = make(map[string]cache.Policy)
+	}
+	st.cacheable[tool] = p
+}
+
+func (st *serverState) cachePolicy(tool string) (cache.Policy, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	p, ok := st.cacheable[tool]
+	return p, ok
+}
+
+// OnClose registers fn to release something the middleware of s holds.
+func OnClose(s *server.MCPServer, fn func() error) {
+	st := stateOf(s)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.closers = append(st.closers, fn)
+}
+
+// Close removes the middleware of s and releases what it holds, e.g. flushing
+// pending audit entries and spans. Errors are logged. Closing again does nothing.
+func Close(s *server.MCPServer) {
+	mu.Lock()
+	st, ok := servers[s]
+	delete(servers, s)
+	mu.Unlock()
+	if !ok {
+		return
+	}
+
+	st.mu.Lock()
+	fns := st.closers
+	st.chain, st.closers = nil, nil
+	st.mu.Unlock()
+	// Undo in reverse order, so that e.g. wrapped transports are restored in turn
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](); err != nil {
+			log.Printf("Warning: Failed to close tool middleware: %v", err)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/i18n"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/policy"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/session"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// record returns middleware appending name to calls around each call.
+func record(calls *[]string, name string) Middleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			*calls = append(*calls, name)
+			result, err := next(ctx, req)
+			*calls = append(*calls, name+" done")
+			return result, err
+		}
+	}
+}
+
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]interface{}) string {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+	require.NoError(t, err)
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": json.RawMessage(params),
+	})
+	require.NoError(t, err)
+	response := s.HandleMessage(context.Background(), message)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	data, err := json.Marshal(response.(mcp.JSONRPCResponse).Result)
+	require.NoError(t, err)
+	raw := json.RawMessage(data)
+	result, err := mcp.ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	return mcptest.ResultText(result)
+}
+
+// Test that tools run through the middleware installed before and after registration
+func TestAddToolAndUse(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	var calls []string
+	Use(s, record(&calls, "first"))
+	AddTool(s, mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "echo")
+		return mcp.NewToolResultText("ok"), nil
+	})
+	Use(s, record(&calls, "second"))
+
+	callTool(t, s, "echo", nil)
+	assert.Equal(t, []string{"first", "second", "echo", "second done", "first done"}, calls)
+
+	// Middleware is kept per server
+	other := server.NewMCPServer("other", "1.0.0")
+	calls = nil
+	AddTool(other, mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "echo")
+		return mcp.NewToolResultText("ok"), nil
+	})
+	callTool(t, other, "echo", nil)
+	assert.Equal(t, []string{"echo"}, calls)
+}
+
+// Test layering middleware on single tools, by tool name and with timeouts
+func TestToolMiddleware(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	var calls []string
+	echo := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, req.Params.Name)
+		return mcp.NewToolResultText("ok"), nil
+	}
+	Use(s, record(&calls, "server"), ForTools(record(&calls, "get"), "get*"))
+	AddTool(s, mcp.NewTool("getItem"), echo, Stack(record(&calls, "outer"), record(&calls, "inner")))
+	AddTool(s, mcp.NewTool("saveItem"), echo)
+
+	callTool(t, s, "getItem", nil)
+	assert.Equal(t, []string{"server", "get", "outer", "inner", "getItem", "inner done", "outer done", "get done", "server done"}, calls)
+	calls = nil
+	callTool(t, s, "saveItem", nil)
+	assert.Equal(t, []string{"server", "saveItem", "server done"}, calls)
+
+	AddTool(s, mcp.NewTool("wait"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("wait: %w", ctx.Err())
+	}, Timeout(10*time.Millisecond))
+	assert.Equal(t, "wait: context deadline exceeded", callTool(t, s, "wait", nil))
+
+	handler := Timeout(0)(echo)
+	_, err := handler(context.Background(), mcptest.NewCallToolRequest("echo", nil))
+	assert.NoError(t, err)
+}
+
+// Test that handler errors reach clients as error results
+func TestAddToolErrors(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	AddTool(s, mcp.NewTool("lookup"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, toolerr.New(toolerr.NotFound, "item 7 not found")
+	})
+	AddTool(s, mcp.NewTool("fail"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+
+	c := mcptest.Connect(t, s)
+	assert.Equal(t, "item 7 not found", c.Error("lookup", nil))
+	result, err := c.CallTool("fail", nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "boom", mcptest.ResultText(result))
+	data, err := json.Marshal(result.Meta)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error": {"code": "internal", "message": "boom", "retryable": false}}`, string(data))
+}
+
+// Test that panics of handlers and middleware fail the call, not the server
+func TestAddToolRecovers(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	var calls []string
+	Use(s, record(&calls, "audit"))
+	AddTool(s, mcp.NewTool("crash"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var m map[string]int
+		m["boom"]++
+		return nil, nil
+	})
+	AddTool(s, mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	c := mcptest.Connect(t, s)
+	result, err := c.CallTool("crash", map[string]interface{}{"query": "q", "password": "hunter2"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "crash failed with an internal error", mcptest.ResultText(result))
+	data, err := json.Marshal(result.Meta)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error": {"code": "internal", "message": "crash failed with an internal error", "retryable": false}}`, string(data))
+	assert.Equal(t, []string{"audit", "audit done"}, calls, "The middleware should see the panic as an error")
+	assert.Contains(t, logged.String(), "Tool crash panicked: assignment to entry in nil map")
+	assert.Contains(t, logged.String(), `"query":"q"`)
+	assert.Contains(t, logged.String(), "middleware.TestAddToolRecovers")
+	assert.NotContains(t, logged.String(), "hunter2")
+	assert.Equal(t, "ok", c.Text("echo", nil), "The server should keep serving")
+
+	// Middleware panicking
+	Use(s, func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			panic("broken middleware")
+		}
+	})
+	assert.Equal(t, "echo failed with an internal error", c.Error("echo", nil))
+	assert.Contains(t, logged.String(), "Tool echo panicked: broken middleware")
+}
+
+// Test that registered secrets are masked in tool results
+func TestAddToolRedacts(t *testing.T) {
+	redact.AddSecret("middleware-test-secret")
+	s := server.NewMCPServer("test", "1.0.0")
+	shared := mcp.NewToolResultText("key=middleware-test-secret")
+	AddTool(s, mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return shared, nil
+	})
+	AddTool(s, mcp.NewTool("fail"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, toolerr.Errorf(toolerr.Unavailable, "GET https://api.example.com/?key=%s failed", "middleware-test-secret")
+	})
+
+	c := mcptest.Connect(t, s)
+	assert.Equal(t, "key=[REDACTED]", c.Text("echo", nil))
+	assert.Equal(t, "key=middleware-test-secret", mcptest.ResultText(shared), "Results should be copied, not masked in place")
+	result, err := c.CallTool("fail", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "GET https://api.example.com/?key=[REDACTED] failed", mcptest.ResultText(result))
+	data, err := json.Marshal(result.Meta)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "middleware-test-secret")
+}
+
+// Test that the audit flags install the audit logger
+func TestFlagsApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var flags Flags
+	flags.Register(fs)
+	require.NoError(t, fs.Parse([]string{"-audit-log", path, "-audit-redact", "note"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := server.NewMCPServer("test", "1.0.0")
+	AddTool(s, mcp.NewTool("save"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("saved"), nil
+	})
+	require.NoError(t, flags.Apply(ctx, "notes", s))
+
+	callTool(t, s, "save", map[string]interface{}{"title": "todo", "note": "private"})
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "notes", entry["server"])
+	assert.Equal(t, "save", entry["tool"])
+	assert.Equal(t, "success", entry["outcome"])
+	assert.Equal(t, map[string]interface{}{"title": "todo", "note": "[REDACTED]"}, entry["arguments"])
+
+	// getServerInfo reports the features and flags
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(callTool(t, s, "getServerInfo", nil)), &info))
+	assert.Equal(t, "notes", info["name"])
+	assert.Equal(t, []interface{}{"audit"}, info["features"])
+	assert.Equal(t, map[string]interface{}{"audit-log": path, "audit-redact": "note"}, info["flags"])
+
+	// Without -audit-log nothing is installed
+	assert.NoError(t, Flags{}.Apply(ctx, "notes", server.NewMCPServer("test", "1.0.0")))
+	assert.EqualError(t, Flags{Record: "a.json", Replay: "b.json"}.Apply(ctx, "notes", server.NewMCPServer("test", "1.0.0")),
+		"--record and --replay cannot be used together")
+}
+
+// Test that -cache caches the results of the tools the server made cacheable
+func TestFlagsApplyCache(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var flags Flags
+	flags.Register(fs)
+	require.NoError(t, fs.Parse([]string{"-cache", "memory:10", "-cache-ttl", "count=1m"}))
+
+	s := server.NewMCPServer("test", "1.0.0")
+	calls := 0
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("ok"), nil
+	}
+	AddTool(s, mcp.NewTool("count"), handler)
+	AddTool(s, mcp.NewTool("uncached"), handler)
+	Cacheable(s, "count", cache.Policy{})
+	require.NoError(t, flags.Apply(context.Background(), "test", s))
+	defer Close(s)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "ok", callTool(t, s, "count", map[string]interface{}{"n": 1}))
+	}
+	assert.Equal(t, 1, calls)
+	callTool(t, s, "count", map[string]interface{}{"n": 2})
+	assert.Equal(t, 2, calls)
+	callTool(t, s, "uncached", nil)
+	callTool(t, s, "uncached", nil)
+	assert.Equal(t, 4, calls)
+
+	assert.ErrorContains(t, Flags{Cache: "floppy"}.Apply(context.Background(), "test", server.NewMCPServer("test", "1.0.0")), `invalid cache "floppy"`)
+}
+
+// Test that servers keeping state per session get the resetSession tool
+func TestFlagsApplySessions(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	sessions := session.Scoped(s)
+	require.NoError(t, Flags{}.Apply(context.Background(), "test", s))
+
+	assert.Equal(t, "The session had no state to reset", callTool(t, s, session.ResetToolName, nil))
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(callTool(t, s, "getServerInfo", nil)), &info))
+	assert.Equal(t, []interface{}{"sessions"}, info["features"])
+
+	// Closing the server drops the state of its sessions
+	Close(s)
+	_, ok := session.Lookup(s)
+	assert.False(t, ok)
+	assert.Equal(t, 0, sessions.Len())
+}
+
+// Test that -lang translates the tool descriptions and adds the setLanguage tool
+func TestFlagsApplyLanguage(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var flags Flags
+	flags.Register(fs)
+	require.NoError(t, fs.Parse([]string{"-lang", "ko_KR.UTF-8"}))
+
+	s := server.NewMCPServer("test", "1.0.0")
+	AddTool(s, mcp.NewTool("searchGoogle",
+		mcp.WithDescription("Performs a Google search and returns the results"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("The search query string")),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args struct {
+			Query string `json:"query" param:"required"`
+		}
+		if err := params.Decode(req, &args); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(i18n.FromContext(ctx)), nil
+	})
+	require.NoError(t, flags.Apply(context.Background(), "test", s))
+	defer Close(s)
+
+	c := mcptest.Connect(t, s)
+	tool := c.Tool("searchGoogle")
+	assert.Equal(t, "Google 검색을 수행하고 결과를 반환합니다", tool.Description)
+	assert.Equal(t, "검색어", tool.InputSchema.Properties["query"].(map[string]interface{})["description"])
+	assert.Equal(t, "ko", c.Text("searchGoogle", map[string]interface{}{"query": "go"}))
+	assert.Equal(t, "잘못된 매개변수: query은(는) 필수입니다", c.Error("searchGoogle", nil))
+
+	c.Text(i18n.SetLanguageToolName, map[string]interface{}{"language": "en"})
+	assert.Equal(t, "en", c.Text("searchGoogle", map[string]interface{}{"query": "go"}))
+	assert.Equal(t, "invalid parameters: query is required", c.Error("searchGoogle", nil))
+
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(c.Text("getServerInfo", nil)), &info))
+	assert.Equal(t, []interface{}{"i18n", "sessions"}, info["features"])
+
+	assert.EqualError(t, Flags{Lang: "fr"}.Apply(context.Background(), "test", server.NewMCPServer("test", "1.0.0")),
+		`-lang: unsupported language "fr": use en, ja, ko`)
+}
+
+// Test that reloading a policy file replaces the policy unless it is invalid
+func TestReloadable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("default: allow\n"), 0644))
+	p, err := policy.Load(file)
+	require.NoError(t, err)
+	s := server.NewMCPServer("test", "1.0.0")
+	AddTool(s, mcp.NewTool("count"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	reload := reloadable(s, file, "policy", p, policy.Parse, (*policy.Policy).Middleware)
+	assert.Equal(t, "ok", callTool(t, s, "count", nil))
+
+	require.NoError(t, os.WriteFile(file, []byte("default: deny\n"), 0644))
+	reload()
+	assert.Equal(t, "Calling count is not allowed by policy", callTool(t, s, "count", nil))
+
+	require.NoError(t, os.WriteFile(file, []byte("default: maybe\n"), 0644))
+	reload()
+	assert.Equal(t, "Calling count is not allowed by policy", callTool(t, s, "count", nil), "The previous policy is kept")
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"runtime/debug"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/audit"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Recover returns middleware turning a panic of the handler it wraps into an
+// Internal error, so that a bug in one tool fails the call rather than the server
+// process and the sessions of all its clients. The panic is logged with its stack,
+// the tool, the client session and the arguments, secrets masked. AddTool installs
+// it on every tool.
+func Recover() Middleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				session := "none"
+				if s := server.ClientSessionFromContext(ctx); s != nil {
+					session = s.SessionID()
+				}
+				args, _ := json.Marshal(audit.NewRedactor().Redact(req.Params.Arguments))
+				log.Printf("Error: Tool %s panicked: %v\nsession: %s\narguments: %.1000s\n%s", req.Params.Name, r, session, args, debug.Stack())
+				result, err = nil, toolerr.Errorf(toolerr.Internal, "%s failed with an internal error", req.Params.Name)
+			}()
+			return next(ctx, req)
+		}
+	}
+}
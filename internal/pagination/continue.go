@@ -0,0 +1,168 @@
+package pagination
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// ContinueToolName is the name of the tool reading the next page of a result.
+const ContinueToolName = "continueResult"
+
+const (
+	// maxKept is the number of results whose pages are kept per server.
+	maxKept = 100
+	// keepFor is how long the pages of a result are kept.
+	keepFor = 30 * time.Minute
+)
+
+// kept is a result split into pages.
+type kept struct {
+	header  string
+	items   []string
+	sep     string
+	size    int
+	expires time.Time
+}
+
+// Continuations keeps the pages of the results of a server for continueResult.
+type Continuations struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	results map[string]*kept
+	order   []string // IDs of results, oldest first
+}
+
+var (
+	mu            sync.Mutex
+	continuations = make(map[*server.MCPServer]*Continuations)
+)
+
+// Continue returns the continuations of s, adding the continueResult tool to s on
+// first use.
+func Continue(s *server.MCPServer) *Continuations {
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := continuations[s]
+	if ok {
+		return c
+	}
+	c = &Continuations{now: time.Now, results: make(map[string]*kept)}
+	continuations[s] = c
+	middleware.AddTool(s, mcp.NewTool(ContinueToolName,
+		mcp.WithDescription("Returns the next page of a long result of another tool of this server, given the nextCursor of its previous page"),
+		mcp.WithString(CursorParam,
+			mcp.Description("nextCursor of the previous page"),
+			mcp.Required(),
+		),
+	), c.handleContinue)
+	middleware.OnClose(s, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(continuations, s)
+		return nil
+	})
+	return c
+}
+
+// Paginate returns the first page of a result made of header followed by items
+// joined by sep, with at most size items a page. The other pages are kept for a while
+// and read with continueResult.
+func (c *Continuations) Paginate(header string, items []string, sep string, size int) *mcp.CallToolResult {
+	r := &kept{header: header, items: items, sep: sep, size: size}
+	if len(items) <= size {
+		return mcp.NewToolResultText(r.page(0))
+	}
+	id := c.keep(r)
+	return c.result(r, id, 0)
+}
+
+// keep keeps r and returns its ID, dropping expired results and the oldest ones
+// beyond maxKept.
+func (c *Continuations) keep(r *kept) string {
+	b := make([]byte, 12)
+	// crypto/rand.Read does not fail
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	r.expires = now.Add(keepFor)
+	for len(c.order) > 0 {
+		oldest := c.results[c.order[0]]
+		if len(c.order) < maxKept && now.Before(oldest.expires) {
+			break
+		}
+		delete(c.results, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.results[id] = r
+	c.order = append(c.order, id)
+	return id
+}
+
+// lookup returns the result of id, unless it expired.
+func (c *Continuations) lookup(id string) (*kept, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.results[id]
+	if !ok || !c.now().Before(r.expires) {
+		return nil, false
+	}
+	return r, true
+}
+
+// page returns the page of r starting at item offset.
+func (r *kept) page(offset int) string {
+	end := min(offset+r.size, len(r.items))
+	text := strings.Join(r.items[offset:end], r.sep)
+	if offset == 0 && r.header != "" && text != "" {
+		text = r.header + r.sep + text
+	} else if offset == 0 && r.header != "" {
+		text = r.header
+	}
+	return text
+}
+
+// result returns the page of r with ID id starting at item offset.
+func (c *Continuations) result(r *kept, id string, offset int) *mcp.CallToolResult {
+	end := min(offset+r.size, len(r.items))
+	result := mcp.NewToolResultText(r.page(offset))
+	if end == len(r.items) {
+		return result
+	}
+	next := encode(cursor{Offset: end, Key: id})
+	note := fmt.Sprintf("Showing items %d-%d of %d. Call %s with cursor %q for the next ones", offset+1, end, len(r.items), ContinueToolName, next)
+	return annotate(result, next, note)
+}
+
+func (c *Continuations) handleContinue(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Cursor string `json:"cursor" param:"required"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	cur, err := decode(args.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := c.lookup(cur.Key)
+	if !ok || cur.Offset >= len(r.items) {
+		return nil, toolerr.Errorf(toolerr.NotFound, "the result of this cursor is no longer kept; results are kept for %s", keepFor)
+	}
+	return c.result(r, cur.Key, cur.Offset), nil
+}
@@ -0,0 +1,142 @@
+// Package pagination splits large tool results into pages, so that no tool returns
+// thousands of items at once. Tools that can list their items again from any
+// position take a cursor argument and return the cursor of the next page, as
+// nextCursor in the _meta of their result (Start, Page). Tools whose results are
+// costly to produce again, such as crawls, return the first page and keep the rest,
+// read with the continueResult tool (Continue).
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// CursorParam is the argument of paginated tools taking the cursor of a page.
+const CursorParam = "cursor"
+
+// Param returns the cursor parameter of a paginated tool.
+func Param() mcp.ToolOption {
+	return mcp.WithString(CursorParam,
+		mcp.Description("nextCursor of the previous call, to get the next page of results with the same arguments"),
+	)
+}
+
+// cursor is the content of the opaque cursors given to clients.
+type cursor struct {
+	Offset int    `json:"o"`
+	Key    string `json:"k"`
+}
+
+func encode(c cursor) string {
+	// Marshaling a struct of an int and a string cannot fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Offset < 0 {
+		return cursor{}, toolerr.New(toolerr.InvalidParams, "invalid cursor")
+	}
+	return c, nil
+}
+
+// Pager is the position of a paginated call in the list of its items.
+type Pager struct {
+	// Offset is the number of items before the page.
+	Offset int
+	key    string
+}
+
+// Start returns the pager of a call, at the offset of its cursor argument or at the
+// start. Cursors are bound to the other arguments of the call that issued them,
+// except those named in ignore, such as an offset the cursor supersedes, so that a
+// cursor of another query is refused.
+func Start(req mcp.CallToolRequest, ignore ...string) (Pager, error) {
+	args := make(map[string]interface{}, len(req.Params.Arguments))
+	for name, value := range req.Params.Arguments {
+		args[name] = value
+	}
+	delete(args, CursorParam)
+	for _, name := range ignore {
+		delete(args, name)
+	}
+	// Maps are encoded with sorted keys, so equal arguments give equal keys
+	data, err := json.Marshal(args)
+	if err != nil {
+		return Pager{}, err
+	}
+	sum := sha256.Sum256(append([]byte(req.Params.Name+"\x00"), data...))
+	p := Pager{key: hex.EncodeToString(sum[:8])}
+
+	s, _ := req.Params.Arguments[CursorParam].(string)
+	if s == "" {
+		return p, nil
+	}
+	c, err := decode(s)
+	if err != nil {
+		return Pager{}, err
+	}
+	if c.Key != p.key {
+		return Pager{}, toolerr.New(toolerr.InvalidParams, "cursor belongs to a call with other arguments")
+	}
+	p.Offset = c.Offset
+	return p, nil
+}
+
+// Cursor returns the cursor of the page starting after offset items.
+func (p Pager) Cursor(offset int) string {
+	return encode(cursor{Offset: offset, Key: p.key})
+}
+
+// Page returns the page of at most limit items at the cursor of req, and the cursor
+// of the next page, "" on the last page.
+func Page[T any](req mcp.CallToolRequest, items []T, limit int) ([]T, string, error) {
+	p, err := Start(req)
+	if err != nil {
+		return nil, "", err
+	}
+	start := min(p.Offset, len(items))
+	end := min(start+limit, len(items))
+	if end == len(items) {
+		return items[start:end], "", nil
+	}
+	return items[start:end], p.Cursor(end), nil
+}
+
+// Result adds next to result, as nextCursor in its _meta and in a note ending its
+// text, unless next is empty.
+func Result(result *mcp.CallToolResult, next string) *mcp.CallToolResult {
+	return annotate(result, next, fmt.Sprintf("More results: call again with cursor %q", next))
+}
+
+// annotate adds next to result as nextCursor in its _meta, and note to its text.
+func annotate(result *mcp.CallToolResult, next, note string) *mcp.CallToolResult {
+	if next == "" {
+		return result
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta["nextCursor"] = next
+	for i := len(result.Content) - 1; i >= 0; i-- {
+		if text, ok := result.Content[i].(mcp.TextContent); ok {
+			text.Text = strings.TrimRight(text.Text, "\n") + "\n\n" + note
+			result.Content[i] = text
+			break
+		}
+	}
+	return result
+}
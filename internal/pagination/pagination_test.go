@@ -0,0 +1,96 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+func request(args map[string]interface{}) mcp.CallToolRequest {
+	return mcptest.NewCallToolRequest("list", args)
+}
+
+// Test paging through a list with cursors
+func TestPage(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	var pages [][]int
+	cursor := ""
+	for {
+		args := map[string]interface{}{"query": "x"}
+		if cursor != "" {
+			args[CursorParam] = cursor
+		}
+		page, next, err := Page(request(args), items, 2)
+		require.NoError(t, err)
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, pages)
+}
+
+// Test that cursors are refused when invalid or used with other arguments
+func TestStartErrors(t *testing.T) {
+	p, err := Start(request(map[string]interface{}{"query": "x", "offset": 10}), "offset")
+	require.NoError(t, err)
+	assert.Equal(t, 0, p.Offset)
+	cursor := p.Cursor(10)
+
+	// Ignored arguments may change
+	p, err = Start(request(map[string]interface{}{"query": "x", "offset": 0, CursorParam: cursor}), "offset")
+	require.NoError(t, err)
+	assert.Equal(t, 10, p.Offset)
+
+	_, err = Start(request(map[string]interface{}{"query": "y", CursorParam: cursor}), "offset")
+	assert.EqualError(t, err, "cursor belongs to a call with other arguments")
+	_, err = Start(request(map[string]interface{}{"query": "x", CursorParam: "not a cursor"}))
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+// Test that results report the next cursor in _meta and text
+func TestResult(t *testing.T) {
+	result := Result(mcp.NewToolResultText("a\nb\n"), "abc")
+	assert.Equal(t, "abc", result.Meta["nextCursor"])
+	assert.Equal(t, "a\nb\n\nMore results: call again with cursor \"abc\"", mcptest.ResultText(result))
+
+	result = Result(mcp.NewToolResultText("a"), "")
+	assert.Nil(t, result.Meta)
+	assert.Equal(t, "a", mcptest.ResultText(result))
+}
+
+// Test reading the pages of a long result with continueResult
+func TestContinue(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	defer middleware.Close(s)
+	c := Continue(s)
+	assert.Same(t, c, Continue(s))
+
+	result := c.Paginate("Header", []string{"a", "b", "c", "d", "e"}, "\n", 2)
+	cursor := result.Meta["nextCursor"].(string)
+	assert.Equal(t, "Header\na\nb\n\nShowing items 1-2 of 5. Call continueResult with cursor \""+cursor+"\" for the next ones", mcptest.ResultText(result))
+
+	client := mcptest.Connect(t, s)
+	result, err := client.CallTool(ContinueToolName, map[string]interface{}{"cursor": cursor})
+	require.NoError(t, err)
+	cursor = result.Meta["nextCursor"].(string)
+	assert.Equal(t, "c\nd\n\nShowing items 3-4 of 5. Call continueResult with cursor \""+cursor+"\" for the next ones", mcptest.ResultText(result))
+	assert.Equal(t, "e", client.Text(ContinueToolName, map[string]interface{}{"cursor": cursor}))
+
+	// Short results are not kept
+	result = c.Paginate("Header", []string{"a"}, "\n", 2)
+	assert.Equal(t, "Header\na", mcptest.ResultText(result))
+	assert.Nil(t, result.Meta)
+
+	// Results expire
+	c.now = func() time.Time { return time.Now().Add(keepFor) }
+	assert.Contains(t, client.Error(ContinueToolName, map[string]interface{}{"cursor": cursor}), "no longer kept")
+}
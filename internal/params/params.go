@@ -0,0 +1,332 @@
+// Package params decodes the arguments of tool calls into structs and validates them
+// following the param tags of the fields, so that handlers get typed parameters and
+// clients get errors naming the parameter at fault.
+package params
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Error is an invalid argument of a tool call.
+type Error struct {
+	// Param is the name of the parameter, empty when the arguments as a whole are invalid.
+	Param   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Param == "" {
+		return "invalid parameters: " + e.Message
+	}
+	return "invalid parameters: " + e.Param + " " + e.Message
+}
+
+// Decode decodes the arguments of req into v, a pointer to a struct, and checks them
+// against the param tags of its fields. Tags hold comma separated rules:
+//
+//	required      the argument must be set, and not empty if it is a string
+//	empty         with required, an empty string is a valid argument
+//	enum=a|b|c    the argument, if set, must be one of the values
+//	min=n, max=n  numbers must be within the bounds, strings and lists must have
+//	              that many characters or items
+//	default=v     the value of the argument when it is not set, for strings,
+//	              numbers and booleans
+//
+// The fields of embedded structs are decoded as fields of v. Numbers and booleans
+// sent as strings, as some clients do, are converted. Errors are *Error.
+func Decode(req mcp.CallToolRequest, v interface{}) error {
+	return Unmarshal(req.Params.Arguments, v)
+}
+
+// Unmarshal decodes and checks args into v like Decode.
+func Unmarshal(args map[string]interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("params: %T is not a pointer to a struct", v)
+	}
+	fields, err := fieldsOf(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	args = coerce(withDefaults(args, fields), fields)
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return &Error{Message: err.Error()}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &Error{Param: typeErr.Field, Message: fmt.Sprintf("must be %s, not %s", kindName(typeErr.Type), typeErr.Value)}
+		}
+		return &Error{Message: err.Error()}
+	}
+
+	for _, f := range fields {
+		if err := f.check(args[f.name], rv.Elem().FieldByIndex(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field is a struct field with its rules.
+type field struct {
+	name  string
+	index []int
+	// typ is the type of the field, kind that of the value it points to if it is a
+	// pointer
+	typ         reflect.Type
+	kind        reflect.Kind
+	description string
+	required    bool
+	allowEmpty  bool
+	enum        []string
+	min, max    *float64
+	def         interface{}
+}
+
+// fields caches the fields of the struct types decoded.
+var fields sync.Map
+
+// fieldsOf returns the fields of t with a JSON name, including those of the structs
+// it embeds.
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if cached, ok := fields.Load(t); ok {
+		return cached.([]field), nil
+	}
+	var list []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			embedded, err := fieldsOf(sf.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range embedded {
+				f.index = append([]int{i}, f.index...)
+				list = append(list, f)
+			}
+			continue
+		}
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, index: sf.Index, typ: sf.Type, kind: sf.Type.Kind(), description: sf.Tag.Get("description")}
+		if f.kind == reflect.Ptr {
+			f.kind = sf.Type.Elem().Kind()
+		}
+		for _, rule := range strings.Split(sf.Tag.Get("param"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			switch key {
+			case "":
+			case "required":
+				f.required = true
+			case "empty":
+				f.allowEmpty = true
+			case "enum":
+				f.enum = strings.Split(value, "|")
+			case "min", "max":
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("params: invalid %s rule of field %s: %q", key, sf.Name, value)
+				}
+				if key == "min" {
+					f.min = &n
+				} else {
+					f.max = &n
+				}
+			case "default":
+				def, err := parseDefault(f.kind, value)
+				if err != nil {
+					return nil, fmt.Errorf("params: invalid default rule of field %s: %w", sf.Name, err)
+				}
+				f.def = def
+			default:
+				return nil, fmt.Errorf("params: unknown rule %q of field %s", key, sf.Name)
+			}
+		}
+		list = append(list, f)
+	}
+	fields.Store(t, list)
+	return list, nil
+}
+
+// parseDefault returns the default value v of a field of kind.
+func parseDefault(kind reflect.Kind, v string) (interface{}, error) {
+	switch kind {
+	case reflect.String:
+		return v, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(v, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(v)
+	}
+	return nil, fmt.Errorf("%s fields have no default", kind)
+}
+
+// withDefaults returns args with the defaults of the fields whose argument is not
+// set, copying args if any is.
+func withDefaults(args map[string]interface{}, fields []field) map[string]interface{} {
+	copied := false
+	for _, f := range fields {
+		if f.def == nil || !f.missing(args[f.name]) {
+			continue
+		}
+		if !copied {
+			args = copyMap(args)
+			copied = true
+		}
+		args[f.name] = f.def
+	}
+	return args
+}
+
+// coerce returns args with the strings given for number and boolean fields
+// converted, copying args if any is.
+func coerce(args map[string]interface{}, fields []field) map[string]interface{} {
+	copied := false
+	for _, f := range fields {
+		s, ok := args[f.name].(string)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		switch f.kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				continue
+			}
+			value = n
+		case reflect.Bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(s))
+			if err != nil {
+				continue
+			}
+			value = b
+		default:
+			continue
+		}
+		if !copied {
+			args = copyMap(args)
+			copied = true
+		}
+		args[f.name] = value
+	}
+	return args
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// missing reports whether arg, the argument given for f, is not set.
+func (f field) missing(arg interface{}) bool {
+	return arg == nil || (arg == "" && !f.allowEmpty)
+}
+
+// check checks the argument given for f, decoded into value.
+func (f field) check(arg interface{}, value reflect.Value) error {
+	if f.missing(arg) {
+		if f.required {
+			return &Error{Param: f.name, Message: "is required"}
+		}
+		return nil
+	}
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if f.enum != nil {
+		s := fmt.Sprint(value.Interface())
+		found := false
+		for _, allowed := range f.enum {
+			found = found || s == allowed
+		}
+		if !found {
+			return &Error{Param: f.name, Message: fmt.Sprintf("must be one of %s, not %q", strings.Join(f.enum, ", "), s)}
+		}
+	}
+
+	if f.min == nil && f.max == nil {
+		return nil
+	}
+	var n float64
+	var unit string
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	case reflect.String:
+		n, unit = float64(len([]rune(value.String()))), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(value.Len()), " items"
+	default:
+		return nil
+	}
+	switch {
+	case f.min != nil && f.max != nil && (n < *f.min || n > *f.max):
+		if unit != "" {
+			return &Error{Param: f.name, Message: fmt.Sprintf("must have %v to %v%s", *f.min, *f.max, unit)}
+		}
+		return &Error{Param: f.name, Message: fmt.Sprintf("must be between %v and %v", *f.min, *f.max)}
+	case f.min != nil && n < *f.min:
+		if unit != "" {
+			return &Error{Param: f.name, Message: fmt.Sprintf("must have at least %v%s", *f.min, unit)}
+		}
+		return &Error{Param: f.name, Message: fmt.Sprintf("must be at least %v", *f.min)}
+	case f.max != nil && n > *f.max:
+		if unit != "" {
+			return &Error{Param: f.name, Message: fmt.Sprintf("must have at most %v%s", *f.max, unit)}
+		}
+		return &Error{Param: f.name, Message: fmt.Sprintf("must be at most %v", *f.max)}
+	}
+	return nil
+}
+
+// kindName describes the values of t in errors.
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
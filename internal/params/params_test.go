@@ -0,0 +1,147 @@
+package params
+
+import (
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+type searchParams struct {
+	Query   string   `json:"query" param:"required"`
+	Num     int      `json:"num,omitempty" param:"min=1,max=10"`
+	Order   string   `json:"order,omitempty" param:"enum=asc|desc"`
+	Exact   bool     `json:"exact,omitempty"`
+	Tags    []string `json:"tags,omitempty" param:"max=2"`
+	Comment string   `json:"comment,omitempty" param:"min=3"`
+	Score   *float64 `json:"score,omitempty" param:"min=0"`
+}
+
+// Test that arguments are decoded and checked against the rules of the fields
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		name string
+		args map[string]interface{}
+		err  string
+	}{
+		{name: "Valid", args: map[string]interface{}{"query": "go", "num": 5, "order": "asc", "tags": []string{"a"}}},
+		{name: "Missing", args: map[string]interface{}{"num": 5}, err: "invalid parameters: query is required"},
+		{name: "Empty", args: map[string]interface{}{"query": ""}, err: "invalid parameters: query is required"},
+		{name: "Range", args: map[string]interface{}{"query": "go", "num": 11}, err: "invalid parameters: num must be between 1 and 10"},
+		{name: "Enum", args: map[string]interface{}{"query": "go", "order": "up"}, err: `invalid parameters: order must be one of asc, desc, not "up"`},
+		{name: "Type", args: map[string]interface{}{"query": "go", "num": []int{1}}, err: "invalid parameters: num must be an integer, not array"},
+		{name: "Fraction", args: map[string]interface{}{"query": "go", "num": 1.5}, err: "invalid parameters: num must be an integer, not number 1.5"},
+		{name: "Items", args: map[string]interface{}{"query": "go", "tags": []string{"a", "b", "c"}}, err: "invalid parameters: tags must have at most 2 items"},
+		{name: "Characters", args: map[string]interface{}{"query": "go", "comment": "éé"}, err: "invalid parameters: comment must have at least 3 characters"},
+		{name: "Pointer", args: map[string]interface{}{"query": "go", "score": -1}, err: "invalid parameters: score must be at least 0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var p searchParams
+			err := Decode(mcptest.NewCallToolRequest("search", tc.args), &p)
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "go", p.Query)
+				return
+			}
+			require.Error(t, err)
+			assert.EqualError(t, err, tc.err)
+			var paramErr *Error
+			assert.ErrorAs(t, err, &paramErr)
+		})
+	}
+}
+
+// Test that numbers and booleans sent as strings are converted
+func TestDecodeStrings(t *testing.T) {
+	args := map[string]interface{}{"query": "42", "num": " 3 ", "exact": "true", "score": "0.5"}
+	var p searchParams
+	require.NoError(t, Unmarshal(args, &p))
+	assert.Equal(t, searchParams{Query: "42", Num: 3, Exact: true, Score: &[]float64{0.5}[0]}, p)
+	assert.Equal(t, " 3 ", args["num"], "the arguments must not be modified")
+
+	err := Unmarshal(map[string]interface{}{"query": "go", "num": "three"}, &p)
+	assert.EqualError(t, err, "invalid parameters: num must be an integer, not string")
+}
+
+// Test defaults, empty required strings and the fields of embedded structs
+func TestDecodeDefaults(t *testing.T) {
+	type paging struct {
+		Limit int `json:"limit" param:"default=20,max=100"`
+	}
+	var p struct {
+		paging
+		Query   string `json:"query" param:"required,empty"`
+		Order   string `json:"order" param:"default=asc"`
+		Reverse bool   `json:"reverse" param:"default=true"`
+	}
+	require.NoError(t, Unmarshal(map[string]interface{}{"query": "", "order": ""}, &p))
+	assert.Equal(t, 20, p.Limit)
+	assert.Equal(t, "asc", p.Order)
+	assert.True(t, p.Reverse)
+
+	require.NoError(t, Unmarshal(map[string]interface{}{"query": "go", "limit": 5, "reverse": false}, &p))
+	assert.Equal(t, 5, p.Limit)
+	assert.False(t, p.Reverse)
+
+	assert.EqualError(t, Unmarshal(map[string]interface{}{}, &p), "invalid parameters: query is required")
+	assert.EqualError(t, Unmarshal(map[string]interface{}{"query": "", "limit": 200}, &p), "invalid parameters: limit must be at most 100")
+
+	var bad struct {
+		Tags []string `json:"tags" param:"default=a"`
+	}
+	assert.EqualError(t, Unmarshal(nil, &bad), "params: invalid default rule of field Tags: slice fields have no default")
+}
+
+// Test that invalid targets and rules are reported
+func TestDecodeInvalid(t *testing.T) {
+	var s string
+	assert.Error(t, Unmarshal(nil, &s))
+
+	var bad struct {
+		N int `json:"n" param:"min=x"`
+	}
+	assert.EqualError(t, Unmarshal(nil, &bad), `params: invalid min rule of field N: "x"`)
+	var unknown struct {
+		N int `json:"n" param:"positive"`
+	}
+	assert.EqualError(t, Unmarshal(nil, &unknown), `params: unknown rule "positive" of field N`)
+}
+
+// Fuzz decoding arguments: whatever the client sends, decoding fails with an *Error
+// or yields arguments following the rules
+func FuzzUnmarshal(f *testing.F) {
+	for _, seed := range []string{
+		`{"query": "go", "num": 5, "order": "asc", "tags": ["a"]}`,
+		`{"query": "go", "num": "7", "exact": "true", "score": "0.5"}`,
+		`{"query": "", "num": 1e308, "order": null}`,
+		`{"query": ["go"], "tags": "a", "comment": {}}`,
+		`{"query": "\u0000\ud800\u202e", "comment": "한국어", "score": -0}`,
+		`{"query": "go", "num": 1.5, "tags": [1, null, {}]}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var args map[string]interface{}
+		if json.Unmarshal(data, &args) != nil {
+			return
+		}
+		var p searchParams
+		err := Unmarshal(args, &p)
+		if err != nil {
+			var paramsErr *Error
+			require.ErrorAs(t, err, &paramsErr)
+			return
+		}
+		assert.NotEmpty(t, p.Query)
+		assert.True(t, p.Num == 0 || (p.Num >= 1 && p.Num <= 10), "num %d", p.Num)
+		assert.Contains(t, []string{"", "asc", "desc"}, p.Order)
+		assert.LessOrEqual(t, len(p.Tags), 2)
+		assert.True(t, p.Comment == "" || utf8.RuneCountInString(p.Comment) >= 3, "comment %q", p.Comment)
+		assert.True(t, p.Score == nil || *p.Score >= 0, "score %v", p.Score)
+	})
+}
@@ -0,0 +1,129 @@
+package params
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Tool returns the definition of the tool called name, with a parameter for each
+// field of T, the struct its handler decodes the arguments into, so that the schema
+// and the decoding do not drift apart. The description tag of a field describes its
+// parameter, and its param rules are declared in the schema: required, enum, the
+// bounds and the default. opts are applied after the parameters of T, so that they
+// can replace one, e.g. one whose description is known at run time only. Tool panics
+// if the rules of T are invalid.
+func Tool[T any](name string, opts ...mcp.ToolOption) mcp.Tool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("params: %s is not a struct", t))
+	}
+	fields, err := fieldsOf(t)
+	if err != nil {
+		panic(err)
+	}
+	options := make([]mcp.ToolOption, 0, len(fields)+len(opts)+1)
+	for _, f := range fields {
+		options = append(options, f.parameter())
+	}
+	options = append(options, opts...)
+	// Parameters replaced by opts may be required twice
+	options = append(options, func(tool *mcp.Tool) {
+		var required []string
+		for _, name := range tool.InputSchema.Required {
+			if !slices.Contains(required, name) {
+				required = append(required, name)
+			}
+		}
+		tool.InputSchema.Required = required
+	})
+	return mcp.NewTool(name, options...)
+}
+
+// parameter returns the tool option declaring the parameter of f.
+func (f field) parameter() mcp.ToolOption {
+	schema := map[string]interface{}{}
+	if typ := jsonType(f.typ); typ != "" {
+		schema["type"] = typ
+		if typ == "array" {
+			if items := jsonType(elem(f.typ).Elem()); items != "" {
+				schema["items"] = map[string]interface{}{"type": items}
+			}
+		}
+	}
+	if f.description != "" {
+		schema["description"] = f.description
+	}
+	if f.enum != nil {
+		values := make([]interface{}, len(f.enum))
+		for i, v := range f.enum {
+			values[i] = v
+			if f.kind != reflect.String {
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					values[i] = n
+				}
+			}
+		}
+		schema["enum"] = values
+	}
+	minKey, maxKey := "minimum", "maximum"
+	switch schema["type"] {
+	case "string":
+		minKey, maxKey = "minLength", "maxLength"
+	case "array":
+		minKey, maxKey = "minItems", "maxItems"
+	}
+	if f.min != nil {
+		schema[minKey] = *f.min
+	}
+	if f.max != nil {
+		schema[maxKey] = *f.max
+	}
+	if f.def != nil {
+		schema["default"] = f.def
+	}
+	return func(tool *mcp.Tool) {
+		tool.InputSchema.Properties[f.name] = schema
+		if f.required {
+			tool.InputSchema.Required = append(tool.InputSchema.Required, f.name)
+		}
+	}
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// elem returns t, or the type it points to if it is a pointer.
+func elem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonType returns the JSON Schema type of the values of t, empty for any value.
+// Integers are numbers, as in the tools declared with mcp.WithNumber.
+func jsonType(t reflect.Type) string {
+	t = elem(t)
+	if t == rawMessageType {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return ""
+}
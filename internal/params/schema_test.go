@@ -0,0 +1,64 @@
+package params
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pageParams struct {
+	Cursor string `json:"cursor,omitempty" description:"Cursor of the next page"`
+}
+
+type listParams struct {
+	pageParams
+	Query  string          `json:"query" param:"required" description:"Search query"`
+	Num    int             `json:"num,omitempty" param:"min=1,max=10,default=5" description:"Number of results"`
+	Order  string          `json:"order,omitempty" param:"enum=asc|desc"`
+	Level  *int            `json:"level,omitempty" param:"enum=1|2|3"`
+	Exact  bool            `json:"exact,omitempty" param:"default=false"`
+	Tags   []string        `json:"tags,omitempty" param:"max=2"`
+	Filter json.RawMessage `json:"filter,omitempty"`
+	Labels map[string]int  `json:"labels,omitempty"`
+	Note   string          `json:"note" param:"required,empty,min=3"`
+}
+
+// Test that tool schemas are declared from the fields of parameter structs
+func TestTool(t *testing.T) {
+	tool := Tool[listParams]("list",
+		mcp.WithDescription("Lists items"),
+		mcp.WithString("note", mcp.Description("Replaced note"), mcp.Required()),
+	)
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "list",
+		"description": "Lists items",
+		"inputSchema": {
+			"type": "object",
+			"properties": {
+				"cursor": {"type": "string", "description": "Cursor of the next page"},
+				"query": {"type": "string", "description": "Search query"},
+				"num": {"type": "number", "description": "Number of results", "minimum": 1, "maximum": 10, "default": 5},
+				"order": {"type": "string", "enum": ["asc", "desc"]},
+				"level": {"type": "number", "enum": [1, 2, 3]},
+				"exact": {"type": "boolean", "default": false},
+				"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+				"filter": {},
+				"labels": {"type": "object"},
+				"note": {"type": "string", "description": "Replaced note"}
+			},
+			"required": ["query", "note"]
+		}
+	}`, string(data))
+
+	assert.PanicsWithValue(t, "params: string is not a struct", func() { Tool[string]("bad") })
+	assert.Panics(t, func() {
+		Tool[struct {
+			N int `json:"n" param:"min=x"`
+		}]("bad")
+	})
+}
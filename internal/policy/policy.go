@@ -0,0 +1,229 @@
+// Package policy allows or denies tool calls by tool, client and arguments, following
+// rules read from a YAML or JSON file.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/identity"
+)
+
+// Effects of a rule.
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Anonymous is the client name of requests whose credentials match no client,
+// including local clients over stdio.
+const Anonymous = "anonymous"
+
+// Policy decides which tool calls are allowed. Its rules are evaluated in order and
+// the first matching one decides; calls matching none get the default effect.
+type Policy struct {
+	// Default is the effect of calls no rule matches, allow unless set.
+	Default string            `yaml:"default"`
+	Clients map[string]Client `yaml:"clients"`
+	Rules   []Rule            `yaml:"rules"`
+}
+
+// Client names the credentials of a client. Environment variables in API keys are
+// expanded, so the keys need not be stored in the file.
+type Client struct {
+	APIKeys      []string `yaml:"apiKeys"`
+	Certificates []string `yaml:"certificates"` // common names of TLS client certificates
+}
+
+// Rule applies Effect to the calls it matches.
+type Rule struct {
+	Effect string `yaml:"effect"`
+	// Tools are tool name patterns such as fetchURL or run*; all tools when empty.
+	Tools []string `yaml:"tools"`
+	// Clients are client names, or anonymous; all clients when empty.
+	Clients []string `yaml:"clients"`
+	// Arguments restrict the rule to calls whose arguments meet all the conditions.
+	Arguments map[string]Condition `yaml:"arguments"`
+	// Message tells denied clients why.
+	Message string `yaml:"message"`
+}
+
+// Condition tests an argument, formatted as text; a missing argument is empty.
+type Condition struct {
+	Match    string `yaml:"match"`    // the argument must match this regular expression
+	NotMatch string `yaml:"notMatch"` // the argument must not match this regular expression
+
+	match    *regexp.Regexp
+	notMatch *regexp.Regexp
+}
+
+// Load reads and validates a policy file.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy file %s: %w", file, err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing policy file %s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and validates a YAML or JSON policy.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch p.Default {
+	case "":
+		p.Default = Allow
+	case Allow, Deny:
+	default:
+		return nil, fmt.Errorf("default must be allow or deny, not %q", p.Default)
+	}
+	for name, c := range p.Clients {
+		if name == Anonymous {
+			return nil, fmt.Errorf("client name %s is reserved", Anonymous)
+		}
+		for i, key := range c.APIKeys {
+			if c.APIKeys[i] = os.ExpandEnv(key); c.APIKeys[i] == "" {
+				return nil, fmt.Errorf("client %s: API key %d is empty", name, i+1)
+			}
+		}
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Effect != Allow && r.Effect != Deny {
+			return nil, fmt.Errorf("rule %d: effect must be allow or deny, not %q", i+1, r.Effect)
+		}
+		for _, pattern := range r.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid tool pattern %q", i+1, pattern)
+			}
+		}
+		for _, client := range r.Clients {
+			if _, ok := p.Clients[client]; !ok && client != Anonymous {
+				return nil, fmt.Errorf("rule %d: unknown client %s", i+1, client)
+			}
+		}
+		for arg, cond := range r.Arguments {
+			var err error
+			if cond.Match != "" {
+				if cond.match, err = regexp.Compile(cond.Match); err != nil {
+					return nil, fmt.Errorf("rule %d: argument %s: %w", i+1, arg, err)
+				}
+			}
+			if cond.NotMatch != "" {
+				if cond.notMatch, err = regexp.Compile(cond.NotMatch); err != nil {
+					return nil, fmt.Errorf("rule %d: argument %s: %w", i+1, arg, err)
+				}
+			}
+			if cond.match == nil && cond.notMatch == nil {
+				return nil, fmt.Errorf("rule %d: argument %s needs match or notMatch", i+1, arg)
+			}
+			r.Arguments[arg] = cond
+		}
+	}
+	return &p, nil
+}
+
+// Client returns the name of the client presenting creds, or Anonymous.
+func (p *Policy) Client(creds identity.Credentials) string {
+	for name, c := range p.Clients {
+		for _, key := range c.APIKeys {
+			if creds.APIKey != "" && subtle.ConstantTimeCompare([]byte(creds.APIKey), []byte(key)) == 1 {
+				return name
+			}
+		}
+		for _, cn := range c.Certificates {
+			if creds.CertificateCN != "" && creds.CertificateCN == cn {
+				return name
+			}
+		}
+	}
+	return Anonymous
+}
+
+// Decide returns whether client may call tool with args and, if not, why.
+func (p *Policy) Decide(client, tool string, args map[string]interface{}) (bool, string) {
+	for _, r := range p.Rules {
+		if r.matches(client, tool, args) {
+			return r.Effect == Allow, r.Message
+		}
+	}
+	return p.Default == Allow, ""
+}
+
+func (r *Rule) matches(client, tool string, args map[string]interface{}) bool {
+	if len(r.Tools) > 0 && !matchesAny(r.Tools, tool) {
+		return false
+	}
+	if len(r.Clients) > 0 && !contains(r.Clients, client) {
+		return false
+	}
+	for name, cond := range r.Arguments {
+		value := ""
+		if v, ok := args[name]; ok && v != nil {
+			value = fmt.Sprint(v)
+		}
+		if cond.match != nil && !cond.match.MatchString(value) {
+			return false
+		}
+		if cond.notMatch != nil && cond.notMatch.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns tool middleware refusing the calls the policy denies with an
+// error result, before their handlers run.
+func (p *Policy) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			client := p.Client(identity.FromContext(ctx))
+			if ok, message := p.Decide(client, req.Params.Name, req.Params.Arguments); !ok {
+				text := fmt.Sprintf("Calling %s is not allowed by policy", req.Params.Name)
+				if message != "" {
+					text += ": " + message
+				}
+				return mcp.NewToolResultError(text), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/identity"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+const testPolicy = `
+default: allow
+clients:
+  ci:
+    apiKeys: ["${TEST_CI_KEY}"]
+  ops:
+    certificates: [ops.example.com]
+rules:
+  - effect: deny
+    tools: [fetchURL]
+    arguments:
+      url:
+        notMatch: '^https://'
+    message: only HTTPS URLs may be fetched
+  - effect: allow
+    tools: ["signal*"]
+    clients: [ops]
+  - effect: deny
+    tools: ["signal*", runCommand]
+`
+
+// Test that the first matching rule decides
+func TestDecide(t *testing.T) {
+	t.Setenv("TEST_CI_KEY", "ci-secret")
+	p, err := Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		client  string
+		tool    string
+		args    map[string]interface{}
+		allowed bool
+		message string
+	}{
+		{name: "HTTPS fetch", client: "ci", tool: "fetchURL", args: map[string]interface{}{"url": "https://example.com"}, allowed: true},
+		{name: "HTTP fetch", client: "ci", tool: "fetchURL", args: map[string]interface{}{"url": "http://example.com"}, message: "only HTTPS URLs may be fetched"},
+		{name: "Missing argument", client: Anonymous, tool: "fetchURL", message: "only HTTPS URLs may be fetched"},
+		{name: "Allowed client", client: "ops", tool: "signalProcess", allowed: true},
+		{name: "Other client", client: "ci", tool: "signalProcess"},
+		{name: "Denied tool", client: "ops", tool: "runCommand"},
+		{name: "Default", client: Anonymous, tool: "getTime", allowed: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, message := p.Decide(tc.client, tc.tool, tc.args)
+			assert.Equal(t, tc.allowed, allowed)
+			assert.Equal(t, tc.message, message)
+		})
+	}
+
+	p.Default = Deny
+	allowed, _ := p.Decide(Anonymous, "getTime", nil)
+	assert.False(t, allowed)
+}
+
+// Test invalid policies
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]string{
+		"default: maybe":                                          "default must be allow or deny",
+		"rules: [{effect: block}]":                                "effect must be allow or deny",
+		"rules: [{effect: deny, tools: ['[']}]":                   "invalid tool pattern",
+		"rules: [{effect: deny, clients: [nobody]}]":              "unknown client nobody",
+		"clients: {anonymous: {}}":                                "reserved",
+		"clients: {ci: {apiKeys: ['$UNSET_KEY_X']}}":              "API key 1 is empty",
+		"rules: [{effect: deny, arguments: {url: {}}}]":           "needs match or notMatch",
+		"rules: [{effect: deny, arguments: {url: {match: '('}}}]": "argument url",
+		"unknown: true":                                           "field unknown not found",
+	}
+	for policy, expected := range testCases {
+		_, err := Parse([]byte(policy))
+		assert.ErrorContains(t, err, expected, policy)
+	}
+}
+
+// Test that clients are identified by API key or certificate
+func TestMiddleware(t *testing.T) {
+	t.Setenv("TEST_CI_KEY", "ci-secret")
+	p, err := Parse([]byte(testPolicy))
+	require.NoError(t, err)
+	handler := p.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	call := func(ctx context.Context, tool string, args map[string]interface{}) *mcp.CallToolResult {
+		result, err := handler(ctx, mcptest.NewCallToolRequest(tool, args))
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(context.Background(), "fetchURL", map[string]interface{}{"url": "ftp://example.com"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Calling fetchURL is not allowed by policy: only HTTPS URLs may be fetched", mcptest.ResultText(result))
+
+	r := httptest.NewRequest("POST", "/message", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "ops.example.com"}}}}}
+	ctx := identity.FromRequest(context.Background(), r)
+	assert.Equal(t, "ops", p.Client(identity.FromContext(ctx)))
+	assert.Equal(t, "ok", mcptest.ResultText(call(ctx, "signalProcess", nil)))
+	assert.True(t, call(context.Background(), "signalProcess", nil).IsError)
+
+	r = httptest.NewRequest("POST", "/message", nil)
+	r.Header.Set("Authorization", "Bearer ci-secret")
+	assert.Equal(t, "ci", p.Client(identity.FromContext(identity.FromRequest(context.Background(), r))))
+	r.Header.Del("Authorization")
+	r.Header.Set("X-API-Key", "ci-secret")
+	assert.Equal(t, "ci", p.Client(identity.FromContext(identity.FromRequest(context.Background(), r))))
+	r.Header.Set("X-API-Key", "wrong")
+	assert.Equal(t, Anonymous, p.Client(identity.FromContext(identity.FromRequest(context.Background(), r))))
+}
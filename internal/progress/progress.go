@@ -0,0 +1,128 @@
+// Package progress sends MCP progress notifications for long-running tool calls, such as
+// downloads and crawls, when the client asked for them with a progress token.
+package progress
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Interval is the shortest time between two notifications of a call, except for the
+// one reporting completion.
+var Interval = 250 * time.Millisecond
+
+// Reporter sends the progress of one tool call. A nil Reporter, returned when the
+// client did not ask for progress, reports nothing.
+type Reporter struct {
+	ctx    context.Context
+	server *server.MCPServer
+	token  mcp.ProgressToken
+	total  float64
+
+	mu       sync.Mutex
+	last     time.Time
+	progress float64
+}
+
+// New returns a Reporter for the call req, or nil when the client supplied no progress
+// token. total is the progress of a completed call, e.g. a number of bytes or pages, or
+// 0 when it is unknown.
+func New(ctx context.Context, req mcp.CallToolRequest, total float64) *Reporter {
+	if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	return &Reporter{ctx: ctx, server: mcpServer, token: req.Params.Meta.ProgressToken, total: total}
+}
+
+// Report sends progress, throttled to one notification per Interval unless it reaches
+// the total.
+func (r *Reporter) Report(progress float64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.progress = progress
+	due := r.due()
+	r.mu.Unlock()
+	if due {
+		r.send(progress)
+	}
+}
+
+// Add reports n more units of progress, e.g. one more page crawled.
+func (r *Reporter) Add(n float64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.progress += n
+	progress, due := r.progress, r.due()
+	r.mu.Unlock()
+	if due {
+		r.send(progress)
+	}
+}
+
+// due tells whether the current progress is to be sent, with r.mu held.
+func (r *Reporter) due() bool {
+	if r.progress != r.total && time.Since(r.last) < Interval {
+		return false
+	}
+	r.last = time.Now()
+	return true
+}
+
+func (r *Reporter) send(progress float64) {
+	params := map[string]interface{}{
+		"progressToken": r.token,
+		"progress":      progress,
+	}
+	if r.total > 0 {
+		params["total"] = r.total
+	}
+	if err := r.server.SendNotificationToClient(r.ctx, "notifications/progress", params); err != nil {
+		log.Printf("Warning: Failed to send progress notification: %v", err)
+	}
+}
+
+// Func returns a callback reporting a count of bytes transferred so far, or nil when r
+// is nil.
+func (r *Reporter) Func() func(int64) {
+	if r == nil {
+		return nil
+	}
+	return func(n int64) { r.Report(float64(n)) }
+}
+
+// Reader returns a reader of rd reporting the bytes read.
+func (r *Reporter) Reader(rd io.Reader) io.Reader {
+	if r == nil {
+		return rd
+	}
+	return &reader{r: rd, report: r}
+}
+
+type reader struct {
+	r      io.Reader
+	n      int64
+	report *Reporter
+}
+
+func (pr *reader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.n += int64(n)
+	if n > 0 {
+		pr.report.Report(float64(pr.n))
+	}
+	return n, err
+}
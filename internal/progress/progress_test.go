@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type session struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *session) SessionID() string                                   { return "test" }
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *session) Initialize()                                         {}
+func (s *session) Initialized() bool                                   { return true }
+
+// call runs the tool "work", reporting with report, with or without a progress token,
+// and returns the notifications sent.
+func call(t *testing.T, token interface{}, report func(ctx context.Context, req mcp.CallToolRequest)) []map[string]interface{} {
+	ctx := context.Background()
+	s := server.NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("work"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report(ctx, req)
+		return mcp.NewToolResultText("done"), nil
+	})
+	client := &session{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	require.NoError(t, s.RegisterSession(ctx, client))
+
+	params := map[string]interface{}{"name": "work"}
+	if token != nil {
+		params["_meta"] = map[string]interface{}{"progressToken": token}
+	}
+	message, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": params})
+	require.NoError(t, err)
+	s.HandleMessage(s.WithContext(ctx, client), message)
+
+	var sent []map[string]interface{}
+	for len(client.notifications) > 0 {
+		n := <-client.notifications
+		assert.Equal(t, "notifications/progress", n.Method)
+		sent = append(sent, n.Params.AdditionalFields)
+	}
+	return sent
+}
+
+// Test that progress is throttled and always sent on completion
+func TestReport(t *testing.T) {
+	sent := call(t, "abc", func(ctx context.Context, req mcp.CallToolRequest) {
+		r := New(ctx, req, 3)
+		require.NotNil(t, r)
+		r.Add(1)
+		r.Add(1)
+		r.Add(1)
+	})
+	assert.Equal(t, []map[string]interface{}{
+		{"progressToken": "abc", "progress": 1.0, "total": 3.0},
+		{"progressToken": "abc", "progress": 3.0, "total": 3.0},
+	}, sent)
+
+	defer func(interval time.Duration) { Interval = interval }(Interval)
+	Interval = 0
+	sent = call(t, 7.0, func(ctx context.Context, req mcp.CallToolRequest) {
+		r := New(ctx, req, 0)
+		data, err := io.ReadAll(r.Reader(strings.NewReader("hello")))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+	assert.Equal(t, []map[string]interface{}{{"progressToken": 7.0, "progress": 5.0}}, sent)
+}
+
+// Test that nothing is sent without a progress token
+func TestNoToken(t *testing.T) {
+	sent := call(t, nil, func(ctx context.Context, req mcp.CallToolRequest) {
+		r := New(ctx, req, 10)
+		assert.Nil(t, r)
+		r.Add(1)
+		r.Report(10)
+		assert.Nil(t, r.Func())
+		rd := strings.NewReader("x")
+		assert.Same(t, rd, r.Reader(rd))
+	})
+	assert.Empty(t, sent)
+}
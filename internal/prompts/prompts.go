@@ -0,0 +1,198 @@
+// Package prompts serves a library of reusable, parameterized prompts, declared by
+// operators in a YAML or JSON file, through prompts/list and prompts/get. Prompt
+// messages are Go templates rendered with the arguments of the request, e.g.
+// "Summarize {{.url}} in {{.length}} sentences".
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// Library is a set of prompts by name.
+type Library struct {
+	Prompts map[string]Prompt `yaml:"prompts"`
+}
+
+// Prompt is a parameterized prompt.
+type Prompt struct {
+	Description string     `yaml:"description"`
+	Arguments   []Argument `yaml:"arguments"`
+	// Template is the text of a single user message; set it or Messages.
+	Template string    `yaml:"template"`
+	Messages []Message `yaml:"messages"`
+}
+
+// Argument is a parameter of a prompt.
+type Argument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	// Default is used when an optional argument is not given.
+	Default string `yaml:"default"`
+}
+
+// Message is a message of a prompt, by the user or the assistant.
+type Message struct {
+	Role string `yaml:"role"`
+	Text string `yaml:"text"`
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Load reads and validates a prompt library file.
+func Load(file string) (*Library, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading prompts file %s: %w", file, err)
+	}
+	l, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing prompts file %s: %w", file, err)
+	}
+	return l, nil
+}
+
+// Parse decodes and validates a YAML or JSON prompt library.
+func Parse(data []byte) (*Library, error) {
+	var l Library
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&l); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := Validate(l.Prompts); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Validate checks the names, arguments and templates of prompts, turning their
+// Template into a single user message.
+func Validate(prompts map[string]Prompt) error {
+	for name, p := range prompts {
+		if !validName.MatchString(name) {
+			return fmt.Errorf("prompt %q: names may only contain letters, digits, _, . and -", name)
+		}
+		switch {
+		case p.Template != "" && len(p.Messages) > 0:
+			return fmt.Errorf("prompt %s: set template or messages, not both", name)
+		case p.Template != "":
+			p.Messages = []Message{{Role: string(mcp.RoleUser), Text: p.Template}}
+			p.Template = ""
+		case len(p.Messages) == 0:
+			return fmt.Errorf("prompt %s: no template or messages", name)
+		}
+
+		// Rendering with every argument catches templates using undeclared ones
+		sample := make(map[string]string)
+		for i, arg := range p.Arguments {
+			if arg.Name == "" {
+				return fmt.Errorf("prompt %s: argument %d has no name", name, i+1)
+			}
+			if _, dup := sample[arg.Name]; dup {
+				return fmt.Errorf("prompt %s: argument %s is declared twice", name, arg.Name)
+			}
+			if arg.Required && arg.Default != "" {
+				return fmt.Errorf("prompt %s: required argument %s cannot have a default", name, arg.Name)
+			}
+			sample[arg.Name] = "x"
+		}
+		for i, m := range p.Messages {
+			if m.Role != string(mcp.RoleUser) && m.Role != string(mcp.RoleAssistant) {
+				return fmt.Errorf("prompt %s: message %d: role must be user or assistant, not %q", name, i+1, m.Role)
+			}
+			if _, err := render(m.Text, sample); err != nil {
+				return fmt.Errorf("prompt %s: message %d: %w", name, i+1, err)
+			}
+		}
+		prompts[name] = p
+	}
+	return nil
+}
+
+// render executes text as a template of args. Using an argument missing from args
+// is an error.
+func render(text string, args map[string]string) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, args); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Register adds the prompts of l to s.
+func (l *Library) Register(s *server.MCPServer) {
+	Register(s, l.Prompts)
+}
+
+// Register adds prompts, validated with Validate, to s.
+func Register(s *server.MCPServer, prompts map[string]Prompt) {
+	names := make([]string, 0, len(prompts))
+	for name := range prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := prompts[name]
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(p.Description)}
+		for _, arg := range p.Arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+			if arg.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+		}
+		s.AddPrompt(mcp.NewPrompt(name, opts...), p.handler)
+	}
+}
+
+// Render returns the messages of p with the arguments substituted.
+func (p Prompt) Render(args map[string]string) (*mcp.GetPromptResult, error) {
+	values := make(map[string]string, len(p.Arguments))
+	var missing []string
+	for _, arg := range p.Arguments {
+		value, ok := args[arg.Name]
+		switch {
+		case ok:
+		case arg.Required:
+			missing = append(missing, arg.Name)
+		default:
+			value = arg.Default
+		}
+		values[arg.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments: %s", strings.Join(missing, ", "))
+	}
+
+	result := &mcp.GetPromptResult{Description: p.Description}
+	for i, m := range p.Messages {
+		text, err := render(m.Text, values)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		result.Messages = append(result.Messages, mcp.NewPromptMessage(mcp.Role(m.Role), mcp.NewTextContent(text)))
+	}
+	return result, nil
+}
+
+func (p Prompt) handler(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return p.Render(req.Params.Arguments)
+}
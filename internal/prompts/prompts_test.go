@@ -0,0 +1,104 @@
+package prompts
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const library = `
+prompts:
+  summarize-url:
+    description: Summarize a web page
+    arguments:
+      - name: url
+        description: Page to summarize
+        required: true
+      - name: sentences
+        default: "3"
+    template: Fetch {{.url}} and summarize it in {{.sentences}} sentences.
+  compare-timezones:
+    arguments:
+      - name: from
+        required: true
+      - name: to
+        required: true
+      - name: time
+    messages:
+      - role: user
+        text: What time is it in {{.to}} when it is {{if .time}}{{.time}}{{else}}now{{end}} in {{.from}}?
+      - role: assistant
+        text: I will look up both timezones.
+`
+
+// Test rendering prompts with their arguments
+func TestRender(t *testing.T) {
+	l, err := Parse([]byte(library))
+	require.NoError(t, err)
+	require.Len(t, l.Prompts, 2)
+
+	result, err := l.Prompts["summarize-url"].Render(map[string]string{"url": "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize a web page", result.Description)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+	assert.Equal(t, "Fetch https://example.com and summarize it in 3 sentences.", result.Messages[0].Content.(mcp.TextContent).Text)
+
+	result, err = l.Prompts["compare-timezones"].Render(map[string]string{"from": "UTC", "to": "Asia/Seoul"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 2)
+	assert.Equal(t, "What time is it in Asia/Seoul when it is now in UTC?", result.Messages[0].Content.(mcp.TextContent).Text)
+	assert.Equal(t, mcp.RoleAssistant, result.Messages[1].Role)
+
+	_, err = l.Prompts["compare-timezones"].Render(map[string]string{"from": "UTC"})
+	assert.EqualError(t, err, "missing required arguments: to")
+}
+
+// Test that invalid libraries are rejected
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"prompts:\n  a b:\n    template: x": `prompt "a b": names may only contain letters, digits, _, . and -`,
+		"prompts:\n  p: {}":                 "prompt p: no template or messages",
+		"prompts:\n  p:\n    template: x\n    messages: [{role: user, text: y}]":                  "prompt p: set template or messages, not both",
+		"prompts:\n  p:\n    messages: [{role: system, text: y}]":                                 `prompt p: message 1: role must be user or assistant, not "system"`,
+		"prompts:\n  p:\n    template: '{{.missing}}'":                                            `prompt p: message 1: template: prompt:1:2: executing "prompt" at <.missing>: map has no entry for key "missing"`,
+		"prompts:\n  p:\n    template: '{{.x'":                                                    "prompt p: message 1: template: prompt:1: unclosed action",
+		"prompts:\n  p:\n    arguments: [{name: x}, {name: x}]\n    template: x":                  "prompt p: argument x is declared twice",
+		"prompts:\n  p:\n    arguments: [{name: x, required: true, default: y}]\n    template: x": "prompt p: required argument x cannot have a default",
+		"prompt: {}": "yaml: unmarshal errors:\n  line 1: field prompt not found in type prompts.Library",
+	}
+	for data, want := range tests {
+		_, err := Parse([]byte(data))
+		assert.EqualError(t, err, want, data)
+	}
+}
+
+// Test serving the prompts through prompts/get
+func TestRegister(t *testing.T) {
+	l, err := Parse([]byte(library))
+	require.NoError(t, err)
+	s := server.NewMCPServer("test", "1.0.0")
+	l.Register(s)
+
+	get := func(args map[string]string) string {
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0", "id": 1, "method": "prompts/get",
+			"params": map[string]interface{}{"name": "summarize-url", "arguments": args},
+		})
+		require.NoError(t, err)
+		response, err := json.Marshal(s.HandleMessage(context.Background(), message))
+		require.NoError(t, err)
+		return string(response)
+	}
+	assert.Contains(t, get(map[string]string{"url": "https://example.com", "sentences": "2"}), "Fetch https://example.com and summarize it in 2 sentences.")
+	assert.Contains(t, get(nil), "missing required arguments: url")
+
+	response, err := json.Marshal(s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`)))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), `{"name":"url","description":"Page to summarize","required":true}`)
+}
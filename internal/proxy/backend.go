@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/inprocess"
+	"github.com/mark3labs/mcphost/internal/secretref"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// backend is a connection to a downstream server.
+type backend interface {
+	Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error)
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	OnNotification(handler func(notification mcp.JSONRPCNotification))
+	Close() error
+}
+
+// sseReadTimeout is how long an SSE connection to a downstream server is kept open.
+const sseReadTimeout = 100 * 365 * 24 * time.Hour
+
+// connect starts or connects to a downstream server and initializes the session.
+func connect(ctx context.Context, bc BackendConfig) (backend, error) {
+	var b backend
+	switch {
+	case bc.Command != "":
+		env, err := secretref.Environ(bc.Env)
+		if err != nil {
+			return nil, err
+		}
+		c, err := mcpclient.NewStdioMCPClient(bc.Command, env, bc.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start server: %w", err)
+		}
+		b = c
+	case bc.URL != "":
+		// The client stops reading events after its read timeout, so make it last
+		c, err := mcpclient.NewSSEMCPClient(bc.URL, mcpclient.WithSSEReadTimeout(sseReadTimeout))
+		if err != nil {
+			return nil, err
+		}
+		// The event stream outlives ctx, which only bounds the connection setup
+		streamCtx, cancel := context.WithCancel(context.Background())
+		if err := c.Start(streamCtx); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		b = &sseClient{SSEMCPClient: c, cancel: cancel}
+	default:
+		bundled, _ := servers.Lookup(bc.Server)
+		// Background work of the server, such as scheduled jobs, lasts until Close
+		serverCtx, cancel := context.WithCancel(context.Background())
+		s, flags, err := bundled.New(serverCtx, bc.Args)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create server: %w", err)
+		}
+		if flags != defaultTransportFlags() {
+			cancel()
+			return nil, errors.New("transport flags cannot be used with a bundled server, the proxy runs it in process")
+		}
+		c, err := inprocess.NewClient(serverCtx, cancel, s)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		b = c
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcphost-proxy",
+		Version: "1.0.0",
+	}
+	if _, err := b.Initialize(ctx, initRequest); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	return b, nil
+}
+
+// sseClient closes the event stream along with the client, which the SSE client of
+// mcp-go leaves open.
+type sseClient struct {
+	*mcpclient.SSEMCPClient
+	cancel context.CancelFunc
+}
+
+func (c *sseClient) Close() error {
+	c.cancel()
+	return c.SSEMCPClient.Close()
+}
+
+// defaultTransportFlags returns the transport flags of a server started without any.
+func defaultTransportFlags() transport.Flags {
+	var flags transport.Flags
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags.Register(fs)
+	return flags
+}
@@ -0,0 +1,221 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/prompts"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSeparator joins a backend name and a tool name, as in fetch__fetchURL. It
+// matches the namespacing of the chat host and is valid in every LLM API's tool names.
+const defaultSeparator = "__"
+
+// AggregateSeparator joins a server name and a tool name in the tools aggregated by
+// mcphost aggregate, as in fetch.fetchURL.
+const AggregateSeparator = "."
+
+// Conflict policies for two servers exposing a tool under the same name.
+const (
+	ConflictPriority = "priority"
+	ConflictError    = "error"
+)
+
+// Config declares the downstream servers of the proxy. It is read from YAML or JSON.
+type Config struct {
+	Separator string                   `yaml:"separator"`
+	Servers   map[string]BackendConfig `yaml:"servers"`
+
+	// Conflicts decides what happens when servers expose a tool under the same name:
+	// priority keeps the tool of the server with the highest priority, or else the
+	// first server by name, while error refuses to start.
+	Conflicts string `yaml:"conflicts"`
+
+	// MCPServers and the mcpServers block of MCPServersFile declare external servers
+	// in the Claude Desktop format. They are added to Servers.
+	MCPServers     map[string]mcpconfig.Server `yaml:"mcpServers"`
+	MCPServersFile string                      `yaml:"mcpServersFile"`
+
+	// Prompts are offered to clients through prompts/list and prompts/get.
+	Prompts map[string]prompts.Prompt `yaml:"prompts"`
+}
+
+// BackendConfig declares one downstream server: a bundled server run in process, named
+// by Server or else by its key in Config.Servers, an external stdio Command or the URL
+// of an SSE endpoint.
+type BackendConfig struct {
+	Server   string            `yaml:"server"`
+	Command  string            `yaml:"command"`
+	Args     []string          `yaml:"args"`
+	Env      map[string]string `yaml:"env"`
+	URL      string            `yaml:"url"`
+	Disabled bool              `yaml:"disabled"`
+
+	// Prefix namespaces the tools of the server as prefix + separator + tool. It
+	// defaults to the server's key; an empty prefix exposes the tools unchanged.
+	Prefix *string `yaml:"prefix"`
+	// Aliases expose tools under other names, used as they are without a prefix.
+	Aliases map[string]string `yaml:"aliases"`
+	// Hide lists tools not to expose, as names or path.Match patterns.
+	Hide []string `yaml:"hide"`
+	// Priority decides which server keeps a tool name that several expose.
+	Priority int `yaml:"priority"`
+}
+
+// LoadConfig reads and validates a config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig decodes a YAML or JSON config, fills in defaults and validates it.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if cfg.Separator == "" {
+		cfg.Separator = defaultSeparator
+	}
+	switch cfg.Conflicts {
+	case "":
+		cfg.Conflicts = ConflictPriority
+	case ConflictPriority, ConflictError:
+	default:
+		return nil, fmt.Errorf("invalid conflicts policy %q; use priority or error", cfg.Conflicts)
+	}
+	if err := prompts.Validate(cfg.Prompts); err != nil {
+		return nil, err
+	}
+	external, err := mcpconfig.Merge(cfg.MCPServers, cfg.MCPServersFile)
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range external {
+		if _, ok := cfg.Servers[name]; ok {
+			return nil, fmt.Errorf("server %s is declared in both servers and mcpServers", name)
+		}
+		if cfg.Servers == nil {
+			cfg.Servers = make(map[string]BackendConfig)
+		}
+		cfg.Servers[name] = BackendConfig{Command: s.Command, Args: s.Args, Env: s.Env}
+	}
+
+	enabled := 0
+	for name, bc := range cfg.Servers {
+		kinds := 0
+		for _, set := range []bool{bc.Server != "", bc.Command != "", bc.URL != ""} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds > 1 {
+			return nil, fmt.Errorf("server %s: set only one of server, command and url", name)
+		}
+		if bc.URL != "" && len(bc.Args) > 0 {
+			return nil, fmt.Errorf("server %s: args cannot be used with url", name)
+		}
+		for _, pattern := range bc.Hide {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("server %s: invalid hide pattern %q", name, pattern)
+			}
+		}
+		for tool, alias := range bc.Aliases {
+			if alias == "" {
+				return nil, fmt.Errorf("server %s: empty alias for tool %s", name, tool)
+			}
+		}
+		if bc.Command == "" && len(bc.Env) > 0 {
+			// Bundled servers run in the proxy process and share its environment
+			return nil, fmt.Errorf("server %s: env can only be used with command", name)
+		}
+		if bc.Command == "" && bc.URL == "" {
+			if bc.Server == "" {
+				bc.Server = name
+			}
+			if _, ok := servers.Lookup(bc.Server); !ok {
+				return nil, fmt.Errorf("server %s: unknown bundled server %q", name, bc.Server)
+			}
+		}
+		if !bc.Disabled {
+			enabled++
+		}
+		cfg.Servers[name] = bc
+	}
+	if enabled == 0 {
+		return nil, fmt.Errorf("no servers configured")
+	}
+	return &cfg, nil
+}
+
+// BundledConfig returns the config of a proxy running the named bundled servers in
+// process as one server. Their tools are exposed unprefixed, as each server exposes
+// them on its own, so two servers with a tool of the same name cannot be combined.
+func BundledConfig(names []string) (*Config, error) {
+	cfg := &Config{
+		Separator: defaultSeparator,
+		Conflicts: ConflictError,
+		Servers:   make(map[string]BackendConfig),
+	}
+	noPrefix := ""
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := servers.Lookup(name); !ok {
+			return nil, fmt.Errorf("unknown server %q, see mcphost list", name)
+		}
+		if _, ok := cfg.Servers[name]; ok {
+			return nil, fmt.Errorf("server %s is listed twice", name)
+		}
+		cfg.Servers[name] = BackendConfig{Server: name, Prefix: &noPrefix}
+	}
+	if len(cfg.Servers) == 0 {
+		return nil, fmt.Errorf("no servers configured")
+	}
+	return cfg, nil
+}
+
+// AggregateConfig returns the config of a proxy connecting to the stdio servers of an
+// mcpServers config as a client, exposing each tool as server + separator + tool. Two
+// servers whose tools end up with the same name cannot be combined.
+func AggregateConfig(mcpServers map[string]mcpconfig.Server, separator string) (*Config, error) {
+	if separator == "" {
+		return nil, fmt.Errorf("the separator must not be empty")
+	}
+	external, err := mcpconfig.Merge(mcpServers, "")
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		Separator: separator,
+		Conflicts: ConflictError,
+		Servers:   make(map[string]BackendConfig),
+	}
+	for name, s := range external {
+		cfg.Servers[name] = BackendConfig{Command: s.Command, Args: s.Args, Env: s.Env}
+	}
+	if len(cfg.Servers) == 0 {
+		return nil, fmt.Errorf("no servers configured")
+	}
+	return cfg, nil
+}
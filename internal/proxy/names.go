@@ -0,0 +1,44 @@
+package proxy
+
+import (
+	"path"
+	"sort"
+)
+
+// exposedName returns the name a tool of a server is exposed under, or false if the
+// tool is hidden.
+func exposedName(name string, bc BackendConfig, separator, tool string) (string, bool) {
+	for _, pattern := range bc.Hide {
+		if matched, _ := path.Match(pattern, tool); matched {
+			return "", false
+		}
+	}
+	if alias, ok := bc.Aliases[tool]; ok {
+		return alias, true
+	}
+	prefix := name
+	if bc.Prefix != nil {
+		prefix = *bc.Prefix
+	}
+	if prefix == "" {
+		return tool, true
+	}
+	return prefix + separator + tool, true
+}
+
+// serverOrder sorts the server names by descending priority and then by name. Servers
+// earlier in the order keep the tool names that several servers expose.
+func serverOrder(servers map[string]BackendConfig) []string {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := servers[names[i]].Priority, servers[names[j]].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
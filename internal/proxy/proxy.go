@@ -0,0 +1,354 @@
+// Package proxy merges the tools of several MCP servers into a single MCP server.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/prompts"
+	"github.com/mark3labs/mcphost/internal/serverinfo"
+)
+
+// route is the downstream server and tool name behind an exposed tool.
+type route struct {
+	backend string
+	tool    string
+}
+
+// exposedTool is a tool of a downstream server as it is exposed.
+type exposedTool struct {
+	route
+	tool mcp.Tool
+}
+
+// progressRoute is the client call behind a progress token passed downstream.
+type progressRoute struct {
+	ctx   context.Context
+	token mcp.ProgressToken
+}
+
+// connection is a connected downstream server and its tools.
+type connection struct {
+	config BackendConfig
+	client backend
+	tools  []mcp.Tool
+}
+
+// ProxyServer is an MCP server exposing the tools of downstream servers, each prefixed
+// with the name of its server.
+type ProxyServer struct {
+	server *server.MCPServer
+	// reloading serializes reloads, which connect servers without holding mu
+	reloading sync.Mutex
+	prompts   map[string]prompts.Prompt
+
+	mu        sync.Mutex
+	backends  map[string]*connection
+	exposed   map[string]exposedTool
+	sessions  map[string]server.ClientSession // connected clients, which receive relayed notifications
+	progress  map[string]progressRoute
+	nextToken atomic.Int64
+}
+
+// Open connects to the enabled servers of cfg and creates a ProxyServer exposing their
+// tools. It fails if any server cannot be reached.
+func Open(ctx context.Context, cfg *Config) (*ProxyServer, error) {
+	p := &ProxyServer{
+		prompts:  cfg.Prompts,
+		backends: make(map[string]*connection),
+		exposed:  make(map[string]exposedTool),
+		sessions: make(map[string]server.ClientSession),
+		progress: make(map[string]progressRoute),
+	}
+	// Downstream notifications arrive outside of requests, so keep track of the
+	// client sessions to relay them to
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		p.mu.Lock()
+		p.sessions[session.SessionID()] = session
+		p.mu.Unlock()
+	})
+	mcpServer := server.NewMCPServer(
+		"proxy-server", // server name
+		"1.0.0",        // version
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		// Reloading the config changes the tools
+		server.WithToolCapabilities(true),
+	)
+	p.server = mcpServer
+	prompts.Register(mcpServer, cfg.Prompts)
+
+	if err := p.Reload(ctx, cfg); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload applies cfg to a running proxy: it connects the servers added or changed,
+// disconnects those removed, changed or disabled, and updates the exposed tools, which
+// notifies the clients with tools/list_changed. Calls in flight to a server that is
+// disconnected fail. If a server cannot be reached or the tools conflict, nothing
+// changes. Prompts are only read by Open.
+func (p *ProxyServer) Reload(ctx context.Context, cfg *Config) error {
+	p.reloading.Lock()
+	defer p.reloading.Unlock()
+	if !reflect.DeepEqual(cfg.Prompts, p.prompts) {
+		log.Printf("Warning: Prompts changed, restart the proxy to offer them")
+	}
+
+	p.mu.Lock()
+	current := p.backends
+	p.mu.Unlock()
+	backends := make(map[string]*connection)
+	var connected []*connection
+	fail := func(err error) error {
+		for _, c := range connected {
+			c.client.Close()
+		}
+		return err
+	}
+	for _, name := range serverOrder(cfg.Servers) {
+		bc := cfg.Servers[name]
+		if bc.Disabled {
+			continue
+		}
+		if c, ok := current[name]; ok && reflect.DeepEqual(c.config, bc) {
+			backends[name] = c
+			continue
+		}
+		log.Printf("Connecting to server %s", name)
+		c, err := open(ctx, bc)
+		if err != nil {
+			return fail(fmt.Errorf("server %s: %w", name, err))
+		}
+		c.client.OnNotification(p.relay(name))
+		connected = append(connected, c)
+		backends[name] = c
+	}
+	exposed, err := expose(cfg, backends)
+	if err != nil {
+		return fail(err)
+	}
+
+	p.mu.Lock()
+	previous := p.exposed
+	p.backends = backends
+	p.exposed = exposed
+	p.mu.Unlock()
+
+	// Tools are only added, replaced and deleted when they change, as each change
+	// notifies the clients
+	var removed []string
+	for name := range previous {
+		if _, ok := exposed[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		sort.Strings(removed)
+		log.Printf("Removing tools %s", strings.Join(removed, ", "))
+		p.server.DeleteTools(removed...)
+	}
+	for name, t := range exposed {
+		if old, ok := previous[name]; ok && reflect.DeepEqual(old, t) {
+			continue
+		}
+		middleware.AddTool(p.server, t.tool, p.forward(t.backend, t.route.tool))
+	}
+	for name, c := range current {
+		if backends[name] != c {
+			log.Printf("Disconnecting from server %s", name)
+			if err := c.client.Close(); err != nil {
+				log.Printf("Error: Failed to close server %s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// open connects to a downstream server and lists its tools.
+func open(ctx context.Context, bc BackendConfig) (*connection, error) {
+	b, err := connect(ctx, bc)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := b.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	return &connection{config: bc, client: b, tools: tools.Tools}, nil
+}
+
+// expose returns the tools of the servers by the name they are exposed under.
+func expose(cfg *Config, backends map[string]*connection) (map[string]exposedTool, error) {
+	exposed := make(map[string]exposedTool)
+	for _, name := range serverOrder(cfg.Servers) {
+		c, ok := backends[name]
+		if !ok {
+			continue
+		}
+		bc := cfg.Servers[name]
+		exposedCount := 0
+		known := make(map[string]bool)
+		for _, tool := range c.tools {
+			known[tool.Name] = true
+			exposedAs, ok := exposedName(name, bc, cfg.Separator, tool.Name)
+			if !ok || exposedAs == serverinfo.ToolName {
+				// The proxy answers getServerInfo itself
+				continue
+			}
+			if t, ok := exposed[exposedAs]; ok {
+				if cfg.Conflicts == ConflictError {
+					return nil, fmt.Errorf("server %s: tool %s is also exposed by server %s", name, exposedAs, t.backend)
+				}
+				log.Printf("Warning: Tool %s of server %s is not exposed, server %s already exposes %s", tool.Name, name, t.backend, exposedAs)
+				continue
+			}
+			t := exposedTool{route: route{backend: name, tool: tool.Name}, tool: tool}
+			t.tool.Name = exposedAs
+			exposed[exposedAs] = t
+			exposedCount++
+		}
+		for tool := range bc.Aliases {
+			if !known[tool] {
+				log.Printf("Warning: Server %s has no tool %s to alias", name, tool)
+			}
+		}
+		log.Printf("Server %s: %d of %d tools exposed", name, exposedCount, len(c.tools))
+	}
+	return exposed, nil
+}
+
+// forward returns a handler passing tool calls on to a downstream server.
+func (p *ProxyServer) forward(name, tool string) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		log.Printf("Forwarding %s to server %s", tool, name)
+		downstream := mcp.CallToolRequest{}
+		downstream.Params.Name = tool
+		downstream.Params.Arguments = req.Params.Arguments
+		if req.Params.Meta != nil {
+			meta := *req.Params.Meta
+			if meta.ProgressToken != nil {
+				// Clients pick their own tokens, so give the downstream server one unique
+				// across clients and map its progress notifications back
+				token := fmt.Sprintf("%s-%d", name, p.nextToken.Add(1))
+				p.mu.Lock()
+				p.progress[token] = progressRoute{ctx: ctx, token: meta.ProgressToken}
+				p.mu.Unlock()
+				defer func() {
+					p.mu.Lock()
+					delete(p.progress, token)
+					p.mu.Unlock()
+				}()
+				meta.ProgressToken = token
+			}
+			downstream.Params.Meta = &meta
+		}
+		p.mu.Lock()
+		c, ok := p.backends[name]
+		p.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: server was removed", name)
+		}
+		result, err := c.client.CallTool(ctx, downstream)
+		if err != nil {
+			log.Printf("Error: Server %s failed to call %s: %v", name, tool, err)
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return result, nil
+	}
+}
+
+// relay returns a handler passing the notifications of a downstream server on to the
+// clients: progress to the client whose call it belongs to, anything else to every
+// client. The tools are listed when a server connects, so list changes are not
+// relayed; reloading the config reconnects the servers whose config changed.
+func (p *ProxyServer) relay(name string) func(notification mcp.JSONRPCNotification) {
+	return func(notification mcp.JSONRPCNotification) {
+		if strings.HasSuffix(notification.Method, "/list_changed") {
+			log.Printf("Ignoring %s from server %s", notification.Method, name)
+			return
+		}
+		params := make(map[string]interface{}, len(notification.Params.AdditionalFields))
+		for k, v := range notification.Params.AdditionalFields {
+			params[k] = v
+		}
+
+		if token, ok := params["progressToken"]; ok {
+			key, _ := token.(string)
+			p.mu.Lock()
+			route, ok := p.progress[key]
+			p.mu.Unlock()
+			if !ok {
+				// The call has already completed
+				return
+			}
+			params["progressToken"] = route.token
+			if err := p.server.SendNotificationToClient(route.ctx, notification.Method, params); err != nil {
+				log.Printf("Error: Failed to relay %s from server %s: %v", notification.Method, name, err)
+			}
+			return
+		}
+
+		p.mu.Lock()
+		sessions := make([]server.ClientSession, 0, len(p.sessions))
+		for _, session := range p.sessions {
+			sessions = append(sessions, session)
+		}
+		p.mu.Unlock()
+		for _, session := range sessions {
+			ctx := p.server.WithContext(context.Background(), session)
+			if err := p.server.SendNotificationToClient(ctx, notification.Method, params); err != nil {
+				// mcp-go does not report disconnects, and the notification channel of a
+				// disconnected session fills up, so a session that cannot take one is gone
+				log.Printf("Error: Failed to relay %s from server %s to session %s, forgetting it: %v", notification.Method, name, session.SessionID(), err)
+				p.mu.Lock()
+				delete(p.sessions, session.SessionID())
+				p.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Tools returns the exposed tool names, sorted.
+func (p *ProxyServer) Tools() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.exposed))
+	for name := range p.exposed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close disconnects from the downstream servers, stopping those it started.
+func (p *ProxyServer) Close() {
+	p.mu.Lock()
+	backends := p.backends
+	p.backends = make(map[string]*connection)
+	p.mu.Unlock()
+	for name, c := range backends {
+		if err := c.client.Close(); err != nil {
+			log.Printf("Error: Failed to close server %s: %v", name, err)
+		}
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (p *ProxyServer) Server() *server.MCPServer {
+	return p.server
+}
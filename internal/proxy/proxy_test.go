@@ -0,0 +1,440 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/inprocess"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+)
+
+func TestParseConfig(t *testing.T) {
+	config := `
+servers:
+  ids:
+    server: identifiers
+    args: ["-max-count", "10"]
+  time: {}
+  sqlite:
+    command: uvx
+    args: ["mcp-server-sqlite"]
+  remote:
+    url: http://localhost:8080/sse
+mcpServers:
+  memory:
+    command: npx
+    args: ["-y", "@modelcontextprotocol/server-memory"]
+`
+	cfg, err := ParseConfig([]byte(config))
+	require.NoError(t, err)
+	assert.Equal(t, defaultSeparator, cfg.Separator)
+	assert.Equal(t, ConflictPriority, cfg.Conflicts)
+	assert.Equal(t, "identifiers", cfg.Servers["ids"].Server)
+	assert.Equal(t, "time", cfg.Servers["time"].Server)
+	assert.Equal(t, "", cfg.Servers["sqlite"].Server)
+	assert.Equal(t, "", cfg.Servers["remote"].Server)
+	assert.Equal(t, BackendConfig{Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-memory"}}, cfg.Servers["memory"])
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"empty", ``, "no servers configured"},
+		{"unknown server", "servers:\n  nope: {}\n", `unknown bundled server "nope"`},
+		{"command and url", "servers:\n  x:\n    command: foo\n    url: http://localhost/sse\n", "only one of"},
+		{"url with args", "servers:\n  x:\n    url: http://localhost/sse\n    args: [a]\n", "args cannot be used with url"},
+		{"unknown field", "servers:\n  time:\n    cmd: foo\n", "field cmd not found"},
+		{"conflicts", "conflicts: merge\nservers:\n  time: {}\n", "invalid conflicts policy"},
+		{"hide pattern", "servers:\n  time:\n    hide: [\"[\"]\n", "invalid hide pattern"},
+		{"empty alias", "servers:\n  time:\n    aliases:\n      getCurrentTime: \"\"\n", "empty alias"},
+		{"env of bundled server", "servers:\n  time:\n    env:\n      TZ: UTC\n", "env can only be used with command"},
+		{"env of url", "servers:\n  x:\n    url: http://localhost/sse\n    env:\n      A: b\n", "env can only be used with command"},
+		{"prompt", "servers:\n  time: {}\nprompts:\n  p:\n    template: '{{.x}}'\n", `map has no entry for key "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.config))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+// callTool sends a tool call to the proxy as a client would.
+func callTool(t *testing.T, p *ProxyServer, name string, args map[string]interface{}) (string, string) {
+	t.Helper()
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+	data, err := json.Marshal(p.Server().HandleMessage(context.Background(), request))
+	require.NoError(t, err)
+	var response struct {
+		Result *struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	if response.Error != nil {
+		return "", response.Error.Message
+	}
+	require.Len(t, response.Result.Content, 1)
+	if response.Result.IsError {
+		return "", response.Result.Content[0].Text
+	}
+	return response.Result.Content[0].Text, ""
+}
+
+func TestOpen(t *testing.T) {
+	// A downstream server reached over SSE
+	remote := server.NewMCPServer("remote", "1.0.0")
+	remote.AddTool(mcp.NewTool("echo", mcp.WithString("text")),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("echo: " + req.Params.Arguments["text"].(string)), nil
+		})
+	testServer := server.NewTestServer(remote)
+	defer testServer.Close()
+
+	cfg := &Config{
+		Separator: defaultSeparator,
+		Servers: map[string]BackendConfig{
+			"ids":    {Server: "identifiers"},
+			"time":   {Server: "time", Args: []string{"-timezone", "UTC"}},
+			"remote": {URL: testServer.URL + "/sse"},
+			"off":    {Server: "regex", Disabled: true},
+		},
+	}
+	p, err := Open(context.Background(), cfg)
+	require.NoError(t, err)
+	defer p.Close()
+
+	assert.Equal(t, []string{"ids__generateIds", "ids__getServerInfo", "ids__validateId", "remote__echo", "time__getCurrentTime", "time__getServerInfo"}, p.Tools())
+
+	text, errMessage := callTool(t, p, "remote__echo", map[string]interface{}{"text": "hello"})
+	assert.Empty(t, errMessage)
+	assert.Equal(t, "echo: hello", text)
+
+	text, errMessage = callTool(t, p, "ids__generateIds", map[string]interface{}{"type": "uuid4", "count": 2})
+	assert.Empty(t, errMessage)
+	assert.Len(t, strings.Split(strings.TrimSpace(text), "\n"), 2)
+
+	// Downstream errors come back as the error results of the server
+	_, errMessage = callTool(t, p, "ids__validateId", map[string]interface{}{})
+	assert.Equal(t, "id is required", errMessage)
+}
+
+func TestOpenFailure(t *testing.T) {
+	cfg := &Config{
+		Separator: defaultSeparator,
+		Servers: map[string]BackendConfig{
+			"broken": {Command: "/nonexistent/server"},
+		},
+	}
+	_, err := Open(context.Background(), cfg)
+	assert.ErrorContains(t, err, "server broken")
+}
+
+func TestOpenBundledArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"unknown flag", []string{"-no-such-flag"}, "server time: failed to create server: flag provided but not defined: -no-such-flag"},
+		{"transport flag", []string{"-transport", "sse", "-listen", ":0"}, "server time: transport flags cannot be used with a bundled server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Separator: defaultSeparator,
+				Servers:   map[string]BackendConfig{"time": {Server: "time", Args: tt.args}},
+			}
+			_, err := Open(context.Background(), cfg)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestInProcessClientClose(t *testing.T) {
+	b, err := connect(context.Background(), BackendConfig{Server: "time"})
+	require.NoError(t, err)
+	c := b.(*inprocess.Client)
+	require.NoError(t, c.Close())
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("closing the client did not stop the server")
+	}
+}
+
+// testSession is a connected client of the proxy.
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *testSession) SessionID() string                                   { return s.id }
+
+// receive waits for a notification sent to the session.
+func (s *testSession) receive(t *testing.T) mcp.JSONRPCNotification {
+	t.Helper()
+	select {
+	case notification := <-s.notifications:
+		return notification
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+		return mcp.JSONRPCNotification{}
+	}
+}
+
+func TestRelayNotifications(t *testing.T) {
+	proceed := make(chan struct{})
+	remote := server.NewMCPServer("remote", "1.0.0")
+	remote.AddTool(mcp.NewTool("slow"),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			srv := server.ServerFromContext(ctx)
+			if req.Params.Meta != nil {
+				srv.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+					"progressToken": req.Params.Meta.ProgressToken,
+					"progress":      1,
+				})
+			}
+			srv.SendNotificationToClient(ctx, "notifications/message", map[string]interface{}{
+				"level": "info",
+				"data":  "working",
+			})
+			select {
+			case <-proceed:
+			case <-time.After(5 * time.Second):
+			}
+			return mcp.NewToolResultText("done"), nil
+		})
+	testServer := server.NewTestServer(remote)
+	defer testServer.Close()
+
+	p, err := Open(context.Background(), &Config{
+		Separator: defaultSeparator,
+		Servers:   map[string]BackendConfig{"remote": {URL: testServer.URL + "/sse"}},
+	})
+	require.NoError(t, err)
+	defer p.Close()
+
+	caller := &testSession{id: "caller", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	other := &testSession{id: "other", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, p.Server().RegisterSession(context.Background(), caller))
+	require.NoError(t, p.Server().RegisterSession(context.Background(), other))
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":  "remote__slow",
+			"_meta": map[string]interface{}{"progressToken": "client-token"},
+		},
+	})
+	require.NoError(t, err)
+	done := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		done <- p.Server().HandleMessage(p.Server().WithContext(context.Background(), caller), request)
+	}()
+
+	// Progress reaches only the caller, with its own token, and log messages every client
+	var progress, messages []mcp.JSONRPCNotification
+	for len(progress) < 1 || len(messages) < 1 {
+		notification := caller.receive(t)
+		if notification.Method == "notifications/progress" {
+			progress = append(progress, notification)
+		} else {
+			messages = append(messages, notification)
+		}
+	}
+	assert.Equal(t, "client-token", progress[0].Params.AdditionalFields["progressToken"])
+	assert.Equal(t, "working", messages[0].Params.AdditionalFields["data"])
+	assert.Equal(t, "notifications/message", other.receive(t).Method)
+
+	close(proceed)
+	<-done
+	assert.Empty(t, other.notifications)
+}
+
+func TestExposedName(t *testing.T) {
+	empty, web := "", "web"
+	tests := []struct {
+		name    string
+		bc      BackendConfig
+		tool    string
+		exposed string
+		visible bool
+	}{
+		{"default prefix", BackendConfig{}, "fetchURL", "fetch__fetchURL", true},
+		{"custom prefix", BackendConfig{Prefix: &web}, "fetchURL", "web__fetchURL", true},
+		{"no prefix", BackendConfig{Prefix: &empty}, "fetchURL", "fetchURL", true},
+		{"alias", BackendConfig{Prefix: &web, Aliases: map[string]string{"fetchURL": "web.fetch"}}, "fetchURL", "web.fetch", true},
+		{"hidden", BackendConfig{Hide: []string{"fetchURL"}}, "fetchURL", "", false},
+		{"hidden by pattern", BackendConfig{Hide: []string{"fetch*"}}, "fetchHTML", "", false},
+		{"not hidden", BackendConfig{Hide: []string{"fetch*"}}, "getHeaders", "fetch__getHeaders", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exposed, visible := exposedName("fetch", tt.bc, defaultSeparator, tt.tool)
+			assert.Equal(t, tt.exposed, exposed)
+			assert.Equal(t, tt.visible, visible)
+		})
+	}
+}
+
+func TestServerOrder(t *testing.T) {
+	order := serverOrder(map[string]BackendConfig{
+		"b": {}, "a": {}, "c": {Priority: 10}, "d": {Priority: -1},
+	})
+	assert.Equal(t, []string{"c", "a", "b", "d"}, order)
+}
+
+func TestOpenConflicts(t *testing.T) {
+	empty := ""
+	servers := map[string]BackendConfig{
+		"seoul": {Server: "time", Args: []string{"-timezone", "Asia/Seoul"}, Prefix: &empty},
+		"utc":   {Server: "time", Args: []string{"-timezone", "UTC"}, Prefix: &empty, Priority: 1},
+		"ids": {
+			Server:  "identifiers",
+			Aliases: map[string]string{"generateIds": "ids.generate", "missing": "x"},
+			Hide:    []string{"validate*"},
+		},
+	}
+
+	p, err := Open(context.Background(), &Config{Separator: defaultSeparator, Conflicts: ConflictPriority, Servers: servers})
+	require.NoError(t, err)
+	defer p.Close()
+	// Unprefixed getServerInfo tools are left to the proxy
+	assert.Equal(t, []string{"getCurrentTime", "ids.generate", "ids__getServerInfo"}, p.Tools())
+
+	// The server with the higher priority keeps the name
+	text, errMessage := callTool(t, p, "getCurrentTime", map[string]interface{}{})
+	assert.Empty(t, errMessage)
+	assert.Contains(t, text, "UTC")
+
+	_, err = Open(context.Background(), &Config{Separator: defaultSeparator, Conflicts: ConflictError, Servers: servers})
+	assert.ErrorContains(t, err, "server seoul: tool getCurrentTime is also exposed by server utc")
+}
+
+func TestBundledConfig(t *testing.T) {
+	cfg, err := BundledConfig([]string{"time", " regex", ""})
+	require.NoError(t, err)
+	p, err := Open(context.Background(), cfg)
+	require.NoError(t, err)
+	defer p.Close()
+	assert.Equal(t, []string{"explainRegex", "getCurrentTime", "replaceRegex", "testRegex"}, p.Tools())
+
+	for names, expected := range map[string]string{
+		"time,bogus": `unknown server "bogus"`,
+		"time,time":  "listed twice",
+		",":          "no servers configured",
+	} {
+		_, err := BundledConfig(strings.Split(names, ","))
+		assert.ErrorContains(t, err, expected, names)
+	}
+}
+
+func TestAggregateConfig(t *testing.T) {
+	cfg, err := AggregateConfig(map[string]mcpconfig.Server{
+		"sqlite": {Command: "uvx", Args: []string{"mcp-server-sqlite"}},
+		"github": {Command: "npx", Env: map[string]string{"GITHUB_TOKEN": "token"}},
+	}, AggregateSeparator)
+	require.NoError(t, err)
+	assert.Equal(t, ConflictError, cfg.Conflicts)
+	assert.Equal(t, BackendConfig{Command: "uvx", Args: []string{"mcp-server-sqlite"}}, cfg.Servers["sqlite"])
+	assert.Equal(t, "token", cfg.Servers["github"].Env["GITHUB_TOKEN"])
+
+	// Tools are named after their server and routed to it
+	cfg.Servers = map[string]BackendConfig{"time": {Server: "time"}, "regex": {Server: "regex"}}
+	p, err := Open(context.Background(), cfg)
+	require.NoError(t, err)
+	defer p.Close()
+	assert.Equal(t, []string{"regex.explainRegex", "regex.getServerInfo", "regex.replaceRegex", "regex.testRegex", "time.getCurrentTime", "time.getServerInfo"}, p.Tools())
+	text, errMessage := callTool(t, p, "regex.testRegex", map[string]interface{}{"pattern": "a+", "text": "caat"})
+	assert.Empty(t, errMessage)
+	assert.Contains(t, text, "aa")
+
+	for _, tt := range []struct {
+		servers   map[string]mcpconfig.Server
+		separator string
+		err       string
+	}{
+		{nil, ".", "no servers configured"},
+		{map[string]mcpconfig.Server{"x": {}}, ".", "command is required"},
+		{map[string]mcpconfig.Server{"x": {Command: "x"}}, "", "separator must not be empty"},
+	} {
+		_, err := AggregateConfig(tt.servers, tt.separator)
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+// Test that reloading the config connects and disconnects servers and keeps the rest
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	p, err := Open(ctx, &Config{
+		Separator: defaultSeparator,
+		Servers: map[string]BackendConfig{
+			"time": {Server: "time", Args: []string{"-timezone", "UTC"}},
+			"ids":  {Server: "identifiers"},
+		},
+	})
+	require.NoError(t, err)
+	defer p.Close()
+	caller := &testSession{id: "caller", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, p.Server().RegisterSession(ctx, caller))
+	timeClient := p.backends["time"].client
+
+	err = p.Reload(ctx, &Config{
+		Separator: defaultSeparator,
+		Servers: map[string]BackendConfig{
+			"time":  {Server: "time", Args: []string{"-timezone", "UTC"}},
+			"regex": {Server: "regex"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"regex__explainRegex", "regex__getServerInfo", "regex__replaceRegex", "regex__testRegex", "time__getCurrentTime", "time__getServerInfo"}, p.Tools())
+	assert.Same(t, timeClient, p.backends["time"].client, "Unchanged servers stay connected")
+	assert.Equal(t, "notifications/tools/list_changed", caller.receive(t).Method)
+	text, errMessage := callTool(t, p, "regex__testRegex", map[string]interface{}{"pattern": "a+", "text": "caaat"})
+	assert.Empty(t, errMessage)
+	assert.NotEmpty(t, text)
+	_, errMessage = callTool(t, p, "ids__generateIds", map[string]interface{}{"type": "uuid4"})
+	assert.Contains(t, errMessage, "not found")
+
+	// A config that cannot be applied changes nothing
+	err = p.Reload(ctx, &Config{
+		Separator: defaultSeparator,
+		Servers: map[string]BackendConfig{
+			"time":   {Server: "time", Args: []string{"-timezone", "UTC"}},
+			"broken": {Command: "/nonexistent/server"},
+		},
+	})
+	assert.ErrorContains(t, err, "server broken")
+	assert.Len(t, p.Tools(), 6)
+	_, errMessage = callTool(t, p, "regex__testRegex", map[string]interface{}{"pattern": "a+", "text": "caaat"})
+	assert.Empty(t, errMessage)
+}
@@ -0,0 +1,184 @@
+// Package ratelimit limits how often each client session may call each tool, with
+// token buckets refilled at a configured rate.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/time/rate"
+
+	"github.com/mark3labs/mcphost/internal/i18n"
+)
+
+// AnyTool is the rule key applying to the tools without a rule of their own.
+const AnyTool = "*"
+
+// sweepInterval is how often buckets left idle long enough to be full again are
+// dropped.
+const sweepInterval = time.Minute
+
+// Rule is the limit of calls to a tool by one session.
+type Rule struct {
+	Rate  rate.Limit // calls per second
+	Burst int        // calls allowed at once
+}
+
+// ParseRules parses comma separated rules of the form tool=count/unit[:burst], where
+// unit is s, m or h and tool is a tool name or * for all other tools, e.g.
+// "searchGoogle=10/m:3,*=2/s". The burst defaults to count.
+func ParseRules(spec string) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tool, limit, ok := strings.Cut(item, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid rate limit %q: expected tool=count/unit[:burst]", item)
+		}
+		limit, burstText, hasBurst := strings.Cut(limit, ":")
+		countText, unit, ok := strings.Cut(limit, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q: expected tool=count/unit[:burst]", item)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(countText))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid rate limit %q: count must be a positive integer", item)
+		}
+		var per time.Duration
+		switch strings.TrimSpace(unit) {
+		case "s":
+			per = time.Second
+		case "m":
+			per = time.Minute
+		case "h":
+			per = time.Hour
+		default:
+			return nil, fmt.Errorf("invalid rate limit %q: unit must be s, m or h", item)
+		}
+		burst := count
+		if hasBurst {
+			if burst, err = strconv.Atoi(strings.TrimSpace(burstText)); err != nil || burst < 1 {
+				return nil, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", item)
+			}
+		}
+		if _, dup := rules[tool]; dup {
+			return nil, fmt.Errorf("duplicate rate limit for %s", tool)
+		}
+		rules[tool] = Rule{Rate: rate.Limit(float64(count) / per.Seconds()), Burst: burst}
+	}
+	return rules, nil
+}
+
+type bucketKey struct {
+	session string
+	tool    string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// Limiter enforces rules per tool and client session.
+type Limiter struct {
+	rules map[string]Rule
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+// New creates a Limiter enforcing rules, keyed by tool name or AnyTool.
+func New(rules map[string]Rule) *Limiter {
+	return &Limiter{
+		rules:   rules,
+		now:     time.Now,
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+// Allow takes a token for a call to tool by session. If none is left, it returns how
+// long until one is.
+func (l *Limiter) Allow(session, tool string) (bool, time.Duration) {
+	rule, ok := l.rules[tool]
+	if !ok {
+		if rule, ok = l.rules[AnyTool]; !ok {
+			return true, 0
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+	key := bucketKey{session: session, tool: tool}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rule.Rate, rule.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled since their last use, which behave
+// like new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		refill := time.Duration(float64(b.limiter.Burst()) / float64(b.limiter.Limit()) * float64(time.Second))
+		if now.Sub(b.lastUsed) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Middleware returns tool middleware refusing the calls over the limits with an
+// error result. The result says when to retry, in its text and, for clients, as
+// retryAfterSeconds in its _meta.
+func (l *Limiter) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			session := ""
+			if s := server.ClientSessionFromContext(ctx); s != nil {
+				session = s.SessionID()
+			}
+			if ok, retryAfter := l.Allow(session, req.Params.Name); !ok {
+				return Exceeded(ctx, req.Params.Name, retryAfter), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// Exceeded returns the error result of a call to tool over a limit, saying to retry
+// after retryAfter in the language of ctx.
+func Exceeded(ctx context.Context, tool string, retryAfter time.Duration) *mcp.CallToolResult {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	result := mcp.NewToolResultError(i18n.Sprintf(i18n.FromContext(ctx), "Rate limit exceeded for %s, retry after %d seconds", tool, seconds))
+	result.Meta = map[string]interface{}{
+		"rateLimited":       true,
+		"retryAfterSeconds": seconds,
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test rule parsing
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(" searchGoogle=10/m:3, *=2/s ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"searchGoogle": {Rate: rate.Limit(10.0 / 60), Burst: 3},
+		"*":            {Rate: 2, Burst: 2},
+	}, rules)
+
+	rules, err = ParseRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, spec := range []string{"fetchURL", "=1/s", "fetchURL=1", "fetchURL=0/s", "fetchURL=1/d", "fetchURL=1/s:0", "a=1/s,a=2/s"} {
+		_, err := ParseRules(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// Test that buckets are kept per session and tool and refill over time
+func TestAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(map[string]Rule{
+		"fetchURL": {Rate: 1, Burst: 2},
+		AnyTool:    {Rate: 0.5, Burst: 1},
+	})
+	l.now = func() time.Time { return now }
+
+	for range 2 {
+		ok, _ := l.Allow("a", "fetchURL")
+		assert.True(t, ok)
+	}
+	ok, retryAfter := l.Allow("a", "fetchURL")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// Other sessions and tools have their own buckets
+	ok, _ = l.Allow("b", "fetchURL")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a", "getTime")
+	assert.True(t, ok)
+	ok, retryAfter = l.Allow("a", "getTime")
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// Refused calls take no token
+	now = now.Add(time.Second)
+	ok, _ = l.Allow("a", "fetchURL")
+	assert.True(t, ok)
+
+	// Refilled buckets are swept
+	now = now.Add(time.Hour)
+	l.Allow("c", "fetchURL")
+	assert.Len(t, l.buckets, 1)
+
+	ok, _ = New(map[string]Rule{"fetchURL": {Rate: 1, Burst: 1}}).Allow("a", "getTime")
+	assert.True(t, ok)
+}
+
+// Test the result of a limited call
+func TestMiddleware(t *testing.T) {
+	l := New(map[string]Rule{AnyTool: {Rate: rate.Limit(1.0 / 60), Burst: 1}})
+	handler := l.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	req := mcptest.NewCallToolRequest("searchGoogle", nil)
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", mcptest.ResultText(result))
+
+	result, err = handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, mcptest.ResultText(result), "Rate limit exceeded for searchGoogle, retry after")
+	assert.Equal(t, true, result.Meta["rateLimited"])
+	assert.InDelta(t, 60, result.Meta["retryAfterSeconds"], 1)
+}
@@ -0,0 +1,147 @@
+// Package redact masks secrets in log lines and in the results of tools, so that API
+// keys, tokens and credentials given to the servers are not echoed to log files or to
+// clients, e.g. in the URL of a failed request.
+//
+// Secrets are known values, registered when flags are parsed, and patterns: the
+// Authorization, Cookie and API key headers, bearer tokens, passwords in URLs and the
+// secret query parameters of URLs, along with the regular expressions configured with
+// the -redact flag.
+package redact
+
+import (
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mask replaces secrets.
+const Mask = "[REDACTED]"
+
+// minSecretLength is the length below which values are not registered as secrets,
+// as masking them would mask ordinary text.
+const minSecretLength = 6
+
+// secretNames are the fragments of the names of arguments, flags and headers whose
+// values are secrets.
+var secretNames = []string{
+	"password", "passwd", "secret", "token", "apikey", "api_key", "api-key",
+	"authorization", "cookie", "credential", "privatekey", "private_key", "private-key",
+}
+
+// SecretName reports whether name, e.g. an argument or flag name, holds a secret,
+// ignoring case.
+func SecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range secretNames {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// builtins are the patterns masked in log lines. Their first and second groups are
+// kept and the rest of the match masked.
+var builtins = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\b(?:proxy-)?authorization["']?\s*[:=]\s*["']?(?:(?:bearer|basic|token)\s+)?)[^\s"',;]+`),
+	regexp.MustCompile(`(?i)(\b(?:set-)?cookie["']?\s*[:=]\s*["']?)[^\r\n"']+`),
+	regexp.MustCompile(`(?i)(\bx-(?:goog-)?api-key["']?\s*[:=]\s*["']?)[^\s"',;]+`),
+	regexp.MustCompile(`(?i)(\bbearer\s+)[a-z0-9._~+/-]+=*`),
+	regexp.MustCompile(`(?i)(\b[a-z][a-z0-9+.-]*://[^\s/:@]+:)[^\s/@]+(@)`),
+	regexp.MustCompile(`(?i)([?&](?:key|api_?key|access_?token|refresh_?token|token|sig|signature|password|secret|client_secret)=)[^&\s"'#]+`),
+}
+
+var (
+	mu       sync.RWMutex
+	secrets  []string
+	patterns []*regexp.Regexp
+)
+
+// AddSecret registers value as a secret, masked wherever it appears. Numbers and
+// values shorter than a few characters are ignored, e.g. that of -max-tokens.
+func AddSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(secrets, value) {
+		return
+	}
+	secrets = append(secrets, value)
+	// Longer secrets are masked first, so those containing others are masked whole
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+}
+
+// AddPattern registers the regular expression expr; its matches are masked.
+func AddPattern(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	patterns = append(patterns, re)
+	return nil
+}
+
+// Secrets returns s with the registered secrets and the matches of the registered
+// patterns masked. It suits tool results, which may legitimately hold headers or
+// URLs.
+func Secrets(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, secret := range secrets {
+		if strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	for _, re := range patterns {
+		s = re.ReplaceAllLiteralString(s, Mask)
+	}
+	return s
+}
+
+// String returns s with the registered secrets and patterns, secret headers, bearer
+// tokens, passwords in URLs and secret query parameters masked. It suits log lines.
+func String(s string) string {
+	s = Secrets(s)
+	for _, re := range builtins {
+		s = re.ReplaceAllString(s, "${1}"+Mask+"${2}")
+	}
+	return s
+}
+
+// writer masks secrets in what it writes.
+type writer struct {
+	w io.Writer
+}
+
+// Writer returns a writer masking secrets with String in what it writes to w, such as
+// the output of a logger, which writes a line at a time.
+func Writer(w io.Writer) io.Writer {
+	if _, ok := w.(*writer); ok {
+		return w
+	}
+	return &writer{w: w}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Install masks secrets in the output of the standard logger.
+func Install() {
+	log.SetOutput(Writer(log.Writer()))
+}
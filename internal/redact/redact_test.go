@@ -0,0 +1,84 @@
+package redact
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reset forgets the registered secrets and patterns.
+func reset(t *testing.T) {
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		secrets, patterns = nil, nil
+	})
+}
+
+// Test the names of secret arguments and flags
+func TestSecretName(t *testing.T) {
+	for _, name := range []string{"api-key", "apiKey", "Authorization", "bot-token", "client_secret", "dbPassword"} {
+		assert.True(t, SecretName(name), name)
+	}
+	for _, name := range []string{"query", "timeout", "user-agent", "key"} {
+		assert.False(t, SecretName(name), name)
+	}
+}
+
+// Test masking registered secrets and patterns
+func TestSecrets(t *testing.T) {
+	reset(t)
+	AddSecret("AIzaSyD-secret")
+	AddSecret("AIzaSyD-secret-longer")
+	AddSecret("short")
+	AddSecret("4096000")
+	require.NoError(t, AddPattern(`sk-[A-Za-z0-9]{8,}`))
+	assert.Error(t, AddPattern(`(`))
+
+	assert.Equal(t, "key [REDACTED] and [REDACTED]", Secrets("key AIzaSyD-secret-longer and AIzaSyD-secret"))
+	assert.Equal(t, "openai [REDACTED]", Secrets("openai sk-abcdefgh123"))
+	assert.Equal(t, "short 4096000", Secrets("short 4096000"), "Short and numeric values should not be secrets")
+	assert.Equal(t, "Authorization: Bearer abc", Secrets("Authorization: Bearer abc"), "Headers are kept in tool results")
+}
+
+// Test masking secrets in log lines
+func TestString(t *testing.T) {
+	reset(t)
+	AddSecret("AIzaSyD-secret")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`Error: Request failed: Get "https://www.googleapis.com/customsearch/v1?cx=123&key=AIzaSyD-secret&q=go": context deadline exceeded`,
+			`Error: Request failed: Get "https://www.googleapis.com/customsearch/v1?cx=123&key=[REDACTED]&q=go": context deadline exceeded`},
+		{"GET /v1?q=go&key=AIzaUnknown", "GET /v1?q=go&key=[REDACTED]"},
+		{"GET /v1?access_token=abc.def&page=2", "GET /v1?access_token=[REDACTED]&page=2"},
+		{"Authorization: Bearer eyJhbGciOi.xyz", "Authorization: Bearer [REDACTED]"},
+		{`headers: {"authorization": "Basic dXNlcjpwYXNz"}`, `headers: {"authorization": "Basic [REDACTED]"}`},
+		{"Cookie: session=abc; theme=dark", "Cookie: [REDACTED]"},
+		{"X-Api-Key: 12345", "X-Api-Key: [REDACTED]"},
+		{"token bearer abc123", "token bearer [REDACTED]"},
+		{"dial postgres://app:hunter2@db:5432/app", "dial postgres://app:[REDACTED]@db:5432/app"},
+		{"Fetching https://example.com/page?id=1", "Fetching https://example.com/page?id=1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, String(tt.in))
+	}
+}
+
+// Test masking the output of a logger
+func TestWriter(t *testing.T) {
+	reset(t)
+	AddSecret("tg-bot-token")
+	var buf bytes.Buffer
+	w := Writer(&buf)
+	assert.Same(t, w, Writer(w), "Writers should not be wrapped twice")
+
+	logger := log.New(w, "", 0)
+	logger.Printf("POST https://api.telegram.org/bottg-bot-token/sendMessage failed")
+	assert.Equal(t, "POST https://api.telegram.org/bot[REDACTED]/sendMessage failed\n", buf.String())
+}
@@ -0,0 +1,74 @@
+// Package reload watches config files so that servers can apply changes to them
+// without a restart, and without dropping their client connections.
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Interval is how often the watched files are checked for changes.
+const Interval = 2 * time.Second
+
+// stamp identifies a version of a file.
+type stamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func stampOf(file string) stamp {
+	info, err := os.Stat(file)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+// Watch calls apply whenever one of files changes or the process receives SIGHUP,
+// until ctx is done. It returns at once. apply is expected to keep the previous
+// config when the new one is invalid, e.g. half written.
+func Watch(ctx context.Context, files []string, apply func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		watch(ctx, files, Interval, hup, apply)
+	}()
+}
+
+// watch checks files every interval, and also applies changes when signaled.
+func watch(ctx context.Context, files []string, interval time.Duration, signals <-chan os.Signal, apply func()) {
+	stamps := make([]stamp, len(files))
+	for i, file := range files {
+		stamps[i] = stampOf(file)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			for i, file := range files {
+				stamps[i] = stampOf(file)
+			}
+			apply()
+		case <-ticker.C:
+			changed := false
+			for i, file := range files {
+				// A file being replaced may be missing for a moment
+				if s := stampOf(file); s.exists && s != stamps[i] {
+					stamps[i] = s
+					changed = true
+				}
+			}
+			if changed {
+				apply()
+			}
+		}
+	}
+}
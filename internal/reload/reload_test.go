@@ -0,0 +1,52 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that changes to the files and signals apply the config
+func TestWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("a: 1\n"), 0644))
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal)
+	applied := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		watch(ctx, []string{file}, 5*time.Millisecond, signals, func() { applied <- struct{}{} })
+		close(done)
+	}()
+	wait := func(message string) {
+		t.Helper()
+		select {
+		case <-applied:
+		case <-time.After(time.Second):
+			t.Fatal(message)
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, applied, "Nothing changed")
+	require.NoError(t, os.WriteFile(file, []byte("a: 22\n"), 0644))
+	wait("A change to the file is applied")
+	signals <- syscall.SIGHUP
+	wait("A signal applies the config")
+
+	// A missing file is not a change, its return is
+	require.NoError(t, os.Remove(file))
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, applied)
+	require.NoError(t, os.WriteFile(file, []byte("a: 333\n"), 0644))
+	wait("A replaced file is applied")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,304 @@
+// Package repl is an interactive shell for exploring the tools of an MCP server: tool
+// names and arguments are completed with tab, missing required arguments are asked
+// for, and results are pretty-printed.
+package repl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/toolclient"
+)
+
+// LineReader reads the lines typed in the shell.
+type LineReader interface {
+	// ReadLine returns the next line, or io.EOF once the input ends.
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// Shell calls the tools of a server with the commands read from a LineReader.
+type Shell struct {
+	client  toolclient.Client
+	tools   []mcp.Tool
+	in      LineReader
+	out     io.Writer
+	timeout time.Duration
+}
+
+// New creates a shell calling the tools of client, each call bounded by timeout.
+func New(client toolclient.Client, tools []mcp.Tool, in LineReader, out io.Writer, timeout time.Duration) *Shell {
+	sorted := append([]mcp.Tool{}, tools...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return &Shell{client: client, tools: sorted, in: in, out: out, timeout: timeout}
+}
+
+const help = `Commands:
+  <tool> [key=value]...   call a tool; missing required arguments are asked for
+  describe <tool>         show the description and arguments of a tool
+  tools                   list the tools
+  help                    show this help
+  exit                    leave the shell (or Ctrl+D)
+Values with spaces are quoted, as in query="model context protocol". Tab completes
+tool names, argument names and enum values.
+`
+
+const prompt = "mcp> "
+
+// Run reads and runs commands until the input ends, exit is typed or ctx is done.
+func (s *Shell) Run(ctx context.Context) error {
+	fmt.Fprintf(s.out, "%d tools, type help for the commands\n", len(s.tools))
+	for ctx.Err() == nil {
+		s.in.SetPrompt(prompt)
+		line, err := s.in.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		words, err := Split(line)
+		if err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		switch words[0] {
+		case "exit", "quit":
+			return nil
+		case "help":
+			fmt.Fprint(s.out, help)
+		case "tools":
+			toolclient.WriteTools(s.out, s.tools, false)
+		case "describe":
+			if len(words) != 2 {
+				fmt.Fprintln(s.out, "Usage: describe <tool>")
+				continue
+			}
+			tool, err := toolclient.FindTool(s.tools, words[1])
+			if err != nil {
+				fmt.Fprintf(s.out, "Error: %v\n", err)
+				continue
+			}
+			toolclient.WriteTools(s.out, []mcp.Tool{tool}, true)
+		default:
+			if err := s.call(ctx, words[0], words[1:]); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				fmt.Fprintf(s.out, "Error: %v\n", err)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// call calls a tool with the key=value pairs, asking for the missing required ones.
+func (s *Shell) call(ctx context.Context, name string, pairs []string) error {
+	tool, err := toolclient.FindTool(s.tools, name)
+	if err != nil {
+		return err
+	}
+	given := make(map[string]bool)
+	for _, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		given[key] = true
+	}
+	for _, key := range tool.InputSchema.Required {
+		if given[key] {
+			continue
+		}
+		property, _ := tool.InputSchema.Properties[key].(map[string]interface{})
+		description, _ := property["description"].(string)
+		if description != "" {
+			fmt.Fprintf(s.out, "  %s: %s\n", key, description)
+		}
+		s.in.SetPrompt(fmt.Sprintf("  %s = ", key))
+		value, err := s.in.ReadLine()
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	args, err := toolclient.Arguments(tool, "", pairs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool.Name
+	req.Params.Arguments = args
+	start := time.Now()
+	result, err := s.client.CallTool(ctx, req)
+	if err != nil {
+		return err
+	}
+	if result.IsError {
+		fmt.Fprint(s.out, "Tool error: ")
+	}
+	for i, content := range result.Content {
+		// Indent JSON text, which many tools return
+		if text, ok := content.(mcp.TextContent); ok {
+			var indented bytes.Buffer
+			if json.Indent(&indented, []byte(strings.TrimSpace(text.Text)), "", "  ") == nil {
+				text.Text = indented.String()
+				result.Content[i] = text
+			}
+		}
+	}
+	if err := toolclient.WriteResult(s.out, result); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "(%s)\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Complete completes the word before the byte position pos in line: a command or tool
+// name first, then the argument names of the tool and the values of enum arguments.
+// It returns the new line and position, and false when there is nothing to complete.
+// The signature matches the AutoCompleteCallback of golang.org/x/term.
+func (s *Shell) Complete(line string, pos int) (string, int, bool) {
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t") + 1
+	word := head[start:]
+	words := strings.Fields(head[:start])
+
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		candidates = []string{"describe", "exit", "help", "tools"}
+		for _, tool := range s.tools {
+			candidates = append(candidates, tool.Name)
+		}
+	case words[0] == "describe" && len(words) == 1:
+		for _, tool := range s.tools {
+			candidates = append(candidates, tool.Name)
+		}
+	default:
+		tool, err := toolclient.FindTool(s.tools, words[0])
+		if err != nil {
+			return "", 0, false
+		}
+		if key, _, ok := strings.Cut(word, "="); ok {
+			property, _ := tool.InputSchema.Properties[key].(map[string]interface{})
+			for _, value := range enumValues(property) {
+				candidates = append(candidates, key+"="+value)
+			}
+			break
+		}
+		given := make(map[string]bool)
+		for _, w := range words[1:] {
+			key, _, _ := strings.Cut(w, "=")
+			given[key] = true
+		}
+		for key := range tool.InputSchema.Properties {
+			if !given[key] {
+				candidates = append(candidates, key+"=")
+			}
+		}
+		sort.Strings(candidates)
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	completion := commonPrefix(matches)
+	if len(matches) == 1 && !strings.HasSuffix(completion, "=") {
+		completion += " "
+	}
+	if completion == word {
+		// Nothing more to complete, so show the choices
+		fmt.Fprintln(s.out, strings.Join(matches, "  "))
+	}
+	return head[:start] + completion + line[pos:], start + len(completion), true
+}
+
+func enumValues(property map[string]interface{}) []string {
+	var values []string
+	switch enum := property["enum"].(type) {
+	case []string:
+		values = enum
+	case []interface{}:
+		for _, v := range enum {
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	if typ, _ := property["type"].(string); typ == "boolean" {
+		values = []string{"false", "true"}
+	}
+	return values
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// Split splits a command line into words at spaces outside of single or double quotes.
+// A backslash escapes the next character outside of single quotes.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
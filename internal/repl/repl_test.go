@@ -0,0 +1,139 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/inprocess"
+)
+
+// lines is a LineReader returning the given lines and recording the prompts.
+type lines struct {
+	lines   []string
+	prompts []string
+}
+
+func (l *lines) ReadLine() (string, error) {
+	if len(l.lines) == 0 {
+		return "", io.EOF
+	}
+	line := l.lines[0]
+	l.lines = l.lines[1:]
+	return line, nil
+}
+
+func (l *lines) SetPrompt(prompt string) {
+	l.prompts = append(l.prompts, prompt)
+}
+
+func newShell(t *testing.T, in LineReader, out io.Writer) *Shell {
+	t.Helper()
+	s := server.NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("echo",
+		mcp.WithString("text", mcp.Required(), mcp.Description("Text to echo")),
+		mcp.WithString("mode", mcp.Enum("upper", "lower")),
+		mcp.WithBoolean("json"),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text := req.Params.Arguments["text"].(string)
+		switch req.Params.Arguments["mode"] {
+		case "upper":
+			text = strings.ToUpper(text)
+		case "lower":
+			text = strings.ToLower(text)
+		}
+		if req.Params.Arguments["json"] == true {
+			return mcp.NewToolResultText(fmt.Sprintf(`{"text":%q}`, text)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+	s.AddTool(mcp.NewTool("explode"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("boom"), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := inprocess.NewClient(ctx, cancel, s)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	tools, err := client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	return New(client, tools.Tools, in, out, time.Minute)
+}
+
+// Test calling tools and asking for missing arguments
+func TestRun(t *testing.T) {
+	in := &lines{lines: []string{
+		"",
+		`echo text="Hello World" mode=upper`,
+		"echo json=true",
+		"Hi",
+		"explode",
+		"nope",
+		`echo text="unterminated`,
+		"exit",
+		"tools",
+	}}
+	var out bytes.Buffer
+	require.NoError(t, newShell(t, in, &out).Run(context.Background()))
+
+	output := out.String()
+	assert.Contains(t, output, "2 tools, type help for the commands")
+	assert.Contains(t, output, "HELLO WORLD\n")
+	assert.Contains(t, output, "  text: Text to echo\n{\n  \"text\": \"Hi\"\n}\n", "JSON results are indented")
+	assert.Contains(t, output, "Tool error: boom\n")
+	assert.Contains(t, output, `Error: unknown tool "nope"`)
+	assert.Contains(t, output, "Error: unterminated \" quote")
+	assert.NotContains(t, output, "echo\nexplode\n", "the shell stops at exit")
+	assert.Equal(t, []string{prompt, prompt, prompt, "  text = ", prompt, prompt, prompt, prompt}, in.prompts)
+}
+
+// Test completing tools, arguments and enum values
+func TestComplete(t *testing.T) {
+	var out bytes.Buffer
+	s := newShell(t, &lines{}, &out)
+
+	complete := func(line string) string {
+		newLine, pos, ok := s.Complete(line, len(line))
+		if !ok {
+			return "<none>"
+		}
+		assert.Equal(t, len(newLine), pos)
+		return newLine
+	}
+	assert.Equal(t, "ex", complete("ex"))
+	assert.Equal(t, "explode ", complete("exp"))
+	assert.Equal(t, "describe ", complete("desc"))
+	assert.Equal(t, "describe echo ", complete("describe ec"))
+	assert.Equal(t, "echo text=", complete("echo t"))
+	assert.Equal(t, "echo text=a mode=", complete("echo text=a m"))
+	assert.Equal(t, "echo mode=upper ", complete("echo mode=u"))
+	assert.Equal(t, "echo json=true ", complete("echo json=t"))
+	assert.Equal(t, "<none>", complete("nope x"))
+
+	out.Reset()
+	assert.Equal(t, "echo mode=", complete("echo mode="))
+	assert.Equal(t, "mode=upper  mode=lower\n", out.String(), "the choices are shown")
+
+	line, pos, ok := s.Complete("echo tex!", len("echo tex"))
+	assert.True(t, ok)
+	assert.Equal(t, "echo text=!", line)
+	assert.Equal(t, len("echo text="), pos)
+}
+
+// Test splitting command lines
+func TestSplit(t *testing.T) {
+	words, err := Split(`fetchURL  url="https://example.com/a b" note="it's" raw='a\b' path=a\ b `)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fetchURL", "url=https://example.com/a b", "note=it's", `raw=a\b`, "path=a b"}, words)
+
+	_, err = Split(`x="open`)
+	assert.Error(t, err)
+}
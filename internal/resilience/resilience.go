@@ -0,0 +1,419 @@
+// Package resilience guards the HTTP calls of the servers to upstream APIs with
+// retries, circuit breakers and bulkheads, configured per upstream host, so that one
+// slow or failing upstream fails fast instead of holding up every tool call.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/budget"
+)
+
+// AnyUpstream is the key applying to the upstream hosts without settings of their own.
+const AnyUpstream = "*"
+
+const (
+	// baseBackoff is the delay before the first retry, doubled for each further one.
+	baseBackoff = 200 * time.Millisecond
+	// maxBackoff bounds the delay before a retry, including one asked for by the
+	// upstream with Retry-After.
+	maxBackoff = 10 * time.Second
+)
+
+// Breaker is the setting of a circuit breaker, which rejects the requests to an
+// upstream for Cooldown after Failures consecutive failed requests, then lets one
+// request through to probe whether the upstream recovered.
+type Breaker struct {
+	Failures int
+	Cooldown time.Duration
+}
+
+// Config is the settings of the upstream hosts, keyed by host name or AnyUpstream.
+type Config struct {
+	// Retries is how many times failed idempotent requests are retried.
+	Retries map[string]int
+	// Breakers are the circuit breakers of the upstreams.
+	Breakers map[string]Breaker
+	// Bulkheads limit the number of concurrent requests to the upstreams.
+	Bulkheads map[string]int
+}
+
+// Empty reports whether c guards no upstream.
+func (c Config) Empty() bool {
+	return len(c.Retries) == 0 && len(c.Breakers) == 0 && len(c.Bulkheads) == 0
+}
+
+// parse parses comma separated settings of the form host=value, where host is a host
+// name or * for all other hosts, with value parsing the values.
+func parse[T any](spec, what, format string, value func(string) (T, bool)) (map[string]T, error) {
+	settings := make(map[string]T)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, text, ok := strings.Cut(item, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid %s %q: expected host=%s", what, item, format)
+		}
+		v, ok := value(strings.TrimSpace(text))
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q: expected host=%s", what, item, format)
+		}
+		if _, dup := settings[host]; dup {
+			return nil, fmt.Errorf("duplicate %s for %s", what, host)
+		}
+		settings[host] = v
+	}
+	return settings, nil
+}
+
+// ParseRetries parses retry counts of the form host=count, e.g. "api.github.com=3,*=1".
+func ParseRetries(spec string) (map[string]int, error) {
+	return parse(spec, "retry setting", "count", func(text string) (int, bool) {
+		n, err := strconv.Atoi(text)
+		return n, err == nil && n >= 0
+	})
+}
+
+// ParseBreakers parses circuit breakers of the form host=failures/cooldown, e.g.
+// "*=5/30s".
+func ParseBreakers(spec string) (map[string]Breaker, error) {
+	return parse(spec, "circuit breaker", "failures/cooldown", func(text string) (Breaker, bool) {
+		failures, cooldown, ok := strings.Cut(text, "/")
+		if !ok {
+			return Breaker{}, false
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(failures))
+		if err != nil || n < 1 {
+			return Breaker{}, false
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(cooldown))
+		if err != nil || d <= 0 {
+			return Breaker{}, false
+		}
+		return Breaker{Failures: n, Cooldown: d}, true
+	})
+}
+
+// ParseBulkheads parses limits of concurrent requests of the form host=count, e.g.
+// "*=10".
+func ParseBulkheads(spec string) (map[string]int, error) {
+	return parse(spec, "bulkhead", "count", func(text string) (int, bool) {
+		n, err := strconv.Atoi(text)
+		return n, err == nil && n >= 1
+	})
+}
+
+// lookup returns the setting of host, or the one of AnyUpstream.
+func lookup[T any](settings map[string]T, host string) (T, bool) {
+	if v, ok := settings[host]; ok {
+		return v, true
+	}
+	v, ok := settings[AnyUpstream]
+	return v, ok
+}
+
+// Stats are the statistics of the requests to an upstream host.
+type Stats struct {
+	// Requests counts the requests made through the transport.
+	Requests int64 `json:"requests"`
+	// Retries counts the retries of failed requests.
+	Retries int64 `json:"retries"`
+	// Failures counts the failed attempts: errors and 429 or 5xx responses.
+	Failures int64 `json:"failures"`
+	// Rejected counts the requests rejected by an open circuit breaker or a full
+	// bulkhead.
+	Rejected int64 `json:"rejected"`
+	// InFlight is the number of requests in progress.
+	InFlight int `json:"inFlight"`
+	// Breaker is the state of the circuit breaker: closed, open or half-open.
+	Breaker string `json:"breaker,omitempty"`
+}
+
+// Circuit breaker states.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// upstream is the state of the requests to a host.
+type upstream struct {
+	retries  int
+	breaker  Breaker
+	slots    chan struct{} // nil without a bulkhead
+	stats    Stats
+	failures int       // consecutive failures
+	openedAt time.Time // when the breaker opened, zero while closed
+	probing  bool      // whether the probe of a half-open breaker is in flight
+}
+
+// Transport is an http.RoundTripper applying a Config to the requests sent through
+// its base.
+type Transport struct {
+	base   http.RoundTripper
+	config Config
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	upstreams map[string]*upstream
+}
+
+// New returns a Transport sending requests through base with config applied.
+func New(config Config, base http.RoundTripper) *Transport {
+	return &Transport{
+		base:      base,
+		config:    config,
+		now:       time.Now,
+		sleep:     sleep,
+		upstreams: make(map[string]*upstream),
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// upstream returns the state of host, creating it on first use.
+func (t *Transport) upstream(host string) *upstream {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.upstreams[host]
+	if !ok {
+		u = &upstream{}
+		u.retries, _ = lookup(t.config.Retries, host)
+		u.breaker, _ = lookup(t.config.Breakers, host)
+		if n, ok := lookup(t.config.Bulkheads, host); ok {
+			u.slots = make(chan struct{}, n)
+		}
+		t.upstreams[host] = u
+	}
+	return u
+}
+
+// Stats returns the statistics of the upstream hosts requested so far.
+func (t *Transport) Stats() map[string]Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[string]Stats, len(t.upstreams))
+	for host, u := range t.upstreams {
+		s := u.stats
+		if u.breaker.Failures > 0 {
+			s.Breaker = t.state(u)
+		}
+		stats[host] = s
+	}
+	return stats
+}
+
+// state returns the state of the breaker of u; t.mu must be held.
+func (t *Transport) state(u *upstream) string {
+	switch {
+	case u.openedAt.IsZero():
+		return StateClosed
+	case t.now().Sub(u.openedAt) < u.breaker.Cooldown:
+		return StateOpen
+	default:
+		return StateHalfOpen
+	}
+}
+
+// RoundTrip sends req, retrying it while it fails, unless the breaker of its host is
+// open or its bulkhead full.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	u := t.upstream(host)
+	t.count(func() { u.stats.Requests++ })
+
+	if u.slots != nil {
+		select {
+		case u.slots <- struct{}{}:
+			defer func() { <-u.slots }()
+		case <-req.Context().Done():
+			t.count(func() { u.stats.Rejected++ })
+			closeBody(req)
+			return nil, fmt.Errorf("%s: all %d request slots busy: %w", host, cap(u.slots), req.Context().Err())
+		}
+	}
+	t.count(func() { u.stats.InFlight++ })
+	defer t.count(func() { u.stats.InFlight-- })
+
+	retries := u.retries
+	if !retryable(req) {
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if err := t.admit(host, u); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				t.record(u, outcomeCanceled)
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err := t.base.RoundTrip(req)
+		result := outcome(req, resp, err)
+		t.record(u, result)
+		if result != outcomeFailed || attempt >= retries {
+			return resp, err
+		}
+
+		delay := backoff(attempt, resp, rand.Float64())
+		// A retry that cannot finish in the time left to the tool call is not attempted
+		if remaining, ok := budget.Remaining(req.Context()); ok && remaining-delay < budget.Reserve+budget.MinRequest {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		t.count(func() { u.stats.Retries++ })
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// count updates the statistics under the lock.
+func (t *Transport) count(update func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update()
+}
+
+// admit returns an error if the breaker of u rejects a request. A half-open breaker
+// admits one probe at a time.
+func (t *Transport) admit(host string, u *upstream) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u.breaker.Failures == 0 {
+		return nil
+	}
+	switch t.state(u) {
+	case StateOpen:
+		u.stats.Rejected++
+		retryIn := u.breaker.Cooldown - t.now().Sub(u.openedAt)
+		return fmt.Errorf("%s: circuit breaker open after %d consecutive failures; retry in %s", host, u.failures, retryIn.Round(time.Second))
+	case StateHalfOpen:
+		if u.probing {
+			u.stats.Rejected++
+			return fmt.Errorf("%s: circuit breaker open after %d consecutive failures; probing whether it recovered", host, u.failures)
+		}
+		u.probing = true
+	}
+	return nil
+}
+
+// Outcomes of an attempt.
+const (
+	outcomeOK = iota
+	outcomeFailed
+	outcomeCanceled
+)
+
+// record updates the breaker of u with the outcome of an attempt. A canceled attempt
+// says nothing about the upstream.
+func (t *Transport) record(u *upstream, result int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u.probing = false
+	switch result {
+	case outcomeOK:
+		u.failures = 0
+		u.openedAt = time.Time{}
+	case outcomeFailed:
+		u.stats.Failures++
+		u.failures++
+		if u.breaker.Failures > 0 && u.failures >= u.breaker.Failures {
+			// A failed probe opens the breaker for another cooldown
+			u.openedAt = t.now()
+		}
+	}
+}
+
+// outcome classifies an attempt. It failed, in a way worth retrying and counting
+// against the upstream, on an error other than the cancellation of the request, or a
+// response saying the upstream is overloaded or failing.
+func outcome(req *http.Request, resp *http.Response, err error) int {
+	if err != nil {
+		if req.Context().Err() != nil || errors.Is(err, context.Canceled) {
+			return outcomeCanceled
+		}
+		return outcomeFailed
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return outcomeFailed
+	}
+	return outcomeOK
+}
+
+// retryKey marks the contexts of requests that may be retried whatever their method.
+type retryKey struct{}
+
+// AllowRetry returns ctx letting the requests made with it be retried although their
+// method is not idempotent, for requests the upstream deduplicates, such as webhook
+// deliveries with an ID.
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// retryable reports whether req may be sent again: it is idempotent, or its context
+// allows it, and its body, if any, can be read again.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Context().Value(retryKey{}) == nil {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// backoff returns the delay before retrying after attempt: the Retry-After of resp in
+// seconds if any, or an exponential backoff with jitter in [0.5, 1) times the delay.
+func backoff(attempt int, resp *http.Response, jitter float64) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+	delay := maxBackoff
+	if attempt < 16 {
+		delay = min(baseBackoff<<attempt, maxBackoff)
+	}
+	return time.Duration(float64(delay) * (0.5 + jitter/2))
+}
+
+// closeBody closes the body of a request that will not be sent, as RoundTrip must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,201 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTransport returns a Transport with config that records its delays instead of
+// sleeping.
+func newTransport(config Config, delays *[]time.Duration) *Transport {
+	t := New(config, http.DefaultTransport)
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return t
+}
+
+// Test the settings
+func TestParse(t *testing.T) {
+	retries, err := ParseRetries("API.github.com=3, *=0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"api.github.com": 3, "*": 0}, retries)
+	breakers, err := ParseBreakers("*=5/30s")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Breaker{"*": {Failures: 5, Cooldown: 30 * time.Second}}, breakers)
+	bulkheads, err := ParseBulkheads("")
+	require.NoError(t, err)
+	assert.Empty(t, bulkheads)
+
+	_, err = ParseRetries("api.github.com=-1")
+	assert.EqualError(t, err, `invalid retry setting "api.github.com=-1": expected host=count`)
+	_, err = ParseBreakers("*=5")
+	assert.EqualError(t, err, `invalid circuit breaker "*=5": expected host=failures/cooldown`)
+	_, err = ParseBreakers("*=0/1m")
+	assert.Error(t, err)
+	_, err = ParseBulkheads("*=0")
+	assert.Error(t, err)
+	_, err = ParseBulkheads("*=1,*=2")
+	assert.EqualError(t, err, "duplicate bulkhead for *")
+}
+
+// Test that failed idempotent requests are retried with backoff
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer upstream.Close()
+
+	var delays []time.Duration
+	transport := newTransport(Config{Retries: map[string]int{"*": 2}}, &delays)
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(upstream.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, delays, 2)
+	assert.True(t, delays[0] >= baseBackoff/2 && delays[0] < baseBackoff, delays[0])
+	assert.Equal(t, 3*time.Second, delays[1], "Retry-After should be honored")
+
+	// Requests are sent again with their body
+	calls.Store(0)
+	resp, err = client.Do(mustRequest(t, http.MethodPut, upstream.URL, "data"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// POST is not idempotent
+	calls.Store(0)
+	resp, err = client.Post(upstream.URL, "text/plain", strings.NewReader("data"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Unless its context allows it
+	calls.Store(0)
+	req := mustRequest(t, http.MethodPost, upstream.URL, "data")
+	resp, err = client.Do(req.WithContext(AllowRetry(req.Context())))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	host := strings.Split(strings.TrimPrefix(upstream.URL, "http://"), ":")[0]
+	assert.Equal(t, Stats{Requests: 4, Retries: 6, Failures: 7}, transport.Stats()[host])
+
+	// Retries that cannot finish in the time left to the call are not attempted
+	calls.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "Retry-After is longer than the time left")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func mustRequest(t *testing.T, method, url, body string) *http.Request {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	return req
+}
+
+// Test that the circuit breaker rejects requests while open and closes once a probe
+// succeeds
+func TestBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer upstream.Close()
+
+	var delays []time.Duration
+	transport := newTransport(Config{Breakers: map[string]Breaker{"*": {Failures: 2, Cooldown: time.Minute}}}, &delays)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(upstream.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get(upstream.URL)
+	assert.ErrorContains(t, err, "circuit breaker open after 2 consecutive failures; retry in 1m0s")
+	assert.Equal(t, int32(2), calls.Load(), "Rejected requests should not reach the upstream")
+	host := strings.Split(strings.TrimPrefix(upstream.URL, "http://"), ":")[0]
+	assert.Equal(t, StateOpen, transport.Stats()[host].Breaker)
+
+	// A failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, transport.Stats()[host].Breaker)
+	resp, err := client.Get(upstream.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = client.Get(upstream.URL)
+	assert.ErrorContains(t, err, "circuit breaker open")
+
+	now = now.Add(time.Minute)
+	failing.Store(false)
+	resp, err = client.Get(upstream.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, Stats{Requests: 6, Failures: 3, Rejected: 2, Breaker: StateClosed}, transport.Stats()[host])
+}
+
+// Test that the bulkhead limits concurrent requests
+func TestBulkhead(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	var delays []time.Duration
+	transport := newTransport(Config{Bulkheads: map[string]int{"*": 1}}, &delays)
+	client := &http.Client{Transport: transport}
+	go func() {
+		if resp, err := client.Get(upstream.URL); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	client.Timeout = 50 * time.Millisecond
+	_, err := client.Get(upstream.URL)
+	assert.ErrorContains(t, err, "all 1 request slots busy")
+	host := strings.Split(strings.TrimPrefix(upstream.URL, "http://"), ":")[0]
+	stats := transport.Stats()[host]
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.Equal(t, 1, stats.InFlight)
+}
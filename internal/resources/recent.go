@@ -0,0 +1,123 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Recent keeps the latest results of the tools of a server as resources: an index
+// listing them, at e.g. fetch://results, and each result at e.g. fetch://results/7.
+// Clients subscribed to the index are notified when a result is added.
+type Recent struct {
+	server *server.MCPServer
+	base   string
+	size   int
+
+	mu      sync.Mutex
+	nextID  int
+	entries []entry // oldest first
+}
+
+type entry struct {
+	ID       int       `json:"-"`
+	URI      string    `json:"uri"`
+	Name     string    `json:"name"`
+	MIMEType string    `json:"mimeType"`
+	Time     time.Time `json:"time"`
+	text     string
+}
+
+// NewRecent adds the resources of the last size results to s, under base, e.g.
+// fetch://results. what describes the results, e.g. "fetched URLs".
+func NewRecent(s *server.MCPServer, base, what string, size int) *Recent {
+	r := &Recent{server: s, base: base, size: size, nextID: 1}
+	s.AddResource(mcp.NewResource(base, "Recent "+what,
+		mcp.WithResourceDescription(fmt.Sprintf("JSON list of the last %d %s, newest first, each readable at its URI", size, what)),
+		mcp.WithMIMEType("application/json"),
+	), r.readIndex)
+	s.AddResourceTemplate(mcp.NewResourceTemplate(base+"/{id}", "Recent "+what+" by ID",
+		mcp.WithTemplateDescription(fmt.Sprintf("One of the last %d %s, as listed at %s", size, what, base)),
+	), r.readEntry)
+	return r
+}
+
+// Add keeps a result named name, e.g. the URL it came from, and returns its URI.
+func (r *Recent) Add(ctx context.Context, name, mimeType, text string) string {
+	r.mu.Lock()
+	e := entry{
+		ID:       r.nextID,
+		URI:      fmt.Sprintf("%s/%d", r.base, r.nextID),
+		Name:     name,
+		MIMEType: mimeType,
+		Time:     time.Now().UTC(),
+		text:     text,
+	}
+	r.nextID++
+	r.entries = append(r.entries, e)
+	if len(r.entries) > r.size {
+		r.entries = append([]entry(nil), r.entries[len(r.entries)-r.size:]...)
+	}
+	r.mu.Unlock()
+
+	Updated(ctx, r.server, r.base)
+	return e.URI
+}
+
+func (r *Recent) readIndex(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	r.mu.Lock()
+	index := make([]entry, 0, len(r.entries))
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		index = append(index, r.entries[i])
+	}
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      r.base,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}, nil
+}
+
+func (r *Recent) readEntry(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	idArg := Argument(req, "id")
+	id, err := strconv.Atoi(idArg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result ID %q", idArg)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.ID == id {
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      e.URI,
+				MIMEType: e.MIMEType,
+				Text:     e.text,
+			}}, nil
+		}
+	}
+	return nil, fmt.Errorf("no result %s; only the last %d are kept", req.Params.URI, r.size)
+}
+
+// Argument returns the value of the variable name of the URI template a resource was
+// read with, which mcp-go passes as a list.
+func Argument(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+// Package resources adds MCP resources with subscriptions to the servers: clients
+// subscribe to a resource URI and are sent notifications/resources/updated when it
+// changes.
+//
+// mcp-go does not route resources/subscribe and resources/unsubscribe requests, so
+// the transports pass the messages of their clients through Route, which records the
+// subscription and turns the request into a ping, answered with the empty result
+// subscribe expects.
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+)
+
+// Methods of the subscription requests.
+const (
+	MethodSubscribe   = "resources/subscribe"
+	MethodUnsubscribe = "resources/unsubscribe"
+)
+
+// subscriptions are the client sessions of a server and the URIs they subscribed to.
+type subscriptions struct {
+	mu       sync.Mutex
+	sessions map[string]server.ClientSession
+	uris     map[string]map[string]bool // URI to the IDs of the subscribed sessions
+}
+
+var (
+	mu      sync.Mutex
+	servers = make(map[*server.MCPServer]*subscriptions)
+)
+
+func lookup(s *server.MCPServer) *subscriptions {
+	mu.Lock()
+	defer mu.Unlock()
+	return servers[s]
+}
+
+// NewMCPServer creates an MCP server offering resources that clients can subscribe
+// to. It installs hooks to track the client sessions, so opts must not include
+// server.WithHooks.
+func NewMCPServer(name, version string, opts ...server.ServerOption) *server.MCPServer {
+	subs := &subscriptions{
+		sessions: make(map[string]server.ClientSession),
+		uris:     make(map[string]map[string]bool),
+	}
+	// mcp-go does not report disconnects; Updated forgets sessions that stop reading
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		subs.mu.Lock()
+		subs.sessions[session.SessionID()] = session
+		subs.mu.Unlock()
+	})
+	opts = append([]server.ServerOption{
+		server.WithResourceCapabilities(true, false),
+		server.WithHooks(hooks),
+	}, opts...)
+	s := server.NewMCPServer(name, version, opts...)
+
+	mu.Lock()
+	servers[s] = subs
+	mu.Unlock()
+	middleware.OnClose(s, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(servers, s)
+		return nil
+	})
+	return s
+}
+
+// Updated notifies the clients of s subscribed to uri that the resource changed.
+func Updated(ctx context.Context, s *server.MCPServer, uri string) {
+	subs := lookup(s)
+	if subs == nil {
+		return
+	}
+	subs.mu.Lock()
+	var sessions []server.ClientSession
+	for id := range subs.uris[uri] {
+		if session, ok := subs.sessions[id]; ok {
+			sessions = append(sessions, session)
+		}
+	}
+	subs.mu.Unlock()
+
+	for _, session := range sessions {
+		err := s.SendNotificationToClient(s.WithContext(ctx, session), "notifications/resources/updated", map[string]interface{}{
+			"uri": uri,
+		})
+		if err != nil {
+			// The notification channel of a disconnected session is no longer drained
+			// and fills up, so a session that cannot take a notification is gone
+			log.Printf("Error: Failed to notify session %s of an update of %s, forgetting it: %v", session.SessionID(), uri, err)
+			subs.forget(session.SessionID())
+		}
+	}
+}
+
+func (subs *subscriptions) forget(sessionID string) {
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	delete(subs.sessions, sessionID)
+	for uri, ids := range subs.uris {
+		delete(ids, sessionID)
+		if len(ids) == 0 {
+			delete(subs.uris, uri)
+		}
+	}
+}
+
+// Subscribed returns the URIs the session subscribed to on s.
+func Subscribed(s *server.MCPServer, sessionID string) []string {
+	subs := lookup(s)
+	if subs == nil {
+		return nil
+	}
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	var uris []string
+	for uri, ids := range subs.uris {
+		if ids[sessionID] {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// Route handles message when it is a subscription request of the session to s, and
+// returns the message to pass on to s: a ping with the ID of the request in its
+// place, or message itself.
+func Route(s *server.MCPServer, sessionID string, message []byte) []byte {
+	subs := lookup(s)
+	if subs == nil || !bytes.Contains(message, []byte("resources/")) {
+		return message
+	}
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil || request.Params.URI == "" {
+		return message
+	}
+
+	subs.mu.Lock()
+	switch request.Method {
+	case MethodSubscribe:
+		if subs.uris[request.Params.URI] == nil {
+			subs.uris[request.Params.URI] = make(map[string]bool)
+		}
+		subs.uris[request.Params.URI][sessionID] = true
+	case MethodUnsubscribe:
+		delete(subs.uris[request.Params.URI], sessionID)
+		if len(subs.uris[request.Params.URI]) == 0 {
+			delete(subs.uris, request.Params.URI)
+		}
+	default:
+		subs.mu.Unlock()
+		return message
+	}
+	subs.mu.Unlock()
+
+	ping, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      request.ID,
+		"method":  "ping",
+	})
+	if err != nil {
+		return message
+	}
+	return ping
+}
+
+// Handler passes the messages posted to h, the SSE handler of s, through Route.
+func Handler(s *server.MCPServer, h http.Handler) http.Handler {
+	if lookup(s) == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.URL.Query().Get("sessionId")
+		if r.Method != http.MethodPost || sessionID == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, "Failed to read the request body", http.StatusBadRequest)
+			return
+		}
+		body = Route(s, sessionID, body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,112 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *session) SessionID() string                                   { return s.id }
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *session) Initialize()                                         {}
+func (s *session) Initialized() bool                                   { return true }
+
+func subscription(method, uri string) []byte {
+	return []byte(`{"jsonrpc":"2.0","id":7,"method":"` + method + `","params":{"uri":"` + uri + `"}}`)
+}
+
+// Test subscribing to the recent results and reading them
+func TestRecent(t *testing.T) {
+	ctx := context.Background()
+	s := NewMCPServer("test", "1.0.0")
+	recent := NewRecent(s, "test://results", "results", 2)
+	client := &session{id: "a", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.RegisterSession(ctx, client))
+
+	// Subscribing is answered like a ping, with an empty result
+	ping := Route(s, "a", subscription(MethodSubscribe, "test://results"))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"method":"ping"}`, string(ping))
+	response, err := json.Marshal(s.HandleMessage(ctx, ping))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":{}}`, string(response))
+	assert.Equal(t, []string{"test://results"}, Subscribed(s, "a"))
+
+	for _, name := range []string{"first", "second", "third"} {
+		recent.Add(ctx, name, "text/plain", "result of "+name)
+	}
+	require.Len(t, client.notifications, 3)
+	notification := <-client.notifications
+	assert.Equal(t, "notifications/resources/updated", notification.Method)
+	assert.Equal(t, "test://results", notification.Params.AdditionalFields["uri"])
+
+	contents, err := mcptest.ReadResource(ctx, s, "test://results")
+	require.NoError(t, err)
+	var index []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResourceText(contents)), &index))
+	require.Len(t, index, 2)
+	assert.Equal(t, "test://results/3", index[0]["uri"])
+	assert.Equal(t, "third", index[0]["name"])
+	assert.Equal(t, "test://results/2", index[1]["uri"])
+
+	contents, err = mcptest.ReadResource(ctx, s, "test://results/2")
+	require.NoError(t, err)
+	assert.Equal(t, "result of second", mcptest.ResourceText(contents))
+	_, err = mcptest.ReadResource(ctx, s, "test://results/1")
+	assert.EqualError(t, err, "no result test://results/1; only the last 2 are kept")
+
+	// Unsubscribed clients are not notified
+	Route(s, "a", subscription(MethodUnsubscribe, "test://results"))
+	assert.Empty(t, Subscribed(s, "a"))
+	<-client.notifications
+	<-client.notifications
+	recent.Add(ctx, "fourth", "text/plain", "")
+	assert.Empty(t, client.notifications)
+
+	// Other messages, and servers without subscriptions, are left alone
+	list := []byte(`{"jsonrpc":"2.0","id":8,"method":"resources/list"}`)
+	assert.Equal(t, list, Route(s, "a", list))
+	other := server.NewMCPServer("other", "1.0.0")
+	subscribe := subscription(MethodSubscribe, "test://results")
+	assert.Equal(t, subscribe, Route(other, "a", subscribe))
+}
+
+// Test that sessions that stop reading notifications are forgotten
+func TestUpdatedForgetsSessions(t *testing.T) {
+	ctx := context.Background()
+	s := NewMCPServer("test", "1.0.0")
+	client := &session{id: "a", notifications: make(chan mcp.JSONRPCNotification)}
+	require.NoError(t, s.RegisterSession(ctx, client))
+	Route(s, "a", subscription(MethodSubscribe, "test://x"))
+
+	Updated(ctx, s, "test://x")
+	assert.Empty(t, Subscribed(s, "a"))
+}
+
+// Test that the messages of SSE clients are routed
+func TestHandler(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	var posted string
+	h := Handler(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = string(body)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/message?sessionId=b", strings.NewReader(string(subscription(MethodSubscribe, "test://x")))))
+	assert.Equal(t, `{"id":7,"jsonrpc":"2.0","method":"ping"}`, posted)
+	assert.Equal(t, []string{"test://x"}, Subscribed(s, "b"))
+}
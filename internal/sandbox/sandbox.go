@@ -0,0 +1,257 @@
+// Package sandbox confines the files servers read and write to a root directory:
+// paths are resolved inside it, symlinks may not lead out of it, glob rules allow
+// and deny paths within it, and files larger than a maximum size are refused.
+package sandbox
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Options are the rules of a sandbox.
+type Options struct {
+	// Allow are glob patterns of the files that may be used, all when empty.
+	Allow []string
+	// Deny are glob patterns of the files and directories that may not be used, even
+	// when allowed.
+	Deny []string
+	// MaxFileSize is the size in bytes of the largest file that may be read, unlimited
+	// when 0.
+	MaxFileSize int64
+}
+
+// Sandbox resolves paths inside a root directory.
+type Sandbox struct {
+	root        string
+	name        string
+	allow, deny []*regexp.Regexp
+	maxFileSize int64
+}
+
+// New returns the sandbox of root with the rules of o. name describes root in error
+// messages, such as "data directory".
+//
+// Patterns are matched against paths relative to root, with / as separator: * and ?
+// match within a path element, ** across elements. A pattern without a slash matches
+// any element, e.g. *.pem or .git, otherwise the path from root, e.g. secrets/**.
+// A directory matching a deny pattern denies all of its content.
+func New(root, name string, o Options) *Sandbox {
+	s := &Sandbox{root: root, name: name, maxFileSize: o.MaxFileSize}
+	s.SetRules(o.Allow, o.Deny)
+	return s
+}
+
+// SetRules replaces the allow and deny patterns of s. It is called before s is used.
+func (s *Sandbox) SetRules(allow, deny []string) {
+	s.allow, s.deny = nil, nil
+	for _, pattern := range allow {
+		s.allow = append(s.allow, globRegexp(pattern))
+	}
+	for _, pattern := range deny {
+		s.deny = append(s.deny, globRegexp(pattern))
+	}
+}
+
+// realRoot returns the absolute root, with symlinks resolved.
+func (s *Sandbox) realRoot() (string, error) {
+	root, err := filepath.Abs(s.root)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", s.name, err)
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	return root, nil
+}
+
+// Resolve returns p resolved inside the root. Absolute paths and ".." components are
+// clamped to the root, symlinks on the deepest existing ancestor must not lead outside
+// it, and the path must be allowed by the rules.
+func (s *Sandbox) Resolve(p string) (string, error) {
+	root, err := s.realRoot()
+	if err != nil {
+		return "", err
+	}
+
+	resolved := filepath.Join(root, filepath.Clean(string(filepath.Separator)+p))
+	if !inside(root, resolved) {
+		return "", toolerr.Errorf(toolerr.PermissionDenied, "path escapes the %s: %s", s.name, p)
+	}
+
+	// Resolve symlinks on the deepest existing ancestor so links cannot point outside the jail
+	existing := resolved
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	if real, err := filepath.EvalSymlinks(existing); err == nil && !inside(root, real) {
+		return "", toolerr.Errorf(toolerr.PermissionDenied, "path escapes the %s via symlink: %s", s.name, p)
+	}
+
+	if !s.allowed(root, resolved) {
+		return "", toolerr.Errorf(toolerr.PermissionDenied, "%s is not allowed in the %s", p, s.name)
+	}
+	return resolved, nil
+}
+
+// Allowed reports whether the rules allow resolved, a path inside the root, e.g. one
+// found walking a directory returned by Resolve.
+func (s *Sandbox) Allowed(resolved string) bool {
+	root, err := s.realRoot()
+	return err == nil && inside(root, resolved) && s.allowed(root, resolved)
+}
+
+// allowed reports whether the rules allow path, inside root. Allow patterns apply to
+// files, not to existing directories, which may hold allowed files.
+func (s *Sandbox) allowed(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return err == nil
+	}
+	rel = filepath.ToSlash(rel)
+	// A denied directory denies its content
+	for dir := rel; dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if matchAny(s.deny, dir) {
+			return false
+		}
+	}
+	if len(s.allow) == 0 || matchAny(s.allow, rel) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Stat resolves p and returns it with its information, if it is a regular file within
+// the maximum size.
+func (s *Sandbox) Stat(p string) (string, fs.FileInfo, error) {
+	resolved, err := s.Resolve(p)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", p, errors.Unwrap(err))
+	}
+	if info.IsDir() {
+		return "", nil, toolerr.Errorf(toolerr.InvalidParams, "%s is a directory", p)
+	}
+	if !info.Mode().IsRegular() {
+		return "", nil, toolerr.Errorf(toolerr.InvalidParams, "%s is not a regular file", p)
+	}
+	if err := s.CheckSize(p, info.Size()); err != nil {
+		return "", nil, err
+	}
+	return resolved, info, nil
+}
+
+// ReadFile returns the content of the file p, if it is a regular file within the
+// maximum size.
+func (s *Sandbox) ReadFile(p string) ([]byte, error) {
+	resolved, _, err := s.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return data, nil
+}
+
+// CheckSize returns an error if size bytes of what, such as a file or decoded data,
+// exceed the maximum file size.
+func (s *Sandbox) CheckSize(what string, size int64) error {
+	if s.maxFileSize > 0 && size > s.maxFileSize {
+		return toolerr.Errorf(toolerr.InvalidParams, "%s of %d bytes exceeds the maximum size of %d bytes", what, size, s.maxFileSize)
+	}
+	return nil
+}
+
+// inside reports whether path is root or below it.
+func inside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func matchAny(patterns []*regexp.Regexp, rel string) bool {
+	for _, re := range patterns {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp compiles a glob pattern to a regular expression matching slash separated
+// relative paths.
+func globRegexp(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(filepath.ToSlash(glob), "./")
+	if !strings.Contains(strings.TrimSuffix(glob, "/"), "/") {
+		glob = "**/" + glob
+	}
+	glob = strings.TrimSuffix(strings.TrimPrefix(glob, "/"), "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// Flags are the command line flags of the rules of a sandbox.
+type Flags struct {
+	Allow string
+	Deny  string
+}
+
+// Register adds the sandbox flags to fs. what names the root directory in their
+// descriptions, e.g. "data directory".
+func (f *Flags) Register(fs *flag.FlagSet, what string) {
+	fs.StringVar(&f.Allow, "allow-paths", "", "Comma separated glob patterns of the files in the "+what+" tools may use, e.g. '*.csv,reports/**' (default: all)")
+	fs.StringVar(&f.Deny, "deny-paths", "", "Comma separated glob patterns of the files and directories in the "+what+" tools may not use, e.g. '.git,*.key'")
+}
+
+// Apply sets the rules of the flags on s.
+func (f *Flags) Apply(s *Sandbox) {
+	s.SetRules(splitList(f.Allow), splitList(f.Deny))
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
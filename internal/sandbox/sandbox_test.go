@@ -0,0 +1,130 @@
+package sandbox
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Test that paths stay inside the root
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	real, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(real, "sub"), filepath.Join(dir, "inner")))
+	s := New(dir, "data directory", Options{})
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+		err      string
+	}{
+		{name: "Relative path", path: "reports/q1.xlsx", expected: filepath.Join(real, "reports", "q1.xlsx")},
+		{name: "Root", path: "", expected: real},
+		{name: "Traversal is clamped", path: "../../etc/passwd", expected: filepath.Join(real, "etc", "passwd")},
+		{name: "Absolute path is clamped", path: "/etc/passwd", expected: filepath.Join(real, "etc", "passwd")},
+		{name: "Symlink inside the root", path: "inner/new.txt", expected: filepath.Join(real, "inner", "new.txt")},
+		{name: "Symlink escape", path: "escape/file.txt", err: "path escapes the data directory via symlink: escape/file.txt"},
+		{name: "Symlink escape of a missing file", path: "escape/a/b/c", err: "via symlink"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := s.Resolve(tc.path)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				assert.Equal(t, toolerr.PermissionDenied, toolerr.Classify(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resolved)
+		})
+	}
+}
+
+// Test the allow and deny rules
+func TestRules(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "reports", "old"), 0755))
+	s := New(dir, "data directory", Options{
+		Allow: []string{"*.csv", "docs/**"},
+		Deny:  []string{"secrets", "reports/old/**", ".*"},
+	})
+
+	for p, allowed := range map[string]bool{
+		"":                      true,
+		"sales.csv":             true,
+		"reports/q1.csv":        true,
+		"reports":               true,
+		"docs/guide/intro.md":   true,
+		"notes.txt":             false,
+		"secrets/keys.csv":      false,
+		"a/secrets/b/keys.csv":  false,
+		"reports/old/q1.csv":    false,
+		".env.csv":              false,
+		"reports/.hidden/a.csv": false,
+		"missing-dir":           false,
+	} {
+		_, err := s.Resolve(p)
+		if allowed {
+			assert.NoError(t, err, p)
+		} else {
+			assert.EqualError(t, err, p+" is not allowed in the data directory")
+		}
+	}
+
+	real, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.True(t, s.Allowed(filepath.Join(real, "reports", "q1.csv")))
+	assert.False(t, s.Allowed(filepath.Join(real, "secrets", "q1.csv")))
+	assert.False(t, s.Allowed(filepath.Dir(real)))
+}
+
+// Test reading files within the maximum size
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "large.txt"), []byte("hello, world"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	s := New(dir, "data directory", Options{MaxFileSize: 10})
+
+	data, err := s.ReadFile("small.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = s.ReadFile("large.txt")
+	assert.EqualError(t, err, "large.txt of 12 bytes exceeds the maximum size of 10 bytes")
+	_, err = s.ReadFile("sub")
+	assert.EqualError(t, err, "sub is a directory")
+	_, err = s.ReadFile("missing.txt")
+	assert.ErrorContains(t, err, "failed to read missing.txt")
+	assert.Equal(t, toolerr.NotFound, toolerr.Classify(err).Code)
+
+	assert.NoError(t, New(dir, "data directory", Options{}).CheckSize("data", 1<<40))
+}
+
+// Test the flags of the rules
+func TestFlags(t *testing.T) {
+	var f Flags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Register(fs, "data directory")
+	require.NoError(t, fs.Parse([]string{"-allow-paths", "*.csv, reports/**", "-deny-paths", ".git"}))
+	dir := t.TempDir()
+	s := New(dir, "data directory", Options{})
+	f.Apply(s)
+	_, err := s.Resolve("reports/2025/q1.csv")
+	assert.NoError(t, err)
+	_, err = s.Resolve("notes.txt")
+	assert.Error(t, err)
+	_, err = s.Resolve(".git/config")
+	assert.Error(t, err)
+}
@@ -0,0 +1,299 @@
+// Package secretref resolves references to secrets given in place of flag and config
+// values, so that API keys and tokens need not be written in config files or passed
+// on command lines, where ps shows them to every user of the machine:
+//
+//	env:GOOGLE_API_KEY            the environment variable GOOGLE_API_KEY
+//	file:~/.config/mcphost/key    the contents of a file, without the final newline
+//	keychain:mcphost/googlesearch the password of account googlesearch of service
+//	                              mcphost in the macOS keychain or the Secret Service
+//	vault:secret/data/mcphost#key field key of a HashiCorp Vault secret, at
+//	                              VAULT_ADDR with VAULT_TOKEN or ~/.vault-token
+//
+// Other values are used as they are.
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Timeout bounds reading one secret from the keychain or Vault.
+var Timeout = 10 * time.Second
+
+// resolvers read the secret of a reference, by its scheme.
+var resolvers = map[string]func(ctx context.Context, ref string) (string, error){
+	"env":      resolveEnv,
+	"file":     resolveFile,
+	"keychain": resolveKeychain,
+	"vault":    resolveVault,
+}
+
+// IsRef reports whether value is a reference to a secret.
+func IsRef(value string) bool {
+	scheme, ref, ok := strings.Cut(value, ":")
+	return ok && ref != "" && resolvers[scheme] != nil
+}
+
+// Resolve returns the secret value refers to, or value itself if it is not a reference.
+// Errors name the reference but never the secret.
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	scheme, ref, _ := strings.Cut(value, ":")
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	secret, err := resolvers[scheme](ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", value, err)
+	}
+	return secret, nil
+}
+
+// Environ returns env as NAME=value entries for the environment of a process, sorted by
+// name, with references resolved.
+func Environ(env map[string]string) ([]string, error) {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make([]string, 0, len(env))
+	for _, name := range names {
+		value, err := Resolve(env[name])
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", name, err)
+		}
+		entries = append(entries, name+"="+value)
+	}
+	return entries, nil
+}
+
+func resolveEnv(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// expandHome replaces a leading ~/ in path with the home directory.
+func expandHome(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home directory: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	return path, nil
+}
+
+func resolveFile(ctx context.Context, path string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// keychainCommand returns the command printing the password of account of service in
+// the keychain of the OS. An empty account matches any.
+var keychainCommand = func(ctx context.Context, service, account string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+		return exec.CommandContext(ctx, "security", args...), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// The Secret Service of GNOME Keyring or KWallet, through libsecret
+		args := []string{"lookup", "service", service}
+		if account != "" {
+			args = append(args, "account", account)
+		}
+		return exec.CommandContext(ctx, "secret-tool", args...), nil
+	default:
+		return nil, fmt.Errorf("keychain references are not supported on %s", runtime.GOOS)
+	}
+}
+
+func resolveKeychain(ctx context.Context, ref string) (string, error) {
+	service, account, _ := strings.Cut(ref, "/")
+	cmd, err := keychainCommand(ctx, service, account)
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%s found no password: %s", filepath.Base(cmd.Path), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	secret := strings.TrimRight(string(out), "\r\n")
+	if secret == "" {
+		return "", errors.New("no password found")
+	}
+	return secret, nil
+}
+
+// keychainStoreCommand returns the command saving secret as the password of account
+// of service in the keychain of the OS, replacing any previous one.
+var keychainStoreCommand = func(ctx context.Context, service, account, secret string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		// security takes the password as an argument only, or prompts for it
+		return exec.CommandContext(ctx, "security", "add-generic-password", "-U", "-s", service, "-a", account, "-w", secret), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd := exec.CommandContext(ctx, "secret-tool", "store", "--label", service+"/"+account, "service", service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+		return cmd, nil
+	default:
+		return nil, fmt.Errorf("keychain references are not supported on %s", runtime.GOOS)
+	}
+}
+
+// KeychainAvailable reports whether secrets can be stored in the keychain of the OS.
+func KeychainAvailable() bool {
+	cmd, err := keychainStoreCommand(context.Background(), "", "", "")
+	if err != nil {
+		return false
+	}
+	_, err = exec.LookPath(cmd.Args[0])
+	return err == nil
+}
+
+// Store saves secret where ref reads it from, so that ref can be written in place of
+// the secret: in the file of a file reference, created readable by its owner only,
+// or in the keychain for a keychain reference, which must name an account.
+func Store(ref, secret string) error {
+	scheme, path, _ := strings.Cut(ref, ":")
+	switch {
+	case !IsRef(ref):
+		return fmt.Errorf("%q is not a secret reference", ref)
+	case scheme == "file":
+		file, err := expandHome(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, []byte(secret+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to store %s: %w", ref, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		return os.Chmod(file, 0o600)
+	case scheme == "keychain":
+		service, account, _ := strings.Cut(path, "/")
+		if service == "" || account == "" {
+			return fmt.Errorf("failed to store %s: expected keychain:service/account", ref)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		cmd, err := keychainStoreCommand(ctx, service, account, secret)
+		if err != nil {
+			return err
+		}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to store %s: %w: %s", ref, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("secrets cannot be stored in %s references", scheme)
+	}
+}
+
+// defaultVaultAddr is the address of Vault when VAULT_ADDR is not set, as for the
+// vault command.
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// resolveVault reads a field of the secret at a path, e.g. secret/data/mcphost#key,
+// from the key/value engine of Vault, version 1 or 2. The field defaults to value.
+func resolveVault(ctx context.Context, ref string) (string, error) {
+	path, field, _ := strings.Cut(ref, "#")
+	if field == "" {
+		field = "value"
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault answered %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		// Version 2 of the key/value engine nests the secret with its metadata
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("the secret has no string field %s", field)
+	}
+	return value, nil
+}
+
+// vaultToken returns the token to read Vault with: VAULT_TOKEN or the token saved by
+// vault login.
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", errors.New("no vault token; set VAULT_TOKEN or run vault login")
+}
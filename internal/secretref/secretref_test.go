@@ -0,0 +1,130 @@
+package secretref
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test resolving references to the environment and to files
+func TestResolve(t *testing.T) {
+	t.Setenv("MCPHOST_TEST_SECRET", "from-env")
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	tests := map[string]string{
+		"env:MCPHOST_TEST_SECRET": "from-env",
+		"file:" + path:            "from-file",
+		"plain value":             "plain value",
+		"https://example.com":     "https://example.com",
+		"env:":                    "env:",
+	}
+	for value, want := range tests {
+		got, err := Resolve(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	_, err := Resolve("env:MCPHOST_TEST_UNSET")
+	assert.EqualError(t, err, "failed to resolve env:MCPHOST_TEST_UNSET: environment variable MCPHOST_TEST_UNSET is not set")
+	_, err = Resolve("file:" + filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+// Test reading secrets from the key/value engine of Vault
+func TestResolveVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/mcphost":
+			w.Write([]byte(`{"data":{"data":{"key":"v2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/mcphost":
+			w.Write([]byte(`{"data":{"value":"v1-secret"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	secret, err := Resolve("vault:secret/data/mcphost#key")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-secret", secret)
+	secret, err = Resolve("vault:kv/mcphost")
+	require.NoError(t, err)
+	assert.Equal(t, "v1-secret", secret)
+
+	_, err = Resolve("vault:secret/data/mcphost#other")
+	assert.ErrorContains(t, err, "the secret has no string field other")
+	_, err = Resolve("vault:secret/data/missing#key")
+	assert.ErrorContains(t, err, "vault answered 404")
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	_, err = Resolve("vault:secret/data/mcphost#key")
+	assert.ErrorContains(t, err, "vault answered 403")
+}
+
+// Test reading secrets from the keychain of the OS
+func TestResolveKeychain(t *testing.T) {
+	defer func(command func(context.Context, string, string) (*exec.Cmd, error)) { keychainCommand = command }(keychainCommand)
+	var service, account string
+	keychainCommand = func(ctx context.Context, s, a string) (*exec.Cmd, error) {
+		service, account = s, a
+		return exec.CommandContext(ctx, "echo", "from-keychain"), nil
+	}
+
+	secret, err := Resolve("keychain:mcphost/googlesearch")
+	require.NoError(t, err)
+	assert.Equal(t, "from-keychain", secret)
+	assert.Equal(t, "mcphost", service)
+	assert.Equal(t, "googlesearch", account)
+}
+
+// Test storing secrets where their references read them from
+func TestStore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets", "googlesearch-api-key")
+	require.NoError(t, Store("file:"+file, "s3cret"))
+	secret, err := Resolve("file:" + file)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", secret)
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	defer func(command func(context.Context, string, string, string) (*exec.Cmd, error)) {
+		keychainStoreCommand = command
+	}(keychainStoreCommand)
+	var stored []string
+	keychainStoreCommand = func(ctx context.Context, service, account, secret string) (*exec.Cmd, error) {
+		stored = []string{service, account, secret}
+		return exec.CommandContext(ctx, "true"), nil
+	}
+	require.NoError(t, Store("keychain:mcphost/googlesearch-api-key", "s3cret"))
+	assert.Equal(t, []string{"mcphost", "googlesearch-api-key", "s3cret"}, stored)
+
+	assert.Error(t, Store("keychain:mcphost", "s3cret"), "An account is required")
+	assert.Error(t, Store("vault:secret/data/mcphost#key", "s3cret"))
+	assert.Error(t, Store("plain", "s3cret"))
+}
+
+// Test the environment of a server, with references resolved
+func TestEnviron(t *testing.T) {
+	t.Setenv("MCPHOST_TEST_SECRET", "from-env")
+	env, err := Environ(map[string]string{"B": "env:MCPHOST_TEST_SECRET", "A": "plain"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A=plain", "B=from-env"}, env)
+
+	_, err = Environ(map[string]string{"API_KEY": "env:MCPHOST_TEST_UNSET"})
+	assert.ErrorContains(t, err, "env API_KEY: failed to resolve env:MCPHOST_TEST_UNSET")
+}
@@ -0,0 +1,343 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// Supported archive formats
+const (
+	formatZip   = "zip"
+	formatTar   = "tar"
+	formatTarGz = "tar.gz"
+	formatGzip  = "gz"
+)
+
+var formats = []string{formatZip, formatTar, formatTarGz, formatGzip}
+
+// entry describes a member of an archive.
+type entry struct {
+	Name           string      `json:"name"`
+	Type           string      `json:"type"` // file, dir, symlink or other
+	Size           int64       `json:"size"`
+	CompressedSize int64       `json:"compressedSize,omitempty"`
+	Mode           fs.FileMode `json:"-"`
+	Modified       time.Time   `json:"modified,omitempty"`
+	LinkTarget     string      `json:"linkTarget,omitempty"`
+	Encrypted      bool        `json:"encrypted,omitempty"`
+}
+
+// formatFromName returns the archive format implied by a file name, or "".
+func formatFromName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		return formatTarGz
+	case strings.HasSuffix(lower, ".tar"):
+		return formatTar
+	case strings.HasSuffix(lower, ".gz"):
+		return formatGzip
+	case strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".jar"):
+		return formatZip
+	}
+	return ""
+}
+
+// detectFormat identifies an archive from its first bytes.
+func detectFormat(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		return formatZip, nil
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		// A gzip stream holding a tar archive has the tar magic in its first block
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("invalid gzip data: %w", err)
+		}
+		header := make([]byte, 512)
+		n, _ := io.ReadFull(zr, header)
+		if isTarHeader(header[:n]) {
+			return formatTarGz, nil
+		}
+		return formatGzip, nil
+	case isTarHeader(data):
+		return formatTar, nil
+	}
+	return "", errors.New("unrecognized archive format; supported formats are " + strings.Join(formats, ", "))
+}
+
+func isTarHeader(block []byte) bool {
+	return len(block) >= 262 && bytes.Equal(block[257:262], []byte("ustar"))
+}
+
+// walkArchive calls fn for each entry of an archive. The reader passed to fn is only valid
+// during the call and is nil for entries without content.
+func walkArchive(data []byte, format, archiveName string, fn func(e entry, r io.Reader) error) error {
+	switch format {
+	case formatZip:
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return fmt.Errorf("invalid zip archive: %w", err)
+		}
+		for _, f := range zr.File {
+			e := entry{
+				Name:           f.Name,
+				Type:           typeFromMode(f.Mode()),
+				Size:           int64(f.UncompressedSize64),
+				CompressedSize: int64(f.CompressedSize64),
+				Mode:           f.Mode(),
+				Modified:       f.Modified,
+				Encrypted:      f.Flags&0x1 != 0,
+			}
+			if strings.HasSuffix(f.Name, "/") {
+				e.Type = "dir"
+			}
+			if e.Type != "file" && e.Type != "symlink" || e.Encrypted {
+				if err := fn(e, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("cannot read %s: %w", f.Name, err)
+			}
+			if e.Type == "symlink" {
+				target, err := io.ReadAll(io.LimitReader(rc, 4096))
+				rc.Close()
+				if err != nil {
+					return fmt.Errorf("cannot read %s: %w", f.Name, err)
+				}
+				e.LinkTarget = string(target)
+				err = fn(e, nil)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err = fn(e, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case formatTar, formatTarGz:
+		var r io.Reader = bytes.NewReader(data)
+		if format == formatTarGz {
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return fmt.Errorf("invalid gzip data: %w", err)
+			}
+			defer zr.Close()
+			r = zr
+		}
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid tar archive: %w", err)
+			}
+			e := entry{
+				Name:       h.Name,
+				Size:       h.Size,
+				Mode:       h.FileInfo().Mode(),
+				Modified:   h.ModTime,
+				LinkTarget: h.Linkname,
+			}
+			var content io.Reader
+			switch h.Typeflag {
+			case tar.TypeReg, tar.TypeRegA:
+				e.Type = "file"
+				content = tr
+			case tar.TypeDir:
+				e.Type = "dir"
+			case tar.TypeSymlink:
+				e.Type = "symlink"
+			case tar.TypeLink:
+				e.Type = "hardlink"
+			default:
+				e.Type = "other"
+			}
+			if err := fn(e, content); err != nil {
+				return err
+			}
+		}
+
+	case formatGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("invalid gzip data: %w", err)
+		}
+		defer zr.Close()
+		name := zr.Name
+		if name == "" {
+			name = strings.TrimSuffix(path.Base(archiveName), path.Ext(archiveName))
+		}
+		if name == "" || name == "." || name == "/" {
+			name = "data"
+		}
+		e := entry{
+			Name:           name,
+			Type:           "file",
+			CompressedSize: int64(len(data)),
+			Mode:           0644,
+			Modified:       zr.ModTime,
+		}
+		// The trailer records the size modulo 2^32, as gzip -l reports it
+		if len(data) >= 4 {
+			e.Size = int64(binary.LittleEndian.Uint32(data[len(data)-4:]))
+		}
+		return fn(e, zr)
+	}
+	return fmt.Errorf("unsupported format: %s", format)
+}
+
+func typeFromMode(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	}
+	return "other"
+}
+
+// safeEntryName validates the name of an archive entry for extraction and returns it as
+// a clean relative path. Absolute paths and names that climb out of the destination with
+// ".." are rejected, which prevents zip-slip attacks.
+func safeEntryName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(slashed, "/") || len(slashed) >= 2 && slashed[1] == ':' {
+		return "", fmt.Errorf("entry %q has an absolute path", name)
+	}
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("entry %q points outside the destination", name)
+		}
+	}
+	clean := path.Clean(slashed)
+	if clean == "." || clean == "" {
+		return "", fmt.Errorf("entry %q has an empty name", name)
+	}
+	return clean, nil
+}
+
+// archiveWriter adds files and directories to a new archive.
+type archiveWriter struct {
+	format string
+	buf    bytes.Buffer
+	zw     *zip.Writer
+	tw     *tar.Writer
+	gz     *gzip.Writer
+	files  int
+}
+
+func newArchiveWriter(format string) (*archiveWriter, error) {
+	w := &archiveWriter{format: format}
+	switch format {
+	case formatZip:
+		w.zw = zip.NewWriter(&w.buf)
+	case formatTar:
+		w.tw = tar.NewWriter(&w.buf)
+	case formatTarGz:
+		w.gz = gzip.NewWriter(&w.buf)
+		w.tw = tar.NewWriter(w.gz)
+	case formatGzip:
+		w.gz = gzip.NewWriter(&w.buf)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s; use one of %s", format, strings.Join(formats, ", "))
+	}
+	return w, nil
+}
+
+// addDir adds a directory entry.
+func (w *archiveWriter) addDir(name string, modified time.Time) error {
+	name = strings.TrimSuffix(name, "/") + "/"
+	switch {
+	case w.zw != nil:
+		h := &zip.FileHeader{Name: name, Modified: modified}
+		h.SetMode(fs.ModeDir | 0755)
+		_, err := w.zw.CreateHeader(h)
+		return err
+	case w.tw != nil:
+		return w.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755, ModTime: modified})
+	}
+	// A gzip file holds a single file and no directories
+	return nil
+}
+
+// addFile adds a regular file with the given content.
+func (w *archiveWriter) addFile(name string, mode fs.FileMode, modified time.Time, content []byte) error {
+	w.files++
+	switch {
+	case w.zw != nil:
+		h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified}
+		h.SetMode(mode.Perm())
+		fw, err := w.zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(content)
+		return err
+	case w.tw != nil:
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(mode.Perm()),
+			Size:     int64(len(content)),
+			ModTime:  modified,
+		}
+		if err := w.tw.WriteHeader(h); err != nil {
+			return err
+		}
+		_, err := w.tw.Write(content)
+		return err
+	}
+	if w.files > 1 {
+		return errors.New("a gz file holds a single file; use tar.gz or zip for several")
+	}
+	w.gz.Name = path.Base(name)
+	w.gz.ModTime = modified
+	_, err := w.gz.Write(content)
+	return err
+}
+
+// finish closes the archive and returns its bytes.
+func (w *archiveWriter) finish() ([]byte, error) {
+	if w.zw != nil {
+		if err := w.zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if w.tw != nil {
+		if err := w.tw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if w.gz != nil {
+		if w.format == formatGzip && w.files == 0 {
+			return nil, errors.New("a gz file needs exactly one file")
+		}
+		if err := w.gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf.Bytes(), nil
+}
@@ -0,0 +1,742 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/sandbox"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// mimeTypes maps archive formats to MIME types for returned archives.
+var mimeTypes = map[string]string{
+	formatZip:   "application/zip",
+	formatTar:   "application/x-tar",
+	formatTarGz: "application/gzip",
+	formatGzip:  "application/gzip",
+}
+
+// ArchiveServer is an MCP server that lists, extracts and creates zip, tar and gzip
+// archives inside a data directory.
+type ArchiveServer struct {
+	server         *server.MCPServer
+	dataDir        string
+	maxFileSize    int
+	maxExtractSize int64
+	maxEntries     int
+	maxOutputSize  int
+	files          *sandbox.Sandbox
+}
+
+// NewArchiveServer creates a new ArchiveServer instance
+func NewArchiveServer(dataDir string, maxFileSize int, maxExtractSize int64, maxEntries, maxOutputSize int) *ArchiveServer {
+	log.Printf("ArchiveServer created: dataDir=%s, maxFileSize=%d, maxExtractSize=%d, maxEntries=%d, maxOutputSize=%d",
+		dataDir, maxFileSize, maxExtractSize, maxEntries, maxOutputSize)
+
+	s := &ArchiveServer{
+		dataDir:        dataDir,
+		maxFileSize:    maxFileSize,
+		maxExtractSize: maxExtractSize,
+		maxEntries:     maxEntries,
+		maxOutputSize:  maxOutputSize,
+		files:          sandbox.New(dataDir, "data directory", sandbox.Options{MaxFileSize: int64(maxFileSize)}),
+	}
+
+	mcpServer := server.NewMCPServer(
+		"archive-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Options shared by the tools reading an archive
+	source := []mcp.ToolOption{
+		mcp.WithString("path",
+			mcp.Description("Path of the archive inside the data directory"),
+		),
+		mcp.WithString("data",
+			mcp.Description("Base64 encoded archive, used instead of path"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Archive format (default: detected from the extension or content)"),
+			mcp.Enum(formats...),
+		),
+	}
+
+	// Register listArchive tool
+	listArchiveTool := mcp.NewTool("listArchive", append([]mcp.ToolOption{
+		mcp.WithDescription("Lists the entries of a zip, tar, tar.gz or gz archive with their types, sizes and modification times"),
+	}, source...)...)
+
+	// Register extractArchive tool
+	extractArchiveTool := mcp.NewTool("extractArchive", append([]mcp.ToolOption{
+		mcp.WithDescription("Extracts entries of an archive into a directory, or returns their contents when no destination is given. " +
+			"Entries with absolute paths or .. components are rejected and symbolic links are skipped"),
+		mcp.WithArray("entries",
+			mcp.Description("Entry names, directory names or glob patterns such as docs/*.md to extract (default: all)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Directory inside the data directory to extract into. Without it, file contents are returned as text or base64"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace existing files (default: false)"),
+		),
+	}, source...)...)
+
+	// Register createArchive tool
+	createArchiveTool := mcp.NewTool("createArchive",
+		mcp.WithDescription("Creates an archive from files and directories in the data directory and from provided contents"),
+		mcp.WithString("path",
+			mcp.Description("Output file inside the data directory. Without it, the archive is returned as an embedded resource"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Archive format (default: from the extension of path, otherwise zip). gz holds a single file"),
+			mcp.Enum(formats...),
+		),
+		mcp.WithArray("sources",
+			mcp.Description("Files and directories inside the data directory to add, stored under their paths relative to the data directory"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("files",
+			mcp.Description("Files to add from provided contents: {name, content, base64}. Set base64 to true for binary content"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":    map[string]interface{}{"type": "string"},
+					"content": map[string]interface{}{"type": "string"},
+					"base64":  map[string]interface{}{"type": "boolean"},
+				},
+				"required": []string{"name", "content"},
+			}),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace an existing output file (default: false)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listArchiveTool, s.handleListArchive)
+	middleware.AddTool(mcpServer, extractArchiveTool, s.handleExtractArchive)
+	middleware.AddTool(mcpServer, createArchiveTool, s.handleCreateArchive)
+
+	s.server = mcpServer
+	return s
+}
+
+// dataPath resolves p inside the data directory, rejecting escapes and paths the
+// allow and deny rules exclude.
+func (s *ArchiveServer) dataPath(p string) (string, error) {
+	return s.files.Resolve(p)
+}
+
+// sourceOptions selects the archive a tool reads.
+type sourceOptions struct {
+	Path   string `json:"path,omitempty"`
+	Data   string `json:"data,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// load reads the archive and determines its format.
+func (s *ArchiveServer) load(opts sourceOptions) ([]byte, string, error) {
+	var data []byte
+	format := opts.Format
+	switch {
+	case opts.Path != "" && opts.Data != "":
+		return nil, "", errors.New("provide either path or data, not both")
+	case opts.Path != "":
+		if format == "" {
+			format = formatFromName(opts.Path)
+		}
+		var err error
+		if data, err = s.files.ReadFile(opts.Path); err != nil {
+			return nil, "", err
+		}
+	case opts.Data != "":
+		encoded := strings.TrimSpace(opts.Data)
+		if strings.HasPrefix(encoded, "data:") {
+			if _, after, found := strings.Cut(encoded, ","); found {
+				encoded = after
+			}
+		}
+		if err := s.files.CheckSize("data", int64(base64.StdEncoding.DecodedLen(len(encoded)))); err != nil {
+			return nil, "", err
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, "", fmt.Errorf("invalid base64 data: %w", err)
+		}
+	default:
+		return nil, "", errors.New("path or data is required")
+	}
+
+	if format == "" {
+		var err error
+		if format, err = detectFormat(data); err != nil {
+			return nil, "", err
+		}
+	}
+	return data, format, nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleListArchive handles the list request.
+func (s *ArchiveServer) handleListArchive(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listArchive request processing")
+
+	var args sourceOptions
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	data, format, err := s.load(args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var entries []entry
+	var files, dirs int
+	var total int64
+	err = walkArchive(data, format, args.Path, func(e entry, r io.Reader) error {
+		if len(entries) >= s.maxEntries {
+			return errTooManyEntries
+		}
+		entries = append(entries, e)
+		switch e.Type {
+		case "file":
+			files++
+			total += e.Size
+		case "dir":
+			dirs++
+		}
+		return nil
+	})
+	truncated := errors.Is(err, errTooManyEntries)
+	if err != nil && !truncated {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s archive: %d entries (%d files, %d directories), %d bytes uncompressed\n", format, len(entries), files, dirs, total)
+	if truncated {
+		fmt.Fprintf(&b, "Only the first %d entries are listed\n", s.maxEntries)
+	}
+	for _, e := range entries {
+		modified := ""
+		if !e.Modified.IsZero() {
+			modified = e.Modified.UTC().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(&b, "\n%-8s %12d  %-16s  %s", e.Type, e.Size, modified, e.Name)
+		if e.LinkTarget != "" {
+			b.WriteString(" -> " + e.LinkTarget)
+		}
+		if e.Encrypted {
+			b.WriteString(" (encrypted)")
+		}
+	}
+
+	log.Printf("listArchive request completed: %d entries", len(entries))
+	return textResult(b.String()), nil
+}
+
+var errTooManyEntries = errors.New("too many entries")
+
+// matchEntry reports whether an entry name is selected by one of the patterns, and marks
+// the patterns that matched.
+func matchEntry(name string, patterns []string, matched []bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	name = strings.TrimSuffix(name, "/")
+	selected := false
+	for i, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+		ok, _ := path.Match(p, name)
+		if ok || name == p || strings.HasPrefix(name, p+"/") {
+			matched[i] = true
+			selected = true
+		}
+	}
+	return selected
+}
+
+// handleExtractArchive handles the extraction request.
+func (s *ArchiveServer) handleExtractArchive(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting extractArchive request processing")
+
+	var args struct {
+		sourceOptions
+		Entries     []string `json:"entries,omitempty"`
+		Destination string   `json:"destination,omitempty"`
+		Overwrite   bool     `json:"overwrite,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	data, format, err := s.load(args.sourceOptions)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var destination string
+	if args.Destination != "" {
+		if destination, err = s.dataPath(args.Destination); err != nil {
+			return nil, err
+		}
+	}
+
+	matched := make([]bool, len(args.Entries))
+	var notes []string
+	var created []string // files written so far, removed again on failure
+	var inline strings.Builder
+	var extracted, count int
+	var total int64
+	var inlineSize int
+
+	err = walkArchive(data, format, args.Path, func(e entry, r io.Reader) error {
+		// Extraction stops, and the files written are removed, when the call is cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !matchEntry(e.Name, args.Entries, matched) {
+			return nil
+		}
+		count++
+		if count > s.maxEntries {
+			return fmt.Errorf("the selection has more than %d entries", s.maxEntries)
+		}
+		name, err := safeEntryName(e.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case e.Encrypted:
+			notes = append(notes, fmt.Sprintf("Skipped %s: encrypted entries are not supported", name))
+			return nil
+		case e.Type == "dir":
+			if destination == "" {
+				return nil
+			}
+			target, err := s.dataPath(filepath.Join(args.Destination, filepath.FromSlash(name)))
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, 0755)
+		case e.Type != "file":
+			notes = append(notes, fmt.Sprintf("Skipped %s: %s entries are not extracted", name, e.Type))
+			return nil
+		}
+
+		remaining := s.maxExtractSize - total
+		if destination == "" {
+			content, err := io.ReadAll(io.LimitReader(r, remaining+1))
+			if err != nil {
+				return fmt.Errorf("cannot read %s: %w", name, err)
+			}
+			if int64(len(content)) > remaining {
+				return fmt.Errorf("extracted data exceeds the maximum of %d bytes", s.maxExtractSize)
+			}
+			total += int64(len(content))
+			extracted++
+
+			text := string(content)
+			encoding := "text"
+			if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+				text = base64.StdEncoding.EncodeToString(content)
+				encoding = "base64"
+			}
+			inlineSize += len(text)
+			if inlineSize > s.maxOutputSize {
+				return fmt.Errorf("contents exceed the maximum output of %d bytes; select fewer entries or set destination", s.maxOutputSize)
+			}
+			fmt.Fprintf(&inline, "\n\n=== %s (%d bytes, %s) ===\n%s", name, len(content), encoding, text)
+			return nil
+		}
+
+		target, err := s.dataPath(filepath.Join(args.Destination, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		if info, err := os.Lstat(target); err == nil {
+			if !args.Overwrite {
+				return fmt.Errorf("%s already exists; set overwrite to replace it", path.Join(args.Destination, name))
+			}
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("%s exists and is not a regular file", path.Join(args.Destination, name))
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		mode := e.Mode.Perm() & 0755
+		if mode == 0 {
+			mode = 0644
+		}
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode|0600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		created = append(created, target)
+		n, err := io.Copy(f, io.LimitReader(r, remaining+1))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		if n > remaining {
+			return fmt.Errorf("extracted data exceeds the maximum of %d bytes", s.maxExtractSize)
+		}
+		if !e.Modified.IsZero() {
+			_ = os.Chtimes(target, e.Modified, e.Modified)
+		}
+		total += n
+		extracted++
+		return nil
+	})
+	if err != nil {
+		for _, p := range created {
+			os.Remove(p)
+		}
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	for i, p := range args.Entries {
+		if !matched[i] {
+			notes = append(notes, fmt.Sprintf("No entries match %s", p))
+		}
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no entries match the selection")
+	}
+
+	var b strings.Builder
+	if destination != "" {
+		fmt.Fprintf(&b, "Extracted %d files (%d bytes) to %s", extracted, total, args.Destination)
+	} else {
+		fmt.Fprintf(&b, "Read %d files (%d bytes)", extracted, total)
+	}
+	for _, note := range notes {
+		b.WriteString("\n" + note)
+	}
+	b.WriteString(inline.String())
+
+	log.Printf("extractArchive request completed: %d files, %d bytes", extracted, total)
+	return textResult(b.String()), nil
+}
+
+// providedFile is a file given by content in a create request.
+type providedFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Base64  bool   `json:"base64,omitempty"`
+}
+
+// handleCreateArchive handles the creation request.
+func (s *ArchiveServer) handleCreateArchive(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting createArchive request processing")
+
+	var args struct {
+		Path      string         `json:"path,omitempty"`
+		Format    string         `json:"format,omitempty"`
+		Sources   []string       `json:"sources,omitempty"`
+		Files     []providedFile `json:"files,omitempty"`
+		Overwrite bool           `json:"overwrite,omitempty"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Sources) == 0 && len(args.Files) == 0 {
+		return nil, toolerr.New(toolerr.InvalidParams, "sources or files are required")
+	}
+	format := args.Format
+	if format == "" && args.Path != "" {
+		format = formatFromName(args.Path)
+	}
+	if format == "" {
+		format = formatZip
+	}
+
+	var output string
+	if args.Path != "" {
+		if output, err = s.dataPath(args.Path); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(output); err == nil && !args.Overwrite {
+			return nil, fmt.Errorf("%s already exists; set overwrite to replace it", args.Path)
+		}
+	}
+
+	w, err := newArchiveWriter(format)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	var total int64
+	var notes []string
+	add := func(name string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate entry %s", name)
+		}
+		names[name] = true
+		if len(names) > s.maxEntries {
+			return fmt.Errorf("the archive would have more than %d entries", s.maxEntries)
+		}
+		return nil
+	}
+	addContent := func(name string, mode fs.FileMode, modified time.Time, content []byte) error {
+		if err := add(name); err != nil {
+			return err
+		}
+		total += int64(len(content))
+		if total > s.maxExtractSize {
+			return fmt.Errorf("the archive contents exceed the maximum of %d bytes", s.maxExtractSize)
+		}
+		return w.addFile(name, mode, modified, content)
+	}
+
+	excluded := 0
+	for _, source := range args.Sources {
+		root, err := s.dataPath(source)
+		if err != nil {
+			return nil, err
+		}
+		base, err := s.dataPath("")
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return fmt.Errorf("cannot read %s: %w", source, errors.Unwrap(err))
+			}
+			rel, err := filepath.Rel(base, p)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(rel)
+			if p == output {
+				// Never add the archive to itself
+				return nil
+			}
+			if !s.files.Allowed(p) {
+				excluded++
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			switch {
+			case d.IsDir():
+				if p == base {
+					return nil
+				}
+				if err := add(name + "/"); err != nil {
+					return err
+				}
+				return w.addDir(name, info.ModTime())
+			case !d.Type().IsRegular():
+				notes = append(notes, fmt.Sprintf("Skipped %s: not a regular file", name))
+				return nil
+			}
+			if total+info.Size() > s.maxExtractSize {
+				return fmt.Errorf("the archive contents exceed the maximum of %d bytes", s.maxExtractSize)
+			}
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			return addContent(name, info.Mode(), info.ModTime(), content)
+		})
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+	}
+
+	if excluded > 0 {
+		notes = append(notes, fmt.Sprintf("Skipped %d files and directories excluded by the allow and deny rules", excluded))
+	}
+
+	now := time.Now()
+	for _, f := range args.Files {
+		name, err := safeEntryName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		content := []byte(f.Content)
+		if f.Base64 {
+			if content, err = base64.StdEncoding.DecodeString(strings.TrimSpace(f.Content)); err != nil {
+				return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid base64 content for %s: %w", name, err)
+			}
+		}
+		if err := addContent(name, 0644, now, content); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := w.finish()
+	if err != nil {
+		log.Printf("Error: Failed to create archive: %v", err)
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := s.files.CheckSize("archive", int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Created %s archive with %d files (%d bytes uncompressed, %d bytes compressed)",
+		format, w.files, total, len(data))
+	for _, note := range notes {
+		summary += "\n" + note
+	}
+
+	if output == "" {
+		log.Printf("createArchive request completed: %d files, returned %d bytes", w.files, len(data))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+					URI:      "archive:///archive." + format,
+					MIMEType: mimeTypes[format],
+					Blob:     base64.StdEncoding.EncodeToString(data),
+				}),
+				mcp.TextContent{
+					Type: "text",
+					Text: summary,
+				},
+			},
+		}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		log.Printf("Error: Failed to write %s: %v", args.Path, err)
+		return nil, fmt.Errorf("failed to write %s: %w", args.Path, err)
+	}
+
+	log.Printf("createArchive request completed: %d files written to %s", w.files, args.Path)
+	return textResult(summary + " at " + args.Path), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *ArchiveServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the archive server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("archive", flag.ContinueOnError)
+	var (
+		dataDir        string
+		maxFileSize    int
+		maxExtractSize int64
+		maxEntries     int
+		maxOutputSize  int
+	)
+	fs.StringVar(&dataDir, "data-dir", ".", "Directory that file paths are restricted to")
+	fs.IntVar(&maxFileSize, "max-file-size", 50*1024*1024, "Maximum size of archives read or created in bytes (default 50MB)")
+	fs.Int64Var(&maxExtractSize, "max-extract-size", 200*1024*1024, "Maximum total uncompressed size extracted or archived per call in bytes (default 200MB)")
+	fs.IntVar(&maxEntries, "max-entries", 10000, "Maximum number of entries listed, extracted or archived per call")
+	fs.IntVar(&maxOutputSize, "max-output-size", 100000, "Maximum size of file contents returned without a destination in bytes")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var sandboxFlags sandbox.Flags
+	sandboxFlags.Register(fs, "data directory")
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting archive server: data-dir=%s, max-file-size=%d, max-extract-size=%d", dataDir, maxFileSize, maxExtractSize)
+
+	// Create ArchiveServer instance
+	archiveServer := NewArchiveServer(dataDir, maxFileSize, maxExtractSize, maxEntries, maxOutputSize)
+	sandboxFlags.Apply(archiveServer.files)
+	log.Println("ArchiveServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), archiveServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return archiveServer.Server(), transportFlags, nil
+}
+
+// Run starts the archive server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[ArchiveServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create archive server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("ArchiveServer shutdown")
+	return nil
+}
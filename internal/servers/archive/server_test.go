@@ -0,0 +1,485 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ArchiveServer creation test
+func TestNewArchiveServer(t *testing.T) {
+	s := NewArchiveServer("/tmp", 1024, 2048, 10, 512)
+
+	assert.NotNil(t, s, "ArchiveServer instance should be created")
+	assert.Equal(t, "/tmp", s.dataDir, "Data directory should match")
+	assert.Equal(t, 1024, s.maxFileSize, "Max file size should match")
+	assert.Equal(t, int64(2048), s.maxExtractSize, "Max extract size should match")
+	assert.Equal(t, 10, s.maxEntries, "Max entries should match")
+	assert.Equal(t, 512, s.maxOutputSize, "Max output size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewArchiveServer("/tmp", 1024, 2048, 10, 512)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// testFile is a file written into test archives.
+type testFile struct {
+	name    string
+	content string
+	dir     bool
+	link    string
+}
+
+func buildZip(t *testing.T, files []testFile) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		h := &zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)}
+		switch {
+		case f.dir:
+			h.SetMode(os.ModeDir | 0755)
+		case f.link != "":
+			h.SetMode(os.ModeSymlink | 0777)
+		default:
+			h.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(h)
+		require.NoError(t, err)
+		if f.link != "" {
+			_, err = w.Write([]byte(f.link))
+		} else {
+			_, err = w.Write([]byte(f.content))
+		}
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func buildTar(t *testing.T, files []testFile, compress bool) []byte {
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	}
+	for _, f := range files {
+		h := &tar.Header{Name: f.name, Mode: 0644, ModTime: time.Now()}
+		switch {
+		case f.dir:
+			h.Typeflag, h.Mode = tar.TypeDir, 0755
+		case f.link != "":
+			h.Typeflag, h.Linkname = tar.TypeSymlink, f.link
+		default:
+			h.Typeflag, h.Size = tar.TypeReg, int64(len(f.content))
+		}
+		require.NoError(t, tw.WriteHeader(h))
+		if h.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(f.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+var sampleFiles = []testFile{
+	{name: "docs/", dir: true},
+	{name: "docs/readme.md", content: "# Readme\n"},
+	{name: "docs/guide.md", content: "Guide\n"},
+	{name: "src/main.go", content: "package main\n"},
+	{name: "link", link: "docs/readme.md"},
+}
+
+// Test format detection from names and content
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, formatTarGz, formatFromName("a.tar.gz"))
+	assert.Equal(t, formatTarGz, formatFromName("A.TGZ"))
+	assert.Equal(t, formatTar, formatFromName("a.tar"))
+	assert.Equal(t, formatGzip, formatFromName("a.txt.gz"))
+	assert.Equal(t, formatZip, formatFromName("a.jar"))
+	assert.Equal(t, "", formatFromName("a.txt"))
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("plain text"))
+	zw.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"zip", buildZip(t, sampleFiles), formatZip},
+		{"tar", buildTar(t, sampleFiles, false), formatTar},
+		{"tar.gz", buildTar(t, sampleFiles, true), formatTarGz},
+		{"gz", gz.Bytes(), formatGzip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := detectFormat(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, format)
+		})
+	}
+
+	_, err := detectFormat([]byte("not an archive"))
+	assert.Error(t, err)
+}
+
+// Test entry name validation against zip-slip
+func TestSafeEntryName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"docs/readme.md", "docs/readme.md", false},
+		{"./docs//readme.md", "docs/readme.md", false},
+		{`docs\readme.md`, "docs/readme.md", false},
+		{"docs/", "docs", false},
+		{"../evil.txt", "", true},
+		{"docs/../../evil.txt", "", true},
+		{`..\evil.txt`, "", true},
+		{"/etc/passwd", "", true},
+		{`C:\Windows\evil.dll`, "", true},
+		{".", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := safeEntryName(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test listing archives of each format
+func TestHandleListArchive(t *testing.T) {
+	dir := t.TempDir()
+	s := NewArchiveServer(dir, 1<<20, 1<<20, 100, 10000)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample.zip"), buildZip(t, sampleFiles), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample.tgz"), buildTar(t, sampleFiles, true), 0644))
+
+	for _, name := range []string{"sample.zip", "sample.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			result, err := s.handleListArchive(context.Background(), mcptest.NewCallToolRequest("listArchive", map[string]interface{}{
+				"path": name,
+			}))
+			require.NoError(t, err)
+			text := mcptest.ResultText(result)
+			assert.Contains(t, text, "5 entries (3 files, 1 directories), 28 bytes uncompressed")
+			assert.Contains(t, text, "docs/readme.md")
+			assert.Contains(t, text, "link -> docs/readme.md")
+		})
+	}
+
+	t.Run("base64 data", func(t *testing.T) {
+		result, err := s.handleListArchive(context.Background(), mcptest.NewCallToolRequest("listArchive", map[string]interface{}{
+			"data": base64.StdEncoding.EncodeToString(buildTar(t, sampleFiles, false)),
+		}))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(mcptest.ResultText(result), "tar archive: 5 entries"))
+	})
+
+	t.Run("entry limit", func(t *testing.T) {
+		small := NewArchiveServer(dir, 1<<20, 1<<20, 2, 10000)
+		result, err := small.handleListArchive(context.Background(), mcptest.NewCallToolRequest("listArchive", map[string]interface{}{
+			"path": "sample.zip",
+		}))
+		require.NoError(t, err)
+		assert.Contains(t, mcptest.ResultText(result), "Only the first 2 entries are listed")
+	})
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.zip"), buildZip(t, sampleFiles), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+
+	errorTests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing source", map[string]interface{}{}},
+		{"path and data", map[string]interface{}{"path": "sample.zip", "data": "AAAA"}},
+		{"symlink escape", map[string]interface{}{"path": "escape/secret.zip"}},
+		{"invalid base64", map[string]interface{}{"data": "!!!"}},
+		{"not an archive", map[string]interface{}{"data": base64.StdEncoding.EncodeToString([]byte("hello"))}},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleListArchive(context.Background(), mcptest.NewCallToolRequest("listArchive", tt.args))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// Test extracting entries to a directory and inline
+func TestHandleExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	s := NewArchiveServer(dir, 1<<20, 1<<20, 100, 10000)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample.zip"), buildZip(t, sampleFiles), 0644))
+
+	t.Run("to destination", func(t *testing.T) {
+		result, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path":        "sample.zip",
+			"destination": "out",
+		}))
+		require.NoError(t, err)
+		text := mcptest.ResultText(result)
+		assert.Contains(t, text, "Extracted 3 files (28 bytes) to out")
+		assert.Contains(t, text, "Skipped link: symlink entries are not extracted")
+
+		content, err := os.ReadFile(filepath.Join(dir, "out", "docs", "readme.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Readme\n", string(content))
+		_, err = os.Lstat(filepath.Join(dir, "out", "link"))
+		assert.True(t, os.IsNotExist(err), "Symlinks should not be extracted")
+	})
+
+	t.Run("existing files", func(t *testing.T) {
+		args := map[string]interface{}{
+			"path":        "sample.zip",
+			"destination": "out",
+			"entries":     []interface{}{"src/main.go"},
+		}
+		_, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", args))
+		assert.ErrorContains(t, err, "already exists")
+
+		args["overwrite"] = true
+		_, err = s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", args))
+		assert.NoError(t, err)
+	})
+
+	t.Run("inline with patterns", func(t *testing.T) {
+		result, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path":    "sample.zip",
+			"entries": []interface{}{"docs/*.md", "missing.txt"},
+		}))
+		require.NoError(t, err)
+		text := mcptest.ResultText(result)
+		assert.Contains(t, text, "Read 2 files (15 bytes)")
+		assert.Contains(t, text, "No entries match missing.txt")
+		assert.Contains(t, text, "=== docs/readme.md (9 bytes, text) ===\n# Readme\n")
+		assert.NotContains(t, text, "package main")
+	})
+
+	t.Run("directory selection", func(t *testing.T) {
+		result, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path":    "sample.zip",
+			"entries": []interface{}{"docs/"},
+		}))
+		require.NoError(t, err)
+		assert.Contains(t, mcptest.ResultText(result), "Read 2 files")
+	})
+
+	t.Run("binary content", func(t *testing.T) {
+		data := buildZip(t, []testFile{{name: "bin", content: "\x00\x01\x02"}})
+		result, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"data": base64.StdEncoding.EncodeToString(data),
+		}))
+		require.NoError(t, err)
+		assert.Contains(t, mcptest.ResultText(result), "=== bin (3 bytes, base64) ===\nAAEC")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path":    "sample.zip",
+			"entries": []interface{}{"nothing"},
+		}))
+		assert.ErrorContains(t, err, "no entries match")
+	})
+}
+
+// Test that malicious archives are rejected without leaving files behind
+func TestExtractArchiveSafety(t *testing.T) {
+	dir := t.TempDir()
+	s := NewArchiveServer(dir, 1<<20, 1000, 5, 10000)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"zip-slip", buildZip(t, []testFile{{name: "ok.txt", content: "ok"}, {name: "../evil.txt", content: "evil"}}), "outside the destination"},
+		{"absolute path", buildTar(t, []testFile{{name: "ok.txt", content: "ok"}, {name: "/tmp/evil.txt", content: "evil"}}, false), "absolute path"},
+		{"windows path", buildZip(t, []testFile{{name: "ok.txt", content: "ok"}, {name: `C:\evil.txt`, content: "evil"}}), "absolute path"},
+		{"size limit", buildZip(t, []testFile{{name: "ok.txt", content: "ok"}, {name: "bomb", content: strings.Repeat("0", 5000)}}), "exceeds the maximum of 1000 bytes"},
+		{"entry limit", buildZip(t, []testFile{{name: "ok.txt"}, {name: "a"}, {name: "b"}, {name: "c"}, {name: "d"}, {name: "e"}}), "more than 5 entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+				"data":        base64.StdEncoding.EncodeToString(tt.data),
+				"destination": "out",
+			}))
+			assert.ErrorContains(t, err, tt.wantErr)
+			_, err = os.Stat(filepath.Join(dir, "out", "ok.txt"))
+			assert.True(t, os.IsNotExist(err), "Files extracted before the failure should be removed")
+		})
+	}
+
+	t.Run("symlinked destination", func(t *testing.T) {
+		outside := t.TempDir()
+		require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+		_, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"data":        base64.StdEncoding.EncodeToString(buildZip(t, []testFile{{name: "a.txt", content: "a"}})),
+			"destination": "escape",
+		}))
+		assert.ErrorContains(t, err, "escapes the data directory")
+		_, err = os.Stat(filepath.Join(outside, "a.txt"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+// Test creating archives and reading them back
+func TestHandleCreateArchive(t *testing.T) {
+	dir := t.TempDir()
+	s := NewArchiveServer(dir, 1<<20, 1<<20, 100, 10000)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "project", "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project", "a.txt"), []byte("alpha\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project", "sub", "b.txt"), []byte("beta\n"), 0644))
+	require.NoError(t, os.Symlink(t.TempDir(), filepath.Join(dir, "escape")))
+
+	for _, name := range []string{"out.zip", "out.tar", "out.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			result, err := s.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+				"path":    name,
+				"sources": []interface{}{"project"},
+				"files": []interface{}{
+					map[string]interface{}{"name": "extra/c.bin", "content": base64.StdEncoding.EncodeToString([]byte{0, 1}), "base64": true},
+				},
+			}))
+			require.NoError(t, err)
+			assert.Contains(t, mcptest.ResultText(result), "archive with 3 files (13 bytes uncompressed")
+
+			result, err = s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+				"path":    name,
+				"entries": []interface{}{"project/sub/b.txt"},
+			}))
+			require.NoError(t, err)
+			assert.Contains(t, mcptest.ResultText(result), "=== project/sub/b.txt (5 bytes, text) ===\nbeta\n")
+		})
+	}
+
+	t.Run("embedded resource", func(t *testing.T) {
+		result, err := s.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+			"files": []interface{}{map[string]interface{}{"name": "hello.txt", "content": "hello"}},
+		}))
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		resource := result.Content[0].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+		assert.Equal(t, "application/zip", resource.MIMEType)
+		data, err := base64.StdEncoding.DecodeString(resource.Blob)
+		require.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		require.Len(t, zr.File, 1)
+		assert.Equal(t, "hello.txt", zr.File[0].Name)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.handleCreateArchive(ctx, mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+			"path":    "cancelled.zip",
+			"sources": []interface{}{"project"},
+		}))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, filepath.Join(dir, "cancelled.zip"))
+
+		_, err = s.handleExtractArchive(ctx, mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path":        "out.zip",
+			"destination": "cancelled",
+		}))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NoFileExists(t, filepath.Join(dir, "cancelled", "project", "a.txt"))
+	})
+
+	t.Run("path rules", func(t *testing.T) {
+		restricted := NewArchiveServer(dir, 1<<20, 1<<20, 100, 10000)
+		restricted.files.SetRules(nil, []string{"sub", "*.zip"})
+		result, err := restricted.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+			"sources": []interface{}{"project"},
+		}))
+		require.NoError(t, err)
+		text := mcptest.ResultText(result)
+		assert.Contains(t, text, "archive with 1 files (6 bytes uncompressed")
+		assert.Contains(t, text, "Skipped 1 files and directories excluded by the allow and deny rules")
+
+		_, err = restricted.handleListArchive(context.Background(), mcptest.NewCallToolRequest("listArchive", map[string]interface{}{
+			"path": "out.zip",
+		}))
+		assert.ErrorContains(t, err, "out.zip is not allowed in the data directory")
+	})
+
+	t.Run("gz single file", func(t *testing.T) {
+		_, err := s.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+			"path":    "notes.txt.gz",
+			"sources": []interface{}{"project/a.txt"},
+		}))
+		require.NoError(t, err)
+		result, err := s.handleExtractArchive(context.Background(), mcptest.NewCallToolRequest("extractArchive", map[string]interface{}{
+			"path": "notes.txt.gz",
+		}))
+		require.NoError(t, err)
+		assert.Contains(t, mcptest.ResultText(result), "=== a.txt (6 bytes, text) ===\nalpha\n")
+
+		_, err = s.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", map[string]interface{}{
+			"path":    "all.gz",
+			"sources": []interface{}{"project"},
+		}))
+		assert.ErrorContains(t, err, "single file")
+	})
+
+	errorTests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"no inputs", map[string]interface{}{"path": "x.zip"}, "sources or files are required"},
+		{"existing output", map[string]interface{}{"path": "out.zip", "sources": []interface{}{"project"}}, "already exists"},
+		{"source outside", map[string]interface{}{"sources": []interface{}{"escape"}}, "escapes the data directory"},
+		{"unsafe name", map[string]interface{}{"files": []interface{}{map[string]interface{}{"name": "../x", "content": "x"}}}, "outside the destination"},
+		{"duplicate name", map[string]interface{}{"files": []interface{}{
+			map[string]interface{}{"name": "x", "content": "1"},
+			map[string]interface{}{"name": "./x", "content": "2"},
+		}}, "duplicate entry"},
+		{"unknown format", map[string]interface{}{"format": "rar", "files": []interface{}{map[string]interface{}{"name": "x", "content": "x"}}}, "unsupported format"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleCreateArchive(context.Background(), mcptest.NewCallToolRequest("createArchive", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewArchiveServer(f.TempDir(), 1024, 2048, 10, 512).Server())
+}
@@ -0,0 +1,145 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// clipboard is implemented by the platform specific backends.
+type clipboard interface {
+	Read(ctx context.Context) (string, error)
+	Write(ctx context.Context, text string) error
+	Name() string
+}
+
+// commandBackend reads and writes the clipboard through helper programs that print the
+// clipboard on stdout and replace it with stdin.
+type commandBackend struct {
+	name  string
+	read  []string
+	write []string
+	// trimNewline removes the line break some readers append to the content
+	trimNewline bool
+}
+
+// Backends known to the server, by name
+var backends = map[string]commandBackend{
+	"pbcopy": {
+		name:  "pbcopy",
+		read:  []string{"pbpaste"},
+		write: []string{"pbcopy"},
+	},
+	"wl-clipboard": {
+		name:  "wl-clipboard",
+		read:  []string{"wl-paste", "--no-newline"},
+		write: []string{"wl-copy"},
+	},
+	"xclip": {
+		name:  "xclip",
+		read:  []string{"xclip", "-selection", "clipboard", "-out"},
+		write: []string{"xclip", "-selection", "clipboard", "-in"},
+	},
+	"xsel": {
+		name:  "xsel",
+		read:  []string{"xsel", "--clipboard", "--output"},
+		write: []string{"xsel", "--clipboard", "--input"},
+	},
+	"powershell": {
+		name: "powershell",
+		read: []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+			"[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw"},
+		write: []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+			"[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"},
+		trimNewline: true,
+	},
+}
+
+// detectBackend picks the clipboard backend for the current platform, or the named one.
+func detectBackend(name string) (clipboard, error) {
+	if name != "" && name != "auto" {
+		b, ok := backends[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown backend %q; use auto, pbcopy, wl-clipboard, xclip, xsel or powershell", name)
+		}
+		if _, err := exec.LookPath(b.read[0]); err != nil {
+			return nil, fmt.Errorf("%s backend is not available: %w", name, err)
+		}
+		return b, nil
+	}
+
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"pbcopy"}
+	case "windows":
+		candidates = []string{"powershell"}
+	default:
+		// Wayland sessions often run XWayland too, but only wl-clipboard sees native clients
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, "wl-clipboard")
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates, "xclip", "xsel")
+		}
+		// WSL can reach the Windows clipboard
+		candidates = append(candidates, "powershell")
+	}
+	for _, c := range candidates {
+		b := backends[c]
+		if _, err := exec.LookPath(b.read[0]); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("no clipboard backend found for %s; install %s", runtime.GOOS, strings.Join(candidates, " or "))
+}
+
+// Name returns the name of the backend.
+func (b commandBackend) Name() string {
+	return b.name
+}
+
+// Read returns the text on the clipboard.
+func (b commandBackend) Read(ctx context.Context) (string, error) {
+	out, err := b.run(ctx, b.read, "")
+	if err != nil {
+		return "", err
+	}
+	if b.trimNewline {
+		out = strings.TrimSuffix(strings.TrimSuffix(out, "\n"), "\r")
+	}
+	return out, nil
+}
+
+// Write replaces the clipboard with text.
+func (b commandBackend) Write(ctx context.Context, text string) error {
+	_, err := b.run(ctx, b.write, text)
+	return err
+}
+
+// run executes a helper program with input on stdin and returns stdout.
+func (b commandBackend) run(ctx context.Context, args []string, input string) (string, error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// xclip and wl-copy fork a process that keeps serving the selection with our pipes open
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil && !(errors.Is(err, exec.ErrWaitDelay) && cmd.ProcessState.Success()) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,238 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// ClipboardServer is an MCP server that reads and writes the system clipboard.
+type ClipboardServer struct {
+	server     *server.MCPServer
+	clipboard  clipboard
+	allowRead  bool
+	allowWrite bool
+	maxSize    int
+	timeout    time.Duration
+}
+
+// NewClipboardServer creates a new ClipboardServer instance. Only the tools for the
+// allowed kinds of access are registered.
+func NewClipboardServer(cb clipboard, allowRead, allowWrite bool, maxSize, timeout int) *ClipboardServer {
+	log.Printf("ClipboardServer created: backend=%s, allowRead=%t, allowWrite=%t, maxSize=%d, timeout=%ds",
+		cb.Name(), allowRead, allowWrite, maxSize, timeout)
+
+	s := &ClipboardServer{
+		clipboard:  cb,
+		allowRead:  allowRead,
+		allowWrite: allowWrite,
+		maxSize:    maxSize,
+		timeout:    time.Duration(timeout) * time.Second,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"clipboard-server", // server name
+		"1.0.0",            // version
+	)
+
+	// Register readClipboard tool
+	if allowRead {
+		readTool := mcp.NewTool("readClipboard",
+			mcp.WithDescription("Returns the text currently on the system clipboard"),
+		)
+		middleware.AddTool(mcpServer, readTool, s.handleReadClipboard, middleware.Timeout(s.timeout))
+	}
+
+	// Register writeClipboard tool
+	if allowWrite {
+		writeTool := mcp.NewTool("writeClipboard",
+			mcp.WithDescription("Replaces the contents of the system clipboard with text"),
+			mcp.WithString("text",
+				mcp.Description("Text to copy to the clipboard"),
+				mcp.Required(),
+			),
+		)
+		middleware.AddTool(mcpServer, writeTool, s.handleWriteClipboard, middleware.Timeout(s.timeout))
+	}
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleReadClipboard handles the read request. Clipboard contents are never logged.
+func (s *ClipboardServer) handleReadClipboard(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting readClipboard request processing")
+
+	if !s.allowRead {
+		return nil, fmt.Errorf("reading the clipboard is disabled; start the server with -allow-read")
+	}
+
+	text, err := s.clipboard.Read(ctx)
+	if err != nil {
+		log.Printf("Error: Failed to read the clipboard: %v", err)
+		return nil, fmt.Errorf("failed to read the clipboard: %w", err)
+	}
+	if text == "" {
+		log.Println("readClipboard request completed: clipboard is empty")
+		return textResult("The clipboard is empty or holds no text"), nil
+	}
+	if !utf8.ValidString(text) {
+		return nil, fmt.Errorf("the clipboard holds %d bytes that are not valid UTF-8 text", len(text))
+	}
+
+	size := len(text)
+	if size > s.maxSize {
+		// Cut at a character boundary
+		cut := s.maxSize
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + fmt.Sprintf("\n\n[Clipboard truncated: showing %d of %d bytes]", cut, size)
+	}
+
+	log.Printf("readClipboard request completed: %d bytes", size)
+	return textResult(text), nil
+}
+
+// handleWriteClipboard handles the write request.
+func (s *ClipboardServer) handleWriteClipboard(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting writeClipboard request processing")
+
+	if !s.allowWrite {
+		return nil, fmt.Errorf("writing the clipboard is disabled; start the server with -allow-write")
+	}
+
+	var args struct {
+		Text *string `json:"text"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Text == nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "text is required")
+	}
+	text := *args.Text
+	if len(text) > s.maxSize {
+		return nil, fmt.Errorf("text of %d bytes exceeds the maximum size of %d bytes", len(text), s.maxSize)
+	}
+
+	if err := s.clipboard.Write(ctx, text); err != nil {
+		log.Printf("Error: Failed to write the clipboard: %v", err)
+		return nil, fmt.Errorf("failed to write the clipboard: %w", err)
+	}
+
+	log.Printf("writeClipboard request completed: %d bytes", len(text))
+	return textResult(fmt.Sprintf("Copied %d characters to the clipboard", utf8.RuneCountInString(text))), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *ClipboardServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the clipboard server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("clipboard", flag.ContinueOnError)
+	var (
+		allowRead   bool
+		allowWrite  bool
+		backendName string
+		maxSize     int
+		timeout     int
+	)
+	fs.BoolVar(&allowRead, "allow-read", false, "Allow reading the clipboard, which may hold passwords and other sensitive data")
+	fs.BoolVar(&allowWrite, "allow-write", false, "Allow replacing the contents of the clipboard")
+	fs.StringVar(&backendName, "backend", "auto", "Clipboard backend: auto, pbcopy, wl-clipboard, xclip, xsel or powershell")
+	fs.IntVar(&maxSize, "max-size", 1024*1024, "Maximum text size read or written in bytes (default 1MB)")
+	fs.IntVar(&timeout, "timeout", 5, "Timeout for clipboard commands in seconds")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting clipboard server: allow-read=%t, allow-write=%t, backend=%s", allowRead, allowWrite, backendName)
+
+	// Clipboard access is opt-in
+	if !allowRead && !allowWrite {
+		log.Println("Error: Clipboard access is disabled; start the server with -allow-read and/or -allow-write")
+		return nil, transport.Flags{}, errors.New("clipboard access is disabled")
+	}
+
+	cb, err := detectBackend(backendName)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create ClipboardServer instance
+	clipboardServer := NewClipboardServer(cb, allowRead, allowWrite, maxSize, timeout)
+	log.Println("ClipboardServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), clipboardServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return clipboardServer.Server(), transportFlags, nil
+}
+
+// Run starts the clipboard server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[ClipboardServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create clipboard server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("ClipboardServer shutdown")
+	return nil
+}
@@ -0,0 +1,159 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClipboard keeps the clipboard in memory.
+type fakeClipboard struct {
+	text string
+	err  error
+}
+
+func (f *fakeClipboard) Read(ctx context.Context) (string, error) {
+	return f.text, f.err
+}
+
+func (f *fakeClipboard) Write(ctx context.Context, text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.text = text
+	return nil
+}
+
+func (f *fakeClipboard) Name() string {
+	return "fake"
+}
+
+// ClipboardServer creation test
+func TestNewClipboardServer(t *testing.T) {
+	cb := &fakeClipboard{}
+	s := NewClipboardServer(cb, true, false, 1024, 5)
+
+	assert.NotNil(t, s, "ClipboardServer instance should be created")
+	assert.Equal(t, cb, s.clipboard, "Clipboard backend should match")
+	assert.True(t, s.allowRead, "Read access should match")
+	assert.False(t, s.allowWrite, "Write access should match")
+	assert.Equal(t, 1024, s.maxSize, "Max size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewClipboardServer(&fakeClipboard{}, true, true, 1024, 5)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test readClipboard handler
+func TestHandleReadClipboard(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		err      error
+		expected string
+		wantErr  string
+	}{
+		{"text", "hello", nil, "hello", ""},
+		{"empty", "", nil, "The clipboard is empty or holds no text", ""},
+		{"truncated", strings.Repeat("a", 8) + "é" + "tail", nil, strings.Repeat("a", 8) + "\n\n[Clipboard truncated: showing 8 of 14 bytes]", ""},
+		{"binary", "\xff\xfe", nil, "", "not valid UTF-8"},
+		{"backend error", "", errors.New("xclip: Error: target STRING not available"), "", "failed to read the clipboard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewClipboardServer(&fakeClipboard{text: tt.text, err: tt.err}, true, false, 9, 5)
+			result, err := s.handleReadClipboard(context.Background(), mcptest.NewCallToolRequest("readClipboard", nil))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mcptest.ResultText(result))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewClipboardServer(&fakeClipboard{text: "secret"}, false, true, 1024, 5)
+		_, err := s.handleReadClipboard(context.Background(), mcptest.NewCallToolRequest("readClipboard", nil))
+		assert.ErrorContains(t, err, "-allow-read")
+	})
+}
+
+// Test writeClipboard handler
+func TestHandleWriteClipboard(t *testing.T) {
+	cb := &fakeClipboard{}
+	s := NewClipboardServer(cb, false, true, 10, 5)
+
+	result, err := s.handleWriteClipboard(context.Background(), mcptest.NewCallToolRequest("writeClipboard", map[string]interface{}{
+		"text": "héllo",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Copied 5 characters to the clipboard", mcptest.ResultText(result))
+	assert.Equal(t, "héllo", cb.text)
+
+	// Clearing the clipboard is allowed
+	_, err = s.handleWriteClipboard(context.Background(), mcptest.NewCallToolRequest("writeClipboard", map[string]interface{}{
+		"text": "",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "", cb.text)
+
+	errorTests := []struct {
+		name    string
+		server  *ClipboardServer
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing text", s, map[string]interface{}{}, "text is required"},
+		{"too large", s, map[string]interface{}{"text": "01234567890"}, "exceeds the maximum size of 10 bytes"},
+		{"disabled", NewClipboardServer(cb, true, false, 10, 5), map[string]interface{}{"text": "x"}, "-allow-write"},
+		{"backend error", NewClipboardServer(&fakeClipboard{err: errors.New("no display")}, true, true, 10, 5), map[string]interface{}{"text": "x"}, "no display"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.server.handleWriteClipboard(context.Background(), mcptest.NewCallToolRequest("writeClipboard", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Test the command backend with shell commands standing in for the clipboard tools
+func TestCommandBackend(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("requires /bin/sh")
+	}
+	file := filepath.Join(t.TempDir(), "clipboard")
+	b := commandBackend{
+		name:        "test",
+		read:        []string{"/bin/sh", "-c", `cat "$0"; echo`, file},
+		write:       []string{"/bin/sh", "-c", `cat > "$0"`, file},
+		trimNewline: true,
+	}
+
+	require.NoError(t, b.Write(context.Background(), "line 1\nline 2"))
+	text, err := b.Read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "line 1\nline 2", text)
+
+	failing := commandBackend{name: "test", read: []string{"/bin/sh", "-c", "echo 'no selection' >&2; exit 1"}}
+	_, err = failing.Read(context.Background())
+	assert.EqualError(t, err, "/bin/sh: no selection")
+
+	_, err = detectBackend("clipboard.exe")
+	assert.ErrorContains(t, err, "unknown backend")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewClipboardServer(&fakeClipboard{}, true, true, 1024, 5).Server())
+}
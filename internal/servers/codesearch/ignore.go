@@ -0,0 +1,200 @@
+package codesearch
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is one line of a .gitignore file.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreFile holds the patterns of one .gitignore, which apply to paths below its
+// directory.
+type ignoreFile struct {
+	dir      string // slash separated, relative to the root; "" for the root itself
+	patterns []ignorePattern
+}
+
+// loadIgnoreFile reads a .gitignore file. A missing file yields nil.
+func loadIgnoreFile(file, dir string) *ignoreFile {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	ig := &ignoreFile{dir: dir}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parseIgnoreLine(scanner.Text()); ok {
+			ig.patterns = append(ig.patterns, p)
+		}
+	}
+	if len(ig.patterns) == 0 {
+		return nil
+	}
+	return ig
+}
+
+// parseIgnoreLine compiles a .gitignore line, following the rules of gitignore(5).
+func parseIgnoreLine(line string) (ignorePattern, bool) {
+	var p ignorePattern
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return p, false
+	}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false
+	}
+	// A pattern without a slash matches at any depth, otherwise it is anchored to the
+	// directory of the .gitignore
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	p.re = globRegexp(strings.TrimPrefix(line, "/"))
+	return p, true
+}
+
+// ignored reports whether the slash separated path, relative to the root, is excluded
+// by the stack of .gitignore files. The last matching pattern wins, so deeper files and
+// later lines override earlier ones.
+func ignored(stack []*ignoreFile, rel string, isDir bool) bool {
+	result := false
+	for _, ig := range stack {
+		p := rel
+		if ig.dir != "" {
+			if !strings.HasPrefix(rel, ig.dir+"/") {
+				continue
+			}
+			p = rel[len(ig.dir)+1:]
+		}
+		for _, pat := range ig.patterns {
+			if pat.dirOnly && !isDir {
+				continue
+			}
+			if pat.re.MatchString(p) {
+				result = !pat.negate
+			}
+		}
+	}
+	return result
+}
+
+// globRegexp compiles a glob in which * and ? do not cross slashes, ** matches any
+// number of directories and [...] is a character class.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		// Malformed classes match literally
+		return regexp.MustCompile("^" + regexp.QuoteMeta(glob) + "$")
+	}
+	return re
+}
+
+// globFilter selects files by glob patterns. Patterns starting with ! exclude files.
+// A pattern without a slash is matched against the file name, otherwise against the
+// path relative to the root.
+type globFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func newGlobFilter(globs []string) *globFilter {
+	f := &globFilter{}
+	for _, g := range globs {
+		negate := strings.HasPrefix(g, "!")
+		g = strings.TrimPrefix(strings.TrimPrefix(g, "!"), "./")
+		if g == "" {
+			continue
+		}
+		if !strings.Contains(g, "/") {
+			g = "**/" + g
+		}
+		re := globRegexp(strings.TrimPrefix(g, "/"))
+		if negate {
+			f.exclude = append(f.exclude, re)
+		} else {
+			f.include = append(f.include, re)
+		}
+	}
+	return f
+}
+
+// excludesDir reports whether a whole directory is excluded, so it need not be walked.
+func (f *globFilter) excludesDir(rel string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a file is selected.
+func (f *globFilter) matches(rel string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(rel) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
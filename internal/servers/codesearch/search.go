@@ -0,0 +1,265 @@
+package codesearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxLineLength bounds the length of a printed line, as minified files can have very
+// long ones.
+const maxLineLength = 300
+
+// errLimit stops a walk once enough results were collected.
+var errLimit = errors.New("result limit reached")
+
+// searchRoot is a project directory the server searches.
+type searchRoot struct {
+	name string
+	dir  string // absolute
+}
+
+// resolveRoots turns directories into roots, named after the directory with a numeric
+// suffix for duplicates.
+func resolveRoots(dirs []string) ([]searchRoot, error) {
+	var roots []searchRoot
+	seen := make(map[string]int)
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %w", dir, err)
+		}
+		if real, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = real
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("root %s is not a directory", dir)
+		}
+		name := filepath.Base(abs)
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+		roots = append(roots, searchRoot{name: name, dir: abs})
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("at least one root is required")
+	}
+	return roots, nil
+}
+
+// target is where a request searches: a root and a slash separated path within it.
+type target struct {
+	root  searchRoot
+	start string
+}
+
+// walkOptions control which files a walk visits.
+type walkOptions struct {
+	hidden   bool
+	noIgnore bool
+	filter   *globFilter
+}
+
+// walker visits the files of a root like ripgrep: .gitignore rules apply, hidden files,
+// symlinks and the .git directory are skipped.
+type walker struct {
+	ctx  context.Context
+	root searchRoot
+	opts walkOptions
+	fn   func(rel, abs string, entry fs.DirEntry) error
+}
+
+// walkTarget calls fn for every selected file below the target, in lexical order.
+func walkTarget(ctx context.Context, t target, opts walkOptions, fn func(rel, abs string, entry fs.DirEntry) error) error {
+	w := &walker{ctx: ctx, root: t.root, opts: opts, fn: fn}
+	abs := filepath.Join(t.root.dir, filepath.FromSlash(t.start))
+	info, err := os.Stat(abs)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		// A file named explicitly is searched even if ignored
+		return fn(t.start, abs, fs.FileInfoToDirEntry(info))
+	}
+
+	// Rules of the directories above the start apply as well
+	var stack []*ignoreFile
+	if !opts.noIgnore {
+		if ig := loadIgnoreFile(filepath.Join(t.root.dir, ".git", "info", "exclude"), ""); ig != nil {
+			stack = append(stack, ig)
+		}
+		dir := ""
+		for _, part := range strings.Split(t.start, "/") {
+			if part == "" {
+				break
+			}
+			if ig := loadIgnoreFile(filepath.Join(t.root.dir, filepath.FromSlash(dir), ".gitignore"), dir); ig != nil {
+				stack = append(stack, ig)
+			}
+			dir = path.Join(dir, part)
+		}
+	}
+	return w.dir(t.start, stack)
+}
+
+func (w *walker) dir(rel string, stack []*ignoreFile) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	abs := filepath.Join(w.root.dir, filepath.FromSlash(rel))
+	if !w.opts.noIgnore {
+		if ig := loadIgnoreFile(filepath.Join(abs, ".gitignore"), rel); ig != nil {
+			// Copy on append so sibling directories do not share rules
+			stack = append(stack[:len(stack):len(stack)], ig)
+		}
+	}
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		log.Printf("Skipping unreadable directory %s: %v", abs, err)
+		return nil
+	}
+	for _, e := range entries {
+		name := e.Name()
+		childRel := path.Join(rel, name)
+		if name == ".git" || (!w.opts.hidden && strings.HasPrefix(name, ".")) {
+			continue
+		}
+		if !e.IsDir() && !e.Type().IsRegular() {
+			// Symlinks could lead outside the root, other types are not files
+			continue
+		}
+		if !w.opts.noIgnore && ignored(stack, childRel, e.IsDir()) {
+			continue
+		}
+		if e.IsDir() {
+			if w.opts.filter.excludesDir(childRel) {
+				continue
+			}
+			if err := w.dir(childRel, stack); err != nil {
+				return err
+			}
+			continue
+		}
+		if !w.opts.filter.matches(childRel) {
+			continue
+		}
+		if err := w.fn(childRel, filepath.Join(abs, name), e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles a search pattern. ^ and $ match at line boundaries.
+func compilePattern(pattern string, literal, ignoreCase bool) (*regexp.Regexp, error) {
+	if literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	flags := "(?m)"
+	if ignoreCase {
+		flags = "(?mi)"
+	}
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// readText reads a file for searching. Files larger than maxSize and binary files, which
+// contain a NUL byte near the start, are skipped.
+func readText(abs string, entry fs.DirEntry, maxSize int64) ([]byte, bool) {
+	info, err := entry.Info()
+	if err != nil || info.Size() > maxSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		log.Printf("Skipping unreadable file %s: %v", abs, err)
+		return nil, false
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines
+}
+
+func clip(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+	cut := maxLineLength
+	for cut > 0 && !isRuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xc0 != 0x80
+}
+
+// searchLines formats up to limit matching lines of a file like ripgrep: "12:" prefixes a
+// match, "13-" a context line and "--" separates groups that are not adjacent. It returns
+// the number of matching lines printed.
+func searchLines(data []byte, re *regexp.Regexp, contextLines, limit int) (string, int) {
+	lines := splitLines(data)
+	var matches []int
+	for i, line := range lines {
+		if len(matches) == limit {
+			break
+		}
+		if re.MatchString(line) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0
+	}
+
+	var b strings.Builder
+	printed := -1 // last printed line index
+	for n, m := range matches {
+		from := max(m-contextLines, printed+1)
+		if printed >= 0 && from > printed+1 {
+			b.WriteString("--\n")
+		}
+		to := min(m+contextLines, len(lines)-1)
+		if n+1 < len(matches) {
+			// Context after a match ends where the next match's context begins
+			to = min(to, matches[n+1]-1)
+		}
+		for i := from; i <= to; i++ {
+			sep := "-"
+			if i == m || (i > m && re.MatchString(lines[i])) {
+				sep = ":"
+			}
+			fmt.Fprintf(&b, "%d%s%s\n", i+1, sep, clip(lines[i]))
+		}
+		printed = max(printed, to)
+	}
+	return b.String(), len(matches)
+}
@@ -0,0 +1,546 @@
+package codesearch
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/pagination"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// CodeSearchServer is an MCP server that searches the files of local project roots.
+type CodeSearchServer struct {
+	server      *server.MCPServer
+	roots       []searchRoot
+	maxResults  int
+	maxFileSize int64
+}
+
+// NewCodeSearchServer creates a new CodeSearchServer instance searching the given roots.
+func NewCodeSearchServer(roots []searchRoot, maxResults int, maxFileSize int64) *CodeSearchServer {
+	log.Printf("CodeSearchServer created: roots=%d, maxResults=%d, maxFileSize=%d", len(roots), maxResults, maxFileSize)
+
+	s := &CodeSearchServer{
+		roots:       roots,
+		maxResults:  maxResults,
+		maxFileSize: maxFileSize,
+	}
+
+	names := make([]string, len(s.roots))
+	for i, r := range s.roots {
+		names[i] = r.name
+	}
+	rootDescription := fmt.Sprintf("Root to search: %s (default: all roots)", strings.Join(names, ", "))
+	pathDescription := "File or directory to search, relative to the root"
+	if len(s.roots) > 1 {
+		pathDescription += "; paths printed by these tools, which start with the root name, are accepted as well"
+	}
+	globDescription := "Glob patterns selecting files, e.g. *.go or src/**/*.ts; prefix with ! to exclude, e.g. !*_test.go"
+
+	mcpServer := server.NewMCPServer(
+		"codesearch-server", // server name
+		"1.0.0",             // version
+	)
+
+	// Register searchCode tool
+	searchCodeTool := mcp.NewTool("searchCode",
+		mcp.WithDescription("Searches file contents for a regular expression or literal text, ripgrep style. "+
+			".gitignore rules are honored and hidden, binary and large files are skipped. "+
+			"Matches print as path, then \"line:text\", with \"line-text\" for context lines"),
+		mcp.WithString("pattern",
+			mcp.Description("Go regular expression, or literal text with literal set; ^ and $ match at line boundaries"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("literal",
+			mcp.Description("Treat pattern as literal text (default: false)"),
+		),
+		mcp.WithBoolean("ignoreCase",
+			mcp.Description("Match case insensitively (default: false)"),
+		),
+		mcp.WithString("root",
+			mcp.Description(rootDescription),
+		),
+		mcp.WithString("path",
+			mcp.Description(pathDescription),
+		),
+		mcp.WithArray("glob",
+			mcp.Description(globDescription),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("context",
+			mcp.Description("Lines of context before and after each match (default: 0, maximum: 10)"),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description(fmt.Sprintf("Maximum number of matching lines (default and maximum: %d)", maxResults)),
+		),
+		mcp.WithBoolean("hidden",
+			mcp.Description("Search hidden files and directories (default: false)"),
+		),
+		mcp.WithBoolean("noIgnore",
+			mcp.Description("Search files excluded by .gitignore (default: false)"),
+		),
+	)
+
+	// Register findFiles tool
+	findFilesTool := mcp.NewTool("findFiles",
+		mcp.WithDescription("Lists files by glob pattern, honoring .gitignore rules and skipping hidden files"),
+		mcp.WithArray("glob",
+			mcp.Description(globDescription+" (default: all files)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithString("root",
+			mcp.Description(rootDescription),
+		),
+		mcp.WithString("path",
+			mcp.Description(pathDescription),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description(fmt.Sprintf("Maximum number of files per page (default and maximum: %d)", maxResults)),
+		),
+		mcp.WithBoolean("hidden",
+			mcp.Description("Include hidden files and directories (default: false)"),
+		),
+		mcp.WithBoolean("noIgnore",
+			mcp.Description("Include files excluded by .gitignore (default: false)"),
+		),
+		pagination.Param(),
+	)
+
+	// Register countMatches tool
+	countMatchesTool := mcp.NewTool("countMatches",
+		mcp.WithDescription("Counts the occurrences of a pattern per file, most matches first, to gauge how widely "+
+			"something is used before searching for it"),
+		mcp.WithString("pattern",
+			mcp.Description("Go regular expression, or literal text with literal set"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("literal",
+			mcp.Description("Treat pattern as literal text (default: false)"),
+		),
+		mcp.WithBoolean("ignoreCase",
+			mcp.Description("Match case insensitively (default: false)"),
+		),
+		mcp.WithString("root",
+			mcp.Description(rootDescription),
+		),
+		mcp.WithString("path",
+			mcp.Description(pathDescription),
+		),
+		mcp.WithArray("glob",
+			mcp.Description(globDescription),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description(fmt.Sprintf("Maximum number of files listed; totals cover all files (default and maximum: %d)", maxResults)),
+		),
+		mcp.WithBoolean("hidden",
+			mcp.Description("Search hidden files and directories (default: false)"),
+		),
+		mcp.WithBoolean("noIgnore",
+			mcp.Description("Search files excluded by .gitignore (default: false)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, searchCodeTool, s.handleSearchCode)
+	middleware.AddTool(mcpServer, findFilesTool, s.handleFindFiles)
+	middleware.AddTool(mcpServer, countMatchesTool, s.handleCountMatches)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// scope holds the parameters that select the files of a request.
+type scope struct {
+	Root       string   `json:"root"`
+	Path       string   `json:"path"`
+	Glob       []string `json:"glob"`
+	MaxResults int      `json:"maxResults"`
+	Hidden     bool     `json:"hidden"`
+	NoIgnore   bool     `json:"noIgnore"`
+}
+
+// targets resolves the root and path of a request. Paths cannot leave their root.
+func (s *CodeSearchServer) targets(rootName, p string) ([]target, error) {
+	p = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+
+	// Printed paths start with the root name when there are several roots
+	if rootName == "" && len(s.roots) > 1 && p != "" {
+		first, rest, _ := strings.Cut(p, "/")
+		for _, r := range s.roots {
+			if r.name == first {
+				rootName, p = first, rest
+				break
+			}
+		}
+	}
+
+	var candidates []searchRoot
+	if rootName == "" {
+		candidates = s.roots
+	} else {
+		for _, r := range s.roots {
+			if r.name == rootName {
+				candidates = append(candidates, r)
+			}
+		}
+		if len(candidates) == 0 {
+			names := make([]string, len(s.roots))
+			for i, r := range s.roots {
+				names[i] = r.name
+			}
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "unknown root %q; use %s", rootName, strings.Join(names, ", "))
+		}
+	}
+
+	var targets []target
+	for _, r := range candidates {
+		abs := filepath.Join(r.dir, filepath.FromSlash(p))
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(r.dir, real)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("path escapes the root via symlink: %s", p)
+		}
+		targets = append(targets, target{root: r, start: p})
+	}
+	if len(targets) == 0 {
+		return nil, toolerr.Errorf(toolerr.NotFound, "path not found: %s", p)
+	}
+	return targets, nil
+}
+
+// prepare validates the scope, returning the targets, walk options and result limit.
+func (s *CodeSearchServer) prepare(sc scope) ([]target, walkOptions, int, error) {
+	targets, err := s.targets(sc.Root, sc.Path)
+	if err != nil {
+		return nil, walkOptions{}, 0, err
+	}
+	limit := sc.MaxResults
+	if limit <= 0 || limit > s.maxResults {
+		limit = s.maxResults
+	}
+	opts := walkOptions{hidden: sc.Hidden, noIgnore: sc.NoIgnore, filter: newGlobFilter(sc.Glob)}
+	return targets, opts, limit, nil
+}
+
+// displayPath names a file for output, prefixed with its root when there are several.
+func (s *CodeSearchServer) displayPath(root searchRoot, rel string) string {
+	if len(s.roots) > 1 {
+		return path.Join(root.name, rel)
+	}
+	return rel
+}
+
+// handleSearchCode handles the content search request.
+func (s *CodeSearchServer) handleSearchCode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting search code request processing")
+
+	var args struct {
+		scope
+		Pattern    string `json:"pattern"`
+		Literal    bool   `json:"literal"`
+		IgnoreCase bool   `json:"ignoreCase"`
+		Context    int    `json:"context"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Pattern == "" {
+		log.Println("Error: Empty pattern")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "pattern is required")
+	}
+	if args.Context < 0 || args.Context > 10 {
+		log.Printf("Error: Invalid context: %d", args.Context)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "context must be between 0 and 10")
+	}
+	re, err := compilePattern(args.Pattern, args.Literal, args.IgnoreCase)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	targets, opts, limit, err := s.prepare(args.scope)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var b strings.Builder
+	matches, files, searched := 0, 0, 0
+	for _, t := range targets {
+		err = walkTarget(ctx, t, opts, func(rel, abs string, entry fs.DirEntry) error {
+			data, ok := readText(abs, entry, s.maxFileSize)
+			if !ok {
+				return nil
+			}
+			searched++
+			out, n := searchLines(data, re, args.Context, limit-matches)
+			if n == 0 {
+				return nil
+			}
+			if files > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(s.displayPath(t.root, rel) + "\n" + out)
+			matches += n
+			files++
+			if matches >= limit {
+				return errLimit
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+	if err != nil && !errors.Is(err, errLimit) {
+		log.Printf("Error: Search failed: %v", err)
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	log.Printf("Search code request completed: %d matches in %d files, %d files searched", matches, files, searched)
+	if matches == 0 {
+		return textResult(fmt.Sprintf("No matches found in %d files", searched)), nil
+	}
+	fmt.Fprintf(&b, "\nFound %d matching lines in %d files", matches, files)
+	if errors.Is(err, errLimit) {
+		b.WriteString(" (result limit reached; narrow the search with path or glob)")
+	}
+	return textResult(b.String()), nil
+}
+
+// handleFindFiles handles the file listing request.
+func (s *CodeSearchServer) handleFindFiles(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting find files request processing")
+
+	var args scope
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	pager, err := pagination.Start(req)
+	if err != nil {
+		return nil, err
+	}
+	targets, opts, limit, err := s.prepare(args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// Files are walked in a stable order, so the files of earlier pages are skipped
+	var paths []string
+	skip := pager.Offset
+	for _, t := range targets {
+		err = walkTarget(ctx, t, opts, func(rel, abs string, entry fs.DirEntry) error {
+			if skip > 0 {
+				skip--
+				return nil
+			}
+			if len(paths) == limit {
+				return errLimit
+			}
+			paths = append(paths, s.displayPath(t.root, rel))
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+	if err != nil && !errors.Is(err, errLimit) {
+		log.Printf("Error: Find failed: %v", err)
+		return nil, fmt.Errorf("find failed: %w", err)
+	}
+
+	log.Printf("Find files request completed: %d files", len(paths))
+	if len(paths) == 0 {
+		return textResult("No files found"), nil
+	}
+	summary := fmt.Sprintf("%d files", len(paths))
+	if pager.Offset > 0 {
+		summary = fmt.Sprintf("Files %d-%d", pager.Offset+1, pager.Offset+len(paths))
+	}
+	var next string
+	if errors.Is(err, errLimit) {
+		next = pager.Cursor(pager.Offset + len(paths))
+	}
+	return pagination.Result(textResult(strings.Join(paths, "\n")+"\n\n"+summary), next), nil
+}
+
+// handleCountMatches handles the match counting request.
+func (s *CodeSearchServer) handleCountMatches(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting count matches request processing")
+
+	var args struct {
+		scope
+		Pattern    string `json:"pattern"`
+		Literal    bool   `json:"literal"`
+		IgnoreCase bool   `json:"ignoreCase"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Pattern == "" {
+		log.Println("Error: Empty pattern")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "pattern is required")
+	}
+	re, err := compilePattern(args.Pattern, args.Literal, args.IgnoreCase)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	targets, opts, limit, err := s.prepare(args.scope)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	type fileCount struct {
+		path  string
+		count int
+	}
+	var counts []fileCount
+	total, searched := 0, 0
+	for _, t := range targets {
+		err := walkTarget(ctx, t, opts, func(rel, abs string, entry fs.DirEntry) error {
+			data, ok := readText(abs, entry, s.maxFileSize)
+			if !ok {
+				return nil
+			}
+			searched++
+			if n := len(re.FindAllIndex(data, -1)); n > 0 {
+				counts = append(counts, fileCount{s.displayPath(t.root, rel), n})
+				total += n
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error: Count failed: %v", err)
+			return nil, fmt.Errorf("count failed: %w", err)
+		}
+	}
+
+	log.Printf("Count matches request completed: %d matches in %d files", total, len(counts))
+	if total == 0 {
+		return textResult(fmt.Sprintf("No matches found in %d files", searched)), nil
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].count > counts[j].count })
+
+	var b strings.Builder
+	for i, c := range counts {
+		if i == limit {
+			fmt.Fprintf(&b, "... %d more files\n", len(counts)-limit)
+			break
+		}
+		fmt.Fprintf(&b, "%s: %d\n", c.path, c.count)
+	}
+	fmt.Fprintf(&b, "\nTotal: %d matches in %d files (%d files searched)", total, len(counts), searched)
+	return textResult(b.String()), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *CodeSearchServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the codesearch server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("codesearch", flag.ContinueOnError)
+	var (
+		rootDirs    string
+		maxResults  int
+		maxFileSize int
+	)
+	fs.StringVar(&rootDirs, "roots", ".", "Comma separated list of project directories to search")
+	fs.IntVar(&maxResults, "max-results", 500, "Maximum number of matching lines or files per request")
+	fs.IntVar(&maxFileSize, "max-file-size", 1024*1024, "Maximum size of searched files in bytes (default 1MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting code search server: roots=%s, max-results=%d, max-file-size=%d", rootDirs, maxResults, maxFileSize)
+
+	var dirs []string
+	for _, dir := range strings.Split(rootDirs, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	roots, err := resolveRoots(dirs)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create CodeSearchServer instance
+	codeSearchServer := NewCodeSearchServer(roots, maxResults, int64(maxFileSize))
+	log.Println("CodeSearchServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), codeSearchServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return codeSearchServer.Server(), transportFlags, nil
+}
+
+// Run starts the codesearch server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[CodeSearchServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create codesearch server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("CodeSearchServer shutdown")
+	return nil
+}
@@ -0,0 +1,337 @@
+package codesearch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree creates files below dir from a map of slash separated paths to contents.
+func writeTree(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+// newTestServer creates a server on a project with .gitignore files, hidden and binary files.
+func newTestServer(t testing.TB) (*CodeSearchServer, string) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":           "*.log\nbuild/\n!keep.log\n",
+		"main.go":              "package main\n\nfunc main() {\n\tgreet(\"world\")\n}\n",
+		"greet.go":             "package main\n\nimport \"fmt\"\n\n// greet prints a greeting\nfunc greet(name string) {\n\tfmt.Println(\"Hello,\", name)\n}\n",
+		"greet_test.go":        "package main\n\nfunc TestGreet() { greet(\"test\") }\n",
+		"debug.log":            "greet failed\n",
+		"keep.log":             "greet kept\n",
+		"build/out.go":         "greet built\n",
+		".hidden/config":       "greet hidden\n",
+		"web/.gitignore":       "generated/\n",
+		"web/app.ts":           "export function greet() {}\n",
+		"web/generated/api.ts": "greet generated\n",
+		"data.bin":             "greet\x00binary\n",
+		".git/HEAD":            "ref: greet\n",
+	})
+	roots, err := resolveRoots([]string{dir})
+	require.NoError(t, err)
+	return NewCodeSearchServer(roots, 100, 1024), dir
+}
+
+// CodeSearchServer creation test
+func TestNewCodeSearchServer(t *testing.T) {
+	dir := t.TempDir()
+	roots, err := resolveRoots([]string{dir, dir})
+	require.NoError(t, err)
+	s := NewCodeSearchServer(roots, 50, 2048)
+
+	assert.NotNil(t, s, "CodeSearchServer instance should be created")
+	require.Len(t, s.roots, 2, "Roots should match")
+	assert.Equal(t, filepath.Base(dir)+"-2", s.roots[1].name, "Duplicate root names should get a suffix")
+	assert.Equal(t, 50, s.maxResults, "Max results should match")
+	assert.Equal(t, int64(2048), s.maxFileSize, "Max file size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+
+	_, err = resolveRoots([]string{filepath.Join(dir, "missing")})
+	assert.ErrorContains(t, err, "not a directory")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s, _ := newTestServer(t)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test .gitignore semantics
+func TestIgnored(t *testing.T) {
+	root := &ignoreFile{}
+	for _, line := range []string{"# comment", "*.log", "!keep.log", "/top.txt", "build/", "docs/**/*.pdf", `\#hash`} {
+		if p, ok := parseIgnoreLine(line); ok {
+			root.patterns = append(root.patterns, p)
+		}
+	}
+	sub := &ignoreFile{dir: "pkg"}
+	if p, ok := parseIgnoreLine("!important.log"); ok {
+		sub.patterns = append(sub.patterns, p)
+	}
+	stack := []*ignoreFile{root, sub}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"a/b/debug.log", false, true},
+		{"keep.log", false, false},
+		{"top.txt", false, true},
+		{"a/top.txt", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/a/b/x.pdf", false, true},
+		{"docs/x.pdf", false, true},
+		{"#hash", false, true},
+		{"pkg/important.log", false, false},
+		{"other/important.log", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ignored(stack, tt.path, tt.isDir), tt.path)
+	}
+}
+
+// Test searchCode handler
+func TestHandleSearchCode(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "greet",
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	for _, want := range []string{"greet.go\n5:// greet prints", "main.go\n4:\tgreet(", "web/app.ts\n1:", "keep.log\n1:"} {
+		assert.Contains(t, text, want)
+	}
+	for _, unwanted := range []string{"debug.log", "build/", ".hidden", "generated", "data.bin", ".git/"} {
+		assert.NotContains(t, text, unwanted)
+	}
+
+	// Hidden and ignored files can be included
+	result, err = s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern":  "greet",
+		"hidden":   true,
+		"noIgnore": true,
+	}))
+	require.NoError(t, err)
+	text = mcptest.ResultText(result)
+	for _, want := range []string{"debug.log", "build/out.go", ".hidden/config", "web/generated/api.ts"} {
+		assert.Contains(t, text, want)
+	}
+	assert.NotContains(t, text, ".git/HEAD")
+	assert.NotContains(t, text, "data.bin")
+}
+
+// Test literal, case insensitive and context options
+func TestSearchCodeOptions(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern":    "HELLO,",
+		"ignoreCase": true,
+		"literal":    true,
+		"context":    1,
+		"glob":       []interface{}{"*.go"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "greet.go\n6-func greet(name string) {\n7:\tfmt.Println(\"Hello,\", name)\n8-}\n\nFound 1 matching lines in 1 files", mcptest.ResultText(result))
+
+	result, err = s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": `^func`,
+		"glob":    []interface{}{"*.go", "!*_test.go"},
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "greet.go\n6:func greet")
+	assert.Contains(t, text, "main.go\n3:func main")
+	assert.NotContains(t, text, "greet_test.go")
+
+	result, err = s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "nothing matches this",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "No matches found")
+}
+
+// Test that context groups merge and are separated by --
+func TestSearchLines(t *testing.T) {
+	data := []byte("a\nx\nb\nc\nd\ne\nx\nf\nx\n")
+	re, err := compilePattern("x", false, false)
+	require.NoError(t, err)
+
+	out, n := searchLines(data, re, 1, 10)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "1-a\n2:x\n3-b\n--\n6-e\n7:x\n8-f\n9:x\n", out)
+
+	out, n = searchLines(data, re, 0, 2)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "2:x\n--\n7:x\n", out)
+}
+
+// Test the result limit
+func TestSearchCodeLimit(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern":    "greet",
+		"maxResults": 2,
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "Found 2 matching lines")
+	assert.Contains(t, text, "result limit reached")
+}
+
+// Test findFiles handler
+func TestHandleFindFiles(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result, err := s.handleFindFiles(context.Background(), mcptest.NewCallToolRequest("findFiles", map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Equal(t, "data.bin\ngreet.go\ngreet_test.go\nkeep.log\nmain.go\nweb/app.ts\n\n6 files", mcptest.ResultText(result))
+
+	result, err = s.handleFindFiles(context.Background(), mcptest.NewCallToolRequest("findFiles", map[string]interface{}{
+		"glob": []interface{}{"**/*.ts"},
+		"path": "web",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "web/app.ts\n\n1 files", mcptest.ResultText(result))
+
+	// Pages are read with the cursor of the previous one
+	var pages, cursors []string
+	cursor := ""
+	for range 3 {
+		args := map[string]interface{}{"maxResults": 2}
+		if cursor != "" {
+			args["cursor"] = cursor
+		}
+		result, err = s.handleFindFiles(context.Background(), mcptest.NewCallToolRequest("findFiles", args))
+		require.NoError(t, err)
+		pages = append(pages, strings.SplitN(mcptest.ResultText(result), "\n\nMore results", 2)[0])
+		cursor, _ = result.Meta["nextCursor"].(string)
+		cursors = append(cursors, cursor)
+	}
+	assert.Equal(t, []string{
+		"data.bin\ngreet.go\n\n2 files",
+		"greet_test.go\nkeep.log\n\nFiles 3-4",
+		"main.go\nweb/app.ts\n\nFiles 5-6",
+	}, pages)
+	assert.Empty(t, cursor)
+
+	// Cursors are refused with other arguments
+	_, err = s.handleFindFiles(context.Background(), mcptest.NewCallToolRequest("findFiles", map[string]interface{}{
+		"maxResults": 3, "cursor": cursors[0],
+	}))
+	assert.ErrorContains(t, err, "cursor belongs to a call with other arguments")
+}
+
+// Test countMatches handler
+func TestHandleCountMatches(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	result, err := s.handleCountMatches(context.Background(), mcptest.NewCallToolRequest("countMatches", map[string]interface{}{
+		"pattern": "greet",
+		"glob":    []interface{}{"*.go"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "greet.go: 3\ngreet_test.go: 1\nmain.go: 1\n\nTotal: 5 matches in 3 files (3 files searched)", mcptest.ResultText(result))
+}
+
+// Test several roots
+func TestMultipleRoots(t *testing.T) {
+	base := t.TempDir()
+	writeTree(t, base, map[string]string{
+		"alpha/a.txt": "needle\n",
+		"beta/b.txt":  "needle\n",
+	})
+	roots, err := resolveRoots([]string{filepath.Join(base, "alpha"), filepath.Join(base, "beta")})
+	require.NoError(t, err)
+	s := NewCodeSearchServer(roots, 100, 1024)
+
+	result, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "needle",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "alpha/a.txt\n1:needle")
+	assert.Contains(t, mcptest.ResultText(result), "beta/b.txt\n1:needle")
+
+	// Printed paths can be passed back
+	result, err = s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "needle",
+		"path":    "beta/b.txt",
+	}))
+	require.NoError(t, err)
+	assert.NotContains(t, mcptest.ResultText(result), "alpha")
+
+	result, err = s.handleFindFiles(context.Background(), mcptest.NewCallToolRequest("findFiles", map[string]interface{}{
+		"root": "alpha",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "alpha/a.txt\n\n1 files", mcptest.ResultText(result))
+}
+
+// Test that symlinks cannot leave the root
+func TestSymlinkEscape(t *testing.T) {
+	s, dir := newTestServer(t)
+	outside := t.TempDir()
+	writeTree(t, outside, map[string]string{"secret.txt": "greet secret\n"})
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+
+	_, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "greet",
+		"path":    "link",
+	}))
+	assert.ErrorContains(t, err, "escapes the root")
+
+	result, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", map[string]interface{}{
+		"pattern": "secret",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "No matches found")
+}
+
+// Test error cases
+func TestErrors(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"empty pattern", map[string]interface{}{}, "pattern is required"},
+		{"invalid regexp", map[string]interface{}{"pattern": "("}, "invalid pattern"},
+		{"context too large", map[string]interface{}{"pattern": "x", "context": 11}, "context must be between"},
+		{"unknown root", map[string]interface{}{"pattern": "x", "root": "nope"}, "unknown root"},
+		{"missing path", map[string]interface{}{"pattern": "x", "path": "nope"}, "path not found"},
+		{"traversal stays inside", map[string]interface{}{"pattern": "x", "path": "../../etc/passwd"}, "path not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleSearchCode(context.Background(), mcptest.NewCallToolRequest("searchCode", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	s, _ := newTestServer(f)
+	mcptest.FuzzTools(f, s.Server())
+}
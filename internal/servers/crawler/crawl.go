@@ -0,0 +1,231 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/budget"
+	"github.com/mark3labs/mcphost/internal/progress"
+)
+
+// maxRobotsSize bounds the robots.txt read, as RFC 9309 allows crawlers to.
+const maxRobotsSize = 500 * 1024
+
+// crawlOptions limit one crawl.
+type crawlOptions struct {
+	maxDepth          int
+	maxPages          int
+	includeSubdomains bool
+	pathPrefix        string
+	// reporter is sent the number of pages crawled
+	reporter *progress.Reporter
+}
+
+// pageError records a page that could not be crawled.
+type pageError struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// crawlResult is the outcome of a crawl.
+type crawlResult struct {
+	Seed            string      `json:"seed"`
+	Pages           []*Page     `json:"pages"`
+	Errors          []pageError `json:"errors,omitempty"`
+	BlockedByRobots int         `json:"blockedByRobots"`
+	Unvisited       int         `json:"unvisited"`
+	Stopped         string      `json:"stopped,omitempty"`
+}
+
+// queued is a URL waiting to be crawled.
+type queued struct {
+	url   string
+	depth int
+}
+
+// errSkipped marks responses that are not crawlable pages, such as images or redirects
+// leaving the site.
+var errSkipped = errors.New("not an HTML page of the site")
+
+// crawl visits the site breadth first from the seed. It stops at the page or depth
+// limits, when the crawl duration or the time left to the call runs out or when ctx is
+// cancelled, returning what was collected so far.
+func (s *CrawlerServer) crawl(ctx context.Context, seed *url.URL, opts crawlOptions) *crawlResult {
+	start, _ := normalizeURL(seed)
+	result := &crawlResult{Seed: start, Pages: []*Page{}}
+	deadline := s.now().Add(s.maxDuration)
+	if remaining, ok := budget.Remaining(ctx); ok && remaining-budget.Reserve < s.maxDuration {
+		deadline = s.now().Add(remaining - budget.Reserve)
+	}
+	siteHost := strings.TrimPrefix(seed.Hostname(), "www.")
+
+	inScope := func(u *url.URL) bool {
+		host := strings.TrimPrefix(u.Hostname(), "www.")
+		if host != siteHost && !(opts.includeSubdomains && strings.HasSuffix(host, "."+siteHost)) {
+			return false
+		}
+		return strings.HasPrefix(u.Path, opts.pathPrefix)
+	}
+
+	robotsByHost := make(map[string]*robots)
+	lastRequest := make(map[string]time.Time)
+	seen := map[string]bool{start: true}
+	queue := []queued{{url: start}}
+
+	for len(queue) > 0 {
+		if len(result.Pages) >= opts.maxPages {
+			result.Stopped = "page limit reached"
+			break
+		}
+		if ctx.Err() != nil {
+			result.Stopped = "crawl cancelled"
+			break
+		}
+		item := queue[0]
+		queue = queue[1:]
+		u, err := url.Parse(item.url)
+		if err != nil {
+			continue
+		}
+
+		host := u.Scheme + "://" + u.Host
+		rb, ok := robotsByHost[host]
+		if !ok {
+			rb = s.fetchRobots(ctx, u)
+			robotsByHost[host] = rb
+		}
+		if !rb.allowed(u.RequestURI()) {
+			result.BlockedByRobots++
+			continue
+		}
+
+		// Politeness: wait between requests to the same host
+		var wait time.Duration
+		if last, ok := lastRequest[host]; ok {
+			wait = last.Add(max(s.delay, rb.crawlDelay)).Sub(s.now())
+		}
+		if s.now().Add(max(wait, 0) + budget.MinRequest).After(deadline) {
+			queue = append(queue, item)
+			result.Stopped = "time limit reached"
+			break
+		}
+		if wait > 0 {
+			if err := s.sleep(ctx, wait); err != nil {
+				queue = append(queue, item)
+				result.Stopped = "crawl cancelled"
+				break
+			}
+		}
+		lastRequest[host] = s.now()
+
+		page, err := s.fetchPage(ctx, u, inScope)
+		if errors.Is(err, errSkipped) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Error: Failed to crawl %s: %v", item.url, err)
+			result.Errors = append(result.Errors, pageError{URL: item.url, Error: err.Error()})
+			continue
+		}
+		// A redirect may lead to a page that is queued as well
+		if page.URL != item.url {
+			if seen[page.URL] {
+				continue
+			}
+			seen[page.URL] = true
+		}
+		page.Depth = item.depth
+		if !page.noIndex {
+			result.Pages = append(result.Pages, page)
+			opts.reporter.Report(float64(len(result.Pages)))
+		}
+
+		if item.depth >= opts.maxDepth || page.noFollow {
+			continue
+		}
+		for _, link := range page.links {
+			lu, err := url.Parse(link)
+			if err != nil || seen[link] || !inScope(lu) {
+				continue
+			}
+			seen[link] = true
+			queue = append(queue, queued{url: link, depth: item.depth + 1})
+		}
+	}
+	result.Unvisited = len(queue)
+	return result
+}
+
+// fetchRobots reads the robots.txt of the URL's host. A missing file allows everything,
+// while a server error or an unreachable host disallows everything (RFC 9309).
+func (s *CrawlerServer) fetchRobots(ctx context.Context, u *url.URL) *robots {
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &robots{disallowAll: true}
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("Error: Failed to fetch %s: %v", robotsURL, err)
+		return &robots{disallowAll: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		log.Printf("Error: %s returned status %d, not crawling the host", robotsURL, resp.StatusCode)
+		return &robots{disallowAll: true}
+	case resp.StatusCode >= 400:
+		return &robots{}
+	case resp.StatusCode >= 300:
+		// The client follows redirects, so this is a redirect loop or limit
+		return &robots{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), s.userAgent)
+}
+
+// fetchPage requests a URL and extracts its content. Non-HTML responses and redirects
+// out of scope yield errSkipped.
+func (s *CrawlerServer) fetchPage(ctx context.Context, u *url.URL, inScope func(*url.URL) bool) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	final := resp.Request.URL
+	if !inScope(final) {
+		return nil, errSkipped
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, errSkipped
+	}
+
+	page, err := parsePage(io.LimitReader(resp.Body, s.maxBodySize), final)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	if normalized, ok := normalizeURL(final); ok {
+		page.URL = normalized
+	}
+	return page, nil
+}
@@ -0,0 +1,197 @@
+package crawler
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Page is the content extracted from one crawled page.
+type Page struct {
+	URL         string   `json:"url"`
+	Depth       int      `json:"depth"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Headings    []string `json:"headings,omitempty"`
+	Words       int      `json:"words"`
+	Text        string   `json:"text,omitempty"`
+
+	links    []string
+	noIndex  bool
+	noFollow bool
+}
+
+// maxHeadings bounds the headings kept per page.
+const maxHeadings = 10
+
+// skippedElements hold no readable text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Iframe: true,
+}
+
+// blockElements start a new line of text.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true, atom.Nav: true,
+	atom.Main: true, atom.Aside: true, atom.Blockquote: true, atom.Pre: true, atom.Table: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Form: true, atom.Hr: true,
+}
+
+// parsePage extracts the title, description, headings, text and links of an HTML page.
+// Links are resolved against the page URL, or its <base> element.
+func parsePage(r io.Reader, pageURL *url.URL) (*Page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Page{URL: pageURL.String()}
+	base := pageURL
+	var text strings.Builder
+	var hrefs []string
+
+	// collect is false inside the head, whose elements are read for metadata only
+	var walk func(n *html.Node, collect bool)
+	walk = func(n *html.Node, collect bool) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if p.Title == "" {
+					p.Title = collapse(nodeText(n))
+				}
+			case atom.Meta:
+				content := attr(n, "content")
+				switch strings.ToLower(attr(n, "name")) {
+				case "description":
+					p.Description = collapse(content)
+				case "robots":
+					directives := strings.ToLower(content)
+					p.noIndex = p.noIndex || strings.Contains(directives, "noindex") || strings.Contains(directives, "none")
+					p.noFollow = p.noFollow || strings.Contains(directives, "nofollow") || strings.Contains(directives, "none")
+				}
+			case atom.Base:
+				if href := attr(n, "href"); href != "" {
+					if u, err := pageURL.Parse(href); err == nil {
+						base = u
+					}
+				}
+			case atom.A:
+				if href := attr(n, "href"); href != "" && !strings.Contains(strings.ToLower(attr(n, "rel")), "nofollow") {
+					hrefs = append(hrefs, href)
+				}
+			case atom.H1, atom.H2, atom.H3:
+				if h := collapse(nodeText(n)); h != "" && len(p.Headings) < maxHeadings {
+					p.Headings = append(p.Headings, h)
+				}
+			case atom.Head:
+				collect = false
+			}
+			if skippedElements[n.DataAtom] {
+				return
+			}
+			if collect && blockElements[n.DataAtom] {
+				text.WriteString("\n")
+			}
+		}
+		if n.Type == html.TextNode && collect {
+			// Line breaks in the source are plain whitespace; block elements break lines
+			text.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, collect)
+		}
+		if n.Type == html.ElementNode && collect && blockElements[n.DataAtom] {
+			text.WriteString("\n")
+		}
+	}
+	walk(doc, true)
+
+	var lines []string
+	for _, line := range strings.Split(text.String(), "\n") {
+		if line = collapse(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	p.Text = strings.Join(lines, "\n")
+	p.Words = len(strings.Fields(p.Text))
+
+	seen := make(map[string]bool)
+	for _, href := range hrefs {
+		u, err := base.Parse(strings.TrimSpace(href))
+		if err != nil {
+			continue
+		}
+		if link, ok := normalizeURL(u); ok && !seen[link] {
+			seen[link] = true
+			p.links = append(p.links, link)
+		}
+	}
+	return p, nil
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// collapse trims text and reduces runs of whitespace to single spaces.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// nonHTMLExtensions are file types that are not worth requesting.
+var nonHTMLExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".svg": true, ".ico": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tar": true, ".mp3": true, ".mp4": true, ".webm": true,
+	".css": true, ".js": true, ".json": true, ".xml": true, ".woff": true, ".woff2": true, ".exe": true,
+	".dmg": true,
+}
+
+// normalizeURL canonicalizes an HTTP(S) URL so that equivalent links are crawled once:
+// the fragment and default port are dropped and the host is lower cased.
+func normalizeURL(u *url.URL) (string, bool) {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", false
+	}
+	if nonHTMLExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return "", false
+	}
+	n := *u
+	n.Fragment = ""
+	n.RawFragment = ""
+	n.User = nil
+	n.Host = strings.ToLower(n.Host)
+	if (n.Scheme == "http" && n.Port() == "80") || (n.Scheme == "https" && n.Port() == "443") {
+		n.Host = n.Hostname()
+	}
+	if n.Path == "" {
+		n.Path = "/"
+	}
+	return n.String(), true
+}
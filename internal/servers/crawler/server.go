@@ -0,0 +1,356 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/pagination"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/progress"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// CrawlerServer is an MCP server that crawls websites and summarizes their content.
+type CrawlerServer struct {
+	server      *server.MCPServer
+	client      *http.Client
+	userAgent   string
+	maxBodySize int64
+	maxPages    int
+	maxDepth    int
+	delay       time.Duration
+	maxDuration time.Duration
+	// results keeps the pages of long crawl summaries for continueResult
+	results *pagination.Continuations
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewCrawlerServer creates a new CrawlerServer instance. The delay is the minimum time
+// between two requests to a host, raised by a robots.txt Crawl-delay.
+func NewCrawlerServer(timeout int, userAgent string, maxBodySize int64, maxPages, maxDepth int, delay, maxDuration time.Duration) *CrawlerServer {
+	log.Printf("CrawlerServer created: timeout=%ds, userAgent=%s, maxBodySize=%d, maxPages=%d, maxDepth=%d, delay=%s, maxDuration=%s",
+		timeout, userAgent, maxBodySize, maxPages, maxDepth, delay, maxDuration)
+
+	s := &CrawlerServer{
+		client:      httpclient.New(httpclient.Options{Timeout: time.Duration(timeout) * time.Second}),
+		userAgent:   userAgent,
+		maxBodySize: maxBodySize,
+		maxPages:    maxPages,
+		maxDepth:    maxDepth,
+		delay:       delay,
+		maxDuration: maxDuration,
+		now:         time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+
+	mcpServer := server.NewMCPServer(
+		"crawler-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register crawlSite tool
+	crawlSiteTool := mcp.NewTool("crawlSite",
+		mcp.WithDescription("Crawls a website breadth first from a seed URL, staying on the same domain, and returns "+
+			"a summary of its pages: title, description, headings and a text excerpt. "+
+			"robots.txt rules and crawl delays are respected, so large crawls take a while. "+
+			fmt.Sprintf("Text summaries of more than %d pages are split, the next parts read with continueResult", pagesPerResult)),
+		mcp.WithString("url",
+			mcp.Description("Seed URL to start crawling from (http or https)"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description(fmt.Sprintf("Number of links to follow away from the seed (default: 2, maximum: %d)", maxDepth)),
+		),
+		mcp.WithNumber("maxPages",
+			mcp.Description(fmt.Sprintf("Maximum number of pages to collect (default: 20, maximum: %d)", maxPages)),
+		),
+		mcp.WithBoolean("includeSubdomains",
+			mcp.Description("Also crawl subdomains of the seed's domain (default: false)"),
+		),
+		mcp.WithString("pathPrefix",
+			mcp.Description("Only crawl URLs whose path starts with this prefix, e.g. /docs/"),
+		),
+		mcp.WithNumber("excerptLength",
+			mcp.Description("Maximum number of characters of text per page (default: 500, maximum: 5000)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, crawlSiteTool, s.handleCrawlSite)
+	s.results = pagination.Continue(mcpServer)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// excerpt shortens text to at most n characters, cutting at a word boundary.
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndexAny(cut, " \n"); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+// pagesPerResult is the number of crawled pages summarized per page of a text result.
+const pagesPerResult = 20
+
+// formatSummary renders a crawl result as readable text: a header, then an item for
+// each page and one for the errors, if any.
+func formatSummary(r *crawlResult, opts crawlOptions) (string, []string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Crawled %d pages starting at %s (max depth %d)\n", len(r.Pages), r.Seed, opts.maxDepth)
+	var skipped []string
+	if r.BlockedByRobots > 0 {
+		skipped = append(skipped, fmt.Sprintf("%d disallowed by robots.txt", r.BlockedByRobots))
+	}
+	if len(r.Errors) > 0 {
+		skipped = append(skipped, fmt.Sprintf("%d failed", len(r.Errors)))
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped: %s\n", strings.Join(skipped, ", "))
+	}
+	if r.Stopped != "" {
+		fmt.Fprintf(&b, "Stopped: %s, %d queued links not crawled\n", r.Stopped, r.Unvisited)
+	}
+
+	header := strings.TrimRight(b.String(), "\n")
+
+	items := make([]string, 0, len(r.Pages)+1)
+	for i, p := range r.Pages {
+		title := p.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		b.Reset()
+		fmt.Fprintf(&b, "%d. %s — %s (depth %d, %d words)\n", i+1, title, p.URL, p.Depth, p.Words)
+		if p.Description != "" {
+			fmt.Fprintf(&b, "   Description: %s\n", p.Description)
+		}
+		if len(p.Headings) > 0 {
+			fmt.Fprintf(&b, "   Headings: %s\n", strings.Join(p.Headings, " · "))
+		}
+		if p.Text != "" {
+			fmt.Fprintf(&b, "   %s\n", strings.ReplaceAll(p.Text, "\n", "\n   "))
+		}
+		items = append(items, strings.TrimRight(b.String(), "\n"))
+	}
+
+	if len(r.Errors) > 0 {
+		b.Reset()
+		b.WriteString("Errors:")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "\n- %s: %s", e.URL, e.Error)
+		}
+		items = append(items, b.String())
+	}
+	return header, items
+}
+
+// handleCrawlSite handles the crawl request.
+func (s *CrawlerServer) handleCrawlSite(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting crawl site request processing")
+
+	var args struct {
+		URL               string `json:"url"`
+		MaxDepth          *int   `json:"maxDepth"`
+		MaxPages          int    `json:"maxPages"`
+		IncludeSubdomains bool   `json:"includeSubdomains"`
+		PathPrefix        string `json:"pathPrefix"`
+		ExcerptLength     int    `json:"excerptLength"`
+		Format            string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	seed, err := url.Parse(strings.TrimSpace(args.URL))
+	if err != nil || (seed.Scheme != "http" && seed.Scheme != "https") || seed.Host == "" {
+		log.Printf("Error: Invalid URL: %s", args.URL)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "url must be an absolute http or https URL")
+	}
+	opts := crawlOptions{
+		maxDepth:          min(2, s.maxDepth),
+		maxPages:          min(20, s.maxPages),
+		includeSubdomains: args.IncludeSubdomains,
+		pathPrefix:        args.PathPrefix,
+	}
+	if args.MaxDepth != nil {
+		if *args.MaxDepth < 0 || *args.MaxDepth > s.maxDepth {
+			log.Printf("Error: Invalid max depth: %d", *args.MaxDepth)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "maxDepth must be between 0 and %d", s.maxDepth)
+		}
+		opts.maxDepth = *args.MaxDepth
+	}
+	if args.MaxPages != 0 {
+		if args.MaxPages < 0 || args.MaxPages > s.maxPages {
+			log.Printf("Error: Invalid max pages: %d", args.MaxPages)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "maxPages must be between 1 and %d", s.maxPages)
+		}
+		opts.maxPages = args.MaxPages
+	}
+	excerptLength := 500
+	if args.ExcerptLength != 0 {
+		if args.ExcerptLength < 0 || args.ExcerptLength > 5000 {
+			log.Printf("Error: Invalid excerpt length: %d", args.ExcerptLength)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "excerptLength must be between 1 and 5000")
+		}
+		excerptLength = args.ExcerptLength
+	}
+	if args.Format != "" && args.Format != "text" && args.Format != "json" {
+		log.Printf("Error: Invalid format: %s", args.Format)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid format %q; use text or json", args.Format)
+	}
+
+	log.Printf("Crawling %s: maxDepth=%d, maxPages=%d", seed, opts.maxDepth, opts.maxPages)
+	opts.reporter = progress.New(ctx, req, float64(opts.maxPages))
+	result := s.crawl(ctx, seed, opts)
+	for _, p := range result.Pages {
+		p.Text = excerpt(p.Text, excerptLength)
+	}
+
+	log.Printf("Crawl site request completed: %d pages, %d errors, %d blocked by robots.txt",
+		len(result.Pages), len(result.Errors), result.BlockedByRobots)
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+		return textResult(string(data)), nil
+	}
+	// Long summaries are split into pages read with continueResult
+	header, items := formatSummary(result, opts)
+	return s.results.Paginate(header, items, "\n\n", pagesPerResult), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *CrawlerServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the crawler server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("crawler", flag.ContinueOnError)
+	var (
+		timeout     int
+		userAgent   string
+		maxBodySize int64
+		maxPages    int
+		maxDepth    int
+		delay       int
+		maxDuration int
+	)
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.StringVar(&userAgent, "user-agent", "MCP-Crawler-Server/1.0", "User-Agent header, also used to select robots.txt rules")
+	fs.Int64Var(&maxBodySize, "max-body-size", 5*1024*1024, "Maximum size of a page in bytes (default 5MB)")
+	fs.IntVar(&maxPages, "max-pages", 100, "Maximum number of pages per crawl")
+	fs.IntVar(&maxDepth, "max-depth", 5, "Maximum link depth per crawl")
+	fs.IntVar(&delay, "delay", 1000, "Minimum delay between requests to a host in milliseconds")
+	fs.IntVar(&maxDuration, "max-duration", 300, "Maximum duration of a crawl in seconds")
+	var guardFlags urlguard.Flags
+	guardFlags.Register(fs)
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	guard, err := guardFlags.Policy()
+	if err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting crawler server: timeout=%ds, user-agent=%s, max-pages=%d, max-depth=%d, delay=%dms, max-duration=%ds",
+		timeout, userAgent, maxPages, maxDepth, delay, maxDuration)
+
+	// Create CrawlerServer instance
+	crawlerServer := NewCrawlerServer(timeout, userAgent, maxBodySize, maxPages, maxDepth,
+		time.Duration(delay)*time.Millisecond, time.Duration(maxDuration)*time.Second)
+	crawlerServer.client = httpclient.New(httpclient.Options{Timeout: time.Duration(timeout) * time.Second, Guard: guard})
+	log.Println("CrawlerServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), crawlerServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return crawlerServer.Server(), transportFlags, nil
+}
+
+// Run starts the crawler server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[CrawlerServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create crawler server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("CrawlerServer shutdown")
+	return nil
+}
@@ -0,0 +1,328 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSite serves a small website and records the requested paths.
+type testSite struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	robots   string
+	status   int // robots.txt status
+}
+
+func newTestSite(t *testing.T) *testSite {
+	site := &testSite{
+		robots: "User-agent: *\nDisallow: /private\nCrawl-delay: 2\n",
+		status: http.StatusOK,
+	}
+	pages := map[string]string{
+		"/": `<html><head><title>Home</title><meta name="description" content="The  home page"></head>
+			<body><nav><a href="/about">About</a> <a href="/docs/">Docs</a></nav>
+			<h1>Welcome</h1><p>Hello from the home page.</p>
+			<script>var ignored = "script text";</script>
+			<a href="/private/secret">Secret</a> <a href="/logo.png">Logo</a>
+			<a href="https://elsewhere.example/">External</a> <a href="/about#team">Team</a>
+			<a href="/missing">Missing</a> <a href="/old">Old</a></body></html>`,
+		"/about": `<html><head><title>About</title></head><body><h2>About us</h2><p>We build things.</p>
+			<a href="/about/team">Team</a></body></html>`,
+		"/about/team": `<html><head><title>Team</title></head><body><p>Deep page.</p></body></html>`,
+		"/docs/": `<html><head><title>Docs</title><meta name="robots" content="nofollow"></head>
+			<body><p>Documentation.</p><a href="/docs/hidden">Hidden</a></body></html>`,
+		"/docs/hidden": `<html><head><title>Hidden</title></head><body></body></html>`,
+		"/new":         `<html><head><title>New</title></head><body><p>Moved here.</p></body></html>`,
+	}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requests = append(site.requests, r.URL.Path)
+		site.mu.Unlock()
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.WriteHeader(site.status)
+			fmt.Fprint(w, site.robots)
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		default:
+			body, ok := pages[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, body)
+		}
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func (site *testSite) requested() []string {
+	site.mu.Lock()
+	defer site.mu.Unlock()
+	return append([]string(nil), site.requests...)
+}
+
+// newTestServer creates a server with a fake clock that sleeping advances.
+func newTestServer() (*CrawlerServer, *[]time.Duration) {
+	s := NewCrawlerServer(5, "TestBot/1.0", 1024*1024, 50, 5, 500*time.Millisecond, time.Minute)
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	s.now = func() time.Time { return now }
+	s.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	return s, &sleeps
+}
+
+// CrawlerServer creation test
+func TestNewCrawlerServer(t *testing.T) {
+	s := NewCrawlerServer(10, "Bot/2.0", 2048, 30, 4, time.Second, time.Minute)
+
+	assert.NotNil(t, s, "CrawlerServer instance should be created")
+	assert.Equal(t, "Bot/2.0", s.userAgent, "User agent should match")
+	assert.Equal(t, 30, s.maxPages, "Max pages should match")
+	assert.Equal(t, 4, s.maxDepth, "Max depth should match")
+	assert.Equal(t, time.Second, s.delay, "Delay should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s, _ := newTestServer()
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test robots.txt group selection and rule precedence
+func TestParseRobots(t *testing.T) {
+	txt := `# comment
+User-agent: OtherBot
+Disallow: /
+
+User-agent: testbot
+User-agent: SomeBot
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 1.5
+
+User-agent: *
+Disallow: /
+`
+	r := parseRobots(strings.NewReader(txt), "TestBot/1.0")
+	assert.Equal(t, 1500*time.Millisecond, r.crawlDelay)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/private", false},
+		{"/private/x", false},
+		{"/private/public/x", true},
+		{"/files/a.pdf", false},
+		{"/files/a.pdf?x=1", true},
+		{"/search?q=go", false},
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, r.allowed(tt.path), tt.path)
+	}
+
+	// Other crawlers fall back to the * group
+	r = parseRobots(strings.NewReader(txt), "Unknown/1.0")
+	assert.False(t, r.allowed("/anything"))
+
+	// An empty file allows everything
+	assert.True(t, parseRobots(strings.NewReader(""), "TestBot").allowed("/x"))
+}
+
+// Test text, metadata and link extraction
+func TestParsePage(t *testing.T) {
+	base, _ := url.Parse("https://Example.com:443/dir/page")
+	p, err := parsePage(strings.NewReader(`<html><head><title> A  Title </title>
+		<meta name="description" content="Desc"><base href="https://example.com/base/"></head>
+		<body><h1>Main</h1><div>First <b>bold</b> line</div><style>.x{}</style>
+		<ul><li>one</li><li>two</li></ul>
+		<a href="rel">r</a><a href="/abs#frag">a</a><a href="mailto:x@example.com">m</a>
+		<a href="https://example.com:443/abs">dup</a><a href="/skip" rel="nofollow">n</a></body></html>`), base)
+	require.NoError(t, err)
+
+	assert.Equal(t, "A Title", p.Title)
+	assert.Equal(t, "Desc", p.Description)
+	assert.Equal(t, []string{"Main"}, p.Headings)
+	assert.Equal(t, "Main\nFirst bold line\none\ntwo\nram dupn", p.Text)
+	assert.Equal(t, 8, p.Words)
+	assert.Equal(t, []string{"https://example.com/base/rel", "https://example.com/abs"}, p.links)
+}
+
+// Test a breadth first crawl of the test site
+func TestHandleCrawlSite(t *testing.T) {
+	site := newTestSite(t)
+	s, sleeps := newTestServer()
+
+	result, err := s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url":    site.URL,
+		"format": "json",
+	}))
+	require.NoError(t, err)
+
+	var r crawlResult
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &r))
+	var titles []string
+	for _, p := range r.Pages {
+		titles = append(titles, fmt.Sprintf("%s@%d", p.Title, p.Depth))
+	}
+	// /docs/ is nofollow, so /docs/hidden is not crawled
+	assert.Equal(t, []string{"Home@0", "About@1", "Docs@1", "New@1", "Team@2"}, titles)
+	assert.Equal(t, 1, r.BlockedByRobots)
+	require.Len(t, r.Errors, 1)
+	assert.Equal(t, site.URL+"/missing", r.Errors[0].URL)
+	assert.Equal(t, "HTTP 404", r.Errors[0].Error)
+
+	home := r.Pages[0]
+	assert.Equal(t, "The home page", home.Description)
+	assert.Contains(t, home.Text, "Hello from the home page.")
+	assert.NotContains(t, home.Text, "script text")
+
+	// Requests are spaced by the robots.txt crawl delay
+	requested := site.requested()
+	assert.Equal(t, "/robots.txt", requested[0])
+	assert.NotContains(t, requested, "/private/secret")
+	assert.NotContains(t, requested, "/logo.png")
+	assert.NotContains(t, requested, "/docs/hidden")
+	require.NotEmpty(t, *sleeps)
+	for _, d := range *sleeps {
+		assert.Equal(t, 2*time.Second, d)
+	}
+}
+
+// Test the page and depth limits and the text summary
+func TestCrawlLimits(t *testing.T) {
+	site := newTestSite(t)
+	s, _ := newTestServer()
+
+	result, err := s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url":      site.URL,
+		"maxPages": 2,
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.True(t, strings.HasPrefix(text, "Crawled 2 pages starting at "+site.URL+"/ (max depth 2)"), text)
+	assert.Contains(t, text, "Stopped: page limit reached")
+	assert.Contains(t, text, "1. Home — "+site.URL+"/ (depth 0, ")
+	assert.Contains(t, text, "   Description: The home page")
+	assert.Contains(t, text, "2. About — ")
+
+	result, err = s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url":      site.URL,
+		"maxDepth": 0,
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Crawled 1 pages")
+
+	result, err = s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url":        site.URL + "/about",
+		"pathPrefix": "/about",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Crawled 2 pages")
+}
+
+// Test that the crawl duration is bounded
+func TestCrawlTimeLimit(t *testing.T) {
+	site := newTestSite(t)
+	site.robots = "User-agent: *\nCrawl-delay: 40\n"
+	s, _ := newTestServer()
+
+	result, err := s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url": site.URL,
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "Crawled 2 pages")
+	assert.Contains(t, text, "Stopped: time limit reached")
+
+	// The crawl ends within the time left to the call
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s, _ = newTestServer()
+	result, err = s.handleCrawlSite(ctx, mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url": site.URL,
+	}))
+	require.NoError(t, err)
+	text = mcptest.ResultText(result)
+	assert.Contains(t, text, "Crawled 1 pages")
+	assert.Contains(t, text, "Stopped: time limit reached")
+}
+
+// Test that a failing robots.txt blocks the host
+func TestRobotsServerError(t *testing.T) {
+	site := newTestSite(t)
+	site.status = http.StatusServiceUnavailable
+	s, _ := newTestServer()
+
+	result, err := s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", map[string]interface{}{
+		"url": site.URL,
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Crawled 0 pages")
+	assert.Contains(t, mcptest.ResultText(result), "Skipped: 1 disallowed by robots.txt")
+	assert.Equal(t, []string{"/robots.txt"}, site.requested())
+}
+
+// Test the excerpt length
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "short", excerpt("short", 10))
+	assert.Equal(t, "one two…", excerpt("one two three", 9))
+	assert.Equal(t, "ääää…", excerpt("ääääää", 4))
+}
+
+// Test error cases
+func TestErrors(t *testing.T) {
+	s, _ := newTestServer()
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing url", map[string]interface{}{}, "url must be"},
+		{"ftp url", map[string]interface{}{"url": "ftp://example.com/"}, "url must be"},
+		{"relative url", map[string]interface{}{"url": "/docs"}, "url must be"},
+		{"depth too large", map[string]interface{}{"url": "https://example.com", "maxDepth": 6}, "maxDepth must be"},
+		{"negative depth", map[string]interface{}{"url": "https://example.com", "maxDepth": -1}, "maxDepth must be"},
+		{"too many pages", map[string]interface{}{"url": "https://example.com", "maxPages": 51}, "maxPages must be"},
+		{"excerpt too long", map[string]interface{}{"url": "https://example.com", "excerptLength": 5001}, "excerptLength must be"},
+		{"bad format", map[string]interface{}{"url": "https://example.com", "format": "xml"}, "invalid format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleCrawlSite(context.Background(), mcptest.NewCallToolRequest("crawlSite", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	s, _ := newTestServer()
+	mcptest.FuzzTools(f, s.Server())
+}
@@ -0,0 +1,629 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"golang.org/x/crypto/blake2b"
+)
+
+// hashAlgorithms maps supported algorithm names to constructors taking an optional key.
+// Only BLAKE2b accepts a key natively; HMAC is used for keyed hashing with the others.
+var hashAlgorithms = map[string]func(key []byte) (hash.Hash, error){
+	"md5":         func([]byte) (hash.Hash, error) { return md5.New(), nil },
+	"sha1":        func([]byte) (hash.Hash, error) { return sha1.New(), nil },
+	"sha256":      func([]byte) (hash.Hash, error) { return sha256.New(), nil },
+	"sha384":      func([]byte) (hash.Hash, error) { return sha512.New384(), nil },
+	"sha512":      func([]byte) (hash.Hash, error) { return sha512.New(), nil },
+	"blake2b-256": blake2b.New256,
+	"blake2b-512": blake2b.New512,
+}
+
+var algorithmNames = []string{"md5", "sha1", "sha256", "sha384", "sha512", "blake2b-256", "blake2b-512"}
+
+// CryptoServer is an MCP server providing hashing, encoding and token inspection utilities.
+// Inputs, keys and outputs are never written to the log.
+type CryptoServer struct {
+	server         *server.MCPServer
+	maxInputSize   int
+	maxRandomBytes int
+}
+
+// NewCryptoServer creates a new CryptoServer instance
+func NewCryptoServer(maxInputSize, maxRandomBytes int) *CryptoServer {
+	log.Printf("CryptoServer created: maxInputSize=%d, maxRandomBytes=%d", maxInputSize, maxRandomBytes)
+
+	s := &CryptoServer{
+		maxInputSize:   maxInputSize,
+		maxRandomBytes: maxRandomBytes,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"crypto-server", // server name
+		"1.0.0",         // version
+	)
+
+	// Register hash tool
+	hashTool := mcp.NewTool("hash",
+		mcp.WithDescription("Computes a cryptographic digest of the input"),
+		mcp.WithString("input",
+			mcp.Description("Data to hash"),
+			mcp.Required(),
+		),
+		mcp.WithString("algorithm",
+			mcp.Description("Hash algorithm (default: sha256)"),
+			mcp.Enum(algorithmNames...),
+		),
+		mcp.WithString("inputEncoding",
+			mcp.Description("How the input is encoded (default: text)"),
+			mcp.Enum("text", "hex", "base64"),
+		),
+		mcp.WithString("outputEncoding",
+			mcp.Description("How the digest is encoded (default: hex)"),
+			mcp.Enum("hex", "base64"),
+		),
+	)
+
+	// Register hmac tool
+	hmacTool := mcp.NewTool("hmac",
+		mcp.WithDescription("Computes or verifies an HMAC of a message (keyed BLAKE2b for blake2b algorithms)"),
+		mcp.WithString("message",
+			mcp.Description("Message to authenticate"),
+			mcp.Required(),
+		),
+		mcp.WithString("key",
+			mcp.Description("Secret key"),
+			mcp.Required(),
+		),
+		mcp.WithString("algorithm",
+			mcp.Description("Hash algorithm (default: sha256)"),
+			mcp.Enum(algorithmNames...),
+		),
+		mcp.WithString("keyEncoding",
+			mcp.Description("How the key is encoded (default: text)"),
+			mcp.Enum("text", "hex", "base64"),
+		),
+		mcp.WithString("inputEncoding",
+			mcp.Description("How the message is encoded (default: text)"),
+			mcp.Enum("text", "hex", "base64"),
+		),
+		mcp.WithString("outputEncoding",
+			mcp.Description("How the MAC is encoded (default: hex)"),
+			mcp.Enum("hex", "base64"),
+		),
+		mcp.WithString("expected",
+			mcp.Description("Optional MAC to verify against, in the output encoding (compared in constant time)"),
+		),
+	)
+
+	// Register encode tool
+	encodeTool := mcp.NewTool("encode",
+		mcp.WithDescription("Encodes text as base64, base64url, base32 or hex"),
+		mcp.WithString("input",
+			mcp.Description("Text to encode"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Target encoding"),
+			mcp.Enum("base64", "base64url", "base32", "hex"),
+			mcp.Required(),
+		),
+	)
+
+	// Register decode tool
+	decodeTool := mcp.NewTool("decode",
+		mcp.WithDescription("Decodes base64, base64url, base32 or hex. Binary results are returned as hex"),
+		mcp.WithString("input",
+			mcp.Description("Encoded data"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Source encoding"),
+			mcp.Enum("base64", "base64url", "base32", "hex"),
+			mcp.Required(),
+		),
+	)
+
+	// Register decodeJWT tool
+	jwtTool := mcp.NewTool("decodeJWT",
+		mcp.WithDescription("Decodes a JSON Web Token's header and claims. The signature is only verified when an HMAC secret is given"),
+		mcp.WithString("token",
+			mcp.Description("JWT in compact serialization"),
+			mcp.Required(),
+		),
+		mcp.WithString("secret",
+			mcp.Description("Optional HMAC secret to verify HS256/HS384/HS512 signatures"),
+		),
+	)
+
+	// Register randomBytes tool
+	randomTool := mcp.NewTool("randomBytes",
+		mcp.WithDescription("Generates cryptographically secure random bytes"),
+		mcp.WithNumber("length",
+			mcp.Description("Number of bytes (default: 32)"),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Output encoding (default: hex)"),
+			mcp.Enum("hex", "base64", "base64url"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, hashTool, s.handleHash)
+	middleware.AddTool(mcpServer, hmacTool, s.handleHMAC)
+	middleware.AddTool(mcpServer, encodeTool, s.handleEncode)
+	middleware.AddTool(mcpServer, decodeTool, s.handleDecode)
+	middleware.AddTool(mcpServer, jwtTool, s.handleDecodeJWT)
+	middleware.AddTool(mcpServer, randomTool, s.handleRandomBytes)
+
+	s.server = mcpServer
+	return s
+}
+
+// decodeInput converts an input string in the given encoding to bytes.
+func (s *CryptoServer) decodeInput(input, encoding string) ([]byte, error) {
+	if len(input) > s.maxInputSize {
+		return nil, fmt.Errorf("input exceeds the maximum size of %d bytes", s.maxInputSize)
+	}
+	switch encoding {
+	case "", "text":
+		return []byte(input), nil
+	case "hex":
+		return hex.DecodeString(strings.TrimSpace(input))
+	case "base64":
+		return decodeBase64(input)
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported input encoding: %s", encoding)
+	}
+}
+
+// decodeBase64 accepts standard or URL-safe base64, with or without padding.
+func decodeBase64(input string) ([]byte, error) {
+	input = strings.TrimRight(strings.Join(strings.Fields(input), ""), "=")
+	if strings.ContainsAny(input, "-_") {
+		return base64.RawURLEncoding.DecodeString(input)
+	}
+	return base64.RawStdEncoding.DecodeString(input)
+}
+
+// encodeOutput encodes a digest for display.
+func encodeOutput(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case "", "hex":
+		return hex.EncodeToString(data), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	default:
+		return "", fmt.Errorf("unsupported output encoding: %s", encoding)
+	}
+}
+
+// newHash returns the hash for an algorithm name.
+func newHash(algorithm string, key []byte) (hash.Hash, error) {
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	constructor, ok := hashAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported algorithm: %s (supported: %s)", algorithm, strings.Join(algorithmNames, ", "))
+	}
+	return constructor(key)
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleHash handles the digest request.
+func (s *CryptoServer) handleHash(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting hash request processing")
+
+	var args struct {
+		Input          string `json:"input" param:"required,empty"`
+		Algorithm      string `json:"algorithm,omitempty"`
+		InputEncoding  string `json:"inputEncoding,omitempty"`
+		OutputEncoding string `json:"outputEncoding,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	data, err := s.decodeInput(args.Input, args.InputEncoding)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid input: %w", err)
+	}
+	h, err := newHash(args.Algorithm, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(data)
+	digest, err := encodeOutput(h.Sum(nil), args.OutputEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("hash request completed: algorithm=%s, bytes=%d", args.Algorithm, len(data))
+	return textResult(digest), nil
+}
+
+// handleHMAC handles the HMAC request.
+func (s *CryptoServer) handleHMAC(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting hmac request processing")
+
+	var args struct {
+		Message        string `json:"message"`
+		Key            string `json:"key"`
+		Algorithm      string `json:"algorithm,omitempty"`
+		KeyEncoding    string `json:"keyEncoding,omitempty"`
+		InputEncoding  string `json:"inputEncoding,omitempty"`
+		OutputEncoding string `json:"outputEncoding,omitempty"`
+		Expected       string `json:"expected,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	message, err := s.decodeInput(args.Message, args.InputEncoding)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid message: %w", err)
+	}
+	key, err := s.decodeInput(args.Key, args.KeyEncoding)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid key: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "key is required")
+	}
+
+	var mac hash.Hash
+	if strings.HasPrefix(strings.ToLower(args.Algorithm), "blake2b") {
+		// BLAKE2b has a native keyed mode, which is used instead of HMAC
+		mac, err = newHash(args.Algorithm, key)
+	} else if _, err = newHash(args.Algorithm, nil); err == nil {
+		mac = hmac.New(func() hash.Hash {
+			h, _ := newHash(args.Algorithm, nil)
+			return h
+		}, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	encoded, err := encodeOutput(sum, args.OutputEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	text := encoded
+	if args.Expected != "" {
+		var expected []byte
+		if args.OutputEncoding == "base64" {
+			expected, err = decodeBase64(args.Expected)
+		} else {
+			expected, err = hex.DecodeString(strings.TrimSpace(args.Expected))
+		}
+		if err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid expected MAC: %w", err)
+		}
+		if hmac.Equal(sum, expected) {
+			text = "Valid: the MAC matches"
+		} else {
+			text = "Invalid: the MAC does not match\nComputed: " + encoded
+		}
+	}
+
+	log.Printf("hmac request completed: algorithm=%s", args.Algorithm)
+	return textResult(text), nil
+}
+
+// handleEncode handles the encoding request.
+func (s *CryptoServer) handleEncode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting encode request processing")
+
+	var args struct {
+		Input  string `json:"input" param:"required,empty"`
+		Format string `json:"format"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Input) > s.maxInputSize {
+		return nil, fmt.Errorf("input exceeds the maximum size of %d bytes", s.maxInputSize)
+	}
+
+	var encoded string
+	switch args.Format {
+	case "base64":
+		encoded = base64.StdEncoding.EncodeToString([]byte(args.Input))
+	case "base64url":
+		encoded = base64.RawURLEncoding.EncodeToString([]byte(args.Input))
+	case "base32":
+		encoded = base32.StdEncoding.EncodeToString([]byte(args.Input))
+	case "hex":
+		encoded = hex.EncodeToString([]byte(args.Input))
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format: %s", args.Format)
+	}
+
+	log.Printf("encode request completed: format=%s", args.Format)
+	return textResult(encoded), nil
+}
+
+// handleDecode handles the decoding request.
+func (s *CryptoServer) handleDecode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting decode request processing")
+
+	var args struct {
+		Input  string `json:"input" param:"required,empty"`
+		Format string `json:"format"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Input) > s.maxInputSize {
+		return nil, fmt.Errorf("input exceeds the maximum size of %d bytes", s.maxInputSize)
+	}
+
+	var decoded []byte
+	input := strings.Join(strings.Fields(args.Input), "")
+	switch args.Format {
+	case "base64", "base64url":
+		decoded, err = decodeBase64(input)
+	case "base32":
+		decoded, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(input, "=")))
+	case "hex":
+		decoded, err = hex.DecodeString(input)
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format: %s", args.Format)
+	}
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid %s input: %w", args.Format, err)
+	}
+
+	text := string(decoded)
+	if !utf8.Valid(decoded) || bytes.ContainsRune(decoded, 0) {
+		text = fmt.Sprintf("Binary data (%d bytes), shown as hex:\n%s", len(decoded), hex.EncodeToString(decoded))
+	}
+
+	log.Printf("decode request completed: format=%s", args.Format)
+	return textResult(text), nil
+}
+
+// handleDecodeJWT handles the JWT inspection request.
+func (s *CryptoServer) handleDecodeJWT(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting decodeJWT request processing")
+
+	var args struct {
+		Token  string `json:"token"`
+		Secret string `json:"secret,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(args.Token), "Bearer "))
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid JWT: expected 3 dot-separated parts, got %d", len(parts))
+	}
+
+	var header map[string]interface{}
+	var claims map[string]interface{}
+	for i, target := range []*map[string]interface{}{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid JWT %s encoding: %w", []string{"header", "payload"}[i], err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(target); err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid JWT %s JSON: %w", []string{"header", "payload"}[i], err)
+		}
+	}
+
+	var resultContent strings.Builder
+	headerJSON, _ := json.MarshalIndent(header, "", "  ")
+	claimsJSON, _ := json.MarshalIndent(claims, "", "  ")
+	resultContent.WriteString("Header:\n" + string(headerJSON) + "\n\n")
+	resultContent.WriteString("Claims:\n" + string(claimsJSON) + "\n\n")
+
+	now := time.Now()
+	for _, claim := range []string{"iat", "nbf", "exp"} {
+		value, ok := claims[claim].(json.Number)
+		if !ok {
+			continue
+		}
+		seconds, err := value.Float64()
+		if err != nil {
+			continue
+		}
+		t := time.Unix(int64(seconds), 0).UTC()
+		note := ""
+		switch {
+		case claim == "exp" && now.After(t):
+			note = " (expired)"
+		case claim == "nbf" && now.Before(t):
+			note = " (not yet valid)"
+		}
+		resultContent.WriteString(fmt.Sprintf("%s: %s%s\n", claim, t.Format(time.RFC3339), note))
+	}
+
+	alg, _ := header["alg"].(string)
+	signature := "not verified"
+	if args.Secret != "" {
+		algorithms := map[string]string{"HS256": "sha256", "HS384": "sha384", "HS512": "sha512"}
+		hashName, ok := algorithms[alg]
+		if !ok {
+			return nil, fmt.Errorf("cannot verify %q signatures with a secret; only HS256, HS384 and HS512 are supported", alg)
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+		if err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid JWT signature encoding: %w", err)
+		}
+		mac := hmac.New(func() hash.Hash {
+			h, _ := newHash(hashName, nil)
+			return h
+		}, []byte(args.Secret))
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		if hmac.Equal(mac.Sum(nil), sig) {
+			signature = "valid"
+		} else {
+			signature = "INVALID"
+		}
+	}
+	resultContent.WriteString(fmt.Sprintf("Signature (%s): %s\n", alg, signature))
+
+	log.Printf("decodeJWT request completed: alg=%s, verified=%v", alg, args.Secret != "")
+	return textResult(resultContent.String()), nil
+}
+
+// handleRandomBytes handles the random bytes request.
+func (s *CryptoServer) handleRandomBytes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting randomBytes request processing")
+
+	var args struct {
+		Length   int    `json:"length,omitempty"`
+		Encoding string `json:"encoding,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Length <= 0 {
+		args.Length = 32
+	}
+	if args.Length > s.maxRandomBytes {
+		return nil, fmt.Errorf("length %d exceeds the maximum of %d bytes", args.Length, s.maxRandomBytes)
+	}
+
+	buf := make([]byte, args.Length)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("Error: Failed to read random data: %v", err)
+		return nil, fmt.Errorf("failed to read random data: %w", err)
+	}
+	encoded, err := encodeOutput(buf, args.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("randomBytes request completed: length=%d", args.Length)
+	return textResult(encoded), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *CryptoServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the crypto server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("crypto", flag.ContinueOnError)
+	var (
+		maxInputSize   int
+		maxRandomBytes int
+	)
+	fs.IntVar(&maxInputSize, "max-input-size", 10*1024*1024, "Maximum input size in bytes (default 10MB)")
+	fs.IntVar(&maxRandomBytes, "max-random-bytes", 4096, "Maximum number of random bytes per request")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Crypto server: maxInputSize=%d", maxInputSize)
+
+	// Create CryptoServer instance
+	cryptoServer := NewCryptoServer(maxInputSize, maxRandomBytes)
+	log.Println("CryptoServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), cryptoServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return cryptoServer.Server(), transportFlags, nil
+}
+
+// Run starts the crypto server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[CryptoServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create crypto server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("CryptoServer shutdown")
+	return nil
+}
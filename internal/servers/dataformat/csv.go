@@ -0,0 +1,204 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSV is mapped to an array of objects keyed by the header row. Values are read as
+// strings; nested values are flattened to dotted keys when writing.
+
+// parseDelimiter returns the field delimiter, defaulting to a comma.
+func parseDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "", ",":
+		return ',', nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q", delimiter)
+	}
+	return r, nil
+}
+
+// decodeCSV parses CSV with a header row into an array of objects.
+func decodeCSV(data []byte, delimiter rune) (interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return []interface{}{}, nil
+		}
+		return nil, err
+	}
+
+	// Name empty columns and make duplicate names unique
+	seen := make(map[string]int)
+	for i, name := range header {
+		if name == "" {
+			name = fmt.Sprintf("column%d", i+1)
+		}
+		if seen[name]++; seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		header[i] = name
+	}
+
+	rows := []interface{}{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) > len(header) {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("line %d: row has %d fields, but the header has %d", line, len(record), len(header))
+		}
+		row := newObject()
+		for i, name := range header {
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			row.set(name, value)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// flatten adds the scalar values of v to row, joining nested keys with dots. Arrays are
+// written as JSON.
+func flatten(row *object, prefix string, v interface{}) {
+	obj, ok := v.(*object)
+	if !ok || obj.len() == 0 {
+		row.set(prefix, v)
+		return
+	}
+	for _, key := range obj.keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		flatten(row, name, obj.values[key])
+	}
+}
+
+// encodeCSV writes an array of objects, or a single object, as CSV with a header row.
+// An array of arrays is written as plain rows without a header.
+func encodeCSV(v interface{}, delimiter rune) ([]byte, error) {
+	var items []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		items = v
+	case *object:
+		items = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("CSV requires an array of objects, not %s", describeValue(v))
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = delimiter
+
+	if len(items) > 0 {
+		if _, ok := items[0].([]interface{}); ok {
+			for _, item := range items {
+				values, ok := item.([]interface{})
+				if !ok {
+					return nil, errors.New("CSV rows must be either all arrays or all objects")
+				}
+				record := make([]string, len(values))
+				for i, value := range values {
+					record[i] = scalarString(value)
+				}
+				if err := w.Write(record); err != nil {
+					return nil, err
+				}
+			}
+			w.Flush()
+			return b.Bytes(), w.Error()
+		}
+	}
+
+	// Collect the columns of all rows in order of first appearance
+	rows := make([]*object, len(items))
+	var header []string
+	columns := make(map[string]bool)
+	for i, item := range items {
+		obj, ok := item.(*object)
+		if !ok {
+			return nil, fmt.Errorf("CSV rows must be objects, but row %d is %s", i+1, describeValue(item))
+		}
+		rows[i] = newObject()
+		flatten(rows[i], "", obj)
+		for _, key := range rows[i].keys {
+			if !columns[key] {
+				columns[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, key := range header {
+			if value, ok := row.get(key); ok {
+				record[i] = scalarString(value)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+// detectDelimiter returns the delimiter that occurs equally often in the first lines of
+// text, which is used for format detection.
+func detectDelimiter(text string) (rune, bool) {
+	lines := strings.SplitN(text, "\n", 6)
+	if len(lines) == 6 {
+		// The last element holds the rest of the text
+		lines = lines[:5]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) < 2 {
+		return 0, false
+	}
+	for _, delimiter := range []rune{',', '\t', ';', '|'} {
+		count := strings.Count(lines[0], string(delimiter))
+		if count == 0 {
+			continue
+		}
+		consistent := true
+		for _, line := range lines[1:] {
+			if strings.Count(line, string(delimiter)) != count {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return delimiter, true
+		}
+	}
+	return 0, false
+}
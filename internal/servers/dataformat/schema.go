@@ -0,0 +1,659 @@
+package dataformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A JSON Schema validator covering the assertions of drafts 4 to 2020-12 that matter for
+// validating documents: types, enums, numeric and string limits, formats, object and array
+// keywords, combinators, conditionals and local $ref pointers. Remote references and
+// dynamic references are not supported.
+
+// maxSchemaErrors limits how many validation errors are reported.
+const maxSchemaErrors = 100
+
+// maxRefDepth limits nested $ref resolution to stop reference cycles.
+const maxRefDepth = 64
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+)
+
+// schemaError is a validation failure at a location in the document.
+type schemaError struct {
+	Path    string
+	Message string
+}
+
+func (e schemaError) String() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+type schemaValidator struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// validateSchema validates a document against a schema and returns the failures.
+func validateSchema(schema, document interface{}) ([]schemaError, error) {
+	v := &schemaValidator{root: schema, patterns: make(map[string]*regexp.Regexp)}
+	errs, err := v.validate(schema, document, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > maxSchemaErrors {
+		errs = errs[:maxSchemaErrors]
+	}
+	return errs, nil
+}
+
+// pointerEscape escapes a key for use in a JSON pointer.
+func pointerEscape(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// resolveRef resolves a local reference such as "#/$defs/address".
+func (v *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references starting with # are supported", ref)
+	}
+	unescaped, err := url.PathUnescape(ref[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q", ref)
+	}
+	current := v.root
+	for _, part := range strings.Split(unescaped, "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case *object:
+			next, ok := node.get(part)
+			if !ok {
+				return nil, fmt.Errorf("$ref %q does not resolve", ref)
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("$ref %q does not resolve", ref)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+	}
+	return current, nil
+}
+
+func (v *schemaValidator) regexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("schema pattern %q is not supported: %w", pattern, err)
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// jsonType returns the JSON Schema type name of a value.
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string, datetime:
+		return "string"
+	case []interface{}:
+		return "array"
+	case *object:
+		return "object"
+	case json.Number:
+		if isInteger(value) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "number"
+}
+
+func toRat(value interface{}) (*big.Rat, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(string(number))
+	return r, ok
+}
+
+func isInteger(number json.Number) bool {
+	r, ok := toRat(number)
+	return ok && r.IsInt()
+}
+
+// equalValues compares values as JSON Schema does, with numbers compared by value.
+func equalValues(a, b interface{}) bool {
+	if ra, ok := toRat(a); ok {
+		rb, ok := toRat(b)
+		return ok && ra.Cmp(rb) == 0
+	}
+	if da, ok := a.(datetime); ok {
+		a = string(da)
+	}
+	if db, ok := b.(datetime); ok {
+		b = string(db)
+	}
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case *object:
+		b, ok := b.(*object)
+		if !ok || a.len() != b.len() {
+			return false
+		}
+		for _, key := range a.keys {
+			other, ok := b.get(key)
+			if !ok || !equalValues(a.values[key], other) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		bf, ok := b.(float64)
+		return ok && (a == bf || math.IsNaN(a) && math.IsNaN(bf))
+	}
+	return a == b
+}
+
+func formatValue(value interface{}) string {
+	data, err := encodeJSON(value, "")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(data) > 60 {
+		return string(data[:60]) + "..."
+	}
+	return string(data)
+}
+
+// validate checks value against schema and returns the failures found. Errors are only
+// returned for schemas that cannot be evaluated.
+func (v *schemaValidator) validate(schema, value interface{}, path string, refDepth int) ([]schemaError, error) {
+	switch schema := schema.(type) {
+	case bool:
+		if !schema {
+			return []schemaError{{path, "no value is allowed here (schema is false)"}}, nil
+		}
+		return nil, nil
+	case *object:
+		return v.validateObject(schema, value, path, refDepth)
+	}
+	return nil, fmt.Errorf("invalid schema at %q: expected an object or boolean", path)
+}
+
+func (v *schemaValidator) validateObject(schema *object, value interface{}, path string, refDepth int) ([]schemaError, error) {
+	var errs []schemaError
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, schemaError{path, fmt.Sprintf(format, args...)})
+	}
+	sub := func(s, val interface{}, p string) error {
+		subErrs, err := v.validate(s, val, p, refDepth)
+		errs = append(errs, subErrs...)
+		return err
+	}
+	keyword := func(name string) (interface{}, bool) {
+		return schema.get(name)
+	}
+
+	if ref, ok := keyword("$ref"); ok {
+		refString, _ := ref.(string)
+		if refDepth >= maxRefDepth {
+			return nil, fmt.Errorf("$ref %q is nested more than %d levels deep", refString, maxRefDepth)
+		}
+		target, err := v.resolveRef(refString)
+		if err != nil {
+			return nil, err
+		}
+		refErrs, err := v.validate(target, value, path, refDepth+1)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, refErrs...)
+	}
+
+	if types, ok := keyword("type"); ok {
+		actual := jsonType(value)
+		var allowed []string
+		switch types := types.(type) {
+		case string:
+			allowed = []string{types}
+		case []interface{}:
+			for _, t := range types {
+				if name, ok := t.(string); ok {
+					allowed = append(allowed, name)
+				}
+			}
+		}
+		matched := false
+		for _, t := range allowed {
+			if t == actual || (t == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(allowed, " or "), actual)
+		}
+	}
+
+	if enum, ok := keyword("enum"); ok {
+		if options, ok := enum.([]interface{}); ok {
+			found := false
+			for _, option := range options {
+				if equalValues(option, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				names := make([]string, len(options))
+				for i, option := range options {
+					names[i] = formatValue(option)
+				}
+				fail("value %s is not one of %s", formatValue(value), strings.Join(names, ", "))
+			}
+		}
+	}
+	if constant, ok := keyword("const"); ok && !equalValues(constant, value) {
+		fail("value must be %s", formatValue(constant))
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		v.validateNumber(schema, value, fail)
+	case string:
+		if err := v.validateString(schema, value, fail); err != nil {
+			return nil, err
+		}
+	case datetime:
+		if err := v.validateString(schema, string(value), fail); err != nil {
+			return nil, err
+		}
+	case []interface{}:
+		if err := v.validateArray(schema, value, path, refDepth, fail, sub); err != nil {
+			return nil, err
+		}
+	case *object:
+		if err := v.validateProperties(schema, value, path, fail, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	// Combinators
+	if all, ok := keyword("allOf"); ok {
+		for _, s := range asSlice(all) {
+			if err := sub(s, value, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range []string{"anyOf", "oneOf"} {
+		options, ok := keyword(name)
+		if !ok {
+			continue
+		}
+		matches := 0
+		for _, s := range asSlice(options) {
+			optionErrs, err := v.validate(s, value, path, refDepth)
+			if err != nil {
+				return nil, err
+			}
+			if len(optionErrs) == 0 {
+				matches++
+			}
+		}
+		switch {
+		case name == "anyOf" && matches == 0:
+			fail("value does not match any of the anyOf schemas")
+		case name == "oneOf" && matches != 1:
+			fail("value matches %d of the oneOf schemas, expected exactly 1", matches)
+		}
+	}
+	if not, ok := keyword("not"); ok {
+		notErrs, err := v.validate(not, value, path, refDepth)
+		if err != nil {
+			return nil, err
+		}
+		if len(notErrs) == 0 {
+			fail("value must not match the schema in not")
+		}
+	}
+	if condition, ok := keyword("if"); ok {
+		conditionErrs, err := v.validate(condition, value, path, refDepth)
+		if err != nil {
+			return nil, err
+		}
+		branch := "else"
+		if len(conditionErrs) == 0 {
+			branch = "then"
+		}
+		if s, ok := keyword(branch); ok {
+			if err := sub(s, value, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return errs, nil
+}
+
+func asSlice(value interface{}) []interface{} {
+	items, _ := value.([]interface{})
+	return items
+}
+
+func schemaNumber(schema *object, name string) (*big.Rat, bool) {
+	value, ok := schema.get(name)
+	if !ok {
+		return nil, false
+	}
+	return toRat(value)
+}
+
+func schemaInt(schema *object, name string) (int, bool) {
+	r, ok := schemaNumber(schema, name)
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return int(r.Num().Int64()), true
+}
+
+func (v *schemaValidator) validateNumber(schema *object, value json.Number, fail func(string, ...interface{})) {
+	n, ok := toRat(value)
+	if !ok {
+		return
+	}
+	// Draft 4 uses booleans for exclusiveMinimum and exclusiveMaximum
+	exclusiveMin, _ := schema.get("exclusiveMinimum")
+	exclusiveMax, _ := schema.get("exclusiveMaximum")
+
+	if minimum, ok := schemaNumber(schema, "minimum"); ok {
+		if exclusiveMin == true && n.Cmp(minimum) <= 0 {
+			fail("%s must be greater than %s", value, minimum.RatString())
+		} else if n.Cmp(minimum) < 0 {
+			fail("%s is less than the minimum of %s", value, minimum.RatString())
+		}
+	}
+	if maximum, ok := schemaNumber(schema, "maximum"); ok {
+		if exclusiveMax == true && n.Cmp(maximum) >= 0 {
+			fail("%s must be less than %s", value, maximum.RatString())
+		} else if n.Cmp(maximum) > 0 {
+			fail("%s is greater than the maximum of %s", value, maximum.RatString())
+		}
+	}
+	if limit, ok := toRat(exclusiveMin); ok && n.Cmp(limit) <= 0 {
+		fail("%s must be greater than %s", value, limit.RatString())
+	}
+	if limit, ok := toRat(exclusiveMax); ok && n.Cmp(limit) >= 0 {
+		fail("%s must be less than %s", value, limit.RatString())
+	}
+	if divisor, ok := schemaNumber(schema, "multipleOf"); ok && divisor.Sign() > 0 {
+		if !new(big.Rat).Quo(n, divisor).IsInt() {
+			fail("%s is not a multiple of %s", value, divisor.RatString())
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema *object, value string, fail func(string, ...interface{})) error {
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := schemaInt(schema, "minLength"); ok && length < minLength {
+		fail("string is %d characters long, shorter than the minimum of %d", length, minLength)
+	}
+	if maxLength, ok := schemaInt(schema, "maxLength"); ok && length > maxLength {
+		fail("string is %d characters long, longer than the maximum of %d", length, maxLength)
+	}
+	if pattern, ok := schema.get("pattern"); ok {
+		if text, ok := pattern.(string); ok {
+			re, err := v.regexp(text)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(value) {
+				fail("string %s does not match the pattern %s", formatValue(value), text)
+			}
+		}
+	}
+	if format, ok := schema.get("format"); ok {
+		if name, ok := format.(string); ok && !validFormat(name, value) {
+			fail("string %s is not a valid %s", formatValue(value), name)
+		}
+	}
+	return nil
+}
+
+// validFormat checks the common string formats. Unknown formats are accepted.
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(value))
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "time":
+		for _, layout := range []string{"15:04:05Z07:00", "15:04:05.999999999Z07:00"} {
+			if _, err := time.Parse(layout, strings.ToUpper(value)); err == nil {
+				return true
+			}
+		}
+		return false
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "hostname":
+		return len(value) <= 253 && hostnamePattern.MatchString(value)
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		return net.ParseIP(value) != nil && strings.Contains(value, ":")
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "uri-reference":
+		_, err := url.Parse(value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "regex":
+		_, err := regexp.Compile(value)
+		return err == nil
+	}
+	return true
+}
+
+func (v *schemaValidator) validateArray(schema *object, value []interface{}, path string, refDepth int,
+	fail func(string, ...interface{}), sub func(interface{}, interface{}, string) error) error {
+	if minItems, ok := schemaInt(schema, "minItems"); ok && len(value) < minItems {
+		fail("array has %d items, fewer than the minimum of %d", len(value), minItems)
+	}
+	if maxItems, ok := schemaInt(schema, "maxItems"); ok && len(value) > maxItems {
+		fail("array has %d items, more than the maximum of %d", len(value), maxItems)
+	}
+	if unique, _ := schema.get("uniqueItems"); unique == true {
+	duplicates:
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if equalValues(value[i], value[j]) {
+					fail("items %d and %d are equal, but items must be unique", i, j)
+					break duplicates
+				}
+			}
+		}
+	}
+
+	// prefixItems (2020-12) or an items array (earlier drafts) validate by position
+	prefix := 0
+	prefixSchemas, hasPrefix := schema.get("prefixItems")
+	items, hasItems := schema.get("items")
+	rest, hasRest := items, hasItems
+	if !hasPrefix {
+		if tuple, ok := items.([]interface{}); ok {
+			prefixSchemas, hasPrefix = tuple, true
+			rest, hasRest = schema.get("additionalItems")
+		}
+	}
+	if hasPrefix {
+		for i, s := range asSlice(prefixSchemas) {
+			if i >= len(value) {
+				break
+			}
+			if err := sub(s, value[i], path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+			prefix = i + 1
+		}
+	}
+	if hasRest {
+		for i := prefix; i < len(value); i++ {
+			if err := sub(rest, value[i], path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if contains, ok := schema.get("contains"); ok {
+		matches := 0
+		for _, item := range value {
+			itemErrs, err := v.validate(contains, item, path, refDepth)
+			if err != nil {
+				return err
+			}
+			if len(itemErrs) == 0 {
+				matches++
+			}
+		}
+		minContains, ok := schemaInt(schema, "minContains")
+		if !ok {
+			minContains = 1
+		}
+		if matches < minContains {
+			fail("array contains %d matching items, fewer than the minimum of %d", matches, minContains)
+		}
+		if maxContains, ok := schemaInt(schema, "maxContains"); ok && matches > maxContains {
+			fail("array contains %d matching items, more than the maximum of %d", matches, maxContains)
+		}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateProperties(schema *object, value *object, path string,
+	fail func(string, ...interface{}), sub func(interface{}, interface{}, string) error) error {
+	if minProperties, ok := schemaInt(schema, "minProperties"); ok && value.len() < minProperties {
+		fail("object has %d properties, fewer than the minimum of %d", value.len(), minProperties)
+	}
+	if maxProperties, ok := schemaInt(schema, "maxProperties"); ok && value.len() > maxProperties {
+		fail("object has %d properties, more than the maximum of %d", value.len(), maxProperties)
+	}
+	if required, ok := schema.get("required"); ok {
+		for _, name := range asSlice(required) {
+			if key, ok := name.(string); ok {
+				if _, exists := value.get(key); !exists {
+					fail("missing required property %q", key)
+				}
+			}
+		}
+	}
+	if dependent, ok := schema.get("dependentRequired"); ok {
+		if dependencies, ok := dependent.(*object); ok {
+			for _, key := range dependencies.keys {
+				if _, exists := value.get(key); !exists {
+					continue
+				}
+				for _, name := range asSlice(dependencies.values[key]) {
+					if other, ok := name.(string); ok {
+						if _, exists := value.get(other); !exists {
+							fail("property %q is required when %q is present", other, key)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	properties, _ := schema.get("properties")
+	propertySchemas, _ := properties.(*object)
+	patternProperties, _ := schema.get("patternProperties")
+	patternSchemas, _ := patternProperties.(*object)
+	additional, hasAdditional := schema.get("additionalProperties")
+	propertyNames, hasPropertyNames := schema.get("propertyNames")
+
+	for _, key := range value.keys {
+		childPath := path + "/" + pointerEscape(key)
+		child := value.values[key]
+		matched := false
+		if propertySchemas != nil {
+			if s, ok := propertySchemas.get(key); ok {
+				matched = true
+				if err := sub(s, child, childPath); err != nil {
+					return err
+				}
+			}
+		}
+		if patternSchemas != nil {
+			for _, pattern := range patternSchemas.keys {
+				re, err := v.regexp(pattern)
+				if err != nil {
+					return err
+				}
+				if re.MatchString(key) {
+					matched = true
+					if err := sub(patternSchemas.values[pattern], child, childPath); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if !matched && hasAdditional {
+			if additional == false {
+				fail("property %q is not allowed", key)
+			} else if err := sub(additional, child, childPath); err != nil {
+				return err
+			}
+		}
+		if hasPropertyNames {
+			if err := sub(propertyNames, key, childPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
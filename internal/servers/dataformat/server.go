@@ -0,0 +1,464 @@
+package dataformat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+var formatNames = []string{"json", "yaml", "toml", "xml", "csv"}
+
+// tomlStart matches a first line that only TOML would start with: a table header or a
+// key = value pair.
+var tomlStart = regexp.MustCompile(`^(\[\[?\s*[A-Za-z0-9_."' -]+\]\]?\s*(#.*)?$|[A-Za-z0-9_."'-]+(\s*\.\s*[A-Za-z0-9_"'-]+)*\s*=)`)
+
+// DataFormatServer is an MCP server converting, formatting and validating structured data.
+type DataFormatServer struct {
+	server       *server.MCPServer
+	maxInputSize int
+}
+
+// NewDataFormatServer creates a new DataFormatServer instance
+func NewDataFormatServer(maxInputSize int) *DataFormatServer {
+	log.Printf("DataFormatServer created: maxInputSize=%d", maxInputSize)
+
+	s := &DataFormatServer{
+		maxInputSize: maxInputSize,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"dataformat-server", // server name
+		"1.0.0",             // version
+	)
+
+	// Register convert tool
+	convertTool := mcp.NewTool("convert",
+		mcp.WithDescription("Converts structured data between JSON, YAML, TOML, XML and CSV. Key order is preserved unless sortKeys is set"),
+		mcp.WithString("input",
+			mcp.Description("Data to convert"),
+			mcp.Required(),
+		),
+		mcp.WithString("from",
+			mcp.Description("Input format (default: detected from the input)"),
+			mcp.Enum(formatNames...),
+		),
+		mcp.WithString("to",
+			mcp.Description("Output format"),
+			mcp.Enum(formatNames...),
+			mcp.Required(),
+		),
+		mcp.WithNumber("indent",
+			mcp.Description("Spaces per indentation level for JSON, YAML and XML (default: 2, 0 for compact JSON and XML)"),
+		),
+		mcp.WithBoolean("sortKeys",
+			mcp.Description("Sort object keys alphabetically (default: false)"),
+		),
+		mcp.WithString("delimiter",
+			mcp.Description("CSV field delimiter, such as , ; | or tab (default: detected for input, comma for output)"),
+		),
+	)
+
+	// Register prettyPrint tool
+	prettyTool := mcp.NewTool("prettyPrint",
+		mcp.WithDescription("Reformats JSON, YAML, TOML, XML or CSV with consistent indentation, optionally sorting keys or minifying. Comments are not preserved"),
+		mcp.WithString("input",
+			mcp.Description("Data to format"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Data format (default: detected from the input)"),
+			mcp.Enum(formatNames...),
+		),
+		mcp.WithNumber("indent",
+			mcp.Description("Spaces per indentation level (default: 2, 0 to minify JSON and XML)"),
+		),
+		mcp.WithBoolean("sortKeys",
+			mcp.Description("Sort object keys alphabetically (default: false)"),
+		),
+		mcp.WithString("delimiter",
+			mcp.Description("CSV field delimiter (default: detected)"),
+		),
+	)
+
+	// Register jsonValidate tool
+	validateTool := mcp.NewTool("jsonValidate",
+		mcp.WithDescription("Checks that data is well-formed, reporting the line and column of syntax errors, and optionally validates it against a JSON Schema"),
+		mcp.WithString("input",
+			mcp.Description("Document to validate"),
+			mcp.Required(),
+		),
+		mcp.WithString("schema",
+			mcp.Description("Optional JSON Schema (drafts 4 to 2020-12, local $ref only)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Document format (default: json). Other formats are converted before schema validation"),
+			mcp.Enum(formatNames...),
+		),
+	)
+
+	middleware.AddTool(mcpServer, convertTool, s.handleConvert)
+	middleware.AddTool(mcpServer, prettyTool, s.handlePrettyPrint)
+	middleware.AddTool(mcpServer, validateTool, s.handleJSONValidate)
+
+	s.server = mcpServer
+	return s
+}
+
+// detectFormat guesses the format of a document from its first characters.
+func detectFormat(input string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(input, "\ufeff"))
+	firstLine := text
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			firstLine = line
+			break
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(text, "{"):
+		return "json"
+	case strings.HasPrefix(text, "["):
+		if tomlStart.MatchString(firstLine) && !json.Valid([]byte(text)) {
+			return "toml"
+		}
+		return "json"
+	case strings.HasPrefix(text, "<"):
+		return "xml"
+	case tomlStart.MatchString(firstLine):
+		return "toml"
+	case !strings.Contains(firstLine, ": ") && !strings.HasPrefix(firstLine, "- "):
+		if _, ok := detectDelimiter(text); ok {
+			return "csv"
+		}
+	}
+	return "yaml"
+}
+
+// decode parses input in the given format. Warnings describe ambiguities in the input.
+func decode(format, input, delimiter string) (interface{}, []string, error) {
+	data := []byte(input)
+	switch format {
+	case "json":
+		return decodeJSON(data)
+	case "yaml":
+		value, err := decodeYAML(data)
+		return value, nil, err
+	case "toml":
+		value, err := decodeTOML(data)
+		return value, nil, err
+	case "xml":
+		value, err := decodeXML(data)
+		return value, nil, err
+	case "csv":
+		comma := ','
+		if delimiter != "" {
+			var err error
+			if comma, err = parseDelimiter(delimiter); err != nil {
+				return nil, nil, err
+			}
+		} else if detected, ok := detectDelimiter(input); ok {
+			comma = detected
+		}
+		value, err := decodeCSV(data, comma)
+		return value, nil, err
+	}
+	return nil, nil, fmt.Errorf("unsupported format: %s (supported: %s)", format, strings.Join(formatNames, ", "))
+}
+
+// encode writes value in the given format.
+func encode(format string, value interface{}, indent int, delimiter string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := encodeJSON(value, strings.Repeat(" ", indent))
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "yaml":
+		if indent < 2 {
+			indent = 2
+		}
+		return encodeYAML(value, indent)
+	case "toml":
+		return encodeTOML(value)
+	case "xml":
+		return encodeXML(value, strings.Repeat(" ", indent))
+	case "csv":
+		comma, err := parseDelimiter(delimiter)
+		if err != nil {
+			return nil, err
+		}
+		return encodeCSV(value, comma)
+	}
+	return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format: %s (supported: %s)", format, strings.Join(formatNames, ", "))
+}
+
+// formatOptions holds the output options shared by convert and prettyPrint.
+type formatOptions struct {
+	Indent    *int   `json:"indent,omitempty"`
+	SortKeys  bool   `json:"sortKeys,omitempty"`
+	Delimiter string `json:"delimiter,omitempty"`
+}
+
+func (o formatOptions) indent() int {
+	if o.Indent == nil {
+		return 2
+	}
+	return max(0, min(*o.Indent, 8))
+}
+
+// transform decodes input, applies the options and encodes it in the target format.
+func (s *DataFormatServer) transform(input, from, to string, options formatOptions) (string, error) {
+	if len(input) > s.maxInputSize {
+		return "", fmt.Errorf("input exceeds the maximum size of %d bytes", s.maxInputSize)
+	}
+	if strings.TrimSpace(input) == "" {
+		return "", fmt.Errorf("input is empty")
+	}
+	if from == "" {
+		from = detectFormat(input)
+	}
+
+	value, _, err := decode(from, input, options.Delimiter)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s input: %w", strings.ToUpper(from), err)
+	}
+	if options.SortKeys {
+		sortKeys(value)
+	}
+
+	// CSV output keeps the delimiter of CSV input unless another one is requested
+	delimiter := options.Delimiter
+	if delimiter == "" && from == "csv" {
+		if comma, ok := detectDelimiter(input); ok {
+			delimiter = string(comma)
+		}
+	}
+	output, err := encode(to, value, options.indent(), delimiter)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", strings.ToUpper(to), err)
+	}
+	return string(output), nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleConvert handles the format conversion request.
+func (s *DataFormatServer) handleConvert(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting convert request processing")
+
+	var args struct {
+		formatOptions
+		Input string `json:"input" param:"required,empty"`
+		From  string `json:"from,omitempty"`
+		To    string `json:"to"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.To == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "to is required")
+	}
+
+	output, err := s.transform(args.Input, strings.ToLower(args.From), strings.ToLower(args.To), args.formatOptions)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("convert request completed: from=%s, to=%s, bytes=%d", args.From, args.To, len(output))
+	return textResult(output), nil
+}
+
+// handlePrettyPrint handles the formatting request.
+func (s *DataFormatServer) handlePrettyPrint(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting prettyPrint request processing")
+
+	var args struct {
+		formatOptions
+		Input  string `json:"input" param:"required,empty"`
+		Format string `json:"format,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	format := strings.ToLower(args.Format)
+	if format == "" {
+		format = detectFormat(args.Input)
+	}
+
+	output, err := s.transform(args.Input, format, format, args.formatOptions)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	log.Printf("prettyPrint request completed: format=%s, bytes=%d", format, len(output))
+	return textResult(output), nil
+}
+
+// handleJSONValidate handles the validation request.
+func (s *DataFormatServer) handleJSONValidate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting jsonValidate request processing")
+
+	var args struct {
+		Input  string `json:"input" param:"required,empty"`
+		Schema string `json:"schema,omitempty"`
+		Format string `json:"format,omitempty"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Input) > s.maxInputSize || len(args.Schema) > s.maxInputSize {
+		return nil, fmt.Errorf("input exceeds the maximum size of %d bytes", s.maxInputSize)
+	}
+	format := strings.ToLower(args.Format)
+	if format == "" {
+		format = "json"
+	}
+	name := strings.ToUpper(format)
+
+	// Parse the schema first, since an unusable schema is a request error
+	var schema interface{}
+	if strings.TrimSpace(args.Schema) != "" {
+		schema, _, err = decodeJSON([]byte(args.Schema))
+		if err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid schema: %w", err)
+		}
+	}
+
+	document, warnings, err := decode(format, args.Input, "")
+	if err != nil {
+		log.Printf("jsonValidate request completed: invalid %s", name)
+		return textResult(fmt.Sprintf("Invalid %s: %v", name, err)), nil
+	}
+
+	var b strings.Builder
+	if schema == nil {
+		fmt.Fprintf(&b, "Valid %s: %s", name, describeValue(document))
+	} else {
+		violations, err := validateSchema(schema, document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate schema: %w", err)
+		}
+		if len(violations) == 0 {
+			fmt.Fprintf(&b, "Valid: the %s document matches the schema", name)
+		} else {
+			if len(violations) == 1 {
+				b.WriteString("Invalid: 1 schema violation")
+			} else {
+				fmt.Fprintf(&b, "Invalid: %d schema violations", len(violations))
+				if len(violations) == maxSchemaErrors {
+					b.WriteString(" (showing the first ones)")
+				}
+			}
+			for _, violation := range violations {
+				b.WriteString("\n- " + violation.String())
+			}
+		}
+	}
+	if len(warnings) > 0 {
+		b.WriteString("\n\nWarnings:\n- " + strings.Join(warnings, "\n- "))
+	}
+
+	log.Printf("jsonValidate request completed: format=%s", format)
+	return textResult(b.String()), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *DataFormatServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the dataformat server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("dataformat", flag.ContinueOnError)
+	var maxInputSize int
+	fs.IntVar(&maxInputSize, "max-input-size", 10*1024*1024, "Maximum input size in bytes (default 10MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting data format server: maxInputSize=%d", maxInputSize)
+
+	// Create DataFormatServer instance
+	dataFormatServer := NewDataFormatServer(maxInputSize)
+	log.Println("DataFormatServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), dataFormatServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return dataFormatServer.Server(), transportFlags, nil
+}
+
+// Run starts the dataformat server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[DataFormatServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create dataformat server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("DataFormatServer shutdown")
+	return nil
+}
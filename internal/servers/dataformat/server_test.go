@@ -0,0 +1,503 @@
+package dataformat
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DataFormatServer creation test
+func TestNewDataFormatServer(t *testing.T) {
+	s := NewDataFormatServer(1024)
+
+	assert.NotNil(t, s, "DataFormatServer instance should be created")
+	assert.Equal(t, 1024, s.maxInputSize, "Max input size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewDataFormatServer(1024)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+func mustJSON(t *testing.T, input string) interface{} {
+	value, _, err := decodeJSON([]byte(input))
+	require.NoError(t, err)
+	return value
+}
+
+func compactJSON(t *testing.T, value interface{}) string {
+	data, err := encodeJSON(value, "")
+	require.NoError(t, err)
+	return string(data)
+}
+
+// Test JSON decoding and encoding
+func TestJSON(t *testing.T) {
+	value := mustJSON(t, `{"z": 1, "a": [1.50, 12345678901234567890, true, null], "m": {"<tag>": "line\nbreak"}}`)
+	assert.Equal(t, `{"z":1,"a":[1.50,12345678901234567890,true,null],"m":{"<tag>":"line\nbreak"}}`, compactJSON(t, value),
+		"Key order, number text and HTML characters should be preserved")
+
+	indented, err := encodeJSON(mustJSON(t, `{"a":[],"b":{},"c":[1]}`), "  ")
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [],\n  \"b\": {},\n  \"c\": [\n    1\n  ]\n}", string(indented))
+
+	_, warnings, err := decodeJSON([]byte(`{"a": 1, "a": 2}`))
+	require.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	testCases := []struct {
+		input    string
+		position string
+	}{
+		{input: "{\n  \"a\": 1,\n  \"b\": }", position: "line 3, column 8"},
+		{input: "{\"a\": 1", position: "unexpected end of JSON input"},
+		{input: "[1, 2] 3", position: "line 1, column 8"},
+		{input: "{\"a\" 1}", position: "line 1, column 6"},
+	}
+	for _, tc := range testCases {
+		_, _, err := decodeJSON([]byte(tc.input))
+		require.Error(t, err, tc.input)
+		assert.Contains(t, err.Error(), tc.position, tc.input)
+	}
+
+	_, err = encodeJSON(math.Inf(1), "")
+	assert.Error(t, err)
+}
+
+// Test YAML conversion
+func TestYAML(t *testing.T) {
+	value, err := decodeYAML([]byte(`
+defaults: &defaults
+  retries: 3
+  timeout: 1.5
+service:
+  <<: *defaults
+  name: api
+  port: 0x1F90
+  enabled: yes
+  ratio: .inf
+  tags: [a, "b"]
+  started: 2024-01-02
+  empty:
+`))
+	require.NoError(t, err)
+	service := value.(*object).values["service"].(*object)
+	assert.Equal(t, []string{"name", "port", "enabled", "ratio", "tags", "started", "empty", "retries", "timeout"}, service.keys)
+	assert.Equal(t, json.Number("8080"), service.values["port"])
+	assert.Equal(t, "yes", service.values["enabled"], "YAML 1.2 reads yes as a string")
+	assert.True(t, math.IsInf(service.values["ratio"].(float64), 1))
+	assert.Equal(t, "2024-01-02", service.values["started"])
+	assert.Nil(t, service.values["empty"])
+
+	output, err := encodeYAML(mustJSON(t, `{"s": "true", "n": 1, "f": 2.0, "multi": "a\nb\n", "list": [{"x": null}]}`), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "s: \"true\"\nn: 1\nf: 2.0\nmulti: |\n  a\n  b\nlist:\n  - x: null\n", string(output))
+
+	documents, err := decodeYAML([]byte("a: 1\n---\nb: 2\n"))
+	require.NoError(t, err)
+	assert.Len(t, documents, 2)
+
+	_, err = decodeYAML([]byte("a: [1, 2\nb: 3"))
+	assert.Error(t, err)
+}
+
+// Test TOML parsing against the main constructs of the specification
+func TestTOMLDecode(t *testing.T) {
+	value, err := decodeTOML([]byte(`
+# Comment
+title = "TOML \"Example\"\t\u00E9"
+literal = 'C:\path'
+"quoted key" = 1_000
+site."google.com" = true
+hex = 0xDEAD_BEEF
+float = -3.14e-2
+special = -inf
+date = 1979-05-27T07:32:00-08:00
+local = 1979-05-27
+multi = """
+Roses are red \
+    and violets blue"""
+raw = '''
+first line
+'''
+nested = [[1, 2], ["a", 'b'],]
+inline = { x = 1, y.z = 2 }
+
+[database]
+ports = [ 8000,
+  8001, # trailing comment
+]
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+
+[[products]]
+name = "Nail"
+[products.size]
+value = 3
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"title":"TOML \"Example\"\té","literal":"C:\\path","quoted key":1000,"site":{"google.com":true},`+
+		`"hex":3735928559,"float":-0.0314,"special":-Inf,"date":"1979-05-27T07:32:00-08:00","local":"1979-05-27",`+
+		`"multi":"Roses are red and violets blue","raw":"first line\n","nested":[[1,2],["a","b"]],"inline":{"x":1,"y":{"z":2}},`+
+		`"database":{"ports":[8000,8001]},"servers":{"alpha":{"ip":"10.0.0.1"}},`+
+		`"products":[{"name":"Hammer"},{},{"name":"Nail","size":{"value":3}}]}`,
+		strings.Replace(formatValueFull(value), "-Inf", "-Inf", 1))
+
+	errorCases := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "Duplicate key", input: "a = 1\na = 2", err: "key a is already defined"},
+		{name: "Duplicate table", input: "[a]\nx = 1\n[a]\ny = 2", err: "table a already exists"},
+		{name: "Table over dotted keys", input: "a.b.c = 1\n[a]", err: "table a already exists"},
+		{name: "Extend inline table", input: "a = { x = 1 }\n[a.b]\n", err: "expected a to be a table"},
+		{name: "Missing value", input: "a = \n", err: "line 1, column 5"},
+		{name: "Leading zero", input: "a = 012", err: "line 1, column 6"},
+		{name: "Unterminated string", input: "a = \"abc\nb = 1", err: "basic strings cannot have new lines"},
+		{name: "Trailing text", input: "a = 1 b = 2", err: "expected newline"},
+		{name: "Array table over array", input: "a = [1]\n[[a]]", err: "should be an array table"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decodeTOML([]byte(tc.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+// formatValueFull writes a value as compact JSON, spelling out infinities.
+func formatValueFull(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		if math.IsInf(v, -1) {
+			return "-Inf"
+		}
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatValueFull(item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case *object:
+		parts := make([]string, len(v.keys))
+		for i, key := range v.keys {
+			parts[i] = formatValueFull(key) + ":" + formatValueFull(v.values[key])
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	}
+	data, _ := encodeJSON(value, "")
+	return string(data)
+}
+
+// Test TOML output
+func TestTOMLEncode(t *testing.T) {
+	output, err := encodeTOML(mustJSON(t, `{
+		"name": "app",
+		"owner": {"name": "Ada", "tags": ["a", "b"]},
+		"servers": {"alpha": {"ip": "10.0.0.1"}, "beta": {"ip": "10.0.0.2"}},
+		"version": 2,
+		"products": [{"name": "Hammer", "dims": {"w": 1}}, {"name": "Nail"}],
+		"points": [{"x": 1}, 2],
+		"odd key": "a\"b"
+	}`))
+	require.NoError(t, err)
+	expected := `name = "app"
+version = 2
+points = [{ x = 1 }, 2]
+"odd key" = "a\"b"
+
+[owner]
+name = "Ada"
+tags = ["a", "b"]
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[servers.beta]
+ip = "10.0.0.2"
+
+[[products]]
+name = "Hammer"
+
+[products.dims]
+w = 1
+
+[[products]]
+name = "Nail"
+`
+	assert.Equal(t, expected, string(output))
+
+	// The output must read back to the same value
+	decoded, err := decodeTOML(output)
+	require.NoError(t, err)
+	assert.Equal(t, compactJSON(t, mustJSON(t, `{"name":"app","version":2,"points":[{"x":1},2],"odd key":"a\"b",`+
+		`"owner":{"name":"Ada","tags":["a","b"]},"servers":{"alpha":{"ip":"10.0.0.1"},"beta":{"ip":"10.0.0.2"}},`+
+		`"products":[{"name":"Hammer","dims":{"w":1}},{"name":"Nail"}]}`)), compactJSON(t, decoded))
+
+	// Dates and times stay unquoted on a round trip
+	input := "offset = 1979-05-27T07:32:00.5-08:00\nlocal = 1979-05-27T07:32:00\ndate = 1979-05-27\ntime = 07:32:00\n"
+	decoded, err = decodeTOML([]byte(input))
+	require.NoError(t, err)
+	output, err = encodeTOML(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(output))
+
+	_, err = encodeTOML(mustJSON(t, `[1, 2]`))
+	assert.Error(t, err)
+	_, err = encodeTOML(mustJSON(t, `{"a": {"b": null}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key a.b")
+}
+
+// Test XML conversion
+func TestXML(t *testing.T) {
+	value, err := decodeXML([]byte(`<?xml version="1.0"?>
+<!-- catalog -->
+<catalog xmlns:x="urn:x" version="2">
+  <book id="1"><title>Go &amp; You</title><x:tag>a</x:tag></book>
+  <book id="2"><title>Second</title></book>
+  <note>mixed <b>bold</b></note>
+  <empty/>
+</catalog>`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"catalog":{"@xmlns:x":"urn:x","@version":"2","book":[{"@id":"1","title":"Go & You","x:tag":"a"},`+
+		`{"@id":"2","title":"Second"}],"note":{"b":"bold","#text":"mixed"},"empty":""}}`, compactJSON(t, value))
+
+	output, err := encodeXML(mustJSON(t, `{"catalog": {"@version": "2", "book": [{"@id": 1, "title": "A & B"}, {"title": null}], "1st": {"#text": "x"}}}`), "  ")
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<catalog version="2">
+  <book id="1">
+    <title>A &amp; B</title>
+  </book>
+  <book>
+    <title/>
+  </book>
+  <_1st>x</_1st>
+</catalog>
+`, string(output))
+
+	output, err = encodeXML(mustJSON(t, `[1, 2]`), "")
+	require.NoError(t, err)
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>1</root><root>2</root>", string(output))
+
+	for _, input := range []string{"<a><b></a>", "<a/><b/>", "text", "<a>"} {
+		_, err := decodeXML([]byte(input))
+		assert.Error(t, err, input)
+	}
+}
+
+// Test CSV conversion
+func TestCSV(t *testing.T) {
+	value, err := decodeCSV([]byte("name,age,\nAda,36,x\n\"Lovelace, A\",\"3\"\"6\"\n"), ',')
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name":"Ada","age":"36","column3":"x"},{"name":"Lovelace, A","age":"3\"6","column3":""}]`, compactJSON(t, value))
+
+	_, err = decodeCSV([]byte("a,b\n1,2,3\n"), ',')
+	assert.Error(t, err)
+
+	output, err := encodeCSV(mustJSON(t, `[{"name": "Ada", "address": {"city": "London"}}, {"name": "Bob", "tags": ["a", "b"]}]`), ';')
+	require.NoError(t, err)
+	assert.Equal(t, "name;address.city;tags\nAda;London;\nBob;;\"[\"\"a\"\",\"\"b\"\"]\"\n", string(output))
+
+	output, err = encodeCSV(mustJSON(t, `[[1, "x"], [2, "y"]]`), ',')
+	require.NoError(t, err)
+	assert.Equal(t, "1,x\n2,y\n", string(output))
+
+	_, err = encodeCSV(mustJSON(t, `"text"`), ',')
+	assert.Error(t, err)
+
+	delimiter, ok := detectDelimiter("a;b;c\n1;2;3\n4;5;6\n")
+	assert.True(t, ok)
+	assert.Equal(t, ';', delimiter)
+}
+
+// Test format detection
+func TestDetectFormat(t *testing.T) {
+	testCases := map[string]string{
+		`{"a": 1}`:                   "json",
+		"[1, 2]":                     "json",
+		"[server]\nport = 80":        "toml",
+		"# config\nname = \"x\"":     "toml",
+		"<a>1</a>":                   "xml",
+		"name: x\nlist:\n  - 1":      "yaml",
+		"- a\n- b":                   "yaml",
+		"id,name\n1,Ada\n2,Bob":      "csv",
+		"id\tname\n1\tAda":           "csv",
+		"just text, with a comma":    "yaml",
+		"\ufeff{\"bom\": true}":      "json",
+		"[[products]]\nname = \"x\"": "toml",
+	}
+	for input, expected := range testCases {
+		assert.Equal(t, expected, detectFormat(input), input)
+	}
+}
+
+// Test JSON Schema validation
+func TestValidateSchema(t *testing.T) {
+	schema := mustJSON(t, `{
+		"$defs": {"tag": {"type": "string", "pattern": "^[a-z]+$"}},
+		"type": "object",
+		"required": ["id", "email", "tags"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"email": {"type": "string", "format": "email"},
+			"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true, "maxItems": 3},
+			"kind": {"enum": ["a", "b"]},
+			"size": {"oneOf": [{"type": "integer"}, {"type": "number", "maximum": 10}]}
+		},
+		"additionalProperties": false
+	}`)
+
+	testCases := []struct {
+		name     string
+		document string
+		errors   []string
+	}{
+		{
+			name:     "Valid",
+			document: `{"id": 1.0, "email": "ada@example.com", "price": 9.99, "tags": ["a", "b"], "kind": "a", "size": 20}`,
+		},
+		{
+			name:     "Type and required",
+			document: `{"id": "1", "tags": []}`,
+			errors:   []string{`(root): missing required property "email"`, "/id: expected integer, got string"},
+		},
+		{
+			name:     "Nested failures",
+			document: `{"id": 0, "email": "not an email", "price": 1.234, "tags": ["a", "B", "a", "c"], "extra": 1}`,
+			errors: []string{
+				"/id: 0 is less than the minimum of 1",
+				`/email: string "not an email" is not a valid email`,
+				"/price: 1.234 is not a multiple of 1/100",
+				"/tags: array has 4 items, more than the maximum of 3",
+				"/tags: items 0 and 2 are equal, but items must be unique",
+				`/tags/1: string "B" does not match the pattern ^[a-z]+$`,
+				`(root): property "extra" is not allowed`,
+			},
+		},
+		{
+			name:     "Enum and oneOf",
+			document: `{"id": 1, "email": "a@b.co", "tags": [], "kind": "c", "size": 5}`,
+			errors: []string{
+				`/kind: value "c" is not one of "a", "b"`,
+				"/size: value matches 2 of the oneOf schemas, expected exactly 1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs, err := validateSchema(schema, mustJSON(t, tc.document))
+			require.NoError(t, err)
+			var messages []string
+			for _, e := range errs {
+				messages = append(messages, e.String())
+			}
+			assert.ElementsMatch(t, tc.errors, messages)
+		})
+	}
+
+	// Conditionals and draft 4 exclusive limits
+	conditional := mustJSON(t, `{"if": {"properties": {"country": {"const": "US"}}}, "then": {"required": ["zip"]},
+		"properties": {"age": {"minimum": 18, "exclusiveMinimum": true}}}`)
+	errs, err := validateSchema(conditional, mustJSON(t, `{"country": "US", "age": 18}`))
+	require.NoError(t, err)
+	assert.Len(t, errs, 2)
+
+	_, err = validateSchema(mustJSON(t, `{"$ref": "https://example.com/schema.json"}`), mustJSON(t, `1`))
+	assert.Error(t, err, "Remote references should be reported")
+	_, err = validateSchema(mustJSON(t, `{"$ref": "#"}`), mustJSON(t, `1`))
+	assert.Error(t, err, "Reference cycles should be reported")
+}
+
+// Test tool handlers
+func TestHandlers(t *testing.T) {
+	s := NewDataFormatServer(4096)
+	ctx := context.Background()
+
+	result, err := s.handleConvert(ctx, mcptest.NewCallToolRequest("convert", map[string]interface{}{
+		"input":    "b: 1\na:\n  - x\n",
+		"to":       "json",
+		"sortKeys": true,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": [\n    \"x\"\n  ],\n  \"b\": 1\n}\n", mcptest.ResultText(result))
+
+	result, err = s.handleConvert(ctx, mcptest.NewCallToolRequest("convert", map[string]interface{}{
+		"input": "id;name\n1;Ada\n",
+		"from":  "csv",
+		"to":    "csv",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "id;name\n1;Ada\n", mcptest.ResultText(result), "CSV output should keep the input delimiter")
+
+	result, err = s.handlePrettyPrint(ctx, mcptest.NewCallToolRequest("prettyPrint", map[string]interface{}{
+		"input":  "{ \"a\" : [1, 2] }",
+		"indent": 0,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":[1,2]}\n", mcptest.ResultText(result))
+
+	_, err = s.handleConvert(ctx, mcptest.NewCallToolRequest("convert", map[string]interface{}{
+		"input": "{\"a\": }",
+		"to":    "yaml",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse JSON input: line 1, column 7")
+
+	_, err = s.handleConvert(ctx, mcptest.NewCallToolRequest("convert", map[string]interface{}{
+		"input": strings.Repeat("a", 5000),
+		"to":    "json",
+	}))
+	assert.Error(t, err, "Input over the size limit should be rejected")
+
+	result, err = s.handleJSONValidate(ctx, mcptest.NewCallToolRequest("jsonValidate", map[string]interface{}{
+		"input": "{\"a\": 1, \"a\": 2}",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Valid JSON: object with 1 key\n\nWarnings:\n- duplicate key \"a\" at line 1, column 10 (the last value is used)", mcptest.ResultText(result))
+
+	result, err = s.handleJSONValidate(ctx, mcptest.NewCallToolRequest("jsonValidate", map[string]interface{}{
+		"input": "[1,]",
+	}))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mcptest.ResultText(result), "Invalid JSON: line 1, column 3"), mcptest.ResultText(result))
+
+	result, err = s.handleJSONValidate(ctx, mcptest.NewCallToolRequest("jsonValidate", map[string]interface{}{
+		"input":  "port = 80",
+		"format": "toml",
+		"schema": `{"properties": {"port": {"type": "integer", "maximum": 65535}}, "required": ["host"]}`,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Invalid: 1 schema violation\n- (root): missing required property \"host\"", mcptest.ResultText(result))
+
+	_, err = s.handleJSONValidate(ctx, mcptest.NewCallToolRequest("jsonValidate", map[string]interface{}{
+		"input":  "{}",
+		"schema": "{not json",
+	}))
+	assert.Error(t, err, "An invalid schema should be a request error")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewDataFormatServer(1024).Server())
+}
@@ -0,0 +1,331 @@
+package dataformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// TOML documents are parsed with go-toml and converted to the value model. Dates and
+// times become datetime values so they are written back unquoted.
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+
+// decodeTOML parses a TOML document into an object, keeping keys in document order.
+func decodeTOML(data []byte) (interface{}, error) {
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, column := decodeErr.Position()
+			return nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+		return nil, err
+	}
+	return tomlToValue(doc, nil, tomlKeyOrder(data), 0)
+}
+
+// tomlKeyOrder returns the position of every key path in the document. Array indexes
+// are left out of the paths.
+func tomlKeyOrder(data []byte) map[string]int {
+	order := make(map[string]int)
+	add := func(path []string) {
+		// Parent tables take the position of their first key
+		for n := 1; n <= len(path); n++ {
+			key := strings.Join(path[:n], "\x00")
+			if _, ok := order[key]; !ok {
+				order[key] = len(order)
+			}
+		}
+	}
+	keyParts := func(node *unstable.Node) []string {
+		var parts []string
+		for it := node.Key(); it.Next(); {
+			parts = append(parts, string(it.Node().Data))
+		}
+		return parts
+	}
+
+	var addValue func(path []string, value *unstable.Node)
+	addValue = func(path []string, value *unstable.Node) {
+		switch value.Kind {
+		case unstable.InlineTable:
+			for it := value.Children(); it.Next(); {
+				child := it.Node()
+				childPath := append(path[:len(path):len(path)], keyParts(child)...)
+				add(childPath)
+				addValue(childPath, child.Value())
+			}
+		case unstable.Array:
+			for it := value.Children(); it.Next(); {
+				addValue(path, it.Node())
+			}
+		}
+	}
+
+	var p unstable.Parser
+	p.Reset(data)
+	var table []string
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = keyParts(expr)
+			add(table)
+		case unstable.KeyValue:
+			path := append(table[:len(table):len(table)], keyParts(expr)...)
+			add(path)
+			addValue(path, expr.Value())
+		}
+	}
+	return order
+}
+
+func tomlToValue(v interface{}, path []string, order map[string]int, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		position := func(key string) int {
+			if i, ok := order[strings.Join(append(path, key), "\x00")]; ok {
+				return i
+			}
+			return math.MaxInt
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			pi, pj := position(keys[i]), position(keys[j])
+			if pi != pj {
+				return pi < pj
+			}
+			return keys[i] < keys[j]
+		})
+
+		obj := newObject()
+		for _, key := range keys {
+			value, err := tomlToValue(v[key], append(path[:len(path):len(path)], key), order, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, value)
+		}
+		return obj, nil
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			value, err := tomlToValue(item, path, order, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return v, nil
+		}
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		return json.Number(text), nil
+	case toml.LocalDate:
+		return datetime(v.String()), nil
+	case toml.LocalTime:
+		return datetime(v.String()), nil
+	case toml.LocalDateTime:
+		return datetime(v.String()), nil
+	case time.Time:
+		return datetime(v.Format(time.RFC3339Nano)), nil
+	case bool, string:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported TOML value type %T", v)
+}
+
+// encodeTOML writes an object as a TOML document.
+func encodeTOML(v interface{}) ([]byte, error) {
+	root, ok := v.(*object)
+	if !ok {
+		return nil, fmt.Errorf("TOML documents must be an object at the top level, not %s", describeValue(v))
+	}
+	var b strings.Builder
+	if err := writeTOMLTable(&b, nil, root); err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimLeft(b.String(), "\n")), nil
+}
+
+// isArrayOfTables reports whether v is written with [[header]] tables.
+func isArrayOfTables(v interface{}) bool {
+	items, ok := v.([]interface{})
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(*object); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(key string) string {
+	if key != "" && tomlBareKey.FindString(key) == key {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlPath(path []string) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		parts[i] = tomlKey(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func writeTOMLTable(b *strings.Builder, path []string, table *object) error {
+	// Plain values come first, since everything after a header belongs to that table
+	var tables []string
+	for _, key := range table.keys {
+		value := table.values[key]
+		if _, ok := value.(*object); ok || isArrayOfTables(value) {
+			tables = append(tables, key)
+			continue
+		}
+		text, err := tomlValue(value, append(path, key))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s = %s\n", tomlKey(key), text)
+	}
+
+	for _, key := range tables {
+		childPath := append(append([]string(nil), path...), key)
+		switch value := table.values[key].(type) {
+		case *object:
+			// Skip headers of tables that only hold other tables
+			if hasPlainValues(value) || value.len() == 0 {
+				fmt.Fprintf(b, "\n[%s]\n", tomlPath(childPath))
+			}
+			if err := writeTOMLTable(b, childPath, value); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, item := range value {
+				fmt.Fprintf(b, "\n[[%s]]\n", tomlPath(childPath))
+				if err := writeTOMLTable(b, childPath, item.(*object)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasPlainValues(table *object) bool {
+	for _, value := range table.values {
+		if _, ok := value.(*object); !ok && !isArrayOfTables(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// tomlValue formats a value inline.
+func tomlValue(v interface{}, path []string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", fmt.Errorf("null values cannot be represented in TOML (key %s)", strings.Join(path, "."))
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return string(v), nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return tomlString(v), nil
+	case datetime:
+		return string(v), nil
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			text, err := tomlValue(item, append(path, strconv.Itoa(i)))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = text
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case *object:
+		if v.len() == 0 {
+			return "{}", nil
+		}
+		parts := make([]string, len(v.keys))
+		for i, key := range v.keys {
+			text, err := tomlValue(v.values[key], append(path, key))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = tomlKey(key) + " = " + text
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", v)
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
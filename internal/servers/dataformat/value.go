@@ -0,0 +1,337 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Documents in every format are converted to a common value model:
+//
+//	nil, bool, string, datetime, json.Number, float64 (only for infinities and NaN),
+//	[]interface{} and *object
+//
+// Numbers are kept as their decimal text so integers of any size and the distinction
+// between 1 and 1.0 survive a conversion.
+
+// maxDepth limits the nesting of decoded documents.
+const maxDepth = 1000
+
+var errTooDeep = fmt.Errorf("document is nested more than %d levels deep", maxDepth)
+
+// datetime is a TOML date, time or date-time in its canonical text. Formats without a
+// date type treat it as a string.
+type datetime string
+
+// object is a mapping that keeps its keys in document order.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]interface{})}
+}
+
+// set adds or replaces a key. Replaced keys keep their original position.
+func (o *object) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+func (o *object) len() int {
+	return len(o.keys)
+}
+
+// sortKeys sorts the keys of all objects in v recursively.
+func sortKeys(v interface{}) {
+	switch v := v.(type) {
+	case *object:
+		sort.Strings(v.keys)
+		for _, value := range v.values {
+			sortKeys(value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			sortKeys(item)
+		}
+	}
+}
+
+// describeValue summarizes the top level of v, such as "object with 3 keys".
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case *object:
+		if v.len() == 1 {
+			return "object with 1 key"
+		}
+		return fmt.Sprintf("object with %d keys", v.len())
+	case []interface{}:
+		if len(v) == 1 {
+			return "array with 1 item"
+		}
+		return fmt.Sprintf("array with %d items", len(v))
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string, datetime:
+		return "string"
+	default:
+		return "number"
+	}
+}
+
+// jsonDecoder decodes JSON into the value model, keeping key order.
+type jsonDecoder struct {
+	dec        *json.Decoder
+	data       []byte
+	duplicates []string
+}
+
+// decodeJSON parses a single JSON document. Duplicate keys are allowed, the last value
+// wins, and they are returned as warnings.
+func decodeJSON(data []byte) (interface{}, []string, error) {
+	d := &jsonDecoder{dec: json.NewDecoder(bytes.NewReader(data)), data: data}
+	d.dec.UseNumber()
+
+	value, err := d.value(0)
+	if err != nil {
+		return nil, nil, d.positionError(err)
+	}
+	start := d.nextOffset()
+	if _, err := d.dec.Token(); err != io.EOF {
+		if err == nil {
+			line, column := position(data, start)
+			return nil, nil, fmt.Errorf("line %d, column %d: unexpected data after the top-level value", line, column)
+		}
+		return nil, nil, d.positionError(err)
+	}
+	return value, d.duplicates, nil
+}
+
+// position returns the line and column of a byte offset.
+func position(data []byte, offset int64) (int, int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return line, column
+}
+
+// nextOffset returns the offset of the next token, skipping whitespace and separators.
+func (d *jsonDecoder) nextOffset() int64 {
+	offset := d.dec.InputOffset()
+	for offset < int64(len(d.data)) && strings.IndexByte(" \t\r\n,:", d.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func (d *jsonDecoder) positionError(err error) error {
+	offset := d.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The offset counts the invalid byte
+		offset = max(0, syntaxErr.Offset-1)
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errors.New("unexpected end of input")
+	}
+	line, column := position(d.data, offset)
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+func (d *jsonDecoder) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	token, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		switch token {
+		case '{':
+			obj := newObject()
+			for d.dec.More() {
+				start := d.nextOffset()
+				keyToken, err := d.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyToken.(string)
+				if _, exists := obj.get(key); exists {
+					line, column := position(d.data, start)
+					d.duplicates = append(d.duplicates, fmt.Sprintf("duplicate key %q at line %d, column %d (the last value is used)", key, line, column))
+				}
+				value, err := d.value(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(key, value)
+			}
+			_, err := d.dec.Token()
+			return obj, err
+		case '[':
+			items := []interface{}{}
+			for d.dec.More() {
+				value, err := d.value(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, value)
+			}
+			_, err := d.dec.Token()
+			return items, err
+		}
+		return nil, fmt.Errorf("unexpected %q", token)
+	default:
+		return token, nil
+	}
+}
+
+// encodeJSON writes v as JSON. An empty indent produces compact output.
+func encodeJSON(v interface{}, indent string) ([]byte, error) {
+	var b bytes.Buffer
+	if err := writeJSON(&b, v, indent, 0); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeJSON(b *bytes.Buffer, v interface{}, indent string, depth int) error {
+	newline := func(depth int) {
+		if indent != "" {
+			b.WriteByte('\n')
+			b.WriteString(strings.Repeat(indent, depth))
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		b.WriteString(string(v))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("%v cannot be represented in JSON", v)
+		}
+		b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		writeJSONString(b, v)
+	case datetime:
+		writeJSONString(b, string(v))
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := writeJSON(b, item, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		b.WriteByte(']')
+	case *object:
+		if v.len() == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			newline(depth + 1)
+			writeJSONString(b, key)
+			b.WriteByte(':')
+			if indent != "" {
+				b.WriteByte(' ')
+			}
+			if err := writeJSON(b, v.values[key], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+// writeJSONString writes a JSON string literal without escaping HTML characters.
+func writeJSONString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\u2028', '\u2029':
+			fmt.Fprintf(b, `\u%04x`, r)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// scalarString formats a scalar for text based formats such as CSV and XML.
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	case datetime:
+		return string(v)
+	}
+	data, _ := encodeJSON(v, "")
+	return string(data)
+}
@@ -0,0 +1,281 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// XML is mapped to objects the way most converters do it: attributes become keys
+// prefixed with "@", text next to attributes or child elements becomes "#text", and
+// repeated child elements become arrays. Elements holding only text become strings.
+
+const (
+	attributePrefix = "@"
+	textKey         = "#text"
+)
+
+// decodeXML parses an XML document into an object with the root element as its only key.
+func decodeXML(data []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := dec.RawToken()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("no root element found")
+			}
+			return nil, xmlError(dec, err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			value, err := decodeXMLElement(dec, token, 0)
+			if err != nil {
+				return nil, xmlError(dec, err)
+			}
+			if err := expectXMLEnd(dec); err != nil {
+				return nil, xmlError(dec, err)
+			}
+			root := newObject()
+			root.set(xmlName(token.Name), value)
+			return root, nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return nil, xmlError(dec, errors.New("text outside the root element"))
+			}
+		}
+	}
+}
+
+func xmlError(dec *xml.Decoder, err error) error {
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return err
+	}
+	line, _ := dec.InputPos()
+	return fmt.Errorf("line %d: %w", line, err)
+}
+
+// expectXMLEnd checks that only comments, processing instructions and whitespace follow
+// the root element.
+func expectXMLEnd(dec *xml.Decoder) error {
+	for {
+		token, err := dec.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			return errors.New("more than one root element")
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return errors.New("text after the root element")
+			}
+		}
+	}
+}
+
+// xmlName returns a name with its namespace prefix, as written in the document.
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// decodeXMLElement reads the content of an element up to its end tag. RawToken is used to
+// keep namespace prefixes, so matching end tags are checked here.
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	obj := newObject()
+	for _, attr := range start.Attr {
+		obj.set(attributePrefix+xmlName(attr.Name), attr.Value)
+	}
+	repeated := make(map[string]bool)
+	var text strings.Builder
+
+	for {
+		token, err := dec.RawToken()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("element <%s> is not closed", xmlName(start.Name))
+			}
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, token, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name := xmlName(token.Name)
+			existing, ok := obj.get(name)
+			switch {
+			case !ok:
+				obj.set(name, child)
+			case repeated[name]:
+				obj.set(name, append(existing.([]interface{}), child))
+			default:
+				obj.set(name, []interface{}{existing, child})
+				repeated[name] = true
+			}
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			if token.Name != start.Name {
+				return nil, fmt.Errorf("element <%s> is closed by </%s>", xmlName(start.Name), xmlName(token.Name))
+			}
+			content := strings.TrimSpace(text.String())
+			if obj.len() == 0 {
+				return content, nil
+			}
+			if content != "" {
+				obj.set(textKey, content)
+			}
+			return obj, nil
+		}
+	}
+}
+
+// encodeXML writes v as an XML document. An object with a single key names the root
+// element; anything else is wrapped in a <root> element.
+func encodeXML(v interface{}, indent string) ([]byte, error) {
+	rootName, rootValue := "root", v
+	if obj, ok := v.(*object); ok && obj.len() == 1 {
+		if _, isArray := obj.values[obj.keys[0]].([]interface{}); !isArray {
+			rootName, rootValue = obj.keys[0], obj.values[obj.keys[0]]
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	b.WriteByte('\n')
+	if err := writeXMLElement(&b, rootName, rootValue, indent, 0); err != nil {
+		return nil, err
+	}
+	if indent != "" {
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// validXMLName converts a key to a valid element or attribute name.
+func validXMLName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		valid := unicode.IsLetter(r) || r == '_' || r == ':' ||
+			(i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))
+		if !valid {
+			if i == 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+				b.WriteByte('_')
+				b.WriteRune(r)
+				continue
+			}
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+func writeXMLElement(b *bytes.Buffer, name string, v interface{}, indent string, depth int) error {
+	name = validXMLName(name)
+	prefix := strings.Repeat(indent, depth)
+	newline := func() {
+		if indent != "" {
+			b.WriteByte('\n')
+		}
+	}
+
+	if items, ok := v.([]interface{}); ok {
+		// Arrays become repeated elements; nested arrays are wrapped in <item> elements
+		for i, item := range items {
+			if i > 0 {
+				newline()
+			}
+			if nested, ok := item.([]interface{}); ok {
+				wrapper := newObject()
+				wrapper.set("item", nested)
+				item = wrapper
+			}
+			if err := writeXMLElement(b, name, item, indent, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	b.WriteString(prefix)
+	b.WriteByte('<')
+	b.WriteString(name)
+
+	obj, ok := v.(*object)
+	if !ok {
+		if v == nil {
+			b.WriteString("/>")
+			return nil
+		}
+		b.WriteByte('>')
+		xml.EscapeText(b, []byte(scalarString(v)))
+		fmt.Fprintf(b, "</%s>", name)
+		return nil
+	}
+
+	var children []string
+	text := ""
+	for _, key := range obj.keys {
+		value := obj.values[key]
+		switch {
+		case strings.HasPrefix(key, attributePrefix):
+			if _, isObject := value.(*object); isObject {
+				return fmt.Errorf("attribute %q must have a scalar value", key)
+			}
+			fmt.Fprintf(b, ` %s="`, validXMLName(strings.TrimPrefix(key, attributePrefix)))
+			xml.EscapeText(b, []byte(scalarString(value)))
+			b.WriteByte('"')
+		case key == textKey:
+			text = scalarString(value)
+		default:
+			children = append(children, key)
+		}
+	}
+
+	if len(children) == 0 && text == "" {
+		b.WriteString("/>")
+		return nil
+	}
+	b.WriteByte('>')
+	if len(children) == 0 {
+		xml.EscapeText(b, []byte(text))
+		fmt.Fprintf(b, "</%s>", name)
+		return nil
+	}
+
+	if text != "" {
+		newline()
+		b.WriteString(strings.Repeat(indent, depth+1))
+		xml.EscapeText(b, []byte(text))
+	}
+	for _, key := range children {
+		newline()
+		if err := writeXMLElement(b, key, obj.values[key], indent, depth+1); err != nil {
+			return err
+		}
+	}
+	newline()
+	b.WriteString(prefix)
+	fmt.Fprintf(b, "</%s>", name)
+	return nil
+}
@@ -0,0 +1,251 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLNodes limits how many nodes alias expansion may produce, guarding against
+// documents that reference the same anchor exponentially often.
+const maxYAMLNodes = 1000000
+
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+type yamlConverter struct {
+	nodes int
+}
+
+// decodeYAML parses YAML. A stream of several documents is returned as an array.
+func decodeYAML(data []byte) (interface{}, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	c := &yamlConverter{}
+
+	var documents []interface{}
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		value, err := c.value(&node, 0)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, value)
+	}
+
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
+	default:
+		return documents, nil
+	}
+}
+
+func (c *yamlConverter) value(node *yaml.Node, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+	if c.nodes++; c.nodes > maxYAMLNodes {
+		return nil, fmt.Errorf("document expands to more than %d nodes", maxYAMLNodes)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return c.value(node.Content[0], depth)
+	case yaml.AliasNode:
+		return c.value(node.Alias, depth+1)
+	case yaml.SequenceNode:
+		items := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			item, err := c.value(child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case yaml.MappingNode:
+		obj := newObject()
+		var merges []*object
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			value, err := c.value(valueNode, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if keyNode.Tag == "!!merge" {
+				// Merge keys (<<) copy the keys of one or more mappings
+				switch merged := value.(type) {
+				case *object:
+					merges = append(merges, merged)
+				case []interface{}:
+					for _, item := range merged {
+						if m, ok := item.(*object); ok {
+							merges = append(merges, m)
+						}
+					}
+				}
+				continue
+			}
+			key, err := c.key(keyNode, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, value)
+		}
+		for _, merged := range merges {
+			for _, key := range merged.keys {
+				if _, exists := obj.get(key); !exists {
+					obj.set(key, merged.values[key])
+				}
+			}
+		}
+		return obj, nil
+	case yaml.ScalarNode:
+		return scalarFromYAML(node)
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// key converts a mapping key to a string. Complex keys are written as compact JSON.
+func (c *yamlConverter) key(node *yaml.Node, depth int) (string, error) {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+	value, err := c.value(node, depth+1)
+	if err != nil {
+		return "", err
+	}
+	data, err := encodeJSON(value, "")
+	return string(data), err
+}
+
+func scalarFromYAML(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case "!!int":
+		if jsonNumberPattern.MatchString(node.Value) {
+			return json.Number(node.Value), nil
+		}
+		var i int64
+		if err := node.Decode(&i); err != nil {
+			// Too large for int64; keep the text
+			return node.Value, nil
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	case "!!float":
+		if jsonNumberPattern.MatchString(node.Value) {
+			return json.Number(node.Value), nil
+		}
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return f, nil
+		}
+		text := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		return json.Number(text), nil
+	default:
+		// Strings, timestamps and binary data keep their text
+		return node.Value, nil
+	}
+}
+
+// encodeYAML writes v as YAML with the given indentation.
+func encodeYAML(v interface{}, indent int) ([]byte, error) {
+	node, err := yamlNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(indent)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(v), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}, nil
+	case float64:
+		text := ".nan"
+		if math.IsInf(v, 1) {
+			text = ".inf"
+		} else if math.IsInf(v, -1) {
+			text = "-.inf"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: text}, nil
+	case datetime:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(v)}, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(strings.TrimRight(v, "\n"), "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case *object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.keys {
+			child, err := yamlNode(v.values[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
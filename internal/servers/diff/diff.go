@@ -0,0 +1,368 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit is one element of an edit script. For opEqual and opDelete, a is the index in the
+// original sequence; for opEqual and opInsert, b is the index in the modified sequence.
+type edit struct {
+	kind opKind
+	a, b int
+}
+
+// diffSlices returns the shortest edit script turning a into b, using Myers' algorithm with
+// the linear space refinement, so large inputs with many changes stay cheap in memory.
+func diffSlices(a, b []string) []edit {
+	var edits []edit
+	var compare func(aLo, aHi, bLo, bHi int)
+	compare = func(aLo, aHi, bLo, bHi int) {
+		// Common prefix and suffix
+		for aLo < aHi && bLo < bHi && a[aLo] == b[bLo] {
+			edits = append(edits, edit{opEqual, aLo, bLo})
+			aLo++
+			bLo++
+		}
+		suffix := 0
+		for aLo < aHi-suffix && bLo < bHi-suffix && a[aHi-suffix-1] == b[bHi-suffix-1] {
+			suffix++
+		}
+		aHi -= suffix
+		bHi -= suffix
+
+		switch {
+		case aLo == aHi:
+			for j := bLo; j < bHi; j++ {
+				edits = append(edits, edit{opInsert, aLo, j})
+			}
+		case bLo == bHi:
+			for i := aLo; i < aHi; i++ {
+				edits = append(edits, edit{opDelete, i, bLo})
+			}
+		default:
+			x, y, ok := middleSnake(a[aLo:aHi], b[bLo:bHi])
+			// A split at either end would not make progress
+			if ok && (x > 0 || y > 0) && (x < aHi-aLo || y < bHi-bLo) {
+				compare(aLo, aLo+x, bLo, bLo+y)
+				compare(aLo+x, aHi, bLo+y, bHi)
+			} else {
+				for i := aLo; i < aHi; i++ {
+					edits = append(edits, edit{opDelete, i, bLo})
+				}
+				for j := bLo; j < bHi; j++ {
+					edits = append(edits, edit{opInsert, aHi, j})
+				}
+			}
+		}
+
+		for i := 0; i < suffix; i++ {
+			edits = append(edits, edit{opEqual, aHi + i, bHi + i})
+		}
+	}
+	compare(0, len(a), 0, len(b))
+	return edits
+}
+
+// middleSnake finds a point on an optimal edit path of a and b by searching forwards from
+// the start and backwards from the end until the two searches meet.
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// With an odd delta the forward search detects the overlap, otherwise the backward one
+	front := delta%2 != 0
+	// Diagonals that ran off the edges of the grid are skipped
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				j := offset + delta - k
+				if j >= 0 && j < size && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !front:
+				j := offset + delta - k
+				if j >= 0 && j < size && forward[j] != -1 {
+					fx := forward[j]
+					fy := offset + fx - j
+					if fx >= n-x {
+						return fx, fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// splitLines splits text into lines that keep their "\n" terminators, so a missing newline
+// at the end of the text shows up as a difference.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOptions controls unified diff output.
+type diffOptions struct {
+	OriginalName     string
+	ModifiedName     string
+	Context          int
+	IgnoreWhitespace bool
+}
+
+// diffStats counts the changed lines of a diff.
+type diffStats struct {
+	Hunks     int
+	Additions int
+	Deletions int
+}
+
+// unifiedDiff formats the differences between two texts as a unified diff. It returns ""
+// when the texts are equal.
+func unifiedDiff(original, modified string, opts diffOptions) (string, diffStats) {
+	a, b := splitLines(original), splitLines(modified)
+	keyA, keyB := a, b
+	if opts.IgnoreWhitespace {
+		keyA, keyB = whitespaceKeys(a), whitespaceKeys(b)
+	}
+	edits := diffSlices(keyA, keyB)
+
+	var stats diffStats
+	var out strings.Builder
+	writeLine := func(prefix byte, line string) {
+		out.WriteByte(prefix)
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	for start := 0; start < len(edits); {
+		// Find the next change
+		for start < len(edits) && edits[start].kind == opEqual {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		// Extend the hunk while the gaps between changes fit in the surrounding context
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].kind != opEqual {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*opts.Context {
+				break
+			}
+		}
+		first := max(start-opts.Context, 0)
+		last := min(end+opts.Context, len(edits))
+
+		if stats.Hunks == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", opts.OriginalName, opts.ModifiedName)
+		}
+		stats.Hunks++
+
+		var oldCount, newCount int
+		for _, e := range edits[first:last] {
+			if e.kind != opInsert {
+				oldCount++
+			}
+			if e.kind != opDelete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(edits[first].a, oldCount), hunkRange(edits[first].b, newCount))
+		for _, e := range edits[first:last] {
+			switch e.kind {
+			case opEqual:
+				writeLine(' ', a[e.a])
+			case opDelete:
+				stats.Deletions++
+				writeLine('-', a[e.a])
+			case opInsert:
+				stats.Additions++
+				writeLine('+', b[e.b])
+			}
+		}
+		start = last
+	}
+	return out.String(), stats
+}
+
+// hunkRange formats the start and length of a hunk side. An empty side names the line
+// before the hunk, and a length of one is left out, as GNU diff does.
+func hunkRange(index, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", index)
+	case 1:
+		return fmt.Sprintf("%d", index+1)
+	}
+	return fmt.Sprintf("%d,%d", index+1, count)
+}
+
+// whitespaceKeys returns the lines with runs of whitespace collapsed and trimmed, for
+// comparisons that ignore whitespace changes.
+func whitespaceKeys(lines []string) []string {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return keys
+}
+
+var (
+	// wordPattern splits text into words, runs of whitespace and single other characters.
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|.`)
+	word        = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+)
+
+// segment is a run of text that is unchanged, removed or added.
+type segment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// countWords returns the number of words in the segments of the given type.
+func countWords(segments []segment, kind string) int {
+	n := 0
+	for _, seg := range segments {
+		if seg.Type == kind {
+			n += len(word.FindAllStringIndex(seg.Text, -1))
+		}
+	}
+	return n
+}
+
+// wordDiff compares two texts word by word and returns the merged runs of text, with
+// removed text before added text in each changed region.
+func wordDiff(original, modified string) []segment {
+	a := wordPattern.FindAllString(original, -1)
+	b := wordPattern.FindAllString(modified, -1)
+
+	var segments []segment
+	add := func(kind, text string) {
+		if n := len(segments); n > 0 && segments[n-1].Type == kind {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, segment{Type: kind, Text: text})
+	}
+
+	var removed, added []string
+	flush := func() {
+		// Tokens both sides share at the edges of a region are unchanged
+		prefix := 0
+		for prefix < len(removed) && prefix < len(added) && removed[prefix] == added[prefix] {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(removed)-prefix && suffix < len(added)-prefix &&
+			removed[len(removed)-suffix-1] == added[len(added)-suffix-1] {
+			suffix++
+		}
+		if prefix > 0 {
+			add("equal", strings.Join(removed[:prefix], ""))
+		}
+		if text := strings.Join(removed[prefix:len(removed)-suffix], ""); text != "" {
+			add("delete", text)
+		}
+		if text := strings.Join(added[prefix:len(added)-suffix], ""); text != "" {
+			add("insert", text)
+		}
+		if suffix > 0 {
+			add("equal", strings.Join(removed[len(removed)-suffix:], ""))
+		}
+		removed, added = nil, nil
+	}
+	edits := diffSlices(a, b)
+	for i, e := range edits {
+		switch e.kind {
+		case opEqual:
+			// Whitespace between two changes joins them into one region
+			if strings.TrimSpace(a[e.a]) == "" && len(removed)+len(added) > 0 && i+1 < len(edits) && edits[i+1].kind != opEqual {
+				removed = append(removed, a[e.a])
+				added = append(added, b[e.b])
+				continue
+			}
+			flush()
+			add("equal", a[e.a])
+		case opDelete:
+			removed = append(removed, a[e.a])
+		case opInsert:
+			added = append(added, b[e.b])
+		}
+	}
+	flush()
+	return segments
+}
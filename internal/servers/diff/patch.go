@@ -0,0 +1,274 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patchLine is a line of a hunk body.
+type patchLine struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// hunk is one @@ section of a unified diff.
+type hunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	lines              []patchLine
+	// header is the line number of the @@ line in the patch, for error messages
+	header int
+}
+
+// filePatch holds the hunks for one file of a unified diff.
+type filePatch struct {
+	oldName, newName string
+	hunks            []hunk
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parsePatch parses a unified diff. File headers are optional, so a bare list of hunks is
+// accepted as a patch for a single file. Text outside of files, such as git's "diff" and
+// "index" lines or a commit message, is ignored.
+func parsePatch(patch string) ([]filePatch, error) {
+	lines := splitLines(patch)
+	var files []filePatch
+	current := func() *filePatch {
+		if len(files) == 0 {
+			files = append(files, filePatch{})
+		}
+		return &files[len(files)-1]
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files = append(files, filePatch{
+				oldName: fileName(line[4:]),
+				newName: fileName(strings.TrimRight(lines[i+1][4:], "\r\n")),
+			})
+			i++
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: malformed hunk header: %s", i+1, line)
+			}
+			h := hunk{
+				oldStart: atoi(m[1]),
+				oldCount: countOrOne(m[2]),
+				newStart: atoi(m[3]),
+				newCount: countOrOne(m[4]),
+				header:   i + 1,
+			}
+
+			// Read lines until both sides have their counts
+			oldSeen, newSeen := 0, 0
+			for i+1 < len(lines) && (oldSeen < h.oldCount || newSeen < h.newCount) {
+				i++
+				body := lines[i]
+				kind := body[0]
+				text := body[1:]
+				switch kind {
+				case ' ', '-', '+':
+				case '\n', '\r':
+					// Editors and chat clients strip the space of empty context lines
+					kind, text = ' ', body
+				case '\\':
+					markNoNewline(h.lines)
+					continue
+				default:
+					return nil, fmt.Errorf("hunk at line %d: expected %d original and %d modified lines but found %d and %d",
+						h.header, h.oldCount, h.newCount, oldSeen, newSeen)
+				}
+				if kind != '+' {
+					oldSeen++
+				}
+				if kind != '-' {
+					newSeen++
+				}
+				// Only a marker removes the newline, not the end of the patch
+				if !strings.HasSuffix(text, "\n") {
+					text += "\n"
+				}
+				h.lines = append(h.lines, patchLine{kind: kind, text: text})
+			}
+			if oldSeen != h.oldCount || newSeen != h.newCount {
+				return nil, fmt.Errorf("hunk at line %d: expected %d original and %d modified lines but found %d and %d",
+					h.header, h.oldCount, h.newCount, oldSeen, newSeen)
+			}
+			// A marker may follow the last line
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], `\`) {
+				i++
+				markNoNewline(h.lines)
+			}
+			f := current()
+			f.hunks = append(f.hunks, h)
+		}
+	}
+
+	// Drop file headers without hunks, such as those of binary files
+	var result []filePatch
+	for _, f := range files {
+		if len(f.hunks) > 0 {
+			result = append(result, f)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no hunks found in the patch")
+	}
+	return result, nil
+}
+
+// markNoNewline removes the newline of the last line, following a "\ No newline at end of
+// file" marker.
+func markNoNewline(lines []patchLine) {
+	if len(lines) > 0 {
+		last := &lines[len(lines)-1]
+		last.text = strings.TrimSuffix(last.text, "\n")
+	}
+}
+
+// fileName returns the name from a ---/+++ header, without a timestamp.
+func fileName(header string) string {
+	if tab := strings.IndexByte(header, '\t'); tab >= 0 {
+		header = header[:tab]
+	}
+	return strings.TrimSpace(header)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
+
+// applyOptions controls how a patch is applied.
+type applyOptions struct {
+	// Reverse undoes the patch instead of applying it
+	Reverse bool
+	// IgnoreWhitespace matches context and removed lines regardless of whitespace changes
+	IgnoreWhitespace bool
+}
+
+// hunkResult describes where a hunk was applied.
+type hunkResult struct {
+	Line   int
+	Offset int
+}
+
+// applyPatch applies the hunks of a file patch to text. Hunks are matched at the line they
+// name first and then at growing distances from it, so a patch still applies when lines
+// were added or removed elsewhere. It fails without changes if any hunk does not match.
+func applyPatch(text string, f filePatch, opts applyOptions) (string, []hunkResult, error) {
+	lines := splitLines(text)
+	key := func(line string) string {
+		if opts.IgnoreWhitespace {
+			return strings.Join(strings.Fields(line), " ")
+		}
+		return line
+	}
+
+	var result []string
+	var results []hunkResult
+	position := 0 // lines before this index are already copied to the result
+	offset := 0   // distance of the previous hunk from its stated line
+	for n, h := range f.hunks {
+		var before, after []string
+		// Positions of context lines in after and before
+		var context [][2]int
+		for _, l := range h.lines {
+			switch l.kind {
+			case ' ':
+				context = append(context, [2]int{len(after), len(before)})
+				before = append(before, l.text)
+				after = append(after, l.text)
+			case '-', '+':
+				if (l.kind == '-') != opts.Reverse {
+					before = append(before, l.text)
+				} else {
+					after = append(after, l.text)
+				}
+			}
+		}
+		start, count := h.oldStart, h.oldCount
+		if opts.Reverse {
+			start, count = h.newStart, h.newCount
+		}
+		// An empty side names the line before the hunk
+		expected := start - 1
+		if count == 0 {
+			expected = start
+		}
+		stated := expected
+		// Later hunks usually moved by as much as the previous one
+		expected += offset
+
+		matches := func(at int) bool {
+			if at < position || at+len(before) > len(lines) {
+				return false
+			}
+			for i, line := range before {
+				if key(lines[at+i]) != key(line) {
+					return false
+				}
+			}
+			return true
+		}
+		at := -1
+		for distance := 0; distance <= len(lines); distance++ {
+			if matches(expected - distance) {
+				at = expected - distance
+				break
+			}
+			if distance > 0 && matches(expected+distance) {
+				at = expected + distance
+				break
+			}
+		}
+		if at < 0 {
+			return "", nil, fmt.Errorf("hunk %d (line %d of the patch) does not apply: the lines it changes were not found%s",
+				n+1, h.header, describeLines(before))
+		}
+
+		result = append(result, lines[position:at]...)
+		if opts.IgnoreWhitespace {
+			// Keep the original form of context lines
+			for _, c := range context {
+				after[c[0]] = lines[at+c[1]]
+			}
+		}
+		result = append(result, after...)
+		position = at + len(before)
+		offset = at - stated
+		results = append(results, hunkResult{Line: at + 1, Offset: offset})
+	}
+	result = append(result, lines[position:]...)
+	return strings.Join(result, ""), results, nil
+}
+
+// describeLines quotes the first lines a hunk expected, for error messages.
+func describeLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("; expected:")
+	for i, line := range lines {
+		if i == 5 {
+			fmt.Fprintf(&b, "\n  ... (%d more lines)", len(lines)-i)
+			break
+		}
+		b.WriteString("\n  " + strconv.Quote(strings.TrimSuffix(line, "\n")))
+	}
+	return b.String()
+}
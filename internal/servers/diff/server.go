@@ -0,0 +1,549 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/sandbox"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// DiffServer is an MCP server that computes and applies unified diffs.
+type DiffServer struct {
+	server       *server.MCPServer
+	dataDir      string
+	maxInputSize int
+	files        *sandbox.Sandbox
+}
+
+// NewDiffServer creates a new DiffServer instance
+func NewDiffServer(dataDir string, maxInputSize int) *DiffServer {
+	log.Printf("DiffServer created: dataDir=%s, maxInputSize=%d", dataDir, maxInputSize)
+
+	s := &DiffServer{
+		dataDir:      dataDir,
+		maxInputSize: maxInputSize,
+		files:        sandbox.New(dataDir, "data directory", sandbox.Options{MaxFileSize: int64(maxInputSize)}),
+	}
+
+	mcpServer := server.NewMCPServer(
+		"diff-server", // server name
+		"1.0.0",       // version
+	)
+
+	// Options shared by the diff tools
+	compareOptions := []mcp.ToolOption{
+		mcp.WithNumber("contextLines",
+			mcp.Description("Number of unchanged lines shown around each change (default: 3)"),
+		),
+		mcp.WithBoolean("ignoreWhitespace",
+			mcp.Description("Treat lines that differ only in whitespace as equal (default: false)"),
+		),
+	}
+
+	// Register diffText tool
+	diffTextTool := mcp.NewTool("diffText", append([]mcp.ToolOption{
+		mcp.WithDescription("Computes a unified diff between two texts"),
+		mcp.WithString("original",
+			mcp.Description("Original text"),
+			mcp.Required(),
+		),
+		mcp.WithString("modified",
+			mcp.Description("Modified text"),
+			mcp.Required(),
+		),
+		mcp.WithString("originalName",
+			mcp.Description("Name of the original in the diff header (default: a)"),
+		),
+		mcp.WithString("modifiedName",
+			mcp.Description("Name of the modified text in the diff header (default: b)"),
+		),
+	}, compareOptions...)...)
+
+	// Register diffFiles tool
+	diffFilesTool := mcp.NewTool("diffFiles", append([]mcp.ToolOption{
+		mcp.WithDescription("Computes a unified diff between two text files in the data directory"),
+		mcp.WithString("originalPath",
+			mcp.Description("Path of the original file inside the data directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("modifiedPath",
+			mcp.Description("Path of the modified file inside the data directory"),
+			mcp.Required(),
+		),
+	}, compareOptions...)...)
+
+	// Register applyPatch tool
+	applyPatchTool := mcp.NewTool("applyPatch",
+		mcp.WithDescription("Applies a unified diff to text or to a file in the data directory. Hunks that moved are found near their stated lines; if any hunk does not match, nothing is changed"),
+		mcp.WithString("patch",
+			mcp.Description("Unified diff, as produced by diffText, diff -u or git diff"),
+			mcp.Required(),
+		),
+		mcp.WithString("text",
+			mcp.Description("Text to patch"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Path of a file inside the data directory to patch, used instead of text. For multi-file patches, selects the file's section"),
+		),
+		mcp.WithBoolean("write",
+			mcp.Description("Write the result back to path instead of returning it (default: false)"),
+		),
+		mcp.WithBoolean("reverse",
+			mcp.Description("Undo the patch instead of applying it (default: false)"),
+		),
+		mcp.WithBoolean("ignoreWhitespace",
+			mcp.Description("Match the lines of the patch regardless of whitespace changes (default: false)"),
+		),
+	)
+
+	// Register wordDiff tool
+	wordDiffTool := mcp.NewTool("wordDiff",
+		mcp.WithDescription("Compares two texts word by word, marking removed text as [-...-] and added text as {+...+}"),
+		mcp.WithString("original",
+			mcp.Description("Original text"),
+			mcp.Required(),
+		),
+		mcp.WithString("modified",
+			mcp.Description("Modified text"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("inline (default) for marked-up text, or json for a list of equal, delete and insert segments"),
+			mcp.Enum("inline", "json"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, diffTextTool, s.handleDiffText)
+	middleware.AddTool(mcpServer, diffFilesTool, s.handleDiffFiles)
+	middleware.AddTool(mcpServer, applyPatchTool, s.handleApplyPatch)
+	middleware.AddTool(mcpServer, wordDiffTool, s.handleWordDiff)
+
+	s.server = mcpServer
+	return s
+}
+
+// dataPath resolves p inside the data directory, rejecting escapes and paths the
+// allow and deny rules exclude.
+func (s *DiffServer) dataPath(p string) (string, error) {
+	return s.files.Resolve(p)
+}
+
+// readFile reads a text file inside the data directory.
+func (s *DiffServer) readFile(p string) (string, error) {
+	data, err := s.files.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	if strings.IndexByte(string(data), 0) >= 0 {
+		return "", fmt.Errorf("%s is a binary file", p)
+	}
+	return string(data), nil
+}
+
+// checkSize validates the size of text inputs.
+func (s *DiffServer) checkSize(texts map[string]string) error {
+	for name, text := range texts {
+		if len(text) > s.maxInputSize {
+			return fmt.Errorf("%s exceeds the maximum size of %d bytes", name, s.maxInputSize)
+		}
+	}
+	return nil
+}
+
+// contextOrDefault returns the number of context lines, 3 when unset.
+func contextOrDefault(contextLines *int) (int, error) {
+	if contextLines == nil {
+		return 3, nil
+	}
+	if *contextLines < 0 {
+		return 0, fmt.Errorf("contextLines must not be negative")
+	}
+	return *contextLines, nil
+}
+
+// formatDiff describes a unified diff and its size.
+func formatDiff(diff string, stats diffStats) string {
+	if stats.Hunks == 0 {
+		return "No differences"
+	}
+	hunks := "hunks"
+	if stats.Hunks == 1 {
+		hunks = "hunk"
+	}
+	return fmt.Sprintf("%d %s (+%d -%d lines)\n\n%s",
+		stats.Hunks, hunks, stats.Additions, stats.Deletions, strings.TrimRight(diff, "\n"))
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleDiffText handles the text diff request.
+func (s *DiffServer) handleDiffText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting diffText request processing")
+
+	var args struct {
+		Original         string `json:"original" param:"required,empty"`
+		Modified         string `json:"modified" param:"required,empty"`
+		OriginalName     string `json:"originalName,omitempty"`
+		ModifiedName     string `json:"modifiedName,omitempty"`
+		ContextLines     *int   `json:"contextLines,omitempty"`
+		IgnoreWhitespace bool   `json:"ignoreWhitespace,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkSize(map[string]string{"original": args.Original, "modified": args.Modified}); err != nil {
+		return nil, err
+	}
+	contextLines, err := contextOrDefault(args.ContextLines)
+	if err != nil {
+		return nil, err
+	}
+	if args.OriginalName == "" {
+		args.OriginalName = "a"
+	}
+	if args.ModifiedName == "" {
+		args.ModifiedName = "b"
+	}
+
+	diff, stats := unifiedDiff(args.Original, args.Modified, diffOptions{
+		OriginalName:     args.OriginalName,
+		ModifiedName:     args.ModifiedName,
+		Context:          contextLines,
+		IgnoreWhitespace: args.IgnoreWhitespace,
+	})
+
+	log.Printf("diffText request completed: %d hunks", stats.Hunks)
+	return textResult(formatDiff(diff, stats)), nil
+}
+
+// handleDiffFiles handles the file diff request.
+func (s *DiffServer) handleDiffFiles(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting diffFiles request processing")
+
+	var args struct {
+		OriginalPath     string `json:"originalPath"`
+		ModifiedPath     string `json:"modifiedPath"`
+		ContextLines     *int   `json:"contextLines,omitempty"`
+		IgnoreWhitespace bool   `json:"ignoreWhitespace,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.OriginalPath == "" || args.ModifiedPath == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "originalPath and modifiedPath are required")
+	}
+	contextLines, err := contextOrDefault(args.ContextLines)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := s.readFile(args.OriginalPath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	modified, err := s.readFile(args.ModifiedPath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	diff, stats := unifiedDiff(original, modified, diffOptions{
+		OriginalName:     "a/" + filepath.ToSlash(strings.TrimPrefix(args.OriginalPath, "/")),
+		ModifiedName:     "b/" + filepath.ToSlash(strings.TrimPrefix(args.ModifiedPath, "/")),
+		Context:          contextLines,
+		IgnoreWhitespace: args.IgnoreWhitespace,
+	})
+
+	log.Printf("diffFiles request completed: %d hunks", stats.Hunks)
+	return textResult(formatDiff(diff, stats)), nil
+}
+
+// handleApplyPatch handles the patch request.
+func (s *DiffServer) handleApplyPatch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting applyPatch request processing")
+
+	var args struct {
+		Patch            string  `json:"patch"`
+		Text             *string `json:"text,omitempty"`
+		Path             string  `json:"path,omitempty"`
+		Write            bool    `json:"write,omitempty"`
+		Reverse          bool    `json:"reverse,omitempty"`
+		IgnoreWhitespace bool    `json:"ignoreWhitespace,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Patch == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "patch is required")
+	}
+	if (args.Text == nil) == (args.Path == "") {
+		return nil, fmt.Errorf("provide either text or path")
+	}
+	if args.Write && args.Path == "" {
+		return nil, fmt.Errorf("write requires path")
+	}
+	if err := s.checkSize(map[string]string{"patch": args.Patch}); err != nil {
+		return nil, err
+	}
+
+	files, err := parsePatch(args.Patch)
+	if err != nil {
+		log.Printf("Error: Invalid patch: %v", err)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid patch: %w", err)
+	}
+
+	var text string
+	if args.Path != "" {
+		if text, err = s.readFile(args.Path); err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+	} else {
+		text = *args.Text
+		if err := s.checkSize(map[string]string{"text": text}); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := selectFile(files, args.Path)
+	if err != nil {
+		return nil, err
+	}
+	patched, results, err := applyPatch(text, file, applyOptions{
+		Reverse:          args.Reverse,
+		IgnoreWhitespace: args.IgnoreWhitespace,
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var b strings.Builder
+	verb := "Applied"
+	if args.Reverse {
+		verb = "Reverted"
+	}
+	hunks := "hunks"
+	if len(results) == 1 {
+		hunks = "hunk"
+	}
+	fmt.Fprintf(&b, "%s %d %s", verb, len(results), hunks)
+	for i, r := range results {
+		fmt.Fprintf(&b, "\nHunk %d at line %d", i+1, r.Line)
+		if r.Offset != 0 {
+			fmt.Fprintf(&b, " (offset %+d lines)", r.Offset)
+		}
+	}
+
+	if args.Write {
+		resolved, err := s.dataPath(args.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("cannot write %s: %w", args.Path, err)
+		}
+		if err := os.WriteFile(resolved, []byte(patched), info.Mode().Perm()); err != nil {
+			log.Printf("Error: Failed to write %s: %v", args.Path, err)
+			return nil, fmt.Errorf("failed to write %s: %w", args.Path, err)
+		}
+		fmt.Fprintf(&b, "\n\nWrote %s (%d bytes)", args.Path, len(patched))
+	} else {
+		b.WriteString("\n\nResult:\n" + patched)
+	}
+
+	log.Printf("applyPatch request completed: %d hunks", len(results))
+	return textResult(b.String()), nil
+}
+
+// selectFile picks the section of a patch to apply. A multi-file patch needs a path that
+// matches one of its file names, with or without git's a/ and b/ prefixes.
+func selectFile(files []filePatch, p string) (filePatch, error) {
+	if len(files) == 1 {
+		return files[0], nil
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.newName)
+	}
+	if p == "" {
+		return filePatch{}, fmt.Errorf("the patch changes %d files (%s); pass path to choose one", len(files), strings.Join(names, ", "))
+	}
+	want := path.Clean("/" + filepath.ToSlash(p))
+	for _, f := range files {
+		for _, name := range []string{f.newName, f.oldName} {
+			for _, prefix := range []string{"a/", "b/", ""} {
+				if strings.HasPrefix(name, prefix) && path.Clean("/"+strings.TrimPrefix(name, prefix)) == want {
+					return f, nil
+				}
+			}
+		}
+	}
+	return filePatch{}, fmt.Errorf("the patch has no changes for %s; it changes %s", p, strings.Join(names, ", "))
+}
+
+// handleWordDiff handles the word diff request.
+func (s *DiffServer) handleWordDiff(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting wordDiff request processing")
+
+	var args struct {
+		Original string `json:"original" param:"required,empty"`
+		Modified string `json:"modified" param:"required,empty"`
+		Format   string `json:"format,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkSize(map[string]string{"original": args.Original, "modified": args.Modified}); err != nil {
+		return nil, err
+	}
+
+	segments := wordDiff(args.Original, args.Modified)
+	removed, added := countWords(segments, "delete"), countWords(segments, "insert")
+
+	var text string
+	switch args.Format {
+	case "", "inline":
+		if args.Original == args.Modified {
+			text = "No differences"
+			break
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "-%d +%d words\n\n", removed, added)
+		for _, seg := range segments {
+			switch seg.Type {
+			case "equal":
+				b.WriteString(seg.Text)
+			case "delete":
+				b.WriteString("[-" + seg.Text + "-]")
+			case "insert":
+				b.WriteString("{+" + seg.Text + "+}")
+			}
+		}
+		text = b.String()
+	case "json":
+		if segments == nil {
+			segments = []segment{}
+		}
+		data, err := json.MarshalIndent(segments, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode segments: %w", err)
+		}
+		text = string(data)
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format: %s", args.Format)
+	}
+
+	log.Printf("wordDiff request completed: %d words removed, %d words added", removed, added)
+	return textResult(text), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *DiffServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the diff server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		dataDir      string
+		maxInputSize int
+	)
+	fs.StringVar(&dataDir, "data-dir", ".", "Directory that file paths are restricted to")
+	fs.IntVar(&maxInputSize, "max-input-size", 5*1024*1024, "Maximum size of texts, patches and files in bytes (default 5MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var sandboxFlags sandbox.Flags
+	sandboxFlags.Register(fs, "data directory")
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting diff server: data-dir=%s, max-input-size=%d", dataDir, maxInputSize)
+
+	// Create DiffServer instance
+	diffServer := NewDiffServer(dataDir, maxInputSize)
+	sandboxFlags.Apply(diffServer.files)
+	log.Println("DiffServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), diffServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return diffServer.Server(), transportFlags, nil
+}
+
+// Run starts the diff server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[DiffServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create diff server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("DiffServer shutdown")
+	return nil
+}
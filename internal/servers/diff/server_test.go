@@ -0,0 +1,486 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DiffServer creation test
+func TestNewDiffServer(t *testing.T) {
+	s := NewDiffServer("/tmp", 1024)
+
+	assert.NotNil(t, s, "DiffServer instance should be created")
+	assert.Equal(t, "/tmp", s.dataDir, "Data directory should match")
+	assert.Equal(t, 1024, s.maxInputSize, "Max input size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewDiffServer("/tmp", 1024)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// lcsLength computes the length of the longest common subsequence by dynamic programming.
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
+
+// Test that edit scripts are valid and minimal
+func TestDiffSlices(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomSlice := func() []string {
+		s := make([]string, rng.Intn(30))
+		for i := range s {
+			s[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return s
+	}
+
+	for n := 0; n < 500; n++ {
+		a, b := randomSlice(), randomSlice()
+		edits := diffSlices(a, b)
+
+		var gotA, gotB []string
+		equal := 0
+		for _, e := range edits {
+			switch e.kind {
+			case opEqual:
+				require.Equal(t, a[e.a], b[e.b])
+				gotA = append(gotA, a[e.a])
+				gotB = append(gotB, b[e.b])
+				equal++
+			case opDelete:
+				gotA = append(gotA, a[e.a])
+			case opInsert:
+				gotB = append(gotB, b[e.b])
+			}
+		}
+		require.Equal(t, strings.Join(a, ""), strings.Join(gotA, ""), "edits should cover the original in order")
+		require.Equal(t, strings.Join(b, ""), strings.Join(gotB, ""), "edits should cover the modified in order")
+		require.Equal(t, lcsLength(a, b), equal, "edit script should be minimal for %v -> %v", a, b)
+	}
+}
+
+// Test the diffText tool
+func TestHandleDiffText(t *testing.T) {
+	s := NewDiffServer(t.TempDir(), 1<<20)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		args     map[string]interface{}
+		expected string
+	}{
+		{
+			name: "Single change with context",
+			args: map[string]interface{}{
+				"original": "one\ntwo\nthree\nfour\nfive\n",
+				"modified": "one\ntwo\n3\nfour\nfive\n",
+			},
+			expected: "1 hunk (+1 -1 lines)\n\n--- a\n+++ b\n@@ -1,5 +1,5 @@\n one\n two\n-three\n+3\n four\n five",
+		},
+		{
+			name: "Distant changes make separate hunks",
+			args: map[string]interface{}{
+				"original":     "1\n2\n3\n4\n5\n6\n7\n8\n",
+				"modified":     "x\n2\n3\n4\n5\n6\n7\ny\n",
+				"contextLines": 1,
+				"originalName": "old.txt",
+				"modifiedName": "new.txt",
+			},
+			expected: "2 hunks (+2 -2 lines)\n\n--- old.txt\n+++ new.txt\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y",
+		},
+		{
+			name: "Close changes share a hunk",
+			args: map[string]interface{}{
+				"original":     "1\n2\n3\n4\n",
+				"modified":     "x\n2\n3\ny\n",
+				"contextLines": 1,
+			},
+			expected: "1 hunk (+2 -2 lines)\n\n--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y",
+		},
+		{
+			name: "Insertion into an empty text",
+			args: map[string]interface{}{
+				"original": "",
+				"modified": "new\n",
+			},
+			expected: "1 hunk (+1 -0 lines)\n\n--- a\n+++ b\n@@ -0,0 +1 @@\n+new",
+		},
+		{
+			name: "Missing newline at end of file",
+			args: map[string]interface{}{
+				"original":     "a\nb",
+				"modified":     "a\nb\n",
+				"contextLines": 0,
+			},
+			expected: "1 hunk (+1 -1 lines)\n\n--- a\n+++ b\n@@ -2 +2 @@\n-b\n\\ No newline at end of file\n+b",
+		},
+		{
+			name: "Whitespace changes can be ignored",
+			args: map[string]interface{}{
+				"original":         "if x {\n\treturn\n}\n",
+				"modified":         "if x  {\n    return\n}\n",
+				"ignoreWhitespace": true,
+			},
+			expected: "No differences",
+		},
+		{
+			name: "Equal texts",
+			args: map[string]interface{}{
+				"original": "same\n",
+				"modified": "same\n",
+			},
+			expected: "No differences",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := s.handleDiffText(ctx, mcptest.NewCallToolRequest("diffText", tc.args))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mcptest.ResultText(result))
+		})
+	}
+
+	_, err := s.handleDiffText(ctx, mcptest.NewCallToolRequest("diffText", map[string]interface{}{
+		"original":     "a",
+		"modified":     "b",
+		"contextLines": -1,
+	}))
+	assert.ErrorContains(t, err, "contextLines must not be negative")
+}
+
+// Test that diffs of random texts apply and revert cleanly
+func TestDiffApplyRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	words := []string{"alpha\n", "beta\n", "gamma\n", "delta\n", "\n", "end"}
+	randomText := func() string {
+		var b strings.Builder
+		for i := rng.Intn(40); i > 0; i-- {
+			b.WriteString(words[rng.Intn(len(words))])
+		}
+		return b.String()
+	}
+
+	for n := 0; n < 300; n++ {
+		original, modified := randomText(), randomText()
+		diff, stats := unifiedDiff(original, modified, diffOptions{OriginalName: "a", ModifiedName: "b", Context: rng.Intn(4)})
+		if stats.Hunks == 0 {
+			require.Equal(t, original, modified)
+			continue
+		}
+		files, err := parsePatch(diff)
+		require.NoError(t, err, diff)
+		require.Len(t, files, 1)
+
+		patched, _, err := applyPatch(original, files[0], applyOptions{})
+		require.NoError(t, err, diff)
+		require.Equal(t, modified, patched, "patch:\n%s", diff)
+
+		reverted, _, err := applyPatch(modified, files[0], applyOptions{Reverse: true})
+		require.NoError(t, err, diff)
+		require.Equal(t, original, reverted, "patch:\n%s", diff)
+	}
+}
+
+// Test the applyPatch tool on text
+func TestHandleApplyPatch(t *testing.T) {
+	s := NewDiffServer(t.TempDir(), 1<<20)
+	ctx := context.Background()
+
+	original := "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+	patch := "--- a/main.go\n+++ b/main.go\n@@ -3,3 +3,4 @@\n func main() {\n-\tprintln(\"hi\")\n+\tprintln(\"hello\")\n+\tprintln(\"world\")\n }\n"
+	expected := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n"
+
+	t.Run("Exact position", func(t *testing.T) {
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch": patch,
+			"text":  original,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "Applied 1 hunk\nHunk 1 at line 3\n\nResult:\n"+expected, mcptest.ResultText(result))
+	})
+
+	t.Run("Moved hunk is found at an offset", func(t *testing.T) {
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch": patch,
+			"text":  "// Header\n// comment\n" + original,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "Applied 1 hunk\nHunk 1 at line 5 (offset +2 lines)\n\nResult:\n// Header\n// comment\n"+expected, mcptest.ResultText(result))
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch":   patch,
+			"text":    expected,
+			"reverse": true,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "Reverted 1 hunk\nHunk 1 at line 3\n\nResult:\n"+original, mcptest.ResultText(result))
+	})
+
+	t.Run("Whitespace differences", func(t *testing.T) {
+		reindented := strings.ReplaceAll(original, "\t", "    ")
+		_, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch": patch,
+			"text":  reindented,
+		}))
+		assert.ErrorContains(t, err, "hunk 1 (line 3 of the patch) does not apply")
+
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch":            patch,
+			"text":             reindented,
+			"ignoreWhitespace": true,
+		}))
+		require.NoError(t, err)
+		// Context lines keep their original form
+		assert.True(t, strings.HasSuffix(mcptest.ResultText(result), "func main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n"), mcptest.ResultText(result))
+	})
+
+	t.Run("Bare hunks with stripped blank context lines", func(t *testing.T) {
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch": "@@ -1,3 +1,3 @@\n-package main\n+package app\n\n func main() {",
+			"text":  original,
+		}))
+		require.NoError(t, err)
+		assert.Contains(t, mcptest.ResultText(result), "Result:\npackage app\n\nfunc main() {\n\tprintln(\"hi\")\n")
+	})
+
+	t.Run("No newline markers", func(t *testing.T) {
+		result, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+			"patch": "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+			"text":  "a\nb",
+		}))
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(mcptest.ResultText(result), "Result:\na\nc"))
+	})
+
+	errorCases := []struct {
+		name  string
+		args  map[string]interface{}
+		error string
+	}{
+		{
+			name:  "Context not found",
+			args:  map[string]interface{}{"patch": patch, "text": "something else\n"},
+			error: "does not apply: the lines it changes were not found; expected:\n  \"func main() {\"",
+		},
+		{
+			name:  "Wrong line counts",
+			args:  map[string]interface{}{"patch": "@@ -1,3 +1,3 @@\n a\n-b\n+c\nnot a hunk line\n", "text": "a\nb\n"},
+			error: "hunk at line 1: expected 3 original and 3 modified lines but found 2 and 2",
+		},
+		{
+			name:  "No hunks",
+			args:  map[string]interface{}{"patch": "just text\n", "text": "a\n"},
+			error: "no hunks found",
+		},
+		{
+			name:  "Text or path",
+			args:  map[string]interface{}{"patch": patch},
+			error: "provide either text or path",
+		},
+		{
+			name:  "Write without path",
+			args:  map[string]interface{}{"patch": patch, "text": original, "write": true},
+			error: "write requires path",
+		},
+		{
+			name:  "Multi-file patch on text",
+			args:  map[string]interface{}{"patch": patch + strings.ReplaceAll(patch, "main.go", "other.go"), "text": original},
+			error: "the patch changes 2 files (b/main.go, b/other.go); pass path to choose one",
+		},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", tc.args))
+			assert.ErrorContains(t, err, tc.error)
+		})
+	}
+}
+
+// Test the file tools
+func TestFileTools(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiffServer(dir, 1<<20)
+	ctx := context.Background()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "old.txt"), []byte("a\nb\nc\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "new.txt"), []byte("a\nB\nc\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "third.txt"), []byte("x\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blob.bin"), []byte("a\x00b"), 0644))
+
+	result, err := s.handleDiffFiles(ctx, mcptest.NewCallToolRequest("diffFiles", map[string]interface{}{
+		"originalPath": "src/old.txt",
+		"modifiedPath": "/src/new.txt",
+	}))
+	require.NoError(t, err)
+	diff := strings.SplitN(mcptest.ResultText(result), "\n\n", 2)[1]
+	assert.Equal(t, "--- a/src/old.txt\n+++ b/src/new.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c", diff)
+
+	// A multi-file patch applies the section for the path
+	multi := "diff --git a/other.txt b/other.txt\n--- a/other.txt\n+++ b/other.txt\n@@ -1 +1 @@\n-x\n+y\n" + diff + "\n"
+	result, err = s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+		"patch": multi,
+		"path":  "src/old.txt",
+		"write": true,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Applied 1 hunk\nHunk 1 at line 1\n\nWrote src/old.txt (6 bytes)", mcptest.ResultText(result))
+	data, err := os.ReadFile(filepath.Join(dir, "src", "old.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\nB\nc\n", string(data))
+	info, err := os.Stat(filepath.Join(dir, "src", "old.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "Writing should keep the file mode")
+
+	// Without write the file is left alone
+	result, err = s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+		"patch": multi,
+		"path":  "other.txt",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Applied 1 hunk\nHunk 1 at line 1\n\nResult:\ny\n", mcptest.ResultText(result))
+	data, err = os.ReadFile(filepath.Join(dir, "other.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(data))
+
+	_, err = s.handleApplyPatch(ctx, mcptest.NewCallToolRequest("applyPatch", map[string]interface{}{
+		"patch": multi,
+		"path":  "third.txt",
+	}))
+	assert.ErrorContains(t, err, "the patch has no changes for third.txt; it changes b/other.txt, b/src/new.txt")
+
+	errorCases := []struct {
+		name     string
+		original string
+		error    string
+	}{
+		{name: "Traversal", original: "../etc/passwd", error: "no such file"},
+		{name: "Missing file", original: "missing.txt", error: "failed to read missing.txt"},
+		{name: "Directory", original: "src", error: "src is a directory"},
+		{name: "Binary file", original: "blob.bin", error: "blob.bin is a binary file"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.handleDiffFiles(ctx, mcptest.NewCallToolRequest("diffFiles", map[string]interface{}{
+				"originalPath": tc.original,
+				"modifiedPath": "other.txt",
+			}))
+			assert.ErrorContains(t, err, tc.error)
+		})
+	}
+
+	// Symlinks cannot reach outside the data directory
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+	_, err = s.handleDiffFiles(ctx, mcptest.NewCallToolRequest("diffFiles", map[string]interface{}{
+		"originalPath": "link/secret.txt",
+		"modifiedPath": "other.txt",
+	}))
+	assert.ErrorContains(t, err, "escapes the data directory via symlink")
+
+	// Oversized files are rejected
+	small := NewDiffServer(dir, 4)
+	_, err = small.handleDiffFiles(ctx, mcptest.NewCallToolRequest("diffFiles", map[string]interface{}{
+		"originalPath": "src/new.txt",
+		"modifiedPath": "other.txt",
+	}))
+	assert.ErrorContains(t, err, "src/new.txt of 6 bytes exceeds the maximum size of 4 bytes")
+}
+
+// Test the wordDiff tool
+func TestHandleWordDiff(t *testing.T) {
+	s := NewDiffServer(t.TempDir(), 1<<20)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		original string
+		modified string
+		expected string
+	}{
+		{
+			name:     "Changed word",
+			original: "The quick brown fox jumps.",
+			modified: "The quick red fox jumps.",
+			expected: "-1 +1 words\n\nThe quick [-brown-]{+red+} fox jumps.",
+		},
+		{
+			name:     "Adjacent changes are merged",
+			original: "one two three four",
+			modified: "one 2 3 four",
+			expected: "-2 +2 words\n\none [-two three-]{+2 3+} four",
+		},
+		{
+			name:     "Insertion and punctuation",
+			original: "Hello world",
+			modified: "Hello, brave new world!",
+			expected: "-0 +2 words\n\nHello{+, brave new+} world{+!+}",
+		},
+		{
+			name:     "Equal texts",
+			original: "same text",
+			modified: "same text",
+			expected: "No differences",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := s.handleWordDiff(ctx, mcptest.NewCallToolRequest("wordDiff", map[string]interface{}{
+				"original": tc.original,
+				"modified": tc.modified,
+			}))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mcptest.ResultText(result))
+		})
+	}
+
+	result, err := s.handleWordDiff(ctx, mcptest.NewCallToolRequest("wordDiff", map[string]interface{}{
+		"original": "a b",
+		"modified": "a c",
+		"format":   "json",
+	}))
+	require.NoError(t, err)
+	var segments []segment
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &segments))
+	assert.Equal(t, []segment{
+		{Type: "equal", Text: "a "},
+		{Type: "delete", Text: "b"},
+		{Type: "insert", Text: "c"},
+	}, segments)
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewDiffServer(f.TempDir(), 1024).Server())
+}
@@ -0,0 +1,677 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const defaultAPIURL = "https://discord.com/api/v10"
+
+// Message is the subset of a Discord message used by the tools
+type Message struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	Content   string `json:"content"`
+	Timestamp string `json:"timestamp"`
+	Author    User   `json:"author"`
+	Mentions  []User `json:"mentions"`
+	// Attachments are listed by file name only
+	Attachments []struct {
+		Filename string `json:"filename"`
+		URL      string `json:"url"`
+	} `json:"attachments"`
+}
+
+// User is a Discord user reference
+type User struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Bot        bool   `json:"bot"`
+}
+
+// DiscordServer is an MCP server for Discord channels accessed through a bot.
+type DiscordServer struct {
+	server       *server.MCPServer
+	client       *http.Client
+	apiURL       string
+	token        string
+	channels     map[string]string // alias -> channel ID
+	maxRetryWait time.Duration
+	searchDepth  int
+
+	mu      sync.Mutex
+	buckets map[string]time.Time // Route -> time until which the rate limit bucket is exhausted
+}
+
+// NewDiscordServer creates a new DiscordServer instance. Only the channels listed in channels
+// can be read from or posted to.
+func NewDiscordServer(apiURL, token string, channels map[string]string, timeout, maxRetryWait, searchDepth int) *DiscordServer {
+	log.Printf("DiscordServer created: channels=%d, timeout=%ds, searchDepth=%d", len(channels), timeout, searchDepth)
+
+	if searchDepth < 100 {
+		searchDepth = 100
+	}
+
+	s := &DiscordServer{
+		// Create HTTP client with configured timeout
+		client:       httpclient.New(httpclient.Options{Timeout: time.Duration(timeout) * time.Second}),
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+		token:        token,
+		channels:     channels,
+		maxRetryWait: time.Duration(maxRetryWait) * time.Second,
+		searchDepth:  searchDepth,
+		buckets:      make(map[string]time.Time),
+	}
+
+	var names []string
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	channelDescription := "Channel alias or ID"
+	if len(names) > 0 {
+		channelDescription = fmt.Sprintf("Channel alias (%s) or ID", strings.Join(names, ", "))
+	}
+
+	mcpServer := server.NewMCPServer(
+		"discord-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register sendMessage tool
+	sendTool := mcp.NewTool("sendMessage",
+		mcp.WithDescription("Sends a message to an allowed channel. Mentions are not pinged and @everyone/@here are neutralized"),
+		mcp.WithString("channel",
+			mcp.Description(channelDescription),
+			mcp.Required(),
+		),
+		mcp.WithString("content",
+			mcp.Description("Message text (up to 2000 characters, Discord markdown supported)"),
+			mcp.Required(),
+		),
+		mcp.WithString("replyTo",
+			mcp.Description("Optional message ID to reply to"),
+		),
+	)
+
+	// Register readMessages tool
+	readTool := mcp.NewTool("readMessages",
+		mcp.WithDescription("Reads recent messages from an allowed channel, newest last"),
+		mcp.WithString("channel",
+			mcp.Description(channelDescription),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of messages (1-100, default: 20)"),
+		),
+		mcp.WithString("before",
+			mcp.Description("Only return messages before this message ID, for paging back"),
+		),
+	)
+
+	// Register searchMessages tool
+	searchTool := mcp.NewTool("searchMessages",
+		mcp.WithDescription("Searches recent history of allowed channels for messages containing the query (case-insensitive)"),
+		mcp.WithString("query",
+			mcp.Description("Text to search for"),
+			mcp.Required(),
+		),
+		mcp.WithString("channel",
+			mcp.Description(channelDescription+" to restrict the search to (default: all allowed channels)"),
+		),
+		mcp.WithString("author",
+			mcp.Description("Only match messages by this username"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of matches (default: 20)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, sendTool, s.handleSendMessage)
+	middleware.AddTool(mcpServer, readTool, s.handleReadMessages)
+	middleware.AddTool(mcpServer, searchTool, s.handleSearchMessages)
+
+	s.server = mcpServer
+	return s
+}
+
+// resolveChannel maps an alias or ID to an allowed channel ID.
+func (s *DiscordServer) resolveChannel(channel string) (string, error) {
+	if id, ok := s.channels[channel]; ok {
+		return id, nil
+	}
+	for _, id := range s.channels {
+		if id == channel {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("channel %q is not allowed", channel)
+}
+
+// channelName returns the alias of a channel ID, falling back to the ID.
+func (s *DiscordServer) channelName(id string) string {
+	for name, channelID := range s.channels {
+		if channelID == id && name != id {
+			return name
+		}
+	}
+	return id
+}
+
+// waitForBucket sleeps until the rate limit bucket of a route has reset.
+func (s *DiscordServer) waitForBucket(ctx context.Context, route string) error {
+	s.mu.Lock()
+	resetAt := s.buckets[route]
+	s.mu.Unlock()
+
+	wait := time.Until(resetAt)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > s.maxRetryWait {
+		return fmt.Errorf("rate limited by Discord, retry in %.1fs", wait.Seconds())
+	}
+	log.Printf("Rate limit bucket for %s exhausted, waiting %v", route, wait)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// updateBucket records the rate limit state reported by a response.
+func (s *DiscordServer) updateBucket(route string, header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	resetAfter, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.buckets[route] = time.Now().Add(time.Duration(resetAfter * float64(time.Second)))
+	s.mu.Unlock()
+}
+
+// doRequest sends an authenticated API request, honouring rate limits. Requests that are
+// rate limited are retried after the advised delay when it is within maxRetryWait.
+func (s *DiscordServer) doRequest(ctx context.Context, method, route, path string, query url.Values, payload interface{}, out interface{}) error {
+	if s.token == "" {
+		return fmt.Errorf("bot token is not configured")
+	}
+
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	reqURL := s.apiURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := s.waitForBucket(ctx, route); err != nil {
+			return err
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(data))
+		if err != nil {
+			log.Printf("Error: Failed to create request: %v", err)
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bot "+s.token)
+		httpReq.Header.Set("User-Agent", "DiscordBot (https://github.com/mark3labs/mcphost, 1.0.0)")
+		if payload != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		log.Printf("Sending %s request to %s", method, path)
+		resp, err := s.client.Do(httpReq)
+		if err != nil {
+			log.Printf("Error: Request failed: %v", err)
+			return fmt.Errorf("request failed: %w", err)
+		}
+
+		// Read response (with size limitation)
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+		resp.Body.Close()
+		if err != nil {
+			log.Printf("Error: Failed to read response body: %v", err)
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		s.updateBucket(route, resp.Header)
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			var limited struct {
+				RetryAfter float64 `json:"retry_after"`
+				Global     bool    `json:"global"`
+			}
+			json.Unmarshal(body, &limited)
+			wait := time.Duration(limited.RetryAfter * float64(time.Second))
+			if attempt >= 2 || wait > s.maxRetryWait {
+				log.Printf("Error: Rate limited on %s (global=%v, retry after %v)", route, limited.Global, wait)
+				return fmt.Errorf("rate limited by Discord, retry in %.1fs", wait.Seconds())
+			}
+			log.Printf("Rate limited on %s, retrying in %v", route, wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			var apiErr struct {
+				Message string `json:"message"`
+				Code    int    `json:"code"`
+			}
+			if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+				log.Printf("Error: API returned %d: %s", resp.StatusCode, apiErr.Message)
+				return fmt.Errorf("Discord API error: %s (status code: %d)", apiErr.Message, resp.StatusCode)
+			}
+			log.Printf("Error: API returned error status code: %d", resp.StatusCode)
+			return fmt.Errorf("Discord API returned error status code: %d", resp.StatusCode)
+		}
+
+		if out != nil {
+			if err := json.Unmarshal(body, out); err != nil {
+				log.Printf("Error: Failed to parse response: %v", err)
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// sanitizeContent neutralizes mass mentions so they cannot ping even if allowed_mentions
+// were ignored, by inserting a zero-width space after the @.
+func sanitizeContent(content string) string {
+	content = strings.ReplaceAll(content, "@everyone", "@\u200beveryone")
+	return strings.ReplaceAll(content, "@here", "@\u200bhere")
+}
+
+var mentionPattern = regexp.MustCompile(`<@!?(\d+)>`)
+
+// renderContent replaces user mention markup with readable usernames.
+func renderContent(msg Message) string {
+	names := make(map[string]string, len(msg.Mentions))
+	for _, u := range msg.Mentions {
+		names[u.ID] = u.Username
+	}
+	content := mentionPattern.ReplaceAllStringFunc(msg.Content, func(m string) string {
+		id := mentionPattern.FindStringSubmatch(m)[1]
+		if name, ok := names[id]; ok {
+			return "@" + name
+		}
+		return m
+	})
+	for _, a := range msg.Attachments {
+		content = strings.TrimSpace(content + fmt.Sprintf("\n[attachment: %s]", a.Filename))
+	}
+	return content
+}
+
+// formatMessage renders a message as a single block.
+func formatMessage(msg Message) string {
+	author := msg.Author.Username
+	if msg.Author.Bot {
+		author += " (bot)"
+	}
+	timestamp := msg.Timestamp
+	if t, err := time.Parse(time.RFC3339, msg.Timestamp); err == nil {
+		timestamp = t.UTC().Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("[%s] %s (id=%s):\n%s\n", timestamp, author, msg.ID, renderContent(msg))
+}
+
+// fetchMessages returns up to limit messages before the given ID, newest first.
+func (s *DiscordServer) fetchMessages(ctx context.Context, channelID string, limit int, before string) ([]Message, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if before != "" {
+		query.Set("before", before)
+	}
+	var messages []Message
+	err := s.doRequest(ctx, http.MethodGet, "GET /channels/"+channelID+"/messages", "/channels/"+url.PathEscape(channelID)+"/messages", query, nil, &messages)
+	return messages, err
+}
+
+// handleSendMessage handles the message sending request.
+func (s *DiscordServer) handleSendMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendMessage request processing")
+
+	var args struct {
+		Channel string `json:"channel"`
+		Content string `json:"content"`
+		ReplyTo string `json:"replyTo,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	channelID, err := s.resolveChannel(args.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Content) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "content is required")
+	}
+	content := sanitizeContent(args.Content)
+	if len([]rune(content)) > 2000 {
+		return nil, fmt.Errorf("content exceeds the Discord limit of 2000 characters")
+	}
+
+	payload := map[string]interface{}{
+		"content": content,
+		// Never ping users, roles or everyone from agent-written messages
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}
+	if args.ReplyTo != "" {
+		payload["message_reference"] = map[string]interface{}{
+			"message_id":         args.ReplyTo,
+			"fail_if_not_exists": false,
+		}
+	}
+
+	var sent Message
+	path := "/channels/" + url.PathEscape(channelID) + "/messages"
+	if err := s.doRequest(ctx, http.MethodPost, "POST /channels/"+channelID+"/messages", path, nil, payload, &sent); err != nil {
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Message sent to %s (id=%s)", s.channelName(channelID), sent.ID),
+			},
+		},
+	}
+
+	log.Println("sendMessage request completed")
+	return result, nil
+}
+
+// handleReadMessages handles the channel history request.
+func (s *DiscordServer) handleReadMessages(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting readMessages request processing")
+
+	var args struct {
+		Channel string `json:"channel"`
+		Limit   int    `json:"limit,omitempty"`
+		Before  string `json:"before,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	channelID, err := s.resolveChannel(args.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	} else if args.Limit > 100 {
+		args.Limit = 100
+	}
+
+	messages, err := s.fetchMessages(ctx, channelID, args.Limit, args.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("%d messages in %s\n\n", len(messages), s.channelName(channelID)))
+	// The API returns newest first; show them in reading order
+	for i := len(messages) - 1; i >= 0; i-- {
+		resultContent.WriteString(formatMessage(messages[i]))
+		resultContent.WriteString("\n")
+	}
+	if len(messages) == args.Limit {
+		resultContent.WriteString(fmt.Sprintf("Use before=%s to read older messages.\n", messages[len(messages)-1].ID))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("readMessages request completed")
+	return result, nil
+}
+
+// handleSearchMessages handles the message search request. Bots cannot use Discord's search
+// endpoint, so recent history of each channel is scanned up to searchDepth messages.
+func (s *DiscordServer) handleSearchMessages(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting searchMessages request processing")
+
+	var args struct {
+		Query   string `json:"query"`
+		Channel string `json:"channel,omitempty"`
+		Author  string `json:"author,omitempty"`
+		Limit   int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Query == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	}
+
+	var channelIDs []string
+	if args.Channel != "" {
+		id, err := s.resolveChannel(args.Channel)
+		if err != nil {
+			return nil, err
+		}
+		channelIDs = []string{id}
+	} else {
+		for _, id := range s.channels {
+			channelIDs = append(channelIDs, id)
+		}
+		sort.Strings(channelIDs)
+	}
+
+	query := strings.ToLower(args.Query)
+	var resultContent strings.Builder
+	matches := 0
+
+	for _, channelID := range channelIDs {
+		before := ""
+		for seen := 0; seen < s.searchDepth && matches < args.Limit; {
+			messages, err := s.fetchMessages(ctx, channelID, min(100, s.searchDepth-seen), before)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", s.channelName(channelID), err)
+			}
+			for _, msg := range messages {
+				if matches >= args.Limit {
+					break
+				}
+				if args.Author != "" && !strings.EqualFold(msg.Author.Username, args.Author) {
+					continue
+				}
+				if !strings.Contains(strings.ToLower(renderContent(msg)), query) {
+					continue
+				}
+				matches++
+				resultContent.WriteString(fmt.Sprintf("#%s ", s.channelName(channelID)))
+				resultContent.WriteString(formatMessage(msg))
+				resultContent.WriteString("\n")
+			}
+			seen += len(messages)
+			if len(messages) < 100 {
+				break
+			}
+			before = messages[len(messages)-1].ID
+		}
+	}
+
+	header := fmt.Sprintf("%d messages matching '%s' (searched the last %d messages of %d channels)\n\n", matches, args.Query, s.searchDepth, len(channelIDs))
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: header + resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("searchMessages request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *DiscordServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// parseChannels parses "alias=id,alias=id" or plain IDs into an alias map.
+func parseChannels(value string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, id, found := strings.Cut(item, "=")
+		if !found {
+			id = name
+		}
+		name, id = strings.TrimSpace(name), strings.TrimSpace(id)
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid channel ID in %q", item)
+		}
+		result[name] = id
+	}
+	return result, nil
+}
+
+// New creates the discord server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("discord", flag.ContinueOnError)
+	var (
+		botToken     string
+		apiURL       string
+		channels     string
+		timeout      int
+		maxRetryWait int
+		searchDepth  int
+	)
+	fs.StringVar(&botToken, "token", "", "Discord bot token")
+	fs.StringVar(&apiURL, "api-url", defaultAPIURL, "Discord API base URL")
+	fs.StringVar(&channels, "channels", "", "Comma separated channels the bot may use, as alias=channelID or channelID")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.IntVar(&maxRetryWait, "max-retry-wait", 10, "Maximum seconds to wait on a rate limit before failing")
+	fs.IntVar(&searchDepth, "search-depth", 500, "Number of recent messages per channel scanned by searchMessages")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("token", "DISCORD_BOT_TOKEN"),
+		config.WithEnv("channels", "DISCORD_CHANNELS"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	channelMap, err := parseChannels(channels)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Discord server: channels=%d, timeout=%ds", len(channelMap), timeout)
+	if botToken == "" {
+		log.Printf("Warning: Bot token not configured. The server will start but requests will fail.")
+	}
+	if len(channelMap) == 0 {
+		log.Printf("Warning: No channels configured. All channel access will be refused.")
+	}
+
+	// Create DiscordServer instance
+	discordServer := NewDiscordServer(apiURL, botToken, channelMap, timeout, maxRetryWait, searchDepth)
+	log.Println("DiscordServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), discordServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return discordServer.Server(), transportFlags, nil
+}
+
+// Run starts the discord server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[DiscordServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create discord server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("DiscordServer shutdown")
+	return nil
+}
@@ -0,0 +1,240 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+// DiscordServer creation test
+func TestNewDiscordServer(t *testing.T) {
+	testCases := []struct {
+		name        string
+		searchDepth int
+		expected    int
+	}{
+		{name: "Default search depth", searchDepth: 500, expected: 500},
+		{name: "Search depth below one page", searchDepth: 10, expected: 100},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := NewDiscordServer(defaultAPIURL+"/", "token", map[string]string{"general": "1"}, 15, 10, tc.searchDepth)
+
+			assert.NotNil(t, ds, "DiscordServer instance should be created")
+			assert.Equal(t, defaultAPIURL, ds.apiURL, "API URL should have the trailing slash trimmed")
+			assert.Equal(t, tc.expected, ds.searchDepth, "Search depth should match")
+			assert.NotNil(t, ds.server, "Internal MCPServer should be initialized")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	ds := NewDiscordServer(defaultAPIURL, "token", nil, 15, 10, 500)
+	assert.NotNil(t, ds.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test channel configuration parsing and resolution
+func TestChannels(t *testing.T) {
+	channels, err := parseChannels("general=111, alerts=222,333")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"general": "111", "alerts": "222", "333": "333"}, channels)
+
+	_, err = parseChannels("general=abc")
+	assert.Error(t, err)
+
+	ds := NewDiscordServer(defaultAPIURL, "token", channels, 15, 10, 500)
+	id, err := ds.resolveChannel("alerts")
+	assert.NoError(t, err)
+	assert.Equal(t, "222", id)
+
+	id, err = ds.resolveChannel("111")
+	assert.NoError(t, err)
+	assert.Equal(t, "111", id)
+
+	_, err = ds.resolveChannel("999")
+	assert.Error(t, err, "Channels not in the allow list should be rejected")
+}
+
+// Test mention sanitization and rendering
+func TestMentions(t *testing.T) {
+	sanitized := sanitizeContent("@everyone deploy done, @here please check")
+	assert.NotContains(t, sanitized, "@everyone")
+	assert.NotContains(t, sanitized, "@here")
+
+	msg := Message{
+		Content:  "thanks <@123> and <@!456>, not <@789>",
+		Mentions: []User{{ID: "123", Username: "alice"}, {ID: "456", Username: "bob"}},
+	}
+	assert.Equal(t, "thanks @alice and @bob, not <@789>", renderContent(msg))
+}
+
+// Test tool handlers against a mock Discord API
+func TestHandlers(t *testing.T) {
+	var lastPayload map[string]interface{}
+	var rateLimited atomic.Bool
+
+	// Channel 111 holds 150 messages with IDs 1000..1149, newest first
+	history := func(before string, limit int) []Message {
+		start := 1149
+		if before != "" {
+			fmt.Sscanf(before, "%d", &start)
+			start--
+		}
+		var messages []Message
+		for id := start; id >= 1000 && len(messages) < limit; id-- {
+			content := fmt.Sprintf("message %d", id)
+			if id == 1010 {
+				content = "the Deploy failed <@42>"
+			}
+			messages = append(messages, Message{
+				ID:        fmt.Sprint(id),
+				Content:   content,
+				Timestamp: "2024-05-01T12:00:00.000000+00:00",
+				Author:    User{ID: "1", Username: "alice"},
+				Mentions:  []User{{ID: "42", Username: "bob"}},
+			})
+		}
+		return messages
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bot test-token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/channels/111/messages":
+			if !rateLimited.Swap(true) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.05, "global": false}`))
+				return
+			}
+			lastPayload = nil
+			json.NewDecoder(r.Body).Decode(&lastPayload)
+			w.Write([]byte(`{"id": "2000", "channel_id": "111"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/channels/111/messages":
+			var limit int
+			fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
+			json.NewEncoder(w).Encode(history(r.URL.Query().Get("before"), limit))
+		case r.URL.Path == "/channels/222/messages":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Missing Access", "code": 50001}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ds := NewDiscordServer(mockServer.URL, "test-token", map[string]string{"general": "111", "secret": "222"}, 5, 1, 200)
+	ctx := context.Background()
+
+	t.Run("Send message retries after rate limit", func(t *testing.T) {
+		result, err := ds.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"channel": "general",
+			"content": "@everyone build <@42> is green",
+			"replyTo": "1149",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Message sent to general (id=2000)", result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, "@\u200beveryone build <@42> is green", lastPayload["content"])
+		assert.Equal(t, map[string]interface{}{"parse": []interface{}{}}, lastPayload["allowed_mentions"])
+		assert.Equal(t, "1149", lastPayload["message_reference"].(map[string]interface{})["message_id"])
+	})
+
+	t.Run("Send message to disallowed channel", func(t *testing.T) {
+		_, err := ds.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"channel": "333",
+			"content": "hi",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not allowed")
+	})
+
+	t.Run("Read messages", func(t *testing.T) {
+		result, err := ds.handleReadMessages(ctx, mcptest.NewCallToolRequest("readMessages", map[string]interface{}{
+			"channel": "general",
+			"limit":   3,
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "3 messages in general")
+		assert.Contains(t, text, "[2024-05-01 12:00] alice (id=1149):\nmessage 1149")
+		assert.Less(t, strings.Index(text, "message 1147"), strings.Index(text, "message 1149"), "Messages should be in reading order")
+		assert.Contains(t, text, "Use before=1147")
+	})
+
+	t.Run("Read messages API error", func(t *testing.T) {
+		_, err := ds.handleReadMessages(ctx, mcptest.NewCallToolRequest("readMessages", map[string]interface{}{
+			"channel": "secret",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Missing Access (status code: 403)")
+	})
+
+	t.Run("Search pages through history", func(t *testing.T) {
+		result, err := ds.handleSearchMessages(ctx, mcptest.NewCallToolRequest("searchMessages", map[string]interface{}{
+			"query":   "deploy failed @bob",
+			"channel": "general",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1 messages matching")
+		assert.Contains(t, text, "#general [2024-05-01 12:00] alice (id=1010)")
+	})
+
+	t.Run("Search respects depth", func(t *testing.T) {
+		shallow := NewDiscordServer(mockServer.URL, "test-token", map[string]string{"general": "111"}, 5, 1, 100)
+		result, err := shallow.handleSearchMessages(ctx, mcptest.NewCallToolRequest("searchMessages", map[string]interface{}{
+			"query": "deploy failed",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "0 messages matching")
+	})
+
+	t.Run("Search by author", func(t *testing.T) {
+		result, err := ds.handleSearchMessages(ctx, mcptest.NewCallToolRequest("searchMessages", map[string]interface{}{
+			"query":   "deploy",
+			"channel": "general",
+			"author":  "mallory",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "0 messages matching")
+	})
+}
+
+// Test that exhausted rate limit buckets are honoured before sending
+func TestRateLimitBucket(t *testing.T) {
+	ds := NewDiscordServer(defaultAPIURL, "token", nil, 15, 0, 500)
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset-After", "5")
+	ds.updateBucket("GET /channels/1/messages", header)
+
+	err := ds.waitForBucket(context.Background(), "GET /channels/1/messages")
+	assert.Error(t, err, "Waits longer than maxRetryWait should fail fast")
+	assert.Contains(t, err.Error(), "rate limited")
+
+	assert.NoError(t, ds.waitForBucket(context.Background(), "GET /channels/2/messages"), "Other routes should not be limited")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewDiscordServer(defaultAPIURL, "token", nil, 15, 0, 500).Server())
+}
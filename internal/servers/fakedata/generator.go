@@ -0,0 +1,445 @@
+package fakedata
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// kinds lists the values generateFake and the "x-faker" schema keyword accept.
+var kinds = []string{
+	"name", "firstName", "lastName", "email", "username", "phone", "company",
+	"street", "city", "postalCode", "region", "country", "address",
+	"uuid", "word", "sentence", "paragraph", "date", "birthDate", "dateTime", "url", "ipv4",
+	"boolean", "integer", "number",
+}
+
+// person is the identity shared by the fields of one record, so a record's email
+// matches its name and its city matches its postal code.
+type person struct {
+	first, last string
+	city        city
+	street      string
+	postal      string
+}
+
+// Address is a generated postal address.
+type Address struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	PostalCode string `json:"postalCode"`
+	Region     string `json:"region"`
+	Country    string `json:"country"`
+}
+
+// generator produces fake values from a seeded random source, so a seed reproduces
+// the same values.
+type generator struct {
+	rng    *rand.Rand
+	locale *locale
+	now    time.Time
+	person *person
+}
+
+func newGenerator(seed int64, loc *locale, now time.Time) *generator {
+	return &generator{rng: rand.New(rand.NewSource(seed)), locale: loc, now: now.Truncate(24 * time.Hour)}
+}
+
+func (g *generator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+// expand replaces "#" with a digit, "%" with a digit from 2 to 9 and "?" with an
+// upper case letter.
+func (g *generator) expand(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '#':
+			b.WriteByte(byte('0' + g.rng.Intn(10)))
+		case '%':
+			b.WriteByte(byte('2' + g.rng.Intn(8)))
+		case '?':
+			b.WriteByte(byte('A' + g.rng.Intn(26)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// current returns the identity of the record being generated, creating it on first use.
+func (g *generator) current() *person {
+	if g.person == nil {
+		c := g.locale.Cities[g.rng.Intn(len(g.locale.Cities))]
+		number := strconv.Itoa(1 + g.rng.Intn(199))
+		street := strings.NewReplacer("{number}", number, "{street}", g.pick(g.locale.Streets)).Replace(g.locale.StreetFormat)
+		g.person = &person{
+			first:  g.pick(g.locale.FirstNames),
+			last:   g.pick(g.locale.LastNames),
+			city:   c,
+			street: street,
+			postal: g.expand(c.Postal),
+		}
+	}
+	return g.person
+}
+
+// reset starts a new identity for the next record.
+func (g *generator) reset() {
+	g.person = nil
+}
+
+// handle turns a name into a lower case ASCII user name part.
+func handle(s string) string {
+	s = asciiFold.Replace(s)
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (g *generator) username() string {
+	p := g.current()
+	switch g.rng.Intn(3) {
+	case 0:
+		return handle(p.first) + "." + handle(p.last)
+	case 1:
+		return handle(p.first)[:1] + handle(p.last) + strconv.Itoa(g.rng.Intn(100))
+	default:
+		return handle(p.first) + "_" + handle(p.last)[:1] + strconv.Itoa(1950+g.rng.Intn(56))
+	}
+}
+
+func (g *generator) company() string {
+	form := g.pick(g.locale.CompanyForms)
+	for strings.Contains(form, "{last}") {
+		form = strings.Replace(form, "{last}", g.pick(g.locale.LastNames), 1)
+	}
+	return strings.ReplaceAll(form, "{suffix}", g.pick(g.locale.Suffixes))
+}
+
+func (g *generator) address() Address {
+	p := g.current()
+	return Address{
+		Street:     p.street,
+		City:       p.city.Name,
+		PostalCode: p.postal,
+		Region:     p.city.Region,
+		Country:    g.locale.Country,
+	}
+}
+
+// addressLine renders an address in the locale's format.
+func (g *generator) addressLine(a Address) string {
+	return strings.NewReplacer(
+		"{street}", a.Street, "{city}", a.City, "{postal}", a.PostalCode, "{region}", a.Region,
+	).Replace(g.locale.AddressFormat)
+}
+
+func (g *generator) words(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.pick(loremWords)
+	}
+	return words
+}
+
+func (g *generator) sentence() string {
+	s := strings.Join(g.words(6+g.rng.Intn(10)), " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+func (g *generator) paragraph() string {
+	sentences := make([]string, 3+g.rng.Intn(4))
+	for i := range sentences {
+		sentences[i] = g.sentence()
+	}
+	return strings.Join(sentences, " ")
+}
+
+func (g *generator) uuid() string {
+	b := make([]byte, 16)
+	g.rng.Read(b)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// date returns a time within the last few years, or a birth date for adults.
+func (g *generator) date(birth bool) time.Time {
+	if birth {
+		return g.now.AddDate(-18-g.rng.Intn(62), 0, -g.rng.Intn(365))
+	}
+	return g.now.Add(-time.Duration(g.rng.Int63n(int64(5 * 365 * 24 * time.Hour))))
+}
+
+// value generates a single value of a kind.
+func (g *generator) value(kind string) (interface{}, error) {
+	switch kind {
+	case "name":
+		p := g.current()
+		return p.first + " " + p.last, nil
+	case "firstName":
+		return g.current().first, nil
+	case "lastName":
+		return g.current().last, nil
+	case "email":
+		p := g.current()
+		return handle(p.first) + "." + handle(p.last) + "@" + g.pick(g.locale.Domains), nil
+	case "username":
+		return g.username(), nil
+	case "phone":
+		return g.expand(g.pick(g.locale.Phones)), nil
+	case "company":
+		return g.company(), nil
+	case "street":
+		return g.current().street, nil
+	case "city":
+		return g.current().city.Name, nil
+	case "postalCode":
+		return g.current().postal, nil
+	case "region":
+		return g.current().city.Region, nil
+	case "country":
+		return g.locale.Country, nil
+	case "address":
+		return g.address(), nil
+	case "uuid":
+		return g.uuid(), nil
+	case "word":
+		return g.pick(loremWords), nil
+	case "sentence":
+		return g.sentence(), nil
+	case "paragraph":
+		return g.paragraph(), nil
+	case "date":
+		return g.date(false).Format("2006-01-02"), nil
+	case "birthDate":
+		return g.date(true).Format("2006-01-02"), nil
+	case "dateTime":
+		return g.date(false).Format(time.RFC3339), nil
+	case "url":
+		domain := g.pick(g.locale.Domains)
+		return "https://www." + handle(g.company()) + "." + domain[strings.IndexByte(domain, '.')+1:] + "/", nil
+	case "ipv4":
+		// Documentation ranges only, so generated addresses never reach a real host
+		nets := []string{"192.0.2.", "198.51.100.", "203.0.113."}
+		return g.pick(nets) + strconv.Itoa(1+g.rng.Intn(254)), nil
+	case "boolean":
+		return g.rng.Intn(2) == 1, nil
+	case "integer":
+		return g.rng.Intn(1000), nil
+	case "number":
+		return float64(g.rng.Intn(100000)) / 100, nil
+	}
+	return nil, fmt.Errorf("unknown kind %q; use %s", kind, strings.Join(kinds, ", "))
+}
+
+// nameHints maps normalized property names to kinds for schemas without "x-faker".
+var nameHints = map[string]string{
+	"name": "name", "fullname": "name", "displayname": "name",
+	"firstname": "firstName", "givenname": "firstName", "forename": "firstName",
+	"lastname": "lastName", "surname": "lastName", "familyname": "lastName",
+	"email": "email", "emailaddress": "email", "mail": "email",
+	"username": "username", "login": "username", "handle": "username", "user": "username",
+	"phone": "phone", "phonenumber": "phone", "mobile": "phone", "telephone": "phone", "tel": "phone",
+	"company": "company", "companyname": "company", "organization": "company", "organisation": "company", "employer": "company",
+	"street": "street", "streetaddress": "street", "address1": "street", "addressline1": "street",
+	"city": "city", "town": "city",
+	"zip": "postalCode", "zipcode": "postalCode", "postcode": "postalCode", "postalcode": "postalCode", "plz": "postalCode",
+	"state": "region", "region": "region", "province": "region", "county": "region",
+	"country": "country",
+	"id":      "uuid", "uuid": "uuid", "guid": "uuid",
+	"url": "url", "website": "url", "homepage": "url",
+	"ip": "ipv4", "ipaddress": "ipv4",
+	"description": "paragraph", "bio": "paragraph", "summary": "paragraph", "about": "paragraph", "notes": "paragraph", "comment": "paragraph",
+	"title": "sentence", "subject": "sentence", "headline": "sentence",
+	"date": "date", "createdat": "dateTime", "updatedat": "dateTime", "timestamp": "dateTime",
+	"birthdate": "birthDate", "dateofbirth": "birthDate", "dob": "birthDate", "birthday": "birthDate",
+}
+
+// hintFor guesses a kind from a property name.
+func hintFor(name string) string {
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+	return nameHints[key]
+}
+
+// maxDepth bounds schema nesting.
+const maxDepth = 10
+
+// fromSchema generates a value for a JSON Schema. Supported are type, properties, items,
+// minItems, maxItems, enum, const, format, minimum, maximum, minLength, maxLength and the
+// "x-faker" keyword naming a kind. Property names such as "email" or "city" are used
+// as hints when a schema names no kind.
+func (g *generator) fromSchema(schema map[string]interface{}, name string, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("schema is nested deeper than %d levels", maxDepth)
+	}
+	if v, ok := schema["const"]; ok {
+		return v, nil
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rng.Intn(len(enum))], nil
+	}
+	if kind, ok := schema["x-faker"].(string); ok {
+		return g.value(kind)
+	}
+
+	typ := schemaType(schema)
+	switch typ {
+	case "object":
+		props, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		// Sorted so a seed reproduces the same record
+		sort.Strings(keys)
+		obj := make(map[string]interface{}, len(props))
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("property %s: schema must be an object", k)
+			}
+			v, err := g.fromSchema(sub, k, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", k, err)
+			}
+			obj[k] = v
+		}
+		return obj, nil
+
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			items = map[string]interface{}{"type": "string"}
+		}
+		lo := intKeyword(schema, "minItems", 1)
+		hi := intKeyword(schema, "maxItems", lo+4)
+		hi = min(max(hi, lo), lo+100)
+		n := lo + g.rng.Intn(hi-lo+1)
+		list := make([]interface{}, n)
+		for i := range list {
+			v, err := g.fromSchema(items, singular(name), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+
+	case "integer", "number":
+		if _, ok := schema["minimum"]; !ok && typ == "integer" && strings.EqualFold(name, "age") {
+			return 18 + g.rng.Intn(72), nil
+		}
+		lo := floatKeyword(schema, "minimum", 0)
+		hi := floatKeyword(schema, "maximum", lo+1000)
+		if hi < lo {
+			return nil, fmt.Errorf("maximum %v is below minimum %v", hi, lo)
+		}
+		if typ == "integer" {
+			return int64(lo) + g.rng.Int63n(int64(hi)-int64(lo)+1), nil
+		}
+		return float64(int64((lo+g.rng.Float64()*(hi-lo))*100)) / 100, nil
+
+	case "boolean":
+		return g.rng.Intn(2) == 1, nil
+
+	case "null":
+		return nil, nil
+
+	case "string":
+		kind := formatKinds[stringKeyword(schema, "format")]
+		if kind == "" {
+			kind = hintFor(name)
+		}
+		var s string
+		if kind != "" {
+			v, err := g.value(kind)
+			if err != nil {
+				return nil, err
+			}
+			if a, ok := v.(Address); ok {
+				v = g.addressLine(a)
+			}
+			s = fmt.Sprint(v)
+		} else {
+			s = strings.Join(g.words(1+g.rng.Intn(3)), " ")
+		}
+		if maxLen := intKeyword(schema, "maxLength", 0); maxLen > 0 && len([]rune(s)) > maxLen {
+			s = strings.TrimSpace(string([]rune(s)[:maxLen]))
+		}
+		for minLen := intKeyword(schema, "minLength", 0); len([]rune(s)) < minLen; {
+			s += " " + g.pick(loremWords)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", typ)
+}
+
+// formatKinds maps JSON Schema string formats to kinds.
+var formatKinds = map[string]string{
+	"email": "email", "date": "date", "date-time": "dateTime", "uuid": "uuid",
+	"uri": "url", "url": "url", "ipv4": "ipv4", "hostname": "word",
+}
+
+// schemaType returns the schema's type, taking the first non-null one of a type list and
+// inferring object and array from properties and items.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+		return "null"
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return "string"
+}
+
+func intKeyword(schema map[string]interface{}, key string, def int) int {
+	if v, ok := schema[key].(float64); ok {
+		return int(v)
+	}
+	return def
+}
+
+func floatKeyword(schema map[string]interface{}, key string, def float64) float64 {
+	if v, ok := schema[key].(float64); ok {
+		return v
+	}
+	return def
+}
+
+func stringKeyword(schema map[string]interface{}, key string) string {
+	s, _ := schema[key].(string)
+	return s
+}
+
+// singular turns an array property name into a hint for its items, e.g. "emails".
+func singular(name string) string {
+	if strings.HasSuffix(name, "ies") {
+		return strings.TrimSuffix(name, "ies") + "y"
+	}
+	if strings.HasSuffix(name, "sses") {
+		return strings.TrimSuffix(name, "es")
+	}
+	return strings.TrimSuffix(name, "s")
+}
@@ -0,0 +1,181 @@
+package fakedata
+
+import "strings"
+
+// city is a city with the postal code prefix and region used to build matching addresses.
+type city struct {
+	Name   string
+	Postal string // postal code pattern, see expand
+	Region string
+}
+
+// locale holds the data used to generate values for one language and country.
+type locale struct {
+	Country      string
+	FirstNames   []string
+	LastNames    []string
+	Streets      []string
+	StreetFormat string // "{number} {street}" or "{street} {number}"
+	Cities       []city
+	CompanyForms []string // "{last} {suffix}" style templates
+	Suffixes     []string
+	Domains      []string
+	Phones       []string // patterns, see expand
+	// AddressFormat joins street, postal code, city and region into one line
+	AddressFormat string
+}
+
+var locales = map[string]*locale{
+	"en_US": {
+		Country: "United States",
+		FirstNames: []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda",
+			"David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas",
+			"Sarah", "Christopher", "Karen", "Daniel", "Emily", "Matthew", "Ashley", "Anthony", "Olivia",
+			"Mark", "Sophia", "Andrew", "Hannah"},
+		LastNames: []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+			"Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore",
+			"Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker", "Young",
+			"Allen", "King", "Wright"},
+		Streets: []string{"Maple Ave", "Oak St", "Washington Blvd", "Lake Dr", "Park Ave", "Cedar Ln",
+			"Elm St", "Pine St", "Main St", "Sunset Blvd", "Hillcrest Rd", "Lincoln Way", "Meadow Ln",
+			"Church St", "River Rd"},
+		StreetFormat: "{number} {street}",
+		Cities: []city{
+			{"Springfield", "627##", "IL"}, {"Portland", "972##", "OR"}, {"Austin", "787##", "TX"},
+			{"Columbus", "432##", "OH"}, {"Denver", "802##", "CO"}, {"Madison", "537##", "WI"},
+			{"Raleigh", "276##", "NC"}, {"Sacramento", "958##", "CA"}, {"Boise", "837##", "ID"},
+			{"Albany", "122##", "NY"}, {"Tampa", "336##", "FL"}, {"Phoenix", "850##", "AZ"},
+		},
+		CompanyForms:  []string{"{last} {suffix}", "{last} & {last}", "{last}-{last} {suffix}"},
+		Suffixes:      []string{"Inc", "LLC", "Group", "Corp", "Partners", "Holdings", "Industries"},
+		Domains:       []string{"example.com", "example.org", "example.net", "mail.test", "inbox.test"},
+		Phones:        []string{"(%##) %##-####", "%##-%##-####", "+1 %## %## ####"},
+		AddressFormat: "{street}, {city}, {region} {postal}",
+	},
+	"en_GB": {
+		Country: "United Kingdom",
+		FirstNames: []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Ava", "Jack", "Mia", "Jacob",
+			"Ivy", "Charlie", "Lily", "Thomas", "Isabella", "Oscar", "Rosie", "William", "Sophia", "James",
+			"Grace", "Alfie", "Freya", "Henry", "Florence", "Archie", "Evie", "Leo", "Poppy", "Arthur", "Ella"},
+		LastNames: []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Johnson", "Davies",
+			"Robinson", "Wright", "Thompson", "Evans", "Walker", "White", "Roberts", "Green", "Hall",
+			"Wood", "Jackson", "Clarke", "Hughes", "Edwards", "Turner", "Hill", "Cooper", "Ward", "Morris",
+			"Harrison", "Baker", "Lewis"},
+		Streets: []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Mill Lane",
+			"Green Lane", "Park Road", "Manor Road", "Kings Road", "Queens Road", "The Crescent",
+			"Windsor Close", "New Road", "School Lane", "Chapel Street"},
+		StreetFormat: "{number} {street}",
+		Cities: []city{
+			{"London", "SW# #??", "Greater London"}, {"Manchester", "M# #??", "Greater Manchester"},
+			{"Birmingham", "B# #??", "West Midlands"}, {"Leeds", "LS# #??", "West Yorkshire"},
+			{"Bristol", "BS# #??", "Bristol"}, {"York", "YO# #??", "North Yorkshire"},
+			{"Oxford", "OX# #??", "Oxfordshire"}, {"Cambridge", "CB# #??", "Cambridgeshire"},
+			{"Brighton", "BN# #??", "East Sussex"}, {"Norwich", "NR# #??", "Norfolk"},
+		},
+		CompanyForms:  []string{"{last} {suffix}", "{last} & {last} {suffix}", "{last} and Sons"},
+		Suffixes:      []string{"Ltd", "PLC", "Group", "Holdings", "Partners"},
+		Domains:       []string{"example.co.uk", "example.com", "mail.test", "post.test"},
+		Phones:        []string{"07### ######", "020 #### ####", "+44 7### ######"},
+		AddressFormat: "{street}, {city} {postal}",
+	},
+	"de_DE": {
+		Country: "Deutschland",
+		FirstNames: []string{"Lukas", "Anna", "Leon", "Lena", "Finn", "Marie", "Paul", "Sophie", "Jonas",
+			"Emma", "Felix", "Laura", "Maximilian", "Julia", "Elias", "Hannah", "Noah", "Lea", "Ben",
+			"Mia", "Jürgen", "Sabine", "Stefan", "Katrin", "Tobias", "Jana", "Matthias", "Anja", "Florian", "Nicole"},
+		LastNames: []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner",
+			"Becker", "Schulz", "Hoffmann", "Schäfer", "Koch", "Bauer", "Richter", "Klein", "Wolf",
+			"Schröder", "Neumann", "Schwarz", "Zimmermann", "Braun", "Krüger", "Hofmann", "Hartmann",
+			"Lange", "Schmitt", "Werner", "Krause", "Meier", "Lehmann"},
+		Streets: []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße",
+			"Bergstraße", "Lindenstraße", "Kirchstraße", "Waldstraße", "Ringstraße", "Am Markt",
+			"Goethestraße", "Schillerstraße", "Mühlenweg", "Birkenweg"},
+		StreetFormat: "{street} {number}",
+		Cities: []city{
+			{"Berlin", "10###", "Berlin"}, {"Hamburg", "20###", "Hamburg"}, {"München", "80###", "Bayern"},
+			{"Köln", "50###", "Nordrhein-Westfalen"}, {"Frankfurt am Main", "60###", "Hessen"},
+			{"Stuttgart", "70###", "Baden-Württemberg"}, {"Leipzig", "04###", "Sachsen"},
+			{"Dresden", "01###", "Sachsen"}, {"Hannover", "30###", "Niedersachsen"},
+			{"Nürnberg", "90###", "Bayern"}, {"Bremen", "28###", "Bremen"},
+		},
+		CompanyForms:  []string{"{last} {suffix}", "{last} & {last} {suffix}", "{last} {last} {suffix}"},
+		Suffixes:      []string{"GmbH", "AG", "KG", "GmbH & Co. KG", "e.K."},
+		Domains:       []string{"example.de", "example.com", "post.test", "mail.test"},
+		Phones:        []string{"015# ########", "030 ########", "+49 17# #######"},
+		AddressFormat: "{street}, {postal} {city}",
+	},
+	"fr_FR": {
+		Country: "France",
+		FirstNames: []string{"Gabriel", "Louise", "Raphaël", "Jade", "Léo", "Ambre", "Louis", "Emma",
+			"Lucas", "Alice", "Adam", "Rose", "Arthur", "Chloé", "Jules", "Léa", "Hugo", "Manon", "Maël",
+			"Inès", "Nathan", "Camille", "Théo", "Juliette", "Paul", "Anna", "Nicolas", "Céline", "Éric", "Sophie"},
+		LastNames: []string{"Martin", "Bernard", "Thomas", "Petit", "Robert", "Richard", "Durand", "Dubois",
+			"Moreau", "Laurent", "Simon", "Michel", "Lefebvre", "Leroy", "Roux", "David", "Bertrand",
+			"Morel", "Fournier", "Girard", "Bonnet", "Dupont", "Lambert", "Fontaine", "Rousseau", "Vincent",
+			"Muller", "Lefèvre", "Faure", "André"},
+		Streets: []string{"rue de la Paix", "rue Victor Hugo", "avenue Jean Jaurès", "rue de la République",
+			"boulevard Voltaire", "rue du Moulin", "place de l'Église", "rue des Écoles", "avenue de la Gare",
+			"rue Pasteur", "chemin des Vignes", "rue du Château", "allée des Tilleuls", "rue Nationale",
+			"impasse des Lilas"},
+		StreetFormat: "{number} {street}",
+		Cities: []city{
+			{"Paris", "750##", "Île-de-France"}, {"Lyon", "6900#", "Auvergne-Rhône-Alpes"},
+			{"Marseille", "130##", "Provence-Alpes-Côte d'Azur"}, {"Toulouse", "310##", "Occitanie"},
+			{"Nantes", "440##", "Pays de la Loire"}, {"Bordeaux", "330##", "Nouvelle-Aquitaine"},
+			{"Lille", "590##", "Hauts-de-France"}, {"Strasbourg", "670##", "Grand Est"},
+			{"Rennes", "350##", "Bretagne"}, {"Nice", "060##", "Provence-Alpes-Côte d'Azur"},
+		},
+		CompanyForms:  []string{"{last} {suffix}", "{last} et {last}", "{last} et Fils"},
+		Suffixes:      []string{"SA", "SARL", "SAS", "Groupe"},
+		Domains:       []string{"example.fr", "example.com", "courriel.test", "mail.test"},
+		Phones:        []string{"06 ## ## ## ##", "07 ## ## ## ##", "01 ## ## ## ##", "+33 6 ## ## ## ##"},
+		AddressFormat: "{street}, {postal} {city}",
+	},
+	"es_ES": {
+		Country: "España",
+		FirstNames: []string{"Hugo", "Lucía", "Martín", "Sofía", "Daniel", "Martina", "Pablo", "María",
+			"Alejandro", "Julia", "Lucas", "Paula", "Álvaro", "Valeria", "Adrián", "Emma", "Mateo", "Daniela",
+			"David", "Carla", "Javier", "Carmen", "José", "Elena", "Manuel", "Laura", "Sergio", "Ana", "Jorge", "Marta"},
+		LastNames: []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez",
+			"Pérez", "Gómez", "Martín", "Jiménez", "Ruiz", "Hernández", "Díaz", "Moreno", "Muñoz", "Álvarez",
+			"Romero", "Alonso", "Gutiérrez", "Navarro", "Torres", "Domínguez", "Vázquez", "Ramos", "Gil",
+			"Ramírez", "Serrano", "Blanco", "Molina"},
+		Streets: []string{"Calle Mayor", "Calle Real", "Avenida de la Constitución", "Calle del Sol",
+			"Plaza de España", "Calle de la Iglesia", "Paseo del Prado", "Calle Nueva", "Calle San José",
+			"Avenida de Andalucía", "Calle del Carmen", "Calle Larga", "Camino Real", "Calle de la Luna",
+			"Ronda de Toledo"},
+		StreetFormat: "{street}, {number}",
+		Cities: []city{
+			{"Madrid", "280##", "Comunidad de Madrid"}, {"Barcelona", "080##", "Cataluña"},
+			{"Valencia", "460##", "Comunidad Valenciana"}, {"Sevilla", "410##", "Andalucía"},
+			{"Zaragoza", "500##", "Aragón"}, {"Málaga", "290##", "Andalucía"}, {"Bilbao", "480##", "País Vasco"},
+			{"Granada", "180##", "Andalucía"}, {"Salamanca", "370##", "Castilla y León"},
+			{"Palma", "070##", "Islas Baleares"},
+		},
+		CompanyForms:  []string{"{last} {suffix}", "{last} y {last} {suffix}", "Grupo {last}"},
+		Suffixes:      []string{"S.L.", "S.A.", "S.L.U.", "y Asociados"},
+		Domains:       []string{"example.es", "example.com", "correo.test", "mail.test"},
+		Phones:        []string{"6## ### ###", "91# ### ###", "+34 6## ### ###"},
+		AddressFormat: "{street}, {postal} {city}",
+	},
+}
+
+// localeNames lists the supported locales in a stable order.
+var localeNames = []string{"en_US", "en_GB", "de_DE", "fr_FR", "es_ES"}
+
+// loremWords are the words lorem text is made of.
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod
+	tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation
+	ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit
+	esse cillum fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui officia
+	deserunt mollit anim id est laborum curabitur pretium tincidunt lacus nulla gravida orci a odio
+	nullam varius turpis et commodo pharetra est eros bibendum elit nec luctus magna felis sollicitudin
+	mauris integer dapibus`)
+
+// asciiFold maps accented letters to ASCII for user names and email addresses.
+var asciiFold = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"á", "a", "à", "a", "â", "a", "é", "e", "è", "e", "ê", "e", "ë", "e", "í", "i", "î", "i", "ï", "i",
+	"ó", "o", "ô", "o", "ú", "u", "ù", "u", "û", "u", "ñ", "n", "ç", "c",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ñ", "N", "Ç", "C", "Ë", "E", "Î", "I", "Ô", "O",
+)
@@ -0,0 +1,392 @@
+package fakedata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// FakeDataServer is an MCP server that generates fake but realistic test data.
+type FakeDataServer struct {
+	server        *server.MCPServer
+	defaultLocale string
+	maxCount      int
+
+	// now and newSeed are replaced in tests.
+	now     func() time.Time
+	newSeed func() int64
+}
+
+// NewFakeDataServer creates a new FakeDataServer instance.
+func NewFakeDataServer(defaultLocale string, maxCount int) *FakeDataServer {
+	log.Printf("FakeDataServer created: defaultLocale=%s, maxCount=%d", defaultLocale, maxCount)
+
+	s := &FakeDataServer{
+		defaultLocale: defaultLocale,
+		maxCount:      maxCount,
+		now:           time.Now,
+		newSeed:       func() int64 { return time.Now().UnixNano() % 1000000000 },
+	}
+
+	mcpServer := server.NewMCPServer(
+		"fakedata-server", // server name
+		"1.0.0",           // version
+	)
+
+	// Register generateFake tool
+	generateFakeTool := mcp.NewTool("generateFake",
+		mcp.WithDescription("Generates fake values such as names, emails, phone numbers, addresses and companies. "+
+			"Values within one item belong together, e.g. an email matches the name"),
+		mcp.WithString("kind",
+			mcp.Description("Kind of value to generate"),
+			mcp.Enum(kinds...),
+			mcp.Required(),
+		),
+		mcp.WithNumber("count",
+			mcp.Description(fmt.Sprintf("Number of values (default: 1, maximum: %d)", maxCount)),
+		),
+		mcp.WithString("locale",
+			mcp.Description(fmt.Sprintf("Locale of names, addresses and phone numbers (default: %s)", defaultLocale)),
+			mcp.Enum(localeNames...),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed that reproduces the same values; a random seed is used and reported when omitted"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text, one value per line)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register generateLorem tool
+	generateLoremTool := mcp.NewTool("generateLorem",
+		mcp.WithDescription("Generates lorem ipsum placeholder text"),
+		mcp.WithString("unit",
+			mcp.Description("Unit of count (default: paragraphs)"),
+			mcp.Enum("words", "sentences", "paragraphs"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of units (default: 1)"),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed that reproduces the same text"),
+		),
+	)
+
+	// Register generateRecords tool
+	generateRecordsTool := mcp.NewTool("generateRecords",
+		mcp.WithDescription("Generates records matching a JSON Schema, for test fixtures. Supports type, properties, items, "+
+			"minItems, maxItems, enum, const, format, minimum, maximum, minLength and maxLength. A property's kind is "+
+			"guessed from its name (email, firstName, city, ...) or set with the \"x-faker\" keyword, one of: "+
+			strings.Join(kinds, ", ")),
+		mcp.WithObject("schema",
+			mcp.Description("JSON Schema of one record"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("count",
+			mcp.Description(fmt.Sprintf("Number of records (default: 10, maximum: %d)", maxCount)),
+		),
+		mcp.WithString("locale",
+			mcp.Description(fmt.Sprintf("Locale of names, addresses and phone numbers (default: %s)", defaultLocale)),
+			mcp.Enum(localeNames...),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed that reproduces the same records; a random seed is used and reported when omitted"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: json)"),
+			mcp.Enum("json", "jsonl"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, generateFakeTool, s.handleGenerateFake)
+	middleware.AddTool(mcpServer, generateLoremTool, s.handleGenerateLorem)
+	middleware.AddTool(mcpServer, generateRecordsTool, s.handleGenerateRecords)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// dataResult returns generated data, followed by the seed when it was chosen randomly
+// so the data can be reproduced.
+func dataResult(data string, seed int64, random bool) *mcp.CallToolResult {
+	result := textResult(data)
+	if random {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Generated with seed %d; pass it as seed to reproduce this data", seed),
+		})
+	}
+	return result
+}
+
+// generatorFor validates the shared parameters and returns a generator.
+func (s *FakeDataServer) generatorFor(localeName string, seed *int64, count, defaultCount int) (*generator, int, int64, error) {
+	if localeName == "" {
+		localeName = s.defaultLocale
+	}
+	loc, ok := locales[localeName]
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("unsupported locale %q; use %s", localeName, strings.Join(localeNames, ", "))
+	}
+	if count == 0 {
+		count = defaultCount
+	}
+	if count < 0 || count > s.maxCount {
+		return nil, 0, 0, fmt.Errorf("count must be between 1 and %d", s.maxCount)
+	}
+	value := s.newSeed()
+	if seed != nil {
+		value = *seed
+	}
+	return newGenerator(value, loc, s.now()), count, value, nil
+}
+
+// handleGenerateFake handles the value generation request.
+func (s *FakeDataServer) handleGenerateFake(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generate fake request processing")
+
+	var args struct {
+		Kind   string `json:"kind"`
+		Count  int    `json:"count"`
+		Locale string `json:"locale"`
+		Seed   *int64 `json:"seed"`
+		Format string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	g, count, seed, err := s.generatorFor(args.Locale, args.Seed, args.Count, 1)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		g.reset()
+		v, err := g.value(args.Kind)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	log.Printf("Generate fake request completed: kind=%s, count=%d, seed=%d", args.Kind, count, seed)
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+		return dataResult(string(data), seed, args.Seed == nil), nil
+	}
+	lines := make([]string, len(values))
+	for i, v := range values {
+		if a, ok := v.(Address); ok {
+			v = g.addressLine(a)
+		}
+		lines[i] = fmt.Sprint(v)
+	}
+	return dataResult(strings.Join(lines, "\n"), seed, args.Seed == nil), nil
+}
+
+// handleGenerateLorem handles the lorem ipsum request.
+func (s *FakeDataServer) handleGenerateLorem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generate lorem request processing")
+
+	var args struct {
+		Unit  string `json:"unit"`
+		Count int    `json:"count"`
+		Seed  *int64 `json:"seed"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	g, count, seed, err := s.generatorFor("", args.Seed, args.Count, 1)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var text string
+	switch args.Unit {
+	case "words":
+		text = strings.Join(g.words(count), " ")
+	case "sentences":
+		sentences := make([]string, count)
+		for i := range sentences {
+			sentences[i] = g.sentence()
+		}
+		text = strings.Join(sentences, " ")
+	case "", "paragraphs":
+		paragraphs := make([]string, count)
+		for i := range paragraphs {
+			paragraphs[i] = g.paragraph()
+		}
+		text = strings.Join(paragraphs, "\n\n")
+	default:
+		log.Printf("Error: Invalid unit: %s", args.Unit)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid unit %q; use words, sentences or paragraphs", args.Unit)
+	}
+
+	log.Printf("Generate lorem request completed: %d %s, seed=%d", count, args.Unit, seed)
+	return dataResult(text, seed, args.Seed == nil), nil
+}
+
+// handleGenerateRecords handles the schema based generation request.
+func (s *FakeDataServer) handleGenerateRecords(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generate records request processing")
+
+	var args struct {
+		Schema json.RawMessage `json:"schema"`
+		Count  int             `json:"count"`
+		Locale string          `json:"locale"`
+		Seed   *int64          `json:"seed"`
+		Format string          `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	// Clients that cannot send objects may pass the schema as a JSON string
+	var raw string
+	if json.Unmarshal(args.Schema, &raw) == nil {
+		args.Schema = json.RawMessage(raw)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(args.Schema, &schema); err != nil || schema == nil {
+		log.Println("Error: Invalid schema")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "schema must be a JSON Schema object")
+	}
+
+	g, count, seed, err := s.generatorFor(args.Locale, args.Seed, args.Count, 10)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	records := make([]interface{}, count)
+	for i := range records {
+		g.reset()
+		if records[i], err = g.fromSchema(schema, "", 0); err != nil {
+			log.Printf("Error: %v", err)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid schema: %w", err)
+		}
+	}
+
+	log.Printf("Generate records request completed: count=%d, seed=%d", count, seed)
+	if args.Format == "jsonl" {
+		lines := make([]string, len(records))
+		for i, r := range records {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode result: %w", err)
+			}
+			lines[i] = string(data)
+		}
+		return dataResult(strings.Join(lines, "\n"), seed, args.Seed == nil), nil
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return dataResult(string(data), seed, args.Seed == nil), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *FakeDataServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the fakedata server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("fakedata", flag.ContinueOnError)
+	var (
+		defaultLocale string
+		maxCount      int
+	)
+	fs.StringVar(&defaultLocale, "locale", "en_US", "Default locale: "+strings.Join(localeNames, ", "))
+	fs.IntVar(&maxCount, "max-count", 1000, "Maximum number of values or records per request")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting fake data server: locale=%s, max-count=%d", defaultLocale, maxCount)
+
+	if _, ok := locales[defaultLocale]; !ok {
+		log.Printf("Error: Unsupported locale %q; use %s", defaultLocale, strings.Join(localeNames, ", "))
+		return nil, transport.Flags{}, fmt.Errorf("unsupported locale %q", defaultLocale)
+	}
+
+	// Create FakeDataServer instance
+	fakeDataServer := NewFakeDataServer(defaultLocale, maxCount)
+	log.Println("FakeDataServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), fakeDataServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return fakeDataServer.Server(), transportFlags, nil
+}
+
+// Run starts the fakedata server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[FakeDataServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create fakedata server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("FakeDataServer shutdown")
+	return nil
+}
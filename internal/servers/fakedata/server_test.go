@@ -0,0 +1,274 @@
+package fakedata
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer() *FakeDataServer {
+	s := NewFakeDataServer("en_US", 100)
+	s.now = func() time.Time { return time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC) }
+	s.newSeed = func() int64 { return 4242 }
+	return s
+}
+
+// FakeDataServer creation test
+func TestNewFakeDataServer(t *testing.T) {
+	s := NewFakeDataServer("de_DE", 500)
+
+	assert.NotNil(t, s, "FakeDataServer instance should be created")
+	assert.Equal(t, "de_DE", s.defaultLocale, "Default locale should match")
+	assert.Equal(t, 500, s.maxCount, "Max count should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewFakeDataServer("en_US", 100)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test that every kind generates a value in every locale
+func TestKinds(t *testing.T) {
+	for _, name := range localeNames {
+		g := newGenerator(1, locales[name], time.Now())
+		for _, kind := range kinds {
+			g.reset()
+			v, err := g.value(kind)
+			require.NoError(t, err, "%s/%s", name, kind)
+			if kind != "boolean" && kind != "integer" {
+				assert.NotEmpty(t, v, "%s/%s", name, kind)
+			}
+		}
+	}
+	_, err := newGenerator(1, locales["en_US"], time.Now()).value("spaceship")
+	assert.ErrorContains(t, err, "unknown kind")
+}
+
+// Test generateFake handler
+func TestHandleGenerateFake(t *testing.T) {
+	s := newTestServer()
+
+	result, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", map[string]interface{}{
+		"kind":  "email",
+		"count": 5,
+	}))
+	require.NoError(t, err)
+	emails := strings.Split(mcptest.ResultText(result), "\n")
+	require.Len(t, emails, 5)
+	for _, e := range emails {
+		assert.Regexp(t, `^[a-z]+\.[a-z]+@[a-z.]+$`, e)
+	}
+	require.Len(t, result.Content, 2, "A random seed should be reported")
+	assert.Equal(t, "Generated with seed 4242; pass it as seed to reproduce this data", result.Content[1].(mcp.TextContent).Text)
+
+	t.Run("seed reproduces values", func(t *testing.T) {
+		args := map[string]interface{}{"kind": "name", "count": 3, "seed": 7, "locale": "fr_FR"}
+		first, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", args))
+		require.NoError(t, err)
+		second, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", args))
+		require.NoError(t, err)
+		assert.Equal(t, mcptest.ResultText(first), mcptest.ResultText(second))
+		assert.Len(t, first.Content, 1, "A given seed should not be reported")
+	})
+
+	t.Run("addresses", func(t *testing.T) {
+		result, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", map[string]interface{}{
+			"kind": "address", "locale": "de_DE", "seed": 1, "format": "json",
+		}))
+		require.NoError(t, err)
+		var addresses []Address
+		require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &addresses))
+		require.Len(t, addresses, 1)
+		a := addresses[0]
+		assert.Equal(t, "Deutschland", a.Country)
+		assert.Regexp(t, `^\d{5}$`, a.PostalCode)
+		assert.Regexp(t, `^\D+ \d+$`, a.Street, "German streets put the number last")
+
+		result, err = s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", map[string]interface{}{
+			"kind": "address", "locale": "de_DE", "seed": 1,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, a.Street+", "+a.PostalCode+" "+a.City, mcptest.ResultText(result))
+	})
+
+	t.Run("phones", func(t *testing.T) {
+		result, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", map[string]interface{}{
+			"kind": "phone", "count": 20, "seed": 3,
+		}))
+		require.NoError(t, err)
+		for _, p := range strings.Split(mcptest.ResultText(result), "\n") {
+			assert.Regexp(t, `^(\([2-9]\d\d\) [2-9]\d\d-\d{4}|[2-9]\d\d-[2-9]\d\d-\d{4}|\+1 [2-9]\d\d [2-9]\d\d \d{4})$`, p)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"kind", map[string]interface{}{"kind": "dragon"}, "unknown kind"},
+		{"locale", map[string]interface{}{"kind": "name", "locale": "xx_XX"}, "unsupported locale"},
+		{"count", map[string]interface{}{"kind": "name", "count": 101}, "count must be between 1 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleGenerateFake(context.Background(), mcptest.NewCallToolRequest("generateFake", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Test generateLorem handler
+func TestHandleGenerateLorem(t *testing.T) {
+	s := newTestServer()
+
+	result, err := s.handleGenerateLorem(context.Background(), mcptest.NewCallToolRequest("generateLorem", map[string]interface{}{
+		"unit": "words", "count": 12,
+	}))
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(mcptest.ResultText(result)), 12)
+
+	result, err = s.handleGenerateLorem(context.Background(), mcptest.NewCallToolRequest("generateLorem", map[string]interface{}{
+		"unit": "sentences", "count": 3, "seed": 1,
+	}))
+	require.NoError(t, err)
+	assert.Len(t, regexp.MustCompile(`[A-Z][a-z ]+\.`).FindAllString(mcptest.ResultText(result), -1), 3)
+
+	result, err = s.handleGenerateLorem(context.Background(), mcptest.NewCallToolRequest("generateLorem", map[string]interface{}{"count": 2}))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(mcptest.ResultText(result), "\n\n"), 2)
+
+	_, err = s.handleGenerateLorem(context.Background(), mcptest.NewCallToolRequest("generateLorem", map[string]interface{}{"unit": "chapters"}))
+	assert.ErrorContains(t, err, "invalid unit")
+}
+
+// Test generateRecords handler
+func TestHandleGenerateRecords(t *testing.T) {
+	s := newTestServer()
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":        map[string]interface{}{"type": "string", "format": "uuid"},
+			"firstName": map[string]interface{}{"type": "string"},
+			"last_name": map[string]interface{}{"type": "string"},
+			"email":     map[string]interface{}{"type": "string"},
+			"age":       map[string]interface{}{"type": "integer"},
+			"score":     map[string]interface{}{"type": "number", "minimum": 1, "maximum": 5},
+			"role":      map[string]interface{}{"enum": []interface{}{"admin", "editor"}},
+			"active":    map[string]interface{}{"type": "boolean"},
+			"plan":      map[string]interface{}{"const": "free"},
+			"employer":  map[string]interface{}{"x-faker": "company"},
+			"address": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city":    map[string]interface{}{"type": "string"},
+					"zipCode": map[string]interface{}{"type": "string"},
+				},
+			},
+			"tags":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "maxLength": 8}, "minItems": 2, "maxItems": 2},
+			"createdAt": map[string]interface{}{"type": []interface{}{"string", "null"}, "format": "date-time"},
+		},
+	}
+
+	result, err := s.handleGenerateRecords(context.Background(), mcptest.NewCallToolRequest("generateRecords", map[string]interface{}{
+		"schema": schema,
+		"count":  5,
+		"seed":   11,
+	}))
+	require.NoError(t, err)
+	var records []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &records))
+	require.Len(t, records, 5)
+
+	cities := map[string]string{}
+	for _, c := range locales["en_US"].Cities {
+		cities[c.Name] = c.Postal[:3]
+	}
+	for _, r := range records {
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, r["id"])
+		assert.Equal(t, handle(r["firstName"].(string))+"."+handle(r["last_name"].(string)), strings.Split(r["email"].(string), "@")[0],
+			"Email should match the record's name")
+		assert.GreaterOrEqual(t, r["age"], float64(18))
+		assert.LessOrEqual(t, r["age"], float64(89))
+		assert.GreaterOrEqual(t, r["score"], float64(1))
+		assert.LessOrEqual(t, r["score"], float64(5))
+		assert.Contains(t, []interface{}{"admin", "editor"}, r["role"])
+		assert.IsType(t, true, r["active"])
+		assert.Equal(t, "free", r["plan"])
+		assert.NotEmpty(t, r["employer"])
+		address := r["address"].(map[string]interface{})
+		assert.True(t, strings.HasPrefix(address["zipCode"].(string), cities[address["city"].(string)]), "Postal code should match the city")
+		require.Len(t, r["tags"], 2)
+		for _, tag := range r["tags"].([]interface{}) {
+			assert.LessOrEqual(t, len(tag.(string)), 8)
+		}
+		_, err := time.Parse(time.RFC3339, r["createdAt"].(string))
+		assert.NoError(t, err)
+	}
+	assert.NotEqual(t, records[0]["email"], records[1]["email"], "Each record should get its own identity")
+
+	t.Run("jsonl and string schema", func(t *testing.T) {
+		result, err := s.handleGenerateRecords(context.Background(), mcptest.NewCallToolRequest("generateRecords", map[string]interface{}{
+			"schema": `{"properties": {"name": {"type": "string"}, "city": {}}}`,
+			"count":  3,
+			"locale": "es_ES",
+			"format": "jsonl",
+		}))
+		require.NoError(t, err)
+		lines := strings.Split(mcptest.ResultText(result), "\n")
+		require.Len(t, lines, 3)
+		var record map[string]string
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Len(t, strings.Fields(record["name"]), 2)
+		assert.Len(t, result.Content, 2, "The random seed should be reported")
+	})
+
+	tests := []struct {
+		name    string
+		schema  interface{}
+		wantErr string
+	}{
+		{"not an object", "[1, 2]", "schema must be a JSON Schema object"},
+		{"bad type", map[string]interface{}{"type": "date"}, "unsupported type \"date\""},
+		{"bad property", map[string]interface{}{"properties": map[string]interface{}{"x": 1}}, "property x: schema must be an object"},
+		{"bad kind", map[string]interface{}{"properties": map[string]interface{}{"x": map[string]interface{}{"x-faker": "nope"}}}, "property x: unknown kind"},
+		{"bad range", map[string]interface{}{"type": "integer", "minimum": 10, "maximum": 1}, "maximum 1 is below minimum 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleGenerateRecords(context.Background(), mcptest.NewCallToolRequest("generateRecords", map[string]interface{}{"schema": tt.schema}))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		deep := map[string]interface{}{"type": "string"}
+		for i := 0; i < 12; i++ {
+			deep = map[string]interface{}{"properties": map[string]interface{}{"child": deep}}
+		}
+		_, err := s.handleGenerateRecords(context.Background(), mcptest.NewCallToolRequest("generateRecords", map[string]interface{}{"schema": deep}))
+		assert.ErrorContains(t, err, "nested deeper than 10 levels")
+	})
+}
+
+// Test user name folding
+func TestHandle(t *testing.T) {
+	assert.Equal(t, "juergenmueller", handle("Jürgen Müller"))
+	assert.Equal(t, "alvaro", handle("Álvaro"))
+	assert.Equal(t, "celine", handle("Céline"))
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, newTestServer().Server())
+}
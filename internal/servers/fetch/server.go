@@ -0,0 +1,406 @@
+package fetch
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/progress"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/session"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+	"github.com/mark3labs/mcphost/pkg/markdown"
+)
+
+// FetchServer is an MCP server that performs HTTP/HTTPS requests and inspects the
+// TLS certificates of servers.
+type FetchServer struct {
+	server      *server.MCPServer
+	client      *http.Client
+	userAgent   string
+	maxBodySize int64
+	recent      *resources.Recent
+	// cookies keeps a cookie jar per client session, when cookies are kept
+	cookies *session.Store
+	// timeout, guard and rootCAs apply to the TLS connections of inspectTLSCertificate
+	timeout time.Duration
+	guard   *urlguard.Policy
+	rootCAs *x509.CertPool
+}
+
+// recentResults is the number of responses kept as resources.
+const recentResults = 20
+
+// NewFetchServer creates a new FetchServer instance.
+func NewFetchServer(timeout int, userAgent string, maxBodySize int64) *FetchServer {
+	log.Printf("FetchServer created: timeout=%ds, userAgent=%s, maxBodySize=%d", timeout, userAgent, maxBodySize)
+
+	s := &FetchServer{
+		client:      httpclient.New(clientOptions(timeout, userAgent, maxBodySize)),
+		userAgent:   userAgent,
+		maxBodySize: maxBodySize,
+		timeout:     time.Duration(timeout) * time.Second,
+	}
+
+	mcpServer := resources.NewMCPServer(
+		"fetch-server", // server name
+		"1.0.0",        // version
+	)
+	s.recent = resources.NewRecent(mcpServer, "fetch://results", "fetched responses", recentResults)
+
+	// Register fetchURL tool
+	tool := mcp.NewTool("fetchURL",
+		mcp.WithDescription("Fetches data from a URL using HTTP/HTTPS. Supports GET, POST, PUT, DELETE, PATCH methods."),
+		mcp.WithString("url",
+			mcp.Description("The URL to fetch data from (must be a valid HTTP/HTTPS URL)"),
+			mcp.Required(),
+		),
+		mcp.WithString("method",
+			mcp.Description("HTTP method to use (GET, POST, PUT, DELETE, PATCH). Defaults to GET if not specified."),
+		),
+		mcp.WithString("body",
+			mcp.Description("Request body for POST, PUT, PATCH requests"),
+		),
+		mcp.WithString("contentType",
+			mcp.Description("Content-Type header for the request. For POST requests with a body, defaults to application/json"),
+		),
+		mcp.WithString("headers",
+			mcp.Description("JSON string containing additional headers to send with the request"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text): text for the status, headers and body, markdown to also convert HTML pages to Markdown, or json for an object with status_code, headers and body. raw is the same as json"),
+			mcp.Enum("text", "markdown", "json", "raw"),
+		),
+	)
+
+	// Register inspectTLSCertificate tool
+	tlsTool := params.Tool[inspectTLSArgs]("inspectTLSCertificate",
+		mcp.WithDescription("Connects to a host over TLS and returns the certificate chain it presents: subjects, issuers, names (SANs), validity dates, days left, keys and signature algorithms. Flags expired, expiring, untrusted, misnamed and weak certificates"),
+	)
+
+	middleware.AddTool(mcpServer, tool, s.handleFetchURL)
+	middleware.AddTool(mcpServer, tlsTool, s.handleInspectTLSCertificate)
+	middleware.Cacheable(mcpServer, "fetchURL", cache.Policy{TTL: 5 * time.Minute, Key: s.cacheKey})
+	s.server = mcpServer
+	return s
+}
+
+// KeepCookies makes the server keep the cookies set by the sites it fetches and send
+// them back, like a browser, with separate cookies for each client session.
+func (s *FetchServer) KeepCookies() {
+	s.cookies = session.Scoped(s.server)
+}
+
+// clientFor returns the HTTP client of the session of ctx.
+func (s *FetchServer) clientFor(ctx context.Context) *http.Client {
+	if s.cookies == nil {
+		return s.client
+	}
+	return session.Value(ctx, s.cookies, "cookies", func() *http.Client {
+		// cookiejar.New fails only with options
+		jar, _ := cookiejar.New(nil)
+		client := *s.client
+		client.Jar = jar
+		return &client
+	})
+}
+
+// cacheKey identifies GET requests by URL, headers and format. Other methods change
+// things and are never cached, nor are responses depending on the cookies of a session.
+func (s *FetchServer) cacheKey(req mcp.CallToolRequest) (string, bool) {
+	if s.cookies != nil {
+		return "", false
+	}
+	if method, _ := req.Params.Arguments["method"].(string); method != "" && !strings.EqualFold(method, http.MethodGet) {
+		return "", false
+	}
+	return cache.Args("url", "headers", "format")(req)
+}
+
+// handleFetchURL handles the URL fetch request.
+func (s *FetchServer) handleFetchURL(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting fetch request processing")
+
+	var args struct {
+		URL         string `json:"url" param:"required"`
+		Method      string `json:"method,omitempty"`
+		Body        string `json:"body,omitempty"`
+		ContentType string `json:"contentType,omitempty"`
+		Headers     string `json:"headers,omitempty"`
+		Format      string `json:"format,omitempty" param:"enum=text|markdown|json|raw"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// Log request details (without sensitive information)
+	log.Printf("Fetch request: URL=%s, Method=%s", args.URL, args.Method)
+
+	// Validate URL
+	if !strings.HasPrefix(args.URL, "http://") && !strings.HasPrefix(args.URL, "https://") {
+		errMsg := "URL must begin with http:// or https://"
+		log.Printf("Error: %s", errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Use GET as default method if not specified
+	method := args.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	// Create request
+	var reqBody io.Reader
+	if args.Body != "" {
+		reqBody = strings.NewReader(args.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, args.URL, reqBody)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set Content-Type if provided
+	if args.ContentType != "" {
+		httpReq.Header.Set("Content-Type", args.ContentType)
+	} else if args.Body != "" && (method == "POST" || method == "PUT" || method == "PATCH") {
+		// Default to application/json for POST/PUT/PATCH with body
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	// Add custom headers if provided
+	if args.Headers != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(args.Headers), &headers); err != nil {
+			log.Printf("Error: Invalid headers JSON: %v", err)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid headers JSON: %w", err)
+		}
+
+		for key, value := range headers {
+			httpReq.Header.Set(key, value)
+		}
+	}
+
+	// Send the request
+	log.Printf("Sending %s request to %s", method, args.URL)
+	resp, err := s.clientFor(ctx).Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	var total int64
+	if resp.ContentLength > 0 {
+		total = min(resp.ContentLength, s.maxBodySize)
+	}
+	reporter := progress.New(ctx, req, float64(total))
+	body, err := io.ReadAll(reporter.Reader(io.LimitReader(resp.Body, s.maxBodySize)))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// raw is the name json had before the formats of the other tools were adopted
+	outputFormat := format.Format(args.Format)
+	if args.Format == "raw" {
+		outputFormat = format.JSON
+	}
+
+	// Convert HTML pages to Markdown if requested
+	responseBody := string(body)
+	if outputFormat == format.Markdown && strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "html") {
+		converted, err := markdown.FromHTML(responseBody, markdown.ConvertOptions{BaseURL: resp.Request.URL.String()})
+		if err != nil {
+			log.Printf("Error: Failed to convert HTML to Markdown: %v", err)
+			return nil, fmt.Errorf("failed to convert HTML to Markdown: %w", err)
+		}
+		responseBody = converted
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if responseBody != string(body) {
+		mimeType = "text/markdown"
+	}
+	s.recent.Add(ctx, method+" "+args.URL, mimeType, responseBody)
+
+	// Prepare headers response
+	headerMap := make(map[string]string)
+	headers := format.Table{Columns: []string{"Header", "Value"}}
+	for _, key := range slices.Sorted(maps.Keys(resp.Header)) {
+		if values := resp.Header[key]; len(values) > 0 {
+			headerMap[key] = values[0]
+			headers.Rows = append(headers.Rows, []string{key, values[0]})
+		}
+	}
+
+	// Create response structure
+	responseDetails := struct {
+		StatusCode int               `json:"status_code"`
+		Headers    map[string]string `json:"headers"`
+		Body       string            `json:"body"`
+		URL        string            `json:"url"`
+		Method     string            `json:"method"`
+	}{
+		StatusCode: resp.StatusCode,
+		Headers:    headerMap,
+		Body:       responseBody,
+		URL:        args.URL,
+		Method:     method,
+	}
+
+	// Create result message
+	var text strings.Builder
+	fmt.Fprintf(&text, "Response from %s (status: %d):\n\n", args.URL, resp.StatusCode)
+	for _, row := range headers.Rows {
+		fmt.Fprintf(&text, "%s: %s\n", row[0], row[1])
+	}
+	fmt.Fprintf(&text, "\n%s", responseBody)
+
+	markdownBody := responseBody
+	if mimeType != "text/markdown" {
+		markdownBody = "```\n" + strings.TrimRight(responseBody, "\n") + "\n```"
+	}
+	markdownText := fmt.Sprintf("## Response from %s (status: %d)\n\n%s\n\n%s", args.URL, resp.StatusCode, headers.Markdown(), markdownBody)
+
+	result, err := format.Result(outputFormat, format.Output{
+		Data:     responseDetails,
+		Text:     text.String(),
+		Markdown: markdownText,
+	})
+	if err != nil {
+		log.Printf("Error rendering response: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Fetch request completed with status: %d", resp.StatusCode)
+	return result, nil
+}
+
+// clientOptions returns the options of the HTTP client of the server.
+func clientOptions(timeout int, userAgent string, maxBodySize int64) httpclient.Options {
+	return httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		UserAgent:   userAgent,
+		MaxBodySize: maxBodySize,
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *FetchServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the fetch server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	var (
+		timeout     int
+		userAgent   string
+		maxBodySize int64
+		cookies     bool
+	)
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.StringVar(&userAgent, "user-agent", "MCP-Fetch-Server/1.0", "User-Agent header for requests")
+	fs.Int64Var(&maxBodySize, "max-body-size", 10*1024*1024, "Maximum response body size in bytes (default 10MB)")
+	fs.BoolVar(&cookies, "cookies", false, "Keep the cookies set by sites and send them back, separately for each client session")
+	var upstreamFlags httpclient.Flags
+	upstreamFlags.Register(fs)
+	var guardFlags urlguard.Flags
+	guardFlags.Register(fs)
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting fetch server: timeout=%ds, user-agent=%s, max-body-size=%d", timeout, userAgent, maxBodySize)
+
+	// Create FetchServer instance
+	fetchServer := NewFetchServer(timeout, userAgent, maxBodySize)
+	options := clientOptions(timeout, userAgent, maxBodySize)
+	if err := upstreamFlags.Apply(&options); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	guard, err := guardFlags.Policy()
+	if err != nil {
+		return nil, transport.Flags{}, err
+	}
+	options.Guard = guard
+	fetchServer.client = httpclient.New(options)
+	fetchServer.guard = guard
+	if options.TLS != nil {
+		fetchServer.rootCAs = options.TLS.RootCAs
+	}
+	if cookies {
+		fetchServer.KeepCookies()
+	}
+	log.Println("FetchServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), fetchServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return fetchServer.Server(), transportFlags, nil
+}
+
+// Run starts the fetch server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[FetchServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create fetch server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("FetchServer shutdown")
+	return nil
+}
@@ -0,0 +1,355 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/urlguard"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// FetchServer creation test
+func TestNewFetchServer(t *testing.T) {
+	// Test cases: Creating new FetchServer instances with different parameters
+	testCases := []struct {
+		name        string
+		timeout     int
+		userAgent   string
+		maxBodySize int64
+	}{
+		{
+			name:        "Default configuration",
+			timeout:     30,
+			userAgent:   "MCP-Fetch-Server/1.0",
+			maxBodySize: 10 * 1024 * 1024,
+		},
+		{
+			name:        "Custom timeout",
+			timeout:     60,
+			userAgent:   "MCP-Fetch-Server/1.0",
+			maxBodySize: 10 * 1024 * 1024,
+		},
+		{
+			name:        "Custom user agent",
+			timeout:     30,
+			userAgent:   "CustomUserAgent/2.0",
+			maxBodySize: 10 * 1024 * 1024,
+		},
+		{
+			name:        "Custom body size limit",
+			timeout:     30,
+			userAgent:   "MCP-Fetch-Server/1.0",
+			maxBodySize: 5 * 1024 * 1024,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create FetchServer instance
+			fs := NewFetchServer(tc.timeout, tc.userAgent, tc.maxBodySize)
+
+			// Verification
+			assert.NotNil(t, fs, "FetchServer instance should be created")
+			assert.Equal(t, tc.userAgent, fs.userAgent, "User-Agent should match")
+			assert.Equal(t, tc.maxBodySize, fs.maxBodySize, "Max body size should match")
+			assert.NotNil(t, fs.server, "Internal MCPServer should be initialized")
+			assert.NotNil(t, fs.client, "HTTP client should be initialized")
+			assert.Equal(t, time.Duration(tc.timeout)*time.Second, fs.client.Timeout, "Timeout should match")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	// Create FetchServer instance
+	fs := NewFetchServer(30, "Test-User-Agent", 1024*1024)
+	assert.NotNil(t, fs, "FetchServer instance should be created")
+
+	// Verify Server method returns valid MCPServer instance
+	server := fs.Server()
+	assert.NotNil(t, server, "Server method should return a valid MCPServer instance")
+}
+
+// Mock server helper for testing HTTP requests
+func setupMockServer() *httptest.Server {
+	handler := http.NewServeMux()
+
+	// GET endpoint
+	handler.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"Hello from GET"}`))
+	})
+
+	// POST endpoint
+	handler.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"Hello from POST"}`))
+	})
+
+	// Echo headers endpoint
+	handler.HandleFunc("/echo-headers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo-Test", "test-value")
+
+		customHeader := r.Header.Get("X-Custom-Header")
+		response := map[string]string{"received-header": customHeader}
+
+		jsonResp, _ := json.Marshal(response)
+		w.Write(jsonResp)
+	})
+
+	// HTML page endpoint
+	handler.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Page</title><script>track()</script></head>` +
+			`<body><h1>Welcome</h1><p>See the <a href="/docs">docs</a>.</p></body></html>`))
+	})
+
+	return httptest.NewServer(handler)
+}
+
+// Test URL validation
+func TestURLValidation(t *testing.T) {
+	fs := NewFetchServer(5, "Test-Agent", 1024)
+
+	invalidURLs := []string{
+		"ftp://example.com",
+		"example.com",
+		"//example.com",
+		"www.example.com",
+	}
+
+	ctx := context.Background()
+
+	for _, url := range invalidURLs {
+		t.Run("Invalid URL: "+url, func(t *testing.T) {
+			req := mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": url})
+
+			// Call the handler
+			result, err := fs.handleFetchURL(ctx, req)
+
+			// Verify error
+			assert.Error(t, err, "Should return error for invalid URL")
+			assert.Nil(t, result, "Result should be nil on error")
+			assert.Contains(t, err.Error(), "URL must begin with http:// or https://", "Error message should indicate URL format issue")
+		})
+	}
+}
+
+// Test HTTP methods
+func TestHTTPMethods(t *testing.T) {
+	// Set up a test server
+	mockServer := setupMockServer()
+	defer mockServer.Close()
+
+	c := mcptest.Connect(t, NewFetchServer(5, "Test-Agent", 1024*1024).Server())
+
+	// Test GET request
+	t.Run("GET request", func(t *testing.T) {
+		text := c.Text("fetchURL", map[string]interface{}{"url": mockServer.URL + "/get", "method": "GET"})
+		assert.Contains(t, text, "Hello from GET", "Response should contain expected content")
+	})
+
+	// Test POST request
+	t.Run("POST request", func(t *testing.T) {
+		text := c.Text("fetchURL", map[string]interface{}{"url": mockServer.URL + "/post", "method": "POST", "body": `{"test":"data"}`})
+		assert.Contains(t, text, "Hello from POST", "Response should contain expected content")
+	})
+
+	// Test default method (GET when not specified)
+	t.Run("Default method (GET)", func(t *testing.T) {
+		text := c.Text("fetchURL", map[string]interface{}{"url": mockServer.URL + "/get"})
+		assert.Contains(t, text, "Hello from GET", "Response should contain expected content")
+	})
+}
+
+// Test custom headers
+func TestCustomHeaders(t *testing.T) {
+	// Set up a test server
+	mockServer := setupMockServer()
+	defer mockServer.Close()
+
+	c := mcptest.Connect(t, NewFetchServer(5, "Test-Agent", 1024*1024).Server())
+
+	t.Run("Custom headers", func(t *testing.T) {
+		headersJSON, _ := json.Marshal(map[string]string{"X-Custom-Header": "test-value"})
+		text := c.Text("fetchURL", map[string]interface{}{"url": mockServer.URL + "/echo-headers", "headers": string(headersJSON)})
+		assert.Contains(t, text, "test-value", "Response should contain the echoed header value")
+	})
+
+	// Test invalid headers JSON
+	t.Run("Invalid headers JSON", func(t *testing.T) {
+		message := c.Error("fetchURL", map[string]interface{}{"url": mockServer.URL + "/echo-headers", "headers": "{invalid json}"})
+		assert.Contains(t, message, "invalid headers JSON", "Error should mention invalid headers JSON")
+	})
+}
+
+// Test rendering responses as text, Markdown and JSON
+func TestFormats(t *testing.T) {
+	mockServer := setupMockServer()
+	defer mockServer.Close()
+
+	fs := NewFetchServer(5, "Test-Agent", 1024*1024)
+	ctx := context.Background()
+
+	fetch := func(path, format string) (string, error) {
+		result, err := fs.handleFetchURL(ctx, mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": mockServer.URL + path, "format": format}))
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	t.Run("Text lists the headers before the body", func(t *testing.T) {
+		text, err := fetch("/get", "")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(text, "Response from "+mockServer.URL+"/get (status: 200):\n\n"))
+		assert.Contains(t, text, "Content-Type: application/json\n")
+		assert.True(t, strings.HasSuffix(text, "\n\n"+`{"message":"Hello from GET"}`))
+	})
+
+	t.Run("Markdown converts HTML", func(t *testing.T) {
+		text, err := fetch("/page", "markdown")
+		require.NoError(t, err)
+		assert.Contains(t, text, "| Content-Type | text/html; charset=utf-8 |")
+		assert.Contains(t, text, "\n\n# Welcome\n\nSee the [docs]("+mockServer.URL+"/docs).\n")
+		assert.NotContains(t, text, "track()")
+	})
+
+	t.Run("Markdown fences other content types", func(t *testing.T) {
+		text, err := fetch("/get", "markdown")
+		require.NoError(t, err)
+		assert.Contains(t, text, "```\n"+`{"message":"Hello from GET"}`+"\n```")
+	})
+
+	for _, format := range []string{"json", "raw"} {
+		t.Run(format+" keeps HTML", func(t *testing.T) {
+			text, err := fetch("/page", format)
+			require.NoError(t, err)
+			var response struct {
+				StatusCode int               `json:"status_code"`
+				Headers    map[string]string `json:"headers"`
+				Body       string            `json:"body"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(text), &response))
+			assert.Equal(t, 200, response.StatusCode)
+			assert.Equal(t, "text/html; charset=utf-8", response.Headers["Content-Type"])
+			assert.Contains(t, response.Body, "track()")
+		})
+	}
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := fetch("/page", "pdf")
+		assert.ErrorContains(t, err, `format must be one of text, markdown, json, raw, not "pdf"`)
+	})
+}
+
+// Test that fetched responses are kept as resources
+func TestRecentResults(t *testing.T) {
+	mockServer := setupMockServer()
+	defer mockServer.Close()
+	ctx := context.Background()
+	fs := NewFetchServer(5, "Test-Agent", 1024*1024)
+
+	_, err := fs.handleFetchURL(ctx, mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": mockServer.URL + "/get"}))
+	require.NoError(t, err)
+
+	contents, err := mcptest.ReadResource(ctx, fs.Server(), "fetch://results")
+	require.NoError(t, err)
+	var index []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResourceText(contents)), &index))
+	require.Len(t, index, 1)
+	assert.Equal(t, "fetch://results/1", index[0]["uri"])
+	assert.Equal(t, "GET "+mockServer.URL+"/get", index[0]["name"])
+
+	contents, err = mcptest.ReadResource(ctx, fs.Server(), "fetch://results/1")
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResourceText(contents), "Hello from GET")
+}
+
+// Test that a fetch is aborted when the call is cancelled
+func TestCancel(t *testing.T) {
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hanging.Close()
+	fs := NewFetchServer(60, "Test-Agent", 1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := fs.handleFetchURL(ctx, mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": hanging.URL}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+type testSession struct{ id string }
+
+func (s *testSession) SessionID() string                                   { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+
+// Test that cookies are kept per client session
+func TestCookies(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "user", Value: r.URL.Query().Get("name")})
+		}
+		if c, err := r.Cookie("user"); err == nil {
+			w.Write([]byte("Hello " + c.Value))
+			return
+		}
+		w.Write([]byte("Hello stranger"))
+	}))
+	defer site.Close()
+	fs := NewFetchServer(5, "Test-Agent", 1024)
+	key, ok := fs.cacheKey(mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": site.URL}))
+	assert.True(t, ok, "Responses are cacheable without cookies")
+	assert.NotEmpty(t, key)
+	fs.KeepCookies()
+	defer fs.cookies.Close()
+	_, ok = fs.cacheKey(mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": site.URL}))
+	assert.False(t, ok, "Responses depend on the cookies of the session")
+
+	fetch := func(ctx context.Context, path string) string {
+		result, err := fs.handleFetchURL(ctx, mcptest.NewCallToolRequest("fetchURL", map[string]interface{}{"url": site.URL + path}))
+		require.NoError(t, err)
+		return mcptest.ResultText(result)
+	}
+	alice := fs.Server().WithContext(context.Background(), &testSession{id: "alice"})
+	bob := fs.Server().WithContext(context.Background(), &testSession{id: "bob"})
+	fetch(alice, "/login?name=alice")
+	assert.Contains(t, fetch(alice, "/"), "Hello alice")
+	assert.Contains(t, fetch(bob, "/"), "Hello stranger")
+
+	fs.cookies.Reset(alice)
+	assert.Contains(t, fetch(alice, "/"), "Hello stranger")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	s := NewFetchServer(5, "Test-Agent", 1024*1024)
+	// inspectTLSCertificate dials without the HTTP client: no port is allowed
+	s.guard = &urlguard.Policy{Ports: []urlguard.PortRange{{}}}
+	mcptest.FuzzTools(f, s.Server())
+}
@@ -0,0 +1,387 @@
+package fetch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/budget"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// inspectTLSArgs are the parameters of inspectTLSCertificate.
+type inspectTLSArgs struct {
+	Host       string `json:"host" param:"required" description:"Host name or IP address, with an optional port as in example.com:8443, or an https URL"`
+	Port       int    `json:"port,omitempty" param:"default=443,min=1,max=65535" description:"Port, when host has none (default: 443)"`
+	ServerName string `json:"serverName,omitempty" description:"Name sent with SNI and checked against the certificate (default: the host)"`
+	WarnDays   int    `json:"warnDays,omitempty" param:"default=30,min=0" description:"Warn about certificates expiring within this many days (default: 30)"`
+	Format     string `json:"format,omitempty" param:"enum=text|markdown|json" description:"Output format (default: text)"`
+}
+
+// Certificate describes a certificate of the chain a server presents.
+type Certificate struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	SerialNumber       string    `json:"serialNumber"`
+	NotBefore          time.Time `json:"notBefore"`
+	NotAfter           time.Time `json:"notAfter"`
+	DaysLeft           int       `json:"daysLeft"`
+	DNSNames           []string  `json:"dnsNames,omitempty"`
+	IPAddresses        []string  `json:"ipAddresses,omitempty"`
+	EmailAddresses     []string  `json:"emailAddresses,omitempty"`
+	URIs               []string  `json:"uris,omitempty"`
+	SignatureAlgorithm string    `json:"signatureAlgorithm"`
+	PublicKey          string    `json:"publicKey"`
+	IsCA               bool      `json:"isCA"`
+	SHA256Fingerprint  string    `json:"sha256Fingerprint"`
+}
+
+// TLSInspection is the result of inspecting the TLS certificate of a server. Errors
+// make the certificate invalid: clients reject it. Warnings do not, yet.
+type TLSInspection struct {
+	Host        string        `json:"host"`
+	Address     string        `json:"address"`
+	ServerName  string        `json:"serverName"`
+	TLSVersion  string        `json:"tlsVersion"`
+	CipherSuite string        `json:"cipherSuite"`
+	Protocol    string        `json:"protocol,omitempty"`
+	Valid       bool          `json:"valid"`
+	Errors      []string      `json:"errors,omitempty"`
+	Warnings    []string      `json:"warnings,omitempty"`
+	Chain       []Certificate `json:"chain"`
+}
+
+// status describes whether the certificate is valid.
+func (i *TLSInspection) status() string {
+	switch {
+	case !i.Valid:
+		return "invalid"
+	case len(i.Warnings) > 0:
+		return "valid, with warnings"
+	}
+	return "valid"
+}
+
+// weakSignatures are the signature algorithms that are no longer secure.
+var weakSignatures = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA: true, x509.MD5WithRSA: true, x509.SHA1WithRSA: true,
+	x509.DSAWithSHA1: true, x509.ECDSAWithSHA1: true,
+}
+
+// tlsAddress returns the host of target, a host name or IP address with an optional
+// port or an https URL, and the address to dial, on port if target has none.
+func tlsAddress(target string, port int) (string, string, error) {
+	host := strings.TrimSpace(target)
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", "", toolerr.Errorf(toolerr.InvalidParams, "invalid URL %q: %w", target, err)
+		}
+		host = u.Host
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return "", "", toolerr.Errorf(toolerr.InvalidParams, "invalid port %q in %q", p, target)
+		}
+		host, port = h, n
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" || strings.ContainsAny(host, "/ ") {
+		return "", "", toolerr.Errorf(toolerr.InvalidParams, "invalid host %q: expected a name or an address such as example.com:443", target)
+	}
+	return host, net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// publicKey describes the algorithm and size of the public key of cert.
+func publicKey(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d bits", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return cert.PublicKeyAlgorithm.String()
+}
+
+// fingerprint returns the SHA-256 fingerprint of cert, in hexadecimal bytes separated
+// by colons as openssl prints it.
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// describeCertificate describes cert at now.
+func describeCertificate(cert *x509.Certificate, now time.Time) Certificate {
+	c := Certificate{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       strings.ToUpper(cert.SerialNumber.Text(16)),
+		NotBefore:          cert.NotBefore.UTC(),
+		NotAfter:           cert.NotAfter.UTC(),
+		DaysLeft:           int(cert.NotAfter.Sub(now).Hours() / 24),
+		DNSNames:           cert.DNSNames,
+		EmailAddresses:     cert.EmailAddresses,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		PublicKey:          publicKey(cert),
+		IsCA:               cert.IsCA,
+		SHA256Fingerprint:  fingerprint(cert),
+	}
+	for _, ip := range cert.IPAddresses {
+		c.IPAddresses = append(c.IPAddresses, ip.String())
+	}
+	for _, u := range cert.URIs {
+		c.URIs = append(c.URIs, u.String())
+	}
+	return c
+}
+
+// name returns the common name of c, or its subject if it has none.
+func (c Certificate) name() string {
+	for _, part := range strings.Split(c.Subject, ",") {
+		if cn, ok := strings.CutPrefix(part, "CN="); ok {
+			return cn
+		}
+	}
+	return c.Subject
+}
+
+// check reports the problems of the chain presented for serverName: expired and
+// expiring certificates, names, trust against roots, nil for the system roots, and
+// weak keys and signatures.
+func (i *TLSInspection) check(certs []*x509.Certificate, roots *x509.CertPool, now time.Time, warnDays int) {
+	for n, cert := range certs {
+		c := i.Chain[n]
+		switch {
+		case now.After(cert.NotAfter):
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate %q expired on %s, %d days ago", c.name(), c.NotAfter.Format(time.DateOnly), -c.DaysLeft))
+		case now.Before(cert.NotBefore):
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate %q is not valid before %s", c.name(), c.NotBefore.Format(time.RFC3339)))
+		case c.DaysLeft < warnDays:
+			i.Warnings = append(i.Warnings, fmt.Sprintf("certificate %q expires on %s, in %d days", c.name(), c.NotAfter.Format(time.DateOnly), c.DaysLeft))
+		}
+		// The signatures of roots are not checked, they are trusted as they are
+		selfSigned := cert.CheckSignatureFrom(cert) == nil
+		if weakSignatures[cert.SignatureAlgorithm] && !selfSigned {
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate %q is signed with the insecure %s algorithm", c.name(), c.SignatureAlgorithm))
+		}
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && key.N.BitLen() < 2048 {
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate %q has a weak %d-bit RSA key, 2048 bits at least are required", c.name(), key.N.BitLen()))
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       i.ServerName,
+		Intermediates: intermediates,
+		Roots:         roots,
+		CurrentTime:   now,
+	})
+	var hostnameErr x509.HostnameError
+	var authorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case err == nil:
+	case errors.As(err, &hostnameErr):
+		names := append(append([]string{}, i.Chain[0].DNSNames...), i.Chain[0].IPAddresses...)
+		if len(names) == 0 {
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate is not valid for %s: it has no subject alternative names", i.ServerName))
+		} else {
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate is not valid for %s, only for %s", i.ServerName, strings.Join(names, ", ")))
+		}
+	case errors.As(err, &authorityErr):
+		if len(certs) == 1 && certs[0].CheckSignatureFrom(certs[0]) == nil {
+			i.Errors = append(i.Errors, "certificate is self-signed and not trusted")
+		} else {
+			i.Errors = append(i.Errors, fmt.Sprintf("certificate is signed by an unknown authority, %s: the chain may be incomplete or its root not trusted", i.Chain[len(i.Chain)-1].Issuer))
+		}
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		// Reported above
+	default:
+		i.Errors = append(i.Errors, "certificate is not trusted: "+err.Error())
+	}
+	i.Valid = len(i.Errors) == 0
+}
+
+// inspectTLS connects to address and inspects the certificates presented for
+// serverName, without sending anything after the handshake.
+func (s *FetchServer) inspectTLS(ctx context.Context, host, address, serverName string, warnDays int) (*TLSInspection, error) {
+	dialer := &net.Dialer{}
+	if s.guard != nil {
+		if err := s.guard.CheckURL(&url.URL{Scheme: "https", Host: address}); err != nil {
+			return nil, err
+		}
+		dialer = s.guard.Dialer(host)
+	}
+	ctx, cancel, err := budget.WithTimeout(ctx, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	// The chain is verified afterwards, to report its problems rather than fail
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2", "http/1.1"},
+	}}
+	conn, err := tlsDialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		if toolerr.Classify(err).Code == toolerr.PermissionDenied {
+			return nil, err
+		}
+		return nil, fmt.Errorf("TLS connection to %s failed: %w", address, err)
+	}
+	defer conn.Close()
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s presented no certificate", address)
+	}
+
+	inspection := &TLSInspection{
+		Host:        host,
+		Address:     conn.RemoteAddr().String(),
+		ServerName:  serverName,
+		TLSVersion:  tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		Protocol:    state.NegotiatedProtocol,
+	}
+	now := time.Now()
+	for _, cert := range state.PeerCertificates {
+		inspection.Chain = append(inspection.Chain, describeCertificate(cert, now))
+	}
+	inspection.check(state.PeerCertificates, s.rootCAs, now, warnDays)
+	return inspection, nil
+}
+
+// role describes the place of certificate n in a chain of count.
+func role(n, count int, c Certificate) string {
+	switch {
+	case n == 0:
+		return "server"
+	case c.Subject == c.Issuer:
+		return "root CA"
+	case n == count-1 || c.IsCA:
+		return "intermediate CA"
+	}
+	return "other"
+}
+
+// text renders the inspection as text.
+func (i *TLSInspection) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Host: %s (%s)\n", i.ServerName, i.Address)
+	fmt.Fprintf(&b, "Connection: %s, %s", i.TLSVersion, i.CipherSuite)
+	if i.Protocol != "" {
+		fmt.Fprintf(&b, ", %s", i.Protocol)
+	}
+	fmt.Fprintf(&b, "\nStatus: %s\n", i.status())
+	for _, e := range i.Errors {
+		fmt.Fprintf(&b, "Error: %s\n", e)
+	}
+	for _, w := range i.Warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", w)
+	}
+	for n, c := range i.Chain {
+		fmt.Fprintf(&b, "\nCertificate %d (%s):\n", n+1, role(n, len(i.Chain), c))
+		fmt.Fprintf(&b, "  Subject: %s\n", c.Subject)
+		fmt.Fprintf(&b, "  Issuer: %s\n", c.Issuer)
+		fmt.Fprintf(&b, "  Valid: %s to %s (%d days left)\n", c.NotBefore.Format(time.DateOnly), c.NotAfter.Format(time.DateOnly), c.DaysLeft)
+		if names := append(append(append(append([]string{}, c.DNSNames...), c.IPAddresses...), c.EmailAddresses...), c.URIs...); len(names) > 0 {
+			fmt.Fprintf(&b, "  Names: %s\n", strings.Join(names, ", "))
+		}
+		fmt.Fprintf(&b, "  Signature: %s\n", c.SignatureAlgorithm)
+		fmt.Fprintf(&b, "  Public key: %s\n", c.PublicKey)
+		fmt.Fprintf(&b, "  Serial number: %s\n", c.SerialNumber)
+		fmt.Fprintf(&b, "  SHA-256 fingerprint: %s\n", c.SHA256Fingerprint)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// markdown renders the inspection as Markdown.
+func (i *TLSInspection) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## TLS certificate of %s (%s)\n\n", i.ServerName, i.Address)
+	fmt.Fprintf(&b, "**Status:** %s, %s, %s\n\n", i.status(), i.TLSVersion, i.CipherSuite)
+	for _, e := range i.Errors {
+		fmt.Fprintf(&b, "- **Error:** %s\n", e)
+	}
+	for _, w := range i.Warnings {
+		fmt.Fprintf(&b, "- **Warning:** %s\n", w)
+	}
+	if len(i.Errors)+len(i.Warnings) > 0 {
+		b.WriteString("\n")
+	}
+	t := format.Table{Columns: []string{"#", "Role", "Subject", "Issuer", "Expires", "Days left", "Key", "Signature"}}
+	for n, c := range i.Chain {
+		t.Rows = append(t.Rows, []string{strconv.Itoa(n + 1), role(n, len(i.Chain), c), c.Subject, c.Issuer, c.NotAfter.Format(time.DateOnly), strconv.Itoa(c.DaysLeft), c.PublicKey, c.SignatureAlgorithm})
+	}
+	b.WriteString(t.Markdown())
+	if names := append(append([]string{}, i.Chain[0].DNSNames...), i.Chain[0].IPAddresses...); len(names) > 0 {
+		fmt.Fprintf(&b, "\n\n**Names:** %s", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+// handleInspectTLSCertificate handles the TLS certificate inspection request.
+func (s *FetchServer) handleInspectTLSCertificate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting inspectTLSCertificate request processing")
+
+	var args inspectTLSArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	host, address, err := tlsAddress(args.Host, args.Port)
+	if err != nil {
+		return nil, err
+	}
+	serverName := args.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	log.Printf("Inspecting the TLS certificate of %s at %s", serverName, address)
+	inspection, err := s.inspectTLS(ctx, host, address, serverName, args.WarnDays)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data:     inspection,
+		Text:     inspection.text(),
+		Markdown: inspection.markdown(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("inspectTLSCertificate request completed: %s", inspection.status())
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package fetch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// issue returns a certificate for names valid from notBefore to notAfter, signed by
+// parent or self-signed if nil, with its key.
+func issue(t *testing.T, cn string, names []string, notBefore, notAfter time.Time, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"Test"}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		DNSNames:              names,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	if parent != nil {
+		cert.Certificate = append(cert.Certificate, parent.Certificate...)
+	}
+	return cert
+}
+
+// serveTLS starts a TLS server presenting cert, returning its address.
+func serveTLS(t *testing.T, cert tls.Certificate) string {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts.Listener.Addr().String()
+}
+
+// inspect calls inspectTLSCertificate, returning the inspection.
+func inspect(t *testing.T, c *mcptest.Client, args map[string]interface{}) TLSInspection {
+	args["format"] = "json"
+	var inspection TLSInspection
+	require.NoError(t, json.Unmarshal([]byte(c.Text("inspectTLSCertificate", args)), &inspection))
+	return inspection
+}
+
+// Test inspecting valid, expiring, expired, misnamed and untrusted certificates
+func TestInspectTLSCertificate(t *testing.T) {
+	now := time.Now()
+	ca := issue(t, "Test Root CA", nil, now.Add(-time.Hour), now.AddDate(10, 0, 0), nil)
+	s := NewFetchServer(5, "Test-Agent", 1024*1024)
+	s.rootCAs = x509.NewCertPool()
+	s.rootCAs.AddCert(ca.Leaf)
+	c := mcptest.Connect(t, s.Server())
+
+	valid := serveTLS(t, issue(t, "example.test", []string{"example.test", "www.example.test"}, now.Add(-time.Hour), now.AddDate(0, 0, 90), &ca))
+	inspection := inspect(t, c, map[string]interface{}{"host": valid, "serverName": "www.example.test"})
+	assert.True(t, inspection.Valid)
+	assert.Empty(t, inspection.Errors)
+	assert.Empty(t, inspection.Warnings)
+	assert.Equal(t, "TLS 1.3", inspection.TLSVersion)
+	require.Len(t, inspection.Chain, 2)
+	assert.Equal(t, "CN=example.test,O=Test", inspection.Chain[0].Subject)
+	assert.Equal(t, "CN=Test Root CA,O=Test", inspection.Chain[0].Issuer)
+	assert.Equal(t, []string{"example.test", "www.example.test"}, inspection.Chain[0].DNSNames)
+	assert.Equal(t, []string{"127.0.0.1"}, inspection.Chain[0].IPAddresses)
+	assert.Equal(t, 89, inspection.Chain[0].DaysLeft)
+	assert.Equal(t, "ECDSA P-256", inspection.Chain[0].PublicKey)
+	assert.Equal(t, "ECDSA-SHA256", inspection.Chain[0].SignatureAlgorithm)
+	assert.Len(t, inspection.Chain[0].SHA256Fingerprint, 95)
+	assert.True(t, inspection.Chain[1].IsCA)
+
+	text := c.Text("inspectTLSCertificate", map[string]interface{}{"host": "https://" + valid + "/path", "serverName": "example.test"})
+	assert.Contains(t, text, "Host: example.test ("+valid+")\nConnection: TLS 1.3, ")
+	assert.Contains(t, text, "Status: valid\n")
+	assert.Contains(t, text, "Certificate 1 (server):\n  Subject: CN=example.test,O=Test\n  Issuer: CN=Test Root CA,O=Test\n")
+	assert.Contains(t, text, "  Names: example.test, www.example.test, 127.0.0.1\n")
+	assert.Contains(t, text, "Certificate 2 (root CA):")
+	assert.Contains(t, c.Text("inspectTLSCertificate", map[string]interface{}{"host": valid, "format": "markdown"}), "| 1 | server | CN=example.test,O=Test |")
+
+	// Certificates expiring soon are valid, with a warning
+	expiring := serveTLS(t, issue(t, "example.test", []string{"example.test"}, now.Add(-time.Hour), now.Add(10*24*time.Hour+time.Hour), &ca))
+	inspection = inspect(t, c, map[string]interface{}{"host": expiring, "serverName": "example.test"})
+	assert.True(t, inspection.Valid)
+	assert.Equal(t, []string{`certificate "example.test" expires on ` + now.Add(10*24*time.Hour+time.Hour).UTC().Format(time.DateOnly) + ", in 10 days"}, inspection.Warnings)
+	assert.Empty(t, inspect(t, c, map[string]interface{}{"host": expiring, "serverName": "example.test", "warnDays": 7}).Warnings)
+
+	expired := serveTLS(t, issue(t, "example.test", []string{"example.test"}, now.AddDate(0, 0, -100), now.AddDate(0, 0, -3), &ca))
+	inspection = inspect(t, c, map[string]interface{}{"host": expired, "serverName": "example.test"})
+	assert.False(t, inspection.Valid)
+	assert.Equal(t, []string{`certificate "example.test" expired on ` + now.AddDate(0, 0, -3).UTC().Format(time.DateOnly) + ", 3 days ago"}, inspection.Errors)
+	assert.Contains(t, c.Text("inspectTLSCertificate", map[string]interface{}{"host": expired, "serverName": "example.test"}), "Status: invalid\nError: certificate")
+
+	inspection = inspect(t, c, map[string]interface{}{"host": valid, "serverName": "other.test"})
+	assert.Equal(t, []string{"certificate is not valid for other.test, only for example.test, www.example.test, 127.0.0.1"}, inspection.Errors)
+
+	selfSigned := serveTLS(t, issue(t, "self.test", []string{"self.test"}, now.Add(-time.Hour), now.AddDate(1, 0, 0), nil))
+	inspection = inspect(t, c, map[string]interface{}{"host": selfSigned, "serverName": "self.test"})
+	assert.Equal(t, []string{"certificate is self-signed and not trusted"}, inspection.Errors)
+
+	otherCA := issue(t, "Other CA", nil, now.Add(-time.Hour), now.AddDate(10, 0, 0), nil)
+	untrusted := issue(t, "example.test", []string{"example.test"}, now.Add(-time.Hour), now.AddDate(1, 0, 0), &otherCA)
+	untrusted.Certificate = untrusted.Certificate[:1]
+	inspection = inspect(t, c, map[string]interface{}{"host": serveTLS(t, untrusted), "serverName": "example.test"})
+	assert.Equal(t, []string{"certificate is signed by an unknown authority, CN=Other CA,O=Test: the chain may be incomplete or its root not trusted"}, inspection.Errors)
+}
+
+// Test the errors of inspectTLSCertificate
+func TestInspectTLSCertificateErrors(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	c := mcptest.Connect(t, NewFetchServer(5, "Test-Agent", 1024*1024).Server())
+	assert.Contains(t, c.Error("inspectTLSCertificate", map[string]interface{}{"host": plain.Listener.Addr().String()}), "TLS connection to "+plain.Listener.Addr().String()+" failed")
+	assert.Equal(t, `invalid port "99999" in "example.com:99999"`, c.Error("inspectTLSCertificate", map[string]interface{}{"host": "example.com:99999"}))
+	assert.Contains(t, c.Error("inspectTLSCertificate", map[string]interface{}{"host": "a b"}), "invalid host")
+
+	// The addresses of the URL policy are denied, as for fetchURL
+	s, _, err := New(context.Background(), nil)
+	require.NoError(t, err)
+	c = mcptest.Connect(t, s)
+	assert.True(t, strings.HasPrefix(c.Error("inspectTLSCertificate", map[string]interface{}{"host": "127.0.0.1", "port": 8443}), "127.0.0.1 is a loopback address"))
+}
+
+// Test parsing the targets of inspectTLSCertificate
+func TestTLSAddress(t *testing.T) {
+	testCases := []struct {
+		target, host, address string
+	}{
+		{"example.com", "example.com", "example.com:443"},
+		{"example.com:8443", "example.com", "example.com:8443"},
+		{"https://example.com/path?q=1", "example.com", "example.com:443"},
+		{"https://example.com:9443", "example.com", "example.com:9443"},
+		{"2001:db8::1", "2001:db8::1", "[2001:db8::1]:443"},
+		{"[2001:db8::1]:8443", "2001:db8::1", "[2001:db8::1]:8443"},
+	}
+	for _, tc := range testCases {
+		host, address, err := tlsAddress(tc.target, 443)
+		require.NoError(t, err, tc.target)
+		assert.Equal(t, tc.host, host, tc.target)
+		assert.Equal(t, tc.address, address, tc.target)
+	}
+}
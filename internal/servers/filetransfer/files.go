@@ -0,0 +1,142 @@
+package filetransfer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/resources"
+)
+
+// localFilesURI is the URI of the listing of the local directory, and the prefix of
+// the URIs of its files, e.g. filetransfer://local/reports/q1.csv.
+const localFilesURI = "filetransfer://local"
+
+const (
+	maxListedFiles  = 1000     // files listed at localFilesURI
+	maxResourceSize = 10 << 20 // size of the largest file read as a resource
+)
+
+// localFile is an entry of the listing of the local directory.
+type localFile struct {
+	URI     string    `json:"uri"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// addFileResources offers the files of the local directory as resources.
+func (s *FileTransferServer) addFileResources(mcpServer *server.MCPServer) {
+	mcpServer.AddResource(mcp.NewResource(localFilesURI, "Local files",
+		mcp.WithResourceDescription(fmt.Sprintf("JSON list of the files in the local directory, up to %d", maxListedFiles)),
+		mcp.WithMIMEType("application/json"),
+	), s.readLocalFiles)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(localFilesURI+"/{+path}", "Local file",
+		mcp.WithTemplateDescription("A file of the local directory, e.g. one downloaded with downloadFile; subscribers are notified when it is downloaded again"),
+	), s.readLocalFile)
+}
+
+// localFileURI returns the URI of the file at p, relative to the local directory.
+func localFileURI(p string) string {
+	return localFilesURI + "/" + strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+}
+
+func (s *FileTransferServer) readLocalFiles(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	root, err := s.localPath("")
+	if err != nil {
+		return nil, err
+	}
+	files := []localFile{}
+	errFull := errors.New("listing full")
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !s.files.Allowed(p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if len(files) == maxListedFiles {
+			return errFull
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		files = append(files, localFile{URI: localFileURI(rel), Size: info.Size(), ModTime: info.ModTime().UTC()})
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFull) {
+		return nil, fmt.Errorf("failed to list the local directory: %w", err)
+	}
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      localFilesURI,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}, nil
+}
+
+func (s *FileTransferServer) readLocalFile(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	p := resources.Argument(req, "path")
+	local, err := s.localPath(p)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return nil, fmt.Errorf("no file %s in the local directory", p)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file", p)
+	}
+	if info.Size() > maxResourceSize {
+		return nil, fmt.Errorf("%s is %d bytes, larger than the %d bytes read as a resource", p, info.Size(), maxResourceSize)
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(local))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if utf8.Valid(data) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: mimeType,
+			Text:     string(data),
+		}}, nil
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{
+		URI:      req.Params.URI,
+		MIMEType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}}, nil
+}
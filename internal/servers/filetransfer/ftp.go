@@ -0,0 +1,322 @@
+package filetransfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpClient is a minimal passive-mode FTP client covering the commands the tools need.
+type ftpClient struct {
+	conn    *textproto.Conn
+	raw     net.Conn
+	host    string
+	timeout time.Duration
+}
+
+// dialFTP connects and logs in to an FTP endpoint.
+func dialFTP(ctx context.Context, ep Endpoint, timeout time.Duration) (*ftpClient, error) {
+	port := ep.Port
+	if port == 0 {
+		port = 21
+	}
+	addr := net.JoinHostPort(ep.Host, strconv.Itoa(port))
+
+	dialer := net.Dialer{Timeout: timeout}
+	raw, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ftpClient{
+		conn:    textproto.NewConn(raw),
+		raw:     raw,
+		host:    ep.Host,
+		timeout: timeout,
+	}
+	c.deadline()
+
+	if _, _, err := c.conn.ReadResponse(220); err != nil {
+		c.raw.Close()
+		return nil, fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	username := ep.Username
+	if username == "" {
+		username = "anonymous"
+	}
+	code, _, err := c.cmd(0, "USER %s", username)
+	if err != nil {
+		c.raw.Close()
+		return nil, err
+	}
+	if code == 331 {
+		if _, _, err := c.cmd(230, "PASS %s", ep.Password); err != nil {
+			c.raw.Close()
+			return nil, fmt.Errorf("login failed: %w", err)
+		}
+	} else if code != 230 {
+		c.raw.Close()
+		return nil, fmt.Errorf("login failed: unexpected response %d", code)
+	}
+
+	if _, _, err := c.cmd(200, "TYPE I"); err != nil {
+		c.raw.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// deadline extends the control connection deadline by the configured timeout.
+func (c *ftpClient) deadline() {
+	if c.timeout > 0 {
+		c.raw.SetDeadline(time.Now().Add(c.timeout))
+	}
+}
+
+// cmd sends a command and reads the response. An expectCode of 0 accepts any code.
+func (c *ftpClient) cmd(expectCode int, format string, args ...interface{}) (int, string, error) {
+	line := fmt.Sprintf(format, args...)
+	if strings.ContainsAny(line, "\r\n") {
+		// A line break would smuggle in a second command
+		return 0, "", fmt.Errorf("invalid FTP command: %q", line)
+	}
+	c.deadline()
+	if _, err := c.conn.Cmd("%s", line); err != nil {
+		return 0, "", err
+	}
+	if expectCode == 0 {
+		code, msg, err := c.conn.ReadResponse(0)
+		if _, ok := err.(*textproto.Error); ok {
+			return code, msg, nil
+		}
+		return code, msg, err
+	}
+	return c.conn.ReadResponse(expectCode)
+}
+
+// openData opens a passive data connection, preferring EPSV over PASV.
+func (c *ftpClient) openData(ctx context.Context) (net.Conn, error) {
+	var addr string
+	if code, msg, err := c.cmd(0, "EPSV"); err == nil && code == 229 {
+		// 229 Entering Extended Passive Mode (|||port|)
+		start := strings.Index(msg, "(")
+		end := strings.LastIndex(msg, ")")
+		if start < 0 || end <= start {
+			return nil, fmt.Errorf("invalid EPSV response: %s", msg)
+		}
+		fields := strings.Split(msg[start+1:end], string(msg[start+1]))
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("invalid EPSV response: %s", msg)
+		}
+		addr = net.JoinHostPort(c.host, fields[3])
+	} else {
+		_, msg, err := c.cmd(227, "PASV")
+		if err != nil {
+			return nil, err
+		}
+		// 227 Entering Passive Mode (h1,h2,h3,h4,p1,p2)
+		start := strings.Index(msg, "(")
+		end := strings.LastIndex(msg, ")")
+		if start < 0 || end <= start {
+			return nil, fmt.Errorf("invalid PASV response: %s", msg)
+		}
+		parts := strings.Split(msg[start+1:end], ",")
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("invalid PASV response: %s", msg)
+		}
+		p1, err1 := strconv.Atoi(parts[4])
+		p2, err2 := strconv.Atoi(parts[5])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid PASV port: %s", msg)
+		}
+		// Ignore the advertised IP to avoid being redirected to internal hosts
+		addr = net.JoinHostPort(c.host, strconv.Itoa(p1*256+p2))
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// transfer runs a data command, hands the data connection to fn and waits for completion.
+func (c *ftpClient) transfer(ctx context.Context, fn func(net.Conn) error, format string, args ...interface{}) error {
+	data, err := c.openData(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open data connection: %w", err)
+	}
+	defer data.Close()
+
+	// Abort the transfer promptly when the request is cancelled
+	stop := context.AfterFunc(ctx, func() { data.Close() })
+	defer stop()
+
+	code, msg, err := c.cmd(0, format, args...)
+	if err != nil {
+		return err
+	}
+	if code != 125 && code != 150 {
+		return fmt.Errorf("%d %s", code, msg)
+	}
+
+	if err := fn(data); err != nil {
+		return err
+	}
+	data.Close()
+
+	c.deadline()
+	if _, _, err := c.conn.ReadResponse(226); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// List returns the entries of dir using MLSD.
+func (c *ftpClient) List(ctx context.Context, dir string) ([]RemoteEntry, error) {
+	var listing []byte
+	err := c.transfer(ctx, func(data net.Conn) error {
+		var err error
+		listing, err = io.ReadAll(data)
+		return err
+	}, "MLSD %s", dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []RemoteEntry
+	for _, line := range strings.Split(string(listing), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		entry, ok := parseMLSDLine(line)
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseMLSDLine parses a "fact=value;fact=value; name" MLSD line.
+func parseMLSDLine(line string) (RemoteEntry, bool) {
+	facts, name, found := strings.Cut(line, " ")
+	if !found || name == "" {
+		return RemoteEntry{}, false
+	}
+
+	entry := RemoteEntry{Name: name}
+	for _, fact := range strings.Split(facts, ";") {
+		key, value, ok := strings.Cut(fact, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "type":
+			switch strings.ToLower(value) {
+			case "cdir", "pdir":
+				return RemoteEntry{}, false
+			case "dir":
+				entry.IsDir = true
+			}
+		case "size":
+			entry.Size, _ = strconv.ParseInt(value, 10, 64)
+		case "modify":
+			if t, err := time.Parse("20060102150405", value[:min(len(value), 14)]); err == nil {
+				entry.ModTime = t
+			}
+		}
+	}
+	return entry, true
+}
+
+// Size returns the size of a remote file.
+func (c *ftpClient) Size(ctx context.Context, p string) (int64, error) {
+	_, msg, err := c.cmd(213, "SIZE %s", p)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+}
+
+// Download retrieves remotePath into localPath.
+func (c *ftpClient) Download(ctx context.Context, remotePath, localPath string, progress func(int64)) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.transfer(ctx, func(data net.Conn) error {
+		_, err := io.Copy(&progressWriter{w: f, progress: progress}, data)
+		return err
+	}, "RETR %s", remotePath)
+}
+
+// Upload stores localPath at remotePath, creating missing parent directories.
+func (c *ftpClient) Upload(ctx context.Context, localPath, remotePath string, progress func(int64)) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c.mkdirAll(path.Dir(remotePath))
+
+	return c.transfer(ctx, func(data net.Conn) error {
+		_, err := io.Copy(data, &progressReader{r: f, progress: progress})
+		return err
+	}, "STOR %s", remotePath)
+}
+
+// mkdirAll creates dir and its parents, ignoring failures for directories that already exist.
+func (c *ftpClient) mkdirAll(dir string) {
+	if dir == "/" || dir == "." {
+		return
+	}
+	c.mkdirAll(path.Dir(dir))
+	c.cmd(0, "MKD %s", dir)
+}
+
+// Close ends the FTP session.
+func (c *ftpClient) Close() error {
+	c.cmd(0, "QUIT")
+	return c.conn.Close()
+}
+
+// progressWriter counts bytes written and reports them to progress.
+type progressWriter struct {
+	w        io.Writer
+	n        int64
+	progress func(int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.n += int64(n)
+	if pw.progress != nil {
+		pw.progress(pw.n)
+	}
+	return n, err
+}
+
+// progressReader counts bytes read and reports them to progress.
+type progressReader struct {
+	r        io.Reader
+	n        int64
+	progress func(int64)
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.n += int64(n)
+	if pr.progress != nil && (n > 0 || err == io.EOF) {
+		pr.progress(pr.n)
+	}
+	return n, err
+}
@@ -0,0 +1,546 @@
+package filetransfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/progress"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/sandbox"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// Endpoint describes a remote SFTP or FTP server that the tools are allowed to use.
+type Endpoint struct {
+	Protocol     string `json:"protocol"`               // "sftp" or "ftp"
+	Host         string `json:"host"`                   // Remote host name or IP address
+	Port         int    `json:"port,omitempty"`         // Defaults to 22 (sftp) or 21 (ftp)
+	Username     string `json:"username,omitempty"`     // Login name
+	Password     string `json:"password,omitempty"`     // FTP password (prefer passwordEnv)
+	PasswordEnv  string `json:"passwordEnv,omitempty"`  // Environment variable holding the FTP password
+	IdentityFile string `json:"identityFile,omitempty"` // SSH private key for SFTP
+	Root         string `json:"root,omitempty"`         // Remote directory all paths are jailed to
+}
+
+// EndpointsConfig is the layout of the -endpoints JSON file.
+type EndpointsConfig struct {
+	Endpoints map[string]Endpoint `json:"endpoints"`
+}
+
+// RemoteEntry is a single entry of a remote directory listing.
+type RemoteEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	IsDir   bool      `json:"isDir"`
+	ModTime time.Time `json:"modTime,omitempty"`
+}
+
+// remoteClient is implemented by the protocol specific backends.
+type remoteClient interface {
+	List(ctx context.Context, dir string) ([]RemoteEntry, error)
+	Size(ctx context.Context, p string) (int64, error)
+	Download(ctx context.Context, remotePath, localPath string, progress func(int64)) error
+	Upload(ctx context.Context, localPath, remotePath string, progress func(int64)) error
+	Close() error
+}
+
+// FileTransferServer is an MCP server that transfers files to and from SFTP/FTP endpoints.
+type FileTransferServer struct {
+	server    *server.MCPServer
+	endpoints map[string]Endpoint
+	localDir  string
+	files     *sandbox.Sandbox
+	timeout   time.Duration
+
+	// dial opens a connection to an endpoint; replaced in tests.
+	dial func(ctx context.Context, ep Endpoint, timeout time.Duration) (remoteClient, error)
+}
+
+// NewFileTransferServer creates a new FileTransferServer instance.
+func NewFileTransferServer(endpoints map[string]Endpoint, localDir string, timeout int) *FileTransferServer {
+	log.Printf("FileTransferServer created: endpoints=%d, localDir=%s, timeout=%ds", len(endpoints), localDir, timeout)
+
+	s := &FileTransferServer{
+		endpoints: endpoints,
+		localDir:  localDir,
+		files:     sandbox.New(localDir, "local directory", sandbox.Options{}),
+		timeout:   time.Duration(timeout) * time.Second,
+		dial:      dialEndpoint,
+	}
+
+	mcpServer := resources.NewMCPServer(
+		"filetransfer-server", // server name
+		"1.0.0",               // version
+	)
+	s.addFileResources(mcpServer)
+
+	// Register listRemote tool
+	listTool := mcp.NewTool("listRemote",
+		mcp.WithDescription("Lists the contents of a directory on a configured SFTP/FTP endpoint"),
+		mcp.WithString("endpoint",
+			mcp.Description("Name of the configured endpoint"),
+			mcp.Required(),
+		),
+		mcp.WithString("path",
+			mcp.Description("Remote directory relative to the endpoint root. Defaults to the root"),
+			mcp.DefaultString("/"),
+		),
+	)
+
+	// Register downloadFile tool
+	downloadTool := mcp.NewTool("downloadFile",
+		mcp.WithDescription("Downloads a file from a configured SFTP/FTP endpoint into the local directory"),
+		mcp.WithString("endpoint",
+			mcp.Description("Name of the configured endpoint"),
+			mcp.Required(),
+		),
+		mcp.WithString("remotePath",
+			mcp.Description("Remote file path relative to the endpoint root"),
+			mcp.Required(),
+		),
+		mcp.WithString("localPath",
+			mcp.Description("Destination path relative to the local directory. Defaults to the remote file name"),
+		),
+	)
+
+	// Register uploadFile tool
+	uploadTool := mcp.NewTool("uploadFile",
+		mcp.WithDescription("Uploads a file from the local directory to a configured SFTP/FTP endpoint"),
+		mcp.WithString("endpoint",
+			mcp.Description("Name of the configured endpoint"),
+			mcp.Required(),
+		),
+		mcp.WithString("localPath",
+			mcp.Description("Source file path relative to the local directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("remotePath",
+			mcp.Description("Destination path relative to the endpoint root. Defaults to the local file name"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listTool, s.handleListRemote)
+	middleware.AddTool(mcpServer, downloadTool, s.handleDownloadFile)
+	middleware.AddTool(mcpServer, uploadTool, s.handleUploadFile)
+
+	s.server = mcpServer
+	return s
+}
+
+// dialEndpoint connects to an endpoint using the backend matching its protocol.
+func dialEndpoint(ctx context.Context, ep Endpoint, timeout time.Duration) (remoteClient, error) {
+	switch strings.ToLower(ep.Protocol) {
+	case "ftp":
+		client, err := dialFTP(ctx, ep, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case "sftp":
+		client, err := newSFTPClient(ep, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", ep.Protocol)
+	}
+}
+
+// endpoint looks up a configured endpoint by name.
+func (s *FileTransferServer) endpoint(name string) (Endpoint, error) {
+	ep, ok := s.endpoints[name]
+	if !ok {
+		return Endpoint{}, fmt.Errorf("unknown endpoint: %s", name)
+	}
+	return ep, nil
+}
+
+// remotePath resolves p against the endpoint root and rejects paths escaping it.
+// Control characters are rejected because paths end up on FTP command lines and in
+// sftp batch scripts, where a line break would start a new command.
+func remotePath(root, p string) (string, error) {
+	if root == "" {
+		root = "/"
+	}
+	if strings.ContainsFunc(p, unicode.IsControl) {
+		return "", fmt.Errorf("invalid remote path: %q", p)
+	}
+	root = path.Clean("/" + root)
+	resolved := path.Join(root, path.Clean("/"+p))
+	if resolved != root && !strings.HasPrefix(resolved, strings.TrimSuffix(root, "/")+"/") {
+		return "", fmt.Errorf("remote path escapes endpoint root: %s", p)
+	}
+	return resolved, nil
+}
+
+// localPath resolves p inside the local directory, rejecting escapes and paths the
+// allow and deny rules exclude.
+func (s *FileTransferServer) localPath(p string) (string, error) {
+	// Local paths are passed to the sftp batch script too
+	if strings.ContainsFunc(p, unicode.IsControl) {
+		return "", fmt.Errorf("invalid local path: %q", p)
+	}
+	return s.files.Resolve(p)
+}
+
+// connect looks up and dials an endpoint, applying the configured timeout.
+func (s *FileTransferServer) connect(ctx context.Context, name string) (Endpoint, remoteClient, error) {
+	ep, err := s.endpoint(name)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return Endpoint{}, nil, err
+	}
+	client, err := s.dial(ctx, ep, s.timeout)
+	if err != nil {
+		log.Printf("Error: Failed to connect to %s: %v", name, err)
+		return Endpoint{}, nil, fmt.Errorf("failed to connect to %s: %w", name, err)
+	}
+	return ep, client, nil
+}
+
+// handleListRemote handles the remote directory listing request.
+func (s *FileTransferServer) handleListRemote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listRemote request processing")
+
+	var args struct {
+		Endpoint string `json:"endpoint"`
+		Path     string `json:"path,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	log.Printf("listRemote request: endpoint=%s, path=%s", args.Endpoint, args.Path)
+
+	ep, client, err := s.connect(ctx, args.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	dir, err := remotePath(ep.Root, args.Path)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	entries, err := client.List(ctx, dir)
+	if err != nil {
+		log.Printf("Error: Failed to list %s: %v", dir, err)
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Contents of %s:%s (%d entries)\n\n", args.Endpoint, dir, len(entries)))
+	for _, entry := range entries {
+		kind := "file"
+		if entry.IsDir {
+			kind = "dir "
+		}
+		modTime := ""
+		if !entry.ModTime.IsZero() {
+			modTime = entry.ModTime.Format(time.RFC3339)
+		}
+		resultContent.WriteString(fmt.Sprintf("%s  %12d  %-25s  %s\n", kind, entry.Size, modTime, entry.Name))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("listRemote request completed")
+	return result, nil
+}
+
+// handleDownloadFile handles the file download request.
+func (s *FileTransferServer) handleDownloadFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting downloadFile request processing")
+
+	var args struct {
+		Endpoint   string `json:"endpoint"`
+		RemotePath string `json:"remotePath"`
+		LocalPath  string `json:"localPath,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	log.Printf("downloadFile request: endpoint=%s, remotePath=%s, localPath=%s", args.Endpoint, args.RemotePath, args.LocalPath)
+
+	if args.RemotePath == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "remotePath is required")
+	}
+	if args.LocalPath == "" {
+		args.LocalPath = path.Base(args.RemotePath)
+	}
+
+	dest, err := s.localPath(args.LocalPath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	ep, client, err := s.connect(ctx, args.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	src, err := remotePath(ep.Root, args.RemotePath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// The size is only used for progress reporting, so a failure is not fatal
+	total, err := client.Size(ctx, src)
+	if err != nil {
+		log.Printf("Warning: Could not determine size of %s: %v", src, err)
+		total = 0
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		log.Printf("Error: Failed to create local directory: %v", err)
+		return nil, fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	start := time.Now()
+	if err := client.Download(ctx, src, dest, progress.New(ctx, req, float64(total)).Func()); err != nil {
+		log.Printf("Error: Download failed: %v", err)
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat downloaded file: %w", err)
+	}
+
+	resources.Updated(ctx, s.server, localFileURI(args.LocalPath))
+	resources.Updated(ctx, s.server, localFilesURI)
+
+	resultMsg := fmt.Sprintf("Downloaded %s:%s to %s (%d bytes in %s)",
+		args.Endpoint, src, args.LocalPath, info.Size(), time.Since(start).Round(time.Millisecond))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultMsg,
+			},
+		},
+	}
+
+	log.Println("downloadFile request completed")
+	return result, nil
+}
+
+// handleUploadFile handles the file upload request.
+func (s *FileTransferServer) handleUploadFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting uploadFile request processing")
+
+	var args struct {
+		Endpoint   string `json:"endpoint"`
+		LocalPath  string `json:"localPath"`
+		RemotePath string `json:"remotePath,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	log.Printf("uploadFile request: endpoint=%s, localPath=%s, remotePath=%s", args.Endpoint, args.LocalPath, args.RemotePath)
+
+	if args.LocalPath == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "localPath is required")
+	}
+	if args.RemotePath == "" {
+		args.RemotePath = filepath.Base(args.LocalPath)
+	}
+
+	src, err := s.localPath(args.LocalPath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		log.Printf("Error: Failed to stat local file: %v", err)
+		return nil, fmt.Errorf("failed to stat local file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("local path is a directory: %s", args.LocalPath)
+	}
+
+	ep, client, err := s.connect(ctx, args.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	dest, err := remotePath(ep.Root, args.RemotePath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	start := time.Now()
+	if err := client.Upload(ctx, src, dest, progress.New(ctx, req, float64(info.Size())).Func()); err != nil {
+		log.Printf("Error: Upload failed: %v", err)
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+
+	resultMsg := fmt.Sprintf("Uploaded %s to %s:%s (%d bytes in %s)",
+		args.LocalPath, args.Endpoint, dest, info.Size(), time.Since(start).Round(time.Millisecond))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultMsg,
+			},
+		},
+	}
+
+	log.Println("uploadFile request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *FileTransferServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// loadEndpoints reads the endpoints configuration file.
+func loadEndpoints(configPath string) (map[string]Endpoint, error) {
+	if configPath == "" {
+		return map[string]Endpoint{}, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading endpoints file %s: %w", configPath, err)
+	}
+
+	var config EndpointsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing endpoints file: %w", err)
+	}
+
+	for name, ep := range config.Endpoints {
+		if ep.Host == "" {
+			return nil, fmt.Errorf("endpoint %s: host is required", name)
+		}
+		switch strings.ToLower(ep.Protocol) {
+		case "ftp", "sftp":
+		default:
+			return nil, fmt.Errorf("endpoint %s: unsupported protocol %q", name, ep.Protocol)
+		}
+		if ep.PasswordEnv != "" && ep.Password == "" {
+			ep.Password = os.Getenv(ep.PasswordEnv)
+		}
+		config.Endpoints[name] = ep
+	}
+	return config.Endpoints, nil
+}
+
+// New creates the filetransfer server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("filetransfer", flag.ContinueOnError)
+	var (
+		endpointsFile string
+		localDir      string
+		timeout       int
+	)
+	fs.StringVar(&endpointsFile, "endpoints", "", "Path to the JSON file declaring SFTP/FTP endpoints")
+	fs.StringVar(&localDir, "local-dir", ".", "Local directory that downloads and uploads are restricted to")
+	fs.IntVar(&timeout, "timeout", 60, "Connection and transfer timeout in seconds")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var sandboxFlags sandbox.Flags
+	sandboxFlags.Register(fs, "local directory")
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	endpoints, err := loadEndpoints(endpointsFile)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting file transfer server: endpoints=%d, local-dir=%s, timeout=%ds", len(endpoints), localDir, timeout)
+	if len(endpoints) == 0 {
+		log.Printf("Warning: No endpoints configured. Use the -endpoints flag to declare SFTP/FTP endpoints.")
+	}
+
+	// Create FileTransferServer instance
+	transferServer := NewFileTransferServer(endpoints, localDir, timeout)
+	sandboxFlags.Apply(transferServer.files)
+	log.Println("FileTransferServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), transferServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return transferServer.Server(), transportFlags, nil
+}
+
+// Run starts the filetransfer server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[FileTransferServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create filetransfer server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("FileTransferServer shutdown")
+	return nil
+}
@@ -0,0 +1,344 @@
+package filetransfer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FileTransferServer creation test
+func TestNewFileTransferServer(t *testing.T) {
+	endpoints := map[string]Endpoint{
+		"backup": {Protocol: "ftp", Host: "ftp.example.com"},
+	}
+
+	fs := NewFileTransferServer(endpoints, "/tmp", 30)
+
+	assert.NotNil(t, fs, "FileTransferServer instance should be created")
+	assert.Equal(t, endpoints, fs.endpoints, "Endpoints should match")
+	assert.Equal(t, "/tmp", fs.localDir, "Local directory should match")
+	assert.Equal(t, 30*time.Second, fs.timeout, "Timeout should match")
+	assert.NotNil(t, fs.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	fs := NewFileTransferServer(nil, ".", 30)
+	assert.NotNil(t, fs.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test remote path jailing
+func TestRemotePath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		root     string
+		path     string
+		expected string
+	}{
+		{name: "Empty root", root: "", path: "dir/file.txt", expected: "/dir/file.txt"},
+		{name: "Relative path", root: "/srv/ftp", path: "file.txt", expected: "/srv/ftp/file.txt"},
+		{name: "Absolute path is rooted", root: "/srv/ftp", path: "/etc/passwd", expected: "/srv/ftp/etc/passwd"},
+		{name: "Traversal is clamped", root: "/srv/ftp", path: "../../etc/passwd", expected: "/srv/ftp/etc/passwd"},
+		{name: "Root itself", root: "/srv/ftp", path: "", expected: "/srv/ftp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := remotePath(tc.root, tc.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, resolved)
+		})
+	}
+
+	for _, p := range []string{"file\x00.txt", "x\n!touch /tmp/pwned #", "file.txt\r\nDELE /etc/passwd", "tab\tname"} {
+		_, err := remotePath("/srv", p)
+		assert.Error(t, err, "Control characters should be rejected in %q", p)
+	}
+}
+
+// Test sftp batch quoting
+func TestSFTPQuote(t *testing.T) {
+	assert.Equal(t, `"/upload/report.pdf"`, sftpQuote("/upload/report.pdf"))
+	assert.Equal(t, `"/upload/my \"quoted\" file"`, sftpQuote(`/upload/my "quoted" file`))
+	assert.Equal(t, `"/upload/\*.txt"`, sftpQuote("/upload/*.txt"), "Globs should match literally")
+	assert.Equal(t, `"/upload/file\?\[1].txt"`, sftpQuote("/upload/file?[1].txt"))
+	assert.Equal(t, `"C:\\data"`, sftpQuote(`C:\data`))
+}
+
+// Test local path jailing
+func TestLocalPath(t *testing.T) {
+	fs := NewFileTransferServer(nil, t.TempDir(), 30)
+
+	resolved, err := fs.localPath("sub/file.txt")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(resolved, filepath.Join("sub", "file.txt")))
+
+	_, err = fs.localPath("file.txt\n!id")
+	assert.Error(t, err, "Line breaks should be rejected")
+}
+
+// Test reading the local directory as resources
+func TestFileResources(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "notes.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0xff, 0x00}, 0644))
+	fs := NewFileTransferServer(nil, dir, 30)
+
+	contents, err := mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local")
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResourceText(contents), `"uri": "filetransfer://local/sub/notes.txt"`)
+	assert.Contains(t, mcptest.ResourceText(contents), `"uri": "filetransfer://local/image.bin"`)
+
+	contents, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local/sub/notes.txt")
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, mcp.TextResourceContents{URI: "filetransfer://local/sub/notes.txt", MIMEType: "text/plain; charset=utf-8", Text: "hello"}, contents[0])
+
+	contents, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local/image.bin")
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "/wA=", contents[0].(mcp.BlobResourceContents).Blob)
+
+	_, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local/missing.txt")
+	assert.EqualError(t, err, "no file missing.txt in the local directory")
+	_, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local/sub")
+	assert.EqualError(t, err, "sub is not a file")
+
+	// Files the deny rules exclude are neither listed nor read
+	fs.files.SetRules(nil, []string{"sub"})
+	contents, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local")
+	require.NoError(t, err)
+	assert.NotContains(t, mcptest.ResourceText(contents), "notes.txt")
+	assert.Contains(t, mcptest.ResourceText(contents), "image.bin")
+	_, err = mcptest.ReadResource(ctx, fs.Server(), "filetransfer://local/sub/notes.txt")
+	assert.ErrorContains(t, err, "sub/notes.txt is not allowed in the local directory")
+}
+
+// Test listing parsers
+func TestParseListings(t *testing.T) {
+	entry, ok := parseMLSDLine("type=file;size=1024;modify=20250406143000; report.pdf")
+	assert.True(t, ok)
+	assert.Equal(t, "report.pdf", entry.Name)
+	assert.Equal(t, int64(1024), entry.Size)
+	assert.False(t, entry.IsDir)
+	assert.Equal(t, 2025, entry.ModTime.Year())
+
+	_, ok = parseMLSDLine("type=cdir;modify=20250406143000; .")
+	assert.False(t, ok, "Current directory entries should be skipped")
+
+	entry, ok = parseLongListing("drwxr-xr-x    2 user     group        4096 Jan  2  2024 my dir")
+	assert.True(t, ok)
+	assert.Equal(t, "my dir", entry.Name)
+	assert.True(t, entry.IsDir)
+
+	entry, ok = parseLongListing("-rw-r--r--    1 user     group          42 Mar 14 09:26 /upload/notes.txt")
+	assert.True(t, ok)
+	assert.Equal(t, "notes.txt", entry.Name)
+	assert.Equal(t, int64(42), entry.Size)
+
+	_, ok = parseLongListing("sftp> ls -la /upload")
+	assert.False(t, ok, "Command echo lines should be ignored")
+}
+
+// fakeFTPServer is a tiny in-memory FTP server supporting the commands used by ftpClient.
+type fakeFTPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	files    map[string][]byte
+	commands []string
+}
+
+func newFakeFTPServer(t *testing.T) *fakeFTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeFTPServer{listener: listener, files: map[string][]byte{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeFTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	var data net.Listener
+	reply("220 fake ftp ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.ToUpper(cmd))
+		s.mu.Unlock()
+
+		switch strings.ToUpper(cmd) {
+		case "USER":
+			reply("331 password required")
+		case "PASS":
+			if arg != "secret" {
+				reply("530 login incorrect")
+				continue
+			}
+			reply("230 logged in")
+		case "TYPE":
+			reply("200 type set")
+		case "EPSV":
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "SIZE":
+			s.mu.Lock()
+			content, ok := s.files[arg]
+			s.mu.Unlock()
+			if !ok {
+				reply("550 not found")
+				continue
+			}
+			reply("213 %d", len(content))
+		case "MKD":
+			reply("257 created")
+		case "MLSD", "RETR", "STOR":
+			reply("150 opening data connection")
+			dc, err := data.Accept()
+			data.Close()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			switch strings.ToUpper(cmd) {
+			case "MLSD":
+				for name, content := range s.files {
+					if strings.HasPrefix(name, strings.TrimSuffix(arg, "/")+"/") {
+						fmt.Fprintf(dc, "type=file;size=%d;modify=20250406143000; %s\r\n", len(content), filepath.Base(name))
+					}
+				}
+			case "RETR":
+				dc.Write(s.files[arg])
+			case "STOR":
+				s.mu.Unlock()
+				content, _ := io.ReadAll(dc)
+				s.mu.Lock()
+				s.files[arg] = content
+			}
+			s.mu.Unlock()
+			dc.Close()
+			reply("226 transfer complete")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// Test FTP transfers end to end through the tool handlers
+func TestFTPTransfers(t *testing.T) {
+	ftpServer := newFakeFTPServer(t)
+	ftpServer.files["/pub/hello.txt"] = []byte("hello world")
+
+	localDir := t.TempDir()
+	fs := NewFileTransferServer(map[string]Endpoint{
+		"test": {Protocol: "ftp", Host: "127.0.0.1", Port: ftpServer.port(), Username: "user", Password: "secret", Root: "/pub"},
+	}, localDir, 5)
+	ctx := context.Background()
+
+	t.Run("List remote directory", func(t *testing.T) {
+		result, err := fs.handleListRemote(ctx, mcptest.NewCallToolRequest("listRemote", map[string]interface{}{
+			"endpoint": "test",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "hello.txt")
+	})
+
+	t.Run("Download file", func(t *testing.T) {
+		result, err := fs.handleDownloadFile(ctx, mcptest.NewCallToolRequest("downloadFile", map[string]interface{}{
+			"endpoint":   "test",
+			"remotePath": "hello.txt",
+			"localPath":  "downloads/hello.txt",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "11 bytes")
+		content, err := os.ReadFile(filepath.Join(localDir, "downloads", "hello.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", string(content))
+	})
+
+	t.Run("Upload file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "upload.txt"), []byte("uploaded"), 0644))
+
+		result, err := fs.handleUploadFile(ctx, mcptest.NewCallToolRequest("uploadFile", map[string]interface{}{
+			"endpoint":  "test",
+			"localPath": "upload.txt",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "/pub/upload.txt")
+		ftpServer.mu.Lock()
+		assert.Equal(t, "uploaded", string(ftpServer.files["/pub/upload.txt"]))
+		ftpServer.mu.Unlock()
+	})
+
+	t.Run("Command injection is rejected", func(t *testing.T) {
+		result, err := fs.handleDownloadFile(ctx, mcptest.NewCallToolRequest("downloadFile", map[string]interface{}{
+			"endpoint":   "test",
+			"remotePath": "hello.txt\r\nDELE /etc/passwd",
+			"localPath":  "injected.txt",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		ftpServer.mu.Lock()
+		assert.NotContains(t, ftpServer.commands, "DELE")
+		ftpServer.mu.Unlock()
+	})
+
+	t.Run("Unknown endpoint", func(t *testing.T) {
+		result, err := fs.handleListRemote(ctx, mcptest.NewCallToolRequest("listRemote", map[string]interface{}{
+			"endpoint": "missing",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unknown endpoint")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewFileTransferServer(nil, f.TempDir(), 30).Server())
+}
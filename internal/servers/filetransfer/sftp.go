@@ -0,0 +1,207 @@
+package filetransfer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sftpClient drives the OpenSSH sftp client in batch mode. Authentication relies on
+// key files or a running ssh-agent, since batch mode cannot prompt for passwords.
+type sftpClient struct {
+	ep      Endpoint
+	timeout time.Duration
+}
+
+// newSFTPClient prepares an SFTP backend for ep. Connections are made per operation.
+func newSFTPClient(ep Endpoint, timeout time.Duration) (*sftpClient, error) {
+	if _, err := exec.LookPath("sftp"); err != nil {
+		return nil, fmt.Errorf("sftp client not found in PATH: %w", err)
+	}
+	return &sftpClient{ep: ep, timeout: timeout}, nil
+}
+
+// args builds the sftp command line for the endpoint.
+func (c *sftpClient) args() []string {
+	args := []string{
+		"-b", "-",
+		"-o", "BatchMode=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(c.timeout.Seconds())),
+	}
+	if c.ep.Port != 0 {
+		args = append(args, "-P", strconv.Itoa(c.ep.Port))
+	}
+	if c.ep.IdentityFile != "" {
+		args = append(args, "-i", c.ep.IdentityFile)
+	}
+	target := c.ep.Host
+	if c.ep.Username != "" {
+		target = c.ep.Username + "@" + target
+	}
+	// "--" keeps a hostile host value from being parsed as an option
+	return append(args, "--", target)
+}
+
+// run executes a batch of sftp commands and returns stdout.
+func (c *sftpClient) run(ctx context.Context, commands ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sftp", c.args()...)
+	cmd.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("sftp: %s", msg)
+	}
+	return stdout.String(), nil
+}
+
+// sftpQuoter escapes the characters the sftp batch parser treats specially inside
+// double quotes. sftp glob expands get, put and ls arguments, so glob characters are
+// escaped to match literally.
+var sftpQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+
+// sftpQuote quotes an argument for the sftp batch command parser. Arguments must not
+// contain line breaks, which end the batch command; remotePath and localPath reject them.
+func sftpQuote(s string) string {
+	return `"` + sftpQuoter.Replace(s) + `"`
+}
+
+// List returns the entries of dir parsed from "ls -la" output.
+func (c *sftpClient) List(ctx context.Context, dir string) ([]RemoteEntry, error) {
+	out, err := c.run(ctx, "ls -la "+sftpQuote(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []RemoteEntry
+	for _, line := range strings.Split(out, "\n") {
+		entry, ok := parseLongListing(line)
+		if !ok || entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseLongListing parses a "drwxr-xr-x 2 user group 4096 Jan 2 15:04 name" line.
+func parseLongListing(line string) (RemoteEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 9 || len(fields[0]) != 10 || !strings.ContainsRune("-dl", rune(fields[0][0])) {
+		return RemoteEntry{}, false
+	}
+
+	size, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return RemoteEntry{}, false
+	}
+
+	// The name is everything after the eighth field, preserving inner spaces
+	rest := line
+	for i := 0; i < 8; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		idx := strings.IndexAny(rest, " \t")
+		if idx < 0 {
+			return RemoteEntry{}, false
+		}
+		rest = rest[idx:]
+	}
+	name := strings.TrimLeft(rest, " \t")
+	if fields[0][0] == 'l' {
+		name, _, _ = strings.Cut(name, " -> ")
+	}
+	// sftp prints full paths when listing an absolute directory
+	if idx := strings.LastIndex(name, "/"); idx >= 0 && idx < len(name)-1 {
+		name = name[idx+1:]
+	}
+
+	entry := RemoteEntry{
+		Name:  name,
+		Size:  size,
+		IsDir: fields[0][0] == 'd',
+	}
+	stamp := strings.Join(fields[5:8], " ")
+	if t, err := time.Parse("Jan _2 15:04", stamp); err == nil {
+		entry.ModTime = t.AddDate(time.Now().Year(), 0, 0)
+	} else if t, err := time.Parse("Jan _2 2006", stamp); err == nil {
+		entry.ModTime = t
+	}
+	return entry, true
+}
+
+// Size returns the size of a remote file.
+func (c *sftpClient) Size(ctx context.Context, p string) (int64, error) {
+	out, err := c.run(ctx, "ls -ln "+sftpQuote(p))
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if entry, ok := parseLongListing(line); ok {
+			return entry.Size, nil
+		}
+	}
+	return 0, fmt.Errorf("file not found: %s", p)
+}
+
+// Download fetches remotePath into localPath, reporting progress by watching the local file grow.
+func (c *sftpClient) Download(ctx context.Context, remotePath, localPath string, progress func(int64)) error {
+	done := make(chan struct{})
+	if progress != nil {
+		go func() {
+			ticker := time.NewTicker(250 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if info, err := os.Stat(localPath); err == nil {
+						progress(info.Size())
+					}
+				}
+			}
+		}()
+	}
+
+	_, err := c.run(ctx, "get "+sftpQuote(remotePath)+" "+sftpQuote(localPath))
+	close(done)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		if info, err := os.Stat(localPath); err == nil {
+			progress(info.Size())
+		}
+	}
+	return nil
+}
+
+// Upload sends localPath to remotePath. Progress is only reported on completion because
+// the sftp client does not expose upload progress in batch mode.
+func (c *sftpClient) Upload(ctx context.Context, localPath, remotePath string, progress func(int64)) error {
+	if _, err := c.run(ctx, "put "+sftpQuote(localPath)+" "+sftpQuote(remotePath)); err != nil {
+		return err
+	}
+	if progress != nil {
+		if info, err := os.Stat(localPath); err == nil {
+			progress(info.Size())
+		}
+	}
+	return nil
+}
+
+// Close is a no-op because every operation runs its own sftp session.
+func (c *sftpClient) Close() error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package geocoding
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const earthRadiusKm = 6371.0088
+
+// Coordinate is a WGS84 latitude/longitude pair
+type Coordinate struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func (c Coordinate) String() string {
+	return fmt.Sprintf("%.6f,%.6f", c.Lat, c.Lon)
+}
+
+// parseCoordinate parses a "lat,lon" string. ok is false when s is not a coordinate pair.
+func parseCoordinate(s string) (Coordinate, bool, error) {
+	latStr, lonStr, found := strings.Cut(s, ",")
+	if !found {
+		return Coordinate{}, false, nil
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err1 != nil || err2 != nil {
+		// Not numeric, so treat it as an address such as "Paris, France"
+		return Coordinate{}, false, nil
+	}
+	c := Coordinate{Lat: lat, Lon: lon}
+	if err := c.validate(); err != nil {
+		return Coordinate{}, true, err
+	}
+	return c, true, nil
+}
+
+// validate checks that the coordinate lies within WGS84 bounds.
+func (c Coordinate) validate() error {
+	if c.Lat < -90 || c.Lat > 90 {
+		return fmt.Errorf("latitude %f out of range [-90, 90]", c.Lat)
+	}
+	if c.Lon < -180 || c.Lon > 180 {
+		return fmt.Errorf("longitude %f out of range [-180, 180]", c.Lon)
+	}
+	return nil
+}
+
+// haversineKm returns the great-circle distance between two coordinates in kilometres.
+func haversineKm(a, b Coordinate) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(b.Lat - a.Lat)
+	dLon := toRad(b.Lon - a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Lat))*math.Cos(toRad(b.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// travelModes holds average speeds (km/h) and detour factors used for travel-time estimates.
+// The detour factor approximates the ratio of road/path distance to straight-line distance.
+var travelModes = map[string]struct {
+	speedKmh float64
+	detour   float64
+}{
+	"driving": {speedKmh: 60, detour: 1.3},
+	"cycling": {speedKmh: 16, detour: 1.25},
+	"walking": {speedKmh: 5, detour: 1.2},
+}
+
+// estimateTravel returns the estimated route distance and duration for a straight-line distance.
+func estimateTravel(distanceKm float64, mode string) (float64, time.Duration, error) {
+	m, ok := travelModes[mode]
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported travel mode: %s", mode)
+	}
+	routeKm := distanceKm * m.detour
+	hours := routeKm / m.speedKmh
+	return routeKm, time.Duration(hours * float64(time.Hour)).Round(time.Minute), nil
+}
+
+// tileXY converts a coordinate to slippy map tile numbers at zoom.
+func tileXY(c Coordinate, zoom int) (int, int) {
+	n := math.Exp2(float64(zoom))
+	latRad := c.Lat * math.Pi / 180
+	x := int(math.Floor((c.Lon + 180) / 360 * n))
+	y := int(math.Floor((1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n))
+	clamp := func(v int) int {
+		return max(0, min(int(n)-1, v))
+	}
+	return clamp(x), clamp(y)
+}
+
+// intervalLimiter spaces upstream requests at least interval apart, as required by the
+// Nominatim and OSM tile usage policies (at most one request per second).
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Wait blocks until the next request slot is available or ctx is done.
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
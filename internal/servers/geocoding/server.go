@@ -0,0 +1,781 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const (
+	providerNominatim = "nominatim"
+	providerGoogle    = "google"
+
+	defaultNominatimURL = "https://nominatim.openstreetmap.org"
+	defaultGoogleURL    = "https://maps.googleapis.com/maps/api"
+	defaultTileURL      = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+	defaultUserAgent    = "mcphost-geocoding/1.0 (+https://github.com/mark3labs/mcphost)"
+
+	// cacheTTL is how long upstream responses and tool results are cached; places
+	// rarely move
+	cacheTTL = 24 * time.Hour
+)
+
+// Config holds the settings of a GeocodingServer
+type Config struct {
+	Provider     string        // "nominatim" or "google"
+	APIKey       string        // Google Maps API key
+	NominatimURL string        // Nominatim base URL
+	GoogleURL    string        // Google Maps API base URL
+	TileURL      string        // Slippy map tile URL template used for static maps with Nominatim
+	UserAgent    string        // User-Agent sent upstream; Nominatim requires an identifying value
+	Email        string        // Contact email passed to Nominatim
+	Language     string        // Preferred result language (e.g. "en")
+	Timeout      time.Duration // HTTP request timeout
+	MaxBodySize  int64         // Maximum upstream response size in bytes
+	MinInterval  time.Duration // Minimum spacing between upstream requests
+	CacheSize    int           // Number of cached upstream responses; 0 disables caching
+	CacheTTL     time.Duration // Lifetime of cached responses
+}
+
+// Place is a geocoding result
+type Place struct {
+	Name string
+	Type string
+	Coordinate
+}
+
+// GeocodingServer is an MCP server providing geocoding, distance and map tools.
+type GeocodingServer struct {
+	server  *server.MCPServer
+	client  *http.Client
+	config  Config
+	cache   *cache.Memory
+	limiter *intervalLimiter
+}
+
+// NewGeocodingServer creates a new GeocodingServer instance.
+func NewGeocodingServer(config Config) *GeocodingServer {
+	log.Printf("GeocodingServer created: provider=%s, minInterval=%s, cacheSize=%d, cacheTTL=%s",
+		config.Provider, config.MinInterval, config.CacheSize, config.CacheTTL)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     config.Timeout,
+		MaxBodySize: config.MaxBodySize,
+	})
+
+	s := &GeocodingServer{
+		client:  client,
+		config:  config,
+		cache:   cache.NewMemory(config.CacheSize),
+		limiter: &intervalLimiter{interval: config.MinInterval},
+	}
+
+	mcpServer := server.NewMCPServer(
+		"geocoding-server", // server name
+		"1.0.0",            // version
+	)
+
+	// Register geocode tool
+	geocodeTool := mcp.NewTool("geocode",
+		mcp.WithDescription("Converts an address or place name into coordinates"),
+		mcp.WithString("query",
+			mcp.Description("Address or place name to look up"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results (1-10, default: 5)"),
+		),
+	)
+
+	// Register reverseGeocode tool
+	reverseTool := mcp.NewTool("reverseGeocode",
+		mcp.WithDescription("Converts coordinates into the nearest address"),
+		mcp.WithNumber("lat",
+			mcp.Description("Latitude in decimal degrees"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("lon",
+			mcp.Description("Longitude in decimal degrees"),
+			mcp.Required(),
+		),
+	)
+
+	// Register distance tool
+	distanceTool := mcp.NewTool("distance",
+		mcp.WithDescription("Calculates the straight-line distance between two places and estimates travel distance and time. Estimates use average speeds, not routing"),
+		mcp.WithString("from",
+			mcp.Description("Origin as 'lat,lon' or an address"),
+			mcp.Required(),
+		),
+		mcp.WithString("to",
+			mcp.Description("Destination as 'lat,lon' or an address"),
+			mcp.Required(),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Travel mode used for the estimate"),
+			mcp.Enum("driving", "cycling", "walking"),
+			mcp.DefaultString("driving"),
+		),
+	)
+
+	// Register staticMap tool
+	staticMapTool := mcp.NewTool("staticMap",
+		mcp.WithDescription("Returns a map image centred on a place. With the Nominatim provider this is a single 256x256 OpenStreetMap tile"),
+		mcp.WithString("center",
+			mcp.Description("Map centre as 'lat,lon' or an address"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("zoom",
+			mcp.Description("Zoom level (1-18, default: 14)"),
+		),
+		mcp.WithNumber("width",
+			mcp.Description("Image width in pixels, Google provider only (max 640, default: 600)"),
+		),
+		mcp.WithNumber("height",
+			mcp.Description("Image height in pixels, Google provider only (max 640, default: 400)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, geocodeTool, s.handleGeocode)
+	middleware.AddTool(mcpServer, reverseTool, s.handleReverseGeocode)
+	middleware.AddTool(mcpServer, distanceTool, s.handleDistance)
+	middleware.AddTool(mcpServer, staticMapTool, s.handleStaticMap)
+	middleware.Cacheable(mcpServer, "geocode", cache.Policy{TTL: cacheTTL})
+	middleware.Cacheable(mcpServer, "reverseGeocode", cache.Policy{TTL: cacheTTL})
+
+	s.server = mcpServer
+	return s
+}
+
+// fetch performs a cached, rate limited GET request against an upstream service.
+func (s *GeocodingServer) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if body, ok, _ := s.cache.Get(ctx, rawURL); ok {
+		log.Printf("Cache hit for %s", redactKey(rawURL))
+		return body, nil
+	}
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", s.config.UserAgent)
+	if s.config.Language != "" {
+		httpReq.Header.Set("Accept-Language", s.config.Language)
+	}
+
+	log.Printf("Sending request to %s", redactKey(rawURL))
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.config.MaxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error: API returned status code %d", resp.StatusCode)
+		return nil, fmt.Errorf("API error: status code %d", resp.StatusCode)
+	}
+
+	s.cache.Set(ctx, rawURL, body, s.config.CacheTTL)
+	return body, nil
+}
+
+// redactKey hides the API key when logging URLs.
+func redactKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	if q.Has("key") {
+		q.Set("key", "REDACTED")
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// googleResponse is the common envelope of Google geocoding responses
+type googleResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []struct {
+		FormattedAddress string   `json:"formatted_address"`
+		Types            []string `json:"types"`
+		Geometry         struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+// places converts a Google response into places.
+func (r googleResponse) places(limit int) ([]Place, error) {
+	switch r.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("Google API error: %s %s", r.Status, r.ErrorMessage)
+	}
+
+	var places []Place
+	for _, result := range r.Results {
+		if len(places) >= limit {
+			break
+		}
+		place := Place{
+			Name:       result.FormattedAddress,
+			Coordinate: Coordinate{Lat: result.Geometry.Location.Lat, Lon: result.Geometry.Location.Lng},
+		}
+		if len(result.Types) > 0 {
+			place.Type = result.Types[0]
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+// nominatimPlace is a Nominatim search or reverse result
+type nominatimPlace struct {
+	DisplayName string `json:"display_name"`
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	Category    string `json:"category"`
+	Type        string `json:"type"`
+	Error       string `json:"error"`
+}
+
+// place converts a Nominatim result into a Place.
+func (p nominatimPlace) place() (Place, error) {
+	lat, err1 := strconv.ParseFloat(p.Lat, 64)
+	lon, err2 := strconv.ParseFloat(p.Lon, 64)
+	if err1 != nil || err2 != nil {
+		return Place{}, fmt.Errorf("invalid coordinates in response: %s,%s", p.Lat, p.Lon)
+	}
+	placeType := p.Type
+	if p.Category != "" {
+		placeType = p.Category + "/" + p.Type
+	}
+	return Place{Name: p.DisplayName, Type: placeType, Coordinate: Coordinate{Lat: lat, Lon: lon}}, nil
+}
+
+// nominatimParams returns query parameters common to all Nominatim requests.
+func (s *GeocodingServer) nominatimParams() url.Values {
+	values := url.Values{}
+	values.Set("format", "jsonv2")
+	if s.config.Email != "" {
+		values.Set("email", s.config.Email)
+	}
+	if s.config.Language != "" {
+		values.Set("accept-language", s.config.Language)
+	}
+	return values
+}
+
+// googleParams returns query parameters common to all Google requests.
+func (s *GeocodingServer) googleParams() (url.Values, error) {
+	if s.config.APIKey == "" {
+		return nil, fmt.Errorf("Google Maps API key is not configured")
+	}
+	values := url.Values{}
+	values.Set("key", s.config.APIKey)
+	if s.config.Language != "" {
+		values.Set("language", s.config.Language)
+	}
+	return values, nil
+}
+
+// geocode looks up places matching query.
+func (s *GeocodingServer) geocode(ctx context.Context, query string, limit int) ([]Place, error) {
+	if s.config.Provider == providerGoogle {
+		values, err := s.googleParams()
+		if err != nil {
+			return nil, err
+		}
+		values.Set("address", query)
+		body, err := s.fetch(ctx, s.config.GoogleURL+"/geocode/json?"+values.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var resp googleResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse API response: %w", err)
+		}
+		return resp.places(limit)
+	}
+
+	values := s.nominatimParams()
+	values.Set("q", query)
+	values.Set("limit", strconv.Itoa(limit))
+	body, err := s.fetch(ctx, s.config.NominatimURL+"/search?"+values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var results []nominatimPlace
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	var places []Place
+	for _, result := range results {
+		place, err := result.place()
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+// reverseGeocode looks up the address nearest to c.
+func (s *GeocodingServer) reverseGeocode(ctx context.Context, c Coordinate) (*Place, error) {
+	if s.config.Provider == providerGoogle {
+		values, err := s.googleParams()
+		if err != nil {
+			return nil, err
+		}
+		values.Set("latlng", c.String())
+		body, err := s.fetch(ctx, s.config.GoogleURL+"/geocode/json?"+values.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var resp googleResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse API response: %w", err)
+		}
+		places, err := resp.places(1)
+		if err != nil || len(places) == 0 {
+			return nil, err
+		}
+		return &places[0], nil
+	}
+
+	values := s.nominatimParams()
+	values.Set("lat", strconv.FormatFloat(c.Lat, 'f', -1, 64))
+	values.Set("lon", strconv.FormatFloat(c.Lon, 'f', -1, 64))
+	body, err := s.fetch(ctx, s.config.NominatimURL+"/reverse?"+values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var result nominatimPlace
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	if result.Error != "" {
+		// Nominatim reports "Unable to geocode" for locations without an address, e.g. the open sea
+		return nil, nil
+	}
+	place, err := result.place()
+	if err != nil {
+		return nil, err
+	}
+	return &place, nil
+}
+
+// resolve turns a "lat,lon" string or an address into a coordinate and a label.
+func (s *GeocodingServer) resolve(ctx context.Context, location string) (Coordinate, string, error) {
+	c, isCoord, err := parseCoordinate(location)
+	if err != nil {
+		return Coordinate{}, "", err
+	}
+	if isCoord {
+		return c, c.String(), nil
+	}
+	places, err := s.geocode(ctx, location, 1)
+	if err != nil {
+		return Coordinate{}, "", err
+	}
+	if len(places) == 0 {
+		return Coordinate{}, "", fmt.Errorf("location not found: %s", location)
+	}
+	return places[0].Coordinate, places[0].Name, nil
+}
+
+// handleGeocode handles the geocoding request.
+func (s *GeocodingServer) handleGeocode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting geocode request processing")
+
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = 5
+	} else if args.Limit > 10 {
+		args.Limit = 10
+	}
+
+	places, err := s.geocode(ctx, args.Query, args.Limit)
+	if err != nil {
+		log.Printf("Error: Geocoding failed: %v", err)
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Geocoding results for '%s'\n\n", args.Query))
+	if len(places) == 0 {
+		resultContent.WriteString("No results found.")
+	}
+	for i, place := range places {
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n   Coordinates: %s\n", i+1, place.Name, place.Coordinate))
+		if place.Type != "" {
+			resultContent.WriteString(fmt.Sprintf("   Type: %s\n", place.Type))
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("geocode request completed")
+	return result, nil
+}
+
+// handleReverseGeocode handles the reverse geocoding request.
+func (s *GeocodingServer) handleReverseGeocode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting reverseGeocode request processing")
+
+	var args Coordinate
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+
+	place, err := s.reverseGeocode(ctx, args)
+	if err != nil {
+		log.Printf("Error: Reverse geocoding failed: %v", err)
+		return nil, err
+	}
+
+	text := fmt.Sprintf("No address found near %s", args)
+	if place != nil {
+		text = fmt.Sprintf("Address near %s:\n%s", args, place.Name)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+
+	log.Println("reverseGeocode request completed")
+	return result, nil
+}
+
+// handleDistance handles the distance estimation request.
+func (s *GeocodingServer) handleDistance(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting distance request processing")
+
+	var args struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Mode string `json:"mode,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.From == "" || args.To == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "from and to are required")
+	}
+	if args.Mode == "" {
+		args.Mode = "driving"
+	}
+
+	from, fromLabel, err := s.resolve(ctx, args.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve origin: %w", err)
+	}
+	to, toLabel, err := s.resolve(ctx, args.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination: %w", err)
+	}
+
+	straight := haversineKm(from, to)
+	routeKm, duration, err := estimateTravel(straight, args.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("From: %s (%s)\n", fromLabel, from))
+	resultContent.WriteString(fmt.Sprintf("To: %s (%s)\n\n", toLabel, to))
+	resultContent.WriteString(fmt.Sprintf("Straight-line distance: %.2f km (%.2f mi)\n", straight, straight*0.621371))
+	resultContent.WriteString(fmt.Sprintf("Estimated %s distance: %.1f km\n", args.Mode, routeKm))
+	resultContent.WriteString(fmt.Sprintf("Estimated %s time: %s\n", args.Mode, duration))
+	resultContent.WriteString("\nTravel estimates are based on average speeds and do not account for actual routes or traffic.")
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("distance request completed")
+	return result, nil
+}
+
+// handleStaticMap handles the map image request.
+func (s *GeocodingServer) handleStaticMap(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting staticMap request processing")
+
+	var args struct {
+		Center string `json:"center"`
+		Zoom   int    `json:"zoom,omitempty"`
+		Width  int    `json:"width,omitempty"`
+		Height int    `json:"height,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Center == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "center is required")
+	}
+	if args.Zoom <= 0 {
+		args.Zoom = 14
+	} else if args.Zoom > 18 {
+		args.Zoom = 18
+	}
+	if args.Width <= 0 {
+		args.Width = 600
+	}
+	if args.Height <= 0 {
+		args.Height = 400
+	}
+	args.Width = min(640, args.Width)
+	args.Height = min(640, args.Height)
+
+	center, label, err := s.resolve(ctx, args.Center)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve center: %w", err)
+	}
+
+	var mapURL, attribution string
+	if s.config.Provider == providerGoogle {
+		values, err := s.googleParams()
+		if err != nil {
+			return nil, err
+		}
+		values.Set("center", center.String())
+		values.Set("zoom", strconv.Itoa(args.Zoom))
+		values.Set("size", fmt.Sprintf("%dx%d", args.Width, args.Height))
+		values.Set("markers", center.String())
+		mapURL = s.config.GoogleURL + "/staticmap?" + values.Encode()
+		attribution = "Map data © Google"
+	} else {
+		x, y := tileXY(center, args.Zoom)
+		mapURL = strings.NewReplacer(
+			"{z}", strconv.Itoa(args.Zoom),
+			"{x}", strconv.Itoa(x),
+			"{y}", strconv.Itoa(y),
+		).Replace(s.config.TileURL)
+		attribution = "© OpenStreetMap contributors"
+	}
+
+	image, err := s.fetch(ctx, mapURL)
+	if err != nil {
+		log.Printf("Error: Failed to fetch map image: %v", err)
+		return nil, fmt.Errorf("failed to fetch map image: %w", err)
+	}
+	mimeType := http.DetectContentType(image)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("map service returned %s instead of an image", mimeType)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Map of %s (%s) at zoom %d. %s", label, center, args.Zoom, attribution),
+			},
+			mcp.ImageContent{
+				Type:     "image",
+				Data:     base64.StdEncoding.EncodeToString(image),
+				MIMEType: mimeType,
+			},
+		},
+	}
+
+	log.Println("staticMap request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *GeocodingServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the geocoding server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("geocoding", flag.ContinueOnError)
+	var (
+		provider     string
+		apiKey       string
+		nominatimURL string
+		googleURL    string
+		tileURL      string
+		userAgent    string
+		email        string
+		language     string
+		timeout      int
+		maxBodySize  int64
+		minInterval  int
+		cacheSize    int
+	)
+	fs.StringVar(&provider, "provider", providerNominatim, "Geocoding provider: nominatim or google")
+	fs.StringVar(&apiKey, "api-key", "", "Google Maps API key (google provider)")
+	fs.StringVar(&nominatimURL, "nominatim-url", defaultNominatimURL, "Nominatim base URL")
+	fs.StringVar(&googleURL, "google-url", defaultGoogleURL, "Google Maps API base URL")
+	fs.StringVar(&tileURL, "tile-url", defaultTileURL, "Map tile URL template used for static maps with the nominatim provider")
+	fs.StringVar(&userAgent, "user-agent", defaultUserAgent, "User-Agent identifying this application to upstream services")
+	fs.StringVar(&email, "email", "", "Contact email sent to Nominatim as recommended by its usage policy")
+	fs.StringVar(&language, "language", "", "Preferred language for results (e.g. en, de)")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 5*1024*1024, "Maximum response body size in bytes (default 5MB)")
+	fs.IntVar(&minInterval, "min-interval", 1000, "Minimum interval between upstream requests in milliseconds (Nominatim allows at most 1 request per second)")
+	fs.IntVar(&cacheSize, "cache-size", 1000, "Number of upstream responses to cache (0 disables caching)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("api-key", "GOOGLE_MAPS_API_KEY"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	if provider != providerNominatim && provider != providerGoogle {
+		log.Printf("Error: Unsupported provider: %s", provider)
+		return nil, transport.Flags{}, fmt.Errorf("unsupported provider: %s", provider)
+	}
+	if provider == providerGoogle && apiKey == "" {
+		log.Printf("Warning: Google Maps API key not configured. The server will start but requests will fail.")
+	}
+	if provider == providerNominatim && minInterval < 1000 && nominatimURL == defaultNominatimURL {
+		log.Printf("Warning: The public Nominatim service allows at most 1 request per second; raising min-interval to 1000ms")
+		minInterval = 1000
+	}
+
+	log.Printf("Starting Geocoding server: provider=%s, timeout=%ds", provider, timeout)
+
+	// Create GeocodingServer instance
+	geoServer := NewGeocodingServer(Config{
+		Provider:     provider,
+		APIKey:       apiKey,
+		NominatimURL: strings.TrimSuffix(nominatimURL, "/"),
+		GoogleURL:    strings.TrimSuffix(googleURL, "/"),
+		TileURL:      tileURL,
+		UserAgent:    userAgent,
+		Email:        email,
+		Language:     language,
+		Timeout:      time.Duration(timeout) * time.Second,
+		MaxBodySize:  maxBodySize,
+		MinInterval:  time.Duration(minInterval) * time.Millisecond,
+		CacheSize:    cacheSize,
+		CacheTTL:     cacheTTL,
+	})
+	log.Println("GeocodingServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), geoServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return geoServer.Server(), transportFlags, nil
+}
+
+// Run starts the geocoding server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[GeocodingServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create geocoding server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("GeocodingServer shutdown")
+	return nil
+}
@@ -0,0 +1,286 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+// pngHeader is enough for http.DetectContentType to report image/png
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestConfig(serverURL string) Config {
+	return Config{
+		Provider:     providerNominatim,
+		NominatimURL: serverURL,
+		GoogleURL:    serverURL + "/maps/api",
+		TileURL:      serverURL + "/tiles/{z}/{x}/{y}.png",
+		UserAgent:    "test-agent",
+		Timeout:      5 * time.Second,
+		MaxBodySize:  1024 * 1024,
+		CacheSize:    10,
+		CacheTTL:     time.Minute,
+	}
+}
+
+// GeocodingServer creation test
+func TestNewGeocodingServer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider string
+	}{
+		{name: "Nominatim provider", provider: providerNominatim},
+		{name: "Google provider", provider: providerGoogle},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := newTestConfig("http://localhost")
+			config.Provider = tc.provider
+			gs := NewGeocodingServer(config)
+
+			assert.NotNil(t, gs, "GeocodingServer instance should be created")
+			assert.Equal(t, tc.provider, gs.config.Provider, "Provider should match")
+			assert.NotNil(t, gs.cache, "Cache should be initialized")
+			assert.NotNil(t, gs.server, "Internal MCPServer should be initialized")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	gs := NewGeocodingServer(newTestConfig("http://localhost"))
+	assert.NotNil(t, gs.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test coordinate parsing, distance and tile math
+func TestGeoHelpers(t *testing.T) {
+	c, ok, err := parseCoordinate("48.8566, 2.3522")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Coordinate{Lat: 48.8566, Lon: 2.3522}, c)
+
+	_, ok, err = parseCoordinate("Paris, France")
+	assert.NoError(t, err)
+	assert.False(t, ok, "Addresses containing commas should not parse as coordinates")
+
+	_, ok, err = parseCoordinate("91,0")
+	assert.True(t, ok)
+	assert.Error(t, err, "Out of range latitude should be rejected")
+
+	// Paris to London is roughly 344 km
+	distance := haversineKm(Coordinate{Lat: 48.8566, Lon: 2.3522}, Coordinate{Lat: 51.5074, Lon: -0.1278})
+	assert.InDelta(t, 344, distance, 2)
+
+	routeKm, duration, err := estimateTravel(10, "walking")
+	assert.NoError(t, err)
+	assert.InDelta(t, 12, routeKm, 0.001)
+	assert.Equal(t, 144*time.Minute, duration)
+
+	_, _, err = estimateTravel(10, "teleport")
+	assert.Error(t, err)
+
+	x, y := tileXY(Coordinate{Lat: 51.5074, Lon: -0.1278}, 10)
+	assert.Equal(t, 511, x)
+	assert.Equal(t, 340, y)
+}
+
+// Test that the limiter spaces requests
+func TestIntervalLimiter(t *testing.T) {
+	limiter := &intervalLimiter{interval: 50 * time.Millisecond}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.interval = time.Hour
+	limiter.Wait(context.Background())
+	assert.Error(t, limiter.Wait(ctx), "Waiting should stop when the context is cancelled")
+}
+
+// Test tool handlers against mock Nominatim, Google and tile services
+func TestHandlers(t *testing.T) {
+	var upstreamRequests int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamRequests, 1)
+		assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("q") == "nowhere" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"display_name": "Paris, France", "lat": "48.8566", "lon": "2.3522", "category": "boundary", "type": "administrative"}]`))
+		case "/reverse":
+			if r.URL.Query().Get("lat") == "0" {
+				w.Write([]byte(`{"error": "Unable to geocode"}`))
+				return
+			}
+			w.Write([]byte(`{"display_name": "10 Downing Street, London", "lat": "51.5034", "lon": "-0.1276"}`))
+		case "/maps/api/geocode/json":
+			if r.URL.Query().Get("key") != "google-key" {
+				w.Write([]byte(`{"status": "REQUEST_DENIED", "error_message": "The provided API key is invalid."}`))
+				return
+			}
+			w.Write([]byte(`{"status": "OK", "results": [{"formatted_address": "Berlin, Germany", "types": ["locality"], "geometry": {"location": {"lat": 52.52, "lng": 13.405}}}]}`))
+		case "/maps/api/staticmap":
+			w.Write(pngHeader)
+		case "/tiles/14/8299/5636.png":
+			w.Write(pngHeader)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	gs := NewGeocodingServer(newTestConfig(mockServer.URL))
+	ctx := context.Background()
+
+	t.Run("Geocode", func(t *testing.T) {
+		result, err := gs.handleGeocode(ctx, mcptest.NewCallToolRequest("geocode", map[string]interface{}{
+			"query": "Paris",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1. Paris, France")
+		assert.Contains(t, text, "Coordinates: 48.856600,2.352200")
+		assert.Contains(t, text, "Type: boundary/administrative")
+	})
+
+	t.Run("Geocode is cached", func(t *testing.T) {
+		before := atomic.LoadInt32(&upstreamRequests)
+		_, err := gs.handleGeocode(ctx, mcptest.NewCallToolRequest("geocode", map[string]interface{}{
+			"query": "Paris",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, before, atomic.LoadInt32(&upstreamRequests), "Repeated queries should be served from cache")
+	})
+
+	t.Run("Geocode without results", func(t *testing.T) {
+		result, err := gs.handleGeocode(ctx, mcptest.NewCallToolRequest("geocode", map[string]interface{}{
+			"query": "nowhere",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No results found.")
+	})
+
+	t.Run("Reverse geocode", func(t *testing.T) {
+		result, err := gs.handleReverseGeocode(ctx, mcptest.NewCallToolRequest("reverseGeocode", map[string]interface{}{
+			"lat": 51.5034,
+			"lon": -0.1276,
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "10 Downing Street, London")
+	})
+
+	t.Run("Reverse geocode without address", func(t *testing.T) {
+		result, err := gs.handleReverseGeocode(ctx, mcptest.NewCallToolRequest("reverseGeocode", map[string]interface{}{
+			"lat": 0,
+			"lon": 0,
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No address found")
+	})
+
+	t.Run("Reverse geocode out of range", func(t *testing.T) {
+		_, err := gs.handleReverseGeocode(ctx, mcptest.NewCallToolRequest("reverseGeocode", map[string]interface{}{
+			"lat": 100,
+			"lon": 0,
+		}))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Distance between address and coordinates", func(t *testing.T) {
+		result, err := gs.handleDistance(ctx, mcptest.NewCallToolRequest("distance", map[string]interface{}{
+			"from": "Paris",
+			"to":   "51.5074,-0.1278",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "From: Paris, France")
+		assert.Contains(t, text, "Straight-line distance: 343")
+		assert.Contains(t, text, "Estimated driving time:")
+	})
+
+	t.Run("Distance to unknown place", func(t *testing.T) {
+		_, err := gs.handleDistance(ctx, mcptest.NewCallToolRequest("distance", map[string]interface{}{
+			"from": "Paris",
+			"to":   "nowhere",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "location not found")
+	})
+
+	t.Run("Static map from OSM tiles", func(t *testing.T) {
+		result, err := gs.handleStaticMap(ctx, mcptest.NewCallToolRequest("staticMap", map[string]interface{}{
+			"center": "Paris",
+		}))
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, result.Content, 2)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "OpenStreetMap contributors")
+		image := result.Content[1].(mcp.ImageContent)
+		assert.Equal(t, "image/png", image.MIMEType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(pngHeader), image.Data)
+	})
+
+	t.Run("Google provider", func(t *testing.T) {
+		config := newTestConfig(mockServer.URL)
+		config.Provider = providerGoogle
+		config.APIKey = "google-key"
+		google := NewGeocodingServer(config)
+
+		result, err := google.handleGeocode(ctx, mcptest.NewCallToolRequest("geocode", map[string]interface{}{
+			"query": "Berlin",
+		}))
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Berlin, Germany")
+
+		result, err = google.handleStaticMap(ctx, mcptest.NewCallToolRequest("staticMap", map[string]interface{}{
+			"center": "52.52,13.405",
+			"width":  1000,
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", result.Content[1].(mcp.ImageContent).MIMEType)
+	})
+
+	t.Run("Google provider with invalid key", func(t *testing.T) {
+		config := newTestConfig(mockServer.URL)
+		config.Provider = providerGoogle
+		config.APIKey = "wrong"
+		google := NewGeocodingServer(config)
+
+		_, err := google.handleGeocode(ctx, mcptest.NewCallToolRequest("geocode", map[string]interface{}{
+			"query": "Berlin",
+		}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "REQUEST_DENIED")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewGeocodingServer(newTestConfig("http://localhost")).Server())
+}
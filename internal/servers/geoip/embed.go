@@ -0,0 +1,11 @@
+//go:build geoip_embed
+
+package geoip
+
+import "embed"
+
+// embedded holds the databases of the data directory, built into binaries built with
+// the geoip_embed tag.
+//
+//go:embed data/*.mmdb
+var embedded embed.FS
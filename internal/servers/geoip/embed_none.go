@@ -0,0 +1,8 @@
+//go:build !geoip_embed
+
+package geoip
+
+import "embed"
+
+// embedded is empty in binaries built without the geoip_embed tag.
+var embedded embed.FS
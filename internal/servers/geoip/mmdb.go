@@ -0,0 +1,378 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"os"
+	"time"
+)
+
+// metadataMarker precedes the metadata at the end of a MaxMind DB file.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the number of zero bytes between the search tree and the
+// data section.
+const dataSectionSeparator = 16
+
+// maxDepth bounds the nesting of maps and arrays decoded, and maxValues the values
+// of a record, against malformed files, e.g. with maps pointing to themselves.
+const (
+	maxDepth  = 32
+	maxValues = 100000
+)
+
+// Metadata describes a MaxMind DB.
+type Metadata struct {
+	DatabaseType string    `json:"databaseType"`
+	Description  string    `json:"description,omitempty"`
+	Languages    []string  `json:"languages,omitempty"`
+	IPVersion    int       `json:"ipVersion"`
+	RecordSize   int       `json:"recordSize"`
+	NodeCount    uint      `json:"nodeCount"`
+	BuildTime    time.Time `json:"buildTime"`
+}
+
+// Reader looks up addresses in a database of the MaxMind DB format, used by GeoIP2,
+// GeoLite2 and compatible databases such as DB-IP's. See
+// https://maxmind.github.io/MaxMind-DB/.
+type Reader struct {
+	Metadata Metadata
+
+	tree      []byte
+	data      []byte
+	nodeSize  int
+	ipv4Start uint
+}
+
+// OpenReader reads the database of file.
+func OpenReader(file string) (*Reader, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading database %s: %w", file, err)
+	}
+	r, err := NewReader(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading database %s: %w", file, err)
+	}
+	return r, nil
+}
+
+// NewReader reads the database in data.
+func NewReader(data []byte) (*Reader, error) {
+	i := bytes.LastIndex(data, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("not a MaxMind DB: metadata not found")
+	}
+	meta := data[i+len(metadataMarker):]
+	v, _, err := (&decoder{data: meta}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid metadata: not a map")
+	}
+
+	r := &Reader{}
+	r.Metadata.DatabaseType, _ = m["database_type"].(string)
+	r.Metadata.IPVersion = int(toUint(m["ip_version"]))
+	r.Metadata.RecordSize = int(toUint(m["record_size"]))
+	r.Metadata.NodeCount = uint(toUint(m["node_count"]))
+	r.Metadata.BuildTime = time.Unix(int64(toUint(m["build_epoch"])), 0).UTC()
+	if languages, ok := m["languages"].([]interface{}); ok {
+		for _, l := range languages {
+			if s, ok := l.(string); ok {
+				r.Metadata.Languages = append(r.Metadata.Languages, s)
+			}
+		}
+	}
+	if descriptions, ok := m["description"].(map[string]interface{}); ok {
+		r.Metadata.Description = localized(descriptions, "en")
+	}
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+		r.nodeSize = r.Metadata.RecordSize / 4
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", r.Metadata.RecordSize)
+	}
+	if r.Metadata.IPVersion != 4 && r.Metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", r.Metadata.IPVersion)
+	}
+	treeSize := uint64(r.Metadata.NodeCount) * uint64(r.nodeSize)
+	if treeSize+dataSectionSeparator > uint64(i) {
+		return nil, errors.New("search tree is larger than the file")
+	}
+	r.tree = data[:treeSize]
+	r.data = data[treeSize+dataSectionSeparator : i]
+
+	// IPv4 addresses are looked up at ::/96 of IPv6 databases
+	if r.Metadata.IPVersion == 6 {
+		for depth := 0; depth < 96 && r.ipv4Start < r.Metadata.NodeCount; depth++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (r *Reader) record(node uint, bit byte) uint {
+	b := r.tree[node*uint(r.nodeSize):]
+	switch r.Metadata.RecordSize {
+	case 24:
+		b = b[3*uint(bit):]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[4*uint(bit):]))
+	}
+}
+
+// Lookup returns the record of addr and the network of the database it belongs to.
+// ok is false when the database has no record for addr.
+func (r *Reader) Lookup(addr netip.Addr) (record interface{}, network netip.Prefix, ok bool, err error) {
+	addr = addr.Unmap().WithZone("")
+	node := uint(0)
+	if addr.Is4() && r.Metadata.IPVersion == 6 {
+		node = r.ipv4Start
+	} else if addr.Is6() && r.Metadata.IPVersion == 4 {
+		return nil, netip.Prefix{}, false, nil
+	}
+	// The bits of IPv4 addresses are counted from the IPv4 subtree, so that their
+	// networks are IPv4 ones
+	ip := addr.AsSlice()
+	i := 0
+	for ; i < len(ip)*8 && node < r.Metadata.NodeCount; i++ {
+		node = r.record(node, ip[i/8]>>(7-i%8)&1)
+	}
+	if node < r.Metadata.NodeCount {
+		return nil, netip.Prefix{}, false, errors.New("invalid search tree: no record at the end of the address")
+	}
+	network, _ = addr.Prefix(i)
+	if node == r.Metadata.NodeCount {
+		return nil, network, false, nil
+	}
+	pointer := node - r.Metadata.NodeCount - dataSectionSeparator
+	if node < r.Metadata.NodeCount+dataSectionSeparator || pointer >= uint(len(r.data)) {
+		return nil, network, false, fmt.Errorf("invalid search tree: record %d out of the data section", node)
+	}
+	record, _, err = (&decoder{data: r.data}).decode(pointer, 0)
+	if err != nil {
+		return nil, network, false, fmt.Errorf("invalid data for %s: %w", network, err)
+	}
+	return record, network, true, nil
+}
+
+// Types of the data section.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// decoder decodes the values of a data section into strings, float64s, []bytes,
+// uint64s, int32s, *big.Ints, bools, map[string]interface{}s and []interface{}s.
+type decoder struct {
+	data   []byte
+	values int
+}
+
+// errTruncated reports a value running past the data section.
+var errTruncated = errors.New("value runs past the end of the data")
+
+// read returns the n bytes at offset.
+func (d *decoder) read(offset, n uint) ([]byte, error) {
+	if offset+n > uint(len(d.data)) || offset+n < offset {
+		return nil, errTruncated
+	}
+	return d.data[offset : offset+n], nil
+}
+
+// decode decodes the value at offset, returning the offset following it.
+func (d *decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, errors.New("values nested too deeply")
+	}
+	if d.values++; d.values > maxValues {
+		return nil, 0, errors.New("too many values")
+	}
+	b, err := d.read(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctrl := b[0]
+	offset++
+	typ := int(ctrl >> 5)
+
+	if typ == typePointer {
+		size := uint(ctrl>>3) & 0x3
+		b, err := d.read(offset, size+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += size + 1
+		var pointer uint
+		switch size {
+		case 0:
+			pointer = uint(ctrl&0x7)<<8 | uint(b[0])
+		case 1:
+			pointer = (uint(ctrl&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+		case 2:
+			pointer = (uint(ctrl&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+		default:
+			pointer = uint(binary.BigEndian.Uint32(b))
+		}
+		// Pointers point to values, never to other pointers
+		if b, err := d.read(pointer, 1); err != nil || b[0]>>5 == typePointer {
+			return nil, 0, fmt.Errorf("invalid pointer %d", pointer)
+		}
+		v, _, err := d.decode(pointer, depth)
+		return v, offset, err
+	}
+
+	if typ == typeExtended {
+		b, err := d.read(offset, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		typ = 7 + int(b[0])
+		offset++
+	}
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		b, err := d.read(offset, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + uint(b[0])
+		case 2:
+			size = 285 + (uint(b[0])<<8 | uint(b[1]))
+		default:
+			size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+		}
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("map key of type %T", key)
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = value
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, 0, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+			offset = next
+		}
+		return a, offset, nil
+	case typeBool:
+		if size > 1 {
+			return nil, 0, fmt.Errorf("invalid boolean of size %d", size)
+		}
+		return size == 1, offset, nil
+	}
+
+	b, err = d.read(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return append([]byte(nil), b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double of size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float of size %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > map[int]uint{typeUint16: 2, typeUint32: 4, typeUint64: 8}[typ] {
+			return nil, 0, fmt.Errorf("invalid unsigned integer of size %d", size)
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid int32 of size %d", size)
+		}
+		var v uint32
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		// Shorter values are padded with zeros, so they are positive
+		return int32(v), offset, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("invalid uint128 of size %d", size)
+		}
+		return new(big.Int).SetBytes(b), offset, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported type %d", typ)
+}
+
+// toUint returns the unsigned integer v decoded, 0 if it is not one.
+func toUint(v interface{}) uint64 {
+	switch v := v.(type) {
+	case uint64:
+		return v
+	case int32:
+		if v >= 0 {
+			return uint64(v)
+		}
+	}
+	return 0
+}
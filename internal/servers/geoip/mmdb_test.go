@@ -0,0 +1,349 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pointer is a pointer to a value of the data section, for encode.
+type pointer uint
+
+// writeCtrl writes the control byte of a value of type typ and size.
+func writeCtrl(b *bytes.Buffer, typ int, size int) {
+	ctrl := byte(0)
+	if typ <= typeMap {
+		ctrl = byte(typ) << 5
+	}
+	var extra []byte
+	switch {
+	case size < 29:
+		ctrl |= byte(size)
+	case size < 285:
+		ctrl |= 29
+		extra = []byte{byte(size - 29)}
+	case size < 65821:
+		ctrl |= 30
+		extra = binary.BigEndian.AppendUint16(nil, uint16(size-285))
+	default:
+		ctrl |= 31
+		extra = binary.BigEndian.AppendUint32(nil, uint32(size-65821))[1:]
+	}
+	b.WriteByte(ctrl)
+	if typ > typeMap {
+		b.WriteByte(byte(typ - 7))
+	}
+	b.Write(extra)
+}
+
+// encode writes v to a data section.
+func encode(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case pointer:
+		if v < 2048 {
+			b.Write([]byte{0x20 | byte(v>>8), byte(v)})
+		} else {
+			v -= 2048
+			b.Write([]byte{0x28 | byte(v>>16), byte(v >> 8), byte(v)})
+		}
+	case string:
+		writeCtrl(b, typeString, len(v))
+		b.WriteString(v)
+	case float64:
+		writeCtrl(b, typeDouble, 8)
+		b.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case int:
+		data := bytes.TrimLeft(binary.BigEndian.AppendUint32(nil, uint32(v)), "\x00")
+		writeCtrl(b, typeUint32, len(data))
+		b.Write(data)
+	case uint64:
+		data := bytes.TrimLeft(binary.BigEndian.AppendUint64(nil, v), "\x00")
+		writeCtrl(b, typeUint64, len(data))
+		b.Write(data)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		writeCtrl(b, typeBool, size)
+	case []interface{}:
+		writeCtrl(b, typeArray, len(v))
+		for _, item := range v {
+			encode(b, item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeCtrl(b, typeMap, len(keys))
+		for _, key := range keys {
+			encode(b, key)
+			encode(b, v[key])
+		}
+	default:
+		panic(fmt.Sprintf("cannot encode %T", v))
+	}
+}
+
+// trieNode is a node of the search tree built by buildDB.
+type trieNode struct {
+	children [2]*trieNode
+	leaf     bool
+	data     int
+}
+
+// buildDB returns a MaxMind DB with the records of networks, after the values of
+// shared, which records may point to.
+func buildDB(t testing.TB, databaseType string, recordSize, ipVersion int, shared []interface{}, networks map[string]interface{}) []byte {
+	t.Helper()
+	var data bytes.Buffer
+	for _, v := range shared {
+		encode(&data, v)
+	}
+	root := &trieNode{}
+	for network, record := range networks {
+		prefix := netip.MustParsePrefix(network)
+		ip, bits := prefix.Addr().AsSlice(), prefix.Bits()
+		if ipVersion == 6 && prefix.Addr().Is4() {
+			ip, bits = append(make([]byte, 12), ip...), bits+96
+		}
+		offset := data.Len()
+		encode(&data, record)
+		node := root
+		for i := 0; i < bits; i++ {
+			bit := ip[i/8] >> (7 - i%8) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &trieNode{}
+			}
+			node = node.children[bit]
+		}
+		node.leaf, node.data = true, offset
+	}
+
+	// Number the nodes breadth first
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	for queue := []*trieNode{root}; len(queue) > 0; queue = queue[1:] {
+		n := queue[0]
+		index[n] = len(nodes)
+		nodes = append(nodes, n)
+		for _, c := range n.children {
+			if c != nil && !c.leaf {
+				queue = append(queue, c)
+			}
+		}
+	}
+	count := len(nodes)
+	var tree bytes.Buffer
+	for _, n := range nodes {
+		var records [2]uint32
+		for i, c := range n.children {
+			switch {
+			case c == nil:
+				records[i] = uint32(count)
+			case c.leaf:
+				records[i] = uint32(count + dataSectionSeparator + c.data)
+			default:
+				records[i] = uint32(index[c])
+			}
+		}
+		switch recordSize {
+		case 24:
+			tree.Write(binary.BigEndian.AppendUint32(nil, records[0])[1:])
+			tree.Write(binary.BigEndian.AppendUint32(nil, records[1])[1:])
+		case 28:
+			l, r := records[0], records[1]
+			tree.Write([]byte{byte(l >> 16), byte(l >> 8), byte(l), byte(l>>24)<<4 | byte(r>>24)&0x0F, byte(r >> 16), byte(r >> 8), byte(r)})
+		case 32:
+			tree.Write(binary.BigEndian.AppendUint32(nil, records[0]))
+			tree.Write(binary.BigEndian.AppendUint32(nil, records[1]))
+		}
+	}
+
+	var db bytes.Buffer
+	db.Write(tree.Bytes())
+	db.Write(make([]byte, dataSectionSeparator))
+	db.Write(data.Bytes())
+	db.Write(metadataMarker)
+	encode(&db, map[string]interface{}{
+		"binary_format_major_version": 2,
+		"binary_format_minor_version": 0,
+		"build_epoch":                 uint64(1767225600),
+		"database_type":               databaseType,
+		"description":                 map[string]interface{}{"en": "Test " + databaseType + " database"},
+		"ip_version":                  ipVersion,
+		"languages":                   []interface{}{"de", "en"},
+		"node_count":                  count,
+		"record_size":                 recordSize,
+	})
+	return db.Bytes()
+}
+
+// Test looking up addresses with the record sizes and IP versions of databases
+func TestReader(t *testing.T) {
+	// Records share their country through a pointer, as in MaxMind's databases
+	shared := []interface{}{map[string]interface{}{"iso_code": "GB", "names": map[string]interface{}{"en": "United Kingdom"}}}
+	networks := map[string]interface{}{
+		"81.2.69.0/24":  map[string]interface{}{"country": pointer(0), "city": map[string]interface{}{"names": map[string]interface{}{"en": "London"}}},
+		"81.2.70.0/23":  map[string]interface{}{"country": pointer(0)},
+		"2001:218::/32": map[string]interface{}{"country": map[string]interface{}{"iso_code": "JP"}},
+	}
+	for _, recordSize := range []int{24, 28, 32} {
+		r, err := NewReader(buildDB(t, "GeoIP2-City", recordSize, 6, shared, networks))
+		require.NoError(t, err, recordSize)
+		assert.Equal(t, Metadata{
+			DatabaseType: "GeoIP2-City",
+			Description:  "Test GeoIP2-City database",
+			Languages:    []string{"de", "en"},
+			IPVersion:    6,
+			RecordSize:   recordSize,
+			NodeCount:    r.Metadata.NodeCount,
+			BuildTime:    r.Metadata.BuildTime,
+		}, r.Metadata)
+		assert.Equal(t, "2026-01-01", r.Metadata.BuildTime.Format("2006-01-02"))
+
+		record, network, ok, err := r.Lookup(netip.MustParseAddr("81.2.69.160"))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "81.2.69.0/24", network.String())
+		assert.Equal(t, "London", get(record, "city", "names", "en"))
+		assert.Equal(t, "GB", get(record, "country", "iso_code"))
+
+		// IPv4-mapped IPv6 addresses are IPv4 addresses
+		_, network, ok, err = r.Lookup(netip.MustParseAddr("::ffff:81.2.71.1"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "81.2.70.0/23", network.String())
+
+		record, network, ok, err = r.Lookup(netip.MustParseAddr("2001:218:1::1"))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "2001:218::/32", network.String())
+		assert.Equal(t, "JP", get(record, "country", "iso_code"))
+
+		_, _, ok, err = r.Lookup(netip.MustParseAddr("8.8.8.8"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	r, err := NewReader(buildDB(t, "GeoLite2-ASN", 24, 4, nil, map[string]interface{}{
+		"8.8.8.0/24": map[string]interface{}{"autonomous_system_number": 15169, "autonomous_system_organization": "GOOGLE"},
+	}))
+	require.NoError(t, err)
+	record, network, ok, err := r.Lookup(netip.MustParseAddr("8.8.8.8"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "8.8.8.0/24", network.String())
+	assert.Equal(t, uint64(15169), get(record, "autonomous_system_number"))
+	_, _, ok, err = r.Lookup(netip.MustParseAddr("2001:4860::1"))
+	require.NoError(t, err)
+	assert.False(t, ok, "IPv4 databases have no IPv6 records")
+}
+
+// Test decoding the types of the data section
+func TestDecode(t *testing.T) {
+	decode := func(data []byte) (interface{}, error) {
+		v, _, err := (&decoder{data: data}).decode(0, 0)
+		return v, err
+	}
+	roundTrip := func(v interface{}) interface{} {
+		var b bytes.Buffer
+		encode(&b, v)
+		got, err := decode(b.Bytes())
+		require.NoError(t, err)
+		return got
+	}
+
+	for _, n := range []int{0, 28, 29, 284, 285, 65820, 65821, 70000} {
+		s := strings.Repeat("x", n)
+		assert.Equal(t, s, roundTrip(s), n)
+	}
+	assert.Equal(t, 51.5142, roundTrip(51.5142))
+	assert.Equal(t, uint64(1<<40), roundTrip(uint64(1<<40)))
+	assert.Equal(t, true, roundTrip(true))
+	assert.Equal(t, []interface{}{"a", uint64(1)}, roundTrip([]interface{}{"a", 1}))
+
+	v, err := decode([]byte{0x04, 0x01, 0xFF, 0xFF, 0xFF, 0xFF}) // int32
+	require.NoError(t, err)
+	assert.Equal(t, int32(-1), v)
+	v, err = decode([]byte{0x04, 0x08, 0x40, 0x49, 0x0F, 0xDB}) // float
+	require.NoError(t, err)
+	assert.InDelta(t, math.Pi, v, 1e-6)
+	v, err = decode(append([]byte{0x10, 0x03}, bytes.Repeat([]byte{0xFF}, 16)...)) // uint128
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)), v)
+	v, err = decode([]byte{0x82, 'h', 'i'}) // bytes
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), v)
+
+	tests := map[string][]byte{
+		"value runs past the end of the data": {0x45, 'a'},
+		"invalid pointer 2":                   {0x20, 0x02, 0x20, 0x00},
+		"map key of type uint64":              {0xE1, 0xC1, 0x01, 0x41, 'a'},
+		"invalid double of size 4":            {0x64, 0, 0, 0, 0},
+		"unsupported type 12":                 {0x00, 0x05},
+		"values nested too deeply":            bytes.Repeat([]byte{0x01, 0x04}, maxDepth+2),
+	}
+	for want, data := range tests {
+		_, err := decode(data)
+		assert.ErrorContains(t, err, want)
+	}
+
+	// Maps whose values point twice to the map below have 2^20 values
+	var b bytes.Buffer
+	encode(&b, "leaf")
+	below := 0
+	for i := 0; i < 20; i++ {
+		offset := b.Len()
+		encode(&b, map[string]interface{}{"a": pointer(below), "b": pointer(below)})
+		below = offset
+	}
+	_, _, err = (&decoder{data: b.Bytes()}).decode(uint(below), 0)
+	assert.EqualError(t, err, "too many values")
+}
+
+// Test reading files that are not valid databases
+func TestNewReaderErrors(t *testing.T) {
+	_, err := NewReader([]byte("not a database"))
+	assert.EqualError(t, err, "not a MaxMind DB: metadata not found")
+
+	db := buildDB(t, "Test", 24, 6, nil, map[string]interface{}{"1.0.0.0/8": "x"})
+	var meta bytes.Buffer
+	encode(&meta, map[string]interface{}{"ip_version": 6, "node_count": 1, "record_size": 20})
+	_, err = NewReader(append(db[:bytes.LastIndex(db, metadataMarker)+len(metadataMarker)], meta.Bytes()...))
+	assert.EqualError(t, err, "unsupported record size 20")
+
+	meta.Reset()
+	encode(&meta, map[string]interface{}{"ip_version": 6, "node_count": 1 << 20, "record_size": 24})
+	_, err = NewReader(append(db[:bytes.LastIndex(db, metadataMarker)+len(metadataMarker)], meta.Bytes()...))
+	assert.EqualError(t, err, "search tree is larger than the file")
+
+	_, err = OpenReader("missing.mmdb")
+	assert.ErrorContains(t, err, "error reading database missing.mmdb")
+}
+
+// Fuzz reading databases and looking up addresses in them
+func FuzzReader(f *testing.F) {
+	f.Add(buildDB(f, "Test", 24, 6, nil, map[string]interface{}{"81.2.69.0/24": map[string]interface{}{"a": "b"}, "2001:db8::/32": 1}), []byte{81, 2, 69, 1})
+	f.Add(buildDB(f, "Test", 28, 4, nil, map[string]interface{}{"10.0.0.0/8": []interface{}{true, 1.5}}), []byte{10, 1, 2, 3})
+	f.Fuzz(func(t *testing.T, data []byte, ip []byte) {
+		r, err := NewReader(data)
+		if err != nil {
+			return
+		}
+		if addr, ok := netip.AddrFromSlice(ip); ok {
+			r.Lookup(addr)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package geoip
+
+import "net/netip"
+
+// reservedNetworks are the special-purpose networks of the IANA registries, which
+// have no location. More specific networks come first.
+var reservedNetworks = []struct {
+	prefix netip.Prefix
+	name   string
+}{
+	{netip.MustParsePrefix("0.0.0.0/8"), "this network (RFC 791)"},
+	{netip.MustParsePrefix("10.0.0.0/8"), "private network (RFC 1918)"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "shared address space for carrier-grade NAT (RFC 6598)"},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback (RFC 1122)"},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link-local (RFC 3927)"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "private network (RFC 1918)"},
+	{netip.MustParsePrefix("192.0.0.0/24"), "IETF protocol assignments (RFC 6890)"},
+	{netip.MustParsePrefix("192.0.2.0/24"), "documentation, TEST-NET-1 (RFC 5737)"},
+	{netip.MustParsePrefix("192.168.0.0/16"), "private network (RFC 1918)"},
+	{netip.MustParsePrefix("198.18.0.0/15"), "benchmarking (RFC 2544)"},
+	{netip.MustParsePrefix("198.51.100.0/24"), "documentation, TEST-NET-2 (RFC 5737)"},
+	{netip.MustParsePrefix("203.0.113.0/24"), "documentation, TEST-NET-3 (RFC 5737)"},
+	{netip.MustParsePrefix("224.0.0.0/4"), "multicast (RFC 5771)"},
+	{netip.MustParsePrefix("255.255.255.255/32"), "limited broadcast (RFC 919)"},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved for future use (RFC 1112)"},
+	{netip.MustParsePrefix("::/128"), "unspecified address (RFC 4291)"},
+	{netip.MustParsePrefix("::1/128"), "loopback (RFC 4291)"},
+	{netip.MustParsePrefix("100::/64"), "discard-only (RFC 6666)"},
+	{netip.MustParsePrefix("2001:db8::/32"), "documentation (RFC 3849)"},
+	{netip.MustParsePrefix("fc00::/7"), "unique local address (RFC 4193)"},
+	{netip.MustParsePrefix("fe80::/10"), "link-local (RFC 4291)"},
+	{netip.MustParsePrefix("ff00::/8"), "multicast (RFC 4291)"},
+}
+
+// reserved returns the special-purpose network addr belongs to and its name, if any.
+func reserved(addr netip.Addr) (netip.Prefix, string, bool) {
+	addr = addr.Unmap().WithZone("")
+	for _, n := range reservedNetworks {
+		if n.prefix.Contains(addr) {
+			return n.prefix, n.name, true
+		}
+	}
+	return netip.Prefix{}, "", false
+}
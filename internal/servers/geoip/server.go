@@ -0,0 +1,627 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/netip"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/reload"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// Database is a GeoIP or ASN database addresses are looked up in.
+type Database struct {
+	// Name is the file of the database, or embedded:<file> for built-in ones.
+	Name string
+	*Reader
+}
+
+// Location is what the databases know of an address.
+type Location struct {
+	IP string `json:"ip"`
+	// Network is the network of the address in the databases, or its special-purpose
+	// network.
+	Network string `json:"network,omitempty"`
+	// Reserved names the special-purpose network of addresses without a location,
+	// e.g. private networks.
+	Reserved       string   `json:"reserved,omitempty"`
+	Found          bool     `json:"found"`
+	Continent      string   `json:"continent,omitempty"`
+	ContinentCode  string   `json:"continentCode,omitempty"`
+	Country        string   `json:"country,omitempty"`
+	CountryCode    string   `json:"countryCode,omitempty"`
+	Region         string   `json:"region,omitempty"`
+	RegionCode     string   `json:"regionCode,omitempty"`
+	City           string   `json:"city,omitempty"`
+	PostalCode     string   `json:"postalCode,omitempty"`
+	Latitude       *float64 `json:"latitude,omitempty"`
+	Longitude      *float64 `json:"longitude,omitempty"`
+	AccuracyRadius uint64   `json:"accuracyRadiusKm,omitempty"`
+	TimeZone       string   `json:"timeZone,omitempty"`
+	ASN            uint64   `json:"asn,omitempty"`
+	Organization   string   `json:"organization,omitempty"`
+	// Error is why a lookup of a batch failed.
+	Error string `json:"error,omitempty"`
+}
+
+// GeoIPServer is an MCP server looking up the location and network owner of IP
+// addresses in local databases, without any network access.
+type GeoIPServer struct {
+	server    *server.MCPServer
+	language  string
+	maxBatch  int
+	mu        sync.RWMutex
+	databases []*Database
+}
+
+// NewGeoIPServer creates a new GeoIPServer instance looking addresses up in databases,
+// in order, naming places in language when the databases have their names in it.
+// Batches are limited to maxBatch addresses.
+func NewGeoIPServer(databases []*Database, language string, maxBatch int) *GeoIPServer {
+	log.Printf("GeoIPServer created: databases=%d, language=%s, maxBatch=%d", len(databases), language, maxBatch)
+
+	s := &GeoIPServer{
+		language:  language,
+		maxBatch:  maxBatch,
+		databases: databases,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"geoip-server", // server name
+		"1.0.0",        // version
+	)
+
+	languageParam := mcp.WithString("language",
+		mcp.Description(fmt.Sprintf("Language of the place names, e.g. de, ja or zh-CN, when the databases have them (default: %s)", language)),
+	)
+
+	// Register lookupIP tool
+	lookupTool := mcp.NewTool("lookupIP",
+		mcp.WithDescription("Looks up the country, region, city, coordinates, time zone, autonomous system (ASN) and organization of an IPv4 or IPv6 address in local GeoIP databases. Private, loopback and other special-purpose addresses are named instead"),
+		mcp.WithString("ip",
+			mcp.Description("IPv4 or IPv6 address, e.g. 8.8.8.8 or 2001:4860:4860::8888"),
+			mcp.Required(),
+		),
+		languageParam,
+		format.Param(format.Text, format.Markdown, format.JSON),
+	)
+
+	// Register lookupIPs tool
+	batchTool := mcp.NewTool("lookupIPs",
+		mcp.WithDescription(fmt.Sprintf("Looks up several IP addresses at once, e.g. those of a log, returning a table of their locations and networks. Up to %d addresses; invalid ones are reported in their row", maxBatch)),
+		mcp.WithArray("ips",
+			mcp.Description("IPv4 or IPv6 addresses"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+			mcp.Required(),
+		),
+		languageParam,
+		format.Param(format.Text, format.Markdown, format.JSON),
+	)
+
+	// Register getDatabaseInfo tool
+	infoTool := mcp.NewTool("getDatabaseInfo",
+		mcp.WithDescription("Lists the GeoIP databases of the server with their type, build date and languages, to judge how current the lookups are"),
+	)
+
+	middleware.AddTool(mcpServer, lookupTool, s.handleLookupIP)
+	middleware.AddTool(mcpServer, batchTool, s.handleLookupIPs)
+	middleware.AddTool(mcpServer, infoTool, s.handleGetDatabaseInfo)
+
+	s.server = mcpServer
+	return s
+}
+
+// SetDatabases replaces the databases addresses are looked up in, e.g. with their
+// updates.
+func (s *GeoIPServer) SetDatabases(databases []*Database) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.databases = databases
+}
+
+// parseIP parses an address as clients write them, possibly in brackets or with a
+// zone.
+func parseIP(ip string) (netip.Addr, error) {
+	ip = strings.TrimSpace(ip)
+	ip = strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]")
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Addr{}, toolerr.Errorf(toolerr.InvalidParams, "invalid IP address %q", ip)
+	}
+	return addr.Unmap().WithZone(""), nil
+}
+
+// lookup returns the location of addr with names in language.
+func (s *GeoIPServer) lookup(addr netip.Addr, language string) (Location, error) {
+	l := Location{IP: addr.String()}
+	if network, name, ok := reserved(addr); ok {
+		l.Network = network.String()
+		l.Reserved = name
+		return l, nil
+	}
+
+	s.mu.RLock()
+	databases := s.databases
+	s.mu.RUnlock()
+	if len(databases) == 0 {
+		return l, toolerr.Errorf(toolerr.Unavailable, "no GeoIP database is configured; start the server with -db")
+	}
+	for _, db := range databases {
+		record, network, ok, err := db.Lookup(addr)
+		if err != nil {
+			return l, fmt.Errorf("error looking up %s in %s: %w", addr, db.Name, err)
+		}
+		m, isMap := record.(map[string]interface{})
+		if !ok || !isMap {
+			continue
+		}
+		if !l.Found {
+			l.Network = network.String()
+		}
+		l.Found = true
+		l.fill(m, language)
+	}
+	return l, nil
+}
+
+// get returns the value at keys of v, a decoded map, or nil.
+func get(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// getString returns the string at keys of v, or "".
+func getString(v interface{}, keys ...string) string {
+	s, _ := get(v, keys...).(string)
+	return s
+}
+
+// localized returns the name of names in language, in English or in the first
+// language there is a name in.
+func localized(names interface{}, language string) string {
+	m, ok := names.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return ""
+	}
+	for _, l := range []string{language, "en"} {
+		if name, ok := m[l].(string); ok {
+			return name
+		}
+	}
+	languages := make([]string, 0, len(m))
+	for l := range m {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+	name, _ := m[languages[0]].(string)
+	return name
+}
+
+// set sets *field to value unless an earlier database set it.
+func set(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// fill sets the fields of l found in record, a record of a City, Country or ASN
+// database in the layout of MaxMind's, which earlier databases did not set.
+func (l *Location) fill(record map[string]interface{}, language string) {
+	country := get(record, "country")
+	if country == nil {
+		country = get(record, "registered_country")
+	}
+	set(&l.Continent, localized(get(record, "continent", "names"), language))
+	set(&l.ContinentCode, getString(record, "continent", "code"))
+	set(&l.Country, localized(get(country, "names"), language))
+	set(&l.CountryCode, getString(country, "iso_code"))
+	if subdivisions, ok := get(record, "subdivisions").([]interface{}); ok && len(subdivisions) > 0 {
+		set(&l.Region, localized(get(subdivisions[0], "names"), language))
+		set(&l.RegionCode, getString(subdivisions[0], "iso_code"))
+	}
+	set(&l.City, localized(get(record, "city", "names"), language))
+	set(&l.PostalCode, getString(record, "postal", "code"))
+	set(&l.TimeZone, getString(record, "location", "time_zone"))
+	if l.Latitude == nil {
+		lat, latOK := get(record, "location", "latitude").(float64)
+		lon, lonOK := get(record, "location", "longitude").(float64)
+		if latOK && lonOK {
+			l.Latitude, l.Longitude = &lat, &lon
+			l.AccuracyRadius = toUint(get(record, "location", "accuracy_radius"))
+		}
+	}
+
+	// ASN databases have the fields at the top, others with ISP data in traits
+	if l.ASN == 0 {
+		if l.ASN = toUint(record["autonomous_system_number"]); l.ASN == 0 {
+			l.ASN = toUint(get(record, "traits", "autonomous_system_number"))
+		}
+	}
+	set(&l.Organization, getString(record, "autonomous_system_organization"))
+	set(&l.Organization, getString(record, "traits", "autonomous_system_organization"))
+	set(&l.Organization, getString(record, "traits", "organization"))
+}
+
+// place returns the city, region, country and continent of l, as far as known.
+func (l Location) place() string {
+	var parts []string
+	for _, part := range []string{l.City, l.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if l.Country != "" {
+		country := l.Country
+		if l.CountryCode != "" {
+			country += " (" + l.CountryCode + ")"
+		}
+		parts = append(parts, country)
+	}
+	if l.Continent != "" {
+		parts = append(parts, l.Continent)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// coordinates returns the coordinates of l, or "".
+func (l Location) coordinates() string {
+	if l.Latitude == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.4f, %.4f", *l.Latitude, *l.Longitude)
+}
+
+// as returns the autonomous system of l with its organization, or "".
+func (l Location) as() string {
+	if l.ASN == 0 {
+		return l.Organization
+	}
+	return strings.TrimSpace(fmt.Sprintf("AS%d %s", l.ASN, l.Organization))
+}
+
+// note returns why l has no location, or "".
+func (l Location) note() string {
+	switch {
+	case l.Error != "":
+		return l.Error
+	case l.Reserved != "":
+		return "reserved: " + l.Reserved
+	case !l.Found:
+		return "not found"
+	}
+	return ""
+}
+
+// text renders l for people.
+func (l Location) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IP: %s\n", l.IP)
+	if l.Network != "" {
+		fmt.Fprintf(&b, "Network: %s\n", l.Network)
+	}
+	if l.Reserved != "" {
+		fmt.Fprintf(&b, "Reserved: %s; such addresses have no location\n", l.Reserved)
+		return strings.TrimSpace(b.String())
+	}
+	if !l.Found {
+		b.WriteString("The address is not in the GeoIP databases\n")
+		return strings.TrimSpace(b.String())
+	}
+	if place := l.place(); place != "" {
+		fmt.Fprintf(&b, "Location: %s\n", place)
+	}
+	if c := l.coordinates(); c != "" {
+		if l.AccuracyRadius > 0 {
+			c += fmt.Sprintf(" (accuracy radius %d km)", l.AccuracyRadius)
+		}
+		fmt.Fprintf(&b, "Coordinates: %s\n", c)
+	}
+	if l.TimeZone != "" {
+		fmt.Fprintf(&b, "Time zone: %s\n", l.TimeZone)
+	}
+	if l.PostalCode != "" {
+		fmt.Fprintf(&b, "Postal code: %s\n", l.PostalCode)
+	}
+	if as := l.as(); as != "" {
+		fmt.Fprintf(&b, "Autonomous system: %s\n", as)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// table returns locations as a table.
+func table(locations []Location) format.Table {
+	t := format.Table{Columns: []string{"IP", "Country", "Region", "City", "Coordinates", "ASN", "Organization", "Note"}}
+	for _, l := range locations {
+		asn := ""
+		if l.ASN > 0 {
+			asn = "AS" + strconv.FormatUint(l.ASN, 10)
+		}
+		t.Rows = append(t.Rows, []string{l.IP, l.CountryCode, l.Region, l.City, l.coordinates(), asn, l.Organization, l.note()})
+	}
+	return t
+}
+
+// handleLookupIP handles the lookup of an address.
+func (s *GeoIPServer) handleLookupIP(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting lookupIP request processing")
+
+	var args struct {
+		IP       string `json:"ip" param:"required"`
+		Language string `json:"language,omitempty"`
+		Format   string `json:"format,omitempty" param:"enum=text|markdown|json"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if args.Language == "" {
+		args.Language = s.language
+	}
+
+	addr, err := parseIP(args.IP)
+	if err != nil {
+		return nil, err
+	}
+	location, err := s.lookup(addr, args.Language)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data:     location,
+		Text:     location.text(),
+		Markdown: table([]Location{location}).Markdown(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("lookupIP request completed")
+	return result, nil
+}
+
+// handleLookupIPs handles the lookup of a batch of addresses.
+func (s *GeoIPServer) handleLookupIPs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting lookupIPs request processing")
+
+	var args struct {
+		IPs      []string `json:"ips" param:"required,min=1"`
+		Language string   `json:"language,omitempty"`
+		Format   string   `json:"format,omitempty" param:"enum=text|markdown|json"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if len(args.IPs) > s.maxBatch {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "at most %d addresses can be looked up at once, got %d", s.maxBatch, len(args.IPs))
+	}
+	if args.Language == "" {
+		args.Language = s.language
+	}
+
+	locations := make([]Location, 0, len(args.IPs))
+	for _, ip := range args.IPs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		addr, err := parseIP(ip)
+		if err != nil {
+			locations = append(locations, Location{IP: ip, Error: err.Error()})
+			continue
+		}
+		location, err := s.lookup(addr, args.Language)
+		if err != nil {
+			// Without databases, no lookup can succeed
+			if toolerr.Classify(err).Code == toolerr.Unavailable {
+				return nil, err
+			}
+			location.Error = err.Error()
+		}
+		locations = append(locations, location)
+	}
+
+	t := table(locations)
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data:     locations,
+		Text:     t.Text(),
+		Markdown: t.Markdown(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("lookupIPs request completed: %d addresses", len(locations))
+	return result, nil
+}
+
+// handleGetDatabaseInfo handles the request for the databases.
+func (s *GeoIPServer) handleGetDatabaseInfo(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.mu.RLock()
+	databases := s.databases
+	s.mu.RUnlock()
+
+	if len(databases) == 0 {
+		return mcp.NewToolResultText("No GeoIP database is configured; only special-purpose addresses, such as private ones, are recognized. Start the server with -db to look up other addresses."), nil
+	}
+	var b strings.Builder
+	for _, db := range databases {
+		fmt.Fprintf(&b, "%s\n  Type: %s\n", db.Name, db.Metadata.DatabaseType)
+		if db.Metadata.Description != "" {
+			fmt.Fprintf(&b, "  Description: %s\n", db.Metadata.Description)
+		}
+		fmt.Fprintf(&b, "  Built: %s\n  IP version: %d\n", db.Metadata.BuildTime.Format("2006-01-02"), db.Metadata.IPVersion)
+		if len(db.Metadata.Languages) > 0 {
+			fmt.Fprintf(&b, "  Languages: %s\n", strings.Join(db.Metadata.Languages, ", "))
+		}
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(b.String())), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *GeoIPServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// openDatabases opens the databases of files.
+func openDatabases(files []string) ([]*Database, error) {
+	var databases []*Database
+	for _, file := range files {
+		r, err := OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		databases = append(databases, &Database{Name: file, Reader: r})
+	}
+	return databases, nil
+}
+
+// embeddedDatabases opens the databases built into the binary with the geoip_embed
+// build tag, in the order of their names.
+func embeddedDatabases() ([]*Database, error) {
+	entries, err := fs.ReadDir(embedded, "data")
+	if err != nil {
+		// Built without databases
+		return nil, nil
+	}
+	var databases []*Database
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".mmdb" {
+			continue
+		}
+		data, err := fs.ReadFile(embedded, "data/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		r, err := NewReader(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading embedded database %s: %w", entry.Name(), err)
+		}
+		databases = append(databases, &Database{Name: "embedded:" + entry.Name(), Reader: r})
+	}
+	return databases, nil
+}
+
+// New creates the geoip server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("geoip", flag.ContinueOnError)
+	var (
+		dbFiles  string
+		language string
+		maxBatch int
+	)
+	fs.StringVar(&dbFiles, "db", "", "Comma separated MaxMind DB files to look addresses up in, e.g. GeoLite2-City.mmdb,GeoLite2-ASN.mmdb; earlier files take precedence (default: the databases built in, if any)")
+	fs.StringVar(&language, "language", "en", "Default language of the place names")
+	fs.IntVar(&maxBatch, "max-batch", 100, "Maximum number of addresses of a lookupIPs call")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(dbFiles, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	var databases []*Database
+	var err error
+	if len(files) > 0 {
+		databases, err = openDatabases(files)
+	} else {
+		databases, err = embeddedDatabases()
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting GeoIP server: databases=%d, language=%s", len(databases), language)
+	if len(databases) == 0 {
+		log.Printf("Warning: No GeoIP database configured. Use -db to look up public addresses.")
+	}
+
+	// Create GeoIPServer instance
+	geoServer := NewGeoIPServer(databases, language, maxBatch)
+	log.Println("GeoIPServer instance created successfully, starting server...")
+
+	// Updated databases, e.g. by geoipupdate, are used without a restart
+	if len(files) > 0 {
+		reload.Watch(ctx, files, func() {
+			databases, err := openDatabases(files)
+			if err != nil {
+				log.Printf("Error: Keeping the previous databases: %v", err)
+				return
+			}
+			geoServer.SetDatabases(databases)
+			log.Printf("Reloaded %d databases", len(databases))
+		})
+	}
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), geoServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return geoServer.Server(), transportFlags, nil
+}
+
+// Run starts the geoip server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[GeoIPServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create geoip server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("GeoIPServer shutdown")
+	return nil
+}
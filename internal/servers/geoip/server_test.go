@@ -0,0 +1,156 @@
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// writeTestDBs writes a City and an ASN database, returning their files.
+func writeTestDBs(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	city := filepath.Join(dir, "GeoLite2-City.mmdb")
+	require.NoError(t, os.WriteFile(city, buildDB(t, "GeoLite2-City", 28, 6, nil, map[string]interface{}{
+		"81.2.69.0/24": map[string]interface{}{
+			"continent":    map[string]interface{}{"code": "EU", "names": map[string]interface{}{"en": "Europe", "de": "Europa"}},
+			"country":      map[string]interface{}{"iso_code": "GB", "names": map[string]interface{}{"en": "United Kingdom", "de": "Vereinigtes Königreich"}},
+			"subdivisions": []interface{}{map[string]interface{}{"iso_code": "ENG", "names": map[string]interface{}{"en": "England"}}},
+			"city":         map[string]interface{}{"names": map[string]interface{}{"en": "London"}},
+			"postal":       map[string]interface{}{"code": "EC1A"},
+			"location":     map[string]interface{}{"latitude": 51.5142, "longitude": -0.0931, "accuracy_radius": 10, "time_zone": "Europe/London"},
+		},
+		"2001:218::/32": map[string]interface{}{
+			"registered_country": map[string]interface{}{"iso_code": "JP", "names": map[string]interface{}{"ja": "日本"}},
+		},
+	}), 0o600))
+	asn := filepath.Join(dir, "GeoLite2-ASN.mmdb")
+	require.NoError(t, os.WriteFile(asn, buildDB(t, "GeoLite2-ASN", 24, 6, nil, map[string]interface{}{
+		"81.2.64.0/20": map[string]interface{}{"autonomous_system_number": 20712, "autonomous_system_organization": "Andrews & Arnold Ltd"},
+		"8.8.8.0/24":   map[string]interface{}{"autonomous_system_number": 15169, "autonomous_system_organization": "GOOGLE"},
+	}), 0o600))
+	return city, asn
+}
+
+// newTestServer returns a client of a server with the test databases.
+func newTestServer(t *testing.T) *mcptest.Client {
+	city, asn := writeTestDBs(t)
+	s, _, err := New(context.Background(), []string{"-db", city + "," + asn, "-max-batch", "3"})
+	require.NoError(t, err)
+	return mcptest.Connect(t, s)
+}
+
+// Test looking up an address in several databases
+func TestLookupIP(t *testing.T) {
+	c := newTestServer(t)
+
+	assert.Equal(t, `IP: 81.2.69.160
+Network: 81.2.69.0/24
+Location: London, England, United Kingdom (GB), Europe
+Coordinates: 51.5142, -0.0931 (accuracy radius 10 km)
+Time zone: Europe/London
+Postal code: EC1A
+Autonomous system: AS20712 Andrews & Arnold Ltd`, c.Text("lookupIP", map[string]interface{}{"ip": "81.2.69.160"}))
+
+	var l Location
+	require.NoError(t, json.Unmarshal([]byte(c.Text("lookupIP", map[string]interface{}{"ip": "::ffff:81.2.69.1", "language": "de", "format": "json"})), &l))
+	assert.Equal(t, "81.2.69.1", l.IP)
+	assert.Equal(t, "Vereinigtes Königreich", l.Country)
+	assert.Equal(t, "England", l.Region, "Names missing in the language are in English")
+	assert.Equal(t, 51.5142, *l.Latitude)
+	assert.Equal(t, uint64(20712), l.ASN)
+
+	// Only the ASN database has the address
+	assert.Equal(t, "IP: 8.8.8.8\nNetwork: 8.8.8.0/24\nAutonomous system: AS15169 GOOGLE", c.Text("lookupIP", map[string]interface{}{"ip": "8.8.8.8"}))
+	assert.Contains(t, c.Text("lookupIP", map[string]interface{}{"ip": "[2001:218::1]"}), "Location: 日本 (JP)")
+	assert.Equal(t, "IP: 1.1.1.1\nThe address is not in the GeoIP databases", c.Text("lookupIP", map[string]interface{}{"ip": "1.1.1.1"}))
+	assert.Equal(t, "IP: 192.168.1.20\nNetwork: 192.168.0.0/16\nReserved: private network (RFC 1918); such addresses have no location",
+		c.Text("lookupIP", map[string]interface{}{"ip": "192.168.1.20"}))
+	assert.Contains(t, c.Text("lookupIP", map[string]interface{}{"ip": "fe80::1%eth0"}), "Reserved: link-local (RFC 4291)")
+	assert.Equal(t, `invalid IP address "example.com"`, c.Error("lookupIP", map[string]interface{}{"ip": "example.com"}))
+
+	// Special-purpose addresses are named without databases
+	s := NewGeoIPServer(nil, "en", 10)
+	_, err := s.lookup(mustParseIP(t, "10.1.2.3"), "en")
+	assert.NoError(t, err)
+	_, err = s.lookup(mustParseIP(t, "8.8.8.8"), "en")
+	assert.EqualError(t, err, "no GeoIP database is configured; start the server with -db")
+	assert.Equal(t, toolerr.Unavailable, toolerr.Classify(err).Code)
+}
+
+// mustParseIP parses ip for a test.
+func mustParseIP(t *testing.T, ip string) netip.Addr {
+	addr, err := parseIP(ip)
+	require.NoError(t, err)
+	return addr
+}
+
+// Test looking up a batch of addresses
+func TestLookupIPs(t *testing.T) {
+	c := newTestServer(t)
+
+	assert.Equal(t, `| IP | Country | Region | City | Coordinates | ASN | Organization | Note |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+| 81.2.69.160 | GB | England | London | 51.5142, -0.0931 | AS20712 | Andrews & Arnold Ltd |  |
+| 10.0.0.1 |  |  |  |  |  |  | reserved: private network (RFC 1918) |
+| nope |  |  |  |  |  |  | invalid IP address "nope" |`,
+		c.Text("lookupIPs", map[string]interface{}{"ips": []interface{}{"81.2.69.160", "10.0.0.1", "nope"}, "format": "markdown"}))
+
+	var locations []Location
+	require.NoError(t, json.Unmarshal([]byte(c.Text("lookupIPs", map[string]interface{}{"ips": []interface{}{"8.8.8.8", "1.1.1.1"}, "format": "json"})), &locations))
+	require.Len(t, locations, 2)
+	assert.True(t, locations[0].Found)
+	assert.False(t, locations[1].Found)
+
+	assert.Equal(t, "at most 3 addresses can be looked up at once, got 4",
+		c.Error("lookupIPs", map[string]interface{}{"ips": []interface{}{"1.1.1.1", "1.1.1.2", "1.1.1.3", "1.1.1.4"}}))
+}
+
+// Test listing the databases and reading them again
+func TestDatabases(t *testing.T) {
+	c := newTestServer(t)
+	info := c.Text("getDatabaseInfo", nil)
+	assert.Contains(t, info, "GeoLite2-City.mmdb\n  Type: GeoLite2-City\n  Description: Test GeoLite2-City database\n  Built: 2026-01-01\n  IP version: 6\n  Languages: de, en")
+	assert.Contains(t, info, "Type: GeoLite2-ASN")
+
+	s := NewGeoIPServer(nil, "en", 10)
+	assert.Contains(t, mcptest.Connect(t, s.Server()).Text("getDatabaseInfo", nil), "No GeoIP database is configured")
+	city, _ := writeTestDBs(t)
+	databases, err := openDatabases([]string{city})
+	require.NoError(t, err)
+	s.SetDatabases(databases)
+	l, err := s.lookup(mustParseIP(t, "81.2.69.1"), "en")
+	require.NoError(t, err)
+	assert.Equal(t, "London", l.City)
+
+	_, _, err = New(context.Background(), []string{"-db", filepath.Join(t.TempDir(), "missing.mmdb")})
+	assert.ErrorContains(t, err, "error reading database")
+	invalid := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a database"), 0o600))
+	_, _, err = New(context.Background(), []string{"-db", invalid})
+	assert.ErrorContains(t, err, "not a MaxMind DB")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	databases := []*Database{}
+	for _, networks := range []map[string]interface{}{
+		{"81.2.69.0/24": map[string]interface{}{"country": map[string]interface{}{"iso_code": "GB"}}},
+		{"8.8.8.0/24": map[string]interface{}{"autonomous_system_number": 15169}},
+	} {
+		r, err := NewReader(buildDB(f, "Test", 24, 6, nil, networks))
+		if err != nil {
+			f.Fatal(err)
+		}
+		databases = append(databases, &Database{Name: "test", Reader: r})
+	}
+	mcptest.FuzzTools(f, NewGeoIPServer(databases, "en", 100).Server())
+}
@@ -0,0 +1,502 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/pagination"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// defaultAPIURL is the base URL of the Custom Search JSON API.
+const defaultAPIURL = "https://www.googleapis.com/customsearch/v1"
+
+// GoogleSearchResult represents a search result from the Google API
+type GoogleSearchResult struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Snippet     string `json:"snippet"`
+	DisplayLink string `json:"displayLink,omitempty"`
+}
+
+// GoogleApiResponse represents the response from Google Custom Search API
+type GoogleApiResponse struct {
+	Kind string `json:"kind"`
+	URL  *struct {
+		Type     string `json:"type"`
+		Template string `json:"template"`
+	} `json:"url,omitempty"`
+	Queries           map[string]interface{} `json:"queries"`
+	Context           interface{}            `json:"context,omitempty"`
+	SearchInformation *struct {
+		SearchTime            float64 `json:"searchTime"`
+		FormattedSearchTime   string  `json:"formattedSearchTime"`
+		TotalResults          string  `json:"totalResults"`
+		FormattedTotalResults string  `json:"formattedTotalResults"`
+	} `json:"searchInformation,omitempty"`
+	Items []struct {
+		Kind        string `json:"kind"`
+		Title       string `json:"title"`
+		HTMLTitle   string `json:"htmlTitle"`
+		Link        string `json:"link"`
+		DisplayLink string `json:"displayLink"`
+		Snippet     string `json:"snippet"`
+		HTMLSnippet string `json:"htmlSnippet"`
+		CacheID     string `json:"cacheId,omitempty"`
+		Mime        string `json:"mime,omitempty"`
+		FileFormat  string `json:"fileFormat,omitempty"`
+	} `json:"items,omitempty"`
+}
+
+// GoogleSearchServer is an MCP server that performs Google searches.
+type GoogleSearchServer struct {
+	server         *server.MCPServer
+	client         *http.Client
+	apiURL         string
+	userAgent      string
+	maxBodySize    int64
+	apiKey         string
+	searchEngineID string
+	recent         *resources.Recent
+}
+
+// recentSearches is the number of searches whose results are kept as resources.
+const recentSearches = 20
+
+// maxResults is the number of results of a query the API returns at most.
+const maxResults = 100
+
+// NewGoogleSearchServer creates a new GoogleSearchServer instance.
+func NewGoogleSearchServer(timeout int, userAgent string, maxBodySize int64, apiKey, searchEngineID string) *GoogleSearchServer {
+	log.Printf("GoogleSearchServer created: timeout=%ds, userAgent=%s, maxBodySize=%d", timeout, userAgent, maxBodySize)
+
+	s := &GoogleSearchServer{
+		client:         httpclient.New(clientOptions(timeout, userAgent, maxBodySize)),
+		apiURL:         defaultAPIURL,
+		userAgent:      userAgent,
+		maxBodySize:    maxBodySize,
+		apiKey:         apiKey,
+		searchEngineID: searchEngineID,
+	}
+	// The API key is sent in the query of the search URL, which errors and logs quote
+	redact.AddSecret(apiKey)
+
+	mcpServer := resources.NewMCPServer(
+		"google-search-server", // server name
+		"1.0.0",                // version
+	)
+	s.recent = resources.NewRecent(mcpServer, "googlesearch://results", "search results", recentSearches)
+
+	// Register searchGoogle tool
+	searchTool := mcp.NewTool("searchGoogle",
+		mcp.WithDescription("Performs a Google search and returns the results"),
+		mcp.WithString("query",
+			mcp.Description("The search query string"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("num",
+			mcp.Description("Number of search results to return (max 10)"),
+			mcp.DefaultNumber(5),
+		),
+		mcp.WithNumber("start",
+			mcp.Description("Index of the first result to return (starts at 1)"),
+			mcp.DefaultNumber(1),
+		),
+		mcp.WithString("language",
+			mcp.Description("Language for search results (e.g., 'en', 'ko', 'ja')"),
+			mcp.DefaultString("en"),
+		),
+		mcp.WithString("country",
+			mcp.Description("Country code for search context (e.g., 'us', 'kr', 'jp')"),
+			mcp.DefaultString("us"),
+		),
+		mcp.WithBoolean("safeSearch",
+			mcp.Description("Whether to filter out adult content"),
+			mcp.DefaultBool(true),
+		),
+		format.Param(format.Text, format.Markdown, format.JSON),
+		pagination.Param(),
+	)
+
+	// Register getApiStatus tool to check and validate API configuration
+	statusTool := mcp.NewTool("getApiStatus",
+		mcp.WithDescription("Checks if the Google API configuration is valid"),
+	)
+
+	middleware.AddTool(mcpServer, searchTool, s.handleGoogleSearch)
+	middleware.AddTool(mcpServer, statusTool, s.handleApiStatus)
+	middleware.Cacheable(mcpServer, "searchGoogle", cache.Policy{TTL: time.Hour})
+	health.Register(mcpServer, "google-api", s.checkAPI)
+
+	s.server = mcpServer
+	return s
+}
+
+// discoveryURL describes the Custom Search API; fetching it spends no search quota.
+const discoveryURL = "https://customsearch.googleapis.com/$discovery/rest?version=v1"
+
+// checkAPI is the readiness check of the server: the API must be configured and
+// reachable.
+func (s *GoogleSearchServer) checkAPI(ctx context.Context) error {
+	if s.apiKey == "" {
+		return errors.New("API key is not configured")
+	}
+	if s.searchEngineID == "" {
+		return errors.New("Search Engine ID is not configured")
+	}
+	return health.Reachable(s.client, discoveryURL)(ctx)
+}
+
+// handleApiStatus checks if the API configuration is valid
+func (s *GoogleSearchServer) handleApiStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Checking API configuration")
+
+	var statusMsg string
+	if s.apiKey == "" {
+		statusMsg = "Error: API key is not configured. Please set the API_KEY environment variable or use the -api-key flag."
+	} else if s.searchEngineID == "" {
+		statusMsg = "Error: Search Engine ID is not configured. Please set the SEARCH_ENGINE_ID environment variable or use the -search-engine-id flag."
+	} else {
+		statusMsg = "Google Search API is properly configured and ready to use."
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: statusMsg,
+			},
+		},
+	}
+
+	return result, nil
+}
+
+// handleGoogleSearch handles the Google search request.
+func (s *GoogleSearchServer) handleGoogleSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting Google search request processing")
+
+	// Validate API configuration
+	if s.apiKey == "" {
+		return nil, fmt.Errorf("API key is not configured")
+	}
+	if s.searchEngineID == "" {
+		return nil, fmt.Errorf("Search Engine ID is not configured")
+	}
+
+	var args struct {
+		Query      string  `json:"query" param:"required"`
+		Num        float64 `json:"num,omitempty"`
+		Start      float64 `json:"start,omitempty"`
+		Language   string  `json:"language,omitempty"`
+		Country    string  `json:"country,omitempty"`
+		SafeSearch bool    `json:"safeSearch,omitempty"`
+		Format     string  `json:"format,omitempty" param:"enum=text|markdown|json"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// Log request details
+	log.Printf("Google search request: Query=%s, Num=%v, Start=%v, Language=%s, Country=%s, SafeSearch=%v",
+		args.Query, args.Num, args.Start, args.Language, args.Country, args.SafeSearch)
+
+	// Set defaults if not provided
+	if args.Num <= 0 {
+		args.Num = 5
+	} else if args.Num > 10 {
+		args.Num = 10 // Google API limit is 10 results per page
+	}
+
+	if args.Start <= 0 {
+		args.Start = 1
+	}
+	// The cursor of the next page supersedes start
+	pager, err := pagination.Start(req, "start")
+	if err != nil {
+		return nil, err
+	}
+	if pager.Offset > 0 {
+		args.Start = float64(pager.Offset + 1)
+	}
+
+	// Construct Google Custom Search API URL
+	values := url.Values{}
+	values.Add("q", args.Query)
+	values.Add("key", s.apiKey)
+	values.Add("cx", s.searchEngineID)
+	values.Add("num", strconv.Itoa(int(args.Num)))
+	values.Add("start", strconv.Itoa(int(args.Start)))
+
+	if args.Language != "" {
+		values.Add("lr", "lang_"+args.Language)
+	}
+
+	if args.Country != "" {
+		values.Add("gl", args.Country)
+	}
+
+	if args.SafeSearch {
+		values.Add("safe", "active")
+	} else {
+		values.Add("safe", "off")
+	}
+
+	searchURL := s.apiURL + "?" + values.Encode()
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+
+	// Send the request
+	log.Printf("Sending request to Google Custom Search API")
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error: API returned status code %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error: %s (status code: %d)", string(body), resp.StatusCode)
+	}
+
+	// Parse API response
+	var apiResponse GoogleApiResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	// Extract search results
+	results := []GoogleSearchResult{}
+	if apiResponse.Items != nil {
+		for _, item := range apiResponse.Items {
+			results = append(results, GoogleSearchResult{
+				Title:       item.Title,
+				Link:        item.Link,
+				Snippet:     item.Snippet,
+				DisplayLink: item.DisplayLink,
+			})
+		}
+	}
+
+	// Create summary information
+	var totalResults string
+	var searchTime string
+	total := -1
+	if apiResponse.SearchInformation != nil {
+		totalResults = apiResponse.SearchInformation.FormattedTotalResults
+		searchTime = apiResponse.SearchInformation.FormattedSearchTime
+		if n, err := strconv.Atoi(apiResponse.SearchInformation.TotalResults); err == nil {
+			total = n
+		}
+	}
+
+	// Google returns the first 100 results of a query at most
+	var next string
+	end := int(args.Start) - 1 + len(results)
+	if len(results) == int(args.Num) && end < maxResults && (total < 0 || end < total) {
+		next = pager.Cursor(end)
+	}
+
+	data := map[string]interface{}{
+		"query":        args.Query,
+		"totalResults": totalResults,
+		"searchTime":   searchTime,
+		"results":      results,
+	}
+	if next != "" {
+		data["nextCursor"] = next
+	}
+	kept, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling results: %w", err)
+	}
+	s.recent.Add(ctx, args.Query, "application/json", string(kept))
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data:     data,
+		Text:     formatResults(args.Query, totalResults, searchTime, results),
+		Markdown: formatResultsMarkdown(args.Query, totalResults, searchTime, results),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pagination.Result(result, next)
+
+	log.Println("Google search request completed successfully")
+	return result, nil
+}
+
+// formatResults renders search results as text.
+func formatResults(query, totalResults, searchTime string, results []GoogleSearchResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Google Search Results for: %s\n\n", query)
+	fmt.Fprintf(&b, "Found approximately %s results in %s seconds\n\n", totalResults, searchTime)
+	if len(results) == 0 {
+		b.WriteString("No results found.")
+	}
+	for i, result := range results {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, result.Title)
+		fmt.Fprintf(&b, "   URL: %s\n", result.Link)
+		fmt.Fprintf(&b, "   %s\n\n", result.Snippet)
+	}
+	return b.String()
+}
+
+// formatResultsMarkdown renders search results as a Markdown table.
+func formatResultsMarkdown(query, totalResults, searchTime string, results []GoogleSearchResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Google Search Results for: %s\n\n", query)
+	fmt.Fprintf(&b, "Found approximately %s results in %s seconds\n\n", totalResults, searchTime)
+	if len(results) == 0 {
+		b.WriteString("No results found.")
+		return b.String()
+	}
+	table := format.Table{Columns: []string{"#", "Result", "Snippet"}}
+	for i, result := range results {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(i + 1),
+			fmt.Sprintf("[%s](%s)", result.Title, result.Link),
+			result.Snippet,
+		})
+	}
+	b.WriteString(table.Markdown())
+	return b.String()
+}
+
+// clientOptions returns the options of the HTTP client of the server.
+func clientOptions(timeout int, userAgent string, maxBodySize int64) httpclient.Options {
+	return httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		UserAgent:   userAgent,
+		MaxBodySize: maxBodySize,
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *GoogleSearchServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the googlesearch server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	var (
+		timeout        int
+		userAgent      string
+		maxBodySize    int64
+		apiKey         string
+		searchEngineID string
+		apiURL         string
+	)
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.StringVar(&userAgent, "user-agent", "MCP-GoogleSearch-Server/1.0", "User-Agent header for requests")
+	fs.Int64Var(&maxBodySize, "max-body-size", 10*1024*1024, "Maximum response body size in bytes (default 10MB)")
+	fs.StringVar(&apiKey, "api-key", "", "Google Custom Search API key")
+	fs.StringVar(&searchEngineID, "search-engine-id", "", "Google Custom Search Engine ID")
+	fs.StringVar(&apiURL, "api-url", defaultAPIURL, "Custom Search JSON API URL, e.g. that of an API gateway")
+	var upstreamFlags httpclient.Flags
+	upstreamFlags.Register(fs)
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("api-key", "API_KEY"),
+		config.WithEnv("search-engine-id", "SEARCH_ENGINE_ID"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Google search server: timeout=%ds, user-agent=%s", timeout, userAgent)
+	if apiKey == "" || searchEngineID == "" {
+		log.Printf("Warning: API key or Search Engine ID not configured. The server will start but searches will fail.")
+	}
+
+	// Create GoogleSearchServer instance
+	searchServer := NewGoogleSearchServer(timeout, userAgent, maxBodySize, apiKey, searchEngineID)
+	options := clientOptions(timeout, userAgent, maxBodySize)
+	if err := upstreamFlags.Apply(&options); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	searchServer.client = httpclient.New(options)
+	searchServer.apiURL = strings.TrimSuffix(apiURL, "/")
+	log.Println("GoogleSearchServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), searchServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return searchServer.Server(), transportFlags, nil
+}
+
+// Run starts the googlesearch server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[GoogleSearchServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create googlesearch server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("GoogleSearchServer shutdown")
+	return nil
+}
@@ -1,16 +1,18 @@
-package main
+package googlesearch
 
 import (
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
 )
 
 // GoogleSearchServer creation test
@@ -88,7 +90,7 @@ func TestServer(t *testing.T) {
 }
 
 // Mock server helper for testing Google Search API
-func setupMockGoogleAPI() *httptest.Server {
+func mockGoogleAPI() http.Handler {
 	handler := http.NewServeMux()
 
 	// Mock search endpoint
@@ -183,72 +185,41 @@ func setupMockGoogleAPI() *httptest.Server {
 		json.NewEncoder(w).Encode(mockResponse)
 	})
 
-	return httptest.NewServer(handler)
+	return handler
 }
 
 // Test API status with missing credentials
 func TestApiStatusMissingCredentials(t *testing.T) {
 	// Create server with missing credentials
 	gs := NewGoogleSearchServer(5, "Test-Agent", 1024, "", "")
-	ctx := context.Background()
-
-	req := mcp.CallToolRequest{
-		Params: struct {
-			Name      string                 `json:"name"`
-			Arguments map[string]interface{} `json:"arguments,omitempty"`
-			Meta      *struct {
-				ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-			} `json:"_meta,omitempty"`
-		}{
-			Name:      "getApiStatus",
-			Arguments: map[string]interface{}{},
-		},
-	}
-
-	// Call the handler
-	result, err := gs.handleApiStatus(ctx, req)
+	c := mcptest.Connect(t, gs.Server())
 
-	// Verify result
-	assert.NoError(t, err, "API status check should not error even with missing credentials")
-	assert.NotNil(t, result, "Result should not be nil")
-	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "API key is not configured", "Should report that API key is missing")
+	// Call the tool, which does not fail without credentials
+	text := c.Text("getApiStatus", nil)
+	assert.Contains(t, text, "API key is not configured", "Should report that API key is missing")
 }
 
 // Test API status with valid credentials
 func TestApiStatusWithCredentials(t *testing.T) {
 	// Create server with valid credentials
 	gs := NewGoogleSearchServer(5, "Test-Agent", 1024, "valid-key", "valid-cx")
-	ctx := context.Background()
-
-	req := mcp.CallToolRequest{
-		Params: struct {
-			Name      string                 `json:"name"`
-			Arguments map[string]interface{} `json:"arguments,omitempty"`
-			Meta      *struct {
-				ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-			} `json:"_meta,omitempty"`
-		}{
-			Name:      "getApiStatus",
-			Arguments: map[string]interface{}{},
-		},
-	}
-
-	// Call the handler
-	result, err := gs.handleApiStatus(ctx, req)
+	c := mcptest.Connect(t, gs.Server())
 
-	// Verify result
-	assert.NoError(t, err, "API status check should not error")
-	assert.NotNil(t, result, "Result should not be nil")
-	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "properly configured", "Should report that configuration is valid")
+	// Call the tool, which does not fail without credentials
+	text := c.Text("getApiStatus", nil)
+	assert.Contains(t, text, "properly configured", "Should report that configuration is valid")
 }
 
 // Test Google search with valid query
 func TestGoogleSearch(t *testing.T) {
-	// Setup mock Google API server
-	mockServer := setupMockGoogleAPI()
-	defer mockServer.Close()
+	// Answer the requests to the API with a mock server
+	var starts []string
+	mockAPI := mockGoogleAPI()
+	mcptest.Upstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		starts = append(starts, r.URL.Query().Get("start"))
+		mockAPI.ServeHTTP(w, r)
+	}), defaultAPIURL)
 
-	// Create GoogleSearchServer and override the base URL to point to the mock server
 	gs := NewGoogleSearchServer(5, "Test-Agent", 1024*1024, "test-key", "test-cx")
 	ctx := context.Background()
 
@@ -258,37 +229,9 @@ func TestGoogleSearch(t *testing.T) {
 			"num":   float64(2),
 		}
 
-		req := mcp.CallToolRequest{
-			Params: struct {
-				Name      string                 `json:"name"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
-			}{
-				Name:      "searchGoogle",
-				Arguments: params,
-			},
-		}
-
-		// Temporarily replace the base URL for testing
-		originalBaseURL := "https://www.googleapis.com/customsearch/v1"
-		baseURL := mockServer.URL + "/customsearch/v1"
-
-		// Hijack the HTTP request to use our mock server
-		originalClient := gs.client
-		gs.client = &http.Client{
-			Transport: &mockTransport{
-				originalURL: originalBaseURL,
-				mockURL:     baseURL,
-			},
-		}
-
+		req := mcptest.NewCallToolRequest("searchGoogle", params)
 		result, err := gs.handleGoogleSearch(ctx, req)
 
-		// Restore the original client
-		gs.client = originalClient
-
 		assert.NoError(t, err, "Search should not error with valid parameters")
 		assert.NotNil(t, result, "Result should not be nil")
 		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Google Search Results for: test search", "Response should contain the search query")
@@ -296,42 +239,58 @@ func TestGoogleSearch(t *testing.T) {
 		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "https://example.com/", "Response should contain result URLs")
 	})
 
-	t.Run("No results search", func(t *testing.T) {
-		params := map[string]interface{}{
-			"query": "no-results",
+	t.Run("Formats", func(t *testing.T) {
+		search := func(format string) string {
+			result, err := gs.handleGoogleSearch(ctx, mcptest.NewCallToolRequest("searchGoogle", map[string]interface{}{
+				"query": "test search", "format": format,
+			}))
+			require.NoError(t, err)
+			return mcptest.ResultText(result)
 		}
 
-		req := mcp.CallToolRequest{
-			Params: struct {
-				Name      string                 `json:"name"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
-			}{
-				Name:      "searchGoogle",
-				Arguments: params,
-			},
+		text := search("markdown")
+		assert.Contains(t, text, "## Google Search Results for: test search")
+		assert.Contains(t, text, "| # | Result | Snippet |")
+		assert.Contains(t, text, "| 1 | [Test Result 1 for test search](https://example.com/1) |")
+
+		var data struct {
+			Query   string               `json:"query"`
+			Results []GoogleSearchResult `json:"results"`
 		}
+		require.NoError(t, json.Unmarshal([]byte(search("json")), &data))
+		assert.Equal(t, "test search", data.Query)
+		require.NotEmpty(t, data.Results)
+		assert.Equal(t, "Test Result 1 for test search", data.Results[0].Title)
+	})
 
-		// Temporarily replace the base URL for testing
-		originalBaseURL := "https://www.googleapis.com/customsearch/v1"
-		baseURL := mockServer.URL + "/customsearch/v1"
+	t.Run("Pagination", func(t *testing.T) {
+		starts = nil
+		args := map[string]interface{}{"query": "test search", "num": 2}
+		result, err := gs.handleGoogleSearch(ctx, mcptest.NewCallToolRequest("searchGoogle", args))
+		require.NoError(t, err)
+		next, ok := result.Meta["nextCursor"].(string)
+		require.True(t, ok, "A full page should have a next cursor")
+		assert.Contains(t, mcptest.ResultText(result), "More results: call again with cursor")
+
+		args["cursor"] = next
+		_, err = gs.handleGoogleSearch(ctx, mcptest.NewCallToolRequest("searchGoogle", args))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "3"}, starts)
+
+		// Pages shorter than num are the last ones
+		result, err = gs.handleGoogleSearch(ctx, mcptest.NewCallToolRequest("searchGoogle", map[string]interface{}{"query": "test search", "num": 5}))
+		require.NoError(t, err)
+		assert.Nil(t, result.Meta)
+	})
 
-		// Hijack the HTTP request to use our mock server
-		originalClient := gs.client
-		gs.client = &http.Client{
-			Transport: &mockTransport{
-				originalURL: originalBaseURL,
-				mockURL:     baseURL,
-			},
+	t.Run("No results search", func(t *testing.T) {
+		params := map[string]interface{}{
+			"query": "no-results",
 		}
 
+		req := mcptest.NewCallToolRequest("searchGoogle", params)
 		result, err := gs.handleGoogleSearch(ctx, req)
 
-		// Restore the original client
-		gs.client = originalClient
-
 		assert.NoError(t, err, "Search should not error with valid parameters")
 		assert.NotNil(t, result, "Result should not be nil")
 		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No results found", "Response should indicate no results found")
@@ -342,24 +301,13 @@ func TestGoogleSearch(t *testing.T) {
 			"query": "",
 		}
 
-		req := mcp.CallToolRequest{
-			Params: struct {
-				Name      string                 `json:"name"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
-			}{
-				Name:      "searchGoogle",
-				Arguments: params,
-			},
-		}
+		req := mcptest.NewCallToolRequest("searchGoogle", params)
 
 		result, err := gs.handleGoogleSearch(ctx, req)
 
 		assert.Error(t, err, "Search should error with empty query")
 		assert.Nil(t, result, "Result should be nil on error")
-		assert.Contains(t, err.Error(), "Search query cannot be empty", "Error should indicate missing query")
+		assert.Contains(t, err.Error(), "query is required", "Error should indicate missing query")
 	})
 
 	t.Run("Missing API credentials", func(t *testing.T) {
@@ -370,18 +318,7 @@ func TestGoogleSearch(t *testing.T) {
 			"query": "test",
 		}
 
-		req := mcp.CallToolRequest{
-			Params: struct {
-				Name      string                 `json:"name"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
-			}{
-				Name:      "searchGoogle",
-				Arguments: params,
-			},
-		}
+		req := mcptest.NewCallToolRequest("searchGoogle", params)
 
 		result, err := gsWithoutCreds.handleGoogleSearch(ctx, req)
 
@@ -391,33 +328,17 @@ func TestGoogleSearch(t *testing.T) {
 	})
 }
 
-// Mock HTTP transport to redirect requests to our test server
-type mockTransport struct {
-	originalURL string
-	mockURL     string
+// Test sending the searches to another API URL, e.g. that of a gateway
+func TestAPIURL(t *testing.T) {
+	gateway := httptest.NewServer(http.StripPrefix("/google", mockGoogleAPI()))
+	defer gateway.Close()
+	s, _, err := New(context.Background(), []string{"-api-key", "k", "-search-engine-id", "cx", "-api-url", gateway.URL + "/google/customsearch/v1/"})
+	require.NoError(t, err)
+	text := mcptest.Connect(t, s).Text("searchGoogle", map[string]interface{}{"query": "gateway"})
+	assert.Contains(t, text, "Test Result 1 for gateway")
 }
 
-func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Replace the host with our mock server's host
-	if strings.HasPrefix(req.URL.String(), t.originalURL) {
-		newURL := strings.Replace(req.URL.String(), t.originalURL, t.mockURL, 1)
-		newReq, err := http.NewRequestWithContext(req.Context(), req.Method, newURL, req.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		// Copy headers
-		for k, v := range req.Header {
-			for _, vv := range v {
-				newReq.Header.Add(k, vv)
-			}
-		}
-
-		// Send the request to the mock server
-		client := &http.Client{}
-		return client.Do(newReq)
-	}
-
-	// For any other requests, use the default transport
-	return http.DefaultTransport.RoundTrip(req)
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewGoogleSearchServer(5, "Test-Agent", 1024*1024, "test-key", "test-cx").Server())
 }
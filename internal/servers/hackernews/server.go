@@ -0,0 +1,646 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const (
+	defaultAPIURL     = "https://hacker-news.firebaseio.com/v0"
+	defaultAlgoliaURL = "https://hn.algolia.com/api/v1"
+	itemURLPrefix     = "https://news.ycombinator.com/item?id="
+)
+
+// Item represents a story, comment, job or poll from the Hacker News API
+type Item struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	By          string `json:"by"`
+	Time        int64  `json:"time"`
+	Text        string `json:"text"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Score       int    `json:"score"`
+	Descendants int    `json:"descendants"`
+	Kids        []int  `json:"kids"`
+	Parent      int    `json:"parent"`
+	Dead        bool   `json:"dead"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// HackerNewsServer is an MCP server providing Hacker News stories and discussions.
+type HackerNewsServer struct {
+	server      *server.MCPServer
+	client      *http.Client
+	apiURL      string
+	algoliaURL  string
+	maxBodySize int64
+	concurrency int
+}
+
+// NewHackerNewsServer creates a new HackerNewsServer instance.
+func NewHackerNewsServer(apiURL, algoliaURL string, timeout int, maxBodySize int64, concurrency int) *HackerNewsServer {
+	log.Printf("HackerNewsServer created: apiURL=%s, algoliaURL=%s, timeout=%ds, concurrency=%d", apiURL, algoliaURL, timeout, concurrency)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		MaxBodySize: maxBodySize,
+	})
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	s := &HackerNewsServer{
+		client:      client,
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		algoliaURL:  strings.TrimSuffix(algoliaURL, "/"),
+		maxBodySize: maxBodySize,
+		concurrency: concurrency,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"hackernews-server", // server name
+		"1.0.0",             // version
+	)
+
+	// Register topStories tool
+	topTool := mcp.NewTool("topStories",
+		mcp.WithDescription("Returns the current top stories on Hacker News"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of stories to return (1-100, default: 10)"),
+		),
+	)
+
+	// Register newStories tool
+	newTool := mcp.NewTool("newStories",
+		mcp.WithDescription("Returns the newest stories on Hacker News"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of stories to return (1-100, default: 10)"),
+		),
+	)
+
+	// Register getItem tool
+	itemTool := mcp.NewTool("getItem",
+		mcp.WithDescription("Returns a story or comment with its discussion flattened into an indented thread"),
+		mcp.WithNumber("id",
+			mcp.Description("Item ID"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("How many levels of replies to include (0-10, default: 2)"),
+		),
+		mcp.WithNumber("maxComments",
+			mcp.Description("Maximum number of comments to include (default: 50, max: 500)"),
+		),
+	)
+
+	// Register searchHN tool
+	searchTool := mcp.NewTool("searchHN",
+		mcp.WithDescription("Searches Hacker News stories and comments using the Algolia API"),
+		mcp.WithString("query",
+			mcp.Description("Search query"),
+			mcp.Required(),
+		),
+		mcp.WithString("tags",
+			mcp.Description("Restrict results to an item type"),
+			mcp.Enum("story", "comment", "ask_hn", "show_hn", "poll", "job"),
+			mcp.DefaultString("story"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Sort by relevance or date"),
+			mcp.Enum("relevance", "date"),
+			mcp.DefaultString("relevance"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of results (1-50, default: 10)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, topTool, s.handleTopStories)
+	middleware.AddTool(mcpServer, newTool, s.handleNewStories)
+	middleware.AddTool(mcpServer, itemTool, s.handleGetItem)
+	middleware.AddTool(mcpServer, searchTool, s.handleSearchHN)
+	// Rankings change by the minute, items and searches less often
+	middleware.Cacheable(mcpServer, "topStories", cache.Policy{TTL: time.Minute})
+	middleware.Cacheable(mcpServer, "newStories", cache.Policy{TTL: time.Minute})
+	middleware.Cacheable(mcpServer, "getItem", cache.Policy{TTL: 5 * time.Minute})
+	middleware.Cacheable(mcpServer, "searchHN", cache.Policy{TTL: 5 * time.Minute})
+
+	s.server = mcpServer
+	return s
+}
+
+// getJSON performs a GET request and decodes the JSON response into out.
+func (s *HackerNewsServer) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error: API returned status code %d", resp.StatusCode)
+		return fmt.Errorf("API error: status code %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// getItem fetches a single item. A nil item is returned for IDs that do not exist.
+func (s *HackerNewsServer) getItem(ctx context.Context, id int) (*Item, error) {
+	var item *Item
+	if err := s.getJSON(ctx, fmt.Sprintf("%s/item/%d.json", s.apiURL, id), &item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// getItems fetches items concurrently, preserving the order of ids.
+// Items that fail to load or do not exist are left nil.
+func (s *HackerNewsServer) getItems(ctx context.Context, ids []int) []*Item {
+	items := make([]*Item, len(ids))
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			item, err := s.getItem(ctx, id)
+			if err != nil {
+				log.Printf("Warning: Failed to fetch item %d: %v", id, err)
+				return
+			}
+			items[i] = item
+		}(i, id)
+	}
+	wg.Wait()
+	return items
+}
+
+var (
+	paragraphTag = regexp.MustCompile(`(?i)<p>`)
+	anyTag       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlToText converts the limited HTML used in item text to plain text.
+func htmlToText(s string) string {
+	s = paragraphTag.ReplaceAllString(s, "\n")
+	s = anyTag.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// formatAge renders a Unix timestamp relative to now.
+func formatAge(unix int64) string {
+	age := time.Since(time.Unix(unix, 0))
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%d minutes ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%d days ago", int(age.Hours()/24))
+}
+
+// formatStory renders a story summary line.
+func formatStory(item *Item) string {
+	var sb strings.Builder
+	sb.WriteString(item.Title + "\n")
+	if item.URL != "" {
+		sb.WriteString("   URL: " + item.URL + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("   %d points by %s %s | %d comments | %s%d\n",
+		item.Score, item.By, formatAge(item.Time), item.Descendants, itemURLPrefix, item.ID))
+	return sb.String()
+}
+
+// handleStoryList serves the top and new story tools.
+func (s *HackerNewsServer) handleStoryList(ctx context.Context, req mcp.CallToolRequest, list, label string) (*mcp.CallToolResult, error) {
+	log.Printf("Starting %s request processing", req.Params.Name)
+
+	var args struct {
+		Limit int `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Limit <= 0 {
+		args.Limit = 10
+	} else if args.Limit > 100 {
+		args.Limit = 100
+	}
+
+	var ids []int
+	if err := s.getJSON(ctx, fmt.Sprintf("%s/%s.json", s.apiURL, list), &ids); err != nil {
+		return nil, err
+	}
+	if len(ids) > args.Limit {
+		ids = ids[:args.Limit]
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("%s on Hacker News\n\n", label))
+	n := 0
+	for _, item := range s.getItems(ctx, ids) {
+		if item == nil || item.Deleted || item.Dead {
+			continue
+		}
+		n++
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n", n, formatStory(item)))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Printf("%s request completed", req.Params.Name)
+	return result, nil
+}
+
+// handleTopStories handles the top stories request.
+func (s *HackerNewsServer) handleTopStories(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.handleStoryList(ctx, req, "topstories", "Top stories")
+}
+
+// handleNewStories handles the new stories request.
+func (s *HackerNewsServer) handleNewStories(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.handleStoryList(ctx, req, "newstories", "New stories")
+}
+
+// commentNode is a comment with the depth it appears at in the thread
+type commentNode struct {
+	item  *Item
+	depth int
+}
+
+// fetchThread loads the replies below root level by level, up to maxDepth levels and
+// maxComments comments, and returns them flattened in thread (pre-order) order.
+func (s *HackerNewsServer) fetchThread(ctx context.Context, root *Item, maxDepth, maxComments int) ([]commentNode, bool) {
+	children := map[int][]*Item{}
+	level := []*Item{root}
+	fetched := 0
+	truncated := false
+
+	for depth := 1; depth <= maxDepth && len(level) > 0; depth++ {
+		// Collect the kids of this level, keeping parent order
+		type edge struct{ parent, kid int }
+		var edges []edge
+		for _, item := range level {
+			for _, kid := range item.Kids {
+				if fetched+len(edges) >= maxComments {
+					truncated = true
+					break
+				}
+				edges = append(edges, edge{parent: item.ID, kid: kid})
+			}
+		}
+
+		ids := make([]int, len(edges))
+		for i, e := range edges {
+			ids[i] = e.kid
+		}
+		items := s.getItems(ctx, ids)
+		fetched += len(edges)
+
+		var next []*Item
+		for i, item := range items {
+			if item == nil || item.Deleted || item.Dead {
+				continue
+			}
+			children[edges[i].parent] = append(children[edges[i].parent], item)
+			next = append(next, item)
+		}
+		level = next
+
+		if depth == maxDepth {
+			for _, item := range level {
+				if len(item.Kids) > 0 {
+					truncated = true
+				}
+			}
+		}
+	}
+
+	var flat []commentNode
+	var walk func(id, depth int)
+	walk = func(id, depth int) {
+		for _, child := range children[id] {
+			flat = append(flat, commentNode{item: child, depth: depth})
+			walk(child.ID, depth+1)
+		}
+	}
+	walk(root.ID, 1)
+	return flat, truncated
+}
+
+// handleGetItem handles the item and discussion request.
+func (s *HackerNewsServer) handleGetItem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getItem request processing")
+
+	var args struct {
+		ID          int  `json:"id"`
+		Depth       *int `json:"depth,omitempty"`
+		MaxComments int  `json:"maxComments,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.ID <= 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "a valid item id is required")
+	}
+	depth := 2
+	if args.Depth != nil {
+		depth = max(0, min(10, *args.Depth))
+	}
+	if args.MaxComments <= 0 {
+		args.MaxComments = 50
+	} else if args.MaxComments > 500 {
+		args.MaxComments = 500
+	}
+
+	item, err := s.getItem(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, toolerr.Errorf(toolerr.NotFound, "item %d not found", args.ID)
+	}
+
+	var resultContent strings.Builder
+	switch {
+	case item.Title != "":
+		resultContent.WriteString(formatStory(item))
+	default:
+		resultContent.WriteString(fmt.Sprintf("%s by %s %s | %s%d\n", item.Type, item.By, formatAge(item.Time), itemURLPrefix, item.ID))
+	}
+	if item.Text != "" {
+		resultContent.WriteString("\n" + htmlToText(item.Text) + "\n")
+	}
+
+	comments, truncated := s.fetchThread(ctx, item, depth, args.MaxComments)
+	if len(comments) > 0 {
+		resultContent.WriteString(fmt.Sprintf("\nComments (%d shown):\n", len(comments)))
+	}
+	for _, c := range comments {
+		indent := strings.Repeat("  ", c.depth-1)
+		resultContent.WriteString(fmt.Sprintf("\n%s[%s, %s]\n", indent, c.item.By, formatAge(c.item.Time)))
+		for _, line := range strings.Split(htmlToText(c.item.Text), "\n") {
+			resultContent.WriteString(indent + line + "\n")
+		}
+	}
+	if truncated {
+		resultContent.WriteString("\n(More replies are available; increase depth or maxComments to see them.)\n")
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("getItem request completed")
+	return result, nil
+}
+
+// algoliaResponse is the search response returned by the Algolia HN API
+type algoliaResponse struct {
+	NbHits int `json:"nbHits"`
+	Hits   []struct {
+		ObjectID    string `json:"objectID"`
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Author      string `json:"author"`
+		Points      *int   `json:"points"`
+		NumComments *int   `json:"num_comments"`
+		CreatedAtI  int64  `json:"created_at_i"`
+		CommentText string `json:"comment_text"`
+		StoryTitle  string `json:"story_title"`
+		StoryID     *int   `json:"story_id"`
+	} `json:"hits"`
+}
+
+// handleSearchHN handles the search request.
+func (s *HackerNewsServer) handleSearchHN(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting searchHN request processing")
+
+	var args struct {
+		Query string `json:"query"`
+		Tags  string `json:"tags,omitempty"`
+		Sort  string `json:"sort,omitempty"`
+		Limit int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+	if args.Tags == "" {
+		args.Tags = "story"
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	} else if args.Limit > 50 {
+		args.Limit = 50
+	}
+
+	endpoint := "/search"
+	switch args.Sort {
+	case "", "relevance":
+	case "date":
+		endpoint = "/search_by_date"
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported sort: %s", args.Sort)
+	}
+
+	values := url.Values{}
+	values.Set("query", args.Query)
+	values.Set("tags", args.Tags)
+	values.Set("hitsPerPage", strconv.Itoa(args.Limit))
+
+	var resp algoliaResponse
+	if err := s.getJSON(ctx, s.algoliaURL+endpoint+"?"+values.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Search results for '%s' (%d total)\n\n", args.Query, resp.NbHits))
+	if len(resp.Hits) == 0 {
+		resultContent.WriteString("No results found.")
+	}
+	for i, hit := range resp.Hits {
+		if hit.CommentText != "" {
+			resultContent.WriteString(fmt.Sprintf("%d. Comment by %s on '%s' %s\n", i+1, hit.Author, hit.StoryTitle, formatAge(hit.CreatedAtI)))
+			text := htmlToText(hit.CommentText)
+			if len(text) > 300 {
+				text = text[:300] + "..."
+			}
+			resultContent.WriteString("   " + strings.ReplaceAll(text, "\n", " ") + "\n")
+			resultContent.WriteString(fmt.Sprintf("   %s%s\n", itemURLPrefix, hit.ObjectID))
+			continue
+		}
+
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n", i+1, hit.Title))
+		if hit.URL != "" {
+			resultContent.WriteString("   URL: " + hit.URL + "\n")
+		}
+		points, comments := 0, 0
+		if hit.Points != nil {
+			points = *hit.Points
+		}
+		if hit.NumComments != nil {
+			comments = *hit.NumComments
+		}
+		resultContent.WriteString(fmt.Sprintf("   %d points by %s %s | %d comments | %s%s\n",
+			points, hit.Author, formatAge(hit.CreatedAtI), comments, itemURLPrefix, hit.ObjectID))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("searchHN request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *HackerNewsServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the hackernews server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("hackernews", flag.ContinueOnError)
+	var (
+		apiURL      string
+		algoliaURL  string
+		timeout     int
+		maxBodySize int64
+		concurrency int
+	)
+	fs.StringVar(&apiURL, "api-url", defaultAPIURL, "Hacker News Firebase API base URL")
+	fs.StringVar(&algoliaURL, "algolia-url", defaultAlgoliaURL, "Hacker News Algolia search API base URL")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 5*1024*1024, "Maximum response body size in bytes (default 5MB)")
+	fs.IntVar(&concurrency, "concurrency", 8, "Maximum number of concurrent item requests")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Hacker News server: timeout=%ds", timeout)
+
+	// Create HackerNewsServer instance
+	hnServer := NewHackerNewsServer(apiURL, algoliaURL, timeout, maxBodySize, concurrency)
+	log.Println("HackerNewsServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), hnServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return hnServer.Server(), transportFlags, nil
+}
+
+// Run starts the hackernews server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[HackerNewsServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create hackernews server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("HackerNewsServer shutdown")
+	return nil
+}
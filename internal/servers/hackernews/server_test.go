@@ -0,0 +1,195 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+// HackerNewsServer creation test
+func TestNewHackerNewsServer(t *testing.T) {
+	testCases := []struct {
+		name        string
+		concurrency int
+		expected    int
+	}{
+		{name: "Default concurrency", concurrency: 8, expected: 8},
+		{name: "Invalid concurrency", concurrency: 0, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hn := NewHackerNewsServer(defaultAPIURL+"/", defaultAlgoliaURL, 15, 1024, tc.concurrency)
+
+			assert.NotNil(t, hn, "HackerNewsServer instance should be created")
+			assert.Equal(t, defaultAPIURL, hn.apiURL, "API URL should have the trailing slash trimmed")
+			assert.Equal(t, tc.expected, hn.concurrency, "Concurrency should match")
+			assert.NotNil(t, hn.server, "Internal MCPServer should be initialized")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	hn := NewHackerNewsServer(defaultAPIURL, defaultAlgoliaURL, 15, 1024, 8)
+	assert.NotNil(t, hn.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test HTML conversion of item text
+func TestHTMLToText(t *testing.T) {
+	input := `First paragraph with <a href="https://example.com" rel="nofollow">a link</a>.<p>Second &amp; last &#x27;one&#x27;.`
+	assert.Equal(t, "First paragraph with a link.\nSecond & last 'one'.", htmlToText(input))
+}
+
+// Test tool handlers against a mock Hacker News API
+func TestHandlers(t *testing.T) {
+	now := time.Now().Add(-2 * time.Hour).Unix()
+	items := map[int]Item{
+		1:  {ID: 1, Type: "story", By: "pg", Time: now, Title: "Show HN: A thing", URL: "https://example.com", Score: 120, Descendants: 4, Kids: []int{2, 3}},
+		2:  {ID: 2, Type: "comment", By: "alice", Time: now, Text: "Great <i>work</i>!", Parent: 1, Kids: []int{4}},
+		3:  {ID: 3, Type: "comment", By: "bob", Time: now, Text: "Line one<p>Line two", Parent: 1, Kids: []int{5}},
+		4:  {ID: 4, Type: "comment", By: "carol", Time: now, Text: "Agreed", Parent: 2, Kids: []int{6}},
+		5:  {ID: 5, Deleted: true, Parent: 3},
+		6:  {ID: 6, Type: "comment", By: "dave", Time: now, Text: "Too deep", Parent: 4},
+		10: {ID: 10, Type: "story", By: "sama", Time: now, Title: "Newest story", Score: 1},
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v0/topstories.json":
+			w.Write([]byte(`[1, 10, 999]`))
+		case r.URL.Path == "/v0/newstories.json":
+			w.Write([]byte(`[10, 1]`))
+		case strings.HasPrefix(r.URL.Path, "/v0/item/"):
+			var id int
+			fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+			item, ok := items[id]
+			if !ok {
+				w.Write([]byte(`null`))
+				return
+			}
+			json.NewEncoder(w).Encode(item)
+		case r.URL.Path == "/algolia/search_by_date":
+			assert.Equal(t, "comment", r.URL.Query().Get("tags"))
+			w.Write([]byte(`{"nbHits": 1, "hits": [{"objectID": "77", "author": "erin", "created_at_i": ` + fmt.Sprint(now) + `, "comment_text": "I use <code>Go</code> daily", "story_title": "Why Go"}]}`))
+		case r.URL.Path == "/algolia/search":
+			assert.Equal(t, "5", r.URL.Query().Get("hitsPerPage"))
+			w.Write([]byte(`{"nbHits": 42, "hits": [{"objectID": "1", "title": "Show HN: A thing", "url": "https://example.com", "author": "pg", "points": 120, "num_comments": 4, "created_at_i": ` + fmt.Sprint(now) + `}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	hn := NewHackerNewsServer(mockServer.URL+"/v0", mockServer.URL+"/algolia", 5, 1024*1024, 4)
+	ctx := context.Background()
+
+	t.Run("Top stories", func(t *testing.T) {
+		result, err := hn.handleTopStories(ctx, mcptest.NewCallToolRequest("topStories", nil))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1. Show HN: A thing")
+		assert.Contains(t, text, "120 points by pg 2 hours ago | 4 comments | https://news.ycombinator.com/item?id=1")
+		assert.Contains(t, text, "2. Newest story")
+		assert.NotContains(t, text, "3.", "Missing items should be skipped")
+	})
+
+	t.Run("New stories with limit", func(t *testing.T) {
+		result, err := hn.handleNewStories(ctx, mcptest.NewCallToolRequest("newStories", map[string]interface{}{
+			"limit": 1,
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1. Newest story")
+		assert.NotContains(t, text, "Show HN")
+	})
+
+	t.Run("Item with comment tree", func(t *testing.T) {
+		result, err := hn.handleGetItem(ctx, mcptest.NewCallToolRequest("getItem", map[string]interface{}{
+			"id": 1,
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Comments (3 shown)")
+		assert.Contains(t, text, "\n[alice, 2 hours ago]\nGreat work!\n")
+		assert.Contains(t, text, "\n  [carol, 2 hours ago]\n  Agreed\n")
+		assert.Contains(t, text, "\n[bob, 2 hours ago]\nLine one\nLine two\n")
+		assert.NotContains(t, text, "Too deep", "Replies beyond the depth limit should be omitted")
+		assert.Contains(t, text, "More replies are available")
+		assert.Less(t, strings.Index(text, "carol"), strings.Index(text, "bob"), "Replies should follow their parent")
+	})
+
+	t.Run("Item with comment limit", func(t *testing.T) {
+		result, err := hn.handleGetItem(ctx, mcptest.NewCallToolRequest("getItem", map[string]interface{}{
+			"id":          1,
+			"depth":       5,
+			"maxComments": 1,
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Comments (1 shown)")
+		assert.NotContains(t, text, "bob")
+	})
+
+	t.Run("Item without comments", func(t *testing.T) {
+		result, err := hn.handleGetItem(ctx, mcptest.NewCallToolRequest("getItem", map[string]interface{}{
+			"id":    1,
+			"depth": 0,
+		}))
+
+		assert.NoError(t, err)
+		assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "Comments")
+	})
+
+	t.Run("Missing item", func(t *testing.T) {
+		_, err := hn.handleGetItem(ctx, mcptest.NewCallToolRequest("getItem", map[string]interface{}{
+			"id": 999,
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("Search stories", func(t *testing.T) {
+		result, err := hn.handleSearchHN(ctx, mcptest.NewCallToolRequest("searchHN", map[string]interface{}{
+			"query": "thing",
+			"limit": 5,
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "(42 total)")
+		assert.Contains(t, text, "1. Show HN: A thing")
+	})
+
+	t.Run("Search comments by date", func(t *testing.T) {
+		result, err := hn.handleSearchHN(ctx, mcptest.NewCallToolRequest("searchHN", map[string]interface{}{
+			"query": "go",
+			"tags":  "comment",
+			"sort":  "date",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Comment by erin on 'Why Go'")
+		assert.Contains(t, text, "I use Go daily")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewHackerNewsServer(defaultAPIURL, defaultAlgoliaURL, 5, 1024, 8).Server())
+}
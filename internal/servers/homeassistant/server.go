@@ -0,0 +1,655 @@
+package homeassistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// EntityState represents the state object returned by the Home Assistant REST API
+type EntityState struct {
+	EntityID    string                 `json:"entity_id"`
+	State       string                 `json:"state"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	LastChanged string                 `json:"last_changed"`
+	LastUpdated string                 `json:"last_updated"`
+}
+
+// HomeAssistantServer is an MCP server that controls a Home Assistant instance.
+type HomeAssistantServer struct {
+	server         *server.MCPServer
+	client         *http.Client
+	baseURL        string
+	token          string
+	maxBodySize    int64
+	allowDomains   []string
+	allowEntities  []string
+	allowServices  []string
+	maxHistoryDays int
+}
+
+// NewHomeAssistantServer creates a new HomeAssistantServer instance.
+// allowDomains and allowEntities restrict which entities are visible and controllable; empty
+// lists allow every entity. allowServices lists the callable services (e.g. "light.turn_on");
+// no service can be called while it is empty.
+func NewHomeAssistantServer(baseURL, token string, timeout int, maxBodySize int64, allowDomains, allowEntities, allowServices []string, maxHistoryDays int) *HomeAssistantServer {
+	log.Printf("HomeAssistantServer created: baseURL=%s, timeout=%ds, domains=%v, entities=%v, services=%v",
+		baseURL, timeout, allowDomains, allowEntities, allowServices)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		MaxBodySize: maxBodySize,
+	})
+
+	s := &HomeAssistantServer{
+		client:         client,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		token:          token,
+		maxBodySize:    maxBodySize,
+		allowDomains:   allowDomains,
+		allowEntities:  allowEntities,
+		allowServices:  allowServices,
+		maxHistoryDays: maxHistoryDays,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"homeassistant-server", // server name
+		"1.0.0",                // version
+	)
+
+	// Register listEntities tool
+	listTool := mcp.NewTool("listEntities",
+		mcp.WithDescription("Lists Home Assistant entities and their current state, optionally filtered by domain (e.g. light, switch, sensor)"),
+		mcp.WithString("domain",
+			mcp.Description("Only list entities in this domain (e.g. 'light')"),
+		),
+		mcp.WithString("search",
+			mcp.Description("Case-insensitive substring matched against entity IDs and friendly names"),
+		),
+	)
+
+	// Register getState tool
+	stateTool := mcp.NewTool("getState",
+		mcp.WithDescription("Returns the current state and attributes of a Home Assistant entity"),
+		mcp.WithString("entityId",
+			mcp.Description("Entity ID (e.g. light.living_room)"),
+			mcp.Required(),
+		),
+	)
+
+	// Register callService tool
+	serviceTool := mcp.NewTool("callService",
+		mcp.WithDescription("Calls a Home Assistant service such as light.turn_on or switch.turn_off on an entity"),
+		mcp.WithString("domain",
+			mcp.Description("Service domain (e.g. 'light')"),
+			mcp.Required(),
+		),
+		mcp.WithString("service",
+			mcp.Description("Service name (e.g. 'turn_on', 'turn_off', 'toggle')"),
+			mcp.Required(),
+		),
+		mcp.WithString("entityId",
+			mcp.Description("Target entity ID (e.g. light.living_room)"),
+			mcp.Required(),
+		),
+		mcp.WithString("data",
+			mcp.Description("Optional JSON object with additional service data (e.g. {\"brightness_pct\": 50}). Target keys such as area_id are not allowed"),
+		),
+	)
+
+	// Register getHistory tool
+	historyTool := mcp.NewTool("getHistory",
+		mcp.WithDescription("Returns the state history of an entity for a time period"),
+		mcp.WithString("entityId",
+			mcp.Description("Entity ID (e.g. sensor.outdoor_temperature)"),
+			mcp.Required(),
+		),
+		mcp.WithString("startTime",
+			mcp.Description("RFC3339 start of the period. Defaults to 24 hours ago"),
+		),
+		mcp.WithString("endTime",
+			mcp.Description("RFC3339 end of the period. Defaults to now"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listTool, s.handleListEntities)
+	middleware.AddTool(mcpServer, stateTool, s.handleGetState)
+	middleware.AddTool(mcpServer, serviceTool, s.handleCallService)
+	middleware.AddTool(mcpServer, historyTool, s.handleGetHistory)
+	health.Register(mcpServer, "home-assistant", s.checkAPI)
+
+	s.server = mcpServer
+	return s
+}
+
+// entityIDPattern matches a single entity ID. Home Assistant also accepts comma separated
+// lists, which would let one allowed entity smuggle in others.
+var entityIDPattern = regexp.MustCompile(`^[a-z0-9_]+\.[a-z0-9_]+$`)
+
+// namePattern matches a service domain or service name.
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// targetKeys are the service data keys Home Assistant resolves to target entities.
+var targetKeys = []string{"entity_id", "device_id", "area_id", "floor_id", "label_id", "target"}
+
+// checkEntity validates an entity ID and checks it against the allowlists.
+func (s *HomeAssistantServer) checkEntity(entityID string) error {
+	if entityID == "" {
+		return fmt.Errorf("entityId is required")
+	}
+	if !entityIDPattern.MatchString(entityID) {
+		return fmt.Errorf("invalid entity ID: %s", entityID)
+	}
+	if !s.entityAllowed(entityID) {
+		log.Printf("Error: Entity not allowed: %s", entityID)
+		return fmt.Errorf("entity %s is not in the allowlist", entityID)
+	}
+	return nil
+}
+
+// entityAllowed reports whether an entity passes the domain/entity allowlists.
+func (s *HomeAssistantServer) entityAllowed(entityID string) bool {
+	if !entityIDPattern.MatchString(entityID) {
+		return false
+	}
+	if len(s.allowDomains) == 0 && len(s.allowEntities) == 0 {
+		return true
+	}
+	domain, _, _ := strings.Cut(entityID, ".")
+	for _, d := range s.allowDomains {
+		if d == domain {
+			return true
+		}
+	}
+	for _, pattern := range s.allowEntities {
+		if ok, _ := path.Match(pattern, entityID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceAllowed reports whether a domain.service pair passes the service allowlist.
+// Services outside the domain of the target entity, such as homeassistant.turn_on, must
+// be listed by their exact name rather than matched by a pattern.
+func (s *HomeAssistantServer) serviceAllowed(domain, service, entityID string) bool {
+	name := domain + "." + service
+	entityDomain, _, _ := strings.Cut(entityID, ".")
+	for _, pattern := range s.allowServices {
+		if pattern == name {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok && domain == entityDomain {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAPI is the readiness check of the server: the API must accept the token.
+func (s *HomeAssistantServer) checkAPI(ctx context.Context) error {
+	_, err := s.doRequest(ctx, http.MethodGet, "/api/", nil)
+	return err
+}
+
+// doRequest performs an authenticated request against the Home Assistant API.
+func (s *HomeAssistantServer) doRequest(ctx context.Context, method, apiPath string, body interface{}) ([]byte, error) {
+	if s.token == "" {
+		return nil, fmt.Errorf("Home Assistant token is not configured")
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, s.baseURL+apiPath, reqBody)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.token)
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	log.Printf("Sending %s request to %s", method, apiPath)
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		log.Printf("Error: API returned status code %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("API error: %s (status code: %d)", strings.TrimSpace(string(respBody)), resp.StatusCode)
+	}
+	return respBody, nil
+}
+
+// formatState renders an entity state for display.
+func formatState(state EntityState) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s", state.EntityID, state.State))
+	if name, ok := state.Attributes["friendly_name"].(string); ok && name != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", name))
+	}
+	if unit, ok := state.Attributes["unit_of_measurement"].(string); ok && unit != "" {
+		sb.WriteString(" " + unit)
+	}
+	return sb.String()
+}
+
+// handleListEntities handles the entity listing request.
+func (s *HomeAssistantServer) handleListEntities(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listEntities request processing")
+
+	var args struct {
+		Domain string `json:"domain,omitempty"`
+		Search string `json:"search,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	body, err := s.doRequest(ctx, http.MethodGet, "/api/states", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []EntityState
+	if err := json.Unmarshal(body, &states); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	search := strings.ToLower(args.Search)
+	var lines []string
+	for _, state := range states {
+		if !s.entityAllowed(state.EntityID) {
+			continue
+		}
+		if args.Domain != "" && !strings.HasPrefix(state.EntityID, args.Domain+".") {
+			continue
+		}
+		if search != "" {
+			name, _ := state.Attributes["friendly_name"].(string)
+			if !strings.Contains(strings.ToLower(state.EntityID), search) && !strings.Contains(strings.ToLower(name), search) {
+				continue
+			}
+		}
+		lines = append(lines, formatState(state))
+	}
+	sort.Strings(lines)
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Found %d entities\n\n", len(lines)))
+	for _, line := range lines {
+		resultContent.WriteString("- " + line + "\n")
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("listEntities request completed")
+	return result, nil
+}
+
+// handleGetState handles the entity state request.
+func (s *HomeAssistantServer) handleGetState(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getState request processing")
+
+	var args struct {
+		EntityID string `json:"entityId"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkEntity(args.EntityID); err != nil {
+		return nil, err
+	}
+
+	body, err := s.doRequest(ctx, http.MethodGet, "/api/states/"+url.PathEscape(args.EntityID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var state EntityState
+	if err := json.Unmarshal(body, &state); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	stateJSON, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling state: %w", err)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("%s\n\n%s", formatState(state), string(stateJSON)),
+			},
+		},
+	}
+
+	log.Println("getState request completed")
+	return result, nil
+}
+
+// handleCallService handles the service call request.
+func (s *HomeAssistantServer) handleCallService(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting callService request processing")
+
+	var args struct {
+		Domain   string `json:"domain"`
+		Service  string `json:"service"`
+		EntityID string `json:"entityId"`
+		Data     string `json:"data,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	log.Printf("callService request: %s.%s on %s", args.Domain, args.Service, args.EntityID)
+
+	if args.Domain == "" || args.Service == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "domain and service are required")
+	}
+	if !namePattern.MatchString(args.Domain) || !namePattern.MatchString(args.Service) {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid service name: %s.%s", args.Domain, args.Service)
+	}
+	if err := s.checkEntity(args.EntityID); err != nil {
+		return nil, err
+	}
+	if len(s.allowServices) == 0 {
+		return nil, fmt.Errorf("no services may be called; start the server with -allow-services")
+	}
+	if !s.serviceAllowed(args.Domain, args.Service, args.EntityID) {
+		log.Printf("Error: Service not allowed: %s.%s", args.Domain, args.Service)
+		return nil, fmt.Errorf("service %s.%s is not in the allowlist", args.Domain, args.Service)
+	}
+
+	serviceData := map[string]interface{}{}
+	if args.Data != "" {
+		if err := json.Unmarshal([]byte(args.Data), &serviceData); err != nil {
+			log.Printf("Error: Invalid service data JSON: %v", err)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid service data JSON: %w", err)
+		}
+	}
+	// Targets in the data would reach entities outside the allowlist
+	for _, key := range targetKeys {
+		if _, ok := serviceData[key]; ok {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "service data must not contain %s; use entityId", key)
+		}
+	}
+	serviceData["entity_id"] = args.EntityID
+
+	body, err := s.doRequest(ctx, http.MethodPost, fmt.Sprintf("/api/services/%s/%s", args.Domain, args.Service), serviceData)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []EntityState
+	if err := json.Unmarshal(body, &changed); err != nil {
+		log.Printf("Warning: Failed to parse service response: %v", err)
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Called %s.%s on %s\n", args.Domain, args.Service, args.EntityID))
+	if len(changed) > 0 {
+		resultContent.WriteString("\nChanged states:\n")
+		for _, state := range changed {
+			if s.entityAllowed(state.EntityID) {
+				resultContent.WriteString("- " + formatState(state) + "\n")
+			}
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("callService request completed")
+	return result, nil
+}
+
+// handleGetHistory handles the entity history request.
+func (s *HomeAssistantServer) handleGetHistory(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getHistory request processing")
+
+	var args struct {
+		EntityID  string `json:"entityId"`
+		StartTime string `json:"startTime,omitempty"`
+		EndTime   string `json:"endTime,omitempty"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkEntity(args.EntityID); err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if args.EndTime != "" {
+		if end, err = time.Parse(time.RFC3339, args.EndTime); err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid endTime (expected RFC3339): %w", err)
+		}
+	}
+	start := end.Add(-24 * time.Hour)
+	if args.StartTime != "" {
+		if start, err = time.Parse(time.RFC3339, args.StartTime); err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid startTime (expected RFC3339): %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "startTime must be before endTime")
+	}
+	if s.maxHistoryDays > 0 && end.Sub(start) > time.Duration(s.maxHistoryDays)*24*time.Hour {
+		return nil, fmt.Errorf("history period exceeds the maximum of %d days", s.maxHistoryDays)
+	}
+
+	values := url.Values{}
+	values.Set("filter_entity_id", args.EntityID)
+	values.Set("end_time", end.Format(time.RFC3339))
+	values.Set("minimal_response", "")
+	apiPath := "/api/history/period/" + url.PathEscape(start.Format(time.RFC3339)) + "?" + values.Encode()
+
+	body, err := s.doRequest(ctx, http.MethodGet, apiPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var history [][]EntityState
+	if err := json.Unmarshal(body, &history); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("History of %s from %s to %s\n\n", args.EntityID, start.Format(time.RFC3339), end.Format(time.RFC3339)))
+	count := 0
+	for _, series := range history {
+		for _, state := range series {
+			resultContent.WriteString(fmt.Sprintf("%s  %s\n", state.LastChanged, state.State))
+			count++
+		}
+	}
+	if count == 0 {
+		resultContent.WriteString("No state changes recorded.")
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("getHistory request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *HomeAssistantServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// New creates the homeassistant server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("homeassistant", flag.ContinueOnError)
+	var (
+		baseURL        string
+		token          string
+		timeout        int
+		maxBodySize    int64
+		allowDomains   string
+		allowEntities  string
+		allowServices  string
+		maxHistoryDays int
+	)
+	fs.StringVar(&baseURL, "url", "http://homeassistant.local:8123", "Home Assistant base URL")
+	fs.StringVar(&token, "token", "", "Home Assistant long-lived access token")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 10*1024*1024, "Maximum response body size in bytes (default 10MB)")
+	fs.StringVar(&allowDomains, "allow-domains", "", "Comma separated entity domains the tools may access (e.g. light,switch,sensor)")
+	fs.StringVar(&allowEntities, "allow-entities", "", "Comma separated entity IDs or glob patterns the tools may access (e.g. light.kitchen_*)")
+	fs.StringVar(&allowServices, "allow-services", "", "Comma separated services or glob patterns that may be called (e.g. light.*,switch.turn_off); callService is disabled without it")
+	fs.IntVar(&maxHistoryDays, "max-history-days", 7, "Maximum history period in days")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("token", "HASS_TOKEN"),
+		config.WithEnv("url", "HASS_URL"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Home Assistant server: url=%s, timeout=%ds", baseURL, timeout)
+	if token == "" {
+		log.Printf("Warning: Access token not configured. The server will start but requests will fail.")
+	}
+	if allowDomains == "" && allowEntities == "" {
+		log.Printf("Warning: No domain/entity allowlist configured. All entities are accessible.")
+	}
+	if allowServices == "" {
+		log.Printf("Warning: No service allowlist configured. callService is disabled.")
+	}
+
+	// Create HomeAssistantServer instance
+	haServer := NewHomeAssistantServer(baseURL, token, timeout, maxBodySize,
+		splitList(allowDomains), splitList(allowEntities), splitList(allowServices), maxHistoryDays)
+	log.Println("HomeAssistantServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), haServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return haServer.Server(), transportFlags, nil
+}
+
+// Run starts the homeassistant server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[HomeAssistantServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create homeassistant server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("HomeAssistantServer shutdown")
+	return nil
+}
@@ -0,0 +1,271 @@
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+// HomeAssistantServer creation test
+func TestNewHomeAssistantServer(t *testing.T) {
+	testCases := []struct {
+		name         string
+		baseURL      string
+		token        string
+		timeout      int
+		allowDomains []string
+	}{
+		{
+			name:    "Default settings",
+			baseURL: "http://homeassistant.local:8123",
+			token:   "token",
+			timeout: 15,
+		},
+		{
+			name:         "Trailing slash and allowlist",
+			baseURL:      "http://ha.example.com/",
+			token:        "token",
+			timeout:      5,
+			allowDomains: []string{"light"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ha := NewHomeAssistantServer(tc.baseURL, tc.token, tc.timeout, 1024, tc.allowDomains, nil, nil, 7)
+
+			assert.NotNil(t, ha, "HomeAssistantServer instance should be created")
+			assert.Equal(t, strings.TrimSuffix(tc.baseURL, "/"), ha.baseURL, "Base URL should match")
+			assert.Equal(t, tc.allowDomains, ha.allowDomains, "Allowed domains should match")
+			assert.NotNil(t, ha.server, "Internal MCPServer should be initialized")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	ha := NewHomeAssistantServer("http://localhost", "token", 15, 1024, nil, nil, nil, 7)
+	assert.NotNil(t, ha.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test allowlist evaluation
+func TestAllowlist(t *testing.T) {
+	ha := NewHomeAssistantServer("http://localhost", "token", 15, 1024,
+		[]string{"light"}, []string{"switch.kitchen_*"}, []string{"light.turn_*", "switch.toggle"}, 7)
+
+	assert.True(t, ha.entityAllowed("light.living_room"))
+	assert.True(t, ha.entityAllowed("switch.kitchen_kettle"))
+	assert.False(t, ha.entityAllowed("switch.garage_door"))
+	assert.False(t, ha.entityAllowed("lock.front_door"))
+
+	assert.False(t, ha.entityAllowed("light.kitchen,lock.front_door"), "Entity lists should be rejected")
+	assert.False(t, ha.entityAllowed("switch.kitchen_*"), "Patterns are not entity IDs")
+
+	assert.True(t, ha.serviceAllowed("light", "turn_on", "light.living_room"))
+	assert.True(t, ha.serviceAllowed("switch", "toggle", "switch.kitchen_kettle"))
+	assert.False(t, ha.serviceAllowed("switch", "turn_on", "switch.kitchen_kettle"))
+	assert.False(t, ha.serviceAllowed("light", "turn_on", "switch.kitchen_kettle"), "Patterns should only match the entity domain")
+
+	cross := NewHomeAssistantServer("http://localhost", "token", 15, 1024, nil, nil, []string{"homeassistant.turn_on", "homeassistant.*"}, 7)
+	assert.True(t, cross.serviceAllowed("homeassistant", "turn_on", "switch.kettle"), "Exact names may target other domains")
+	assert.False(t, cross.serviceAllowed("homeassistant", "turn_off", "switch.kettle"))
+
+	open := NewHomeAssistantServer("http://localhost", "token", 15, 1024, nil, nil, nil, 7)
+	assert.True(t, open.entityAllowed("lock.front_door"), "Empty entity allowlists should allow every entity")
+	assert.False(t, open.serviceAllowed("lock", "unlock", "lock.front_door"), "An empty service allowlist should allow nothing")
+}
+
+// Test tool handlers against a mock Home Assistant API
+func TestHandlers(t *testing.T) {
+	var lastServiceBody map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("401: Unauthorized"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/api/states":
+			w.Write([]byte(`[
+				{"entity_id": "light.living_room", "state": "on", "attributes": {"friendly_name": "Living Room"}},
+				{"entity_id": "sensor.temperature", "state": "21.5", "attributes": {"unit_of_measurement": "°C"}},
+				{"entity_id": "lock.front_door", "state": "locked", "attributes": {}}
+			]`))
+		case r.URL.Path == "/api/states/light.living_room":
+			w.Write([]byte(`{"entity_id": "light.living_room", "state": "on", "attributes": {"brightness": 255}}`))
+		case r.URL.Path == "/api/services/light/turn_off":
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &lastServiceBody)
+			w.Write([]byte(`[{"entity_id": "light.living_room", "state": "off", "attributes": {}}]`))
+		case strings.HasPrefix(r.URL.Path, "/api/history/period/"):
+			assert.Equal(t, "sensor.temperature", r.URL.Query().Get("filter_entity_id"))
+			w.Write([]byte(`[[
+				{"entity_id": "sensor.temperature", "state": "20.0", "last_changed": "2025-04-06T10:00:00+00:00"},
+				{"state": "21.5", "last_changed": "2025-04-06T11:00:00+00:00"}
+			]]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Entity not found."}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	ha := NewHomeAssistantServer(mockServer.URL, "test-token", 5, 1024*1024,
+		[]string{"light", "sensor"}, nil, []string{"light.*"}, 7)
+	ctx := context.Background()
+
+	t.Run("List entities filters by allowlist", func(t *testing.T) {
+		result, err := ha.handleListEntities(ctx, mcptest.NewCallToolRequest("listEntities", nil))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Found 2 entities")
+		assert.Contains(t, text, "light.living_room: on (Living Room)")
+		assert.Contains(t, text, "sensor.temperature: 21.5 °C")
+		assert.NotContains(t, text, "lock.front_door")
+	})
+
+	t.Run("List entities by domain", func(t *testing.T) {
+		result, err := ha.handleListEntities(ctx, mcptest.NewCallToolRequest("listEntities", map[string]interface{}{
+			"domain": "sensor",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Found 1 entities")
+		assert.NotContains(t, text, "light.living_room")
+	})
+
+	t.Run("Get state", func(t *testing.T) {
+		result, err := ha.handleGetState(ctx, mcptest.NewCallToolRequest("getState", map[string]interface{}{
+			"entityId": "light.living_room",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"brightness": 255`)
+	})
+
+	t.Run("Get state of disallowed entity", func(t *testing.T) {
+		result, err := ha.handleGetState(ctx, mcptest.NewCallToolRequest("getState", map[string]interface{}{
+			"entityId": "lock.front_door",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "not in the allowlist")
+	})
+
+	t.Run("Call service", func(t *testing.T) {
+		result, err := ha.handleCallService(ctx, mcptest.NewCallToolRequest("callService", map[string]interface{}{
+			"domain":   "light",
+			"service":  "turn_off",
+			"entityId": "light.living_room",
+			"data":     `{"transition": 2}`,
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "light.living_room: off")
+		assert.Equal(t, "light.living_room", lastServiceBody["entity_id"])
+		assert.Equal(t, float64(2), lastServiceBody["transition"])
+	})
+
+	t.Run("Service data cannot add targets", func(t *testing.T) {
+		for _, key := range []string{"entity_id", "area_id", "device_id", "floor_id", "label_id", "target"} {
+			result, err := ha.handleCallService(ctx, mcptest.NewCallToolRequest("callService", map[string]interface{}{
+				"domain":   "light",
+				"service":  "turn_off",
+				"entityId": "light.living_room",
+				"data":     fmt.Sprintf(`{%q: "house"}`, key),
+			}))
+
+			assert.Error(t, err, key)
+			assert.Nil(t, result)
+			assert.Contains(t, err.Error(), "must not contain "+key)
+		}
+	})
+
+	t.Run("Entity lists are rejected", func(t *testing.T) {
+		result, err := ha.handleCallService(ctx, mcptest.NewCallToolRequest("callService", map[string]interface{}{
+			"domain":   "light",
+			"service":  "turn_off",
+			"entityId": "light.living_room,lock.front_door",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "invalid entity ID")
+	})
+
+	t.Run("Services are disabled without an allowlist", func(t *testing.T) {
+		open := NewHomeAssistantServer(mockServer.URL, "test-token", 5, 1024, nil, nil, nil, 7)
+		result, err := open.handleCallService(ctx, mcptest.NewCallToolRequest("callService", map[string]interface{}{
+			"domain":   "light",
+			"service":  "turn_off",
+			"entityId": "light.living_room",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "-allow-services")
+	})
+
+	t.Run("Call disallowed service", func(t *testing.T) {
+		result, err := ha.handleCallService(ctx, mcptest.NewCallToolRequest("callService", map[string]interface{}{
+			"domain":   "sensor",
+			"service":  "reload",
+			"entityId": "sensor.temperature",
+		}))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "service sensor.reload is not in the allowlist")
+	})
+
+	t.Run("Get history", func(t *testing.T) {
+		result, err := ha.handleGetHistory(ctx, mcptest.NewCallToolRequest("getHistory", map[string]interface{}{
+			"entityId":  "sensor.temperature",
+			"startTime": "2025-04-06T00:00:00Z",
+			"endTime":   "2025-04-07T00:00:00Z",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "2025-04-06T10:00:00+00:00  20.0")
+		assert.Contains(t, text, "2025-04-06T11:00:00+00:00  21.5")
+	})
+
+	t.Run("History period too long", func(t *testing.T) {
+		_, err := ha.handleGetHistory(ctx, mcptest.NewCallToolRequest("getHistory", map[string]interface{}{
+			"entityId":  "sensor.temperature",
+			"startTime": "2025-01-01T00:00:00Z",
+			"endTime":   "2025-04-01T00:00:00Z",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "maximum of 7 days")
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		bad := NewHomeAssistantServer(mockServer.URL, "wrong", 5, 1024, nil, nil, nil, 7)
+		_, err := bad.handleListEntities(ctx, mcptest.NewCallToolRequest("listEntities", nil))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "status code: 401")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewHomeAssistantServer("http://localhost", "token", 5, 1024, nil, nil, nil, 7).Server())
+}
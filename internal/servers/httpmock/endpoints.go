@@ -0,0 +1,259 @@
+package httpmock
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Response is the response an endpoint returns.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// LatencyMs delays the response, e.g. to test client timeouts.
+	LatencyMs int `json:"latencyMs,omitempty"`
+	// Echo returns the request received as JSON instead of Body.
+	Echo bool `json:"echo,omitempty"`
+}
+
+// Request is a request received by an endpoint.
+type Request struct {
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body,omitempty"`
+	// BodyBase64 tells that Body is encoded in base64, as it is not UTF-8 text.
+	BodyBase64    bool   `json:"bodyBase64,omitempty"`
+	BodyTruncated bool   `json:"bodyTruncated,omitempty"`
+	BodySize      int64  `json:"bodySize"`
+	RemoteAddr    string `json:"remoteAddr"`
+	Status        int    `json:"status"`
+}
+
+// Endpoint is a mock HTTP endpoint, answering the requests of its URL and the paths
+// below it with its response and recording them.
+type Endpoint struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	Method  string    `json:"method,omitempty"` // any if empty
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+	Response
+	// Received is the number of requests received, including those no longer kept.
+	Received int `json:"received"`
+
+	requests []Request
+}
+
+// endpoints are the endpoints of a server, served over HTTP.
+type endpoints struct {
+	mu          sync.Mutex
+	byID        map[string]*Endpoint
+	maxRequests int
+	maxBodySize int64
+	now         func() time.Time
+}
+
+// newEndpoints returns an empty set of endpoints keeping maxRequests requests each,
+// with bodies of at most maxBodySize bytes.
+func newEndpoints(maxRequests int, maxBodySize int64) *endpoints {
+	return &endpoints{byID: map[string]*Endpoint{}, maxRequests: maxRequests, maxBodySize: maxBodySize, now: time.Now}
+}
+
+// newEndpointID returns a random identifier, hard to guess so that endpoints reachable
+// from other hosts are not found by scanning.
+func newEndpointID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// expire removes the endpoints that have expired. The caller holds mu.
+func (e *endpoints) expire() {
+	now := e.now()
+	for id, endpoint := range e.byID {
+		if now.After(endpoint.Expires) {
+			log.Printf("Endpoint %s expired after receiving %d requests", id, endpoint.Received)
+			delete(e.byID, id)
+		}
+	}
+}
+
+// add adds endpoint, assigning its ID, URL under baseURL and dates, unless there are
+// already max endpoints.
+func (e *endpoints) add(endpoint *Endpoint, baseURL string, ttl time.Duration, max int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire()
+	if len(e.byID) >= max {
+		return fmt.Errorf("there are already %d endpoints, the maximum; delete one first", max)
+	}
+	endpoint.ID = newEndpointID()
+	endpoint.URL = strings.TrimSuffix(baseURL, "/") + "/" + endpoint.ID
+	endpoint.Created = e.now()
+	endpoint.Expires = endpoint.Created.Add(ttl)
+	e.byID[endpoint.ID] = endpoint
+	return nil
+}
+
+// snapshot returns a copy of endpoint without its requests. The caller holds mu.
+func snapshot(endpoint *Endpoint) Endpoint {
+	c := *endpoint
+	c.requests = nil
+	return c
+}
+
+// list returns the endpoints, the oldest first.
+func (e *endpoints) list() []Endpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire()
+	list := make([]Endpoint, 0, len(e.byID))
+	for _, endpoint := range e.byID {
+		list = append(list, snapshot(endpoint))
+	}
+	slices.SortFunc(list, func(a, b Endpoint) int { return a.Created.Compare(b.Created) })
+	return list
+}
+
+// requests returns endpoint id and its last limit requests, the oldest first,
+// forgetting them if clear is set.
+func (e *endpoints) requests(id string, limit int, clear bool) (Endpoint, []Request, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire()
+	endpoint, ok := e.byID[id]
+	if !ok {
+		return Endpoint{}, nil, false
+	}
+	requests := endpoint.requests[max(len(endpoint.requests)-limit, 0):]
+	if clear {
+		endpoint.requests = nil
+	} else {
+		requests = slices.Clone(requests)
+	}
+	return snapshot(endpoint), requests, true
+}
+
+// remove removes endpoint id, reporting whether it existed.
+func (e *endpoints) remove(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.expire()
+	_, ok := e.byID[id]
+	delete(e.byID, id)
+	return ok
+}
+
+// record records req as received by endpoint, keeping its last maxRequests requests.
+// The caller holds mu.
+func (e *endpoints) record(endpoint *Endpoint, req Request) {
+	endpoint.Received++
+	endpoint.requests = append(endpoint.requests, req)
+	if over := len(endpoint.requests) - e.maxRequests; over > 0 {
+		endpoint.requests = slices.Delete(endpoint.requests, 0, over)
+	}
+}
+
+// readRequest describes r, reading up to maxBodySize bytes of its body.
+func readRequest(r *http.Request, maxBodySize int64, now time.Time) Request {
+	req := Request{
+		Time:       now,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.RawQuery,
+		Headers:    map[string]string{},
+		RemoteAddr: r.RemoteAddr,
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.Header)) {
+		req.Headers[name] = strings.Join(r.Header[name], ", ")
+	}
+	if r.Host != "" {
+		req.Headers["Host"] = r.Host
+	}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	req.BodySize = int64(len(body))
+	if int64(len(body)) > maxBodySize {
+		body = body[:maxBodySize]
+		req.BodyTruncated = true
+		// The rest is counted, not kept
+		n, _ := io.Copy(io.Discard, r.Body)
+		req.BodySize += n
+	}
+	if utf8.Valid(body) {
+		req.Body = string(body)
+	} else {
+		req.Body, req.BodyBase64 = base64.StdEncoding.EncodeToString(body), true
+	}
+	return req
+}
+
+// ServeHTTP answers the requests of the endpoints, at /<id> and the paths below it.
+func (e *endpoints) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	e.mu.Lock()
+	endpoint, ok := e.byID[id]
+	if ok && e.now().After(endpoint.Expires) {
+		ok = false
+	}
+	var response Response
+	if ok {
+		response = endpoint.Response
+	}
+	e.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	req := readRequest(r, e.maxBodySize, e.now())
+	req.Status = response.Status
+	if endpoint.Method != "" && !strings.EqualFold(endpoint.Method, r.Method) {
+		req.Status = http.StatusMethodNotAllowed
+	}
+	e.mu.Lock()
+	e.record(endpoint, req)
+	e.mu.Unlock()
+	log.Printf("Endpoint %s received %s %s, answering %d", id, r.Method, r.URL.Path, req.Status)
+
+	if response.LatencyMs > 0 {
+		timer := time.NewTimer(time.Duration(response.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if req.Status == http.StatusMethodNotAllowed && response.Status != http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", strings.ToUpper(endpoint.Method))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := []byte(response.Body)
+	if response.Echo {
+		body, _ = json.MarshalIndent(req, "", "  ")
+		w.Header().Set("Content-Type", "application/json")
+	}
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(response.Status)
+	w.Write(body)
+}
@@ -0,0 +1,415 @@
+package httpmock
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// HTTPMockServer is an MCP server serving mock HTTP endpoints that return configured
+// responses and record the requests they receive, so that agents can test webhooks
+// and HTTP clients end to end.
+type HTTPMockServer struct {
+	server       *server.MCPServer
+	endpoints    *endpoints
+	baseURL      string
+	ttl          time.Duration
+	maxEndpoints int
+}
+
+// NewHTTPMockServer creates a new HTTPMockServer instance with endpoints under baseURL,
+// expiring after ttl, keeping their last maxRequests requests.
+func NewHTTPMockServer(baseURL string, ttl time.Duration, maxEndpoints, maxRequests int, maxBodySize int64) *HTTPMockServer {
+	log.Printf("HTTPMockServer created: baseURL=%s, ttl=%s, maxEndpoints=%d, maxRequests=%d, maxBodySize=%d", baseURL, ttl, maxEndpoints, maxRequests, maxBodySize)
+
+	s := &HTTPMockServer{
+		endpoints:    newEndpoints(maxRequests, maxBodySize),
+		baseURL:      baseURL,
+		ttl:          ttl,
+		maxEndpoints: maxEndpoints,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"httpmock-server", // server name
+		"1.0.0",           // version
+	)
+
+	// Register createMockEndpoint tool
+	createTool := params.Tool[createArgs]("createMockEndpoint",
+		mcp.WithDescription(fmt.Sprintf("Creates a mock HTTP endpoint returning the response configured, and returns its URL. The endpoint answers its URL and the paths below it, records the requests it receives for getMockRequests, and expires after %s. Use it to test webhooks and HTTP clients", ttl)),
+	)
+
+	// Register listMockEndpoints tool
+	listTool := params.Tool[listArgs]("listMockEndpoints",
+		mcp.WithDescription("Lists the mock HTTP endpoints with their URL, response and number of requests received"),
+	)
+
+	// Register getMockRequests tool
+	requestsTool := params.Tool[requestsArgs]("getMockRequests",
+		mcp.WithDescription(fmt.Sprintf("Returns the requests a mock endpoint received, the oldest first, with their method, path, query, headers and body. The last %d requests of each endpoint are kept", maxRequests)),
+	)
+
+	// Register deleteMockEndpoint tool
+	deleteTool := params.Tool[deleteArgs]("deleteMockEndpoint",
+		mcp.WithDescription("Deletes a mock HTTP endpoint, which then answers 404 Not Found"),
+	)
+
+	middleware.AddTool(mcpServer, createTool, s.handleCreateEndpoint)
+	middleware.AddTool(mcpServer, listTool, s.handleListEndpoints)
+	middleware.AddTool(mcpServer, requestsTool, s.handleGetRequests)
+	middleware.AddTool(mcpServer, deleteTool, s.handleDeleteEndpoint)
+
+	s.server = mcpServer
+	return s
+}
+
+// ServeHTTP answers the requests of the endpoints.
+func (s *HTTPMockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.endpoints.ServeHTTP(w, r)
+}
+
+// Listen serves the endpoints on addr until ctx is done. The URL of the endpoints is
+// that of the listener unless the server has a base URL.
+func (s *HTTPMockServer) Listen(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for mock endpoints on %s: %w", addr, err)
+	}
+	if ip, ok := ln.Addr().(*net.TCPAddr); !ok || !ip.IP.IsLoopback() {
+		log.Printf("Warning: Mock endpoints on %s are reachable from other hosts", ln.Addr())
+	}
+	if s.baseURL == "" {
+		s.baseURL = "http://" + ln.Addr().String()
+	}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error: Mock endpoint server failed: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	log.Printf("Serving mock endpoints on %s", s.baseURL)
+	return nil
+}
+
+// createArgs are the parameters of createMockEndpoint.
+type createArgs struct {
+	Status      int               `json:"status,omitempty" param:"default=200,min=100,max=599" description:"HTTP status code of the response (default: 200)"`
+	Body        string            `json:"body,omitempty" description:"Body of the response"`
+	ContentType string            `json:"contentType,omitempty" description:"Content-Type of the response (default: application/json if the body is JSON, text/plain otherwise)"`
+	Headers     map[string]string `json:"headers,omitempty" description:"Other headers of the response, by name"`
+	LatencyMs   int               `json:"latencyMs,omitempty" param:"min=0,max=60000" description:"Delay before responding in milliseconds, e.g. to test client timeouts (default: 0)"`
+	Method      string            `json:"method,omitempty" description:"Only method accepted, others are answered 405 Method Not Allowed (default: any)"`
+	Echo        bool              `json:"echo,omitempty" description:"Respond with the request received as JSON instead of the body (default: false)"`
+}
+
+// listArgs are the parameters of listMockEndpoints.
+type listArgs struct {
+	Format string `json:"format,omitempty" param:"enum=text|markdown|json" description:"Output format (default: text)"`
+}
+
+// requestsArgs are the parameters of getMockRequests.
+type requestsArgs struct {
+	ID     string `json:"id" param:"required" description:"ID of the endpoint"`
+	Limit  int    `json:"limit,omitempty" param:"default=20,min=1" description:"Maximum number of requests, the last ones (default: 20)"`
+	Clear  bool   `json:"clear,omitempty" description:"Forget the requests returned, so that the next call returns only new ones (default: false)"`
+	Format string `json:"format,omitempty" param:"enum=text|json" description:"Output format (default: text)"`
+}
+
+// deleteArgs are the parameters of deleteMockEndpoint.
+type deleteArgs struct {
+	ID string `json:"id" param:"required" description:"ID of the endpoint"`
+}
+
+// handleCreateEndpoint handles the endpoint creation request.
+func (s *HTTPMockServer) handleCreateEndpoint(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting createMockEndpoint request processing")
+
+	var args createArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if int64(len(args.Body)) > s.endpoints.maxBodySize {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "body exceeds the maximum size of %d bytes", s.endpoints.maxBodySize)
+	}
+	if args.Method != "" && !token(args.Method) {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid method %q", args.Method)
+	}
+	headers := map[string]string{}
+	for name, value := range args.Headers {
+		if !token(name) || strings.ContainsAny(value, "\r\n") {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid header %q", name)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	if args.ContentType != "" {
+		if strings.ContainsAny(args.ContentType, "\r\n") {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid content type %q", args.ContentType)
+		}
+		headers["Content-Type"] = args.ContentType
+	} else if _, ok := headers["Content-Type"]; !ok && args.Body != "" {
+		headers["Content-Type"] = "text/plain; charset=utf-8"
+		if trimmed := strings.TrimSpace(args.Body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			headers["Content-Type"] = "application/json"
+		}
+	}
+
+	endpoint := &Endpoint{
+		Method: strings.ToUpper(args.Method),
+		Response: Response{
+			Status:    args.Status,
+			Headers:   headers,
+			Body:      args.Body,
+			LatencyMs: args.LatencyMs,
+			Echo:      args.Echo,
+		},
+	}
+	if err := s.endpoints.add(endpoint, s.baseURL, s.ttl, s.maxEndpoints); err != nil {
+		return nil, toolerr.Errorf(toolerr.PermissionDenied, "%w", err)
+	}
+
+	method := endpoint.Method
+	if method == "" {
+		method = "any method"
+	}
+	text := fmt.Sprintf("Created endpoint %s\nURL: %s\nAccepts: %s, at the URL and the paths below it\nResponds: %s\nExpires: %s",
+		endpoint.ID, endpoint.URL, method, endpoint.describe(), endpoint.Expires.Format(time.RFC3339))
+
+	log.Printf("createMockEndpoint request completed: %s", endpoint.ID)
+	return mcp.NewToolResultText(text), nil
+}
+
+// token reports whether s is an HTTP token, as methods and header names are.
+func token(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r)
+	})
+}
+
+// describe describes the response of e.
+func (e Endpoint) describe() string {
+	d := strconv.Itoa(e.Status) + " " + http.StatusText(e.Status)
+	if e.Echo {
+		d += ", echoing the request"
+	} else if e.Body != "" {
+		d += fmt.Sprintf(", %d bytes of %s", len(e.Body), e.Headers["Content-Type"])
+	}
+	if e.LatencyMs > 0 {
+		d += fmt.Sprintf(", after %dms", e.LatencyMs)
+	}
+	return d
+}
+
+// handleListEndpoints handles the endpoint listing request.
+func (s *HTTPMockServer) handleListEndpoints(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listMockEndpoints request processing")
+
+	var args listArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	list := s.endpoints.list()
+	table := format.Table{Columns: []string{"ID", "URL", "Method", "Response", "Requests", "Expires"}}
+	for _, e := range list {
+		method := e.Method
+		if method == "" {
+			method = "any"
+		}
+		table.Rows = append(table.Rows, []string{e.ID, e.URL, method, e.describe(), strconv.Itoa(e.Received), e.Expires.Format(time.RFC3339)})
+	}
+	text, markdown := table.Text(), table.Markdown()
+	if len(list) == 0 {
+		text, markdown = "No mock endpoints", "No mock endpoints"
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: list, Text: text, Markdown: markdown})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("listMockEndpoints request completed: %d endpoints", len(list))
+	return result, nil
+}
+
+// handleGetRequests handles the request listing request.
+func (s *HTTPMockServer) handleGetRequests(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getMockRequests request processing")
+
+	var args requestsArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	endpoint, requests, ok := s.endpoints.requests(args.ID, args.Limit, args.Clear)
+	if !ok {
+		return nil, toolerr.Errorf(toolerr.NotFound, "endpoint %q not found; it may have expired", args.ID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Endpoint %s received %d requests", endpoint.ID, endpoint.Received)
+	if len(requests) < endpoint.Received {
+		fmt.Fprintf(&b, ", the last %d:", len(requests))
+	}
+	for i, r := range requests {
+		target := r.Path
+		if r.Query != "" {
+			target += "?" + r.Query
+		}
+		fmt.Fprintf(&b, "\n\n%d. %s %s at %s from %s, answered %d\n", i+1, r.Method, target, r.Time.Format(time.RFC3339Nano), r.RemoteAddr, r.Status)
+		for _, name := range slices.Sorted(maps.Keys(r.Headers)) {
+			fmt.Fprintf(&b, "%s: %s\n", name, r.Headers[name])
+		}
+		switch {
+		case r.BodySize == 0:
+		case r.BodyBase64:
+			fmt.Fprintf(&b, "\n[%d bytes of binary data, in base64]\n%s", r.BodySize, r.Body)
+		default:
+			fmt.Fprintf(&b, "\n%s", r.Body)
+		}
+		if r.BodyTruncated {
+			fmt.Fprintf(&b, "\n[truncated, %d bytes in all]", r.BodySize)
+		}
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data: map[string]interface{}{"endpoint": endpoint, "requests": requests},
+		Text: strings.TrimRight(b.String(), "\n"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("getMockRequests request completed: %d requests", len(requests))
+	return result, nil
+}
+
+// handleDeleteEndpoint handles the endpoint deletion request.
+func (s *HTTPMockServer) handleDeleteEndpoint(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting deleteMockEndpoint request processing")
+
+	var args deleteArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if !s.endpoints.remove(args.ID) {
+		return nil, toolerr.Errorf(toolerr.NotFound, "endpoint %q not found; it may have expired", args.ID)
+	}
+
+	log.Printf("deleteMockEndpoint request completed: %s", args.ID)
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted endpoint %s", args.ID)), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *HTTPMockServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the HTTP mock server from the command line arguments in args. It returns
+// the MCPServer along with the transport flags, for callers that serve it themselves.
+// The endpoints are served until ctx is done.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("httpmock", flag.ContinueOnError)
+	var (
+		listen       string
+		publicURL    string
+		ttl          time.Duration
+		maxEndpoints int
+		maxRequests  int
+		maxBodySize  int64
+	)
+	fs.StringVar(&listen, "mock-listen", "127.0.0.1:0", "Address the mock endpoints listen on; port 0 picks a free port")
+	fs.StringVar(&publicURL, "mock-url", "", "Base URL the mock endpoints are reached at, e.g. through a tunnel or a reverse proxy (default: the -mock-listen address)")
+	fs.DurationVar(&ttl, "endpoint-ttl", time.Hour, "How long mock endpoints live")
+	fs.IntVar(&maxEndpoints, "max-endpoints", 20, "Maximum number of mock endpoints")
+	fs.IntVar(&maxRequests, "max-requests", 100, "Number of requests kept per endpoint")
+	fs.Int64Var(&maxBodySize, "max-body-size", 1024*1024, "Maximum size of the response bodies and of the request bodies recorded in bytes (default 1MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	if publicURL != "" {
+		if u, err := url.Parse(publicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, transport.Flags{}, fmt.Errorf("invalid -mock-url %q: expected a URL such as https://mocks.example.com", publicURL)
+		}
+	}
+	if ttl <= 0 || maxEndpoints < 1 || maxRequests < 1 || maxBodySize < 1 {
+		return nil, transport.Flags{}, errors.New("-endpoint-ttl, -max-endpoints, -max-requests and -max-body-size must be positive")
+	}
+
+	log.Printf("Starting HTTP mock server: mock-listen=%s, mock-url=%s", listen, publicURL)
+
+	// Create HTTPMockServer instance
+	mockServer := NewHTTPMockServer(publicURL, ttl, maxEndpoints, maxRequests, maxBodySize)
+	if err := mockServer.Listen(ctx, listen); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	log.Println("HTTPMockServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), mockServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return mockServer.Server(), transportFlags, nil
+}
+
+// Run starts the HTTP mock server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[HTTPMockServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// The endpoints are served until Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create HTTP mock server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("HTTPMockServer shutdown")
+	return nil
+}
@@ -0,0 +1,175 @@
+package httpmock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// newTestServer returns a client of a server listening on a free port.
+func newTestServer(t *testing.T, args ...string) *mcptest.Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s, _, err := New(ctx, append([]string{"-mock-listen", "127.0.0.1:0"}, args...))
+	require.NoError(t, err)
+	return mcptest.Connect(t, s)
+}
+
+var urlPattern = regexp.MustCompile(`URL: (\S+)`)
+
+// create creates an endpoint, returning its URL and ID.
+func create(t *testing.T, c *mcptest.Client, args map[string]interface{}) (string, string) {
+	text := c.Text("createMockEndpoint", args)
+	m := urlPattern.FindStringSubmatch(text)
+	require.NotNil(t, m, text)
+	return m[1], m[1][strings.LastIndex(m[1], "/")+1:]
+}
+
+// send sends a request, returning the response and its body.
+func send(t *testing.T, method, url, body string, headers map[string]string) (*http.Response, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+// Test endpoints returning configured responses and recording requests
+func TestEndpoint(t *testing.T) {
+	c := newTestServer(t)
+
+	url, id := create(t, c, map[string]interface{}{
+		"status":  201,
+		"body":    `{"ok": true}`,
+		"headers": map[string]interface{}{"x-request-id": "abc"},
+	})
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+/[0-9a-f]{16}$`, url)
+
+	resp, body := send(t, http.MethodPost, url+"/hooks/github?event=push", `{"ref":"main"}`, map[string]string{"X-GitHub-Event": "push"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"ok": true}`, body)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "abc", resp.Header.Get("X-Request-Id"))
+	send(t, http.MethodGet, url, "", nil)
+
+	text := c.Text("getMockRequests", map[string]interface{}{"id": id})
+	assert.Contains(t, text, "Endpoint "+id+" received 2 requests\n\n1. POST /"+id+"/hooks/github?event=push at ")
+	assert.Contains(t, text, ", answered 201\n")
+	assert.Contains(t, text, "X-Github-Event: push\n")
+	assert.Contains(t, text, "\n\n{\"ref\":\"main\"}\n\n2. GET /"+id+" at ")
+
+	var result struct {
+		Endpoint Endpoint
+		Requests []Request
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.Text("getMockRequests", map[string]interface{}{"id": id, "limit": 1, "clear": true, "format": "json"})), &result))
+	assert.Equal(t, 2, result.Endpoint.Received)
+	require.Len(t, result.Requests, 1)
+	assert.Equal(t, "GET", result.Requests[0].Method)
+	assert.Equal(t, "", result.Requests[0].Body)
+	assert.Equal(t, "Endpoint "+id+" received 2 requests, the last 0:", c.Text("getMockRequests", map[string]interface{}{"id": id}), "Cleared requests are forgotten")
+
+	list := c.Text("listMockEndpoints", map[string]interface{}{})
+	assert.Contains(t, list, id)
+	assert.Contains(t, list, "201 Created, 12 bytes of application/json")
+
+	assert.Equal(t, "Deleted endpoint "+id, c.Text("deleteMockEndpoint", map[string]interface{}{"id": id}))
+	resp, _ = send(t, http.MethodGet, url, "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, `endpoint "`+id+`" not found; it may have expired`, c.Error("getMockRequests", map[string]interface{}{"id": id}))
+	assert.Equal(t, "No mock endpoints", c.Text("listMockEndpoints", map[string]interface{}{}))
+}
+
+// Test echoing requests, restricting methods and delaying responses
+func TestEchoMethodLatency(t *testing.T) {
+	c := newTestServer(t)
+
+	url, _ := create(t, c, map[string]interface{}{"echo": true, "method": "put"})
+	resp, body := send(t, http.MethodPut, url+"/x?a=1", "\xff\xfe", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var echoed Request
+	require.NoError(t, json.Unmarshal([]byte(body), &echoed))
+	assert.Equal(t, "PUT", echoed.Method)
+	assert.Equal(t, "a=1", echoed.Query)
+	assert.True(t, echoed.BodyBase64, "Binary bodies are encoded")
+	assert.Equal(t, "//4=", echoed.Body)
+
+	resp, _ = send(t, http.MethodGet, url, "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "PUT", resp.Header.Get("Allow"))
+
+	url, _ = create(t, c, map[string]interface{}{"latencyMs": 200, "body": "slow"})
+	start := time.Now()
+	resp, body = send(t, http.MethodGet, url, "", nil)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, "slow", body)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+// Test the limits of the endpoints
+func TestLimits(t *testing.T) {
+	c := newTestServer(t, "-max-endpoints", "2", "-max-requests", "2", "-max-body-size", "8", "-mock-url", "https://mocks.example.com/base/")
+
+	url, _ := create(t, c, map[string]interface{}{})
+	assert.True(t, strings.HasPrefix(url, "https://mocks.example.com/base/"), url)
+	create(t, c, map[string]interface{}{})
+	assert.Equal(t, "there are already 2 endpoints, the maximum; delete one first", c.Error("createMockEndpoint", map[string]interface{}{}))
+	assert.Equal(t, "body exceeds the maximum size of 8 bytes", c.Error("createMockEndpoint", map[string]interface{}{"body": "123456789"}))
+	assert.Equal(t, `invalid header "bad header"`, c.Error("createMockEndpoint", map[string]interface{}{"headers": map[string]interface{}{"bad header": "x"}}))
+	assert.Equal(t, `invalid method "GET /"`, c.Error("createMockEndpoint", map[string]interface{}{"method": "GET /"}))
+
+	// Requests are recorded through the handler, as the public URL is not served here
+	s := NewHTTPMockServer("http://mock.test", time.Hour, 1, 2, 8)
+	c = mcptest.Connect(t, s.Server())
+	_, id := create(t, c, map[string]interface{}{})
+	for _, body := range []string{"first", "second", "third body is long"} {
+		req, err := http.NewRequest(http.MethodPost, "http://mock.test/"+id, strings.NewReader(body))
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	_, requests, ok := s.endpoints.requests(id, 10, false)
+	require.True(t, ok)
+	require.Len(t, requests, 2, "Only the last requests are kept")
+	assert.Equal(t, "second", requests[0].Body)
+	assert.Equal(t, "third bo", requests[1].Body)
+	assert.True(t, requests[1].BodyTruncated)
+	assert.Equal(t, int64(18), requests[1].BodySize)
+
+	// Expired endpoints are removed
+	s.endpoints.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.Contains(t, c.Error("getMockRequests", map[string]interface{}{"id": id}), "not found")
+}
+
+// Test the validation of the flags
+func TestFlags(t *testing.T) {
+	_, _, err := New(context.Background(), []string{"-mock-url", "ftp://x"})
+	assert.ErrorContains(t, err, "invalid -mock-url")
+	_, _, err = New(context.Background(), []string{"-max-endpoints", "0"})
+	assert.ErrorContains(t, err, "must be positive")
+	_, _, err = New(context.Background(), []string{"-mock-listen", "256.0.0.1:1"})
+	assert.ErrorContains(t, err, "failed to listen for mock endpoints")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewHTTPMockServer("http://mock.test", time.Hour, 10, 10, 1024).Server())
+}
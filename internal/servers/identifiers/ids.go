@@ -0,0 +1,407 @@
+package identifiers
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snowflake layout: 41 bits of milliseconds since the epoch, 10 bits of machine ID and
+// 12 bits of sequence, as introduced by Twitter.
+const (
+	machineBits  = 10
+	sequenceBits = 12
+	maxMachineID = 1<<machineBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// snowflakeEpochs are well known epochs in milliseconds since the Unix epoch.
+var snowflakeEpochs = map[string]int64{
+	"twitter": 1288834974657,
+	"discord": 1420070400000,
+}
+
+// gregorianOffset is the number of 100ns intervals between the start of the Gregorian
+// calendar, where UUIDv1 and v6 timestamps start, and the Unix epoch.
+const gregorianOffset = 0x01b21dd213814000
+
+// crockford is the base32 alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// nanoidAlphabet is the URL safe default alphabet of nanoid.
+const nanoidAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// idGenerator generates identifiers. It keeps the state that makes UUIDv7, ULID and
+// snowflake IDs strictly increasing, also within one millisecond.
+type idGenerator struct {
+	// now and random are replaced in tests.
+	now    func() time.Time
+	random io.Reader
+
+	mu        sync.Mutex
+	lastV7    [16]byte
+	lastULID  [16]byte
+	lastMs    int64 // snowflake
+	sequence  int64 // snowflake
+	machineID int64
+	epoch     int64
+}
+
+func (g *idGenerator) read(b []byte) error {
+	if _, err := io.ReadFull(g.random, b); err != nil {
+		return fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return nil
+}
+
+// uuid4 returns a random UUID.
+func (g *idGenerator) uuid4() (string, error) {
+	var b [16]byte
+	if err := g.read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+// increment adds one to the big endian number in b, reporting overflow.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// putMillis writes a 48-bit millisecond timestamp to the first six bytes of b.
+func putMillis(b []byte, ms int64) {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], uint64(ms))
+	copy(b[:6], t[2:])
+}
+
+func millis(b []byte) int64 {
+	var t [8]byte
+	copy(t[2:], b[:6])
+	return int64(binary.BigEndian.Uint64(t[:]))
+}
+
+// uuid7 returns a time ordered UUID (RFC 9562). Within a millisecond, the random bits
+// after the timestamp are incremented to keep IDs ordered.
+func (g *idGenerator) uuid7() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().UnixMilli()
+	var b [16]byte
+	if ms <= millis(g.lastV7[:]) {
+		b = g.lastV7
+		// The version and variant bits sit inside the counter; increment around them
+		counter := binary.BigEndian.Uint64(b[8:]) & 0x3fffffffffffffff
+		counter++
+		hi := uint16(b[6]&0x0f)<<8 | uint16(b[7])
+		if counter > 0x3fffffffffffffff {
+			counter = 0
+			hi++
+			if hi > 0x0fff {
+				// Borrow the next millisecond, as RFC 9562 allows
+				putMillis(b[:], millis(b[:])+1)
+				hi = 0
+			}
+		}
+		b[6], b[7] = byte(hi>>8), byte(hi)
+		binary.BigEndian.PutUint64(b[8:], counter)
+	} else {
+		if err := g.read(b[6:]); err != nil {
+			return "", err
+		}
+		putMillis(b[:], ms)
+	}
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	g.lastV7 = b
+	return formatUUID(b), nil
+}
+
+// ulid returns a ULID, monotonic within a millisecond.
+func (g *idGenerator) ulid() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().UnixMilli()
+	var b [16]byte
+	if ms <= millis(g.lastULID[:]) {
+		b = g.lastULID
+		if increment(b[6:]) {
+			return "", errors.New("ULID random component overflowed within one millisecond")
+		}
+	} else {
+		if err := g.read(b[6:]); err != nil {
+			return "", err
+		}
+		putMillis(b[:], ms)
+	}
+	g.lastULID = b
+	return encodeULID(b), nil
+}
+
+// nanoid returns a random string of the alphabet, drawing bytes with a mask and
+// rejecting those outside the alphabet so every character is equally likely.
+func (g *idGenerator) nanoid(size int, alphabet string) (string, error) {
+	chars := []rune(alphabet)
+	mask := 1
+	for mask < len(chars) {
+		mask <<= 1
+	}
+	mask--
+
+	out := make([]rune, 0, size)
+	buf := make([]byte, size*2)
+	for len(out) < size {
+		if err := g.read(buf); err != nil {
+			return "", err
+		}
+		for _, c := range buf {
+			if i := int(c) & mask; i < len(chars) {
+				out = append(out, chars[i])
+				if len(out) == size {
+					break
+				}
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// snowflake returns the next snowflake ID. When the sequence of a millisecond is used
+// up, it continues in the next one.
+func (g *idGenerator) snowflake() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().UnixMilli() - g.epoch
+	if ms < 0 {
+		return 0, errors.New("the clock is before the snowflake epoch")
+	}
+	if ms < g.lastMs {
+		// The clock went backwards; keep counting in the last millisecond
+		ms = g.lastMs
+	}
+	if ms == g.lastMs {
+		g.sequence++
+		if g.sequence > maxSequence {
+			// Borrow the next millisecond; later IDs keep counting there until the clock catches up
+			ms++
+			g.sequence = 0
+		}
+	} else {
+		g.sequence = 0
+	}
+	if ms >= 1<<41 {
+		return 0, errors.New("snowflake timestamp exceeds 41 bits")
+	}
+	g.lastMs = ms
+	return ms<<(machineBits+sequenceBits) | g.machineID<<sequenceBits | g.sequence, nil
+}
+
+func formatUUID(b [16]byte) string {
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// encodeULID encodes 128 bits as 26 Crockford base32 characters.
+func encodeULID(b [16]byte) string {
+	n := new(big.Int).SetBytes(b[:])
+	out := make([]byte, 26)
+	mod := new(big.Int)
+	base := big.NewInt(32)
+	for i := 25; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = crockford[mod.Int64()]
+	}
+	return string(out)
+}
+
+// decodeULID decodes a ULID, accepting lower case and the Crockford aliases I, L and O.
+func decodeULID(s string) ([16]byte, error) {
+	var b [16]byte
+	if len(s) != 26 {
+		return b, fmt.Errorf("a ULID has 26 characters, not %d", len(s))
+	}
+	s = strings.NewReplacer("I", "1", "L", "1", "O", "0").Replace(strings.ToUpper(s))
+	if s[0] > '7' {
+		return b, errors.New("a ULID's first character is at most 7")
+	}
+	n := new(big.Int)
+	for _, c := range s {
+		i := strings.IndexRune(crockford, c)
+		if i < 0 {
+			return b, fmt.Errorf("invalid ULID character %q", c)
+		}
+		n.Lsh(n, 5)
+		n.Or(n, big.NewInt(int64(i)))
+	}
+	n.FillBytes(b[:])
+	return b, nil
+}
+
+// parseUUID parses the canonical form, optionally wrapped in braces or prefixed with
+// "urn:uuid:".
+func parseUUID(s string) ([16]byte, error) {
+	var b [16]byte
+	s = strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return b, errors.New("a UUID has the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
+	}
+	raw, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return b, errors.New("a UUID consists of hexadecimal digits")
+	}
+	copy(b[:], raw)
+	return b, nil
+}
+
+// idInfo describes a validated identifier.
+type idInfo struct {
+	ID        string     `json:"id"`
+	Valid     bool       `json:"valid"`
+	Type      string     `json:"type,omitempty"`
+	Version   int        `json:"version,omitempty"`
+	Variant   string     `json:"variant,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	MachineID *int64     `json:"machineId,omitempty"`
+	Sequence  *int64     `json:"sequence,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// uuidVariant names the variant bits of byte 8.
+func uuidVariant(b byte) string {
+	switch {
+	case b&0x80 == 0:
+		return "NCS"
+	case b&0xc0 == 0x80:
+		return "RFC 9562"
+	case b&0xe0 == 0xc0:
+		return "Microsoft"
+	default:
+		return "future"
+	}
+}
+
+// inspectUUID validates a UUID and extracts the timestamp of versions 1, 6 and 7.
+func inspectUUID(s string) idInfo {
+	info := idInfo{ID: s, Type: "uuid"}
+	b, err := parseUUID(s)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Valid = true
+	if b == [16]byte{} {
+		info.Variant = "nil UUID"
+		return info
+	}
+	info.Version = int(b[6] >> 4)
+	info.Variant = uuidVariant(b[8])
+	if info.Variant != "RFC 9562" {
+		return info
+	}
+	var ts time.Time
+	switch info.Version {
+	case 1, 6:
+		// 60-bit count of 100ns intervals since 1582-10-15
+		var ticks uint64
+		if info.Version == 1 {
+			ticks = uint64(b[6]&0x0f)<<56 | uint64(b[7])<<48 | uint64(b[4])<<40 | uint64(b[5])<<32 |
+				uint64(binary.BigEndian.Uint32(b[0:4]))
+		} else {
+			ticks = uint64(binary.BigEndian.Uint32(b[0:4]))<<28 | uint64(binary.BigEndian.Uint16(b[4:6]))<<12 |
+				uint64(b[6]&0x0f)<<8 | uint64(b[7])
+		}
+		unix := int64(ticks) - gregorianOffset
+		ts = time.Unix(unix/1e7, unix%1e7*100).UTC()
+	case 7:
+		ts = time.UnixMilli(millis(b[:])).UTC()
+	default:
+		return info
+	}
+	info.Timestamp = &ts
+	return info
+}
+
+func inspectULID(s string) idInfo {
+	info := idInfo{ID: s, Type: "ulid"}
+	b, err := decodeULID(s)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Valid = true
+	ts := time.UnixMilli(millis(b[:])).UTC()
+	info.Timestamp = &ts
+	return info
+}
+
+func inspectSnowflake(s string, epoch int64) idInfo {
+	info := idInfo{ID: s, Type: "snowflake"}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n >= 1<<63 {
+		info.Error = "a snowflake ID is a positive 63-bit decimal number"
+		return info
+	}
+	info.Valid = true
+	ts := time.UnixMilli(int64(n>>(machineBits+sequenceBits)) + epoch).UTC()
+	machine := int64(n>>sequenceBits) & maxMachineID
+	sequence := int64(n) & maxSequence
+	info.Timestamp, info.MachineID, info.Sequence = &ts, &machine, &sequence
+	return info
+}
+
+func inspectNanoid(s string, size int, alphabet string) idInfo {
+	info := idInfo{ID: s, Type: "nanoid"}
+	if n := len([]rune(s)); n != size {
+		info.Error = fmt.Sprintf("expected %d characters, got %d", size, n)
+		return info
+	}
+	for _, c := range s {
+		if !strings.ContainsRune(alphabet, c) {
+			info.Error = fmt.Sprintf("character %q is not in the alphabet", c)
+			return info
+		}
+	}
+	info.Valid = true
+	return info
+}
+
+// detectType guesses the type of an identifier from its shape.
+func detectType(s string) string {
+	trimmed := strings.TrimPrefix(strings.ToLower(s), "urn:uuid:")
+	switch {
+	case len(trimmed) == 36 || (len(trimmed) == 38 && trimmed[0] == '{'):
+		return "uuid"
+	case len(s) == 26:
+		if _, err := decodeULID(s); err == nil {
+			return "ulid"
+		}
+	}
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil && len(s) >= 15 {
+		return "snowflake"
+	}
+	if len(s) == 21 {
+		return "nanoid"
+	}
+	return ""
+}
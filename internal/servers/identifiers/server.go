@@ -0,0 +1,373 @@
+package identifiers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// idTypes are the identifier types the server generates and validates.
+var idTypes = []string{"uuid4", "uuid7", "ulid", "nanoid", "snowflake"}
+
+// IdentifiersServer is an MCP server that generates and validates unique identifiers.
+type IdentifiersServer struct {
+	server   *server.MCPServer
+	gen      *idGenerator
+	maxCount int
+}
+
+// NewIdentifiersServer creates a new IdentifiersServer instance. The snowflake epoch is
+// in milliseconds since the Unix epoch.
+func NewIdentifiersServer(maxCount int, machineID, epoch int64) *IdentifiersServer {
+	log.Printf("IdentifiersServer created: maxCount=%d, machineID=%d, epoch=%d", maxCount, machineID, epoch)
+
+	s := &IdentifiersServer{
+		gen: &idGenerator{
+			now:       time.Now,
+			random:    rand.Reader,
+			machineID: machineID,
+			epoch:     epoch,
+		},
+		maxCount: maxCount,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"identifiers-server", // server name
+		"1.0.0",              // version
+	)
+
+	// Register generateIds tool
+	generateIdsTool := mcp.NewTool("generateIds",
+		mcp.WithDescription("Generates unique identifiers: random UUIDv4, time ordered UUIDv7 and ULID, nanoid and "+
+			"snowflake IDs. Time ordered IDs generated in one batch are strictly increasing"),
+		mcp.WithString("type",
+			mcp.Description("Identifier type (default: uuid4)"),
+			mcp.Enum(idTypes...),
+		),
+		mcp.WithNumber("count",
+			mcp.Description(fmt.Sprintf("Number of identifiers (default: 1, maximum: %d)", maxCount)),
+		),
+		mcp.WithNumber("size",
+			mcp.Description("Length of a nanoid (default: 21)"),
+		),
+		mcp.WithString("alphabet",
+			mcp.Description("Characters of a nanoid (default: A-Za-z0-9_-)"),
+		),
+		mcp.WithBoolean("uppercase",
+			mcp.Description("Print UUIDs in upper case (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text, one identifier per line)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register validateId tool
+	validateIdTool := mcp.NewTool("validateId",
+		mcp.WithDescription("Validates an identifier and reports its type, UUID version and variant, and for sortable "+
+			"IDs (UUIDv1/v6/v7, ULID, snowflake) the embedded timestamp. The type is detected when omitted"),
+		mcp.WithString("id",
+			mcp.Description("Identifier to validate"),
+			mcp.Required(),
+		),
+		mcp.WithString("type",
+			mcp.Description("Expected identifier type"),
+			mcp.Enum("uuid", "ulid", "nanoid", "snowflake"),
+		),
+		mcp.WithString("epoch",
+			mcp.Description(fmt.Sprintf("Snowflake epoch: twitter, discord or milliseconds since the Unix epoch (default: %d)", epoch)),
+		),
+		mcp.WithNumber("size",
+			mcp.Description("Expected length of a nanoid (default: 21)"),
+		),
+		mcp.WithString("alphabet",
+			mcp.Description("Characters of a nanoid (default: A-Za-z0-9_-)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, generateIdsTool, s.handleGenerateIds)
+	middleware.AddTool(mcpServer, validateIdTool, s.handleValidateId)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// parseEpoch resolves a named epoch or a number of milliseconds.
+func parseEpoch(value string) (int64, error) {
+	if ms, ok := snowflakeEpochs[strings.ToLower(value)]; ok {
+		return ms, nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("invalid epoch %q; use twitter, discord or milliseconds since the Unix epoch", value)
+	}
+	return ms, nil
+}
+
+// nanoidOptions applies the nanoid defaults and validates size and alphabet.
+func nanoidOptions(size int, alphabet string) (int, string, error) {
+	if size == 0 {
+		size = 21
+	}
+	if alphabet == "" {
+		alphabet = nanoidAlphabet
+	}
+	if size < 2 || size > 256 {
+		return 0, "", fmt.Errorf("size must be between 2 and 256")
+	}
+	seen := make(map[rune]bool)
+	for _, c := range alphabet {
+		if seen[c] {
+			return 0, "", fmt.Errorf("alphabet contains %q twice", c)
+		}
+		seen[c] = true
+	}
+	if len(seen) < 2 || len(seen) > 256 {
+		return 0, "", fmt.Errorf("alphabet must have between 2 and 256 characters")
+	}
+	return size, alphabet, nil
+}
+
+// handleGenerateIds handles the identifier generation request.
+func (s *IdentifiersServer) handleGenerateIds(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generate IDs request processing")
+
+	var args struct {
+		Type      string `json:"type"`
+		Count     int    `json:"count"`
+		Size      int    `json:"size"`
+		Alphabet  string `json:"alphabet"`
+		Uppercase bool   `json:"uppercase"`
+		Format    string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Type == "" {
+		args.Type = "uuid4"
+	}
+	if args.Count == 0 {
+		args.Count = 1
+	}
+	if args.Count < 0 || args.Count > s.maxCount {
+		log.Printf("Error: Invalid count: %d", args.Count)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "count must be between 1 and %d", s.maxCount)
+	}
+
+	var next func() (string, error)
+	switch args.Type {
+	case "uuid4":
+		next = s.gen.uuid4
+	case "uuid7":
+		next = s.gen.uuid7
+	case "ulid":
+		next = s.gen.ulid
+	case "nanoid":
+		size, alphabet, err := nanoidOptions(args.Size, args.Alphabet)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		next = func() (string, error) { return s.gen.nanoid(size, alphabet) }
+	case "snowflake":
+		next = func() (string, error) {
+			id, err := s.gen.snowflake()
+			return strconv.FormatInt(id, 10), err
+		}
+	default:
+		log.Printf("Error: Invalid type: %s", args.Type)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid type %q; use %s", args.Type, strings.Join(idTypes, ", "))
+	}
+
+	ids := make([]string, args.Count)
+	for i := range ids {
+		id, err := next()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		if args.Uppercase && strings.HasPrefix(args.Type, "uuid") {
+			id = strings.ToUpper(id)
+		}
+		ids[i] = id
+	}
+
+	log.Printf("Generate IDs request completed: type=%s, count=%d", args.Type, args.Count)
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(ids, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+		return textResult(string(data)), nil
+	}
+	return textResult(strings.Join(ids, "\n")), nil
+}
+
+// handleValidateId handles the identifier validation request.
+func (s *IdentifiersServer) handleValidateId(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting validate ID request processing")
+
+	var args struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Epoch    string `json:"epoch"`
+		Size     int    `json:"size"`
+		Alphabet string `json:"alphabet"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	args.ID = strings.TrimSpace(args.ID)
+	if args.ID == "" {
+		log.Println("Error: Empty ID")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "id is required")
+	}
+
+	epoch := s.gen.epoch
+	if args.Epoch != "" {
+		var err error
+		if epoch, err = parseEpoch(args.Epoch); err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+	}
+	size, alphabet, err := nanoidOptions(args.Size, args.Alphabet)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	idType := args.Type
+	if idType == "" {
+		idType = detectType(args.ID)
+	}
+	var info idInfo
+	switch idType {
+	case "uuid":
+		info = inspectUUID(args.ID)
+	case "ulid":
+		info = inspectULID(args.ID)
+	case "snowflake":
+		info = inspectSnowflake(args.ID, epoch)
+	case "nanoid":
+		info = inspectNanoid(args.ID, size, alphabet)
+	case "":
+		info = idInfo{ID: args.ID, Error: "unrecognized identifier format"}
+	default:
+		log.Printf("Error: Invalid type: %s", args.Type)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid type %q; use uuid, ulid, nanoid or snowflake", args.Type)
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	log.Printf("Validate ID request completed: type=%s, valid=%t", info.Type, info.Valid)
+	return textResult(string(data)), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *IdentifiersServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the identifiers server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("identifiers", flag.ContinueOnError)
+	var (
+		maxCount       int
+		machineID      int64
+		snowflakeEpoch string
+	)
+	fs.IntVar(&maxCount, "max-count", 1000, "Maximum number of identifiers per request")
+	fs.Int64Var(&machineID, "machine-id", 0, fmt.Sprintf("Machine ID of generated snowflake IDs (0-%d)", maxMachineID))
+	fs.StringVar(&snowflakeEpoch, "snowflake-epoch", "twitter", "Snowflake epoch: twitter, discord or milliseconds since the Unix epoch")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting identifiers server: max-count=%d, machine-id=%d, snowflake-epoch=%s", maxCount, machineID, snowflakeEpoch)
+
+	if machineID < 0 || machineID > maxMachineID {
+		log.Printf("Error: Machine ID must be between 0 and %d", maxMachineID)
+		return nil, transport.Flags{}, fmt.Errorf("machine ID must be between 0 and %d", maxMachineID)
+	}
+	epoch, err := parseEpoch(snowflakeEpoch)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create IdentifiersServer instance
+	identifiersServer := NewIdentifiersServer(maxCount, machineID, epoch)
+	log.Println("IdentifiersServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), identifiersServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return identifiersServer.Server(), transportFlags, nil
+}
+
+// Run starts the identifiers server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[IdentifiersServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create identifiers server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("IdentifiersServer shutdown")
+	return nil
+}
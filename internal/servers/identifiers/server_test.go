@@ -0,0 +1,282 @@
+package identifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestServer() *IdentifiersServer {
+	s := NewIdentifiersServer(100, 7, snowflakeEpochs["twitter"])
+	s.gen.now = func() time.Time { return testTime }
+	return s
+}
+
+func generate(t *testing.T, s *IdentifiersServer, args map[string]interface{}) []string {
+	t.Helper()
+	result, err := s.handleGenerateIds(context.Background(), mcptest.NewCallToolRequest("generateIds", args))
+	require.NoError(t, err)
+	return strings.Split(mcptest.ResultText(result), "\n")
+}
+
+func validate(t *testing.T, s *IdentifiersServer, args map[string]interface{}) idInfo {
+	t.Helper()
+	result, err := s.handleValidateId(context.Background(), mcptest.NewCallToolRequest("validateId", args))
+	require.NoError(t, err)
+	var info idInfo
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &info))
+	return info
+}
+
+// IdentifiersServer creation test
+func TestNewIdentifiersServer(t *testing.T) {
+	s := NewIdentifiersServer(500, 3, 0)
+
+	assert.NotNil(t, s, "IdentifiersServer instance should be created")
+	assert.Equal(t, 500, s.maxCount, "Max count should match")
+	assert.Equal(t, int64(3), s.gen.machineID, "Machine ID should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewIdentifiersServer(100, 0, 0)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test that generated IDs validate as their type, with the generation time
+func TestGenerateAndValidate(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		idType    string
+		check     string
+		version   int
+		timestamp bool
+	}{
+		{"uuid4", "uuid", 4, false},
+		{"uuid7", "uuid", 7, true},
+		{"ulid", "ulid", 0, true},
+		{"snowflake", "snowflake", 0, true},
+		{"nanoid", "nanoid", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idType, func(t *testing.T) {
+			ids := generate(t, s, map[string]interface{}{"type": tt.idType, "count": 20})
+			require.Len(t, ids, 20)
+			for _, id := range ids {
+				info := validate(t, s, map[string]interface{}{"id": id})
+				assert.True(t, info.Valid, "%s: %s", id, info.Error)
+				assert.Equal(t, tt.check, info.Type, id)
+				assert.Equal(t, tt.version, info.Version, id)
+				if tt.timestamp {
+					require.NotNil(t, info.Timestamp, id)
+					assert.Equal(t, testTime, info.Timestamp.UTC(), id)
+				}
+			}
+		})
+	}
+}
+
+// Test that time ordered IDs increase within one millisecond
+func TestMonotonic(t *testing.T) {
+	s := newTestServer()
+
+	for _, idType := range []string{"uuid7", "ulid"} {
+		ids := generate(t, s, map[string]interface{}{"type": idType, "count": 100})
+		assert.True(t, sort.StringsAreSorted(ids), idType)
+		assert.Len(t, unique(ids), 100, idType)
+	}
+
+	ids := generate(t, s, map[string]interface{}{"type": "snowflake", "count": 100})
+	for i := 1; i < len(ids); i++ {
+		prev, _ := strconv.ParseInt(ids[i-1], 10, 64)
+		cur, _ := strconv.ParseInt(ids[i], 10, 64)
+		assert.Greater(t, cur, prev)
+	}
+	info := validate(t, s, map[string]interface{}{"id": ids[99]})
+	assert.Equal(t, int64(7), *info.MachineID)
+	assert.Equal(t, int64(99), *info.Sequence)
+}
+
+// Test that an exhausted snowflake sequence moves to the next millisecond
+func TestSnowflakeSequenceOverflow(t *testing.T) {
+	s := newTestServer()
+	for i := 0; i <= maxSequence; i++ {
+		_, err := s.gen.snowflake()
+		require.NoError(t, err)
+	}
+	id, err := s.gen.snowflake()
+	require.NoError(t, err)
+	info := inspectSnowflake(strconv.FormatInt(id, 10), s.gen.epoch)
+	assert.Equal(t, testTime.Add(time.Millisecond), *info.Timestamp)
+	assert.Equal(t, int64(0), *info.Sequence)
+}
+
+func unique(ids []string) map[string]bool {
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return seen
+}
+
+// Test nanoid size and alphabet
+func TestNanoid(t *testing.T) {
+	s := newTestServer()
+
+	ids := generate(t, s, map[string]interface{}{"type": "nanoid", "count": 50, "size": 10, "alphabet": "abc"})
+	for _, id := range ids {
+		assert.Regexp(t, `^[abc]{10}$`, id)
+	}
+	assert.Greater(t, len(unique(ids)), 40)
+
+	info := validate(t, s, map[string]interface{}{"id": ids[0], "type": "nanoid", "size": 10, "alphabet": "abc"})
+	assert.True(t, info.Valid)
+	info = validate(t, s, map[string]interface{}{"id": "abcd", "type": "nanoid", "size": 4, "alphabet": "abc"})
+	assert.False(t, info.Valid)
+	assert.Contains(t, info.Error, "not in the alphabet")
+}
+
+// Test generation with a deterministic random source
+func TestUUID4Format(t *testing.T) {
+	g := &idGenerator{now: time.Now, random: bytes.NewReader(bytes.Repeat([]byte{0xff}, 16))}
+	id, err := g.uuid4()
+	require.NoError(t, err)
+	assert.Equal(t, "ffffffff-ffff-4fff-bfff-ffffffffffff", id)
+
+	s := newTestServer()
+	ids := generate(t, s, map[string]interface{}{"type": "uuid4", "uppercase": true})
+	assert.Equal(t, strings.ToUpper(ids[0]), ids[0])
+}
+
+// Test validation of known identifiers
+func TestValidateKnownIDs(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		idType    string
+		valid     bool
+		version   int
+		timestamp string
+	}{
+		{
+			name:      "uuid v7 from RFC 9562",
+			args:      map[string]interface{}{"id": "017F22E2-79B0-7CC3-98C4-DC0C0C07398F"},
+			idType:    "uuid",
+			valid:     true,
+			version:   7,
+			timestamp: "2022-02-22T19:22:22Z",
+		},
+		{
+			name:      "uuid v1 from RFC 9562",
+			args:      map[string]interface{}{"id": "urn:uuid:C232AB00-9414-11EC-B3C8-9F6BDECED846"},
+			idType:    "uuid",
+			valid:     true,
+			version:   1,
+			timestamp: "2022-02-22T19:22:22Z",
+		},
+		{
+			name:      "uuid v6 from RFC 9562",
+			args:      map[string]interface{}{"id": "{1EC9414C-232A-6B00-B3C8-9F6BDECED846}"},
+			idType:    "uuid",
+			valid:     true,
+			version:   6,
+			timestamp: "2022-02-22T19:22:22Z",
+		},
+		{
+			name:      "ulid",
+			args:      map[string]interface{}{"id": "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+			idType:    "ulid",
+			valid:     true,
+			timestamp: "2016-07-30T23:54:10.259Z",
+		},
+		{
+			name:      "discord snowflake",
+			args:      map[string]interface{}{"id": "175928847299117063", "epoch": "discord"},
+			idType:    "snowflake",
+			valid:     true,
+			timestamp: "2016-04-30T11:18:25.796Z",
+		},
+		{
+			name:   "uuid with bad digits",
+			args:   map[string]interface{}{"id": "017f22e2-79b0-7cc3-98c4-dc0c0c07398g"},
+			idType: "uuid",
+		},
+		{
+			name:   "ulid overflow",
+			args:   map[string]interface{}{"id": "81ARZ3NDEKTSV4RRFFQ69G5FAV", "type": "ulid"},
+			idType: "ulid",
+		},
+		{
+			name: "unrecognized",
+			args: map[string]interface{}{"id": "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := validate(t, s, tt.args)
+			assert.Equal(t, tt.idType, info.Type)
+			assert.Equal(t, tt.valid, info.Valid, info.Error)
+			assert.Equal(t, tt.version, info.Version)
+			if tt.timestamp != "" {
+				want, err := time.Parse(time.RFC3339Nano, tt.timestamp)
+				require.NoError(t, err)
+				require.NotNil(t, info.Timestamp)
+				assert.Equal(t, want, info.Timestamp.UTC())
+			} else if !tt.valid {
+				assert.NotEmpty(t, info.Error)
+			}
+		})
+	}
+}
+
+// Test error cases
+func TestErrors(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"unknown type", "generateIds", map[string]interface{}{"type": "guid"}, "invalid type"},
+		{"count too large", "generateIds", map[string]interface{}{"count": 101}, "count must be between"},
+		{"duplicate alphabet", "generateIds", map[string]interface{}{"type": "nanoid", "alphabet": "aab"}, "twice"},
+		{"size too small", "generateIds", map[string]interface{}{"type": "nanoid", "size": 1}, "size must be"},
+		{"empty id", "validateId", map[string]interface{}{"id": " "}, "id is required"},
+		{"bad epoch", "validateId", map[string]interface{}{"id": "1", "epoch": "mars"}, "invalid epoch"},
+		{"bad validate type", "validateId", map[string]interface{}{"id": "1", "type": "guid"}, "invalid type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			req := mcptest.NewCallToolRequest(tt.tool, tt.args)
+			if tt.tool == "generateIds" {
+				_, err = s.handleGenerateIds(context.Background(), req)
+			} else {
+				_, err = s.handleValidateId(context.Background(), req)
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, newTestServer().Server())
+}
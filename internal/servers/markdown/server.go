@@ -0,0 +1,380 @@
+package markdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/pkg/markdown"
+)
+
+// MarkdownServer is an MCP server for rendering, converting and checking Markdown.
+type MarkdownServer struct {
+	server       *server.MCPServer
+	maxInputSize int
+}
+
+// NewMarkdownServer creates a new MarkdownServer instance
+func NewMarkdownServer(maxInputSize int) *MarkdownServer {
+	log.Printf("MarkdownServer created: maxInputSize=%d", maxInputSize)
+
+	s := &MarkdownServer{
+		maxInputSize: maxInputSize,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"markdown-server", // server name
+		"1.0.0",           // version
+	)
+
+	// Register renderMarkdown tool
+	renderTool := mcp.NewTool("renderMarkdown",
+		mcp.WithDescription("Renders GitHub Flavored Markdown as HTML. Headings get the same anchor IDs as on GitHub"),
+		mcp.WithString("markdown",
+			mcp.Description("Markdown source"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("unsafeHTML",
+			mcp.Description("Keep raw HTML and javascript: links from the source (default: false)"),
+		),
+		mcp.WithBoolean("hardWraps",
+			mcp.Description("Render every newline in a paragraph as a line break (default: false)"),
+		),
+		mcp.WithBoolean("fullDocument",
+			mcp.Description("Wrap the output in a complete HTML document (default: false)"),
+		),
+		mcp.WithString("title",
+			mcp.Description("Title of the full document (default: the first level 1 heading)"),
+		),
+	)
+
+	// Register htmlToMarkdown tool
+	convertTool := mcp.NewTool("htmlToMarkdown",
+		mcp.WithDescription("Converts HTML to GitHub Flavored Markdown. Scripts, styles, forms and navigation are dropped"),
+		mcp.WithString("html",
+			mcp.Description("HTML document or fragment"),
+			mcp.Required(),
+		),
+		mcp.WithString("baseURL",
+			mcp.Description("URL to resolve relative links and images against"),
+		),
+	)
+
+	// Register tableOfContents tool
+	tocTool := mcp.NewTool("tableOfContents",
+		mcp.WithDescription("Extracts the table of contents of a Markdown document"),
+		mcp.WithString("markdown",
+			mcp.Description("Markdown source"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("minLevel",
+			mcp.Description("Lowest heading level to include (default: 1)"),
+		),
+		mcp.WithNumber("maxLevel",
+			mcp.Description("Highest heading level to include (default: 6)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("markdown (default) for a nested list of links, or json for the headings with levels, anchors and line numbers"),
+			mcp.Enum("markdown", "json"),
+		),
+	)
+
+	// Register lintMarkdown tool
+	lintTool := mcp.NewTool("lintMarkdown",
+		mcp.WithDescription("Checks the structure of a Markdown document: heading order and duplicates, broken #fragment links, missing alt text, code fence languages, list markers and whitespace"),
+		mcp.WithString("markdown",
+			mcp.Description("Markdown source"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxLineLength",
+			mcp.Description("Report lines longer than this outside code blocks (default: no limit)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, renderTool, s.handleRenderMarkdown)
+	middleware.AddTool(mcpServer, convertTool, s.handleHTMLToMarkdown)
+	middleware.AddTool(mcpServer, tocTool, s.handleTableOfContents)
+	middleware.AddTool(mcpServer, lintTool, s.handleLintMarkdown)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// checkInput validates the size of the input document.
+func (s *MarkdownServer) checkInput(name, input string) error {
+	if input == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	if len(input) > s.maxInputSize {
+		return fmt.Errorf("%s exceeds the maximum size of %d bytes", name, s.maxInputSize)
+	}
+	return nil
+}
+
+// handleRenderMarkdown handles the Markdown to HTML request.
+func (s *MarkdownServer) handleRenderMarkdown(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting renderMarkdown request processing")
+
+	var args struct {
+		Markdown     string `json:"markdown"`
+		UnsafeHTML   bool   `json:"unsafeHTML,omitempty"`
+		HardWraps    bool   `json:"hardWraps,omitempty"`
+		FullDocument bool   `json:"fullDocument,omitempty"`
+		Title        string `json:"title,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkInput("markdown", args.Markdown); err != nil {
+		return nil, err
+	}
+
+	source := []byte(args.Markdown)
+	body, err := markdown.ToHTML(source, markdown.HTMLOptions{
+		Unsafe:    args.UnsafeHTML,
+		HardWraps: args.HardWraps,
+	})
+	if err != nil {
+		log.Printf("Error: Failed to render Markdown: %v", err)
+		return nil, fmt.Errorf("failed to render Markdown: %w", err)
+	}
+
+	if args.FullDocument {
+		title := args.Title
+		if title == "" {
+			for _, h := range markdown.Headings(source) {
+				if h.Level == 1 {
+					title = h.Text
+					break
+				}
+			}
+		}
+		body = fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s</body>\n</html>\n",
+			html.EscapeString(title), body)
+	}
+
+	log.Printf("renderMarkdown request completed: %d bytes of HTML", len(body))
+	return textResult(body), nil
+}
+
+// handleHTMLToMarkdown handles the HTML to Markdown request.
+func (s *MarkdownServer) handleHTMLToMarkdown(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting htmlToMarkdown request processing")
+
+	var args struct {
+		HTML    string `json:"html"`
+		BaseURL string `json:"baseURL,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkInput("html", args.HTML); err != nil {
+		return nil, err
+	}
+
+	text, err := markdown.FromHTML(args.HTML, markdown.ConvertOptions{BaseURL: args.BaseURL})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if text == "" {
+		text = "The HTML has no text content"
+	}
+
+	log.Printf("htmlToMarkdown request completed: %d bytes of Markdown", len(text))
+	return textResult(text), nil
+}
+
+// handleTableOfContents handles the table of contents request.
+func (s *MarkdownServer) handleTableOfContents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting tableOfContents request processing")
+
+	var args struct {
+		Markdown string `json:"markdown"`
+		MinLevel int    `json:"minLevel,omitempty"`
+		MaxLevel int    `json:"maxLevel,omitempty"`
+		Format   string `json:"format,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkInput("markdown", args.Markdown); err != nil {
+		return nil, err
+	}
+	if args.MinLevel == 0 {
+		args.MinLevel = 1
+	}
+	if args.MaxLevel == 0 {
+		args.MaxLevel = 6
+	}
+	if args.MinLevel < 1 || args.MaxLevel > 6 || args.MinLevel > args.MaxLevel {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid heading levels %d-%d: levels range from 1 to 6", args.MinLevel, args.MaxLevel)
+	}
+
+	var headings []markdown.Heading
+	for _, h := range markdown.Headings([]byte(args.Markdown)) {
+		if h.Level >= args.MinLevel && h.Level <= args.MaxLevel {
+			headings = append(headings, h)
+		}
+	}
+
+	var text string
+	switch args.Format {
+	case "", "markdown":
+		text = markdown.TOC(headings, args.MinLevel, args.MaxLevel)
+		if text == "" {
+			text = "The document has no headings"
+		}
+	case "json":
+		if headings == nil {
+			headings = []markdown.Heading{}
+		}
+		data, err := json.MarshalIndent(headings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode headings: %w", err)
+		}
+		text = string(data)
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format: %s", args.Format)
+	}
+
+	log.Printf("tableOfContents request completed: %d headings", len(headings))
+	return textResult(strings.TrimRight(text, "\n")), nil
+}
+
+// handleLintMarkdown handles the lint request.
+func (s *MarkdownServer) handleLintMarkdown(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting lintMarkdown request processing")
+
+	var args struct {
+		Markdown      string `json:"markdown"`
+		MaxLineLength int    `json:"maxLineLength,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.checkInput("markdown", args.Markdown); err != nil {
+		return nil, err
+	}
+	if args.MaxLineLength < 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "maxLineLength must not be negative")
+	}
+
+	issues := markdown.Lint([]byte(args.Markdown), markdown.LintOptions{MaxLineLength: args.MaxLineLength})
+
+	var b strings.Builder
+	switch len(issues) {
+	case 0:
+		b.WriteString("No issues found")
+	case 1:
+		b.WriteString("1 issue found:")
+	default:
+		fmt.Fprintf(&b, "%d issues found:", len(issues))
+	}
+	for _, issue := range issues {
+		b.WriteString("\n" + issue.String())
+	}
+
+	log.Printf("lintMarkdown request completed: %d issues", len(issues))
+	return textResult(b.String()), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *MarkdownServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the markdown server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("markdown", flag.ContinueOnError)
+	var maxInputSize int
+	fs.IntVar(&maxInputSize, "max-input-size", 2*1024*1024, "Maximum size of an input document in bytes (default 2MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting markdown server: maxInputSize=%d", maxInputSize)
+
+	// Create MarkdownServer instance
+	markdownServer := NewMarkdownServer(maxInputSize)
+	log.Println("MarkdownServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), markdownServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return markdownServer.Server(), transportFlags, nil
+}
+
+// Run starts the markdown server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[MarkdownServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create markdown server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("MarkdownServer shutdown")
+	return nil
+}
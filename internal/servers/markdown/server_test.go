@@ -0,0 +1,363 @@
+package markdown
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MarkdownServer creation test
+func TestNewMarkdownServer(t *testing.T) {
+	s := NewMarkdownServer(1024)
+
+	assert.NotNil(t, s, "MarkdownServer instance should be created")
+	assert.Equal(t, 1024, s.maxInputSize, "Max input size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewMarkdownServer(1024)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test the renderMarkdown tool
+func TestHandleRenderMarkdown(t *testing.T) {
+	s := NewMarkdownServer(4096)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		args     map[string]interface{}
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "Headings get GitHub anchors",
+			args: map[string]interface{}{"markdown": "# Hello, World!\n\n## Hello, World!\n\n## `code` & more\n"},
+			contains: []string{
+				`<h1 id="hello-world">Hello, World!</h1>`,
+				`<h2 id="hello-world-1">Hello, World!</h2>`,
+				`<h2 id="code--more"><code>code</code> &amp; more</h2>`,
+			},
+		},
+		{
+			name: "GFM tables, task lists and strikethrough",
+			args: map[string]interface{}{"markdown": "| a | b |\n|---|--:|\n| 1 | 2 |\n\n- [x] done\n- [ ] todo\n\n~~gone~~ https://example.com\n"},
+			contains: []string{
+				"<table>",
+				`<td style="text-align:right">2</td>`,
+				`<input checked="" disabled="" type="checkbox"`,
+				"<del>gone</del>",
+				`<a href="https://example.com">https://example.com</a>`,
+			},
+		},
+		{
+			name:     "Raw HTML is omitted by default",
+			args:     map[string]interface{}{"markdown": "<script>alert(1)</script>\n\n[x](javascript:alert(1))\n"},
+			contains: []string{"<!-- raw HTML omitted -->", `<a href="">x</a>`},
+			excludes: []string{"<script>", "javascript:"},
+		},
+		{
+			name:     "Unsafe HTML is kept on request",
+			args:     map[string]interface{}{"markdown": "<div class=\"note\">hi</div>\n", "unsafeHTML": true},
+			contains: []string{`<div class="note">hi</div>`},
+		},
+		{
+			name:     "Hard wraps",
+			args:     map[string]interface{}{"markdown": "one\ntwo\n", "hardWraps": true},
+			contains: []string{"one<br>\ntwo"},
+		},
+		{
+			name:     "Full document takes the title from the first h1",
+			args:     map[string]interface{}{"markdown": "intro\n\n# Guide & more\n", "fullDocument": true},
+			contains: []string{"<!DOCTYPE html>", "<title>Guide &amp; more</title>", "<body>\n<p>intro</p>"},
+		},
+		{
+			name:     "Full document with an explicit title",
+			args:     map[string]interface{}{"markdown": "# Guide\n", "fullDocument": true, "title": "Manual"},
+			contains: []string{"<title>Manual</title>"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := s.handleRenderMarkdown(ctx, mcptest.NewCallToolRequest("renderMarkdown", tc.args))
+			require.NoError(t, err)
+			text := mcptest.ResultText(result)
+			for _, want := range tc.contains {
+				assert.Contains(t, text, want)
+			}
+			for _, unwanted := range tc.excludes {
+				assert.NotContains(t, text, unwanted)
+			}
+		})
+	}
+
+	_, err := s.handleRenderMarkdown(ctx, mcptest.NewCallToolRequest("renderMarkdown", map[string]interface{}{
+		"markdown": strings.Repeat("a", 5000),
+	}))
+	assert.ErrorContains(t, err, "maximum size")
+
+	_, err = s.handleRenderMarkdown(ctx, mcptest.NewCallToolRequest("renderMarkdown", map[string]interface{}{}))
+	assert.ErrorContains(t, err, "markdown is required")
+}
+
+// Test the htmlToMarkdown tool
+func TestHandleHTMLToMarkdown(t *testing.T) {
+	s := NewMarkdownServer(1 << 16)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		html     string
+		baseURL  string
+		expected string
+	}{
+		{
+			name:     "Headings and inline formatting",
+			html:     "<h1>Title</h1><p>Some <strong>bold</strong>, <em>italic</em> and <code>co`de</code> text.</p>",
+			expected: "# Title\n\nSome **bold**, *italic* and ``co`de`` text.\n",
+		},
+		{
+			name:     "Whitespace is collapsed and Markdown characters escaped",
+			html:     "<p>  a   *star*\n  and_under [x]  </p>",
+			expected: "a \\*star\\* and\\_under \\[x\\]\n",
+		},
+		{
+			name:     "Block syntax at the start of a line is escaped",
+			html:     "<p># not a heading<br>1. not a list<br>- nor this</p>",
+			expected: "\\# not a heading\\\n1\\. not a list\\\n\\- nor this\n",
+		},
+		{
+			name:     "Links and images resolve against the base URL",
+			html:     `<p><a href="/docs" title="The docs">Docs</a> <a href="https://x.org">https://x.org</a> <img src="img/a.png" alt="A"> <a href="javascript:void(0)">JS</a></p>`,
+			baseURL:  "https://example.com/guide/",
+			expected: "[Docs](https://example.com/docs \"The docs\") <https://x.org> ![A](https://example.com/guide/img/a.png) JS\n",
+		},
+		{
+			name:     "Base element takes precedence",
+			html:     `<html><head><base href="https://cdn.example.com/"><title>T</title></head><body><a href="p">P</a></body></html>`,
+			baseURL:  "https://example.com/",
+			expected: "[P](https://cdn.example.com/p)\n",
+		},
+		{
+			name:     "Nested lists",
+			html:     "<ul><li>one<ul><li>nested</li></ul></li><li>two</li></ul><ol start=\"3\"><li>three<li>four</ol>",
+			expected: "- one\n  - nested\n- two\n\n3. three\n4. four\n",
+		},
+		{
+			name:     "Loose list items",
+			html:     "<ul><li><p>first</p><p>more</p></li><li><p>second</p></li></ul>",
+			expected: "- first\n\n  more\n\n- second\n",
+		},
+		{
+			name:     "Task list",
+			html:     `<ul><li><input type="checkbox" checked> done</li><li><input type="checkbox"> todo</li></ul>`,
+			expected: "- [x] done\n- [ ] todo\n",
+		},
+		{
+			name:     "Code block keeps whitespace and language",
+			html:     "<pre><code class=\"language-go\">func main() {\n\n\tfmt.Println(\"&lt;hi&gt;\")\n}\n</code></pre>",
+			expected: "```go\nfunc main() {\n\n\tfmt.Println(\"<hi>\")\n}\n```\n",
+		},
+		{
+			name:     "Code block in a list item",
+			html:     "<ol><li>Run:<pre>make\n\nmake test</pre></li></ol>",
+			expected: "1. Run:\n   ```\n   make\n\n   make test\n   ```\n",
+		},
+		{
+			name:     "Blockquote",
+			html:     "<blockquote><p>quoted</p><p>twice</p></blockquote>",
+			expected: "> quoted\n>\n> twice\n",
+		},
+		{
+			name:     "Table with alignment",
+			html:     `<table><thead><tr><th>Name</th><th align="right">Qty</th></tr></thead><tbody><tr><td>a|b</td><td>1</td></tr><tr><td>c</td></tr></tbody></table>`,
+			expected: "| Name | Qty |\n| --- | --: |\n| a\\|b | 1 |\n| c |  |\n",
+		},
+		{
+			name:     "Scripts, styles and forms are dropped",
+			html:     "<style>p{}</style><p>text<script>alert('<p>')</script></p><form><input name=q><button>Go</button></form><nav>menu</nav>",
+			expected: "text\n",
+		},
+		{
+			name:     "Unclosed paragraphs and entities",
+			html:     "<p>one &amp; two<p>three &copy;<hr>",
+			expected: "one & two\n\nthree ©\n\n---\n",
+		},
+		{
+			name:     "Data images become their alt text",
+			html:     `<p><img src="data:image/png;base64,AAAA" alt="logo"></p>`,
+			expected: "logo\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]interface{}{"html": tc.html}
+			if tc.baseURL != "" {
+				args["baseURL"] = tc.baseURL
+			}
+			result, err := s.handleHTMLToMarkdown(ctx, mcptest.NewCallToolRequest("htmlToMarkdown", args))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mcptest.ResultText(result))
+		})
+	}
+
+	result, err := s.handleHTMLToMarkdown(ctx, mcptest.NewCallToolRequest("htmlToMarkdown", map[string]interface{}{
+		"html": "<script>only()</script>",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "The HTML has no text content", mcptest.ResultText(result))
+
+	_, err = s.handleHTMLToMarkdown(ctx, mcptest.NewCallToolRequest("htmlToMarkdown", map[string]interface{}{
+		"html":    "<p>x</p>",
+		"baseURL": "http://[::1",
+	}))
+	assert.ErrorContains(t, err, "invalid base URL")
+}
+
+// Test the tableOfContents tool
+func TestHandleTableOfContents(t *testing.T) {
+	s := NewMarkdownServer(4096)
+	ctx := context.Background()
+
+	doc := "# Guide\n\n## Install\n\n### From [source](https://x.org)\n\n## Usage\n\n```\n# not a heading\n```\n\nSetext\n------\n\n#### Deep\n"
+
+	result, err := s.handleTableOfContents(ctx, mcptest.NewCallToolRequest("tableOfContents", map[string]interface{}{
+		"markdown": doc,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "- [Guide](#guide)\n  - [Install](#install)\n    - [From source](#from-source)\n  - [Usage](#usage)\n  - [Setext](#setext)\n    - [Deep](#deep)", mcptest.ResultText(result))
+
+	result, err = s.handleTableOfContents(ctx, mcptest.NewCallToolRequest("tableOfContents", map[string]interface{}{
+		"markdown": doc,
+		"minLevel": 2,
+		"maxLevel": 2,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "- [Install](#install)\n- [Usage](#usage)\n- [Setext](#setext)", mcptest.ResultText(result))
+
+	result, err = s.handleTableOfContents(ctx, mcptest.NewCallToolRequest("tableOfContents", map[string]interface{}{
+		"markdown": doc,
+		"format":   "json",
+		"maxLevel": 2,
+	}))
+	require.NoError(t, err)
+	var headings []struct {
+		Level int    `json:"level"`
+		Text  string `json:"text"`
+		ID    string `json:"id"`
+		Line  int    `json:"line"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &headings))
+	require.Len(t, headings, 4)
+	assert.Equal(t, 1, headings[0].Line)
+	assert.Equal(t, "usage", headings[2].ID)
+	assert.Equal(t, 7, headings[2].Line)
+	assert.Equal(t, 2, headings[3].Level)
+	assert.Equal(t, 13, headings[3].Line)
+
+	result, err = s.handleTableOfContents(ctx, mcptest.NewCallToolRequest("tableOfContents", map[string]interface{}{
+		"markdown": "just text\n",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "The document has no headings", mcptest.ResultText(result))
+
+	_, err = s.handleTableOfContents(ctx, mcptest.NewCallToolRequest("tableOfContents", map[string]interface{}{
+		"markdown": doc,
+		"minLevel": 4,
+		"maxLevel": 2,
+	}))
+	assert.ErrorContains(t, err, "invalid heading levels")
+}
+
+// Test the lintMarkdown tool
+func TestHandleLintMarkdown(t *testing.T) {
+	s := NewMarkdownServer(4096)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		markdown string
+		options  map[string]interface{}
+		issues   []string
+	}{
+		{
+			name:     "Clean document",
+			markdown: "# Title\n\nText with a hard break  \nand a [link](#section).\n\n## Section\n\n```go\n\tcode   \n```\n",
+		},
+		{
+			name:     "Heading structure",
+			markdown: "# One\n\n### Skipped\n\n#Missing space\n\n# Two\n\n## Part\n\n## Part\n\n##\n",
+			issues: []string{
+				"line 3: heading-increment: Heading levels should only increase by one level at a time (h1 to h3)",
+				"line 5: no-missing-space-atx: No space after the hash of a heading",
+				"line 7: single-title: Multiple top-level headings; the first is on line 1",
+				"line 11: no-duplicate-heading: Duplicate heading \"Part\" under the same parent; the first is on line 9",
+				"line 13: no-empty-heading: Heading has no text",
+			},
+		},
+		{
+			name:     "Same heading under different parents is fine",
+			markdown: "# Doc\n\n## A\n\n### Notes\n\n## B\n\n### Notes\n",
+		},
+		{
+			name:     "Links and images",
+			markdown: "# Doc\n\n[empty]() [hash](#) [bad](#nowhere) [ok](#doc)\n\n![](a.png)\n",
+			issues: []string{
+				"line 3: no-empty-links: Link \"empty\" has no destination",
+				"line 3: no-empty-links: Link \"hash\" has no destination",
+				"line 3: link-fragments: Link fragment #nowhere does not match any heading",
+				"line 5: no-alt-text: Image a.png has no alternative text",
+			},
+		},
+		{
+			name:     "Whitespace and fences",
+			markdown: "# Doc\n\ntrailing \n\n\ntext\twith tab\n\n```\nplain\n```\n\n- a\n\n* b\n\nlast",
+			issues: []string{
+				"line 3: no-trailing-spaces: Trailing whitespace",
+				"line 5: no-multiple-blanks: Multiple consecutive blank lines",
+				"line 6: no-hard-tabs: Hard tab character",
+				"line 8: fenced-code-language: Fenced code blocks should specify a language",
+				"line 14: ul-style: Unordered list uses asterisk markers, but earlier lists use dash markers",
+				"line 16: single-trailing-newline: File should end with a single newline",
+			},
+		},
+		{
+			name:     "Line length",
+			markdown: "# Doc\n\nthis line is rather long\n\nhttps://example.com/a/very/long/url/without/spaces\n",
+			options:  map[string]interface{}{"maxLineLength": 20},
+			issues:   []string{"line 3: line-length: Line is 24 characters long (maximum 20)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]interface{}{"markdown": tc.markdown}
+			for k, v := range tc.options {
+				args[k] = v
+			}
+			result, err := s.handleLintMarkdown(ctx, mcptest.NewCallToolRequest("lintMarkdown", args))
+			require.NoError(t, err)
+			text := mcptest.ResultText(result)
+			if len(tc.issues) == 0 {
+				assert.Equal(t, "No issues found", text)
+				return
+			}
+			lines := strings.Split(text, "\n")
+			assert.Equal(t, tc.issues, lines[1:], text)
+		})
+	}
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewMarkdownServer(1024).Server())
+}
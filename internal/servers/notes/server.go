@@ -0,0 +1,701 @@
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// NotesServer is an MCP server that keeps notes and a todo list in a local JSON store.
+type NotesServer struct {
+	server      *server.MCPServer
+	store       *store
+	maxNoteSize int
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewNotesServer creates a new NotesServer instance.
+func NewNotesServer(st *store, maxNoteSize int) *NotesServer {
+	log.Printf("NotesServer created: store=%s, maxNoteSize=%d", st.path, maxNoteSize)
+
+	s := &NotesServer{
+		store:       st,
+		maxNoteSize: maxNoteSize,
+		now:         time.Now,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"notes-server", // server name
+		"1.0.0",        // version
+	)
+
+	// Register createNote tool
+	createNoteTool := mcp.NewTool("createNote",
+		mcp.WithDescription("Creates a note that persists across sessions"),
+		mcp.WithString("title",
+			mcp.Description("Title of the note"),
+			mcp.Required(),
+		),
+		mcp.WithString("body",
+			mcp.Description("Content of the note"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Tags for finding the note again"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+	)
+
+	// Register searchNotes tool
+	searchNotesTool := mcp.NewTool("searchNotes",
+		mcp.WithDescription("Searches notes by words in their title, body or tags. Without a query, lists the most recently updated notes"),
+		mcp.WithString("query",
+			mcp.Description("Words that must all occur in the note (case insensitive)"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only search notes with this tag"),
+		),
+		mcp.WithNumber("id",
+			mcp.Description("Returns the full note with this ID instead of searching"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of notes returned (default: 10)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register updateNote tool
+	updateNoteTool := mcp.NewTool("updateNote",
+		mcp.WithDescription("Changes the title, body or tags of a note, or appends text to its body"),
+		mcp.WithNumber("id",
+			mcp.Description("ID of the note"),
+			mcp.Required(),
+		),
+		mcp.WithString("title",
+			mcp.Description("New title"),
+		),
+		mcp.WithString("body",
+			mcp.Description("New body, replacing the current one"),
+		),
+		mcp.WithString("append",
+			mcp.Description("Text appended to the body on a new line"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("New tags, replacing the current ones"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+	)
+
+	// Register addTodo tool
+	addTodoTool := mcp.NewTool("addTodo",
+		mcp.WithDescription("Adds an item to the todo list"),
+		mcp.WithString("text",
+			mcp.Description("What needs to be done"),
+			mcp.Required(),
+		),
+		mcp.WithString("priority",
+			mcp.Description("Priority of the item (default: normal)"),
+			mcp.Enum("low", "normal", "high"),
+		),
+		mcp.WithString("due",
+			mcp.Description("Due date in YYYY-MM-DD format"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Tags for grouping items"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+	)
+
+	// Register listTodos tool
+	listTodosTool := mcp.NewTool("listTodos",
+		mcp.WithDescription("Lists todo items, open items ordered by priority and due date"),
+		mcp.WithString("status",
+			mcp.Description("Which items to list (default: open)"),
+			mcp.Enum("open", "done", "all"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("Only list items with this tag"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register completeTodo tool
+	completeTodoTool := mcp.NewTool("completeTodo",
+		mcp.WithDescription("Marks a todo item as done, or as open again"),
+		mcp.WithNumber("id",
+			mcp.Description("ID of the item"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("reopen",
+			mcp.Description("Marks a done item as open again (default: false)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, createNoteTool, s.handleCreateNote)
+	middleware.AddTool(mcpServer, searchNotesTool, s.handleSearchNotes)
+	middleware.AddTool(mcpServer, updateNoteTool, s.handleUpdateNote)
+	middleware.AddTool(mcpServer, addTodoTool, s.handleAddTodo)
+	middleware.AddTool(mcpServer, listTodosTool, s.handleListTodos)
+	middleware.AddTool(mcpServer, completeTodoTool, s.handleCompleteTodo)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// jsonResult renders v as indented JSON.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return textResult(string(data)), nil
+}
+
+// checkSize rejects notes larger than the configured limit.
+func (s *NotesServer) checkSize(title, body string) error {
+	if size := len(title) + len(body); size > s.maxNoteSize {
+		return fmt.Errorf("note is %d bytes, larger than the limit of %d bytes", size, s.maxNoteSize)
+	}
+	return nil
+}
+
+// formatNote renders a full note.
+func formatNote(n Note) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Note %d: %s\n", n.ID, n.Title)
+	if len(n.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n", strings.Join(n.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "Updated: %s\n", n.Updated.Format("2006-01-02 15:04"))
+	if n.Body != "" {
+		b.WriteString("\n" + n.Body)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// handleCreateNote handles the note creation request.
+func (s *NotesServer) handleCreateNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting create note request processing")
+
+	var args struct {
+		Title string   `json:"title"`
+		Body  string   `json:"body"`
+		Tags  []string `json:"tags"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	args.Title = strings.TrimSpace(args.Title)
+	if args.Title == "" {
+		log.Println("Error: Empty title")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "title is required")
+	}
+	if err := s.checkSize(args.Title, args.Body); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	now := s.now()
+	var note Note
+	err := s.store.update(func(d *storeData) error {
+		note = Note{
+			ID:      d.NextNoteID,
+			Title:   args.Title,
+			Body:    args.Body,
+			Tags:    normalizeTags(args.Tags),
+			Created: now,
+			Updated: now,
+		}
+		d.NextNoteID++
+		stored := note
+		d.Notes = append(d.Notes, &stored)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error: Failed to create note: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Create note request completed: id=%d", note.ID)
+	return textResult(fmt.Sprintf("Created note %d: %s", note.ID, note.Title)), nil
+}
+
+// handleSearchNotes handles the search request.
+func (s *NotesServer) handleSearchNotes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting search notes request processing")
+
+	var args struct {
+		Query  string `json:"query"`
+		Tag    string `json:"tag"`
+		ID     int    `json:"id"`
+		Limit  int    `json:"limit"`
+		Format string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	if args.ID != 0 {
+		var note Note
+		var err error
+		s.store.view(func(d *storeData) {
+			var n *Note
+			if n, err = d.findNote(args.ID); err == nil {
+				note = *n
+			}
+		})
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		log.Printf("Search notes request completed: id=%d", args.ID)
+		if args.Format == "json" {
+			return jsonResult(note)
+		}
+		return textResult(formatNote(note)), nil
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	var matches []noteMatch
+	total := 0
+	s.store.view(func(d *storeData) {
+		total = len(d.Notes)
+		matches = searchNotes(d.Notes, args.Query, args.Tag)
+	})
+	found := len(matches)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	log.Printf("Search notes request completed: %d of %d notes match", found, total)
+	if args.Format == "json" {
+		notes := make([]Note, 0, len(matches))
+		for _, m := range matches {
+			notes = append(notes, m.note)
+		}
+		return jsonResult(notes)
+	}
+	if found == 0 {
+		if total == 0 {
+			return textResult("No notes yet"), nil
+		}
+		return textResult("No notes match"), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d notes found", found)
+	if found > len(matches) {
+		fmt.Fprintf(&b, ", showing %d", len(matches))
+	}
+	b.WriteString("\n")
+	terms := strings.Fields(args.Query)
+	for _, m := range matches {
+		n := m.note
+		fmt.Fprintf(&b, "\n[%d] %s", n.ID, n.Title)
+		if len(n.Tags) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(n.Tags, ", "))
+		}
+		fmt.Fprintf(&b, " - updated %s\n", n.Updated.Format("2006-01-02 15:04"))
+		if n.Body != "" {
+			fmt.Fprintf(&b, "    %s\n", snippet(n.Body, terms, 160))
+		}
+	}
+	return textResult(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// handleUpdateNote handles the note update request.
+func (s *NotesServer) handleUpdateNote(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting update note request processing")
+
+	var args struct {
+		ID     int       `json:"id"`
+		Title  *string   `json:"title"`
+		Body   *string   `json:"body"`
+		Append string    `json:"append"`
+		Tags   *[]string `json:"tags"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Title == nil && args.Body == nil && args.Append == "" && args.Tags == nil {
+		log.Println("Error: Nothing to update")
+		return nil, fmt.Errorf("specify title, body, append or tags")
+	}
+	if args.Body != nil && args.Append != "" {
+		log.Println("Error: Both body and append given")
+		return nil, fmt.Errorf("use either body or append, not both")
+	}
+
+	var changed []string
+	var note Note
+	err := s.store.update(func(d *storeData) error {
+		n, err := d.findNote(args.ID)
+		if err != nil {
+			return err
+		}
+		title, body := n.Title, n.Body
+		if args.Title != nil {
+			if title = strings.TrimSpace(*args.Title); title == "" {
+				return fmt.Errorf("title must not be empty")
+			}
+			changed = append(changed, "title")
+		}
+		if args.Body != nil {
+			body = *args.Body
+			changed = append(changed, "body")
+		}
+		if args.Append != "" {
+			if body != "" && !strings.HasSuffix(body, "\n") {
+				body += "\n"
+			}
+			body += args.Append
+			changed = append(changed, "body")
+		}
+		if err := s.checkSize(title, body); err != nil {
+			return err
+		}
+		n.Title, n.Body = title, body
+		if args.Tags != nil {
+			n.Tags = normalizeTags(*args.Tags)
+			changed = append(changed, "tags")
+		}
+		n.Updated = s.now()
+		note = *n
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error: Failed to update note: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Update note request completed: id=%d", note.ID)
+	return textResult(fmt.Sprintf("Updated %s of note %d: %s", strings.Join(changed, ", "), note.ID, note.Title)), nil
+}
+
+// handleAddTodo handles the todo creation request.
+func (s *NotesServer) handleAddTodo(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting add todo request processing")
+
+	var args struct {
+		Text     string   `json:"text"`
+		Priority string   `json:"priority"`
+		Due      string   `json:"due"`
+		Tags     []string `json:"tags"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	args.Text = strings.TrimSpace(args.Text)
+	if args.Text == "" {
+		log.Println("Error: Empty text")
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "text is required")
+	}
+	if err := s.checkSize(args.Text, ""); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if args.Priority == "" {
+		args.Priority = "normal"
+	}
+	if _, ok := priorityRank[args.Priority]; !ok {
+		log.Printf("Error: Invalid priority: %s", args.Priority)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid priority %q; use low, normal or high", args.Priority)
+	}
+	if args.Due != "" {
+		if _, err := time.Parse("2006-01-02", args.Due); err != nil {
+			log.Printf("Error: Invalid due date: %s", args.Due)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid due date %q; use YYYY-MM-DD", args.Due)
+		}
+	}
+
+	var todo Todo
+	err := s.store.update(func(d *storeData) error {
+		todo = Todo{
+			ID:       d.NextTodoID,
+			Text:     args.Text,
+			Priority: args.Priority,
+			Due:      args.Due,
+			Tags:     normalizeTags(args.Tags),
+			Created:  s.now(),
+		}
+		d.NextTodoID++
+		stored := todo
+		d.Todos = append(d.Todos, &stored)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error: Failed to add todo: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Add todo request completed: id=%d", todo.ID)
+	return textResult(fmt.Sprintf("Added todo %d: %s", todo.ID, todo.Text)), nil
+}
+
+// handleListTodos handles the todo listing request.
+func (s *NotesServer) handleListTodos(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting list todos request processing")
+
+	var args struct {
+		Status string `json:"status"`
+		Tag    string `json:"tag"`
+		Format string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Status == "" {
+		args.Status = "open"
+	}
+	if args.Status != "open" && args.Status != "done" && args.Status != "all" {
+		log.Printf("Error: Invalid status: %s", args.Status)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid status %q; use open, done or all", args.Status)
+	}
+
+	var todos []Todo
+	s.store.view(func(d *storeData) {
+		for _, t := range d.Todos {
+			if (args.Status == "open" && t.Done) || (args.Status == "done" && !t.Done) {
+				continue
+			}
+			if args.Tag != "" && !hasTag(t.Tags, args.Tag) {
+				continue
+			}
+			todos = append(todos, *t)
+		}
+	})
+	// Open items first by priority then due date, items without a due date last; done
+	// items follow with the most recently completed first
+	sort.SliceStable(todos, func(a, b int) bool {
+		ta, tb := todos[a], todos[b]
+		if ta.Done != tb.Done {
+			return !ta.Done
+		}
+		if ta.Done {
+			return ta.Completed.After(tb.Completed)
+		}
+		if priorityRank[ta.Priority] != priorityRank[tb.Priority] {
+			return priorityRank[ta.Priority] < priorityRank[tb.Priority]
+		}
+		if ta.Due != tb.Due {
+			return tb.Due == "" || (ta.Due != "" && ta.Due < tb.Due)
+		}
+		return ta.ID < tb.ID
+	})
+
+	log.Printf("List todos request completed: %d items", len(todos))
+	if args.Format == "json" {
+		if todos == nil {
+			todos = []Todo{}
+		}
+		return jsonResult(todos)
+	}
+	if len(todos) == 0 {
+		switch args.Status {
+		case "open":
+			return textResult("No open todos"), nil
+		case "done":
+			return textResult("No completed todos"), nil
+		}
+		return textResult("No todos"), nil
+	}
+
+	today := s.now().Format("2006-01-02")
+	var b strings.Builder
+	for _, t := range todos {
+		mark := " "
+		if t.Done {
+			mark = "x"
+		}
+		fmt.Fprintf(&b, "[%s] %d. %s", mark, t.ID, t.Text)
+		var details []string
+		if t.Priority != "normal" {
+			details = append(details, t.Priority+" priority")
+		}
+		if t.Due != "" {
+			due := "due " + t.Due
+			if !t.Done && t.Due < today {
+				due += ", overdue"
+			}
+			details = append(details, due)
+		}
+		if len(t.Tags) > 0 {
+			details = append(details, "#"+strings.Join(t.Tags, " #"))
+		}
+		if t.Done && !t.Completed.IsZero() {
+			details = append(details, "done "+t.Completed.Format("2006-01-02"))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(details, "; "))
+		}
+		b.WriteString("\n")
+	}
+	return textResult(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// handleCompleteTodo handles the completion request.
+func (s *NotesServer) handleCompleteTodo(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting complete todo request processing")
+
+	var args struct {
+		ID     int  `json:"id"`
+		Reopen bool `json:"reopen"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	var todo Todo
+	var unchanged bool
+	err := s.store.update(func(d *storeData) error {
+		t, err := d.findTodo(args.ID)
+		if err != nil {
+			return err
+		}
+		unchanged = t.Done != args.Reopen
+		t.Done = !args.Reopen
+		if args.Reopen {
+			t.Completed = time.Time{}
+		} else if !unchanged {
+			t.Completed = s.now()
+		}
+		todo = *t
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error: Failed to update todo: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Complete todo request completed: id=%d, done=%t", todo.ID, todo.Done)
+	switch {
+	case unchanged && todo.Done:
+		return textResult(fmt.Sprintf("Todo %d was already done: %s", todo.ID, todo.Text)), nil
+	case unchanged:
+		return textResult(fmt.Sprintf("Todo %d is already open: %s", todo.ID, todo.Text)), nil
+	case todo.Done:
+		return textResult(fmt.Sprintf("Completed todo %d: %s", todo.ID, todo.Text)), nil
+	default:
+		return textResult(fmt.Sprintf("Reopened todo %d: %s", todo.ID, todo.Text)), nil
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *NotesServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the notes server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("notes", flag.ContinueOnError)
+	var (
+		storePath   string
+		maxNoteSize int
+	)
+	fs.StringVar(&storePath, "store", "", "JSON file notes and todos are kept in (default: ~/.mcphost/notes.json)")
+	fs.IntVar(&maxNoteSize, "max-note-size", 64*1024, "Maximum size of a note in bytes")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	if storePath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			log.Printf("Error: Failed to get home directory: %v", err)
+			return nil, transport.Flags{}, err
+		}
+		storePath = filepath.Join(homeDir, ".mcphost", "notes.json")
+	}
+
+	log.Printf("Starting notes server: store=%s, max-note-size=%d", storePath, maxNoteSize)
+
+	st, err := openStore(storePath)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create NotesServer instance
+	notesServer := NewNotesServer(st, maxNoteSize)
+	log.Println("NotesServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), notesServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return notesServer.Server(), transportFlags, nil
+}
+
+// Run starts the notes server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[NotesServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create notes server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("NotesServer shutdown")
+	return nil
+}
@@ -0,0 +1,256 @@
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a server on an empty store with a clock that advances a
+// minute per call.
+func newTestServer(t testing.TB) *NotesServer {
+	t.Helper()
+	st, err := openStore(filepath.Join(t.TempDir(), "notes.json"))
+	require.NoError(t, err)
+	s := NewNotesServer(st, 1024)
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return s
+}
+
+// call invokes a handler and returns its text.
+func call(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) string {
+	t.Helper()
+	result, err := handler(context.Background(), mcptest.NewCallToolRequest("tool", args))
+	require.NoError(t, err)
+	return mcptest.ResultText(result)
+}
+
+// NotesServer creation test
+func TestNewNotesServer(t *testing.T) {
+	st, err := openStore(filepath.Join(t.TempDir(), "notes.json"))
+	require.NoError(t, err)
+	s := NewNotesServer(st, 2048)
+
+	assert.NotNil(t, s, "NotesServer instance should be created")
+	assert.Equal(t, st, s.store, "Store should match")
+	assert.Equal(t, 2048, s.maxNoteSize, "Max note size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := newTestServer(t)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test creating, searching and updating notes
+func TestNotes(t *testing.T) {
+	s := newTestServer(t)
+
+	assert.Equal(t, "No notes yet", call(t, s.handleSearchNotes, nil))
+	assert.Equal(t, "Created note 1: Deploy checklist", call(t, s.handleCreateNote, map[string]interface{}{
+		"title": "Deploy checklist",
+		"body":  "Run migrations before switching traffic. Check the dashboards afterwards.",
+		"tags":  []interface{}{"Ops", "#deploy", "ops"},
+	}))
+	assert.Equal(t, "Created note 2: Meeting notes", call(t, s.handleCreateNote, map[string]interface{}{
+		"title": "Meeting notes",
+		"body":  "Discussed the deploy schedule. Deploy on Thursdays.",
+	}))
+	assert.Equal(t, "Created note 3: Groceries", call(t, s.handleCreateNote, map[string]interface{}{
+		"title": "Groceries",
+	}))
+
+	t.Run("search", func(t *testing.T) {
+		text := call(t, s.handleSearchNotes, map[string]interface{}{"query": "deploy"})
+		assert.Equal(t, "2 notes found\n\n"+
+			"[1] Deploy checklist (ops, deploy) - updated 2026-05-04 09:01\n"+
+			"    Run migrations before switching traffic. Check the dashboards afterwards.\n\n"+
+			"[2] Meeting notes - updated 2026-05-04 09:02\n"+
+			"    Discussed the deploy schedule. Deploy on Thursdays.", text)
+
+		text = call(t, s.handleSearchNotes, map[string]interface{}{"query": "DEPLOY thursdays"})
+		assert.True(t, strings.HasPrefix(text, "1 notes found\n\n[2] Meeting notes"))
+
+		text = call(t, s.handleSearchNotes, map[string]interface{}{"tag": "ops"})
+		assert.True(t, strings.HasPrefix(text, "1 notes found\n\n[1] Deploy checklist"))
+
+		text = call(t, s.handleSearchNotes, map[string]interface{}{"limit": 1})
+		assert.True(t, strings.HasPrefix(text, "3 notes found, showing 1\n\n[3] Groceries"), "Most recent note should come first")
+
+		assert.Equal(t, "No notes match", call(t, s.handleSearchNotes, map[string]interface{}{"query": "kubernetes"}))
+
+		var notes []Note
+		require.NoError(t, json.Unmarshal([]byte(call(t, s.handleSearchNotes, map[string]interface{}{"query": "deploy", "format": "json"})), &notes))
+		require.Len(t, notes, 2)
+		assert.Equal(t, []string{"ops", "deploy"}, notes[0].Tags)
+	})
+
+	t.Run("by id", func(t *testing.T) {
+		text := call(t, s.handleSearchNotes, map[string]interface{}{"id": 1})
+		assert.Equal(t, "Note 1: Deploy checklist\nTags: ops, deploy\nUpdated: 2026-05-04 09:01\n\n"+
+			"Run migrations before switching traffic. Check the dashboards afterwards.", text)
+		_, err := s.handleSearchNotes(context.Background(), mcptest.NewCallToolRequest("searchNotes", map[string]interface{}{"id": 9}))
+		assert.EqualError(t, err, "no note with id 9")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		assert.Equal(t, "Updated body of note 3: Groceries", call(t, s.handleUpdateNote, map[string]interface{}{"id": 3, "append": "milk"}))
+		assert.Equal(t, "Updated title, body of note 3: Shopping", call(t, s.handleUpdateNote, map[string]interface{}{
+			"id": 3, "title": "Shopping", "append": "eggs",
+		}))
+		assert.Equal(t, "Updated tags of note 3: Shopping", call(t, s.handleUpdateNote, map[string]interface{}{"id": 3, "tags": []interface{}{}}))
+		text := call(t, s.handleSearchNotes, map[string]interface{}{"id": 3})
+		assert.True(t, strings.HasSuffix(text, "\n\nmilk\neggs"))
+
+		tests := []struct {
+			name    string
+			args    map[string]interface{}
+			wantErr string
+		}{
+			{"nothing", map[string]interface{}{"id": 3}, "specify title, body, append or tags"},
+			{"both", map[string]interface{}{"id": 3, "body": "x", "append": "y"}, "either body or append"},
+			{"empty title", map[string]interface{}{"id": 3, "title": " "}, "title must not be empty"},
+			{"missing", map[string]interface{}{"id": 42, "body": "x"}, "no note with id 42"},
+			{"too large", map[string]interface{}{"id": 3, "body": strings.Repeat("x", 2000)}, "larger than the limit of 1024 bytes"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := s.handleUpdateNote(context.Background(), mcptest.NewCallToolRequest("updateNote", tt.args))
+				assert.ErrorContains(t, err, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("create errors", func(t *testing.T) {
+		_, err := s.handleCreateNote(context.Background(), mcptest.NewCallToolRequest("createNote", map[string]interface{}{"title": ""}))
+		assert.EqualError(t, err, "title is required")
+		_, err = s.handleCreateNote(context.Background(), mcptest.NewCallToolRequest("createNote", map[string]interface{}{
+			"title": "big", "body": strings.Repeat("x", 1024),
+		}))
+		assert.ErrorContains(t, err, "larger than the limit")
+	})
+}
+
+// Test the todo list
+func TestTodos(t *testing.T) {
+	s := newTestServer(t)
+
+	assert.Equal(t, "No open todos", call(t, s.handleListTodos, nil))
+	for _, args := range []map[string]interface{}{
+		{"text": "Write release notes"},
+		{"text": "Renew certificate", "priority": "high", "due": "2026-05-01", "tags": []interface{}{"ops"}},
+		{"text": "Clean up branches", "priority": "low"},
+		{"text": "Rotate keys", "priority": "high", "due": "2026-06-01", "tags": []interface{}{"ops", "security"}},
+		{"text": "Fix flaky test", "priority": "high"},
+	} {
+		call(t, s.handleAddTodo, args)
+	}
+
+	assert.Equal(t, "[ ] 2. Renew certificate (high priority; due 2026-05-01, overdue; #ops)\n"+
+		"[ ] 4. Rotate keys (high priority; due 2026-06-01; #ops #security)\n"+
+		"[ ] 5. Fix flaky test (high priority)\n"+
+		"[ ] 1. Write release notes\n"+
+		"[ ] 3. Clean up branches (low priority)", call(t, s.handleListTodos, nil))
+
+	assert.Equal(t, "Completed todo 2: Renew certificate", call(t, s.handleCompleteTodo, map[string]interface{}{"id": 2}))
+	assert.Equal(t, "Todo 2 was already done: Renew certificate", call(t, s.handleCompleteTodo, map[string]interface{}{"id": 2}))
+	assert.Equal(t, "Completed todo 3: Clean up branches", call(t, s.handleCompleteTodo, map[string]interface{}{"id": 3}))
+
+	assert.Equal(t, "[ ] 4. Rotate keys (high priority; due 2026-06-01; #ops #security)",
+		call(t, s.handleListTodos, map[string]interface{}{"tag": "OPS"}))
+	assert.Equal(t, "[x] 3. Clean up branches (low priority; done 2026-05-04)\n"+
+		"[x] 2. Renew certificate (high priority; due 2026-05-01; #ops; done 2026-05-04)",
+		call(t, s.handleListTodos, map[string]interface{}{"status": "done"}))
+
+	assert.Equal(t, "Reopened todo 3: Clean up branches", call(t, s.handleCompleteTodo, map[string]interface{}{"id": 3, "reopen": true}))
+	assert.Equal(t, "Todo 3 is already open: Clean up branches", call(t, s.handleCompleteTodo, map[string]interface{}{"id": 3, "reopen": true}))
+
+	var todos []Todo
+	require.NoError(t, json.Unmarshal([]byte(call(t, s.handleListTodos, map[string]interface{}{"status": "all", "format": "json"})), &todos))
+	require.Len(t, todos, 5)
+	assert.Equal(t, 2, todos[4].ID, "Done items should be listed last")
+	assert.True(t, todos[4].Done)
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"empty text", s.handleAddTodo, map[string]interface{}{"text": " "}, "text is required"},
+		{"priority", s.handleAddTodo, map[string]interface{}{"text": "x", "priority": "urgent"}, "invalid priority"},
+		{"due", s.handleAddTodo, map[string]interface{}{"text": "x", "due": "next week"}, "invalid due date"},
+		{"status", s.handleListTodos, map[string]interface{}{"status": "later"}, "invalid status"},
+		{"missing", s.handleCompleteTodo, map[string]interface{}{"id": 99}, "no todo with id 99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.handler(context.Background(), mcptest.NewCallToolRequest("tool", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// Test that the store persists across restarts
+func TestStorePersistence(t *testing.T) {
+	s := newTestServer(t)
+	call(t, s.handleCreateNote, map[string]interface{}{"title": "Remember me"})
+	call(t, s.handleAddTodo, map[string]interface{}{"text": "Survive a restart"})
+	call(t, s.handleCompleteTodo, map[string]interface{}{"id": 1})
+
+	info, err := os.Stat(s.store.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "Store should be private to the user")
+
+	st, err := openStore(s.store.path)
+	require.NoError(t, err)
+	reopened := NewNotesServer(st, 1024)
+	assert.Contains(t, call(t, reopened.handleSearchNotes, map[string]interface{}{"query": "remember"}), "[1] Remember me")
+	assert.Contains(t, call(t, reopened.handleListTodos, map[string]interface{}{"status": "done"}), "[x] 1. Survive a restart")
+	assert.Equal(t, "Created note 2: Another", call(t, reopened.handleCreateNote, map[string]interface{}{"title": "Another"}))
+
+	// A failed save leaves the store unchanged
+	require.NoError(t, os.Chmod(filepath.Dir(s.store.path), 0500))
+	defer os.Chmod(filepath.Dir(s.store.path), 0700)
+	if os.Getuid() != 0 {
+		_, err = reopened.handleCreateNote(context.Background(), mcptest.NewCallToolRequest("createNote", map[string]interface{}{"title": "Lost"}))
+		assert.Error(t, err)
+		assert.Equal(t, "No notes match", call(t, reopened.handleSearchNotes, map[string]interface{}{"query": "lost"}))
+	}
+
+	// Counters are rebuilt for hand edited files
+	path := filepath.Join(t.TempDir(), "edited.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"notes": [{"id": 7, "title": "x"}], "todos": [{"id": 3, "text": "y"}]}`), 0600))
+	st, err = openStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 8, st.data.NextNoteID)
+	assert.Equal(t, 4, st.data.NextTodoID)
+}
+
+// Test snippets around search terms
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "the needle is here " + strings.Repeat("dolor sit ", 20)
+	got := snippet(text, []string{"NEEDLE"}, 40)
+	assert.Contains(t, got, "needle")
+	assert.True(t, strings.HasPrefix(got, "...") && strings.HasSuffix(got, "..."))
+	assert.Equal(t, "short text", snippet("short\n\ntext", nil, 40))
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, newTestServer(f).Server())
+}
@@ -0,0 +1,250 @@
+package notes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Note is a free form note.
+type Note struct {
+	ID      int       `json:"id"`
+	Title   string    `json:"title"`
+	Body    string    `json:"body,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Todo is an item of the task list.
+type Todo struct {
+	ID        int       `json:"id"`
+	Text      string    `json:"text"`
+	Priority  string    `json:"priority"` // "low", "normal" or "high"
+	Due       string    `json:"due,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Done      bool      `json:"done"`
+	Created   time.Time `json:"created"`
+	Completed time.Time `json:"completed,omitempty"`
+}
+
+// priorityRank orders priorities from most to least urgent.
+var priorityRank = map[string]int{"high": 0, "normal": 1, "low": 2}
+
+// hasTag reports whether tags contains tag, ignoring case.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTags trims, lower cases and de-duplicates tags.
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#")))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// storeData is the layout of the store file.
+type storeData struct {
+	NextNoteID int     `json:"nextNoteId"`
+	NextTodoID int     `json:"nextTodoId"`
+	Notes      []*Note `json:"notes"`
+	Todos      []*Todo `json:"todos"`
+}
+
+// store keeps notes and todos in a JSON file, rewritten on every change.
+type store struct {
+	path string
+	mu   sync.Mutex
+	data storeData
+}
+
+// openStore loads the store file, starting empty when it does not exist yet.
+func openStore(path string) (*store, error) {
+	s := &store{path: path, data: storeData{NextNoteID: 1, NextTodoID: 1}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %w", path, err)
+	}
+	// Files edited by hand may lack the counters
+	for _, n := range s.data.Notes {
+		s.data.NextNoteID = max(s.data.NextNoteID, n.ID+1)
+	}
+	for _, t := range s.data.Todos {
+		s.data.NextTodoID = max(s.data.NextTodoID, t.ID+1)
+	}
+	return s, nil
+}
+
+// save writes the store through a temporary file so a crash never leaves it truncated.
+// The caller holds s.mu.
+func (s *store) save() error {
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".notes-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	return nil
+}
+
+// update applies fn and saves the store. Changes are rolled back when saving fails, so
+// memory and disk never disagree.
+func (s *store) update(fn func(d *storeData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup, err := json.Marshal(s.data)
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+	if err := fn(&s.data); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		var restored storeData
+		if json.Unmarshal(backup, &restored) == nil {
+			s.data = restored
+		}
+		return err
+	}
+	return nil
+}
+
+// view runs fn with the store locked for reading.
+func (s *store) view(fn func(d *storeData)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.data)
+}
+
+// findNote returns the note with an ID.
+func (d *storeData) findNote(id int) (*Note, error) {
+	for _, n := range d.Notes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("no note with id %d", id)
+}
+
+// findTodo returns the todo with an ID.
+func (d *storeData) findTodo(id int) (*Todo, error) {
+	for _, t := range d.Todos {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("no todo with id %d", id)
+}
+
+// noteMatch is a search hit with its score.
+type noteMatch struct {
+	note  Note
+	score int
+}
+
+// searchNotes returns the notes containing every query term, ranked by how often the
+// terms occur with title hits counting more, then by most recent update.
+func searchNotes(notes []*Note, query, tag string) []noteMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	var matches []noteMatch
+	for _, n := range notes {
+		if tag != "" && !hasTag(n.Tags, tag) {
+			continue
+		}
+		title, body := strings.ToLower(n.Title), strings.ToLower(n.Body)
+		score := 0
+		for _, term := range terms {
+			hits := 3*strings.Count(title, term) + strings.Count(body, term)
+			if hits == 0 && !hasTag(n.Tags, term) {
+				score = -1
+				break
+			}
+			score += hits
+		}
+		if score >= 0 {
+			matches = append(matches, noteMatch{note: *n, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return matches[a].note.Updated.After(matches[b].note.Updated)
+	})
+	return matches
+}
+
+// snippet returns the part of text around the first occurrence of a term.
+func snippet(text string, terms []string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= width {
+		return text
+	}
+	lower := strings.ToLower(text)
+	start := 0
+	for _, term := range terms {
+		if i := strings.Index(lower, strings.ToLower(term)); i >= 0 {
+			start = max(0, i-width/4)
+			break
+		}
+	}
+	end := min(len(text), start+width)
+	// Do not cut UTF-8 sequences in half
+	for start > 0 && start < len(text) && text[start]&0xC0 == 0x80 {
+		start--
+	}
+	for end < len(text) && text[end]&0xC0 == 0x80 {
+		end++
+	}
+	out := text[start:end]
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(text) {
+		out += "..."
+	}
+	return out
+}
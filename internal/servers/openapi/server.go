@@ -0,0 +1,642 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// maxSpecSize is the size of the documents read, in bytes.
+const maxSpecSize = 20 * 1024 * 1024
+
+// Credentials authenticate the requests to an API, by the security schemes of its
+// spec.
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+	Username    string
+	Password    string
+}
+
+// satisfies reports whether c has the credentials of scheme.
+func (c Credentials) satisfies(scheme SecurityScheme) bool {
+	switch {
+	case scheme.Type == "apiKey":
+		return c.APIKey != ""
+	case scheme.Type == "http" && scheme.Scheme == "basic":
+		return c.Username != ""
+	case scheme.Type == "http" && scheme.Scheme == "bearer", scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+		return c.BearerToken != ""
+	}
+	return false
+}
+
+// authorize adds the credentials of the first security requirement of op that c
+// satisfies to req.
+func (c Credentials) authorize(req *http.Request, spec *Spec, op *Operation) {
+	security := op.Security
+	if security == nil {
+		security = spec.Security
+	}
+	for _, requirement := range security {
+		satisfied := true
+		for name := range requirement {
+			if scheme, ok := spec.SecuritySchemes[name]; !ok || !c.satisfies(scheme) {
+				satisfied = false
+			}
+		}
+		if !satisfied {
+			continue
+		}
+		for name := range requirement {
+			scheme := spec.SecuritySchemes[name]
+			switch {
+			case scheme.Type == "apiKey" && scheme.In == "query":
+				q := req.URL.Query()
+				q.Set(scheme.Name, c.APIKey)
+				req.URL.RawQuery = q.Encode()
+			case scheme.Type == "apiKey" && scheme.In == "cookie":
+				req.AddCookie(&http.Cookie{Name: scheme.Name, Value: c.APIKey})
+			case scheme.Type == "apiKey":
+				req.Header.Set(scheme.Name, c.APIKey)
+			case scheme.Scheme == "basic":
+				req.SetBasicAuth(c.Username, c.Password)
+			default:
+				req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+			}
+		}
+		return
+	}
+}
+
+// API is a loaded spec, whose operations are tools.
+type API struct {
+	Name    string   `json:"name"`
+	Title   string   `json:"title,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Source  string   `json:"source"`
+	BaseURL string   `json:"baseUrl"`
+	Tools   []string `json:"tools"`
+	// Startup tells that the API was loaded from the flags, with their credentials.
+	Startup bool `json:"startup"`
+
+	spec *Spec
+	// operations are the operations of the tools, in their order
+	operations  []*Operation
+	credentials Credentials
+}
+
+// LoadOptions are the options of loading a spec.
+type LoadOptions struct {
+	// Prefix prefixes the names of the tools.
+	Prefix string
+	// BaseURL replaces the URL of the servers of the spec.
+	BaseURL string
+	// Include are the patterns of the operation IDs and paths of the operations
+	// loaded, all if empty.
+	Include []string
+	// Credentials authenticate the requests of the tools.
+	Credentials Credentials
+	// Startup tells that the spec is loaded from the flags.
+	Startup bool
+}
+
+// OpenAPIServer is an MCP server exposing the operations of OpenAPI and Swagger specs
+// as tools, loaded at startup or by the client.
+type OpenAPIServer struct {
+	server      *server.MCPServer
+	client      *http.Client
+	maxBodySize int64
+	maxTools    int
+	readOnly    bool
+
+	mu   sync.Mutex
+	apis map[string]*API
+	// tools are the names of the tools of the APIs
+	tools map[string]bool
+}
+
+// NewOpenAPIServer creates a new OpenAPIServer instance requesting APIs with client,
+// with at most maxTools tools, of the operations only reading if readOnly is set.
+// The client may load specs with the loadSpec tool if allowLoad is set.
+func NewOpenAPIServer(client *http.Client, maxBodySize int64, maxTools int, readOnly, allowLoad bool) *OpenAPIServer {
+	log.Printf("OpenAPIServer created: maxBodySize=%d, maxTools=%d, readOnly=%t, allowLoad=%t", maxBodySize, maxTools, readOnly, allowLoad)
+
+	s := &OpenAPIServer{
+		client:      client,
+		maxBodySize: maxBodySize,
+		maxTools:    maxTools,
+		readOnly:    readOnly,
+		apis:        map[string]*API{},
+		tools:       map[string]bool{"listSpecs": true},
+	}
+
+	mcpServer := server.NewMCPServer(
+		"openapi-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register listSpecs tool
+	listTool := params.Tool[listArgs]("listSpecs",
+		mcp.WithDescription("Lists the APIs loaded from OpenAPI specs, with their tools"),
+	)
+	middleware.AddTool(mcpServer, listTool, s.handleListSpecs)
+
+	if allowLoad {
+		s.tools["loadSpec"], s.tools["unloadSpec"] = true, true
+
+		// Register loadSpec tool
+		loadTool := params.Tool[loadArgs]("loadSpec",
+			mcp.WithDescription("Loads an OpenAPI 3 or Swagger 2 spec, in JSON or YAML, from a URL or its content, and adds a tool for each of its operations, which calls the API. The requests are not authenticated"),
+		)
+		middleware.AddTool(mcpServer, loadTool, s.handleLoadSpec)
+
+		// Register unloadSpec tool
+		unloadTool := params.Tool[unloadArgs]("unloadSpec",
+			mcp.WithDescription("Unloads an API loaded with loadSpec, removing its tools"),
+		)
+		middleware.AddTool(mcpServer, unloadTool, s.handleUnloadSpec)
+	}
+
+	s.server = mcpServer
+	return s
+}
+
+// readSpec returns the document at source, a URL, or a file if files is set.
+func (s *OpenAPIServer) readSpec(ctx context.Context, source string, files bool) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if !files {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid spec URL %q", source)
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading spec: %w", err)
+		}
+		return data, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid spec URL %q: %v", source, err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching spec: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, statusError(&Operation{Method: http.MethodGet, Path: u.Redacted()}, resp, "")
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecSize+1))
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.Unavailable, "error fetching spec: %v", err)
+	}
+	if len(data) > maxSpecSize {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "the spec exceeds the maximum size of %d bytes", maxSpecSize)
+	}
+	return data, nil
+}
+
+// included reports whether the operation op is selected by the patterns include.
+func included(op *Operation, include []string) bool {
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		for _, name := range []string{op.ID, op.Path} {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Load adds the tools of the operations of spec, read from source, as the API name,
+// replacing the API of that name loaded by the client.
+func (s *OpenAPIServer) Load(name, source string, spec *Spec, o LoadOptions) (*API, error) {
+	api := &API{
+		Name:        name,
+		Title:       spec.Title,
+		Version:     spec.Version,
+		Source:      source,
+		BaseURL:     strings.TrimSuffix(spec.BaseURL, "/"),
+		Startup:     o.Startup,
+		spec:        spec,
+		credentials: o.Credentials,
+	}
+	if o.BaseURL != "" {
+		api.BaseURL = strings.TrimSuffix(o.BaseURL, "/")
+	}
+	if api.BaseURL == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "the spec of %s has no absolute server URL; set the base URL of the API", name)
+	}
+	if u, err := url.Parse(api.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid base URL %q of %s: expected an http or https URL", api.BaseURL, name)
+	}
+
+	var ops []*Operation
+	for _, op := range spec.Operations {
+		if (s.readOnly && !op.readOnly()) || !included(op, o.Include) {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) == 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "no operations of %s are selected", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.apis[name]
+	if previous != nil && previous.Startup && !o.Startup {
+		return nil, toolerr.Errorf(toolerr.PermissionDenied, "API %s is loaded at startup and cannot be replaced", name)
+	}
+	count := 0
+	for _, api := range s.apis {
+		if api != previous {
+			count += len(api.Tools)
+		}
+	}
+	if count+len(ops) > s.maxTools {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "%s has %d operations, more tools than the %d left; select some of them", name, len(ops), s.maxTools-count)
+	}
+	if previous != nil {
+		s.remove(previous)
+	}
+
+	tools := make([]server.ServerTool, 0, len(ops))
+	for _, op := range ops {
+		toolName := ToolName(o.Prefix, op)
+		base := toolName
+		for i := 2; s.tools[toolName]; i++ {
+			suffix := "_" + strconv.Itoa(i)
+			toolName = base[:min(len(base), maxNameLength-len(suffix))] + suffix
+		}
+		s.tools[toolName] = true
+		api.Tools = append(api.Tools, toolName)
+		api.operations = append(api.operations, op)
+		tools = append(tools, server.ServerTool{Tool: op.tool(toolName), Handler: s.call(api, op)})
+	}
+	s.apis[name] = api
+	for _, t := range tools {
+		middleware.AddTool(s.server, t.Tool, t.Handler)
+	}
+	log.Printf("Loaded API %s from %s: %d tools calling %s", name, source, len(api.Tools), api.BaseURL)
+	return api, nil
+}
+
+// remove removes the tools of api. The caller holds mu.
+func (s *OpenAPIServer) remove(api *API) {
+	for _, name := range api.Tools {
+		delete(s.tools, name)
+	}
+	delete(s.apis, api.Name)
+	s.server.DeleteTools(api.Tools...)
+	log.Printf("Unloaded API %s", api.Name)
+}
+
+// call returns the handler of the tool of op, calling api.
+func (s *OpenAPIServer) call(api *API, op *Operation) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		log.Printf("Calling %s %s of %s", op.Method, op.Path, api.Name)
+
+		req, err := op.newRequest(ctx, api.BaseURL, request.Params.Arguments)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		api.credentials.authorize(req, api.spec, op)
+		resp, err := s.client.Do(req)
+		if err != nil {
+			log.Printf("Error: %s %s failed: %v", op.Method, op.Path, err)
+			return nil, fmt.Errorf("%s %s failed: %w", op.Method, op.Path, err)
+		}
+		defer resp.Body.Close()
+		body, err := readBody(resp, s.maxBodySize)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			log.Printf("Error: %s %s returned %s", op.Method, op.Path, resp.Status)
+			return nil, statusError(op, resp, body)
+		}
+
+		text := "HTTP " + resp.Status
+		if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+			text += "\nContent-Type: " + contentType
+		}
+		if location := resp.Header.Get("Location"); location != "" {
+			text += "\nLocation: " + location
+		}
+		if body != "" {
+			text += "\n\n" + body
+		}
+		log.Printf("%s %s returned %s", op.Method, op.Path, resp.Status)
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+// loadArgs are the parameters of loadSpec.
+type loadArgs struct {
+	URL     string `json:"url,omitempty" description:"URL of the spec, JSON or YAML"`
+	Spec    string `json:"spec,omitempty" description:"Content of the spec, instead of url"`
+	Name    string `json:"name,omitempty" description:"Name of the API, replacing the API of that name loaded with loadSpec (default: from the title of the spec)"`
+	Prefix  string `json:"prefix,omitempty" description:"Prefix of the names of the tools, e.g. petstore_ (default: none)"`
+	BaseURL string `json:"baseUrl,omitempty" description:"Base URL of the API (default: the first server of the spec)"`
+	Include string `json:"include,omitempty" description:"Comma separated patterns of the operation IDs or paths to load, e.g. get*,/pets/* (default: all)"`
+}
+
+// unloadArgs are the parameters of unloadSpec.
+type unloadArgs struct {
+	Name string `json:"name" param:"required" description:"Name of the API"`
+}
+
+// listArgs are the parameters of listSpecs.
+type listArgs struct {
+	Format string `json:"format,omitempty" param:"enum=text|markdown|json" description:"Output format (default: text)"`
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// apiName returns the name of an API titled title.
+func apiName(title string) string {
+	name := strings.ToLower(sanitize(strings.ReplaceAll(title, " ", "_"), 32))
+	if name == "" {
+		return "api"
+	}
+	return name
+}
+
+// handleLoadSpec handles the spec loading request.
+func (s *OpenAPIServer) handleLoadSpec(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting loadSpec request processing")
+
+	var args loadArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if (args.URL == "") == (args.Spec == "") {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "either url or spec is required")
+	}
+	if args.Prefix != "" && (sanitize(args.Prefix, maxNameLength) != strings.Trim(args.Prefix, "_") || len(args.Prefix) > maxNameLength/2) {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid prefix %q: expected at most %d letters, digits, _, . or -", args.Prefix, maxNameLength/2)
+	}
+
+	data, source := []byte(args.Spec), "content"
+	if args.URL != "" {
+		var err error
+		if data, err = s.readSpec(ctx, args.URL, false); err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		source = args.URL
+	} else if len(data) > maxSpecSize {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "the spec exceeds the maximum size of %d bytes", maxSpecSize)
+	}
+	spec, err := Parse(data, args.URL)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "%v", err)
+	}
+	name := args.Name
+	if name == "" {
+		name = apiName(spec.Title)
+	}
+	api, err := s.Load(name, source, spec, LoadOptions{Prefix: args.Prefix, BaseURL: args.BaseURL, Include: splitList(args.Include)})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Loaded %s %s as %s, calling %s\nTools:", api.Title, api.Version, api.Name, api.BaseURL)
+	for i, op := range api.operations {
+		fmt.Fprintf(&text, "\n- %s: %s %s", api.Tools[i], op.Method, op.Path)
+	}
+
+	log.Printf("loadSpec request completed: %s", api.Name)
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// handleUnloadSpec handles the spec unloading request.
+func (s *OpenAPIServer) handleUnloadSpec(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting unloadSpec request processing")
+
+	var args unloadArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	api, ok := s.apis[args.Name]
+	if !ok {
+		return nil, toolerr.Errorf(toolerr.NotFound, "API %s is not loaded", args.Name)
+	}
+	if api.Startup {
+		return nil, toolerr.Errorf(toolerr.PermissionDenied, "API %s is loaded at startup and cannot be unloaded", args.Name)
+	}
+	s.remove(api)
+
+	log.Printf("unloadSpec request completed: %s", args.Name)
+	return mcp.NewToolResultText(fmt.Sprintf("Unloaded %s, removing %d tools", args.Name, len(api.Tools))), nil
+}
+
+// handleListSpecs handles the spec listing request.
+func (s *OpenAPIServer) handleListSpecs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listSpecs request processing")
+
+	var args listArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	list := make([]API, 0, len(s.apis))
+	for _, api := range s.apis {
+		list = append(list, *api)
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	table := format.Table{Columns: []string{"Name", "Title", "Version", "Base URL", "Source", "Tools"}}
+	for _, api := range list {
+		table.Rows = append(table.Rows, []string{api.Name, api.Title, api.Version, api.BaseURL, api.Source, strings.Join(api.Tools, ", ")})
+	}
+	text, markdown := table.Text(), table.Markdown()
+	if len(list) == 0 {
+		text, markdown = "No APIs loaded", "No APIs loaded"
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: list, Text: text, Markdown: markdown})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("listSpecs request completed: %d APIs", len(list))
+	return result, nil
+}
+
+// Server returns the underlying MCP server
+func (s *OpenAPIServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the openapi server from the command line arguments in args, without
+// serving it.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	var (
+		specs       string
+		baseURL     string
+		prefix      string
+		include     string
+		credentials Credentials
+		readOnly    bool
+		allowLoad   bool
+		maxTools    int
+		timeout     int
+		maxBodySize int64
+	)
+	fs.StringVar(&specs, "spec", "", "Comma separated files or URLs of OpenAPI 3 or Swagger 2 specs, JSON or YAML, whose operations are tools")
+	fs.StringVar(&baseURL, "api-url", "", "Base URL of the APIs of -spec (default: the first server of each spec)")
+	fs.StringVar(&prefix, "tool-prefix", "", "Prefix of the names of the tools of -spec")
+	fs.StringVar(&include, "include", "", "Comma separated patterns of the operation IDs or paths of -spec to load, e.g. get*,/pets/* (default: all)")
+	fs.StringVar(&credentials.APIKey, "api-key", "", "API key of the APIs of -spec, sent as their apiKey security schemes tell")
+	fs.StringVar(&credentials.BearerToken, "bearer-token", "", "Bearer token of the APIs of -spec, for their bearer, OAuth2 and OpenID Connect security schemes")
+	fs.StringVar(&credentials.Username, "username", "", "Username of the APIs of -spec, for their basic security schemes")
+	fs.StringVar(&credentials.Password, "password", "", "Password of the APIs of -spec, for their basic security schemes")
+	fs.BoolVar(&readOnly, "read-only", false, "Only load the GET, HEAD and OPTIONS operations")
+	fs.BoolVar(&allowLoad, "allow-load", true, "Offer the loadSpec and unloadSpec tools, letting the client load specs from URLs, without credentials")
+	fs.IntVar(&maxTools, "max-tools", 100, "Maximum number of tools of the operations of all specs")
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 1024*1024, "Maximum size of the responses returned in bytes (default 1MB)")
+	var upstreamFlags httpclient.Flags
+	upstreamFlags.Register(fs)
+	var guardFlags urlguard.Flags
+	guardFlags.Register(fs)
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	if maxTools < 1 || maxBodySize < 1 {
+		return nil, transport.Flags{}, errors.New("-max-tools and -max-body-size must be positive")
+	}
+	if credentials.Password != "" && credentials.Username == "" {
+		return nil, transport.Flags{}, errors.New("-password requires -username")
+	}
+
+	log.Printf("Starting openapi server: specs=%s, timeout=%ds, max-tools=%d, read-only=%t", specs, timeout, maxTools, readOnly)
+
+	options := httpclient.Options{Timeout: time.Duration(timeout) * time.Second, UserAgent: "mcphost-openapi/1.0"}
+	if err := upstreamFlags.Apply(&options); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	guard, err := guardFlags.Policy()
+	if err != nil {
+		return nil, transport.Flags{}, err
+	}
+	options.Guard = guard
+
+	// Create OpenAPIServer instance
+	openAPIServer := NewOpenAPIServer(httpclient.New(options), maxBodySize, maxTools, readOnly, allowLoad)
+	for _, source := range splitList(specs) {
+		data, err := openAPIServer.readSpec(ctx, source, true)
+		if err != nil {
+			return nil, transport.Flags{}, fmt.Errorf("error loading spec %s: %w", source, err)
+		}
+		spec, err := Parse(data, source)
+		if err != nil {
+			return nil, transport.Flags{}, fmt.Errorf("error loading spec %s: %w", source, err)
+		}
+		name := apiName(spec.Title)
+		for i := 2; openAPIServer.apis[name] != nil; i++ {
+			name = apiName(spec.Title) + strconv.Itoa(i)
+		}
+		o := LoadOptions{Prefix: prefix, BaseURL: baseURL, Include: splitList(include), Credentials: credentials, Startup: true}
+		if _, err := openAPIServer.Load(name, source, spec, o); err != nil {
+			return nil, transport.Flags{}, fmt.Errorf("error loading spec %s: %w", source, err)
+		}
+	}
+	if specs == "" && !allowLoad {
+		log.Printf("Warning: No specs configured. Use -spec or -allow-load to load specs.")
+	}
+	log.Println("OpenAPIServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), openAPIServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return openAPIServer.Server(), transportFlags, nil
+}
+
+// Run starts the openapi server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[OpenAPIServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create openapi server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("OpenAPIServer shutdown")
+	return nil
+}
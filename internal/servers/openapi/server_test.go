@@ -0,0 +1,400 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// petstore is an OpenAPI 3 spec with references, parameters of every kind, a body and
+// security schemes.
+const petstore = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.2.0
+servers:
+  - url: /{version}
+    variables:
+      version:
+        default: v1
+security:
+  - apiKey: []
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    bearer:
+      type: http
+      scheme: bearer
+  parameters:
+    PetID:
+      name: petId
+      in: path
+      required: true
+      schema:
+        type: integer
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Pet'
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      parameters:
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: filter
+          in: query
+          style: deepObject
+          schema:
+            type: object
+        - name: X-Request-ID
+          in: header
+          schema:
+            type: string
+      responses:
+        200:
+          description: The pets
+    post:
+      operationId: createPet
+      summary: Create a pet
+      security:
+        - bearer: []
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        201:
+          description: Created
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetID'
+    get:
+      operationId: get pet
+      description: Returns a pet by ID
+      responses:
+        200:
+          description: The pet
+    delete:
+      security: []
+      responses:
+        204:
+          description: Deleted
+  /upload:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+      responses:
+        200:
+          description: Uploaded
+`
+
+// swagger is a Swagger 2 spec with a body and form parameters.
+const swagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy", "version": "1"},
+  "host": "api.example.com",
+  "basePath": "/api",
+  "schemes": ["http", "https"],
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "security": [{"basic": []}],
+  "paths": {
+    "/items/{id}": {
+      "put": {
+        "operationId": "putItem",
+        "parameters": [
+          {"name": "id", "in": "path", "type": "string"},
+          {"name": "ids", "in": "query", "type": "array", "items": {"type": "integer"}, "collectionFormat": "csv"},
+          {"name": "body", "in": "body", "required": true, "schema": {"type": "object"}}
+        ]
+      }
+    },
+    "/login": {
+      "post": {
+        "consumes": ["application/x-www-form-urlencoded"],
+        "parameters": [
+          {"name": "user", "in": "formData", "type": "string", "required": true},
+          {"name": "body", "in": "query", "type": "string"}
+        ]
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(petstore), "https://pets.example.com/docs/openapi.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "Pet Store", spec.Title)
+	assert.Equal(t, "https://pets.example.com/v1", spec.BaseURL)
+	assert.Equal(t, SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}, spec.SecuritySchemes["apiKey"])
+
+	// The multipart upload is left out
+	var ids []string
+	for _, op := range spec.Operations {
+		ids = append(ids, op.Method+" "+op.ID)
+	}
+	assert.Equal(t, []string{"GET listPets", "POST createPet", "GET get pet", "DELETE delete/pets/{petId}"}, ids)
+
+	list := spec.Operations[0]
+	require.Len(t, list.Parameters, 3)
+	assert.True(t, list.Parameters[0].Explode)
+	assert.Equal(t, "deepObject", list.Parameters[1].Style)
+	assert.Nil(t, list.Security)
+
+	create := spec.Operations[1]
+	require.NotNil(t, create.Body)
+	assert.Equal(t, "application/json", create.Body.ContentType)
+	parent := create.Body.Schema["properties"].(map[string]interface{})["parent"].(map[string]interface{})
+	assert.Equal(t, "Recursive Pet", parent["description"])
+
+	// Path parameters are inherited and required
+	get := spec.Operations[2]
+	require.Len(t, get.Parameters, 1)
+	assert.Equal(t, "petId", get.Parameters[0].Arg)
+	assert.True(t, get.Parameters[0].Required)
+	assert.Equal(t, "get_pet", ToolName("", get))
+	assert.Equal(t, "pets.delete_pets_petId", ToolName("pets.", spec.Operations[3]))
+	assert.Equal(t, []map[string][]string{}, spec.Operations[3].Security)
+
+	// Relative server URLs of files are left to the user
+	spec, err = Parse([]byte(petstore), "openapi.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, spec.BaseURL)
+
+	spec, err = Parse([]byte(swagger), "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/api", spec.BaseURL)
+	assert.Equal(t, "basic", spec.SecuritySchemes["basic"].Scheme)
+	put := spec.Operations[0]
+	assert.Equal(t, "putItem", put.ID)
+	assert.Equal(t, map[string]interface{}{"type": "object"}, put.Body.Schema)
+	assert.False(t, put.Parameters[1].Explode)
+	login := spec.Operations[1]
+	assert.Equal(t, "application/x-www-form-urlencoded", login.Body.ContentType)
+	assert.Equal(t, []interface{}{"user"}, login.Body.Schema["required"])
+	// The query parameter called body gets another argument
+	assert.Equal(t, "query_body", login.Parameters[0].Arg)
+
+	for _, doc := range []string{"", "[1]", "openapi: 3.0.0\npaths: {}", "swagger: '1.2'\npaths: {}", "{"} {
+		_, err := Parse([]byte(doc), "")
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestParseReferenceBomb(t *testing.T) {
+	// Each schema refers to the next twice, doubling the size of the inlined schema:
+	// the operation is left out, not the others
+	doc := "openapi: 3.0.0\ninfo: {title: Bomb}\npaths:\n  /a:\n    get: {}\n    post:\n      requestBody:\n        content:\n          application/json:\n            schema: {$ref: '#/components/schemas/S0'}\ncomponents:\n  schemas:\n"
+	for i := 0; i < 30; i++ {
+		doc += "    S" + strconv.Itoa(i) + ":\n      properties:\n        a: {$ref: '#/components/schemas/S" + strconv.Itoa(i+1) + "'}\n        b: {$ref: '#/components/schemas/S" + strconv.Itoa(i+1) + "'}\n"
+	}
+	spec, err := Parse([]byte(doc), "")
+	require.NoError(t, err)
+	require.Len(t, spec.Operations, 1)
+	assert.Equal(t, "GET", spec.Operations[0].Method)
+}
+
+// received is a request received by the test API.
+type received struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+}
+
+// newTestAPI starts an API recording its requests and answering them with JSON, 404
+// Not Found for pet 404.
+func newTestAPI(t *testing.T) (*httptest.Server, func() received) {
+	var mu sync.Mutex
+	var last received
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		last = received{Method: r.Method, URL: r.URL.String(), Header: r.Header.Clone(), Body: string(body)}
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/v1/pets/404":
+			http.Error(w, `{"error":"no such pet"}`, http.StatusNotFound)
+		case r.URL.Path == "/openapi.yaml":
+			w.Write([]byte(petstore))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":1,"name":"Rex"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, func() received {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+// newTestServer returns a server allowed to request local APIs.
+func newTestServer(maxTools int, readOnly bool) *OpenAPIServer {
+	client := httpclient.New(httpclient.Options{Guard: &urlguard.Policy{AllowPrivate: true}})
+	return NewOpenAPIServer(client, 1024, maxTools, readOnly, true)
+}
+
+func TestTools(t *testing.T) {
+	ts, last := newTestAPI(t)
+	s := newTestServer(100, false)
+	spec, err := Parse([]byte(petstore), ts.URL+"/openapi.yaml")
+	require.NoError(t, err)
+	_, err = s.Load("pets", "test", spec, LoadOptions{Credentials: Credentials{APIKey: "key123", BearerToken: "token123"}, Startup: true})
+	require.NoError(t, err)
+	c := mcptest.Connect(t, s.Server())
+
+	tool := c.Tool("listPets")
+	assert.Contains(t, tool.Description, "GET /pets")
+	assert.Contains(t, tool.InputSchema.Properties, "X-Request-ID")
+	create := c.Tool("createPet")
+	assert.Equal(t, []string{"body"}, create.InputSchema.Required)
+
+	text := c.Text("listPets", map[string]interface{}{"tag": []interface{}{"a", "b"}, "filter": map[string]interface{}{"age": 2}, "X-Request-ID": "r1"})
+	assert.Contains(t, text, "HTTP 200 OK")
+	assert.Contains(t, text, "\"name\": \"Rex\"")
+	req := last()
+	assert.Equal(t, "/v1/pets?filter%5Bage%5D=2&tag=a&tag=b", req.URL)
+	assert.Equal(t, "r1", req.Header.Get("X-Request-ID"))
+	assert.Equal(t, "key123", req.Header.Get("X-API-Key"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	c.Text("createPet", map[string]interface{}{"body": map[string]interface{}{"name": "Rex"}})
+	req = last()
+	assert.Equal(t, "POST", req.Method)
+	assert.JSONEq(t, `{"name":"Rex"}`, req.Body)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token123", req.Header.Get("Authorization"))
+	assert.Empty(t, req.Header.Get("X-API-Key"))
+
+	// Operations without security send no credentials
+	c.Text("delete_pets_petId", map[string]interface{}{"petId": 7})
+	req = last()
+	assert.Equal(t, "DELETE /v1/pets/7", req.Method+" "+req.URL)
+	assert.Empty(t, req.Header.Get("X-API-Key"))
+
+	assert.Contains(t, c.Error("get_pet", map[string]interface{}{"petId": "404"}), "returned 404 Not Found: {\n  \"error\": \"no such pet\"\n}")
+	assert.Contains(t, c.Error("get_pet", map[string]interface{}{}), "petId is required")
+	assert.Contains(t, c.Error("createPet", map[string]interface{}{}), "body is required")
+
+	// Path arguments are escaped
+	c.Text("get_pet", map[string]interface{}{"petId": "a/b?c"})
+	assert.Equal(t, "/v1/pets/a%2Fb%3Fc", last().URL)
+
+	var apis []API
+	require.NoError(t, json.Unmarshal([]byte(c.Text("listSpecs", map[string]interface{}{"format": "json"})), &apis))
+	require.Len(t, apis, 1)
+	assert.Equal(t, ts.URL+"/v1", apis[0].BaseURL)
+	assert.Equal(t, []string{"listPets", "createPet", "get_pet", "delete_pets_petId"}, apis[0].Tools)
+	assert.Contains(t, c.Error("unloadSpec", map[string]interface{}{"name": "pets"}), "loaded at startup")
+}
+
+func TestLoadSpec(t *testing.T) {
+	ts, last := newTestAPI(t)
+	s := newTestServer(4, true)
+	c := mcptest.Connect(t, s.Server())
+
+	text := c.Text("loadSpec", map[string]interface{}{"url": ts.URL + "/openapi.yaml", "prefix": "pets."})
+	assert.Contains(t, text, "Loaded Pet Store 1.2.0 as pet_store, calling "+ts.URL+"/v1\nTools:\n- pets.listPets: GET /pets\n")
+	assert.Contains(t, text, "- pets.get_pet: GET /pets/{petId}")
+
+	// Specs loaded by the client send no credentials
+	c.Text("pets.listPets", map[string]interface{}{})
+	assert.Empty(t, last().Header.Get("X-API-Key"))
+
+	// Loading an API again replaces its tools
+	text = c.Text("loadSpec", map[string]interface{}{"spec": petstore, "name": "pet_store", "baseUrl": ts.URL + "/v1", "include": "list*"})
+	assert.NotContains(t, text, "get_pet")
+	var names []string
+	for _, tool := range c.ListTools() {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"listSpecs", "loadSpec", "unloadSpec", "listPets"}, names)
+
+	// Tool names are made unique
+	text = c.Text("loadSpec", map[string]interface{}{"spec": petstore, "name": "other", "baseUrl": ts.URL + "/v1"})
+	assert.Contains(t, text, "- listPets_2: GET /pets")
+
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{"spec": petstore, "name": "third", "baseUrl": ts.URL}), "more tools than the 1 left")
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{"spec": petstore}), "no absolute server URL")
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{}), "either url or spec is required")
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{"spec": "openapi: 3.0.0"}), "no operations")
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{"url": "/etc/openapi.yaml"}), "invalid spec URL")
+
+	assert.Contains(t, c.Text("unloadSpec", map[string]interface{}{"name": "other"}), "Unloaded other, removing 2 tools")
+	assert.Contains(t, c.Error("unloadSpec", map[string]interface{}{"name": "other"}), "not loaded")
+}
+
+func TestNew(t *testing.T) {
+	ts, last := newTestAPI(t)
+	file := filepath.Join(t.TempDir(), "swagger.json")
+	require.NoError(t, os.WriteFile(file, []byte(swagger), 0o600))
+
+	srv, _, err := New(context.Background(), []string{"-spec", file, "-api-url", ts.URL + "/api", "-username", "user", "-password", "pass", "-url-allow-private", "-allow-load=false"})
+	require.NoError(t, err)
+	c := mcptest.Connect(t, srv)
+	assert.Contains(t, c.Text("post_login", map[string]interface{}{"body": map[string]interface{}{"user": "ann"}, "query_body": "q"}), "HTTP 200 OK")
+	req := last()
+	assert.Equal(t, "/api/login?body=q", req.URL)
+	assert.Equal(t, "user=ann", req.Body)
+	username, password, _ := (&http.Request{Header: req.Header}).BasicAuth()
+	assert.Equal(t, "user:pass", username+":"+password)
+
+	c.Text("putItem", map[string]interface{}{"id": "x", "ids": []interface{}{1, 2}, "body": `{"a":1}`})
+	req = last()
+	assert.Equal(t, "/api/items/x?ids=1%2C2", req.URL)
+	assert.Equal(t, `{"a":1}`, req.Body)
+	assert.Contains(t, c.Error("loadSpec", map[string]interface{}{"spec": swagger}), "not found")
+
+	_, _, err = New(context.Background(), []string{"-spec", file, "-include", "none"})
+	assert.ErrorContains(t, err, "no operations of legacy are selected")
+	_, _, err = New(context.Background(), []string{"-spec", filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+}
+
+func FuzzTools(f *testing.F) {
+	s := newTestServer(100, false)
+	spec, err := Parse([]byte(petstore), "https://pets.example.com/openapi.yaml")
+	if err != nil {
+		f.Fatal(err)
+	}
+	if _, err := s.Load("pets", "test", spec, LoadOptions{}); err != nil {
+		f.Fatal(err)
+	}
+	mcptest.FuzzTools(f, s.Server())
+}
@@ -0,0 +1,578 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is an API described by an OpenAPI 3 or Swagger 2 document, reduced to what its
+// tools need.
+type Spec struct {
+	Title       string
+	Version     string
+	Description string
+	// BaseURL is the URL of the first server of the document, empty if unknown.
+	BaseURL    string
+	Operations []*Operation
+	// SecuritySchemes are the security schemes of the document by name, and Security
+	// the requirements of the operations that have none of their own.
+	SecuritySchemes map[string]SecurityScheme
+	Security        []map[string][]string
+}
+
+// Operation is an operation of an API, a tool of the server.
+type Operation struct {
+	// ID is the operationId of the operation, or its method and path.
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Deprecated  bool
+	Parameters  []*Parameter
+	Body        *Body
+	// Security are the alternative requirements of the operation, nil for those of
+	// the spec.
+	Security []map[string][]string
+}
+
+// Parameter is a path, query, header or cookie parameter of an operation.
+type Parameter struct {
+	Name        string
+	In          string
+	Required    bool
+	Description string
+	// Style and Explode tell how arrays and objects are serialized in the query.
+	Style   string
+	Explode bool
+	Schema  map[string]interface{}
+	// Arg is the argument of the tool, Name unless it is not a valid property name
+	// or clashes with another.
+	Arg string
+}
+
+// Body is the request body of an operation.
+type Body struct {
+	ContentType string
+	Required    bool
+	Description string
+	Schema      map[string]interface{}
+}
+
+// SecurityScheme is a way an API authenticates requests.
+type SecurityScheme struct {
+	// Type is apiKey, http, oauth2 or openIdConnect.
+	Type string
+	// Scheme is the scheme of http security, basic or bearer.
+	Scheme string
+	// In and Name are the place and name of the key of apiKey security.
+	In   string
+	Name string
+}
+
+// methods are the methods of operations, in the order tools are listed.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Limits of the documents, against specs crafted to exhaust the server.
+const (
+	// maxRefDepth is the depth of nested references inlined; deeper schemas are left
+	// open.
+	maxRefDepth = 16
+	// maxSchemaNodes is the number of values of the parameters and body of an
+	// operation once their references are inlined; larger operations are left out.
+	maxSchemaNodes = 20000
+)
+
+// normalize converts the maps decoded from YAML with keys that are not strings, e.g.
+// the status codes of responses, to maps with string keys, as in JSON.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+	}
+	return v
+}
+
+// resolver inlines the local references of a document.
+type resolver struct {
+	doc   map[string]interface{}
+	nodes int
+}
+
+// errTooLarge is the error of operations whose schemas are too large once inlined.
+var errTooLarge = errors.New("the schemas of the operation are too large once their references are inlined")
+
+// lookup returns the value of ref, a JSON pointer in the document such as
+// #/components/schemas/Pet.
+func (r *resolver) lookup(ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var v interface{} = r.doc
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// resolve returns v with its references inlined. References to the schemas being
+// inlined, in refs, and references too deep are replaced by open schemas.
+func (r *resolver) resolve(v interface{}, refs []string) (interface{}, error) {
+	if r.nodes++; r.nodes > maxSchemaNodes {
+		return nil, errTooLarge
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			for _, seen := range refs {
+				if seen == ref {
+					return map[string]interface{}{"description": "Recursive " + refName(ref)}, nil
+				}
+			}
+			target, ok := r.lookup(ref)
+			if !ok || len(refs) >= maxRefDepth {
+				return map[string]interface{}{"description": "Unresolved " + refName(ref)}, nil
+			}
+			return r.resolve(target, append(refs, ref))
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			resolved, err := r.resolve(e, refs)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = resolved
+		}
+		return m, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			resolved, err := r.resolve(e, refs)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	}
+	return v, nil
+}
+
+// deref returns the value v refers to if it is a reference, without inlining the
+// references it has.
+func (r *resolver) deref(v interface{}) map[string]interface{} {
+	for i := 0; i < maxRefDepth; i++ {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		if v, ok = r.lookup(ref); !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+// object resolves v as an object, nil if it is not one.
+func (r *resolver) object(v interface{}) (map[string]interface{}, error) {
+	resolved, err := r.resolve(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := resolved.(map[string]interface{})
+	return m, nil
+}
+
+// refName returns the name of the schema ref refers to.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// str returns m[key] as a string.
+func str(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// Parse parses an OpenAPI 3 or Swagger 2 document, in JSON or YAML, read from
+// location, a URL or a file. Relative server URLs are resolved against location when
+// it is a URL.
+func Parse(data []byte, location string) (*Spec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	doc, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid OpenAPI document: not an object")
+	}
+	r := &resolver{doc: doc}
+
+	info, _ := doc["info"].(map[string]interface{})
+	spec := &Spec{
+		Title:           str(info, "title"),
+		Version:         str(info, "version"),
+		Description:     str(info, "description"),
+		SecuritySchemes: map[string]SecurityScheme{},
+		Security:        requirements(doc["security"]),
+	}
+	var schemes map[string]interface{}
+	var err error
+	switch {
+	case strings.HasPrefix(fmt.Sprint(doc["openapi"]), "3."):
+		spec.BaseURL, err = serverURL(doc, location)
+		components, _ := doc["components"].(map[string]interface{})
+		schemes, _ = components["securitySchemes"].(map[string]interface{})
+	case fmt.Sprint(doc["swagger"]) == "2.0":
+		spec.BaseURL, err = swaggerURL(doc, location)
+		schemes, _ = doc["securityDefinitions"].(map[string]interface{})
+	default:
+		return nil, errors.New("invalid OpenAPI document: expected openapi 3.x or swagger 2.0")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range schemes {
+		m, err := r.object(v)
+		if err != nil {
+			return nil, err
+		}
+		scheme := SecurityScheme{Type: str(m, "type"), Scheme: strings.ToLower(str(m, "scheme")), In: str(m, "in"), Name: str(m, "name")}
+		// Swagger 2 calls basic authentication a type
+		if scheme.Type == "basic" {
+			scheme.Type, scheme.Scheme = "http", "basic"
+		}
+		spec.SecuritySchemes[name] = scheme
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+	for _, path := range pathNames {
+		item := r.deref(paths[path])
+		for _, method := range methods {
+			raw, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			r.nodes = 0
+			op, err := parseOperation(r, doc, method, path, item, raw)
+			if errors.Is(err, errTooLarge) {
+				log.Printf("Warning: Skipping %s %s: %v", strings.ToUpper(method), path, err)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			if op != nil {
+				spec.Operations = append(spec.Operations, op)
+			}
+		}
+	}
+	if len(spec.Operations) == 0 {
+		return nil, errors.New("the OpenAPI document has no operations")
+	}
+	return spec, nil
+}
+
+// serverVariable matches the variables of server URLs.
+var serverVariable = regexp.MustCompile(`\{([^}]+)\}`)
+
+// serverURL returns the URL of the first server of an OpenAPI 3 document, with the
+// default values of its variables.
+func serverURL(doc map[string]interface{}, location string) (string, error) {
+	servers, _ := doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return resolveURL("/", location), nil
+	}
+	server, _ := servers[0].(map[string]interface{})
+	variables, _ := server["variables"].(map[string]interface{})
+	u := serverVariable.ReplaceAllStringFunc(str(server, "url"), func(v string) string {
+		variable, _ := variables[v[1:len(v)-1]].(map[string]interface{})
+		if def, ok := variable["default"]; ok {
+			return fmt.Sprint(def)
+		}
+		return v
+	})
+	return resolveURL(u, location), nil
+}
+
+// swaggerURL returns the base URL of a Swagger 2 document, from its host, base path
+// and schemes, preferring https.
+func swaggerURL(doc map[string]interface{}, location string) (string, error) {
+	host := str(doc, "host")
+	scheme := "https"
+	if schemes, _ := doc["schemes"].([]interface{}); len(schemes) > 0 {
+		scheme = fmt.Sprint(schemes[0])
+		for _, s := range schemes {
+			if s == "https" {
+				scheme = "https"
+			}
+		}
+	}
+	path := "/" + strings.TrimPrefix(str(doc, "basePath"), "/")
+	if host == "" {
+		return resolveURL(path, location), nil
+	}
+	return scheme + "://" + host + path, nil
+}
+
+// resolveURL resolves u against location when location is a URL. Relative URLs of
+// specs read from files are left empty, to be set by the user.
+func resolveURL(u, location string) string {
+	if parsed, err := url.Parse(u); err == nil && parsed.IsAbs() {
+		return u
+	}
+	base, err := url.Parse(location)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return ""
+	}
+	ref, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// requirements returns the security requirements in v.
+func requirements(v interface{}) []map[string][]string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	reqs := []map[string][]string{}
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		req := map[string][]string{}
+		for name, scopes := range m {
+			var s []string
+			if list, ok := scopes.([]interface{}); ok {
+				for _, scope := range list {
+					s = append(s, fmt.Sprint(scope))
+				}
+			}
+			req[name] = s
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// parseOperation parses the operation raw of method and path in the path item item.
+// Operations whose body cannot be sent are left out, returning nil.
+func parseOperation(r *resolver, doc map[string]interface{}, method, path string, item, raw map[string]interface{}) (*Operation, error) {
+	op := &Operation{
+		ID:          str(raw, "operationId"),
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Summary:     str(raw, "summary"),
+		Description: str(raw, "description"),
+		Deprecated:  raw["deprecated"] == true,
+	}
+	if op.ID == "" {
+		op.ID = method + path
+	}
+	if _, ok := raw["security"]; ok {
+		op.Security = requirements(raw["security"])
+	}
+
+	// Parameters of the operation override those of the path with the same name
+	var params []interface{}
+	if list, ok := item["parameters"].([]interface{}); ok {
+		params = append(params, list...)
+	}
+	if list, ok := raw["parameters"].([]interface{}); ok {
+		params = append(params, list...)
+	}
+	index := map[string]int{}
+	var formParams []*Parameter
+	for _, v := range params {
+		m, err := r.object(v)
+		if err != nil {
+			return nil, err
+		}
+		p := &Parameter{
+			Name:        str(m, "name"),
+			In:          str(m, "in"),
+			Required:    m["required"] == true,
+			Description: str(m, "description"),
+			Style:       str(m, "style"),
+		}
+		schema, _ := m["schema"].(map[string]interface{})
+		switch p.In {
+		case "body":
+			// Swagger 2 request bodies are parameters
+			op.Body = &Body{ContentType: consumes(doc, raw, "application/json"), Required: p.Required, Description: p.Description, Schema: schema}
+			continue
+		case "formData":
+			p.Schema = swaggerSchema(m)
+			formParams = append(formParams, p)
+			continue
+		case "path", "query", "header", "cookie":
+		default:
+			continue
+		}
+		if schema == nil {
+			// Swagger 2 parameters have their schema inline, and a collection format
+			// instead of a style
+			schema = swaggerSchema(m)
+			p.Style = map[string]string{"ssv": "spaceDelimited", "pipes": "pipeDelimited"}[str(m, "collectionFormat")]
+			p.Explode = str(m, "collectionFormat") == "multi"
+		} else {
+			explode, ok := m["explode"].(bool)
+			p.Explode = explode || (!ok && (p.Style == "" || p.Style == "form"))
+		}
+		p.Schema = schema
+		if p.In == "path" {
+			p.Required = true
+		}
+		key := p.In + " " + p.Name
+		if i, ok := index[key]; ok {
+			op.Parameters[i] = p
+			continue
+		}
+		index[key] = len(op.Parameters)
+		op.Parameters = append(op.Parameters, p)
+	}
+	if len(formParams) > 0 {
+		properties := map[string]interface{}{}
+		var required []interface{}
+		for _, p := range formParams {
+			if p.Schema["type"] == "file" {
+				log.Printf("Warning: Skipping %s %s, which uploads files", op.Method, path)
+				return nil, nil
+			}
+			properties[p.Name] = p.Schema
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		op.Body = &Body{ContentType: consumes(doc, raw, "application/x-www-form-urlencoded"), Required: len(required) > 0, Schema: schema}
+	}
+
+	if v, ok := raw["requestBody"]; ok {
+		m, err := r.object(v)
+		if err != nil {
+			return nil, err
+		}
+		content, _ := m["content"].(map[string]interface{})
+		contentType := bodyType(content)
+		if contentType == "" {
+			log.Printf("Warning: Skipping %s %s, whose body is none of JSON, form or text", op.Method, path)
+			return nil, nil
+		}
+		media, _ := content[contentType].(map[string]interface{})
+		schema, _ := media["schema"].(map[string]interface{})
+		op.Body = &Body{ContentType: contentType, Required: m["required"] == true, Description: str(m, "description"), Schema: schema}
+	}
+	if op.Body != nil && op.Body.Schema == nil {
+		op.Body.Schema = map[string]interface{}{}
+	}
+	if op.Body != nil && strings.HasPrefix(op.Body.ContentType, "multipart/") {
+		log.Printf("Warning: Skipping %s %s, which sends multipart bodies", op.Method, path)
+		return nil, nil
+	}
+	op.assignArgs()
+	return op, nil
+}
+
+// consumes returns the first media type a Swagger 2 operation consumes, else def.
+func consumes(doc, op map[string]interface{}, def string) string {
+	for _, m := range []map[string]interface{}{op, doc} {
+		if list, ok := m["consumes"].([]interface{}); ok && len(list) > 0 {
+			for _, t := range list {
+				if isJSON(fmt.Sprint(t)) {
+					return fmt.Sprint(t)
+				}
+			}
+			return fmt.Sprint(list[0])
+		}
+	}
+	return def
+}
+
+// isJSON reports whether contentType is JSON.
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// bodyType returns the media type bodies are sent as among those of content:
+// JSON, else a form, else text, empty if none.
+func bodyType(content map[string]interface{}) string {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, accept := range []func(string) bool{
+		isJSON,
+		func(t string) bool { return t == "application/x-www-form-urlencoded" },
+		func(t string) bool { return strings.HasPrefix(t, "text/") || t == "*/*" },
+	} {
+		for _, t := range types {
+			if accept(t) {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// swaggerSchema returns the schema of a Swagger 2 parameter, declared by its type,
+// format, items, enum and bounds.
+func swaggerSchema(m map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	for _, key := range []string{"type", "format", "items", "enum", "default", "minimum", "maximum", "minLength", "maxLength", "pattern", "minItems", "maxItems"} {
+		if v, ok := m[key]; ok {
+			schema[key] = v
+		}
+	}
+	return schema
+}
+
+// readOnly reports whether op only reads, by its method.
+func (op *Operation) readOnly() bool {
+	return op.Method == http.MethodGet || op.Method == http.MethodHead || op.Method == http.MethodOptions
+}
@@ -0,0 +1,342 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// invalidName matches the characters not allowed in the names of tools and of their
+// arguments.
+var invalidName = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// maxNameLength is the length of the names of tools and arguments clients accept.
+const maxNameLength = 64
+
+// sanitize returns s with the characters not allowed in names replaced, at most max
+// characters long.
+func sanitize(s string, max int) string {
+	s = strings.Trim(invalidName.ReplaceAllString(s, "_"), "_")
+	if len(s) > max {
+		s = strings.TrimRight(s[:max], "_")
+	}
+	return s
+}
+
+// ToolName returns the name of the tool of op with prefix, from its operationId, or its
+// method and path.
+func ToolName(prefix string, op *Operation) string {
+	name := sanitize(op.ID, maxNameLength-len(prefix))
+	if name == "" {
+		name = strings.ToLower(op.Method)
+	}
+	return prefix + name
+}
+
+// assignArgs sets the arguments of the parameters of op: their name, with the
+// characters not allowed replaced, and their location in front if it clashes with
+// another.
+func (op *Operation) assignArgs() {
+	used := map[string]bool{}
+	if op.Body != nil {
+		used["body"] = true
+	}
+	for _, p := range op.Parameters {
+		arg := sanitize(p.Name, maxNameLength)
+		if arg == "" || used[arg] {
+			arg = sanitize(p.In+"_"+p.Name, maxNameLength)
+		}
+		for i := 2; used[arg]; i++ {
+			arg = sanitize(p.In+"_"+p.Name, maxNameLength-4) + "_" + strconv.Itoa(i)
+		}
+		used[arg] = true
+		p.Arg = arg
+	}
+}
+
+// maxDescription is the length of the descriptions of tools, the rest of those of
+// long operations being cut.
+const maxDescription = 2000
+
+// tool returns the tool of op named name.
+func (op *Operation) tool(name string) mcp.Tool {
+	var description strings.Builder
+	if op.Deprecated {
+		description.WriteString("Deprecated. ")
+	}
+	description.WriteString(op.Summary)
+	if op.Description != "" && op.Description != op.Summary {
+		if op.Summary != "" {
+			description.WriteString("\n\n")
+		}
+		description.WriteString(op.Description)
+	}
+	text := description.String()
+	if len(text) > maxDescription {
+		text = strings.ToValidUTF8(text[:maxDescription], "") + "..."
+	}
+	text = strings.TrimSpace(text + "\n\n" + op.Method + " " + op.Path)
+
+	schema := mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{}}
+	for _, p := range op.Parameters {
+		schema.Properties[p.Arg] = describe(p.Schema, p.Description, p.In+" parameter "+p.Name)
+		if p.Required {
+			schema.Required = append(schema.Required, p.Arg)
+		}
+	}
+	if op.Body != nil {
+		schema.Properties["body"] = describe(op.Body.Schema, op.Body.Description, "Request body, "+op.Body.ContentType)
+		if op.Body.Required {
+			schema.Required = append(schema.Required, "body")
+		}
+	}
+	return mcp.Tool{Name: name, Description: text, InputSchema: schema}
+}
+
+// describe returns a copy of schema with description, telling where the argument
+// goes.
+func describe(schema map[string]interface{}, description, where string) map[string]interface{} {
+	c := make(map[string]interface{}, len(schema)+1)
+	for k, v := range schema {
+		c[k] = v
+	}
+	if description == "" {
+		description = str(schema, "description")
+	}
+	if description == "" {
+		c["description"] = where
+	} else {
+		c["description"] = description + " (" + where + ")"
+	}
+	return c
+}
+
+// stringify returns the text of a scalar argument v as sent in paths, queries and
+// headers.
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64:
+		return fmt.Sprint(v)
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// values returns the values of the query parameter p with the argument v: one per item
+// of arrays when exploded, else joined by commas, and objects as name[key] for the
+// deepObject style, else as their own parameters.
+func (p *Parameter) values(query url.Values, v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = stringify(item)
+		}
+		if p.Explode {
+			for _, item := range items {
+				query.Add(p.Name, item)
+			}
+			return
+		}
+		sep := ","
+		switch p.Style {
+		case "spaceDelimited":
+			sep = " "
+		case "pipeDelimited":
+			sep = "|"
+		}
+		query.Add(p.Name, strings.Join(items, sep))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		switch {
+		case p.Style == "deepObject":
+			for _, k := range keys {
+				query.Add(p.Name+"["+k+"]", stringify(v[k]))
+			}
+		case p.Explode:
+			for _, k := range keys {
+				query.Add(k, stringify(v[k]))
+			}
+		default:
+			var items []string
+			for _, k := range keys {
+				items = append(items, k, stringify(v[k]))
+			}
+			query.Add(p.Name, strings.Join(items, ","))
+		}
+	default:
+		query.Add(p.Name, stringify(v))
+	}
+}
+
+// newRequest returns the request of op to the API at baseURL with the arguments args.
+func (op *Operation) newRequest(ctx context.Context, baseURL string, args map[string]interface{}) (*http.Request, error) {
+	path := op.Path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+	for _, p := range op.Parameters {
+		v, ok := args[p.Arg]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, toolerr.Errorf(toolerr.InvalidParams, "%s is required", p.Arg)
+			}
+			continue
+		}
+		switch p.In {
+		case "path":
+			value := stringify(v)
+			if list, ok := v.([]interface{}); ok {
+				items := make([]string, len(list))
+				for i, item := range list {
+					items[i] = stringify(item)
+				}
+				value = strings.Join(items, ",")
+			}
+			if value == "" {
+				return nil, toolerr.Errorf(toolerr.InvalidParams, "%s must not be empty", p.Arg)
+			}
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			p.values(query, v)
+		case "header":
+			value := stringify(v)
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, toolerr.Errorf(toolerr.InvalidParams, "%s must not contain line breaks", p.Arg)
+			}
+			header.Set(p.Name, value)
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: p.Name, Value: stringify(v)})
+		}
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + path)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid URL of %s %s: %v", op.Method, op.Path, err)
+	}
+	if len(query) > 0 {
+		q := u.Query()
+		for k, v := range query {
+			q[k] = append(q[k], v...)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var body io.Reader
+	if op.Body != nil {
+		if v, ok := args["body"]; ok && v != nil {
+			data, err := op.Body.encode(v)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(data)
+			header.Set("Content-Type", op.Body.ContentType)
+		} else if op.Body.Required {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "body is required")
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, op.Method, u.String(), body)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid request of %s %s: %v", op.Method, op.Path, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+	return req, nil
+}
+
+// encode returns the body v encoded as its content type. Bodies given as JSON text
+// are sent as they are.
+func (b *Body) encode(v interface{}) ([]byte, error) {
+	switch {
+	case isJSON(b.ContentType):
+		if s, ok := v.(string); ok && json.Valid([]byte(s)) && b.Schema["type"] != "string" {
+			return []byte(s), nil
+		}
+		return json.Marshal(v)
+	case b.ContentType == "application/x-www-form-urlencoded":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "body must be an object of the form fields")
+		}
+		form := url.Values{}
+		for k, item := range m {
+			if list, ok := item.([]interface{}); ok {
+				for _, e := range list {
+					form.Add(k, stringify(e))
+				}
+				continue
+			}
+			form.Add(k, stringify(item))
+		}
+		return []byte(form.Encode()), nil
+	}
+	return []byte(stringify(v)), nil
+}
+
+// statusError returns the error of a response with an unsuccessful status.
+func statusError(op *Operation, resp *http.Response, body string) error {
+	code := toolerr.InvalidParams
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		code = toolerr.PermissionDenied
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		code = toolerr.NotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		code = toolerr.Unavailable
+	case resp.StatusCode < 400:
+		code = toolerr.Internal
+	}
+	if body == "" {
+		return toolerr.Errorf(code, "%s %s returned %s", op.Method, op.Path, resp.Status)
+	}
+	return toolerr.Errorf(code, "%s %s returned %s: %s", op.Method, op.Path, resp.Status, body)
+}
+
+// readBody returns the body of resp, up to max bytes, indented if it is JSON.
+func readBody(resp *http.Response, max int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return "", toolerr.Errorf(toolerr.Unavailable, "error reading the response: %v", err)
+	}
+	truncated := int64(len(data)) > max
+	if truncated {
+		data = data[:max]
+	} else if json.Valid(data) {
+		var indented bytes.Buffer
+		if json.Indent(&indented, data, "", "  ") == nil {
+			data = indented.Bytes()
+		}
+	}
+	text := strings.ToValidUTF8(string(data), "�")
+	if truncated {
+		text += fmt.Sprintf("\n[truncated at %d bytes]", max)
+	}
+	return text, nil
+}
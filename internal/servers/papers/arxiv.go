@@ -0,0 +1,130 @@
+package papers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const sourceArxiv = "arxiv"
+
+// arxivFeed is the Atom feed returned by the arXiv query API
+type arxivFeed struct {
+	TotalResults int          `xml:"http://a9.com/-/spec/opensearch/1.1/ totalResults"`
+	Entries      []arxivEntry `xml:"entry"`
+}
+
+type arxivEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Published string `xml:"published"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	DOI        string `xml:"http://arxiv.org/schemas/atom doi"`
+	JournalRef string `xml:"http://arxiv.org/schemas/atom journal_ref"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// collapseSpace joins whitespace runs, since arXiv wraps titles and abstracts.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// paper converts an Atom entry into a Paper.
+func (e arxivEntry) paper() Paper {
+	id, _ := normalizeArxivID(e.ID)
+	p := Paper{
+		Source:   sourceArxiv,
+		ID:       id,
+		ArxivID:  id,
+		Title:    collapseSpace(e.Title),
+		Abstract: collapseSpace(e.Summary),
+		DOI:      e.DOI,
+		Venue:    collapseSpace(e.JournalRef),
+		URL:      "https://arxiv.org/abs/" + id,
+	}
+	for _, author := range e.Authors {
+		p.Authors = append(p.Authors, collapseSpace(author.Name))
+	}
+	for _, category := range e.Categories {
+		p.Categories = append(p.Categories, category.Term)
+	}
+	if len(e.Published) >= 10 {
+		p.Published = e.Published[:10]
+		p.Year, _ = strconv.Atoi(e.Published[:4])
+	}
+	return p
+}
+
+// arxivSearchQuery turns free text into an arXiv search_query. Queries that already use
+// field prefixes such as "ti:" or "au:" are passed through unchanged.
+func arxivSearchQuery(query string) string {
+	for _, prefix := range []string{"ti:", "au:", "abs:", "co:", "jr:", "cat:", "rn:", "id:", "all:"} {
+		if strings.Contains(query, prefix) {
+			return query
+		}
+	}
+	terms := strings.Fields(query)
+	for i, term := range terms {
+		terms[i] = "all:" + term
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// parseArxivFeed decodes an Atom feed, surfacing API errors reported as feed entries.
+func parseArxivFeed(body []byte) ([]Paper, int, error) {
+	var feed arxivFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse arXiv response: %w", err)
+	}
+
+	var papers []Paper
+	for _, entry := range feed.Entries {
+		if strings.Contains(entry.ID, "/api/errors") {
+			return nil, 0, fmt.Errorf("arXiv API error: %s", collapseSpace(entry.Summary))
+		}
+		papers = append(papers, entry.paper())
+	}
+	return papers, feed.TotalResults, nil
+}
+
+// searchArxiv searches arXiv.
+func (s *PapersServer) searchArxiv(ctx context.Context, query string, limit int) ([]Paper, int, error) {
+	values := url.Values{}
+	values.Set("search_query", arxivSearchQuery(query))
+	values.Set("start", "0")
+	values.Set("max_results", strconv.Itoa(limit))
+	values.Set("sortBy", "relevance")
+
+	body, err := s.get(ctx, s.arxivLimiter, s.arxivURL+"/query?"+values.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseArxivFeed(body)
+}
+
+// getArxivPaper fetches a single paper by arXiv ID.
+func (s *PapersServer) getArxivPaper(ctx context.Context, id string) (*Paper, error) {
+	values := url.Values{}
+	values.Set("id_list", id)
+
+	body, err := s.get(ctx, s.arxivLimiter, s.arxivURL+"/query?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	papers, _, err := parseArxivFeed(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(papers) == 0 || papers[0].Title == "" {
+		return nil, fmt.Errorf("paper not found on arXiv: %s", id)
+	}
+	return &papers[0], nil
+}
@@ -0,0 +1,252 @@
+package papers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Paper is the source independent representation of a publication
+type Paper struct {
+	Source        string   `json:"source"` // "arxiv" or "semanticscholar"
+	ID            string   `json:"id"`     // Source specific identifier
+	Title         string   `json:"title"`
+	Authors       []string `json:"authors"`
+	Abstract      string   `json:"abstract,omitempty"`
+	Year          int      `json:"year,omitempty"`
+	Published     string   `json:"published,omitempty"` // YYYY-MM-DD when known
+	Venue         string   `json:"venue,omitempty"`
+	DOI           string   `json:"doi,omitempty"`
+	ArxivID       string   `json:"arxivId,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	Categories    []string `json:"categories,omitempty"`
+	CitationCount *int     `json:"citationCount,omitempty"`
+}
+
+var (
+	// Matches new style (2101.00001, optionally versioned) and old style (hep-th/9901001) arXiv IDs
+	arxivIDPattern = regexp.MustCompile(`^(\d{4}\.\d{4,5}|[a-z\-]+(\.[A-Z]{2})?/\d{7})(v\d+)?$`)
+	arxivVersion   = regexp.MustCompile(`v\d+$`)
+)
+
+// normalizeArxivID strips URL prefixes, "arXiv:" prefixes and version suffixes.
+// ok is false when id is not an arXiv identifier.
+func normalizeArxivID(id string) (string, bool) {
+	id = strings.TrimSpace(id)
+	for _, prefix := range []string{"https://arxiv.org/abs/", "http://arxiv.org/abs/", "arxiv.org/abs/"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	if len(id) > 6 && strings.EqualFold(id[:6], "arxiv:") {
+		id = id[6:]
+	}
+	if !arxivIDPattern.MatchString(id) {
+		return "", false
+	}
+	return arxivVersion.ReplaceAllString(id, ""), true
+}
+
+// dedupeKey returns the key used to merge the same paper reported by several sources.
+func (p Paper) dedupeKey() string {
+	switch {
+	case p.ArxivID != "":
+		return "arxiv:" + p.ArxivID
+	case p.DOI != "":
+		return "doi:" + strings.ToLower(p.DOI)
+	}
+	return "title:" + strings.Join(strings.FieldsFunc(strings.ToLower(p.Title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// mergePapers interleaves result lists, dropping duplicates. When a paper appears in
+// several lists the first occurrence wins and missing fields are filled from later ones.
+func mergePapers(lists ...[]Paper) []Paper {
+	var merged []Paper
+	index := map[string]int{}
+
+	longest := 0
+	for _, list := range lists {
+		longest = max(longest, len(list))
+	}
+	for i := 0; i < longest; i++ {
+		for _, list := range lists {
+			if i >= len(list) {
+				continue
+			}
+			p := list[i]
+			key := p.dedupeKey()
+			if at, ok := index[key]; ok {
+				merged[at] = fillMissing(merged[at], p)
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// fillMissing copies fields that are empty in dst from src.
+func fillMissing(dst, src Paper) Paper {
+	if dst.Abstract == "" {
+		dst.Abstract = src.Abstract
+	}
+	if dst.Venue == "" {
+		dst.Venue = src.Venue
+	}
+	if dst.DOI == "" {
+		dst.DOI = src.DOI
+	}
+	if dst.ArxivID == "" {
+		dst.ArxivID = src.ArxivID
+	}
+	if dst.CitationCount == nil {
+		dst.CitationCount = src.CitationCount
+	}
+	if dst.Year == 0 {
+		dst.Year = src.Year
+	}
+	return dst
+}
+
+// Format renders a paper for display. The abstract is included when withAbstract is set.
+func (p Paper) Format(withAbstract bool) string {
+	var sb strings.Builder
+	sb.WriteString(p.Title + "\n")
+	if len(p.Authors) > 0 {
+		authors := p.Authors
+		if len(authors) > 10 && !withAbstract {
+			authors = append(authors[:10:10], fmt.Sprintf("and %d more", len(p.Authors)-10))
+		}
+		sb.WriteString("   Authors: " + strings.Join(authors, ", ") + "\n")
+	}
+
+	var meta []string
+	if p.Year != 0 {
+		meta = append(meta, fmt.Sprintf("Year: %d", p.Year))
+	}
+	if p.Venue != "" {
+		meta = append(meta, "Venue: "+p.Venue)
+	}
+	if p.CitationCount != nil {
+		meta = append(meta, fmt.Sprintf("Citations: %d", *p.CitationCount))
+	}
+	if len(meta) > 0 {
+		sb.WriteString("   " + strings.Join(meta, " | ") + "\n")
+	}
+
+	var ids []string
+	if p.ArxivID != "" {
+		ids = append(ids, "arXiv:"+p.ArxivID)
+	}
+	if p.DOI != "" {
+		ids = append(ids, "DOI:"+p.DOI)
+	}
+	if p.Source == sourceSemanticScholar && p.ID != "" {
+		ids = append(ids, "S2:"+p.ID)
+	}
+	if len(ids) > 0 {
+		sb.WriteString("   IDs: " + strings.Join(ids, ", ") + "\n")
+	}
+	if p.URL != "" {
+		sb.WriteString("   URL: " + p.URL + "\n")
+	}
+	if withAbstract && p.Abstract != "" {
+		sb.WriteString("\nAbstract:\n" + p.Abstract + "\n")
+	}
+	return sb.String()
+}
+
+// BibTeX renders the paper as a BibTeX entry.
+func (p Paper) BibTeX() string {
+	entryType := "article"
+	if p.Venue == "" && p.ArxivID != "" {
+		entryType = "misc"
+	}
+
+	fields := [][2]string{
+		{"title", "{" + bibEscape(p.Title) + "}"},
+		{"author", bibEscape(strings.Join(p.Authors, " and "))},
+	}
+	if p.Year != 0 {
+		fields = append(fields, [2]string{"year", fmt.Sprintf("%d", p.Year)})
+	}
+	if p.Venue != "" {
+		fields = append(fields, [2]string{"journal", bibEscape(p.Venue)})
+	}
+	if p.DOI != "" {
+		fields = append(fields, [2]string{"doi", p.DOI})
+	}
+	if p.ArxivID != "" {
+		fields = append(fields,
+			[2]string{"eprint", p.ArxivID},
+			[2]string{"archivePrefix", "arXiv"},
+		)
+		if len(p.Categories) > 0 {
+			fields = append(fields, [2]string{"primaryClass", p.Categories[0]})
+		}
+	}
+	if p.URL != "" {
+		fields = append(fields, [2]string{"url", p.URL})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("@%s{%s,\n", entryType, p.citationKey()))
+	for i, field := range fields {
+		sb.WriteString(fmt.Sprintf("  %s = {%s}", field[0], field[1]))
+		if i < len(fields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// citationKey builds a key such as "vaswani2017attention".
+func (p Paper) citationKey() string {
+	alnum := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, s)
+	}
+
+	key := "paper"
+	if len(p.Authors) > 0 {
+		names := strings.Fields(p.Authors[0])
+		if len(names) > 0 {
+			if last := alnum(names[len(names)-1]); last != "" {
+				key = last
+			}
+		}
+	}
+	if p.Year != 0 {
+		key += fmt.Sprintf("%d", p.Year)
+	}
+	stopWords := map[string]bool{"a": true, "an": true, "the": true, "on": true, "of": true, "for": true, "and": true, "in": true, "to": true}
+	for _, word := range strings.Fields(p.Title) {
+		if w := alnum(word); w != "" && !stopWords[w] {
+			key += w
+			break
+		}
+	}
+	return key
+}
+
+// bibEscape escapes characters with special meaning in BibTeX values.
+func bibEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`,
+		"{", `\{`,
+		"}", `\}`,
+		"&", `\&`,
+		"%", `\%`,
+		"$", `\$`,
+		"#", `\#`,
+		"_", `\_`,
+	).Replace(s)
+}
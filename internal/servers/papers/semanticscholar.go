@@ -0,0 +1,173 @@
+package papers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	sourceSemanticScholar = "semanticscholar"
+
+	s2Fields         = "paperId,title,authors,abstract,year,venue,externalIds,url,citationCount,publicationDate"
+	s2CitationFields = "paperId,title,authors,year,venue,externalIds,url,citationCount"
+)
+
+// s2Paper is a paper object from the Semantic Scholar Graph API
+type s2Paper struct {
+	PaperID string `json:"paperId"`
+	Title   string `json:"title"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Abstract        string                 `json:"abstract"`
+	Year            int                    `json:"year"`
+	Venue           string                 `json:"venue"`
+	ExternalIDs     map[string]interface{} `json:"externalIds"`
+	URL             string                 `json:"url"`
+	CitationCount   *int                   `json:"citationCount"`
+	PublicationDate string                 `json:"publicationDate"`
+}
+
+// paper converts a Semantic Scholar paper into a Paper.
+func (sp s2Paper) paper() Paper {
+	p := Paper{
+		Source:        sourceSemanticScholar,
+		ID:            sp.PaperID,
+		Title:         sp.Title,
+		Abstract:      sp.Abstract,
+		Year:          sp.Year,
+		Published:     sp.PublicationDate,
+		Venue:         sp.Venue,
+		URL:           sp.URL,
+		CitationCount: sp.CitationCount,
+	}
+	for _, author := range sp.Authors {
+		p.Authors = append(p.Authors, author.Name)
+	}
+	// externalIds values are strings except CorpusId, which is a number
+	if doi, ok := sp.ExternalIDs["DOI"].(string); ok {
+		p.DOI = doi
+	}
+	if arxivID, ok := sp.ExternalIDs["ArXiv"].(string); ok {
+		p.ArxivID, _ = normalizeArxivID(arxivID)
+	}
+	return p
+}
+
+// s2Identifier converts a user supplied ID into a Graph API paper identifier.
+func s2Identifier(id string) string {
+	if arxivID, ok := normalizeArxivID(id); ok {
+		return "arXiv:" + arxivID
+	}
+	lower := strings.ToLower(id)
+	switch {
+	case strings.HasPrefix(lower, "doi:"):
+		return "DOI:" + id[4:]
+	case strings.HasPrefix(lower, "https://doi.org/"):
+		return "DOI:" + id[len("https://doi.org/"):]
+	case strings.HasPrefix(id, "10."):
+		return "DOI:" + id
+	case strings.HasPrefix(lower, "s2:"):
+		return id[3:]
+	}
+	return id
+}
+
+// s2PathID escapes an identifier for use in a URL path. DOIs contain slashes which the
+// Graph API expects verbatim, so each segment is escaped separately.
+func s2PathID(id string) string {
+	segments := strings.Split(s2Identifier(id), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s2Get performs a Graph API request and decodes the JSON response into out.
+func (s *PapersServer) s2Get(ctx context.Context, apiPath string, values url.Values, out interface{}) error {
+	var headers http.Header
+	if s.s2APIKey != "" {
+		headers = http.Header{"X-Api-Key": []string{s.s2APIKey}}
+	}
+
+	body, err := s.get(ctx, s.s2Limiter, s.s2URL+apiPath+"?"+values.Encode(), headers)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse Semantic Scholar response: %w", err)
+	}
+	return nil
+}
+
+// searchSemanticScholar searches Semantic Scholar.
+func (s *PapersServer) searchSemanticScholar(ctx context.Context, query string, limit int) ([]Paper, int, error) {
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("fields", s2Fields)
+
+	var resp struct {
+		Total int       `json:"total"`
+		Data  []s2Paper `json:"data"`
+	}
+	if err := s.s2Get(ctx, "/paper/search", values, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	papers := make([]Paper, 0, len(resp.Data))
+	for _, sp := range resp.Data {
+		papers = append(papers, sp.paper())
+	}
+	return papers, resp.Total, nil
+}
+
+// getSemanticScholarPaper fetches a single paper by any supported identifier.
+func (s *PapersServer) getSemanticScholarPaper(ctx context.Context, id string) (*Paper, error) {
+	values := url.Values{}
+	values.Set("fields", s2Fields)
+
+	var sp s2Paper
+	if err := s.s2Get(ctx, "/paper/"+s2PathID(id), values, &sp); err != nil {
+		return nil, err
+	}
+	p := sp.paper()
+	return &p, nil
+}
+
+// getCitations returns papers citing id (direction "citations") or cited by id ("references").
+func (s *PapersServer) getCitations(ctx context.Context, id, direction string, limit int) ([]Paper, error) {
+	values := url.Values{}
+	values.Set("fields", s2CitationFields)
+	values.Set("limit", strconv.Itoa(limit))
+
+	var resp struct {
+		Data []struct {
+			CitingPaper *s2Paper `json:"citingPaper"`
+			CitedPaper  *s2Paper `json:"citedPaper"`
+		} `json:"data"`
+	}
+	apiPath := "/paper/" + s2PathID(id) + "/" + direction
+	if err := s.s2Get(ctx, apiPath, values, &resp); err != nil {
+		return nil, err
+	}
+
+	var papers []Paper
+	for _, item := range resp.Data {
+		sp := item.CitingPaper
+		if direction == "references" {
+			sp = item.CitedPaper
+		}
+		// Entries without a paperId are unresolved references
+		if sp == nil || sp.PaperID == "" {
+			continue
+		}
+		papers = append(papers, sp.paper())
+	}
+	return papers, nil
+}
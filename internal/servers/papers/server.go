@@ -0,0 +1,544 @@
+package papers
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/cache"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/progress"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const (
+	defaultArxivURL = "http://export.arxiv.org/api"
+	defaultS2URL    = "https://api.semanticscholar.org/graph/v1"
+)
+
+// PapersServer is an MCP server searching arXiv and Semantic Scholar.
+type PapersServer struct {
+	server       *server.MCPServer
+	client       *http.Client
+	arxivURL     string
+	s2URL        string
+	s2APIKey     string
+	userAgent    string
+	maxBodySize  int64
+	arxivLimiter *intervalLimiter
+	s2Limiter    *intervalLimiter
+}
+
+// NewPapersServer creates a new PapersServer instance. The intervals space requests to each
+// API; arXiv asks clients to wait 3 seconds between calls.
+func NewPapersServer(arxivURL, s2URL, s2APIKey, userAgent string, timeout int, maxBodySize int64, arxivInterval, s2Interval time.Duration) *PapersServer {
+	log.Printf("PapersServer created: arxivURL=%s, s2URL=%s, timeout=%ds", arxivURL, s2URL, timeout)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		MaxBodySize: maxBodySize,
+	})
+
+	s := &PapersServer{
+		client:       client,
+		arxivURL:     strings.TrimSuffix(arxivURL, "/"),
+		s2URL:        strings.TrimSuffix(s2URL, "/"),
+		s2APIKey:     s2APIKey,
+		userAgent:    userAgent,
+		maxBodySize:  maxBodySize,
+		arxivLimiter: &intervalLimiter{interval: arxivInterval},
+		s2Limiter:    &intervalLimiter{interval: s2Interval},
+	}
+
+	mcpServer := server.NewMCPServer(
+		"papers-server", // server name
+		"1.0.0",         // version
+	)
+
+	// Register searchPapers tool
+	searchTool := mcp.NewTool("searchPapers",
+		mcp.WithDescription("Searches academic papers on arXiv and Semantic Scholar. Results from both sources are merged and de-duplicated"),
+		mcp.WithString("query",
+			mcp.Description("Search query. arXiv field prefixes such as 'ti:' or 'au:' are supported for the arxiv source"),
+			mcp.Required(),
+		),
+		mcp.WithString("source",
+			mcp.Description("Where to search"),
+			mcp.Enum("all", sourceArxiv, sourceSemanticScholar),
+			mcp.DefaultString("all"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results per source (1-50, default: 10)"),
+		),
+	)
+
+	// Register getPaper tool
+	paperTool := mcp.NewTool("getPaper",
+		mcp.WithDescription("Returns the details and abstract of a paper"),
+		mcp.WithString("id",
+			mcp.Description("Paper identifier: arXiv ID (2106.09685 or arXiv:2106.09685), DOI (DOI:10.xxx/yyy) or Semantic Scholar ID (S2:...)"),
+			mcp.Required(),
+		),
+	)
+
+	// Register getCitations tool
+	citationsTool := mcp.NewTool("getCitations",
+		mcp.WithDescription("Lists papers citing a paper, or papers referenced by it, using Semantic Scholar"),
+		mcp.WithString("id",
+			mcp.Description("Paper identifier: arXiv ID, DOI or Semantic Scholar ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("direction",
+			mcp.Description("'citations' for papers citing this one, 'references' for papers it cites"),
+			mcp.Enum("citations", "references"),
+			mcp.DefaultString("citations"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of papers (1-100, default: 20)"),
+		),
+	)
+
+	// Register exportBibtex tool
+	bibtexTool := mcp.NewTool("exportBibtex",
+		mcp.WithDescription("Exports one or more papers as BibTeX entries"),
+		mcp.WithString("ids",
+			mcp.Description("Comma separated paper identifiers"),
+			mcp.Required(),
+		),
+	)
+
+	middleware.AddTool(mcpServer, searchTool, s.handleSearchPapers)
+	middleware.AddTool(mcpServer, paperTool, s.handleGetPaper)
+	middleware.AddTool(mcpServer, citationsTool, s.handleGetCitations)
+	middleware.AddTool(mcpServer, bibtexTool, s.handleExportBibtex)
+	for _, tool := range []string{"searchPapers", "getPaper", "getCitations"} {
+		middleware.Cacheable(mcpServer, tool, cache.Policy{TTL: time.Hour})
+	}
+
+	s.server = mcpServer
+	return s
+}
+
+// intervalLimiter spaces requests to an API at least interval apart.
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Wait blocks until the next request slot is available or ctx is done.
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	slot := l.next
+	if now := time.Now(); slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// get performs a rate limited GET request.
+func (s *PapersServer) get(ctx context.Context, limiter *intervalLimiter, rawURL string, headers http.Header) ([]byte, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range headers {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("User-Agent", s.userAgent)
+
+	log.Printf("Sending request to %s", rawURL)
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, toolerr.Errorf(toolerr.NotFound, "paper not found")
+	case http.StatusTooManyRequests:
+		log.Printf("Error: Rate limited by %s", httpReq.URL.Host)
+		return nil, fmt.Errorf("rate limited by %s, please retry later", httpReq.URL.Host)
+	}
+	log.Printf("Error: API returned status code %d: %s", resp.StatusCode, string(body))
+	return nil, fmt.Errorf("API error: status code %d", resp.StatusCode)
+}
+
+// getPaper fetches a paper from the source best suited to its identifier.
+func (s *PapersServer) getPaper(ctx context.Context, id string) (*Paper, error) {
+	if arxivID, ok := normalizeArxivID(id); ok {
+		paper, err := s.getArxivPaper(ctx, arxivID)
+		if err == nil {
+			return paper, nil
+		}
+		log.Printf("Warning: arXiv lookup failed, falling back to Semantic Scholar: %v", err)
+	}
+	return s.getSemanticScholarPaper(ctx, id)
+}
+
+// handleSearchPapers handles the paper search request.
+func (s *PapersServer) handleSearchPapers(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting searchPapers request processing")
+
+	var args struct {
+		Query  string `json:"query"`
+		Source string `json:"source,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+	if args.Source == "" {
+		args.Source = "all"
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	} else if args.Limit > 50 {
+		args.Limit = 50
+	}
+
+	var (
+		arxivPapers, s2Papers []Paper
+		arxivErr, s2Err       error
+		wg                    sync.WaitGroup
+	)
+	switch args.Source {
+	case "all", sourceArxiv, sourceSemanticScholar:
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported source: %s", args.Source)
+	}
+	if args.Source != sourceSemanticScholar {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			arxivPapers, _, arxivErr = s.searchArxiv(ctx, args.Query, args.Limit)
+		}()
+	}
+	if args.Source != sourceArxiv {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s2Papers, _, s2Err = s.searchSemanticScholar(ctx, args.Query, args.Limit)
+		}()
+	}
+	wg.Wait()
+
+	// A single failing source is reported as a warning when searching both
+	var warnings []string
+	if arxivErr != nil {
+		log.Printf("Error: arXiv search failed: %v", arxivErr)
+		if args.Source == sourceArxiv || s2Err != nil {
+			return nil, fmt.Errorf("arXiv search failed: %w", arxivErr)
+		}
+		warnings = append(warnings, "arXiv search failed: "+arxivErr.Error())
+	}
+	if s2Err != nil {
+		log.Printf("Error: Semantic Scholar search failed: %v", s2Err)
+		if args.Source == sourceSemanticScholar || arxivErr != nil {
+			return nil, fmt.Errorf("Semantic Scholar search failed: %w", s2Err)
+		}
+		warnings = append(warnings, "Semantic Scholar search failed: "+s2Err.Error())
+	}
+
+	papers := mergePapers(s2Papers, arxivPapers)
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Found %d papers for '%s'\n\n", len(papers), args.Query))
+	for _, warning := range warnings {
+		resultContent.WriteString("Warning: " + warning + "\n\n")
+	}
+	for i, paper := range papers {
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n", i+1, paper.Format(false)))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("searchPapers request completed")
+	return result, nil
+}
+
+// handleGetPaper handles the paper details request.
+func (s *PapersServer) handleGetPaper(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getPaper request processing")
+
+	var args struct {
+		ID string `json:"id"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.ID) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "id is required")
+	}
+
+	paper, err := s.getPaper(ctx, strings.TrimSpace(args.ID))
+	if err != nil {
+		log.Printf("Error: Failed to get paper: %v", err)
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: paper.Format(true),
+			},
+		},
+	}
+
+	log.Println("getPaper request completed")
+	return result, nil
+}
+
+// handleGetCitations handles the citation lookup request.
+func (s *PapersServer) handleGetCitations(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getCitations request processing")
+
+	var args struct {
+		ID        string `json:"id"`
+		Direction string `json:"direction,omitempty"`
+		Limit     int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.ID) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "id is required")
+	}
+	if args.Direction == "" {
+		args.Direction = "citations"
+	}
+	if args.Direction != "citations" && args.Direction != "references" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported direction: %s", args.Direction)
+	}
+	if args.Limit <= 0 {
+		args.Limit = 20
+	} else if args.Limit > 100 {
+		args.Limit = 100
+	}
+
+	papers, err := s.getCitations(ctx, strings.TrimSpace(args.ID), args.Direction, args.Limit)
+	if err != nil {
+		log.Printf("Error: Citation lookup failed: %v", err)
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	if args.Direction == "citations" {
+		resultContent.WriteString(fmt.Sprintf("%d papers citing %s\n\n", len(papers), args.ID))
+	} else {
+		resultContent.WriteString(fmt.Sprintf("%d papers referenced by %s\n\n", len(papers), args.ID))
+	}
+	for i, paper := range papers {
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n", i+1, paper.Format(false)))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("getCitations request completed")
+	return result, nil
+}
+
+// handleExportBibtex handles the BibTeX export request.
+func (s *PapersServer) handleExportBibtex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting exportBibtex request processing")
+
+	var args struct {
+		IDs string `json:"ids"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var ids []string
+	for _, id := range strings.Split(args.IDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "at least one id is required")
+	}
+	if len(ids) > 20 {
+		return nil, fmt.Errorf("at most 20 papers can be exported at once")
+	}
+
+	// Papers are fetched one at a time within the rate limits of the APIs
+	reporter := progress.New(ctx, req, float64(len(ids)))
+	var entries []string
+	for _, id := range ids {
+		paper, err := s.getPaper(ctx, id)
+		if err != nil {
+			log.Printf("Error: Failed to get paper %s: %v", id, err)
+			return nil, fmt.Errorf("failed to get paper %s: %w", id, err)
+		}
+		entries = append(entries, paper.BibTeX())
+		reporter.Add(1)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: strings.Join(entries, "\n\n"),
+			},
+		},
+	}
+
+	log.Println("exportBibtex request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *PapersServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the papers server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("papers", flag.ContinueOnError)
+	var (
+		arxivURL      string
+		s2URL         string
+		s2APIKey      string
+		userAgent     string
+		timeout       int
+		maxBodySize   int64
+		arxivInterval int
+		s2Interval    int
+	)
+	fs.StringVar(&arxivURL, "arxiv-url", defaultArxivURL, "arXiv API base URL")
+	fs.StringVar(&s2URL, "s2-url", defaultS2URL, "Semantic Scholar Graph API base URL")
+	fs.StringVar(&s2APIKey, "s2-api-key", "", "Semantic Scholar API key (optional, raises rate limits)")
+	fs.StringVar(&userAgent, "user-agent", "mcphost-papers/1.0 (+https://github.com/mark3labs/mcphost)", "User-Agent for API requests")
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 10*1024*1024, "Maximum response body size in bytes (default 10MB)")
+	fs.IntVar(&arxivInterval, "arxiv-interval", 3000, "Minimum interval between arXiv requests in milliseconds")
+	fs.IntVar(&s2Interval, "s2-interval", 1000, "Minimum interval between Semantic Scholar requests in milliseconds")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("s2-api-key", "S2_API_KEY"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Papers server: timeout=%ds", timeout)
+
+	// Create PapersServer instance
+	papersServer := NewPapersServer(arxivURL, s2URL, s2APIKey, userAgent, timeout, maxBodySize,
+		time.Duration(arxivInterval)*time.Millisecond, time.Duration(s2Interval)*time.Millisecond)
+	log.Println("PapersServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), papersServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return papersServer.Server(), transportFlags, nil
+}
+
+// Run starts the papers server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[PapersServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create papers server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("PapersServer shutdown")
+	return nil
+}
@@ -0,0 +1,268 @@
+package papers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testArxivFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/" xmlns:arxiv="http://arxiv.org/schemas/atom">
+  <opensearch:totalResults>1</opensearch:totalResults>
+  <entry>
+    <id>http://arxiv.org/abs/1706.03762v7</id>
+    <published>2017-06-12T17:57:34Z</published>
+    <title>Attention Is All
+      You Need</title>
+    <summary>  The dominant sequence transduction models
+      are based on recurrent networks.</summary>
+    <author><name>Ashish Vaswani</name></author>
+    <author><name>Noam Shazeer</name></author>
+    <arxiv:primary_category term="cs.CL"/>
+    <category term="cs.CL"/>
+    <category term="cs.LG"/>
+  </entry>
+</feed>`
+
+const testArxivError = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>http://arxiv.org/api/errors#incorrect_id_format_for_9999</id>
+    <title>Error</title>
+    <summary>incorrect id format for 9999</summary>
+  </entry>
+</feed>`
+
+const testS2Search = `{"total": 2, "data": [
+  {"paperId": "204e3073870fae3d05bcbc2f6a8e263d9b72e776", "title": "Attention is All you Need",
+   "authors": [{"name": "Ashish Vaswani"}], "year": 2017, "venue": "NeurIPS", "citationCount": 100000,
+   "externalIds": {"ArXiv": "1706.03762", "DOI": "10.5555/3295222", "CorpusId": 13756489}},
+  {"paperId": "abc", "title": "BERT", "authors": [{"name": "Jacob Devlin"}], "year": 2019,
+   "externalIds": {"CorpusId": 52967399}}
+]}`
+
+// PapersServer creation test
+func TestNewPapersServer(t *testing.T) {
+	ps := NewPapersServer(defaultArxivURL+"/", defaultS2URL, "key", "agent", 30, 1024, 0, 0)
+
+	assert.NotNil(t, ps, "PapersServer instance should be created")
+	assert.Equal(t, defaultArxivURL, ps.arxivURL, "arXiv URL should have the trailing slash trimmed")
+	assert.Equal(t, "key", ps.s2APIKey, "API key should match")
+	assert.NotNil(t, ps.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	ps := NewPapersServer(defaultArxivURL, defaultS2URL, "", "agent", 30, 1024, 0, 0)
+	assert.NotNil(t, ps.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test identifier normalization
+func TestIdentifiers(t *testing.T) {
+	testCases := []struct {
+		input   string
+		arxivID string
+		isArxiv bool
+		s2ID    string
+	}{
+		{input: "1706.03762", arxivID: "1706.03762", isArxiv: true, s2ID: "arXiv:1706.03762"},
+		{input: "arXiv:1706.03762v7", arxivID: "1706.03762", isArxiv: true, s2ID: "arXiv:1706.03762"},
+		{input: "https://arxiv.org/abs/2106.09685", arxivID: "2106.09685", isArxiv: true, s2ID: "arXiv:2106.09685"},
+		{input: "hep-th/9901001", arxivID: "hep-th/9901001", isArxiv: true, s2ID: "arXiv:hep-th/9901001"},
+		{input: "10.18653/v1/N19-1423", s2ID: "DOI:10.18653/v1/N19-1423"},
+		{input: "doi:10.18653/v1/N19-1423", s2ID: "DOI:10.18653/v1/N19-1423"},
+		{input: "S2:204e3073870fae3d05bcbc2f6a8e263d9b72e776", s2ID: "204e3073870fae3d05bcbc2f6a8e263d9b72e776"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			arxivID, ok := normalizeArxivID(tc.input)
+			assert.Equal(t, tc.isArxiv, ok)
+			assert.Equal(t, tc.arxivID, arxivID)
+			assert.Equal(t, tc.s2ID, s2Identifier(tc.input))
+		})
+	}
+}
+
+// Test arXiv feed parsing
+func TestParseArxivFeed(t *testing.T) {
+	papers, total, err := parseArxivFeed([]byte(testArxivFeed))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, papers, 1)
+
+	p := papers[0]
+	assert.Equal(t, "1706.03762", p.ArxivID)
+	assert.Equal(t, "Attention Is All You Need", p.Title, "Whitespace in titles should be collapsed")
+	assert.Equal(t, "The dominant sequence transduction models are based on recurrent networks.", p.Abstract)
+	assert.Equal(t, []string{"Ashish Vaswani", "Noam Shazeer"}, p.Authors)
+	assert.Equal(t, 2017, p.Year)
+	assert.Equal(t, "2017-06-12", p.Published)
+	assert.Equal(t, []string{"cs.CL", "cs.LG"}, p.Categories)
+
+	_, _, err = parseArxivFeed([]byte(testArxivError))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "incorrect id format")
+}
+
+// Test merging and BibTeX rendering
+func TestMergeAndBibTeX(t *testing.T) {
+	count := 5
+	s2 := []Paper{{Source: sourceSemanticScholar, ID: "x", Title: "Attention is All you Need", ArxivID: "1706.03762", CitationCount: &count}}
+	arxiv := []Paper{
+		{Source: sourceArxiv, ID: "1706.03762", Title: "Attention Is All You Need", ArxivID: "1706.03762", Abstract: "abstract"},
+		{Source: sourceArxiv, ID: "1810.04805", Title: "BERT", ArxivID: "1810.04805"},
+	}
+
+	merged := mergePapers(s2, arxiv)
+	assert.Len(t, merged, 2)
+	assert.Equal(t, sourceSemanticScholar, merged[0].Source)
+	assert.Equal(t, "abstract", merged[0].Abstract, "Missing fields should be filled from duplicates")
+
+	p := Paper{
+		Title:      "Attention Is All You Need",
+		Authors:    []string{"Ashish Vaswani", "Noam Shazeer"},
+		Year:       2017,
+		ArxivID:    "1706.03762",
+		Categories: []string{"cs.CL"},
+		URL:        "https://arxiv.org/abs/1706.03762",
+	}
+	bib := p.BibTeX()
+	assert.True(t, strings.HasPrefix(bib, "@misc{vaswani2017attention,\n"))
+	assert.Contains(t, bib, "  author = {Ashish Vaswani and Noam Shazeer},\n")
+	assert.Contains(t, bib, "  eprint = {1706.03762},\n")
+	assert.Contains(t, bib, "  primaryClass = {cs.CL},\n")
+	assert.True(t, strings.HasSuffix(bib, "}\n}"))
+
+	assert.Equal(t, `Tom \& Jerry: 100\% \{fun\}`, bibEscape("Tom & Jerry: 100% {fun}"))
+}
+
+// Test tool handlers against mock arXiv and Semantic Scholar APIs
+func TestHandlers(t *testing.T) {
+	var s2Down atomic.Bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/arxiv/query":
+			if r.URL.Query().Get("id_list") == "9999.99999" {
+				w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`))
+				return
+			}
+			w.Write([]byte(testArxivFeed))
+		case strings.HasPrefix(r.URL.Path, "/s2/") && s2Down.Load():
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/s2/paper/search":
+			assert.Equal(t, "s2-key", r.Header.Get("X-Api-Key"))
+			w.Write([]byte(testS2Search))
+		case r.URL.Path == "/s2/paper/DOI:10.18653/v1/N19-1423":
+			w.Write([]byte(`{"paperId": "abc", "title": "BERT", "authors": [{"name": "Jacob Devlin"}], "year": 2019, "venue": "NAACL", "externalIds": {"DOI": "10.18653/v1/N19-1423"}}`))
+		case r.URL.Path == "/s2/paper/arXiv:1706.03762/citations":
+			w.Write([]byte(`{"data": [{"citingPaper": {"paperId": "abc", "title": "BERT", "year": 2019}}, {"citingPaper": {"paperId": null, "title": "Unresolved"}}]}`))
+		case r.URL.Path == "/s2/paper/arXiv:1706.03762/references":
+			w.Write([]byte(`{"data": [{"citedPaper": {"paperId": "def", "title": "Neural Machine Translation", "year": 2014}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ps := NewPapersServer(mockServer.URL+"/arxiv", mockServer.URL+"/s2", "s2-key", "agent", 5, 1024*1024, 0, 0)
+	ctx := context.Background()
+
+	t.Run("Search all sources", func(t *testing.T) {
+		result, err := ps.handleSearchPapers(ctx, mcptest.NewCallToolRequest("searchPapers", map[string]interface{}{
+			"query": "attention",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Found 2 papers", "The arXiv duplicate should be merged")
+		assert.Contains(t, text, "Citations: 100000")
+		assert.Contains(t, text, "IDs: arXiv:1706.03762, DOI:10.5555/3295222")
+	})
+
+	t.Run("Search with one source failing", func(t *testing.T) {
+		s2Down.Store(true)
+		defer s2Down.Store(false)
+
+		result, err := ps.handleSearchPapers(ctx, mcptest.NewCallToolRequest("searchPapers", map[string]interface{}{
+			"query": "attention",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Warning: Semantic Scholar search failed: rate limited")
+		assert.Contains(t, text, "Attention Is All You Need")
+	})
+
+	t.Run("Get arXiv paper", func(t *testing.T) {
+		result, err := ps.handleGetPaper(ctx, mcptest.NewCallToolRequest("getPaper", map[string]interface{}{
+			"id": "arXiv:1706.03762",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Abstract:\nThe dominant sequence")
+	})
+
+	t.Run("Get paper by DOI", func(t *testing.T) {
+		result, err := ps.handleGetPaper(ctx, mcptest.NewCallToolRequest("getPaper", map[string]interface{}{
+			"id": "10.18653/v1/N19-1423",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Venue: NAACL")
+	})
+
+	t.Run("Get missing paper", func(t *testing.T) {
+		_, err := ps.handleGetPaper(ctx, mcptest.NewCallToolRequest("getPaper", map[string]interface{}{
+			"id": "9999.99999",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "paper not found")
+	})
+
+	t.Run("Citations", func(t *testing.T) {
+		result, err := ps.handleGetCitations(ctx, mcptest.NewCallToolRequest("getCitations", map[string]interface{}{
+			"id": "1706.03762",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1 papers citing 1706.03762")
+		assert.NotContains(t, text, "Unresolved")
+	})
+
+	t.Run("References", func(t *testing.T) {
+		result, err := ps.handleGetCitations(ctx, mcptest.NewCallToolRequest("getCitations", map[string]interface{}{
+			"id":        "1706.03762",
+			"direction": "references",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Neural Machine Translation")
+	})
+
+	t.Run("Export BibTeX", func(t *testing.T) {
+		result, err := ps.handleExportBibtex(ctx, mcptest.NewCallToolRequest("exportBibtex", map[string]interface{}{
+			"ids": "1706.03762, DOI:10.18653/v1/N19-1423",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "@misc{vaswani2017attention,")
+		assert.Contains(t, text, "@article{devlin2019bert,")
+		assert.Contains(t, text, "  journal = {NAACL},")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewPapersServer(defaultArxivURL, defaultS2URL, "", "agent", 5, 1024, 0, 0).Server())
+}
@@ -0,0 +1,88 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/pkg/mcpserver"
+)
+
+// List returns the bundled servers and those registered with mcpserver.Register,
+// sorted by name. Registered servers named like a bundled one are left out.
+func List() []Server {
+	list := append([]Server(nil), All...)
+	for _, srv := range mcpserver.Registered() {
+		if _, ok := bundled(srv.Name()); ok {
+			log.Printf("Warning: Server %s is bundled, ignoring the registered server of the same name", srv.Name())
+			continue
+		}
+		list = append(list, fromRegistered(srv))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// fromRegistered adapts a registered server to the New and Run functions of the
+// bundled servers.
+func fromRegistered(srv mcpserver.Server) Server {
+	s := Server{Name: srv.Name()}
+	if d, ok := srv.(mcpserver.Describer); ok {
+		s.Description = d.Description()
+	}
+	s.New = func(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+		fs := flag.NewFlagSet(srv.Name(), flag.ContinueOnError)
+		if f, ok := srv.(mcpserver.FlagRegisterer); ok {
+			f.RegisterFlags(fs)
+		}
+		var transportFlags transport.Flags
+		transportFlags.Register(fs)
+		var middlewareFlags middleware.Flags
+		middlewareFlags.Register(fs)
+		if err := config.Parse(fs, args); err != nil {
+			return nil, transport.Flags{}, err
+		}
+
+		mcpServer := server.NewMCPServer(srv.Name(), srv.Version(), server.WithLogging())
+		if err := srv.Register(mcpServer); err != nil {
+			return nil, transport.Flags{}, fmt.Errorf("server %s: %w", srv.Name(), err)
+		}
+		if err := middlewareFlags.Apply(ctx, fs.Name(), mcpServer); err != nil {
+			return nil, transport.Flags{}, err
+		}
+		return mcpServer, transportFlags, nil
+	}
+	s.Run = func(args []string) error {
+		log.SetPrefix(fmt.Sprintf("[%s] ", srv.Name()))
+		log.SetFlags(log.Ldate | log.Ltime)
+
+		// Background work of the server stops when Run returns
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mcpServer, transportFlags, err := s.New(ctx, args)
+		if err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+
+		defer middleware.Close(mcpServer)
+		if err := transport.Serve(mcpServer, transportFlags); err != nil {
+			log.Printf("Error: Server execution failed: %v", err)
+			return err
+		}
+		log.Printf("Server %s shutdown", srv.Name())
+		return nil
+	}
+	return s
+}
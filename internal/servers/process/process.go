@@ -0,0 +1,230 @@
+package process
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// procEntry is a process in a listing.
+type procEntry struct {
+	PID     int       `json:"pid"`
+	PPID    int       `json:"ppid"`
+	Name    string    `json:"name"`
+	State   string    `json:"state"`
+	User    string    `json:"user"`
+	Threads int       `json:"threads"`
+	RSS     uint64    `json:"rss"`
+	Started time.Time `json:"started,omitempty"`
+	Command string    `json:"command,omitempty"`
+}
+
+// procDetail is a process with the details an inspection reports.
+type procDetail struct {
+	procEntry
+	Exe       string   `json:"exe,omitempty"`
+	Cwd       string   `json:"cwd,omitempty"`
+	EnvCount  int      `json:"envCount"`
+	OpenFiles int      `json:"openFiles"`
+	Sockets   []socket `json:"sockets,omitempty"`
+	// Restricted lists details that could not be read, usually for lack of permission
+	Restricted []string `json:"restricted,omitempty"`
+}
+
+// socket is a network socket held by a process.
+type socket struct {
+	Proto  string `json:"proto"`
+	Local  string `json:"local"`
+	Remote string `json:"remote,omitempty"`
+	State  string `json:"state"`
+	inode  uint64
+}
+
+// processTable reads processes and delivers signals to them.
+type processTable interface {
+	List() ([]procEntry, error)
+	Inspect(pid int) (procDetail, error)
+	Signal(pid int, sig syscall.Signal) error
+}
+
+// parseSignal accepts names with or without the SIG prefix, in any case.
+func parseSignal(name string) (syscall.Signal, string, error) {
+	if name == "" {
+		name = "TERM"
+	}
+	name = strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	sig, ok := signals[name]
+	if !ok {
+		return 0, "", fmt.Errorf("unsupported signal %q; use %s", name, strings.Join(signalNames, ", "))
+	}
+	return sig, "SIG" + name, nil
+}
+
+// allowlist holds the process name patterns signals may be sent to.
+type allowlist []string
+
+// parseAllowlist splits a comma separated list of glob patterns.
+func parseAllowlist(s string) (allowlist, error) {
+	var list allowlist
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+// allows reports whether a process name matches one of the patterns.
+func (l allowlist) allows(name string) bool {
+	for _, p := range l {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatus reads the fields of /proc/<pid>/status a listing needs. The user is left
+// as the numeric UID.
+func parseStatus(r io.Reader) (procEntry, string, error) {
+	var e procEntry
+	var uid string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Name":
+			e.Name = value
+		case "State":
+			e.State = value
+		case "PPid":
+			e.PPID, _ = strconv.Atoi(value)
+		case "Pid":
+			e.PID, _ = strconv.Atoi(value)
+		case "Uid":
+			if fields := strings.Fields(value); len(fields) > 0 {
+				uid = fields[0]
+			}
+		case "Threads":
+			e.Threads, _ = strconv.Atoi(value)
+		case "VmRSS":
+			if fields := strings.Fields(value); len(fields) > 0 {
+				kb, _ := strconv.ParseUint(fields[0], 10, 64)
+				e.RSS = kb * 1024
+			}
+		}
+	}
+	if e.Name == "" {
+		return procEntry{}, "", errors.New("invalid status")
+	}
+	return e, uid, scanner.Err()
+}
+
+// parseStartTicks returns the start time field of /proc/<pid>/stat, in clock ticks after
+// boot. Fields are counted from the last ")" since the name may contain spaces.
+func parseStartTicks(stat string) (uint64, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, errors.New("invalid stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return 0, errors.New("invalid stat")
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// tcpStates names the states of /proc/net/tcp.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+	"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+	"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING",
+}
+
+// parseSockets reads a /proc/net/{tcp,tcp6,udp,udp6} table.
+func parseSockets(r io.Reader, proto string) ([]socket, error) {
+	var sockets []socket
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+		local, err := decodeAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		remote, err := decodeAddress(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+		s := socket{Proto: proto, Local: local, State: tcpStates[fields[3]], inode: inode}
+		if strings.HasPrefix(proto, "udp") {
+			// UDP sockets are either bound or connected
+			s.State = "UNCONN"
+			if fields[3] == "01" {
+				s.State = "ESTABLISHED"
+			}
+		}
+		if s.State != "LISTEN" && s.State != "UNCONN" {
+			s.Remote = remote
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// decodeAddress converts "0100007F:1F90" to "127.0.0.1:8080". The kernel prints each
+// 32-bit word of the address in host byte order, which is little endian on the
+// platforms this reads.
+func decodeAddress(s string) (string, error) {
+	host, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid address %q", s)
+	}
+	raw, err := hex.DecodeString(host)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", fmt.Errorf("invalid address %q", s)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid port in %q", s)
+	}
+	// IPv4-mapped addresses of dual-stack sockets print in dotted form
+	return net.JoinHostPort(net.IP(raw).String(), strconv.FormatUint(p, 10)), nil
+}
+
+// formatBytes renders a byte count with binary units.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit && exp < 5; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,463 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// ProcessServer is an MCP server that lists and inspects processes and, when enabled,
+// sends signals to allowlisted processes.
+type ProcessServer struct {
+	server       *server.MCPServer
+	table        processTable
+	allowSignals bool
+	allowlist    allowlist
+	maxProcesses int
+}
+
+// NewProcessServer creates a new ProcessServer instance. The sendSignal tool is only
+// registered when signals are allowed.
+func NewProcessServer(table processTable, allowSignals bool, allowed allowlist, maxProcesses int) *ProcessServer {
+	log.Printf("ProcessServer created: allowSignals=%t, allowlist=%v, maxProcesses=%d", allowSignals, allowed, maxProcesses)
+
+	s := &ProcessServer{
+		table:        table,
+		allowSignals: allowSignals,
+		allowlist:    allowed,
+		maxProcesses: maxProcesses,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"process-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register listProcesses tool
+	listProcessesTool := mcp.NewTool("listProcesses",
+		mcp.WithDescription("Lists running processes with their owner, state, memory and command line"),
+		mcp.WithString("filter",
+			mcp.Description("Only list processes whose name or command line contains this text (case insensitive)"),
+		),
+		mcp.WithString("user",
+			mcp.Description("Only list processes owned by this user"),
+		),
+		mcp.WithString("sortBy",
+			mcp.Description("Order of the listing (default: pid)"),
+			mcp.Enum("pid", "name", "memory", "started"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of processes to list (default and maximum: %d)", maxProcesses)),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register inspectProcess tool
+	inspectProcessTool := mcp.NewTool("inspectProcess",
+		mcp.WithDescription("Shows the details of a process: command line, executable, working directory, "+
+			"number of environment variables, open files and network sockets including listening ports"),
+		mcp.WithNumber("pid",
+			mcp.Description("Process ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listProcessesTool, s.handleListProcesses)
+	middleware.AddTool(mcpServer, inspectProcessTool, s.handleInspectProcess)
+
+	// Register sendSignal tool
+	if allowSignals {
+		sendSignalTool := mcp.NewTool("sendSignal",
+			mcp.WithDescription(fmt.Sprintf("Sends a signal to a process by PID, or to all processes with a name. "+
+				"Only processes whose name matches %s can be signalled", describeAllowlist(allowed))),
+			mcp.WithNumber("pid",
+				mcp.Description("Process ID"),
+			),
+			mcp.WithString("name",
+				mcp.Description("Exact process name, used instead of pid to signal every process with that name"),
+			),
+			mcp.WithString("signal",
+				mcp.Description("Signal to send (default: TERM)"),
+				mcp.Enum(signalNames...),
+			),
+		)
+		middleware.AddTool(mcpServer, sendSignalTool, s.handleSendSignal)
+	}
+
+	s.server = mcpServer
+	return s
+}
+
+// describeAllowlist renders the allowlist for tool descriptions.
+func describeAllowlist(l allowlist) string {
+	if len(l) == 0 {
+		return "the allowlist, which is empty"
+	}
+	return strings.Join(l, ", ")
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// jsonResult renders v as indented JSON.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return textResult(string(data)), nil
+}
+
+// shorten cuts s to n bytes with an ellipsis.
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+// handleListProcesses handles the listing request.
+func (s *ProcessServer) handleListProcesses(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listProcesses request processing")
+
+	var args struct {
+		Filter string `json:"filter,omitempty"`
+		User   string `json:"user,omitempty"`
+		SortBy string `json:"sortBy,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
+		Format string `json:"format,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Limit <= 0 || args.Limit > s.maxProcesses {
+		args.Limit = s.maxProcesses
+	}
+
+	all, err := s.table.List()
+	if err != nil {
+		log.Printf("Error: Failed to list processes: %v", err)
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	filter := strings.ToLower(args.Filter)
+	var procs []procEntry
+	for _, p := range all {
+		if args.User != "" && p.User != args.User {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(p.Name), filter) && !strings.Contains(strings.ToLower(p.Command), filter) {
+			continue
+		}
+		procs = append(procs, p)
+	}
+
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		switch args.SortBy {
+		case "name":
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case "memory":
+			if a.RSS != b.RSS {
+				return a.RSS > b.RSS
+			}
+		case "started":
+			if !a.Started.Equal(b.Started) {
+				return a.Started.After(b.Started)
+			}
+		}
+		return a.PID < b.PID
+	})
+	matched := len(procs)
+	procs = procs[:min(len(procs), args.Limit)]
+
+	log.Printf("listProcesses request completed: %d of %d processes", len(procs), len(all))
+	if args.Format == "json" {
+		return jsonResult(procs)
+	}
+	if matched == 0 {
+		return textResult("No processes match"), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d processes match", matched, len(all))
+	if len(procs) < matched {
+		fmt.Fprintf(&b, ", showing %d", len(procs))
+	}
+	fmt.Fprintf(&b, "\n\n%7s %7s %-12s %-5s %10s %-16s  %s\n", "PID", "PPID", "USER", "STATE", "RSS", "STARTED", "COMMAND")
+	for _, p := range procs {
+		started := ""
+		if !p.Started.IsZero() {
+			started = p.Started.Local().Format("2006-01-02 15:04")
+		}
+		command := p.Command
+		if command == "" {
+			// Kernel threads have no command line
+			command = "[" + p.Name + "]"
+		}
+		state, _, _ := strings.Cut(p.State, " ")
+		fmt.Fprintf(&b, "%7d %7d %-12s %-5s %10s %-16s  %s\n",
+			p.PID, p.PPID, shorten(p.User, 12), state, formatBytes(p.RSS), started, shorten(command, 100))
+	}
+	return textResult(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// handleInspectProcess handles the inspection request.
+func (s *ProcessServer) handleInspectProcess(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting inspectProcess request processing")
+
+	var args struct {
+		PID    int    `json:"pid"`
+		Format string `json:"format,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.PID <= 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "pid must be positive")
+	}
+
+	d, err := s.table.Inspect(args.PID)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no process with pid %d", args.PID)
+	}
+	if err != nil {
+		log.Printf("Error: Failed to inspect process %d: %v", args.PID, err)
+		return nil, fmt.Errorf("failed to inspect process %d: %w", args.PID, err)
+	}
+
+	log.Printf("inspectProcess request completed: pid=%d", args.PID)
+	if args.Format == "json" {
+		return jsonResult(d)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Process %d (%s)\n", d.PID, d.Name)
+	fmt.Fprintf(&b, "State: %s\n", d.State)
+	fmt.Fprintf(&b, "Parent: %d\n", d.PPID)
+	fmt.Fprintf(&b, "User: %s\n", d.User)
+	if !d.Started.IsZero() {
+		fmt.Fprintf(&b, "Started: %s (%s ago)\n", d.Started.Local().Format(time.RFC3339), time.Since(d.Started).Round(time.Second))
+	}
+	fmt.Fprintf(&b, "Threads: %d\n", d.Threads)
+	fmt.Fprintf(&b, "Memory: %s resident\n", formatBytes(d.RSS))
+	if d.Command != "" {
+		fmt.Fprintf(&b, "Command: %s\n", d.Command)
+	}
+	if d.Exe != "" {
+		fmt.Fprintf(&b, "Executable: %s\n", d.Exe)
+	}
+	if d.Cwd != "" {
+		fmt.Fprintf(&b, "Working directory: %s\n", d.Cwd)
+	}
+	fmt.Fprintf(&b, "Environment variables: %d\n", d.EnvCount)
+	fmt.Fprintf(&b, "Open files: %d\n", d.OpenFiles)
+
+	var listening, connections []string
+	for _, sock := range d.Sockets {
+		switch sock.State {
+		case "LISTEN", "UNCONN":
+			listening = append(listening, fmt.Sprintf("  %-5s %s", sock.Proto, sock.Local))
+		default:
+			connections = append(connections, fmt.Sprintf("  %-5s %s -> %s %s", sock.Proto, sock.Local, sock.Remote, sock.State))
+		}
+	}
+	if len(listening) > 0 {
+		b.WriteString("\nListening ports:\n" + strings.Join(listening, "\n") + "\n")
+	}
+	if len(connections) > 0 {
+		b.WriteString("\nConnections:\n" + strings.Join(connections, "\n") + "\n")
+	}
+	if len(d.Restricted) > 0 {
+		fmt.Fprintf(&b, "\nNot readable without more privileges: %s\n", strings.Join(d.Restricted, ", "))
+	}
+	return textResult(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// handleSendSignal handles the signal request.
+func (s *ProcessServer) handleSendSignal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendSignal request processing")
+
+	if !s.allowSignals {
+		return nil, fmt.Errorf("sending signals is disabled; start the server with -allow-signals")
+	}
+
+	var args struct {
+		PID    int    `json:"pid,omitempty"`
+		Name   string `json:"name,omitempty"`
+		Signal string `json:"signal,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if (args.PID == 0) == (args.Name == "") {
+		return nil, fmt.Errorf("provide either pid or name")
+	}
+	sig, sigName, err := parseSignal(args.Signal)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.allowlist) == 0 {
+		return nil, fmt.Errorf("no processes may be signalled; start the server with -signal-allowlist")
+	}
+
+	all, err := s.table.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	var targets []procEntry
+	for _, p := range all {
+		if p.PID == args.PID || (args.Name != "" && p.Name == args.Name) {
+			targets = append(targets, p)
+		}
+	}
+	if len(targets) == 0 {
+		if args.Name != "" {
+			return nil, fmt.Errorf("no process is named %s", args.Name)
+		}
+		return nil, fmt.Errorf("no process with pid %d", args.PID)
+	}
+
+	// Check every target before signalling any
+	for _, p := range targets {
+		switch {
+		case p.PID <= 1:
+			return nil, fmt.Errorf("refusing to signal pid %d", p.PID)
+		case p.PID == os.Getpid() || p.PID == os.Getppid():
+			return nil, fmt.Errorf("refusing to signal pid %d, which runs this server or its host", p.PID)
+		case !s.allowlist.allows(p.Name):
+			return nil, fmt.Errorf("process %d (%s) does not match the signal allowlist (%s)", p.PID, p.Name, strings.Join(s.allowlist, ", "))
+		}
+	}
+
+	var sent, failed []string
+	for _, p := range targets {
+		if err := s.table.Signal(p.PID, sig); err != nil {
+			failed = append(failed, fmt.Sprintf("%d (%s): %v", p.PID, p.Name, err))
+			continue
+		}
+		log.Printf("Sent %s to pid %d (%s)", sigName, p.PID, p.Name)
+		sent = append(sent, fmt.Sprintf("%d (%s)", p.PID, p.Name))
+	}
+	if len(sent) == 0 {
+		return nil, fmt.Errorf("failed to send %s: %s", sigName, strings.Join(failed, "; "))
+	}
+
+	text := fmt.Sprintf("Sent %s to %s", sigName, strings.Join(sent, ", "))
+	if len(failed) > 0 {
+		text += "\nFailed: " + strings.Join(failed, "; ")
+	}
+	log.Printf("sendSignal request completed: %d sent, %d failed", len(sent), len(failed))
+	return textResult(text), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *ProcessServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the process server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("process", flag.ContinueOnError)
+	var (
+		allowSignals    bool
+		signalAllowlist string
+		maxProcesses    int
+	)
+	fs.BoolVar(&allowSignals, "allow-signals", false, "Enable the sendSignal tool")
+	fs.StringVar(&signalAllowlist, "signal-allowlist", "", "Comma separated glob patterns of process names that may be signalled, e.g. \"node,python*\"")
+	fs.IntVar(&maxProcesses, "max-processes", 200, "Maximum number of processes listed")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting process server: allow-signals=%t, signal-allowlist=%q", allowSignals, signalAllowlist)
+
+	allowed, err := parseAllowlist(signalAllowlist)
+	if err != nil {
+		log.Printf("Error: Invalid -signal-allowlist: %v", err)
+		return nil, transport.Flags{}, err
+	}
+	if allowSignals && len(allowed) == 0 {
+		log.Println("Warning: -allow-signals is set but -signal-allowlist is empty; no process can be signalled")
+	}
+
+	// Create ProcessServer instance
+	processServer := NewProcessServer(newProcessTable(), allowSignals, allowed, maxProcesses)
+	log.Println("ProcessServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), processServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return processServer.Server(), transportFlags, nil
+}
+
+// Run starts the process server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[ProcessServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create process server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("ProcessServer shutdown")
+	return nil
+}
@@ -0,0 +1,310 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTable is an in-memory process table that records signals.
+type fakeTable struct {
+	procs    []procEntry
+	detail   map[int]procDetail
+	signaled map[int]syscall.Signal
+	failPID  int
+}
+
+func newFakeTable() *fakeTable {
+	started := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	return &fakeTable{
+		procs: []procEntry{
+			{PID: 1, Name: "systemd", State: "S (sleeping)", User: "root", RSS: 12 << 20, Started: started, Command: "/sbin/init"},
+			{PID: 2, Name: "kthreadd", State: "S (sleeping)", User: "root", Started: started},
+			{PID: 300, PPID: 1, Name: "node", State: "R (running)", User: "alice", RSS: 300 << 20, Started: started.Add(time.Hour), Command: "node server.js"},
+			{PID: 301, PPID: 1, Name: "node", State: "S (sleeping)", User: "alice", RSS: 100 << 20, Started: started.Add(2 * time.Hour), Command: "node worker.js"},
+			{PID: 400, PPID: 1, Name: "postgres", State: "S (sleeping)", User: "postgres", RSS: 500 << 20, Started: started, Command: "postgres -D /var/lib/postgres"},
+		},
+		detail: map[int]procDetail{
+			300: {
+				procEntry: procEntry{PID: 300, PPID: 1, Name: "node", State: "R (running)", User: "alice", Threads: 11, RSS: 300 << 20, Command: "node server.js"},
+				Exe:       "/usr/bin/node",
+				Cwd:       "/srv/app",
+				EnvCount:  24,
+				OpenFiles: 30,
+				Sockets: []socket{
+					{Proto: "tcp", Local: "0.0.0.0:3000", State: "LISTEN"},
+					{Proto: "tcp", Local: "10.0.0.5:3000", Remote: "10.0.0.9:51234", State: "ESTABLISHED"},
+					{Proto: "udp", Local: "0.0.0.0:5353", State: "UNCONN"},
+				},
+			},
+			400: {
+				procEntry:  procEntry{PID: 400, Name: "postgres", User: "postgres"},
+				Restricted: []string{"exe", "cwd", "environment", "open files"},
+			},
+		},
+		signaled: make(map[int]syscall.Signal),
+	}
+}
+
+func (f *fakeTable) List() ([]procEntry, error) {
+	return append([]procEntry(nil), f.procs...), nil
+}
+
+func (f *fakeTable) Inspect(pid int) (procDetail, error) {
+	d, ok := f.detail[pid]
+	if !ok {
+		return procDetail{}, os.ErrNotExist
+	}
+	return d, nil
+}
+
+func (f *fakeTable) Signal(pid int, sig syscall.Signal) error {
+	if pid == f.failPID {
+		return syscall.EPERM
+	}
+	f.signaled[pid] = sig
+	return nil
+}
+
+// ProcessServer creation test
+func TestNewProcessServer(t *testing.T) {
+	table := newFakeTable()
+	s := NewProcessServer(table, true, allowlist{"node"}, 50)
+
+	assert.NotNil(t, s, "ProcessServer instance should be created")
+	assert.Equal(t, table, s.table, "Process table should match")
+	assert.True(t, s.allowSignals, "Signal permission should match")
+	assert.Equal(t, allowlist{"node"}, s.allowlist, "Allowlist should match")
+	assert.Equal(t, 50, s.maxProcesses, "Max processes should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewProcessServer(newFakeTable(), false, nil, 50)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test procfs parsers
+func TestParsers(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		status := "Name:\tnode\nUmask:\t0022\nState:\tS (sleeping)\nTgid:\t300\nPid:\t300\nPPid:\t1\n" +
+			"Uid:\t1000\t1000\t1000\t1000\nVmRSS:\t  307200 kB\nThreads:\t11\n"
+		e, uid, err := parseStatus(strings.NewReader(status))
+		require.NoError(t, err)
+		assert.Equal(t, procEntry{PID: 300, PPID: 1, Name: "node", State: "S (sleeping)", Threads: 11, RSS: 300 << 20}, e)
+		assert.Equal(t, "1000", uid)
+
+		_, _, err = parseStatus(strings.NewReader(""))
+		assert.Error(t, err)
+	})
+
+	t.Run("start ticks", func(t *testing.T) {
+		ticks, err := parseStartTicks("300 (my (odd) name) S 1 300 300 0 -1 4194304 100 0 0 0 5 3 0 0 20 0 11 0 123456 1000 250")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(123456), ticks)
+	})
+
+	t.Run("sockets", func(t *testing.T) {
+		tcp := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+			"   0: 00000000:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 11111 1 0000000000000000 100 0 0 10 0\n" +
+			"   1: 0500000A:0BB8 0900000A:C802 01 00000000:00000000 00:00000000 00000000  1000        0 22222 1 0000000000000000 20 4 30 10 -1\n"
+		sockets, err := parseSockets(strings.NewReader(tcp), "tcp")
+		require.NoError(t, err)
+		require.Len(t, sockets, 2)
+		assert.Equal(t, socket{Proto: "tcp", Local: "0.0.0.0:3000", State: "LISTEN", inode: 11111}, sockets[0])
+		assert.Equal(t, socket{Proto: "tcp", Local: "10.0.0.5:3000", Remote: "10.0.0.9:51202", State: "ESTABLISHED", inode: 22222}, sockets[1])
+
+		udp6 := "  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n" +
+			"   0: 00000000000000000000000000000000:14E9 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000   100        0 33333 2 0000000000000000 0\n"
+		sockets, err = parseSockets(strings.NewReader(udp6), "udp6")
+		require.NoError(t, err)
+		assert.Equal(t, []socket{{Proto: "udp6", Local: "[::]:5353", State: "UNCONN", inode: 33333}}, sockets)
+	})
+
+	t.Run("addresses", func(t *testing.T) {
+		tests := map[string]string{
+			"0100007F:1F90":                         "127.0.0.1:8080",
+			"00000000000000000000000001000000:0016": "[::1]:22",
+			"0000000000000000FFFF00000100007F:0050": "127.0.0.1:80",
+		}
+		for in, want := range tests {
+			got, err := decodeAddress(in)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, in)
+		}
+		_, err := decodeAddress("zz:1")
+		assert.Error(t, err)
+	})
+
+	t.Run("signals and allowlist", func(t *testing.T) {
+		sig, name, err := parseSignal("sigterm")
+		require.NoError(t, err)
+		assert.Equal(t, syscall.SIGTERM, sig)
+		assert.Equal(t, "SIGTERM", name)
+		_, name, err = parseSignal("")
+		require.NoError(t, err)
+		assert.Equal(t, "SIGTERM", name)
+		_, _, err = parseSignal("SEGV")
+		assert.ErrorContains(t, err, "unsupported signal")
+
+		list, err := parseAllowlist(" node, python* ,,")
+		require.NoError(t, err)
+		assert.Equal(t, allowlist{"node", "python*"}, list)
+		assert.True(t, list.allows("python3.12"))
+		assert.False(t, list.allows("nodejs"))
+		_, err = parseAllowlist("[")
+		assert.Error(t, err)
+	})
+}
+
+// Test listProcesses handler
+func TestHandleListProcesses(t *testing.T) {
+	s := NewProcessServer(newFakeTable(), false, nil, 50)
+
+	result, err := s.handleListProcesses(context.Background(), mcptest.NewCallToolRequest("listProcesses", nil))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.True(t, strings.HasPrefix(text, "5 of 5 processes match\n"))
+	assert.Contains(t, text, "node server.js")
+	assert.Contains(t, text, "[kthreadd]", "Kernel threads should be shown by name")
+
+	tests := []struct {
+		name  string
+		args  map[string]interface{}
+		first int
+		count int
+	}{
+		{"filter", map[string]interface{}{"filter": "WORKER"}, 301, 1},
+		{"user", map[string]interface{}{"user": "alice"}, 300, 2},
+		{"memory order", map[string]interface{}{"sortBy": "memory"}, 400, 5},
+		{"newest first", map[string]interface{}{"sortBy": "started"}, 301, 5},
+		{"limit", map[string]interface{}{"sortBy": "name", "limit": 2}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{"format": "json"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			result, err := s.handleListProcesses(context.Background(), mcptest.NewCallToolRequest("listProcesses", args))
+			require.NoError(t, err)
+			var procs []procEntry
+			require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &procs))
+			require.Len(t, procs, tt.count)
+			assert.Equal(t, tt.first, procs[0].PID)
+		})
+	}
+
+	result, err = s.handleListProcesses(context.Background(), mcptest.NewCallToolRequest("listProcesses", map[string]interface{}{"filter": "nothing"}))
+	require.NoError(t, err)
+	assert.Equal(t, "No processes match", mcptest.ResultText(result))
+}
+
+// Test inspectProcess handler
+func TestHandleInspectProcess(t *testing.T) {
+	s := NewProcessServer(newFakeTable(), false, nil, 50)
+
+	result, err := s.handleInspectProcess(context.Background(), mcptest.NewCallToolRequest("inspectProcess", map[string]interface{}{"pid": 300}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	for _, expected := range []string{
+		"Process 300 (node)",
+		"Command: node server.js",
+		"Executable: /usr/bin/node",
+		"Working directory: /srv/app",
+		"Environment variables: 24",
+		"Listening ports:\n  tcp   0.0.0.0:3000\n  udp   0.0.0.0:5353",
+		"Connections:\n  tcp   10.0.0.5:3000 -> 10.0.0.9:51234 ESTABLISHED",
+	} {
+		assert.Contains(t, text, expected)
+	}
+
+	result, err = s.handleInspectProcess(context.Background(), mcptest.NewCallToolRequest("inspectProcess", map[string]interface{}{"pid": 400}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Not readable without more privileges: exe, cwd, environment, open files")
+
+	_, err = s.handleInspectProcess(context.Background(), mcptest.NewCallToolRequest("inspectProcess", map[string]interface{}{"pid": 999}))
+	assert.EqualError(t, err, "no process with pid 999")
+	_, err = s.handleInspectProcess(context.Background(), mcptest.NewCallToolRequest("inspectProcess", map[string]interface{}{}))
+	assert.ErrorContains(t, err, "pid must be positive")
+}
+
+// Test sendSignal handler
+func TestHandleSendSignal(t *testing.T) {
+	t.Run("by name", func(t *testing.T) {
+		table := newFakeTable()
+		s := NewProcessServer(table, true, allowlist{"no*"}, 50)
+		result, err := s.handleSendSignal(context.Background(), mcptest.NewCallToolRequest("sendSignal", map[string]interface{}{
+			"name":   "node",
+			"signal": "HUP",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "Sent SIGHUP to 300 (node), 301 (node)", mcptest.ResultText(result))
+		assert.Equal(t, map[int]syscall.Signal{300: syscall.SIGHUP, 301: syscall.SIGHUP}, table.signaled)
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		table := newFakeTable()
+		table.failPID = 301
+		s := NewProcessServer(table, true, allowlist{"node"}, 50)
+		result, err := s.handleSendSignal(context.Background(), mcptest.NewCallToolRequest("sendSignal", map[string]interface{}{"name": "node"}))
+		require.NoError(t, err)
+		assert.Equal(t, "Sent SIGTERM to 300 (node)\nFailed: 301 (node): operation not permitted", mcptest.ResultText(result))
+	})
+
+	tests := []struct {
+		name      string
+		allow     bool
+		allowlist allowlist
+		args      map[string]interface{}
+		wantErr   string
+	}{
+		{"disabled", false, allowlist{"node"}, map[string]interface{}{"pid": 300}, "-allow-signals"},
+		{"empty allowlist", true, nil, map[string]interface{}{"pid": 300}, "-signal-allowlist"},
+		{"not allowlisted", true, allowlist{"node"}, map[string]interface{}{"pid": 400}, "does not match the signal allowlist"},
+		{"init", true, allowlist{"*"}, map[string]interface{}{"pid": 1}, "refusing to signal pid 1"},
+		{"pid and name", true, allowlist{"node"}, map[string]interface{}{"pid": 300, "name": "node"}, "either pid or name"},
+		{"unknown pid", true, allowlist{"node"}, map[string]interface{}{"pid": 12345}, "no process with pid 12345"},
+		{"unknown name", true, allowlist{"node"}, map[string]interface{}{"name": "deno"}, "no process is named deno"},
+		{"bad signal", true, allowlist{"node"}, map[string]interface{}{"pid": 300, "signal": "BUS"}, "unsupported signal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newFakeTable()
+			s := NewProcessServer(table, tt.allow, tt.allowlist, 50)
+			_, err := s.handleSendSignal(context.Background(), mcptest.NewCallToolRequest("sendSignal", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, table.signaled, "No signal should be sent")
+		})
+	}
+
+	t.Run("own process", func(t *testing.T) {
+		table := newFakeTable()
+		table.procs = append(table.procs, procEntry{PID: os.Getpid(), Name: "process"})
+		s := NewProcessServer(table, true, allowlist{"*"}, 50)
+		_, err := s.handleSendSignal(context.Background(), mcptest.NewCallToolRequest("sendSignal", map[string]interface{}{"pid": os.Getpid()}))
+		assert.ErrorContains(t, err, "runs this server")
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		table := newFakeTable()
+		table.failPID = 300
+		s := NewProcessServer(table, true, allowlist{"node"}, 50)
+		_, err := s.handleSendSignal(context.Background(), mcptest.NewCallToolRequest("sendSignal", map[string]interface{}{"pid": 300}))
+		assert.ErrorContains(t, err, "operation not permitted")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewProcessServer(newFakeTable(), false, nil, 50).Server())
+}
@@ -0,0 +1,187 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// signals maps the names accepted by sendSignal to signals.
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"STOP": syscall.SIGSTOP,
+	"CONT": syscall.SIGCONT,
+}
+
+var signalNames = []string{"HUP", "INT", "QUIT", "KILL", "TERM", "USR1", "USR2", "STOP", "CONT"}
+
+// clockTicks is USER_HZ, which Linux fixes at 100 on all architectures Go supports.
+const clockTicks = 100
+
+// procfsTable reads processes from procfs.
+type procfsTable struct {
+	proc  string
+	mu    sync.Mutex
+	users map[string]string // UID to user name
+}
+
+func newProcessTable() processTable {
+	return &procfsTable{proc: "/proc", users: make(map[string]string)}
+}
+
+// userName resolves a UID, falling back to the number.
+func (t *procfsTable) userName(uid string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if name, ok := t.users[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	t.users[uid] = name
+	return name
+}
+
+// bootTime reads the boot time from /proc/stat.
+func (t *procfsTable) bootTime() time.Time {
+	f, err := os.Open(filepath.Join(t.proc, "stat"))
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			seconds, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// entry reads a single process. Processes may exit while being read.
+func (t *procfsTable) entry(pid int, boot time.Time) (procEntry, error) {
+	dir := filepath.Join(t.proc, strconv.Itoa(pid))
+	f, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return procEntry{}, err
+	}
+	e, uid, err := parseStatus(f)
+	f.Close()
+	if err != nil {
+		return procEntry{}, err
+	}
+	e.PID = pid
+	e.User = t.userName(uid)
+	if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil && !boot.IsZero() {
+		if ticks, err := parseStartTicks(string(stat)); err == nil {
+			e.Started = boot.Add(time.Duration(ticks) * time.Second / clockTicks)
+		}
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		e.Command = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	}
+	return e, nil
+}
+
+func (t *procfsTable) List() ([]procEntry, error) {
+	entries, err := os.ReadDir(t.proc)
+	if err != nil {
+		return nil, err
+	}
+	boot := t.bootTime()
+	var procs []procEntry
+	for _, d := range entries {
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil {
+			continue
+		}
+		if e, err := t.entry(pid, boot); err == nil {
+			procs = append(procs, e)
+		}
+	}
+	return procs, nil
+}
+
+func (t *procfsTable) Inspect(pid int) (procDetail, error) {
+	e, err := t.entry(pid, t.bootTime())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return procDetail{}, os.ErrNotExist
+		}
+		return procDetail{}, err
+	}
+	d := procDetail{procEntry: e}
+	dir := filepath.Join(t.proc, strconv.Itoa(pid))
+
+	if d.Exe, err = os.Readlink(filepath.Join(dir, "exe")); err != nil {
+		d.Restricted = append(d.Restricted, "exe")
+	}
+	if d.Cwd, err = os.Readlink(filepath.Join(dir, "cwd")); err != nil {
+		d.Restricted = append(d.Restricted, "cwd")
+	}
+	// Only the number of variables is reported, since values often hold secrets
+	if environ, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+		d.EnvCount = bytes.Count(environ, []byte{0})
+	} else {
+		d.Restricted = append(d.Restricted, "environment")
+	}
+
+	fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		d.Restricted = append(d.Restricted, "open files")
+		return d, nil
+	}
+	d.OpenFiles = len(fds)
+	inodes := make(map[uint64]bool)
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		if v, ok := strings.CutPrefix(link, "socket:["); ok {
+			inode, _ := strconv.ParseUint(strings.TrimSuffix(v, "]"), 10, 64)
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return d, nil
+	}
+	// The process's own network namespace lists its sockets
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		f, err := os.Open(filepath.Join(dir, "net", proto))
+		if err != nil {
+			continue
+		}
+		sockets, err := parseSockets(f, proto)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		for _, s := range sockets {
+			if inodes[s.inode] {
+				d.Sockets = append(d.Sockets, s)
+			}
+		}
+	}
+	return d, nil
+}
+
+func (t *procfsTable) Signal(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,157 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	ps "github.com/shirou/gopsutil/v4/process"
+)
+
+// signals maps the names accepted by sendSignal to signals.
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+var signalNames = []string{"HUP", "INT", "QUIT", "KILL", "TERM"}
+
+// psTable reads processes with gopsutil on platforms without procfs.
+type psTable struct{}
+
+func newProcessTable() processTable {
+	return psTable{}
+}
+
+// open returns a process, mapping a missing process to os.ErrNotExist.
+func (psTable) open(pid int) (*ps.Process, error) {
+	p, err := ps.NewProcess(int32(pid))
+	if errors.Is(err, ps.ErrorProcessNotRunning) {
+		return nil, os.ErrNotExist
+	}
+	return p, err
+}
+
+// entry reads a single process. Processes may exit while being read.
+func (psTable) entry(p *ps.Process) (procEntry, error) {
+	name, err := p.Name()
+	if err != nil {
+		return procEntry{}, err
+	}
+	e := procEntry{PID: int(p.Pid), Name: name}
+	if ppid, err := p.Ppid(); err == nil {
+		e.PPID = int(ppid)
+	}
+	if status, err := p.Status(); err == nil {
+		e.State = strings.Join(status, ",")
+	}
+	e.User, _ = p.Username()
+	if threads, err := p.NumThreads(); err == nil {
+		e.Threads = int(threads)
+	}
+	if memory, err := p.MemoryInfo(); err == nil {
+		e.RSS = memory.RSS
+	}
+	if created, err := p.CreateTime(); err == nil {
+		e.Started = time.UnixMilli(created)
+	}
+	e.Command, _ = p.Cmdline()
+	return e, nil
+}
+
+func (t psTable) List() ([]procEntry, error) {
+	procs, err := ps.Processes()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]procEntry, 0, len(procs))
+	for _, p := range procs {
+		if e, err := t.entry(p); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (t psTable) Inspect(pid int) (procDetail, error) {
+	p, err := t.open(pid)
+	if err != nil {
+		return procDetail{}, err
+	}
+	e, err := t.entry(p)
+	if err != nil {
+		return procDetail{}, err
+	}
+	d := procDetail{procEntry: e}
+
+	if d.Exe, err = p.Exe(); err != nil {
+		d.Restricted = append(d.Restricted, "exe")
+	}
+	if d.Cwd, err = p.Cwd(); err != nil {
+		d.Restricted = append(d.Restricted, "cwd")
+	}
+	// Only the number of variables is reported, since values often hold secrets
+	if environ, err := p.Environ(); err == nil {
+		d.EnvCount = len(environ)
+	} else {
+		d.Restricted = append(d.Restricted, "environment")
+	}
+	if fds, err := p.NumFDs(); err == nil {
+		d.OpenFiles = int(fds)
+	} else {
+		d.Restricted = append(d.Restricted, "open files")
+	}
+
+	connections, err := p.Connections()
+	if err != nil {
+		return d, nil
+	}
+	for _, c := range connections {
+		s := socket{
+			Proto: socketProto(c.Type, c.Family),
+			Local: net.JoinHostPort(c.Laddr.IP, strconv.FormatUint(uint64(c.Laddr.Port), 10)),
+			State: c.Status,
+		}
+		if c.Raddr.IP != "" && s.State != "LISTEN" {
+			s.Remote = net.JoinHostPort(c.Raddr.IP, strconv.FormatUint(uint64(c.Raddr.Port), 10))
+		}
+		if strings.HasPrefix(s.Proto, "udp") && (s.State == "" || s.State == "NONE") {
+			s.State = "UNCONN"
+		}
+		d.Sockets = append(d.Sockets, s)
+	}
+	return d, nil
+}
+
+// socketProto names a socket type and address family as /proc/net does.
+func socketProto(kind, family uint32) string {
+	proto := "tcp"
+	if kind == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+func (t psTable) Signal(pid int, sig syscall.Signal) error {
+	p, err := t.open(pid)
+	if err != nil {
+		return err
+	}
+	if sig == syscall.SIGKILL {
+		// The only signal Windows can deliver
+		return p.Kill()
+	}
+	return p.SendSignal(sig)
+}
@@ -0,0 +1,549 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// QR code symbol construction following ISO/IEC 18004. Data is encoded as a single numeric,
+// alphanumeric or byte segment, whichever is the most compact for the whole input.
+
+// ECLevel is a QR code error correction level
+type ECLevel int
+
+const (
+	ECLow      ECLevel = iota // Recovers ~7% of codewords
+	ECMedium                  // Recovers ~15% of codewords
+	ECQuartile                // Recovers ~25% of codewords
+	ECHigh                    // Recovers ~30% of codewords
+)
+
+var ecLevelNames = map[string]ECLevel{"L": ECLow, "M": ECMedium, "Q": ECQuartile, "H": ECHigh}
+
+func (e ECLevel) String() string {
+	return [...]string{"L", "M", "Q", "H"}[e]
+}
+
+// formatBits is the two bit level indicator used in the format information
+func (e ECLevel) formatBits() int {
+	return [...]int{1, 0, 3, 2}[e]
+}
+
+// eccCodewordsPerBlock and numECBlocks are indexed by level and version (index 0 unused).
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numECBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+const alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// Segment modes
+const (
+	modeNumeric      = 0x1
+	modeAlphanumeric = 0x2
+	modeByte         = 0x4
+	modeECI          = 0x7
+	modeKanji        = 0x8
+)
+
+// numRawDataModules returns the number of modules available for data and error correction.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// numDataCodewords returns the number of data codewords for a version and level.
+func numDataCodewords(version int, ec ECLevel) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[ec][version]*numECBlocks[ec][version]
+}
+
+// alignmentPositions returns the row/column centers of alignment patterns.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	}
+	size := version*4 + 17
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// formatInfo returns the 15 bit BCH coded format information.
+func formatInfo(ec ECLevel, mask int) int {
+	data := ec.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionInfo returns the 18 bit BCH coded version information (versions 7 and up).
+func versionInfo(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// charCountBits returns the length of the character count field.
+func charCountBits(mode, version int) int {
+	idx := 0
+	if version >= 27 {
+		idx = 2
+	} else if version >= 10 {
+		idx = 1
+	}
+	switch mode {
+	case modeNumeric:
+		return [...]int{10, 12, 14}[idx]
+	case modeAlphanumeric:
+		return [...]int{9, 11, 13}[idx]
+	default:
+		return [...]int{8, 16, 16}[idx]
+	}
+}
+
+// bitBuffer accumulates bits most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+// segment is an encoded data segment without its character count
+type segment struct {
+	mode  int
+	count int
+	bits  bitBuffer
+}
+
+// makeSegment encodes text in the most compact single mode.
+func makeSegment(text string) segment {
+	isNumeric := text != ""
+	isAlphanumeric := text != ""
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			isNumeric = false
+		}
+		if !strings.ContainsRune(alphanumericChars, r) {
+			isAlphanumeric = false
+		}
+	}
+
+	var seg segment
+	switch {
+	case isNumeric:
+		seg.mode, seg.count = modeNumeric, len(text)
+		for i := 0; i < len(text); i += 3 {
+			chunk := text[i:min(i+3, len(text))]
+			value := 0
+			for _, c := range chunk {
+				value = value*10 + int(c-'0')
+			}
+			seg.bits.append(value, len(chunk)*3+1)
+		}
+	case isAlphanumeric:
+		seg.mode, seg.count = modeAlphanumeric, len(text)
+		for i := 0; i+1 < len(text); i += 2 {
+			seg.bits.append(strings.IndexByte(alphanumericChars, text[i])*45+strings.IndexByte(alphanumericChars, text[i+1]), 11)
+		}
+		if len(text)%2 == 1 {
+			seg.bits.append(strings.IndexByte(alphanumericChars, text[len(text)-1]), 6)
+		}
+	default:
+		seg.mode, seg.count = modeByte, len(text)
+		for i := 0; i < len(text); i++ {
+			seg.bits.append(int(text[i]), 8)
+		}
+	}
+	return seg
+}
+
+// QRCode is a generated symbol. Modules are indexed [row][column]; true is dark.
+type QRCode struct {
+	Version int
+	Level   ECLevel
+	Mask    int
+	Size    int
+	Modules [][]bool
+
+	isFunction [][]bool
+}
+
+// newQRGrid returns an empty symbol with its function patterns drawn.
+func newQRGrid(version int) *QRCode {
+	size := version*4 + 17
+	q := &QRCode{Version: version, Size: size}
+	q.Modules = make([][]bool, size)
+	q.isFunction = make([][]bool, size)
+	for i := range q.Modules {
+		q.Modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+
+	// Timing patterns
+	for i := 0; i < size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with separators
+	for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || x >= size || y < 0 || y >= size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				q.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	// Alignment patterns, skipping those overlapping finder patterns
+	positions := alignmentPositions(version)
+	for i, ay := range positions {
+		for j, ax := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == len(positions)-1) || (i == len(positions)-1 && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format information areas and the dark module
+	q.drawFormat(0)
+
+	// Version information
+	if version >= 7 {
+		bits := versionInfo(version)
+		for i := 0; i < 18; i++ {
+			a, b := size-11+i%3, i/3
+			dark := bits>>i&1 == 1
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+	return q
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// setFunction sets a function module at column x, row y.
+func (q *QRCode) setFunction(x, y int, dark bool) {
+	q.Modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+// formatPositions returns the two module positions (x, y) of each format information bit.
+func formatPositions(size int) [15][2][2]int {
+	var pos [15][2][2]int
+	for i := 0; i < 15; i++ {
+		switch {
+		case i < 6:
+			pos[i][0] = [2]int{8, i}
+		case i < 8:
+			pos[i][0] = [2]int{8, i + 1}
+		case i == 8:
+			pos[i][0] = [2]int{7, 8}
+		default:
+			pos[i][0] = [2]int{14 - i, 8}
+		}
+		if i < 8 {
+			pos[i][1] = [2]int{size - 1 - i, 8}
+		} else {
+			pos[i][1] = [2]int{8, size - 15 + i}
+		}
+	}
+	return pos
+}
+
+// drawFormat draws both copies of the format information and the dark module.
+func (q *QRCode) drawFormat(bits int) {
+	for i, copies := range formatPositions(q.Size) {
+		for _, p := range copies {
+			q.setFunction(p[0], p[1], bits>>i&1 == 1)
+		}
+	}
+	q.setFunction(8, q.Size-8, true)
+}
+
+// dataPositions returns the non-function module positions (x, y) in placement order.
+func (q *QRCode) dataPositions() [][2]int {
+	var positions [][2]int
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.Size; vert++ {
+			y := vert
+			if upward {
+				y = q.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !q.isFunction[y][x] {
+					positions = append(positions, [2]int{x, y})
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// maskBit reports whether mask pattern m inverts the module at column x, row y.
+func maskBit(m, x, y int) bool {
+	switch m {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern.
+func (q *QRCode) applyMask(m int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.isFunction[y][x] && maskBit(m, x, y) {
+				q.Modules[y][x] = !q.Modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores a masked symbol; the mask with the lowest score is used.
+func (q *QRCode) penalty() int {
+	score := 0
+	size := q.Size
+	get := func(x, y int, transpose bool) bool {
+		if transpose {
+			return q.Modules[x][y]
+		}
+		return q.Modules[y][x]
+	}
+
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < size; y++ {
+			// Runs of five or more same colored modules
+			run := 1
+			for x := 1; x <= size; x++ {
+				if x < size && get(x, y, transpose) == get(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// Finder-like patterns 1:1:3:1:1 preceded or followed by four light modules
+			for x := 0; x+7 <= size; x++ {
+				pattern := [7]bool{true, false, true, true, true, false, true}
+				match := true
+				for k := 0; k < 7 && match; k++ {
+					match = get(x+k, y, transpose) == pattern[k]
+				}
+				if !match {
+					continue
+				}
+				lightBefore, lightAfter := x >= 4, x+11 <= size
+				for k := 1; k <= 4; k++ {
+					lightBefore = lightBefore && !get(x-k, y, transpose)
+					lightAfter = lightAfter && !get(x+6+k, y, transpose)
+				}
+				if lightBefore || lightAfter {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if q.Modules[y][x] {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				c := q.Modules[y][x]
+				if c == q.Modules[y][x+1] && c == q.Modules[y+1][x] && c == q.Modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	percent := dark * 100 / (size * size)
+	score += abs(percent-50) / 5 * 10
+	return score
+}
+
+// blockLayout returns the number of short blocks, the short block length (data plus error
+// correction) and the error correction length per block.
+func blockLayout(version int, ec ECLevel) (numBlocks, numShort, shortLen, eccLen int) {
+	numBlocks = numECBlocks[ec][version]
+	eccLen = eccCodewordsPerBlock[ec][version]
+	rawCodewords := numRawDataModules(version) / 8
+	return numBlocks, numBlocks - rawCodewords%numBlocks, rawCodewords / numBlocks, eccLen
+}
+
+// interleave splits data into blocks, appends error correction and interleaves the codewords.
+func interleave(data []byte, version int, ec ECLevel) []byte {
+	numBlocks, numShort, shortLen, eccLen := blockLayout(version, ec)
+
+	// Short blocks get a placeholder so all blocks have the same length
+	var blocks [][]byte
+	k := 0
+	for i := 0; i < numBlocks; i++ {
+		dataLen := shortLen - eccLen
+		if i >= numShort {
+			dataLen++
+		}
+		block := append([]byte(nil), data[k:k+dataLen]...)
+		k += dataLen
+		ecc := rsEncode(block, eccLen)
+		if i < numShort {
+			block = append(block, 0)
+		}
+		blocks = append(blocks, append(block, ecc...))
+	}
+
+	var result []byte
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// encodeData chooses the smallest version from minVersion that fits text and returns the
+// padded data codewords.
+func encodeData(text string, ec ECLevel, minVersion int) (int, []byte, error) {
+	seg := makeSegment(text)
+
+	version := max(minVersion, 1)
+	for ; ; version++ {
+		if version > 40 {
+			return 0, nil, errors.New("data too long for a QR code at this error correction level")
+		}
+		dataBits := 4 + charCountBits(seg.mode, version) + len(seg.bits)
+		if seg.count < 1<<charCountBits(seg.mode, version) && dataBits <= numDataCodewords(version, ec)*8 {
+			break
+		}
+	}
+
+	capacity := numDataCodewords(version, ec) * 8
+	var bits bitBuffer
+	bits.append(seg.mode, 4)
+	bits.append(seg.count, charCountBits(seg.mode, version))
+	bits = append(bits, seg.bits...)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	data := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return version, data, nil
+}
+
+// EncodeQR builds a QR code for text. A version of 0 selects the smallest that fits; a mask
+// of -1 selects the mask with the lowest penalty.
+func EncodeQR(text string, ec ECLevel, minVersion, mask int) (*QRCode, error) {
+	if mask > 7 {
+		return nil, fmt.Errorf("invalid mask %d", mask)
+	}
+	version, data, err := encodeData(text, ec, minVersion)
+	if err != nil {
+		return nil, err
+	}
+	codewords := interleave(data, version, ec)
+
+	q := newQRGrid(version)
+	q.Level = ec
+	for i, p := range q.dataPositions() {
+		if i < len(codewords)*8 {
+			q.Modules[p[1]][p[0]] = codewords[i/8]>>(7-i%8)&1 == 1
+		}
+	}
+
+	if mask < 0 {
+		best := -1
+		for m := 0; m < 8; m++ {
+			q.applyMask(m)
+			q.drawFormat(formatInfo(ec, m))
+			if p := q.penalty(); best < 0 || p < best {
+				best, mask = p, m
+			}
+			q.applyMask(m)
+		}
+	}
+	q.applyMask(mask)
+	q.drawFormat(formatInfo(ec, mask))
+	q.Mask = mask
+	return q, nil
+}
@@ -0,0 +1,539 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// QR code reading for upright or rotated symbols without perspective distortion, such as
+// screenshots and generated images. The symbol is located by its three finder patterns and
+// sampled on an affine grid.
+
+// DecodedQR is the result of reading a symbol
+type DecodedQR struct {
+	Text      string
+	Version   int
+	Level     ECLevel
+	Mask      int
+	Corrected int
+}
+
+// binarize converts an image to dark/light modules using Otsu's threshold on luminance.
+// Transparent pixels are treated as light.
+func binarize(img image.Image) [][]bool {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	lum := make([]uint8, w*h)
+	var histogram [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			// Composite over white
+			white := 0xffff - a
+			gray := color.Gray16Model.Convert(color.RGBA64{uint16(r + white), uint16(g + white), uint16(bl + white), 0xffff}).(color.Gray16)
+			l := uint8(gray.Y >> 8)
+			lum[y*w+x] = l
+			histogram[l]++
+		}
+	}
+
+	total := w * h
+	sum := 0
+	for i, c := range histogram {
+		sum += i * c
+	}
+	var sumB, weightB int
+	var bestVar float64
+	threshold := 128
+	for t := 0; t < 256; t++ {
+		weightB += histogram[t]
+		if weightB == 0 {
+			continue
+		}
+		weightF := total - weightB
+		if weightF == 0 {
+			break
+		}
+		sumB += t * histogram[t]
+		meanB := float64(sumB) / float64(weightB)
+		meanF := float64(sum-sumB) / float64(weightF)
+		between := float64(weightB) * float64(weightF) * (meanB - meanF) * (meanB - meanF)
+		if between > bestVar {
+			bestVar, threshold = between, t
+		}
+	}
+
+	grid := make([][]bool, h)
+	for y := range grid {
+		grid[y] = make([]bool, w)
+		for x := range grid[y] {
+			grid[y][x] = lum[y*w+x] <= uint8(threshold)
+		}
+	}
+	return grid
+}
+
+// finderCandidate is a possible finder pattern center
+type finderCandidate struct {
+	x, y, module float64
+	count        int
+}
+
+// finderRatio reports whether run lengths match the 1:1:3:1:1 finder pattern.
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, c := range counts {
+		if c == 0 {
+			return false
+		}
+		total += c
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	tolerance := module / 2
+	return math.Abs(float64(counts[0])-module) < tolerance &&
+		math.Abs(float64(counts[1])-module) < tolerance &&
+		math.Abs(float64(counts[2])-3*module) < 3*tolerance &&
+		math.Abs(float64(counts[3])-module) < tolerance &&
+		math.Abs(float64(counts[4])-module) < tolerance
+}
+
+// crossCheck measures the finder pattern runs through (cx, cy) along direction (dx, dy) and
+// returns the refined center offset along that direction and the pattern's total length.
+func crossCheck(grid [][]bool, cx, cy, dx, dy int) (float64, int, bool) {
+	h, w := len(grid), len(grid[0])
+	inside := func(x, y int) bool { return x >= 0 && y >= 0 && x < w && y < h }
+	if !inside(cx, cy) || !grid[cy][cx] {
+		return 0, 0, false
+	}
+
+	var counts [5]int
+	// Walk backwards through the center, light and outer dark runs
+	x, y := cx, cy
+	for i, dark := range []bool{true, false, true} {
+		for inside(x, y) && grid[y][x] == dark {
+			counts[2-i]++
+			x, y = x-dx, y-dy
+		}
+	}
+	start := counts[0] + counts[1] + counts[2]
+	// Walk forwards
+	x, y = cx+dx, cy+dy
+	for i, dark := range []bool{true, false, true} {
+		for inside(x, y) && grid[y][x] == dark {
+			counts[2+i]++
+			x, y = x+dx, y+dy
+		}
+	}
+	if !finderRatio(counts) {
+		return 0, 0, false
+	}
+	total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+	// Offset of the center from the start of pixel (cx, cy); the pattern starts start-1 pixels back
+	center := float64(-(start - 1)) + float64(counts[0]+counts[1]) + float64(counts[2])/2
+	return center, total, true
+}
+
+// findFinders scans the image for finder pattern centers.
+func findFinders(grid [][]bool) []*finderCandidate {
+	var candidates []*finderCandidate
+
+	add := func(counts [5]int, row, end int) {
+		total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+		cx := float64(end) - float64(counts[4]+counts[3]) - float64(counts[2])/2
+		offY, vTotal, ok := crossCheck(grid, int(cx), row, 0, 1)
+		if !ok || 5*abs(vTotal-total) >= 2*total {
+			return
+		}
+		cy := float64(row) + offY
+		offX, hTotal, ok := crossCheck(grid, int(cx), int(cy), 1, 0)
+		if !ok {
+			return
+		}
+		cx = float64(int(cx)) + offX
+		module := float64(hTotal+vTotal) / 14
+
+		for _, c := range candidates {
+			if math.Abs(c.x-cx) <= c.module*2 && math.Abs(c.y-cy) <= c.module*2 && math.Abs(c.module-module) <= c.module {
+				n := float64(c.count)
+				c.x, c.y, c.module = (c.x*n+cx)/(n+1), (c.y*n+cy)/(n+1), (c.module*n+module)/(n+1)
+				c.count++
+				return
+			}
+		}
+		candidates = append(candidates, &finderCandidate{x: cx, y: cy, module: module, count: 1})
+	}
+
+	for y, row := range grid {
+		var counts [5]int
+		state := 0
+		for x, dark := range row {
+			if dark {
+				if state%2 == 1 {
+					state++
+				}
+				counts[state]++
+				continue
+			}
+			if state%2 == 1 {
+				counts[state]++
+				continue
+			}
+			if state == 4 {
+				if finderRatio(counts) {
+					add(counts, y, x)
+				}
+				counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+				state = 3
+				continue
+			}
+			state++
+			counts[state]++
+		}
+		if state == 4 && finderRatio(counts) {
+			add(counts, y, len(row))
+		}
+	}
+	return candidates
+}
+
+// selectFinders picks the three candidates forming the best right isosceles triangle and
+// returns them as top-left, top-right and bottom-left.
+func selectFinders(candidates []*finderCandidate) ([3]*finderCandidate, error) {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].count > candidates[j].count })
+	if len(candidates) > 10 {
+		candidates = candidates[:10]
+	}
+
+	dist := func(a, b *finderCandidate) float64 { return math.Hypot(a.x-b.x, a.y-b.y) }
+	var best [3]*finderCandidate
+	bestScore := math.Inf(1)
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			for k := j + 1; k < len(candidates); k++ {
+				tri := [3]*finderCandidate{candidates[i], candidates[j], candidates[k]}
+				minModule := math.Min(tri[0].module, math.Min(tri[1].module, tri[2].module))
+				maxModule := math.Max(tri[0].module, math.Max(tri[1].module, tri[2].module))
+				if maxModule > minModule*1.4 {
+					continue
+				}
+
+				// The corner is opposite the longest side
+				corner := 0
+				longest := dist(tri[1], tri[2])
+				for c := 1; c < 3; c++ {
+					if d := dist(tri[(c+1)%3], tri[(c+2)%3]); d > longest {
+						corner, longest = c, d
+					}
+				}
+				tl, p, q := tri[corner], tri[(corner+1)%3], tri[(corner+2)%3]
+				legP, legQ := dist(tl, p), dist(tl, q)
+				if legP < 7*minModule || math.Abs(legP-legQ) > 0.2*math.Max(legP, legQ) {
+					continue
+				}
+				hypotenuseError := math.Abs(longest-math.Hypot(legP, legQ)) / longest
+				if hypotenuseError > 0.1 {
+					continue
+				}
+
+				score := math.Abs(legP-legQ)/legP + hypotenuseError + (maxModule-minModule)/minModule
+				if score < bestScore {
+					// Order so that top-right follows top-left clockwise
+					if (p.x-tl.x)*(q.y-tl.y)-(p.y-tl.y)*(q.x-tl.x) < 0 {
+						p, q = q, p
+					}
+					best, bestScore = [3]*finderCandidate{tl, p, q}, score
+				}
+			}
+		}
+	}
+	if best[0] == nil {
+		return best, errors.New("no QR code found in the image")
+	}
+	return best, nil
+}
+
+// sampler reads modules on an affine grid defined by the finder pattern centers.
+type sampler struct {
+	grid       [][]bool
+	tl, tr, bl *finderCandidate
+	size       int
+}
+
+// at returns whether the module at column x, row y is dark.
+func (s *sampler) at(x, y int) bool {
+	span := float64(s.size - 7)
+	u, v := (float64(x)+0.5-3.5)/span, (float64(y)+0.5-3.5)/span
+	px := s.tl.x + u*(s.tr.x-s.tl.x) + v*(s.bl.x-s.tl.x)
+	py := s.tl.y + u*(s.tr.y-s.tl.y) + v*(s.bl.y-s.tl.y)
+
+	// Majority of the center and four nearby points
+	r := s.tl.module / 4
+	dark := 0
+	for _, d := range [][2]float64{{0, 0}, {-r, -r}, {r, -r}, {-r, r}, {r, r}} {
+		sx, sy := int(math.Floor(px+d[0])), int(math.Floor(py+d[1]))
+		if sy >= 0 && sy < len(s.grid) && sx >= 0 && sx < len(s.grid[sy]) && s.grid[sy][sx] {
+			dark++
+		}
+	}
+	return dark >= 3
+}
+
+// readFormat returns the level and mask with the closest format information.
+func (s *sampler) readFormat() (ECLevel, int, error) {
+	var copies [2]int
+	for i, positions := range formatPositions(s.size) {
+		for c, p := range positions {
+			if s.at(p[0], p[1]) {
+				copies[c] |= 1 << i
+			}
+		}
+	}
+
+	bestDistance, bestLevel, bestMask := 16, ECLow, 0
+	for level := ECLow; level <= ECHigh; level++ {
+		for mask := 0; mask < 8; mask++ {
+			expected := formatInfo(level, mask)
+			for _, c := range copies {
+				if d := bits.OnesCount(uint(c ^ expected)); d < bestDistance {
+					bestDistance, bestLevel, bestMask = d, level, mask
+				}
+			}
+		}
+	}
+	if bestDistance > 3 {
+		return 0, 0, errors.New("unreadable format information")
+	}
+	return bestLevel, bestMask, nil
+}
+
+// readVersion returns the version encoded in the version information, or 0 if unreadable.
+func (s *sampler) readVersion() int {
+	var copies [2]int
+	for i := 0; i < 18; i++ {
+		a, b := s.size-11+i%3, i/3
+		if s.at(a, b) {
+			copies[0] |= 1 << i
+		}
+		if s.at(b, a) {
+			copies[1] |= 1 << i
+		}
+	}
+	bestDistance, bestVersion := 4, 0
+	for v := 7; v <= 40; v++ {
+		for _, c := range copies {
+			if d := bits.OnesCount(uint(c ^ versionInfo(v))); d < bestDistance {
+				bestDistance, bestVersion = d, v
+			}
+		}
+	}
+	return bestVersion
+}
+
+// bitReader reads big-endian bit fields from a byte slice
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) remaining() int { return len(r.data)*8 - r.pos }
+
+func (r *bitReader) read(n int) (int, error) {
+	if n > r.remaining() {
+		return 0, errors.New("unexpected end of data")
+	}
+	value := 0
+	for i := 0; i < n; i++ {
+		value = value<<1 | int(r.data[r.pos/8]>>(7-r.pos%8)&1)
+		r.pos++
+	}
+	return value, nil
+}
+
+// parseSegments decodes the data codewords into text.
+func parseSegments(data []byte, version int) (string, error) {
+	r := &bitReader{data: data}
+	var out strings.Builder
+	for r.remaining() >= 4 {
+		mode, _ := r.read(4)
+		switch mode {
+		case 0:
+			return out.String(), nil
+		case modeECI:
+			// Skip the assignment number; byte segments are returned as UTF-8 either way
+			first, err := r.read(8)
+			if err != nil {
+				return "", err
+			}
+			extra := 0
+			if first&0x80 != 0 {
+				extra = 8
+				if first&0x40 != 0 {
+					extra = 16
+				}
+			}
+			if _, err := r.read(extra); err != nil {
+				return "", err
+			}
+			continue
+		case modeNumeric, modeAlphanumeric, modeByte:
+		case modeKanji:
+			return "", errors.New("Kanji mode segments are not supported")
+		default:
+			return "", fmt.Errorf("unsupported segment mode %d", mode)
+		}
+
+		count, err := r.read(charCountBits(mode, version))
+		if err != nil {
+			return "", err
+		}
+		switch mode {
+		case modeNumeric:
+			for count > 0 {
+				n := min(count, 3)
+				value, err := r.read(n*3 + 1)
+				if err != nil {
+					return "", err
+				}
+				out.WriteString(fmt.Sprintf("%0*d", n, value))
+				count -= n
+			}
+		case modeAlphanumeric:
+			for ; count >= 2; count -= 2 {
+				value, err := r.read(11)
+				if err != nil || value/45 >= 45 {
+					return "", errors.New("invalid alphanumeric data")
+				}
+				out.WriteByte(alphanumericChars[value/45])
+				out.WriteByte(alphanumericChars[value%45])
+			}
+			if count == 1 {
+				value, err := r.read(6)
+				if err != nil || value >= 45 {
+					return "", errors.New("invalid alphanumeric data")
+				}
+				out.WriteByte(alphanumericChars[value])
+			}
+		case modeByte:
+			for ; count > 0; count-- {
+				value, err := r.read(8)
+				if err != nil {
+					return "", err
+				}
+				out.WriteByte(byte(value))
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// decodeGrid reads, error corrects and parses a symbol of the given version.
+func (s *sampler) decodeGrid(version int) (*DecodedQR, error) {
+	level, mask, err := s.readFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	q := newQRGrid(version)
+	positions := q.dataPositions()
+	rawCodewords := numRawDataModules(version) / 8
+	codewords := make([]byte, rawCodewords)
+	for i, p := range positions[:rawCodewords*8] {
+		if s.at(p[0], p[1]) != maskBit(mask, p[0], p[1]) {
+			codewords[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	// Undo the interleaving
+	numBlocks, numShort, shortLen, eccLen := blockLayout(version, level)
+	blocks := make([][]byte, numBlocks)
+	for j := range blocks {
+		blocks[j] = make([]byte, shortLen+1)
+	}
+	k := 0
+	for i := 0; i <= shortLen; i++ {
+		for j := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				blocks[j][i] = codewords[k]
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	corrected := 0
+	for j, block := range blocks {
+		if j < numShort {
+			// Drop the placeholder
+			block = append(block[:shortLen-eccLen], block[shortLen-eccLen+1:]...)
+		}
+		n, err := rsDecode(block, eccLen)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", j+1, err)
+		}
+		corrected += n
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	text, err := parseSegments(data, version)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedQR{Text: text, Version: version, Level: level, Mask: mask, Corrected: corrected}, nil
+}
+
+// DecodeQR locates and reads a QR code in an image.
+func DecodeQR(img image.Image) (*DecodedQR, error) {
+	grid := binarize(img)
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil, errors.New("empty image")
+	}
+	finders, err := selectFinders(findFinders(grid))
+	if err != nil {
+		return nil, err
+	}
+	tl, tr, bl := finders[0], finders[1], finders[2]
+
+	// Estimate the version from the finder spacing, which spans size-7 modules
+	module := (tl.module + tr.module + bl.module) / 3
+	spacing := (math.Hypot(tr.x-tl.x, tr.y-tl.y) + math.Hypot(bl.x-tl.x, bl.y-tl.y)) / 2
+	estimate := int(math.Round((spacing/module + 7 - 17) / 4))
+
+	var lastErr error
+	tried := map[int]bool{}
+	for _, delta := range []int{0, 1, -1, 2, -2} {
+		version := estimate + delta
+		if version < 1 || version > 40 || tried[version] {
+			continue
+		}
+		s := &sampler{grid: grid, tl: tl, tr: tr, bl: bl, size: version*4 + 17}
+		if version >= 7 {
+			// Trust readable version information over the estimate
+			if v := s.readVersion(); v != 0 && v != version {
+				version = v
+				s.size = v*4 + 17
+			}
+		}
+		if tried[version] {
+			continue
+		}
+		tried[version] = true
+		result, err := s.decodeGrid(version)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("could not determine the QR code version")
+	}
+	return nil, fmt.Errorf("failed to decode QR code: %w", lastErr)
+}
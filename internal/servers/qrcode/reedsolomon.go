@@ -0,0 +1,188 @@
+package qrcode
+
+import "errors"
+
+// Reed-Solomon coding over GF(256) with the QR code polynomial x^8+x^4+x^3+x^2+1.
+// Codewords are stored highest degree first, as they appear in the symbol.
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+255-int(gfLog[b]))%255]
+}
+
+// gfPow returns alpha^n for any integer n.
+func gfPow(n int) byte {
+	n %= 255
+	if n < 0 {
+		n += 255
+	}
+	return gfExp[n]
+}
+
+// rsGenerator returns the generator polynomial (x-a^0)(x-a^1)...(x-a^(n-1)), highest degree first.
+func rsGenerator(n int) []byte {
+	gen := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(gen)+1)
+		for j, c := range gen {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfPow(i))
+		}
+		gen = next
+	}
+	return gen
+}
+
+// rsEncode returns the n error correction codewords for data.
+func rsEncode(data []byte, n int) []byte {
+	gen := rsGenerator(n)
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := 0; j < n; j++ {
+			rem[j] ^= gfMul(gen[j+1], factor)
+		}
+	}
+	return rem
+}
+
+// rsDecode corrects errors in block (data followed by nsym error correction codewords) in
+// place and returns the number of corrected codewords.
+func rsDecode(block []byte, nsym int) (int, error) {
+	// Syndromes S_j = c(a^j)
+	syndromes := make([]byte, nsym)
+	clean := true
+	for j := range syndromes {
+		var s byte
+		x := gfPow(j)
+		for _, c := range block {
+			s = gfMul(s, x) ^ c
+		}
+		syndromes[j] = s
+		if s != 0 {
+			clean = false
+		}
+	}
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp-Massey: error locator Lambda(x), lowest degree first
+	lambda := []byte{1}
+	prev := []byte{1}
+	l, m := 0, 1
+	b := byte(1)
+	for n := 0; n < nsym; n++ {
+		d := syndromes[n]
+		for i := 1; i <= l && i < len(lambda); i++ {
+			d ^= gfMul(lambda[i], syndromes[n-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		coef := gfDiv(d, b)
+		next := make([]byte, max(len(lambda), len(prev)+m))
+		copy(next, lambda)
+		for i, p := range prev {
+			next[i+m] ^= gfMul(coef, p)
+		}
+		if 2*l <= n {
+			prev = lambda
+			l = n + 1 - l
+			b = d
+			m = 1
+		} else {
+			m++
+		}
+		lambda = next
+	}
+	if 2*l > nsym {
+		return 0, errors.New("too many errors to correct")
+	}
+
+	// Chien search: the codeword at index i has locator X = a^(len-1-i)
+	evalLow := func(poly []byte, x byte) byte {
+		var y byte
+		for i := len(poly) - 1; i >= 0; i-- {
+			y = gfMul(y, x) ^ poly[i]
+		}
+		return y
+	}
+	var positions []int
+	for i := range block {
+		if evalLow(lambda, gfPow(-(len(block)-1-i))) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != l {
+		return 0, errors.New("too many errors to correct")
+	}
+
+	// Forney: Omega(x) = S(x) Lambda(x) mod x^nsym, e = X Omega(X^-1) / Lambda'(X^-1)
+	omega := make([]byte, nsym)
+	for i, s := range syndromes {
+		for j, c := range lambda {
+			if i+j < nsym {
+				omega[i+j] ^= gfMul(s, c)
+			}
+		}
+	}
+	derivative := make([]byte, len(lambda))
+	for i := 1; i < len(lambda); i += 2 {
+		derivative[i-1] = lambda[i]
+	}
+	for _, i := range positions {
+		x := gfPow(len(block) - 1 - i)
+		xInv := gfPow(-(len(block) - 1 - i))
+		denominator := evalLow(derivative, xInv)
+		if denominator == 0 {
+			return 0, errors.New("failed to compute error magnitude")
+		}
+		block[i] ^= gfMul(x, gfDiv(evalLow(omega, xInv), denominator))
+	}
+
+	// Verify the corrected block
+	for j := 0; j < nsym; j++ {
+		var s byte
+		x := gfPow(j)
+		for _, c := range block {
+			s = gfMul(s, x) ^ c
+		}
+		if s != 0 {
+			return 0, errors.New("error correction failed")
+		}
+	}
+	return len(positions), nil
+}
@@ -0,0 +1,310 @@
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// QRCodeServer is an MCP server that generates and reads QR codes.
+type QRCodeServer struct {
+	server       *server.MCPServer
+	maxImageSize int
+	maxPixels    int
+}
+
+// NewQRCodeServer creates a new QRCodeServer instance
+func NewQRCodeServer(maxImageSize, maxPixels int) *QRCodeServer {
+	log.Printf("QRCodeServer created: maxImageSize=%d, maxPixels=%d", maxImageSize, maxPixels)
+
+	s := &QRCodeServer{
+		maxImageSize: maxImageSize,
+		maxPixels:    maxPixels,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"qrcode-server", // server name
+		"1.0.0",         // version
+	)
+
+	// Register generateQRCode tool
+	generateTool := mcp.NewTool("generateQRCode",
+		mcp.WithDescription("Generates a QR code PNG image for text or a URL"),
+		mcp.WithString("text",
+			mcp.Description("Content to encode"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("size",
+			mcp.Description("Approximate image width and height in pixels (default: 256)"),
+		),
+		mcp.WithString("errorCorrection",
+			mcp.Description("Error correction level: L (7%), M (15%), Q (25%), H (30%) (default: M)"),
+			mcp.Enum("L", "M", "Q", "H"),
+		),
+		mcp.WithNumber("margin",
+			mcp.Description("Quiet zone width in modules (default: 4)"),
+		),
+	)
+
+	// Register decodeQRCode tool
+	decodeTool := mcp.NewTool("decodeQRCode",
+		mcp.WithDescription("Reads a QR code from a base64 encoded PNG, JPEG or GIF image. Works with screenshots and generated images; skewed photos are not supported"),
+		mcp.WithString("image",
+			mcp.Description("Base64 encoded image data, optionally as a data: URL"),
+			mcp.Required(),
+		),
+	)
+
+	middleware.AddTool(mcpServer, generateTool, s.handleGenerateQRCode)
+	middleware.AddTool(mcpServer, decodeTool, s.handleDecodeQRCode)
+
+	s.server = mcpServer
+	return s
+}
+
+// renderPNG draws a symbol with the given module scale and quiet zone.
+func renderPNG(q *QRCode, scale, margin int) ([]byte, error) {
+	side := (q.Size + 2*margin) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.Modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+margin)*scale+dx, (y+margin)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// handleGenerateQRCode handles the QR code generation request.
+func (s *QRCodeServer) handleGenerateQRCode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generateQRCode request processing")
+
+	args := struct {
+		Text            string `json:"text"`
+		Size            int    `json:"size,omitempty"`
+		ErrorCorrection string `json:"errorCorrection,omitempty"`
+		Margin          *int   `json:"margin,omitempty"`
+	}{}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Text == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "text is required")
+	}
+	if args.ErrorCorrection == "" {
+		args.ErrorCorrection = "M"
+	}
+	level, ok := ecLevelNames[strings.ToUpper(args.ErrorCorrection)]
+	if !ok {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid error correction level: %s (use L, M, Q or H)", args.ErrorCorrection)
+	}
+	margin := 4
+	if args.Margin != nil {
+		margin = max(0, min(*args.Margin, 20))
+	}
+	if args.Size <= 0 {
+		args.Size = 256
+	}
+
+	q, err := EncodeQR(args.Text, level, 1, -1)
+	if err != nil {
+		log.Printf("Error: Failed to encode QR code: %v", err)
+		return nil, err
+	}
+
+	// Use a whole number of pixels per module so the image stays sharp
+	scale := max(1, args.Size/(q.Size+2*margin))
+	side := (q.Size + 2*margin) * scale
+	if side*side > s.maxPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds the maximum of %d pixels", side, side, s.maxPixels)
+	}
+
+	data, err := renderPNG(q, scale, margin)
+	if err != nil {
+		log.Printf("Error: Failed to encode PNG: %v", err)
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.ImageContent{
+				Type:     "image",
+				Data:     base64.StdEncoding.EncodeToString(data),
+				MIMEType: "image/png",
+			},
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("QR code version %d (%dx%d modules), error correction %s, %dx%d pixels",
+					q.Version, q.Size, q.Size, q.Level, side, side),
+			},
+		},
+	}
+
+	log.Printf("generateQRCode request completed: version=%d, level=%s", q.Version, q.Level)
+	return result, nil
+}
+
+// handleDecodeQRCode handles the QR code reading request.
+func (s *QRCodeServer) handleDecodeQRCode(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting decodeQRCode request processing")
+
+	var args struct {
+		Image string `json:"image"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	encoded := strings.TrimSpace(args.Image)
+	if strings.HasPrefix(encoded, "data:") {
+		if _, after, found := strings.Cut(encoded, ","); found {
+			encoded = after
+		}
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > s.maxImageSize {
+		return nil, fmt.Errorf("image exceeds the maximum size of %d bytes", s.maxImageSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid base64 image data: %w", err)
+	}
+
+	// Check dimensions before decoding the full image
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported image format (use PNG, JPEG or GIF): %w", err)
+	}
+	if config.Width*config.Height > s.maxPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds the maximum of %d pixels", config.Width, config.Height, s.maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+
+	decoded, err := DecodeQR(img)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	info := fmt.Sprintf("(QR code version %d, error correction %s", decoded.Version, decoded.Level)
+	if decoded.Corrected > 0 {
+		info += fmt.Sprintf(", %d damaged codewords corrected", decoded.Corrected)
+	}
+	info += ")"
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: decoded.Text + "\n\n" + info,
+			},
+		},
+	}
+
+	log.Printf("decodeQRCode request completed: version=%d", decoded.Version)
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *QRCodeServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the qrcode server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("qrcode", flag.ContinueOnError)
+	var (
+		maxImageSize int
+		maxPixels    int
+	)
+	fs.IntVar(&maxImageSize, "max-image-size", 10*1024*1024, "Maximum decoded image size in bytes (default 10MB)")
+	fs.IntVar(&maxPixels, "max-pixels", 16*1024*1024, "Maximum number of pixels of generated or decoded images")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting QR code server: maxImageSize=%d", maxImageSize)
+
+	// Create QRCodeServer instance
+	qrServer := NewQRCodeServer(maxImageSize, maxPixels)
+	log.Println("QRCodeServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), qrServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return qrServer.Server(), transportFlags, nil
+}
+
+// Run starts the qrcode server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[QRCodeServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create qrcode server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("QRCodeServer shutdown")
+	return nil
+}
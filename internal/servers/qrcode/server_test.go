@@ -0,0 +1,238 @@
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// QRCodeServer creation test
+func TestNewQRCodeServer(t *testing.T) {
+	s := NewQRCodeServer(1024*1024, 4*1024*1024)
+
+	assert.NotNil(t, s, "QRCodeServer instance should be created")
+	assert.Equal(t, 1024*1024, s.maxImageSize, "Max image size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewQRCodeServer(1024*1024, 4*1024*1024)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test symbol tables and BCH codes against values from the specification
+func TestTables(t *testing.T) {
+	assert.Equal(t, 0x77C4, formatInfo(ECLow, 0))
+	assert.Equal(t, 0x5412, formatInfo(ECMedium, 0))
+	assert.Equal(t, 0x355F, formatInfo(ECQuartile, 0))
+	assert.Equal(t, 0x1689, formatInfo(ECHigh, 0))
+	assert.Equal(t, 0x07C94, versionInfo(7))
+	assert.Equal(t, 0x28C69, versionInfo(40))
+
+	assert.Equal(t, 19, numDataCodewords(1, ECLow))
+	assert.Equal(t, 9, numDataCodewords(1, ECHigh))
+	assert.Equal(t, 62, numDataCodewords(5, ECQuartile))
+	assert.Equal(t, 2956, numDataCodewords(40, ECLow))
+	assert.Equal(t, 1276, numDataCodewords(40, ECHigh))
+
+	assert.Nil(t, alignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPositions(32))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPositions(40))
+}
+
+// Test data encoding and error correction with the well known HELLO WORLD 1-M example
+func TestHelloWorld(t *testing.T) {
+	version, data, err := encodeData("HELLO WORLD", ECMedium, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}, data)
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, rsEncode(data, 10))
+
+	version, _, err = encodeData("01234567", ECMedium, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
+
+// Test Reed-Solomon error correction
+func TestReedSolomon(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 40)
+	rng.Read(data)
+	const nsym = 16
+	codeword := append(append([]byte(nil), data...), rsEncode(data, nsym)...)
+
+	for errors := 0; errors <= nsym/2; errors++ {
+		damaged := append([]byte(nil), codeword...)
+		for _, i := range rng.Perm(len(damaged))[:errors] {
+			damaged[i] ^= byte(rng.Intn(255) + 1)
+		}
+		corrected, err := rsDecode(damaged, nsym)
+		assert.NoError(t, err, "%d errors should be correctable", errors)
+		assert.Equal(t, errors, corrected)
+		assert.Equal(t, codeword, damaged)
+	}
+
+	damaged := append([]byte(nil), codeword...)
+	for _, i := range rng.Perm(len(damaged))[:nsym/2+3] {
+		damaged[i] ^= 0x5A
+	}
+	_, err := rsDecode(damaged, nsym)
+	assert.Error(t, err, "Too many errors should be detected")
+}
+
+func renderImage(t *testing.T, q *QRCode, scale, margin int) image.Image {
+	data, err := renderPNG(q, scale, margin)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+// Test that generated symbols can be read back
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		level ECLevel
+	}{
+		{name: "Numeric", text: "3141592653589793238462643383279", level: ECLow},
+		{name: "Alphanumeric", text: "HTTPS://EXAMPLE.COM/A-B", level: ECQuartile},
+		{name: "UTF-8 bytes", text: "héllo wörld ✓", level: ECHigh},
+		{name: "URL with version information", text: "https://example.com/search?q=" + strings.Repeat("mcphost+", 20), level: ECMedium},
+		{name: "Many blocks", text: strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), level: ECHigh},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := EncodeQR(tc.text, tc.level, 1, -1)
+			require.NoError(t, err)
+
+			decoded, err := DecodeQR(renderImage(t, q, 3, 4))
+			require.NoError(t, err)
+			assert.Equal(t, tc.text, decoded.Text)
+			assert.Equal(t, q.Version, decoded.Version)
+			assert.Equal(t, tc.level, decoded.Level)
+			assert.Equal(t, q.Mask, decoded.Mask)
+		})
+	}
+
+	t.Run("Every mask", func(t *testing.T) {
+		for mask := 0; mask < 8; mask++ {
+			q, err := EncodeQR("mask test", ECMedium, 2, mask)
+			require.NoError(t, err)
+			decoded, err := DecodeQR(renderImage(t, q, 4, 4))
+			require.NoError(t, err, "mask %d", mask)
+			assert.Equal(t, "mask test", decoded.Text)
+			assert.Equal(t, mask, decoded.Mask)
+		}
+	})
+}
+
+// Test reading rotated and damaged symbols
+func TestDecodeRobustness(t *testing.T) {
+	q, err := EncodeQR("https://github.com/mark3labs/mcphost", ECHigh, 1, -1)
+	require.NoError(t, err)
+	img := renderImage(t, q, 5, 4)
+
+	t.Run("Rotated 90 degrees", func(t *testing.T) {
+		b := img.Bounds()
+		rotated := image.NewGray(image.Rect(0, 0, b.Dy(), b.Dx()))
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				rotated.Set(b.Dy()-1-y, x, img.At(x, y))
+			}
+		}
+		decoded, err := DecodeQR(rotated)
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/mark3labs/mcphost", decoded.Text)
+	})
+
+	t.Run("Damaged with tinted colors", func(t *testing.T) {
+		damaged := image.NewRGBA(img.Bounds())
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				if gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray); gray.Y < 128 {
+					damaged.Set(x, y, color.RGBA{0, 0, 120, 255})
+				} else {
+					damaged.Set(x, y, color.RGBA{250, 250, 200, 255})
+				}
+			}
+		}
+		// Paint over a block of data modules near the bottom right corner
+		side := (q.Size + 8) * 5
+		for y := side - 9*5; y < side-6*5; y++ {
+			for x := side - 9*5; x < side-6*5; x++ {
+				damaged.Set(x, y, color.RGBA{0, 0, 120, 255})
+			}
+		}
+		decoded, err := DecodeQR(damaged)
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/mark3labs/mcphost", decoded.Text)
+		assert.Greater(t, decoded.Corrected, 0)
+	})
+
+	t.Run("No QR code", func(t *testing.T) {
+		blank := image.NewGray(image.Rect(0, 0, 100, 100))
+		_, err := DecodeQR(blank)
+		assert.Error(t, err)
+	})
+}
+
+// Test tool handlers
+func TestHandlers(t *testing.T) {
+	s := NewQRCodeServer(1024*1024, 4*1024*1024)
+	ctx := context.Background()
+
+	result, err := s.handleGenerateQRCode(ctx, mcptest.NewCallToolRequest("generateQRCode", map[string]interface{}{
+		"text":            "WIFI:S:home;T:WPA;P:secret;;",
+		"size":            300,
+		"errorCorrection": "Q",
+	}))
+	require.NoError(t, err)
+	content := result.Content[0].(mcp.ImageContent)
+	assert.Equal(t, "image/png", content.MIMEType)
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "error correction Q")
+
+	data, err := base64.StdEncoding.DecodeString(content.Data)
+	require.NoError(t, err)
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, config.Width, 300)
+	assert.Greater(t, config.Width, 200)
+
+	result, err = s.handleDecodeQRCode(ctx, mcptest.NewCallToolRequest("decodeQRCode", map[string]interface{}{
+		"image": "data:image/png;base64," + content.Data,
+	}))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Content[0].(mcp.TextContent).Text, "WIFI:S:home;T:WPA;P:secret;;\n\n(QR code version"))
+
+	_, err = s.handleGenerateQRCode(ctx, mcptest.NewCallToolRequest("generateQRCode", map[string]interface{}{
+		"text":            "x",
+		"errorCorrection": "Z",
+	}))
+	assert.Error(t, err)
+
+	_, err = s.handleDecodeQRCode(ctx, mcptest.NewCallToolRequest("decodeQRCode", map[string]interface{}{
+		"image": base64.StdEncoding.EncodeToString([]byte("not an image")),
+	}))
+	assert.Error(t, err)
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewQRCodeServer(1024*1024, 4*1024*1024).Server())
+}
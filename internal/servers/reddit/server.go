@@ -0,0 +1,944 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const (
+	defaultPublicURL = "https://www.reddit.com"
+	defaultOAuthURL  = "https://oauth.reddit.com"
+	defaultUserAgent = "mcphost-reddit/1.0 (by /u/mcphost)"
+)
+
+// Config holds the settings of a RedditServer
+type Config struct {
+	ClientID     string // OAuth client ID; anonymous access is used when empty
+	ClientSecret string
+	Username     string // Account used for the password grant; required for write tools
+	Password     string
+	UserAgent    string // Reddit requires a unique, descriptive User-Agent
+	PublicURL    string // Base URL for anonymous access and token requests
+	OAuthURL     string // Base URL for authenticated API access
+	Timeout      time.Duration
+	MaxBodySize  int64
+	AllowWrite   bool // Register the submitPost and comment tools
+	AllowNSFW    bool // Include posts marked over_18
+}
+
+// Post is the subset of a Reddit link (t3) rendered by the tools
+type Post struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Title       string  `json:"title"`
+	Author      string  `json:"author"`
+	Subreddit   string  `json:"subreddit"`
+	Score       int     `json:"score"`
+	NumComments int     `json:"num_comments"`
+	Created     float64 `json:"created_utc"`
+	URL         string  `json:"url"`
+	Permalink   string  `json:"permalink"`
+	Selftext    string  `json:"selftext"`
+	IsSelf      bool    `json:"is_self"`
+	Over18      bool    `json:"over_18"`
+	Stickied    bool    `json:"stickied"`
+}
+
+// Comment is the subset of a Reddit comment (t1) rendered by the tools
+type Comment struct {
+	ID      string          `json:"id"`
+	Author  string          `json:"author"`
+	Body    string          `json:"body"`
+	Score   int             `json:"score"`
+	Replies json.RawMessage `json:"replies"` // A Listing, or "" when there are no replies
+}
+
+// listing is the generic Reddit Listing envelope
+type listing struct {
+	Kind string `json:"kind"`
+	Data struct {
+		After    string `json:"after"`
+		Children []struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// RedditServer is an MCP server for browsing and optionally posting to Reddit.
+type RedditServer struct {
+	server *server.MCPServer
+	client *http.Client
+	config Config
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewRedditServer creates a new RedditServer instance.
+func NewRedditServer(config Config) *RedditServer {
+	log.Printf("RedditServer created: oauth=%t, allowWrite=%t, allowNSFW=%t", config.ClientID != "", config.AllowWrite, config.AllowNSFW)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     config.Timeout,
+		MaxBodySize: config.MaxBodySize,
+	})
+
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	config.OAuthURL = strings.TrimSuffix(config.OAuthURL, "/")
+	s := &RedditServer{
+		client: client,
+		config: config,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"reddit-server", // server name
+		"1.0.0",         // version
+	)
+
+	// Register listSubreddit tool
+	listTool := mcp.NewTool("listSubreddit",
+		mcp.WithDescription("Lists posts in a subreddit"),
+		mcp.WithString("subreddit",
+			mcp.Description("Subreddit name without the r/ prefix (e.g. 'golang')"),
+			mcp.Required(),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Listing order"),
+			mcp.Enum("hot", "new", "top", "rising"),
+			mcp.DefaultString("hot"),
+		),
+		mcp.WithString("time",
+			mcp.Description("Time window for 'top'"),
+			mcp.Enum("hour", "day", "week", "month", "year", "all"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of posts (1-100, default: 10)"),
+		),
+	)
+
+	// Register searchPosts tool
+	searchTool := mcp.NewTool("searchPosts",
+		mcp.WithDescription("Searches Reddit posts, optionally within one subreddit"),
+		mcp.WithString("query",
+			mcp.Description("Search query"),
+			mcp.Required(),
+		),
+		mcp.WithString("subreddit",
+			mcp.Description("Restrict the search to this subreddit"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Result order"),
+			mcp.Enum("relevance", "new", "top", "comments"),
+			mcp.DefaultString("relevance"),
+		),
+		mcp.WithString("time",
+			mcp.Description("Time window"),
+			mcp.Enum("hour", "day", "week", "month", "year", "all"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of posts (1-100, default: 10)"),
+		),
+	)
+
+	// Register getPost tool
+	postTool := mcp.NewTool("getPost",
+		mcp.WithDescription("Returns a post and its top comments"),
+		mcp.WithString("id",
+			mcp.Description("Post ID (e.g. '1abcde', 't3_1abcde') or permalink URL"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("commentLimit",
+			mcp.Description("Number of top-level comments (1-100, default: 20)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Reply depth to include (1-10, default: 2)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listTool, s.handleListSubreddit)
+	middleware.AddTool(mcpServer, searchTool, s.handleSearchPosts)
+	middleware.AddTool(mcpServer, postTool, s.handleGetPost)
+
+	if config.AllowWrite {
+		// Register submitPost tool
+		submitTool := mcp.NewTool("submitPost",
+			mcp.WithDescription("Submits a new text or link post to a subreddit"),
+			mcp.WithString("subreddit",
+				mcp.Description("Subreddit name without the r/ prefix"),
+				mcp.Required(),
+			),
+			mcp.WithString("title",
+				mcp.Description("Post title"),
+				mcp.Required(),
+			),
+			mcp.WithString("text",
+				mcp.Description("Body for a text post"),
+			),
+			mcp.WithString("url",
+				mcp.Description("URL for a link post. Mutually exclusive with text"),
+			),
+		)
+
+		// Register comment tool
+		commentTool := mcp.NewTool("comment",
+			mcp.WithDescription("Replies to a post or comment"),
+			mcp.WithString("parentId",
+				mcp.Description("Fullname of the post (t3_...) or comment (t1_...) to reply to"),
+				mcp.Required(),
+			),
+			mcp.WithString("text",
+				mcp.Description("Comment body in Markdown"),
+				mcp.Required(),
+			),
+		)
+
+		middleware.AddTool(mcpServer, submitTool, s.handleSubmitPost)
+		middleware.AddTool(mcpServer, commentTool, s.handleComment)
+	}
+
+	s.server = mcpServer
+	return s
+}
+
+// token returns an OAuth access token, or "" for anonymous access.
+func (s *RedditServer) token(ctx context.Context) (string, error) {
+	if s.config.ClientID == "" {
+		return "", nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	// Script apps use the password grant; otherwise fall back to application-only access
+	form := url.Values{}
+	if s.config.Username != "" {
+		form.Set("grant_type", "password")
+		form.Set("username", s.config.Username)
+		form.Set("password", s.config.Password)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+
+	log.Printf("Requesting Reddit access token (grant_type=%s)", form.Get("grant_type"))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.PublicURL+"/api/v1/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	httpReq.SetBasicAuth(s.config.ClientID, s.config.ClientSecret)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("User-Agent", s.config.UserAgent)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Token request failed: %v", err)
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.config.MaxBodySize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil || resp.StatusCode != http.StatusOK || tokenResp.AccessToken == "" {
+		log.Printf("Error: Token request returned status code %d: %s", resp.StatusCode, string(body))
+		if tokenResp.Error != "" {
+			return "", fmt.Errorf("authentication failed: %s", tokenResp.Error)
+		}
+		return "", fmt.Errorf("authentication failed (status code: %d)", resp.StatusCode)
+	}
+
+	s.accessToken = tokenResp.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+// do performs an API request. GET requests against the public site get a ".json" suffix.
+func (s *RedditServer) do(ctx context.Context, method, apiPath string, values url.Values) ([]byte, error) {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if values == nil {
+		values = url.Values{}
+	}
+	// raw_json disables legacy HTML escaping of &, < and > in text fields
+	values.Set("raw_json", "1")
+
+	base := s.config.OAuthURL
+	if accessToken == "" {
+		base = s.config.PublicURL
+		if method == http.MethodGet {
+			apiPath += ".json"
+		}
+	}
+
+	var httpReq *http.Request
+	if method == http.MethodGet {
+		httpReq, err = http.NewRequestWithContext(ctx, method, base+apiPath+"?"+values.Encode(), nil)
+	} else {
+		httpReq, err = http.NewRequestWithContext(ctx, method, base+apiPath, strings.NewReader(values.Encode()))
+		if err == nil {
+			httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", s.config.UserAgent)
+	if accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	log.Printf("Sending %s request to %s", method, apiPath)
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response (with size limitation)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.config.MaxBodySize))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, toolerr.Errorf(toolerr.NotFound, "not found")
+	case http.StatusForbidden:
+		return nil, toolerr.Errorf(toolerr.PermissionDenied, "access denied (private or quarantined subreddit)")
+	case http.StatusTooManyRequests:
+		return nil, fmt.Errorf("rate limited by Reddit, please retry later")
+	}
+	log.Printf("Error: API returned status code %d: %s", resp.StatusCode, string(body))
+	return nil, fmt.Errorf("API error: status code %d", resp.StatusCode)
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_]{2,21}$`)
+
+// validSubreddit checks a subreddit name, accepting an optional r/ prefix.
+func validSubreddit(name string) (string, error) {
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "/"), "r/")
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid subreddit name: %s", name)
+	}
+	return name, nil
+}
+
+// parsePosts decodes the posts of a listing, dropping NSFW posts unless allowed.
+func (s *RedditServer) parsePosts(body []byte) ([]Post, int, error) {
+	var l listing
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	var posts []Post
+	hidden := 0
+	for _, child := range l.Data.Children {
+		if child.Kind != "t3" {
+			continue
+		}
+		var p Post
+		if err := json.Unmarshal(child.Data, &p); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse post: %w", err)
+		}
+		if p.Over18 && !s.config.AllowNSFW {
+			hidden++
+			continue
+		}
+		posts = append(posts, p)
+	}
+	return posts, hidden, nil
+}
+
+// formatPost renders a post summary.
+func formatPost(p Post) string {
+	var sb strings.Builder
+	sb.WriteString(p.Title)
+	if p.Over18 {
+		sb.WriteString(" [NSFW]")
+	}
+	if p.Stickied {
+		sb.WriteString(" [pinned]")
+	}
+	sb.WriteString("\n")
+	if !p.IsSelf && p.URL != "" {
+		sb.WriteString("   Link: " + p.URL + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("   r/%s | %d points | %d comments | by u/%s | %s\n",
+		p.Subreddit, p.Score, p.NumComments, p.Author, time.Unix(int64(p.Created), 0).UTC().Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("   id=%s https://www.reddit.com%s\n", p.ID, p.Permalink))
+	return sb.String()
+}
+
+// formatPostList renders a listing result.
+func formatPostList(header string, posts []Post, hidden int) string {
+	var sb strings.Builder
+	sb.WriteString(header + "\n\n")
+	if len(posts) == 0 {
+		sb.WriteString("No posts found.\n")
+	}
+	for i, p := range posts {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatPost(p)))
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("(%d NSFW posts hidden)\n", hidden))
+	}
+	return sb.String()
+}
+
+// clampLimit keeps a listing size within the API bounds.
+func clampLimit(limit, def int) int {
+	if limit <= 0 {
+		return def
+	}
+	return min(limit, 100)
+}
+
+// handleListSubreddit handles the subreddit listing request.
+func (s *RedditServer) handleListSubreddit(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listSubreddit request processing")
+
+	var args struct {
+		Subreddit string `json:"subreddit"`
+		Sort      string `json:"sort,omitempty"`
+		Time      string `json:"time,omitempty"`
+		Limit     int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	subreddit, err := validSubreddit(args.Subreddit)
+	if err != nil {
+		return nil, err
+	}
+	if args.Sort == "" {
+		args.Sort = "hot"
+	}
+	switch args.Sort {
+	case "hot", "new", "top", "rising":
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported sort: %s", args.Sort)
+	}
+
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(clampLimit(args.Limit, 10)))
+	if args.Time != "" {
+		values.Set("t", args.Time)
+	}
+
+	body, err := s.do(ctx, http.MethodGet, fmt.Sprintf("/r/%s/%s", subreddit, args.Sort), values)
+	if err != nil {
+		return nil, err
+	}
+	posts, hidden, err := s.parsePosts(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: formatPostList(fmt.Sprintf("r/%s (%s)", subreddit, args.Sort), posts, hidden),
+			},
+		},
+	}
+
+	log.Println("listSubreddit request completed")
+	return result, nil
+}
+
+// handleSearchPosts handles the post search request.
+func (s *RedditServer) handleSearchPosts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting searchPosts request processing")
+
+	var args struct {
+		Query     string `json:"query"`
+		Subreddit string `json:"subreddit,omitempty"`
+		Sort      string `json:"sort,omitempty"`
+		Time      string `json:"time,omitempty"`
+		Limit     int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+
+	values := url.Values{}
+	values.Set("q", args.Query)
+	values.Set("limit", strconv.Itoa(clampLimit(args.Limit, 10)))
+	values.Set("type", "link")
+	if args.Sort != "" {
+		values.Set("sort", args.Sort)
+	}
+	if args.Time != "" {
+		values.Set("t", args.Time)
+	}
+	if s.config.AllowNSFW {
+		values.Set("include_over_18", "on")
+	}
+
+	apiPath := "/search"
+	header := fmt.Sprintf("Search results for '%s'", args.Query)
+	if args.Subreddit != "" {
+		subreddit, err := validSubreddit(args.Subreddit)
+		if err != nil {
+			return nil, err
+		}
+		apiPath = "/r/" + subreddit + "/search"
+		values.Set("restrict_sr", "on")
+		header += " in r/" + subreddit
+	}
+
+	body, err := s.do(ctx, http.MethodGet, apiPath, values)
+	if err != nil {
+		return nil, err
+	}
+	posts, hidden, err := s.parsePosts(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: formatPostList(header, posts, hidden),
+			},
+		},
+	}
+
+	log.Println("searchPosts request completed")
+	return result, nil
+}
+
+var permalinkPattern = regexp.MustCompile(`/comments/([a-z0-9]+)`)
+
+// postID extracts a base36 post ID from an ID, fullname or permalink.
+func postID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if m := permalinkPattern.FindStringSubmatch(id); m != nil {
+		return m[1], nil
+	}
+	id = strings.TrimPrefix(id, "t3_")
+	if id == "" || strings.Trim(id, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+		return "", fmt.Errorf("invalid post id: %s", id)
+	}
+	return id, nil
+}
+
+// flattenComments appends comments from a listing in thread order with indentation.
+func flattenComments(sb *strings.Builder, raw json.RawMessage, depth int) int {
+	var l listing
+	if len(raw) == 0 || raw[0] != '{' || json.Unmarshal(raw, &l) != nil {
+		return 0
+	}
+
+	count := 0
+	indent := strings.Repeat("  ", depth)
+	for _, child := range l.Data.Children {
+		if child.Kind != "t1" {
+			continue
+		}
+		var c Comment
+		if err := json.Unmarshal(child.Data, &c); err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s[u/%s, %d points]\n", indent, c.Author, c.Score))
+		for _, line := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+			sb.WriteString(indent + line + "\n")
+		}
+		count++
+		count += flattenComments(sb, c.Replies, depth+1)
+	}
+	return count
+}
+
+// handleGetPost handles the post and comments request.
+func (s *RedditServer) handleGetPost(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getPost request processing")
+
+	var args struct {
+		ID           string `json:"id"`
+		CommentLimit int    `json:"commentLimit,omitempty"`
+		Depth        int    `json:"depth,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	id, err := postID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if args.Depth <= 0 {
+		args.Depth = 2
+	}
+
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(clampLimit(args.CommentLimit, 20)))
+	values.Set("depth", strconv.Itoa(min(args.Depth, 10)))
+	values.Set("sort", "top")
+
+	body, err := s.do(ctx, http.MethodGet, "/comments/"+id, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response is a pair of listings: the post, then its comments
+	var listings []json.RawMessage
+	if err := json.Unmarshal(body, &listings); err != nil || len(listings) < 2 {
+		return nil, fmt.Errorf("unexpected response format")
+	}
+
+	var l listing
+	if err := json.Unmarshal(listings[0], &l); err != nil || len(l.Data.Children) == 0 {
+		return nil, toolerr.Errorf(toolerr.NotFound, "post not found")
+	}
+	var post Post
+	if err := json.Unmarshal(l.Data.Children[0].Data, &post); err != nil {
+		return nil, fmt.Errorf("failed to parse post: %w", err)
+	}
+	if post.Over18 && !s.config.AllowNSFW {
+		log.Printf("Error: NSFW post %s requested while NSFW content is disabled", id)
+		return nil, fmt.Errorf("post %s is marked NSFW and NSFW content is disabled", id)
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(formatPost(post))
+	if post.Selftext != "" {
+		resultContent.WriteString("\n" + post.Selftext + "\n")
+	}
+
+	var comments strings.Builder
+	count := flattenComments(&comments, listings[1], 0)
+	if count > 0 {
+		resultContent.WriteString(fmt.Sprintf("\nTop comments (%d shown):\n", count))
+		resultContent.WriteString(comments.String())
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("getPost request completed")
+	return result, nil
+}
+
+// apiErrors extracts errors from an api_type=json response.
+func apiErrors(body []byte) (json.RawMessage, error) {
+	var resp struct {
+		JSON struct {
+			Errors [][]interface{} `json:"errors"`
+			Data   json.RawMessage `json:"data"`
+		} `json:"json"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	if len(resp.JSON.Errors) > 0 {
+		var messages []string
+		for _, e := range resp.JSON.Errors {
+			parts := make([]string, 0, len(e))
+			for _, part := range e {
+				if str, ok := part.(string); ok && str != "" {
+					parts = append(parts, str)
+				}
+			}
+			messages = append(messages, strings.Join(parts, ": "))
+		}
+		return nil, fmt.Errorf("Reddit rejected the request: %s", strings.Join(messages, "; "))
+	}
+	return resp.JSON.Data, nil
+}
+
+// requireUser checks that write tools run with a user account.
+func (s *RedditServer) requireUser() error {
+	if s.config.ClientID == "" || s.config.Username == "" {
+		return fmt.Errorf("write tools require OAuth client credentials and a Reddit username/password")
+	}
+	return nil
+}
+
+// handleSubmitPost handles the post submission request.
+func (s *RedditServer) handleSubmitPost(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting submitPost request processing")
+
+	var args struct {
+		Subreddit string `json:"subreddit"`
+		Title     string `json:"title"`
+		Text      string `json:"text,omitempty"`
+		URL       string `json:"url,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.requireUser(); err != nil {
+		return nil, err
+	}
+	subreddit, err := validSubreddit(args.Subreddit)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Title) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "title is required")
+	}
+	if args.Text != "" && args.URL != "" {
+		return nil, fmt.Errorf("text and url are mutually exclusive")
+	}
+
+	values := url.Values{}
+	values.Set("api_type", "json")
+	values.Set("sr", subreddit)
+	values.Set("title", args.Title)
+	if args.URL != "" {
+		values.Set("kind", "link")
+		values.Set("url", args.URL)
+	} else {
+		values.Set("kind", "self")
+		values.Set("text", args.Text)
+	}
+
+	body, err := s.do(ctx, http.MethodPost, "/api/submit", values)
+	if err != nil {
+		return nil, err
+	}
+	data, err := apiErrors(body)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	var submitted struct {
+		URL  string `json:"url"`
+		Name string `json:"name"`
+	}
+	json.Unmarshal(data, &submitted)
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Submitted post %s to r/%s: %s", submitted.Name, subreddit, submitted.URL),
+			},
+		},
+	}
+
+	log.Println("submitPost request completed")
+	return result, nil
+}
+
+// handleComment handles the comment request.
+func (s *RedditServer) handleComment(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting comment request processing")
+
+	var args struct {
+		ParentID string `json:"parentId"`
+		Text     string `json:"text"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if err := s.requireUser(); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(args.ParentID, "t1_") && !strings.HasPrefix(args.ParentID, "t3_") {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "parentId must be a post (t3_...) or comment (t1_...) fullname")
+	}
+	if strings.TrimSpace(args.Text) == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "text is required")
+	}
+
+	values := url.Values{}
+	values.Set("api_type", "json")
+	values.Set("thing_id", args.ParentID)
+	values.Set("text", args.Text)
+
+	body, err := s.do(ctx, http.MethodPost, "/api/comment", values)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := apiErrors(body); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Replied to %s", args.ParentID),
+			},
+		},
+	}
+
+	log.Println("comment request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *RedditServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the reddit server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("reddit", flag.ContinueOnError)
+	var (
+		clientID     string
+		clientSecret string
+		username     string
+		password     string
+		userAgent    string
+		publicURL    string
+		oauthURL     string
+		timeout      int
+		maxBodySize  int64
+		allowWrite   bool
+		allowNSFW    bool
+	)
+	fs.StringVar(&clientID, "client-id", "", "Reddit OAuth client ID (anonymous read-only access when empty)")
+	fs.StringVar(&clientSecret, "client-secret", "", "Reddit OAuth client secret")
+	fs.StringVar(&username, "username", "", "Reddit username for the password grant (required for write tools)")
+	fs.StringVar(&password, "password", "", "Reddit password for the password grant")
+	fs.StringVar(&userAgent, "user-agent", defaultUserAgent, "User-Agent sent to Reddit, which requires a unique descriptive value")
+	fs.StringVar(&publicURL, "public-url", defaultPublicURL, "Reddit base URL for anonymous access and token requests")
+	fs.StringVar(&oauthURL, "oauth-url", defaultOAuthURL, "Reddit base URL for authenticated API access")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 5*1024*1024, "Maximum response body size in bytes (default 5MB)")
+	fs.BoolVar(&allowWrite, "allow-write", false, "Enable the submitPost and comment tools")
+	fs.BoolVar(&allowNSFW, "allow-nsfw", false, "Include posts marked NSFW")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("client-id", "REDDIT_CLIENT_ID"),
+		config.WithEnv("client-secret", "REDDIT_CLIENT_SECRET"),
+		config.WithEnv("username", "REDDIT_USERNAME"),
+		config.WithEnv("password", "REDDIT_PASSWORD"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Reddit server: timeout=%ds", timeout)
+	if allowWrite && (clientID == "" || username == "") {
+		log.Printf("Warning: Write tools are enabled but OAuth credentials are incomplete. Submissions will fail.")
+	}
+
+	// Create RedditServer instance
+	redditServer := NewRedditServer(Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Username:     username,
+		Password:     password,
+		UserAgent:    userAgent,
+		PublicURL:    publicURL,
+		OAuthURL:     oauthURL,
+		Timeout:      time.Duration(timeout) * time.Second,
+		MaxBodySize:  maxBodySize,
+		AllowWrite:   allowWrite,
+		AllowNSFW:    allowNSFW,
+	})
+	log.Println("RedditServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), redditServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return redditServer.Server(), transportFlags, nil
+}
+
+// Run starts the reddit server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[RedditServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create reddit server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("RedditServer shutdown")
+	return nil
+}
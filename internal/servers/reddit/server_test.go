@@ -0,0 +1,310 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig(serverURL string) Config {
+	return Config{
+		UserAgent:   "test-agent",
+		PublicURL:   serverURL + "/public",
+		OAuthURL:    serverURL + "/oauth",
+		Timeout:     5 * time.Second,
+		MaxBodySize: 1024 * 1024,
+	}
+}
+
+// listTools returns the names of the tools registered on s.
+func listTools(t *testing.T, s *RedditServer) string {
+	response := s.Server().HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`))
+	data, err := json.Marshal(response)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+// RedditServer creation test
+func TestNewRedditServer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		allowWrite bool
+	}{
+		{name: "Read only", allowWrite: false},
+		{name: "Write enabled", allowWrite: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := newTestConfig("http://localhost")
+			config.AllowWrite = tc.allowWrite
+			rs := NewRedditServer(config)
+
+			assert.NotNil(t, rs, "RedditServer instance should be created")
+			assert.NotNil(t, rs.server, "Internal MCPServer should be initialized")
+
+			tools := listTools(t, rs)
+			assert.Contains(t, tools, "listSubreddit")
+			if tc.allowWrite {
+				assert.Contains(t, tools, "submitPost", "Write tools should be registered")
+			} else {
+				assert.NotContains(t, tools, "submitPost", "Write tools should not be registered")
+			}
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	rs := NewRedditServer(newTestConfig("http://localhost"))
+	assert.NotNil(t, rs.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test identifier and name validation
+func TestValidation(t *testing.T) {
+	id, err := postID("https://www.reddit.com/r/golang/comments/1abcde/some_title/")
+	assert.NoError(t, err)
+	assert.Equal(t, "1abcde", id)
+
+	id, err = postID("t3_1abcde")
+	assert.NoError(t, err)
+	assert.Equal(t, "1abcde", id)
+
+	_, err = postID("../api/me")
+	assert.Error(t, err)
+
+	name, err := validSubreddit("r/golang")
+	assert.NoError(t, err)
+	assert.Equal(t, "golang", name)
+
+	_, err = validSubreddit("golang/../../api")
+	assert.Error(t, err)
+}
+
+const testListing = `{"kind": "Listing", "data": {"children": [
+  {"kind": "t3", "data": {"id": "a1", "name": "t3_a1", "title": "Go 1.24 released", "author": "gopher", "subreddit": "golang", "score": 500, "num_comments": 42, "created_utc": 1700000000, "url": "https://go.dev/blog", "permalink": "/r/golang/comments/a1/go_124/", "is_self": false}},
+  {"kind": "t3", "data": {"id": "a2", "name": "t3_a2", "title": "Spicy post", "author": "x", "subreddit": "golang", "over_18": true, "permalink": "/r/golang/comments/a2/spicy/"}}
+]}}`
+
+const testPost = `[
+  {"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "a1", "title": "Ask: best router?", "author": "gopher", "subreddit": "golang", "score": 10, "is_self": true, "selftext": "Which one & why?", "permalink": "/r/golang/comments/a1/ask/"}}]}},
+  {"kind": "Listing", "data": {"children": [
+    {"kind": "t1", "data": {"id": "c1", "author": "alice", "body": "Use net/http", "score": 30, "replies": {"kind": "Listing", "data": {"children": [
+      {"kind": "t1", "data": {"id": "c2", "author": "bob", "body": "Agreed", "score": 5, "replies": ""}},
+      {"kind": "more", "data": {"count": 3}}
+    ]}}}},
+    {"kind": "t1", "data": {"id": "c3", "author": "carol", "body": "chi", "score": 4, "replies": ""}}
+  ]}}
+]`
+
+// Test anonymous read tools against a mock Reddit
+func TestReadHandlers(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+		assert.Equal(t, "1", r.URL.Query().Get("raw_json"))
+
+		switch r.URL.Path {
+		case "/public/r/golang/hot.json", "/public/r/golang/search.json":
+			w.Write([]byte(testListing))
+		case "/public/comments/a1.json":
+			assert.Equal(t, "top", r.URL.Query().Get("sort"))
+			w.Write([]byte(testPost))
+		case "/public/comments/a2.json":
+			w.Write([]byte(`[{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "a2", "title": "Spicy", "over_18": true}}]}}, {"kind": "Listing", "data": {"children": []}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	rs := NewRedditServer(newTestConfig(mockServer.URL))
+	ctx := context.Background()
+
+	t.Run("List subreddit hides NSFW", func(t *testing.T) {
+		result, err := rs.handleListSubreddit(ctx, mcptest.NewCallToolRequest("listSubreddit", map[string]interface{}{
+			"subreddit": "golang",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "1. Go 1.24 released")
+		assert.Contains(t, text, "Link: https://go.dev/blog")
+		assert.Contains(t, text, "r/golang | 500 points | 42 comments | by u/gopher")
+		assert.NotContains(t, text, "Spicy post")
+		assert.Contains(t, text, "(1 NSFW posts hidden)")
+	})
+
+	t.Run("List subreddit with NSFW allowed", func(t *testing.T) {
+		config := newTestConfig(mockServer.URL)
+		config.AllowNSFW = true
+		nsfw := NewRedditServer(config)
+
+		result, err := nsfw.handleListSubreddit(ctx, mcptest.NewCallToolRequest("listSubreddit", map[string]interface{}{
+			"subreddit": "golang",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Spicy post [NSFW]")
+	})
+
+	t.Run("Search within subreddit", func(t *testing.T) {
+		result, err := rs.handleSearchPosts(ctx, mcptest.NewCallToolRequest("searchPosts", map[string]interface{}{
+			"query":     "release",
+			"subreddit": "golang",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Search results for 'release' in r/golang")
+	})
+
+	t.Run("Get post with comments", func(t *testing.T) {
+		result, err := rs.handleGetPost(ctx, mcptest.NewCallToolRequest("getPost", map[string]interface{}{
+			"id": "t3_a1",
+		}))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Which one & why?")
+		assert.Contains(t, text, "Top comments (3 shown)")
+		assert.Contains(t, text, "\n[u/alice, 30 points]\nUse net/http\n")
+		assert.Contains(t, text, "\n  [u/bob, 5 points]\n  Agreed\n")
+	})
+
+	t.Run("Get NSFW post is refused", func(t *testing.T) {
+		_, err := rs.handleGetPost(ctx, mcptest.NewCallToolRequest("getPost", map[string]interface{}{
+			"id": "a2",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "NSFW")
+	})
+
+	t.Run("Missing subreddit", func(t *testing.T) {
+		_, err := rs.handleListSubreddit(ctx, mcptest.NewCallToolRequest("listSubreddit", map[string]interface{}{
+			"subreddit": "doesnotexist",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+// Test OAuth write tools against a mock Reddit
+func TestWriteHandlers(t *testing.T) {
+	tokenRequests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/public/api/v1/access_token":
+			tokenRequests++
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "id", user)
+			assert.Equal(t, "secret", pass)
+			assert.Equal(t, "password", r.FormValue("grant_type"))
+			w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+			return
+		}
+
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/oauth/api/submit":
+			if r.FormValue("sr") == "locked" {
+				w.Write([]byte(`{"json": {"errors": [["SUBREDDIT_NOTALLOWED", "you aren't allowed to post there.", "sr"]]}}`))
+				return
+			}
+			assert.Equal(t, "self", r.FormValue("kind"))
+			w.Write([]byte(`{"json": {"errors": [], "data": {"url": "https://www.reddit.com/r/test/comments/z9/hello/", "name": "t3_z9"}}}`))
+		case "/oauth/api/comment":
+			assert.Equal(t, "t3_z9", r.FormValue("thing_id"))
+			w.Write([]byte(`{"json": {"errors": [], "data": {"things": []}}}`))
+		case "/oauth/r/golang/hot":
+			w.Write([]byte(testListing))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	config := newTestConfig(mockServer.URL)
+	config.ClientID = "id"
+	config.ClientSecret = "secret"
+	config.Username = "user"
+	config.Password = "pass"
+	config.AllowWrite = true
+	rs := NewRedditServer(config)
+	ctx := context.Background()
+
+	t.Run("Submit text post", func(t *testing.T) {
+		result, err := rs.handleSubmitPost(ctx, mcptest.NewCallToolRequest("submitPost", map[string]interface{}{
+			"subreddit": "test",
+			"title":     "Hello",
+			"text":      "World",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Submitted post t3_z9 to r/test")
+	})
+
+	t.Run("Submit rejected by Reddit", func(t *testing.T) {
+		_, err := rs.handleSubmitPost(ctx, mcptest.NewCallToolRequest("submitPost", map[string]interface{}{
+			"subreddit": "locked",
+			"title":     "Hello",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "SUBREDDIT_NOTALLOWED: you aren't allowed to post there.: sr")
+	})
+
+	t.Run("Comment", func(t *testing.T) {
+		result, err := rs.handleComment(ctx, mcptest.NewCallToolRequest("comment", map[string]interface{}{
+			"parentId": "t3_z9",
+			"text":     "Nice",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Replied to t3_z9")
+	})
+
+	t.Run("Comment with invalid parent", func(t *testing.T) {
+		_, err := rs.handleComment(ctx, mcptest.NewCallToolRequest("comment", map[string]interface{}{
+			"parentId": "z9",
+			"text":     "Nice",
+		}))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Authenticated reads use the OAuth host", func(t *testing.T) {
+		_, err := rs.handleListSubreddit(ctx, mcptest.NewCallToolRequest("listSubreddit", map[string]interface{}{
+			"subreddit": "golang",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tokenRequests, "The access token should be reused")
+	})
+
+	t.Run("Write without user credentials", func(t *testing.T) {
+		anonConfig := newTestConfig(mockServer.URL)
+		anonConfig.AllowWrite = true
+		anon := NewRedditServer(anonConfig)
+
+		_, err := anon.handleSubmitPost(ctx, mcptest.NewCallToolRequest("submitPost", map[string]interface{}{
+			"subreddit": "test",
+			"title":     "Hello",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "require OAuth")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewRedditServer(newTestConfig("http://localhost")).Server())
+}
@@ -0,0 +1,250 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compatIssue describes a construct that behaves differently, or not at all, in RE2
+// compared to PCRE.
+type compatIssue struct {
+	Offset      int
+	Construct   string
+	Unsupported bool
+	Hint        string
+}
+
+func (c compatIssue) String() string {
+	kind := "differs from PCRE"
+	if c.Unsupported {
+		kind = "not supported by RE2"
+	}
+	return fmt.Sprintf("%s at offset %d %s: %s", c.Construct, c.Offset, kind, c.Hint)
+}
+
+var (
+	repetitionPattern = regexp.MustCompile(`^\{\d+(,\d*)?\}`)
+	flagGroupPattern  = regexp.MustCompile(`^\(\?([a-zA-Z^-]+)[:)]`)
+)
+
+// groupPrefixes lists PCRE group syntax that RE2 rejects, checked in order after "(".
+var groupPrefixes = []struct {
+	prefix string
+	name   string
+	hint   string
+}{
+	{"(?<=", "lookbehind (?<=...)", "RE2 has no lookaround; match the context and use a capture group for the part you need"},
+	{"(?<!", "negative lookbehind (?<!...)", "RE2 has no lookaround; filter the matches in a second step instead"},
+	{"(?=", "lookahead (?=...)", "RE2 has no lookaround; match the context and use a capture group for the part you need"},
+	{"(?!", "negative lookahead (?!...)", "RE2 has no lookaround; filter the matches in a second step instead"},
+	{"(?>", "atomic group (?>...)", "RE2 never backtracks, so a plain group (?:...) gives the same matches"},
+	{"(?|", "branch reset group (?|...)", "use separate capture groups for each alternative"},
+	{"(?#", "comment group (?#...)", "remove the comment"},
+	{"(?(", "conditional (?(...)...)", "split the pattern into alternatives"},
+	{"(?R)", "recursion (?R)", "RE2 cannot match nested structures; use a parser"},
+	{"(?&", "subroutine call (?&name)", "repeat the referenced subpattern"},
+	{"(?P>", "subroutine call (?P>name)", "repeat the referenced subpattern"},
+	{"(?P=", "named backreference (?P=name)", "RE2 has no backreferences; compare the captured groups in code"},
+	{"(?'", "named group (?'name'...)", "use (?P<name>...) or (?<name>...)"},
+	{"(*", "backtracking verb (*...)", "RE2 does not support backtracking control verbs"},
+}
+
+// escapeIssues lists PCRE escapes that RE2 rejects or interprets differently.
+var escapeIssues = map[byte]compatIssue{
+	'G': {Construct: `\G`, Unsupported: true, Hint: "match positions are independent in RE2; anchor with ^ and slice the text instead"},
+	'Z': {Construct: `\Z`, Unsupported: true, Hint: `use \n?\z, or $ without the m flag`},
+	'K': {Construct: `\K`, Unsupported: true, Hint: "capture the part after the prefix in a group instead"},
+	'R': {Construct: `\R`, Unsupported: true, Hint: `use (?:\r\n|\n|\r)`},
+	'X': {Construct: `\X`, Unsupported: true, Hint: `RE2 has no grapheme clusters; \P{M}\p{M}* is a close approximation`},
+	'h': {Construct: `\h`, Unsupported: true, Hint: `use [\t\p{Zs}]`},
+	'H': {Construct: `\H`, Unsupported: true, Hint: `use [^\t\p{Zs}]`},
+	'N': {Construct: `\N`, Unsupported: true, Hint: "use . without the s flag"},
+	'v': {Construct: `\v`, Hint: `RE2 treats \v as the vertical tab character only, not as any vertical whitespace; use [\n\v\f\r\x{85}\x{2028}\x{2029}]`},
+}
+
+// findCompatIssues scans pattern for PCRE constructs. It is a best effort lexical scan
+// used to explain compile errors and warn about behavior differences.
+func findCompatIssues(pattern string, flags string) []compatIssue {
+	var issues []compatIssue
+	add := func(offset int, construct string, unsupported bool, hint string) {
+		issues = append(issues, compatIssue{Offset: offset, Construct: construct, Unsupported: unsupported, Hint: hint})
+	}
+
+	inClass := false
+	quantified := false // the previous token was a quantifier
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		wasQuantified := quantified
+		quantified = false
+
+		if c == '\\' {
+			if i+1 >= len(pattern) {
+				break
+			}
+			next := pattern[i+1]
+			if next == 'Q' {
+				// Skip quoted text up to \E
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					break
+				}
+				i += end + 3
+				continue
+			}
+			if !inClass {
+				switch {
+				case next >= '1' && next <= '9':
+					add(i, pattern[i:i+2], true, "RE2 has no backreferences; compare the captured groups in code")
+				case next == 'k' || next == 'g':
+					add(i, `\`+string(next)+" backreference", true, "RE2 has no backreferences; compare the captured groups in code")
+				}
+			}
+			if issue, ok := escapeIssues[next]; ok {
+				issue.Offset = i
+				issues = append(issues, issue)
+			}
+			i++
+			continue
+		}
+
+		if inClass {
+			if c == '[' && strings.HasPrefix(pattern[i:], "[:") {
+				// POSIX class such as [:alpha:]
+				if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+					i += end + 3
+				}
+			} else if c == ']' {
+				inClass = false
+			}
+			continue
+		}
+
+		switch c {
+		case '[':
+			inClass = true
+			// A leading ] (after an optional ^) is a literal
+			if strings.HasPrefix(pattern[i+1:], "^]") {
+				i += 2
+			} else if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case '(':
+			for _, g := range groupPrefixes {
+				if strings.HasPrefix(pattern[i:], g.prefix) {
+					add(i, g.name, true, g.hint)
+					break
+				}
+			}
+			if len(pattern) > i+2 && pattern[i+1] == '?' && (pattern[i+2] >= '0' && pattern[i+2] <= '9' || pattern[i+2] == '+') {
+				add(i, "subroutine call (?N)", true, "repeat the referenced subpattern")
+			}
+			if m := flagGroupPattern.FindStringSubmatch(pattern[i:]); m != nil {
+				if bad := strings.Trim(m[1], "imsU-"); bad != "" {
+					add(i, "inline flags "+bad, true, "RE2 only supports the i, m, s and U flags")
+				}
+				flags += m[1]
+			}
+			// Keep the ? or * of group syntax from being read as a quantifier
+			if i+1 < len(pattern) && (pattern[i+1] == '?' || pattern[i+1] == '*') {
+				i++
+			}
+		case '*', '+', '?':
+			if c == '+' && wasQuantified {
+				add(i-1, "possessive quantifier", true, "RE2 never backtracks, so the plain quantifier gives the same matches")
+			} else if c != '?' || !wasQuantified {
+				quantified = true
+			}
+		case '{':
+			if m := repetitionPattern.FindString(pattern[i:]); m != "" {
+				i += len(m) - 1
+				quantified = true
+			}
+		case '$':
+			if !strings.Contains(flags, "m") {
+				add(i, "$", false, `without the m flag RE2 matches $ only at the very end of the text, while PCRE also matches before a final newline; use \n?$ or the m flag`)
+			}
+		}
+	}
+	return issues
+}
+
+// translateReplacement converts a PCRE or JavaScript style replacement template, which
+// may use \1, $1 and $<name>, to the RE2 template syntax using ${1} and ${name}.
+func translateReplacement(replacement string) string {
+	var b strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if i+1 >= len(replacement) || (c != '\\' && c != '$') {
+			b.WriteByte(c)
+			continue
+		}
+		next := replacement[i+1]
+		switch {
+		case c == '\\' && next == '$':
+			b.WriteString("$$")
+			i++
+		case c == '\\' && next == '\\':
+			b.WriteByte('\\')
+			i++
+		case next >= '0' && next <= '9':
+			j := i + 1
+			for j < len(replacement) && j < i+3 && replacement[j] >= '0' && replacement[j] <= '9' {
+				j++
+			}
+			b.WriteString("${" + replacement[i+1:j] + "}")
+			i = j - 1
+		case c == '$' && next == '<':
+			if end := strings.IndexByte(replacement[i:], '>'); end > 0 {
+				b.WriteString("${" + replacement[i+2:i+end] + "}")
+				i += end
+			} else {
+				b.WriteString("$$")
+			}
+		case c == '$' && (next == '$' || next == '{'):
+			b.WriteByte('$')
+			b.WriteByte(next)
+			i++
+		case c == '$':
+			// A lone $ is literal in PCRE
+			b.WriteString("$$")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+var (
+	backslashReference = regexp.MustCompile(`\\\d`)
+	ambiguousReference = regexp.MustCompile(`\$(\d+)([a-zA-Z_]\w*)`)
+	numberedReference  = regexp.MustCompile(`\$\{?(\d+)`)
+	namedReference     = regexp.MustCompile(`\$\{?([a-zA-Z_]\w*)`)
+)
+
+// replacementWarnings reports RE2 template pitfalls in replacement.
+func replacementWarnings(re *regexp.Regexp, replacement string) []string {
+	var warnings []string
+	// $$ is a literal dollar sign and never starts a reference
+	replacement = strings.ReplaceAll(replacement, "$$", "")
+
+	if backslashReference.MatchString(replacement) {
+		warnings = append(warnings, `the replacement contains \N, which RE2 inserts literally; use ${N} or the pcre mode`)
+	}
+	for _, m := range ambiguousReference.FindAllStringSubmatch(replacement, -1) {
+		warnings = append(warnings, fmt.Sprintf("%s refers to a group named %q, not group %s followed by %q; use ${%s}%s",
+			m[0], m[1]+m[2], m[1], m[2], m[1], m[2]))
+	}
+	for _, m := range numberedReference.FindAllStringSubmatch(replacement, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > re.NumSubexp() {
+			warnings = append(warnings, fmt.Sprintf("the pattern has no group %d, so %s is replaced with an empty string", n, m[0]))
+		}
+	}
+	for _, m := range namedReference.FindAllStringSubmatch(replacement, -1) {
+		if re.SubexpIndex(m[1]) < 0 {
+			warnings = append(warnings, fmt.Sprintf("the pattern has no group named %q, so %s is replaced with an empty string", m[1], m[0]))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,234 @@
+package regex
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxClassRanges limits how many ranges of a character class are listed.
+const maxClassRanges = 12
+
+// namedClasses maps the ranges of the Perl and common classes to their names. The
+// parser expands classes such as \d to plain ranges, so they are recognized by content.
+var namedClasses = map[string]string{
+	"0-9":                   `digit (\d)`,
+	"0-9,A-Z,_,a-z":         `word character (\w)`,
+	"\t-\n,\f-\r, ":         `whitespace (\s)`,
+	"A-Z,a-z":               "ASCII letter",
+	"0-9,A-Z,a-z":           "ASCII letter or digit",
+	"0-9,A-F,a-f":           "hexadecimal digit",
+	"\x00-\x7f":             "ASCII character",
+	"\x00-\t,\v-\U0010ffff": "any character except newline",
+}
+
+// explainPattern describes the parsed form of pattern as an indented list.
+func explainPattern(re *syntax.Regexp) string {
+	var b strings.Builder
+	explainNode(&b, re, 0)
+	return b.String()
+}
+
+func writeLine(b *strings.Builder, depth int, format string, args ...interface{}) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("- ")
+	fmt.Fprintf(b, format, args...)
+	b.WriteByte('\n')
+}
+
+func explainNode(b *strings.Builder, re *syntax.Regexp, depth int) {
+	switch re.Op {
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			explainNode(b, sub, depth)
+		}
+	case syntax.OpAlternate:
+		writeLine(b, depth, "one of %d alternatives:", len(re.Sub))
+		for i, sub := range re.Sub {
+			if line, ok := describeLine(sub); ok {
+				writeLine(b, depth+1, "alternative %d: %s", i+1, line)
+				continue
+			}
+			writeLine(b, depth+1, "alternative %d:", i+1)
+			explainNode(b, sub, depth+2)
+		}
+	case syntax.OpCapture:
+		if re.Name != "" {
+			writeLine(b, depth, "capture group %d named %q:", re.Cap, re.Name)
+		} else {
+			writeLine(b, depth, "capture group %d:", re.Cap)
+		}
+		explainNode(b, re.Sub[0], depth+1)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if line, ok := describeLine(re); ok {
+			writeLine(b, depth, "%s", line)
+			return
+		}
+		writeLine(b, depth, "%s:", describeQuantifier(re))
+		explainNode(b, re.Sub[0], depth+1)
+	default:
+		writeLine(b, depth, "%s", describeSimple(re))
+	}
+}
+
+// describeLine returns a single line description of re if one suffices: for a single
+// element, optionally with a quantifier.
+func describeLine(re *syntax.Regexp) (string, bool) {
+	switch re.Op {
+	case syntax.OpConcat, syntax.OpAlternate, syntax.OpCapture:
+		return "", false
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub, ok := describeLine(re.Sub[0])
+		if !ok || re.Sub[0].Op == syntax.OpStar || re.Sub[0].Op == syntax.OpPlus ||
+			re.Sub[0].Op == syntax.OpQuest || re.Sub[0].Op == syntax.OpRepeat {
+			return "", false
+		}
+		return sub + ", " + describeQuantifier(re), true
+	}
+	return describeSimple(re), true
+}
+
+func describeQuantifier(re *syntax.Regexp) string {
+	var text string
+	switch re.Op {
+	case syntax.OpStar:
+		text = "zero or more times"
+	case syntax.OpPlus:
+		text = "one or more times"
+	case syntax.OpQuest:
+		text = "optional (zero or one time)"
+	case syntax.OpRepeat:
+		switch {
+		case re.Max == re.Min:
+			text = fmt.Sprintf("exactly %d times", re.Min)
+		case re.Max < 0:
+			text = fmt.Sprintf("%d or more times", re.Min)
+		default:
+			text = fmt.Sprintf("between %d and %d times", re.Min, re.Max)
+		}
+	}
+	if re.Flags&syntax.NonGreedy != 0 {
+		text += ", as few as possible (lazy)"
+	}
+	return text
+}
+
+func describeSimple(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return "nothing (can never match)"
+	case syntax.OpEmptyMatch:
+		return "empty string"
+	case syntax.OpLiteral:
+		kind := "literal"
+		if len(re.Rune) == 1 {
+			kind = "character"
+		}
+		if re.Flags&syntax.FoldCase != 0 {
+			// The parser stores case folded literals in upper case
+			return fmt.Sprintf("%s %s (case-insensitive)", kind, strconv.Quote(strings.ToLower(string(re.Rune))))
+		}
+		return fmt.Sprintf("%s %s", kind, strconv.Quote(string(re.Rune)))
+	case syntax.OpCharClass:
+		return describeClass(re.Rune)
+	case syntax.OpAnyCharNotNL:
+		return "any character except newline"
+	case syntax.OpAnyChar:
+		return "any character, including newline"
+	case syntax.OpBeginLine:
+		return "start of line"
+	case syntax.OpEndLine:
+		return "end of line"
+	case syntax.OpBeginText:
+		return "start of text"
+	case syntax.OpEndText:
+		if re.Flags&syntax.WasDollar != 0 {
+			return "end of text ($ without the m flag)"
+		}
+		return `end of text (\z)`
+	case syntax.OpWordBoundary:
+		return `word boundary (\b)`
+	case syntax.OpNoWordBoundary:
+		return `not a word boundary (\B)`
+	}
+	return re.String()
+}
+
+// describeClass names a character class given as sorted, non-overlapping rune ranges.
+func describeClass(ranges []rune) string {
+	if len(ranges) == 0 {
+		return "nothing (empty class)"
+	}
+	if name, ok := namedClasses[rangeKey(ranges)]; ok {
+		return name
+	}
+	if ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		negated := complementRanges(ranges)
+		if name, ok := namedClasses[rangeKey(negated)]; ok {
+			return "not a " + name
+		}
+		return "any character except " + formatRanges(negated)
+	}
+	if len(ranges) == 2 && ranges[0] == ranges[1] {
+		return "character " + strconv.QuoteRune(ranges[0])
+	}
+	return "one of " + formatRanges(ranges)
+}
+
+func rangeKey(ranges []rune) string {
+	parts := make([]string, 0, len(ranges)/2)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] == ranges[i+1] {
+			parts = append(parts, string(ranges[i]))
+		} else {
+			parts = append(parts, string(ranges[i])+"-"+string(ranges[i+1]))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func complementRanges(ranges []rune) []rune {
+	var out []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			out = append(out, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		out = append(out, next, unicode.MaxRune)
+	}
+	return out
+}
+
+func formatRanges(ranges []rune) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i := 0; i < len(ranges); i += 2 {
+		if i/2 == maxClassRanges {
+			fmt.Fprintf(&b, " ... %d more ranges", (len(ranges)-i)/2)
+			break
+		}
+		b.WriteString(formatRune(ranges[i]))
+		if ranges[i+1] != ranges[i] {
+			b.WriteByte('-')
+			b.WriteString(formatRune(ranges[i+1]))
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func formatRune(r rune) string {
+	if strings.ContainsRune(`\]-^[`, r) {
+		return `\` + string(r)
+	}
+	if unicode.IsPrint(r) {
+		return string(r)
+	}
+	quoted := strconv.QuoteRuneToASCII(r)
+	return quoted[1 : len(quoted)-1]
+}
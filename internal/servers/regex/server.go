@@ -0,0 +1,432 @@
+package regex
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// flagNames describes the supported flags.
+var flagNames = map[rune]string{
+	'i': "case-insensitive",
+	'm': "multi-line",
+	's': "dot matches newline",
+	'U': "ungreedy",
+}
+
+// maxDisplayLength limits how many characters of a match are shown.
+const maxDisplayLength = 200
+
+// RegexServer is an MCP server for testing and explaining regular expressions. Patterns use
+// Go's RE2 syntax, which matches in linear time, so untrusted patterns cannot hang the server.
+type RegexServer struct {
+	server      *server.MCPServer
+	maxTextSize int
+	maxMatches  int
+}
+
+// NewRegexServer creates a new RegexServer instance
+func NewRegexServer(maxTextSize, maxMatches int) *RegexServer {
+	log.Printf("RegexServer created: maxTextSize=%d, maxMatches=%d", maxTextSize, maxMatches)
+
+	s := &RegexServer{
+		maxTextSize: maxTextSize,
+		maxMatches:  maxMatches,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"regex-server", // server name
+		"1.0.0",        // version
+	)
+
+	// Register testRegex tool
+	testTool := params.Tool[testRegexArgs]("testRegex",
+		mcp.WithDescription("Tests a regular expression against sample text and lists matches, capture groups and their positions"),
+	)
+
+	// Register replaceRegex tool
+	replaceTool := params.Tool[replaceRegexArgs]("replaceRegex",
+		mcp.WithDescription("Replaces matches of a regular expression in text"),
+		mcp.WithString("mode",
+			mcp.Description("re2 (default) or pcre to accept PCRE replacement syntax and warn about differences"),
+			mcp.Enum("re2", "pcre"),
+		),
+	)
+
+	// Register explainRegex tool
+	explainTool := params.Tool[patternOptions]("explainRegex",
+		mcp.WithDescription("Explains the components of a regular expression"),
+	)
+
+	middleware.AddTool(mcpServer, testTool, s.handleTestRegex)
+	middleware.AddTool(mcpServer, replaceTool, s.handleReplaceRegex)
+	middleware.AddTool(mcpServer, explainTool, s.handleExplainRegex)
+
+	s.server = mcpServer
+	return s
+}
+
+// patternOptions holds the parameters shared by all tools.
+type patternOptions struct {
+	Pattern string `json:"pattern" param:"required" description:"Regular expression in Go RE2 syntax"`
+	Flags   string `json:"flags,omitempty" description:"Flags: i (case-insensitive), m (multi-line ^ and $), s (. matches newline), U (ungreedy)"`
+	Mode    string `json:"mode,omitempty" param:"enum=re2|pcre" description:"re2 (default) or pcre to warn about constructs that behave differently than in PCRE"`
+}
+
+// testRegexArgs are the parameters of testRegex.
+type testRegexArgs struct {
+	patternOptions
+	Text       string `json:"text" param:"required,empty" description:"Sample text to search"`
+	MaxMatches int    `json:"maxMatches,omitempty" param:"default=100" description:"Maximum number of matches to list (default: 100)"`
+}
+
+// replaceRegexArgs are the parameters of replaceRegex.
+type replaceRegexArgs struct {
+	patternOptions
+	Text        string `json:"text" param:"required,empty" description:"Text to modify"`
+	Replacement string `json:"replacement" param:"required,empty" description:"Replacement template. Use $1, ${1} or ${name} for groups and $$ for a literal $. In pcre mode \\1 and $<name> are accepted too"`
+	Literal     bool   `json:"literal,omitempty" description:"Insert the replacement as is, without expanding group references (default: false)"`
+	Limit       int    `json:"limit,omitempty" description:"Replace only the first N matches (default: all)"`
+}
+
+// fullPattern returns the pattern with the flags applied as an inline flag group.
+func (o patternOptions) fullPattern() (string, error) {
+	if o.Pattern == "" {
+		return "", fmt.Errorf("pattern is required")
+	}
+	if o.Mode != "" && o.Mode != "re2" && o.Mode != "pcre" {
+		return "", fmt.Errorf("invalid mode: %s (use re2 or pcre)", o.Mode)
+	}
+	if o.Flags == "" {
+		return o.Pattern, nil
+	}
+	if bad := strings.Trim(o.Flags, "imsU"); bad != "" {
+		return "", fmt.Errorf("invalid flags: %s (supported: i, m, s, U)", bad)
+	}
+	return "(?" + o.Flags + ")" + o.Pattern, nil
+}
+
+// compile compiles the pattern. Compile errors list any PCRE constructs found, and in
+// pcre mode the returned warnings describe behavior differences.
+func (o patternOptions) compile() (*regexp.Regexp, []string, error) {
+	pattern, err := o.fullPattern()
+	if err != nil {
+		return nil, nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	issues := findCompatIssues(o.Pattern, o.Flags)
+	if err != nil {
+		return nil, nil, compileError(err, issues)
+	}
+
+	var warnings []string
+	if o.Mode == "pcre" {
+		for _, issue := range issues {
+			warnings = append(warnings, issue.String())
+		}
+	}
+	return re, warnings, nil
+}
+
+// compileError adds the unsupported PCRE constructs found in the pattern to err.
+func compileError(err error, issues []compatIssue) error {
+	var unsupported []string
+	for _, issue := range issues {
+		if issue.Unsupported {
+			unsupported = append(unsupported, "- "+issue.String())
+		}
+	}
+	if len(unsupported) == 0 {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	return fmt.Errorf("invalid pattern: %w\nThe pattern uses PCRE features:\n%s", err, strings.Join(unsupported, "\n"))
+}
+
+// formatWarnings renders warnings as a trailing section.
+func formatWarnings(warnings []string) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	return "\n\nWarnings:\n- " + strings.Join(warnings, "\n- ")
+}
+
+// quoteMatch quotes matched text for display, shortening long matches.
+func quoteMatch(text string) string {
+	if utf8.RuneCountInString(text) <= maxDisplayLength {
+		return strconv.Quote(text)
+	}
+	runes := []rune(text)
+	return strconv.Quote(string(runes[:maxDisplayLength])) + fmt.Sprintf("... (%d characters)", len(runes))
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleTestRegex handles the match testing request.
+func (s *RegexServer) handleTestRegex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting testRegex request processing")
+
+	var args testRegexArgs
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Text) > s.maxTextSize {
+		return nil, fmt.Errorf("text exceeds the maximum size of %d bytes", s.maxTextSize)
+	}
+	if args.MaxMatches <= 0 {
+		args.MaxMatches = 100
+	}
+	args.MaxMatches = min(args.MaxMatches, s.maxMatches)
+
+	re, warnings, err := args.compile()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// Find one extra match to tell whether the list was truncated
+	matches := re.FindAllStringSubmatchIndex(args.Text, args.MaxMatches+1)
+	truncated := len(matches) > args.MaxMatches
+	if truncated {
+		matches = matches[:args.MaxMatches]
+	}
+
+	var b strings.Builder
+	switch {
+	case len(matches) == 0:
+		b.WriteString("No matches")
+	case truncated:
+		fmt.Fprintf(&b, "Showing the first %d matches", len(matches))
+	case len(matches) == 1:
+		b.WriteString("1 match")
+	default:
+		fmt.Fprintf(&b, "%d matches", len(matches))
+	}
+	if len(matches) > 0 {
+		b.WriteString(" (positions are character offsets, end exclusive)\n")
+	}
+
+	names := re.SubexpNames()
+	position := 0   // byte offset of the previous match
+	runeOffset := 0 // character offset of the previous match
+	for i, m := range matches {
+		runeOffset += utf8.RuneCountInString(args.Text[position:m[0]])
+		position = m[0]
+		offset := func(byteOffset int) int {
+			return runeOffset + utf8.RuneCountInString(args.Text[m[0]:byteOffset])
+		}
+
+		fmt.Fprintf(&b, "\nMatch %d [%d-%d]: %s\n", i+1, offset(m[0]), offset(m[1]), quoteMatch(args.Text[m[0]:m[1]]))
+		for g := 1; g < len(names); g++ {
+			label := fmt.Sprintf("Group %d", g)
+			if names[g] != "" {
+				label += fmt.Sprintf(" (%s)", names[g])
+			}
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				fmt.Fprintf(&b, "  %s: not matched\n", label)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s [%d-%d]: %s\n", label, offset(start), offset(end), quoteMatch(args.Text[start:end]))
+		}
+	}
+
+	log.Printf("testRegex request completed: %d matches", len(matches))
+	return textResult(strings.TrimRight(b.String(), "\n") + formatWarnings(warnings)), nil
+}
+
+// handleReplaceRegex handles the replacement request.
+func (s *RegexServer) handleReplaceRegex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting replaceRegex request processing")
+
+	var args replaceRegexArgs
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if len(args.Text) > s.maxTextSize {
+		return nil, fmt.Errorf("text exceeds the maximum size of %d bytes", s.maxTextSize)
+	}
+
+	re, warnings, err := args.compile()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	template := args.Replacement
+	if !args.Literal {
+		if args.Mode == "pcre" {
+			template = translateReplacement(template)
+		}
+		warnings = append(warnings, replacementWarnings(re, template)...)
+	}
+
+	limit := -1
+	if args.Limit > 0 {
+		limit = args.Limit
+	}
+
+	var out []byte
+	last := 0
+	matches := re.FindAllStringSubmatchIndex(args.Text, limit)
+	for _, m := range matches {
+		out = append(out, args.Text[last:m[0]]...)
+		if args.Literal {
+			out = append(out, template...)
+		} else {
+			out = re.ExpandString(out, template, args.Text, m)
+		}
+		last = m[1]
+		if len(out) > s.maxTextSize {
+			return nil, fmt.Errorf("result exceeds the maximum size of %d bytes", s.maxTextSize)
+		}
+	}
+	out = append(out, args.Text[last:]...)
+
+	summary := fmt.Sprintf("Replaced %d matches", len(matches))
+	if len(matches) == 1 {
+		summary = "Replaced 1 match"
+	}
+
+	log.Printf("replaceRegex request completed: %d replacements", len(matches))
+	return textResult(summary + "\n\n" + string(out) + formatWarnings(warnings)), nil
+}
+
+// handleExplainRegex handles the pattern explanation request.
+func (s *RegexServer) handleExplainRegex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting explainRegex request processing")
+
+	var args patternOptions
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	re, warnings, err := args.compile()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	pattern, _ := args.fullPattern()
+	// Parse with the same flags as regexp.Compile
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, compileError(err, findCompatIssues(args.Pattern, args.Flags))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pattern: %s\n", args.Pattern)
+	if args.Flags != "" {
+		var names []string
+		for _, f := range args.Flags {
+			names = append(names, flagNames[f])
+		}
+		fmt.Fprintf(&b, "Flags: %s\n", strings.Join(names, ", "))
+	}
+	fmt.Fprintf(&b, "Capture groups: %d\n", re.NumSubexp())
+	if prefix, complete := re.LiteralPrefix(); complete {
+		fmt.Fprintf(&b, "Matches only the literal text %s\n", strconv.Quote(prefix))
+	}
+	b.WriteString("\nComponents (alternatives with common prefixes are shown factored):\n")
+	b.WriteString(explainPattern(parsed))
+
+	log.Printf("explainRegex request completed: %d capture groups", re.NumSubexp())
+	return textResult(strings.TrimRight(b.String(), "\n") + formatWarnings(warnings)), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *RegexServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the regex server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("regex", flag.ContinueOnError)
+	var (
+		maxTextSize int
+		maxMatches  int
+	)
+	fs.IntVar(&maxTextSize, "max-text-size", 1024*1024, "Maximum text size in bytes (default 1MB)")
+	fs.IntVar(&maxMatches, "max-matches", 1000, "Maximum number of matches listed per request")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting regex server: maxTextSize=%d, maxMatches=%d", maxTextSize, maxMatches)
+
+	// Create RegexServer instance
+	regexServer := NewRegexServer(maxTextSize, maxMatches)
+	log.Println("RegexServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), regexServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return regexServer.Server(), transportFlags, nil
+}
+
+// Run starts the regex server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[RegexServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create regex server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("RegexServer shutdown")
+	return nil
+}
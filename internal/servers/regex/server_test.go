@@ -0,0 +1,271 @@
+package regex
+
+import (
+	"context"
+	"regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RegexServer creation test
+func TestNewRegexServer(t *testing.T) {
+	s := NewRegexServer(1024, 10)
+
+	assert.NotNil(t, s, "RegexServer instance should be created")
+	assert.Equal(t, 1024, s.maxTextSize, "Max text size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewRegexServer(1024, 10)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test detection of PCRE constructs
+func TestFindCompatIssues(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pattern   string
+		flags     string
+		construct string
+		offset    int
+	}{
+		{name: "Lookahead", pattern: `foo(?=bar)`, construct: "lookahead (?=...)", offset: 3},
+		{name: "Negative lookbehind", pattern: `(?<!\$)\d+`, construct: "negative lookbehind (?<!...)", offset: 0},
+		{name: "Backreference", pattern: `(\w)\1`, construct: `\1`, offset: 4},
+		{name: "Named backreference", pattern: `(?P<q>["'])x\k<q>`, construct: `\k backreference`, offset: 12},
+		{name: "Possessive quantifier", pattern: `a++b`, construct: "possessive quantifier", offset: 1},
+		{name: "Possessive repetition", pattern: `\d{2,}+`, construct: "possessive quantifier", offset: 5},
+		{name: "Atomic group", pattern: `(?>a|ab)c`, construct: "atomic group (?>...)", offset: 0},
+		{name: "Extended flag", pattern: `(?x) a b`, construct: "inline flags x", offset: 0},
+		{name: "End before newline", pattern: `end\Z`, construct: `\Z`, offset: 3},
+		{name: "Dollar without m flag", pattern: `end$`, construct: "$", offset: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := findCompatIssues(tc.pattern, tc.flags)
+			require.Len(t, issues, 1, "%v", issues)
+			assert.Equal(t, tc.construct, issues[0].Construct)
+			assert.Equal(t, tc.offset, issues[0].Offset)
+		})
+	}
+
+	// Supported syntax must not be reported
+	for _, pattern := range []string{
+		`(?i)(?P<year>\d{4})-(?<month>\d\d)`,
+		`a+?b*?c??`,
+		`[(?=][\]+]\(\?=\)`,
+		`\Q(?=\1)\E`,
+		`[[:alpha:]]+`,
+		`(?m)^line$`,
+	} {
+		assert.Empty(t, findCompatIssues(pattern, ""), pattern)
+	}
+	assert.Empty(t, findCompatIssues(`end$`, "m"))
+}
+
+// Test conversion of PCRE replacement templates
+func TestTranslateReplacement(t *testing.T) {
+	testCases := map[string]string{
+		`\1-\2`:       "${1}-${2}",
+		`$1x`:         "${1}x",
+		`$<year>/$2`:  "${year}/${2}",
+		`${name}`:     "${name}",
+		`cost: \$5`:   "cost: $$5",
+		`$$ and $`:    "$$ and $",
+		`a\\b`:        `a\b`,
+		`\12`:         "${12}",
+		`plain text`:  "plain text",
+		`100$ total`:  "100$$ total",
+		`\n stays \n`: `\n stays \n`,
+	}
+	for input, expected := range testCases {
+		assert.Equal(t, expected, translateReplacement(input), input)
+	}
+}
+
+// Test the pattern explanation
+func TestExplainPattern(t *testing.T) {
+	parsed, err := syntax.Parse(`^(?P<user>[\w.]+)@(\d{1,3}|[^\s@]+?)$`, syntax.Perl)
+	require.NoError(t, err)
+	explanation := explainPattern(parsed)
+
+	for _, expected := range []string{
+		"- start of text",
+		`- capture group 1 named "user":`,
+		`  - one of [.0-9A-Z_a-z], one or more times`,
+		`- character "@"`,
+		"- capture group 2:",
+		"  - one of 2 alternatives:",
+		`    - alternative 1: digit (\d), between 1 and 3 times`,
+		`    - alternative 2: any character except [\t-\n\f-\r @], one or more times, as few as possible (lazy)`,
+		"- end of text ($ without the m flag)",
+	} {
+		assert.Contains(t, explanation, expected)
+	}
+
+	assert.Equal(t, `not a digit (\d)`, describeClass([]rune{0, '0' - 1, '9' + 1, 0x10FFFF}))
+	assert.Equal(t, `word character (\w)`, describeClass([]rune{'0', '9', 'A', 'Z', '_', '_', 'a', 'z'}))
+	assert.Equal(t, `one of [\-az]`, describeClass([]rune{'-', '-', 'a', 'a', 'z', 'z'}))
+}
+
+// Test the testRegex tool
+func TestHandleTestRegex(t *testing.T) {
+	s := NewRegexServer(1024, 3)
+	ctx := context.Background()
+
+	result, err := s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `(?P<word>\p{L}+)(!)?`,
+		"text":    "héllo wörld!",
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "2 matches (positions are character offsets, end exclusive)")
+	assert.Contains(t, text, "Match 1 [0-5]: \"héllo\"\n  Group 1 (word) [0-5]: \"héllo\"\n  Group 2: not matched")
+	assert.Contains(t, text, "Match 2 [6-12]: \"wörld!\"\n  Group 1 (word) [6-11]: \"wörld\"\n  Group 2 [11-12]: \"!\"")
+
+	result, err = s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `a`,
+		"text":    "aaaaa",
+	}))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mcptest.ResultText(result), "Showing the first 3 matches"))
+
+	result, err = s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `HELLO$`,
+		"text":    "hello\n",
+		"flags":   "i",
+		"mode":    "pcre",
+	}))
+	require.NoError(t, err)
+	text = mcptest.ResultText(result)
+	assert.True(t, strings.HasPrefix(text, "No matches"))
+	assert.Contains(t, text, "Warnings:\n- $ at offset 5 differs from PCRE")
+
+	_, err = s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `foo(?!bar)`,
+		"text":    "foobaz",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "negative lookahead (?!...) at offset 3 not supported by RE2")
+
+	_, err = s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `a`,
+		"text":    "a",
+		"flags":   "x",
+	}))
+	assert.Error(t, err)
+
+	_, err = s.handleTestRegex(ctx, mcptest.NewCallToolRequest("testRegex", map[string]interface{}{
+		"pattern": `a`,
+		"text":    strings.Repeat("a", 2000),
+	}))
+	assert.Error(t, err, "Text over the size limit should be rejected")
+}
+
+// Test the replaceRegex tool
+func TestHandleReplaceRegex(t *testing.T) {
+	s := NewRegexServer(1024, 10)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		args     map[string]interface{}
+		expected string
+		warning  string
+	}{
+		{
+			name:     "Group references",
+			args:     map[string]interface{}{"pattern": `(\d+)-(?P<b>\d+)`, "text": "1-2 and 3-4", "replacement": "${b}-$1"},
+			expected: "Replaced 2 matches\n\n2-1 and 4-3",
+		},
+		{
+			name:     "Limit",
+			args:     map[string]interface{}{"pattern": `o`, "text": "foo boo", "replacement": "0", "limit": 2},
+			expected: "Replaced 2 matches\n\nf00 boo",
+		},
+		{
+			name:     "Literal",
+			args:     map[string]interface{}{"pattern": `cost`, "text": "cost", "replacement": "$1", "literal": true},
+			expected: "Replaced 1 match\n\n$1",
+		},
+		{
+			name:     "PCRE syntax",
+			args:     map[string]interface{}{"pattern": `(\w+)@(\w+)`, "text": "user@host", "replacement": `\2 at \1x`, "mode": "pcre"},
+			expected: "Replaced 1 match\n\nhost at userx",
+		},
+		{
+			name:     "Ambiguous reference",
+			args:     map[string]interface{}{"pattern": `(\w+)`, "text": "ab", "replacement": "$1x"},
+			expected: "Replaced 1 match\n\n",
+			warning:  `$1x refers to a group named "1x"`,
+		},
+		{
+			name:     "Backslash reference in re2 mode",
+			args:     map[string]interface{}{"pattern": `(\w+)`, "text": "ab", "replacement": `\1`},
+			expected: "Replaced 1 match\n\n\\1",
+			warning:  `which RE2 inserts literally`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := s.handleReplaceRegex(ctx, mcptest.NewCallToolRequest("replaceRegex", tc.args))
+			require.NoError(t, err)
+			text := mcptest.ResultText(result)
+			assert.True(t, strings.HasPrefix(text, tc.expected), text)
+			if tc.warning != "" {
+				assert.Contains(t, text, tc.warning)
+			} else {
+				assert.NotContains(t, text, "Warnings")
+			}
+		})
+	}
+
+	_, err := s.handleReplaceRegex(ctx, mcptest.NewCallToolRequest("replaceRegex", map[string]interface{}{
+		"pattern":     `a`,
+		"text":        strings.Repeat("a", 500),
+		"replacement": "aaa",
+	}))
+	assert.Error(t, err, "Results over the size limit should be rejected")
+}
+
+// Test the explainRegex tool
+func TestHandleExplainRegex(t *testing.T) {
+	s := NewRegexServer(1024, 10)
+	ctx := context.Background()
+
+	result, err := s.handleExplainRegex(ctx, mcptest.NewCallToolRequest("explainRegex", map[string]interface{}{
+		"pattern": `colou?r`,
+		"flags":   "i",
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "Flags: case-insensitive")
+	assert.Contains(t, text, "Capture groups: 0")
+	assert.Contains(t, text, `- literal "colo" (case-insensitive)`)
+	assert.Contains(t, text, `- character "u" (case-insensitive), optional (zero or one time)`)
+
+	result, err = s.handleExplainRegex(ctx, mcptest.NewCallToolRequest("explainRegex", map[string]interface{}{
+		"pattern": `v1\.0`,
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), `Matches only the literal text "v1.0"`)
+
+	_, err = s.handleExplainRegex(ctx, mcptest.NewCallToolRequest("explainRegex", map[string]interface{}{
+		"pattern": `(a)\1`,
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "The pattern uses PCRE features")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewRegexServer(1024, 10).Server())
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Action is what a job does when it fires.
+type Action struct {
+	Type string `json:"type"` // "tool" or "webhook"
+
+	// Tool actions call a tool on one of the configured MCP servers
+	Server    string                 `json:"server,omitempty"`
+	Tool      string                 `json:"tool,omitempty"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+
+	// Webhook actions send an HTTP request
+	URL     string            `json:"url,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// describe renders the action for listings.
+func (a Action) describe() string {
+	if a.Type == "webhook" {
+		return fmt.Sprintf("%s %s", a.Method, a.URL)
+	}
+	return fmt.Sprintf("%s/%s", a.Server, a.Tool)
+}
+
+// Job is a scheduled action. One-shot jobs have RunAt set and are kept, marked done,
+// after they run; recurring jobs have a cron Schedule.
+type Job struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	Schedule string    `json:"schedule,omitempty"`
+	Timezone string    `json:"timezone,omitempty"`
+	RunAt    time.Time `json:"runAt,omitempty"`
+	Action   Action    `json:"action"`
+	Created  time.Time `json:"created"`
+
+	NextRun    time.Time `json:"nextRun,omitempty"`
+	LastRun    time.Time `json:"lastRun,omitempty"`
+	LastStatus string    `json:"lastStatus,omitempty"` // "ok" or "error"
+	LastResult string    `json:"lastResult,omitempty"`
+	Runs       int       `json:"runs"`
+	Done       bool      `json:"done,omitempty"`
+
+	cron *cronSchedule
+	loc  *time.Location
+}
+
+// prepare parses the schedule and timezone of a job read from disk or a request.
+func (j *Job) prepare() error {
+	j.loc = time.Local
+	if j.Timezone != "" {
+		loc, err := time.LoadLocation(j.Timezone)
+		if err != nil {
+			return fmt.Errorf("unknown timezone %q", j.Timezone)
+		}
+		j.loc = loc
+	}
+	switch {
+	case j.Schedule != "" && !j.RunAt.IsZero():
+		return errors.New("a job has either a cron schedule or a run time, not both")
+	case j.Schedule != "":
+		c, err := parseCron(j.Schedule)
+		if err != nil {
+			return err
+		}
+		j.cron = c
+	case j.RunAt.IsZero():
+		return errors.New("a job needs a cron schedule or a run time")
+	}
+	return nil
+}
+
+// next returns the next run after t, or the zero time when the job will not run again.
+func (j *Job) next(t time.Time) time.Time {
+	if j.cron != nil {
+		return j.cron.Next(t.In(j.loc))
+	}
+	if j.Done {
+		return time.Time{}
+	}
+	return j.RunAt
+}
+
+// newJobID returns a short random identifier.
+func newJobID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// jobFile is the layout of the persisted jobs file.
+type jobFile struct {
+	Jobs []*Job `json:"jobs"`
+}
+
+// loadJobs reads the jobs file. A missing file holds no jobs.
+func loadJobs(path string) ([]*Job, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs file: %w", err)
+	}
+	var f jobFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse jobs file %s: %w", path, err)
+	}
+	for _, j := range f.Jobs {
+		if err := j.prepare(); err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.ID, err)
+		}
+	}
+	return f.Jobs, nil
+}
+
+// saveJobs writes the jobs file through a temporary file so a crash never leaves it
+// truncated. Jobs may hold webhook credentials, so the file is private to the user.
+func saveJobs(path string, jobs map[string]*Job) error {
+	list := make([]*Job, 0, len(jobs))
+	for _, j := range jobs {
+		list = append(list, j)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Created.Before(list[b].Created) })
+
+	data, err := json.MarshalIndent(jobFile{Jobs: list}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode jobs: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".jobs-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write jobs file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write jobs file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write jobs file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write jobs file: %w", err)
+	}
+	return nil
+}
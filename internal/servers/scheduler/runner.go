@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/secretref"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// maxResultSize caps the tool or webhook output kept with a job.
+const maxResultSize = 4096
+
+// callTool starts the server, calls one tool and shuts the server down again. Servers are
+// not kept running between jobs, which may be hours apart.
+func callTool(ctx context.Context, sc mcpconfig.Server, tool string, args map[string]interface{}) (string, error) {
+	env, err := secretref.Environ(sc.Env)
+	if err != nil {
+		return "", err
+	}
+	client, err := mcpclient.NewStdioMCPClient(sc.Command, env, sc.Args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to start server: %w", err)
+	}
+	defer client.Close()
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "scheduler-server",
+		Version: "1.0.0",
+	}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		return "", fmt.Errorf("failed to initialize server: %w", err)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	result, err := client.CallTool(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	output := strings.Join(parts, "\n")
+	if result.IsError {
+		return output, fmt.Errorf("tool returned an error: %s", truncate(output, 200))
+	}
+	return output, nil
+}
+
+// callWebhook sends the webhook request. Responses outside 2xx are errors.
+func callWebhook(ctx context.Context, client *http.Client, a Action) (string, error) {
+	var body io.Reader
+	if a.Body != "" {
+		body = strings.NewReader(a.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, a.Method, a.URL, body)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook request: %w", err)
+	}
+	for k, v := range a.Headers {
+		req.Header.Set(k, v)
+	}
+	if a.Body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "mcphost-scheduler/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResultSize))
+	output := fmt.Sprintf("%s\n%s", resp.Status, data)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return output, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return output, nil
+}
+
+// newWebhookClient returns an HTTP client requesting the URLs guard allows, if set,
+// that does not follow redirects, so a permitted host cannot forward the request to
+// one that is not.
+func newWebhookClient(timeout time.Duration, guard *urlguard.Policy) *http.Client {
+	client := httpclient.New(httpclient.Options{Timeout: timeout, Guard: guard})
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// truncate cuts s to n bytes with an ellipsis.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
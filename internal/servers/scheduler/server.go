@@ -0,0 +1,734 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/internal/urlguard"
+)
+
+// maxWait bounds how long the scheduler sleeps, so it notices wall clock changes such
+// as a resumed laptop.
+const maxWait = time.Minute
+
+// SchedulerServer is an MCP server that runs one-shot and cron scheduled jobs, each of
+// which calls a tool on a configured MCP server or sends a webhook.
+type SchedulerServer struct {
+	server       *server.MCPServer
+	jobsFile     string
+	servers      map[string]mcpconfig.Server
+	webhookHosts []string
+	timeout      time.Duration
+	maxJobs      int
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	wake     chan struct{}
+	running  sync.WaitGroup
+	sessions map[string]server.ClientSession // connected clients, which receive job notifications
+
+	// now and run are replaced in tests.
+	now func() time.Time
+	run func(ctx context.Context, a Action) (string, error)
+}
+
+// NewSchedulerServer creates a new SchedulerServer instance holding previously
+// persisted jobs. Jobs only run once Start is called.
+func NewSchedulerServer(jobs []*Job, jobsFile string, servers map[string]mcpconfig.Server, webhookHosts []string, timeout int, maxJobs int) *SchedulerServer {
+	log.Printf("SchedulerServer created: jobs=%d, jobsFile=%s, servers=%d, webhookHosts=%v, timeout=%ds, maxJobs=%d",
+		len(jobs), jobsFile, len(servers), webhookHosts, timeout, maxJobs)
+
+	s := &SchedulerServer{
+		jobsFile:     jobsFile,
+		servers:      servers,
+		webhookHosts: webhookHosts,
+		timeout:      time.Duration(timeout) * time.Second,
+		maxJobs:      maxJobs,
+		jobs:         make(map[string]*Job),
+		wake:         make(chan struct{}, 1),
+		sessions:     make(map[string]server.ClientSession),
+		now:          time.Now,
+	}
+	client := newWebhookClient(s.timeout, &urlguard.Policy{AllowHosts: webhookHosts})
+	s.run = func(ctx context.Context, a Action) (string, error) {
+		if a.Type == "webhook" {
+			return callWebhook(ctx, client, a)
+		}
+		sc, ok := s.servers[a.Server]
+		if !ok {
+			return "", fmt.Errorf("server %s is no longer configured", a.Server)
+		}
+		return callTool(ctx, sc, a.Tool, a.Arguments)
+	}
+
+	// Missed recurring runs are skipped, while a one-shot job whose time passed while the
+	// server was down runs as soon as the scheduler starts
+	now := s.now()
+	for _, j := range jobs {
+		j.NextRun = j.next(now)
+		s.jobs[j.ID] = j
+	}
+
+	// Jobs run outside of requests, so keep track of the client sessions to notify.
+	// mcp-go does not report disconnects; execute forgets sessions that stop reading.
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.mu.Lock()
+		s.sessions[session.SessionID()] = session
+		s.mu.Unlock()
+	})
+
+	mcpServer := server.NewMCPServer(
+		"scheduler-server", // server name
+		"1.0.0",            // version
+		server.WithLogging(),
+		server.WithHooks(hooks),
+	)
+
+	// Register scheduleJob tool
+	scheduleJobTool := mcp.NewTool("scheduleJob",
+		mcp.WithDescription("Schedules a job that calls a tool on another MCP server or sends an HTTP webhook, "+
+			"either once or on a cron schedule. Jobs persist across restarts. "+describeTargets(servers, webhookHosts)),
+		mcp.WithString("name",
+			mcp.Description("Short description of the job"),
+		),
+		mcp.WithString("schedule",
+			mcp.Description("Five field cron expression (minute hour day-of-month month day-of-week), e.g. \"0 9 * * MON-FRI\", "+
+				"or @hourly, @daily, @weekly, @monthly, @yearly"),
+		),
+		mcp.WithString("runAt",
+			mcp.Description("Time of a one-shot job, in RFC 3339 format or as \"2006-01-02 15:04\" in the job's timezone"),
+		),
+		mcp.WithString("delay",
+			mcp.Description("Runs a one-shot job after this delay, e.g. \"90s\", \"15m\" or \"2h\""),
+		),
+		mcp.WithString("timezone",
+			mcp.Description("IANA timezone for the cron schedule and runAt, e.g. \"Europe/Berlin\" (default: the server's local time)"),
+		),
+		mcp.WithString("type",
+			mcp.Description("What the job does"),
+			mcp.Enum("tool", "webhook"),
+			mcp.Required(),
+		),
+		mcp.WithString("server",
+			mcp.Description("MCP server whose tool a tool job calls"),
+		),
+		mcp.WithString("tool",
+			mcp.Description("Name of the tool a tool job calls"),
+		),
+		mcp.WithObject("arguments",
+			mcp.Description("Arguments passed to the tool"),
+		),
+		mcp.WithString("url",
+			mcp.Description("URL a webhook job requests"),
+		),
+		mcp.WithString("method",
+			mcp.Description("HTTP method of a webhook job (default: POST)"),
+			mcp.Enum("GET", "POST", "PUT", "PATCH", "DELETE"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("HTTP headers of a webhook job"),
+		),
+		mcp.WithString("body",
+			mcp.Description("Request body of a webhook job, sent as application/json unless a Content-Type header is given"),
+		),
+	)
+
+	// Register listJobs tool
+	listJobsTool := mcp.NewTool("listJobs",
+		mcp.WithDescription("Lists scheduled jobs with their next run and the outcome of their last run"),
+		mcp.WithBoolean("includeDone",
+			mcp.Description("Include one-shot jobs that already ran (default: true)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register cancelJob tool
+	cancelJobTool := mcp.NewTool("cancelJob",
+		mcp.WithDescription("Cancels a job and removes it from the schedule"),
+		mcp.WithString("id",
+			mcp.Description("ID of the job"),
+			mcp.Required(),
+		),
+	)
+
+	middleware.AddTool(mcpServer, scheduleJobTool, s.handleScheduleJob)
+	middleware.AddTool(mcpServer, listJobsTool, s.handleListJobs)
+	middleware.AddTool(mcpServer, cancelJobTool, s.handleCancelJob)
+
+	s.server = mcpServer
+	return s
+}
+
+// describeTargets renders what jobs may call for the tool description.
+func describeTargets(servers map[string]mcpconfig.Server, hosts []string) string {
+	names := serverNames(servers)
+	var parts []string
+	if len(names) > 0 {
+		parts = append(parts, "Tool jobs may call servers: "+strings.Join(names, ", ")+".")
+	} else {
+		parts = append(parts, "No servers are configured for tool jobs.")
+	}
+	if len(hosts) > 0 {
+		parts = append(parts, "Webhooks may be sent to: "+strings.Join(hosts, ", ")+".")
+	} else {
+		parts = append(parts, "Webhooks are disabled.")
+	}
+	return strings.Join(parts, " ")
+}
+
+// serverNames returns the sorted names of the configured servers.
+func serverNames(servers map[string]mcpconfig.Server) []string {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// jsonResult renders v as indented JSON.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return textResult(string(data)), nil
+}
+
+// parseRunAt accepts RFC 3339 or a wall clock time in loc.
+func parseRunAt(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid runAt %q; use RFC 3339 or \"2006-01-02 15:04\"", s)
+}
+
+// validateAction checks that a job only targets configured servers and permitted hosts.
+func (s *SchedulerServer) validateAction(a *Action) error {
+	switch a.Type {
+	case "tool":
+		if a.Server == "" || a.Tool == "" {
+			return fmt.Errorf("tool jobs need server and tool")
+		}
+		if _, ok := s.servers[a.Server]; !ok {
+			return fmt.Errorf("unknown server %q; configured servers: %s", a.Server, strings.Join(serverNames(s.servers), ", "))
+		}
+	case "webhook":
+		if len(s.webhookHosts) == 0 {
+			return fmt.Errorf("webhooks are disabled; start the server with -webhook-hosts")
+		}
+		if a.URL == "" {
+			return fmt.Errorf("webhook jobs need url")
+		}
+		u, err := url.Parse(a.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q; use an http or https URL", a.URL)
+		}
+		if !urlguard.MatchHost(u.Hostname(), s.webhookHosts) {
+			return fmt.Errorf("host %s is not permitted; webhooks may be sent to %s", u.Hostname(), strings.Join(s.webhookHosts, ", "))
+		}
+		a.Method = strings.ToUpper(a.Method)
+		if a.Method == "" {
+			a.Method = http.MethodPost
+		}
+	default:
+		return fmt.Errorf("invalid type %q; use tool or webhook", a.Type)
+	}
+	return nil
+}
+
+// save persists the jobs. The caller holds s.mu.
+func (s *SchedulerServer) save() error {
+	if s.jobsFile == "" {
+		return nil
+	}
+	return saveJobs(s.jobsFile, s.jobs)
+}
+
+// notify wakes the scheduler loop after the schedule changed.
+func (s *SchedulerServer) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs due jobs until ctx is cancelled.
+func (s *SchedulerServer) Start(ctx context.Context) {
+	go func() {
+		for {
+			wait := s.runDue(ctx)
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			case <-s.wake:
+				timer.Stop()
+			}
+		}
+	}()
+}
+
+// runDue starts every job whose time has come and returns how long to wait for the
+// next one. A job's next run is advanced before it starts, so a slow job is never
+// started twice and a one-shot job runs at most once, even if the server crashes.
+func (s *SchedulerServer) runDue(ctx context.Context) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	wait := maxWait
+	changed := false
+	for _, j := range s.jobs {
+		if j.NextRun.IsZero() {
+			continue
+		}
+		if j.NextRun.After(now) {
+			wait = min(wait, j.NextRun.Sub(now))
+			continue
+		}
+		if j.cron == nil {
+			j.Done = true
+		}
+		j.NextRun = j.next(now)
+		changed = true
+
+		job := *j
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			s.execute(ctx, &job)
+		}()
+	}
+	if changed {
+		if err := s.save(); err != nil {
+			log.Printf("Error: Failed to save jobs: %v", err)
+		}
+	}
+	return wait
+}
+
+// execute runs a job, records the outcome and notifies clients.
+func (s *SchedulerServer) execute(ctx context.Context, job *Job) {
+	log.Printf("Running job %s: %s", job.ID, job.Action.describe())
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	started := s.now()
+	output, err := s.run(ctx, job.Action)
+	status, level := "ok", "info"
+	if err != nil {
+		status, level = "error", "error"
+		output = strings.TrimSpace(err.Error() + "\n" + output)
+		log.Printf("Error: Job %s failed: %v", job.ID, err)
+	} else {
+		log.Printf("Job %s completed", job.ID)
+	}
+	output = truncate(output, maxResultSize)
+
+	s.mu.Lock()
+	// The job may have been cancelled while it ran
+	if j, ok := s.jobs[job.ID]; ok {
+		j.LastRun = started
+		j.LastStatus = status
+		j.LastResult = output
+		j.Runs++
+		if err := s.save(); err != nil {
+			log.Printf("Error: Failed to save jobs: %v", err)
+		}
+	}
+	s.mu.Unlock()
+
+	// Job outcomes are delivered as MCP log messages
+	data := map[string]interface{}{
+		"jobId":  job.ID,
+		"action": job.Action.describe(),
+		"status": status,
+		"result": output,
+	}
+	if job.Name != "" {
+		data["name"] = job.Name
+	}
+	s.mu.Lock()
+	sessions := make([]server.ClientSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+	if len(sessions) == 0 {
+		log.Printf("No client connected, job %s outcome not delivered", job.ID)
+		return
+	}
+	for _, session := range sessions {
+		err := s.server.SendNotificationToClient(s.server.WithContext(ctx, session), "notifications/message", map[string]interface{}{
+			"level":  level,
+			"logger": "scheduler",
+			"data":   data,
+		})
+		if err != nil {
+			// The notification channel of a disconnected session is no longer drained
+			// and fills up, so a session that cannot take a notification is gone
+			log.Printf("Error: Failed to notify session %s of job %s outcome, forgetting it: %v", session.SessionID(), job.ID, err)
+			s.mu.Lock()
+			delete(s.sessions, session.SessionID())
+			s.mu.Unlock()
+		}
+	}
+}
+
+// handleScheduleJob handles the scheduling request.
+func (s *SchedulerServer) handleScheduleJob(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting schedule job request processing")
+
+	var args struct {
+		Name     string `json:"name"`
+		Schedule string `json:"schedule"`
+		RunAt    string `json:"runAt"`
+		Delay    string `json:"delay"`
+		Timezone string `json:"timezone"`
+		Action
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	given := 0
+	for _, v := range []string{args.Schedule, args.RunAt, args.Delay} {
+		if v != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		log.Println("Error: Exactly one of schedule, runAt and delay is required")
+		return nil, fmt.Errorf("specify exactly one of schedule, runAt and delay")
+	}
+	if err := s.validateAction(&args.Action); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	now := s.now()
+	job := &Job{
+		ID:       newJobID(),
+		Name:     args.Name,
+		Schedule: args.Schedule,
+		Timezone: args.Timezone,
+		Action:   args.Action,
+		Created:  now,
+	}
+	loc := time.Local
+	if args.Timezone != "" {
+		l, err := time.LoadLocation(args.Timezone)
+		if err != nil {
+			log.Printf("Error: Unknown timezone: %s", args.Timezone)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "unknown timezone %q", args.Timezone)
+		}
+		loc = l
+	}
+	switch {
+	case args.RunAt != "":
+		t, err := parseRunAt(args.RunAt, loc)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		if !t.After(now) {
+			log.Printf("Error: runAt is in the past: %s", args.RunAt)
+			return nil, fmt.Errorf("runAt %s is in the past", t.Format(time.RFC3339))
+		}
+		job.RunAt = t
+	case args.Delay != "":
+		d, err := time.ParseDuration(args.Delay)
+		if err != nil || d <= 0 {
+			log.Printf("Error: Invalid delay: %s", args.Delay)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid delay %q; use a positive duration such as \"15m\"", args.Delay)
+		}
+		job.RunAt = now.Add(d)
+	}
+	if err := job.prepare(); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	job.NextRun = job.next(now)
+	if job.NextRun.IsZero() {
+		log.Printf("Error: Schedule never fires: %s", args.Schedule)
+		return nil, fmt.Errorf("schedule %q never fires", args.Schedule)
+	}
+
+	s.mu.Lock()
+	active := 0
+	for _, j := range s.jobs {
+		if !j.Done {
+			active++
+		}
+	}
+	if active >= s.maxJobs {
+		s.mu.Unlock()
+		log.Printf("Error: Job limit reached: %d", s.maxJobs)
+		return nil, fmt.Errorf("job limit of %d reached; cancel a job first", s.maxJobs)
+	}
+	s.jobs[job.ID] = job
+	if err := s.save(); err != nil {
+		delete(s.jobs, job.ID)
+		s.mu.Unlock()
+		log.Printf("Error: Failed to save jobs: %v", err)
+		return nil, err
+	}
+	s.mu.Unlock()
+	s.notify()
+
+	log.Printf("Schedule job request completed: id=%s, next=%s", job.ID, job.NextRun.Format(time.RFC3339))
+	return textResult(fmt.Sprintf("Scheduled job %s (%s %s), next run at %s",
+		job.ID, job.Action.Type, job.Action.describe(), job.NextRun.In(job.loc).Format(time.RFC3339))), nil
+}
+
+// handleListJobs handles the listing request.
+func (s *SchedulerServer) handleListJobs(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting list jobs request processing")
+
+	var args struct {
+		IncludeDone *bool  `json:"includeDone"`
+		Format      string `json:"format"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	includeDone := args.IncludeDone == nil || *args.IncludeDone
+
+	s.mu.Lock()
+	var jobs []Job
+	for _, j := range s.jobs {
+		if j.Done && !includeDone {
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	s.mu.Unlock()
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].Created.Equal(jobs[b].Created) {
+			return jobs[a].ID < jobs[b].ID
+		}
+		return jobs[a].Created.Before(jobs[b].Created)
+	})
+
+	log.Printf("List jobs request completed: %d jobs", len(jobs))
+	if args.Format == "json" {
+		if jobs == nil {
+			jobs = []Job{}
+		}
+		return jsonResult(jobs)
+	}
+	if len(jobs) == 0 {
+		return textResult("No jobs scheduled"), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d jobs\n", len(jobs))
+	for _, j := range jobs {
+		b.WriteString("\n" + j.ID)
+		if j.Name != "" {
+			b.WriteString(" " + j.Name)
+		}
+		b.WriteString("\n")
+		if j.Schedule != "" {
+			fmt.Fprintf(&b, "  Schedule: %s (%s)\n", j.Schedule, j.loc)
+		} else {
+			fmt.Fprintf(&b, "  Once at: %s\n", j.RunAt.In(j.loc).Format(time.RFC3339))
+		}
+		fmt.Fprintf(&b, "  Action: %s %s\n", j.Action.Type, j.Action.describe())
+		if j.Done {
+			b.WriteString("  Next run: done\n")
+		} else if !j.NextRun.IsZero() {
+			fmt.Fprintf(&b, "  Next run: %s\n", j.NextRun.In(j.loc).Format(time.RFC3339))
+		}
+		if !j.LastRun.IsZero() {
+			fmt.Fprintf(&b, "  Last run: %s (%s, %d runs)\n", j.LastRun.In(j.loc).Format(time.RFC3339), j.LastStatus, j.Runs)
+			if j.LastResult != "" {
+				fmt.Fprintf(&b, "  Last result: %s\n", truncate(strings.ReplaceAll(j.LastResult, "\n", " "), 200))
+			}
+		}
+	}
+	return textResult(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// handleCancelJob handles the cancellation request.
+func (s *SchedulerServer) handleCancelJob(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting cancel job request processing")
+
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	job, ok := s.jobs[args.ID]
+	if !ok {
+		s.mu.Unlock()
+		log.Printf("Error: Unknown job: %s", args.ID)
+		return nil, fmt.Errorf("no job with id %q", args.ID)
+	}
+	delete(s.jobs, args.ID)
+	if err := s.save(); err != nil {
+		s.jobs[args.ID] = job
+		s.mu.Unlock()
+		log.Printf("Error: Failed to save jobs: %v", err)
+		return nil, err
+	}
+	s.mu.Unlock()
+	s.notify()
+
+	log.Printf("Cancel job request completed: id=%s", args.ID)
+	return textResult(fmt.Sprintf("Cancelled job %s (%s %s)", job.ID, job.Action.Type, job.Action.describe())), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *SchedulerServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// splitList splits a comma separated flag value.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// New creates the scheduler server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves. Jobs
+// run until ctx is done.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("scheduler", flag.ContinueOnError)
+	var (
+		jobsFile     string
+		serversFile  string
+		webhookHosts string
+		timeout      int
+		maxJobs      int
+	)
+	fs.StringVar(&jobsFile, "jobs-file", "", "File the jobs are persisted in (default: ~/.mcphost/scheduler-jobs.json)")
+	fs.StringVar(&serversFile, "servers", "", "mcphost or Claude Desktop style config file declaring the MCP servers tool jobs may call")
+	fs.StringVar(&webhookHosts, "webhook-hosts", "", "Comma separated hosts webhooks may be sent to, e.g. \"hooks.slack.com,*.example.com\", IP addresses or CIDR ranges; webhooks are disabled when empty")
+	fs.IntVar(&timeout, "timeout", 60, "Timeout of a single job run in seconds")
+	fs.IntVar(&maxJobs, "max-jobs", 100, "Maximum number of pending jobs")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	if jobsFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			log.Printf("Error: Failed to get home directory: %v", err)
+			return nil, transport.Flags{}, err
+		}
+		jobsFile = filepath.Join(homeDir, ".mcphost", "scheduler-jobs.json")
+	}
+
+	log.Printf("Starting scheduler server: jobs-file=%s, servers=%s, webhook-hosts=%q, timeout=%ds", jobsFile, serversFile, webhookHosts, timeout)
+
+	servers, err := mcpconfig.Merge(nil, serversFile)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+	jobs, err := loadJobs(jobsFile)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create SchedulerServer instance
+	schedulerServer := NewSchedulerServer(jobs, jobsFile, servers, splitList(webhookHosts), timeout, maxJobs)
+	log.Println("SchedulerServer instance created successfully, starting server...")
+
+	schedulerServer.Start(ctx)
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), schedulerServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return schedulerServer.Server(), transportFlags, nil
+}
+
+// Run starts the scheduler server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[SchedulerServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create scheduler server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("SchedulerServer shutdown")
+	return nil
+}
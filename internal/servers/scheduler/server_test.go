@@ -0,0 +1,413 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testServers = map[string]mcpconfig.Server{
+	"reports": {Command: "reports-server"},
+}
+
+// fakeRunner records the actions a scheduler runs.
+type fakeRunner struct {
+	mu      sync.Mutex
+	actions []Action
+	err     error
+}
+
+func (f *fakeRunner) run(ctx context.Context, a Action) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, a)
+	if f.err != nil {
+		return "partial output", f.err
+	}
+	return "done: " + a.describe(), nil
+}
+
+// newTestServer returns a scheduler with a fixed clock and a fake runner.
+func newTestServer(t testing.TB, jobs []*Job) (*SchedulerServer, *fakeRunner, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	runner := &fakeRunner{}
+	s := NewSchedulerServer(jobs, filepath.Join(t.TempDir(), "jobs.json"), testServers, []string{"hooks.example.com"}, 5, 3)
+	s.now = func() time.Time { return now }
+	s.run = runner.run
+	return s, runner, &now
+}
+
+// SchedulerServer creation test
+func TestNewSchedulerServer(t *testing.T) {
+	s := NewSchedulerServer(nil, "jobs.json", testServers, []string{"hooks.example.com"}, 30, 10)
+
+	assert.NotNil(t, s, "SchedulerServer instance should be created")
+	assert.Equal(t, "jobs.json", s.jobsFile, "Jobs file should match")
+	assert.Equal(t, testServers, s.servers, "Servers should match")
+	assert.Equal(t, []string{"hooks.example.com"}, s.webhookHosts, "Webhook hosts should match")
+	assert.Equal(t, 30*time.Second, s.timeout, "Timeout should match")
+	assert.Equal(t, 10, s.maxJobs, "Max jobs should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewSchedulerServer(nil, "", nil, nil, 30, 10)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test cron expression parsing and evaluation
+func TestCron(t *testing.T) {
+	from := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 2, 8, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 2, 8, 45, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1", time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2026, 3, 2, 10, 5, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * FRI", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := parseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Next(from))
+		})
+	}
+
+	t.Run("timezone", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		if err != nil {
+			t.Skip("timezone database not available")
+		}
+		c, err := parseCron("0 9 * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC), c.Next(from.In(berlin)).UTC())
+		// The spring forward day skips 02:00-03:00
+		c, err = parseCron("30 2 * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 30, 2, 30, 0, 0, berlin), c.Next(time.Date(2026, 3, 29, 0, 0, 0, 0, berlin)))
+	})
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *"} {
+		_, err := parseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+// Test scheduleJob handler
+func TestHandleScheduleJob(t *testing.T) {
+	s, _, _ := newTestServer(t, nil)
+
+	result, err := s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+		"name":      "morning report",
+		"schedule":  "0 9 * * MON-FRI",
+		"timezone":  "UTC",
+		"type":      "tool",
+		"server":    "reports",
+		"tool":      "generate",
+		"arguments": map[string]interface{}{"period": "daily"},
+	}))
+	require.NoError(t, err)
+	assert.Regexp(t, `^Scheduled job [0-9a-f]{8} \(tool reports/generate\), next run at 2026-03-02T09:00:00Z$`, mcptest.ResultText(result))
+
+	result, err = s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+		"delay": "90s",
+		"type":  "webhook",
+		"url":   "https://hooks.example.com/notify",
+		"body":  `{"text":"hi"}`,
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "(webhook POST https://hooks.example.com/notify), next run at ")
+
+	// Both jobs were persisted
+	jobs, err := loadJobs(s.jobsFile)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	// Both were created at the same test time, so their order is not fixed
+	if jobs[0].Name != "morning report" {
+		jobs[0], jobs[1] = jobs[1], jobs[0]
+	}
+	assert.Equal(t, "morning report", jobs[0].Name)
+	assert.Equal(t, map[string]interface{}{"period": "daily"}, jobs[0].Action.Arguments)
+	assert.Equal(t, time.Date(2026, 3, 2, 8, 31, 30, 0, time.UTC), jobs[1].RunAt.UTC())
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"no time", map[string]interface{}{"type": "tool", "server": "reports", "tool": "x"}, "exactly one of schedule, runAt and delay"},
+		{"two times", map[string]interface{}{"schedule": "@daily", "delay": "1m", "type": "tool", "server": "reports", "tool": "x"}, "exactly one"},
+		{"bad cron", map[string]interface{}{"schedule": "61 * * * *", "type": "tool", "server": "reports", "tool": "x"}, "out of range"},
+		{"never", map[string]interface{}{"schedule": "0 0 31 2 *", "type": "tool", "server": "reports", "tool": "x"}, "never fires"},
+		{"past", map[string]interface{}{"runAt": "2026-03-01T00:00:00Z", "type": "tool", "server": "reports", "tool": "x"}, "in the past"},
+		{"bad runAt", map[string]interface{}{"runAt": "tomorrow", "type": "tool", "server": "reports", "tool": "x"}, "invalid runAt"},
+		{"bad delay", map[string]interface{}{"delay": "-5m", "type": "tool", "server": "reports", "tool": "x"}, "invalid delay"},
+		{"bad timezone", map[string]interface{}{"delay": "5m", "timezone": "Mars/Base", "type": "tool", "server": "reports", "tool": "x"}, "unknown timezone"},
+		{"unknown server", map[string]interface{}{"delay": "5m", "type": "tool", "server": "mail", "tool": "x"}, "unknown server \"mail\"; configured servers: reports"},
+		{"missing tool", map[string]interface{}{"delay": "5m", "type": "tool", "server": "reports"}, "need server and tool"},
+		{"host", map[string]interface{}{"delay": "5m", "type": "webhook", "url": "https://evil.example.net/x"}, "host evil.example.net is not permitted"},
+		{"scheme", map[string]interface{}{"delay": "5m", "type": "webhook", "url": "file:///etc/passwd"}, "invalid webhook url"},
+		{"type", map[string]interface{}{"delay": "5m", "type": "email"}, "invalid type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		_, err := s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+			"schedule": "@daily", "type": "tool", "server": "reports", "tool": "x",
+		}))
+		require.NoError(t, err)
+		_, err = s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+			"schedule": "@daily", "type": "tool", "server": "reports", "tool": "x",
+		}))
+		assert.ErrorContains(t, err, "job limit of 3 reached")
+	})
+
+	t.Run("webhooks disabled", func(t *testing.T) {
+		s := NewSchedulerServer(nil, "", testServers, nil, 5, 3)
+		_, err := s.handleScheduleJob(context.Background(), mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+			"delay": "5m", "type": "webhook", "url": "https://hooks.example.com/x",
+		}))
+		assert.ErrorContains(t, err, "-webhook-hosts")
+	})
+}
+
+// Test running due jobs
+func TestRunDue(t *testing.T) {
+	s, runner, now := newTestServer(t, nil)
+	ctx := context.Background()
+
+	for _, args := range []map[string]interface{}{
+		{"name": "ticker", "schedule": "*/5 * * * *", "timezone": "UTC", "type": "tool", "server": "reports", "tool": "tick"},
+		{"name": "once", "delay": "30s", "type": "webhook", "url": "https://hooks.example.com/once", "method": "put"},
+	} {
+		_, err := s.handleScheduleJob(ctx, mcptest.NewCallToolRequest("scheduleJob", args))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 30*time.Second, s.runDue(ctx), "Should wait for the one-shot job")
+	s.running.Wait()
+	assert.Empty(t, runner.actions)
+
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, maxWait, s.runDue(ctx), "Waits are capped")
+	s.running.Wait()
+	require.Len(t, runner.actions, 1)
+	assert.Equal(t, "PUT https://hooks.example.com/once", runner.actions[0].describe())
+
+	*now = now.Add(4*time.Minute + 30*time.Second)
+	runner.err = errors.New("server exploded")
+	s.runDue(ctx)
+	s.running.Wait()
+	require.Len(t, runner.actions, 2)
+	assert.Equal(t, "reports/tick", runner.actions[1].describe())
+
+	// The one-shot job is done and the ticker moved on
+	*now = now.Add(5 * time.Minute)
+	s.runDue(ctx)
+	s.running.Wait()
+	assert.Len(t, runner.actions, 3)
+
+	result, err := s.handleListJobs(ctx, mcptest.NewCallToolRequest("listJobs", nil))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.True(t, strings.HasPrefix(text, "2 jobs\n"))
+	assert.Contains(t, text, "  Schedule: */5 * * * * (UTC)\n  Action: tool reports/tick\n  Next run: 2026-03-02T08:45:00Z\n")
+	assert.Contains(t, text, "  Last run: 2026-03-02T08:40:00Z (error, 2 runs)\n  Last result: server exploded partial output")
+	assert.Contains(t, text, "  Action: webhook PUT https://hooks.example.com/once\n  Next run: done\n  Last run: 2026-03-02T08:30:30Z (ok, 1 runs)")
+
+	result, err = s.handleListJobs(ctx, mcptest.NewCallToolRequest("listJobs", map[string]interface{}{"includeDone": false, "format": "json"}))
+	require.NoError(t, err)
+	var jobs []Job
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &jobs))
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "ticker", jobs[0].Name)
+	assert.Equal(t, 2, jobs[0].Runs)
+
+	// Outcomes survive a restart
+	loaded, err := loadJobs(s.jobsFile)
+	require.NoError(t, err)
+	restarted, _, _ := newTestServer(t, loaded)
+	result, err = restarted.handleListJobs(ctx, mcptest.NewCallToolRequest("listJobs", nil))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "(error, 2 runs)")
+}
+
+// Test that an overdue one-shot job runs after a restart
+func TestOverdueJobAfterRestart(t *testing.T) {
+	created := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	jobs := []*Job{
+		{ID: "a", RunAt: created.Add(time.Hour), Created: created, Action: Action{Type: "tool", Server: "reports", Tool: "late"}},
+		{ID: "b", Schedule: "0 * * * *", Timezone: "UTC", Created: created, Action: Action{Type: "tool", Server: "reports", Tool: "hourly"}},
+	}
+	for _, j := range jobs {
+		require.NoError(t, j.prepare())
+	}
+
+	s, runner, _ := newTestServer(t, jobs)
+	s.runDue(context.Background())
+	s.running.Wait()
+	require.Len(t, runner.actions, 1, "Only the one-shot job should catch up")
+	assert.Equal(t, "late", runner.actions[0].Tool)
+}
+
+// Test cancelJob handler
+func TestHandleCancelJob(t *testing.T) {
+	s, _, _ := newTestServer(t, nil)
+	ctx := context.Background()
+
+	result, err := s.handleScheduleJob(ctx, mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+		"schedule": "@daily", "type": "tool", "server": "reports", "tool": "x",
+	}))
+	require.NoError(t, err)
+	id := strings.Fields(mcptest.ResultText(result))[2]
+
+	result, err = s.handleCancelJob(ctx, mcptest.NewCallToolRequest("cancelJob", map[string]interface{}{"id": id}))
+	require.NoError(t, err)
+	assert.Equal(t, "Cancelled job "+id+" (tool reports/x)", mcptest.ResultText(result))
+
+	jobs, err := loadJobs(s.jobsFile)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	_, err = s.handleCancelJob(ctx, mcptest.NewCallToolRequest("cancelJob", map[string]interface{}{"id": id}))
+	assert.ErrorContains(t, err, "no job with id")
+
+	result, err = s.handleListJobs(ctx, mcptest.NewCallToolRequest("listJobs", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "No jobs scheduled", mcptest.ResultText(result))
+}
+
+// fakeSession is a client session with a buffered notification channel.
+type fakeSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newFakeSession(id string) *fakeSession {
+	return &fakeSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (f *fakeSession) Initialize()                                         {}
+func (f *fakeSession) Initialized() bool                                   { return true }
+func (f *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return f.notifications }
+func (f *fakeSession) SessionID() string                                   { return f.id }
+
+// Test that job outcomes reach every connected client and no disconnected one
+func TestJobNotifications(t *testing.T) {
+	s, _, now := newTestServer(t, nil)
+	ctx := context.Background()
+
+	first, second := newFakeSession("first"), newFakeSession("second")
+	require.NoError(t, s.server.RegisterSession(ctx, first))
+	require.NoError(t, s.server.RegisterSession(ctx, second))
+
+	_, err := s.handleScheduleJob(ctx, mcptest.NewCallToolRequest("scheduleJob", map[string]interface{}{
+		"schedule": "* * * * *", "timezone": "UTC", "type": "tool", "server": "reports", "tool": "tick",
+	}))
+	require.NoError(t, err)
+
+	*now = now.Add(time.Minute)
+	s.runDue(ctx)
+	s.running.Wait()
+	for _, session := range []*fakeSession{first, second} {
+		require.Len(t, session.notifications, 1, "Session %s should be notified", session.id)
+		notification := <-session.notifications
+		assert.Equal(t, "notifications/message", notification.Method)
+		assert.Equal(t, "scheduler", notification.Params.AdditionalFields["logger"])
+	}
+
+	// A disconnected session stops draining its channel; once it is full, the session
+	// is forgotten
+	for len(first.notifications) < cap(first.notifications) {
+		first.notifications <- mcp.JSONRPCNotification{}
+	}
+	*now = now.Add(time.Minute)
+	s.runDue(ctx)
+	s.running.Wait()
+	assert.Len(t, second.notifications, 1)
+
+	for len(first.notifications) > 0 {
+		<-first.notifications
+	}
+	<-second.notifications
+	*now = now.Add(time.Minute)
+	s.runDue(ctx)
+	s.running.Wait()
+	assert.Empty(t, first.notifications, "Disconnected sessions should not be notified")
+	assert.Len(t, second.notifications, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Len(t, s.sessions, 1)
+}
+
+// Test the webhook client
+func TestCallWebhook(t *testing.T) {
+	var gotMethod, gotBody, gotType, gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "https://elsewhere.example.net/", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotBody, gotType, gotAuth = r.Method, string(body), r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		w.Write([]byte("accepted"))
+	}))
+	defer ts.Close()
+	client := newWebhookClient(5*time.Second, nil)
+
+	output, err := callWebhook(context.Background(), client, Action{
+		Method: "POST", URL: ts.URL + "/hook", Body: `{"a":1}`, Headers: map[string]string{"Authorization": "Bearer x"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "200 OK\naccepted", output)
+	assert.Equal(t, []string{"POST", `{"a":1}`, "application/json", "Bearer x"}, []string{gotMethod, gotBody, gotType, gotAuth})
+
+	_, err = callWebhook(context.Background(), client, Action{Method: "GET", URL: ts.URL + "/fail"})
+	assert.EqualError(t, err, "webhook returned 500 Internal Server Error")
+
+	_, err = callWebhook(context.Background(), client, Action{Method: "GET", URL: ts.URL + "/redirect"})
+	assert.ErrorContains(t, err, "302", "Redirects should not be followed")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	s, _, _ := newTestServer(f, nil)
+	mcptest.FuzzTools(f, s.Server())
+}
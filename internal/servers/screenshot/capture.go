@@ -0,0 +1,247 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// target selects what to capture. Exactly one of Window and Region is set for window and
+// region captures; neither is set for the full screen.
+type target struct {
+	Window string
+	Region *region
+}
+
+// region is a rectangle in screen coordinates.
+type region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// capturer is implemented by the platform specific backends. Capture writes a PNG file.
+type capturer interface {
+	Capture(ctx context.Context, t target, file string) error
+	Name() string
+}
+
+// detectCapturer picks the screenshot backend for the current platform, or the named one.
+func detectCapturer(name string) (capturer, error) {
+	all := map[string]capturer{
+		"screencapture": screencaptureBackend{},
+		"grim":          grimBackend{},
+		"import":        importBackend{},
+		"powershell":    powershellBackend{},
+	}
+	if name != "" && name != "auto" {
+		c, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown backend %q; use auto, screencapture, grim, import or powershell", name)
+		}
+		if _, err := exec.LookPath(commandOf(c)); err != nil {
+			return nil, fmt.Errorf("%s backend is not available: %w", name, err)
+		}
+		return c, nil
+	}
+
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"screencapture"}
+	case "windows":
+		candidates = []string{"powershell"}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, "grim")
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates, "import")
+		}
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(commandOf(all[c])); err == nil {
+			return all[c], nil
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("no display found; set DISPLAY or WAYLAND_DISPLAY")
+	}
+	return nil, fmt.Errorf("no screenshot backend found; install %s", strings.Join(candidates, " or "))
+}
+
+// commandOf returns the program a backend runs.
+func commandOf(c capturer) string {
+	if c.Name() == "powershell" {
+		return "powershell.exe"
+	}
+	return c.Name()
+}
+
+// run executes a program and returns stdout, or stderr as the error.
+func run(ctx context.Context, env []string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s: %s", name, msg)
+	}
+	return stdout.String(), nil
+}
+
+// screencaptureBackend uses the macOS screencapture tool.
+type screencaptureBackend struct{}
+
+func (screencaptureBackend) Name() string { return "screencapture" }
+
+func (screencaptureBackend) Capture(ctx context.Context, t target, file string) error {
+	// -x silences the shutter sound
+	args := []string{"-x", "-t", "png"}
+	switch {
+	case t.Region != nil:
+		r := t.Region
+		args = append(args, "-R", fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height))
+	case t.Window != "":
+		// screencapture only addresses windows by their CGWindowID
+		if _, err := strconv.ParseUint(t.Window, 10, 32); err != nil {
+			return fmt.Errorf("on macOS the window must be a numeric window ID, got %q", t.Window)
+		}
+		args = append(args, "-o", "-l", t.Window)
+	}
+	_, err := run(ctx, nil, "screencapture", append(args, file)...)
+	return err
+}
+
+// grimBackend uses grim on Wayland compositors that support wlr-screencopy.
+type grimBackend struct{}
+
+func (grimBackend) Name() string { return "grim" }
+
+func (grimBackend) Capture(ctx context.Context, t target, file string) error {
+	var args []string
+	switch {
+	case t.Region != nil:
+		r := t.Region
+		args = append(args, "-g", fmt.Sprintf("%d,%d %dx%d", r.X, r.Y, r.Width, r.Height))
+	case t.Window != "":
+		return errors.New("capturing a window is not supported on Wayland; capture a region instead")
+	}
+	_, err := run(ctx, nil, "grim", append(args, "-t", "png", file)...)
+	return err
+}
+
+// importBackend uses ImageMagick's import on X11, with xdotool to find windows by title.
+type importBackend struct{}
+
+func (importBackend) Name() string { return "import" }
+
+func (importBackend) Capture(ctx context.Context, t target, file string) error {
+	args := []string{"-silent", "-window", "root"}
+	switch {
+	case t.Region != nil:
+		r := t.Region
+		args = append(args, "-crop", fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y), "+repage")
+	case t.Window != "":
+		id := t.Window
+		if !isWindowID(id) {
+			out, err := run(ctx, nil, "xdotool", "search", "--onlyvisible", "--limit", "1", "--name", regexpQuote(t.Window))
+			if err != nil {
+				return fmt.Errorf("cannot find window %q: %w", t.Window, err)
+			}
+			id = strings.TrimSpace(out)
+			if id == "" {
+				return fmt.Errorf("no visible window has a title containing %q", t.Window)
+			}
+		}
+		args = []string{"-silent", "-window", id}
+	}
+	_, err := run(ctx, nil, "import", append(args, "png:"+file)...)
+	return err
+}
+
+// isWindowID reports whether s is an X11 window ID in decimal or 0x hexadecimal form.
+func isWindowID(s string) bool {
+	var err error
+	if hex, ok := strings.CutPrefix(s, "0x"); ok {
+		_, err = strconv.ParseUint(hex, 16, 32)
+	} else {
+		_, err = strconv.ParseUint(s, 10, 32)
+	}
+	return err == nil
+}
+
+// regexpQuote escapes a title for xdotool, which matches names as regular expressions.
+func regexpQuote(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// powershellBackend uses System.Drawing through PowerShell on Windows. Values reach the
+// script through environment variables so they are never parsed as code.
+type powershellBackend struct{}
+
+func (powershellBackend) Name() string { return "powershell" }
+
+const powershellScript = `
+$ErrorActionPreference = 'Stop'
+Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+Add-Type @'
+using System;
+using System.Runtime.InteropServices;
+public struct RECT { public int Left, Top, Right, Bottom; }
+public static class Win32 {
+  [DllImport("user32.dll")] public static extern bool SetProcessDPIAware();
+  [DllImport("user32.dll")] public static extern bool GetWindowRect(IntPtr hWnd, out RECT rect);
+}
+'@
+[Win32]::SetProcessDPIAware() | Out-Null
+if ($env:SCREENSHOT_WINDOW) {
+  $p = Get-Process | Where-Object { $_.MainWindowHandle -ne 0 -and $_.MainWindowTitle -like "*$($env:SCREENSHOT_WINDOW)*" } | Select-Object -First 1
+  if (-not $p) { throw "no window has a title containing '$($env:SCREENSHOT_WINDOW)'" }
+  $r = New-Object RECT
+  [Win32]::GetWindowRect($p.MainWindowHandle, [ref]$r) | Out-Null
+  $bounds = [Drawing.Rectangle]::FromLTRB($r.Left, $r.Top, $r.Right, $r.Bottom)
+} elseif ($env:SCREENSHOT_REGION) {
+  $v = $env:SCREENSHOT_REGION.Split(',') | ForEach-Object { [int]$_ }
+  $bounds = New-Object Drawing.Rectangle $v[0], $v[1], $v[2], $v[3]
+} else {
+  $bounds = [Windows.Forms.SystemInformation]::VirtualScreen
+}
+$bmp = New-Object Drawing.Bitmap $bounds.Width, $bounds.Height
+$g = [Drawing.Graphics]::FromImage($bmp)
+$g.CopyFromScreen($bounds.Location, [Drawing.Point]::Empty, $bounds.Size)
+$bmp.Save($env:SCREENSHOT_FILE, [Drawing.Imaging.ImageFormat]::Png)
+$g.Dispose(); $bmp.Dispose()
+`
+
+func (powershellBackend) Capture(ctx context.Context, t target, file string) error {
+	env := []string{"SCREENSHOT_FILE=" + file, "SCREENSHOT_WINDOW=" + t.Window, "SCREENSHOT_REGION="}
+	if r := t.Region; r != nil {
+		env[2] = fmt.Sprintf("SCREENSHOT_REGION=%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+	}
+	_, err := run(ctx, env, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", powershellScript)
+	return err
+}
@@ -0,0 +1,62 @@
+package screenshot
+
+import (
+	"image"
+	"image/draw"
+)
+
+// fitSize returns the largest size with the aspect ratio of width x height that fits in
+// maxWidth x maxHeight. Images that already fit keep their size.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if maxWidth <= 0 || maxWidth > width {
+		maxWidth = width
+	}
+	if maxHeight <= 0 || maxHeight > height {
+		maxHeight = height
+	}
+	if width*maxHeight > height*maxWidth {
+		// Width is the limiting side
+		return maxWidth, max(1, height*maxWidth/width)
+	}
+	return max(1, width*maxHeight/height), maxHeight
+}
+
+// downscale shrinks img to width x height by averaging the source pixels each
+// destination pixel covers, which keeps text legible better than sampling.
+func downscale(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == sw && height == sh {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint32(row[i])
+					g += uint32(row[i+1])
+					bl += uint32(row[i+2])
+					a += uint32(row[i+3])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
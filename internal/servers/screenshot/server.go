@@ -0,0 +1,327 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// ScreenshotServer is an MCP server that captures the screen, windows and screen regions.
+type ScreenshotServer struct {
+	server       *server.MCPServer
+	capturer     capturer
+	maxWidth     int
+	maxHeight    int
+	maxImageSize int
+	timeout      time.Duration
+}
+
+// NewScreenshotServer creates a new ScreenshotServer instance
+func NewScreenshotServer(c capturer, maxWidth, maxHeight, maxImageSize, timeout int) *ScreenshotServer {
+	log.Printf("ScreenshotServer created: backend=%s, maxWidth=%d, maxHeight=%d, maxImageSize=%d, timeout=%ds",
+		c.Name(), maxWidth, maxHeight, maxImageSize, timeout)
+
+	s := &ScreenshotServer{
+		capturer:     c,
+		maxWidth:     maxWidth,
+		maxHeight:    maxHeight,
+		maxImageSize: maxImageSize,
+		timeout:      time.Duration(timeout) * time.Second,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"screenshot-server", // server name
+		"1.0.0",             // version
+	)
+
+	// Options shared by all capture tools
+	scaling := []mcp.ToolOption{
+		mcp.WithNumber("maxWidth",
+			mcp.Description(fmt.Sprintf("Largest width of the returned image in pixels; larger captures are scaled down (default and limit: %d)", maxWidth)),
+		),
+		mcp.WithNumber("maxHeight",
+			mcp.Description(fmt.Sprintf("Largest height of the returned image in pixels; larger captures are scaled down (default and limit: %d)", maxHeight)),
+		),
+	}
+
+	// Register captureScreen tool
+	captureScreenTool := mcp.NewTool("captureScreen", append([]mcp.ToolOption{
+		mcp.WithDescription("Captures the whole screen, including all displays, as a PNG image"),
+	}, scaling...)...)
+
+	// Register captureWindow tool
+	captureWindowTool := mcp.NewTool("captureWindow", append([]mcp.ToolOption{
+		mcp.WithDescription("Captures a single window as a PNG image"),
+		mcp.WithString("window",
+			mcp.Description("Part of the window title, or a window ID. macOS only supports numeric window IDs and Wayland does not support window captures"),
+			mcp.Required(),
+		),
+	}, scaling...)...)
+
+	// Register captureRegion tool
+	captureRegionTool := mcp.NewTool("captureRegion", append([]mcp.ToolOption{
+		mcp.WithDescription("Captures a rectangle of the screen as a PNG image"),
+		mcp.WithNumber("x",
+			mcp.Description("Left edge in screen coordinates"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("y",
+			mcp.Description("Top edge in screen coordinates"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("width",
+			mcp.Description("Width in pixels"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("height",
+			mcp.Description("Height in pixels"),
+			mcp.Required(),
+		),
+	}, scaling...)...)
+
+	captureTimeout := middleware.Timeout(s.timeout)
+	middleware.AddTool(mcpServer, captureScreenTool, s.handleCaptureScreen, captureTimeout)
+	middleware.AddTool(mcpServer, captureWindowTool, s.handleCaptureWindow, captureTimeout)
+	middleware.AddTool(mcpServer, captureRegionTool, s.handleCaptureRegion, captureTimeout)
+
+	s.server = mcpServer
+	return s
+}
+
+// captureOptions holds the parameters shared by all capture tools.
+type captureOptions struct {
+	MaxWidth  int `json:"maxWidth,omitempty"`
+	MaxHeight int `json:"maxHeight,omitempty"`
+}
+
+// handleCaptureScreen handles the full screen capture request.
+func (s *ScreenshotServer) handleCaptureScreen(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting captureScreen request processing")
+
+	var args captureOptions
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	return s.capture(ctx, "screen", target{}, args)
+}
+
+// handleCaptureWindow handles the window capture request.
+func (s *ScreenshotServer) handleCaptureWindow(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting captureWindow request processing")
+
+	var args struct {
+		captureOptions
+		Window string `json:"window"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Window == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "window is required")
+	}
+	return s.capture(ctx, "window "+args.Window, target{Window: args.Window}, args.captureOptions)
+}
+
+// handleCaptureRegion handles the region capture request.
+func (s *ScreenshotServer) handleCaptureRegion(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting captureRegion request processing")
+
+	var args struct {
+		captureOptions
+		region
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	r := args.region
+	if r.Width <= 0 || r.Height <= 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "width and height must be positive")
+	}
+	// Coordinates may be negative for displays left of or above the primary one
+	if r.Width > 32768 || r.Height > 32768 {
+		return nil, fmt.Errorf("region of %dx%d pixels is larger than any screen", r.Width, r.Height)
+	}
+	name := fmt.Sprintf("region %dx%d at %d,%d", r.Width, r.Height, r.X, r.Y)
+	return s.capture(ctx, name, target{Region: &r}, args.captureOptions)
+}
+
+// capture takes a screenshot with the backend and returns it scaled to fit the limits.
+func (s *ScreenshotServer) capture(ctx context.Context, name string, t target, opts captureOptions) (*mcp.CallToolResult, error) {
+	limitWidth, limitHeight := s.maxWidth, s.maxHeight
+	if opts.MaxWidth > 0 && opts.MaxWidth < limitWidth {
+		limitWidth = opts.MaxWidth
+	}
+	if opts.MaxHeight > 0 && opts.MaxHeight < limitHeight {
+		limitHeight = opts.MaxHeight
+	}
+
+	f, err := os.CreateTemp("", "screenshot-*.png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	file := f.Name()
+	f.Close()
+	defer os.Remove(file)
+
+	if err := s.capturer.Capture(ctx, t, file); err != nil {
+		log.Printf("Error: Failed to capture %s: %v", name, err)
+		return nil, fmt.Errorf("failed to capture %s: %w", name, err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the screenshot: %w", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		// Some tools leave an empty file when screen recording permission is missing
+		log.Printf("Error: Invalid screenshot: %v", err)
+		return nil, fmt.Errorf("the screenshot could not be decoded; check that screen capture is permitted: %w", err)
+	}
+
+	b := img.Bounds()
+	width, height := fitSize(b.Dx(), b.Dy(), limitWidth, limitHeight)
+	scaled, encoded, err := s.encode(img, width, height)
+	if err != nil {
+		return nil, err
+	}
+	sb := scaled.Bounds()
+
+	text := fmt.Sprintf("Captured %s: %dx%d pixels", name, b.Dx(), b.Dy())
+	if sb.Dx() != b.Dx() || sb.Dy() != b.Dy() {
+		text += fmt.Sprintf(", scaled to %dx%d", sb.Dx(), sb.Dy())
+	}
+	text += fmt.Sprintf(" (%d bytes PNG)", len(encoded))
+
+	log.Printf("Capture request completed: %s, %dx%d, %d bytes", name, sb.Dx(), sb.Dy(), len(encoded))
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.ImageContent{
+				Type:     "image",
+				Data:     base64.StdEncoding.EncodeToString(encoded),
+				MIMEType: "image/png",
+			},
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+// encode scales img to width x height and encodes it as PNG, shrinking it further until
+// the encoded image fits in the maximum image size.
+func (s *ScreenshotServer) encode(img image.Image, width, height int) (image.Image, []byte, error) {
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	for {
+		scaled := downscale(img, width, height)
+		var buf bytes.Buffer
+		if err := enc.Encode(&buf, scaled); err != nil {
+			log.Printf("Error: Failed to encode PNG: %v", err)
+			return nil, nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		if buf.Len() <= s.maxImageSize {
+			return scaled, buf.Bytes(), nil
+		}
+		if width <= 16 || height <= 16 {
+			return nil, nil, fmt.Errorf("the screenshot does not fit in %d bytes", s.maxImageSize)
+		}
+		width, height = width*3/4, height*3/4
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *ScreenshotServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the screenshot server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("screenshot", flag.ContinueOnError)
+	var (
+		backendName  string
+		maxWidth     int
+		maxHeight    int
+		maxImageSize int
+		timeout      int
+	)
+	fs.StringVar(&backendName, "backend", "auto", "Screenshot backend: auto, screencapture, grim, import or powershell")
+	fs.IntVar(&maxWidth, "max-width", 1920, "Maximum width of returned images in pixels")
+	fs.IntVar(&maxHeight, "max-height", 1920, "Maximum height of returned images in pixels")
+	fs.IntVar(&maxImageSize, "max-image-size", 2*1024*1024, "Maximum size of returned PNG images in bytes (default 2MB)")
+	fs.IntVar(&timeout, "timeout", 15, "Timeout for screenshot commands in seconds")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting screenshot server: backend=%s, max-width=%d, max-height=%d", backendName, maxWidth, maxHeight)
+
+	c, err := detectCapturer(backendName)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	// Create ScreenshotServer instance
+	screenshotServer := NewScreenshotServer(c, maxWidth, maxHeight, maxImageSize, timeout)
+	log.Println("ScreenshotServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), screenshotServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return screenshotServer.Server(), transportFlags, nil
+}
+
+// Run starts the screenshot server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[ScreenshotServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create screenshot server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("ScreenshotServer shutdown")
+	return nil
+}
@@ -0,0 +1,237 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCapturer writes a generated image and records the last target.
+type fakeCapturer struct {
+	width, height int
+	noise         bool
+	err           error
+	last          target
+}
+
+func (f *fakeCapturer) Name() string {
+	return "fake"
+}
+
+func (f *fakeCapturer) Capture(ctx context.Context, t target, file string) error {
+	f.last = t
+	if f.err != nil {
+		return f.err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, f.width, f.height))
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < f.height; y++ {
+		for x := 0; x < f.width; x++ {
+			c := color.RGBA{uint8(x), uint8(y), 128, 255}
+			if f.noise {
+				c = color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return png.Encode(out, img)
+}
+
+// ScreenshotServer creation test
+func TestNewScreenshotServer(t *testing.T) {
+	c := &fakeCapturer{}
+	s := NewScreenshotServer(c, 800, 600, 1024, 5)
+
+	assert.NotNil(t, s, "ScreenshotServer instance should be created")
+	assert.Equal(t, c, s.capturer, "Capturer should match")
+	assert.Equal(t, 800, s.maxWidth, "Max width should match")
+	assert.Equal(t, 600, s.maxHeight, "Max height should match")
+	assert.Equal(t, 1024, s.maxImageSize, "Max image size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewScreenshotServer(&fakeCapturer{}, 800, 600, 1024, 5)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// decodeResult returns the image and text of a capture result.
+func decodeResult(t *testing.T, result *mcp.CallToolResult) (image.Image, string) {
+	require.Len(t, result.Content, 2)
+	content := result.Content[0].(mcp.ImageContent)
+	assert.Equal(t, "image/png", content.MIMEType)
+	data, err := base64.StdEncoding.DecodeString(content.Data)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img, result.Content[1].(mcp.TextContent).Text
+}
+
+// Test size fitting
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height, maxWidth, maxHeight int
+		wantWidth, wantHeight              int
+	}{
+		{1920, 1080, 960, 960, 960, 540},
+		{1080, 1920, 960, 960, 540, 960},
+		{800, 600, 1920, 1920, 800, 600},
+		{3000, 10, 100, 100, 100, 1},
+		{800, 600, 0, 300, 400, 300},
+	}
+	for _, tt := range tests {
+		w, h := fitSize(tt.width, tt.height, tt.maxWidth, tt.maxHeight)
+		assert.Equal(t, tt.wantWidth, w, "width for %dx%d", tt.width, tt.height)
+		assert.Equal(t, tt.wantHeight, h, "height for %dx%d", tt.width, tt.height)
+	}
+}
+
+// Test that downscaling averages pixels
+func TestDownscale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			// Columns alternate between black and white
+			v := uint8(255 * (x % 2))
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	scaled := downscale(img, 2, 1)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), scaled.Bounds())
+	assert.Equal(t, color.RGBA{127, 127, 127, 255}, scaled.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{127, 127, 127, 255}, scaled.RGBAAt(1, 0))
+
+	// Images that fit are returned unchanged
+	assert.Equal(t, img.Pix, downscale(img, 4, 2).Pix)
+}
+
+// Test the capture tools
+func TestCaptureTools(t *testing.T) {
+	c := &fakeCapturer{width: 400, height: 200}
+	s := NewScreenshotServer(c, 200, 200, 1<<20, 5)
+
+	t.Run("screen", func(t *testing.T) {
+		result, err := s.handleCaptureScreen(context.Background(), mcptest.NewCallToolRequest("captureScreen", nil))
+		require.NoError(t, err)
+		img, text := decodeResult(t, result)
+		assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+		assert.Contains(t, text, "Captured screen: 400x200 pixels, scaled to 200x100")
+		assert.Equal(t, target{}, c.last)
+	})
+
+	t.Run("smaller than the limits", func(t *testing.T) {
+		result, err := s.handleCaptureScreen(context.Background(), mcptest.NewCallToolRequest("captureScreen", map[string]interface{}{
+			"maxWidth": 100,
+		}))
+		require.NoError(t, err)
+		img, _ := decodeResult(t, result)
+		assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+	})
+
+	t.Run("larger than the limits", func(t *testing.T) {
+		result, err := s.handleCaptureScreen(context.Background(), mcptest.NewCallToolRequest("captureScreen", map[string]interface{}{
+			"maxWidth": 4000,
+		}))
+		require.NoError(t, err)
+		img, _ := decodeResult(t, result)
+		assert.Equal(t, 200, img.Bounds().Dx(), "The server limit should apply")
+	})
+
+	t.Run("window", func(t *testing.T) {
+		result, err := s.handleCaptureWindow(context.Background(), mcptest.NewCallToolRequest("captureWindow", map[string]interface{}{
+			"window": "Terminal",
+		}))
+		require.NoError(t, err)
+		_, text := decodeResult(t, result)
+		assert.Contains(t, text, "Captured window Terminal")
+		assert.Equal(t, "Terminal", c.last.Window)
+	})
+
+	t.Run("region", func(t *testing.T) {
+		result, err := s.handleCaptureRegion(context.Background(), mcptest.NewCallToolRequest("captureRegion", map[string]interface{}{
+			"x": -100, "y": 20, "width": 400, "height": 200,
+		}))
+		require.NoError(t, err)
+		_, text := decodeResult(t, result)
+		assert.Contains(t, text, "Captured region 400x200 at -100,20")
+		assert.Equal(t, &region{X: -100, Y: 20, Width: 400, Height: 200}, c.last.Region)
+	})
+
+	errorTests := []struct {
+		name    string
+		call    func() error
+		wantErr string
+	}{
+		{"missing window", func() error {
+			_, err := s.handleCaptureWindow(context.Background(), mcptest.NewCallToolRequest("captureWindow", map[string]interface{}{}))
+			return err
+		}, "window is required"},
+		{"empty region", func() error {
+			_, err := s.handleCaptureRegion(context.Background(), mcptest.NewCallToolRequest("captureRegion", map[string]interface{}{
+				"x": 0, "y": 0, "width": 0, "height": 10,
+			}))
+			return err
+		}, "must be positive"},
+		{"backend error", func() error {
+			failing := NewScreenshotServer(&fakeCapturer{err: errors.New("permission denied")}, 200, 200, 1<<20, 5)
+			_, err := failing.handleCaptureScreen(context.Background(), mcptest.NewCallToolRequest("captureScreen", nil))
+			return err
+		}, "failed to capture screen: permission denied"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.call(), tt.wantErr)
+		})
+	}
+}
+
+// Test that images are shrunk until they fit the size limit
+func TestCaptureSizeLimit(t *testing.T) {
+	s := NewScreenshotServer(&fakeCapturer{width: 300, height: 300, noise: true}, 1000, 1000, 60000, 5)
+
+	result, err := s.handleCaptureScreen(context.Background(), mcptest.NewCallToolRequest("captureScreen", nil))
+	require.NoError(t, err)
+	img, text := decodeResult(t, result)
+	assert.Less(t, img.Bounds().Dx(), 300)
+	assert.Contains(t, text, "scaled to")
+
+	data, _ := base64.StdEncoding.DecodeString(result.Content[0].(mcp.ImageContent).Data)
+	assert.LessOrEqual(t, len(data), 60000)
+}
+
+// Test window ID and title handling helpers
+func TestWindowHelpers(t *testing.T) {
+	assert.True(t, isWindowID("12345"))
+	assert.True(t, isWindowID("0x1a00003"))
+	assert.False(t, isWindowID("abc"))
+	assert.False(t, isWindowID("Firefox"))
+	assert.Equal(t, `Report \(draft\)\.txt`, regexpQuote("Report (draft).txt"))
+
+	err := screencaptureBackend{}.Capture(context.Background(), target{Window: "Safari"}, "out.png")
+	assert.ErrorContains(t, err, "numeric window ID")
+	_, err = detectCapturer("scrot")
+	assert.ErrorContains(t, err, "unknown backend")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewScreenshotServer(&fakeCapturer{}, 800, 600, 1024, 5).Server())
+}
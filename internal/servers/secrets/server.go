@@ -0,0 +1,658 @@
+package secrets
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+//go:embed wordlist.txt
+var defaultWordlist string
+
+const (
+	lowerChars     = "abcdefghijklmnopqrstuvwxyz"
+	upperChars     = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars     = "0123456789"
+	symbolChars    = "!@#$%^&*()-_=+[]{};:,.<>?/~"
+	ambiguousChars = "Il1O0o|`'\""
+	base62Chars    = digitChars + upperChars + lowerChars
+)
+
+// SecretsServer is an MCP server that generates passwords, passphrases and tokens locally.
+// Generated secrets and checked passwords are never written to the log.
+type SecretsServer struct {
+	server    *server.MCPServer
+	words     []string
+	maxLength int
+	maxCount  int
+}
+
+// NewSecretsServer creates a new SecretsServer instance
+func NewSecretsServer(words []string, maxLength, maxCount int) *SecretsServer {
+	log.Printf("SecretsServer created: words=%d, maxLength=%d, maxCount=%d", len(words), maxLength, maxCount)
+
+	s := &SecretsServer{
+		words:     words,
+		maxLength: maxLength,
+		maxCount:  maxCount,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"secrets-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register generatePassword tool
+	passwordTool := mcp.NewTool("generatePassword",
+		mcp.WithDescription("Generates random passwords using a cryptographically secure source"),
+		mcp.WithNumber("length",
+			mcp.Description("Password length (default: 20)"),
+		),
+		mcp.WithNumber("minEntropy",
+			mcp.Description("Minimum entropy in bits; the length is increased until it is reached"),
+		),
+		mcp.WithBoolean("lowercase",
+			mcp.Description("Include lowercase letters (default: true)"),
+		),
+		mcp.WithBoolean("uppercase",
+			mcp.Description("Include uppercase letters (default: true)"),
+		),
+		mcp.WithBoolean("digits",
+			mcp.Description("Include digits (default: true)"),
+		),
+		mcp.WithBoolean("symbols",
+			mcp.Description("Include symbols (default: true)"),
+		),
+		mcp.WithBoolean("excludeAmbiguous",
+			mcp.Description("Exclude look-alike characters such as I, l, 1, O and 0"),
+		),
+		mcp.WithString("charset",
+			mcp.Description("Custom character set, overriding the class options"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of passwords to generate (default: 1)"),
+		),
+	)
+
+	// Register generatePassphrase tool
+	passphraseTool := mcp.NewTool("generatePassphrase",
+		mcp.WithDescription("Generates diceware-style passphrases from a wordlist"),
+		mcp.WithNumber("words",
+			mcp.Description("Number of words (default: 6)"),
+		),
+		mcp.WithString("separator",
+			mcp.Description("Separator between words (default: -)"),
+		),
+		mcp.WithBoolean("capitalize",
+			mcp.Description("Capitalize each word"),
+		),
+		mcp.WithBoolean("includeNumber",
+			mcp.Description("Append a random digit to one random word"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of passphrases to generate (default: 1)"),
+		),
+	)
+
+	// Register generateToken tool
+	tokenTool := mcp.NewTool("generateToken",
+		mcp.WithDescription("Generates random API-key-style tokens"),
+		mcp.WithNumber("bytes",
+			mcp.Description("Random bytes of entropy (default: 32)"),
+		),
+		mcp.WithString("encoding",
+			mcp.Description("Token encoding (default: base62)"),
+			mcp.Enum("base62", "hex", "base64url", "base32"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Optional prefix such as sk_live_"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of tokens to generate (default: 1)"),
+		),
+	)
+
+	// Register checkPasswordStrength tool
+	strengthTool := mcp.NewTool("checkPasswordStrength",
+		mcp.WithDescription("Estimates the strength of a password, accounting for dictionary words, common passwords, repeats and sequences"),
+		mcp.WithString("password",
+			mcp.Description("Password to check"),
+			mcp.Required(),
+		),
+	)
+
+	middleware.AddTool(mcpServer, passwordTool, s.handleGeneratePassword)
+	middleware.AddTool(mcpServer, passphraseTool, s.handleGeneratePassphrase)
+	middleware.AddTool(mcpServer, tokenTool, s.handleGenerateToken)
+	middleware.AddTool(mcpServer, strengthTool, s.handleCheckPasswordStrength)
+
+	s.server = mcpServer
+	return s
+}
+
+// randomInt returns a uniformly distributed integer in [0, n).
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random data: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// uniqueRunes removes duplicate characters, keeping the first occurrence.
+func uniqueRunes(s string) []rune {
+	seen := make(map[rune]bool)
+	var result []rune
+	for _, r := range s {
+		if !seen[r] {
+			seen[r] = true
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// removeChars returns s without any of the characters in remove.
+func removeChars(s, remove string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(remove, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// generatePassword builds a password of the given length from the classes. When more than one
+// class is used, every class is guaranteed to appear at least once.
+func generatePassword(length int, classes [][]rune) (string, error) {
+	var pool []rune
+	for _, class := range classes {
+		pool = append(pool, class...)
+	}
+
+	password := make([]rune, length)
+	for i := range password {
+		n, err := randomInt(len(pool))
+		if err != nil {
+			return "", err
+		}
+		password[i] = pool[n]
+	}
+
+	if len(classes) > 1 && length >= len(classes) {
+		// Place one character of each class at distinct random positions
+		positions := make([]int, length)
+		for i := range positions {
+			positions[i] = i
+		}
+		for i, class := range classes {
+			j, err := randomInt(length - i)
+			if err != nil {
+				return "", err
+			}
+			positions[i], positions[i+j] = positions[i+j], positions[i]
+			n, err := randomInt(len(class))
+			if err != nil {
+				return "", err
+			}
+			password[positions[i]] = class[n]
+		}
+	}
+	return string(password), nil
+}
+
+// handleGeneratePassword handles the password generation request.
+func (s *SecretsServer) handleGeneratePassword(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generatePassword request processing")
+
+	args := struct {
+		Length           int     `json:"length,omitempty"`
+		MinEntropy       float64 `json:"minEntropy,omitempty"`
+		Lowercase        *bool   `json:"lowercase,omitempty"`
+		Uppercase        *bool   `json:"uppercase,omitempty"`
+		Digits           *bool   `json:"digits,omitempty"`
+		Symbols          *bool   `json:"symbols,omitempty"`
+		ExcludeAmbiguous bool    `json:"excludeAmbiguous,omitempty"`
+		Charset          string  `json:"charset,omitempty"`
+		Count            int     `json:"count,omitempty"`
+	}{}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	enabled := func(b *bool) bool { return b == nil || *b }
+
+	var classes [][]rune
+	if args.Charset != "" {
+		charset := args.Charset
+		if args.ExcludeAmbiguous {
+			charset = removeChars(charset, ambiguousChars)
+		}
+		if class := uniqueRunes(charset); len(class) > 0 {
+			classes = append(classes, class)
+		}
+	} else {
+		for _, class := range []struct {
+			on    bool
+			chars string
+		}{
+			{enabled(args.Lowercase), lowerChars},
+			{enabled(args.Uppercase), upperChars},
+			{enabled(args.Digits), digitChars},
+			{enabled(args.Symbols), symbolChars},
+		} {
+			if !class.on {
+				continue
+			}
+			chars := class.chars
+			if args.ExcludeAmbiguous {
+				chars = removeChars(chars, ambiguousChars)
+			}
+			classes = append(classes, []rune(chars))
+		}
+	}
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("the character set is empty")
+	}
+
+	poolSize := 0
+	for _, class := range classes {
+		poolSize += len(class)
+	}
+	if poolSize < 2 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "the character set must contain at least 2 distinct characters")
+	}
+	bitsPerChar := math.Log2(float64(poolSize))
+
+	if args.Length <= 0 {
+		args.Length = 20
+	}
+	if args.MinEntropy > 0 {
+		args.Length = max(args.Length, int(math.Ceil(args.MinEntropy/bitsPerChar)))
+	}
+	if args.Length > s.maxLength {
+		return nil, fmt.Errorf("length %d exceeds the maximum of %d", args.Length, s.maxLength)
+	}
+	count, err := s.count(args.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	for i := 0; i < count; i++ {
+		password, err := generatePassword(args.Length, classes)
+		if err != nil {
+			return nil, err
+		}
+		resultContent.WriteString(password)
+		resultContent.WriteString("\n")
+	}
+	resultContent.WriteString(fmt.Sprintf("\nLength: %d, character pool: %d, entropy: ~%.0f bits each\n",
+		args.Length, poolSize, float64(args.Length)*bitsPerChar))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Printf("generatePassword request completed: count=%d, length=%d", count, args.Length)
+	return result, nil
+}
+
+// handleGeneratePassphrase handles the passphrase generation request.
+func (s *SecretsServer) handleGeneratePassphrase(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generatePassphrase request processing")
+
+	args := struct {
+		Words         int     `json:"words,omitempty"`
+		Separator     *string `json:"separator,omitempty"`
+		Capitalize    bool    `json:"capitalize,omitempty"`
+		IncludeNumber bool    `json:"includeNumber,omitempty"`
+		Count         int     `json:"count,omitempty"`
+	}{}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Words <= 0 {
+		args.Words = 6
+	}
+	if args.Words > 64 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "words must be at most 64")
+	}
+	separator := "-"
+	if args.Separator != nil {
+		separator = *args.Separator
+	}
+	count, err := s.count(args.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	entropy := float64(args.Words) * math.Log2(float64(len(s.words)))
+	if args.IncludeNumber {
+		entropy += math.Log2(10) + math.Log2(float64(args.Words))
+	}
+
+	var resultContent strings.Builder
+	for i := 0; i < count; i++ {
+		words := make([]string, args.Words)
+		for j := range words {
+			n, err := randomInt(len(s.words))
+			if err != nil {
+				return nil, err
+			}
+			words[j] = s.words[n]
+			if args.Capitalize {
+				words[j] = strings.ToUpper(words[j][:1]) + words[j][1:]
+			}
+		}
+		if args.IncludeNumber {
+			j, err := randomInt(len(words))
+			if err != nil {
+				return nil, err
+			}
+			digit, err := randomInt(10)
+			if err != nil {
+				return nil, err
+			}
+			words[j] += fmt.Sprint(digit)
+		}
+		resultContent.WriteString(strings.Join(words, separator))
+		resultContent.WriteString("\n")
+	}
+	resultContent.WriteString(fmt.Sprintf("\nWords: %d from a list of %d, entropy: ~%.0f bits each\n", args.Words, len(s.words), entropy))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Printf("generatePassphrase request completed: count=%d, words=%d", count, args.Words)
+	return result, nil
+}
+
+// encodeBase62 encodes bytes as a base62 string of fixed length for the input size.
+func encodeBase62(data []byte) string {
+	length := int(math.Ceil(float64(len(data)*8) / math.Log2(62)))
+	n := new(big.Int).SetBytes(data)
+	base := big.NewInt(62)
+	mod := new(big.Int)
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62Chars[mod.Int64()]
+	}
+	return string(out)
+}
+
+// handleGenerateToken handles the token generation request.
+func (s *SecretsServer) handleGenerateToken(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting generateToken request processing")
+
+	var args struct {
+		Bytes    int    `json:"bytes,omitempty"`
+		Encoding string `json:"encoding,omitempty"`
+		Prefix   string `json:"prefix,omitempty"`
+		Count    int    `json:"count,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Bytes <= 0 {
+		args.Bytes = 32
+	}
+	if args.Bytes > s.maxLength {
+		return nil, fmt.Errorf("bytes %d exceeds the maximum of %d", args.Bytes, s.maxLength)
+	}
+	if args.Encoding == "" {
+		args.Encoding = "base62"
+	}
+	count, err := s.count(args.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	var encode func([]byte) string
+	switch args.Encoding {
+	case "base62":
+		encode = encodeBase62
+	case "hex":
+		encode = hex.EncodeToString
+	case "base64url":
+		encode = base64.RawURLEncoding.EncodeToString
+	case "base32":
+		encode = func(b []byte) string {
+			return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+		}
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported encoding: %s", args.Encoding)
+	}
+
+	var resultContent strings.Builder
+	buf := make([]byte, args.Bytes)
+	for i := 0; i < count; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			log.Printf("Error: Failed to read random data: %v", err)
+			return nil, fmt.Errorf("failed to read random data: %w", err)
+		}
+		resultContent.WriteString(args.Prefix + encode(buf))
+		resultContent.WriteString("\n")
+	}
+	resultContent.WriteString(fmt.Sprintf("\nEncoding: %s, entropy: %d bits each\n", args.Encoding, args.Bytes*8))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Printf("generateToken request completed: count=%d, bytes=%d", count, args.Bytes)
+	return result, nil
+}
+
+// handleCheckPasswordStrength handles the password strength request.
+func (s *SecretsServer) handleCheckPasswordStrength(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting checkPasswordStrength request processing")
+
+	var args struct {
+		Password string `json:"password"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Password == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "password is required")
+	}
+	if len(args.Password) > s.maxLength {
+		return nil, fmt.Errorf("password exceeds the maximum length of %d", s.maxLength)
+	}
+
+	report := estimateStrength(args.Password, s.words)
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Strength: %s (score %d/4)\n", report.Rating, report.Score))
+	resultContent.WriteString(fmt.Sprintf("Estimated entropy: ~%.0f bits\n", report.Entropy))
+	resultContent.WriteString(fmt.Sprintf("Time to crack (online, 10 guesses/s): %s\n", crackTime(report.Entropy, 10)))
+	resultContent.WriteString(fmt.Sprintf("Time to crack (offline fast hash, 10^10 guesses/s): %s\n", crackTime(report.Entropy, 1e10)))
+	if len(report.Warnings) > 0 {
+		resultContent.WriteString("\nWarnings:\n")
+		for _, w := range report.Warnings {
+			resultContent.WriteString("- " + w + "\n")
+		}
+	}
+	if len(report.Suggestions) > 0 {
+		resultContent.WriteString("\nSuggestions:\n")
+		for _, sg := range report.Suggestions {
+			resultContent.WriteString("- " + sg + "\n")
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("checkPasswordStrength request completed")
+	return result, nil
+}
+
+// count validates the requested number of generated values.
+func (s *SecretsServer) count(n int) (int, error) {
+	if n <= 0 {
+		return 1, nil
+	}
+	if n > s.maxCount {
+		return 0, fmt.Errorf("count %d exceeds the maximum of %d", n, s.maxCount)
+	}
+	return n, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *SecretsServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// parseWordlist reads one word per line. Diceware files with a leading dice roll column
+// ("11111<TAB>word") are supported by taking the last field of each line.
+func parseWordlist(data string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// New creates the secrets server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("secrets", flag.ContinueOnError)
+	var (
+		wordlistPath string
+		maxLength    int
+		maxCount     int
+	)
+	fs.StringVar(&wordlistPath, "wordlist", "", "Diceware wordlist file (e.g. the EFF large wordlist); the built-in 1296 word list is used when empty")
+	fs.IntVar(&maxLength, "max-length", 1024, "Maximum password length and token size in bytes")
+	fs.IntVar(&maxCount, "max-count", 50, "Maximum number of values generated per request")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("wordlist", "SECRETS_WORDLIST"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	words := parseWordlist(defaultWordlist)
+	if wordlistPath != "" {
+		data, err := os.ReadFile(wordlistPath)
+		if err != nil {
+			log.Printf("Error: Failed to read wordlist: %v", err)
+			return nil, transport.Flags{}, err
+		}
+		words = parseWordlist(string(data))
+		if len(words) < 1000 {
+			log.Printf("Error: Wordlist %s has only %d unique words, at least 1000 are required", wordlistPath, len(words))
+			return nil, transport.Flags{}, fmt.Errorf("wordlist %s has only %d unique words", wordlistPath, len(words))
+		}
+	}
+
+	log.Printf("Starting Secrets server: words=%d", len(words))
+
+	// Create SecretsServer instance
+	secretsServer := NewSecretsServer(words, maxLength, maxCount)
+	log.Println("SecretsServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), secretsServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return secretsServer.Server(), transportFlags, nil
+}
+
+// Run starts the secrets server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[SecretsServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create secrets server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("SecretsServer shutdown")
+	return nil
+}
@@ -0,0 +1,225 @@
+package secrets
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer() *SecretsServer {
+	return NewSecretsServer(parseWordlist(defaultWordlist), 1024, 50)
+}
+
+// SecretsServer creation test
+func TestNewSecretsServer(t *testing.T) {
+	s := newTestServer()
+
+	assert.NotNil(t, s, "SecretsServer instance should be created")
+	assert.Len(t, s.words, 1296, "The built-in wordlist should map onto four dice")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := newTestServer()
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test diceware wordlist parsing
+func TestParseWordlist(t *testing.T) {
+	words := parseWordlist("# EFF list\n11111\tabacus\n11112\tabdomen\n\n11113 abacus\nzebra\n")
+	assert.Equal(t, []string{"abacus", "abdomen", "zebra"}, words)
+}
+
+func resultLines(t *testing.T, result *mcp.CallToolResult) []string {
+	text := result.Content[0].(mcp.TextContent).Text
+	return strings.Split(strings.SplitN(text, "\n\n", 2)[0], "\n")
+}
+
+// Test password generation
+func TestGeneratePassword(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	t.Run("Default password includes every class", func(t *testing.T) {
+		result, err := s.handleGeneratePassword(ctx, mcptest.NewCallToolRequest("generatePassword", map[string]interface{}{
+			"count": 20,
+		}))
+
+		assert.NoError(t, err)
+		lines := resultLines(t, result)
+		assert.Len(t, lines, 20)
+		for _, pw := range lines {
+			assert.Len(t, pw, 20)
+			assert.Regexp(t, `[a-z]`, pw)
+			assert.Regexp(t, `[A-Z]`, pw)
+			assert.Regexp(t, `[0-9]`, pw)
+			assert.Regexp(t, `[^a-zA-Z0-9]`, pw)
+		}
+	})
+
+	t.Run("Digits only without ambiguous characters", func(t *testing.T) {
+		result, err := s.handleGeneratePassword(ctx, mcptest.NewCallToolRequest("generatePassword", map[string]interface{}{
+			"length":           12,
+			"lowercase":        false,
+			"uppercase":        false,
+			"symbols":          false,
+			"excludeAmbiguous": true,
+		}))
+
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[2-9]{12}$`, resultLines(t, result)[0])
+	})
+
+	t.Run("Minimum entropy extends length", func(t *testing.T) {
+		result, err := s.handleGeneratePassword(ctx, mcptest.NewCallToolRequest("generatePassword", map[string]interface{}{
+			"charset":    "ab",
+			"length":     8,
+			"minEntropy": 64,
+		}))
+
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[ab]{64}$`, resultLines(t, result)[0])
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "entropy: ~64 bits")
+	})
+
+	t.Run("Empty character set", func(t *testing.T) {
+		_, err := s.handleGeneratePassword(ctx, mcptest.NewCallToolRequest("generatePassword", map[string]interface{}{
+			"lowercase": false,
+			"uppercase": false,
+			"digits":    false,
+			"symbols":   false,
+		}))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Count limit", func(t *testing.T) {
+		_, err := s.handleGeneratePassword(ctx, mcptest.NewCallToolRequest("generatePassword", map[string]interface{}{
+			"count": 51,
+		}))
+
+		assert.Error(t, err)
+	})
+}
+
+// Test passphrase generation
+func TestGeneratePassphrase(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	result, err := s.handleGeneratePassphrase(ctx, mcptest.NewCallToolRequest("generatePassphrase", map[string]interface{}{
+		"words":         5,
+		"separator":     " ",
+		"capitalize":    true,
+		"includeNumber": true,
+	}))
+
+	assert.NoError(t, err)
+	words := strings.Split(resultLines(t, result)[0], " ")
+	assert.Len(t, words, 5)
+	digits := 0
+	for _, w := range words {
+		assert.Regexp(t, `^[A-Z][a-z]+[0-9]?$`, w)
+		if regexp.MustCompile(`[0-9]$`).MatchString(w) {
+			digits++
+		}
+	}
+	assert.Equal(t, 1, digits, "Exactly one word should carry a digit")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "from a list of 1296")
+}
+
+// Test token generation
+func TestGenerateToken(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+
+	testCases := []struct {
+		encoding string
+		pattern  string
+	}{
+		{"base62", `^sk_[0-9A-Za-z]{43}$`},
+		{"hex", `^sk_[0-9a-f]{64}$`},
+		{"base64url", `^sk_[0-9A-Za-z_-]{43}$`},
+		{"base32", `^sk_[a-z2-7]{52}$`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.encoding, func(t *testing.T) {
+			result, err := s.handleGenerateToken(ctx, mcptest.NewCallToolRequest("generateToken", map[string]interface{}{
+				"encoding": tc.encoding,
+				"prefix":   "sk_",
+				"count":    2,
+			}))
+
+			assert.NoError(t, err)
+			lines := resultLines(t, result)
+			assert.Len(t, lines, 2)
+			assert.Regexp(t, tc.pattern, lines[0])
+			assert.NotEqual(t, lines[0], lines[1])
+		})
+	}
+
+	assert.Equal(t, strings.Repeat("0", 22), encodeBase62(make([]byte, 16)), "Base62 output should be zero padded to a fixed width")
+	assert.Equal(t, "00z", encodeBase62([]byte{0, 61}))
+}
+
+// Test password strength estimation
+func TestPasswordStrength(t *testing.T) {
+	words := parseWordlist(defaultWordlist)
+
+	testCases := []struct {
+		name     string
+		password string
+		maxScore int
+		minScore int
+		warning  string
+	}{
+		{name: "Common password", password: "Password123", maxScore: 0, warning: "most common"},
+		{name: "Keyboard walk", password: "asdfghjkl9", maxScore: 1, warning: "sequences"},
+		{name: "Sequence", password: "abcdefgh1234", maxScore: 1, warning: "sequences"},
+		{name: "Dictionary words", password: "tigerpiano", maxScore: 1, warning: "dictionary"},
+		{name: "Random characters", password: "x7#Qp2!vR9zL@m4K", minScore: 4},
+		{name: "Passphrase", password: "correct-horse-battery-staple-otter-ninja", minScore: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := estimateStrength(tc.password, words)
+			assert.GreaterOrEqual(t, report.Score, tc.minScore)
+			if tc.maxScore > 0 || tc.minScore == 0 {
+				assert.LessOrEqual(t, report.Score, tc.maxScore)
+			}
+			if tc.warning != "" {
+				assert.Contains(t, strings.Join(report.Warnings, "\n"), tc.warning)
+			}
+		})
+	}
+
+	s := newTestServer()
+	result, err := s.handleCheckPasswordStrength(context.Background(), mcptest.NewCallToolRequest("checkPasswordStrength", map[string]interface{}{
+		"password": "letmein",
+	}))
+	assert.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Strength: very weak")
+	assert.Contains(t, text, "Time to crack (online, 10 guesses/s):")
+	assert.NotContains(t, text, "letmein", "The checked password should not be echoed")
+}
+
+// Test crack time formatting
+func TestCrackTime(t *testing.T) {
+	assert.Equal(t, "instant", crackTime(10, 1e10))
+	assert.Equal(t, "more than a million centuries", crackTime(256, 1e10))
+	assert.Equal(t, "2 days", crackTime(22, 10))
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, newTestServer().Server())
+}
@@ -0,0 +1,225 @@
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// commonPasswords are among the most frequently leaked passwords. A password equal to one
+// of these, ignoring case and trailing digits or symbols, is trivially guessable.
+var commonPasswords = []string{
+	"password", "123456", "12345678", "qwerty", "abc123", "letmein", "monkey", "dragon",
+	"iloveyou", "admin", "welcome", "login", "princess", "football", "baseball", "master",
+	"sunshine", "shadow", "superman", "trustno1", "passw0rd", "starwars", "whatever",
+	"qwertyuiop", "123123", "111111", "654321", "secret", "freedom", "hello", "charlie",
+	"michael", "jordan", "hunter", "ninja", "mustang", "access", "batman", "changeme",
+}
+
+// keyboardRows are used to detect keyboard walks such as "qwerty" or "asdf".
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890"}
+
+// StrengthReport is the result of a password strength estimate
+type StrengthReport struct {
+	Entropy     float64
+	Score       int
+	Rating      string
+	Warnings    []string
+	Suggestions []string
+}
+
+// poolSize returns the size of the character pool implied by the classes used in password.
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			size += c.size
+		}
+	}
+	return size
+}
+
+// sequenceLength returns the length of the repeat, alphabetical/numeric sequence or keyboard
+// walk starting at runes[i], or 1 when there is none.
+func sequenceLength(runes []rune, i int) int {
+	if i+1 >= len(runes) {
+		return 1
+	}
+
+	// Repeated characters: aaaa
+	n := 1
+	for i+n < len(runes) && runes[i+n] == runes[i] {
+		n++
+	}
+	if n >= 3 {
+		return n
+	}
+
+	// Ascending or descending by one: abcd, 4321
+	step := runes[i+1] - runes[i]
+	if step == 1 || step == -1 {
+		n = 2
+		for i+n < len(runes) && runes[i+n]-runes[i+n-1] == step {
+			n++
+		}
+		if n >= 3 {
+			return n
+		}
+	}
+
+	// Keyboard walk: qwer, asdf
+	for _, row := range keyboardRows {
+		start := strings.IndexRune(row, runes[i])
+		if start < 0 {
+			continue
+		}
+		n = 1
+		for i+n < len(runes) && start+n < len(row) && rune(row[start+n]) == runes[i+n] {
+			n++
+		}
+		if n >= 4 {
+			return n
+		}
+	}
+	return 1
+}
+
+// longestWord returns the longest wordlist word of at least 4 letters starting at runes[i].
+func longestWord(runes []rune, i int, dictionary map[string]bool, maxWordLen int) int {
+	for n := min(maxWordLen, len(runes)-i); n >= 4; n-- {
+		if dictionary[string(runes[i:i+n])] {
+			return n
+		}
+	}
+	return 0
+}
+
+// estimateStrength estimates the entropy of a password by splitting it into dictionary words,
+// sequences and random characters, charging each segment the bits an informed attacker needs.
+func estimateStrength(password string, words []string) StrengthReport {
+	report := StrengthReport{}
+	lower := strings.ToLower(password)
+
+	base := strings.TrimRightFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	if base == "" {
+		base = lower
+	}
+	for _, common := range commonPasswords {
+		if lower == common || base == common {
+			report.Entropy = math.Log2(float64(len(commonPasswords))) + float64(len(lower)-len(base))*math.Log2(10)
+			report.Warnings = append(report.Warnings, "This is one of the most common passwords")
+			break
+		}
+	}
+
+	if report.Entropy == 0 {
+		dictionary := make(map[string]bool, len(words)+len(commonPasswords))
+		maxWordLen := 0
+		for _, list := range [][]string{words, commonPasswords} {
+			for _, w := range list {
+				dictionary[w] = true
+				maxWordLen = max(maxWordLen, len([]rune(w)))
+			}
+		}
+
+		bitsPerChar := math.Log2(float64(max(poolSize(password), 2)))
+		runes := []rune(lower)
+		foundWords, foundSequences := 0, 0
+		for i := 0; i < len(runes); {
+			if n := longestWord(runes, i, dictionary, maxWordLen); n > 0 {
+				// Word choice plus a bit for capitalization
+				report.Entropy += math.Log2(float64(len(dictionary))) + 1
+				foundWords++
+				i += n
+				continue
+			}
+			if n := sequenceLength(runes, i); n > 1 {
+				// Start character plus direction and length
+				report.Entropy += bitsPerChar + math.Log2(float64(n)) + 1
+				foundSequences++
+				i += n
+				continue
+			}
+			report.Entropy += bitsPerChar
+			i++
+		}
+
+		if foundWords > 0 && foundWords < 4 && report.Entropy < 60 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Contains %d dictionary word(s), which attackers try first", foundWords))
+		}
+		if foundSequences > 0 {
+			report.Warnings = append(report.Warnings, "Contains repeated characters, sequences or keyboard patterns")
+		}
+	}
+
+	switch {
+	case report.Entropy < 28:
+		report.Score, report.Rating = 0, "very weak"
+	case report.Entropy < 36:
+		report.Score, report.Rating = 1, "weak"
+	case report.Entropy < 60:
+		report.Score, report.Rating = 2, "fair"
+	case report.Entropy < 80:
+		report.Score, report.Rating = 3, "strong"
+	default:
+		report.Score, report.Rating = 4, "very strong"
+	}
+
+	if report.Score < 3 {
+		if len([]rune(password)) < 12 {
+			report.Suggestions = append(report.Suggestions, "Use at least 12 characters")
+		}
+		if poolSize(password) < 62 {
+			report.Suggestions = append(report.Suggestions, "Mix lowercase, uppercase, digits and symbols")
+		}
+		report.Suggestions = append(report.Suggestions, "Consider a generated passphrase of 5 or more random words")
+	}
+	return report
+}
+
+// crackTime formats the average time to guess a password of the given entropy.
+func crackTime(entropy, guessesPerSecond float64) string {
+	seconds := math.Pow(2, entropy-1) / guessesPerSecond
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"centuries", 100 * 365.25 * 86400},
+		{"years", 365.25 * 86400},
+		{"days", 86400},
+		{"hours", 3600},
+		{"minutes", 60},
+		{"seconds", 1},
+	}
+	if seconds < 1 {
+		return "instant"
+	}
+	if seconds > 1e6*units[0].seconds {
+		return "more than a million centuries"
+	}
+	for _, u := range units {
+		if seconds >= u.seconds {
+			return fmt.Sprintf("%.0f %s", seconds/u.seconds, u.name)
+		}
+	}
+	return "instant"
+}
@@ -0,0 +1,119 @@
+// Package servers lists the MCP servers bundled with mcphost, along with those
+// registered by other packages through pkg/mcpserver.
+package servers
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/servers/archive"
+	"github.com/mark3labs/mcphost/internal/servers/clipboard"
+	"github.com/mark3labs/mcphost/internal/servers/codesearch"
+	"github.com/mark3labs/mcphost/internal/servers/crawler"
+	"github.com/mark3labs/mcphost/internal/servers/crypto"
+	"github.com/mark3labs/mcphost/internal/servers/dataformat"
+	"github.com/mark3labs/mcphost/internal/servers/diff"
+	"github.com/mark3labs/mcphost/internal/servers/discord"
+	"github.com/mark3labs/mcphost/internal/servers/fakedata"
+	"github.com/mark3labs/mcphost/internal/servers/fetch"
+	"github.com/mark3labs/mcphost/internal/servers/filetransfer"
+	"github.com/mark3labs/mcphost/internal/servers/geocoding"
+	"github.com/mark3labs/mcphost/internal/servers/geoip"
+	"github.com/mark3labs/mcphost/internal/servers/googlesearch"
+	"github.com/mark3labs/mcphost/internal/servers/hackernews"
+	"github.com/mark3labs/mcphost/internal/servers/homeassistant"
+	"github.com/mark3labs/mcphost/internal/servers/httpmock"
+	"github.com/mark3labs/mcphost/internal/servers/identifiers"
+	"github.com/mark3labs/mcphost/internal/servers/markdown"
+	"github.com/mark3labs/mcphost/internal/servers/notes"
+	"github.com/mark3labs/mcphost/internal/servers/openapi"
+	"github.com/mark3labs/mcphost/internal/servers/papers"
+	"github.com/mark3labs/mcphost/internal/servers/process"
+	"github.com/mark3labs/mcphost/internal/servers/qrcode"
+	"github.com/mark3labs/mcphost/internal/servers/reddit"
+	"github.com/mark3labs/mcphost/internal/servers/regex"
+	"github.com/mark3labs/mcphost/internal/servers/scheduler"
+	"github.com/mark3labs/mcphost/internal/servers/screenshot"
+	"github.com/mark3labs/mcphost/internal/servers/secrets"
+	"github.com/mark3labs/mcphost/internal/servers/spotify"
+	"github.com/mark3labs/mcphost/internal/servers/spreadsheet"
+	"github.com/mark3labs/mcphost/internal/servers/sysinfo"
+	"github.com/mark3labs/mcphost/internal/servers/telegram"
+	"github.com/mark3labs/mcphost/internal/servers/textanalysis"
+	"github.com/mark3labs/mcphost/internal/servers/timeserver"
+	"github.com/mark3labs/mcphost/internal/servers/webhook"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/pkg/mcpserver"
+)
+
+// Server is a bundled MCP server. New creates the server from its command line flags,
+// for running it in process, and Run also serves it. The background work of a server
+// created by New, such as scheduled jobs, stops when ctx is done.
+type Server struct {
+	Name        string
+	Description string
+	New         func(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error)
+	Run         func(args []string) error
+}
+
+// All lists the bundled servers by name.
+var All = []Server{
+	{"archive", "List, extract and create zip, tar and gzip archives", archive.New, archive.Run},
+	{"clipboard", "Read and write the system clipboard", clipboard.New, clipboard.Run},
+	{"codesearch", "Search the files of local project roots", codesearch.New, codesearch.Run},
+	{"crawler", "Crawl websites and summarize their content", crawler.New, crawler.Run},
+	{"crypto", "Hashing, encoding and token inspection utilities", crypto.New, crypto.Run},
+	{"dataformat", "Convert, format and validate structured data", dataformat.New, dataformat.Run},
+	{"diff", "Compute and apply unified diffs", diff.New, diff.Run},
+	{"discord", "Read and send messages in Discord channels through a bot", discord.New, discord.Run},
+	{"fakedata", "Generate fake but realistic test data", fakedata.New, fakedata.Run},
+	{"fetch", "Perform HTTP/HTTPS requests", fetch.New, fetch.Run},
+	{"filetransfer", "Transfer files to and from SFTP/FTP endpoints", filetransfer.New, filetransfer.Run},
+	{"geocoding", "Geocoding, distance and map tools", geocoding.New, geocoding.Run},
+	{"geoip", "Look up the location and network of IP addresses in local GeoIP databases", geoip.New, geoip.Run},
+	{"googlesearch", "Search the web with Google", googlesearch.New, googlesearch.Run},
+	{"hackernews", "Hacker News stories and discussions", hackernews.New, hackernews.Run},
+	{"homeassistant", "Control a Home Assistant instance", homeassistant.New, homeassistant.Run},
+	{"httpmock", "Serve mock HTTP endpoints and record the requests they receive", httpmock.New, httpmock.Run},
+	{"identifiers", "Generate and validate UUIDs, ULIDs, nanoids and snowflake IDs", identifiers.New, identifiers.Run},
+	{"markdown", "Render, convert and check Markdown", markdown.New, markdown.Run},
+	{"notes", "Keep notes and a todo list in a local JSON store", notes.New, notes.Run},
+	{"openapi", "Call the operations of OpenAPI and Swagger specs as tools", openapi.New, openapi.Run},
+	{"papers", "Search arXiv and Semantic Scholar", papers.New, papers.Run},
+	{"process", "List and inspect processes and signal allowlisted ones", process.New, process.Run},
+	{"qrcode", "Generate and read QR codes", qrcode.New, qrcode.Run},
+	{"reddit", "Browse and optionally post to Reddit", reddit.New, reddit.Run},
+	{"regex", "Test and explain regular expressions", regex.New, regex.Run},
+	{"scheduler", "Run one-shot and cron scheduled jobs", scheduler.New, scheduler.Run},
+	{"screenshot", "Capture the screen, windows and screen regions", screenshot.New, screenshot.Run},
+	{"secrets", "Generate passwords, passphrases and tokens locally", secrets.New, secrets.Run},
+	{"spotify", "Control Spotify playback through the Web API", spotify.New, spotify.Run},
+	{"spreadsheet", "Read, query and write CSV and XLSX files", spreadsheet.New, spreadsheet.Run},
+	{"sysinfo", "Report system information and resource usage", sysinfo.New, sysinfo.Run},
+	{"telegram", "Send and receive messages through a Telegram bot", telegram.New, telegram.Run},
+	{"textanalysis", "Count, analyze, summarize and chunk text locally", textanalysis.New, textanalysis.Run},
+	{"time", "Provide the current time", timeserver.New, timeserver.Run},
+	{"webhook", "Send notifications to Slack, Discord and other configured webhooks", webhook.New, webhook.Run},
+}
+
+// Lookup returns the bundled or registered server with the given name.
+func Lookup(name string) (Server, bool) {
+	if s, ok := bundled(name); ok {
+		return s, true
+	}
+	for _, srv := range mcpserver.Registered() {
+		if srv.Name() == name {
+			return fromRegistered(srv), true
+		}
+	}
+	return Server{}, false
+}
+
+func bundled(name string) (Server, bool) {
+	for _, s := range All {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Server{}, false
+}
@@ -0,0 +1,116 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/transport"
+	"github.com/mark3labs/mcphost/pkg/mcpserver"
+)
+
+func TestAll(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, s := range All {
+		assert.False(t, seen[s.Name], "duplicate server %s", s.Name)
+		seen[s.Name] = true
+		assert.NotEmpty(t, s.Description, s.Name)
+		assert.NotNil(t, s.New, s.Name)
+		assert.NotNil(t, s.Run, s.Name)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s, ok := Lookup("time")
+	assert.True(t, ok)
+	assert.Equal(t, "time", s.Name)
+
+	_, ok = Lookup("unknown")
+	assert.False(t, ok)
+}
+
+// Test that bad arguments are returned as errors instead of exiting the process, which
+// would take the proxy running the server down with it
+func TestNewRejectsUnknownFlags(t *testing.T) {
+	for _, s := range All {
+		_, _, err := s.New(context.Background(), []string{"-no-such-flag"})
+		assert.ErrorContains(t, err, "flag provided but not defined", s.Name)
+	}
+}
+
+// greeter is a server registered through pkg/mcpserver.
+type greeter struct {
+	greeting string
+}
+
+func (g *greeter) Name() string        { return "test-greeter" }
+func (g *greeter) Version() string     { return "0.1.0" }
+func (g *greeter) Description() string { return "Greet people" }
+
+func (g *greeter) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&g.greeting, "greeting", "Hello", "Greeting to use")
+}
+
+func (g *greeter) Register(s *server.MCPServer) error {
+	mcpserver.AddTool(s, mcp.NewTool("greet", mcp.WithString("name")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprintf("%s, %s!", g.greeting, req.Params.Arguments["name"])), nil
+	})
+	return nil
+}
+
+// Test that registered servers are listed and run like the bundled ones
+func TestRegisteredServers(t *testing.T) {
+	mcpserver.Register(&greeter{})
+	mcpserver.Register(namedServer("time"))
+
+	var names []string
+	for _, s := range List() {
+		names = append(names, s.Name)
+	}
+	assert.Contains(t, names, "test-greeter")
+	assert.Len(t, names, len(All)+1, "the registered time server is shadowed by the bundled one")
+	assert.IsIncreasing(t, names)
+
+	s, ok := Lookup("test-greeter")
+	require.True(t, ok)
+	assert.Equal(t, "Greet people", s.Description)
+	mcpServer, flags, err := s.New(context.Background(), []string{"-greeting", "Hi", "-transport", "sse"})
+	require.NoError(t, err)
+	assert.Equal(t, transport.SSE, flags.Transport)
+
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]interface{}{"name": "greet", "arguments": map[string]interface{}{"name": "Ada"}},
+	})
+	require.NoError(t, err)
+	response, err := json.Marshal(mcpServer.HandleMessage(context.Background(), message))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "Hi, Ada!")
+
+	_, _, err = s.New(context.Background(), []string{"-no-such-flag"})
+	assert.Error(t, err)
+}
+
+type namedServer string
+
+func (s namedServer) Name() string                     { return string(s) }
+func (s namedServer) Version() string                  { return "1.0.0" }
+func (s namedServer) Register(*server.MCPServer) error { return nil }
+
+// Test listing the tools of a server
+func TestTools(t *testing.T) {
+	s, ok := Lookup("time")
+	require.True(t, ok)
+	tools, err := Tools(s)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "getCurrentTime", tools[0].Name)
+	assert.Equal(t, "getServerInfo", tools[1].Name)
+}
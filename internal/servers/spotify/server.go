@@ -0,0 +1,783 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const (
+	defaultAPIURL      = "https://api.spotify.com/v1"
+	defaultAccountsURL = "https://accounts.spotify.com"
+)
+
+// Artist, Track and related types hold the subset of Spotify objects rendered by the tools
+type Artist struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+type Album struct {
+	Name    string   `json:"name"`
+	URI     string   `json:"uri"`
+	Artists []Artist `json:"artists"`
+}
+
+type Track struct {
+	Name       string   `json:"name"`
+	URI        string   `json:"uri"`
+	DurationMs int      `json:"duration_ms"`
+	Artists    []Artist `json:"artists"`
+	Album      Album    `json:"album"`
+}
+
+type Playlist struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	URI    string `json:"uri"`
+	Public bool   `json:"public"`
+	Tracks struct {
+		Total int `json:"total"`
+	} `json:"tracks"`
+	Owner struct {
+		DisplayName string `json:"display_name"`
+	} `json:"owner"`
+}
+
+// SpotifyServer is an MCP server that controls Spotify playback through the Web API.
+type SpotifyServer struct {
+	server       *server.MCPServer
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	apiURL       string
+	accountsURL  string
+	maxBodySize  int64
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+// NewSpotifyServer creates a new SpotifyServer instance.
+func NewSpotifyServer(clientID, clientSecret, refreshToken, apiURL, accountsURL string, timeout int, maxBodySize int64) *SpotifyServer {
+	log.Printf("SpotifyServer created: apiURL=%s, timeout=%ds", apiURL, timeout)
+
+	// Create HTTP client with configured timeout
+	client := httpclient.New(httpclient.Options{
+		Timeout:     time.Duration(timeout) * time.Second,
+		MaxBodySize: maxBodySize,
+	})
+
+	s := &SpotifyServer{
+		client:       client,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+		accountsURL:  strings.TrimSuffix(accountsURL, "/"),
+		maxBodySize:  maxBodySize,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"spotify-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register search tool
+	searchTool := mcp.NewTool("search",
+		mcp.WithDescription("Searches the Spotify catalog for tracks, albums, artists or playlists"),
+		mcp.WithString("query",
+			mcp.Description("Search query"),
+			mcp.Required(),
+		),
+		mcp.WithString("type",
+			mcp.Description("Item type to search for"),
+			mcp.Enum("track", "album", "artist", "playlist"),
+			mcp.DefaultString("track"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of results to return (1-50, default: 10)"),
+		),
+	)
+
+	// Register nowPlaying tool
+	nowPlayingTool := mcp.NewTool("nowPlaying",
+		mcp.WithDescription("Returns the track that is currently playing"),
+	)
+
+	// Register playback tool
+	playbackTool := mcp.NewTool("playback",
+		mcp.WithDescription("Controls playback on the active device: play, pause, next or previous"),
+		mcp.WithString("action",
+			mcp.Description("Playback action"),
+			mcp.Enum("play", "pause", "next", "previous"),
+			mcp.Required(),
+		),
+		mcp.WithString("uri",
+			mcp.Description("Optional Spotify URI to start playing with the 'play' action. Track URIs are played directly; album, artist and playlist URIs are played as a context"),
+		),
+	)
+
+	// Register listPlaylists tool
+	listPlaylistsTool := mcp.NewTool("listPlaylists",
+		mcp.WithDescription("Lists the current user's playlists"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of playlists to return (1-50, default: 20)"),
+		),
+	)
+
+	// Register createPlaylist tool
+	createPlaylistTool := mcp.NewTool("createPlaylist",
+		mcp.WithDescription("Creates a new playlist for the current user"),
+		mcp.WithString("name",
+			mcp.Description("Playlist name"),
+			mcp.Required(),
+		),
+		mcp.WithString("description",
+			mcp.Description("Playlist description"),
+		),
+		mcp.WithBoolean("public",
+			mcp.Description("Whether the playlist is public (default: false)"),
+		),
+	)
+
+	// Register modifyPlaylist tool
+	modifyPlaylistTool := mcp.NewTool("modifyPlaylist",
+		mcp.WithDescription("Adds tracks to or removes tracks from a playlist"),
+		mcp.WithString("playlistId",
+			mcp.Description("Playlist ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("action",
+			mcp.Description("Whether to add or remove the tracks"),
+			mcp.Enum("add", "remove"),
+			mcp.Required(),
+		),
+		mcp.WithString("uris",
+			mcp.Description("Comma separated track URIs (e.g. spotify:track:4uLU6hMCjMI75M1A2tKUQC)"),
+			mcp.Required(),
+		),
+	)
+
+	middleware.AddTool(mcpServer, searchTool, s.handleSearch)
+	middleware.AddTool(mcpServer, nowPlayingTool, s.handleNowPlaying)
+	middleware.AddTool(mcpServer, playbackTool, s.handlePlayback)
+	middleware.AddTool(mcpServer, listPlaylistsTool, s.handleListPlaylists)
+	middleware.AddTool(mcpServer, createPlaylistTool, s.handleCreatePlaylist)
+	middleware.AddTool(mcpServer, modifyPlaylistTool, s.handleModifyPlaylist)
+
+	s.server = mcpServer
+	return s
+}
+
+// token returns a valid access token, refreshing it when it is missing or about to expire.
+func (s *SpotifyServer) token(ctx context.Context, force bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !force && s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-30*time.Second)) {
+		return s.accessToken, nil
+	}
+	if s.clientID == "" || s.clientSecret == "" || s.refreshToken == "" {
+		return "", fmt.Errorf("Spotify credentials are not configured (client ID, client secret and refresh token are required)")
+	}
+
+	log.Println("Refreshing Spotify access token")
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", s.refreshToken)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.accountsURL+"/api/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	httpReq.SetBasicAuth(s.clientID, s.clientSecret)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Token refresh failed: %v", err)
+		return "", fmt.Errorf("token refresh failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error: Token endpoint returned status code %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("token refresh failed: %s (status code: %d)", strings.TrimSpace(string(body)), resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("token response did not contain an access token")
+	}
+
+	s.accessToken = tokenResp.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	// Spotify may rotate the refresh token
+	if tokenResp.RefreshToken != "" {
+		s.refreshToken = tokenResp.RefreshToken
+	}
+	return s.accessToken, nil
+}
+
+// doRequest performs an authenticated Web API request, retrying once with a fresh token on 401.
+// A nil body is returned for 204 No Content responses.
+func (s *SpotifyServer) doRequest(ctx context.Context, method, apiPath string, body interface{}) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		accessToken, err := s.token(ctx, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, method, s.apiURL+apiPath, bytes.NewReader(payload))
+		if err != nil {
+			log.Printf("Error: Failed to create request: %v", err)
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		log.Printf("Sending %s request to %s", method, apiPath)
+		resp, err := s.client.Do(httpReq)
+		if err != nil {
+			log.Printf("Error: Request failed: %v", err)
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		// Read response (with size limitation)
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBodySize))
+		resp.Body.Close()
+		if err != nil {
+			log.Printf("Error: Failed to read response body: %v", err)
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && attempt == 0:
+			log.Println("Access token rejected, refreshing and retrying")
+			continue
+		case resp.StatusCode == http.StatusNoContent:
+			return nil, nil
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return respBody, nil
+		}
+
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		message := strings.TrimSpace(string(respBody))
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Message != "" {
+			message = apiErr.Error.Message
+		}
+		log.Printf("Error: API returned status code %d: %s", resp.StatusCode, message)
+		return nil, fmt.Errorf("API error: %s (status code: %d)", message, resp.StatusCode)
+	}
+}
+
+// artistNames joins the names of artists.
+func artistNames(artists []Artist) string {
+	names := make([]string, len(artists))
+	for i, a := range artists {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatTrack renders a track for display.
+func formatTrack(t Track) string {
+	return fmt.Sprintf("%s - %s (%s) [%s]", t.Name, artistNames(t.Artists), t.Album.Name, t.URI)
+}
+
+// formatDuration renders milliseconds as m:ss.
+func formatDuration(ms int) string {
+	return fmt.Sprintf("%d:%02d", ms/60000, (ms/1000)%60)
+}
+
+// clampLimit keeps a page size within the Web API bounds.
+func clampLimit(limit, def int) int {
+	if limit <= 0 {
+		return def
+	}
+	if limit > 50 {
+		return 50
+	}
+	return limit
+}
+
+// textResult wraps text in a tool result.
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// handleSearch handles the catalog search request.
+func (s *SpotifyServer) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting search request processing")
+
+	var args struct {
+		Query string `json:"query"`
+		Type  string `json:"type,omitempty"`
+		Limit int    `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Query == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "query is required")
+	}
+	if args.Type == "" {
+		args.Type = "track"
+	}
+	switch args.Type {
+	case "track", "album", "artist", "playlist":
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported search type: %s", args.Type)
+	}
+
+	values := url.Values{}
+	values.Set("q", args.Query)
+	values.Set("type", args.Type)
+	values.Set("limit", fmt.Sprintf("%d", clampLimit(args.Limit, 10)))
+
+	body, err := s.doRequest(ctx, http.MethodGet, "/search?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var searchResp struct {
+		Tracks struct {
+			Items []Track `json:"items"`
+		} `json:"tracks"`
+		Albums struct {
+			Items []Album `json:"items"`
+		} `json:"albums"`
+		Artists struct {
+			Items []Artist `json:"items"`
+		} `json:"artists"`
+		Playlists struct {
+			Items []*Playlist `json:"items"`
+		} `json:"playlists"`
+	}
+	if err := json.Unmarshal(body, &searchResp); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	var lines []string
+	switch args.Type {
+	case "track":
+		for _, t := range searchResp.Tracks.Items {
+			lines = append(lines, formatTrack(t))
+		}
+	case "album":
+		for _, a := range searchResp.Albums.Items {
+			lines = append(lines, fmt.Sprintf("%s - %s [%s]", a.Name, artistNames(a.Artists), a.URI))
+		}
+	case "artist":
+		for _, a := range searchResp.Artists.Items {
+			lines = append(lines, fmt.Sprintf("%s [%s]", a.Name, a.URI))
+		}
+	case "playlist":
+		// Spotify returns null entries for playlists that are no longer available
+		for _, p := range searchResp.Playlists.Items {
+			if p != nil {
+				lines = append(lines, fmt.Sprintf("%s by %s (%d tracks) [%s]", p.Name, p.Owner.DisplayName, p.Tracks.Total, p.URI))
+			}
+		}
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Search results for '%s' (%s)\n\n", args.Query, args.Type))
+	if len(lines) == 0 {
+		resultContent.WriteString("No results found.")
+	}
+	for i, line := range lines {
+		resultContent.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
+	}
+
+	log.Println("search request completed")
+	return textResult(resultContent.String()), nil
+}
+
+// handleNowPlaying handles the currently playing request.
+func (s *SpotifyServer) handleNowPlaying(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting nowPlaying request processing")
+
+	body, err := s.doRequest(ctx, http.MethodGet, "/me/player/currently-playing", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return textResult("Nothing is currently playing."), nil
+	}
+
+	var playing struct {
+		IsPlaying  bool   `json:"is_playing"`
+		ProgressMs int    `json:"progress_ms"`
+		Item       *Track `json:"item"`
+	}
+	if err := json.Unmarshal(body, &playing); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	if playing.Item == nil {
+		return textResult("Nothing is currently playing."), nil
+	}
+
+	state := "Paused"
+	if playing.IsPlaying {
+		state = "Playing"
+	}
+
+	log.Println("nowPlaying request completed")
+	return textResult(fmt.Sprintf("%s: %s\nPosition: %s / %s",
+		state, formatTrack(*playing.Item), formatDuration(playing.ProgressMs), formatDuration(playing.Item.DurationMs))), nil
+}
+
+// handlePlayback handles the playback control request.
+func (s *SpotifyServer) handlePlayback(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting playback request processing")
+
+	var args struct {
+		Action string `json:"action"`
+		URI    string `json:"uri,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	var (
+		method  string
+		apiPath string
+		body    interface{}
+	)
+	switch args.Action {
+	case "play":
+		method, apiPath = http.MethodPut, "/me/player/play"
+		if args.URI != "" {
+			if !strings.HasPrefix(args.URI, "spotify:") {
+				return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid Spotify URI: %s", args.URI)
+			}
+			if strings.HasPrefix(args.URI, "spotify:track:") {
+				body = map[string]interface{}{"uris": []string{args.URI}}
+			} else {
+				body = map[string]interface{}{"context_uri": args.URI}
+			}
+		}
+	case "pause":
+		method, apiPath = http.MethodPut, "/me/player/pause"
+	case "next":
+		method, apiPath = http.MethodPost, "/me/player/next"
+	case "previous":
+		method, apiPath = http.MethodPost, "/me/player/previous"
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported playback action: %s", args.Action)
+	}
+
+	if _, err := s.doRequest(ctx, method, apiPath, body); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Playback action '%s' sent", args.Action)
+	if args.URI != "" && args.Action == "play" {
+		message += fmt.Sprintf(" for %s", args.URI)
+	}
+
+	log.Println("playback request completed")
+	return textResult(message), nil
+}
+
+// handleListPlaylists handles the playlist listing request.
+func (s *SpotifyServer) handleListPlaylists(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listPlaylists request processing")
+
+	var args struct {
+		Limit int `json:"limit,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	body, err := s.doRequest(ctx, http.MethodGet, fmt.Sprintf("/me/playlists?limit=%d", clampLimit(args.Limit, 20)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Items []*Playlist `json:"items"`
+		Total int         `json:"total"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	var resultContent strings.Builder
+	resultContent.WriteString(fmt.Sprintf("Showing %d of %d playlists\n\n", len(page.Items), page.Total))
+	for _, p := range page.Items {
+		if p == nil {
+			continue
+		}
+		visibility := "private"
+		if p.Public {
+			visibility = "public"
+		}
+		resultContent.WriteString(fmt.Sprintf("- %s (%d tracks, %s) id=%s\n", p.Name, p.Tracks.Total, visibility, p.ID))
+	}
+
+	log.Println("listPlaylists request completed")
+	return textResult(resultContent.String()), nil
+}
+
+// handleCreatePlaylist handles the playlist creation request.
+func (s *SpotifyServer) handleCreatePlaylist(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting createPlaylist request processing")
+
+	var args struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Public      bool   `json:"public,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.Name == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "name is required")
+	}
+
+	// Playlists are created under the user ID, which has to be looked up first
+	body, err := s.doRequest(ctx, http.MethodGet, "/me", nil)
+	if err != nil {
+		return nil, err
+	}
+	var me struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &me); err != nil || me.ID == "" {
+		return nil, fmt.Errorf("failed to determine current user")
+	}
+
+	body, err = s.doRequest(ctx, http.MethodPost, "/users/"+url.PathEscape(me.ID)+"/playlists", map[string]interface{}{
+		"name":        args.Name,
+		"description": args.Description,
+		"public":      args.Public,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist Playlist
+	if err := json.Unmarshal(body, &playlist); err != nil {
+		log.Printf("Error: Failed to parse API response: %v", err)
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	log.Println("createPlaylist request completed")
+	return textResult(fmt.Sprintf("Created playlist '%s' id=%s [%s]", playlist.Name, playlist.ID, playlist.URI)), nil
+}
+
+// handleModifyPlaylist handles adding and removing playlist tracks.
+func (s *SpotifyServer) handleModifyPlaylist(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting modifyPlaylist request processing")
+
+	var args struct {
+		PlaylistID string `json:"playlistId"`
+		Action     string `json:"action"`
+		URIs       string `json:"uris"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	if args.PlaylistID == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "playlistId is required")
+	}
+
+	var uris []string
+	for _, uri := range strings.Split(args.URIs, ",") {
+		if uri = strings.TrimSpace(uri); uri == "" {
+			continue
+		}
+		if !strings.HasPrefix(uri, "spotify:") {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid Spotify URI: %s", uri)
+		}
+		uris = append(uris, uri)
+	}
+	if len(uris) == 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "at least one URI is required")
+	}
+	if len(uris) > 100 {
+		return nil, fmt.Errorf("at most 100 URIs can be modified at once")
+	}
+
+	apiPath := "/playlists/" + url.PathEscape(args.PlaylistID) + "/tracks"
+	var verb string
+	switch args.Action {
+	case "add":
+		verb = "Added"
+		_, err = s.doRequest(ctx, http.MethodPost, apiPath, map[string]interface{}{"uris": uris})
+	case "remove":
+		verb = "Removed"
+		tracks := make([]map[string]string, len(uris))
+		for i, uri := range uris {
+			tracks[i] = map[string]string{"uri": uri}
+		}
+		_, err = s.doRequest(ctx, http.MethodDelete, apiPath, map[string]interface{}{"tracks": tracks})
+	default:
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported playlist action: %s", args.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("modifyPlaylist request completed")
+	return textResult(fmt.Sprintf("%s %d tracks in playlist %s", verb, len(uris), args.PlaylistID)), nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *SpotifyServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the spotify server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("spotify", flag.ContinueOnError)
+	var (
+		clientID     string
+		clientSecret string
+		refreshToken string
+		apiURL       string
+		accountsURL  string
+		timeout      int
+		maxBodySize  int64
+	)
+	fs.StringVar(&clientID, "client-id", "", "Spotify application client ID")
+	fs.StringVar(&clientSecret, "client-secret", "", "Spotify application client secret")
+	fs.StringVar(&refreshToken, "refresh-token", "", "OAuth refresh token for the Spotify account")
+	fs.StringVar(&apiURL, "api-url", defaultAPIURL, "Spotify Web API base URL")
+	fs.StringVar(&accountsURL, "accounts-url", defaultAccountsURL, "Spotify accounts service base URL")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxBodySize, "max-body-size", 5*1024*1024, "Maximum response body size in bytes (default 5MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("client-id", "SPOTIFY_CLIENT_ID"),
+		config.WithEnv("client-secret", "SPOTIFY_CLIENT_SECRET"),
+		config.WithEnv("refresh-token", "SPOTIFY_REFRESH_TOKEN"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Spotify server: timeout=%ds", timeout)
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		log.Printf("Warning: Spotify credentials not configured. The server will start but requests will fail.")
+	}
+
+	// Create SpotifyServer instance
+	spotifyServer := NewSpotifyServer(clientID, clientSecret, refreshToken, apiURL, accountsURL, timeout, maxBodySize)
+	log.Println("SpotifyServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), spotifyServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return spotifyServer.Server(), transportFlags, nil
+}
+
+// Run starts the spotify server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[SpotifyServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create spotify server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("SpotifyServer shutdown")
+	return nil
+}
@@ -0,0 +1,238 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+)
+
+// SpotifyServer creation test
+func TestNewSpotifyServer(t *testing.T) {
+	testCases := []struct {
+		name    string
+		apiURL  string
+		timeout int
+	}{
+		{
+			name:    "Default settings",
+			apiURL:  defaultAPIURL,
+			timeout: 15,
+		},
+		{
+			name:    "Custom API URL",
+			apiURL:  "http://localhost:8080/v1/",
+			timeout: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ss := NewSpotifyServer("id", "secret", "refresh", tc.apiURL, defaultAccountsURL, tc.timeout, 1024)
+
+			assert.NotNil(t, ss, "SpotifyServer instance should be created")
+			assert.Equal(t, strings.TrimSuffix(tc.apiURL, "/"), ss.apiURL, "API URL should match without trailing slash")
+			assert.Equal(t, "refresh", ss.refreshToken, "Refresh token should match")
+			assert.NotNil(t, ss.server, "Internal MCPServer should be initialized")
+		})
+	}
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	ss := NewSpotifyServer("id", "secret", "refresh", defaultAPIURL, defaultAccountsURL, 15, 1024)
+	assert.NotNil(t, ss.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test tool handlers and token refresh against a mock Spotify API
+func TestHandlers(t *testing.T) {
+	var refreshes int32
+	var lastBody map[string]interface{}
+	var rejectNext atomic.Bool
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/token" {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "id" || pass != "secret" || r.FormValue("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+			n := atomic.AddInt32(&refreshes, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": fmt.Sprintf("access-%d", n),
+				"expires_in":   3600,
+			})
+			return
+		}
+
+		if rejectNext.CompareAndSwap(true, false) || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"status": 401, "message": "The access token expired"}}`))
+			return
+		}
+
+		lastBody = nil
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			json.Unmarshal(data, &lastBody)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/search":
+			assert.Equal(t, "track", r.URL.Query().Get("type"))
+			w.Write([]byte(`{"tracks": {"items": [
+				{"name": "Song", "uri": "spotify:track:1", "artists": [{"name": "Band"}], "album": {"name": "Record"}}
+			]}}`))
+		case "GET /v1/me/player/currently-playing":
+			w.Write([]byte(`{"is_playing": true, "progress_ms": 65000, "item":
+				{"name": "Song", "uri": "spotify:track:1", "duration_ms": 200000, "artists": [{"name": "Band"}], "album": {"name": "Record"}}}`))
+		case "PUT /v1/me/player/play", "PUT /v1/me/player/pause", "POST /v1/me/player/next":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /v1/me/playlists":
+			w.Write([]byte(`{"total": 1, "items": [{"id": "pl1", "name": "Mix", "public": true, "tracks": {"total": 12}}]}`))
+		case "GET /v1/me":
+			w.Write([]byte(`{"id": "user1"}`))
+		case "POST /v1/users/user1/playlists":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "pl2", "name": "New", "uri": "spotify:playlist:pl2"}`))
+		case "POST /v1/playlists/pl1/tracks", "DELETE /v1/playlists/pl1/tracks":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"snapshot_id": "abc"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"status": 404, "message": "Not found"}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	ss := NewSpotifyServer("id", "secret", "refresh", mockServer.URL+"/v1", mockServer.URL, 5, 1024*1024)
+	ctx := context.Background()
+
+	t.Run("Search tracks", func(t *testing.T) {
+		result, err := ss.handleSearch(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{
+			"query": "song",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "1. Song - Band (Record) [spotify:track:1]")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes), "Token should be fetched once")
+	})
+
+	t.Run("Now playing", func(t *testing.T) {
+		result, err := ss.handleNowPlaying(ctx, mcptest.NewCallToolRequest("nowPlaying", nil))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "Playing: Song - Band")
+		assert.Contains(t, text, "Position: 1:05 / 3:20")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes), "Cached token should be reused")
+	})
+
+	t.Run("Expired token is refreshed", func(t *testing.T) {
+		rejectNext.Store(true)
+		result, err := ss.handlePlayback(ctx, mcptest.NewCallToolRequest("playback", map[string]interface{}{
+			"action": "pause",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "'pause' sent")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&refreshes), "Token should be refreshed after a 401")
+	})
+
+	t.Run("Play a track URI", func(t *testing.T) {
+		_, err := ss.handlePlayback(ctx, mcptest.NewCallToolRequest("playback", map[string]interface{}{
+			"action": "play",
+			"uri":    "spotify:track:1",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"spotify:track:1"}, lastBody["uris"])
+	})
+
+	t.Run("Play a playlist context", func(t *testing.T) {
+		_, err := ss.handlePlayback(ctx, mcptest.NewCallToolRequest("playback", map[string]interface{}{
+			"action": "play",
+			"uri":    "spotify:playlist:pl1",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "spotify:playlist:pl1", lastBody["context_uri"])
+	})
+
+	t.Run("Unsupported playback action", func(t *testing.T) {
+		_, err := ss.handlePlayback(ctx, mcptest.NewCallToolRequest("playback", map[string]interface{}{
+			"action": "shuffle",
+		}))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("List playlists", func(t *testing.T) {
+		result, err := ss.handleListPlaylists(ctx, mcptest.NewCallToolRequest("listPlaylists", nil))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "- Mix (12 tracks, public) id=pl1")
+	})
+
+	t.Run("Create playlist", func(t *testing.T) {
+		result, err := ss.handleCreatePlaylist(ctx, mcptest.NewCallToolRequest("createPlaylist", map[string]interface{}{
+			"name": "New",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "id=pl2")
+		assert.Equal(t, false, lastBody["public"])
+	})
+
+	t.Run("Remove tracks from playlist", func(t *testing.T) {
+		result, err := ss.handleModifyPlaylist(ctx, mcptest.NewCallToolRequest("modifyPlaylist", map[string]interface{}{
+			"playlistId": "pl1",
+			"action":     "remove",
+			"uris":       "spotify:track:1, spotify:track:2",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Removed 2 tracks")
+		assert.Len(t, lastBody["tracks"], 2)
+	})
+
+	t.Run("Invalid URI", func(t *testing.T) {
+		_, err := ss.handleModifyPlaylist(ctx, mcptest.NewCallToolRequest("modifyPlaylist", map[string]interface{}{
+			"playlistId": "pl1",
+			"action":     "add",
+			"uris":       "https://open.spotify.com/track/1",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid Spotify URI")
+	})
+
+	t.Run("API error message", func(t *testing.T) {
+		_, err := ss.doRequest(ctx, http.MethodGet, "/missing", nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Not found (status code: 404)")
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		empty := NewSpotifyServer("", "", "", mockServer.URL+"/v1", mockServer.URL, 5, 1024)
+		_, err := empty.handleNowPlaying(ctx, mcptest.NewCallToolRequest("nowPlaying", nil))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "credentials are not configured")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewSpotifyServer("id", "secret", "refresh", defaultAPIURL, defaultAccountsURL, 5, 1024).Server())
+}
@@ -0,0 +1,79 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// parseDelimiter returns the field delimiter, defaulting to a comma.
+func parseDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "", ",":
+		return ',', nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q", delimiter)
+	}
+	return r, nil
+}
+
+// decodeCSV reads the records of a CSV file inside r as a grid of strings.
+func decodeCSV(data []byte, delimiter rune, r cellRange) ([][]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var grid [][]interface{}
+	for row := 0; r.lastRow < 0 || row <= r.lastRow; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !r.containsRow(row) {
+			continue
+		}
+		var cells []interface{}
+		for col, field := range record {
+			if r.containsCol(col) {
+				cells = append(cells, field)
+			}
+		}
+		grid = append(grid, cells)
+	}
+	return grid, nil
+}
+
+// encodeCSVRecords writes records as CSV lines.
+func encodeCSVRecords(records [][]string, delimiter rune) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = delimiter
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// encodeCSV writes a table with a header row.
+func encodeCSV(t *table, delimiter rune) ([]byte, error) {
+	records := make([][]string, 0, len(t.rows)+1)
+	records = append(records, t.columns)
+	for _, row := range t.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = cellString(value)
+		}
+		records = append(records, record)
+	}
+	return encodeCSVRecords(records, delimiter)
+}
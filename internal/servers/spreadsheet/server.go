@@ -0,0 +1,795 @@
+package spreadsheet
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/pagination"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/sandbox"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// SpreadsheetServer is an MCP server that reads, queries and writes CSV and XLSX files.
+type SpreadsheetServer struct {
+	server        *server.MCPServer
+	dataDir       string
+	maxFileSize   int
+	maxRows       int
+	maxOutputSize int
+	files         *sandbox.Sandbox
+}
+
+// NewSpreadsheetServer creates a new SpreadsheetServer instance
+func NewSpreadsheetServer(dataDir string, maxFileSize, maxRows, maxOutputSize int) *SpreadsheetServer {
+	log.Printf("SpreadsheetServer created: dataDir=%s, maxFileSize=%d, maxRows=%d, maxOutputSize=%d",
+		dataDir, maxFileSize, maxRows, maxOutputSize)
+
+	s := &SpreadsheetServer{
+		dataDir:       dataDir,
+		maxFileSize:   maxFileSize,
+		maxRows:       maxRows,
+		maxOutputSize: maxOutputSize,
+		files:         sandbox.New(dataDir, "data directory", sandbox.Options{MaxFileSize: int64(maxFileSize)}),
+	}
+
+	mcpServer := server.NewMCPServer(
+		"spreadsheet-server", // server name
+		"1.0.0",              // version
+	)
+
+	// Options shared by the tools reading a sheet
+	source := []mcp.ToolOption{
+		mcp.WithString("path",
+			mcp.Description("Path of a .xlsx or .csv file inside the data directory"),
+		),
+		mcp.WithString("data",
+			mcp.Description("Base64 encoded file content, used instead of path"),
+		),
+		mcp.WithString("format",
+			mcp.Description("File format (default: from the file extension or content)"),
+			mcp.Enum("xlsx", "csv"),
+		),
+		mcp.WithString("sheet",
+			mcp.Description("Sheet name for XLSX files (default: the first sheet)"),
+		),
+		mcp.WithString("range",
+			mcp.Description("Cells to read in A1 notation, such as A1:D50, B:D or 2:100 (default: the whole sheet)"),
+		),
+		mcp.WithBoolean("header",
+			mcp.Description("Whether the first row of the range holds column names (default: true). Without a header, columns are named by their letters"),
+		),
+		mcp.WithString("delimiter",
+			mcp.Description("CSV field delimiter, such as , ; | or tab (default: comma, tab for .tsv files)"),
+		),
+	}
+	output := []mcp.ToolOption{
+		mcp.WithNumber("offset",
+			mcp.Description("Number of result rows to skip (default: 0)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return (default and maximum: %d)", maxRows)),
+		),
+		mcp.WithString("outputFormat",
+			mcp.Description("Format of the returned rows (default: json)"),
+			mcp.Enum("json", "csv"),
+		),
+		pagination.Param(),
+	}
+
+	// Register listSheets tool
+	listSheetsTool := mcp.NewTool("listSheets",
+		mcp.WithDescription("Lists the sheets of an XLSX workbook or describes a CSV file, with the used range and size of each sheet"),
+		// path, data, format and delimiter
+		source[0], source[1], source[2], source[6],
+	)
+
+	// Register readSheet tool
+	readSheetTool := mcp.NewTool("readSheet",
+		append(append([]mcp.ToolOption{
+			mcp.WithDescription("Reads rows from a sheet of an XLSX workbook or a CSV file. Dates in XLSX files are returned as ISO 8601 text"),
+		}, source...), output...)...,
+	)
+
+	// Register queryRows tool
+	queryRowsTool := mcp.NewTool("queryRows",
+		append(append([]mcp.ToolOption{
+			mcp.WithDescription("Filters, groups, aggregates and sorts the rows of a sheet. Filters are applied first, then grouping, then sorting. " +
+				"Text comparisons ignore case and numbers stored as text compare as numbers"),
+		}, source...), append(output,
+			mcp.WithArray("filters",
+				mcp.Description("Conditions that all must match: {column, op, value}. Operators: "+strings.Join(filterOps, ", ")+
+					". The in operator takes a list of values; empty and notEmpty take none"),
+				mcp.Items(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"column": map[string]interface{}{"type": "string"},
+						"op":     map[string]interface{}{"type": "string", "enum": filterOps},
+						"value":  map[string]interface{}{},
+					},
+					"required": []string{"column", "op"},
+				}),
+			),
+			mcp.WithArray("columns",
+				mcp.Description("Columns to return, in order (default: all). Ignored when grouping"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("groupBy",
+				mcp.Description("Columns to group rows by. Each group becomes one row with the aggregates"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("aggregates",
+				mcp.Description("Aggregates to compute per group, or over all rows without groupBy: {column, func, as}. Functions: "+
+					strings.Join(aggregateFuncs, ", ")+" (default: count of rows)"),
+				mcp.Items(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"column": map[string]interface{}{"type": "string"},
+						"func":   map[string]interface{}{"type": "string", "enum": aggregateFuncs},
+						"as":     map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"func"},
+				}),
+			),
+			mcp.WithString("sortBy",
+				mcp.Description("Column of the result to sort by"),
+			),
+			mcp.WithBoolean("descending",
+				mcp.Description("Sort in descending order (default: false)"),
+			),
+			mcp.WithString("outputPath",
+				mcp.Description("Also write all result rows to this .xlsx or .csv file inside the data directory"),
+			),
+			mcp.WithBoolean("overwrite",
+				mcp.Description("Replace outputPath if it exists (default: false)"),
+			),
+		)...)...,
+	)
+
+	// Register writeTable tool
+	writeTableTool := mcp.NewTool("writeTable",
+		mcp.WithDescription("Writes rows to an XLSX or CSV file inside the data directory, or returns the file content when no path is given"),
+		mcp.WithArray("rows",
+			mcp.Description("Rows as arrays of cell values in column order, or as objects keyed by column name"),
+			mcp.Required(),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Column names written as the header row (default: the keys of the first object row)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithString("path",
+			mcp.Description("Output file inside the data directory; the format follows the extension"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: from the path extension, or csv)"),
+			mcp.Enum("xlsx", "csv"),
+		),
+		mcp.WithString("sheet",
+			mcp.Description("Sheet name for XLSX output (default: Sheet1)"),
+		),
+		mcp.WithString("delimiter",
+			mcp.Description("CSV field delimiter (default: comma)"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace the file if it exists (default: false)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, listSheetsTool, s.handleListSheets)
+	middleware.AddTool(mcpServer, readSheetTool, s.handleReadSheet)
+	middleware.AddTool(mcpServer, queryRowsTool, s.handleQueryRows)
+	middleware.AddTool(mcpServer, writeTableTool, s.handleWriteTable)
+
+	s.server = mcpServer
+	return s
+}
+
+// dataPath resolves p inside the data directory, rejecting escapes and paths the
+// allow and deny rules exclude.
+func (s *SpreadsheetServer) dataPath(p string) (string, error) {
+	return s.files.Resolve(p)
+}
+
+// sourceOptions selects the file and cells a tool reads.
+type sourceOptions struct {
+	Path      string `json:"path,omitempty"`
+	Data      string `json:"data,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Sheet     string `json:"sheet,omitempty"`
+	Range     string `json:"range,omitempty"`
+	Header    *bool  `json:"header,omitempty"`
+	Delimiter string `json:"delimiter,omitempty"`
+}
+
+// fileFormat returns the format and default delimiter implied by a file extension.
+func fileFormat(name string) (format string, delimiter string, err error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx", ".xlsm":
+		return "xlsx", "", nil
+	case ".csv", ".txt":
+		return "csv", "", nil
+	case ".tsv", ".tab":
+		return "csv", "tab", nil
+	case ".xls":
+		return "", "", errors.New("legacy .xls files are not supported; save the workbook as .xlsx or CSV")
+	}
+	return "", "", nil
+}
+
+// load reads the source file and returns its content, format and CSV delimiter.
+func (s *SpreadsheetServer) load(opts sourceOptions) ([]byte, string, rune, error) {
+	var data []byte
+	format, delimiter := opts.Format, opts.Delimiter
+	switch {
+	case opts.Path != "" && opts.Data != "":
+		return nil, "", 0, errors.New("provide either path or data, not both")
+	case opts.Path != "":
+		extFormat, extDelimiter, err := fileFormat(opts.Path)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		if format == "" {
+			format = extFormat
+		}
+		if delimiter == "" {
+			delimiter = extDelimiter
+		}
+		if data, err = s.files.ReadFile(opts.Path); err != nil {
+			return nil, "", 0, err
+		}
+	case opts.Data != "":
+		encoded := strings.TrimSpace(opts.Data)
+		if strings.HasPrefix(encoded, "data:") {
+			if _, after, found := strings.Cut(encoded, ","); found {
+				encoded = after
+			}
+		}
+		if err := s.files.CheckSize("data", int64(base64.StdEncoding.DecodedLen(len(encoded)))); err != nil {
+			return nil, "", 0, err
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, "", 0, fmt.Errorf("invalid base64 data: %w", err)
+		}
+	default:
+		return nil, "", 0, errors.New("path or data is required")
+	}
+
+	if format == "" {
+		// XLSX files are zip archives
+		format = "csv"
+		if strings.HasPrefix(string(data), "PK\x03\x04") {
+			format = "xlsx"
+		}
+	}
+	if format != "csv" && format != "xlsx" {
+		return nil, "", 0, fmt.Errorf("unsupported format %q (use xlsx or csv)", format)
+	}
+	comma, err := parseDelimiter(delimiter)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return data, format, comma, nil
+}
+
+// loadTable reads the selected cells of the source as a table. It also returns a short
+// description of what was read.
+func (s *SpreadsheetServer) loadTable(opts sourceOptions) (*table, string, error) {
+	r, err := parseRange(opts.Range)
+	if err != nil {
+		return nil, "", err
+	}
+	data, format, delimiter, err := s.load(opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var grid [][]interface{}
+	var description string
+	if format == "xlsx" {
+		wb, err := openWorkbook(data, s.maxPartSize())
+		if err != nil {
+			return nil, "", err
+		}
+		sheet, err := wb.sheet(opts.Sheet)
+		if err != nil {
+			return nil, "", err
+		}
+		if grid, err = wb.readGrid(sheet, r); err != nil {
+			return nil, "", err
+		}
+		description = fmt.Sprintf("Sheet %q", sheet.name)
+	} else {
+		if grid, err = decodeCSV(data, delimiter, r); err != nil {
+			return nil, "", fmt.Errorf("invalid CSV: %w", err)
+		}
+		description = "CSV"
+	}
+	if rangeText := r.String(); rangeText != "" {
+		description += " range " + rangeText
+	}
+
+	header := opts.Header == nil || *opts.Header
+	return newTable(grid, max(r.firstCol, 0), header), description, nil
+}
+
+// maxPartSize limits the uncompressed size of each part of an XLSX file.
+func (s *SpreadsheetServer) maxPartSize() int64 {
+	return int64(s.maxFileSize) * 10
+}
+
+// outputOptions controls which rows are returned and how.
+type outputOptions struct {
+	Offset       int    `json:"offset,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	OutputFormat string `json:"outputFormat,omitempty"`
+}
+
+// render formats the requested rows of t within the row and size limits, and returns
+// the cursor of the next rows, if any. The cursor of req supersedes the offset.
+func (s *SpreadsheetServer) render(req mcp.CallToolRequest, t *table, description string, opts outputOptions) (string, string, error) {
+	pager, err := pagination.Start(req, "offset")
+	if err != nil {
+		return "", "", err
+	}
+	if pager.Offset > 0 {
+		opts.Offset = pager.Offset
+	}
+	limit := s.maxRows
+	if opts.Limit > 0 {
+		limit = min(opts.Limit, s.maxRows)
+	}
+	text, written, err := renderTable(t, opts.Offset, limit, s.maxOutputSize, opts.OutputFormat, ',')
+	if err != nil {
+		return "", "", err
+	}
+
+	summary := fmt.Sprintf("%s: %d rows x %d columns", description, len(t.rows), len(t.columns))
+	offset := min(max(opts.Offset, 0), len(t.rows))
+	var next string
+	if written > 0 && written < len(t.rows) {
+		summary += fmt.Sprintf(", showing rows %d-%d", offset+1, offset+written)
+		if offset+written < len(t.rows) {
+			next = pager.Cursor(offset + written)
+		}
+	}
+	return summary + "\n\n" + text, next, nil
+}
+
+// handleListSheets handles the sheet listing request.
+func (s *SpreadsheetServer) handleListSheets(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting listSheets request processing")
+
+	var args sourceOptions
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	data, format, delimiter, err := s.load(args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	// describe summarizes the used range of a grid read from A1
+	describe := func(grid [][]interface{}) string {
+		t := newTable(grid, 0, false)
+		if len(t.rows) == 0 {
+			return "empty"
+		}
+		return fmt.Sprintf("A1:%s%d, %d rows x %d columns", columnName(len(t.columns)-1), len(t.rows), len(t.rows), len(t.columns))
+	}
+
+	var b strings.Builder
+	if format == "xlsx" {
+		wb, err := openWorkbook(data, s.maxPartSize())
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		fmt.Fprintf(&b, "Workbook with %d sheets:", len(wb.sheets))
+		for i, sheet := range wb.sheets {
+			grid, err := wb.readGrid(sheet, fullRange)
+			if err != nil {
+				fmt.Fprintf(&b, "\n%d. %s (unreadable: %v)", i+1, sheet.name, err)
+				continue
+			}
+			fmt.Fprintf(&b, "\n%d. %s (%s)", i+1, sheet.name, describe(grid))
+		}
+	} else {
+		grid, err := decodeCSV(data, delimiter, fullRange)
+		if err != nil {
+			log.Printf("Error: Invalid CSV: %v", err)
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid CSV: %w", err)
+		}
+		fmt.Fprintf(&b, "CSV file with delimiter %q (%s)", string(delimiter), describe(grid))
+	}
+
+	log.Printf("listSheets request completed: format=%s", format)
+	return textResult(b.String()), nil
+}
+
+// handleReadSheet handles the sheet reading request.
+func (s *SpreadsheetServer) handleReadSheet(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting readSheet request processing")
+
+	var args struct {
+		sourceOptions
+		outputOptions
+	}
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	t, description, err := s.loadTable(args.sourceOptions)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	text, next, err := s.render(req, t, description, args.outputOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("readSheet request completed: rows=%d, columns=%d", len(t.rows), len(t.columns))
+	return pagination.Result(textResult(text), next), nil
+}
+
+// handleQueryRows handles the filter and aggregation request.
+func (s *SpreadsheetServer) handleQueryRows(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting queryRows request processing")
+
+	var args struct {
+		sourceOptions
+		outputOptions
+		Filters    []rowFilter `json:"filters,omitempty"`
+		Columns    []string    `json:"columns,omitempty"`
+		GroupBy    []string    `json:"groupBy,omitempty"`
+		Aggregates []aggregate `json:"aggregates,omitempty"`
+		SortBy     string      `json:"sortBy,omitempty"`
+		Descending bool        `json:"descending,omitempty"`
+		OutputPath string      `json:"outputPath,omitempty"`
+		Overwrite  bool        `json:"overwrite,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	t, description, err := s.loadTable(args.sourceOptions)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	total := len(t.rows)
+
+	if t, err = filterRows(t, args.Filters); err != nil {
+		return nil, err
+	}
+	description += fmt.Sprintf(", %d of %d rows matched", len(t.rows), total)
+
+	skipped := 0
+	if len(args.GroupBy) > 0 || len(args.Aggregates) > 0 {
+		if t, skipped, err = groupRows(t, args.GroupBy, args.Aggregates); err != nil {
+			return nil, err
+		}
+		description += " (grouped)"
+	} else if t, err = selectColumns(t, args.Columns); err != nil {
+		return nil, err
+	}
+	if args.SortBy != "" {
+		if err := sortRows(t, args.SortBy, args.Descending); err != nil {
+			return nil, err
+		}
+	}
+
+	text, next, err := s.render(req, t, description, args.outputOptions)
+	if err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		text += fmt.Sprintf("\n\nNote: %d non-numeric values were skipped by numeric aggregates", skipped)
+	}
+	if args.OutputPath != "" {
+		written, err := s.writeFile(args.OutputPath, "", args.Sheet, "", args.Overwrite, t)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		text += "\n\n" + written
+	}
+
+	log.Printf("queryRows request completed: rows=%d", len(t.rows))
+	return pagination.Result(textResult(text), next), nil
+}
+
+// handleWriteTable handles the table writing request.
+func (s *SpreadsheetServer) handleWriteTable(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting writeTable request processing")
+
+	var args struct {
+		Rows      []interface{} `json:"rows"`
+		Columns   []string      `json:"columns,omitempty"`
+		Path      string        `json:"path,omitempty"`
+		Format    string        `json:"format,omitempty"`
+		Sheet     string        `json:"sheet,omitempty"`
+		Delimiter string        `json:"delimiter,omitempty"`
+		Overwrite bool          `json:"overwrite,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	t, err := tableFromJSON(args.Columns, args.Rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Path != "" {
+		text, err := s.writeFile(args.Path, args.Format, args.Sheet, args.Delimiter, args.Overwrite, t)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return nil, err
+		}
+		log.Printf("writeTable request completed: path=%s, rows=%d", args.Path, len(t.rows))
+		return textResult(text), nil
+	}
+
+	// Without a path, the content is returned
+	format := args.Format
+	if format == "" {
+		format = "csv"
+	}
+	data, err := encodeTable(format, args.Sheet, args.Delimiter, t)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("%d rows x %d columns as %s (%d bytes)", len(t.rows), len(t.columns), strings.ToUpper(format), len(data))
+	if format == "csv" {
+		log.Printf("writeTable request completed: format=csv, rows=%d", len(t.rows))
+		return textResult(string(data)), nil
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+				URI:      "spreadsheet:///" + sheetName(args.Sheet) + ".xlsx",
+				MIMEType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				Blob:     base64.StdEncoding.EncodeToString(data),
+			}),
+			mcp.TextContent{
+				Type: "text",
+				Text: summary,
+			},
+		},
+	}
+
+	log.Printf("writeTable request completed: format=%s, rows=%d", format, len(t.rows))
+	return result, nil
+}
+
+// sheetName returns the sheet name for XLSX output.
+func sheetName(name string) string {
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+// tableFromJSON builds a table from rows given as arrays or objects. Nested arrays and
+// objects in cells are stored as JSON text.
+func tableFromJSON(columns []string, rows []interface{}) (*table, error) {
+	t := &table{columns: columns}
+	if len(t.columns) == 0 && len(rows) > 0 {
+		if first, ok := rows[0].(map[string]interface{}); ok {
+			// JSON objects have no key order, so the columns are sorted
+			for key := range first {
+				t.columns = append(t.columns, key)
+			}
+			sort.Strings(t.columns)
+		}
+	}
+
+	cell := func(value interface{}) (interface{}, error) {
+		switch value.(type) {
+		case []interface{}, map[string]interface{}:
+			data, err := json.Marshal(value)
+			return string(data), err
+		}
+		return value, nil
+	}
+
+	for i, row := range rows {
+		switch row := row.(type) {
+		case []interface{}:
+			if len(t.columns) == 0 {
+				for j := range row {
+					t.columns = append(t.columns, columnName(j))
+				}
+			}
+			if len(row) > len(t.columns) {
+				return nil, fmt.Errorf("row %d has %d values, but there are %d columns", i+1, len(row), len(t.columns))
+			}
+			cells := make([]interface{}, len(t.columns))
+			for j, value := range row {
+				v, err := cell(value)
+				if err != nil {
+					return nil, err
+				}
+				cells[j] = v
+			}
+			t.rows = append(t.rows, cells)
+		case map[string]interface{}:
+			cells := make([]interface{}, len(t.columns))
+			for j, column := range t.columns {
+				v, err := cell(row[column])
+				if err != nil {
+					return nil, err
+				}
+				cells[j] = v
+			}
+			t.rows = append(t.rows, cells)
+		default:
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "row %d must be an array or an object", i+1)
+		}
+	}
+	if len(t.columns) == 0 {
+		return nil, toolerr.New(toolerr.InvalidParams, "columns are required when there are no rows")
+	}
+	return t, nil
+}
+
+// encodeTable writes t in the given format.
+func encodeTable(format, sheet, delimiter string, t *table) ([]byte, error) {
+	switch format {
+	case "xlsx":
+		return encodeXLSX(sheetName(sheet), t)
+	case "csv":
+		comma, err := parseDelimiter(delimiter)
+		if err != nil {
+			return nil, err
+		}
+		return encodeCSV(t, comma)
+	}
+	return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported format %q (use xlsx or csv)", format)
+}
+
+// writeFile writes t to a file inside the data directory and describes the result.
+func (s *SpreadsheetServer) writeFile(p, format, sheet, delimiter string, overwrite bool, t *table) (string, error) {
+	extFormat, extDelimiter, err := fileFormat(p)
+	if err != nil {
+		return "", err
+	}
+	if format == "" {
+		format = extFormat
+	}
+	if format == "" {
+		return "", fmt.Errorf("cannot tell the format of %s; use a .xlsx or .csv extension or set format", p)
+	}
+	if delimiter == "" {
+		delimiter = extDelimiter
+	}
+
+	resolved, err := s.dataPath(p)
+	if err != nil {
+		return "", err
+	}
+	data, err := encodeTable(format, sheet, delimiter, t)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(resolved); err == nil && !overwrite {
+		return "", fmt.Errorf("%s already exists; set overwrite to replace it", p)
+	}
+	if err := os.MkdirAll(filepath.Dir(resolved), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(resolved, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", p, err)
+	}
+	return fmt.Sprintf("Wrote %d rows x %d columns to %s (%d bytes)", len(t.rows), len(t.columns), p, len(data)), nil
+}
+
+// textResult wraps text in a tool result.
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *SpreadsheetServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the spreadsheet server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("spreadsheet", flag.ContinueOnError)
+	var (
+		dataDir       string
+		maxFileSize   int
+		maxRows       int
+		maxOutputSize int
+	)
+	fs.StringVar(&dataDir, "data-dir", ".", "Directory that file paths are restricted to")
+	fs.IntVar(&maxFileSize, "max-file-size", 20*1024*1024, "Maximum size of input files in bytes (default 20MB)")
+	fs.IntVar(&maxRows, "max-rows", 200, "Maximum number of rows returned by a tool call")
+	fs.IntVar(&maxOutputSize, "max-output-size", 100000, "Maximum size of returned rows in bytes")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var sandboxFlags sandbox.Flags
+	sandboxFlags.Register(fs, "data directory")
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting spreadsheet server: data-dir=%s, max-file-size=%d, max-rows=%d", dataDir, maxFileSize, maxRows)
+
+	// Create SpreadsheetServer instance
+	spreadsheetServer := NewSpreadsheetServer(dataDir, maxFileSize, maxRows, maxOutputSize)
+	sandboxFlags.Apply(spreadsheetServer.files)
+	log.Println("SpreadsheetServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), spreadsheetServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return spreadsheetServer.Server(), transportFlags, nil
+}
+
+// Run starts the spreadsheet server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[SpreadsheetServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create spreadsheet server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("SpreadsheetServer shutdown")
+	return nil
+}
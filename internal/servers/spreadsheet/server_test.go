@@ -0,0 +1,492 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SpreadsheetServer creation test
+func TestNewSpreadsheetServer(t *testing.T) {
+	s := NewSpreadsheetServer("/tmp", 1024, 10, 2048)
+
+	assert.NotNil(t, s, "SpreadsheetServer instance should be created")
+	assert.Equal(t, "/tmp", s.dataDir, "Data directory should match")
+	assert.Equal(t, 1024, s.maxFileSize, "Max file size should match")
+	assert.Equal(t, 10, s.maxRows, "Max rows should match")
+	assert.Equal(t, 2048, s.maxOutputSize, "Max output size should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewSpreadsheetServer(".", 1024, 10, 2048)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test A1 references and ranges
+func TestParseRange(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "XFD", columnName(maxColumns-1))
+
+	testCases := []struct {
+		input    string
+		expected cellRange
+		err      bool
+	}{
+		{input: "", expected: fullRange},
+		{input: "A1:C10", expected: cellRange{firstRow: 0, lastRow: 9, firstCol: 0, lastCol: 2}},
+		{input: "c10:a1", expected: cellRange{firstRow: 0, lastRow: 9, firstCol: 0, lastCol: 2}},
+		{input: "$B$2", expected: cellRange{firstRow: 1, lastRow: 1, firstCol: 1, lastCol: 1}},
+		{input: "B:D", expected: cellRange{firstRow: -1, lastRow: -1, firstCol: 1, lastCol: 3}},
+		{input: "2:5", expected: cellRange{firstRow: 1, lastRow: 4, firstCol: -1, lastCol: -1}},
+		{input: "A2:C", expected: cellRange{firstRow: 1, lastRow: -1, firstCol: 0, lastCol: 2}},
+		{input: "A0", err: true},
+		{input: "XFE1", err: true},
+		{input: "A1:?", err: true},
+	}
+	for _, tc := range testCases {
+		r, err := parseRange(tc.input)
+		if tc.err {
+			assert.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, r, tc.input)
+	}
+
+	r, _ := parseRange("B2:D")
+	assert.Equal(t, "B2:D", r.String())
+}
+
+// buildXLSX creates a workbook from raw parts, as written by spreadsheet applications.
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func excelWorkbook(t *testing.T) []byte {
+	return buildXLSX(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sales" sheetId="1" r:id="rId1"/><sheet name="Empty" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Region</t></si><si><t>Amount</t></si><si><t>Date</t></si><si><t>North</t></si>
+<si><r><t>So</t></r><r><rPr><b/></rPr><t>uth</t></r></si></sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm"/></numFmts>
+<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="inlineStr"><is><t>Paid</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>10.5</v></c><c r="C2" s="1"><v>45292</v></c><c r="D2" t="b"><v>1</v></c></row>
+<row><c t="s"><v>4</v></c><c><v>4</v></c><c s="2"><v>45292.75</v></c><c t="b"><v>0</v></c></row>
+<row r="5"><c r="A5" t="s"><v>3</v></c><c r="B5" t="e"><v>#DIV/0!</v></c><c r="E5" s="1"/></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+	})
+}
+
+// Test reading cells, shared strings, dates and sparse rows from XLSX files
+func TestReadXLSX(t *testing.T) {
+	wb, err := openWorkbook(excelWorkbook(t), 1<<20)
+	require.NoError(t, err)
+	require.Len(t, wb.sheets, 2)
+	assert.Equal(t, "xl/worksheets/sheet2.xml", wb.sheets[1].part)
+
+	sheet, err := wb.sheet("sales")
+	require.NoError(t, err)
+	grid, err := wb.readGrid(sheet, fullRange)
+	require.NoError(t, err)
+
+	tbl := newTable(grid, 0, true)
+	assert.Equal(t, []string{"Region", "Amount", "Date", "Paid"}, tbl.columns)
+	assert.Equal(t, [][]interface{}{
+		{"North", 10.5, "2024-01-01", true},
+		{"South", 4.0, "2024-01-01T18:00:00", false},
+		{nil, nil, nil, nil},
+		{"North", "#DIV/0!", nil, nil},
+	}, tbl.rows)
+
+	r, _ := parseRange("B2:C3")
+	grid, err = wb.readGrid(sheet, r)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{10.5, "2024-01-01"}, {4.0, "2024-01-01T18:00:00"}}, grid)
+
+	_, err = wb.sheet("Missing")
+	assert.ErrorContains(t, err, "sheets: Sales, Empty")
+
+	_, err = openWorkbook([]byte("not a zip"), 1<<20)
+	assert.Error(t, err)
+
+	_, err = openWorkbook(excelWorkbook(t), 100)
+	assert.ErrorContains(t, err, "exceeds the maximum size")
+}
+
+// Test date serial numbers and format detection
+func TestExcelDate(t *testing.T) {
+	assert.Equal(t, "2024-01-01", excelDate(45292, false))
+	assert.Equal(t, "1900-01-01", excelDate(1, false))
+	assert.Equal(t, "1900-03-01", excelDate(61, false))
+	assert.Equal(t, "12:00:00", excelDate(0.5, false))
+	assert.Equal(t, "1904-01-02", excelDate(1, true))
+
+	assert.True(t, isDateFormat("dd/mm/yyyy"))
+	assert.True(t, isDateFormat("[$-409]h:mm AM/PM"))
+	assert.False(t, isDateFormat("#,##0.00"))
+	assert.False(t, isDateFormat(`0 "days"`))
+	assert.False(t, isDateFormat("[Red]0.00;0.00"))
+}
+
+// Test that written workbooks read back
+func TestWriteXLSX(t *testing.T) {
+	tbl := &table{
+		columns: []string{"name", "score", "ok"},
+		rows: [][]interface{}{
+			{"<Ada> & co", 1.5, true},
+			{"  spaced  ", nil, false},
+		},
+	}
+	data, err := encodeXLSX("Results", tbl)
+	require.NoError(t, err)
+
+	wb, err := openWorkbook(data, 1<<20)
+	require.NoError(t, err)
+	sheet, err := wb.sheet("")
+	require.NoError(t, err)
+	assert.Equal(t, "Results", sheet.name)
+	grid, err := wb.readGrid(sheet, fullRange)
+	require.NoError(t, err)
+	assert.Equal(t, tbl, newTable(grid, 0, true))
+
+	_, err = encodeXLSX("bad/name", tbl)
+	assert.Error(t, err)
+	_, err = encodeXLSX(strings.Repeat("x", 32), tbl)
+	assert.Error(t, err)
+}
+
+func csvTable(t *testing.T, input string) *table {
+	grid, err := decodeCSV([]byte(input), ',', fullRange)
+	require.NoError(t, err)
+	return newTable(grid, 0, true)
+}
+
+const salesCSV = "\ufeffregion,product,amount,units\n" +
+	"North,Apple,10.5,3\n" +
+	"south,Pear,4,1\n" +
+	"North,Pear,n/a,2\n" +
+	"South,Apple,20,\n" +
+	"East,Apple,,5\n" +
+	",,,\n"
+
+// Test table building from CSV
+func TestCSVTable(t *testing.T) {
+	tbl := csvTable(t, salesCSV)
+	assert.Equal(t, []string{"region", "product", "amount", "units"}, tbl.columns, "BOM should be removed")
+	assert.Len(t, tbl.rows, 5, "Trailing empty rows should be dropped")
+
+	tbl = csvTable(t, "a,,a\n1,2,3,4\n")
+	assert.Equal(t, []string{"a", "B", "a_2", "D"}, tbl.columns, "Missing and duplicate names should be made unique")
+
+	r, _ := parseRange("B2:C3")
+	grid, err := decodeCSV([]byte(salesCSV), ',', r)
+	require.NoError(t, err)
+	tbl = newTable(grid, r.firstCol, false)
+	assert.Equal(t, []string{"B", "C"}, tbl.columns)
+	assert.Equal(t, [][]interface{}{{"Apple", "10.5"}, {"Pear", "4"}}, tbl.rows)
+
+	_, err = decodeCSV([]byte("a,\"b\n"), ',', fullRange)
+	assert.Error(t, err)
+}
+
+// Test row filters
+func TestFilterRows(t *testing.T) {
+	tbl := csvTable(t, salesCSV)
+
+	testCases := []struct {
+		name    string
+		filters []rowFilter
+		regions []string
+		err     string
+	}{
+		{name: "Equal ignores case", filters: []rowFilter{{Column: "Region", Op: "eq", Value: "south"}}, regions: []string{"south", "South"}},
+		{name: "Numeric comparison", filters: []rowFilter{{Column: "amount", Op: "gt", Value: 5.0}}, regions: []string{"North", "South"}},
+		{name: "Numbers as text", filters: []rowFilter{{Column: "amount", Op: "lte", Value: "10"}}, regions: []string{"south"}},
+		{name: "Combined", filters: []rowFilter{{Column: "product", Op: "eq", Value: "Apple"}, {Column: "units", Op: "notEmpty"}}, regions: []string{"North", "East"}},
+		{name: "In", filters: []rowFilter{{Column: "region", Op: "in", Value: []interface{}{"east", "NORTH"}}}, regions: []string{"North", "North", "East"}},
+		{name: "Contains", filters: []rowFilter{{Column: "product", Op: "contains", Value: "EA"}}, regions: []string{"south", "North"}},
+		{name: "Empty", filters: []rowFilter{{Column: "amount", Op: "empty"}}, regions: []string{"East"}},
+		{name: "Unknown column", filters: []rowFilter{{Column: "price", Op: "eq", Value: 1.0}}, err: "unknown column \"price\""},
+		{name: "Unknown operator", filters: []rowFilter{{Column: "amount", Op: "like", Value: "1"}}, err: "unknown filter operator"},
+		{name: "Missing value", filters: []rowFilter{{Column: "amount", Op: "gt"}}, err: "requires a value"},
+		{name: "In without list", filters: []rowFilter{{Column: "amount", Op: "in", Value: "1"}}, err: "requires a list"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered, err := filterRows(tbl, tc.filters)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			regions := []string{}
+			for _, row := range filtered.rows {
+				regions = append(regions, cellString(row[0]))
+			}
+			assert.Equal(t, tc.regions, regions)
+		})
+	}
+}
+
+// Test grouping, aggregates and sorting
+func TestGroupRows(t *testing.T) {
+	tbl := csvTable(t, salesCSV)
+
+	grouped, skipped, err := groupRows(tbl, []string{"region"}, []aggregate{
+		{Func: "count"},
+		{Column: "amount", Func: "sum"},
+		{Column: "amount", Func: "mean", As: "average"},
+		{Column: "units", Func: "max"},
+		{Column: "product", Func: "countDistinct"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped, "The n/a amount should be skipped")
+	assert.Equal(t, []string{"region", "count", "sum(amount)", "average", "max(units)", "countDistinct(product)"}, grouped.columns)
+	assert.Equal(t, [][]interface{}{
+		{"North", 2.0, 10.5, 10.5, 3.0, 2.0},
+		{"south", 2.0, 24.0, 12.0, 1.0, 2.0},
+		{"East", 1.0, 0.0, nil, 5.0, 1.0},
+	}, grouped.rows)
+
+	require.NoError(t, sortRows(grouped, "average", true))
+	assert.Equal(t, []interface{}{"south", "North", "East"}, []interface{}{grouped.rows[0][0], grouped.rows[1][0], grouped.rows[2][0]},
+		"Empty values should sort last")
+
+	totals, _, err := groupRows(tbl, nil, []aggregate{{Column: "units", Func: "sum"}, {Column: "units", Func: "min"}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{11.0, 1.0}}, totals.rows)
+
+	counts, _, err := groupRows(tbl, []string{"product"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"product", "count"}, counts.columns)
+
+	_, _, err = groupRows(tbl, nil, []aggregate{{Func: "sum"}})
+	assert.ErrorContains(t, err, "requires a column")
+	_, _, err = groupRows(tbl, nil, []aggregate{{Column: "units", Func: "median"}})
+	assert.ErrorContains(t, err, "unknown aggregate function")
+
+	selected, err := selectColumns(tbl, []string{"UNITS", "region"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"units", "region"}, selected.columns)
+	assert.Equal(t, []interface{}{"3", "North"}, selected.rows[0])
+}
+
+// Test output rendering and limits
+func TestRenderTable(t *testing.T) {
+	tbl := csvTable(t, salesCSV)
+
+	text, written, err := renderTable(tbl, 1, 2, 10000, "json", ',')
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+	assert.Equal(t, `{
+  "columns": ["region","product","amount","units"],
+  "rows": [
+    ["south","Pear","4","1"],
+    ["North","Pear","n/a","2"]
+  ],
+  "totalRows": 5,
+  "offset": 1,
+  "truncated": true
+}`, text)
+
+	text, written, err = renderTable(tbl, 0, 100, 62, "csv", ',')
+	require.NoError(t, err)
+	assert.Equal(t, 2, written, "Rows should stop at the size limit")
+	assert.Equal(t, "region,product,amount,units\nNorth,Apple,10.5,3\nsouth,Pear,4,1\n", text)
+
+	text, written, err = renderTable(tbl, 10, 100, 10000, "json", ',')
+	require.NoError(t, err)
+	assert.Equal(t, 0, written)
+	assert.Contains(t, text, "\"rows\": [],")
+
+	_, _, err = renderTable(tbl, 0, 1, 100, "xml", ',')
+	assert.Error(t, err)
+}
+
+// Test the tool handlers
+func TestHandlers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sales.csv"), []byte(salesCSV), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sales.xlsx"), excelWorkbook(t), 0644))
+	s := NewSpreadsheetServer(dir, 1<<20, 3, 10000)
+	ctx := context.Background()
+
+	result, err := s.handleListSheets(ctx, mcptest.NewCallToolRequest("listSheets", map[string]interface{}{
+		"path": "sales.xlsx",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Workbook with 2 sheets:\n1. Sales (A1:D5, 5 rows x 4 columns)\n2. Empty (empty)", mcptest.ResultText(result))
+
+	result, err = s.handleListSheets(ctx, mcptest.NewCallToolRequest("listSheets", map[string]interface{}{
+		"data":      base64.StdEncoding.EncodeToString([]byte("a;b\n1;2\n")),
+		"delimiter": ";",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "CSV file with delimiter \";\" (A1:B2, 2 rows x 2 columns)", mcptest.ResultText(result))
+
+	result, err = s.handleReadSheet(ctx, mcptest.NewCallToolRequest("readSheet", map[string]interface{}{
+		"path":         "sales.csv",
+		"outputFormat": "csv",
+	}))
+	require.NoError(t, err)
+	next := result.Meta["nextCursor"].(string)
+	assert.Equal(t, "CSV: 5 rows x 4 columns, showing rows 1-3\n\n"+
+		"region,product,amount,units\nNorth,Apple,10.5,3\nsouth,Pear,4,1\nNorth,Pear,n/a,2\n\n"+
+		"More results: call again with cursor \""+next+"\"", mcptest.ResultText(result))
+
+	// The next rows are read with the cursor
+	result, err = s.handleReadSheet(ctx, mcptest.NewCallToolRequest("readSheet", map[string]interface{}{
+		"path":         "sales.csv",
+		"outputFormat": "csv",
+		"cursor":       next,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "CSV: 5 rows x 4 columns, showing rows 4-5\n\n"+
+		"region,product,amount,units\nSouth,Apple,20,\nEast,Apple,,5\n", mcptest.ResultText(result))
+	assert.Nil(t, result.Meta)
+
+	result, err = s.handleReadSheet(ctx, mcptest.NewCallToolRequest("readSheet", map[string]interface{}{
+		"path":  "sales.xlsx",
+		"range": "A1:B2",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Sheet \"Sales\" range A1:B2: 1 rows x 2 columns\n\n")
+	assert.Contains(t, mcptest.ResultText(result), `["North",10.5]`)
+
+	result, err = s.handleQueryRows(ctx, mcptest.NewCallToolRequest("queryRows", map[string]interface{}{
+		"path":         "sales.csv",
+		"filters":      []interface{}{map[string]interface{}{"column": "product", "op": "eq", "value": "apple"}},
+		"groupBy":      []interface{}{"region"},
+		"aggregates":   []interface{}{map[string]interface{}{"column": "amount", "func": "sum", "as": "total"}},
+		"sortBy":       "total",
+		"descending":   true,
+		"outputFormat": "csv",
+		"outputPath":   "out/apples.xlsx",
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.True(t, strings.HasPrefix(text, "CSV, 3 of 5 rows matched (grouped): 3 rows x 2 columns\n\nregion,total\nSouth,20\nNorth,10.5\nEast,0\n"), text)
+	assert.Contains(t, text, "\n\nWrote 3 rows x 2 columns to out/apples.xlsx (")
+
+	result, err = s.handleReadSheet(ctx, mcptest.NewCallToolRequest("readSheet", map[string]interface{}{
+		"path": "out/apples.xlsx",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), `["South",20]`, "The written file should read back")
+
+	_, err = s.handleQueryRows(ctx, mcptest.NewCallToolRequest("queryRows", map[string]interface{}{
+		"path":       "sales.csv",
+		"outputPath": "out/apples.xlsx",
+	}))
+	assert.ErrorContains(t, err, "already exists")
+
+	result, err = s.handleWriteTable(ctx, mcptest.NewCallToolRequest("writeTable", map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"b": 1.0, "a": "x,y"},
+			map[string]interface{}{"a": "z", "c": []interface{}{1.0}},
+		},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n\"x,y\",1\nz,\n", mcptest.ResultText(result))
+
+	result, err = s.handleWriteTable(ctx, mcptest.NewCallToolRequest("writeTable", map[string]interface{}{
+		"columns": []interface{}{"name", "tags"},
+		"rows":    []interface{}{[]interface{}{"Ada", []interface{}{"a", "b"}}},
+		"path":    "people.tsv",
+	}))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mcptest.ResultText(result), "Wrote 1 rows x 2 columns to people.tsv"))
+	written, err := os.ReadFile(filepath.Join(dir, "people.tsv"))
+	require.NoError(t, err)
+	assert.Equal(t, "name\ttags\nAda\t\"[\"\"a\"\",\"\"b\"\"]\"\n", string(written))
+
+	result, err = s.handleWriteTable(ctx, mcptest.NewCallToolRequest("writeTable", map[string]interface{}{
+		"rows":   []interface{}{[]interface{}{1.0, 2.0}},
+		"format": "xlsx",
+	}))
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	resource, ok := result.Content[0].(mcp.EmbeddedResource)
+	require.True(t, ok)
+	blob := resource.Resource.(mcp.BlobResourceContents)
+	data, err := base64.StdEncoding.DecodeString(blob.Blob)
+	require.NoError(t, err)
+	_, err = openWorkbook(data, 1<<20)
+	assert.NoError(t, err)
+
+	errorCases := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		err  string
+	}{
+		{name: "No source", tool: "readSheet", args: map[string]interface{}{}, err: "path or data is required"},
+		{name: "Missing file", tool: "readSheet", args: map[string]interface{}{"path": "missing.csv"}, err: "failed to read missing.csv"},
+		{name: "Legacy format", tool: "readSheet", args: map[string]interface{}{"path": "old.xls"}, err: "legacy .xls"},
+		{name: "Bad range", tool: "readSheet", args: map[string]interface{}{"path": "sales.csv", "range": "1A"}, err: "invalid cell reference"},
+		{name: "Bad base64", tool: "readSheet", args: map[string]interface{}{"data": "%%%"}, err: "invalid base64"},
+		{name: "Bad filter", tool: "queryRows", args: map[string]interface{}{"path": "sales.csv", "filters": []interface{}{map[string]interface{}{"column": "x", "op": "eq", "value": 1}}}, err: "unknown column"},
+		{name: "Ragged rows", tool: "writeTable", args: map[string]interface{}{"columns": []interface{}{"a"}, "rows": []interface{}{[]interface{}{1, 2}}}, err: "row 1 has 2 values"},
+		{name: "Unknown extension", tool: "writeTable", args: map[string]interface{}{"rows": []interface{}{[]interface{}{1}}, "path": "out.json"}, err: "cannot tell the format"},
+		{name: "Escape", tool: "writeTable", args: map[string]interface{}{"rows": []interface{}{[]interface{}{1}}, "path": "../x.csv", "overwrite": true}, err: ""},
+	}
+	handlers := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"readSheet":  s.handleReadSheet,
+		"queryRows":  s.handleQueryRows,
+		"writeTable": s.handleWriteTable,
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := handlers[tc.tool](ctx, mcptest.NewCallToolRequest(tc.tool, tc.args))
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+	_, err = os.Stat(filepath.Join(dir, "x.csv"))
+	assert.NoError(t, err, "Traversal should be clamped to the data directory")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewSpreadsheetServer(f.TempDir(), 1024, 10, 2048).Server())
+}
@@ -0,0 +1,684 @@
+package spreadsheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cell values are nil, string, float64 or bool. CSV cells are always strings; numbers are
+// recognized when filtering, aggregating and sorting.
+
+// table is a grid of cells with named columns.
+type table struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// columnName returns the spreadsheet letters of a zero-based column index.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// maxColumns is the number of columns of an Excel sheet (A to XFD).
+const maxColumns = 16384
+
+// maxRowNumber is the number of rows of an Excel sheet.
+const maxRowNumber = 1048576
+
+// parseCellRef splits a reference like "B12", "B" or "12" into a zero-based column and row.
+// Missing parts are returned as -1.
+func parseCellRef(ref string) (col, row int, err error) {
+	ref = strings.ToUpper(strings.ReplaceAll(ref, "$", ""))
+	i := 0
+	col, row = -1, -1
+	for i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' {
+		i++
+	}
+	if i > 0 {
+		col = 0
+		for _, c := range ref[:i] {
+			col = col*26 + int(c-'A') + 1
+			if col > maxColumns {
+				return 0, 0, fmt.Errorf("column %s is out of range", ref[:i])
+			}
+		}
+		col--
+	}
+	if i < len(ref) {
+		n, err := strconv.Atoi(ref[i:])
+		if err != nil || n < 1 || n > maxRowNumber {
+			return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+		}
+		row = n - 1
+	}
+	if i == 0 && row < 0 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col, row, nil
+}
+
+// cellRange selects a rectangle of a sheet. Bounds are zero-based and inclusive; -1 leaves
+// a side open.
+type cellRange struct {
+	firstRow, lastRow int
+	firstCol, lastCol int
+}
+
+// fullRange selects the whole sheet.
+var fullRange = cellRange{firstRow: -1, lastRow: -1, firstCol: -1, lastCol: -1}
+
+// parseRange parses ranges like "A1:D20", "B:D", "2:10" or a single cell "C3".
+func parseRange(s string) (cellRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fullRange, nil
+	}
+	start, end, isRange := strings.Cut(s, ":")
+	if !isRange {
+		end = start
+	}
+	startCol, startRow, err := parseCellRef(start)
+	if err != nil {
+		return cellRange{}, err
+	}
+	endCol, endRow, err := parseCellRef(end)
+	if err != nil {
+		return cellRange{}, err
+	}
+	var r cellRange
+	r.firstRow, r.lastRow = rangeBounds(startRow, endRow)
+	r.firstCol, r.lastCol = rangeBounds(startCol, endCol)
+	return r, nil
+}
+
+// rangeBounds orders two bounds. A bound given on one end only, as the row in "A2:C",
+// leaves the other end open.
+func rangeBounds(start, end int) (int, int) {
+	if start >= 0 && end >= 0 {
+		return min(start, end), max(start, end)
+	}
+	return start, end
+}
+
+// containsRow reports whether the zero-based row is inside the range.
+func (r cellRange) containsRow(row int) bool {
+	return (r.firstRow < 0 || row >= r.firstRow) && (r.lastRow < 0 || row <= r.lastRow)
+}
+
+// containsCol reports whether the zero-based column is inside the range.
+func (r cellRange) containsCol(col int) bool {
+	return (r.firstCol < 0 || col >= r.firstCol) && (r.lastCol < 0 || col <= r.lastCol)
+}
+
+// String formats the range in A1 notation, or "" for the whole sheet.
+func (r cellRange) String() string {
+	side := func(col, row int) string {
+		s := ""
+		if col >= 0 {
+			s = columnName(col)
+		}
+		if row >= 0 {
+			s += strconv.Itoa(row + 1)
+		}
+		return s
+	}
+	if r == fullRange {
+		return ""
+	}
+	return side(r.firstCol, r.firstRow) + ":" + side(r.lastCol, r.lastRow)
+}
+
+// newTable builds a table from a grid whose first column is firstCol. With header, the
+// first row names the columns; otherwise columns are named by their letters.
+func newTable(grid [][]interface{}, firstCol int, header bool) *table {
+	// Drop trailing empty rows, which spreadsheets often keep after formatting
+	for len(grid) > 0 && emptyRow(grid[len(grid)-1]) {
+		grid = grid[:len(grid)-1]
+	}
+
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	for len(grid) > 0 && width > 0 && columnEmpty(grid, width-1) {
+		width--
+	}
+
+	t := &table{columns: make([]string, width)}
+	for i := range t.columns {
+		t.columns[i] = columnName(firstCol + i)
+	}
+	if header && len(grid) > 0 {
+		seen := make(map[string]int)
+		for i := range t.columns {
+			name := ""
+			if i < len(grid[0]) {
+				name = strings.TrimSpace(cellString(grid[0][i]))
+			}
+			if name == "" {
+				name = columnName(firstCol + i)
+			}
+			if seen[name]++; seen[name] > 1 {
+				name = fmt.Sprintf("%s_%d", name, seen[name])
+			}
+			t.columns[i] = name
+		}
+		grid = grid[1:]
+	}
+
+	t.rows = make([][]interface{}, len(grid))
+	for i, row := range grid {
+		cells := make([]interface{}, width)
+		copy(cells, row)
+		t.rows[i] = cells
+	}
+	return t
+}
+
+func emptyRow(row []interface{}) bool {
+	for _, value := range row {
+		if !isEmpty(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func columnEmpty(grid [][]interface{}, col int) bool {
+	for _, row := range grid {
+		if col < len(row) && !isEmpty(row[col]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmpty(value interface{}) bool {
+	return value == nil || value == ""
+}
+
+// column returns the index of a column by name, ignoring case when there is no exact match.
+func (t *table) column(name string) (int, error) {
+	for i, column := range t.columns {
+		if column == name {
+			return i, nil
+		}
+	}
+	for i, column := range t.columns {
+		if strings.EqualFold(column, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown column %q (columns: %s)", name, strings.Join(t.columns, ", "))
+}
+
+// cellString formats a cell for text output.
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// toNumber returns the numeric value of a cell. Strings are numbers when they parse as one.
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// compareCells orders numbers numerically and everything else as case-insensitive text.
+// Numbers sort before text and empty cells sort last.
+func compareCells(a, b interface{}) int {
+	switch aEmpty, bEmpty := isEmpty(a), isEmpty(b); {
+	case aEmpty && bEmpty:
+		return 0
+	case aEmpty:
+		return 1
+	case bEmpty:
+		return -1
+	}
+	x, aNumber := toNumber(a)
+	y, bNumber := toNumber(b)
+	switch {
+	case aNumber && bNumber:
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case aNumber:
+		return -1
+	case bNumber:
+		return 1
+	}
+	return strings.Compare(strings.ToLower(cellString(a)), strings.ToLower(cellString(b)))
+}
+
+// rowFilter is a condition on one column.
+type rowFilter struct {
+	Column string      `json:"column"`
+	Op     string      `json:"op"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+// filterOps lists the supported filter operators.
+var filterOps = []string{"eq", "ne", "gt", "gte", "lt", "lte", "contains", "startsWith", "endsWith", "in", "empty", "notEmpty"}
+
+// compile returns a predicate on rows of t.
+func (f rowFilter) compile(t *table) (func([]interface{}) bool, error) {
+	col, err := t.column(f.Column)
+	if err != nil {
+		return nil, err
+	}
+	lower := func(v interface{}) string { return strings.ToLower(cellString(v)) }
+	needsValue := func() error {
+		if f.Value == nil {
+			return fmt.Errorf("filter %q on column %q requires a value", f.Op, f.Column)
+		}
+		if _, ok := f.Value.(map[string]interface{}); ok {
+			return fmt.Errorf("filter value for column %q must be a string, number or boolean", f.Column)
+		}
+		return nil
+	}
+
+	switch f.Op {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		if err := needsValue(); err != nil {
+			return nil, err
+		}
+		if _, ok := f.Value.([]interface{}); ok {
+			return nil, fmt.Errorf("filter %q on column %q requires a single value; use \"in\" for lists", f.Op, f.Column)
+		}
+		test := map[string]func(int) bool{
+			"eq":  func(c int) bool { return c == 0 },
+			"ne":  func(c int) bool { return c != 0 },
+			"gt":  func(c int) bool { return c > 0 },
+			"gte": func(c int) bool { return c >= 0 },
+			"lt":  func(c int) bool { return c < 0 },
+			"lte": func(c int) bool { return c <= 0 },
+		}[f.Op]
+		ordered := f.Op != "eq" && f.Op != "ne"
+		_, valueNumber := toNumber(f.Value)
+		return func(row []interface{}) bool {
+			// Ordering comparisons only match cells of the same kind, so text never
+			// counts as greater than a number
+			if ordered {
+				_, cellNumber := toNumber(row[col])
+				if isEmpty(row[col]) || cellNumber != valueNumber {
+					return false
+				}
+			}
+			return test(compareCells(row[col], f.Value))
+		}, nil
+	case "contains", "startsWith", "endsWith":
+		if err := needsValue(); err != nil {
+			return nil, err
+		}
+		match := map[string]func(string, string) bool{
+			"contains":   strings.Contains,
+			"startsWith": strings.HasPrefix,
+			"endsWith":   strings.HasSuffix,
+		}[f.Op]
+		value := lower(f.Value)
+		return func(row []interface{}) bool { return match(lower(row[col]), value) }, nil
+	case "in":
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter \"in\" on column %q requires a list of values", f.Column)
+		}
+		return func(row []interface{}) bool {
+			for _, value := range values {
+				if compareCells(row[col], value) == 0 {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "empty":
+		return func(row []interface{}) bool { return isEmpty(row[col]) }, nil
+	case "notEmpty":
+		return func(row []interface{}) bool { return !isEmpty(row[col]) }, nil
+	}
+	return nil, fmt.Errorf("unknown filter operator %q (use %s)", f.Op, strings.Join(filterOps, ", "))
+}
+
+// filterRows keeps the rows matching all filters.
+func filterRows(t *table, filters []rowFilter) (*table, error) {
+	if len(filters) == 0 {
+		return t, nil
+	}
+	predicates := make([]func([]interface{}) bool, len(filters))
+	for i, f := range filters {
+		predicate, err := f.compile(t)
+		if err != nil {
+			return nil, err
+		}
+		predicates[i] = predicate
+	}
+
+	filtered := &table{columns: t.columns}
+rows:
+	for _, row := range t.rows {
+		for _, predicate := range predicates {
+			if !predicate(row) {
+				continue rows
+			}
+		}
+		filtered.rows = append(filtered.rows, row)
+	}
+	return filtered, nil
+}
+
+// selectColumns returns a table with only the named columns, in the given order.
+func selectColumns(t *table, names []string) (*table, error) {
+	if len(names) == 0 {
+		return t, nil
+	}
+	indexes := make([]int, len(names))
+	selected := &table{columns: make([]string, len(names)), rows: make([][]interface{}, len(t.rows))}
+	for i, name := range names {
+		col, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = col
+		selected.columns[i] = t.columns[col]
+	}
+	for i, row := range t.rows {
+		cells := make([]interface{}, len(indexes))
+		for j, col := range indexes {
+			cells[j] = row[col]
+		}
+		selected.rows[i] = cells
+	}
+	return selected, nil
+}
+
+// aggregate computes a function over a column of each group.
+type aggregate struct {
+	Column string `json:"column,omitempty"`
+	Func   string `json:"func"`
+	As     string `json:"as,omitempty"`
+}
+
+// aggregateFuncs lists the supported aggregate functions.
+var aggregateFuncs = []string{"count", "countDistinct", "sum", "mean", "min", "max"}
+
+// name returns the output column name of the aggregate.
+func (a aggregate) name() string {
+	if a.As != "" {
+		return a.As
+	}
+	if a.Column == "" {
+		return a.Func
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, a.Column)
+}
+
+// accumulator collects the values of one aggregate in one group.
+type accumulator struct {
+	count    int
+	sum      float64
+	min, max float64
+	numbers  int
+	distinct map[string]bool
+}
+
+// groupRows groups the rows of t by the given columns and computes the aggregates of each
+// group. Without groupBy columns, the aggregates cover all rows. Non-numeric cells are
+// skipped by numeric functions; the number of skipped cells is returned.
+func groupRows(t *table, groupBy []string, aggregates []aggregate) (*table, int, error) {
+	if len(aggregates) == 0 {
+		aggregates = []aggregate{{Func: "count"}}
+	}
+
+	keys := make([]int, len(groupBy))
+	for i, name := range groupBy {
+		col, err := t.column(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys[i] = col
+	}
+	valueCols := make([]int, len(aggregates))
+	result := &table{}
+	for _, col := range keys {
+		result.columns = append(result.columns, t.columns[col])
+	}
+	for i, a := range aggregates {
+		if !containsString(aggregateFuncs, a.Func) {
+			return nil, 0, fmt.Errorf("unknown aggregate function %q (use %s)", a.Func, strings.Join(aggregateFuncs, ", "))
+		}
+		valueCols[i] = -1
+		if a.Column != "" {
+			col, err := t.column(a.Column)
+			if err != nil {
+				return nil, 0, err
+			}
+			valueCols[i] = col
+		} else if a.Func != "count" {
+			return nil, 0, fmt.Errorf("aggregate %q requires a column", a.Func)
+		}
+		result.columns = append(result.columns, a.name())
+	}
+
+	type group struct {
+		values []interface{}
+		accs   []*accumulator
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	// Cells skipped by several aggregates are counted once
+	skipped := make(map[[2]int]bool)
+
+	for r, row := range t.rows {
+		parts := make([]string, len(keys))
+		for i, col := range keys {
+			parts[i] = strings.ToLower(cellString(row[col]))
+		}
+		key := strings.Join(parts, "\x00")
+		g, ok := index[key]
+		if !ok {
+			g = &group{values: make([]interface{}, len(keys)), accs: make([]*accumulator, len(aggregates))}
+			for i, col := range keys {
+				g.values[i] = row[col]
+			}
+			for i := range g.accs {
+				g.accs[i] = &accumulator{distinct: make(map[string]bool)}
+			}
+			index[key] = g
+			groups = append(groups, g)
+		}
+
+		for i, a := range aggregates {
+			acc := g.accs[i]
+			if valueCols[i] < 0 {
+				acc.count++
+				continue
+			}
+			value := row[valueCols[i]]
+			if isEmpty(value) {
+				continue
+			}
+			acc.count++
+			switch a.Func {
+			case "countDistinct":
+				acc.distinct[strings.ToLower(cellString(value))] = true
+			case "sum", "mean", "min", "max":
+				n, ok := toNumber(value)
+				if !ok {
+					skipped[[2]int{r, valueCols[i]}] = true
+					continue
+				}
+				if acc.numbers == 0 || n < acc.min {
+					acc.min = n
+				}
+				if acc.numbers == 0 || n > acc.max {
+					acc.max = n
+				}
+				acc.sum += n
+				acc.numbers++
+			}
+		}
+	}
+
+	// Aggregates over no rows still produce one row of totals
+	if len(groups) == 0 && len(keys) == 0 {
+		g := &group{accs: make([]*accumulator, len(aggregates))}
+		for i := range g.accs {
+			g.accs[i] = &accumulator{distinct: make(map[string]bool)}
+		}
+		groups = append(groups, g)
+	}
+
+	for _, g := range groups {
+		row := append([]interface{}{}, g.values...)
+		for i, a := range aggregates {
+			acc := g.accs[i]
+			var value interface{}
+			switch a.Func {
+			case "count":
+				value = float64(acc.count)
+			case "countDistinct":
+				value = float64(len(acc.distinct))
+			case "sum":
+				value = acc.sum
+			case "mean":
+				if acc.numbers > 0 {
+					value = acc.sum / float64(acc.numbers)
+				}
+			case "min":
+				if acc.numbers > 0 {
+					value = acc.min
+				}
+			case "max":
+				if acc.numbers > 0 {
+					value = acc.max
+				}
+			}
+			row = append(row, value)
+		}
+		result.rows = append(result.rows, row)
+	}
+	return result, len(skipped), nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRows sorts t by a column, keeping the original order of equal rows.
+func sortRows(t *table, column string, descending bool) error {
+	col, err := t.column(column)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		a, b := t.rows[i][col], t.rows[j][col]
+		// Empty cells stay last in both directions
+		if isEmpty(a) || isEmpty(b) {
+			return !isEmpty(a) && isEmpty(b)
+		}
+		if descending {
+			return compareCells(a, b) > 0
+		}
+		return compareCells(a, b) < 0
+	})
+	return nil
+}
+
+// renderTable writes rows [offset, offset+limit) of t as JSON or CSV, stopping early when
+// the output would exceed maxSize bytes. It returns the text and the number of rows written.
+func renderTable(t *table, offset, limit, maxSize int, format string, delimiter rune) (string, int, error) {
+	offset = min(max(offset, 0), len(t.rows))
+	end := min(offset+max(limit, 0), len(t.rows))
+
+	var b strings.Builder
+	written := 0
+	switch format {
+	case "csv":
+		header, err := encodeCSVRecords([][]string{t.columns}, delimiter)
+		if err != nil {
+			return "", 0, err
+		}
+		b.Write(header)
+		for _, row := range t.rows[offset:end] {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = cellString(value)
+			}
+			line, err := encodeCSVRecords([][]string{record}, delimiter)
+			if err != nil {
+				return "", 0, err
+			}
+			if written > 0 && b.Len()+len(line) > maxSize {
+				break
+			}
+			b.Write(line)
+			written++
+		}
+	case "json", "":
+		columns, err := json.Marshal(t.columns)
+		if err != nil {
+			return "", 0, err
+		}
+		b.WriteString("{\n  \"columns\": ")
+		b.Write(columns)
+		b.WriteString(",\n  \"rows\": [")
+		for _, row := range t.rows[offset:end] {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return "", 0, err
+			}
+			if written > 0 && b.Len()+len(data) > maxSize {
+				break
+			}
+			if written > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString("\n    ")
+			b.Write(data)
+			written++
+		}
+		if written > 0 {
+			b.WriteString("\n  ")
+		}
+		fmt.Fprintf(&b, "],\n  \"totalRows\": %d,\n  \"offset\": %d,\n  \"truncated\": %t\n}",
+			len(t.rows), offset, offset+written < len(t.rows))
+	default:
+		return "", 0, fmt.Errorf("unsupported output format %q (use json or csv)", format)
+	}
+	return b.String(), written, nil
+}
@@ -0,0 +1,522 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XLSX files are zip archives of XML parts (Office Open XML). Only the parts needed for
+// cell values are read: the workbook, its relationships, shared strings, styles (to tell
+// dates from numbers) and the worksheets.
+
+const relationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// maxGridCells limits the size of the grid built from a sheet, since a sheet with a single
+// cell at XFD1048576 would otherwise expand to billions of cells.
+const maxGridCells = 5_000_000
+
+// workbook is an opened XLSX file.
+type workbook struct {
+	files         map[string]*zip.File
+	maxPartSize   int64
+	sheets        []sheetInfo
+	sharedStrings []string
+	dateStyles    map[int]bool
+	date1904      bool
+}
+
+// sheetInfo is a worksheet and the archive path of its XML part.
+type sheetInfo struct {
+	name string
+	part string
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Properties struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxText is rich or plain text in shared strings and inline strings.
+type xlsxText struct {
+	T    *string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if t.T != nil {
+		return *t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string    `xml:"r,attr"`
+			T      string    `xml:"t,attr"`
+			S      int       `xml:"s,attr"`
+			V      *string   `xml:"v"`
+			Inline *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// openWorkbook reads the workbook structure of an XLSX file. maxPartSize limits the
+// uncompressed size of each XML part, guarding against zip bombs.
+func openWorkbook(data []byte, maxPartSize int64) (*workbook, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid XLSX file: %w", err)
+	}
+	wb := &workbook{
+		files:       make(map[string]*zip.File),
+		maxPartSize: maxPartSize,
+		dateStyles:  make(map[int]bool),
+	}
+	for _, f := range archive.File {
+		wb.files[f.Name] = f
+	}
+
+	// The package relationships point to the workbook part
+	workbookPart := "xl/workbook.xml"
+	var rootRels xlsxRelationships
+	if err := wb.decodePart("_rels/.rels", &rootRels); err == nil {
+		for _, rel := range rootRels.Relationships {
+			if strings.HasSuffix(rel.Type, "/officeDocument") {
+				workbookPart = resolvePart("", rel.Target)
+			}
+		}
+	}
+
+	var wbXML xlsxWorkbook
+	if err := wb.decodePart(workbookPart, &wbXML); err != nil {
+		return nil, fmt.Errorf("not a valid XLSX file: %w", err)
+	}
+	wb.date1904 = wbXML.Properties.Date1904 == "1" || wbXML.Properties.Date1904 == "true"
+
+	dir := path.Dir(workbookPart)
+	relsPart := path.Join(dir, "_rels", path.Base(workbookPart)+".rels")
+	var rels xlsxRelationships
+	if err := wb.decodePart(relsPart, &rels); err != nil {
+		return nil, fmt.Errorf("not a valid XLSX file: %w", err)
+	}
+	targets := make(map[string]string)
+	for _, rel := range rels.Relationships {
+		target := resolvePart(dir, rel.Target)
+		targets[rel.ID] = target
+		switch {
+		case strings.HasSuffix(rel.Type, "/sharedStrings"):
+			if err := wb.readSharedStrings(target); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(rel.Type, "/styles"):
+			if err := wb.readStyles(target); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, sheet := range wbXML.Sheets {
+		part, ok := targets[sheet.ID]
+		if !ok {
+			return nil, fmt.Errorf("sheet %q has no worksheet part", sheet.Name)
+		}
+		wb.sheets = append(wb.sheets, sheetInfo{name: sheet.Name, part: part})
+	}
+	return wb, nil
+}
+
+// resolvePart resolves a relationship target against the directory of its source part.
+func resolvePart(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return path.Join(dir, target)
+}
+
+// decodePart unmarshals an XML part of the archive.
+func (wb *workbook) decodePart(name string, v interface{}) error {
+	f, ok := wb.files[name]
+	if !ok {
+		return fmt.Errorf("missing part %s", name)
+	}
+	if f.UncompressedSize64 > uint64(wb.maxPartSize) {
+		return fmt.Errorf("part %s exceeds the maximum size of %d bytes", name, wb.maxPartSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	// The declared size can be forged, so the limit is enforced while reading too
+	data, err := io.ReadAll(io.LimitReader(rc, wb.maxPartSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if int64(len(data)) > wb.maxPartSize {
+		return fmt.Errorf("part %s exceeds the maximum size of %d bytes", name, wb.maxPartSize)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid XML in %s: %w", name, err)
+	}
+	return nil
+}
+
+func (wb *workbook) readSharedStrings(part string) error {
+	var sst xlsxSharedStrings
+	if err := wb.decodePart(part, &sst); err != nil {
+		return err
+	}
+	wb.sharedStrings = make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		wb.sharedStrings[i] = item.String()
+	}
+	return nil
+}
+
+// builtinDateFormats are the predefined number format IDs showing dates or times.
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	45: true, 46: true, 47: true,
+}
+
+func (wb *workbook) readStyles(part string) error {
+	var styles xlsxStyles
+	if err := wb.decodePart(part, &styles); err != nil {
+		return err
+	}
+	custom := make(map[int]bool)
+	for _, format := range styles.NumFmts {
+		custom[format.ID] = isDateFormat(format.Code)
+	}
+	for i, xf := range styles.CellXfs {
+		if builtinDateFormats[xf.NumFmtID] || custom[xf.NumFmtID] {
+			wb.dateStyles[i] = true
+		}
+	}
+	return nil
+}
+
+// isDateFormat reports whether a custom number format code shows a date or time, ignoring
+// quoted text, escaped characters and bracketed sections such as colors.
+func isDateFormat(code string) bool {
+	// Only the first section (positive numbers) matters
+	inQuote, inBracket := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inQuote:
+			inQuote = c != '"'
+		case inBracket:
+			inBracket = c != ']'
+		case c == '"':
+			inQuote = true
+		case c == '[':
+			inBracket = true
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		case c == ';':
+			return false
+		case strings.IndexByte("dmyhsDMYHS", c) >= 0:
+			return true
+		}
+	}
+	return false
+}
+
+// sheet returns a sheet by name, ignoring case, or the first sheet when name is empty.
+func (wb *workbook) sheet(name string) (sheetInfo, error) {
+	if len(wb.sheets) == 0 {
+		return sheetInfo{}, errors.New("workbook has no sheets")
+	}
+	if name == "" {
+		return wb.sheets[0], nil
+	}
+	for _, sheet := range wb.sheets {
+		if strings.EqualFold(sheet.name, name) {
+			return sheet, nil
+		}
+	}
+	names := make([]string, len(wb.sheets))
+	for i, sheet := range wb.sheets {
+		names[i] = sheet.name
+	}
+	return sheetInfo{}, fmt.Errorf("sheet %q not found (sheets: %s)", name, strings.Join(names, ", "))
+}
+
+// readGrid returns the cell values of a sheet inside r as a grid starting at the top left
+// corner of r, or at A1 for open sides.
+func (wb *workbook) readGrid(sheet sheetInfo, r cellRange) ([][]interface{}, error) {
+	var ws xlsxWorksheet
+	if err := wb.decodePart(sheet.part, &ws); err != nil {
+		return nil, err
+	}
+
+	type cell struct {
+		row, col int
+		value    interface{}
+	}
+	var cells []cell
+	rows, cols := 0, 0
+	firstRow, firstCol := max(r.firstRow, 0), max(r.firstCol, 0)
+
+	rowIndex := -1
+	for _, row := range ws.Rows {
+		// Row and cell references are optional and default to the next position
+		if row.R > 0 {
+			rowIndex = row.R - 1
+		} else {
+			rowIndex++
+		}
+		colIndex := -1
+		for _, c := range row.Cells {
+			if c.R != "" {
+				col, _, err := parseCellRef(c.R)
+				if err != nil || col < 0 {
+					return nil, fmt.Errorf("sheet %q: invalid cell reference %q", sheet.name, c.R)
+				}
+				colIndex = col
+			} else {
+				colIndex++
+			}
+			if !r.containsRow(rowIndex) || !r.containsCol(colIndex) {
+				continue
+			}
+			value, err := wb.cellValue(c.T, c.S, c.V, c.Inline)
+			if err != nil {
+				return nil, fmt.Errorf("sheet %q cell %s%d: %w", sheet.name, columnName(colIndex), rowIndex+1, err)
+			}
+			if isEmpty(value) {
+				continue
+			}
+			cells = append(cells, cell{row: rowIndex - firstRow, col: colIndex - firstCol, value: value})
+			rows = max(rows, rowIndex-firstRow+1)
+			cols = max(cols, colIndex-firstCol+1)
+		}
+	}
+
+	if rows*cols > maxGridCells {
+		return nil, fmt.Errorf("sheet %q spans %d rows and %d columns, which exceeds the limit of %d cells; read a smaller range",
+			sheet.name, rows, cols, maxGridCells)
+	}
+	grid := make([][]interface{}, rows)
+	for _, c := range cells {
+		if grid[c.row] == nil {
+			grid[c.row] = make([]interface{}, cols)
+		}
+		grid[c.row][c.col] = c.value
+	}
+	return grid, nil
+}
+
+// cellValue converts a cell of the given type and style to a value.
+func (wb *workbook) cellValue(cellType string, style int, v *string, inline *xlsxText) (interface{}, error) {
+	if cellType == "inlineStr" {
+		if inline == nil {
+			return nil, nil
+		}
+		return inline.String(), nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch cellType {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(*v))
+		if err != nil || index < 0 || index >= len(wb.sharedStrings) {
+			return nil, fmt.Errorf("invalid shared string index %q", *v)
+		}
+		return wb.sharedStrings[index], nil
+	case "str", "e", "d":
+		// Formula results, errors like #DIV/0! and ISO 8601 dates are kept as text
+		return *v, nil
+	case "b":
+		return strings.TrimSpace(*v) == "1", nil
+	case "", "n":
+		n, err := strconv.ParseFloat(strings.TrimSpace(*v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", *v)
+		}
+		if wb.dateStyles[style] {
+			return excelDate(n, wb.date1904), nil
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unknown cell type %q", cellType)
+}
+
+// excelDate formats a date serial number as an ISO 8601 date, time or date and time.
+func excelDate(serial float64, date1904 bool) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	switch {
+	case date1904:
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	case serial < 61:
+		// Excel counts the nonexistent 1900-02-29, so earlier serials are off by a day
+		base = base.AddDate(0, 0, 1)
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	t := base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	switch {
+	case seconds == 0:
+		return t.Format("2006-01-02")
+	case days == 0:
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02T15:04:05")
+}
+
+// validSheetName checks the rules Excel applies to sheet names.
+func validSheetName(name string) error {
+	if name == "" || len([]rune(name)) > 31 {
+		return errors.New("sheet names must have 1 to 31 characters")
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) {
+		return fmt.Errorf("sheet name %q must not contain any of [ ] : * ? / \\", name)
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("sheet name %q must not start or end with an apostrophe", name)
+	}
+	return nil
+}
+
+// encodeXLSX writes a table with a header row as a single sheet workbook.
+func encodeXLSX(sheetName string, t *table) ([]byte, error) {
+	if err := validSheetName(sheetName); err != nil {
+		return nil, err
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]interface{}, len(t.columns))
+	for i, column := range t.columns {
+		header[i] = column
+	}
+	if err := writeXLSXRow(&sheet, 0, header); err != nil {
+		return nil, err
+	}
+	for i, row := range t.rows {
+		if err := writeXLSXRow(&sheet, i+1, row); err != nil {
+			return nil, err
+		}
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var escapedName bytes.Buffer
+	if err := xml.EscapeText(&escapedName, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + relationshipsNamespace + `/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + relationshipsNamespace + `">` +
+			`<sheets><sheet name="` + escapedName.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + relationshipsNamespace + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, part := range parts {
+		f, err := w.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeXLSXRow writes a worksheet row. Text is stored as inline strings.
+func writeXLSXRow(b *bytes.Buffer, index int, values []interface{}) error {
+	fmt.Fprintf(b, `<row r="%d">`, index+1)
+	for col, value := range values {
+		ref := columnName(col) + strconv.Itoa(index+1)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case float64:
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return fmt.Errorf("cell %s: %v cannot be stored in a spreadsheet", ref, v)
+			}
+			fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+		case bool:
+			n := 0
+			if v {
+				n = 1
+			}
+			fmt.Fprintf(b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+		default:
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(b, []byte(cellString(v))); err != nil {
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+	return nil
+}
@@ -0,0 +1,42 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readBatteries reads batteries from sysfs, which gopsutil does not cover.
+func readBatteries() ([]battery, error) {
+	dir := filepath.Join(sysRoot(), "class", "power_supply")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	read := func(elem ...string) string {
+		data, _ := os.ReadFile(filepath.Join(append([]string{dir}, elem...)...))
+		return strings.TrimSpace(string(data))
+	}
+	var batteries []battery
+	for _, e := range entries {
+		if read(e.Name(), "type") != "Battery" {
+			continue
+		}
+		b := battery{Name: e.Name(), Status: read(e.Name(), "status")}
+		b.Percent, _ = strconv.ParseFloat(read(e.Name(), "capacity"), 64)
+		batteries = append(batteries, b)
+	}
+	return batteries, nil
+}
+
+// sysRoot honours HOST_SYS like gopsutil does.
+func sysRoot() string {
+	if root := os.Getenv("HOST_SYS"); root != "" {
+		return root
+	}
+	return "/sys"
+}
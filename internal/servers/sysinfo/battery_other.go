@@ -0,0 +1,8 @@
+//go:build !linux
+
+package sysinfo
+
+// readBatteries is only implemented for Linux; gopsutil has no battery support.
+func readBatteries() ([]battery, error) {
+	return nil, errUnsupported
+}
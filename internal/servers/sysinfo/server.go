@@ -0,0 +1,669 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// Sections of a snapshot, in display order
+var sections = []string{"host", "cpu", "load", "memory", "disk", "network", "battery", "processes"}
+
+// SysInfoServer is an MCP server that reports system information and resource usage.
+type SysInfoServer struct {
+	server       *server.MCPServer
+	source       source
+	cpuInterval  time.Duration
+	maxDuration  time.Duration
+	maxProcesses int
+
+	// sleep is replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewSysInfoServer creates a new SysInfoServer instance
+func NewSysInfoServer(src source, cpuInterval, maxDuration, maxProcesses int) *SysInfoServer {
+	log.Printf("SysInfoServer created: cpuInterval=%dms, maxDuration=%ds, maxProcesses=%d", cpuInterval, maxDuration, maxProcesses)
+
+	s := &SysInfoServer{
+		source:       src,
+		cpuInterval:  time.Duration(cpuInterval) * time.Millisecond,
+		maxDuration:  time.Duration(maxDuration) * time.Second,
+		maxProcesses: maxProcesses,
+		sleep:        wait,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"sysinfo-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register systemSnapshot tool
+	snapshotTool := mcp.NewTool("systemSnapshot",
+		mcp.WithDescription("Reports the host, CPU usage, load averages, memory, disks, network traffic, batteries and top processes"),
+		mcp.WithArray("sections",
+			mcp.Description("Sections to include (default: all)"),
+			mcp.Items(map[string]interface{}{"type": "string", "enum": sections}),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("Number of processes to list (default: 5)"),
+		),
+		mcp.WithString("sortBy",
+			mcp.Description("Order of the process list (default: cpu)"),
+			mcp.Enum("cpu", "memory"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register topProcesses tool
+	topProcessesTool := mcp.NewTool("topProcesses",
+		mcp.WithDescription("Lists the processes using the most CPU or memory. CPU usage is measured over a short interval and is relative to one core"),
+		mcp.WithString("sortBy",
+			mcp.Description("Resource to sort by (default: cpu)"),
+			mcp.Enum("cpu", "memory"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Number of processes to list (default: 10, maximum: %d)", maxProcesses)),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Only list processes whose name or command line contains this text"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	// Register sampleSystem tool
+	sampleTool := mcp.NewTool("sampleSystem",
+		mcp.WithDescription("Samples CPU, memory, load and network throughput at regular intervals and reports the time series with minimum, average and maximum"),
+		mcp.WithNumber("interval",
+			mcp.Description("Seconds between samples, from 0.1 (default: 1)"),
+		),
+		mcp.WithNumber("samples",
+			mcp.Description(fmt.Sprintf("Number of samples (default: 5). The total duration is limited to %d seconds", maxDuration)),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, snapshotTool, s.handleSystemSnapshot)
+	middleware.AddTool(mcpServer, topProcessesTool, s.handleTopProcesses)
+	middleware.AddTool(mcpServer, sampleTool, s.handleSampleSystem)
+
+	s.server = mcpServer
+	return s
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// jsonResult renders v as indented JSON.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return textResult(string(data)), nil
+}
+
+// wait sleeps for d unless the request is cancelled first.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// snapshot holds the sections of a systemSnapshot result. Sections that could not be
+// read are reported in Errors.
+type snapshot struct {
+	Host       *hostInfo         `json:"host,omitempty"`
+	CPU        *cpuInfo          `json:"cpu,omitempty"`
+	CPUPercent *float64          `json:"cpuPercent,omitempty"`
+	Load       *loadAvg          `json:"load,omitempty"`
+	Memory     *memInfo          `json:"memory,omitempty"`
+	Disks      []diskUsage       `json:"disks,omitempty"`
+	Network    []netIO           `json:"network,omitempty"`
+	Batteries  []battery         `json:"batteries,omitempty"`
+	Processes  []process         `json:"processes,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+func (snap *snapshot) fail(section string, err error) {
+	if snap.Errors == nil {
+		snap.Errors = make(map[string]string)
+	}
+	snap.Errors[section] = err.Error()
+}
+
+// measure reads CPU and process counters twice, cpuInterval apart, and returns the CPU
+// usage and the processes with their usage over the interval.
+func (s *SysInfoServer) measure(ctx context.Context, withProcesses bool) (float64, []process, error) {
+	cpu, err := s.source.CPU()
+	if err != nil {
+		return 0, nil, err
+	}
+	mem, err := s.source.Memory()
+	if err != nil && withProcesses {
+		return 0, nil, err
+	}
+
+	cpuBefore, err := s.source.CPUTimes()
+	if err != nil {
+		return 0, nil, err
+	}
+	var before []procTimes
+	if withProcesses {
+		if before, err = s.source.Processes(); err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := s.sleep(ctx, s.cpuInterval); err != nil {
+		return 0, nil, err
+	}
+	cpuAfter, err := s.source.CPUTimes()
+	if err != nil {
+		return 0, nil, err
+	}
+	var procs []process
+	if withProcesses {
+		after, err := s.source.Processes()
+		if err != nil {
+			return 0, nil, err
+		}
+		procs = processUsage(before, after, cpuBefore, cpuAfter, cpu.Cores, mem.Total)
+	}
+	return cpuPercent(cpuBefore, cpuAfter), procs, nil
+}
+
+// sortProcesses orders processes by CPU or memory usage, highest first.
+func sortProcesses(procs []process, sortBy string) {
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		if sortBy == "memory" {
+			if a.RSS != b.RSS {
+				return a.RSS > b.RSS
+			}
+			return a.CPUPercent > b.CPUPercent
+		}
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.RSS > b.RSS
+	})
+}
+
+// handleSystemSnapshot handles the snapshot request.
+func (s *SysInfoServer) handleSystemSnapshot(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting systemSnapshot request processing")
+
+	var args struct {
+		Sections []string `json:"sections,omitempty"`
+		Top      int      `json:"top,omitempty"`
+		SortBy   string   `json:"sortBy,omitempty"`
+		Format   string   `json:"format,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+
+	for _, section := range args.Sections {
+		if !slices.Contains(sections, section) {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "unknown section %q; use %s", section, strings.Join(sections, ", "))
+		}
+	}
+	want := func(section string) bool {
+		return len(args.Sections) == 0 || slices.Contains(args.Sections, section)
+	}
+	if args.Top <= 0 {
+		args.Top = 5
+	}
+	args.Top = min(args.Top, s.maxProcesses)
+
+	snap := &snapshot{}
+	if want("host") {
+		if h, err := s.source.Host(); err != nil {
+			snap.fail("host", err)
+		} else {
+			snap.Host = &h
+		}
+	}
+	if want("cpu") || want("processes") {
+		usage, procs, err := s.measure(ctx, want("processes"))
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil:
+			snap.fail("cpu", err)
+		default:
+			if want("cpu") {
+				snap.CPUPercent = &usage
+				if c, err := s.source.CPU(); err == nil {
+					snap.CPU = &c
+				}
+			}
+			sortProcesses(procs, args.SortBy)
+			snap.Processes = procs[:min(len(procs), args.Top)]
+		}
+	}
+	if want("load") {
+		if l, err := s.source.Load(); err != nil {
+			snap.fail("load", err)
+		} else {
+			snap.Load = &l
+		}
+	}
+	if want("memory") {
+		if m, err := s.source.Memory(); err != nil {
+			snap.fail("memory", err)
+		} else {
+			snap.Memory = &m
+		}
+	}
+	if want("disk") {
+		if d, err := s.source.Disks(); err != nil {
+			snap.fail("disk", err)
+		} else {
+			snap.Disks = d
+		}
+	}
+	if want("network") {
+		if n, err := s.source.Network(); err != nil {
+			snap.fail("network", err)
+		} else {
+			snap.Network = n
+		}
+	}
+	if want("battery") {
+		if b, err := s.source.Batteries(); err != nil {
+			snap.fail("battery", err)
+		} else {
+			snap.Batteries = b
+		}
+	}
+
+	log.Printf("systemSnapshot request completed: %d errors", len(snap.Errors))
+	if args.Format == "json" {
+		return jsonResult(snap)
+	}
+	return textResult(formatSnapshot(snap, want, args.SortBy)), nil
+}
+
+// formatSnapshot renders a snapshot as text.
+func formatSnapshot(snap *snapshot, want func(string) bool, sortBy string) string {
+	var b strings.Builder
+	if h := snap.Host; h != nil {
+		fmt.Fprintf(&b, "Host: %s (%s", h.Hostname, h.OS)
+		if h.Platform != "" {
+			fmt.Fprintf(&b, ", %s", h.Platform)
+		}
+		if h.Kernel != "" {
+			fmt.Fprintf(&b, ", kernel %s", h.Kernel)
+		}
+		fmt.Fprintf(&b, ", %s)", h.Arch)
+		if h.Uptime > 0 {
+			fmt.Fprintf(&b, ", up %s", formatDuration(h.Uptime))
+		}
+		b.WriteString("\n")
+	}
+	if snap.CPUPercent != nil {
+		b.WriteString("CPU: ")
+		if c := snap.CPU; c != nil {
+			if c.Model != "" {
+				b.WriteString(c.Model + ", ")
+			}
+			fmt.Fprintf(&b, "%d cores, ", c.Cores)
+		}
+		fmt.Fprintf(&b, "%.1f%% used\n", *snap.CPUPercent)
+	}
+	if l := snap.Load; l != nil {
+		fmt.Fprintf(&b, "Load average: %.2f %.2f %.2f (1, 5, 15 min)\n", l.Load1, l.Load5, l.Load15)
+	}
+	if m := snap.Memory; m != nil {
+		fmt.Fprintf(&b, "Memory: %s of %s used (%.1f%%), %s available\n",
+			formatBytes(m.Used), formatBytes(m.Total), m.UsedPercent, formatBytes(m.Available))
+		if m.SwapTotal > 0 {
+			fmt.Fprintf(&b, "Swap: %s of %s used\n", formatBytes(m.SwapUsed), formatBytes(m.SwapTotal))
+		}
+	}
+	if want("disk") && snap.Disks != nil {
+		b.WriteString("\nDisks:\n")
+		for _, d := range snap.Disks {
+			fmt.Fprintf(&b, "  %-20s %-8s %s of %s used (%.1f%%), %s free\n",
+				d.Mount, d.FSType, formatBytes(d.Used), formatBytes(d.Total), d.UsedPercent, formatBytes(d.Free))
+		}
+	}
+	if want("network") && snap.Network != nil {
+		b.WriteString("\nNetwork (since boot):\n")
+		for _, n := range snap.Network {
+			fmt.Fprintf(&b, "  %-16s received %s, sent %s\n", n.Interface, formatBytes(n.BytesRecv), formatBytes(n.BytesSent))
+		}
+	}
+	if want("battery") && snap.Errors["battery"] == "" {
+		if len(snap.Batteries) == 0 {
+			b.WriteString("\nBattery: none\n")
+		} else {
+			b.WriteString("\nBattery:\n")
+			for _, bat := range snap.Batteries {
+				fmt.Fprintf(&b, "  %-16s %.0f%% (%s)\n", bat.Name, bat.Percent, bat.Status)
+			}
+		}
+	}
+	if want("processes") && snap.Errors["cpu"] == "" {
+		if sortBy != "memory" {
+			sortBy = "cpu"
+		}
+		fmt.Fprintf(&b, "\nTop processes by %s:\n", sortBy)
+		b.WriteString(formatProcesses(snap.Processes))
+	}
+	if len(snap.Errors) > 0 {
+		b.WriteString("\nUnavailable:\n")
+		for _, section := range sections {
+			if msg, ok := snap.Errors[section]; ok {
+				fmt.Fprintf(&b, "  %s: %s\n", section, msg)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatProcesses renders processes as a table.
+func formatProcesses(procs []process) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %7s %6s %6s %10s  %s\n", "PID", "CPU%", "MEM%", "RSS", "COMMAND")
+	for _, p := range procs {
+		command := p.Command
+		if command == "" {
+			command = p.Name
+		}
+		if len(command) > 80 {
+			command = command[:77] + "..."
+		}
+		fmt.Fprintf(&b, "  %7d %6.1f %6.1f %10s  %s\n", p.PID, p.CPUPercent, p.MemPercent, formatBytes(p.RSS), command)
+	}
+	return b.String()
+}
+
+// handleTopProcesses handles the process listing request.
+func (s *SysInfoServer) handleTopProcesses(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting topProcesses request processing")
+
+	var args struct {
+		SortBy string `json:"sortBy,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
+		Filter string `json:"filter,omitempty"`
+		Format string `json:"format,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+	args.Limit = min(args.Limit, s.maxProcesses)
+
+	_, procs, err := s.measure(ctx, true)
+	if err != nil {
+		log.Printf("Error: Failed to read processes: %v", err)
+		return nil, fmt.Errorf("failed to read processes: %w", err)
+	}
+	total := len(procs)
+	if args.Filter != "" {
+		filter := strings.ToLower(args.Filter)
+		procs = slices.DeleteFunc(procs, func(p process) bool {
+			return !strings.Contains(strings.ToLower(p.Name), filter) && !strings.Contains(strings.ToLower(p.Command), filter)
+		})
+	}
+	matched := len(procs)
+	sortProcesses(procs, args.SortBy)
+	procs = procs[:min(len(procs), args.Limit)]
+
+	log.Printf("topProcesses request completed: %d of %d processes", len(procs), total)
+	if args.Format == "json" {
+		return jsonResult(procs)
+	}
+	if matched == 0 {
+		return textResult(fmt.Sprintf("No processes match %q", args.Filter)), nil
+	}
+	summary := fmt.Sprintf("%d processes", total)
+	if args.Filter != "" {
+		summary = fmt.Sprintf("%d of %d processes match %q", matched, total, args.Filter)
+	}
+	return textResult(fmt.Sprintf("%s, showing %d, CPU measured over %s:\n%s",
+		summary, len(procs), s.cpuInterval, strings.TrimRight(formatProcesses(procs), "\n"))), nil
+}
+
+// sample is one point of a sampleSystem time series. Rates are per second over the
+// preceding interval.
+type sample struct {
+	Elapsed    float64 `json:"elapsedSeconds"`
+	CPUPercent float64 `json:"cpuPercent"`
+	MemPercent float64 `json:"memPercent"`
+	Load1      float64 `json:"load1"`
+	RecvRate   float64 `json:"recvBytesPerSecond"`
+	SentRate   float64 `json:"sentBytesPerSecond"`
+}
+
+// reading holds the counters taken at one point in time.
+type reading struct {
+	at         time.Time
+	cpu        cpuTimes
+	recv, sent uint64
+}
+
+func (s *SysInfoServer) read() (reading, error) {
+	r := reading{at: time.Now()}
+	var err error
+	if r.cpu, err = s.source.CPUTimes(); err != nil {
+		return r, err
+	}
+	// Network counters are optional
+	if interfaces, err := s.source.Network(); err == nil {
+		for _, n := range interfaces {
+			r.recv += n.BytesRecv
+			r.sent += n.BytesSent
+		}
+	}
+	return r, nil
+}
+
+// handleSampleSystem handles the sampling request.
+func (s *SysInfoServer) handleSampleSystem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sampleSystem request processing")
+
+	var args struct {
+		Interval float64 `json:"interval,omitempty"`
+		Samples  int     `json:"samples,omitempty"`
+		Format   string  `json:"format,omitempty"`
+	}
+	if err := params.Decode(req, &args); err != nil {
+		return nil, err
+	}
+	if args.Interval == 0 {
+		args.Interval = 1
+	}
+	if args.Samples == 0 {
+		args.Samples = 5
+	}
+	if args.Interval < 0.1 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "interval must be at least 0.1 seconds")
+	}
+	if args.Samples < 1 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "samples must be positive")
+	}
+	// The total is computed in seconds, as huge intervals overflow a time.Duration
+	if total := args.Interval * float64(args.Samples); total > s.maxDuration.Seconds() {
+		return nil, fmt.Errorf("sampling for %gs exceeds the maximum duration of %s", total, s.maxDuration)
+	}
+	interval := time.Duration(args.Interval * float64(time.Second))
+
+	start, err := s.read()
+	if err != nil {
+		log.Printf("Error: Failed to read counters: %v", err)
+		return nil, fmt.Errorf("failed to read system counters: %w", err)
+	}
+	previous := start
+	var samples []sample
+	for i := 0; i < args.Samples; i++ {
+		if err := s.sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		current, err := s.read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read system counters: %w", err)
+		}
+		seconds := current.at.Sub(previous.at).Seconds()
+		point := sample{
+			Elapsed:    current.at.Sub(start.at).Seconds(),
+			CPUPercent: cpuPercent(previous.cpu, current.cpu),
+		}
+		if current.recv >= previous.recv && current.sent >= previous.sent && seconds > 0 {
+			point.RecvRate = float64(current.recv-previous.recv) / seconds
+			point.SentRate = float64(current.sent-previous.sent) / seconds
+		}
+		if m, err := s.source.Memory(); err == nil {
+			point.MemPercent = m.UsedPercent
+		}
+		if l, err := s.source.Load(); err == nil {
+			point.Load1 = l.Load1
+		}
+		samples = append(samples, point)
+		previous = current
+	}
+
+	log.Printf("sampleSystem request completed: %d samples", len(samples))
+	if args.Format == "json" {
+		return jsonResult(samples)
+	}
+	return textResult(formatSamples(samples, interval)), nil
+}
+
+// formatSamples renders a time series with a summary.
+func formatSamples(samples []sample, interval time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d samples every %s:\n\n", len(samples), interval)
+	fmt.Fprintf(&b, "%8s %6s %6s %6s %12s %12s\n", "TIME", "CPU%", "MEM%", "LOAD1", "RECV/s", "SENT/s")
+	for _, p := range samples {
+		fmt.Fprintf(&b, "%7.1fs %6.1f %6.1f %6.2f %12s %12s\n",
+			p.Elapsed, p.CPUPercent, p.MemPercent, p.Load1, formatBytes(uint64(p.RecvRate)), formatBytes(uint64(p.SentRate)))
+	}
+
+	stats := func(name string, value func(sample) float64, format func(float64) string) {
+		lo, hi, sum := value(samples[0]), value(samples[0]), 0.0
+		for _, p := range samples {
+			v := value(p)
+			lo, hi, sum = min(lo, v), max(hi, v), sum+v
+		}
+		fmt.Fprintf(&b, "\n%-8s min %s, avg %s, max %s", name, format(lo), format(sum/float64(len(samples))), format(hi))
+	}
+	percent := func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+	rate := func(v float64) string { return formatBytes(uint64(v)) + "/s" }
+	stats("CPU:", func(p sample) float64 { return p.CPUPercent }, percent)
+	stats("Memory:", func(p sample) float64 { return p.MemPercent }, percent)
+	stats("Recv:", func(p sample) float64 { return p.RecvRate }, rate)
+	stats("Sent:", func(p sample) float64 { return p.SentRate }, rate)
+	return b.String()
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *SysInfoServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the sysinfo server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("sysinfo", flag.ContinueOnError)
+	var (
+		cpuInterval  int
+		maxDuration  int
+		maxProcesses int
+	)
+	fs.IntVar(&cpuInterval, "cpu-interval", 500, "Interval over which CPU usage is measured in milliseconds")
+	fs.IntVar(&maxDuration, "max-duration", 60, "Maximum duration of a sampleSystem call in seconds")
+	fs.IntVar(&maxProcesses, "max-processes", 50, "Maximum number of processes listed")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting sysinfo server: cpu-interval=%dms, max-duration=%ds", cpuInterval, maxDuration)
+
+	// Create SysInfoServer instance
+	sysInfoServer := NewSysInfoServer(newSource(), cpuInterval, maxDuration, maxProcesses)
+	log.Println("SysInfoServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), sysInfoServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return sysInfoServer.Server(), transportFlags, nil
+}
+
+// Run starts the sysinfo server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[SysInfoServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create sysinfo server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("SysInfoServer shutdown")
+	return nil
+}
@@ -0,0 +1,276 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource advances its counters on every reading, as a busy machine would.
+type fakeSource struct {
+	cpuCalls  int
+	procCalls int
+	netCalls  int
+	failDisks bool
+}
+
+func (f *fakeSource) Host() (hostInfo, error) {
+	return hostInfo{Hostname: "build01", OS: "linux", Platform: "Ubuntu 24.04 LTS", Kernel: "6.8.0", Arch: "amd64", Uptime: 26*time.Hour + 5*time.Minute}, nil
+}
+
+func (f *fakeSource) CPU() (cpuInfo, error) {
+	return cpuInfo{Model: "Test CPU", Cores: 4}, nil
+}
+
+// CPUTimes advances 400 seconds per reading, 100 of them idle: 75% usage
+func (f *fakeSource) CPUTimes() (cpuTimes, error) {
+	f.cpuCalls++
+	return cpuTimes{Total: float64(f.cpuCalls) * 400, Idle: float64(f.cpuCalls) * 100}, nil
+}
+
+func (f *fakeSource) Memory() (memInfo, error) {
+	return memInfo{Total: 8 << 30, Available: 6 << 30, Used: 2 << 30, UsedPercent: 25, SwapTotal: 1 << 30}, nil
+}
+
+func (f *fakeSource) Disks() ([]diskUsage, error) {
+	if f.failDisks {
+		return nil, errors.New("permission denied")
+	}
+	return []diskUsage{{Mount: "/", Device: "/dev/sda1", FSType: "ext4", Total: 100 << 30, Used: 40 << 30, Free: 60 << 30, UsedPercent: 40}}, nil
+}
+
+// Network receives 1000 bytes and sends 500 bytes between readings
+func (f *fakeSource) Network() ([]netIO, error) {
+	f.netCalls++
+	return []netIO{{Interface: "eth0", BytesRecv: uint64(f.netCalls) * 1000, BytesSent: uint64(f.netCalls) * 500}}, nil
+}
+
+func (f *fakeSource) Load() (loadAvg, error) {
+	return loadAvg{Load1: 1.5, Load5: 1.25, Load15: 1}, nil
+}
+
+func (f *fakeSource) Batteries() ([]battery, error) {
+	return nil, nil
+}
+
+// Processes: "busy" uses a full core, "idle" nothing, "big" much memory
+func (f *fakeSource) Processes() ([]procTimes, error) {
+	f.procCalls++
+	n := float64(f.procCalls)
+	return []procTimes{
+		{PID: 1, Name: "idle", Command: "/sbin/init", RSS: 10 << 20, CPUTime: 5},
+		{PID: 42, Name: "busy", Command: "busy --loop", RSS: 100 << 20, CPUTime: n * 100},
+		{PID: 99, Name: "big", Command: "big-database", RSS: 4 << 30, CPUTime: n * 20},
+	}, nil
+}
+
+// SysInfoServer creation test
+func TestNewSysInfoServer(t *testing.T) {
+	src := &fakeSource{}
+	s := NewSysInfoServer(src, 10, 5, 20)
+
+	assert.NotNil(t, s, "SysInfoServer instance should be created")
+	assert.Equal(t, src, s.source, "Source should match")
+	assert.Equal(t, 10*time.Millisecond, s.cpuInterval, "CPU interval should match")
+	assert.Equal(t, 5*time.Second, s.maxDuration, "Max duration should match")
+	assert.Equal(t, 20, s.maxProcesses, "Max processes should match")
+	assert.NotNil(t, s.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	s := NewSysInfoServer(&fakeSource{}, 10, 5, 20)
+	assert.NotNil(t, s.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test CPU usage and formatting helpers
+func TestHelpers(t *testing.T) {
+	t.Run("cpu percent", func(t *testing.T) {
+		times := cpuTimes{Total: 1000, Idle: 840}
+		assert.InDelta(t, 50.0, cpuPercent(times, cpuTimes{Total: 1200, Idle: 940}), 0.001)
+		assert.Equal(t, 0.0, cpuPercent(times, times))
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		assert.Equal(t, "512 B", formatBytes(512))
+		assert.Equal(t, "1.5 KiB", formatBytes(1536))
+		assert.Equal(t, "2.0 GiB", formatBytes(2<<30))
+		assert.Equal(t, "1d 2h 5m", formatDuration(26*time.Hour+5*time.Minute))
+		assert.Equal(t, "42m", formatDuration(42*time.Minute))
+	})
+}
+
+// Test process usage over an interval
+func TestProcessUsage(t *testing.T) {
+	before := []procTimes{{PID: 1, CPUTime: 100}, {PID: 2, CPUTime: 0}}
+	after := []procTimes{{PID: 1, CPUTime: 300, RSS: 512}, {PID: 2, CPUTime: 50}, {PID: 3, CPUTime: 1000}}
+	// 800 seconds over 4 cores: 200 seconds per core
+	procs := processUsage(before, after, cpuTimes{Total: 0}, cpuTimes{Total: 800}, 4, 1024)
+
+	require.Len(t, procs, 3)
+	assert.Equal(t, 100.0, procs[0].CPUPercent)
+	assert.Equal(t, 50.0, procs[0].MemPercent)
+	assert.Equal(t, 25.0, procs[1].CPUPercent)
+	assert.Equal(t, 0.0, procs[2].CPUPercent, "Processes started during the interval have no usage yet")
+}
+
+// Test systemSnapshot handler
+func TestHandleSystemSnapshot(t *testing.T) {
+	s := NewSysInfoServer(&fakeSource{}, 1, 5, 20)
+
+	result, err := s.handleSystemSnapshot(context.Background(), mcptest.NewCallToolRequest("systemSnapshot", nil))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	for _, expected := range []string{
+		"Host: build01 (linux, Ubuntu 24.04 LTS, kernel 6.8.0, amd64), up 1d 2h 5m",
+		"CPU: Test CPU, 4 cores, 75.0% used",
+		"Load average: 1.50 1.25 1.00 (1, 5, 15 min)",
+		"Memory: 2.0 GiB of 8.0 GiB used (25.0%), 6.0 GiB available",
+		"ext4     40.0 GiB of 100.0 GiB used (40.0%), 60.0 GiB free",
+		"eth0             received 1000 B, sent 500 B",
+		"Battery: none",
+		"Top processes by cpu:",
+	} {
+		assert.Contains(t, text, expected)
+	}
+	// The busy process used all 100 seconds that elapsed per core
+	lines := strings.Split(text[strings.Index(text, "Top processes"):], "\n")
+	assert.Contains(t, lines[2], "busy --loop")
+	assert.Contains(t, lines[2], "100.0")
+
+	t.Run("sections and memory order", func(t *testing.T) {
+		result, err := s.handleSystemSnapshot(context.Background(), mcptest.NewCallToolRequest("systemSnapshot", map[string]interface{}{
+			"sections": []interface{}{"processes"},
+			"sortBy":   "memory",
+			"top":      1,
+		}))
+		require.NoError(t, err)
+		text := mcptest.ResultText(result)
+		assert.NotContains(t, text, "Host:")
+		assert.NotContains(t, text, "CPU:")
+		assert.Contains(t, text, "Top processes by memory:")
+		assert.Contains(t, text, "big-database")
+		assert.NotContains(t, text, "busy")
+	})
+
+	t.Run("json with errors", func(t *testing.T) {
+		s := NewSysInfoServer(&fakeSource{failDisks: true}, 1, 5, 20)
+		result, err := s.handleSystemSnapshot(context.Background(), mcptest.NewCallToolRequest("systemSnapshot", map[string]interface{}{
+			"sections": []interface{}{"memory", "disk"},
+			"format":   "json",
+		}))
+		require.NoError(t, err)
+		var snap snapshot
+		require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &snap))
+		assert.Equal(t, 25.0, snap.Memory.UsedPercent)
+		assert.Nil(t, snap.Host)
+		assert.Equal(t, "permission denied", snap.Errors["disk"])
+	})
+
+	t.Run("unknown section", func(t *testing.T) {
+		_, err := s.handleSystemSnapshot(context.Background(), mcptest.NewCallToolRequest("systemSnapshot", map[string]interface{}{
+			"sections": []interface{}{"gpu"},
+		}))
+		assert.ErrorContains(t, err, "unknown section")
+	})
+}
+
+// Test topProcesses handler
+func TestHandleTopProcesses(t *testing.T) {
+	s := NewSysInfoServer(&fakeSource{}, 1, 5, 2)
+
+	result, err := s.handleTopProcesses(context.Background(), mcptest.NewCallToolRequest("topProcesses", nil))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "3 processes, showing 2")
+	assert.Less(t, strings.Index(text, "busy"), strings.Index(text, "big-database"))
+	assert.NotContains(t, text, "/sbin/init")
+
+	result, err = s.handleTopProcesses(context.Background(), mcptest.NewCallToolRequest("topProcesses", map[string]interface{}{
+		"filter": "DATA",
+		"format": "json",
+	}))
+	require.NoError(t, err)
+	var procs []process
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &procs))
+	require.Len(t, procs, 1)
+	assert.Equal(t, 99, procs[0].PID)
+	assert.Equal(t, 50.0, procs[0].MemPercent)
+
+	result, err = s.handleTopProcesses(context.Background(), mcptest.NewCallToolRequest("topProcesses", map[string]interface{}{
+		"filter": "nothing",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, `No processes match "nothing"`, mcptest.ResultText(result))
+}
+
+// Test sampleSystem handler
+func TestHandleSampleSystem(t *testing.T) {
+	s := NewSysInfoServer(&fakeSource{}, 1, 1, 20)
+
+	result, err := s.handleSampleSystem(context.Background(), mcptest.NewCallToolRequest("sampleSystem", map[string]interface{}{
+		"interval": 0.1,
+		"samples":  3,
+		"format":   "json",
+	}))
+	require.NoError(t, err)
+	var samples []sample
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &samples))
+	require.Len(t, samples, 3)
+	for i, p := range samples {
+		assert.Equal(t, 75.0, p.CPUPercent)
+		assert.Equal(t, 25.0, p.MemPercent)
+		assert.Equal(t, 1.5, p.Load1)
+		assert.Greater(t, p.RecvRate, p.SentRate)
+		if i > 0 {
+			assert.Greater(t, p.Elapsed, samples[i-1].Elapsed)
+		}
+	}
+
+	result, err = s.handleSampleSystem(context.Background(), mcptest.NewCallToolRequest("sampleSystem", map[string]interface{}{
+		"interval": 0.1,
+		"samples":  2,
+	}))
+	require.NoError(t, err)
+	text := mcptest.ResultText(result)
+	assert.Contains(t, text, "2 samples every 100ms:")
+	assert.Contains(t, text, "CPU:     min 75.0%, avg 75.0%, max 75.0%")
+
+	errorTests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"too long", map[string]interface{}{"interval": 1, "samples": 5}, "exceeds the maximum duration"},
+		{"huge interval", map[string]interface{}{"interval": 1e308}, "exceeds the maximum duration"},
+		{"interval too short", map[string]interface{}{"interval": 0.01}, "at least 0.1 seconds"},
+		{"negative samples", map[string]interface{}{"samples": -1}, "samples must be positive"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.handleSampleSystem(context.Background(), mcptest.NewCallToolRequest("sampleSystem", tt.args))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.handleSampleSystem(ctx, mcptest.NewCallToolRequest("sampleSystem", map[string]interface{}{"interval": 0.5, "samples": 1}))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	s := NewSysInfoServer(&fakeSource{}, 1, 5, 20)
+	s.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	mcptest.FuzzTools(f, s.Server())
+}
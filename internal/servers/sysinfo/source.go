@@ -0,0 +1,183 @@
+package sysinfo
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+	ps "github.com/shirou/gopsutil/v4/process"
+)
+
+// psSource reads statistics with gopsutil, which supports Linux, macOS, Windows and the
+// BSDs. Set HOST_PROC, HOST_SYS and HOST_ETC to read a host /proc mounted elsewhere,
+// such as in a container.
+type psSource struct{}
+
+func newSource() source {
+	return psSource{}
+}
+
+func (psSource) Host() (hostInfo, error) {
+	info, err := host.Info()
+	if err != nil {
+		return hostInfo{OS: runtime.GOOS, Arch: runtime.GOARCH}, err
+	}
+	h := hostInfo{
+		Hostname: info.Hostname,
+		OS:       info.OS,
+		Platform: strings.TrimSpace(info.Platform + " " + info.PlatformVersion),
+		Kernel:   info.KernelVersion,
+		Arch:     info.KernelArch,
+		Uptime:   time.Duration(info.Uptime) * time.Second,
+	}
+	if h.Arch == "" {
+		h.Arch = runtime.GOARCH
+	}
+	return h, nil
+}
+
+func (psSource) CPU() (cpuInfo, error) {
+	info := cpuInfo{Cores: runtime.NumCPU()}
+	if cores, err := cpu.Counts(true); err == nil && cores > 0 {
+		info.Cores = cores
+	}
+	stats, err := cpu.Info()
+	if err != nil {
+		return info, err
+	}
+	if len(stats) > 0 {
+		info.Model = strings.TrimSpace(stats[0].ModelName)
+	}
+	return info, nil
+}
+
+func (psSource) CPUTimes() (cpuTimes, error) {
+	times, err := cpu.Times(false)
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	if len(times) == 0 {
+		return cpuTimes{}, errUnsupported
+	}
+	t := times[0]
+	// Guest time is already counted in user time
+	return cpuTimes{
+		Total: t.User + t.Nice + t.System + t.Idle + t.Iowait + t.Irq + t.Softirq + t.Steal,
+		Idle:  t.Idle + t.Iowait,
+	}, nil
+}
+
+func (psSource) Memory() (memInfo, error) {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return memInfo{}, err
+	}
+	m := memInfo{
+		Total:       vm.Total,
+		Available:   vm.Available,
+		Used:        vm.Total - min(vm.Available, vm.Total),
+		UsedPercent: vm.UsedPercent,
+	}
+	if vm.Total > 0 {
+		m.UsedPercent = 100 * float64(m.Used) / float64(vm.Total)
+	}
+	if swap, err := mem.SwapMemory(); err == nil {
+		m.SwapTotal = swap.Total
+		m.SwapUsed = swap.Used
+	}
+	return m, nil
+}
+
+func (psSource) Disks() ([]diskUsage, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+	var disks []diskUsage
+	seen := make(map[string]bool)
+	for _, p := range partitions {
+		// Bind mounts and btrfs subvolumes repeat the same device
+		if seen[p.Device] {
+			continue
+		}
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		seen[p.Device] = true
+		disks = append(disks, diskUsage{
+			Mount:       p.Mountpoint,
+			Device:      p.Device,
+			FSType:      p.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	return disks, nil
+}
+
+func (psSource) Network() ([]netIO, error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+	var result []netIO
+	for _, c := range counters {
+		if c.Name == "lo" || c.Name == "lo0" {
+			continue
+		}
+		result = append(result, netIO{
+			Interface:   c.Name,
+			BytesRecv:   c.BytesRecv,
+			BytesSent:   c.BytesSent,
+			PacketsRecv: c.PacketsRecv,
+			PacketsSent: c.PacketsSent,
+		})
+	}
+	return result, nil
+}
+
+func (psSource) Load() (loadAvg, error) {
+	avg, err := load.Avg()
+	if err != nil {
+		return loadAvg{}, err
+	}
+	return loadAvg{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}, nil
+}
+
+func (psSource) Batteries() ([]battery, error) {
+	return readBatteries()
+}
+
+func (psSource) Processes() ([]procTimes, error) {
+	procs, err := ps.Processes()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]procTimes, 0, len(procs))
+	for _, p := range procs {
+		// Processes may exit while being read
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		pt := procTimes{PID: int(p.Pid), Name: name}
+		if times, err := p.Times(); err == nil {
+			pt.CPUTime = times.User + times.System
+		}
+		if memory, err := p.MemoryInfo(); err == nil {
+			pt.RSS = memory.RSS
+		}
+		pt.Command, _ = p.Cmdline()
+		result = append(result, pt)
+	}
+	return result, nil
+}
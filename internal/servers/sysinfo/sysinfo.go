@@ -0,0 +1,172 @@
+package sysinfo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errUnsupported is returned for statistics the platform does not provide.
+var errUnsupported = errors.New("not supported on this platform")
+
+// hostInfo describes the machine and operating system.
+type hostInfo struct {
+	Hostname string        `json:"hostname"`
+	OS       string        `json:"os"`
+	Platform string        `json:"platform,omitempty"`
+	Kernel   string        `json:"kernel,omitempty"`
+	Arch     string        `json:"arch"`
+	Uptime   time.Duration `json:"uptimeSeconds"`
+}
+
+// cpuInfo describes the processors.
+type cpuInfo struct {
+	Model string `json:"model,omitempty"`
+	Cores int    `json:"cores"`
+}
+
+// cpuTimes holds cumulative CPU time counters in seconds.
+type cpuTimes struct {
+	Total float64
+	Idle  float64
+}
+
+// memInfo describes physical memory and swap in bytes.
+type memInfo struct {
+	Total       uint64  `json:"total"`
+	Available   uint64  `json:"available"`
+	Used        uint64  `json:"used"`
+	UsedPercent float64 `json:"usedPercent"`
+	SwapTotal   uint64  `json:"swapTotal"`
+	SwapUsed    uint64  `json:"swapUsed"`
+}
+
+// diskUsage describes a mounted filesystem in bytes.
+type diskUsage struct {
+	Mount       string  `json:"mount"`
+	Device      string  `json:"device"`
+	FSType      string  `json:"fsType"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// netIO holds cumulative traffic counters of a network interface.
+type netIO struct {
+	Interface   string `json:"interface"`
+	BytesRecv   uint64 `json:"bytesRecv"`
+	BytesSent   uint64 `json:"bytesSent"`
+	PacketsRecv uint64 `json:"packetsRecv"`
+	PacketsSent uint64 `json:"packetsSent"`
+}
+
+// loadAvg holds the 1, 5 and 15 minute load averages.
+type loadAvg struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// battery describes a battery.
+type battery struct {
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent"`
+	Status  string  `json:"status"`
+}
+
+// procTimes is a process with its cumulative CPU time in seconds.
+type procTimes struct {
+	PID     int     `json:"pid"`
+	Name    string  `json:"name"`
+	Command string  `json:"command,omitempty"`
+	RSS     uint64  `json:"rss"`
+	CPUTime float64 `json:"-"`
+}
+
+// process is a process with its resource usage over a sampling interval.
+type process struct {
+	procTimes
+	CPUPercent float64 `json:"cpuPercent"`
+	MemPercent float64 `json:"memPercent"`
+}
+
+// source provides raw system statistics. Counters are cumulative; callers take two
+// readings to compute rates and percentages.
+type source interface {
+	Host() (hostInfo, error)
+	CPU() (cpuInfo, error)
+	CPUTimes() (cpuTimes, error)
+	Memory() (memInfo, error)
+	Disks() ([]diskUsage, error)
+	Network() ([]netIO, error)
+	Load() (loadAvg, error)
+	Batteries() ([]battery, error)
+	Processes() ([]procTimes, error)
+}
+
+// cpuPercent returns the share of non-idle time between two readings.
+func cpuPercent(before, after cpuTimes) float64 {
+	total := after.Total - before.Total
+	if after.Total <= before.Total {
+		return 0
+	}
+	idle := after.Idle - before.Idle
+	return clampPercent(100 * (total - idle) / total)
+}
+
+// processUsage computes the CPU and memory usage of the processes in after. CPU usage is
+// relative to one core, as top reports it, so busy multi-threaded processes exceed 100%.
+func processUsage(before, after []procTimes, cpuBefore, cpuAfter cpuTimes, cores int, memTotal uint64) []process {
+	previous := make(map[int]float64, len(before))
+	for _, p := range before {
+		previous[p.PID] = p.CPUTime
+	}
+	// CPU time that elapsed on a single core during the interval
+	perCore := (cpuAfter.Total - cpuBefore.Total) / float64(max(cores, 1))
+
+	result := make([]process, 0, len(after))
+	for _, p := range after {
+		proc := process{procTimes: p}
+		if start, ok := previous[p.PID]; ok && perCore > 0 && p.CPUTime >= start {
+			proc.CPUPercent = 100 * (p.CPUTime - start) / perCore
+		}
+		if memTotal > 0 {
+			proc.MemPercent = clampPercent(100 * float64(p.RSS) / float64(memTotal))
+		}
+		result = append(result, proc)
+	}
+	return result
+}
+
+func clampPercent(p float64) float64 {
+	return min(max(p, 0), 100)
+}
+
+// formatBytes renders a byte count with binary units.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit && exp < 5; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatDuration renders an uptime as days, hours and minutes.
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	days, hours := minutes/(24*60), minutes/60%24
+	minutes %= 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
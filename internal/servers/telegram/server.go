@@ -0,0 +1,685 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/sandbox"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+const defaultAPIURL = "https://api.telegram.org"
+
+// Message is the subset of a Telegram message returned by getUpdates
+type Message struct {
+	MessageID int   `json:"message_id"`
+	Date      int64 `json:"date"`
+	Chat      struct {
+		ID    int64  `json:"id"`
+		Type  string `json:"type"`
+		Title string `json:"title"`
+	} `json:"chat"`
+	From *struct {
+		ID        int64  `json:"id"`
+		Username  string `json:"username"`
+		FirstName string `json:"first_name"`
+	} `json:"from"`
+	Text     string `json:"text"`
+	Caption  string `json:"caption"`
+	Document *struct {
+		FileName string `json:"file_name"`
+	} `json:"document"`
+	Photo []struct {
+		FileID string `json:"file_id"`
+	} `json:"photo"`
+}
+
+// Update is an incoming update from getUpdates
+type Update struct {
+	UpdateID      int      `json:"update_id"`
+	Message       *Message `json:"message"`
+	ChannelPost   *Message `json:"channel_post"`
+	EditedMessage *Message `json:"edited_message"`
+}
+
+// TelegramServer is an MCP server that sends and receives messages through a Telegram bot.
+type TelegramServer struct {
+	server   *server.MCPServer
+	client   *http.Client
+	apiURL   string
+	token    string
+	chats    map[string]int64 // alias -> chat ID
+	filesDir string
+	files    *sandbox.Sandbox
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	offset int // Next update_id to request, acknowledging earlier updates
+}
+
+// NewTelegramServer creates a new TelegramServer instance. Only chats listed in chats can be
+// messaged or read; files are only sent from filesDir.
+func NewTelegramServer(apiURL, token string, chats map[string]int64, filesDir string, timeout int, maxFileSize int64) *TelegramServer {
+	log.Printf("TelegramServer created: chats=%d, filesDir=%s, timeout=%ds", len(chats), filesDir, timeout)
+
+	s := &TelegramServer{
+		// Long polling holds requests open, so the client timeout leaves room beyond the poll timeout
+		client:   httpclient.New(httpclient.Options{Timeout: time.Duration(timeout)*time.Second + 60*time.Second}),
+		apiURL:   strings.TrimSuffix(apiURL, "/"),
+		token:    token,
+		chats:    chats,
+		filesDir: filesDir,
+		files:    sandbox.New(filesDir, "files directory", sandbox.Options{MaxFileSize: maxFileSize}),
+		timeout:  time.Duration(timeout) * time.Second,
+	}
+
+	var names []string
+	for name := range chats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	chatDescription := "Chat alias or ID"
+	if len(names) > 0 {
+		chatDescription = fmt.Sprintf("Chat alias (%s) or ID", strings.Join(names, ", "))
+	}
+
+	mcpServer := server.NewMCPServer(
+		"telegram-server", // server name
+		"1.0.0",           // version
+	)
+
+	// Register sendMessage tool
+	messageTool := mcp.NewTool("sendMessage",
+		mcp.WithDescription("Sends a text message to a configured chat"),
+		mcp.WithString("chat",
+			mcp.Description(chatDescription),
+			mcp.Required(),
+		),
+		mcp.WithString("text",
+			mcp.Description("Message text (up to 4096 characters)"),
+			mcp.Required(),
+		),
+		mcp.WithString("parseMode",
+			mcp.Description("Optional formatting mode"),
+			mcp.Enum("MarkdownV2", "HTML"),
+		),
+		mcp.WithBoolean("silent",
+			mcp.Description("Send without a notification sound"),
+		),
+	)
+
+	// Register sendPhoto tool
+	photoTool := mcp.NewTool("sendPhoto",
+		mcp.WithDescription("Sends a photo from the files directory or a URL to a configured chat"),
+		mcp.WithString("chat",
+			mcp.Description(chatDescription),
+			mcp.Required(),
+		),
+		mcp.WithString("photo",
+			mcp.Description("Path relative to the files directory, or an http(s) URL"),
+			mcp.Required(),
+		),
+		mcp.WithString("caption",
+			mcp.Description("Optional caption"),
+		),
+	)
+
+	// Register sendFile tool
+	fileTool := mcp.NewTool("sendFile",
+		mcp.WithDescription("Sends a file from the files directory to a configured chat"),
+		mcp.WithString("chat",
+			mcp.Description(chatDescription),
+			mcp.Required(),
+		),
+		mcp.WithString("path",
+			mcp.Description("Path relative to the files directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("caption",
+			mcp.Description("Optional caption"),
+		),
+	)
+
+	// Register getUpdates tool
+	updatesTool := mcp.NewTool("getUpdates",
+		mcp.WithDescription("Returns new incoming messages from configured chats. Returned messages are acknowledged and will not be returned again"),
+		mcp.WithNumber("wait",
+			mcp.Description("Seconds to wait for a message when none are pending (0-50, default: 0)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of updates (1-100, default: 20)"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, messageTool, s.handleSendMessage)
+	middleware.AddTool(mcpServer, photoTool, s.handleSendPhoto)
+	middleware.AddTool(mcpServer, fileTool, s.handleSendFile)
+	middleware.AddTool(mcpServer, updatesTool, s.handleGetUpdates)
+	health.Register(mcpServer, "telegram-api", s.checkAPI)
+
+	s.server = mcpServer
+	return s
+}
+
+// resolveChat maps an alias or numeric ID to an allowed chat ID.
+func (s *TelegramServer) resolveChat(chat string) (int64, error) {
+	if id, ok := s.chats[chat]; ok {
+		return id, nil
+	}
+	if id, err := strconv.ParseInt(chat, 10, 64); err == nil {
+		for _, allowed := range s.chats {
+			if allowed == id {
+				return id, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("chat %q is not configured", chat)
+}
+
+// chatAllowed reports whether messages from a chat may be returned.
+func (s *TelegramServer) chatAllowed(id int64) bool {
+	for _, allowed := range s.chats {
+		if allowed == id {
+			return true
+		}
+	}
+	return false
+}
+
+// localPath resolves a file inside the files directory, rejecting escapes, paths the
+// allow and deny rules exclude and files above the maximum size.
+func (s *TelegramServer) localPath(p string) (string, error) {
+	if s.filesDir == "" {
+		return "", fmt.Errorf("sending local files is disabled (no files directory configured)")
+	}
+	resolved, _, err := s.files.Stat(p)
+	return resolved, err
+}
+
+// call invokes a Bot API method and decodes its result into out.
+func (s *TelegramServer) call(ctx context.Context, method, contentType string, body io.Reader, out interface{}) error {
+	if s.token == "" {
+		return fmt.Errorf("bot token is not configured")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", s.apiURL, s.token, method), body)
+	if err != nil {
+		log.Printf("Error: Failed to create request: %v", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+
+	// The URL contains the bot token, so only the method is logged
+	log.Printf("Calling Bot API method %s", method)
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		log.Printf("Error: Request failed: %v", strings.ReplaceAll(err.Error(), s.token, "REDACTED"))
+		return fmt.Errorf("request to %s failed", method)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+	if err != nil {
+		log.Printf("Error: Failed to read response body: %v", err)
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		ErrorCode   int             `json:"error_code"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		log.Printf("Error: Failed to parse API response (status %d)", resp.StatusCode)
+		return fmt.Errorf("failed to parse API response (status code: %d)", resp.StatusCode)
+	}
+	if !apiResp.OK {
+		log.Printf("Error: %s failed: %d %s", method, apiResp.ErrorCode, apiResp.Description)
+		return fmt.Errorf("Telegram API error: %s (code: %d)", apiResp.Description, apiResp.ErrorCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(apiResp.Result, out); err != nil {
+			return fmt.Errorf("failed to parse API result: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkAPI is the readiness check of the server: the Bot API must accept the token.
+func (s *TelegramServer) checkAPI(ctx context.Context) error {
+	return s.callJSON(ctx, "getMe", map[string]interface{}{}, nil)
+}
+
+// callJSON invokes a Bot API method with a JSON payload.
+func (s *TelegramServer) callJSON(ctx context.Context, method string, payload map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return s.call(ctx, method, "application/json", bytes.NewReader(data), out)
+}
+
+// callUpload invokes a Bot API method with a multipart upload of a local file.
+func (s *TelegramServer) callUpload(ctx context.Context, method, field, path string, fields map[string]string, out interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	part, err := writer.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return s.call(ctx, method, writer.FormDataContentType(), &buf, out)
+}
+
+// sendResult builds the tool result for a sent message.
+func sendResult(kind, chat string, sent Message) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("%s sent to %s (message_id=%d)", kind, chat, sent.MessageID),
+			},
+		},
+	}
+}
+
+// handleSendMessage handles the text message request.
+func (s *TelegramServer) handleSendMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendMessage request processing")
+
+	var args struct {
+		Chat      string `json:"chat"`
+		Text      string `json:"text"`
+		ParseMode string `json:"parseMode,omitempty"`
+		Silent    bool   `json:"silent,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	chatID, err := s.resolveChat(args.Chat)
+	if err != nil {
+		return nil, err
+	}
+	if args.Text == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "text is required")
+	}
+	if len([]rune(args.Text)) > 4096 {
+		return nil, fmt.Errorf("text exceeds the Telegram limit of 4096 characters")
+	}
+
+	payload := map[string]interface{}{
+		"chat_id":              chatID,
+		"text":                 args.Text,
+		"disable_notification": args.Silent,
+	}
+	if args.ParseMode != "" {
+		payload["parse_mode"] = args.ParseMode
+	}
+
+	var sent Message
+	if err := s.callJSON(ctx, "sendMessage", payload, &sent); err != nil {
+		return nil, err
+	}
+
+	log.Println("sendMessage request completed")
+	return sendResult("Message", args.Chat, sent), nil
+}
+
+// handleSendPhoto handles the photo request.
+func (s *TelegramServer) handleSendPhoto(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendPhoto request processing")
+
+	var args struct {
+		Chat    string `json:"chat"`
+		Photo   string `json:"photo"`
+		Caption string `json:"caption,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	chatID, err := s.resolveChat(args.Chat)
+	if err != nil {
+		return nil, err
+	}
+	if args.Photo == "" {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "photo is required")
+	}
+
+	var sent Message
+	if u, err := url.Parse(args.Photo); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		// Telegram downloads URL photos itself
+		err = s.callJSON(ctx, "sendPhoto", map[string]interface{}{
+			"chat_id": chatID,
+			"photo":   args.Photo,
+			"caption": args.Caption,
+		}, &sent)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		path, err := s.localPath(args.Photo)
+		if err != nil {
+			return nil, err
+		}
+		err = s.callUpload(ctx, "sendPhoto", "photo", path, map[string]string{
+			"chat_id": strconv.FormatInt(chatID, 10),
+			"caption": args.Caption,
+		}, &sent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Println("sendPhoto request completed")
+	return sendResult("Photo", args.Chat, sent), nil
+}
+
+// handleSendFile handles the document request.
+func (s *TelegramServer) handleSendFile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendFile request processing")
+
+	var args struct {
+		Chat    string `json:"chat"`
+		Path    string `json:"path"`
+		Caption string `json:"caption,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	chatID, err := s.resolveChat(args.Chat)
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.localPath(args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent Message
+	err = s.callUpload(ctx, "sendDocument", "document", path, map[string]string{
+		"chat_id": strconv.FormatInt(chatID, 10),
+		"caption": args.Caption,
+	}, &sent)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("sendFile request completed")
+	return sendResult("File", args.Chat, sent), nil
+}
+
+// chatName returns the alias of a chat ID, falling back to the ID.
+func (s *TelegramServer) chatName(id int64) string {
+	for name, chatID := range s.chats {
+		if chatID == id && name != strconv.FormatInt(id, 10) {
+			return name
+		}
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// handleGetUpdates handles the incoming message polling request.
+func (s *TelegramServer) handleGetUpdates(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting getUpdates request processing")
+
+	var args struct {
+		Wait  int `json:"wait,omitempty"`
+		Limit int `json:"limit,omitempty"`
+	}
+
+	err := params.Decode(req, &args)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	args.Wait = max(0, min(50, args.Wait))
+	if args.Limit <= 0 {
+		args.Limit = 20
+	} else if args.Limit > 100 {
+		args.Limit = 100
+	}
+
+	// Serialize polling so concurrent calls do not acknowledge each other's updates
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updates []Update
+	err = s.callJSON(ctx, "getUpdates", map[string]interface{}{
+		"offset":          s.offset,
+		"limit":           args.Limit,
+		"timeout":         args.Wait,
+		"allowed_updates": []string{"message", "channel_post", "edited_message"},
+	}, &updates)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultContent strings.Builder
+	count, ignored := 0, 0
+	for _, update := range updates {
+		s.offset = max(s.offset, update.UpdateID+1)
+
+		msg, label := update.Message, ""
+		if msg == nil {
+			msg = update.ChannelPost
+		}
+		if msg == nil && update.EditedMessage != nil {
+			msg, label = update.EditedMessage, " (edited)"
+		}
+		if msg == nil {
+			continue
+		}
+		if !s.chatAllowed(msg.Chat.ID) {
+			ignored++
+			continue
+		}
+
+		sender := "channel"
+		if msg.From != nil {
+			sender = msg.From.FirstName
+			if msg.From.Username != "" {
+				sender = "@" + msg.From.Username
+			}
+		}
+		text := msg.Text
+		if text == "" {
+			text = msg.Caption
+		}
+		switch {
+		case msg.Document != nil:
+			text = strings.TrimSpace(fmt.Sprintf("[file: %s] %s", msg.Document.FileName, text))
+		case len(msg.Photo) > 0:
+			text = strings.TrimSpace("[photo] " + text)
+		}
+
+		count++
+		resultContent.WriteString(fmt.Sprintf("[%s] %s in %s%s (message_id=%d):\n%s\n\n",
+			time.Unix(msg.Date, 0).UTC().Format(time.RFC3339), sender, s.chatName(msg.Chat.ID), label, msg.MessageID, text))
+	}
+	if ignored > 0 {
+		log.Printf("Ignored %d updates from unconfigured chats", ignored)
+	}
+
+	header := fmt.Sprintf("%d new messages\n\n", count)
+	if count == 0 {
+		header = "No new messages.\n"
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: header + resultContent.String(),
+			},
+		},
+	}
+
+	log.Println("getUpdates request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *TelegramServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// parseChats parses "alias=id,alias=id" or plain IDs into an alias map.
+func parseChats(value string) (map[string]int64, error) {
+	result := map[string]int64{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, idStr, found := strings.Cut(item, "=")
+		if !found {
+			idStr = name
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "invalid chat ID in %q", item)
+		}
+		result[strings.TrimSpace(name)] = id
+	}
+	return result, nil
+}
+
+// New creates the telegram server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("telegram", flag.ContinueOnError)
+	var (
+		botToken    string
+		apiURL      string
+		chats       string
+		filesDir    string
+		timeout     int
+		maxFileSize int64
+	)
+	fs.StringVar(&botToken, "token", "", "Telegram bot token")
+	fs.StringVar(&apiURL, "api-url", defaultAPIURL, "Telegram Bot API base URL")
+	fs.StringVar(&chats, "chats", "", "Comma separated chats the bot may use, as alias=chatID or chatID (e.g. team=-1001234567890,me=12345678)")
+	fs.StringVar(&filesDir, "files-dir", "", "Directory from which local photos and files may be sent (disabled when empty)")
+	fs.IntVar(&timeout, "timeout", 30, "HTTP request timeout in seconds")
+	fs.Int64Var(&maxFileSize, "max-file-size", 50*1024*1024, "Maximum upload size in bytes (default 50MB, the Bot API limit)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var sandboxFlags sandbox.Flags
+	sandboxFlags.Register(fs, "files directory")
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags, also reading the environment variables the server always read
+	if err := config.Parse(fs, args,
+		config.WithEnv("token", "TELEGRAM_BOT_TOKEN"),
+		config.WithEnv("chats", "TELEGRAM_CHATS"),
+	); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	chatMap, err := parseChats(chats)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting Telegram server: chats=%d, timeout=%ds", len(chatMap), timeout)
+	if botToken == "" {
+		log.Printf("Warning: Bot token not configured. The server will start but requests will fail.")
+	}
+	if len(chatMap) == 0 {
+		log.Printf("Warning: No chats configured. Messages can neither be sent nor received.")
+	}
+
+	// Create TelegramServer instance
+	telegramServer := NewTelegramServer(apiURL, botToken, chatMap, filesDir, timeout, maxFileSize)
+	sandboxFlags.Apply(telegramServer.files)
+	log.Println("TelegramServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), telegramServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return telegramServer.Server(), transportFlags, nil
+}
+
+// Run starts the telegram server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[TelegramServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create telegram server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("TelegramServer shutdown")
+	return nil
+}
@@ -0,0 +1,227 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TelegramServer creation test
+func TestNewTelegramServer(t *testing.T) {
+	chats := map[string]int64{"team": -100123}
+	ts := NewTelegramServer(defaultAPIURL+"/", "token", chats, "", 30, 1024)
+
+	assert.NotNil(t, ts, "TelegramServer instance should be created")
+	assert.Equal(t, defaultAPIURL, ts.apiURL, "API URL should have the trailing slash trimmed")
+	assert.Equal(t, chats, ts.chats, "Chats should match")
+	assert.NotNil(t, ts.server, "Internal MCPServer should be initialized")
+}
+
+// Server method test
+func TestServer(t *testing.T) {
+	ts := NewTelegramServer(defaultAPIURL, "token", nil, "", 30, 1024)
+	assert.NotNil(t, ts.Server(), "Server method should return a valid MCPServer instance")
+}
+
+// Test chat configuration parsing and resolution
+func TestChats(t *testing.T) {
+	chats, err := parseChats("team=-100123, me=42,77")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"team": -100123, "me": 42, "77": 77}, chats)
+
+	_, err = parseChats("team=abc")
+	assert.Error(t, err)
+
+	ts := NewTelegramServer(defaultAPIURL, "token", chats, "", 30, 1024)
+	id, err := ts.resolveChat("team")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-100123), id)
+
+	id, err = ts.resolveChat("42")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	_, err = ts.resolveChat("999")
+	assert.Error(t, err, "Unconfigured chat IDs should be rejected")
+}
+
+// Test the files directory jail
+func TestLocalPath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "report.txt"), []byte("report"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")))
+
+	ts := NewTelegramServer(defaultAPIURL, "token", nil, root, 30, 4)
+
+	_, err := ts.localPath("report.txt")
+	assert.Error(t, err, "Files above the size limit should be rejected")
+
+	ts = NewTelegramServer(defaultAPIURL, "token", nil, root, 30, 1024)
+	path, err := ts.localPath("report.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "report.txt", filepath.Base(path))
+
+	_, err = ts.localPath("../" + filepath.Base(outside) + "/secret.txt")
+	assert.Error(t, err, "Traversal should not escape the files directory")
+
+	_, err = ts.localPath("link.txt")
+	assert.Error(t, err, "Symlinks pointing outside should be rejected")
+
+	disabled := NewTelegramServer(defaultAPIURL, "token", nil, "", 30, 1024)
+	_, err = disabled.localPath("report.txt")
+	assert.Error(t, err)
+}
+
+// Test tool handlers against a mock Bot API
+func TestHandlers(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "chart.png"), []byte("png"), 0644))
+
+	var lastPayload map[string]interface{}
+	var lastUpload string
+	var offsets []float64
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bottest-token/sendMessage", "/bottest-token/sendPhoto", "/bottest-token/sendDocument":
+			if r.Header.Get("Content-Type") == "application/json" {
+				lastPayload = nil
+				json.NewDecoder(r.Body).Decode(&lastPayload)
+				if lastPayload["text"] == "bad *markdown" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: can't parse entities"}`))
+					return
+				}
+			} else {
+				file, header, err := r.FormFile(map[string]string{"/bottest-token/sendPhoto": "photo", "/bottest-token/sendDocument": "document"}[r.URL.Path])
+				if assert.NoError(t, err) {
+					data, _ := io.ReadAll(file)
+					lastUpload = header.Filename + ":" + string(data) + ":" + r.FormValue("chat_id")
+				}
+			}
+			w.Write([]byte(`{"ok": true, "result": {"message_id": 7, "date": 1700000000, "chat": {"id": -100123}}}`))
+		case "/bottest-token/getUpdates":
+			var payload map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&payload)
+			offsets = append(offsets, payload["offset"].(float64))
+			w.Write([]byte(`{"ok": true, "result": [
+				{"update_id": 100, "message": {"message_id": 1, "date": 1700000000, "chat": {"id": -100123}, "from": {"id": 1, "username": "alice"}, "text": "/deploy staging"}},
+				{"update_id": 101, "message": {"message_id": 2, "date": 1700000000, "chat": {"id": 555}, "from": {"id": 2, "first_name": "Mallory"}, "text": "hello bot"}},
+				{"update_id": 102, "edited_message": {"message_id": 1, "date": 1700000000, "chat": {"id": -100123}, "from": {"id": 1, "username": "alice"}, "text": "/deploy production"}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ok": false, "error_code": 404, "description": "Not Found"}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	ts := NewTelegramServer(mockServer.URL, "test-token", map[string]int64{"team": -100123}, root, 5, 1024)
+	ctx := context.Background()
+
+	t.Run("Send message", func(t *testing.T) {
+		result, err := ts.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"chat":      "team",
+			"text":      "Build *passed*",
+			"parseMode": "MarkdownV2",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Message sent to team (message_id=7)")
+		assert.Equal(t, float64(-100123), lastPayload["chat_id"])
+		assert.Equal(t, "MarkdownV2", lastPayload["parse_mode"])
+	})
+
+	t.Run("Send message API error", func(t *testing.T) {
+		_, err := ts.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"chat": "team",
+			"text": "bad *markdown",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "can't parse entities (code: 400)")
+	})
+
+	t.Run("Send message to unconfigured chat", func(t *testing.T) {
+		_, err := ts.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"chat": "555",
+			"text": "hi",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not configured")
+	})
+
+	t.Run("Send local photo", func(t *testing.T) {
+		_, err := ts.handleSendPhoto(ctx, mcptest.NewCallToolRequest("sendPhoto", map[string]interface{}{
+			"chat":  "team",
+			"photo": "chart.png",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "chart.png:png:-100123", lastUpload)
+	})
+
+	t.Run("Send photo URL", func(t *testing.T) {
+		_, err := ts.handleSendPhoto(ctx, mcptest.NewCallToolRequest("sendPhoto", map[string]interface{}{
+			"chat":  "team",
+			"photo": "https://example.com/cat.jpg",
+		}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/cat.jpg", lastPayload["photo"])
+	})
+
+	t.Run("Send file", func(t *testing.T) {
+		result, err := ts.handleSendFile(ctx, mcptest.NewCallToolRequest("sendFile", map[string]interface{}{
+			"chat": "team",
+			"path": "chart.png",
+		}))
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "File sent to team")
+	})
+
+	t.Run("Get updates acknowledges and filters chats", func(t *testing.T) {
+		result, err := ts.handleGetUpdates(ctx, mcptest.NewCallToolRequest("getUpdates", nil))
+
+		assert.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "2 new messages")
+		assert.Contains(t, text, "@alice in team (message_id=1):\n/deploy staging")
+		assert.Contains(t, text, "@alice in team (edited) (message_id=1):\n/deploy production")
+		assert.NotContains(t, text, "hello bot", "Messages from unconfigured chats should be ignored")
+
+		_, err = ts.handleGetUpdates(ctx, mcptest.NewCallToolRequest("getUpdates", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{0, 103}, offsets, "The next poll should acknowledge previous updates")
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		noToken := NewTelegramServer(mockServer.URL, "", map[string]int64{"team": -100123}, "", 5, 1024)
+		_, err := noToken.handleSendMessage(ctx, mcptest.NewCallToolRequest("sendMessage", map[string]interface{}{
+			"chat": "team",
+			"text": "hi",
+		}))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "bot token is not configured")
+	})
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewTelegramServer(defaultAPIURL, "token", nil, "", 5, 1024).Server())
+}
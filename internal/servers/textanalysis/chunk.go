@@ -0,0 +1,114 @@
+package textanalysis
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is a piece of a text of at most a number of tokens.
+type Chunk struct {
+	Index int `json:"index"` // from 1
+	// Start and End are the offsets of the chunk in the text, in characters.
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Tokens int    `json:"tokens"`
+	Text   string `json:"text"`
+}
+
+// Strengths of the boundaries chunks are cut at, the strongest preferred.
+const (
+	boundaryNone = iota
+	boundaryWord
+	boundarySentence
+	boundaryLine
+	boundaryParagraph
+)
+
+// boundary returns the strength of the boundary before pieces[i] of text.
+func boundary(text string, pieces []piece, i int) int {
+	prev := pieces[i-1]
+	s := text[prev.start:prev.end]
+	switch {
+	case prev.kind == pieceSpace && strings.Count(s, "\n") >= 2:
+		return boundaryParagraph
+	case prev.kind == pieceSpace && strings.Contains(s, "\n"):
+		return boundaryLine
+	case prev.kind == pieceSpace && i >= 2 && pieces[i-2].kind == pieceSymbol:
+		r, _ := utf8.DecodeLastRuneInString(text[:pieces[i-2].end])
+		if sentenceEnd(r) || strings.ContainsRune(`"')]»”’`, r) {
+			return boundarySentence
+		}
+		return boundaryWord
+	case prev.kind == pieceSymbol:
+		// Sentences of CJK text end without a space
+		r, _ := utf8.DecodeRuneInString(s)
+		if r == '。' || r == '！' || r == '？' {
+			return boundarySentence
+		}
+	case prev.kind == pieceSpace:
+		return boundaryWord
+	}
+	return boundaryNone
+}
+
+// chunkText splits text into chunks of at most maxTokens estimated tokens, the next
+// starting with the last overlap tokens of the previous one. Chunks end at the end of
+// a paragraph, a line, a sentence or a word, the strongest boundary found in their
+// second half, so that they keep their context; a word longer than maxTokens is a
+// chunk of its own.
+func chunkText(text string, maxTokens, overlap int) []Chunk {
+	pieces := splitPieces(text)
+	// Character offsets of the pieces
+	offsets := make([]int, len(pieces)+1)
+	for i, p := range pieces {
+		offsets[i+1] = offsets[i] + utf8.RuneCountInString(text[p.start:p.end])
+	}
+
+	var chunks []Chunk
+	for start := 0; start < len(pieces); {
+		for start < len(pieces) && pieces[start].kind == pieceSpace {
+			start++
+		}
+		if start == len(pieces) {
+			break
+		}
+		end, tokens := start, 0
+		for end < len(pieces) && tokens+pieces[end].tokens <= maxTokens {
+			tokens += pieces[end].tokens
+			end++
+		}
+		cut := end
+		switch {
+		case end == start:
+			cut = start + 1
+		case end < len(pieces):
+			best := boundary(text, pieces, end)
+			for i := end - 1; i > start+(end-start)/2 && best < boundaryParagraph; i-- {
+				if b := boundary(text, pieces, i); b > best {
+					best, cut = b, i
+				}
+			}
+		}
+
+		last := cut
+		for last > start && pieces[last-1].kind == pieceSpace {
+			last--
+		}
+		chunk := Chunk{Index: len(chunks) + 1, Start: offsets[start], End: offsets[last], Text: text[pieces[start].start:pieces[last-1].end]}
+		for _, p := range pieces[start:last] {
+			chunk.Tokens += p.tokens
+		}
+		chunks = append(chunks, chunk)
+		if cut == len(pieces) {
+			break
+		}
+
+		next, kept := cut, 0
+		for next-1 > start && kept+pieces[next-1].tokens <= overlap {
+			next--
+			kept += pieces[next].tokens
+		}
+		start = next
+	}
+	return chunks
+}
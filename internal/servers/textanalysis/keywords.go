@@ -0,0 +1,149 @@
+package textanalysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPhraseWords is the length of the longest keyword phrases.
+const maxPhraseWords = 3
+
+// Keyword is a keyword phrase of a text.
+type Keyword struct {
+	Phrase string  `json:"phrase"`
+	Score  float64 `json:"score"`
+	Count  int     `json:"count"`
+}
+
+// contentWord reports whether w, lowercased, carries meaning in a text with stopwords:
+// it is not a stopword, a number or a single letter.
+func contentWord(w string, stop map[string]bool) bool {
+	if stop[w] || utf8.RuneCountInString(w) < 2 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(w)
+	return wordRune(r)
+}
+
+// phrases returns the candidate keyword phrases of text, the runs of content words
+// between stopwords and punctuation, with the case of their words as written.
+func phrases(text string, stop map[string]bool) [][]string {
+	var phrases [][]string
+	var current []string
+	flush := func() {
+		if len(current) > 0 && len(current) <= maxPhraseWords {
+			phrases = append(phrases, current)
+		}
+		current = nil
+	}
+	for _, p := range splitPieces(text) {
+		s := text[p.start:p.end]
+		switch {
+		case p.kind == pieceWord && contentWord(strings.ToLower(s), stop):
+			current = append(current, s)
+		case p.kind == pieceSpace && !strings.Contains(s, "\n"):
+		default:
+			flush()
+		}
+	}
+	flush()
+	return phrases
+}
+
+// extractKeywords returns the limit best keyword phrases of text with the Rapid
+// Automatic Keyword Extraction (RAKE) algorithm: words score their degree, the number
+// of words of the phrases they appear in, over their frequency, and phrases the sum of
+// the scores of their words.
+func extractKeywords(text string, stop map[string]bool, limit int) []Keyword {
+	candidates := phrases(text, stop)
+	frequency := map[string]int{}
+	degree := map[string]int{}
+	for _, phrase := range candidates {
+		for _, w := range phrase {
+			w = strings.ToLower(w)
+			frequency[w]++
+			degree[w] += len(phrase)
+		}
+	}
+
+	byPhrase := map[string]*Keyword{}
+	var keywords []*Keyword
+	for _, phrase := range candidates {
+		key := strings.ToLower(strings.Join(phrase, " "))
+		if k, ok := byPhrase[key]; ok {
+			k.Count++
+			continue
+		}
+		score := 0.0
+		for _, w := range phrase {
+			w = strings.ToLower(w)
+			score += float64(degree[w]) / float64(frequency[w])
+		}
+		k := &Keyword{Phrase: strings.Join(phrase, " "), Score: math.Round(score*100) / 100, Count: 1}
+		byPhrase[key] = k
+		keywords = append(keywords, k)
+	}
+	sort.SliceStable(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Count > keywords[j].Count
+	})
+
+	result := make([]Keyword, 0, min(limit, len(keywords)))
+	for _, k := range keywords {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, *k)
+	}
+	return result
+}
+
+// summarize returns the n sentences of text that best summarize it, in their order:
+// those whose content words are the most frequent in the text. Sentences score the sum
+// of the frequencies of their content words, relative to the most frequent one, over
+// the square root of their number of words, favoring neither long nor short ones.
+func summarize(text string, stop map[string]bool, n int) []string {
+	all := sentences(text)
+	if len(all) <= n {
+		return all
+	}
+	frequency := map[string]int{}
+	most := 1
+	for _, w := range words(text) {
+		if w = strings.ToLower(w); contentWord(w, stop) {
+			frequency[w]++
+			most = max(most, frequency[w])
+		}
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, len(all))
+	for i, s := range all {
+		sum, count := 0.0, 0
+		for _, w := range words(s) {
+			count++
+			if w = strings.ToLower(w); contentWord(w, stop) {
+				sum += float64(frequency[w]) / float64(most)
+			}
+		}
+		scores[i] = scored{index: i}
+		if count > 0 {
+			scores[i].score = sum / math.Sqrt(float64(count))
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+	chosen := scores[:n]
+	sort.Slice(chosen, func(i, j int) bool { return chosen[i].index < chosen[j].index })
+	summary := make([]string, n)
+	for i, c := range chosen {
+		summary[i] = all[c.index]
+	}
+	return summary
+}
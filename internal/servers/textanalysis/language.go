@@ -0,0 +1,164 @@
+package textanalysis
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// languageNames are the names of the languages detected, by ISO 639-1 code.
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fr": "French", "he": "Hebrew", "hi": "Hindi", "it": "Italian", "ja": "Japanese",
+	"ko": "Korean", "nl": "Dutch", "pt": "Portuguese", "ru": "Russian", "th": "Thai",
+	"uk": "Ukrainian", "zh": "Chinese",
+}
+
+// stopwords are the most common words of the languages written in the Latin script,
+// which tell them apart and carry no meaning of their own for keywords.
+var stopwords = map[string]map[string]bool{
+	"en": wordSet("a about above after again against all also am an and any are as at be because been before being below between both but by can could did do does doing don down during each even few for from further get had has have having he her here him his how i if in into is it its just like many may me might more most must my no nor not now of off on once one only or other our out over own same say she should so some such than that the their them then there these they this those through to too under until up us very was way we well were what when where which while who why will with would you your"),
+	"de": wordSet("aber als am an auch auf aus bei bin bis bist da damit dann das dass dem den denn der des die dies diese dieser doch du durch ein eine einem einen einer es für hat haben hatte ich ihr im in ist ja kann kein man mehr mit nach nicht noch nur oder schon sehr sein sich sie sind so über um und uns von vor war waren was weil wenn wer werden wie wir wird wurde zu zum zur"),
+	"es": wordSet("a al algo como con contra cuando de del desde donde durante el ella ellos en entre era es esa ese eso esta este estos fue ha hay hasta la las le lo los más me mi muy ni no nos o para pero por porque que quien se ser si sin sobre son su sus también te tiene todo un una uno y ya"),
+	"fr": wordSet("a au aussi aux avec avoir ce ces cette comme dans de des du elle en est et été être il ils je la le les leur lui mais me même ne nous on ont ou où par pas plus pour qu que qui sa se ses son sont sur tout très un une vous y"),
+	"it": wordSet("a ad al alla anche che chi ci come con da dal dalla dei del della di e è essere gli ha hanno i il in io la le lei lo loro lui ma mi ne nel nella noi non o per più quando questa questo se si sono su tra un una uno voi"),
+	"nl": wordSet("aan al als bij dan dat de deze die dit door een en er geen had heb heeft hem het hij hoe ik in is je kan maar me met mijn na naar niet nog nu of om ook op over te tot uit van veel voor was wat we wel werd wij worden wordt zal ze zich zij zijn zo"),
+	"pt": wordSet("a ao aos as até com como da das de do dos e é ela ele eles em entre era essa esse esta este foi há isso já mais mas muito na nas não no nos o os ou para pela pelo por qual quando que se sem ser seu sua são também tem um uma"),
+}
+
+// wordSet returns the set of the words of s.
+func wordSet(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+// Candidate is a language text may be in, with the confidence of the detection from
+// 0 to 1: the share of the letters in the script of the language, or for languages
+// written in the Latin script, the share of its stopwords among the stopwords found.
+type Candidate struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// Detection is the language detected in a text.
+type Detection struct {
+	Candidate
+	Script string `json:"script"`
+	// Candidates are the languages the text may be in, the likeliest first.
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// scripts are the scripts told apart, and the language of those used by one.
+var scripts = []struct {
+	name     string
+	table    *unicode.RangeTable
+	language string
+}{
+	{"Hangul", unicode.Hangul, "ko"},
+	{"Hiragana", unicode.Hiragana, "ja"},
+	{"Katakana", unicode.Katakana, "ja"},
+	{"Han", unicode.Han, "zh"},
+	{"Cyrillic", unicode.Cyrillic, "ru"},
+	{"Greek", unicode.Greek, "el"},
+	{"Arabic", unicode.Arabic, "ar"},
+	{"Hebrew", unicode.Hebrew, "he"},
+	{"Devanagari", unicode.Devanagari, "hi"},
+	{"Thai", unicode.Thai, "th"},
+	{"Latin", unicode.Latin, ""},
+}
+
+// candidate returns the candidate of language code with score.
+func candidate(code string, score float64) Candidate {
+	return Candidate{Code: code, Name: languageNames[code], Score: score}
+}
+
+// detectLanguage detects the language of text from its script, and for the Latin
+// script from the stopwords of each language among its words. The code is "und" when
+// the language cannot be told.
+func detectLanguage(text string) Detection {
+	counts := make([]int, len(scripts))
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return Detection{Candidate: Candidate{Code: "und", Name: "Undetermined"}}
+	}
+
+	best := 0
+	for i := range scripts {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+	if counts[best] == 0 {
+		return Detection{Candidate: Candidate{Code: "und", Name: "Undetermined"}}
+	}
+	script := scripts[best].name
+	share := float64(counts[best]) / float64(letters)
+	switch script {
+	case "Hangul":
+		return Detection{Candidate: candidate("ko", share), Script: script}
+	case "Hiragana", "Katakana", "Han":
+		// Japanese mixes kana with Han characters
+		kana := counts[1] + counts[2]
+		cjk := float64(kana+counts[3]) / float64(letters)
+		if kana > 0 && float64(kana) >= 0.05*float64(kana+counts[3]) {
+			return Detection{Candidate: candidate("ja", cjk), Script: "Han, Hiragana and Katakana"}
+		}
+		return Detection{Candidate: candidate("zh", cjk), Script: "Han"}
+	case "Cyrillic":
+		if strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+			return Detection{Candidate: candidate("uk", share), Script: script}
+		}
+		return Detection{Candidate: candidate("ru", share), Script: script}
+	case "Latin":
+	default:
+		return Detection{Candidate: candidate(scripts[best].language, share), Script: script}
+	}
+
+	total, sum, most := 0, 0, 0
+	hits := map[string]int{}
+	for _, w := range words(text) {
+		w = strings.ToLower(w)
+		total++
+		for code, set := range stopwords {
+			if set[w] {
+				hits[code]++
+				sum++
+				most = max(most, hits[code])
+			}
+		}
+	}
+	// Too few stopwords, e.g. in lists of names, tell no language
+	if most == 0 || float64(most) < 0.05*float64(total) {
+		return Detection{Candidate: Candidate{Code: "und", Name: "Undetermined"}, Script: script}
+	}
+	var candidates []Candidate
+	for code, n := range hits {
+		candidates = append(candidates, candidate(code, float64(n)/float64(sum)))
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Code < candidates[j].Code
+	})
+	if len(candidates) > 3 {
+		candidates = candidates[:3]
+	}
+	return Detection{Candidate: candidates[0], Script: script, Candidates: candidates}
+}
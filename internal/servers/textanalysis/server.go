@@ -0,0 +1,575 @@
+package textanalysis
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// Reading and speaking speeds, in words per minute.
+const (
+	readingSpeed  = 238
+	speakingSpeed = 150
+)
+
+// TextAnalysisServer is an MCP server counting, analyzing, summarizing and chunking
+// text locally, so that agents can measure text against their context budget without
+// calling a model.
+type TextAnalysisServer struct {
+	server      *server.MCPServer
+	maxTextSize int
+}
+
+// NewTextAnalysisServer creates a new TextAnalysisServer instance
+func NewTextAnalysisServer(maxTextSize int) *TextAnalysisServer {
+	log.Printf("TextAnalysisServer created: maxTextSize=%d", maxTextSize)
+
+	s := &TextAnalysisServer{
+		maxTextSize: maxTextSize,
+	}
+
+	mcpServer := server.NewMCPServer(
+		"textanalysis-server", // server name
+		"1.0.0",               // version
+	)
+
+	// Register textStatistics tool
+	statisticsTool := params.Tool[statisticsArgs]("textStatistics",
+		mcp.WithDescription("Counts the characters, words, sentences, paragraphs, lines and estimated language model tokens of a text, with its reading and speaking times"),
+	)
+
+	// Register extractKeywords tool
+	keywordsTool := params.Tool[keywordsArgs]("extractKeywords",
+		mcp.WithDescription("Extracts the keyword phrases of a text with the RAKE algorithm, ranked by score. Works for languages written with spaces between words"),
+	)
+
+	// Register detectLanguage tool
+	languageTool := params.Tool[textArgs]("detectLanguage",
+		mcp.WithDescription("Detects the language of a text from its script and common words, with a confidence score and the likeliest alternatives"),
+	)
+
+	// Register readabilityScores tool
+	readabilityTool := params.Tool[textArgs]("readabilityScores",
+		mcp.WithDescription("Computes the Flesch reading ease, Flesch-Kincaid grade, Gunning fog, SMOG, Coleman-Liau and automated readability indexes of an English text"),
+	)
+
+	// Register chunkText tool
+	chunkTool := params.Tool[chunkArgs]("chunkText",
+		mcp.WithDescription("Splits a long text into chunks of at most maxTokens estimated tokens, overlapping by overlap tokens, ending at paragraph, sentence or word boundaries. Use it to process text larger than the context budget piece by piece"),
+	)
+
+	// Register summarizeText tool
+	summarizeTool := params.Tool[summarizeArgs]("summarizeText",
+		mcp.WithDescription("Summarizes a text with its most representative sentences, those made of its most frequent words, in their order. The summary is extractive: sentences are quoted, not rewritten"),
+	)
+
+	middleware.AddTool(mcpServer, statisticsTool, s.handleTextStatistics)
+	middleware.AddTool(mcpServer, keywordsTool, s.handleExtractKeywords)
+	middleware.AddTool(mcpServer, languageTool, s.handleDetectLanguage)
+	middleware.AddTool(mcpServer, readabilityTool, s.handleReadabilityScores)
+	middleware.AddTool(mcpServer, chunkTool, s.handleChunkText)
+	middleware.AddTool(mcpServer, summarizeTool, s.handleSummarizeText)
+
+	s.server = mcpServer
+	return s
+}
+
+// textArgs holds the parameters shared by all tools.
+type textArgs struct {
+	Text   string `json:"text" param:"required" description:"Text to analyze"`
+	Format string `json:"format,omitempty" param:"enum=text|json" description:"Output format (default: text)"`
+}
+
+// statisticsArgs are the parameters of textStatistics.
+type statisticsArgs struct {
+	textArgs
+}
+
+// keywordsArgs are the parameters of extractKeywords.
+type keywordsArgs struct {
+	textArgs
+	Limit    int    `json:"limit,omitempty" param:"default=10,min=1,max=100" description:"Maximum number of keywords (default: 10)"`
+	Language string `json:"language,omitempty" description:"ISO 639-1 code of the language of the text, whose common words are left out: en, de, es, fr, it, nl or pt (default: detected)"`
+}
+
+// chunkArgs are the parameters of chunkText.
+type chunkArgs struct {
+	textArgs
+	MaxTokens int `json:"maxTokens,omitempty" param:"default=500,min=10" description:"Maximum estimated tokens of a chunk (default: 500)"`
+	Overlap   int `json:"overlap,omitempty" param:"min=0" description:"Estimated tokens a chunk repeats from the end of the previous one, less than half of maxTokens (default: 0)"`
+	Chunk     int `json:"chunk,omitempty" param:"min=0" description:"Return only the chunk with this number, from 1, with the number of chunks (default: all)"`
+}
+
+// summarizeArgs are the parameters of summarizeText.
+type summarizeArgs struct {
+	textArgs
+	Sentences int `json:"sentences,omitempty" param:"default=3,min=1,max=20" description:"Number of sentences of the summary (default: 3)"`
+}
+
+// check checks the size of text.
+func (s *TextAnalysisServer) check(text string) error {
+	if len(text) > s.maxTextSize {
+		return toolerr.Errorf(toolerr.InvalidParams, "text exceeds the maximum size of %d bytes", s.maxTextSize)
+	}
+	return nil
+}
+
+// Statistics are the counts of a text.
+type Statistics struct {
+	Characters          int     `json:"characters"`
+	CharactersNoSpaces  int     `json:"charactersNoSpaces"`
+	Words               int     `json:"words"`
+	UniqueWords         int     `json:"uniqueWords"`
+	Sentences           int     `json:"sentences"`
+	Paragraphs          int     `json:"paragraphs"`
+	Lines               int     `json:"lines"`
+	Tokens              int     `json:"estimatedTokens"`
+	AverageWordLength   float64 `json:"averageWordLength"`
+	AverageSentence     float64 `json:"averageSentenceWords"`
+	ReadingTimeSeconds  int     `json:"readingTimeSeconds"`
+	SpeakingTimeSeconds int     `json:"speakingTimeSeconds"`
+}
+
+// round rounds v to 2 decimals.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// statistics counts text.
+func statistics(text string) Statistics {
+	st := Statistics{
+		Characters: utf8.RuneCountInString(text),
+		Sentences:  len(sentences(text)),
+		Paragraphs: paragraphs(text),
+		Lines:      strings.Count(strings.TrimRight(text, "\n"), "\n") + 1,
+		Tokens:     estimateTokens(text),
+	}
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			st.CharactersNoSpaces++
+		}
+	}
+	unique := map[string]bool{}
+	letters := 0
+	for _, w := range words(text) {
+		st.Words++
+		letters += utf8.RuneCountInString(w)
+		unique[strings.ToLower(w)] = true
+	}
+	st.UniqueWords = len(unique)
+	if st.Words > 0 {
+		st.AverageWordLength = round(float64(letters) / float64(st.Words))
+	}
+	if st.Sentences > 0 {
+		st.AverageSentence = round(float64(st.Words) / float64(st.Sentences))
+	}
+	st.ReadingTimeSeconds = int(math.Ceil(float64(st.Words) * 60 / readingSpeed))
+	st.SpeakingTimeSeconds = int(math.Ceil(float64(st.Words) * 60 / speakingSpeed))
+	return st
+}
+
+// duration formats seconds for people.
+func duration(seconds int) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	return fmt.Sprintf("%dm %02ds", seconds/60, seconds%60)
+}
+
+// handleTextStatistics handles the text statistics request.
+func (s *TextAnalysisServer) handleTextStatistics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting textStatistics request processing")
+
+	var args statisticsArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+
+	st := statistics(args.Text)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Characters: %d (%d without spaces)\n", st.Characters, st.CharactersNoSpaces)
+	fmt.Fprintf(&b, "Words: %d (%d unique)\n", st.Words, st.UniqueWords)
+	fmt.Fprintf(&b, "Sentences: %d\n", st.Sentences)
+	fmt.Fprintf(&b, "Paragraphs: %d\n", st.Paragraphs)
+	fmt.Fprintf(&b, "Lines: %d\n", st.Lines)
+	fmt.Fprintf(&b, "Estimated tokens: %d\n", st.Tokens)
+	fmt.Fprintf(&b, "Average word length: %.2f characters\n", st.AverageWordLength)
+	fmt.Fprintf(&b, "Average sentence length: %.2f words\n", st.AverageSentence)
+	fmt.Fprintf(&b, "Reading time: %s\n", duration(st.ReadingTimeSeconds))
+	fmt.Fprintf(&b, "Speaking time: %s", duration(st.SpeakingTimeSeconds))
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: st, Text: b.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("textStatistics request completed")
+	return result, nil
+}
+
+// stopwordsFor returns the stopwords of language, detected in text if empty. Texts in
+// other languages have none.
+func stopwordsFor(language, text string) (map[string]bool, error) {
+	if language == "" {
+		return stopwords[detectLanguage(text).Code], nil
+	}
+	stop, ok := stopwords[strings.ToLower(language)]
+	if !ok {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "unsupported language %q; supported: de, en, es, fr, it, nl, pt", language)
+	}
+	return stop, nil
+}
+
+// handleExtractKeywords handles the keyword extraction request.
+func (s *TextAnalysisServer) handleExtractKeywords(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting extractKeywords request processing")
+
+	var args keywordsArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+	switch detectLanguage(args.Text).Code {
+	case "ja", "zh", "th":
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "keywords cannot be extracted from Chinese, Japanese or Thai text, written without spaces between words")
+	}
+	stop, err := stopwordsFor(args.Language, args.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	keywords := extractKeywords(args.Text, stop, args.Limit)
+	var b strings.Builder
+	if len(keywords) == 0 {
+		b.WriteString("No keywords found.")
+	}
+	for i, k := range keywords {
+		fmt.Fprintf(&b, "%d. %s (score %.2f", i+1, k.Phrase, k.Score)
+		if k.Count > 1 {
+			fmt.Fprintf(&b, ", %d times", k.Count)
+		}
+		b.WriteString(")\n")
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: keywords, Text: strings.TrimSpace(b.String())})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("extractKeywords request completed")
+	return result, nil
+}
+
+// handleDetectLanguage handles the language detection request.
+func (s *TextAnalysisServer) handleDetectLanguage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting detectLanguage request processing")
+
+	var args textArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+
+	d := detectLanguage(args.Text)
+	text := "The language could not be detected"
+	if d.Code != "und" {
+		text = fmt.Sprintf("Language: %s (%s), confidence %.2f", d.Name, d.Code, d.Score)
+		if d.Script != "" {
+			text += "\nScript: " + d.Script
+		}
+		if len(d.Candidates) > 1 {
+			var others []string
+			for _, c := range d.Candidates[1:] {
+				others = append(others, fmt.Sprintf("%s (%s) %.2f", c.Name, c.Code, c.Score))
+			}
+			text += "\nAlternatives: " + strings.Join(others, ", ")
+		}
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: d, Text: text})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("detectLanguage request completed")
+	return result, nil
+}
+
+// Readability are the readability scores of a text.
+type Readability struct {
+	FleschReadingEase       float64 `json:"fleschReadingEase"`
+	FleschKincaidGrade      float64 `json:"fleschKincaidGrade"`
+	GunningFog              float64 `json:"gunningFog"`
+	SMOG                    float64 `json:"smog"`
+	ColemanLiau             float64 `json:"colemanLiau"`
+	AutomatedReadability    float64 `json:"automatedReadabilityIndex"`
+	Level                   string  `json:"level"`
+	Words                   int     `json:"words"`
+	Sentences               int     `json:"sentences"`
+	Syllables               int     `json:"syllables"`
+	ComplexWords            int     `json:"complexWords"`
+	AverageSyllablesPerWord float64 `json:"averageSyllablesPerWord"`
+	AverageWordsPerSentence float64 `json:"averageWordsPerSentence"`
+	NotEnglish              bool    `json:"notEnglish,omitempty"`
+}
+
+// fleschLevel describes a Flesch reading ease score.
+func fleschLevel(score float64) string {
+	switch {
+	case score >= 90:
+		return "very easy (5th grade)"
+	case score >= 80:
+		return "easy (6th grade)"
+	case score >= 70:
+		return "fairly easy (7th grade)"
+	case score >= 60:
+		return "plain English (8th to 9th grade)"
+	case score >= 50:
+		return "fairly difficult (10th to 12th grade)"
+	case score >= 30:
+		return "difficult (college)"
+	}
+	return "very difficult (college graduate)"
+}
+
+// readability scores text, whose words are counted without numbers.
+func readability(text string) (Readability, error) {
+	var r Readability
+	letters := 0
+	for _, w := range words(text) {
+		first, _ := utf8.DecodeRuneInString(w)
+		if !wordRune(first) {
+			continue
+		}
+		r.Words++
+		letters += utf8.RuneCountInString(w)
+		n := syllables(w)
+		r.Syllables += n
+		if n >= 3 {
+			r.ComplexWords++
+		}
+	}
+	r.Sentences = len(sentences(text))
+	if r.Words == 0 || r.Sentences == 0 {
+		return r, toolerr.Errorf(toolerr.InvalidParams, "text has no words to score")
+	}
+
+	words, sents := float64(r.Words), float64(r.Sentences)
+	wordsPerSentence := words / sents
+	syllablesPerWord := float64(r.Syllables) / words
+	r.FleschReadingEase = round(206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord)
+	r.FleschKincaidGrade = round(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+	r.GunningFog = round(0.4 * (wordsPerSentence + 100*float64(r.ComplexWords)/words))
+	r.SMOG = round(1.043*math.Sqrt(float64(r.ComplexWords)*30/sents) + 3.1291)
+	r.ColemanLiau = round(0.0588*(float64(letters)/words*100) - 0.296*(sents/words*100) - 15.8)
+	r.AutomatedReadability = round(4.71*float64(letters)/words + 0.5*wordsPerSentence - 21.43)
+	r.AverageSyllablesPerWord = round(syllablesPerWord)
+	r.AverageWordsPerSentence = round(wordsPerSentence)
+	r.Level = fleschLevel(r.FleschReadingEase)
+	return r, nil
+}
+
+// handleReadabilityScores handles the readability request.
+func (s *TextAnalysisServer) handleReadabilityScores(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting readabilityScores request processing")
+
+	var args textArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+
+	r, err := readability(args.Text)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Flesch reading ease: %.2f, %s\n", r.FleschReadingEase, r.Level)
+	fmt.Fprintf(&b, "Flesch-Kincaid grade: %.2f\n", r.FleschKincaidGrade)
+	fmt.Fprintf(&b, "Gunning fog index: %.2f\n", r.GunningFog)
+	fmt.Fprintf(&b, "SMOG index: %.2f\n", r.SMOG)
+	fmt.Fprintf(&b, "Coleman-Liau index: %.2f\n", r.ColemanLiau)
+	fmt.Fprintf(&b, "Automated readability index: %.2f\n", r.AutomatedReadability)
+	fmt.Fprintf(&b, "Words: %d, sentences: %d, syllables: %d, complex words: %d", r.Words, r.Sentences, r.Syllables, r.ComplexWords)
+	if d := detectLanguage(args.Text); d.Code != "en" && d.Code != "und" {
+		r.NotEnglish = true
+		fmt.Fprintf(&b, "\nThe text seems to be in %s; the formulas are calibrated for English", d.Name)
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{Data: r, Text: b.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("readabilityScores request completed")
+	return result, nil
+}
+
+// handleChunkText handles the chunking request.
+func (s *TextAnalysisServer) handleChunkText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting chunkText request processing")
+
+	var args chunkArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+	if args.Overlap*2 >= args.MaxTokens {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "overlap must be less than half of maxTokens")
+	}
+
+	chunks := chunkText(args.Text, args.MaxTokens, args.Overlap)
+	total := len(chunks)
+	if args.Chunk > 0 {
+		if args.Chunk > total {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "chunk %d does not exist; the text has %d chunks", args.Chunk, total)
+		}
+		chunks = chunks[args.Chunk-1 : args.Chunk]
+	}
+
+	var b strings.Builder
+	for i, c := range chunks {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "--- Chunk %d of %d (%d tokens, characters %d-%d) ---\n%s", c.Index, total, c.Tokens, c.Start, c.End, c.Text)
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data: map[string]interface{}{"chunks": chunks, "totalChunks": total},
+		Text: b.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("chunkText request completed: %d chunks", total)
+	return result, nil
+}
+
+// handleSummarizeText handles the summarization request.
+func (s *TextAnalysisServer) handleSummarizeText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting summarizeText request processing")
+
+	var args summarizeArgs
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	if err := s.check(args.Text); err != nil {
+		return nil, err
+	}
+
+	stop, err := stopwordsFor("", args.Text)
+	if err != nil {
+		return nil, err
+	}
+	summary := summarize(args.Text, stop, args.Sentences)
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data: map[string]interface{}{"sentences": summary},
+		Text: strings.Join(summary, " "),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("summarizeText request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *TextAnalysisServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the text analysis server from the command line arguments in args. It
+// returns the MCPServer along with the transport flags, for callers that serve it
+// themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("textanalysis", flag.ContinueOnError)
+	var maxTextSize int
+	fs.IntVar(&maxTextSize, "max-text-size", 5*1024*1024, "Maximum text size in bytes (default 5MB)")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	log.Printf("Starting text analysis server: maxTextSize=%d", maxTextSize)
+
+	// Create TextAnalysisServer instance
+	textServer := NewTextAnalysisServer(maxTextSize)
+	log.Println("TextAnalysisServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), textServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return textServer.Server(), transportFlags, nil
+}
+
+// Run starts the text analysis server with the command line arguments in args and serves
+// it over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[TextAnalysisServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create text analysis server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("TextAnalysisServer shutdown")
+	return nil
+}
@@ -0,0 +1,229 @@
+package textanalysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+const sample = "Dr. Smith went to Washington. He said: \"It's a well-known fact!\" Then he left... The U.S. economy grew 3.5% in 2023.\n\nA new paragraph starts here. It has two sentences."
+
+// newTestServer returns a client of a server with the default flags.
+func newTestServer(t *testing.T, args ...string) *mcptest.Client {
+	s, _, err := New(context.Background(), args)
+	require.NoError(t, err)
+	return mcptest.Connect(t, s)
+}
+
+// Test splitting sentences at their end only
+func TestSentences(t *testing.T) {
+	assert.Equal(t, []string{
+		"Dr. Smith went to Washington.",
+		`He said: "It's a well-known fact!"`,
+		"Then he left...",
+		"The U.S. economy grew 3.5% in 2023.",
+		"A new paragraph starts here.",
+		"It has two sentences.",
+	}, sentences(sample))
+	assert.Equal(t, []string{"J. R. R. Tolkien wrote it", "Next"}, sentences("J. R. R. Tolkien wrote it\n\nNext"))
+	assert.Equal(t, []string{"東京は日本の首都です。", "晴れです。"}, sentences("東京は日本の首都です。晴れです。"))
+	assert.Empty(t, sentences(" \n\n "))
+}
+
+// Test estimating tokens
+func TestEstimateTokens(t *testing.T) {
+	testCases := []struct {
+		text   string
+		tokens int
+	}{
+		{"The quick brown fox jumps over the lazy dog.", 10},
+		{"internationalization", 5},
+		{"1234567", 3},
+		{"こんにちは世界", 7},
+		{"Привет мир", 5},
+		{"a\n\n    b", 3},
+		{"", 0},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.tokens, estimateTokens(tc.text), tc.text)
+	}
+}
+
+// Test detecting languages by script and stopwords
+func TestDetectLanguage(t *testing.T) {
+	testCases := []struct {
+		text string
+		code string
+	}{
+		{"The cat sat on the mat and looked at the dog.", "en"},
+		{"Le chat est sur le tapis et il regarde le chien.", "fr"},
+		{"Der Hund ist nicht in dem Haus, aber die Katze ist da.", "de"},
+		{"El perro está en la casa y el gato también.", "es"},
+		{"Привет, как дела?", "ru"},
+		{"Привіт, як справи? Їжак", "uk"},
+		{"東京は日本の首都です。", "ja"},
+		{"我们今天去公园。", "zh"},
+		{"안녕하세요", "ko"},
+		{"Καλημέρα κόσμε", "el"},
+		{"Alice Bob Carol Dave", "und"},
+		{"12345 !!!", "und"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.code, detectLanguage(tc.text).Code, tc.text)
+	}
+
+	d := detectLanguage("The cat sat on the mat and looked at the dog.")
+	assert.Equal(t, "English", d.Name)
+	assert.Equal(t, "Latin", d.Script)
+	assert.Greater(t, d.Score, 0.8)
+	assert.Equal(t, "en", d.Candidates[0].Code)
+}
+
+// Test extracting keywords with RAKE
+func TestExtractKeywords(t *testing.T) {
+	text := "Compatibility of systems of linear constraints over the set of natural numbers. Criteria of compatibility of a system of linear Diophantine equations, strict inequations, and nonstrict inequations are considered. Upper bounds for components of a minimal set of solutions and algorithms of construction of minimal generating sets of solutions for all types of systems are given."
+	keywords := extractKeywords(text, stopwords["en"], 3)
+	require.Len(t, keywords, 3)
+	assert.Equal(t, Keyword{Phrase: "linear Diophantine equations", Score: 8.5, Count: 1}, keywords[0])
+	assert.Equal(t, "minimal generating sets", keywords[1].Phrase)
+	assert.Equal(t, "linear constraints", keywords[2].Phrase)
+	assert.Empty(t, extractKeywords("the and of", stopwords["en"], 10))
+}
+
+// Test chunking text at boundaries with overlap
+func TestChunkText(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&b, "Sentence number %d is here. ", i)
+		if i%10 == 9 {
+			b.WriteString("\n\n")
+		}
+	}
+	text := b.String()
+
+	chunks := chunkText(text, 50, 0)
+	require.NotEmpty(t, chunks)
+	end := 0
+	for i, c := range chunks {
+		assert.Equal(t, i+1, c.Index)
+		assert.LessOrEqual(t, c.Tokens, 50)
+		assert.Equal(t, estimateTokens(c.Text), c.Tokens)
+		assert.True(t, strings.HasSuffix(c.Text, "here."), "Chunks end at sentences: %q", c.Text)
+		assert.GreaterOrEqual(t, c.Start, end, "Chunks do not overlap")
+		assert.Equal(t, c.Text, string([]rune(text)[c.Start:c.End]))
+		end = c.End
+	}
+
+	overlapping := chunkText(text, 50, 10)
+	require.Greater(t, len(overlapping), len(chunks))
+	for i := 1; i < len(overlapping); i++ {
+		assert.Less(t, overlapping[i].Start, overlapping[i-1].End, "Chunks overlap")
+	}
+
+	// A word longer than the limit is a chunk of its own
+	chunks = chunkText("short "+strings.Repeat("x", 100)+" end", 10, 2)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, strings.Repeat("x", 100), chunks[1].Text)
+	assert.Empty(t, chunkText(" \n ", 10, 0))
+}
+
+// Test the statistics of a text
+func TestTextStatistics(t *testing.T) {
+	c := newTestServer(t)
+
+	assert.Equal(t, `Characters: 168 (138 without spaces)
+Words: 32 (30 unique)
+Sentences: 6
+Paragraphs: 2
+Lines: 3
+Estimated tokens: 55
+Average word length: 3.81 characters
+Average sentence length: 5.33 words
+Reading time: 9s
+Speaking time: 13s`, c.Text("textStatistics", map[string]interface{}{"text": sample}))
+
+	var st Statistics
+	require.NoError(t, json.Unmarshal([]byte(c.Text("textStatistics", map[string]interface{}{"text": strings.Repeat("word ", 500), "format": "json"})), &st))
+	assert.Equal(t, 500, st.Words)
+	assert.Equal(t, 1, st.UniqueWords)
+	assert.Equal(t, 127, st.ReadingTimeSeconds)
+
+	c = newTestServer(t, "-max-text-size", "10")
+	assert.Equal(t, "text exceeds the maximum size of 10 bytes", c.Error("textStatistics", map[string]interface{}{"text": sample}))
+}
+
+// Test the keyword, language and summary tools
+func TestTools(t *testing.T) {
+	c := newTestServer(t)
+
+	text := "Solar panels are cheap. Many homes have solar panels. The electricity from solar panels is clean."
+	assert.Equal(t, "1. Solar panels (score 4.00, 3 times)\n2. cheap (score 1.00)", c.Text("extractKeywords", map[string]interface{}{"text": text, "limit": 2}))
+	assert.Equal(t, `unsupported language "xx"; supported: de, en, es, fr, it, nl, pt`, c.Error("extractKeywords", map[string]interface{}{"text": text, "language": "xx"}))
+	assert.Contains(t, c.Error("extractKeywords", map[string]interface{}{"text": "我们今天去公园。"}), "cannot be extracted from Chinese")
+
+	assert.Equal(t, "Language: Russian (ru), confidence 1.00\nScript: Cyrillic", c.Text("detectLanguage", map[string]interface{}{"text": "Привет, как дела?"}))
+	assert.Equal(t, "The language could not be detected", c.Text("detectLanguage", map[string]interface{}{"text": "42"}))
+	assert.Contains(t, c.Text("detectLanguage", map[string]interface{}{"text": "Le chat est sur le tapis et il regarde le chien."}), "Language: French (fr)")
+
+	assert.Equal(t, "Dr. Smith went to Washington. He said: \"It's a well-known fact!\"", c.Text("summarizeText", map[string]interface{}{"text": sample, "sentences": 2}))
+	var summary struct{ Sentences []string }
+	require.NoError(t, json.Unmarshal([]byte(c.Text("summarizeText", map[string]interface{}{"text": "One. Two.", "format": "json"})), &summary))
+	assert.Equal(t, []string{"One.", "Two."}, summary.Sentences)
+}
+
+// Test the readability scores
+func TestReadabilityScores(t *testing.T) {
+	c := newTestServer(t)
+
+	var r Readability
+	require.NoError(t, json.Unmarshal([]byte(c.Text("readabilityScores", map[string]interface{}{"text": "The cat sat on the mat. The dog ate the food.", "format": "json"})), &r))
+	assert.Equal(t, 116.65, r.FleschReadingEase)
+	assert.Equal(t, "very easy (5th grade)", r.Level)
+	assert.Equal(t, 11, r.Words)
+	assert.Equal(t, 2, r.Sentences)
+	assert.False(t, r.NotEnglish)
+
+	hard := "The institutionalization of interdisciplinary methodologies necessitates considerable organizational reconfiguration."
+	assert.Less(t, readabilityScore(t, c, hard), 0.0)
+	assert.Contains(t, c.Text("readabilityScores", map[string]interface{}{"text": "Der Hund ist nicht in dem Haus, aber die Katze ist da."}), "The text seems to be in German")
+	assert.Equal(t, "text has no words to score", c.Error("readabilityScores", map[string]interface{}{"text": "123 456"}))
+}
+
+// readabilityScore returns the Flesch reading ease of text.
+func readabilityScore(t *testing.T, c *mcptest.Client, text string) float64 {
+	var r Readability
+	require.NoError(t, json.Unmarshal([]byte(c.Text("readabilityScores", map[string]interface{}{"text": text, "format": "json"})), &r))
+	return r.FleschReadingEase
+}
+
+// Test the chunkText tool
+func TestChunkTextTool(t *testing.T) {
+	c := newTestServer(t)
+
+	text := strings.Repeat("This is a sentence. ", 20)
+	assert.Equal(t, "--- Chunk 2 of 5 (24 tokens, characters 80-159) ---\n"+strings.TrimSpace(strings.Repeat("This is a sentence. ", 4)),
+		c.Text("chunkText", map[string]interface{}{"text": text, "maxTokens": 25, "chunk": 2}))
+
+	var result struct {
+		Chunks      []Chunk
+		TotalChunks int
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.Text("chunkText", map[string]interface{}{"text": text, "maxTokens": 25, "overlap": 5, "format": "json"})), &result))
+	assert.Equal(t, len(result.Chunks), result.TotalChunks)
+	assert.Greater(t, result.TotalChunks, 5)
+
+	assert.Equal(t, "overlap must be less than half of maxTokens", c.Error("chunkText", map[string]interface{}{"text": text, "maxTokens": 20, "overlap": 10}))
+	assert.Equal(t, "chunk 6 does not exist; the text has 5 chunks", c.Error("chunkText", map[string]interface{}{"text": text, "maxTokens": 25, "chunk": 6}))
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewTextAnalysisServer(1024*1024).Server())
+}
@@ -0,0 +1,232 @@
+package textanalysis
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kinds of pieces of text.
+const (
+	pieceWord = iota
+	pieceNumber
+	pieceSpace
+	pieceSymbol
+)
+
+// piece is a run of text of one kind, the unit token estimates and chunks are made
+// of. Pieces cover the whole text.
+type piece struct {
+	kind       int
+	start, end int // byte offsets
+	tokens     int
+}
+
+// ideographic reports whether r belongs to a script written without spaces between
+// words, whose characters are counted as words and tokens of their own.
+func ideographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
+
+// wordRune reports whether r is part of a word.
+func wordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+// splitPieces splits text into pieces. The tokens of the pieces approximate those of
+// the byte pair encodings of current language models, within about 10% for English
+// prose: common words are one token, long ones one per 4 more letters, numbers one per
+// 3 digits, CJK characters one each and other scripts one per 2 letters.
+func splitPieces(text string) []piece {
+	var pieces []piece
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		start := i
+		switch {
+		case ideographic(r):
+			i += size
+			pieces = append(pieces, piece{kind: pieceWord, start: start, end: i, tokens: 1})
+		case wordRune(r):
+			ascii, letters := true, 0
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				// Apostrophes and hyphens join the parts of words such as don't or well-known
+				if (r == '\'' || r == '’' || r == '-') && i > start {
+					next, _ := utf8.DecodeRuneInString(text[i+size:])
+					if !wordRune(next) || ideographic(next) {
+						break
+					}
+				} else if !wordRune(r) || ideographic(r) {
+					break
+				}
+				if r >= utf8.RuneSelf {
+					ascii = false
+				}
+				letters++
+				i += size
+			}
+			tokens := (letters + 1) / 2
+			if ascii {
+				tokens = 1
+				if letters > 6 {
+					tokens += (letters - 6 + 3) / 4
+				}
+			}
+			pieces = append(pieces, piece{kind: pieceWord, start: start, end: i, tokens: tokens})
+		case unicode.IsDigit(r):
+			digits := 0
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsDigit(r) {
+					break
+				}
+				digits++
+				i += size
+			}
+			pieces = append(pieces, piece{kind: pieceNumber, start: start, end: i, tokens: (digits + 2) / 3})
+		case unicode.IsSpace(r):
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !unicode.IsSpace(r) {
+					break
+				}
+				i += size
+			}
+			// Single spaces belong to the next word; newlines and indentation are tokens
+			tokens := 0
+			if i-start > 1 || r == '\n' {
+				tokens = 1
+			}
+			pieces = append(pieces, piece{kind: pieceSpace, start: start, end: i, tokens: tokens})
+		default:
+			i += size
+			pieces = append(pieces, piece{kind: pieceSymbol, start: start, end: i, tokens: 1})
+		}
+	}
+	return pieces
+}
+
+// estimateTokens returns the estimated number of tokens of text.
+func estimateTokens(text string) int {
+	tokens := 0
+	for _, p := range splitPieces(text) {
+		tokens += p.tokens
+	}
+	return tokens
+}
+
+// words returns the words of text: runs of letters, with their inner apostrophes and
+// hyphens, numbers and CJK characters.
+func words(text string) []string {
+	var words []string
+	for _, p := range splitPieces(text) {
+		if p.kind == pieceWord || p.kind == pieceNumber {
+			words = append(words, text[p.start:p.end])
+		}
+	}
+	return words
+}
+
+// abbreviations end with a period that does not end a sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true, "st": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "cf": true, "no": true, "fig": true, "inc": true,
+	"ltd": true, "co": true, "corp": true, "approx": true, "dept": true, "est": true, "u.s": true,
+	"jan": true, "feb": true, "mar": true, "apr": true, "jun": true, "jul": true, "aug": true,
+	"sep": true, "sept": true, "oct": true, "nov": true, "dec": true,
+}
+
+// sentenceEnd reports whether r ends sentences.
+func sentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '。' || r == '！' || r == '？' || r == '…'
+}
+
+// sentences splits text into sentences, at the punctuation ending them when followed
+// by a space or the end of the text, except after abbreviations and initials, and at
+// blank lines. Sentences are trimmed.
+func sentences(text string) []string {
+	var sentences []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		switch {
+		case r == '\n':
+			// Blank lines end paragraphs, and the sentences in them
+			if j := strings.IndexFunc(text[i:], func(r rune) bool { return r != ' ' && r != '\t' && r != '\r' }); j >= 0 && text[i+j] == '\n' {
+				add(text[start:i])
+				start = i
+			}
+		case sentenceEnd(r):
+			end := i - size
+			// Closing punctuation and quotes belong to the sentence
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !sentenceEnd(r) && !strings.ContainsRune(`"')]»”’`, r) {
+					break
+				}
+				i += size
+			}
+			next, _ := utf8.DecodeRuneInString(text[i:])
+			if i < len(text) && !unicode.IsSpace(next) && r < utf8.RuneSelf {
+				continue
+			}
+			if r == '.' {
+				// Abbreviations and initials, such as J. in J. R. R. Tolkien
+				word := lastWord(text[start:end])
+				if abbreviations[strings.ToLower(word)] || (utf8.RuneCountInString(word) == 1 && unicode.IsUpper([]rune(word)[0])) {
+					continue
+				}
+			}
+			add(text[start:i])
+			start = i
+		}
+	}
+	add(text[start:])
+	return sentences
+}
+
+// lastWord returns the letters and inner periods ending s, e.g. e.g of "see e.g".
+func lastWord(s string) string {
+	i := strings.LastIndexFunc(s, func(r rune) bool { return !wordRune(r) && r != '.' })
+	return strings.Trim(s[i+1:], ".")
+}
+
+// paragraphs returns the number of paragraphs of text, separated by blank lines.
+func paragraphs(text string) int {
+	n := 0
+	blank := true
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			blank = true
+		} else if blank {
+			blank = false
+			n++
+		}
+	}
+	return n
+}
+
+// syllables estimates the syllables of an English word, counting groups of vowels
+// less a silent final e.
+func syllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	vowel := false
+	for _, r := range word {
+		isVowel := strings.ContainsRune("aeiouy", r)
+		if isVowel && !vowel {
+			count++
+		}
+		vowel = isVowel
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
@@ -0,0 +1,231 @@
+package timeserver
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/format"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// TimeServer is an MCP server that provides the current time.
+type TimeServer struct {
+	server          *server.MCPServer
+	defaultTimezone string
+	recent          *resources.Recent
+}
+
+// recentQueries is the number of time queries kept as resources.
+const recentQueries = 20
+
+// NewTimeServer creates a new TimeServer instance.
+func NewTimeServer(defaultTimezone string) *TimeServer {
+	log.Printf("TimeServer created: default timezone=%s", defaultTimezone)
+	s := &TimeServer{
+		defaultTimezone: defaultTimezone,
+	}
+
+	mcpServer := resources.NewMCPServer(
+		"time-server", // server name
+		"1.0.0",       // version
+	)
+	s.recent = resources.NewRecent(mcpServer, "time://queries", "time queries", recentQueries)
+
+	// The current time of any timezone, e.g. time://now/Asia/Seoul
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("time://now/{+timezone}", "Current time",
+		mcp.WithTemplateDescription("The current time in an IANA timezone, in RFC3339 format"),
+		mcp.WithTemplateMIMEType("text/plain"),
+	), s.readCurrentTime)
+
+	// Register getCurrentTime tool
+	tool := mcp.NewTool("getCurrentTime",
+		mcp.WithDescription("Returns the time for the specified timezone. If a time string is provided, it converts that time; otherwise, it returns the current time."),
+		mcp.WithString("timezone",
+			mcp.Description("Timezone to query the time for (e.g., Asia/Seoul, UTC); defaults to that of the server"),
+		),
+		mcp.WithString("timeStr",
+			mcp.Description("RFC3339 formatted time string to convert (e.g., 2025-04-06T14:30:00Z). If empty, current time is used"),
+		),
+		format.Param(format.Text, format.Markdown, format.JSON),
+	)
+
+	middleware.AddTool(mcpServer, tool, s.handleGetCurrentTime)
+	s.server = mcpServer
+	return s
+}
+
+// convertTimeToTimezone converts a time to the specified timezone.
+// timeStr is an RFC3339 formatted time string (e.g., "2025-04-06T14:30:00Z").
+// If timeStr is empty, the current time (time.Now()) is used.
+// If requestedTimezone is empty, defaultTimezone is used.
+func (s *TimeServer) convertTimeToTimezone(timeStr, requestedTimezone string) (string, time.Time, error) {
+	// Use default timezone if parameter is empty
+	timezone := requestedTimezone
+	if timezone == "" {
+		timezone = s.defaultTimezone
+		log.Printf("Using default timezone: %s", timezone)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("Error: Invalid timezone: %s - %v", timezone, err)
+		return "", time.Time{}, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	// Check if time string was provided
+	var targetTime time.Time
+	if timeStr == "" {
+		// Use current time if no time string is provided
+		targetTime = time.Now().In(loc)
+	} else {
+		// Parse the time string
+		parsedTime, err := time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			log.Printf("Error: Invalid time format: %s - %v", timeStr, err)
+			return "", time.Time{}, fmt.Errorf("invalid time format (expected RFC3339, e.g. 2025-04-06T14:30:00Z): %w", err)
+		}
+
+		// Convert parsed time to requested timezone
+		targetTime = parsedTime.In(loc)
+	}
+
+	return timezone, targetTime, nil
+}
+
+// handleGetCurrentTime handles the current time request.
+func (s *TimeServer) handleGetCurrentTime(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting time request processing")
+	var args struct {
+		Timezone string `json:"timezone"`
+		TimeStr  string `json:"timeStr,omitempty"` // Optional time string parameter
+		Format   string `json:"format,omitempty" param:"enum=text|markdown|json"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+	log.Printf("Parameters: timezone=%s, timeStr=%s", args.Timezone, args.TimeStr)
+
+	// Convert time based on timezone using the separated function
+	timezone, now, err := s.convertTimeToTimezone(args.TimeStr, args.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create result message
+	label := "Current time"
+	if args.TimeStr != "" {
+		label = "Converted time"
+	}
+	resultMsg := fmt.Sprintf("%s (%s): %s", label, timezone, now.Format(time.RFC3339))
+	table := format.Table{
+		Columns: []string{"Timezone", label},
+		Rows:    [][]string{{timezone, now.Format(time.RFC3339)}},
+	}
+
+	result, err := format.Result(format.Format(args.Format), format.Output{
+		Data: map[string]interface{}{
+			"timezone": timezone,
+			"time":     now.Format(time.RFC3339),
+			"unix":     now.Unix(),
+		},
+		Text:     resultMsg,
+		Markdown: table.Markdown(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recent.Add(ctx, timezone, "text/plain", resultMsg)
+	log.Println("Time request processing completed")
+	return result, nil
+}
+
+// readCurrentTime reads the time://now resource of a timezone.
+func (s *TimeServer) readCurrentTime(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	timezone := resources.Argument(req, "timezone")
+	_, now, err := s.convertTimeToTimezone("", timezone)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      req.Params.URI,
+		MIMEType: "text/plain",
+		Text:     now.Format(time.RFC3339),
+	}}, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *TimeServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the time server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("time", flag.ContinueOnError)
+	var defaultTimezone string
+	fs.StringVar(&defaultTimezone, "timezone", "Asia/Seoul", "Set default timezone")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	// Set default timezone
+	log.Printf("Starting time server: default timezone=%s", defaultTimezone)
+
+	// Create TimeServer instance with default timezone
+	timeServer := NewTimeServer(defaultTimezone)
+	log.Println("TimeServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), timeServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return timeServer.Server(), transportFlags, nil
+}
+
+// Run starts the time server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[TimeServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create time server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("TimeServer shutdown")
+	return nil
+}
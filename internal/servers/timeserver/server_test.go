@@ -1,11 +1,16 @@
-package main
+package timeserver
 
 import (
+	"context"
+	"encoding/json"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
 )
 
 // TimeServer creation test
@@ -264,3 +269,59 @@ func TestDefaultTimezoneLogic(t *testing.T) {
 		assert.NoError(t, err, "Default timezone should be valid")
 	})
 }
+
+// Test the current time and recent query resources
+func TestResources(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTimeServer("UTC")
+
+	contents, err := mcptest.ReadResource(ctx, ts.Server(), "time://now/Asia/Seoul")
+	require.NoError(t, err)
+	now, err := time.Parse(time.RFC3339, mcptest.ResourceText(contents))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), now, time.Minute)
+	_, offset := now.Zone()
+	assert.Equal(t, 9*60*60, offset)
+	_, err = mcptest.ReadResource(ctx, ts.Server(), "time://now/Nowhere/City")
+	assert.ErrorContains(t, err, "invalid timezone")
+
+	_, err = ts.handleGetCurrentTime(ctx, mcptest.NewCallToolRequest("getCurrentTime", map[string]interface{}{
+		"timezone": "UTC", "timeStr": "2025-04-06T14:30:00Z",
+	}))
+	require.NoError(t, err)
+	contents, err = mcptest.ReadResource(ctx, ts.Server(), "time://queries")
+	require.NoError(t, err)
+	var index []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResourceText(contents)), &index))
+	require.Len(t, index, 1)
+	contents, err = mcptest.ReadResource(ctx, ts.Server(), index[0]["uri"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "Converted time (UTC): 2025-04-06T14:30:00Z", mcptest.ResourceText(contents))
+}
+
+// Test rendering times as text, Markdown and JSON
+func TestFormats(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTimeServer("UTC")
+	getTime := func(format string) string {
+		result, err := ts.handleGetCurrentTime(ctx, mcptest.NewCallToolRequest("getCurrentTime", map[string]interface{}{
+			"timezone": "Asia/Seoul", "timeStr": "2025-04-06T14:30:00Z", "format": format,
+		}))
+		require.NoError(t, err)
+		return mcptest.ResultText(result)
+	}
+
+	assert.Equal(t, "Converted time (Asia/Seoul): 2025-04-06T23:30:00+09:00", getTime("text"))
+	assert.Equal(t, "| Timezone | Converted time |\n| --- | --- |\n| Asia/Seoul | 2025-04-06T23:30:00+09:00 |", getTime("markdown"))
+	assert.JSONEq(t, `{"timezone": "Asia/Seoul", "time": "2025-04-06T23:30:00+09:00", "unix": 1743949800}`, getTime("json"))
+
+	_, err := ts.handleGetCurrentTime(ctx, mcptest.NewCallToolRequest("getCurrentTime", map[string]interface{}{
+		"timezone": "UTC", "format": "xml",
+	}))
+	assert.ErrorContains(t, err, `format must be one of text, markdown, json, not "xml"`)
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	mcptest.FuzzTools(f, NewTimeServer("UTC").Server())
+}
@@ -0,0 +1,49 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+)
+
+// Tools returns the tools of s, created with its default flags, e.g. for help output.
+// The server is closed again, so its background work never starts.
+func Tools(s Server) ([]mcp.Tool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mcpServer, _, err := s.New(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer middleware.Close(mcpServer)
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  "tools/list",
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result mcp.ListToolsResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s", response.Error.Message)
+	}
+	return response.Result.Tools, nil
+}
@@ -0,0 +1,328 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcphost/internal/config"
+	"github.com/mark3labs/mcphost/internal/httpclient"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/internal/resilience"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// maxResponseSize caps the response of a webhook quoted in results.
+const maxResponseSize = 500
+
+// WebhookServer is an MCP server sending notifications to webhooks configured by
+// name, so that agents never see their secret URLs.
+type WebhookServer struct {
+	server  *server.MCPServer
+	client  *http.Client
+	targets map[string]*Target
+	now     func() time.Time
+}
+
+// NewWebhookServer creates a new WebhookServer instance sending notifications to
+// targets. Deliveries failing with a network error, 429 or a 5xx are retried up to
+// retries times.
+func NewWebhookServer(targets map[string]*Target, timeout, retries int) *WebhookServer {
+	log.Printf("WebhookServer created: targets=%d, timeout=%ds, retries=%d", len(targets), timeout, retries)
+
+	s := &WebhookServer{
+		client:  httpclient.New(httpclient.Options{Timeout: time.Duration(timeout) * time.Second, Retries: retries, UserAgent: "mcphost-webhook/1.0"}),
+		targets: targets,
+		now:     time.Now,
+	}
+	// A target cannot forward the notification to a URL that is not configured
+	s.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	targetDescription := "Name of the webhook target"
+	if len(names) > 0 {
+		var described []string
+		for _, name := range names {
+			t := targets[name]
+			d := name + " (" + t.Type
+			if t.Description != "" {
+				d += ": " + t.Description
+			}
+			described = append(described, d+")")
+		}
+		targetDescription = "Webhook target: " + strings.Join(described, ", ")
+	}
+
+	mcpServer := server.NewMCPServer(
+		"webhook-server", // server name
+		"1.0.0",          // version
+	)
+
+	// Register sendWebhook tool
+	sendTool := mcp.NewTool("sendWebhook",
+		mcp.WithDescription("Sends a notification to a configured webhook target, such as a Slack or Discord channel or an HTTP endpoint. The payload is built from the message, title and data for the target"),
+		mcp.WithString("target",
+			mcp.Description(targetDescription),
+			mcp.Required(),
+		),
+		mcp.WithString("message",
+			mcp.Description("Text of the notification"),
+		),
+		mcp.WithString("title",
+			mcp.Description("Optional title, shown in bold in chat messages"),
+		),
+		mcp.WithObject("data",
+			mcp.Description("Optional fields of the notification, listed in chat messages and sent as data in generic payloads or to the template of the target"),
+		),
+	)
+
+	middleware.AddTool(mcpServer, sendTool, s.handleSendWebhook)
+
+	s.server = mcpServer
+	return s
+}
+
+// newDeliveryID returns a random ID identifying a delivery across its retries.
+func newDeliveryID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// deliver sends payload to target t, signed if t has a secret, and returns the
+// response status and its beginning.
+func (s *WebhookServer) deliver(ctx context.Context, name string, t *Target, payload []byte) (string, error) {
+	delivery := newDeliveryID()
+	// Receivers deduplicate the retries of a delivery by its ID
+	req, err := http.NewRequestWithContext(resilience.AllowRetry(ctx), t.Method, t.URL, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("invalid request to %s: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Delivery", delivery)
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	if t.Secret != "" {
+		req.Header.Set(t.SignatureHeader, sign(t.Secret, payload))
+	}
+
+	log.Printf("Sending webhook %s (delivery %s)", name, delivery)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The error of the client quotes the URL, a secret
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		log.Printf("Error: Webhook %s failed: %v", name, err)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return "", err
+		}
+		return "", toolerr.Errorf(toolerr.Unavailable, "webhook %s could not be reached: %v", name, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	body := strings.TrimSpace(string(data))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Printf("Error: Webhook %s returned %s: %s", name, resp.Status, body)
+		code := toolerr.InvalidParams
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			code = toolerr.PermissionDenied
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+			code = toolerr.NotFound
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			code = toolerr.Unavailable
+		case resp.StatusCode < 400:
+			code = toolerr.Internal
+		}
+		return "", toolerr.Errorf(code, "webhook %s returned %s: %s", name, resp.Status, body)
+	}
+	return strings.TrimSpace(resp.Status + "\n" + body), nil
+}
+
+// handleSendWebhook handles the notification request.
+func (s *WebhookServer) handleSendWebhook(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Println("Starting sendWebhook request processing")
+
+	var args struct {
+		Target  string                 `json:"target" param:"required"`
+		Message string                 `json:"message,omitempty"`
+		Title   string                 `json:"title,omitempty"`
+		Data    map[string]interface{} `json:"data,omitempty"`
+	}
+
+	if err := params.Decode(req, &args); err != nil {
+		log.Printf("Error: %v", err)
+		return nil, err
+	}
+
+	t, ok := s.targets[args.Target]
+	if !ok {
+		names := make([]string, 0, len(s.targets))
+		for name := range s.targets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, toolerr.Errorf(toolerr.NotFound, "webhook target %q is not configured; no targets are configured", args.Target)
+		}
+		return nil, toolerr.Errorf(toolerr.NotFound, "webhook target %q is not configured; targets are %s", args.Target, strings.Join(names, ", "))
+	}
+	if strings.TrimSpace(args.Message) == "" && strings.TrimSpace(args.Title) == "" && len(args.Data) == 0 {
+		return nil, toolerr.Errorf(toolerr.InvalidParams, "message, title or data is required")
+	}
+
+	payload, err := t.payload(Notification{
+		Target:  args.Target,
+		Title:   args.Title,
+		Message: args.Message,
+		Data:    args.Data,
+		Time:    s.now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.deliver(ctx, args.Target, t, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Notification sent to %s: %s", args.Target, response),
+			},
+		},
+	}
+
+	log.Println("sendWebhook request completed")
+	return result, nil
+}
+
+// Server returns the MCPServer - for direct access by mcphost
+func (s *WebhookServer) Server() *server.MCPServer {
+	return s.server
+}
+
+// New creates the webhook server from the command line arguments in args. It returns the
+// MCPServer along with the transport flags, for callers that serve it themselves.
+func New(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+	// Define flags
+	fs := flag.NewFlagSet("webhook", flag.ContinueOnError)
+	var (
+		targetsFile string
+		slackURL    string
+		discordURL  string
+		timeout     int
+		retries     int
+	)
+	fs.StringVar(&targetsFile, "targets", "", "YAML or JSON file of the webhook targets, by name")
+	fs.StringVar(&slackURL, "slack-url", "", "Slack incoming webhook URL, added as the target slack")
+	fs.StringVar(&discordURL, "discord-url", "", "Discord webhook URL, added as the target discord")
+	fs.IntVar(&timeout, "timeout", 15, "HTTP request timeout in seconds")
+	fs.IntVar(&retries, "retries", 2, "Number of retries of deliveries failing with a network error, 429 or a 5xx")
+	var transportFlags transport.Flags
+	transportFlags.Register(fs)
+	var middlewareFlags middleware.Flags
+	middlewareFlags.Register(fs)
+
+	// Parse flags
+	if err := config.Parse(fs, args); err != nil {
+		return nil, transport.Flags{}, err
+	}
+
+	targets := map[string]*Target{}
+	if targetsFile != "" {
+		var err error
+		if targets, err = loadTargets(targetsFile); err != nil {
+			log.Printf("Error: %v", err)
+			return nil, transport.Flags{}, err
+		}
+	}
+	for name, target := range map[string]*Target{
+		Slack:   {Type: Slack, URL: slackURL},
+		Discord: {Type: Discord, URL: discordURL},
+	} {
+		if target.URL == "" {
+			continue
+		}
+		if _, ok := targets[name]; ok {
+			return nil, transport.Flags{}, fmt.Errorf("target %s is set both by -%s-url and in %s", name, name, targetsFile)
+		}
+		if err := target.prepare(); err != nil {
+			return nil, transport.Flags{}, fmt.Errorf("invalid -%s-url: %w", name, err)
+		}
+		targets[name] = target
+	}
+
+	log.Printf("Starting webhook server: targets=%d, timeout=%ds, retries=%d", len(targets), timeout, retries)
+	if len(targets) == 0 {
+		log.Printf("Warning: No targets configured. Use -targets, -slack-url or -discord-url to configure webhooks.")
+	}
+
+	// Create WebhookServer instance
+	webhookServer := NewWebhookServer(targets, timeout, retries)
+	log.Println("WebhookServer instance created successfully, starting server...")
+
+	if err := middlewareFlags.Apply(ctx, fs.Name(), webhookServer.Server()); err != nil {
+		return nil, transport.Flags{}, err
+	}
+	return webhookServer.Server(), transportFlags, nil
+}
+
+// Run starts the webhook server with the command line arguments in args and serves it
+// over the selected transport until the client disconnects.
+func Run(args []string) error {
+	// Set up basic logging
+	log.SetPrefix("[WebhookServer] ")
+	log.SetFlags(log.Ldate | log.Ltime)
+
+	// Background work of the server stops when Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create webhook server instance
+	mcpServer, transportFlags, err := New(ctx, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	defer middleware.Close(mcpServer)
+	if err := transport.Serve(mcpServer, transportFlags); err != nil {
+		log.Printf("Error: Server execution failed: %v", err)
+		return err
+	}
+
+	log.Println("WebhookServer shutdown")
+	return nil
+}
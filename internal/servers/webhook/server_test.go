@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// received is a request received by a test webhook.
+type received struct {
+	Method string
+	Header http.Header
+	Body   string
+}
+
+// newTestWebhook starts a webhook answering ok, the first failures requests with 503
+// Service Unavailable and those to paths ending in /revoked with 404 Not Found.
+func newTestWebhook(t *testing.T, failures int) (*httptest.Server, func() []received) {
+	var mu sync.Mutex
+	var requests []received
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{Method: r.Method, Header: r.Header.Clone(), Body: string(body)})
+		n := len(requests)
+		mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/revoked"):
+			http.Error(w, "no_service", http.StatusNotFound)
+		case n <= failures:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), requests...)
+	}
+}
+
+// writeTargets writes a targets file.
+func writeTargets(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+// Test loading targets and resolving their secrets
+func TestLoadTargets(t *testing.T) {
+	t.Setenv("MCPHOST_TEST_HOOK", "https://hooks.slack.com/services/T0/B0/secret-path")
+	targets, err := loadTargets(writeTargets(t, `
+targets:
+  alerts:
+    type: Slack
+    url: env:MCPHOST_TEST_HOOK
+    description: on-call channel
+  deploys:
+    url: https://ci.example.com/hooks/deploy
+    method: put
+    headers:
+      Authorization: Bearer token-of-ci
+    secret: signing-secret
+    template: '{"event": "deploy", "text": {{json .message}}}'
+`))
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, Slack, targets["alerts"].Type)
+	assert.Equal(t, "https://hooks.slack.com/services/T0/B0/secret-path", targets["alerts"].URL)
+	assert.Equal(t, Generic, targets["deploys"].Type)
+	assert.Equal(t, http.MethodPut, targets["deploys"].Method)
+	assert.Equal(t, defaultSignatureHeader, targets["deploys"].SignatureHeader)
+	assert.NotNil(t, targets["deploys"].template)
+
+	tests := map[string]string{
+		"targets:\n  a:\n    url: ftp://example.com\n":                         "target a: url must be an http or https URL",
+		"targets:\n  a:\n    type: teams\n    url: https://example.com\n":      `target a: unknown type "teams"`,
+		"targets:\n  a:\n    url: https://example.com\n    template: '{{.x'\n": "target a: invalid template",
+		"targets:\n  a:\n    url: https://example.com\n    colour: red\n":      "field colour not found",
+		"targets:\n  a:\n": "target a is empty",
+		"targets:\n  a:\n    url: env:MCPHOST_TEST_UNSET_HOOK\n": "MCPHOST_TEST_UNSET_HOOK",
+	}
+	for content, want := range tests {
+		_, err := loadTargets(writeTargets(t, content))
+		assert.ErrorContains(t, err, want, content)
+	}
+	_, err = loadTargets(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "error reading targets file")
+}
+
+// Test the payloads of the types of targets and of templates
+func TestPayload(t *testing.T) {
+	n := Notification{
+		Target:  "alerts",
+		Title:   "Deploy",
+		Message: "v1.2 is live @everyone",
+		Data:    map[string]interface{}{"env": "prod", "replicas": 3},
+		Time:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	payload := func(target *Target) map[string]interface{} {
+		target.URL = "https://example.com/hook"
+		require.NoError(t, target.prepare())
+		data, err := target.payload(n)
+		require.NoError(t, err)
+		var v map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &v))
+		return v
+	}
+
+	assert.Equal(t, map[string]interface{}{"text": "*Deploy*\nv1.2 is live @everyone\n• env: prod\n• replicas: 3"}, payload(&Target{Type: Slack}))
+
+	discord := payload(&Target{Type: Discord})
+	assert.Equal(t, "**Deploy**\nv1.2 is live @\u200beveryone\n• env: prod\n• replicas: 3", discord["content"])
+	assert.Equal(t, map[string]interface{}{"parse": []interface{}{}}, discord["allowed_mentions"])
+
+	assert.Equal(t, map[string]interface{}{
+		"target": "alerts", "title": "Deploy", "message": "v1.2 is live @everyone", "time": "2026-03-01T12:00:00Z",
+		"data": map[string]interface{}{"env": "prod", "replicas": float64(3)},
+	}, payload(&Target{}))
+
+	// Templates quote the values they are given with json
+	assert.Equal(t, map[string]interface{}{"summary": "Deploy: v1.2 is live @everyone", "env": "prod", "missing": nil},
+		payload(&Target{Template: `{"summary": {{json (printf "%s: %s" .title .message)}}, "env": {{json .data.env}}, "missing": {{json .data.other}}}`}))
+
+	invalid := &Target{URL: "https://example.com/hook", Template: `{"text": {{.message}}}`}
+	require.NoError(t, invalid.prepare())
+	_, err := invalid.payload(n)
+	assert.ErrorContains(t, err, "the template of alerts does not render valid JSON")
+
+	long := &Target{Type: Discord, URL: "https://example.com/hook"}
+	require.NoError(t, long.prepare())
+	_, err = long.payload(Notification{Message: strings.Repeat("a", discordLimit+1)})
+	assert.Equal(t, toolerr.InvalidParams, toolerr.Classify(err).Code)
+}
+
+// Test sending notifications, signed, with retries
+func TestSendWebhook(t *testing.T) {
+	ts, requests := newTestWebhook(t, 1)
+	targets := map[string]*Target{
+		"alerts":  {Type: Slack, URL: ts.URL + "/slack"},
+		"deploys": {URL: ts.URL + "/deploy", Headers: map[string]string{"Authorization": "Bearer x"}, Secret: "signing-secret"},
+		"revoked": {Type: Discord, URL: ts.URL + "/revoked"},
+	}
+	for _, target := range targets {
+		require.NoError(t, target.prepare())
+	}
+	s := NewWebhookServer(targets, 5, 2)
+	c := mcptest.Connect(t, s.Server())
+
+	// The first delivery fails with 503 and is retried with the same ID
+	text := c.Text("sendWebhook", map[string]interface{}{"target": "alerts", "message": "disk full"})
+	assert.Equal(t, "Notification sent to alerts: 200 OK\nok", text)
+	got := requests()
+	require.Len(t, got, 2)
+	assert.Equal(t, `{"text":"disk full"}`, got[1].Body)
+	assert.Equal(t, "application/json", got[1].Header.Get("Content-Type"))
+	assert.NotEmpty(t, got[0].Header.Get("X-Webhook-Delivery"))
+	assert.Equal(t, got[0].Header.Get("X-Webhook-Delivery"), got[1].Header.Get("X-Webhook-Delivery"))
+
+	// Payloads of targets with a secret are signed
+	c.Text("sendWebhook", map[string]interface{}{"target": "deploys", "title": "v2", "data": map[string]interface{}{"sha": "abc"}})
+	got = requests()
+	deploy := got[len(got)-1]
+	assert.Equal(t, http.MethodPost, deploy.Method)
+	assert.Equal(t, "Bearer x", deploy.Header.Get("Authorization"))
+	assert.Equal(t, sign("signing-secret", []byte(deploy.Body)), deploy.Header.Get(defaultSignatureHeader))
+	assert.Contains(t, deploy.Body, `"data":{"sha":"abc"}`)
+
+	// Errors do not quote the URLs of the targets
+	text = c.Error("sendWebhook", map[string]interface{}{"target": "revoked", "message": "x"})
+	assert.Contains(t, text, "webhook revoked returned 404 Not Found: no_service")
+	assert.NotContains(t, text, ts.URL)
+
+	_, err := s.handleSendWebhook(context.Background(), mcptest.NewCallToolRequest("sendWebhook", map[string]interface{}{"target": "pager", "message": "x"}))
+	assert.EqualError(t, err, `webhook target "pager" is not configured; targets are alerts, deploys, revoked`)
+	assert.Equal(t, toolerr.NotFound, toolerr.Classify(err).Code)
+	_, err = s.handleSendWebhook(context.Background(), mcptest.NewCallToolRequest("sendWebhook", map[string]interface{}{"target": "alerts"}))
+	assert.EqualError(t, err, "message, title or data is required")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	unreachable := &Target{URL: closed.URL + "/hook"}
+	require.NoError(t, unreachable.prepare())
+	s = NewWebhookServer(map[string]*Target{"down": unreachable}, 5, 0)
+	_, err = s.handleSendWebhook(context.Background(), mcptest.NewCallToolRequest("sendWebhook", map[string]interface{}{"target": "down", "message": "x"}))
+	require.Error(t, err)
+	assert.Equal(t, toolerr.Unavailable, toolerr.Classify(err).Code)
+	assert.NotContains(t, err.Error(), closed.URL)
+}
+
+// Test the targets set by flags
+func TestNew(t *testing.T) {
+	s, _, err := New(context.Background(), []string{"-slack-url", "https://hooks.slack.com/services/T/B/X", "-discord-url", "https://discord.com/api/webhooks/1/x"})
+	require.NoError(t, err)
+	tool := mcptest.Connect(t, s).Tool("sendWebhook")
+	assert.Contains(t, tool.InputSchema.Properties["target"].(map[string]interface{})["description"], "discord (discord), slack (slack)")
+
+	file := writeTargets(t, "targets:\n  slack:\n    type: slack\n    url: https://hooks.slack.com/services/T/B/Y\n")
+	_, _, err = New(context.Background(), []string{"-targets", file, "-slack-url", "https://hooks.slack.com/services/T/B/X"})
+	assert.ErrorContains(t, err, "target slack is set both by -slack-url and in "+file)
+	_, _, err = New(context.Background(), []string{"-discord-url", "discord.com/api/webhooks/1/x"})
+	assert.ErrorContains(t, err, "invalid -discord-url: url must be an http or https URL")
+}
+
+// Fuzz the arguments of the tools
+func FuzzTools(f *testing.F) {
+	target := &Target{Type: Slack, URL: "https://hooks.example.com/services/x"}
+	if err := target.prepare(); err != nil {
+		f.Fatal(err)
+	}
+	mcptest.FuzzTools(f, NewWebhookServer(map[string]*Target{"alerts": target}, 5, 0).Server())
+}
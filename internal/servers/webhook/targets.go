@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/redact"
+	"github.com/mark3labs/mcphost/internal/secretref"
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Kinds of targets, which decide the payload sent without a template.
+const (
+	Slack   = "slack"
+	Discord = "discord"
+	Generic = "generic"
+)
+
+// defaultSignatureHeader carries the HMAC signature of the payloads of targets with a
+// secret.
+const defaultSignatureHeader = "X-Signature-256"
+
+// discordLimit is the number of characters of a Discord message.
+const discordLimit = 2000
+
+// Target is a webhook agents may send notifications to by name. The URL, the header
+// values and the secret may be secret references, see package secretref.
+type Target struct {
+	Type        string            `yaml:"type"` // slack, discord or generic (default)
+	URL         string            `yaml:"url"`
+	Description string            `yaml:"description"`
+	Method      string            `yaml:"method"` // POST unless set
+	Headers     map[string]string `yaml:"headers"`
+	// Template renders the JSON payload from the message, title and data of the
+	// notification, instead of the default payload of Type.
+	Template string `yaml:"template"`
+	// Secret signs the payloads with HMAC-SHA256, sent as sha256=<hex> in
+	// SignatureHeader.
+	Secret          string `yaml:"secret"`
+	SignatureHeader string `yaml:"signatureHeader"`
+
+	template *template.Template
+}
+
+// targetsFile is the layout of the -targets file.
+type targetsFile struct {
+	Targets map[string]*Target `yaml:"targets"`
+}
+
+// loadTargets reads the targets of a YAML or JSON file.
+func loadTargets(file string) (map[string]*Target, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading targets file %s: %w", file, err)
+	}
+	var f targetsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error parsing targets file %s: %w", file, err)
+	}
+	for name, t := range f.Targets {
+		if t == nil {
+			return nil, fmt.Errorf("error parsing targets file %s: target %s is empty", file, name)
+		}
+		if err := t.prepare(); err != nil {
+			return nil, fmt.Errorf("error parsing targets file %s: target %s: %w", file, name, err)
+		}
+	}
+	return f.Targets, nil
+}
+
+// prepare resolves the secrets of t, checks it and parses its template. The secrets
+// are masked in the log.
+func (t *Target) prepare() error {
+	t.Type = strings.ToLower(t.Type)
+	switch t.Type {
+	case "":
+		t.Type = Generic
+	case Slack, Discord, Generic:
+	default:
+		return fmt.Errorf("unknown type %q, expected slack, discord or generic", t.Type)
+	}
+	var err error
+	if t.URL, err = secretref.Resolve(t.URL); err != nil {
+		return err
+	}
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http or https URL")
+	}
+	// Incoming webhook URLs are credentials
+	redact.AddSecret(t.URL)
+	if t.Method == "" {
+		t.Method = http.MethodPost
+	}
+	t.Method = strings.ToUpper(t.Method)
+	for name, value := range t.Headers {
+		if t.Headers[name], err = secretref.Resolve(value); err != nil {
+			return err
+		}
+		if secretref.IsRef(value) || redact.SecretName(name) {
+			redact.AddSecret(t.Headers[name])
+		}
+	}
+	if t.Secret != "" {
+		if t.Secret, err = secretref.Resolve(t.Secret); err != nil {
+			return err
+		}
+		redact.AddSecret(t.Secret)
+		if t.SignatureHeader == "" {
+			t.SignatureHeader = defaultSignatureHeader
+		}
+	}
+	if t.Template != "" {
+		t.template, err = template.New("payload").Option("missingkey=zero").Funcs(template.FuncMap{"json": toJSON}).Parse(t.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
+
+// toJSON encodes a value in templates, so that strings are quoted and escaped.
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Notification is what is sent to a target.
+type Notification struct {
+	Target  string
+	Title   string
+	Message string
+	Data    map[string]interface{}
+	Time    time.Time
+}
+
+// fields returns the values of n for templates and generic payloads.
+func (n Notification) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"target":  n.Target,
+		"message": n.Message,
+		"time":    n.Time.UTC().Format(time.RFC3339),
+	}
+	if n.Title != "" {
+		fields["title"] = n.Title
+	}
+	if len(n.Data) > 0 {
+		fields["data"] = n.Data
+	}
+	return fields
+}
+
+// text renders n as the text of a chat message: the title in bold, the message and
+// the data as a list.
+func (n Notification) text(bold string) string {
+	var lines []string
+	if n.Title != "" {
+		lines = append(lines, bold+n.Title+bold)
+	}
+	if n.Message != "" {
+		lines = append(lines, n.Message)
+	}
+	keys := make([]string, 0, len(n.Data))
+	for key := range n.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := n.Data[key].(string)
+		if !ok {
+			data, _ := json.Marshal(n.Data[key])
+			value = string(data)
+		}
+		lines = append(lines, fmt.Sprintf("• %s: %s", key, value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// payload returns the JSON body of n for t: its template rendered, or the default
+// payload of its type.
+func (t *Target) payload(n Notification) ([]byte, error) {
+	if t.template != nil {
+		var b bytes.Buffer
+		if err := t.template.Execute(&b, n.fields()); err != nil {
+			return nil, fmt.Errorf("error rendering the template of %s: %w", n.Target, err)
+		}
+		if !json.Valid(b.Bytes()) {
+			return nil, fmt.Errorf("the template of %s does not render valid JSON: %.200s", n.Target, b.String())
+		}
+		return b.Bytes(), nil
+	}
+	switch t.Type {
+	case Slack:
+		return json.Marshal(map[string]interface{}{"text": n.text("*")})
+	case Discord:
+		content := n.text("**")
+		// Mass mentions are neutralized, and no mention pings anyone
+		content = strings.NewReplacer("@everyone", "@\u200beveryone", "@here", "@\u200bhere").Replace(content)
+		if len([]rune(content)) > discordLimit {
+			return nil, toolerr.Errorf(toolerr.InvalidParams, "the message exceeds the Discord limit of %d characters", discordLimit)
+		}
+		return json.Marshal(map[string]interface{}{
+			"content":          content,
+			"allowed_mentions": map[string]interface{}{"parse": []string{}},
+		})
+	}
+	return json.Marshal(n.fields())
+}
+
+// sign returns the HMAC-SHA256 signature of payload with secret, as sha256=<hex>.
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package service
+
+import "context"
+
+// Run runs fn; services outside Windows are ordinary processes stopped with signals.
+func Run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,158 @@
+// Package service registers mcphost serve with the service manager of the system, a
+// systemd unit on Linux, a launchd agent or daemon on macOS or a Windows service, so
+// that the servers run in the background, start at boot or login and restart when
+// they fail.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultName is the name of the service unless another is given.
+const DefaultName = "mcphost"
+
+// ErrUnsupported is returned on systems without a supported service manager.
+var ErrUnsupported = errors.New("services are not supported on this system")
+
+// Config describes the service to install.
+type Config struct {
+	// Name identifies the service: the systemd unit, the launchd label or the
+	// Windows service name.
+	Name        string
+	Description string
+	// Executable and Args are the command the service runs.
+	Executable string
+	Args       []string
+	// WorkingDir is the directory the command runs in.
+	WorkingDir string
+	// Env are environment variables of the command, such as PATH and API keys.
+	Env map[string]string
+	// LogFile receives the output of the command; with systemd, the journal does
+	// when it is empty.
+	LogFile string
+	// System installs the service for all users, started at boot, rather than for
+	// the current user, started at login. Windows services are always system ones.
+	System bool
+}
+
+// Label returns the launchd label of the service, in reverse DNS form.
+func (c Config) Label() string {
+	if strings.Contains(c.Name, ".") {
+		return c.Name
+	}
+	return "com.github.mark3labs." + c.Name
+}
+
+// SystemdUnit returns the systemd unit file of c.
+func SystemdUnit(c Config) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\nWants=network-online.target\nAfter=network-online.target\n\n", c.Description)
+	b.WriteString("[Service]\nType=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommand(append([]string{c.Executable}, c.Args...)))
+	if c.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(c.WorkingDir))
+	}
+	for _, name := range sortedKeys(c.Env) {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(name+"="+c.Env[name]))
+	}
+	if c.LogFile != "" {
+		fmt.Fprintf(&b, "StandardOutput=append:%s\nStandardError=append:%s\n", c.LogFile, c.LogFile)
+	}
+	b.WriteString("Restart=on-failure\nRestartSec=5\n")
+	// mcphost serve stops its servers on SIGTERM, giving them time to drain
+	b.WriteString("KillMode=mixed\nTimeoutStopSec=60\n\n")
+	target := "default.target"
+	if c.System {
+		target = "multi-user.target"
+	}
+	fmt.Fprintf(&b, "[Install]\nWantedBy=%s\n", target)
+	return b.String()
+}
+
+// systemdCommand joins a command line, quoting the arguments that need it and
+// escaping the specifiers and variables systemd would expand.
+func systemdCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "$", "$$")
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// LaunchdPlist returns the launchd property list of c.
+func LaunchdPlist(c Config) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	plistKey(&b, "Label", c.Label())
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	for _, arg := range append([]string{c.Executable}, c.Args...) {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", html.EscapeString(arg))
+	}
+	b.WriteString("  </array>\n")
+	if c.WorkingDir != "" {
+		plistKey(&b, "WorkingDirectory", c.WorkingDir)
+	}
+	if len(c.Env) > 0 {
+		b.WriteString("  <key>EnvironmentVariables</key>\n  <dict>\n")
+		for _, name := range sortedKeys(c.Env) {
+			fmt.Fprintf(&b, "    <key>%s</key>\n    <string>%s</string>\n", html.EscapeString(name), html.EscapeString(c.Env[name]))
+		}
+		b.WriteString("  </dict>\n")
+	}
+	if c.LogFile != "" {
+		plistKey(&b, "StandardOutPath", c.LogFile)
+		plistKey(&b, "StandardErrorPath", c.LogFile)
+	}
+	b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+	// Restart after failures, not after mcphost serve was stopped cleanly
+	b.WriteString("  <key>KeepAlive</key>\n  <dict>\n    <key>SuccessfulExit</key>\n    <false/>\n  </dict>\n")
+	b.WriteString("  <key>ExitTimeOut</key>\n  <integer>60</integer>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func plistKey(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "  <key>%s</key>\n  <string>%s</string>\n", key, html.EscapeString(value))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeFile writes a service definition, readable only by its owner as it may hold
+// secrets in its environment.
+func writeFile(file, content string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
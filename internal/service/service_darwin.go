@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// plistFile returns the path of the launchd property list of c.
+func plistFile(c Config) (string, error) {
+	if c.System {
+		return filepath.Join("/Library/LaunchDaemons", c.Label()+".plist"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", c.Label()+".plist"), nil
+}
+
+// domain returns the launchd domain of c: the system one or the GUI session of the
+// current user.
+func domain(c Config) string {
+	if c.System {
+		return "system"
+	}
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+func launchctl(args ...string) error {
+	out, err := exec.CommandContext(context.Background(), "launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Install writes the launchd property list of c, a daemon started at boot or an
+// agent started at login. It does not start it.
+func Install(c Config) error {
+	file, err := plistFile(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.LogFile), 0o755); err != nil {
+		return err
+	}
+	if err := writeFile(file, LaunchdPlist(c)); err != nil {
+		return err
+	}
+	if c.System {
+		// launchd ignores daemons not owned by root or writable by others
+		return os.Chmod(file, 0o644)
+	}
+	return nil
+}
+
+// Start loads the service into launchd, which starts it.
+func Start(c Config) error {
+	file, err := plistFile(c)
+	if err != nil {
+		return err
+	}
+	return launchctl("bootstrap", domain(c), file)
+}
+
+// Stop unloads the service from launchd, which stops it until it is started again or
+// at the next login or boot.
+func Stop(c Config) error {
+	return launchctl("bootout", domain(c)+"/"+c.Label())
+}
+
+// Uninstall stops the service and removes its property list.
+func Uninstall(c Config) error {
+	file, err := plistFile(c)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", c.Name, err)
+	}
+	// Stopping fails when the service is not loaded, which is fine
+	Stop(c)
+	return os.Remove(file)
+}
+
+// Describe returns where the service is defined and where its output goes.
+func Describe(c Config) string {
+	file, _ := plistFile(c)
+	return fmt.Sprintf("property list %s, logs: %s", file, c.LogFile)
+}
+
+// DefaultLogFile returns the log file of a service without one, in ~/Library/Logs or,
+// for daemons, /Library/Logs.
+func DefaultLogFile(c Config) string {
+	if c.System {
+		return filepath.Join("/Library/Logs", c.Name+".log")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Logs", c.Name+".log")
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// unitFile returns the path of the systemd unit of c.
+func unitFile(c Config) (string, error) {
+	if c.System {
+		return filepath.Join("/etc/systemd/system", c.Name+".service"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user", c.Name+".service"), nil
+}
+
+// systemctl runs systemctl for the user or the system manager.
+func systemctl(c Config, args ...string) error {
+	if !c.System {
+		args = append([]string{"--user"}, args...)
+	}
+	out, err := exec.CommandContext(context.Background(), "systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Install writes the systemd unit of c and enables it, so that it starts at boot, or
+// at login for a user unit. It does not start it.
+func Install(c Config) error {
+	file, err := unitFile(c)
+	if err != nil {
+		return err
+	}
+	if c.LogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(c.LogFile), 0o755); err != nil {
+			return err
+		}
+	}
+	if err := writeFile(file, SystemdUnit(c)); err != nil {
+		return err
+	}
+	if err := systemctl(c, "daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl(c, "enable", c.Name+".service")
+}
+
+// Start starts the installed service.
+func Start(c Config) error {
+	return systemctl(c, "start", c.Name+".service")
+}
+
+// Stop stops the running service.
+func Stop(c Config) error {
+	return systemctl(c, "stop", c.Name+".service")
+}
+
+// Uninstall stops and disables the service and removes its unit.
+func Uninstall(c Config) error {
+	file, err := unitFile(c)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", c.Name, err)
+	}
+	// Stopping fails when the service is not running, which is fine
+	systemctl(c, "stop", c.Name+".service")
+	if err := systemctl(c, "disable", c.Name+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+	return systemctl(c, "daemon-reload")
+}
+
+// Describe returns where the service is defined and where its output goes.
+func Describe(c Config) string {
+	file, _ := unitFile(c)
+	logs := c.LogFile
+	if logs == "" {
+		logs = "journalctl --user -u " + c.Name
+		if c.System {
+			logs = "journalctl -u " + c.Name
+		}
+	}
+	return fmt.Sprintf("unit %s, logs: %s", file, logs)
+}
+
+// DefaultLogFile returns the log file of a service without one; systemd services
+// log to the journal.
+func DefaultLogFile(c Config) string {
+	return ""
+}
@@ -0,0 +1,33 @@
+//go:build !linux && !darwin && !windows
+
+package service
+
+// Install is not supported on this system.
+func Install(c Config) error {
+	return ErrUnsupported
+}
+
+// Start is not supported on this system.
+func Start(c Config) error {
+	return ErrUnsupported
+}
+
+// Stop is not supported on this system.
+func Stop(c Config) error {
+	return ErrUnsupported
+}
+
+// Uninstall is not supported on this system.
+func Uninstall(c Config) error {
+	return ErrUnsupported
+}
+
+// Describe returns an empty description on this system.
+func Describe(c Config) string {
+	return ""
+}
+
+// DefaultLogFile returns no log file on this system.
+func DefaultLogFile(c Config) string {
+	return ""
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = Config{
+	Name:        "mcphost",
+	Description: "MCP servers of /etc/mcp host/mcphost.yaml",
+	Executable:  "/usr/local/bin/mcphost",
+	Args:        []string{"serve", "-config", "/etc/mcp host/mcphost.yaml"},
+	WorkingDir:  "/etc/mcp host",
+	Env:         map[string]string{"PATH": "/usr/bin:/bin", "API_KEY": `s3cr%t$"&<`},
+}
+
+// Test rendering systemd units
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnit(testConfig)
+	assert.Contains(t, unit, "Description=MCP servers of /etc/mcp host/mcphost.yaml\n")
+	assert.Contains(t, unit, "ExecStart=/usr/local/bin/mcphost serve -config \"/etc/mcp host/mcphost.yaml\"\n")
+	assert.Contains(t, unit, "WorkingDirectory=\"/etc/mcp host\"\n")
+	assert.Contains(t, unit, "Environment=\"API_KEY=s3cr%%t$$\\\"&<\"\nEnvironment=PATH=/usr/bin:/bin\n", "Variables are sorted, quoted and escaped")
+	assert.Contains(t, unit, "WantedBy=default.target\n")
+	assert.NotContains(t, unit, "StandardOutput", "Output goes to the journal")
+
+	c := testConfig
+	c.System = true
+	c.LogFile = "/var/log/mcphost.log"
+	unit = SystemdUnit(c)
+	assert.Contains(t, unit, "StandardOutput=append:/var/log/mcphost.log\nStandardError=append:/var/log/mcphost.log\n")
+	assert.Contains(t, unit, "WantedBy=multi-user.target\n")
+}
+
+// Test rendering launchd property lists
+func TestLaunchdPlist(t *testing.T) {
+	c := testConfig
+	c.LogFile = "/Users/me/Library/Logs/mcphost.log"
+	plist := LaunchdPlist(c)
+	assert.Contains(t, plist, "<key>Label</key>\n  <string>com.github.mark3labs.mcphost</string>\n")
+	assert.Contains(t, plist, "    <string>/usr/local/bin/mcphost</string>\n    <string>serve</string>\n    <string>-config</string>\n    <string>/etc/mcp host/mcphost.yaml</string>\n")
+	assert.Contains(t, plist, "<key>WorkingDirectory</key>\n  <string>/etc/mcp host</string>\n")
+	assert.Contains(t, plist, "    <key>API_KEY</key>\n    <string>s3cr%t$&#34;&amp;&lt;</string>\n", "Values are escaped")
+	assert.Contains(t, plist, "<key>StandardErrorPath</key>\n  <string>/Users/me/Library/Logs/mcphost.log</string>\n")
+
+	c.Name = "org.example.tools"
+	assert.Equal(t, "org.example.tools", c.Label())
+}
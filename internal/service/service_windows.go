@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Install creates the Windows service of c, started automatically at boot and
+// restarted when it fails. It does not start it. Windows services have no working
+// directory or output of their own, so mcphost serve is passed -dir and -log-file.
+func Install(c Config) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager, run as administrator: %w", err)
+	}
+	defer m.Disconnect()
+
+	args := c.Args
+	if c.WorkingDir != "" {
+		args = append(args, "-dir", c.WorkingDir)
+	}
+	if c.LogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(c.LogFile), 0o755); err != nil {
+			return err
+		}
+		args = append(args, "-log-file", c.LogFile)
+	}
+	s, err := m.CreateService(c.Name, c.Executable, mgr.Config{
+		DisplayName: c.Name,
+		Description: c.Description,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create service %s: %w", c.Name, err)
+	}
+	defer s.Close()
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return err
+	}
+	if len(c.Env) == 0 {
+		return nil
+	}
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+c.Name, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to set the environment of service %s: %w", c.Name, err)
+	}
+	defer key.Close()
+	env := make([]string, 0, len(c.Env))
+	for _, name := range sortedKeys(c.Env) {
+		env = append(env, name+"="+c.Env[name])
+	}
+	return key.SetStringsValue("Environment", env)
+}
+
+// openService opens the installed service of c.
+func openService(c Config) (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the service manager, run as administrator: %w", err)
+	}
+	s, err := m.OpenService(c.Name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("service %s is not installed: %w", c.Name, err)
+	}
+	return m, s, nil
+}
+
+// Start starts the installed service.
+func Start(c Config) error {
+	m, s, err := openService(c)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	return s.Start()
+}
+
+// Stop stops the running service.
+func Stop(c Config) error {
+	m, s, err := openService(c)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	_, err = s.Control(svc.Stop)
+	return err
+}
+
+// Uninstall stops the service and deletes it.
+func Uninstall(c Config) error {
+	m, s, err := openService(c)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	// Stopping fails when the service is not running, which is fine
+	s.Control(svc.Stop)
+	return s.Delete()
+}
+
+// Describe returns where the output of the service goes.
+func Describe(c Config) string {
+	return "logs: " + c.LogFile
+}
+
+// DefaultLogFile returns the log file of a service without one, under %ProgramData%.
+func DefaultLogFile(c Config) string {
+	dir := os.Getenv("ProgramData")
+	if dir == "" {
+		dir = `C:\ProgramData`
+	}
+	return filepath.Join(dir, "mcphost", c.Name+".log")
+}
+
+// Run runs fn, reporting to the service manager when the process is a Windows
+// service: ctx is canceled when the service is asked to stop.
+func Run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	h := &handler{fn: fn, ctx: ctx, cancel: cancel}
+	if err := svc.Run(name, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// handler runs fn as the Windows service.
+type handler struct {
+	fn     func(ctx context.Context) error
+	ctx    context.Context
+	cancel context.CancelFunc
+	err    error
+}
+
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	done := make(chan error, 1)
+	go func() { done <- h.fn(h.ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case h.err = <-done:
+			if h.err != nil && !errors.Is(h.err, context.Canceled) {
+				// A service specific exit code makes the manager apply its recovery actions
+				return true, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				h.cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package session keeps state of a server per client session, such as the cookies of
+// fetched sites, so that clients of a server served over the network do not see each
+// other's state. The state of a session is dropped after it was idle for a while, or
+// when the client calls the resetSession tool.
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResetToolName is the name of the tool dropping the state of the calling session.
+const ResetToolName = "resetSession"
+
+const (
+	// DefaultIdleTimeout is how long the state of an idle session is kept by default.
+	DefaultIdleTimeout = 30 * time.Minute
+	// sweepInterval is how often the state of idle sessions is looked for.
+	sweepInterval = time.Minute
+)
+
+// ID returns the ID of the client session of a call: the MCP session over SSE, a
+// fixed ID over stdio, where there is one client, or "" outside of a session.
+func ID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		return s.SessionID()
+	}
+	return ""
+}
+
+// state is the state of one session.
+type state struct {
+	values   map[string]interface{}
+	lastUsed time.Time
+}
+
+// Store keeps the state of the sessions of a server by key. Values implementing
+// io.Closer are closed when their session is dropped.
+type Store struct {
+	srv *server.MCPServer
+	now func() time.Time
+
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	sessions    map[string]*state
+	lastSweep   time.Time
+}
+
+var (
+	mu     sync.Mutex
+	stores = make(map[*server.MCPServer]*Store)
+)
+
+// Scoped returns the session store of s, creating it on first use. Servers keeping
+// state per session call it when they are created, so that the resetSession tool and
+// the idle timeout flag apply to them.
+func Scoped(s *server.MCPServer) *Store {
+	mu.Lock()
+	defer mu.Unlock()
+	st, ok := stores[s]
+	if !ok {
+		st = &Store{srv: s, now: time.Now, idleTimeout: DefaultIdleTimeout, sessions: make(map[string]*state)}
+		stores[s] = st
+	}
+	return st
+}
+
+// Lookup returns the session store of s, if it keeps state per session.
+func Lookup(s *server.MCPServer) (*Store, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	st, ok := stores[s]
+	return st, ok
+}
+
+// SetIdleTimeout sets how long the state of a session is kept after its last use.
+func (st *Store) SetIdleTimeout(d time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.idleTimeout = d
+}
+
+// Value returns the value stored under key for the session of ctx, storing the value
+// create returns if there is none.
+func Value[T any](ctx context.Context, st *Store, key string, create func() T) T {
+	st.mu.Lock()
+	now := st.now()
+	expired := st.sweep(now)
+	id := ID(ctx)
+	s, ok := st.sessions[id]
+	if !ok {
+		s = &state{values: make(map[string]interface{})}
+		st.sessions[id] = s
+	}
+	s.lastUsed = now
+	value, ok := s.values[key].(T)
+	if !ok {
+		value = create()
+		s.values[key] = value
+	}
+	st.mu.Unlock()
+
+	closeAll(expired)
+	return value
+}
+
+// sweep drops the sessions idle for longer than the idle timeout and returns them;
+// st.mu must be held.
+func (st *Store) sweep(now time.Time) []*state {
+	if now.Sub(st.lastSweep) < sweepInterval {
+		return nil
+	}
+	st.lastSweep = now
+	var expired []*state
+	for id, s := range st.sessions {
+		if now.Sub(s.lastUsed) > st.idleTimeout {
+			expired = append(expired, s)
+			delete(st.sessions, id)
+		}
+	}
+	return expired
+}
+
+// Reset drops the state of the session of ctx and reports whether it had any.
+func (st *Store) Reset(ctx context.Context) bool {
+	st.mu.Lock()
+	id := ID(ctx)
+	s, ok := st.sessions[id]
+	delete(st.sessions, id)
+	st.mu.Unlock()
+
+	if ok {
+		closeAll([]*state{s})
+	}
+	return ok
+}
+
+// Len returns the number of sessions with state.
+func (st *Store) Len() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return len(st.sessions)
+}
+
+// Close drops the state of all sessions and forgets the store of its server.
+func (st *Store) Close() error {
+	mu.Lock()
+	delete(stores, st.srv)
+	mu.Unlock()
+
+	st.mu.Lock()
+	var all []*state
+	for _, s := range st.sessions {
+		all = append(all, s)
+	}
+	st.sessions = make(map[string]*state)
+	st.mu.Unlock()
+
+	closeAll(all)
+	return nil
+}
+
+// closeAll closes the values of sessions that are io.Closers.
+func closeAll(sessions []*state) {
+	for _, s := range sessions {
+		for key, value := range s.values {
+			if c, ok := value.(io.Closer); ok {
+				if err := c.Close(); err != nil {
+					log.Printf("Warning: Failed to close session state %s: %v", key, err)
+				}
+			}
+		}
+	}
+}
+
+// ResetTool returns the definition of the resetSession tool.
+func ResetTool() mcp.Tool {
+	return mcp.NewTool(ResetToolName,
+		mcp.WithDescription("Forgets the state this server keeps for the calling client session, such as cookies, as if the client had just connected"),
+	)
+}
+
+// ResetHandler returns the handler of the resetSession tool.
+func (st *Store) ResetHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !st.Reset(ctx) {
+			return mcp.NewToolResultText("The session had no state to reset"), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Reset the state of session %q", ID(ctx))), nil
+	}
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+type client struct{ id string }
+
+func (c *client) SessionID() string                                   { return c.id }
+func (c *client) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (c *client) Initialize()                                         {}
+func (c *client) Initialized() bool                                   { return true }
+
+// closer counts how often it is closed.
+type closer struct{ closed int }
+
+func (c *closer) Close() error {
+	c.closed++
+	return nil
+}
+
+// Test that sessions get their own values
+func TestValue(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	st := Scoped(s)
+	defer st.Close()
+	assert.Same(t, st, Scoped(s), "A server has one store")
+	found, ok := Lookup(s)
+	assert.True(t, ok)
+	assert.Same(t, st, found)
+
+	alice := s.WithContext(context.Background(), &client{id: "alice"})
+	bob := s.WithContext(context.Background(), &client{id: "bob"})
+	created := 0
+	counter := func() *int {
+		created++
+		return new(int)
+	}
+	*Value(alice, st, "count", counter) += 2
+	*Value(bob, st, "count", counter)++
+	assert.Equal(t, 2, *Value(alice, st, "count", counter))
+	assert.Equal(t, 1, *Value(bob, st, "count", counter))
+	assert.Equal(t, 2, created)
+	assert.Equal(t, 2, st.Len())
+
+	// Outside of a session, calls share the empty ID
+	assert.Equal(t, "", ID(context.Background()))
+	assert.Equal(t, "alice", ID(alice))
+}
+
+// Test that idle sessions are dropped and their values closed
+func TestIdleTimeout(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	st := Scoped(s)
+	defer st.Close()
+	now := time.Now()
+	st.now = func() time.Time { return now }
+	st.SetIdleTimeout(10 * time.Minute)
+
+	alice := s.WithContext(context.Background(), &client{id: "alice"})
+	bob := s.WithContext(context.Background(), &client{id: "bob"})
+	aliceState := Value(alice, st, "state", func() *closer { return &closer{} })
+	now = now.Add(6 * time.Minute)
+	Value(bob, st, "state", func() *closer { return &closer{} })
+	now = now.Add(6 * time.Minute)
+	Value(bob, st, "state", func() *closer { return &closer{} })
+
+	assert.Equal(t, 1, aliceState.closed, "Alice was idle for 12 minutes")
+	assert.Equal(t, 1, st.Len())
+	assert.NotSame(t, aliceState, Value(alice, st, "state", func() *closer { return &closer{} }), "Alice starts over")
+}
+
+// Test that resetSession drops the state of the caller only
+func TestReset(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	st := Scoped(s)
+	alice := s.WithContext(context.Background(), &client{id: "alice"})
+	bob := s.WithContext(context.Background(), &client{id: "bob"})
+	aliceState := Value(alice, st, "state", func() *closer { return &closer{} })
+	bobState := Value(bob, st, "state", func() *closer { return &closer{} })
+
+	result, err := st.ResetHandler()(alice, mcptest.NewCallToolRequest(ResetToolName, nil))
+	require.NoError(t, err)
+	assert.Equal(t, `Reset the state of session "alice"`, mcptest.ResultText(result))
+	assert.Equal(t, 1, aliceState.closed)
+	assert.Equal(t, 0, bobState.closed)
+
+	result, err = st.ResetHandler()(alice, mcptest.NewCallToolRequest(ResetToolName, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "The session had no state to reset", mcptest.ResultText(result))
+
+	// Closing the store drops every session and forgets it
+	require.NoError(t, st.Close())
+	assert.Equal(t, 1, bobState.closed)
+	_, ok := Lookup(s)
+	assert.False(t, ok)
+}
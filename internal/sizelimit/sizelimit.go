@@ -0,0 +1,191 @@
+// Package sizelimit bounds the size of the arguments and results of tool calls, so
+// that every server refuses oversized arguments and truncates oversized results the
+// same way, optionally keeping the full results as resources.
+package sizelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/i18n"
+)
+
+// Limits are the size limits of tool calls, in bytes; 0 means no limit.
+type Limits struct {
+	// Args bounds the arguments of a call, encoded as JSON.
+	Args int
+	// Result bounds the text of a result.
+	Result int
+}
+
+// Truncation describes a truncated result. It is set as truncated in the _meta of
+// the result.
+type Truncation struct {
+	// OriginalBytes and ReturnedBytes are the sizes of the text of the result.
+	OriginalBytes int `json:"originalBytes"`
+	ReturnedBytes int `json:"returnedBytes"`
+	// Resource is the URI of the full result, when results are kept.
+	Resource string `json:"resource,omitempty"`
+}
+
+// Middleware returns tool middleware refusing calls whose arguments exceed the limit
+// with an error result, and truncating the text of results over the limit. A notice
+// saying how much was left out is added to truncated results, and they have
+// truncated in their _meta. If spill is not nil, the full text of truncated results
+// is kept in it.
+func Middleware(limits Limits, spill *Spill) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if limits.Args > 0 {
+				data, err := json.Marshal(req.Params.Arguments)
+				if err == nil && len(data) > limits.Args {
+					return mcp.NewToolResultError(i18n.Sprintf(i18n.FromContext(ctx), "The arguments of %s are %d bytes, over the limit of %d bytes", req.Params.Name, len(data), limits.Args)), nil
+				}
+			}
+			result, err := next(ctx, req)
+			if err != nil || result == nil || limits.Result <= 0 {
+				return result, err
+			}
+			return truncate(result, limits.Result, spill), nil
+		}
+	}
+}
+
+// truncate cuts the text contents of result so that they fit in limit bytes.
+func truncate(result *mcp.CallToolResult, limit int, spill *Spill) *mcp.CallToolResult {
+	size := 0
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := textOf(content); ok {
+			size += len(text)
+			texts = append(texts, text)
+		}
+	}
+	if size <= limit {
+		return result
+	}
+
+	t := Truncation{OriginalBytes: size}
+	if spill != nil {
+		t.Resource = spill.Add(strings.Join(texts, "\n"))
+	}
+	truncated := &mcp.CallToolResult{Result: result.Result, IsError: result.IsError}
+	left := limit
+	for _, content := range result.Content {
+		text, ok := textOf(content)
+		if !ok {
+			truncated.Content = append(truncated.Content, content)
+			continue
+		}
+		if len(text) > left {
+			text = cut(text, left)
+		}
+		left -= len(text)
+		t.ReturnedBytes += len(text)
+		if text != "" {
+			truncated.Content = append(truncated.Content, mcp.NewTextContent(text))
+		}
+	}
+
+	notice := fmt.Sprintf("[Result truncated: showing %d of %d bytes]", t.ReturnedBytes, t.OriginalBytes)
+	if t.Resource != "" {
+		notice = fmt.Sprintf("[Result truncated: showing %d of %d bytes; the full result is at %s]", t.ReturnedBytes, t.OriginalBytes, t.Resource)
+	}
+	truncated.Content = append(truncated.Content, mcp.NewTextContent(notice))
+	meta := make(map[string]interface{}, len(result.Meta)+1)
+	for k, v := range result.Meta {
+		meta[k] = v
+	}
+	meta["truncated"] = t
+	truncated.Meta = meta
+	return truncated
+}
+
+// textOf returns the text of a text content.
+func textOf(content mcp.Content) (string, bool) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, true
+	case *mcp.TextContent:
+		return c.Text, true
+	}
+	return "", false
+}
+
+// cut shortens s to at most n bytes, keeping it valid UTF-8.
+func cut(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Spill keeps the full text of the last truncated results as resources of a server,
+// at e.g. fetch://truncated/3.
+type Spill struct {
+	base string
+	size int
+
+	mu      sync.Mutex
+	nextID  int
+	entries []spilled // oldest first
+}
+
+type spilled struct {
+	id   int
+	text string
+}
+
+// NewSpill adds the resources of the last size truncated results to s, under
+// name://truncated.
+func NewSpill(s *server.MCPServer, name string, size int) *Spill {
+	sp := &Spill{base: name + "://truncated", size: size, nextID: 1}
+	s.AddResourceTemplate(mcp.NewResourceTemplate(sp.base+"/{id}", "Truncated results by ID",
+		mcp.WithTemplateDescription(fmt.Sprintf("Full text of one of the last %d tool results that were truncated, at the URI given in their truncation notice", size)),
+		mcp.WithTemplateMIMEType("text/plain"),
+	), sp.read)
+	return sp
+}
+
+// Add keeps the full text of a result and returns its URI.
+func (sp *Spill) Add(text string) string {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	id := sp.nextID
+	sp.nextID++
+	sp.entries = append(sp.entries, spilled{id: id, text: text})
+	if len(sp.entries) > sp.size {
+		sp.entries = append([]spilled(nil), sp.entries[len(sp.entries)-sp.size:]...)
+	}
+	return fmt.Sprintf("%s/%d", sp.base, id)
+}
+
+func (sp *Spill) read(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(req.Params.URI, sp.base+"/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid truncated result %s", req.Params.URI)
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for _, e := range sp.entries {
+		if e.id == id {
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      req.Params.URI,
+				MIMEType: "text/plain",
+				Text:     e.text,
+			}}, nil
+		}
+	}
+	return nil, fmt.Errorf("no truncated result %s; only the last %d are kept", req.Params.URI, sp.size)
+}
@@ -0,0 +1,71 @@
+package sizelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test that oversized arguments are refused and oversized results truncated
+func TestMiddleware(t *testing.T) {
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, _ := req.Params.Arguments["text"].(string)
+		result := mcp.NewToolResultText(text)
+		result.Content = append(result.Content, mcp.NewImageContent("aW1hZ2U=", "image/png"), mcp.NewTextContent("tail"))
+		result.Meta = map[string]interface{}{"page": 1}
+		return result, nil
+	}
+	call := Middleware(Limits{Args: 40, Result: 10}, nil)(handler)
+	ctx := context.Background()
+
+	result, err := call(ctx, mcptest.NewCallToolRequest("echo", map[string]interface{}{"text": strings.Repeat("x", 40)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "The arguments of echo are 51 bytes, over the limit of 40 bytes", mcptest.ResultText(result))
+
+	result, err = call(ctx, mcptest.NewCallToolRequest("echo", map[string]interface{}{"text": "short"}))
+	require.NoError(t, err)
+	assert.Len(t, result.Content, 3, "Results within the limit are unchanged")
+	assert.NotContains(t, result.Meta, "truncated")
+
+	// Truncation keeps UTF-8 valid, and other contents
+	result, err = call(ctx, mcptest.NewCallToolRequest("echo", map[string]interface{}{"text": "héllo wörld"}))
+	require.NoError(t, err)
+	require.Len(t, result.Content, 3)
+	assert.Equal(t, "héllo wö", result.Content[0].(mcp.TextContent).Text)
+	assert.IsType(t, mcp.ImageContent{}, result.Content[1])
+	assert.Equal(t, "[Result truncated: showing 10 of 17 bytes]", result.Content[2].(mcp.TextContent).Text)
+	assert.Equal(t, Truncation{OriginalBytes: 17, ReturnedBytes: 10}, result.Meta["truncated"])
+	assert.Equal(t, 1, result.Meta["page"])
+}
+
+// Test that the full text of truncated results can be read as resources
+func TestSpill(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	spill := NewSpill(s, "test", 2)
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(req.Params.Arguments["text"].(string)), nil
+	}
+	call := Middleware(Limits{Result: 4}, spill)(handler)
+	ctx := context.Background()
+
+	for _, text := range []string{"first result", "second result", "third result"} {
+		result, err := call(ctx, mcptest.NewCallToolRequest("echo", map[string]interface{}{"text": text}))
+		require.NoError(t, err)
+		notice := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+		assert.Contains(t, notice, "the full result is at test://truncated/")
+	}
+
+	contents, err := mcptest.ReadResource(ctx, s, "test://truncated/3")
+	require.NoError(t, err)
+	assert.Equal(t, "third result", mcptest.ResourceText(contents))
+	_, err = mcptest.ReadResource(ctx, s, "test://truncated/1")
+	assert.ErrorContains(t, err, "only the last 2 are kept")
+}
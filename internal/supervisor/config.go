@@ -0,0 +1,182 @@
+package supervisor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/transport"
+	"gopkg.in/yaml.v3"
+)
+
+// Restart policies of a server.
+const (
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+	RestartNever     = "never"
+)
+
+// Default restart backoff, doubled after each crash up to the maximum.
+const (
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// Config declares the servers run by mcphost serve. It is read from YAML or JSON.
+type Config struct {
+	// Status is the listen address of the HTTP status endpoint, e.g. 127.0.0.1:8090.
+	// The endpoint is disabled when empty.
+	Status  string                  `yaml:"status"`
+	Backoff Backoff                 `yaml:"backoff"`
+	Servers map[string]ServerConfig `yaml:"servers"`
+
+	// MCPServers and the mcpServers block of MCPServersFile declare external servers
+	// in the Claude Desktop format. They are added to Servers.
+	MCPServers     map[string]mcpconfig.Server `yaml:"mcpServers"`
+	MCPServersFile string                      `yaml:"mcpServersFile"`
+}
+
+// Backoff bounds the delay before a crashed server is restarted.
+type Backoff struct {
+	Initial time.Duration `yaml:"initial"`
+	Max     time.Duration `yaml:"max"`
+}
+
+// ServerConfig declares one supervised server: either a bundled server, named by
+// Server or else by its key in Config.Servers, or an external Command.
+type ServerConfig struct {
+	Server  string `yaml:"server"`
+	Command string `yaml:"command"`
+	// Listen is the address a bundled server serves SSE on. Bundled servers started
+	// over stdio could not be reached by any client, so it is required for them.
+	Listen string `yaml:"listen"`
+	// Transport is the transport a bundled server serves on Listen, sse by default or
+	// websocket, which browsers can connect to.
+	Transport string            `yaml:"transport"`
+	Args      []string          `yaml:"args"`
+	Env       map[string]string `yaml:"env"`
+	// Dir is the working directory of the server, that of mcphost serve if empty. A
+	// leading ~ is the home directory.
+	Dir      string `yaml:"dir"`
+	Restart  string `yaml:"restart"`
+	Disabled bool   `yaml:"disabled"`
+}
+
+// LoadConfig reads and validates a config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig decodes a YAML or JSON config, fills in defaults and validates it.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if cfg.Backoff.Initial == 0 {
+		cfg.Backoff.Initial = defaultInitialBackoff
+	}
+	if cfg.Backoff.Max == 0 {
+		cfg.Backoff.Max = max(defaultMaxBackoff, cfg.Backoff.Initial)
+	}
+	if cfg.Backoff.Initial < 0 || cfg.Backoff.Max < cfg.Backoff.Initial {
+		return nil, fmt.Errorf("backoff must be positive with max at least initial")
+	}
+
+	external, err := mcpconfig.Merge(cfg.MCPServers, cfg.MCPServersFile)
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range external {
+		if _, ok := cfg.Servers[name]; ok {
+			return nil, fmt.Errorf("server %s is declared in both servers and mcpServers", name)
+		}
+		if cfg.Servers == nil {
+			cfg.Servers = make(map[string]ServerConfig)
+		}
+		cfg.Servers[name] = ServerConfig{Command: s.Command, Args: s.Args, Env: s.Env}
+	}
+
+	enabled := 0
+	listeners := make(map[string]string)
+	// Servers are checked in name order so errors about two of them are stable
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sc := cfg.Servers[name]
+		if sc.Command != "" && sc.Server != "" {
+			return nil, fmt.Errorf("server %s: set either server or command, not both", name)
+		}
+		if sc.Command != "" && (sc.Listen != "" || sc.Transport != "") {
+			return nil, fmt.Errorf("server %s: listen and transport apply only to bundled servers", name)
+		}
+		if sc.Command == "" {
+			if sc.Server == "" {
+				sc.Server = name
+			}
+			if _, ok := servers.Lookup(sc.Server); !ok {
+				return nil, fmt.Errorf("server %s: unknown bundled server %q", name, sc.Server)
+			}
+			if sc.Listen == "" {
+				return nil, fmt.Errorf("server %s: listen is required; bundled servers are served over SSE or WebSocket", name)
+			}
+			switch sc.Transport {
+			case "":
+				sc.Transport = transport.SSE
+			case transport.SSE, transport.WebSocket:
+			default:
+				return nil, fmt.Errorf("server %s: invalid transport %q; use sse or websocket", name, sc.Transport)
+			}
+			if other, ok := listeners[sc.Listen]; ok && !sc.Disabled {
+				return nil, fmt.Errorf("servers %s and %s both listen on %s", other, name, sc.Listen)
+			}
+			if !sc.Disabled {
+				listeners[sc.Listen] = name
+			}
+			for _, arg := range sc.Args {
+				if flag := strings.TrimLeft(strings.SplitN(arg, "=", 2)[0], "-"); flag == "transport" || flag == "listen" {
+					return nil, fmt.Errorf("server %s: set the address and transport with listen and transport, not the -%s argument", name, flag)
+				}
+			}
+		}
+		if sc.Dir, err = mcpconfig.ExpandHome(sc.Dir); err != nil {
+			return nil, fmt.Errorf("server %s: %w", name, err)
+		}
+		switch sc.Restart {
+		case "":
+			sc.Restart = RestartOnFailure
+		case RestartOnFailure, RestartAlways, RestartNever:
+		default:
+			return nil, fmt.Errorf("server %s: invalid restart policy %q; use on-failure, always or never", name, sc.Restart)
+		}
+		if !sc.Disabled {
+			enabled++
+		}
+		cfg.Servers[name] = sc
+	}
+	if enabled == 0 {
+		return nil, fmt.Errorf("no servers configured")
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,106 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/health"
+)
+
+// probeTimeout bounds asking a bundled server whether it is ready.
+const probeTimeout = 5 * time.Second
+
+// tlsFlags are the server flags that make a bundled server serve HTTPS, whose
+// readiness endpoint is not probed.
+var tlsFlags = []string{"-tls-cert", "--tls-cert", "-acme-domains", "--acme-domains"}
+
+// Ready reports whether every enabled server is running. Bundled servers must also
+// pass the readiness checks served on their listen address, e.g. that the API they
+// wrap is reachable.
+func (s *Supervisor) Ready(ctx context.Context) health.Report {
+	statuses := s.Status()
+	results := make([]health.CheckResult, len(statuses))
+	var wg sync.WaitGroup
+	for i, st := range statuses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = health.CheckResult{Status: health.StatusOK, Time: time.Now()}
+			if err := s.serverReady(ctx, st); err != nil {
+				results[i].Status, results[i].Error = health.StatusFail, err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := health.Report{Status: health.StatusOK, Checks: make(map[string]health.CheckResult, len(statuses))}
+	for i, st := range statuses {
+		report.Checks[st.Name] = results[i]
+		if results[i].Status != health.StatusOK {
+			report.Status = health.StatusFail
+		}
+	}
+	return report
+}
+
+func (s *Supervisor) serverReady(ctx context.Context, st Status) error {
+	if st.State != StateRunning {
+		return fmt.Errorf("server is %s", st.State)
+	}
+	sc := s.cfg.Servers[st.Name]
+	if sc.Command != "" || slices.ContainsFunc(sc.Args, isTLSFlag) {
+		return nil
+	}
+	return probe(ctx, st.Listen)
+}
+
+func isTLSFlag(arg string) bool {
+	name, _, _ := strings.Cut(arg, "=")
+	return slices.Contains(tlsFlags, name)
+}
+
+// probe asks the bundled server listening on listen whether it is ready.
+func probe(ctx context.Context, listen string) error {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+net.JoinHostPort(host, port)+health.ReadinessPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("readiness probe failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var report health.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return fmt.Errorf("readiness probe answered %s", resp.Status)
+	}
+	if report.OK() {
+		return nil
+	}
+	var failed []string
+	for name, check := range report.Checks {
+		if check.Status != health.StatusOK {
+			failed = append(failed, name+": "+check.Error)
+		}
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("not ready: %s", strings.Join(failed, "; "))
+}
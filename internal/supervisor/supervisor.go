@@ -0,0 +1,232 @@
+// Package supervisor runs a set of MCP servers as child processes and restarts them with
+// backoff when they crash.
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/secretref"
+)
+
+// stableAfter is how long a server must run before its restart backoff is reset.
+const stableAfter = time.Minute
+
+// stopTimeout is how long a server may take to exit after its stdin is closed. It
+// leaves bundled servers time to drain their tool calls, 30 seconds by default.
+const stopTimeout = 40 * time.Second
+
+// State is the lifecycle state of a supervised server.
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateBackoff  State = "backoff"
+	StateStopped  State = "stopped"
+	StateFailed   State = "failed"
+)
+
+// Status describes a supervised server.
+type Status struct {
+	Name     string    `json:"name"`
+	State    State     `json:"state"`
+	Listen   string    `json:"listen,omitempty"`
+	PID      int       `json:"pid,omitempty"`
+	Restarts int       `json:"restarts"`
+	LastExit string    `json:"lastExit,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// Supervisor runs the servers of a Config.
+type Supervisor struct {
+	cfg        *Config
+	executable string
+	// Stderr receives the standard error of the servers, os.Stderr by default.
+	Stderr io.Writer
+
+	mu     sync.Mutex
+	status map[string]*Status
+}
+
+// New creates a Supervisor. Bundled servers are started as "executable run <server>"
+// serving SSE or WebSocket on their listen address.
+func New(cfg *Config, executable string) *Supervisor {
+	s := &Supervisor{
+		cfg:        cfg,
+		executable: executable,
+		Stderr:     os.Stderr,
+		status:     make(map[string]*Status),
+	}
+	for name, sc := range cfg.Servers {
+		if !sc.Disabled {
+			s.status[name] = &Status{Name: name, State: StateStarting, Listen: sc.Listen, Since: time.Now()}
+		}
+	}
+	return s
+}
+
+// Run starts the enabled servers and supervises them until ctx is cancelled or every
+// server has stopped for good.
+func (s *Supervisor) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for name, sc := range s.cfg.Servers {
+		if sc.Disabled {
+			log.Info("Server disabled", "name", name)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.supervise(ctx, name, sc)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Status returns the status of the servers, sorted by name.
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Status, 0, len(s.status))
+	for _, st := range s.status {
+		list = append(list, *st)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// ServeHTTP reports the status of the servers as JSON, and serves the liveness and
+// readiness endpoints.
+func (s *Supervisor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case health.LivenessPath:
+		health.WriteReport(w, health.Report{Status: health.StatusOK})
+		return
+	case health.ReadinessPath:
+		health.WriteReport(w, s.Ready(r.Context()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.Status()); err != nil {
+		log.Error("Failed to write status", "error", err)
+	}
+}
+
+func (s *Supervisor) update(name string, fn func(st *Status)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.status[name]
+	prev := st.State
+	fn(st)
+	if st.State != prev {
+		st.Since = time.Now()
+	}
+}
+
+// supervise runs one server, restarting it according to its policy.
+func (s *Supervisor) supervise(ctx context.Context, name string, sc ServerConfig) {
+	backoff := s.cfg.Backoff.Initial
+	for {
+		s.update(name, func(st *Status) { st.State, st.PID = StateStarting, 0 })
+		started := time.Now()
+		err := s.runOnce(ctx, name, sc)
+		if ctx.Err() != nil {
+			s.update(name, func(st *Status) { st.State, st.PID = StateStopped, 0 })
+			log.Info("Server stopped", "name", name)
+			return
+		}
+
+		exit := "exited cleanly"
+		if err != nil {
+			exit = err.Error()
+		}
+		if sc.Restart == RestartNever || (sc.Restart == RestartOnFailure && err == nil) {
+			state := StateStopped
+			if err != nil {
+				state = StateFailed
+			}
+			s.update(name, func(st *Status) { st.State, st.PID, st.LastExit = state, 0, exit })
+			log.Warn("Server exited, not restarting", "name", name, "exit", exit, "restart", sc.Restart)
+			return
+		}
+
+		if time.Since(started) >= stableAfter {
+			backoff = s.cfg.Backoff.Initial
+		}
+		s.update(name, func(st *Status) {
+			st.State, st.PID, st.LastExit = StateBackoff, 0, exit
+			st.Restarts++
+		})
+		log.Warn("Server exited, restarting", "name", name, "exit", exit, "backoff", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			s.update(name, func(st *Status) { st.State = StateStopped })
+			log.Info("Server stopped", "name", name)
+			return
+		}
+		backoff = min(backoff*2, s.cfg.Backoff.Max)
+	}
+}
+
+// command returns the command line that starts the server.
+func (s *Supervisor) command(sc ServerConfig) (string, []string) {
+	if sc.Command != "" {
+		return sc.Command, sc.Args
+	}
+	return s.executable, append([]string{"run", sc.Server, "-transport", sc.Transport, "-listen", sc.Listen}, sc.Args...)
+}
+
+// runOnce starts the server and waits for it to exit. Its stdin is held open so that
+// stdio servers keep running, and closed to stop it when ctx is cancelled. Bundled
+// servers, which serve SSE or WebSocket, are sent SIGTERM as well.
+func (s *Supervisor) runOnce(ctx context.Context, name string, sc ServerConfig) error {
+	bundled := sc.Command == ""
+	command, args := s.command(sc)
+
+	env, err := secretref.Environ(sc.Env)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Dir = sc.Dir
+	cmd.Stderr = s.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Cancel = func() error {
+		err := stdin.Close()
+		if bundled {
+			// Where signals are unsupported, WaitDelay kills the server instead
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+		return err
+	}
+	cmd.WaitDelay = stopTimeout
+
+	if err := cmd.Start(); err != nil {
+		log.Error("Failed to start server", "name", name, "error", err)
+		return err
+	}
+	s.update(name, func(st *Status) { st.State, st.PID = StateRunning, cmd.Process.Pid })
+	log.Info("Server started", "name", name, "pid", cmd.Process.Pid)
+	return cmd.Wait()
+}
@@ -0,0 +1,283 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/health"
+)
+
+func TestParseConfig(t *testing.T) {
+	yamlConfig := `
+status: 127.0.0.1:8090
+backoff:
+  initial: 500ms
+servers:
+  fetch:
+    listen: 127.0.0.1:8081
+    args: ["-timeout", "10"]
+  clock:
+    server: time
+    listen: 127.0.0.1:8082
+    transport: websocket
+    restart: never
+  custom:
+    command: my-server
+    dir: ~/servers/custom
+    env:
+      API_KEY: secret
+`
+	cfg, err := ParseConfig([]byte(yamlConfig))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8090", cfg.Status)
+	assert.Equal(t, 500*time.Millisecond, cfg.Backoff.Initial)
+	assert.Equal(t, defaultMaxBackoff, cfg.Backoff.Max)
+	assert.Equal(t, "fetch", cfg.Servers["fetch"].Server)
+	assert.Equal(t, "127.0.0.1:8081", cfg.Servers["fetch"].Listen)
+	assert.Equal(t, RestartOnFailure, cfg.Servers["fetch"].Restart)
+	assert.Equal(t, "sse", cfg.Servers["fetch"].Transport)
+	assert.Equal(t, "websocket", cfg.Servers["clock"].Transport)
+	assert.Equal(t, "time", cfg.Servers["clock"].Server)
+	assert.Equal(t, RestartNever, cfg.Servers["clock"].Restart)
+	assert.Equal(t, "", cfg.Servers["custom"].Server)
+	assert.Equal(t, "secret", cfg.Servers["custom"].Env["API_KEY"])
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "servers", "custom"), cfg.Servers["custom"].Dir)
+
+	claudeConfig := `
+mcpServers:
+  sqlite:
+    command: uvx
+    args: ["mcp-server-sqlite"]
+`
+	cfg, err = ParseConfig([]byte(claudeConfig))
+	require.NoError(t, err)
+	assert.Equal(t, ServerConfig{Command: "uvx", Args: []string{"mcp-server-sqlite"}, Restart: RestartOnFailure}, cfg.Servers["sqlite"])
+
+	jsonConfig := `{"servers": {"time": {"listen": ":8081", "args": ["-timezone", "UTC"]}}}`
+	cfg, err = ParseConfig([]byte(jsonConfig))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-timezone", "UTC"}, cfg.Servers["time"].Args)
+	assert.Equal(t, defaultInitialBackoff, cfg.Backoff.Initial)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"empty", ``, "no servers configured"},
+		{"all disabled", "servers:\n  time:\n    listen: :8081\n    disabled: true\n", "no servers configured"},
+		{"unknown server", "servers:\n  nope: {}\n", `unknown bundled server "nope"`},
+		{"server and command", "servers:\n  x:\n    server: time\n    command: foo\n", "not both"},
+		{"restart", "servers:\n  time:\n    listen: :8081\n    restart: sometimes\n", "invalid restart policy"},
+		{"transport", "servers:\n  time:\n    listen: :8081\n    transport: stdio\n", `invalid transport "stdio"`},
+		{"command transport", "servers:\n  x:\n    command: foo\n    transport: websocket\n", "apply only to bundled servers"},
+		{"unknown field", "servers:\n  time:\n    argz: []\n", "field argz not found"},
+		{"duplicate", "servers:\n  time:\n    listen: :8081\nmcpServers:\n  time:\n    command: foo\n", "declared in both"},
+		{"mcpServers command", "mcpServers:\n  x: {}\n", "command is required"},
+		{"backoff", "backoff:\n  initial: 10s\n  max: 1s\nservers:\n  time:\n    listen: :8081\n", "backoff"},
+		{"bundled without listen", "servers:\n  time: {}\n", "listen is required"},
+		{"command with listen", "servers:\n  x:\n    command: foo\n    listen: :8081\n", "only to bundled servers"},
+		{"shared listen", "servers:\n  time:\n    listen: :8081\n  fetch:\n    listen: :8081\n", "both listen on :8081"},
+		{"transport argument", "servers:\n  time:\n    listen: :8081\n    args: [\"--transport=stdio\"]\n", "not the -transport argument"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.config))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	sup := New(&Config{}, "/usr/bin/mcphost")
+
+	command, args := sup.command(ServerConfig{Server: "time", Listen: "127.0.0.1:8081", Transport: "sse", Args: []string{"-timezone", "UTC"}})
+	assert.Equal(t, "/usr/bin/mcphost", command)
+	assert.Equal(t, []string{"run", "time", "-transport", "sse", "-listen", "127.0.0.1:8081", "-timezone", "UTC"}, args)
+	_, args = sup.command(ServerConfig{Server: "time", Listen: "127.0.0.1:8081", Transport: "websocket"})
+	assert.Equal(t, []string{"run", "time", "-transport", "websocket", "-listen", "127.0.0.1:8081"}, args)
+
+	command, args = sup.command(ServerConfig{Command: "uvx", Args: []string{"mcp-server-sqlite"}})
+	assert.Equal(t, "uvx", command)
+	assert.Equal(t, []string{"mcp-server-sqlite"}, args)
+}
+
+func newTestSupervisor(t *testing.T, servers map[string]ServerConfig) *Supervisor {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	cfg := &Config{
+		Backoff: Backoff{Initial: 10 * time.Millisecond, Max: 40 * time.Millisecond},
+		Servers: servers,
+	}
+	return New(cfg, "sh")
+}
+
+func TestRunRestartPolicies(t *testing.T) {
+	sup := newTestSupervisor(t, map[string]ServerConfig{
+		"clean":  {Command: "sh", Args: []string{"-c", "exit 0"}, Restart: RestartOnFailure},
+		"never":  {Command: "sh", Args: []string{"-c", "exit 3"}, Restart: RestartNever},
+		"off":    {Command: "sh", Args: []string{"-c", "exit 1"}, Restart: RestartAlways, Disabled: true},
+		"broken": {Command: "/nonexistent/server", Restart: RestartNever},
+		"nodir":  {Command: "sh", Args: []string{"-c", "exit 0"}, Dir: "/nonexistent", Restart: RestartNever},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sup.Run(ctx))
+
+	status := sup.Status()
+	require.Len(t, status, 4)
+	assert.Equal(t, "broken", status[0].Name)
+	assert.Equal(t, StateFailed, status[0].State)
+	assert.Equal(t, "clean", status[1].Name)
+	assert.Equal(t, StateStopped, status[1].State)
+	assert.Equal(t, "exited cleanly", status[1].LastExit)
+	assert.Equal(t, "never", status[2].Name)
+	assert.Equal(t, StateFailed, status[2].State)
+	assert.Equal(t, "exit status 3", status[2].LastExit)
+	assert.Zero(t, status[2].Restarts)
+	// Servers whose directory is missing do not start
+	assert.Equal(t, "nodir", status[3].Name)
+	assert.Equal(t, StateFailed, status[3].State)
+}
+
+func TestRunInDir(t *testing.T) {
+	dir := t.TempDir()
+	sup := newTestSupervisor(t, map[string]ServerConfig{
+		"pwd": {Command: "sh", Args: []string{"-c", "pwd > pwd.txt"}, Dir: dir, Restart: RestartNever},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sup.Run(ctx))
+
+	pwd, err := os.ReadFile(filepath.Join(dir, "pwd.txt"))
+	require.NoError(t, err)
+	want, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	got, err := filepath.EvalSymlinks(strings.TrimSpace(string(pwd)))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestRunRestartsCrashedServer(t *testing.T) {
+	sup := newTestSupervisor(t, map[string]ServerConfig{
+		"crashing": {Command: "sh", Args: []string{"-c", "exit 1"}, Restart: RestartOnFailure},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, sup.Run(ctx))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return sup.Status()[0].Restarts >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	status := sup.Status()[0]
+	assert.Equal(t, StateStopped, status.State)
+	assert.Equal(t, "exit status 1", status.LastExit)
+}
+
+func TestRunStopsRunningServer(t *testing.T) {
+	// The server runs until its stdin is closed
+	sup := newTestSupervisor(t, map[string]ServerConfig{
+		"stdio": {Command: "sh", Args: []string{"-c", "cat >/dev/null"}, Restart: RestartAlways},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, sup.Run(ctx))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return sup.Status()[0].State == StateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotZero(t, sup.Status()[0].PID)
+
+	// The status endpoint reports the running server
+	rec := httptest.NewRecorder()
+	sup.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	var status []Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Len(t, status, 1)
+	assert.Equal(t, StateRunning, status[0].State)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(stopTimeout + 5*time.Second):
+		t.Fatal("server was not stopped")
+	}
+	assert.Equal(t, StateStopped, sup.Status()[0].State)
+	assert.Zero(t, sup.Status()[0].Restarts)
+}
+
+// Test readiness of running, stopped and bundled servers
+func TestReady(t *testing.T) {
+	readiness := health.Report{Status: health.StatusOK}
+	bundled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, health.ReadinessPath, r.URL.Path)
+		health.WriteReport(w, readiness)
+	}))
+	defer bundled.Close()
+	_, port, err := net.SplitHostPort(bundled.Listener.Addr().String())
+	require.NoError(t, err)
+
+	sup := New(&Config{Servers: map[string]ServerConfig{
+		"search": {Server: "googlesearch", Listen: ":" + port},
+		"https":  {Server: "fetch", Listen: "127.0.0.1:1", Args: []string{"-tls-cert=cert.pem", "-tls-key=key.pem"}},
+		"custom": {Command: "my-server"},
+		"down":   {Command: "my-server"},
+	}}, "mcphost")
+	for _, name := range []string{"search", "https", "custom"} {
+		sup.update(name, func(st *Status) { st.State = StateRunning })
+	}
+	sup.update("down", func(st *Status) { st.State = StateBackoff })
+
+	report := sup.Ready(context.Background())
+	assert.False(t, report.OK())
+	assert.Equal(t, health.StatusOK, report.Checks["search"].Status)
+	assert.Equal(t, health.StatusOK, report.Checks["https"].Status)
+	assert.Equal(t, health.StatusOK, report.Checks["custom"].Status)
+	assert.Equal(t, "server is backoff", report.Checks["down"].Error)
+
+	readiness = health.Report{Status: health.StatusFail, Checks: map[string]health.CheckResult{
+		"google-api": {Status: health.StatusFail, Error: "API key is not configured"},
+	}}
+	sup.update("down", func(st *Status) { st.State = StateRunning })
+	rec := httptest.NewRecorder()
+	sup.ServeHTTP(rec, httptest.NewRequest("GET", health.ReadinessPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "not ready: google-api: API key is not configured", report.Checks["search"].Error)
+	assert.Equal(t, health.StatusOK, report.Checks["down"].Status)
+
+	rec = httptest.NewRecorder()
+	sup.ServeHTTP(rec, httptest.NewRequest("GET", health.LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
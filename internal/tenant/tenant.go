@@ -0,0 +1,230 @@
+// Package tenant lets one deployment serve several teams: each tenant, read from a
+// YAML or JSON file, has its own credentials, client networks, enabled tools and rate
+// limits, and the calls of remote clients are held to those of the tenant their
+// credentials select.
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/i18n"
+	"github.com/mark3labs/mcphost/internal/identity"
+	"github.com/mark3labs/mcphost/internal/ratelimit"
+)
+
+// Config declares the tenants of a server.
+type Config struct {
+	// Anonymous names the tenant of remote clients whose credentials match no tenant;
+	// they are refused when it is empty.
+	Anonymous string            `yaml:"anonymous"`
+	Tenants   map[string]Tenant `yaml:"tenants"`
+
+	names []string // sorted, so that credentials are matched in a stable order
+}
+
+// Tenant is what the clients of a team may do. Environment variables in API keys are
+// expanded, so the keys need not be stored in the file.
+type Tenant struct {
+	APIKeys      []string `yaml:"apiKeys"`
+	Certificates []string `yaml:"certificates"` // common names of TLS client certificates
+	// Networks are the addresses or CIDR prefixes the clients may call from; any
+	// address when empty.
+	Networks []string `yaml:"networks"`
+	// Tools are the patterns of the tools enabled for the tenant, such as fetchURL or
+	// search*; all tools when empty.
+	Tools []string `yaml:"tools"`
+	// RateLimit limits the calls of all the clients of the tenant together, with the
+	// syntax of -rate-limit, e.g. searchGoogle=100/h,*=10/s.
+	RateLimit string `yaml:"rateLimit"`
+
+	networks []netip.Prefix
+	limiter  *ratelimit.Limiter
+}
+
+// Load reads and validates a tenants file.
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tenants file %s: %w", file, err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tenants file %s: %w", file, err)
+	}
+	return c, nil
+}
+
+// Parse decodes and validates a YAML or JSON tenants config.
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(c.Tenants) == 0 {
+		return nil, errors.New("no tenants configured")
+	}
+	if _, ok := c.Tenants[c.Anonymous]; c.Anonymous != "" && !ok {
+		return nil, fmt.Errorf("anonymous: unknown tenant %s", c.Anonymous)
+	}
+
+	keys := make(map[string]string)
+	for name := range c.Tenants {
+		c.names = append(c.names, name)
+	}
+	sort.Strings(c.names)
+	for _, name := range c.names {
+		t := c.Tenants[name]
+		if len(t.APIKeys) == 0 && len(t.Certificates) == 0 && name != c.Anonymous {
+			return nil, fmt.Errorf("tenant %s: apiKeys or certificates are required", name)
+		}
+		for i, key := range t.APIKeys {
+			if t.APIKeys[i] = os.ExpandEnv(key); t.APIKeys[i] == "" {
+				return nil, fmt.Errorf("tenant %s: API key %d is empty", name, i+1)
+			}
+			if other, dup := keys[t.APIKeys[i]]; dup {
+				return nil, fmt.Errorf("tenant %s: API key %d is also a key of tenant %s", name, i+1, other)
+			}
+			keys[t.APIKeys[i]] = name
+		}
+		for _, network := range t.Networks {
+			prefix, err := parseNetwork(network)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s: invalid network %q: expected an address or a CIDR prefix", name, network)
+			}
+			t.networks = append(t.networks, prefix)
+		}
+		for _, pattern := range t.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("tenant %s: invalid tool pattern %q", name, pattern)
+			}
+		}
+		rules, err := ratelimit.ParseRules(t.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		if len(rules) > 0 {
+			t.limiter = ratelimit.New(rules)
+		}
+		c.Tenants[name] = t
+	}
+	return &c, nil
+}
+
+// parseNetwork parses an address, standing for itself, or a CIDR prefix.
+func parseNetwork(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Match returns the name of the tenant whose credentials creds presents, or else the
+// anonymous tenant; ok is false if there is none.
+func (c *Config) Match(creds identity.Credentials) (name string, ok bool) {
+	for _, name := range c.names {
+		t := c.Tenants[name]
+		for _, key := range t.APIKeys {
+			if creds.APIKey != "" && subtle.ConstantTimeCompare([]byte(creds.APIKey), []byte(key)) == 1 {
+				return name, true
+			}
+		}
+		for _, cn := range t.Certificates {
+			if creds.CertificateCN != "" && creds.CertificateCN == cn {
+				return name, true
+			}
+		}
+	}
+	return c.Anonymous, c.Anonymous != ""
+}
+
+// allowsAddr returns whether a client of t may call from addr, a host:port address.
+func (t *Tenant) allowsAddr(addr string) bool {
+	if len(t.networks) == 0 {
+		return true
+	}
+	ip, err := netip.ParseAddrPort(addr)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range t.networks {
+		if prefix.Contains(ip.Addr().Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsTool returns whether tool is enabled for t.
+func (t *Tenant) allowsTool(tool string) bool {
+	if len(t.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range t.Tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type nameKey struct{}
+
+// FromContext returns the name of the tenant of a call, empty for local clients.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
+
+// Middleware returns tool middleware holding the calls of remote clients to their
+// tenant: calls of unknown clients, from networks or to tools the tenant does not
+// allow, or over its rate limits get an error result. Local clients, such as those
+// over stdio, are not restricted.
+func (c *Config) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			creds := identity.FromContext(ctx)
+			if creds.RemoteAddr == "" {
+				return next(ctx, req)
+			}
+			lang := i18n.FromContext(ctx)
+			name, ok := c.Match(creds)
+			if !ok {
+				return mcp.NewToolResultError(i18n.T(lang, "Unknown client: present the API key or TLS client certificate of a tenant")), nil
+			}
+			t := c.Tenants[name]
+			if !t.allowsAddr(creds.RemoteAddr) {
+				return mcp.NewToolResultError(i18n.Sprintf(lang, "Tenant %s may not call tools from %s", name, creds.RemoteAddr)), nil
+			}
+			if !t.allowsTool(req.Params.Name) {
+				return mcp.NewToolResultError(i18n.Sprintf(lang, "%s is not enabled for tenant %s", req.Params.Name, name)), nil
+			}
+			if t.limiter != nil {
+				if ok, retryAfter := t.limiter.Allow(name, req.Params.Name); !ok {
+					return ratelimit.Exceeded(ctx, req.Params.Name, retryAfter), nil
+				}
+			}
+			return next(context.WithValue(ctx, nameKey{}, name), req)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tenant
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/identity"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+const testTenants = `
+anonymous: public
+tenants:
+  search:
+    apiKeys: ["${TEST_SEARCH_KEY}"]
+    tools: ["search*", getServerInfo]
+    rateLimit: searchGoogle=2/h
+  ops:
+    apiKeys: [ops-secret]
+    certificates: [ops.example.com]
+    networks: [10.0.0.0/8, "192.168.1.7"]
+  public:
+    tools: [getCurrentTime]
+`
+
+// Test that credentials select their tenant
+func TestMatch(t *testing.T) {
+	t.Setenv("TEST_SEARCH_KEY", "search-secret")
+	c, err := Parse([]byte(testTenants))
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		creds  identity.Credentials
+		tenant string
+	}{
+		"API key":     {identity.Credentials{APIKey: "search-secret"}, "search"},
+		"Certificate": {identity.Credentials{CertificateCN: "ops.example.com"}, "ops"},
+		"Wrong key":   {identity.Credentials{APIKey: "guess"}, "public"},
+		"None":        {identity.Credentials{}, "public"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tenant, ok := c.Match(tc.creds)
+			assert.True(t, ok)
+			assert.Equal(t, tc.tenant, tenant)
+		})
+	}
+
+	c.Anonymous = ""
+	_, ok := c.Match(identity.Credentials{APIKey: "guess"})
+	assert.False(t, ok)
+}
+
+// Test invalid tenants
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]string{
+		"": "no tenants configured",
+		"tenants: {a: {apiKeys: [k]}}\nanonymous: b":            "anonymous: unknown tenant b",
+		"tenants: {a: {tools: [x]}}":                            "tenant a: apiKeys or certificates are required",
+		"tenants: {a: {apiKeys: ['']}}":                         "tenant a: API key 1 is empty",
+		"tenants: {a: {apiKeys: [k]}, b: {apiKeys: [k]}}":       "tenant b: API key 1 is also a key of tenant a",
+		"tenants: {a: {apiKeys: [k], networks: [10.0.0.0/33]}}": `tenant a: invalid network "10.0.0.0/33"`,
+		"tenants: {a: {apiKeys: [k], tools: ['[']}}":            `tenant a: invalid tool pattern "["`,
+		"tenants: {a: {apiKeys: [k], rateLimit: 'x=1'}}":        `tenant a: invalid rate limit "x=1"`,
+		"tenants: {a: {apiKeys: [k], models: [gpt]}}":           "field models not found",
+	}
+	for data, message := range testCases {
+		_, err := Parse([]byte(data))
+		assert.ErrorContains(t, err, message, data)
+	}
+}
+
+// Test that remote calls are held to their tenant and local ones are not
+func TestMiddleware(t *testing.T) {
+	t.Setenv("TEST_SEARCH_KEY", "search-secret")
+	c, err := Parse([]byte(testTenants))
+	require.NoError(t, err)
+	handler := c.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("called by " + FromContext(ctx)), nil
+	})
+	call := func(remoteAddr, apiKey, tool string) *mcp.CallToolResult {
+		ctx := context.Background()
+		if remoteAddr != "" {
+			r := httptest.NewRequest("POST", "/message", nil)
+			r.RemoteAddr = remoteAddr
+			if apiKey != "" {
+				r.Header.Set("X-API-Key", apiKey)
+			}
+			ctx = identity.FromRequest(ctx, r)
+		}
+		result, err := handler(ctx, mcptest.NewCallToolRequest(tool, nil))
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("203.0.113.5:4000", "search-secret", "searchGoogle")
+	assert.False(t, result.IsError)
+	assert.Equal(t, "called by search", mcptest.ResultText(result))
+	assert.Equal(t, "called by public", mcptest.ResultText(call("203.0.113.5:4000", "", "getCurrentTime")))
+	assert.Equal(t, "called by ", mcptest.ResultText(call("", "", "runCommand")), "Local clients are not restricted")
+
+	result = call("203.0.113.5:4000", "search-secret", "fetchURL")
+	assert.True(t, result.IsError)
+	assert.Equal(t, "fetchURL is not enabled for tenant search", mcptest.ResultText(result))
+
+	// The rate limits of a tenant are shared by its clients
+	assert.False(t, call("203.0.113.6:4000", "search-secret", "searchGoogle").IsError)
+	result = call("203.0.113.7:4000", "search-secret", "searchGoogle")
+	assert.True(t, result.IsError)
+	assert.Equal(t, true, result.Meta["rateLimited"])
+
+	// Tenants with networks only accept calls from them
+	assert.False(t, call("10.1.2.3:4000", "ops-secret", "runCommand").IsError)
+	assert.False(t, call("192.168.1.7:4000", "ops-secret", "runCommand").IsError)
+	result = call("192.168.1.8:4000", "ops-secret", "runCommand")
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Tenant ops may not call tools from 192.168.1.8:4000", mcptest.ResultText(result))
+
+	c.Anonymous = ""
+	result = call("203.0.113.5:4000", "", "getCurrentTime")
+	assert.True(t, result.IsError)
+	assert.Contains(t, mcptest.ResultText(result), "Unknown client")
+}
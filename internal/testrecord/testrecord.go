@@ -0,0 +1,400 @@
+// Package testrecord records the tool calls of a server, and the HTTP requests its
+// tools make upstream, in cassette files, and replays them, so that tests and demos
+// run deterministically and offline.
+//
+// Secret arguments and query parameters are masked with the audit redactor, both
+// when recording and when matching, so cassettes can be committed.
+package testrecord
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/audit"
+)
+
+// Cassette is the recording of a session.
+type Cassette struct {
+	Calls    []Call     `json:"calls,omitempty"`
+	Requests []Exchange `json:"requests,omitempty"`
+}
+
+// Call is a recorded tool call.
+type Call struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	// Result is the CallToolResult returned by the tool, absent if the call failed.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Exchange is a recorded HTTP request and its response.
+type Exchange struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	RequestBody Body   `json:"requestBody,omitempty"`
+
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+	// Error is the transport error of a request that got no response.
+	Error string `json:"error,omitempty"`
+}
+
+// Body is a request or response body. It is stored as a JSON string when it is
+// valid UTF-8, so cassettes stay readable, and as {"base64": "..."} otherwise.
+type Body []byte
+
+// MarshalJSON implements json.Marshaler.
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return fmt.Errorf("body is neither a string nor base64: %w", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("testrecord: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("testrecord: invalid cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes c to a cassette file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("testrecord: %w", err)
+	}
+	return nil
+}
+
+// redactor masks secrets in recorded arguments and URLs.
+var redactor = audit.NewRedactor()
+
+// secretParams are query parameters masked besides those the redactor masks, which
+// are often named just so by APIs, e.g. key=... for Google APIs.
+var secretParams = map[string]bool{"key": true, "sig": true, "signature": true}
+
+// redactURL masks the secret query parameters of rawURL, e.g. an API key.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	query := u.Query()
+	args := make(map[string]interface{}, len(query))
+	for key := range query {
+		args[key] = ""
+	}
+	for key, value := range redactor.Redact(args) {
+		if value == audit.Redacted || secretParams[strings.ToLower(key)] {
+			query[key] = []string{audit.Redacted}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// normalize returns args as they are recorded: redacted, and with numbers and
+// nested values in their JSON form, so recorded and live arguments compare equal.
+func normalize(args map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(redactor.Redact(args))
+	if err != nil {
+		return nil
+	}
+	var normalized map[string]interface{}
+	json.Unmarshal(data, &normalized)
+	redactURLs(normalized)
+	return normalized
+}
+
+// redactURLs masks the secret query parameters of the URLs in value, in place.
+func redactURLs(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok && (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")) {
+				v[key] = redactURL(s)
+			} else {
+				redactURLs(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if s, ok := item.(string); ok && (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")) {
+				v[i] = redactURL(s)
+			} else {
+				redactURLs(item)
+			}
+		}
+	}
+}
+
+// Recorder records tool calls and HTTP requests into a cassette.
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder with an empty cassette.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Cassette returns a copy of what was recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{
+		Calls:    append([]Call(nil), r.cassette.Calls...),
+		Requests: append([]Exchange(nil), r.cassette.Requests...),
+	}
+}
+
+// Save writes what was recorded so far to a cassette file.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// Middleware returns tool middleware recording the calls and their results.
+func (r *Recorder) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, req)
+
+			call := Call{Tool: req.Params.Name, Arguments: normalize(req.Params.Arguments)}
+			if err != nil {
+				call.Error = err.Error()
+			} else if result != nil {
+				if call.Result, err = json.Marshal(result); err != nil {
+					return nil, err
+				}
+			}
+			r.mu.Lock()
+			r.cassette.Calls = append(r.cassette.Calls, call)
+			r.mu.Unlock()
+			return result, err
+		}
+	}
+}
+
+// Transport returns a RoundTripper recording the requests sent through base and
+// their responses.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		exchange := Exchange{Method: req.Method, URL: redactURL(req.URL.String()), RequestBody: body}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			exchange.Error = err.Error()
+		} else {
+			if exchange.Body, err = readBody(&resp.Body); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			exchange.Status = resp.StatusCode
+			exchange.Header = resp.Header.Clone()
+		}
+		r.mu.Lock()
+		r.cassette.Requests = append(r.cassette.Requests, exchange)
+		r.mu.Unlock()
+		return resp, err
+	})
+}
+
+// readBody reads *body and replaces it with a reader of the same bytes.
+func readBody(body *io.ReadCloser) (Body, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// Player replays a cassette. A recorded call or request is replayed for the first
+// matching one, then the next recording of it for the next, and so on; once they
+// are used up, the last one is repeated.
+type Player struct {
+	mu           sync.Mutex
+	cassette     *Cassette
+	usedCalls    []bool
+	usedRequests []bool
+}
+
+// NewPlayer creates a Player replaying c.
+func NewPlayer(c *Cassette) *Player {
+	return &Player{
+		cassette:     c,
+		usedCalls:    make([]bool, len(c.Calls)),
+		usedRequests: make([]bool, len(c.Requests)),
+	}
+}
+
+// next returns the index of the first unused recording matching, or of the last
+// one if all are used, or -1 if none matches.
+func next(n int, used []bool, matches func(i int) bool) int {
+	last := -1
+	for i := 0; i < n; i++ {
+		if !matches(i) {
+			continue
+		}
+		if !used[i] {
+			used[i] = true
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+// Middleware returns tool middleware answering the recorded calls from the
+// cassette. Other calls run the tool, whose HTTP requests Transport can replay.
+func (p *Player) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			call, ok := p.call(req)
+			if !ok {
+				return next(ctx, req)
+			}
+			if call.Result == nil {
+				return nil, fmt.Errorf("%s", call.Error)
+			}
+			return mcp.ParseCallToolResult(&call.Result)
+		}
+	}
+}
+
+func (p *Player) call(req mcp.CallToolRequest) (Call, bool) {
+	args := normalize(req.Params.Arguments)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := p.cassette.Calls
+	i := next(len(calls), p.usedCalls, func(i int) bool {
+		return calls[i].Tool == req.Params.Name && equalArguments(calls[i].Arguments, args)
+	})
+	if i < 0 {
+		return Call{}, false
+	}
+	return calls[i], true
+}
+
+func equalArguments(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Transport returns a RoundTripper answering requests with the recorded responses
+// to the same method, URL and body. Requests that were not recorded fail, so that
+// nothing goes out to the network.
+func (p *Player) Transport() http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		requestURL := redactURL(req.URL.String())
+
+		p.mu.Lock()
+		requests := p.cassette.Requests
+		i := next(len(requests), p.usedRequests, func(i int) bool {
+			r := requests[i]
+			return r.Method == req.Method && r.URL == requestURL && bytes.Equal(r.RequestBody, body)
+		})
+		p.mu.Unlock()
+		if i < 0 {
+			return nil, fmt.Errorf("testrecord: no recorded response to %s %s", req.Method, requestURL)
+		}
+		exchange := requests[i]
+		if exchange.Error != "" {
+			return nil, fmt.Errorf("%s", exchange.Error)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+			StatusCode:    exchange.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        exchange.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(exchange.Body)),
+			ContentLength: int64(len(exchange.Body)),
+			Request:       req,
+		}, nil
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+var interceptMu sync.Mutex
+
+// Intercept sends the requests made through http.DefaultTransport, which the HTTP
+// clients of the servers use, to the RoundTripper wrap returns for it. The returned
+// function restores the transport.
+func Intercept(wrap func(base http.RoundTripper) http.RoundTripper) func() {
+	interceptMu.Lock()
+	defer interceptMu.Unlock()
+	base := http.DefaultTransport
+	http.DefaultTransport = wrap(base)
+	return func() {
+		interceptMu.Lock()
+		defer interceptMu.Unlock()
+		http.DefaultTransport = base
+	}
+}
@@ -0,0 +1,122 @@
+package testrecord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// fetchTool returns a tool handler fetching the url argument with client.
+func fetchTool(client *http.Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		u, _ := req.Params.Arguments["url"].(string)
+		if u == "" {
+			return nil, errors.New("url is required")
+		}
+		resp, err := client.Get(u)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return mcp.NewToolResultText(fmt.Sprintf("%d %s", resp.StatusCode, body)), nil
+	}
+}
+
+// Test that a recorded session replays without the upstream server
+func TestRecordAndReplay(t *testing.T) {
+	count := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s #%d", r.URL.Query().Get("q"), count)
+	}))
+	recorder := NewRecorder()
+	tool := recorder.Middleware()(fetchTool(&http.Client{Transport: recorder.Transport(http.DefaultTransport)}))
+
+	ctx := context.Background()
+	search := mcptest.NewCallToolRequest("search", map[string]interface{}{"url": upstream.URL + "?q=go&key=secret"})
+	for _, want := range []string{"200 go #1", "200 go #2"} {
+		result, err := tool(ctx, search)
+		require.NoError(t, err)
+		assert.Equal(t, want, mcptest.ResultText(result))
+	}
+	_, err := tool(ctx, mcptest.NewCallToolRequest("search", nil))
+	require.EqualError(t, err, "url is required")
+	upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Save(path))
+	cassette, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Calls, 3)
+	require.Len(t, cassette.Requests, 2)
+	assert.Equal(t, "text/plain", cassette.Requests[0].Header.Get("Content-Type"))
+	// Secrets are masked in what is recorded
+	assert.Equal(t, upstream.URL+"?key=%5BREDACTED%5D&q=go", cassette.Requests[0].URL)
+	assert.Equal(t, upstream.URL+"?key=%5BREDACTED%5D&q=go", cassette.Calls[0].Arguments["url"])
+
+	// Recorded calls are answered in order, the last one repeated
+	player := NewPlayer(cassette)
+	replayed := player.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("tool should not run")
+	})
+	for _, want := range []string{"200 go #1", "200 go #2", "200 go #2"} {
+		result, err := replayed(ctx, search)
+		require.NoError(t, err)
+		assert.Equal(t, want, mcptest.ResultText(result))
+	}
+	_, err = replayed(ctx, mcptest.NewCallToolRequest("search", nil))
+	require.EqualError(t, err, "url is required")
+
+	// Other calls run the tool, against the recorded HTTP responses
+	tool = player.Middleware()(fetchTool(&http.Client{Transport: player.Transport()}))
+	result, err := tool(ctx, mcptest.NewCallToolRequest("search", map[string]interface{}{"url": upstream.URL + "/?q=go&key=other"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, mcptest.ResultText(result), "testrecord: no recorded response to GET "+upstream.URL+"/?key=%5BREDACTED%5D&q=go")
+
+	resp, err := (&http.Client{Transport: player.Transport()}).Get(upstream.URL + "?q=go&key=other")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "go #1", string(body))
+}
+
+// Test that bodies that are not text are stored in base64
+func TestBody(t *testing.T) {
+	for _, body := range []Body{Body("hello"), Body{0xff, 0x00}, nil} {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		var decoded Body
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, string(body), string(decoded))
+	}
+	data, _ := json.Marshal(Body{0xff})
+	assert.Equal(t, `{"base64":"/w=="}`, string(data))
+}
+
+// Test that Intercept routes the default transport and restores it
+func TestIntercept(t *testing.T) {
+	base := http.DefaultTransport
+	player := NewPlayer(&Cassette{Requests: []Exchange{{Method: "GET", URL: "http://example.com/", Status: 404, Body: Body("missing")}}})
+	restore := Intercept(func(http.RoundTripper) http.RoundTripper { return player.Transport() })
+	resp, err := http.Get("http://example.com/")
+	restore()
+	require.NoError(t, err)
+	assert.Equal(t, "404 Not Found", resp.Status)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "missing", string(body))
+	assert.Equal(t, base, http.DefaultTransport)
+}
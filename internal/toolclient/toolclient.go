@@ -0,0 +1,285 @@
+// Package toolclient connects to MCP servers as a client, so that their tools can be
+// listed and called from the terminal and from scripts, without an LLM in the loop.
+package toolclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/inprocess"
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/secretref"
+	"github.com/mark3labs/mcphost/internal/servers"
+)
+
+// sseReadTimeout is how long the SSE connection of a client is kept open.
+const sseReadTimeout = 24 * time.Hour
+
+// Client is a connection to a server.
+type Client interface {
+	Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error)
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	Close() error
+}
+
+// Target is the server a client connects to.
+type Target struct {
+	// Server is the URL of an SSE endpoint, the name of a server declared in
+	// MCPServers, or the name of a bundled server, which is run in process.
+	Server string
+	// Args are the flags of a bundled server, or passed to a stdio server after its
+	// own arguments.
+	Args []string
+	// Headers are sent to an SSE server, e.g. Authorization.
+	Headers map[string]string
+	// MCPServers are the stdio servers of the chat config.
+	MCPServers map[string]mcpconfig.Server
+	// Stderr receives the log output of stdio servers; it is discarded when nil.
+	Stderr io.Writer
+}
+
+// Connect starts or connects to the server of t and initializes the session.
+func Connect(ctx context.Context, t Target) (Client, error) {
+	var client Client
+	switch {
+	case strings.HasPrefix(t.Server, "http://") || strings.HasPrefix(t.Server, "https://"):
+		if len(t.Args) > 0 {
+			return nil, fmt.Errorf("server arguments cannot be passed to %s", t.Server)
+		}
+		c, err := mcpclient.NewSSEMCPClient(t.Server,
+			mcpclient.WithHeaders(t.Headers), mcpclient.WithSSEReadTimeout(sseReadTimeout))
+		if err != nil {
+			return nil, err
+		}
+		// The event stream outlives ctx, which only bounds the connection setup
+		streamCtx, cancel := context.WithCancel(context.Background())
+		if err := c.Start(streamCtx); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to connect to %s: %w", t.Server, err)
+		}
+		client = &sseClient{SSEMCPClient: c, cancel: cancel}
+	case t.MCPServers[t.Server].Command != "":
+		sc := t.MCPServers[t.Server]
+		env, err := secretref.Environ(sc.Env)
+		if err != nil {
+			return nil, err
+		}
+		args := append(append([]string{}, sc.Args...), t.Args...)
+		c, err := mcpclient.NewStdioMCPClient(sc.Command, env, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start server %s: %w", t.Server, err)
+		}
+		// The server blocks once the stderr pipe is full, so keep reading it
+		stderr := t.Stderr
+		if stderr == nil {
+			stderr = io.Discard
+		}
+		go io.Copy(stderr, c.Stderr())
+		client = c
+	default:
+		bundled, ok := servers.Lookup(t.Server)
+		if !ok {
+			return nil, fmt.Errorf("unknown server %q; use a bundled server, a server of the config or an SSE URL", t.Server)
+		}
+		// Background work of the server, such as scheduled jobs, lasts until Close
+		serverCtx, cancel := context.WithCancel(context.Background())
+		s, _, err := bundled.New(serverCtx, t.Args)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create server %s: %w", t.Server, err)
+		}
+		c, err := inprocess.NewClient(serverCtx, cancel, s)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		client = c
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcphost",
+		Version: "1.0.0",
+	}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to initialize server %s: %w", t.Server, err)
+	}
+	return client, nil
+}
+
+// sseClient closes the event stream along with the client, which the SSE client of
+// mcp-go leaves open.
+type sseClient struct {
+	*mcpclient.SSEMCPClient
+	cancel context.CancelFunc
+}
+
+func (c *sseClient) Close() error {
+	c.cancel()
+	return c.SSEMCPClient.Close()
+}
+
+// FindTool returns the tool with the given name.
+func FindTool(tools []mcp.Tool, name string) (mcp.Tool, error) {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return mcp.Tool{}, fmt.Errorf("unknown tool %q; the server has %s", name, strings.Join(names, ", "))
+}
+
+// Arguments builds the arguments of a call to tool from a JSON object, which may be
+// empty, and key=value pairs added to it. Values are converted to the type the input
+// schema of the tool declares for them; values of array arguments that are not JSON
+// arrays are appended as items, so that a key can be repeated.
+func Arguments(tool mcp.Tool, jsonArgs string, pairs []string) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	if strings.TrimSpace(jsonArgs) != "" {
+		if err := json.Unmarshal([]byte(jsonArgs), &args); err != nil {
+			return nil, fmt.Errorf("invalid JSON arguments: %w", err)
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected key=value", pair)
+		}
+		property, _ := tool.InputSchema.Properties[key].(map[string]interface{})
+		typ, _ := property["type"].(string)
+		if typ == "array" && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			items, _ := property["items"].(map[string]interface{})
+			itemType, _ := items["type"].(string)
+			item, err := convert(itemType, value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: %w", key, err)
+			}
+			list, _ := args[key].([]interface{})
+			args[key] = append(list, item)
+			continue
+		}
+		v, err := convert(typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", key, err)
+		}
+		args[key] = v
+	}
+	return args, nil
+}
+
+// convert parses value as the JSON schema type typ. Values of unknown type are taken
+// as JSON when they parse as such, and as text otherwise.
+func convert(typ, value string) (interface{}, error) {
+	switch typ {
+	case "string":
+		return value, nil
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return n, nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case "object", "array":
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("%q is not a JSON %s", value, typ)
+		}
+		return v, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return value, nil
+	}
+	return v, nil
+}
+
+// WriteResult writes the content of a tool result: text as it is, and a line
+// describing each image and resource.
+func WriteResult(w io.Writer, result *mcp.CallToolResult) error {
+	for _, content := range result.Content {
+		var err error
+		switch c := content.(type) {
+		case mcp.TextContent:
+			_, err = fmt.Fprintln(w, strings.TrimRight(c.Text, "\n"))
+		case mcp.ImageContent:
+			_, err = fmt.Fprintf(w, "[image %s, %d bytes]\n", c.MIMEType, base64.StdEncoding.DecodedLen(len(c.Data)))
+		case mcp.EmbeddedResource:
+			switch r := c.Resource.(type) {
+			case mcp.TextResourceContents:
+				_, err = fmt.Fprintf(w, "[resource %s]\n%s\n", r.URI, strings.TrimRight(r.Text, "\n"))
+			case mcp.BlobResourceContents:
+				_, err = fmt.Fprintf(w, "[resource %s, %s, %d bytes]\n", r.URI, r.MIMEType, base64.StdEncoding.DecodedLen(len(r.Blob)))
+			}
+		default:
+			_, err = fmt.Fprintf(w, "[%T content]\n", content)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTools writes the name and description of each tool and, with verbose, its
+// arguments.
+func WriteTools(w io.Writer, tools []mcp.Tool, verbose bool) error {
+	var b strings.Builder
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "%s\n", tool.Name)
+		if tool.Description != "" {
+			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(strings.TrimSpace(tool.Description), "\n", "\n    "))
+		}
+		if !verbose {
+			continue
+		}
+		required := make(map[string]bool)
+		for _, name := range tool.InputSchema.Required {
+			required[name] = true
+		}
+		names := make([]string, 0, len(tool.InputSchema.Properties))
+		for name := range tool.InputSchema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, _ := tool.InputSchema.Properties[name].(map[string]interface{})
+			typ, _ := property["type"].(string)
+			description, _ := property["description"].(string)
+			if required[name] {
+				typ += ", required"
+			}
+			fmt.Fprintf(&b, "    --arg %s=<%s>  %s\n", name, typ, description)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
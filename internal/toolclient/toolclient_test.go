@@ -0,0 +1,101 @@
+package toolclient
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test calling bundled servers in process and servers over SSE
+func TestConnect(t *testing.T) {
+	ctx := context.Background()
+	client, err := Connect(ctx, Target{Server: "time", Args: []string{"-timezone", "UTC"}})
+	require.NoError(t, err)
+	defer client.Close()
+	result, err := client.CallTool(ctx, mcptest.NewCallToolRequest("getCurrentTime", nil))
+	require.NoError(t, err)
+	assert.Contains(t, mcptest.ResultText(result), "Current time (UTC)")
+
+	s := server.NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(req.Params.Arguments["text"].(string)), nil
+	})
+	ts := server.NewTestServer(s)
+	defer ts.Close()
+	client, err = Connect(ctx, Target{Server: ts.URL + "/sse"})
+	require.NoError(t, err)
+	defer client.Close()
+	result, err = client.CallTool(ctx, mcptest.NewCallToolRequest("echo", map[string]interface{}{"text": "hi"}))
+	require.NoError(t, err)
+	assert.Equal(t, "hi", mcptest.ResultText(result))
+
+	_, err = Connect(ctx, Target{Server: "nope"})
+	assert.ErrorContains(t, err, `unknown server "nope"`)
+	_, err = Connect(ctx, Target{Server: "time", Args: []string{"-nope"}})
+	assert.ErrorContains(t, err, "failed to create server time")
+}
+
+// Test converting arguments to the types of the input schema
+func TestArguments(t *testing.T) {
+	tool := mcp.NewTool("test",
+		mcp.WithString("name"),
+		mcp.WithNumber("limit"),
+		mcp.WithBoolean("literal"),
+		mcp.WithArray("tags", mcp.Items(map[string]interface{}{"type": "string"})),
+		mcp.WithObject("headers"),
+	)
+	args, err := Arguments(tool, `{"name": "from json", "extra": 1}`,
+		[]string{"name=42", "limit=10", "literal=true", "tags=a", "tags=b", "headers={\"X\": \"y\"}", "other=[1,2]", "text=plain"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":    "42",
+		"extra":   float64(1),
+		"limit":   float64(10),
+		"literal": true,
+		"tags":    []interface{}{"a", "b"},
+		"headers": map[string]interface{}{"X": "y"},
+		"other":   []interface{}{float64(1), float64(2)},
+		"text":    "plain",
+	}, args)
+
+	args, err = Arguments(tool, "", []string{`tags=["x","y"]`})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"x", "y"}, args["tags"])
+
+	for _, pair := range []string{"limit=ten", "literal=maybe", "headers=[]x", "novalue", "=x"} {
+		_, err := Arguments(tool, "", []string{pair})
+		assert.Error(t, err, pair)
+	}
+	_, err = Arguments(tool, "[1]", nil)
+	assert.ErrorContains(t, err, "invalid JSON arguments")
+}
+
+// Test writing results and tool lists
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	result := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("hello\n"),
+		mcp.NewImageContent("aGVsbG8=", "image/png"),
+		mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "file:///a.txt", Text: "text"}),
+	}}
+	require.NoError(t, WriteResult(&buf, result))
+	assert.Equal(t, "hello\n[image image/png, 6 bytes]\n[resource file:///a.txt]\ntext\n", buf.String())
+
+	buf.Reset()
+	tools := []mcp.Tool{mcp.NewTool("echo", mcp.WithDescription("Echoes"), mcp.WithString("text", mcp.Required(), mcp.Description("Text to echo")))}
+	require.NoError(t, WriteTools(&buf, tools, false))
+	assert.Equal(t, "echo\n    Echoes\n", buf.String())
+	buf.Reset()
+	require.NoError(t, WriteTools(&buf, tools, true))
+	assert.Equal(t, "echo\n    Echoes\n    --arg text=<string, required>  Text to echo\n", buf.String())
+
+	_, err := FindTool(tools, "nope")
+	assert.ErrorContains(t, err, `unknown tool "nope"; the server has echo`)
+}
@@ -0,0 +1,139 @@
+// Package toolerr reports the failures of tool calls to clients as error results,
+// with a code and whether retrying may succeed, rather than as JSON-RPC errors, so
+// that models see why a tool failed and can recover.
+package toolerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/params"
+)
+
+// Code classifies the failure of a tool call.
+type Code string
+
+// Codes of failures.
+const (
+	// InvalidParams is for arguments the tool cannot work with.
+	InvalidParams Code = "invalid_params"
+	// NotFound is for missing files, records or other things the call refers to.
+	NotFound Code = "not_found"
+	// PermissionDenied is for calls the configuration of the server does not allow.
+	PermissionDenied Code = "permission_denied"
+	// Unavailable is for upstream services that failed or could not be reached.
+	Unavailable Code = "unavailable"
+	// Timeout is for calls that took too long.
+	Timeout Code = "timeout"
+	// Canceled is for calls canceled by the client.
+	Canceled Code = "canceled"
+	// Internal is for other failures.
+	Internal Code = "internal"
+)
+
+// Error is the failure of a tool call.
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	// Retryable tells clients that the same call may succeed later.
+	Retryable bool `json:"retryable"`
+
+	err error
+	// format and args are the message before formatting, for Translate
+	format string
+	args   []interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// New returns an error with code and message, retryable for Unavailable and Timeout.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Retryable: code == Unavailable || code == Timeout, format: message}
+}
+
+// Wrap returns err with code, retryable for Unavailable and Timeout.
+func Wrap(code Code, err error) *Error {
+	e := New(code, err.Error())
+	e.err = err
+	return e
+}
+
+// Errorf returns an error with code and the message formatted as fmt.Errorf does,
+// wrapping the error of a %w verb.
+func Errorf(code Code, format string, a ...interface{}) *Error {
+	e := Wrap(code, fmt.Errorf(format, a...))
+	e.format, e.args = format, a
+	return e
+}
+
+// Translate returns err with its message translated, if err is an *Error created by
+// New or Errorf: translate returns the translation of its message, or of its format
+// for Errorf, which is then formatted with the same arguments.
+func Translate(err error, translate func(string) string) error {
+	e, ok := err.(*Error)
+	if !ok || e.format == "" {
+		return err
+	}
+	format := translate(e.format)
+	if format == e.format {
+		return err
+	}
+	message := format
+	if e.args != nil {
+		// %w only formats errors in fmt.Errorf
+		message = fmt.Sprintf(strings.ReplaceAll(format, "%w", "%v"), e.args...)
+	}
+	return &Error{Code: e.Code, Message: message, Retryable: e.Retryable, err: e}
+}
+
+// Classify returns err as an *Error: err itself if it is one, the code of the *Error
+// it wraps with its full message, or the code its cause suggests, Internal otherwise.
+func Classify(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		if e == err {
+			return e
+		}
+		return &Error{Code: e.Code, Message: err.Error(), Retryable: e.Retryable, err: err}
+	}
+	var paramErr *params.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &paramErr):
+		return Wrap(InvalidParams, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(Timeout, err)
+	case errors.Is(err, context.Canceled):
+		return Wrap(Canceled, err)
+	case errors.Is(err, fs.ErrNotExist):
+		return Wrap(NotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return Wrap(PermissionDenied, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Wrap(Timeout, err)
+	case errors.As(err, &netErr):
+		return Wrap(Unavailable, err)
+	}
+	return Wrap(Internal, err)
+}
+
+// Result returns the error result of err: its message as text, and the code, message
+// and retryable flag under error in the _meta of the result.
+func Result(err error) *mcp.CallToolResult {
+	e := Classify(err)
+	result := mcp.NewToolResultError(e.Message)
+	result.Meta = map[string]interface{}{"error": e}
+	return result
+}
@@ -0,0 +1,100 @@
+package toolerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/params"
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test that errors are classified by their cause
+func TestClassify(t *testing.T) {
+	_, statErr := os.Stat("does-not-exist")
+	tests := []struct {
+		name      string
+		err       error
+		code      Code
+		retryable bool
+	}{
+		{"tool error", New(NotFound, "item 1 not found"), NotFound, false},
+		{"wrapped tool error", fmt.Errorf("lookup: %w", New(PermissionDenied, "denied")), PermissionDenied, false},
+		{"params", &params.Error{Param: "num", Message: "is required"}, InvalidParams, false},
+		{"deadline", fmt.Errorf("request failed: %w", context.DeadlineExceeded), Timeout, true},
+		{"canceled", context.Canceled, Canceled, false},
+		{"missing file", statErr, NotFound, false},
+		{"permission", fmt.Errorf("open: %w", os.ErrPermission), PermissionDenied, false},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, Unavailable, true},
+		{"other", errors.New("boom"), Internal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Classify(tt.err)
+			assert.Equal(t, tt.code, e.Code)
+			assert.Equal(t, tt.retryable, e.Retryable)
+			assert.Equal(t, tt.err.Error(), e.Message)
+		})
+	}
+}
+
+// Test that Errorf keeps the errors it wraps
+func TestErrorf(t *testing.T) {
+	err := Errorf(InvalidParams, "invalid schema: %w", os.ErrInvalid)
+	assert.Equal(t, "invalid schema: invalid argument", err.Error())
+	assert.ErrorIs(t, err, os.ErrInvalid)
+	assert.Equal(t, InvalidParams, Classify(fmt.Errorf("validate: %w", err)).Code)
+}
+
+// Test translating the messages of tool errors
+func TestTranslate(t *testing.T) {
+	catalog := map[string]string{
+		"invalid cursor":          "잘못된 커서",
+		"invalid schema: %w":      "잘못된 스키마: %w",
+		"item %d of %s not found": "%[2]s의 항목 %[1]d이(가) 없습니다",
+	}
+	translate := func(msg string) string {
+		if translated, ok := catalog[msg]; ok {
+			return translated
+		}
+		return msg
+	}
+	assert.Equal(t, "잘못된 커서", Translate(New(InvalidParams, "invalid cursor"), translate).Error())
+	assert.Equal(t, "notes의 항목 3이(가) 없습니다", Translate(Errorf(NotFound, "item %d of %s not found", 3, "notes"), translate).Error())
+
+	err := Translate(Errorf(InvalidParams, "invalid schema: %w", os.ErrInvalid), translate)
+	assert.Equal(t, "잘못된 스키마: invalid argument", err.Error())
+	assert.ErrorIs(t, err, os.ErrInvalid)
+	assert.Equal(t, InvalidParams, Classify(err).Code)
+
+	untranslated := New(Internal, "boom")
+	assert.Same(t, untranslated, Translate(untranslated, translate))
+	wrapped := fmt.Errorf("lookup: %w", New(InvalidParams, "invalid cursor"))
+	assert.Same(t, wrapped, Translate(wrapped, translate), "Only tool errors should be translated")
+}
+
+// Test that results carry the message as text and the error in _meta
+func TestResult(t *testing.T) {
+	result := Result(New(Unavailable, "upstream returned 503"))
+	assert.True(t, result.IsError)
+	assert.Equal(t, "upstream returned 503", mcptest.ResultText(result))
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var decoded struct {
+		Meta struct {
+			Error map[string]interface{} `json:"error"`
+		} `json:"_meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]interface{}{
+		"code": "unavailable", "message": "upstream returned 503", "retryable": true,
+	}, decoded.Meta.Error)
+}
@@ -0,0 +1,131 @@
+// Package toolschema exports the tools of the servers with their JSON Schemas, as a
+// plain JSON document or an OpenAPI document, for generating typed clients and
+// documentation.
+package toolschema
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/mark3labs/mcphost/internal/buildinfo"
+	"github.com/mark3labs/mcphost/internal/servers"
+)
+
+// Server is a server and its tools.
+type Server struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Tools       []mcp.Tool `json:"tools"`
+	// Error says why the tools could not be listed, e.g. a missing setting.
+	Error string `json:"error,omitempty"`
+}
+
+// Document is the tools of servers, as listed by mcphost Version.
+type Document struct {
+	Version string   `json:"version"`
+	Servers []Server `json:"servers"`
+}
+
+// Export lists the tools of the servers, each created with its default flags.
+func Export(list []servers.Server) Document {
+	doc := Document{Version: buildinfo.Version, Servers: []Server{}}
+	for _, s := range list {
+		srv := Server{Name: s.Name, Description: s.Description, Tools: []mcp.Tool{}}
+		tools, err := servers.Tools(s)
+		if err != nil {
+			srv.Error = err.Error()
+		} else {
+			srv.Tools = tools
+		}
+		doc.Servers = append(doc.Servers, srv)
+	}
+	return doc
+}
+
+// callToolResult is the schema of the results of the tools.
+var callToolResult = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"content"},
+	"properties": map[string]interface{}{
+		"content": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"type"},
+				"properties": map[string]interface{}{
+					"type":     map[string]interface{}{"type": "string", "enum": []string{"text", "image", "resource"}},
+					"text":     map[string]interface{}{"type": "string"},
+					"data":     map[string]interface{}{"type": "string", "contentEncoding": "base64"},
+					"mimeType": map[string]interface{}{"type": "string"},
+					"resource": map[string]interface{}{"type": "object"},
+				},
+			},
+		},
+		"isError": map[string]interface{}{"type": "boolean"},
+		"_meta":   map[string]interface{}{"type": "object"},
+	},
+}
+
+// OpenAPI returns doc as an OpenAPI 3.1 document, with an operation per tool at
+// /{server}/{tool} taking the arguments of the tool as its JSON body. Servers whose
+// tools could not be listed are left out.
+func OpenAPI(doc Document) map[string]interface{} {
+	paths := make(map[string]interface{})
+	var tags []interface{}
+	for _, s := range doc.Servers {
+		if s.Error != "" {
+			continue
+		}
+		tags = append(tags, map[string]interface{}{"name": s.Name, "description": s.Description})
+		for _, tool := range s.Tools {
+			operation := map[string]interface{}{
+				"operationId": s.Name + "." + tool.Name,
+				"tags":        []string{s.Name},
+				"summary":     summary(tool.Description),
+				"description": tool.Description,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": tool.InputSchema},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Result of the tool; isError is set when the tool failed",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{"$ref": "#/components/schemas/CallToolResult"},
+							},
+						},
+					},
+				},
+			}
+			paths["/"+s.Name+"/"+tool.Name] = map[string]interface{}{"post": operation}
+		}
+	}
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "mcphost tools",
+			"description": "The tools of the MCP servers of mcphost. Each operation is a tool call, taking the arguments of the tool",
+			"version":     doc.Version,
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{"CallToolResult": callToolResult},
+		},
+	}
+}
+
+// summary returns the first sentence or line of a description.
+func summary(description string) string {
+	if i := strings.IndexByte(description, '\n'); i >= 0 {
+		description = description[:i]
+	}
+	if i := strings.Index(description, ". "); i >= 0 {
+		description = description[:i+1]
+	}
+	return strings.TrimSpace(description)
+}
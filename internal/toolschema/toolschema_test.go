@@ -0,0 +1,46 @@
+package toolschema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/transport"
+)
+
+// Test exporting the tools of servers, including one that cannot be created
+func TestExport(t *testing.T) {
+	timeServer, ok := servers.Lookup("time")
+	require.True(t, ok)
+	broken := servers.Server{
+		Name: "broken",
+		New: func(ctx context.Context, args []string) (*server.MCPServer, transport.Flags, error) {
+			return nil, transport.Flags{}, errors.New("no token")
+		},
+	}
+
+	doc := Export([]servers.Server{timeServer, broken})
+	require.Len(t, doc.Servers, 2)
+	assert.Equal(t, "time", doc.Servers[0].Name)
+	var names []string
+	for _, tool := range doc.Servers[0].Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "getCurrentTime")
+	assert.Equal(t, "no token", doc.Servers[1].Error)
+	assert.Empty(t, doc.Servers[1].Tools)
+
+	spec := OpenAPI(doc)
+	assert.Equal(t, "3.1.0", spec["openapi"])
+	paths := spec["paths"].(map[string]interface{})
+	require.Contains(t, paths, "/time/getCurrentTime")
+	assert.NotContains(t, paths, "/broken/getServerInfo")
+	operation := paths["/time/getCurrentTime"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, "time.getCurrentTime", operation["operationId"])
+	assert.Equal(t, "Returns the time for the specified timezone.", operation["summary"])
+}
@@ -0,0 +1,129 @@
+// Package tracing exports OpenTelemetry spans for tool calls and the outbound HTTP
+// requests made while handling them.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the tool call spans.
+const instrumentationName = "github.com/mark3labs/mcphost"
+
+var (
+	startOnce sync.Once
+	startErr  error
+	provider  *sdktrace.TracerProvider
+)
+
+// Enabled reports whether spans should be exported: an endpoint is given or set
+// through the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// variables.
+func Enabled(endpoint string) bool {
+	return endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Start exports spans over OTLP/HTTP to endpoint, a host:port or URL, or to the
+// endpoint configured by the standard OTEL_* variables if it is empty. Spans of the
+// process are attributed to serviceName unless OTEL_SERVICE_NAME is set. Outbound
+// requests through http.DefaultTransport are traced too, and trace context is
+// propagated in W3C traceparent headers.
+//
+// A process exports to one endpoint; calls after the first do nothing. The returned
+// function flushes and stops the export.
+func Start(ctx context.Context, serviceName, endpoint string, insecure bool) (func(context.Context) error, error) {
+	started := false
+	startOnce.Do(func() {
+		started = true
+		provider, startErr = newProvider(ctx, serviceName, endpoint, insecure)
+		if startErr != nil {
+			return
+		}
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		http.DefaultTransport = otelhttp.NewTransport(http.DefaultTransport)
+	})
+	if !started || startErr != nil {
+		return func(context.Context) error { return nil }, startErr
+	}
+	return provider.Shutdown, nil
+}
+
+func newProvider(ctx context.Context, serviceName, endpoint string, insecure bool) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	} else if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+// Extract returns ctx carrying the trace context in the headers of r, so spans of
+// requests from traced clients join their traces.
+func Extract(ctx context.Context, r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// Middleware returns tool middleware recording a span for each call to the tools of
+// the server called serverName. Handlers get the span in their context, so the
+// requests they make become its children.
+func Middleware(serverName string) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	tracer := otel.Tracer(instrumentationName)
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			attrs := []attribute.KeyValue{
+				attribute.String("mcp.server", serverName),
+				attribute.String("mcp.tool", req.Params.Name),
+			}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				attrs = append(attrs, attribute.String("mcp.session.id", session.SessionID()))
+			}
+			ctx, span := tracer.Start(ctx, "tools/call "+req.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			result, err := next(ctx, req)
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case result != nil && result.IsError:
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test the spans of tool calls and that they join the trace of the client
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	traced := Middleware("fetch")
+
+	r := httptest.NewRequest("POST", "/message", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), r)
+
+	var handlerSpan trace.SpanContext
+	_, err := traced(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})(ctx, mcptest.NewCallToolRequest("fetchURL", nil))
+	require.NoError(t, err)
+	_, err = traced(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})(context.Background(), mcptest.NewCallToolRequest("fetchURL", nil))
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "tools/call fetchURL", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("mcp.server", "fetch"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("mcp.tool", "fetchURL"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.False(t, spans[1].Parent().IsValid())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+}
+
+// Test that tracing is enabled by the endpoint flag or the OTLP variables
+func TestEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	assert.False(t, Enabled(""))
+	assert.True(t, Enabled("localhost:4318"))
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+	assert.True(t, Enabled(""))
+}
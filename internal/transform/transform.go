@@ -0,0 +1,292 @@
+// Package transform post-processes the results of tools following rules read from a
+// YAML or JSON file, so that operators can cut results down to what agents need
+// without changing the tools: truncating text, stripping fields from JSON, rendering
+// a Go template or redacting patterns.
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultReplacement replaces the text matched by redact patterns unless set.
+const DefaultReplacement = "[REDACTED]"
+
+// Config lists the transforms of tool results. They apply in order, each to the
+// results of the tools it matches.
+type Config struct {
+	Transforms []Transform `yaml:"transforms"`
+}
+
+// Transform is one operation on the text of results: exactly one of Truncate,
+// StripFields, Template and Redact is set.
+type Transform struct {
+	// Tools are tool name patterns such as fetchURL or search*; all tools when empty.
+	Tools []string `yaml:"tools"`
+
+	// Truncate cuts each text to this many characters.
+	Truncate int `yaml:"truncate"`
+	// StripFields removes fields from JSON texts, as dot separated paths in which *
+	// matches any key or array element, e.g. items.*.pagemap.
+	StripFields []string `yaml:"stripFields"`
+	// Template renders each text with a Go template, given the decoded JSON of the
+	// text, or the text itself if it is not JSON, as its data.
+	Template string `yaml:"template"`
+	// Redact replaces the text matching these regular expressions with Replacement.
+	// Unlike the other operations, it applies to error results too.
+	Redact      []string `yaml:"redact"`
+	Replacement string   `yaml:"replacement"`
+
+	fields   [][]string
+	template *template.Template
+	redact   []*regexp.Regexp
+}
+
+// Load reads and validates a transforms file.
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transforms file %s: %w", file, err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing transforms file %s: %w", file, err)
+	}
+	return c, nil
+}
+
+// templateFuncs are the functions of templates besides the built-in ones.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"truncate": func(n int, s string) string {
+		return truncate(s, n, "…")
+	},
+}
+
+// Parse decodes and validates YAML or JSON transforms.
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for i := range c.Transforms {
+		t := &c.Transforms[i]
+		ops := 0
+		for _, set := range []bool{t.Truncate != 0, len(t.StripFields) > 0, t.Template != "", len(t.Redact) > 0} {
+			if set {
+				ops++
+			}
+		}
+		if ops != 1 {
+			return nil, fmt.Errorf("transform %d: set exactly one of truncate, stripFields, template and redact", i+1)
+		}
+		for _, pattern := range t.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("transform %d: invalid tool pattern %q", i+1, pattern)
+			}
+		}
+		if t.Truncate < 0 {
+			return nil, fmt.Errorf("transform %d: truncate must be positive", i+1)
+		}
+		for _, field := range t.StripFields {
+			parts := strings.Split(field, ".")
+			for _, part := range parts {
+				if part == "" {
+					return nil, fmt.Errorf("transform %d: invalid field path %q", i+1, field)
+				}
+			}
+			t.fields = append(t.fields, parts)
+		}
+		if t.Template != "" {
+			tmpl, err := template.New(fmt.Sprintf("transform %d", i+1)).Funcs(templateFuncs).Option("missingkey=zero").Parse(t.Template)
+			if err != nil {
+				return nil, err
+			}
+			t.template = tmpl
+		}
+		for _, pattern := range t.Redact {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("transform %d: %w", i+1, err)
+			}
+			t.redact = append(t.redact, re)
+		}
+		if t.Replacement != "" && len(t.Redact) == 0 {
+			return nil, fmt.Errorf("transform %d: replacement applies to redact only", i+1)
+		}
+		if t.Replacement == "" {
+			t.Replacement = DefaultReplacement
+		}
+	}
+	return &c, nil
+}
+
+// matches reports whether t applies to tool.
+func (t *Transform) matches(tool string) bool {
+	if len(t.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range t.Tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns text transformed by t.
+func (t *Transform) apply(text string) (string, error) {
+	switch {
+	case t.Truncate > 0:
+		return truncate(text, t.Truncate, fmt.Sprintf("\n[Truncated to %d of %d characters]", t.Truncate, utf8.RuneCountInString(text))), nil
+	case t.fields != nil:
+		var v interface{}
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			// Only JSON texts have fields
+			return text, nil
+		}
+		for _, field := range t.fields {
+			v = strip(v, field)
+		}
+		data, err := json.Marshal(v)
+		return string(data), err
+	case t.template != nil:
+		var data interface{} = text
+		var v interface{}
+		if json.Unmarshal([]byte(text), &v) == nil {
+			data = v
+		}
+		var b strings.Builder
+		if err := t.template.Execute(&b, data); err != nil {
+			return text, err
+		}
+		return b.String(), nil
+	default:
+		for _, re := range t.redact {
+			text = re.ReplaceAllLiteralString(text, t.Replacement)
+		}
+		return text, nil
+	}
+}
+
+// truncate cuts s to n characters, adding notice if it was longer.
+func truncate(s string, n int, notice string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n]) + notice
+}
+
+// strip removes the field at path from v.
+func strip(v interface{}, path []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			if path[0] == "*" {
+				return map[string]interface{}{}
+			}
+			delete(v, path[0])
+			return v
+		}
+		for key, child := range v {
+			if path[0] == "*" || key == path[0] {
+				v[key] = strip(child, path[1:])
+			}
+		}
+		return v
+	case []interface{}:
+		if path[0] != "*" {
+			return v
+		}
+		if len(path) == 1 {
+			return []interface{}{}
+		}
+		for i, child := range v {
+			v[i] = strip(child, path[1:])
+		}
+		return v
+	}
+	return v
+}
+
+// Apply returns result transformed for tool. Results are copied, not modified; a
+// transform failing on a text, such as a template referring to a missing method,
+// leaves the text as it was and is logged.
+func (c *Config) Apply(tool string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	var transforms []*Transform
+	for i := range c.Transforms {
+		t := &c.Transforms[i]
+		if t.matches(tool) && (!result.IsError || t.redact != nil) {
+			transforms = append(transforms, t)
+		}
+	}
+	if len(transforms) == 0 {
+		return result
+	}
+	transformed := &mcp.CallToolResult{Result: result.Result, IsError: result.IsError}
+	for _, content := range result.Content {
+		text, ok := textOf(content)
+		if !ok {
+			transformed.Content = append(transformed.Content, content)
+			continue
+		}
+		for _, t := range transforms {
+			out, err := t.apply(text)
+			if err != nil {
+				log.Printf("Warning: Failed to transform the result of %s: %v", tool, err)
+				continue
+			}
+			text = out
+		}
+		transformed.Content = append(transformed.Content, mcp.NewTextContent(text))
+	}
+	return transformed
+}
+
+// textOf returns the text of a text content.
+func textOf(content mcp.Content) (string, bool) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, true
+	case *mcp.TextContent:
+		return c.Text, true
+	}
+	return "", false
+}
+
+// Middleware returns tool middleware transforming the results of the tools.
+func (c *Config) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, req)
+			if err != nil {
+				return result, err
+			}
+			return c.Apply(req.Params.Name, result), nil
+		}
+	}
+}
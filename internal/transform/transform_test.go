@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+const testTransforms = `
+transforms:
+  - tools: [search*]
+    stripFields: [items.*.pagemap, queries]
+  - tools: [getWeather]
+    template: "{{.name}}: {{.temp}}°C"
+  - tools: [fetchURL]
+    truncate: 5
+  - redact: ['\b\d{3}-\d{2}-\d{4}\b', 'key=\w+']
+`
+
+// Test that matching transforms apply in order
+func TestApply(t *testing.T) {
+	c, err := Parse([]byte(testTransforms))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		tool   string
+		text   string
+		isErr  bool
+		expect string
+	}{
+		{
+			name:   "Strip fields",
+			tool:   "searchGoogle",
+			text:   `{"items":[{"title":"a","pagemap":{"x":1}},{"title":"b"}],"queries":{},"total":2}`,
+			expect: `{"items":[{"title":"a"},{"title":"b"}],"total":2}`,
+		},
+		{name: "Strip fields of text", tool: "searchGoogle", text: "not JSON", expect: "not JSON"},
+		{name: "Template", tool: "getWeather", text: `{"name":"Paris","temp":21.5}`, expect: "Paris: 21.5°C"},
+		{name: "Truncate", tool: "fetchURL", text: "héllo world", expect: "héllo\n[Truncated to 5 of 11 characters]"},
+		{name: "Short text", tool: "fetchURL", text: "hi", expect: "hi"},
+		{name: "Redact", tool: "getTime", text: "SSN 123-45-6789, url ?key=abc", expect: "SSN [REDACTED], url ?[REDACTED]"},
+		{name: "Redact error", tool: "fetchURL", text: "failed with key=abc", isErr: true, expect: "failed with [REDACTED]"},
+		{name: "Truncate and redact", tool: "fetchURL", text: "key=abcdef", expect: "[REDACTED]\n[Truncated to 5 of 10 characters]"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := mcp.NewToolResultText(tc.text)
+			result.IsError = tc.isErr
+			transformed := c.Apply(tc.tool, result)
+			assert.Equal(t, tc.expect, mcptest.ResultText(transformed))
+			assert.Equal(t, tc.isErr, transformed.IsError)
+			assert.Equal(t, tc.text, mcptest.ResultText(result), "the result must not be modified")
+		})
+	}
+}
+
+// Test that the middleware transforms results and keeps other contents
+func TestMiddleware(t *testing.T) {
+	c, err := Parse([]byte("transforms:\n  - truncate: 3\n    replacement: unused\n"))
+	require.Error(t, err, "replacement is for redact only")
+
+	c, err = Parse([]byte("transforms:\n  - truncate: 3\n"))
+	require.NoError(t, err)
+	handler := c.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			mcp.NewTextContent("abcdef"),
+			mcp.NewImageContent("aW1hZ2U=", "image/png"),
+		}}, nil
+	})
+	result, err := handler(context.Background(), mcptest.NewCallToolRequest("captureScreen", nil))
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "abc\n[Truncated to 3 of 6 characters]", result.Content[0].(mcp.TextContent).Text)
+	assert.IsType(t, mcp.ImageContent{}, result.Content[1])
+}
+
+// Test that invalid transforms are refused
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{name: "No operation", config: "transforms:\n  - tools: [a]\n", err: "transform 1: set exactly one"},
+		{name: "Two operations", config: "transforms:\n  - truncate: 3\n    redact: [a]\n", err: "transform 1: set exactly one"},
+		{name: "Unknown field", config: "transforms:\n  - truncat: 3\n", err: "field truncat not found"},
+		{name: "Negative truncate", config: "transforms:\n  - truncate: -1\n", err: "truncate must be positive"},
+		{name: "Bad pattern", config: "transforms:\n  - tools: ['[']\n    truncate: 1\n", err: "invalid tool pattern"},
+		{name: "Bad path", config: "transforms:\n  - stripFields: [a..b]\n", err: `invalid field path "a..b"`},
+		{name: "Bad template", config: "transforms:\n  - template: '{{.a'\n", err: "transform 1"},
+		{name: "Bad regexp", config: "transforms:\n  - redact: ['(']\n", err: "transform 1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// methodCancelled is the notification of a client giving up on one of its requests.
+const methodCancelled = "notifications/cancelled"
+
+// maxTimeout bounds the time a client may give a request, so that huge values do not
+// overflow a time.Duration.
+const maxTimeout = 24 * time.Hour
+
+// message holds the fields of a JSON-RPC message needed to track requests.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// call identifies a request of a client session.
+type call struct {
+	session string
+	id      string
+}
+
+// running is the state of a request being handled.
+type running struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// calls tracks the requests being handled for clients, so that cancelling one, with
+// notifications/cancelled, cancels the context of its handler.
+type calls struct {
+	mu      sync.Mutex
+	running map[call]*running
+}
+
+// timeout returns the time the client gives request m, as timeoutMs in its _meta, or
+// 0 if it sets none.
+func (m message) timeout() time.Duration {
+	var params struct {
+		Meta struct {
+			TimeoutMs float64 `json:"timeoutMs"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(m.Params, &params); err != nil || params.Meta.TimeoutMs <= 0 {
+		return 0
+	}
+	return time.Duration(min(params.Meta.TimeoutMs, float64(maxTimeout/time.Millisecond)) * float64(time.Millisecond))
+}
+
+// start returns the context to handle request m of the session with, and a function to
+// call once it is handled, which reports whether the client cancelled the request. The
+// time the client gives the request is the deadline of the context.
+func (c *calls) start(ctx context.Context, session string, m message) (context.Context, func() bool) {
+	var cancel context.CancelFunc
+	if timeout := m.timeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	key := call{session: session, id: string(bytes.TrimSpace(m.ID))}
+	r := &running{cancel: cancel}
+
+	c.mu.Lock()
+	if c.running == nil {
+		c.running = make(map[call]*running)
+	}
+	c.running[key] = r
+	c.mu.Unlock()
+
+	return ctx, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.running[key] == r {
+			delete(c.running, key)
+		}
+		cancel()
+		return r.cancelled
+	}
+}
+
+// cancel cancels the request that notification m of the session is about, if it is
+// still being handled.
+func (c *calls) cancel(session string, m message) {
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+		Reason    string          `json:"reason"`
+	}
+	if err := json.Unmarshal(m.Params, &params); err != nil || params.RequestID == nil {
+		return
+	}
+	key := call{session: session, id: string(bytes.TrimSpace(params.RequestID))}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.running[key]
+	if !ok {
+		return
+	}
+	log.Printf("Request %s cancelled by the client: %s", key.id, params.Reason)
+	r.cancelled = true
+	r.cancel()
+}
+
+// cancelHandler handles the messages POSTed by the SSE clients of h, cancelling
+// their requests on notifications/cancelled.
+func cancelHandler(c *calls, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.URL.Query().Get("sessionId")
+		if r.Method != http.MethodPost || sessionID == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, "Failed to read the request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var m message
+		if err := json.Unmarshal(body, &m); err == nil {
+			switch {
+			case m.Method == methodCancelled:
+				c.cancel(sessionID, m)
+			case m.ID != nil:
+				ctx, done := c.start(r.Context(), sessionID, m)
+				defer done()
+				r = r.WithContext(ctx)
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
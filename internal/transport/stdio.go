@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/resources"
+)
+
+// session is the session of the client of a stdio or WebSocket connection.
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *session) SessionID() string                                   { return s.id }
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *session) Initialize()                                         { s.initialized.Store(true) }
+func (s *session) Initialized() bool                                   { return s.initialized.Load() }
+
+// ListenStdio serves s to the client writing to in and reading out, until in is closed
+// or ctx is cancelled, e.g. over pipes to drive a server in process as its clients
+// would. Unlike with the stdio server of mcp-go, requests are handled concurrently, so
+// that pings and cancellations are answered while tools run. Once in is closed, the
+// running requests are answered before ListenStdio returns.
+func ListenStdio(ctx context.Context, s *server.MCPServer, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	read := func() ([]byte, error) {
+		line, err := reader.ReadString('\n')
+		return []byte(line), err
+	}
+	write := func(data []byte) error {
+		_, err := fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+	return listen(ctx, s, stdioSessionID, read, write)
+}
+
+// listen serves s to the client of session id, whose messages are returned by read
+// and written with write, until read fails or ctx is cancelled. Requests are handled
+// concurrently, and answered before listen returns once read reaches io.EOF.
+func listen(ctx context.Context, s *server.MCPServer, id string, read func() ([]byte, error), write func([]byte) error) error {
+	session := &session{id: id, notifications: make(chan mcp.JSONRPCNotification, 100)}
+	if err := s.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer s.UnregisterSession(id)
+	ctx = s.WithContext(ctx, session)
+
+	var mu sync.Mutex
+	send := func(message mcp.JSONRPCMessage) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Error: Failed to encode a message: %v", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err := write(data); err != nil {
+			log.Printf("Error: Failed to write a message: %v", err)
+		}
+	}
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				send(notification)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			message, err := read()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var calls calls
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		var data []byte
+		select {
+		case data = <-messages:
+		case err := <-readErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		data = resources.Route(s, id, data)
+		var m message
+		if err := json.Unmarshal(data, &m); err != nil {
+			send(mcp.JSONRPCError{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				Error: struct {
+					Code    int         `json:"code"`
+					Message string      `json:"message"`
+					Data    interface{} `json:"data,omitempty"`
+				}{Code: mcp.PARSE_ERROR, Message: "Parse error"},
+			})
+			continue
+		}
+		switch {
+		case m.Method == methodCancelled:
+			calls.cancel(id, m)
+		case m.ID == nil || m.Method == string(mcp.MethodInitialize):
+			// Notifications and the handshake are handled in order
+			if response := s.HandleMessage(ctx, data); response != nil {
+				send(response)
+			}
+		default:
+			callCtx, done := calls.start(ctx, id, m)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				response := s.HandleMessage(callCtx, data)
+				// Cancelled requests are not answered
+				if !done() && response != nil {
+					send(response)
+				}
+			}()
+		}
+	}
+}
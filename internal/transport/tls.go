@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig builds the TLS configuration of a listener from a certificate file pair or
+// Let's Encrypt. It returns nil when TLS is not configured.
+func (f Flags) TLSConfig() (*tls.Config, error) {
+	var domains []string
+	for _, domain := range strings.Split(f.ACMEDomains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	var config *tls.Config
+	switch {
+	case len(domains) > 0 && (f.TLSCert != "" || f.TLSKey != ""):
+		return nil, errors.New("use either -acme-domains or -tls-cert and -tls-key, not both")
+	case len(domains) > 0:
+		cache := f.ACMECache
+		if cache == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("error getting home directory: %w", err)
+			}
+			cache = filepath.Join(homeDir, ".mcphost", "acme")
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Cache:      autocert.DirCache(cache),
+			Email:      f.ACMEEmail,
+		}
+		config = manager.TLSConfig()
+	case f.TLSCert != "" || f.TLSKey != "":
+		if f.TLSCert == "" || f.TLSKey == "" {
+			return nil, errors.New("-tls-cert and -tls-key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(f.TLSCert, f.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		config = &tls.Config{Certificates: []tls.Certificate{cert}}
+	default:
+		if f.TLSClientCA != "" {
+			return nil, errors.New("-tls-client-ca requires -tls-cert and -tls-key or -acme-domains")
+		}
+		return nil, nil
+	}
+	config.MinVersion = tls.VersionTLS12
+
+	if f.TLSClientCA != "" {
+		data, err := os.ReadFile(f.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", f.TLSClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		if len(domains) > 0 {
+			// Let's Encrypt validates the domains with TLS-ALPN-01 handshakes, which come
+			// without a client certificate
+			challenge := config.Clone()
+			challenge.ClientAuth = tls.NoClientCert
+			challenge.ClientCAs = nil
+			config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+				if slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+					return challenge, nil
+				}
+				return nil, nil
+			}
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert is a certificate and key, signed by parent or else self-signed.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write stores the certificate and key as PEM files and returns their paths.
+func (c *testCert) write(t *testing.T, name string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, name+".pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0644))
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certPath, keyPath
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags Flags
+		err   string
+	}{
+		{"cert without key", Flags{TLSCert: "cert.pem"}, "must be set together"},
+		{"acme and cert", Flags{ACMEDomains: "example.com", TLSCert: "cert.pem", TLSKey: "key.pem"}, "not both"},
+		{"client CA without TLS", Flags{TLSClientCA: "ca.pem"}, "-tls-client-ca requires"},
+		{"missing files", Flags{TLSCert: "missing.pem", TLSKey: "missing-key.pem"}, "failed to load TLS certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.flags.TLSConfig()
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	config, err := Flags{}.TLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = Flags{ACMEDomains: "example.com, mcp.example.com", ACMECache: t.TempDir()}.TLSConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, config.GetCertificate)
+	assert.Contains(t, config.NextProtos, "acme-tls/1")
+}
+
+func TestTLSConfigACMEWithClientCA(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	caPath, _ := ca.write(t, "ca")
+
+	config, err := Flags{ACMEDomains: "example.com", ACMECache: t.TempDir(), TLSClientCA: caPath}.TLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	require.NotNil(t, config.GetConfigForClient)
+
+	// TLS-ALPN-01 challenges skip client authentication
+	challenge, err := config.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"acme-tls/1"}})
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.Equal(t, tls.NoClientCert, challenge.ClientAuth)
+	assert.NotNil(t, challenge.GetCertificate)
+
+	// Other clients keep the mTLS configuration
+	other, err := config.GetConfigForClient(&tls.ClientHelloInfo{SupportedProtos: []string{"h2", "http/1.1"}})
+	require.NoError(t, err)
+	assert.Nil(t, other)
+}
+
+func TestServeSSEWithMutualTLS(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	certPath, keyPath := serverCert.write(t, "server")
+	caPath, _ := ca.write(t, "ca")
+	config, err := Flags{TLSCert: certPath, TLSKey: keyPath, TLSClientCA: caPath}.TLSConfig()
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveSSE(ctx, server.NewMCPServer("test", "1.0.0"), tls.NewListener(ln, config), "", time.Second)
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs []tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+		// A message without a session is refused, which proves the request got through
+		return client.Post("https://"+ln.Addr().String()+"/message", "application/json", nil)
+	}
+
+	_, err = get(nil)
+	assert.Error(t, err, "a client without a certificate must be rejected")
+
+	resp, err := get([]tls.Certificate{clientCert.tlsCertificate()})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatal("server did not stop")
+	}
+}
@@ -0,0 +1,209 @@
+// Package transport serves an MCP server over stdio or, for remote clients, over
+// HTTP with server-sent events or over WebSocket, which browsers can open directly.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/identity"
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/tracing"
+)
+
+// Supported transports.
+const (
+	Stdio     = "stdio"
+	SSE       = "sse"
+	WebSocket = "websocket"
+)
+
+// shutdownTimeout bounds closing the connections once the tool calls are drained.
+const shutdownTimeout = 5 * time.Second
+
+// stdioSessionID is the ID of the session of a stdio client, the one mcp-go uses.
+const stdioSessionID = "stdio"
+
+// Flags are the transport flags shared by the servers.
+type Flags struct {
+	Transport    string
+	Listen       string
+	BaseURL      string
+	Origins      string
+	DrainTimeout time.Duration
+
+	TLSCert     string
+	TLSKey      string
+	TLSClientCA string
+	ACMEDomains string
+	ACMEEmail   string
+	ACMECache   string
+}
+
+// Register defines the transport flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Transport, "transport", Stdio, "Transport to serve MCP over: stdio, sse or websocket")
+	fs.StringVar(&f.Listen, "listen", ":8080", "Address the SSE and WebSocket transports listen on")
+	fs.StringVar(&f.BaseURL, "base-url", "", "Public URL of the SSE transport, e.g. when behind a proxy (default: relative message endpoint)")
+	fs.StringVar(&f.Origins, "allowed-origins", "", "Comma separated origins of the web pages that may open WebSocket connections, e.g. https://app.example.com, or * for any (default: the server's own)")
+	fs.DurationVar(&f.DrainTimeout, "drain-timeout", 30*time.Second, "How long running tool calls may take to finish when the server is interrupted")
+	f.RegisterTLS(fs)
+}
+
+// RegisterTLS defines only the TLS flags on fs, for HTTP listeners other than the SSE
+// transport.
+func (f *Flags) RegisterTLS(fs *flag.FlagSet) {
+	fs.StringVar(&f.TLSCert, "tls-cert", "", "TLS certificate file; serves the SSE transport over HTTPS together with -tls-key")
+	fs.StringVar(&f.TLSKey, "tls-key", "", "TLS private key file")
+	fs.StringVar(&f.TLSClientCA, "tls-client-ca", "", "CA certificate file; clients must present a certificate signed by it (mTLS)")
+	fs.StringVar(&f.ACMEDomains, "acme-domains", "", "Comma separated domains to obtain Let's Encrypt certificates for; the listener must be reachable on port 443")
+	fs.StringVar(&f.ACMEEmail, "acme-email", "", "Contact email for the Let's Encrypt account")
+	fs.StringVar(&f.ACMECache, "acme-cache", "", "Directory certificates from Let's Encrypt are kept in (default: ~/.mcphost/acme)")
+}
+
+// Serve serves s over the transport selected by f until the client disconnects, for
+// stdio, or until the process is interrupted. When interrupted by SIGINT or SIGTERM,
+// new tool calls are refused and the running ones get f.DrainTimeout to finish; Serve
+// fails if some do not.
+func Serve(s *server.MCPServer, f Flags) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// The servers' HTTP clients share the default transport
+	defer http.DefaultClient.CloseIdleConnections()
+
+	switch f.Transport {
+	case Stdio:
+		return serveStdio(ctx, s, os.Stdin, os.Stdout, f.DrainTimeout)
+	case SSE, WebSocket:
+		tlsConfig, err := f.TLSConfig()
+		if err != nil {
+			return err
+		}
+		ln, err := net.Listen("tcp", f.Listen)
+		if err != nil {
+			return err
+		}
+		name := map[string]string{SSE: "SSE", WebSocket: "WebSocket"}[f.Transport]
+		if tlsConfig != nil {
+			ln = tls.NewListener(ln, tlsConfig)
+			log.Printf("Serving %s over HTTPS on %s", name, ln.Addr())
+		} else {
+			log.Printf("Serving %s on %s", name, ln.Addr())
+		}
+		if f.Transport == WebSocket {
+			return serveWebSocket(ctx, s, ln, splitOrigins(f.Origins), f.DrainTimeout)
+		}
+		return serveSSE(ctx, s, ln, f.BaseURL, f.DrainTimeout)
+	default:
+		return fmt.Errorf("unsupported transport %q; use stdio, sse or websocket", f.Transport)
+	}
+}
+
+// splitOrigins splits a comma separated list of origins.
+func splitOrigins(s string) []string {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// drain refuses new tool calls of s and waits up to timeout for the running ones.
+func drain(s *server.MCPServer, timeout time.Duration) error {
+	log.Printf("Shutting down, waiting up to %s for running tool calls", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := middleware.Drain(ctx, s); err != nil {
+		return fmt.Errorf("shutdown: %w after %s", err, timeout)
+	}
+	return nil
+}
+
+// serveStdio serves s on in and out until in is closed or ctx is cancelled.
+func serveStdio(ctx context.Context, s *server.MCPServer, in io.Reader, out io.Writer, drainTimeout time.Duration) error {
+	// Tool calls get listenCtx, which stays alive until they are drained
+	listenCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ListenStdio(listenCtx, s, in, out)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	err := drain(s, drainTimeout)
+	cancel()
+	select {
+	case <-errc:
+	case <-time.After(shutdownTimeout):
+	}
+	return err
+}
+
+// serveSSE serves s on ln until ctx is cancelled.
+func serveSSE(ctx context.Context, s *server.MCPServer, ln net.Listener, baseURL string, drainTimeout time.Duration) error {
+	// Request contexts derive from connCtx, so cancelling it ends the open SSE streams
+	connCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := []server.SSEOption{server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+		return identity.FromRequest(tracing.Extract(ctx, r), r)
+	})}
+	if baseURL != "" {
+		opts = append(opts, server.WithBaseURL(baseURL))
+	} else {
+		opts = append(opts, server.WithUseFullURLForMessageEndpoint(false))
+	}
+	httpServer := &http.Server{
+		Handler:     health.Handler(s, resources.Handler(s, cancelHandler(&calls{}, server.NewSSEServer(s, opts...)))),
+		BaseContext: func(net.Listener) context.Context { return connCtx },
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.Serve(ln)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// The SSE streams stay open until the results of the running calls are sent
+	drainErr := drain(s, drainTimeout)
+	cancel()
+	shutdownCtx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer done()
+	if err := httpServer.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+		// Connections that never sent a request hold up Shutdown; drop them
+		httpServer.Close()
+	} else if err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return drainErr
+}
@@ -0,0 +1,297 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/resources"
+)
+
+func TestRegister(t *testing.T) {
+	var f Flags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Register(fs)
+	assert.Equal(t, Flags{Transport: Stdio, Listen: ":8080", DrainTimeout: 30 * time.Second}, f)
+
+	require.NoError(t, fs.Parse([]string{"-transport=sse", "-listen", "127.0.0.1:9000", "-base-url", "https://mcp.example.com", "-drain-timeout", "5s"}))
+	assert.Equal(t, Flags{Transport: SSE, Listen: "127.0.0.1:9000", BaseURL: "https://mcp.example.com", DrainTimeout: 5 * time.Second}, f)
+}
+
+func TestServeUnsupportedTransport(t *testing.T) {
+	err := Serve(server.NewMCPServer("test", "1.0.0"), Flags{Transport: "grpc"})
+	assert.ErrorContains(t, err, `unsupported transport "grpc"`)
+}
+
+func TestServeSSE(t *testing.T) {
+	s := server.NewMCPServer("test-server", "1.0.0")
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(req.Params.Arguments["text"].(string)), nil
+		})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveSSE(ctx, s, ln, "", time.Second)
+	}()
+
+	c, err := client.NewSSEMCPClient("http://" + ln.Addr().String() + "/sse")
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Start(context.Background()))
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0.0"}
+	result, err := c.Initialize(context.Background(), initRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "test-server", result.ServerInfo.Name)
+
+	callRequest := mcp.CallToolRequest{}
+	callRequest.Params.Name = "echo"
+	callRequest.Params.Arguments = map[string]interface{}{"text": "hello"}
+	callResult, err := c.CallTool(context.Background(), callRequest)
+	require.NoError(t, err)
+	require.Len(t, callResult.Content, 1)
+	assert.Equal(t, "hello", callResult.Content[0].(mcp.TextContent).Text)
+
+	// Stopping the server ends the open SSE stream
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatal("server did not stop")
+	}
+}
+
+// Test that interrupting a stdio server lets the running tool call finish, refuses new
+// ones and fails if a call outlives the drain timeout
+func TestServeStdioDrains(t *testing.T) {
+	s := server.NewMCPServer("test-server", "1.0.0")
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	middleware.AddTool(s, mcp.NewTool("wait"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return mcp.NewToolResultText("done"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	serve := func(drainTimeout time.Duration) (context.CancelFunc, *io.PipeWriter, *bufio.Reader, chan error) {
+		inReader, in := io.Pipe()
+		outReader, out := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- serveStdio(ctx, s, inReader, out, drainTimeout)
+		}()
+		return cancel, in, bufio.NewReader(outReader), done
+	}
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}}` + "\n"
+
+	interrupt, in, out, done := serve(time.Minute)
+	_, err := io.WriteString(in, call)
+	require.NoError(t, err)
+	<-started
+	interrupt()
+	close(release)
+	line, err := out.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"text":"done"`)
+	assert.NoError(t, <-done)
+
+	// Later calls are refused
+	response, err := json.Marshal(s.HandleMessage(context.Background(), []byte(call)))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "The server is shutting down")
+
+	// A call outliving the drain timeout is cancelled
+	s = server.NewMCPServer("test-server", "1.0.0")
+	release = make(chan struct{})
+	middleware.AddTool(s, mcp.NewTool("wait"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	interrupt, in, out, done = serve(10 * time.Millisecond)
+	_, err = io.WriteString(in, call)
+	require.NoError(t, err)
+	<-started
+	go out.ReadString('\n')
+	interrupt()
+	assert.ErrorContains(t, <-done, "1 tool calls still running")
+}
+
+// Test that stdio clients can subscribe to resources and are notified of updates
+func TestServeStdioSubscriptions(t *testing.T) {
+	s := resources.NewMCPServer("test-server", "1.0.0")
+	inReader, in := io.Pipe()
+	outReader, out := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStdio(ctx, s, inReader, out, time.Second)
+	}()
+	lines := bufio.NewReader(outReader)
+
+	_, err := io.WriteString(in, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`+"\n")
+	require.NoError(t, err)
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"resources":{"subscribe":true}`)
+
+	_, err = io.WriteString(in, `{"jsonrpc":"2.0","method":"notifications/initialized"}`+"\n"+
+		`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"test://x"}}`+"\n")
+	require.NoError(t, err)
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, line)
+
+	resources.Updated(context.Background(), s, "test://x")
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"test://x"}}`, line)
+
+	in.Close()
+	cancel()
+	<-done
+}
+
+// Test that stdio requests run concurrently and that cancelled calls abort promptly
+func TestServeStdioCancel(t *testing.T) {
+	s := server.NewMCPServer("test-server", "1.0.0")
+	aborted := make(chan time.Time, 1)
+	middleware.AddTool(s, mcp.NewTool("wait"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		aborted <- time.Now()
+		return nil, ctx.Err()
+	})
+	inReader, in := io.Pipe()
+	outReader, out := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStdio(ctx, s, inReader, out, time.Second)
+	}()
+	lines := bufio.NewReader(outReader)
+
+	// A ping is answered while the call runs
+	_, err := io.WriteString(in, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}}`+"\n"+
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n")
+	require.NoError(t, err)
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, line)
+
+	cancelled := time.Now()
+	_, err = io.WriteString(in, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"user"}}`+"\n")
+	require.NoError(t, err)
+	select {
+	case at := <-aborted:
+		assert.Less(t, at.Sub(cancelled), time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not cancelled")
+	}
+
+	// The cancelled call is not answered
+	_, err = io.WriteString(in, `{"jsonrpc":"2.0","id":3,"method":"ping"}`+"\n")
+	require.NoError(t, err)
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":{}}`, line)
+
+	in.Close()
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// Test that the time a client gives a call is the deadline of its handler
+func TestServeStdioTimeout(t *testing.T) {
+	s := server.NewMCPServer("test-server", "1.0.0")
+	middleware.AddTool(s, mcp.NewTool("wait"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return mcp.NewToolResultText("no deadline"), nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	inReader, in := io.Pipe()
+	outReader, out := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStdio(ctx, s, inReader, out, time.Second)
+	}()
+	lines := bufio.NewReader(outReader)
+
+	_, err := io.WriteString(in, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait","_meta":{"timeoutMs":50}}}`+"\n")
+	require.NoError(t, err)
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"code":"timeout"`)
+
+	_, err = io.WriteString(in, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait"}}`+"\n")
+	require.NoError(t, err)
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, "no deadline")
+
+	in.Close()
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// Test that cancelling the request of an SSE client cancels its handler
+func TestCancelHandler(t *testing.T) {
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	h := cancelHandler(&calls{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "" {
+			close(started)
+			<-r.Context().Done()
+			close(aborted)
+		}
+	}))
+	post := func(session, body string) {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/message?sessionId="+session, strings.NewReader(body)))
+	}
+	go post("a&wait=1", `{"jsonrpc":"2.0","id":"call","method":"tools/call","params":{"name":"wait"}}`)
+	<-started
+
+	// Only the request of the same session and ID is cancelled
+	post("a", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"other"}}`)
+	post("b", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call"}}`)
+	select {
+	case <-aborted:
+		t.Fatal("the wrong request was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	post("a", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call"}}`)
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("the call was not cancelled")
+	}
+}
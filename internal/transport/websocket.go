@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/net/websocket"
+
+	"github.com/mark3labs/mcphost/internal/health"
+	"github.com/mark3labs/mcphost/internal/identity"
+	"github.com/mark3labs/mcphost/internal/resources"
+	"github.com/mark3labs/mcphost/internal/tracing"
+)
+
+// WebSocketPath is the path clients open WebSocket connections on.
+const WebSocketPath = "/ws"
+
+// checkOrigin returns the handshake check of the Origin header browsers send: the
+// connections of pages served by the host of the server itself and by origins, or by
+// any if origins has *, are accepted. Other clients send no Origin header and are
+// accepted.
+func checkOrigin(origins []string) func(*websocket.Config, *http.Request) error {
+	return func(config *websocket.Config, r *http.Request) error {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return nil
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return fmt.Errorf("invalid origin %q", origin)
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return nil
+		}
+		for _, allowed := range origins {
+			if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+				return nil
+			}
+		}
+		log.Printf("Refused a WebSocket connection from origin %s", origin)
+		return fmt.Errorf("origin %s is not allowed", origin)
+	}
+}
+
+// newSessionID returns a random ID for the session of a WebSocket connection.
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "ws-" + hex.EncodeToString(b)
+}
+
+// webSocketHandler serves s to the clients of WebSocket connections, one session per
+// connection whose messages are JSON-RPC messages, until ctx is cancelled. wg tracks
+// the connections.
+func webSocketHandler(ctx context.Context, s *server.MCPServer, origins []string, wg *sync.WaitGroup) http.Handler {
+	ws := websocket.Server{
+		Handshake: checkOrigin(origins),
+		Handler: func(conn *websocket.Conn) {
+			wg.Add(1)
+			defer wg.Done()
+			r := conn.Request()
+			connCtx := identity.FromRequest(tracing.Extract(ctx, r), r)
+			id := newSessionID()
+			read := func() ([]byte, error) {
+				var data []byte
+				err := websocket.Message.Receive(conn, &data)
+				return data, err
+			}
+			write := func(data []byte) error {
+				return websocket.Message.Send(conn, string(data))
+			}
+			log.Printf("WebSocket session %s opened from %s", id, r.RemoteAddr)
+			if err := listen(connCtx, s, id, read, write); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("WebSocket session %s failed: %v", id, err)
+			}
+			log.Printf("WebSocket session %s closed", id)
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != WebSocketPath {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			w.Header().Set("Upgrade", "websocket")
+			http.Error(w, "expected a WebSocket connection", http.StatusUpgradeRequired)
+			return
+		}
+		ws.ServeHTTP(w, r)
+	})
+}
+
+// serveWebSocket serves s over WebSocket on ln until ctx is cancelled, accepting the
+// connections of browsers from origins besides the host of the server.
+func serveWebSocket(ctx context.Context, s *server.MCPServer, ln net.Listener, origins []string, drainTimeout time.Duration) error {
+	// Sessions derive from connCtx, so cancelling it closes the open connections
+	connCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sessions sync.WaitGroup
+	httpServer := &http.Server{
+		Handler:           health.Handler(s, resources.Handler(s, webSocketHandler(connCtx, s, origins, &sessions))),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.Serve(ln)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// The connections stay open until the results of the running calls are sent
+	drainErr := drain(s, drainTimeout)
+	cancel()
+	shutdownCtx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer done()
+	if err := httpServer.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+		httpServer.Close()
+	} else if err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	// Hijacked connections are not tracked by Shutdown
+	closed := make(chan struct{})
+	go func() {
+		sessions.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-shutdownCtx.Done():
+	}
+	return drainErr
+}
@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// exchange sends request over conn and returns the message it receives next.
+func exchange(t *testing.T, conn *websocket.Conn, request string) map[string]interface{} {
+	t.Helper()
+	require.NoError(t, websocket.Message.Send(conn, request))
+	var data []byte
+	require.NoError(t, websocket.Message.Receive(conn, &data))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &response))
+	return response
+}
+
+func TestServeWebSocket(t *testing.T) {
+	s := server.NewMCPServer("test-server", "1.0.0")
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(req.Params.Arguments["text"].(string)), nil
+		})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveWebSocket(ctx, s, ln, []string{"https://app.example.com"}, time.Second)
+	}()
+
+	// Each connection is a session of its own
+	var conns []*websocket.Conn
+	for _, origin := range []string{"http://" + addr, "https://app.example.com"} {
+		conn, err := websocket.Dial("ws://"+addr+WebSocketPath, "", origin)
+		require.NoError(t, err)
+		defer conn.Close()
+		conns = append(conns, conn)
+
+		response := exchange(t, conn, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`)
+		assert.Equal(t, "test-server", response["result"].(map[string]interface{})["serverInfo"].(map[string]interface{})["name"])
+	}
+	response := exchange(t, conns[1], `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hello"}}}`)
+	content := response["result"].(map[string]interface{})["content"].([]interface{})
+	assert.Equal(t, "hello", content[0].(map[string]interface{})["text"])
+	response = exchange(t, conns[0], `not json`)
+	assert.Equal(t, "Parse error", response["error"].(map[string]interface{})["message"])
+
+	// Pages of other sites may not connect
+	_, err = websocket.Dial("ws://"+addr+WebSocketPath, "", "https://evil.example.com")
+	assert.ErrorContains(t, err, "bad status")
+
+	resp, err := http.Get("http://" + addr + WebSocketPath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	resp, err = http.Get("http://" + addr + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Stopping the server closes the open connections
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatal("server did not stop")
+	}
+	var data []byte
+	assert.Error(t, websocket.Message.Receive(conns[0], &data))
+}
@@ -0,0 +1,45 @@
+package update
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckInterval is how often Check looks up the latest release.
+const CheckInterval = 24 * time.Hour
+
+// StampFile returns the file recording when Check last looked up the latest release.
+func StampFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcphost", "update-check"), nil
+}
+
+// Check returns the latest release if it is newer than version current. It looks it
+// up at most once per CheckInterval, recording the time in the stamp file, and
+// returns nil when no lookup is due. A failed lookup is not retried before the next
+// interval either, so that an offline host is not slowed down.
+func (c *Client) Check(ctx context.Context, current, stamp string) (*Release, error) {
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < CheckInterval {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(stamp), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(stamp, nil, 0o644); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := os.Chtimes(stamp, now, now); err != nil {
+		return nil, err
+	}
+	release, err := c.Latest(ctx)
+	if err != nil || !Newer(release.Version, current) {
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,346 @@
+// Package update replaces the running mcphost binary with the latest release on
+// GitHub. The archive of the release is checked against the SHA-256 sums published
+// with it and, in builds carrying a release public key, the Ed25519 signature of the
+// sums, before the binary is swapped in place.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Where releases are looked up.
+const (
+	DefaultAPIURL = "https://api.github.com"
+	Repository    = "mark3labs/mcphost"
+)
+
+// Names of the release assets listing the SHA-256 sums of the archives, and holding
+// the signature of that list.
+const (
+	checksumsAsset = "checksums.txt"
+	signatureAsset = "checksums.txt.sig"
+)
+
+// maxDownload bounds the size of a downloaded asset.
+const maxDownload = 200 << 20
+
+// PublicKey is the base64 Ed25519 key releases are signed with, set with -ldflags -X
+// at release time. When set, releases must carry a valid signature of their sums.
+var PublicKey = ""
+
+// Release is a GitHub release.
+type Release struct {
+	Version string  `json:"tag_name"`
+	URL     string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the asset of r called name.
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client looks up and downloads releases.
+type Client struct {
+	HTTP   *http.Client
+	APIURL string
+}
+
+// NewClient returns a Client of the GitHub API.
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: 5 * time.Minute}, APIURL: DefaultAPIURL}
+}
+
+// Latest returns the latest release.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.APIURL, "/")+"/repos/"+Repository+"/releases/latest", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to look up the latest release: GitHub answered %s", resp.Status)
+	}
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	return &release, nil
+}
+
+// download returns the content of an asset.
+func (c *Client) download(ctx context.Context, a Asset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", a.Name, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("%s is larger than %d MB", a.Name, maxDownload>>20)
+	}
+	return data, nil
+}
+
+// ArchiveName returns the name of the release archive for a platform, as named by the
+// release build, e.g. mcphost_Linux_x86_64.tar.gz.
+func ArchiveName(goos, goarch string) string {
+	arch := goarch
+	if arch == "amd64" {
+		arch = "x86_64"
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return "mcphost_" + strings.ToUpper(goos[:1]) + goos[1:] + "_" + arch + ext
+}
+
+// Binary downloads the archive of r for the running platform, verifies it and returns
+// the mcphost binary it holds.
+func (c *Client) Binary(ctx context.Context, r *Release) ([]byte, error) {
+	name := ArchiveName(runtime.GOOS, runtime.GOARCH)
+	archive, ok := r.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", r.Version, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset, ok := r.asset(checksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s to verify it with", r.Version, checksumsAsset)
+	}
+	sums, err := c.download(ctx, sumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	if PublicKey != "" {
+		sigAsset, ok := r.asset(signatureAsset)
+		if !ok {
+			return nil, fmt.Errorf("release %s is not signed", r.Version)
+		}
+		sig, err := c.download(ctx, sigAsset)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifySignature(PublicKey, sums, sig); err != nil {
+			return nil, err
+		}
+	}
+	data, err := c.download(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(sums, name, data); err != nil {
+		return nil, err
+	}
+	return Extract(name, data)
+}
+
+// VerifyChecksum checks data, the asset called name, against its SHA-256 sum in sums,
+// lines of hex sum and name as written by sha256sum.
+func VerifyChecksum(sums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		want, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid checksum of %s: %w", name, err)
+		}
+		got := sha256.Sum256(data)
+		if !bytes.Equal(got[:], want) {
+			return fmt.Errorf("checksum mismatch for %s: the download is corrupt or was tampered with", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum for %s", name)
+}
+
+// VerifySignature checks sig, a raw or base64 Ed25519 signature, of data with the
+// base64 public key.
+func VerifySignature(publicKey string, data, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid release public key")
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return errors.New("invalid release signature")
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+		return errors.New("the release signature does not match: the download was tampered with")
+	}
+	return nil
+}
+
+// Extract returns the mcphost binary in the tar.gz or zip archive called name.
+func Extract(name string, data []byte) ([]byte, error) {
+	binary := "mcphost"
+	if strings.HasSuffix(name, ".zip") {
+		binary += ".exe"
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != binary {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxDownload))
+		}
+		return nil, fmt.Errorf("%s has no %s", name, binary)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no %s", name, binary)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+		}
+		if h.Typeflag == tar.TypeReg && filepath.Base(h.Name) == binary {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+// Replace swaps binary in for the executable file. The old file is renamed first,
+// which running executables allow on every system, and removed if possible; on
+// Windows it remains as <executable>.old until the next update.
+func Replace(executable string, binary []byte) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".mcphost-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s, run the update with the rights to: %w", filepath.Dir(executable), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	old := executable + ".old"
+	os.Remove(old)
+	if err := os.Rename(executable, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), executable); err != nil {
+		if restoreErr := os.Rename(old, executable); restoreErr != nil {
+			return fmt.Errorf("%w; the previous version is left at %s", err, old)
+		}
+		return err
+	}
+	os.Remove(old)
+	return nil
+}
+
+// Newer reports whether version latest is newer than current, both of the form
+// v1.2.3. Versions that are not, such as dev builds, are never newer nor older.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses the major, minor and patch numbers of v1.2.3, ignoring any
+// pre-release or build suffix.
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,165 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tarGz returns a tar.gz archive holding the files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return b.Bytes()
+}
+
+// releaseServer serves a release of the GitHub API with the assets.
+func releaseServer(t *testing.T, version string, assets map[string][]byte) *Client {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/repos/"+Repository+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		release := Release{Version: version, URL: srv.URL + "/release"}
+		for name := range assets {
+			release.Assets = append(release.Assets, Asset{Name: name, URL: srv.URL + "/download/" + name})
+		}
+		json.NewEncoder(w).Encode(release)
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(assets[filepath.Base(r.URL.Path)])
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return &Client{HTTP: srv.Client(), APIURL: srv.URL}
+}
+
+func checksums(assets map[string][]byte) []byte {
+	var b bytes.Buffer
+	for name, data := range assets {
+		sum := sha256.Sum256(data)
+		b.WriteString(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+	}
+	return b.Bytes()
+}
+
+// Test downloading and verifying the binary of a release
+func TestBinary(t *testing.T) {
+	name := ArchiveName(runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		t.Skip("the test release has tar.gz archives only")
+	}
+	archive := tarGz(t, map[string]string{"README.md": "docs", "mcphost": "new binary"})
+	assets := map[string][]byte{name: archive}
+	assets[checksumsAsset] = checksums(assets)
+	client := releaseServer(t, "v1.2.0", assets)
+
+	release, err := client.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", release.Version)
+	binary, err := client.Binary(context.Background(), release)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(binary))
+
+	// A tampered archive is refused
+	assets[name] = tarGz(t, map[string]string{"mcphost": "evil binary"})
+	_, err = client.Binary(context.Background(), release)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assets[name] = archive
+
+	// Signed builds require a valid signature of the sums
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	defer func(key string) { PublicKey = key }(PublicKey)
+	PublicKey = base64.StdEncoding.EncodeToString(public)
+	_, err = client.Binary(context.Background(), release)
+	assert.ErrorContains(t, err, "is not signed")
+
+	assets[signatureAsset] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, assets[checksumsAsset])))
+	release, err = client.Latest(context.Background())
+	require.NoError(t, err)
+	binary, err = client.Binary(context.Background(), release)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(binary))
+
+	assets[signatureAsset] = ed25519.Sign(private, []byte("other sums"))
+	_, err = client.Binary(context.Background(), release)
+	assert.ErrorContains(t, err, "signature does not match")
+}
+
+// Test the names of the release archives
+func TestArchiveName(t *testing.T) {
+	assert.Equal(t, "mcphost_Linux_x86_64.tar.gz", ArchiveName("linux", "amd64"))
+	assert.Equal(t, "mcphost_Darwin_arm64.tar.gz", ArchiveName("darwin", "arm64"))
+	assert.Equal(t, "mcphost_Windows_x86_64.zip", ArchiveName("windows", "amd64"))
+}
+
+// Test swapping the binary in place
+func TestReplace(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "mcphost")
+	require.NoError(t, os.WriteFile(executable, []byte("old binary"), 0o755))
+	require.NoError(t, Replace(executable, []byte("new binary")))
+	data, err := os.ReadFile(executable)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+	info, err := os.Stat(executable)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(executable))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary or old file is left")
+}
+
+// Test comparing versions
+func TestNewer(t *testing.T) {
+	assert.True(t, Newer("v1.2.0", "v1.1.9"))
+	assert.True(t, Newer("v2.0.0", "1.10.3"))
+	assert.False(t, Newer("v1.2.0", "v1.2.0"))
+	assert.False(t, Newer("v1.2.0", "v1.10.0"))
+	assert.False(t, Newer("v1.2.0", "dev"), "Development builds are not compared")
+	assert.False(t, Newer("nightly", "v1.0.0"))
+	assert.True(t, Newer("v1.2.1", "v1.2.0-rc.1+dirty"))
+}
+
+// Test that the startup check looks up releases once per interval
+func TestCheck(t *testing.T) {
+	client := releaseServer(t, "v1.2.0", nil)
+	stamp := filepath.Join(t.TempDir(), "cache", "update-check")
+
+	release, err := client.Check(context.Background(), "v1.1.0", stamp)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	assert.Equal(t, "v1.2.0", release.Version)
+
+	release, err = client.Check(context.Background(), "v1.1.0", stamp)
+	require.NoError(t, err)
+	assert.Nil(t, release, "No lookup is due")
+
+	old := time.Now().Add(-CheckInterval - time.Minute)
+	require.NoError(t, os.Chtimes(stamp, old, old))
+	release, err = client.Check(context.Background(), "v1.2.0", stamp)
+	require.NoError(t, err)
+	assert.Nil(t, release, "The current version is the latest")
+}
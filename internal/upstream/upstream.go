@@ -0,0 +1,103 @@
+// Package upstream redirects the requests servers make to their upstream APIs, so that
+// users can point a server at an API-compatible service, such as a self-hosted
+// instance or an API gateway, and tests at a local server, without a flag for the base
+// URL of each API. Routes map the base URL of an API to the one its requests are sent
+// to instead, keeping the rest of their path and their query.
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Routes map base URLs of upstream APIs, e.g. https://www.googleapis.com/customsearch,
+// to the base URLs their requests are sent to instead.
+type Routes map[string]string
+
+// ParseRoutes parses routes given as comma separated from=to pairs of URLs.
+func ParseRoutes(s string) (Routes, error) {
+	routes := make(Routes)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid upstream route %q: expected from=to, e.g. https://api.example.com=http://gateway:8080/example", item)
+		}
+		for _, raw := range []string{from, to} {
+			if u, err := url.Parse(strings.TrimSpace(raw)); err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("invalid URL %q in upstream route %q", raw, item)
+			}
+		}
+		routes[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	return routes, nil
+}
+
+// Rewrite returns u sent to the base URL its longest matching route maps to, and
+// whether a route matched. A base URL matches the URLs with its scheme and host and
+// a path below its own.
+func (r Routes) Rewrite(u *url.URL) (*url.URL, bool) {
+	var match string
+	var rest string
+	for from := range r {
+		base, err := url.Parse(from)
+		if err != nil || !strings.EqualFold(base.Scheme, u.Scheme) || !strings.EqualFold(base.Host, u.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(base.Path, "/")
+		tail, ok := strings.CutPrefix(u.Path, prefix)
+		if !ok || (tail != "" && !strings.HasPrefix(tail, "/")) || (match != "" && len(from) <= len(match)) {
+			continue
+		}
+		match, rest = from, tail
+	}
+	if match == "" {
+		return u, false
+	}
+	to, err := url.Parse(r[match])
+	if err != nil {
+		return u, false
+	}
+	rewritten := *u
+	rewritten.Scheme, rewritten.Host, rewritten.User = to.Scheme, to.Host, to.User
+	rewritten.Path = strings.TrimSuffix(to.Path, "/") + rest
+	rewritten.RawPath = ""
+	return &rewritten, true
+}
+
+// Request returns req sent to the URL Rewrite gives, or req itself if no route
+// matches it.
+func (r Routes) Request(req *http.Request) *http.Request {
+	u, ok := r.Rewrite(req.URL)
+	if !ok {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.URL, req.Host = u, ""
+	return req
+}
+
+// Transport returns a transport sending requests through base after rewriting their
+// URLs by routes. A nil base is http.DefaultTransport as it is when requests are sent.
+func Transport(base http.RoundTripper, routes Routes) http.RoundTripper {
+	return &transport{base: base, routes: routes}
+}
+
+// transport rewrites the URLs of requests.
+type transport struct {
+	base   http.RoundTripper
+	routes Routes
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(t.routes.Request(req))
+}
@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test rewriting URLs to the longest matching route
+func TestRewrite(t *testing.T) {
+	routes := Routes{
+		"https://api.example.com":    "http://gateway:8080/example/",
+		"https://api.example.com/v2": "http://v2.internal",
+		"https://other.example.com/": "http://mock",
+	}
+	tests := map[string]string{
+		"https://api.example.com/v1/items?q=a%20b": "http://gateway:8080/example/v1/items?q=a%20b",
+		"https://API.example.com":                  "http://gateway:8080/example",
+		"https://api.example.com/v2/items":         "http://v2.internal/items",
+		"https://api.example.com/v20/items":        "http://gateway:8080/example/v20/items",
+		"https://other.example.com/":               "http://mock/",
+		"http://api.example.com/v1":                "",
+		"https://api.example.com:8443/v1":          "",
+		"https://example.com/":                     "",
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		rewritten, ok := routes.Rewrite(u)
+		if want == "" {
+			assert.False(t, ok, raw)
+			assert.Same(t, u, rewritten)
+			continue
+		}
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, rewritten.String(), raw)
+	}
+	_, ok := Routes(nil).Rewrite(&url.URL{Scheme: "https", Host: "api.example.com"})
+	assert.False(t, ok)
+}
+
+// Test parsing routes from a flag
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes(" https://api.example.com = http://gateway:8080/example, ,https://b.example.com/v1=http://mock")
+	require.NoError(t, err)
+	assert.Equal(t, Routes{"https://api.example.com": "http://gateway:8080/example", "https://b.example.com/v1": "http://mock"}, routes)
+	routes, err = ParseRoutes("")
+	require.NoError(t, err)
+	assert.Empty(t, routes)
+
+	_, err = ParseRoutes("https://api.example.com")
+	assert.ErrorContains(t, err, `invalid upstream route "https://api.example.com": expected from=to`)
+	_, err = ParseRoutes("api.example.com=http://mock")
+	assert.EqualError(t, err, `invalid URL "api.example.com" in upstream route "api.example.com=http://mock"`)
+}
+
+// Test that the transport sends requests to the routes, with their headers
+func TestTransport(t *testing.T) {
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.RequestURI())
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Token", r.Header.Get("Authorization"))
+	}))
+	defer mock.Close()
+	client := &http.Client{Transport: Transport(nil, Routes{"https://api.example.com/v1": mock.URL + "/mock"})}
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/v1/items?id=7", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer x")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "/mock/items?id=7", resp.Header.Get("X-Path"))
+	assert.Equal(t, mock.Listener.Addr().String(), resp.Header.Get("X-Host"))
+	assert.Equal(t, "Bearer x", resp.Header.Get("X-Token"))
+	assert.Equal(t, "https://api.example.com/v1/items?id=7", req.URL.String(), "The request of the caller is not changed")
+}
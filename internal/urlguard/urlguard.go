@@ -0,0 +1,292 @@
+// Package urlguard checks the URLs that tools request on behalf of clients, against
+// server-side request forgery: a model should not reach the cloud metadata service,
+// the loopback interface or the private network through fetchURL or crawlSite. A
+// Policy restricts the schemes, ports and hosts of URLs, and the addresses their
+// hosts resolve to, which are checked when the connection is dialed so that a name
+// cannot resolve to a public address when checked and a private one when used.
+package urlguard
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// PortRange is a range of ports, a single port when Low and High are equal.
+type PortRange struct {
+	Low, High int
+}
+
+// Policy decides which URLs may be requested. The zero Policy allows http and https
+// URLs of public hosts on any port.
+type Policy struct {
+	// Schemes are the schemes allowed, http and https if empty.
+	Schemes []string
+	// Ports are the ports allowed, any if empty.
+	Ports []PortRange
+	// AllowHosts, if set, are the only hosts allowed, see MatchHost. Hosts they name
+	// may be in private ranges, e.g. an internal API.
+	AllowHosts []string
+	// DenyHosts are hosts denied, see MatchHost, including the names resolving to
+	// the addresses they give.
+	DenyHosts []string
+	// AllowPrivate allows the loopback, private, link-local and other addresses that
+	// are not public.
+	AllowPrivate bool
+}
+
+// nonPublic are the ranges of addresses that are not public besides those the
+// methods of netip.Addr report: shared address space, benchmarking, reserved and
+// NAT64 addresses, which may embed private IPv4 addresses.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// describe returns what kind of address that is not public addr is, or "" if it is
+// public.
+func describe(addr netip.Addr) string {
+	switch {
+	case addr.IsLoopback():
+		return "a loopback address"
+	case addr.IsPrivate():
+		return "a private address"
+	case addr.IsLinkLocalUnicast():
+		return "a link-local address"
+	case addr.IsUnspecified():
+		return "an unspecified address"
+	case addr.IsMulticast():
+		return "a multicast address"
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return "a reserved address"
+		}
+	}
+	return ""
+}
+
+// MatchHost reports whether host, a name or an IP address, matches one of patterns,
+// ignoring case: a name such as example.com, *.example.com for its subdomains, an IP
+// address or a CIDR range such as 10.0.0.0/8.
+func MatchHost(host string, patterns []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	addr, addrErr := netip.ParseAddr(host)
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			if addrErr == nil && prefix.Contains(addr.Unmap()) {
+				return true
+			}
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if other, err := netip.ParseAddr(strings.Trim(p, "[]")); err == nil {
+			if addrErr == nil && other.Unmap() == addr.Unmap() {
+				return true
+			}
+			continue
+		}
+		if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// denied returns the error of a URL the policy does not allow.
+func denied(format string, a ...interface{}) error {
+	return toolerr.Errorf(toolerr.PermissionDenied, format, a...)
+}
+
+// CheckURL checks the scheme, port and host of u, and the address of a host that is
+// an IP address. The addresses names resolve to are checked by CheckAddr.
+func (p *Policy) CheckURL(u *url.URL) error {
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !slices.Contains(schemes, scheme) {
+		return denied("URL scheme %q is not allowed, use %s", u.Scheme, strings.Join(schemes, " or "))
+	}
+	host := u.Hostname()
+	if host == "" {
+		return denied("URL %q has no host", u.Redacted())
+	}
+	if len(p.Ports) > 0 {
+		port := u.Port()
+		if port == "" {
+			port = map[string]string{"http": "80", "https": "443", "ws": "80", "wss": "443", "ftp": "21"}[scheme]
+		}
+		n, _ := strconv.Atoi(port)
+		if !slices.ContainsFunc(p.Ports, func(r PortRange) bool { return n >= r.Low && n <= r.High }) {
+			return denied("port %s of %s is not allowed", port, host)
+		}
+	}
+	if MatchHost(host, p.DenyHosts) {
+		return denied("host %s is denied", host)
+	}
+	if len(p.AllowHosts) > 0 && !MatchHost(host, p.AllowHosts) {
+		return denied("host %s is not allowed, allowed hosts are %s", host, strings.Join(p.AllowHosts, ", "))
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return p.CheckAddr(addr.Unmap().String(), addr)
+	}
+	if name := strings.ToLower(strings.TrimSuffix(host, ".")); (name == "localhost" || strings.HasSuffix(name, ".localhost")) && !p.trusted(host) {
+		return denied("%s is a loopback address", host)
+	}
+	return nil
+}
+
+// trusted reports whether host is named by AllowHosts, or all addresses are allowed.
+func (p *Policy) trusted(host string) bool {
+	return p.AllowPrivate || MatchHost(host, p.AllowHosts)
+}
+
+// CheckAddr checks addr, the address host resolved to, which is in a denied range or
+// is not public while AllowPrivate is off and AllowHosts does not name host or addr.
+func (p *Policy) CheckAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap()
+	if MatchHost(addr.String(), p.DenyHosts) {
+		return denied("host %s is denied: %s is in a denied range", host, addr)
+	}
+	if kind := describe(addr); kind != "" && !p.trusted(host) && !MatchHost(addr.String(), p.AllowHosts) {
+		if host == addr.String() {
+			return denied("%s is %s, not allowed unless listed in -url-allow-hosts", addr, kind)
+		}
+		return denied("host %s resolves to %s, %s, not allowed unless listed in -url-allow-hosts", host, addr, kind)
+	}
+	return nil
+}
+
+// contextKey carries the check of the addresses dialed for a request.
+type contextKey struct{}
+
+// dialCheck checks the addresses dialed for a request of host.
+type dialCheck struct {
+	policy *Policy
+	host   string
+}
+
+// WithDialCheck returns ctx making the transports protected with Protect check the
+// addresses they dial for the request of u it is the context of against p.
+func (p *Policy) WithDialCheck(ctx context.Context, u *url.URL) context.Context {
+	return context.WithValue(ctx, contextKey{}, dialCheck{policy: p, host: u.Hostname()})
+}
+
+// Protect makes t check the addresses it dials for requests whose context has a
+// dial check, see WithDialCheck, and returns t. Other requests are dialed as before.
+func Protect(t *http.Transport) *http.Transport {
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		check, ok := ctx.Value(contextKey{}).(dialCheck)
+		if !ok {
+			return dial(ctx, network, address)
+		}
+		return check.policy.Dialer(check.host).DialContext(ctx, network, address)
+	}
+	return t
+}
+
+// Dialer returns a dialer checking the addresses it dials for host against p, for
+// connections made without an HTTP client, e.g. TLS handshakes. The address of host
+// must be checked with CheckURL first.
+func (p *Policy) Dialer(host string) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return denied("cannot check the address %s of %s", address, host)
+			}
+			return p.CheckAddr(host, addrPort.Addr())
+		},
+	}
+}
+
+// Flags are the flags of the policy of the URLs a server requests.
+type Flags struct {
+	Schemes      string
+	Ports        string
+	AllowHosts   string
+	DenyHosts    string
+	AllowPrivate bool
+}
+
+// Register defines the flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Schemes, "url-schemes", "http,https", "Comma separated URL schemes tools may request")
+	fs.StringVar(&f.Ports, "url-ports", "", "Comma separated ports and port ranges tools may request, e.g. 80,443,8000-8999 (default: any)")
+	fs.StringVar(&f.AllowHosts, "url-allow-hosts", "", "Comma separated hosts, the only ones tools may request if set: names, *.example.com for subdomains, IP addresses or CIDR ranges; they may be private, e.g. an internal API")
+	fs.StringVar(&f.DenyHosts, "url-deny-hosts", "", "Comma separated hosts tools may not request, as -url-allow-hosts; CIDR ranges also deny the names resolving into them")
+	fs.BoolVar(&f.AllowPrivate, "url-allow-private", false, "Let tools request loopback, private, link-local and other addresses that are not public, such as the cloud metadata service")
+}
+
+// Policy returns the policy set by the flags.
+func (f Flags) Policy() (*Policy, error) {
+	p := &Policy{
+		Schemes:      splitList(strings.ToLower(f.Schemes)),
+		AllowHosts:   splitList(f.AllowHosts),
+		DenyHosts:    splitList(f.DenyHosts),
+		AllowPrivate: f.AllowPrivate,
+	}
+	for _, item := range splitList(f.Ports) {
+		low, high, isRange := strings.Cut(item, "-")
+		if !isRange {
+			high = low
+		}
+		r := PortRange{}
+		var lowErr, highErr error
+		r.Low, lowErr = strconv.Atoi(strings.TrimSpace(low))
+		r.High, highErr = strconv.Atoi(strings.TrimSpace(high))
+		if lowErr != nil || highErr != nil || r.Low < 1 || r.High > 65535 || r.Low > r.High {
+			return nil, fmt.Errorf("invalid port %q in -url-ports: expected a port or a range such as 8000-8999", item)
+		}
+		p.Ports = append(p.Ports, r)
+	}
+	for _, host := range append(slices.Clone(p.AllowHosts), p.DenyHosts...) {
+		if strings.Contains(host, "/") {
+			if _, err := netip.ParsePrefix(host); err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q: %w", host, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
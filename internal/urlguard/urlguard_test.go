@@ -0,0 +1,174 @@
+package urlguard
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/toolerr"
+)
+
+// Test matching hosts against names, subdomain wildcards, addresses and ranges
+func TestMatchHost(t *testing.T) {
+	patterns := []string{"hooks.example.com", "*.internal.test", "192.0.2.7", "10.0.0.0/8", "[2001:db8::1]"}
+	tests := map[string]bool{
+		"hooks.example.com":      true,
+		"HOOKS.example.com":      true,
+		"hooks.example.com.":     true,
+		"a.b.internal.test":      true,
+		"internal.test":          false,
+		"example.com":            false,
+		"hooks.example.com.evil": false,
+		"192.0.2.7":              true,
+		"::ffff:192.0.2.7":       true,
+		"192.0.2.8":              false,
+		"10.1.2.3":               true,
+		"[2001:db8::1]":          true,
+		"2001:db8::2":            false,
+		"10.example.com":         false,
+	}
+	for host, want := range tests {
+		assert.Equal(t, want, MatchHost(host, patterns), host)
+	}
+	assert.False(t, MatchHost("example.com", nil))
+}
+
+// Test checking the scheme, port and host of URLs
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		url    string
+		err    string
+	}{
+		{name: "public", url: "https://example.com/a"},
+		{name: "scheme", url: "file:///etc/passwd", err: `URL scheme "file" is not allowed, use http or https`},
+		{name: "gopher", url: "gopher://example.com:70/", err: `URL scheme "gopher" is not allowed`},
+		{name: "own schemes", policy: Policy{Schemes: []string{"https"}}, url: "http://example.com/", err: "use https"},
+		{name: "no host", url: "http:///path", err: "has no host"},
+		{name: "port", policy: Policy{Ports: []PortRange{{443, 443}, {8000, 8999}}}, url: "https://example.com/"},
+		{name: "port range", policy: Policy{Ports: []PortRange{{443, 443}, {8000, 8999}}}, url: "http://example.com:8080/"},
+		{name: "port denied", policy: Policy{Ports: []PortRange{{443, 443}}}, url: "http://example.com/", err: "port 80 of example.com is not allowed"},
+		{name: "deny", policy: Policy{DenyHosts: []string{"*.example.com"}}, url: "https://api.example.com/", err: "host api.example.com is denied"},
+		{name: "allow", policy: Policy{AllowHosts: []string{"example.com"}}, url: "https://example.com/"},
+		{name: "not allowed", policy: Policy{AllowHosts: []string{"example.com"}}, url: "https://example.org/", err: "host example.org is not allowed, allowed hosts are example.com"},
+		{name: "loopback", url: "http://127.0.0.1:8080/", err: "127.0.0.1 is a loopback address"},
+		{name: "metadata", url: "http://169.254.169.254/latest/meta-data/", err: "169.254.169.254 is a link-local address"},
+		{name: "private", url: "http://10.0.0.5/", err: "10.0.0.5 is a private address"},
+		{name: "shared", url: "http://100.64.0.1/", err: "100.64.0.1 is a reserved address"},
+		{name: "unspecified", url: "http://0.0.0.0/", err: "0.0.0.0 is an unspecified address"},
+		{name: "mapped", url: "http://[::ffff:127.0.0.1]/", err: "127.0.0.1 is a loopback address"},
+		{name: "ipv6 loopback", url: "http://[::1]/", err: "::1 is a loopback address"},
+		{name: "nat64", url: "http://[64:ff9b::a00:1]/", err: "is a reserved address"},
+		{name: "localhost", url: "http://localhost:3000/", err: "localhost is a loopback address"},
+		{name: "localhost subdomain", url: "http://app.localhost./", err: "app.localhost. is a loopback address"},
+		{name: "allow private", policy: Policy{AllowPrivate: true}, url: "http://127.0.0.1:8080/"},
+		{name: "allowed private", policy: Policy{AllowHosts: []string{"10.0.0.0/8"}}, url: "http://10.0.0.5/"},
+		{name: "allowed localhost", policy: Policy{AllowHosts: []string{"localhost"}}, url: "http://localhost/"},
+		{name: "denied range", policy: Policy{AllowPrivate: true, DenyHosts: []string{"169.254.0.0/16"}}, url: "http://169.254.169.254/", err: "host 169.254.169.254 is denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			err = tt.policy.CheckURL(u)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Equal(t, toolerr.PermissionDenied, toolerr.Classify(err).Code)
+		})
+	}
+}
+
+// Test checking the addresses names resolve to
+func TestCheckAddr(t *testing.T) {
+	p := &Policy{AllowHosts: []string{"api.internal.test"}, DenyHosts: []string{"203.0.113.0/24"}}
+	assert.NoError(t, p.CheckAddr("www.example.com", netip.MustParseAddr("93.184.216.34")))
+	err := p.CheckAddr("www.example.com", netip.MustParseAddr("127.0.0.1"))
+	assert.ErrorContains(t, err, "host www.example.com resolves to 127.0.0.1, a loopback address")
+	assert.NoError(t, p.CheckAddr("api.internal.test", netip.MustParseAddr("10.1.2.3")), "Allowed hosts may be private")
+	err = p.CheckAddr("www.example.com", netip.MustParseAddr("203.0.113.9"))
+	assert.ErrorContains(t, err, "host www.example.com is denied: 203.0.113.9 is in a denied range")
+}
+
+// Test that protected transports check the addresses they dial
+func TestProtect(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+	transport := Protect(&http.Transport{})
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+	u, err := url.Parse(strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1))
+	require.NoError(t, err)
+
+	get := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	// The name is denied when dialed, whatever CheckURL allowed
+	policy := &Policy{}
+	err = get(policy.WithDialCheck(context.Background(), u))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "host localhost resolves to")
+	assert.Contains(t, err.Error(), "a loopback address")
+	assert.Equal(t, toolerr.PermissionDenied, toolerr.Classify(err).Code)
+
+	assert.NoError(t, get(context.Background()), "Requests without a check are dialed as before")
+	allowed := &Policy{AllowHosts: []string{"localhost"}}
+	assert.NoError(t, get(allowed.WithDialCheck(context.Background(), u)))
+
+	// Connections made without a transport are checked by the dialer
+	_, err = policy.Dialer("localhost").Dial("tcp", u.Host)
+	assert.ErrorContains(t, err, "host localhost resolves to")
+	conn, err := allowed.Dialer("localhost").Dial("tcp", u.Host)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+// Test the policy set by the flags
+func TestFlags(t *testing.T) {
+	var f Flags
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	f.Register(fs)
+	require.NoError(t, fs.Parse([]string{"-url-schemes", "HTTPS", "-url-ports", "443, 8000-8999", "-url-allow-hosts", "example.com,10.0.0.0/8", "-url-deny-hosts", "*.ads.example.com", "-url-allow-private"}))
+	p, err := f.Policy()
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{
+		Schemes:      []string{"https"},
+		Ports:        []PortRange{{443, 443}, {8000, 8999}},
+		AllowHosts:   []string{"example.com", "10.0.0.0/8"},
+		DenyHosts:    []string{"*.ads.example.com"},
+		AllowPrivate: true,
+	}, p)
+
+	var defaults Flags
+	defaults.Register(flag.NewFlagSet("crawler", flag.ContinueOnError))
+	p, err = defaults.Policy()
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{Schemes: []string{"http", "https"}}, p)
+
+	for ports, want := range map[string]string{"0": `invalid port "0"`, "70000": `invalid port "70000"`, "9-1": `invalid port "9-1"`, "http": `invalid port "http"`} {
+		_, err := Flags{Ports: ports}.Policy()
+		assert.ErrorContains(t, err, want)
+	}
+	_, err = Flags{DenyHosts: "10.0.0.0/33"}.Policy()
+	assert.ErrorContains(t, err, `invalid CIDR range "10.0.0.0/33"`)
+}
@@ -0,0 +1,274 @@
+// Package usage counts the calls to each tool of a server, with their errors and
+// latency, so that operators can see which tools agents actually use. The counts are
+// kept in memory, optionally saved to a file across restarts, and reported by the
+// getUsageStats tool and mcphost stats.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolName is the name of the usage statistics tool.
+const ToolName = "getUsageStats"
+
+// saveInterval is how often the counts are saved to their file.
+const saveInterval = time.Minute
+
+// counts are the calls to one tool.
+type counts struct {
+	Calls        int64     `json:"calls"`
+	Errors       int64     `json:"errors"`
+	TotalLatency float64   `json:"totalLatencyMs"`
+	MaxLatency   float64   `json:"maxLatencyMs"`
+	LastCalled   time.Time `json:"lastCalled"`
+}
+
+// state is what a Tracker saves to its file.
+type state struct {
+	Server string             `json:"server"`
+	Since  time.Time          `json:"since"`
+	Tools  map[string]*counts `json:"tools"`
+}
+
+// ToolStats are the statistics of one tool.
+type ToolStats struct {
+	Tool         string    `json:"tool"`
+	Calls        int64     `json:"calls"`
+	Errors       int64     `json:"errors"`
+	ErrorRate    float64   `json:"errorRate"`
+	AvgLatencyMs float64   `json:"avgLatencyMs"`
+	MaxLatencyMs float64   `json:"maxLatencyMs"`
+	LastCalled   time.Time `json:"lastCalled"`
+}
+
+// Report are the statistics of the tools of a server since counting began, by number
+// of calls.
+type Report struct {
+	Server string      `json:"server"`
+	Since  time.Time   `json:"since"`
+	Tools  []ToolStats `json:"tools"`
+}
+
+// report returns the Report of s.
+func (s *state) report() Report {
+	r := Report{Server: s.Server, Since: s.Since, Tools: make([]ToolStats, 0, len(s.Tools))}
+	for tool, c := range s.Tools {
+		stats := ToolStats{Tool: tool, Calls: c.Calls, Errors: c.Errors, MaxLatencyMs: round(c.MaxLatency), LastCalled: c.LastCalled}
+		if c.Calls > 0 {
+			stats.ErrorRate = round(float64(c.Errors) / float64(c.Calls))
+			stats.AvgLatencyMs = round(c.TotalLatency / float64(c.Calls))
+		}
+		r.Tools = append(r.Tools, stats)
+	}
+	sort.Slice(r.Tools, func(i, j int) bool {
+		if r.Tools[i].Calls != r.Tools[j].Calls {
+			return r.Tools[i].Calls > r.Tools[j].Calls
+		}
+		return r.Tools[i].Tool < r.Tools[j].Tool
+	})
+	return r
+}
+
+// round rounds x to three decimals.
+func round(x float64) float64 {
+	return float64(int64(x*1000+0.5)) / 1000
+}
+
+// Tracker counts the tool calls of a server.
+type Tracker struct {
+	file string
+	now  func() time.Time
+
+	mu    sync.Mutex
+	state state
+	dirty bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a Tracker of the server called name keeping its counts in memory.
+func New(name string) *Tracker {
+	return &Tracker{
+		now:   time.Now,
+		state: state{Server: name, Since: time.Now().UTC(), Tools: make(map[string]*counts)},
+	}
+}
+
+// Open returns a Tracker of the server called name that continues the counts saved in
+// file, if it exists, and saves them there every minute and when closed.
+func Open(name, file string) (*Tracker, error) {
+	t := New(name)
+	t.file = file
+	data, err := os.ReadFile(file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		var saved state
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, fmt.Errorf("invalid usage file %s: %w", file, err)
+		}
+		if saved.Tools != nil {
+			t.state.Since, t.state.Tools = saved.Since, saved.Tools
+		}
+	}
+	t.stop, t.done = make(chan struct{}), make(chan struct{})
+	go t.saveEvery(saveInterval)
+	return t, nil
+}
+
+func (t *Tracker) saveEvery(interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			if err := t.Save(); err != nil {
+				log.Printf("Warning: Failed to save usage statistics: %v", err)
+			}
+		}
+	}
+}
+
+// Save writes the counts to the file of t, if they changed since they were last
+// saved.
+func (t *Tracker) Save() error {
+	t.mu.Lock()
+	if t.file == "" || !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	t.dirty = false
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.file), 0o755); err != nil {
+		return err
+	}
+	// Written to a temporary file first so that a crash leaves the previous counts
+	tmp := t.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.file)
+}
+
+// Close stops saving the counts periodically and saves them a last time.
+func (t *Tracker) Close() error {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.done
+	}
+	return t.Save()
+}
+
+// record counts a call to tool that took latency and failed if failed.
+func (t *Tracker) record(tool string, latency time.Duration, failed bool) {
+	ms := float64(latency.Microseconds()) / 1000
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.state.Tools[tool]
+	if c == nil {
+		c = &counts{}
+		t.state.Tools[tool] = c
+	}
+	c.Calls++
+	if failed {
+		c.Errors++
+	}
+	c.TotalLatency += ms
+	c.MaxLatency = max(c.MaxLatency, ms)
+	c.LastCalled = t.now().UTC()
+	t.dirty = true
+}
+
+// Middleware returns tool middleware counting the calls, those failing or returning
+// an error result as errors.
+func (t *Tracker) Middleware() func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := t.now()
+			result, err := next(ctx, req)
+			t.record(req.Params.Name, t.now().Sub(start), err != nil || (result != nil && result.IsError))
+			return result, err
+		}
+	}
+}
+
+// Report returns the statistics of the tools.
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state.report()
+}
+
+// ReadFile returns the statistics saved in a usage file.
+func ReadFile(file string) (Report, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Report{}, err
+	}
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return Report{}, fmt.Errorf("invalid usage file %s: %w", file, err)
+	}
+	return saved.report(), nil
+}
+
+// WriteTable writes r as a table, one tool per line.
+func WriteTable(w io.Writer, r Report) error {
+	if _, err := fmt.Fprintf(w, "Usage of %s since %s\n", r.Server, r.Since.Format(time.RFC3339)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tCALLS\tERRORS\tERROR RATE\tAVG LATENCY\tMAX LATENCY\tLAST CALLED")
+	for _, s := range r.Tools {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%s\t%s\t%s\n", s.Tool, s.Calls, s.Errors, s.ErrorRate*100,
+			milliseconds(s.AvgLatencyMs), milliseconds(s.MaxLatencyMs), s.LastCalled.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+func milliseconds(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond / 10).String()
+}
+
+// Tool returns the definition of the getUsageStats tool.
+func Tool() mcp.Tool {
+	return mcp.NewTool(ToolName,
+		mcp.WithDescription("Reports how often each tool of the server was called since counting began, with the share of calls that failed and their average and maximum latency"),
+	)
+}
+
+// ToolHandler returns the handler of the getUsageStats tool.
+func (t *Tracker) ToolHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data, err := json.MarshalIndent(t.Report(), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode usage statistics: %w", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
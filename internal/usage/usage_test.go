@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// call calls tool through the middleware of t with a handler taking latency and
+// returning outcome.
+func call(t *Tracker, tool string, latency time.Duration, outcome string) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	t.now = func() time.Time { return now }
+	t.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		now = now.Add(latency)
+		switch outcome {
+		case "fail":
+			return nil, errors.New("failed")
+		case "refuse":
+			return mcp.NewToolResultError("refused"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})(context.Background(), mcptest.NewCallToolRequest(tool, nil))
+}
+
+// Test counting calls, errors and latency
+func TestReport(t *testing.T) {
+	tracker := New("fetch")
+	call(tracker, "fetchURL", 100*time.Millisecond, "ok")
+	call(tracker, "fetchURL", 300*time.Millisecond, "fail")
+	call(tracker, "fetchURL", 200*time.Millisecond, "ok")
+	call(tracker, "fetchRSS", 50*time.Millisecond, "refuse")
+
+	report := tracker.Report()
+	assert.Equal(t, "fetch", report.Server)
+	require.Len(t, report.Tools, 2)
+	assert.Equal(t, ToolStats{
+		Tool: "fetchURL", Calls: 3, Errors: 1, ErrorRate: 0.333, AvgLatencyMs: 200, MaxLatencyMs: 300,
+		LastCalled: time.Date(2024, 5, 1, 12, 0, 0, 200_000_000, time.UTC),
+	}, report.Tools[0], "Tools are sorted by calls")
+	assert.Equal(t, int64(1), report.Tools[1].Errors, "Error results count as errors")
+	assert.Equal(t, 1.0, report.Tools[1].ErrorRate)
+
+	result, err := tracker.ToolHandler()(context.Background(), mcptest.NewCallToolRequest(ToolName, nil))
+	require.NoError(t, err)
+	var reported Report
+	require.NoError(t, json.Unmarshal([]byte(mcptest.ResultText(result)), &reported))
+	assert.Equal(t, report.Tools, reported.Tools)
+
+	var table bytes.Buffer
+	require.NoError(t, WriteTable(&table, report))
+	assert.Contains(t, table.String(), "TOOL      CALLS  ERRORS  ERROR RATE  AVG LATENCY  MAX LATENCY  LAST CALLED\n")
+	assert.Contains(t, table.String(), "fetchURL  3      1       33.3%       200ms        300ms")
+}
+
+// Test that counts are saved and continued across restarts
+func TestOpen(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "usage", "fetch.json")
+	tracker, err := Open("fetch", file)
+	require.NoError(t, err)
+	call(tracker, "fetchURL", 100*time.Millisecond, "ok")
+	require.NoError(t, tracker.Close())
+
+	report, err := ReadFile(file)
+	require.NoError(t, err)
+	require.Len(t, report.Tools, 1)
+	assert.Equal(t, int64(1), report.Tools[0].Calls)
+
+	tracker, err = Open("fetch", file)
+	require.NoError(t, err)
+	assert.Equal(t, report.Since, tracker.Report().Since)
+	call(tracker, "fetchURL", 300*time.Millisecond, "ok")
+	require.NoError(t, tracker.Close())
+	report, err = ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), report.Tools[0].Calls)
+	assert.Equal(t, 200.0, report.Tools[0].AvgLatencyMs)
+}
@@ -0,0 +1,164 @@
+// Package watchdog bounds how long a tool call may run: a call over its limit has its
+// context canceled, the stack of its handler logged and a timeout error returned, so
+// that a stuck handler cannot hang its client.
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/i18n"
+)
+
+// AnyTool is the limit key applying to the tools without a limit of their own.
+const AnyTool = "*"
+
+// ParseLimits parses comma separated maximum execution times of the form
+// tool=duration, where tool is a tool name or * for all other tools, e.g.
+// "captureScreen=30s,*=10m". A duration of 0 means no limit.
+func ParseLimits(spec string) (map[string]time.Duration, error) {
+	limits := make(map[string]time.Duration)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tool, value, ok := strings.Cut(item, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid tool timeout %q: expected tool=duration", item)
+		}
+		limit, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid tool timeout %q: expected a duration such as 30s", item)
+		}
+		if _, dup := limits[tool]; dup {
+			return nil, fmt.Errorf("duplicate tool timeout for %s", tool)
+		}
+		limits[tool] = limit
+	}
+	return limits, nil
+}
+
+// Timeout describes a call stopped by the watchdog. It is set as timedOut in the
+// _meta of the result.
+type Timeout struct {
+	Tool         string  `json:"tool"`
+	LimitSeconds float64 `json:"limitSeconds"`
+}
+
+// outcome is what a handler returned, or the panic it raised.
+type outcome struct {
+	result *mcp.CallToolResult
+	err    error
+	panic  interface{}
+}
+
+// Middleware returns tool middleware running each call with the limit of its tool.
+// The limit is the deadline of the context of the call. A call over its limit has
+// its context canceled and gets an error result with timedOut in its _meta at once,
+// while the stack of its handler is logged; the handler is left to return on its own.
+func Middleware(limits map[string]time.Duration) func(server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			limit, ok := limits[req.Params.Name]
+			if !ok {
+				limit = limits[AnyTool]
+			}
+			if limit <= 0 {
+				return next(ctx, req)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			ctx = limitContext{Context: ctx, deadline: time.Now().Add(limit)}
+			done := make(chan outcome, 1)
+			started := make(chan []byte, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- outcome{panic: r}
+					}
+				}()
+				started <- goroutineID()
+				result, err := next(ctx, req)
+				done <- outcome{result: result, err: err}
+			}()
+			id := <-started
+
+			timer := time.NewTimer(limit)
+			defer timer.Stop()
+			select {
+			case o := <-done:
+				if o.panic != nil {
+					panic(o.panic)
+				}
+				return o.result, o.err
+			case <-timer.C:
+			}
+			// The stack shows where the handler is stuck, before it sees the cancellation
+			stack := goroutineStack(id)
+			cancel()
+			log.Printf("Warning: Tool call %s exceeded its limit of %s and was canceled; its handler is at:\n%s", req.Params.Name, limit, stack)
+			result := mcp.NewToolResultError(i18n.Sprintf(i18n.FromContext(ctx), "%s did not finish within %s and was canceled", req.Params.Name, limit))
+			result.Meta = map[string]interface{}{
+				"timedOut": Timeout{Tool: req.Params.Name, LimitSeconds: limit.Seconds()},
+			}
+			return result, nil
+		}
+	}
+}
+
+// limitContext reports the limit of a call as its deadline, so that the handler can
+// budget its upstream requests, see package budget, while the watchdog cancels it
+// itself, once it has the stack of the handler.
+type limitContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c limitContext) Deadline() (time.Time, bool) {
+	if deadline, ok := c.Context.Deadline(); ok && deadline.Before(c.deadline) {
+		return deadline, true
+	}
+	return c.deadline, true
+}
+
+// goroutineID returns the header of the stack of the calling goroutine, such as
+// "goroutine 42 ", which starts its stack in a dump of all goroutines.
+func goroutineID() []byte {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	if i := bytes.IndexByte(buf, '['); i > 0 {
+		return buf[:i]
+	}
+	return nil
+}
+
+// goroutineStack returns the stack of the goroutine starting with id, or a note if it
+// has ended.
+func goroutineStack(id []byte) string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id != nil && bytes.HasPrefix(stack, id) {
+			return string(stack)
+		}
+	}
+	return "(the handler has just returned)"
+}
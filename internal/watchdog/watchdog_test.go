@@ -0,0 +1,94 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+// Test limit parsing
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" captureScreen=30s, *=10m ,runCommand=0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"captureScreen": 30 * time.Second, "*": 10 * time.Minute, "runCommand": 0}, limits)
+
+	for _, spec := range []string{"captureScreen", "=1s", "captureScreen=soon", "captureScreen=-1s", "a=1s,a=2s"} {
+		_, err := ParseLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// stuck blocks until its context is canceled, then reports it.
+func stuck(canceled chan<- struct{}) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}
+}
+
+// Test that hung calls are canceled and reported with the stack of their handler
+func TestMiddleware(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	canceled := make(chan struct{})
+	call := Middleware(map[string]time.Duration{"hang": 20 * time.Millisecond, AnyTool: time.Minute})(stuck(canceled))
+	began := time.Now()
+	result, err := call(context.Background(), mcptest.NewCallToolRequest("hang", nil))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(began), 5*time.Second)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "hang did not finish within 20ms and was canceled", mcptest.ResultText(result))
+	assert.Equal(t, Timeout{Tool: "hang", LimitSeconds: 0.02}, result.Meta["timedOut"])
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the handler context was not canceled")
+	}
+	assert.Contains(t, logged.String(), "Tool call hang exceeded its limit of 20ms")
+	assert.Contains(t, logged.String(), "watchdog.stuck.func1", "The stack of the handler is logged")
+
+	// Calls within their limit, or without one, return what the handler returns
+	quick := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	for _, limits := range []map[string]time.Duration{{AnyTool: time.Minute}, {"quick": 0, AnyTool: time.Nanosecond}, nil} {
+		result, err = Middleware(limits)(quick)(context.Background(), mcptest.NewCallToolRequest("quick", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "done", mcptest.ResultText(result))
+	}
+
+	// The limit is the deadline of the call, unless the caller has an earlier one
+	var deadline time.Time
+	deadlineOf := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		deadline, _ = ctx.Deadline()
+		return mcp.NewToolResultText("done"), nil
+	}
+	_, err = Middleware(map[string]time.Duration{AnyTool: time.Minute})(deadlineOf)(context.Background(), mcptest.NewCallToolRequest("quick", nil))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = Middleware(map[string]time.Duration{AnyTool: time.Minute})(deadlineOf)(ctx, mcptest.NewCallToolRequest("quick", nil))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 500*time.Millisecond)
+
+	// Panics reach the caller
+	panicking := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	}
+	assert.PanicsWithValue(t, "boom", func() {
+		Middleware(map[string]time.Duration{AnyTool: time.Minute})(panicking)(context.Background(), mcptest.NewCallToolRequest("panic", nil))
+	})
+}
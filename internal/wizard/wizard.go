@@ -0,0 +1,183 @@
+// Package wizard builds what mcphost init generates from the answers of the user: the
+// config file of mcphost serve, with the credentials of the servers stored as secret
+// references, and the configuration of MCP clients such as Claude Desktop.
+package wizard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/mcphost/internal/mcpconfig"
+	"github.com/mark3labs/mcphost/internal/supervisor"
+)
+
+// Addresses of the generated config: the status endpoint, then the SSE addresses of
+// the servers from firstPort on, in name order.
+const (
+	host       = "127.0.0.1"
+	statusPort = 8080
+	firstPort  = 8081
+)
+
+// Credential is a flag of a server that takes a credential or an account setting.
+type Credential struct {
+	Flag  string
+	Title string
+	// Secret credentials are stored as secret references rather than written in the
+	// config.
+	Secret   bool
+	Required bool
+	Default  string
+}
+
+// Credentials lists the credentials of the bundled servers that call APIs, by server.
+var Credentials = map[string][]Credential{
+	"discord": {
+		{Flag: "token", Title: "Discord bot token", Secret: true, Required: true},
+		{Flag: "channels", Title: "Channels the bot may use, as alias=channelID, comma separated"},
+	},
+	"geocoding": {
+		{Flag: "api-key", Title: "Google Maps API key, to use Google instead of OpenStreetMap", Secret: true},
+	},
+	"googlesearch": {
+		{Flag: "api-key", Title: "Google Custom Search API key", Secret: true, Required: true},
+		{Flag: "search-engine-id", Title: "Google Custom Search Engine ID", Required: true},
+	},
+	"homeassistant": {
+		{Flag: "url", Title: "Home Assistant URL", Required: true, Default: "http://homeassistant.local:8123"},
+		{Flag: "token", Title: "Home Assistant long-lived access token", Secret: true, Required: true},
+	},
+	"papers": {
+		{Flag: "s2-api-key", Title: "Semantic Scholar API key, for higher rate limits", Secret: true},
+	},
+	"reddit": {
+		{Flag: "client-id", Title: "Reddit OAuth client ID, for authenticated access"},
+		{Flag: "client-secret", Title: "Reddit OAuth client secret", Secret: true},
+		{Flag: "username", Title: "Reddit username, to post"},
+		{Flag: "password", Title: "Reddit password", Secret: true},
+	},
+	"spotify": {
+		{Flag: "client-id", Title: "Spotify application client ID", Required: true},
+		{Flag: "client-secret", Title: "Spotify application client secret", Secret: true, Required: true},
+		{Flag: "refresh-token", Title: "Spotify OAuth refresh token", Secret: true, Required: true},
+	},
+	"telegram": {
+		{Flag: "token", Title: "Telegram bot token", Secret: true, Required: true},
+		{Flag: "chats", Title: "Chats the bot may use, as alias=chatID, comma separated"},
+	},
+	"webhook": {
+		{Flag: "slack-url", Title: "Slack incoming webhook URL", Secret: true},
+		{Flag: "discord-url", Title: "Discord webhook URL", Secret: true},
+	},
+}
+
+// Ways of storing secrets.
+const (
+	Keychain = "keychain" // the keychain of the OS
+	Files    = "file"     // files readable by their owner only
+)
+
+// SecretRef returns the reference a secret of server set by flag is stored at: a
+// keychain entry of the mcphost service or a file in ~/.config/mcphost/secrets.
+func SecretRef(storage, server, flag string) string {
+	if storage == Keychain {
+		return "keychain:mcphost/" + server + "-" + flag
+	}
+	return "file:~/.config/mcphost/secrets/" + server + "-" + flag
+}
+
+// Server is a server to enable with the values of its credential flags, secrets given
+// as references.
+type Server struct {
+	Name   string
+	Values map[string]string
+}
+
+// Args returns the flags setting the credentials of s, in the order of Credentials.
+func (s Server) Args() []string {
+	var args []string
+	for _, c := range Credentials[s.Name] {
+		if value := s.Values[c.Flag]; value != "" {
+			args = append(args, "-"+c.Flag, value)
+		}
+	}
+	return args
+}
+
+// sorted returns servers in name order.
+func sorted(servers []Server) []Server {
+	servers = append([]Server(nil), servers...)
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
+}
+
+// listen returns the SSE address of the i-th server in name order.
+func listen(i int) string {
+	return host + ":" + strconv.Itoa(firstPort+i)
+}
+
+// Endpoint is the SSE endpoint a server of the generated config serves.
+type Endpoint struct {
+	Server string
+	URL    string
+}
+
+// Endpoints returns the SSE endpoints of servers in the config, in name order.
+func Endpoints(servers []Server) []Endpoint {
+	endpoints := make([]Endpoint, len(servers))
+	for i, s := range sorted(servers) {
+		endpoints[i] = Endpoint{Server: s.Name, URL: "http://" + listen(i) + "/sse"}
+	}
+	return endpoints
+}
+
+// serveConfig is the generated config of mcphost serve, in the order of its keys.
+type serveConfig struct {
+	Status  string                 `yaml:"status"`
+	Servers map[string]serveServer `yaml:"servers"`
+}
+
+type serveServer struct {
+	Listen string   `yaml:"listen"`
+	Args   []string `yaml:"args,omitempty,flow"`
+}
+
+// Config returns the config file of mcphost serve running servers, checked as mcphost
+// serve would load it.
+func Config(servers []Server) ([]byte, error) {
+	cfg := serveConfig{Status: host + ":" + strconv.Itoa(statusPort), Servers: make(map[string]serveServer)}
+	for i, s := range sorted(servers) {
+		cfg.Servers[s.Name] = serveServer{Listen: listen(i), Args: s.Args()}
+	}
+	var b bytes.Buffer
+	b.WriteString("# Generated by mcphost init. Run with: mcphost serve -config <this file>\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	if _, err := supervisor.ParseConfig(b.Bytes()); err != nil {
+		return nil, fmt.Errorf("generated an invalid config: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// ClientConfig returns the configuration of Claude Desktop, and other clients taking
+// the same mcpServers format, starting servers over stdio with executable.
+func ClientConfig(executable string, servers []Server) ([]byte, error) {
+	cfg := struct {
+		MCPServers map[string]mcpconfig.Server `json:"mcpServers"`
+	}{make(map[string]mcpconfig.Server)}
+	for _, s := range servers {
+		cfg.MCPServers[s.Name] = mcpconfig.Server{
+			Command: executable,
+			Args:    append([]string{"run", s.Name}, s.Args()...),
+		}
+	}
+	return json.MarshalIndent(cfg, "", "  ")
+}
@@ -0,0 +1,78 @@
+package wizard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+	"github.com/mark3labs/mcphost/internal/servers"
+	"github.com/mark3labs/mcphost/internal/supervisor"
+)
+
+var enabled = []Server{
+	{Name: "time", Values: map[string]string{}},
+	{Name: "googlesearch", Values: map[string]string{
+		"api-key":          SecretRef(Keychain, "googlesearch", "api-key"),
+		"search-engine-id": "0123456789abc",
+	}},
+	{Name: "papers", Values: map[string]string{"s2-api-key": ""}},
+}
+
+// Test generating the config of mcphost serve
+func TestConfig(t *testing.T) {
+	data, err := Config(enabled)
+	require.NoError(t, err)
+	cfg, err := supervisor.ParseConfig(data)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8080", cfg.Status)
+	assert.Equal(t, []string{"-api-key", "keychain:mcphost/googlesearch-api-key", "-search-engine-id", "0123456789abc"}, cfg.Servers["googlesearch"].Args)
+	assert.Equal(t, "127.0.0.1:8081", cfg.Servers["googlesearch"].Listen, "Servers listen in name order")
+	assert.Empty(t, cfg.Servers["papers"].Args, "Optional credentials left empty are not set")
+	assert.Equal(t, "127.0.0.1:8083", cfg.Servers["time"].Listen)
+
+	assert.Equal(t, []Endpoint{
+		{Server: "googlesearch", URL: "http://127.0.0.1:8081/sse"},
+		{Server: "papers", URL: "http://127.0.0.1:8082/sse"},
+		{Server: "time", URL: "http://127.0.0.1:8083/sse"},
+	}, Endpoints(enabled))
+}
+
+// Test generating the configuration of Claude Desktop
+func TestClientConfig(t *testing.T) {
+	data, err := ClientConfig("/usr/local/bin/mcphost", enabled)
+	require.NoError(t, err)
+	var cfg struct {
+		MCPServers map[string]struct {
+			Command string   `json:"command"`
+			Args    []string `json:"args"`
+		} `json:"mcpServers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &cfg))
+	assert.Len(t, cfg.MCPServers, 3)
+	assert.Equal(t, "/usr/local/bin/mcphost", cfg.MCPServers["time"].Command)
+	assert.Equal(t, []string{"run", "time"}, cfg.MCPServers["time"].Args)
+	assert.Equal(t, []string{"run", "googlesearch", "-api-key", "keychain:mcphost/googlesearch-api-key", "-search-engine-id", "0123456789abc"}, cfg.MCPServers["googlesearch"].Args)
+}
+
+// Test that the credentials are flags of bundled servers
+func TestCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, credentials := range Credentials {
+		s, ok := servers.Lookup(name)
+		require.True(t, ok, name)
+		for _, c := range credentials {
+			srv, _, err := s.New(ctx, []string{"-" + c.Flag, "value"})
+			if err == nil {
+				middleware.Close(srv)
+			} else {
+				assert.NotContains(t, err.Error(), "flag provided but not defined", "%s -%s", name, c.Flag)
+			}
+		}
+	}
+	assert.Equal(t, "file:~/.config/mcphost/secrets/spotify-client-secret", SecretRef(Files, "spotify", "client-secret"))
+}
@@ -2,8 +2,6 @@ package main
 
 import "github.com/mark3labs/mcphost/cmd"
 
-var version = "dev"
-
 func main() {
 	cmd.Execute()
 }
@@ -78,7 +78,8 @@ func (p *Provider) CreateMessage(
 						content = append(content, ContentBlock{
 							Type:      "tool_result",
 							ToolUseID: block.ToolUseID,
-							Content:   block.Content,
+							Content:   toolResultContent(block.Content),
+							IsError:   block.IsError,
 						})
 					}
 				}
@@ -143,6 +144,53 @@ func (p *Provider) CreateMessage(
 	return &Message{Msg: *resp}, nil
 }
 
+// toolResultContent returns the content of an MCP tool result as the content blocks
+// of a tool_result: text as it is, images as base64 image sources and resources as
+// their text, or their URI when they are binary.
+func toolResultContent(content interface{}) interface{} {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return content
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		// Not a list of MCP content, such as a string
+		return content
+	}
+
+	var blocks []map[string]interface{}
+	for _, item := range items {
+		switch item["type"] {
+		case "text":
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": item["text"]})
+		case "image":
+			blocks = append(blocks, map[string]interface{}{
+				"type": "image",
+				"source": map[string]interface{}{
+					"type":       "base64",
+					"media_type": item["mimeType"],
+					"data":       item["data"],
+				},
+			})
+		case "resource":
+			resource, _ := item["resource"].(map[string]interface{})
+			text, ok := resource["text"].(string)
+			if !ok {
+				text = fmt.Sprintf("[resource %v (%v)]", resource["uri"], resource["mimeType"])
+			}
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": text})
+		default:
+			raw, _ := json.Marshal(item)
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": string(raw)})
+		}
+	}
+	if len(blocks) == 0 {
+		// A tool_result may have no content, but not an empty one
+		return nil
+	}
+	return blocks
+}
+
 func (p *Provider) SupportsTools() bool {
 	return true
 }
@@ -29,6 +29,7 @@ type ContentBlock struct {
 	Name      string          `json:"name,omitempty"`
 	Input     json.RawMessage `json:"input,omitempty"`
 	Content   interface{}     `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
 }
 
 type Tool struct {
@@ -99,41 +99,18 @@ func (p *Provider) CreateMessage(
 				"tool_call_id", msg.GetToolResponseID(),
 				"raw_message", msg)
 
-			// Extract content from tool response
-			var contentStr string
-			if content := msg.GetContent(); content != "" {
-				contentStr = content
-			} else {
-				// Try to extract text from history message content blocks
-				if historyMsg, ok := msg.(*history.HistoryMessage); ok {
-					var texts []string
-					for _, block := range historyMsg.Content {
-						if block.Type == "tool_result" {
-							if block.Text != "" {
-								texts = append(texts, block.Text)
-							} else if contentArray, ok := block.Content.([]interface{}); ok {
-								for _, item := range contentArray {
-									if contentMap, ok := item.(map[string]interface{}); ok {
-										if text, ok := contentMap["text"]; ok {
-											texts = append(texts, fmt.Sprint(text))
-										}
-									}
-								}
-							}
-						}
+			// The results of parallel tool calls share a history message, while
+			// OpenAI expects a tool message answering each call
+			if historyMsg, ok := msg.(*history.HistoryMessage); ok {
+				for _, block := range historyMsg.Content {
+					if block.Type == "tool_result" {
+						openaiMessages = append(openaiMessages, toolMessage(block.ToolUseID, toolResultText(block)))
 					}
-					contentStr = strings.Join(texts, "\n")
 				}
+				continue
 			}
 
-			if contentStr == "" {
-				contentStr = "No content returned from function"
-			}
-
-			param.Content = &contentStr
-			param.Role = "tool" // Use tool role instead of function
-			param.ToolCallID = msg.GetToolResponseID()
-			// Don't set name field for tool responses
+			param = toolMessage(msg.GetToolResponseID(), msg.GetContent())
 		}
 
 		openaiMessages = append(openaiMessages, param)
@@ -185,6 +162,38 @@ func (p *Provider) CreateMessage(
 	return &Message{Resp: resp, Choice: &resp.Choices[0]}, nil
 }
 
+// toolMessage returns the tool message answering the tool call toolCallID with
+// content.
+func toolMessage(toolCallID, content string) MessageParam {
+	if content == "" {
+		content = "No content returned from function"
+	}
+	// Don't set name field for tool responses
+	return MessageParam{
+		Role:       "tool",
+		Content:    &content,
+		ToolCallID: toolCallID,
+	}
+}
+
+// toolResultText returns the text of a tool_result block of the history.
+func toolResultText(block history.ContentBlock) string {
+	if block.Text != "" {
+		return block.Text
+	}
+	var texts []string
+	if contentArray, ok := block.Content.([]interface{}); ok {
+		for _, item := range contentArray {
+			if contentMap, ok := item.(map[string]interface{}); ok {
+				if text, ok := contentMap["text"]; ok {
+					texts = append(texts, fmt.Sprint(text))
+				}
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
 func (p *Provider) SupportsTools() bool {
 	return true
 }
@@ -0,0 +1,520 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ConvertOptions controls HTML to Markdown conversion.
+type ConvertOptions struct {
+	// BaseURL resolves relative link and image URLs. A <base href> in the document takes
+	// precedence.
+	BaseURL string
+}
+
+// skippedElements never contribute content.
+var skippedElements = setOf("head", "script", "style", "noscript", "template", "iframe", "object", "embed",
+	"svg", "canvas", "math", "button", "select", "textarea", "input", "form", "nav", "title", "meta", "link")
+
+// blockElements start a new block of Markdown.
+var blockElements = setOf("address", "article", "aside", "blockquote", "body", "center", "details", "dialog",
+	"dd", "div", "dl", "dt", "fieldset", "figcaption", "figure", "footer", "h1", "h2", "h3", "h4", "h5", "h6",
+	"header", "hgroup", "hr", "html", "li", "main", "menu", "ol", "p", "pre", "section", "summary", "table",
+	"ul")
+
+var (
+	whitespace    = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+	languageClass = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#.-]+)`)
+	// Text at the start of a line that Markdown would read as block syntax
+	blockStart   = regexp.MustCompile(`^(#{1,6}(?:\s|$)|>|[-+](?:\s|$)|=+\s*$|~~~|` + "```" + `)`)
+	orderedStart = regexp.MustCompile(`^(\d{1,9})[.)](?:\s|$)`)
+)
+
+// codeNewline stands for newlines inside code blocks while blocks are assembled, so the
+// line-based clean-up of surrounding text leaves code untouched.
+const codeNewline = "\x02"
+
+type converter struct {
+	base *url.URL
+}
+
+// FromHTML converts an HTML document or fragment to GitHub Flavored Markdown. Scripts,
+// styles, forms and navigation are dropped; tables become pipe tables.
+func FromHTML(document string, opts ConvertOptions) (string, error) {
+	c := &converter{}
+	if opts.BaseURL != "" {
+		base, err := url.Parse(opts.BaseURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid base URL: %w", err)
+		}
+		c.base = base
+	}
+
+	root, err := parseHTML(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	if baseTag := find(root, "base"); baseTag != nil && attr(baseTag, "href") != "" {
+		if href, err := url.Parse(attr(baseTag, "href")); err == nil {
+			c.base = c.resolveURL(href)
+		}
+	}
+
+	text := strings.ReplaceAll(c.blocks(root), codeNewline, "\n")
+	text = strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+	if text == "" {
+		return "", nil
+	}
+	return text + "\n", nil
+}
+
+// resolveURL resolves u against the base URL, if any.
+func (c *converter) resolveURL(u *url.URL) *url.URL {
+	if c.base == nil {
+		return u
+	}
+	return c.base.ResolveReference(u)
+}
+
+// link resolves an href, returning "" for links that cannot be followed.
+func (c *converter) link(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme == "javascript" || scheme == "vbscript" {
+		return ""
+	}
+	if strings.HasPrefix(href, "#") {
+		return href
+	}
+	return c.resolveURL(u).String()
+}
+
+func isBlock(n *html.Node) bool {
+	return isElement(n) && blockElements[n.Data]
+}
+
+// blocks converts the children of n into Markdown blocks separated by blank lines. Runs of
+// inline content become paragraphs.
+func (c *converter) blocks(n *html.Node) string {
+	var parts []string
+	var run strings.Builder
+	flush := func() {
+		if text := paragraph(run.String()); text != "" {
+			parts = append(parts, text)
+		}
+		run.Reset()
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !isBlock(child) {
+			run.WriteString(c.inline(child))
+			continue
+		}
+		flush()
+		if text := c.block(child); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	flush()
+	return strings.Join(parts, "\n\n")
+}
+
+// paragraph tidies a run of inline Markdown: whitespace is collapsed, lines are trimmed
+// and text that would read as block syntax is escaped.
+func paragraph(text string) string {
+	lines := strings.Split(text, "\n")
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimSpace(whitespace.ReplaceAllString(line, " "))
+		if line == "" || line == `\` {
+			continue
+		}
+		if m := orderedStart.FindStringSubmatch(line); m != nil {
+			line = m[1] + `\` + line[len(m[1]):]
+		} else if blockStart.MatchString(line) {
+			line = `\` + line
+		}
+		kept = append(kept, line)
+	}
+	text = strings.Join(kept, "\n")
+	// A line break at the end of a paragraph has no effect
+	return strings.TrimSuffix(text, `\`)
+}
+
+// block converts a block element.
+func (c *converter) block(n *html.Node) string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.Data[1] - '0')
+		text := strings.ReplaceAll(paragraph(c.inlineChildren(n)), "\n", " ")
+		text = strings.TrimPrefix(text, `\`)
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	case "p", "summary", "dt":
+		text := paragraph(c.inlineChildren(n))
+		if n.Data == "dt" && text != "" {
+			return "**" + text + "**"
+		}
+		return text
+	case "hr":
+		return "---"
+	case "pre":
+		return c.codeBlock(n)
+	case "ul", "ol", "menu":
+		return c.list(n)
+	case "blockquote":
+		content := c.blocks(n)
+		if content == "" {
+			return ""
+		}
+		return prefixLines(content, "> ", "> ")
+	case "table":
+		return c.table(n)
+	case "dd":
+		content := c.blocks(n)
+		if content == "" {
+			return ""
+		}
+		return prefixLines(content, ": ", "  ")
+	case "li":
+		// A list item outside a list
+		return c.listItem(n, "- ")
+	}
+	return c.blocks(n)
+}
+
+// prefixLines prefixes the first line of text with first and the others with rest. Empty
+// lines get the prefix without trailing spaces.
+func prefixLines(text, first, rest string) string {
+	text = strings.ReplaceAll(text, codeNewline, codeNewline+rest)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	text = strings.Join(lines, "\n")
+	// Empty lines in code blocks
+	if trimmed := strings.TrimRight(rest, " "); trimmed != rest {
+		empty := codeNewline + rest + codeNewline
+		for strings.Contains(text, empty) {
+			text = strings.ReplaceAll(text, empty, codeNewline+trimmed+codeNewline)
+		}
+	}
+	return text
+}
+
+// list converts an ordered or unordered list.
+func (c *converter) list(n *html.Node) string {
+	ordered := n.Data == "ol"
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil && ordered {
+		number = start
+	}
+
+	var items []string
+	loose := false
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !isElement(child) {
+			continue
+		}
+		if child.Data != "li" {
+			// Lists nested directly in lists belong to the previous item
+			if text := c.block(child); text != "" {
+				if len(items) == 0 {
+					items = append(items, text)
+				} else {
+					items[len(items)-1] += "\n" + prefixLines(text, "  ", "  ")
+				}
+			}
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		item := c.listItem(child, marker)
+		if strings.Contains(item, "\n\n") {
+			loose = true
+		}
+		items = append(items, item)
+	}
+	if loose {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+// listItem converts a list item with the given marker. Items without paragraphs are kept
+// tight.
+func (c *converter) listItem(n *html.Node, marker string) string {
+	content := c.blocks(n)
+	if find(n, "p") == nil {
+		content = strings.ReplaceAll(content, "\n\n", "\n")
+	}
+	if content == "" {
+		return strings.TrimRight(marker, " ")
+	}
+	return prefixLines(content, marker, strings.Repeat(" ", len(marker)))
+}
+
+// codeBlock converts preformatted text to a fenced code block.
+func (c *converter) codeBlock(n *html.Node) string {
+	code := textContent(n)
+	code = strings.TrimPrefix(code, "\n")
+	code = strings.TrimRight(code, "\n")
+
+	language := ""
+	classes := attr(n, "class")
+	if inner := find(n, "code"); inner != nil {
+		classes += " " + attr(inner, "class")
+	}
+	if m := languageClass.FindStringSubmatch(classes); m != nil {
+		language = m[1]
+	}
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	lines := []string{fence + language}
+	if code != "" {
+		lines = append(lines, strings.Split(code, "\n")...)
+	}
+	lines = append(lines, fence)
+	return strings.Join(lines, codeNewline)
+}
+
+// table converts a table to a pipe table, using the first row as the header.
+func (c *converter) table(n *html.Node) string {
+	var rows [][]string
+	var aligns []string
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if !isElement(child) {
+				continue
+			}
+			switch child.Data {
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			case "tr":
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if !isElement(cell) || (cell.Data != "td" && cell.Data != "th") {
+						continue
+					}
+					text := strings.ReplaceAll(paragraph(c.inlineChildren(cell)), "\n", " ")
+					// Text already has its pipes escaped
+					row = append(row, text)
+					if len(rows) == 0 {
+						aligns = append(aligns, cellAlign(cell))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rows[0])
+	separator := make([]string, width)
+	for i := range separator {
+		separator[i] = "---"
+		if i < len(aligns) {
+			switch aligns[i] {
+			case "left":
+				separator[i] = ":--"
+			case "center":
+				separator[i] = ":-:"
+			case "right":
+				separator[i] = "--:"
+			}
+		}
+	}
+	writeRow(separator)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// cellAlign returns the alignment of a table cell from its align attribute or style.
+func cellAlign(cell *html.Node) string {
+	if align := strings.ToLower(attr(cell, "align")); align != "" {
+		return align
+	}
+	style := strings.ToLower(strings.ReplaceAll(attr(cell, "style"), " ", ""))
+	for _, align := range []string{"left", "center", "right"} {
+		if strings.Contains(style, "text-align:"+align) {
+			return align
+		}
+	}
+	return ""
+}
+
+// inlineChildren converts the children of n as inline content.
+func (c *converter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+// inline converts a node inside a paragraph.
+func (c *converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeText(whitespace.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		// Comments and doctypes
+		return ""
+	}
+	if skippedElements[n.Data] {
+		if n.Data == "input" && strings.EqualFold(attr(n, "type"), "checkbox") {
+			// Task list items
+			if _, checked := lookupAttr(n, "checked"); checked {
+				return "[x] "
+			}
+			return "[ ] "
+		}
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "\\\n"
+	case "strong", "b":
+		return wrapInline(c.inlineChildren(n), "**")
+	case "em", "i", "cite", "var", "dfn":
+		return wrapInline(c.inlineChildren(n), "*")
+	case "del", "s", "strike":
+		return wrapInline(c.inlineChildren(n), "~~")
+	case "code", "kbd", "samp", "tt":
+		return codeSpan(textContent(n))
+	case "a":
+		text := strings.TrimSpace(strings.ReplaceAll(paragraph(c.inlineChildren(n)), "\n", " "))
+		href := c.link(attr(n, "href"))
+		if href == "" || text == "" {
+			return text
+		}
+		if text == href && strings.Contains(href, "://") {
+			return "<" + href + ">"
+		}
+		return "[" + text + "](" + destination(href) + title(attr(n, "title")) + ")"
+	case "img":
+		src := attr(n, "src")
+		if src == "" || strings.HasPrefix(strings.ToLower(src), "data:") {
+			// Inline image data would swamp the text
+			return escapeText(attr(n, "alt"))
+		}
+		return "![" + escapeText(attr(n, "alt")) + "](" + destination(c.link(src)) + title(attr(n, "title")) + ")"
+	case "sup", "sub", "span", "small", "abbr", "mark", "u", "q", "time", "label", "font", "big":
+		return c.inlineChildren(n)
+	}
+	if isBlock(n) {
+		// Block content inside inline content, such as a <div> in a link
+		return " " + c.inlineChildren(n) + " "
+	}
+	return c.inlineChildren(n)
+}
+
+// wrapInline wraps text in emphasis markers, keeping surrounding spaces outside.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+// codeSpan formats text as inline code, choosing a delimiter longer than any backtick run
+// in the text.
+func codeSpan(text string) string {
+	text = whitespace.ReplaceAllString(text, " ")
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	delimiter := "`"
+	for strings.Contains(text, delimiter) {
+		delimiter += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return delimiter + text + delimiter
+}
+
+// destination formats a link destination, using angle brackets when it has spaces or
+// unbalanced parentheses.
+func destination(href string) string {
+	if strings.ContainsAny(href, " <>") || strings.Count(href, "(") != strings.Count(href, ")") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+	}
+	return href
+}
+
+// title formats an optional link title.
+func title(text string) string {
+	if text == "" {
+		return ""
+	}
+	return ` "` + strings.ReplaceAll(text, `"`, `\"`) + `"`
+}
+
+// escapeText escapes characters that Markdown would read as inline syntax.
+var escapeText = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`|`, `\|`,
+	`~~`, `\~\~`,
+).Replace
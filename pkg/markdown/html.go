@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// parseHTML parses a document or fragment with the HTML5 algorithm. Fragments are placed
+// in the <body> of an implied document.
+func parseHTML(s string) (*html.Node, error) {
+	return html.Parse(strings.NewReader(s))
+}
+
+func isElement(n *html.Node) bool {
+	return n.Type == html.ElementNode
+}
+
+// attr returns the value of an attribute, or "" if it is missing.
+func attr(n *html.Node, name string) string {
+	value, _ := lookupAttr(n, name)
+	return value
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// textContent returns the text of a node and its descendants, with <br> as newlines.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.Data == "br" {
+				b.WriteByte('\n')
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// find returns the first descendant element with the given tag.
+func find(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isElement(c) {
+			continue
+		}
+		if c.Data == tag {
+			return c
+		}
+		if found := find(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,200 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Issue is a structural problem found by Lint. Rule names follow markdownlint.
+type Issue struct {
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Rule, i.Message)
+}
+
+// LintOptions controls the optional lint rules.
+type LintOptions struct {
+	// MaxLineLength reports longer lines outside code blocks; 0 disables the check.
+	MaxLineLength int
+}
+
+var (
+	fencePattern      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	missingSpaceATX   = regexp.MustCompile(`^ {0,3}#{1,6}[^#\s]`)
+	unorderedListItem = map[byte]string{'-': "dash", '*': "asterisk", '+': "plus"}
+)
+
+// Lint checks the structure of a Markdown document: heading order and uniqueness, link
+// targets, image alt text, code fence languages and whitespace.
+func Lint(source []byte, opts LintOptions) []Issue {
+	var issues []Issue
+	report := func(line int, rule, format string, args ...interface{}) {
+		issues = append(issues, Issue{Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	text := string(source)
+	lines := strings.Split(text, "\n")
+	if strings.HasSuffix(text, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Lines inside code and HTML blocks are excluded from the whitespace checks
+	verbatim := make(map[int]bool)
+	var fence string
+	for i, line := range lines {
+		if fence != "" {
+			verbatim[i+1] = true
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			if m[1][0] == '`' && strings.Contains(m[2], "`") {
+				// Not a fence: backtick fences cannot have backticks in the info string
+				continue
+			}
+			verbatim[i+1] = true
+			fence = m[1]
+			if strings.TrimSpace(m[2]) == "" {
+				report(i+1, "fenced-code-language", "Fenced code blocks should specify a language")
+			}
+		}
+	}
+
+	doc := parse(source)
+	positions := newPositions(source)
+	ids := make(map[string]bool)
+	for _, h := range Headings(source) {
+		ids[h.ID] = true
+	}
+
+	previousLevel := 0
+	topLevel := 0
+	// Heading texts by the path of enclosing headings, for sibling duplicates
+	var parents []string
+	seen := make(map[string]int)
+	listStyle := ""
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		line := positions.line(n)
+
+		switch n := n.(type) {
+		case *ast.CodeBlock, *ast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				verbatim[positions.lines.line(n.Lines().At(i).Start)] = true
+			}
+		case *ast.Heading:
+			text := strings.TrimSpace(nodeText(n, source))
+			if text == "" {
+				report(line, "no-empty-heading", "Heading has no text")
+			}
+			if previousLevel > 0 && n.Level > previousLevel+1 {
+				report(line, "heading-increment", "Heading levels should only increase by one level at a time (h%d to h%d)", previousLevel, n.Level)
+			}
+			previousLevel = n.Level
+			if n.Level == 1 {
+				if topLevel > 0 {
+					report(line, "single-title", "Multiple top-level headings; the first is on line %d", topLevel)
+				} else {
+					topLevel = line
+				}
+			}
+
+			if len(parents) >= n.Level {
+				parents = parents[:n.Level-1]
+			}
+			for len(parents) < n.Level-1 {
+				parents = append(parents, "")
+			}
+			key := strings.Join(parents, "\x00") + "\x00" + strings.ToLower(text)
+			if first, ok := seen[key]; ok && text != "" {
+				report(line, "no-duplicate-heading", "Duplicate heading %q under the same parent; the first is on line %d", text, first)
+			} else {
+				seen[key] = line
+			}
+			parents = append(parents, strings.ToLower(text))
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			destination := strings.TrimSpace(string(n.Destination))
+			switch {
+			case destination == "" || destination == "#":
+				report(line, "no-empty-links", "Link %q has no destination", nodeText(n, source))
+			case strings.HasPrefix(destination, "#"):
+				fragment, err := url.PathUnescape(destination[1:])
+				if err != nil {
+					fragment = destination[1:]
+				}
+				if !ids[fragment] {
+					report(line, "link-fragments", "Link fragment %s does not match any heading", destination)
+				}
+			}
+		case *ast.Image:
+			if strings.TrimSpace(nodeText(n, source)) == "" {
+				report(line, "no-alt-text", "Image %s has no alternative text", n.Destination)
+			}
+		case *ast.List:
+			if !n.IsOrdered() {
+				style := unorderedListItem[n.Marker]
+				if listStyle == "" {
+					listStyle = style
+				} else if style != listStyle {
+					report(line, "ul-style", "Unordered list uses %s markers, but earlier lists use %s markers", style, listStyle)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	blanks := 0
+	for i, line := range lines {
+		number := i + 1
+		if verbatim[number] {
+			blanks = 0
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			blanks++
+			if blanks == 2 {
+				report(number, "no-multiple-blanks", "Multiple consecutive blank lines")
+			}
+		} else {
+			blanks = 0
+		}
+
+		trailing := len(line) - len(strings.TrimRight(line, " \t"))
+		// Exactly two spaces after text are a line break
+		if trailing > 0 && !(trailing == 2 && strings.HasSuffix(line, "  ") && strings.TrimSpace(line) != "") {
+			report(number, "no-trailing-spaces", "Trailing whitespace")
+		}
+		if strings.Contains(line, "\t") {
+			report(number, "no-hard-tabs", "Hard tab character")
+		}
+		if missingSpaceATX.MatchString(line) {
+			report(number, "no-missing-space-atx", "No space after the hash of a heading")
+		}
+		if opts.MaxLineLength > 0 && strings.Contains(line, " ") && utf8.RuneCountInString(line) > opts.MaxLineLength {
+			report(number, "line-length", "Line is %d characters long (maximum %d)", utf8.RuneCountInString(line), opts.MaxLineLength)
+		}
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		report(len(lines), "single-trailing-newline", "File should end with a single newline")
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
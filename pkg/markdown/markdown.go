@@ -0,0 +1,172 @@
+// Package markdown converts between Markdown and HTML and inspects the structure of
+// Markdown documents. Markdown is parsed as GitHub Flavored Markdown.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// HTMLOptions controls Markdown rendering.
+type HTMLOptions struct {
+	// Unsafe keeps raw HTML and javascript: links from the source. By default they are
+	// omitted, which is the safe choice for untrusted input.
+	Unsafe bool
+	// HardWraps renders every newline inside a paragraph as a line break.
+	HardWraps bool
+}
+
+// newConverter returns a GFM converter with heading IDs matching the ones GitHub generates.
+func newConverter(opts HTMLOptions) goldmark.Markdown {
+	var htmlOptions []renderer.Option
+	if opts.Unsafe {
+		htmlOptions = append(htmlOptions, html.WithUnsafe())
+	}
+	if opts.HardWraps {
+		htmlOptions = append(htmlOptions, html.WithHardWraps())
+	}
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(htmlOptions...),
+	)
+}
+
+// ToHTML renders Markdown as an HTML fragment. Headings get the same IDs as on GitHub, so
+// links to #fragments keep working.
+func ToHTML(source []byte, opts HTMLOptions) (string, error) {
+	converter := newConverter(opts)
+	doc := converter.Parser().Parse(text.NewReader(source))
+	assignHeadingIDs(doc, source)
+
+	var buf bytes.Buffer
+	if err := converter.Renderer().Render(&buf, source, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parse returns the syntax tree of a Markdown document with heading IDs assigned.
+func parse(source []byte) ast.Node {
+	doc := newConverter(HTMLOptions{}).Parser().Parse(text.NewReader(source))
+	assignHeadingIDs(doc, source)
+	return doc
+}
+
+// lineIndex maps byte offsets of a source to line numbers.
+type lineIndex []int
+
+func newLineIndex(source []byte) lineIndex {
+	starts := lineIndex{0}
+	for i, c := range source {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// line returns the 1-based line holding offset.
+func (l lineIndex) line(offset int) int {
+	return sort.Search(len(l), func(i int) bool { return l[i] > offset })
+}
+
+// nodeOffset returns the offset of the first source segment of a node or its descendants,
+// or -1 when the node has no source text.
+func nodeOffset(n ast.Node) int {
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start
+	}
+	if t, ok := n.(*ast.Text); ok {
+		return t.Segment.Start
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if offset := nodeOffset(c); offset >= 0 {
+			return offset
+		}
+	}
+	return -1
+}
+
+// emptyATXHeading matches an ATX heading without text, which has no source segments.
+var emptyATXHeading = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+#*)?[ \t]*\r?$`)
+
+// positions tracks the source lines of nodes during a walk. Nodes without source text,
+// such as inline containers, are placed on the line of the previous node.
+type positions struct {
+	source []byte
+	lines  lineIndex
+	offset int
+	// end is the offset after the last source text seen so far
+	end int
+}
+
+func newPositions(source []byte) *positions {
+	return &positions{source: source, lines: newLineIndex(source)}
+}
+
+// line returns the line of n, which must be visited in document order.
+func (p *positions) line(n ast.Node) int {
+	if offset := nodeOffset(n); offset >= 0 {
+		p.offset = offset
+	} else if _, ok := n.(*ast.Heading); ok {
+		p.offset = p.emptyHeading()
+	}
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		p.end = max(p.end, n.Lines().At(n.Lines().Len()-1).Stop)
+	} else if t, ok := n.(*ast.Text); ok {
+		p.end = max(p.end, t.Segment.Stop)
+	}
+	p.end = max(p.end, p.offset+1)
+	return p.lines.line(p.offset)
+}
+
+// emptyHeading returns the offset of the next empty ATX heading after the text seen so far.
+func (p *positions) emptyHeading() int {
+	for _, start := range p.lines {
+		if start < p.end {
+			continue
+		}
+		end := bytes.IndexByte(p.source[start:], '\n')
+		if end < 0 {
+			end = len(p.source) - start
+		}
+		if emptyATXHeading.Match(p.source[start : start+end]) {
+			return start
+		}
+	}
+	return p.offset
+}
+
+// nodeText returns the plain text of a node's inline content.
+func nodeText(n ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			buf.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(c.Value)
+		case *ast.CodeSpan:
+			buf.Write(c.Text(source))
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			buf.Write(c.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return buf.String()
+}
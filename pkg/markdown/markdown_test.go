@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Markdown rendering
+func TestToHTML(t *testing.T) {
+	output, err := ToHTML([]byte("# Intro\n\n## Intro\n\n<b>raw</b> [x](javascript:alert(1))\n"), HTMLOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, output, `<h1 id="intro">Intro</h1>`)
+	assert.Contains(t, output, `<h2 id="intro-1">Intro</h2>`)
+	assert.NotContains(t, output, "<b>raw</b>")
+	assert.NotContains(t, output, "javascript:")
+
+	output, err = ToHTML([]byte("<b>raw</b>\nline\n"), HTMLOptions{Unsafe: true, HardWraps: true})
+	require.NoError(t, err)
+	assert.Contains(t, output, "<b>raw</b><br>")
+}
+
+// Test HTML conversion of markup that needs the HTML5 parsing rules
+func TestFromHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		html     string
+		baseURL  string
+		expected string
+	}{
+		{
+			name:     "Implicitly closed paragraphs",
+			html:     "<p>one<p>two<div>three</div>",
+			expected: "one\n\ntwo\n\nthree\n",
+		},
+		{
+			name:     "Misnested formatting",
+			html:     "<p><b>bold <i>both</b> italic</i></p>",
+			expected: "**bold *both*** *italic*\n",
+		},
+		{
+			name:     "Table without tbody or closing tags",
+			html:     "<table><tr><th>A<th align=right>B<tr><td>1<td>2</table>",
+			expected: "| A | B |\n| --- | --: |\n| 1 | 2 |\n",
+		},
+		{
+			name:     "Comments, scripts and raw text are dropped",
+			html:     "<!DOCTYPE html><!-- note --><script>if (a < b) {}</script><style>p{}</style><p>kept</p><textarea><p>no</p></textarea>",
+			expected: "kept\n",
+		},
+		{
+			name:     "Entities and attributes",
+			html:     `<p>&lt;tag&gt; &amp; <a href='/a?x=1&amp;y=2'>link</a></p>`,
+			baseURL:  "https://example.com/",
+			expected: "\\<tag> & [link](https://example.com/a?x=1&y=2)\n",
+		},
+		{
+			name:     "Leading newline in pre",
+			html:     "<pre>\n  indented\n</pre>",
+			expected: "```\n  indented\n```\n",
+		},
+		{
+			name:     "Empty document",
+			html:     "<html><head><title>Only a title</title></head></html>",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := FromHTML(tc.html, ConvertOptions{BaseURL: tc.baseURL})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+
+	_, err := FromHTML("<p>x</p>", ConvertOptions{BaseURL: "://bad"})
+	assert.Error(t, err)
+}
+
+// Test heading slugs
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "hello-world", Slug("Hello, World!"))
+	assert.Equal(t, "snake_case-and-kebab-case", Slug(" snake_case and kebab-case "))
+	assert.Equal(t, "héllo-wörld", Slug("Héllo Wörld"))
+	assert.Equal(t, "", Slug("!!!"))
+}
+
+// Test heading extraction and the table of contents
+func TestHeadingsAndTOC(t *testing.T) {
+	source := []byte("# Title\n\ntext\n\n## A [link](x)\n\n### Deep\n\n## A link\n\n```\n# not a heading\n```\n")
+	headings := Headings(source)
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Title", ID: "title", Line: 1},
+		{Level: 2, Text: "A link", ID: "a-link", Line: 5},
+		{Level: 3, Text: "Deep", ID: "deep", Line: 7},
+		{Level: 2, Text: "A link", ID: "a-link-1", Line: 9},
+	}, headings)
+
+	assert.Equal(t, "- [A link](#a-link)\n  - [Deep](#deep)\n- [A link](#a-link-1)\n", TOC(headings, 2, 3))
+	assert.Equal(t, "- [Title](#title)\n", TOC(headings, 1, 1))
+}
+
+// Test structural lint rules
+func TestLint(t *testing.T) {
+	source := []byte("# One\n\n### Skipped\n\n# Two\n\n[empty]()\n[missing](#nowhere)\n\n![](a.png)\n\n```\ncode\n```\n\ntrailing \n\n\n#nospace\n")
+	rules := map[string]int{}
+	for _, issue := range Lint(source, LintOptions{MaxLineLength: 10}) {
+		rules[issue.Rule] = issue.Line
+	}
+	assert.Equal(t, 3, rules["heading-increment"])
+	assert.Equal(t, 5, rules["single-title"])
+	assert.Equal(t, 7, rules["no-empty-links"])
+	assert.Equal(t, 8, rules["link-fragments"])
+	assert.Equal(t, 10, rules["no-alt-text"])
+	assert.Equal(t, 12, rules["fenced-code-language"])
+	assert.Equal(t, 16, rules["no-trailing-spaces"])
+	assert.Contains(t, rules, "no-multiple-blanks")
+	assert.Contains(t, rules, "no-missing-space-atx")
+	assert.Contains(t, rules, "line-length")
+
+	assert.Empty(t, Lint([]byte("# Clean\n\nText.\n"), LintOptions{}))
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Slug converts heading text to an anchor the way GitHub does: letters and digits are
+// lower-cased, spaces become hyphens, hyphens and underscores are kept and everything else
+// is dropped.
+func Slug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(strings.ToLower(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// assignHeadingIDs sets an id attribute on every heading from its text, numbering
+// repeated slugs as GitHub does.
+func assignHeadingIDs(doc ast.Node, source []byte) {
+	used := make(map[string]bool)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		base := Slug(nodeText(heading, source))
+		if base == "" {
+			base = "heading"
+		}
+		id := base
+		for i := 1; used[id]; i++ {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[id] = true
+		heading.SetAttributeString("id", []byte(id))
+		return ast.WalkSkipChildren, nil
+	})
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Heading is a section heading of a Markdown document.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+	Line  int    `json:"line"`
+}
+
+// Headings returns the headings of a document in order. IDs match the anchors GitHub and
+// ToHTML generate.
+func Headings(source []byte) []Heading {
+	doc := parse(source)
+	positions := newPositions(source)
+	var headings []Heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		line := positions.line(n)
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		headings = append(headings, Heading{
+			Level: heading.Level,
+			Text:  strings.TrimSpace(nodeText(heading, source)),
+			ID:    string(id.([]byte)),
+			Line:  line,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// TOC formats headings between minLevel and maxLevel as a nested Markdown list of links.
+// Nesting follows the enclosing headings, so skipped levels and documents without a level
+// 1 heading are not indented needlessly.
+func TOC(headings []Heading, minLevel, maxLevel int) string {
+	var b strings.Builder
+	// The levels of the enclosing list items
+	var stack []int
+	for _, h := range headings {
+		if h.Level < minLevel || h.Level > maxLevel {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1] >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", len(stack)), escapeLinkText(h.Text), h.ID)
+		stack = append(stack, h.Level)
+	}
+	return b.String()
+}
+
+// escapeLinkText escapes the characters that would end link text early.
+func escapeLinkText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`).Replace(text)
+}
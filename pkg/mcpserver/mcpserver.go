@@ -0,0 +1,113 @@
+// Package mcpserver lets other packages add MCP servers to the mcphost binary. A
+// server implements Server and registers itself in an init function:
+//
+//	func init() {
+//		mcpserver.Register(&weatherServer{})
+//	}
+//
+// A binary importing the package, e.g. a main package calling cmd.Execute with a
+// blank import of it, then runs the server like the bundled ones, with
+// "mcphost run weather", and lets the supervisor and proxy use it.
+package mcpserver
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/mark3labs/mcphost/internal/middleware"
+)
+
+// Server is an MCP server that can be added to the mcphost binary.
+type Server interface {
+	// Name is the name the server is run by, e.g. "weather".
+	Name() string
+	// Version is reported to clients.
+	Version() string
+	// Register adds the tools of the server to s, with AddTool so that the tool
+	// middleware selected on the command line applies to them.
+	Register(s *server.MCPServer) error
+}
+
+// Describer is implemented by servers describing themselves in mcphost list.
+type Describer interface {
+	Description() string
+}
+
+// FlagRegisterer is implemented by servers with command line flags of their own.
+// They are defined before the command line is parsed, and so set when Register runs.
+type FlagRegisterer interface {
+	RegisterFlags(fs *flag.FlagSet)
+}
+
+var (
+	mu         sync.Mutex
+	registered = make(map[string]Server)
+)
+
+// Register adds srv to the servers of the binary. Like database/sql.Register, it
+// panics when srv has no name or its name is taken.
+func Register(srv Server) {
+	mu.Lock()
+	defer mu.Unlock()
+	name := srv.Name()
+	if name == "" {
+		panic("mcpserver: Register of a server without a name")
+	}
+	if _, dup := registered[name]; dup {
+		panic(fmt.Sprintf("mcpserver: Register called twice for server %s", name))
+	}
+	registered[name] = srv
+}
+
+// Registered returns the registered servers, sorted by name.
+func Registered() []Server {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Server, 0, len(registered))
+	for _, srv := range registered {
+		list = append(list, srv)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// ToolMiddleware wraps a tool handler, e.g. to log, time out or reject tool calls.
+type ToolMiddleware func(next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+// AddTool adds tool to s with handler, running through the shared tool middleware
+// such as audit logging, rate limiting and the access policy, then through mw, the
+// middleware of this tool only, the first one outermost.
+func AddTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc, mw ...ToolMiddleware) {
+	middleware.AddTool(s, tool, handler, internal(mw)...)
+}
+
+// Chain composes mw into one middleware, the first one outermost, e.g. to add the
+// same layers to all the tools of a server.
+func Chain(mw ...ToolMiddleware) ToolMiddleware {
+	return ToolMiddleware(middleware.Stack(internal(mw)...))
+}
+
+// ForTools returns middleware running the calls of the tools matching patterns,
+// path.Match patterns such as "get*", through mw; other calls skip it.
+func ForTools(mw ToolMiddleware, patterns ...string) ToolMiddleware {
+	return ToolMiddleware(middleware.ForTools(middleware.Middleware(mw), patterns...))
+}
+
+// Timeout returns middleware canceling the context of calls after d.
+func Timeout(d time.Duration) ToolMiddleware {
+	return ToolMiddleware(middleware.Timeout(d))
+}
+
+func internal(mw []ToolMiddleware) []middleware.Middleware {
+	converted := make([]middleware.Middleware, len(mw))
+	for i, m := range mw {
+		converted[i] = middleware.Middleware(m)
+	}
+	return converted
+}
@@ -0,0 +1,62 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mark3labs/mcphost/pkg/mcptest"
+)
+
+type namedServer string
+
+func (s namedServer) Name() string                     { return string(s) }
+func (s namedServer) Version() string                  { return "1.0.0" }
+func (s namedServer) Register(*server.MCPServer) error { return nil }
+
+// Test registration and its panics
+func TestRegister(t *testing.T) {
+	Register(namedServer("zeta"))
+	Register(namedServer("alpha"))
+	defer func() {
+		mu.Lock()
+		delete(registered, "zeta")
+		delete(registered, "alpha")
+		mu.Unlock()
+	}()
+
+	names := []string{}
+	for _, srv := range Registered() {
+		names = append(names, srv.Name())
+	}
+	assert.Equal(t, []string{"alpha", "zeta"}, names)
+
+	assert.PanicsWithValue(t, "mcpserver: Register called twice for server alpha", func() { Register(namedServer("alpha")) })
+	assert.Panics(t, func() { Register(namedServer("")) })
+}
+
+// Test that tools added with middleware run through it
+func TestAddToolMiddleware(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	var calls []string
+	record := func(name string) ToolMiddleware {
+		return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	AddTool(s, mcp.NewTool("getForecast"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_, ok := ctx.Deadline()
+		return mcp.NewToolResultText(fmt.Sprintf("deadline: %t", ok)), nil
+	}, Chain(record("logging"), ForTools(record("forecasts"), "get*"), ForTools(record("alerts"), "alert*")), Timeout(time.Minute))
+
+	assert.Equal(t, "deadline: true", mcptest.Connect(t, s).Text("getForecast", nil))
+	assert.Equal(t, []string{"logging", "forecasts"}, calls)
+}
@@ -0,0 +1,243 @@
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessions numbers the sessions of test clients.
+var sessions atomic.Int64
+
+// session is the session of a Client, through which the server sends notifications.
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *session) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *session) Initialized() bool {
+	return s.initialized.Load()
+}
+
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *session) SessionID() string {
+	return s.id
+}
+
+// Client is a client session of a server under test, passing JSON-RPC messages to
+// the server in memory. Its methods fail the test on errors it does not expect.
+type Client struct {
+	t       testing.TB
+	server  *server.MCPServer
+	session *session
+	nextID  atomic.Int64
+
+	mu            sync.Mutex
+	notifications []mcp.JSONRPCNotification
+
+	// Info is the result of the initialize handshake.
+	Info *mcp.InitializeResult
+}
+
+// Connect connects a client to s and performs the initialize handshake. The session
+// ends with the test.
+func Connect(t testing.TB, s *server.MCPServer) *Client {
+	t.Helper()
+	c := &Client{
+		t:      t,
+		server: s,
+		session: &session{
+			id:            fmt.Sprintf("mcptest-%d", sessions.Add(1)),
+			notifications: make(chan mcp.JSONRPCNotification, 100),
+		},
+	}
+	if err := s.RegisterSession(context.Background(), c.session); err != nil {
+		t.Fatalf("mcptest: registering session: %v", err)
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go c.collect(done, stopped)
+	t.Cleanup(func() {
+		s.UnregisterSession(c.session.id)
+		close(done)
+		<-stopped
+	})
+
+	var result mcp.InitializeResult
+	params := map[string]interface{}{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"clientInfo":      mcp.Implementation{Name: "mcptest", Version: "1.0.0"},
+		"capabilities":    mcp.ClientCapabilities{},
+	}
+	if err := c.Request(string(mcp.MethodInitialize), params, &result); err != nil {
+		t.Fatalf("mcptest: initialize: %v", err)
+	}
+	c.Info = &result
+	c.notify("notifications/initialized")
+	return c
+}
+
+// SessionID returns the ID of the session, as the server sees it.
+func (c *Client) SessionID() string {
+	return c.session.id
+}
+
+// collect keeps the notifications of the server until done is closed.
+func (c *Client) collect(done <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	for {
+		select {
+		case n := <-c.session.notifications:
+			c.mu.Lock()
+			c.notifications = append(c.notifications, n)
+			c.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+// Notifications returns the notifications the server sent so far.
+func (c *Client) Notifications() []mcp.JSONRPCNotification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]mcp.JSONRPCNotification(nil), c.notifications...)
+}
+
+// Request sends a request and decodes its result into result. It returns the
+// message of an error response as an error.
+func (c *Client) Request(method string, params, result interface{}) error {
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      c.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	ctx := c.server.WithContext(context.Background(), c.session)
+	data, err := json.Marshal(c.server.HandleMessage(ctx, request))
+	if err != nil {
+		return err
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return &ResponseError{Message: response.Error.Message}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// notify sends a notification.
+func (c *Client) notify(method string) {
+	message, _ := json.Marshal(map[string]interface{}{"jsonrpc": mcp.JSONRPC_VERSION, "method": method})
+	c.server.HandleMessage(c.server.WithContext(context.Background(), c.session), message)
+}
+
+// ResponseError is an error response of the server.
+type ResponseError struct {
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+// ListTools returns the tools of the server.
+func (c *Client) ListTools() []mcp.Tool {
+	c.t.Helper()
+	var result mcp.ListToolsResult
+	if err := c.Request(string(mcp.MethodToolsList), map[string]interface{}{}, &result); err != nil {
+		c.t.Fatalf("mcptest: tools/list: %v", err)
+	}
+	return result.Tools
+}
+
+// Tool returns the tool called name, failing the test if the server has none.
+func (c *Client) Tool(name string) mcp.Tool {
+	c.t.Helper()
+	for _, tool := range c.ListTools() {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	c.t.Fatalf("mcptest: no tool %s", name)
+	return mcp.Tool{}
+}
+
+// CallTool calls the tool called name with args. Errors of the handler come back as
+// *ResponseError.
+func (c *Client) CallTool(name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	var raw json.RawMessage
+	if err := c.Request(string(mcp.MethodToolsCall), map[string]interface{}{"name": name, "arguments": args}, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// Text calls a tool expecting it to succeed and returns the first text of its
+// result.
+func (c *Client) Text(name string, args map[string]interface{}) string {
+	c.t.Helper()
+	result, err := c.CallTool(name, args)
+	if err != nil {
+		c.t.Fatalf("mcptest: %s failed: %v", name, err)
+	}
+	if result.IsError {
+		c.t.Fatalf("mcptest: %s returned an error result: %s", name, ResultText(result))
+	}
+	return ResultText(result)
+}
+
+// Error calls a tool expecting it to fail, with an error response or an error
+// result, and returns the error message.
+func (c *Client) Error(name string, args map[string]interface{}) string {
+	c.t.Helper()
+	result, err := c.CallTool(name, args)
+	if err != nil {
+		return err.Error()
+	}
+	if !result.IsError {
+		c.t.Fatalf("mcptest: %s succeeded, expected an error: %s", name, ResultText(result))
+	}
+	return ResultText(result)
+}
+
+// ReadResource reads the resource at uri.
+func (c *Client) ReadResource(uri string) []mcp.ResourceContents {
+	c.t.Helper()
+	var raw json.RawMessage
+	if err := c.Request(string(mcp.MethodResourcesRead), map[string]interface{}{"uri": uri}, &raw); err != nil {
+		c.t.Fatalf("mcptest: reading %s: %v", uri, err)
+	}
+	result, err := mcp.ParseReadResourceResult(&raw)
+	if err != nil {
+		c.t.Fatalf("mcptest: reading %s: %v", uri, err)
+	}
+	return result.Contents
+}
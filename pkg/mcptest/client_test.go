@@ -0,0 +1,52 @@
+package mcptest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a client connects to a server and calls its tools
+func TestConnect(t *testing.T) {
+	s := server.NewMCPServer("test", "1.2.3", server.WithResourceCapabilities(false, false))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text", mcp.Required())), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, _ := req.Params.Arguments["text"].(string)
+		switch text {
+		case "fail":
+			return nil, errors.New("handler failed")
+		case "error":
+			return mcp.NewToolResultError("bad text"), nil
+		}
+		server.ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/message", map[string]interface{}{"data": text})
+		return mcp.NewToolResultText(text), nil
+	})
+	s.AddResource(mcp.NewResource("test://readme", "readme"), func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: "test://readme", Text: "hello"}}, nil
+	})
+
+	c := Connect(t, s)
+	assert.Equal(t, "test", c.Info.ServerInfo.Name)
+	assert.Equal(t, "1.2.3", c.Info.ServerInfo.Version)
+	assert.NotEmpty(t, c.SessionID())
+
+	assert.Len(t, c.ListTools(), 1)
+	assert.Equal(t, []string{"text"}, c.Tool("echo").InputSchema.Required)
+
+	assert.Equal(t, "hi", c.Text("echo", map[string]interface{}{"text": "hi"}))
+	assert.Equal(t, "handler failed", c.Error("echo", map[string]interface{}{"text": "fail"}))
+	assert.Equal(t, "bad text", c.Error("echo", map[string]interface{}{"text": "error"}))
+	_, err := c.CallTool("missing", nil)
+	var responseErr *ResponseError
+	require.ErrorAs(t, err, &responseErr)
+	assert.Contains(t, responseErr.Message, "tool 'missing' not found")
+
+	assert.Equal(t, "hello", ResourceText(c.ReadResource("test://readme")))
+	assert.Eventually(t, func() bool { return len(c.Notifications()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "notifications/message", c.Notifications()[0].Method)
+}
@@ -0,0 +1,187 @@
+package mcptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fuzzTimeout bounds each tool call of FuzzTools.
+const fuzzTimeout = 10 * time.Second
+
+// oddValues are JSON values clients send where they should not: values of the wrong
+// type, edge case numbers and strings with unusual unicode.
+var oddValues = []string{
+	`null`, `true`, `0`, `-1`, `1e308`, `0.5`, `""`, `" "`, `"0"`, `[]`, `[null]`, `{}`, `{"": ""}`,
+	`"\u0000"`, `"\ud800"`, `"\u202eevil"`, `"\ufeffbom"`, `"Z\u0335\u0321a\u0337l\u0338g\u0335o"`,
+	`"\ud83d\udc69\u200d\ud83d\udc67"`, `"%s%n%x"`, `"\r\n\t"`,
+	`"` + strings.Repeat("A", 1<<17) + `"`,
+	`"` + strings.Repeat("한", 1<<15) + `"`,
+	`[` + strings.Repeat("1,", 1<<13) + `1]`,
+}
+
+// malformed are arguments that are not JSON objects, or not JSON at all.
+var malformed = []string{`{}`, `null`, `[]`, `"text"`, `{`, `{"a":`, `[1,`, `nul`, ``}
+
+// FuzzTools fuzzes the arguments of the tools of s, or of the tools named, through
+// the whole MCP request path, so that handlers are hardened against the arguments of
+// hostile or confused clients. The corpus is seeded, for each tool, with malformed
+// arguments and with the required arguments of its input schema with each parameter
+// in turn replaced by a value of the wrong type, a huge value or unusual unicode. A
+// call fails the fuzz test when the server panics or answers with anything but an
+// error or a valid tool result.
+//
+// Requests of the tools through http.DefaultTransport, as the HTTP clients of the
+// servers make them, are answered with 404 Not Found without reaching the network.
+// Tools changing files should be given temporary directories.
+func FuzzTools(f *testing.F, s *server.MCPServer, tools ...string) {
+	f.Helper()
+	c := Connect(f, s)
+	list := c.ListTools()
+	if len(tools) > 0 {
+		list = slices.DeleteFunc(list, func(t mcp.Tool) bool { return !slices.Contains(tools, t.Name) })
+	}
+	if len(list) == 0 {
+		f.Fatal("mcptest: no tools to fuzz")
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	base := http.DefaultTransport
+	http.DefaultTransport = offline{}
+	f.Cleanup(func() { http.DefaultTransport = base })
+
+	for i, tool := range list {
+		for _, seed := range Seeds(tool) {
+			f.Add(uint(i), seed)
+		}
+	}
+	f.Fuzz(func(t *testing.T, i uint, args []byte) {
+		tool := list[i%uint(len(list))]
+		name, _ := json.Marshal(tool.Name)
+		message := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":` + string(name) + `,"arguments":` + string(args) + `}}`)
+		ctx, cancel := context.WithTimeout(context.Background(), fuzzTimeout)
+		defer cancel()
+		switch response := c.server.HandleMessage(c.server.WithContext(ctx, c.session), message).(type) {
+		case mcp.JSONRPCError:
+		case mcp.JSONRPCResponse:
+			data, err := json.Marshal(response.Result)
+			if err != nil {
+				t.Fatalf("mcptest: %s: result cannot be encoded: %v", tool.Name, err)
+			}
+			var result struct {
+				Content []struct {
+					Type string `json:"type"`
+				} `json:"content"`
+			}
+			if err := json.Unmarshal(data, &result); err != nil || result.Content == nil {
+				t.Fatalf("mcptest: %s: invalid tool result: %.200s", tool.Name, data)
+			}
+			for _, content := range result.Content {
+				if content.Type == "" {
+					t.Fatalf("mcptest: %s: content without a type: %.200s", tool.Name, data)
+				}
+			}
+		default:
+			t.Fatalf("mcptest: %s: unexpected response %#v", tool.Name, response)
+		}
+	})
+}
+
+// Seeds returns the seed arguments of FuzzTools for tool, as JSON.
+func Seeds(tool mcp.Tool) [][]byte {
+	var seeds [][]byte
+	for _, m := range malformed {
+		seeds = append(seeds, []byte(m))
+	}
+	base := make(map[string]json.RawMessage)
+	for _, name := range tool.InputSchema.Required {
+		base[name] = validValue(tool.InputSchema.Properties[name])
+	}
+	if data, err := json.Marshal(base); err == nil {
+		seeds = append(seeds, data)
+	}
+
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range oddValues {
+			args := make(map[string]json.RawMessage, len(base)+1)
+			for k, v := range base {
+				args[k] = v
+			}
+			args[name] = json.RawMessage(value)
+			data, err := json.Marshal(args)
+			if err != nil {
+				continue
+			}
+			seeds = append(seeds, data)
+		}
+	}
+	return seeds
+}
+
+// validValue returns a value matching the schema of a parameter: its default, its
+// first allowed value or a value of its type.
+func validValue(property interface{}) json.RawMessage {
+	schema, _ := property.(map[string]interface{})
+	if def, ok := schema["default"]; ok {
+		if data, err := json.Marshal(def); err == nil {
+			return data
+		}
+	}
+	switch enum := schema["enum"].(type) {
+	case []string:
+		if len(enum) > 0 {
+			data, _ := json.Marshal(enum[0])
+			return data
+		}
+	case []interface{}:
+		if len(enum) > 0 {
+			if data, err := json.Marshal(enum[0]); err == nil {
+				return data
+			}
+		}
+	}
+	switch schema["type"] {
+	case "number", "integer":
+		return json.RawMessage(`1`)
+	case "boolean":
+		return json.RawMessage(`true`)
+	case "array":
+		return json.RawMessage(`[]`)
+	case "object":
+		return json.RawMessage(`{}`)
+	}
+	return json.RawMessage(`"a"`)
+}
+
+// offline answers every request with 404 Not Found.
+type offline struct{}
+
+func (offline) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:     "404 Not Found",
+		StatusCode: http.StatusNotFound,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+		Request:    req,
+	}, nil
+}
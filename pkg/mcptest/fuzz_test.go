@@ -0,0 +1,62 @@
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcphost/internal/params"
+)
+
+// Test the seeds derived from the input schema of a tool
+func TestSeeds(t *testing.T) {
+	tool := mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithString("sort", mcp.Required(), mcp.Enum("top", "new")),
+		mcp.WithNumber("limit"),
+	)
+	seeds := Seeds(tool)
+	assert.Len(t, seeds, len(malformed)+1+3*len(oddValues))
+	assert.Contains(t, seeds, []byte(`{"query":"a","sort":"top"}`))
+	assert.Contains(t, seeds, []byte(`{"limit":"\u202eevil","query":"a","sort":"top"}`))
+	assert.Contains(t, seeds, []byte(`{"query":{},"sort":"top"}`))
+	for _, seed := range seeds[len(malformed):] {
+		var args map[string]interface{}
+		require.NoError(t, json.Unmarshal(seed, &args), "%.100s", seed)
+	}
+}
+
+// Fuzz a tool decoding its arguments with package params and calling an API
+func FuzzSearch(f *testing.F) {
+	s := server.NewMCPServer("test", "1.0.0")
+	s.AddTool(mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithNumber("limit"),
+		mcp.WithArray("tags"),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args struct {
+			Query string   `json:"query" param:"required,max=100"`
+			Limit int      `json:"limit" param:"min=1,max=50,default=10"`
+			Tags  []string `json:"tags"`
+		}
+		if err := params.Decode(req, &args); err != nil {
+			return nil, err
+		}
+		resp, err := http.Get("https://api.example.com/search?q=" + args.Query)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return mcp.NewToolResultText(fmt.Sprintf("%d %s %d %v", resp.StatusCode, body, args.Limit, args.Tags)), nil
+	})
+	FuzzTools(f, s)
+}
@@ -0,0 +1,71 @@
+// Package mcptest provides helpers for testing MCP tool handlers and resources, and a
+// client calling servers under test in memory, through the whole MCP request path.
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewCallToolRequest builds a tools/call request for a handler.
+func NewCallToolRequest(name string, args map[string]interface{}) mcp.CallToolRequest {
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	return req
+}
+
+// ResultText returns the first text content of a result.
+func ResultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// ReadResource reads the resource at uri from s with a resources/read request. It
+// fails with the message of the error response.
+func ReadResource(ctx context.Context, s *server.MCPServer, uri string) ([]mcp.ResourceContents, error) {
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  mcp.MethodResourcesRead,
+		"params":  map[string]interface{}{"uri": uri},
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch response := s.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCError:
+		return nil, errors.New(response.Error.Message)
+	case mcp.JSONRPCResponse:
+		data, err := json.Marshal(response.Result)
+		if err != nil {
+			return nil, err
+		}
+		raw := json.RawMessage(data)
+		result, err := mcp.ParseReadResourceResult(&raw)
+		if err != nil {
+			return nil, err
+		}
+		return result.Contents, nil
+	default:
+		return nil, errors.New("unexpected response")
+	}
+}
+
+// ResourceText returns the text of the first text contents of a resource.
+func ResourceText(contents []mcp.ResourceContents) string {
+	for _, c := range contents {
+		if text, ok := c.(mcp.TextResourceContents); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
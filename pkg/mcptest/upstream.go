@@ -0,0 +1,36 @@
+package mcptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mark3labs/mcphost/internal/upstream"
+)
+
+// Upstream starts a test server answering with handler the requests to the APIs at
+// baseURLs that go through http.DefaultTransport, as the HTTP clients of the servers
+// send them, until the end of the test. Requests keep their path, so that
+//
+//	mcptest.Upstream(t, handler, "https://www.googleapis.com/customsearch/v1")
+//
+// has handler answer GET /customsearch/v1?q=... of the googlesearch server. Other
+// requests are sent as before. It returns the test server.
+func Upstream(t testing.TB, handler http.Handler, baseURLs ...string) *httptest.Server {
+	t.Helper()
+	mock := httptest.NewServer(handler)
+	t.Cleanup(mock.Close)
+	routes := make(upstream.Routes, len(baseURLs))
+	for _, raw := range baseURLs {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			t.Fatalf("mcptest: invalid upstream URL %q", raw)
+		}
+		routes[raw] = mock.URL + u.Path
+	}
+	base := http.DefaultTransport
+	http.DefaultTransport = upstream.Transport(base, routes)
+	t.Cleanup(func() { http.DefaultTransport = base })
+	return mock
+}
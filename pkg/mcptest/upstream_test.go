@@ -0,0 +1,27 @@
+package mcptest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test answering the requests to an upstream API with a test server
+func TestUpstream(t *testing.T) {
+	base := http.DefaultTransport
+	t.Run("mock", func(t *testing.T) {
+		Upstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.RequestURI()))
+		}), "https://api.example.com/v1")
+
+		resp, err := http.Get("https://api.example.com/v1/items?id=7")
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "/v1/items?id=7", string(body))
+	})
+	assert.Equal(t, base, http.DefaultTransport, "The transport is restored with the end of the test")
+}